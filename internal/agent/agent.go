@@ -0,0 +1,141 @@
+// Package agent installs and runs the kraze agent: a controller in the
+// cluster that keeps the services kraze installed reconciled between runs of
+// the CLI, re-applying resources that drifted and restarting crashed pods
+// behind port forwards.
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// Namespace is where the agent and its desired state live
+	Namespace = "kraze-system"
+
+	// Name of the agent's Deployment, ServiceAccount and ClusterRoleBinding
+	Name = "kraze-agent"
+
+	// DefaultInterval is how often the agent reconciles when nothing changes
+	DefaultInterval = 30 * time.Second
+
+	// managedByLabel marks the objects that make up the agent
+	managedByLabel = "app.kubernetes.io/managed-by"
+)
+
+// InstallOptions configures the agent's Deployment
+type InstallOptions struct {
+	Image    string        // Image running the kraze binary, with /kraze as its entrypoint
+	Interval time.Duration // Time between reconciles
+}
+
+// Install creates the agent's namespace, its ServiceAccount bound to
+// cluster-admin (the services it reconciles can contain any resource) and its
+// Deployment. Installing again updates the Deployment's image and interval.
+func Install(ctx context.Context, clientset kubernetes.Interface, opts InstallOptions) error {
+	labels := map[string]string{"app.kubernetes.io/name": Name, managedByLabel: "kraze"}
+	meta := metav1.ObjectMeta{Name: Name, Namespace: Namespace, Labels: labels}
+
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: Namespace, Labels: map[string]string{managedByLabel: "kraze"}}}
+	if _, err := clientset.CoreV1().Namespaces().Create(ctx, namespace, metav1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create namespace %s: %w", Namespace, err)
+	}
+
+	serviceAccount := &corev1.ServiceAccount{ObjectMeta: meta}
+	if _, err := clientset.CoreV1().ServiceAccounts(Namespace).Create(ctx, serviceAccount, metav1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create service account: %w", err)
+	}
+
+	binding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: Name, Labels: labels},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "cluster-admin"},
+		Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: Name, Namespace: Namespace}},
+	}
+	if _, err := clientset.RbacV1().ClusterRoleBindings().Create(ctx, binding, metav1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create cluster role binding: %w", err)
+	}
+
+	deployment := agentDeployment(meta, opts)
+	deployments := clientset.AppsV1().Deployments(Namespace)
+	existing, err := deployments.Get(ctx, Name, metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		if _, err := deployments.Create(ctx, deployment, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create deployment: %w", err)
+		}
+	case err != nil:
+		return fmt.Errorf("failed to get deployment: %w", err)
+	default:
+		existing.Labels = deployment.Labels
+		existing.Spec = deployment.Spec
+		if _, err := deployments.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update deployment: %w", err)
+		}
+	}
+	return nil
+}
+
+// agentDeployment returns the Deployment running 'kraze agent run'
+func agentDeployment(meta metav1.ObjectMeta, opts InstallOptions) *appsv1.Deployment {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	replicas := int32(1)
+	nonRoot := true
+	user := int64(65532)
+	return &appsv1.Deployment{
+		ObjectMeta: meta,
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			// Two agents reconciling at once would race each other
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/name": Name}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: meta.Labels},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: Name,
+					SecurityContext:    &corev1.PodSecurityContext{RunAsNonRoot: &nonRoot, RunAsUser: &user},
+					Containers: []corev1.Container{{
+						Name:            "agent",
+						Image:           opts.Image,
+						ImagePullPolicy: corev1.PullIfNotPresent,
+						Args:            []string{"agent", "run", "--interval", interval.String()},
+					}},
+				},
+			},
+		},
+	}
+}
+
+// Uninstall deletes the agent, its desired state and its status. The services
+// it reconciled are left as they are.
+func Uninstall(ctx context.Context, clientset kubernetes.Interface) error {
+	if err := clientset.RbacV1().ClusterRoleBindings().Delete(ctx, Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete cluster role binding: %w", err)
+	}
+	if err := clientset.CoreV1().Namespaces().Delete(ctx, Namespace, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete namespace %s: %w", Namespace, err)
+	}
+	return nil
+}
+
+// Deployment returns the agent's Deployment, or nil if it isn't installed
+func Deployment(ctx context.Context, clientset kubernetes.Interface) (*appsv1.Deployment, error) {
+	deployment, err := clientset.AppsV1().Deployments(Namespace).Get(ctx, Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent deployment: %w", err)
+	}
+	return deployment, nil
+}
@@ -0,0 +1,177 @@
+package agent
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hjames9/kraze/internal/providers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const redisResources = `apiVersion: v1
+kind: Service
+metadata:
+  name: redis
+  namespace: cache
+`
+
+func TestInstall(test *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset()
+
+	if err := Install(ctx, clientset, InstallOptions{Image: "kraze-agent:v1"}); err != nil {
+		test.Fatalf("Install() error = %v", err)
+	}
+	if err := Install(ctx, clientset, InstallOptions{Image: "kraze-agent:v2", Interval: time.Minute}); err != nil {
+		test.Fatalf("Install() again error = %v", err)
+	}
+
+	deployment, err := Deployment(ctx, clientset)
+	if err != nil || deployment == nil {
+		test.Fatalf("Deployment() = %v, %v", deployment, err)
+	}
+	container := deployment.Spec.Template.Spec.Containers[0]
+	if container.Image != "kraze-agent:v2" {
+		test.Errorf("image = %q, want kraze-agent:v2", container.Image)
+	}
+	if expected := []string{"agent", "run", "--interval", "1m0s"}; !reflect.DeepEqual(container.Args, expected) {
+		test.Errorf("args = %v, want %v", container.Args, expected)
+	}
+	binding, err := clientset.RbacV1().ClusterRoleBindings().Get(ctx, Name, metav1.GetOptions{})
+	if err != nil {
+		test.Fatal(err)
+	}
+	if binding.Subjects[0].Namespace != Namespace || binding.RoleRef.Name != "cluster-admin" {
+		test.Errorf("binding = %+v", binding)
+	}
+
+	if err := Uninstall(ctx, clientset); err != nil {
+		test.Fatalf("Uninstall() error = %v", err)
+	}
+	if _, err := clientset.RbacV1().ClusterRoleBindings().Get(ctx, Name, metav1.GetOptions{}); err == nil {
+		test.Error("cluster role binding still exists after Uninstall()")
+	}
+}
+
+func TestUpdateDesired(test *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset()
+
+	redis := DesiredService{Environment: "kraze-metadata", Name: "redis", Namespace: "cache", Resources: redisResources}
+	api := DesiredService{Environment: "kraze-metadata", Name: "api", Namespace: "app", PodSelector: "app=api", Ports: []string{"8080:80"}}
+	if err := UpdateDesired(ctx, clientset, "kraze-metadata", []DesiredService{redis, api}, nil); err != nil {
+		test.Fatalf("UpdateDesired() error = %v", err)
+	}
+	if err := Pause(ctx, clientset, time.Now().Add(time.Hour)); err != nil {
+		test.Fatalf("Pause() error = %v", err)
+	}
+
+	services, paused, err := LoadDesired(ctx, clientset)
+	if err != nil {
+		test.Fatalf("LoadDesired() error = %v", err)
+	}
+	if !paused {
+		test.Error("LoadDesired() not paused after Pause()")
+	}
+	if !reflect.DeepEqual(services, []DesiredService{api, redis}) {
+		test.Errorf("LoadDesired() = %+v", services)
+	}
+
+	if err := UpdateDesired(ctx, clientset, "kraze-metadata", nil, []string{"api"}); err != nil {
+		test.Fatalf("UpdateDesired() error = %v", err)
+	}
+	services, paused, err = LoadDesired(ctx, clientset)
+	if err != nil {
+		test.Fatalf("LoadDesired() error = %v", err)
+	}
+	if paused {
+		test.Error("LoadDesired() still paused after UpdateDesired()")
+	}
+	if !reflect.DeepEqual(services, []DesiredService{redis}) {
+		test.Errorf("LoadDesired() = %+v, want only redis", services)
+	}
+}
+
+func TestUpdateDesiredVariants(test *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset()
+
+	// Variants of one config share service names
+	for _, environment := range []string{"kraze-metadata", "kraze-metadata-pr-1"} {
+		if err := UpdateDesired(ctx, clientset, environment, []DesiredService{{Name: "api", Namespace: "app"}}, nil); err != nil {
+			test.Fatalf("UpdateDesired(%s) error = %v", environment, err)
+		}
+	}
+	if err := UpdateDesired(ctx, clientset, "kraze-metadata-pr-1", nil, []string{"api"}); err != nil {
+		test.Fatalf("UpdateDesired() error = %v", err)
+	}
+
+	services, _, err := LoadDesired(ctx, clientset)
+	if err != nil {
+		test.Fatalf("LoadDesired() error = %v", err)
+	}
+	if len(services) != 1 || services[0].Key() != "kraze-metadata.api" {
+		test.Errorf("LoadDesired() = %+v, want only the api of the default environment", services)
+	}
+}
+
+func TestReconcile(test *testing.T) {
+	ctx := context.Background()
+	controller := true
+	clientset := fake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "api-7f6d", Namespace: "app", Labels: map[string]string{"app": "api"},
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "api", UID: "1", Controller: &controller}},
+		},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name: "api", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+		}}},
+	})
+	err := UpdateDesired(ctx, clientset, "kraze-metadata", []DesiredService{
+		{Name: "api", Namespace: "app", PodSelector: "app=api", Ports: []string{"8080:80"}},
+		{Name: "redis", Namespace: "cache", Resources: redisResources},
+	}, nil)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	var applied []string
+	reconciler := &Reconciler{
+		clientset: clientset,
+		out:       io.Discard,
+		reapply: func(ctx context.Context, resources []*unstructured.Unstructured) ([]providers.ResourceRef, error) {
+			refs := providers.ResourceRefs(resources)
+			for _, ref := range refs {
+				applied = append(applied, ref.String())
+			}
+			return refs, nil
+		},
+	}
+	if err := reconciler.Reconcile(ctx); err != nil {
+		test.Fatalf("Reconcile() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(applied, []string{"Service/cache/redis"}) {
+		test.Errorf("re-applied %v, want [Service/cache/redis]", applied)
+	}
+	if pods, _ := clientset.CoreV1().Pods("app").List(ctx, metav1.ListOptions{}); len(pods.Items) != 0 {
+		test.Errorf("crashed pod wasn't restarted")
+	}
+	status, err := LoadStatus(ctx, clientset)
+	if err != nil || status == nil {
+		test.Fatalf("LoadStatus() = %v, %v", status, err)
+	}
+	if status.Services["kraze-metadata.api"].Restarted != 1 || status.Services["kraze-metadata.redis"].Reapplied != 1 {
+		test.Errorf("status = %+v", status.Services)
+	}
+	if !strings.HasPrefix(status.Services["kraze-metadata.api"].LastAction, "restarted 1") {
+		test.Errorf("api last action = %q", status.Services["kraze-metadata.api"].LastAction)
+	}
+}
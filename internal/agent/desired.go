@@ -0,0 +1,196 @@
+package agent
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// DesiredSecret holds what the agent keeps applied, one gzipped JSON key per
+// service of each environment (see DesiredService.Key). It's a Secret because
+// the applied resources can contain Secrets.
+const DesiredSecret = "kraze-agent-desired"
+
+// pausedUntilAnnotation on DesiredSecret stops the agent from reconciling
+// until the RFC 3339 time it holds, while kraze is changing services
+const pausedUntilAnnotation = "kraze.dev/paused-until"
+
+// DesiredService is how kraze last installed a service
+type DesiredService struct {
+	Environment string    `json:"environment,omitempty"` // State of the environment (variant) the service belongs to
+	Name        string    `json:"name"`
+	Namespace   string    `json:"namespace"`
+	PodSelector string    `json:"pod_selector,omitempty"` // Pods behind the service's port forwards
+	Ports       []string  `json:"ports,omitempty"`
+	Resources   string    `json:"resources"` // Multi-document YAML of the applied resources
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Key returns the key of the service in DesiredSecret, so variants (kraze
+// --suffix) sharing a cluster keep their own services
+func (svc DesiredService) Key() string {
+	return desiredKey(svc.Environment, svc.Name)
+}
+
+// desiredKey returns the key of a service of an environment in DesiredSecret
+func desiredKey(environment, name string) string {
+	if environment == "" {
+		return name
+	}
+	return environment + "." + name
+}
+
+// UpdateDesired records the services of an environment (its state name) as
+// the agent should keep them, forgets the removed ones and resumes
+// reconciling. Does nothing if the agent isn't installed.
+func UpdateDesired(ctx context.Context, clientset kubernetes.Interface, environment string, services []DesiredService, removed []string) error {
+	data := make(map[string][]byte, len(services))
+	for _, svc := range services {
+		svc.Environment = environment
+		encoded, err := encodeDesired(svc)
+		if err != nil {
+			return err
+		}
+		data[svc.Key()] = encoded
+	}
+
+	secrets := clientset.CoreV1().Secrets(Namespace)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		secret, err := secrets.Get(ctx, DesiredSecret, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			if len(data) == 0 {
+				return nil
+			}
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: DesiredSecret, Namespace: Namespace, Labels: map[string]string{managedByLabel: "kraze"}},
+				Data:       data,
+			}
+			_, err = secrets.Create(ctx, secret, metav1.CreateOptions{})
+			if errors.IsNotFound(err) {
+				// The namespace doesn't exist, so the agent isn't installed
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", DesiredSecret, err)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get %s: %w", DesiredSecret, err)
+		}
+
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		for name, encoded := range data {
+			secret.Data[name] = encoded
+		}
+		for _, name := range removed {
+			delete(secret.Data, desiredKey(environment, name))
+		}
+		delete(secret.Annotations, pausedUntilAnnotation)
+		if _, err := secrets.Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update %s: %w", DesiredSecret, err)
+		}
+		return nil
+	})
+}
+
+// Pause stops the agent from reconciling until UpdateDesired is called or
+// until passes, so it doesn't revert services kraze is in the middle of
+// changing. Does nothing if the agent has no desired state.
+func Pause(ctx context.Context, clientset kubernetes.Interface, until time.Time) error {
+	secrets := clientset.CoreV1().Secrets(Namespace)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		secret, err := secrets.Get(ctx, DesiredSecret, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get %s: %w", DesiredSecret, err)
+		}
+		if secret.Annotations == nil {
+			secret.Annotations = map[string]string{}
+		}
+		secret.Annotations[pausedUntilAnnotation] = until.UTC().Format(time.RFC3339)
+		if _, err := secrets.Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update %s: %w", DesiredSecret, err)
+		}
+		return nil
+	})
+}
+
+// LoadDesired returns the services the agent keeps reconciled, sorted by
+// environment and name, and whether reconciling is paused
+func LoadDesired(ctx context.Context, clientset kubernetes.Interface) ([]DesiredService, bool, error) {
+	secret, err := clientset.CoreV1().Secrets(Namespace).Get(ctx, DesiredSecret, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get %s: %w", DesiredSecret, err)
+	}
+
+	paused := false
+	if value, ok := secret.Annotations[pausedUntilAnnotation]; ok {
+		until, err := time.Parse(time.RFC3339, value)
+		paused = err == nil && time.Now().Before(until)
+	}
+
+	services := make([]DesiredService, 0, len(secret.Data))
+	for key, encoded := range secret.Data {
+		svc, err := decodeDesired(encoded)
+		if err != nil {
+			return nil, paused, fmt.Errorf("service '%s': %w", key, err)
+		}
+		services = append(services, svc)
+	}
+	sort.Slice(services, func(left, right int) bool { return services[left].Key() < services[right].Key() })
+	return services, paused, nil
+}
+
+// encodeDesired serializes a service as gzipped JSON
+func encodeDesired(svc DesiredService) ([]byte, error) {
+	data, err := json.Marshal(svc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize service '%s': %w", svc.Name, err)
+	}
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress service '%s': %w", svc.Name, err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress service '%s': %w", svc.Name, err)
+	}
+	return compressed.Bytes(), nil
+}
+
+// decodeDesired reads a service written by encodeDesired
+func decodeDesired(encoded []byte) (DesiredService, error) {
+	var svc DesiredService
+	reader, err := gzip.NewReader(bytes.NewReader(encoded))
+	if err != nil {
+		return svc, fmt.Errorf("failed to decompress desired state: %w", err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return svc, fmt.Errorf("failed to decompress desired state: %w", err)
+	}
+	if err := json.Unmarshal(data, &svc); err != nil {
+		return svc, fmt.Errorf("failed to parse desired state: %w", err)
+	}
+	return svc, nil
+}
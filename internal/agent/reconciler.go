@@ -0,0 +1,222 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/hjames9/kraze/internal/providers"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// StatusConfigMap is where the agent reports what it last did
+	StatusConfigMap = "kraze-agent-status"
+
+	// statusKey is the key of the status JSON in StatusConfigMap
+	statusKey = "status"
+)
+
+// Status is the agent's report of its reconciles
+type Status struct {
+	LastReconcile time.Time                `json:"last_reconcile"`
+	Services      map[string]ServiceStatus `json:"services,omitempty"`
+}
+
+// ServiceStatus is what the agent did for one service
+type ServiceStatus struct {
+	Reapplied    int       `json:"reapplied,omitempty"` // Resources re-applied since the agent started
+	Restarted    int       `json:"restarted,omitempty"` // Crashed pods restarted since the agent started
+	LastAction   string    `json:"last_action,omitempty"`
+	LastActionAt time.Time `json:"last_action_at,omitzero"`
+	Error        string    `json:"error,omitempty"` // Why the last reconcile of the service failed
+}
+
+// Reconciler keeps the desired services applied
+type Reconciler struct {
+	clientset kubernetes.Interface
+	out       io.Writer
+	status    Status
+
+	// reapply re-applies the resources that drifted, returning the ones it changed
+	reapply func(ctx context.Context, resources []*unstructured.Unstructured) ([]providers.ResourceRef, error)
+}
+
+// NewReconciler creates a reconciler for the cluster of restConfig, logging its actions to out
+func NewReconciler(restConfig *rest.Config, out io.Writer) (*Reconciler, error) {
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+	opts := &providers.ProviderOptions{Quiet: true, Output: out}
+	return &Reconciler{
+		clientset: clientset,
+		out:       out,
+		reapply: func(ctx context.Context, resources []*unstructured.Unstructured) ([]providers.ResourceRef, error) {
+			return providers.ReapplyDrift(ctx, restConfig, resources, opts)
+		},
+	}, nil
+}
+
+// Run reconciles every interval, and as soon as the desired state changes,
+// until ctx is done
+func (reconciler *Reconciler) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := reconciler.Reconcile(ctx); err != nil {
+			fmt.Fprintf(reconciler.out, "reconcile failed: %v\n", err)
+		}
+		if !reconciler.wait(ctx, ticker.C) {
+			return nil
+		}
+	}
+}
+
+// wait blocks until the next reconcile is due, when the interval passed or
+// the desired state changed. Returns false once ctx is done.
+func (reconciler *Reconciler) wait(ctx context.Context, tick <-chan time.Time) bool {
+	var events <-chan watch.Event
+	watcher, err := reconciler.clientset.CoreV1().Secrets(Namespace).Watch(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", DesiredSecret).String(),
+	})
+	if err == nil {
+		// Without a watch, changes are picked up on the next tick
+		defer watcher.Stop()
+		events = watcher.ResultChan()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-tick:
+			return true
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			// The watch starts with the Secret as it is now
+			if event.Type != watch.Added {
+				return true
+			}
+		}
+	}
+}
+
+// Reconcile re-applies the drifted resources of every desired service and
+// restarts the crashed pods of the services with port forwards, then writes
+// the agent's status. Does nothing while the agent is paused.
+func (reconciler *Reconciler) Reconcile(ctx context.Context) error {
+	services, paused, err := LoadDesired(ctx, reconciler.clientset)
+	if err != nil {
+		return err
+	}
+	if paused {
+		return nil
+	}
+
+	previous := reconciler.status.Services
+	reconciler.status.Services = make(map[string]ServiceStatus, len(services))
+	for _, svc := range services {
+		status := previous[svc.Key()]
+		status.Error = ""
+		if err := reconciler.reconcileService(ctx, svc, &status); err != nil {
+			status.Error = err.Error()
+			fmt.Fprintf(reconciler.out, "service '%s': %v\n", svc.Key(), err)
+		}
+		reconciler.status.Services[svc.Key()] = status
+	}
+	reconciler.status.LastReconcile = time.Now().UTC()
+	return writeStatus(ctx, reconciler.clientset, &reconciler.status)
+}
+
+// reconcileService reconciles one service, recording what it did in status
+func (reconciler *Reconciler) reconcileService(ctx context.Context, svc DesiredService, status *ServiceStatus) error {
+	resources, err := providers.ParseResources([]byte(svc.Resources))
+	if err != nil {
+		return fmt.Errorf("failed to parse resources: %w", err)
+	}
+	reapplied, err := reconciler.reapply(ctx, resources)
+	if len(reapplied) > 0 {
+		status.Reapplied += len(reapplied)
+		status.LastAction = fmt.Sprintf("re-applied %d drifted resource(s)", len(reapplied))
+		status.LastActionAt = time.Now().UTC()
+		for _, ref := range reapplied {
+			fmt.Fprintf(reconciler.out, "service '%s': re-applied %s\n", svc.Name, ref)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(svc.Ports) == 0 || svc.PodSelector == "" {
+		return nil
+	}
+	restarted, err := providers.RestartCrashedPods(ctx, reconciler.clientset, svc.Namespace, svc.PodSelector)
+	if len(restarted) > 0 {
+		status.Restarted += len(restarted)
+		status.LastAction = fmt.Sprintf("restarted %d crashed pod(s)", len(restarted))
+		status.LastActionAt = time.Now().UTC()
+		for _, pod := range restarted {
+			fmt.Fprintf(reconciler.out, "service '%s': restarted crashed pod %s\n", svc.Name, pod)
+		}
+	}
+	return err
+}
+
+// writeStatus stores the agent's status
+func writeStatus(ctx context.Context, clientset kubernetes.Interface, status *Status) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("failed to serialize agent status: %w", err)
+	}
+
+	configMaps := clientset.CoreV1().ConfigMaps(Namespace)
+	configMap, err := configMaps.Get(ctx, StatusConfigMap, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: StatusConfigMap, Namespace: Namespace, Labels: map[string]string{managedByLabel: "kraze"}},
+			Data:       map[string]string{statusKey: string(data)},
+		}
+		if _, err := configMaps.Create(ctx, configMap, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create %s: %w", StatusConfigMap, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %w", StatusConfigMap, err)
+	}
+	configMap.Data = map[string]string{statusKey: string(data)}
+	if _, err := configMaps.Update(ctx, configMap, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update %s: %w", StatusConfigMap, err)
+	}
+	return nil
+}
+
+// LoadStatus returns the agent's status, or nil if it hasn't reconciled yet
+func LoadStatus(ctx context.Context, clientset kubernetes.Interface) (*Status, error) {
+	configMap, err := clientset.CoreV1().ConfigMaps(Namespace).Get(ctx, StatusConfigMap, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", StatusConfigMap, err)
+	}
+	var status Status
+	if err := json.Unmarshal([]byte(configMap.Data[statusKey]), &status); err != nil {
+		return nil, fmt.Errorf("failed to parse agent status: %w", err)
+	}
+	return &status, nil
+}
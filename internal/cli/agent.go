@@ -0,0 +1,383 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/hjames9/kraze/internal/agent"
+	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/hjames9/kraze/internal/state"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// agentPauseTimeout is how long 'kraze up' keeps the agent paused, in case
+// it's interrupted before handing the agent the new desired state
+const agentPauseTimeout = 30 * time.Minute
+
+var (
+	agentImage    string
+	agentInterval time.Duration
+)
+
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Manage the in-cluster agent that keeps services reconciled",
+	Long: `Run a lightweight controller in the cluster that keeps the services kraze
+installed as kraze left them, between runs of the CLI:
+
+  - Resources that drifted (edited with kubectl, deleted by hand) are re-applied
+  - Pods behind a service's port forwards ('ports') that are stuck in
+    CrashLoopBackOff are deleted, so they're recreated without the back-off
+
+The agent reconciles every --interval, and right away when 'kraze up' or
+'kraze down' changes what it should keep applied. While 'kraze up' is
+installing, the agent is paused so it doesn't revert the changes.
+
+For kind clusters the agent image is built from the running kraze binary and
+loaded into the cluster. Other clusters (and kraze running on macOS or
+Windows) need --image, an image with a linux kraze binary as its entrypoint.
+
+Examples:
+  kraze agent install                   # Install and hand over the installed services
+  kraze agent install --interval 1m     # Reconcile every minute
+  kraze agent status                    # Show what the agent last did
+  kraze agent uninstall                 # Remove the agent (services are left as they are)`,
+}
+
+var agentInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the agent and hand it the installed services",
+	Args:  cobra.NoArgs,
+	RunE:  runAgentInstall,
+}
+
+var agentUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the agent",
+	Args:  cobra.NoArgs,
+	RunE:  runAgentUninstall,
+}
+
+var agentStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the agent's state and what it last did",
+	Args:  cobra.NoArgs,
+	RunE:  runAgentStatus,
+}
+
+// agentRunCmd is the agent's process in the cluster
+var agentRunCmd = &cobra.Command{
+	Use:    "run",
+	Short:  "Run the agent's reconcile loop in the cluster (internal)",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	RunE:   runAgentRun,
+}
+
+// agentClients parses the config and returns the cluster's kubeconfig (as
+// the impersonated identity, if any) and a clientset
+func agentClients(ctx context.Context, cmd *cobra.Command, command string) (*config.Config, string, kubernetes.Interface, func(), error) {
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return nil, "", nil, cleanupPack, err
+	}
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		return nil, "", nil, cleanupPack, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := requireCluster(cfg, command); err != nil {
+		return nil, "", nil, cleanupPack, err
+	}
+
+	kubeconfig, err := getShellKubeconfig(ctx, cfg)
+	if err != nil {
+		return nil, "", nil, cleanupPack, err
+	}
+	clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig)
+	if err != nil {
+		return nil, "", nil, cleanupPack, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	kubeconfig, err = impersonatedKubeconfig(kubeconfig, &cfg.Cluster)
+	if err != nil {
+		return nil, "", nil, cleanupPack, err
+	}
+	return cfg, kubeconfig, clientset, cleanupPack, nil
+}
+
+func runAgentInstall(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	cfg, kubeconfig, clientset, cleanup, err := agentClients(ctx, cmd, "agent install")
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+
+	image := agentImage
+	if image == "" {
+		if cfg.Cluster.IsExternal() {
+			return fmt.Errorf("external clusters can't load a locally built agent image, use --image")
+		}
+		if runtime.GOOS != "linux" {
+			return fmt.Errorf("the agent image is built from the running kraze binary, which isn't a linux binary on %s, use --image", runtime.GOOS)
+		}
+	}
+
+	if dryRun {
+		if image == "" {
+			image = "an image built from this kraze binary"
+		}
+		fmt.Printf("[DRY RUN] Would install the kraze agent in cluster '%s' using %s\n", cfg.Cluster.Name, image)
+		return nil
+	}
+	recordClusterActivity(cfg.Cluster.Name)
+
+	if image == "" {
+		if image, err = buildAgentImage(ctx, cfg.Cluster.Name); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Installing the kraze agent (%s)...\n", image)
+	if err := agent.Install(ctx, clientset, agent.InstallOptions{Image: image, Interval: agentInterval}); err != nil {
+		return fmt.Errorf("failed to install the agent: %w", err)
+	}
+
+	st, err := state.Load(ctx, clientset, cfg.Cluster.Name, cfg.StateBackend)
+	if err != nil {
+		return fmt.Errorf("failed to load cluster state: %w", err)
+	}
+	var services []*config.ServiceConfig
+	for name := range cfg.Services {
+		svc := cfg.Services[name]
+		if svc.IsEnabled() && st != nil && st.IsServiceInstalled(name) {
+			services = append(services, &svc)
+		}
+	}
+	if err := publishAgentServices(ctx, cfg, services, kubeconfig, clientset); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s The kraze agent is reconciling %d service(s) every %v\n", color.Checkmark(), len(services), agentDeploymentInterval(agentInterval))
+	fmt.Printf("\nTo check status: kraze agent status\n")
+	return nil
+}
+
+// buildAgentImage builds the agent image from the running kraze binary and
+// loads it into a kind cluster. The tag is the binary's hash, so installing
+// from a different kraze rolls the agent.
+func buildAgentImage(ctx context.Context, clusterName string) (string, error) {
+	binary, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the kraze binary: %w", err)
+	}
+	file, err := os.Open(binary)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the kraze binary: %w", err)
+	}
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	file.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to read the kraze binary: %w", err)
+	}
+	image := "kraze-agent:" + hex.EncodeToString(hash.Sum(nil))[:12]
+
+	if err := cluster.CheckDockerAvailable(ctx); err != nil {
+		return "", err
+	}
+	fmt.Printf("Building agent image %s...\n", image)
+	if err := cluster.NewImageManager(verbose).BuildAgentImage(ctx, binary, image); err != nil {
+		return "", err
+	}
+	if err := cluster.NewKindManager().LoadImage(ctx, clusterName, image); err != nil {
+		return "", fmt.Errorf("failed to load agent image: %w", err)
+	}
+	return image, nil
+}
+
+// agentDeploymentInterval returns the interval the agent runs with
+func agentDeploymentInterval(interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return agent.DefaultInterval
+	}
+	return interval
+}
+
+func runAgentUninstall(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	cfg, _, clientset, cleanup, err := agentClients(ctx, cmd, "agent uninstall")
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would remove the kraze agent from cluster '%s'\n", cfg.Cluster.Name)
+		return nil
+	}
+	recordClusterActivity(cfg.Cluster.Name)
+
+	if err := agent.Uninstall(ctx, clientset); err != nil {
+		return err
+	}
+	fmt.Printf("%s Removed the kraze agent; services are left as they are\n", color.Checkmark())
+	return nil
+}
+
+func runAgentStatus(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	cfg, _, clientset, cleanup, err := agentClients(ctx, cmd, "agent status")
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+
+	deployment, err := agent.Deployment(ctx, clientset)
+	if err != nil {
+		return err
+	}
+	if deployment == nil {
+		fmt.Printf("The kraze agent isn't installed in cluster '%s' (install it with 'kraze agent install')\n", cfg.Cluster.Name)
+		return nil
+	}
+	ready := fmt.Sprintf("%s Running", color.Checkmark())
+	if deployment.Status.ReadyReplicas == 0 {
+		ready = fmt.Sprintf("%s Not ready", color.Warning())
+	}
+	fmt.Printf("Agent:  %s (%s)\n", ready, deployment.Spec.Template.Spec.Containers[0].Image)
+
+	status, err := agent.LoadStatus(ctx, clientset)
+	if err != nil {
+		return err
+	}
+	if status == nil {
+		fmt.Println("The agent hasn't reconciled yet")
+		return nil
+	}
+	fmt.Printf("Last reconcile: %s\n\n", status.LastReconcile.Local().Format(time.RFC3339))
+	printAgentServices(status)
+	return nil
+}
+
+// printAgentServices prints a table of what the agent did for each service
+func printAgentServices(status *agent.Status) {
+	names := make([]string, 0, len(status.Services))
+	for name := range status.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Printf("%-20s %-10s %-10s %s\n", "SERVICE", "REAPPLIED", "RESTARTED", "LAST ACTION")
+	fmt.Println("--------------------------------------------------------------------------------")
+	for _, name := range names {
+		svc := status.Services[name]
+		action := "-"
+		if svc.LastAction != "" {
+			action = fmt.Sprintf("%s (%s)", svc.LastAction, svc.LastActionAt.Local().Format(time.RFC3339))
+		}
+		fmt.Printf("%-20s %-10d %-10d %s\n", name, svc.Reapplied, svc.Restarted, action)
+		if svc.Error != "" {
+			fmt.Printf("  %s %s\n", color.Warning(), svc.Error)
+		}
+	}
+}
+
+func runAgentRun(cmd *cobra.Command, args []string) error {
+	restConfig, err := rest.InClusterConfig()
+	if err != nil {
+		return fmt.Errorf("the agent only runs in a cluster: %w", err)
+	}
+	reconciler, err := agent.NewReconciler(restConfig, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	interval := agentDeploymentInterval(agentInterval)
+	fmt.Printf("kraze agent %s reconciling every %v\n", version, interval)
+	return reconciler.Run(ctx, interval)
+}
+
+// pauseAgent stops the agent, if it's installed, from reverting the services
+// 'kraze up' is about to change. Returns true if the agent is installed.
+func pauseAgent(ctx context.Context, clientset kubernetes.Interface) bool {
+	deployment, err := agent.Deployment(ctx, clientset)
+	if err != nil {
+		Verbose("Warning: failed to check for the kraze agent: %v", err)
+		return false
+	}
+	if deployment == nil {
+		return false
+	}
+	if err := agent.Pause(ctx, clientset, time.Now().Add(agentPauseTimeout)); err != nil {
+		fmt.Printf("%s Failed to pause the kraze agent: %v\n", color.Warning(), err)
+	}
+	return true
+}
+
+// publishAgentServices hands the agent the services as they're now installed,
+// which also resumes it
+func publishAgentServices(ctx context.Context, cfg *config.Config, services []*config.ServiceConfig, kubeconfig string, clientset kubernetes.Interface) error {
+	var desired []agent.DesiredService
+	for _, svc := range services {
+		opts := &providers.ProviderOptions{
+			ClusterName: cfg.Cluster.Name,
+			KubeConfig:  kubeconfig,
+			Verbose:     verbose,
+			Quiet:       true,
+			Registries:  cfg.Registries,
+		}
+		resources, err := providers.DeployedResources(ctx, svc, opts)
+		if err != nil {
+			return fmt.Errorf("failed to read the applied resources of '%s' for the agent: %w", svc.Name, err)
+		}
+		data, err := providers.MarshalResources(resources)
+		if err != nil {
+			return err
+		}
+		selector, err := providers.ServicePodSelector(svc)
+		if err != nil {
+			return err
+		}
+		desired = append(desired, agent.DesiredService{
+			Name:        svc.Name,
+			Namespace:   svc.GetNamespace(),
+			PodSelector: selector,
+			Ports:       svc.Ports,
+			Resources:   string(data),
+			UpdatedAt:   time.Now().UTC(),
+		})
+	}
+	if err := agent.UpdateDesired(ctx, clientset, state.Name(), desired, nil); err != nil {
+		return fmt.Errorf("failed to update the agent's desired state: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	agentInstallCmd.Flags().StringVar(&agentImage, "image", "", "Agent image, with a linux kraze binary as its entrypoint (default: built from this binary, kind clusters only)")
+	agentInstallCmd.Flags().DurationVar(&agentInterval, "interval", agent.DefaultInterval, "How often the agent reconciles")
+	agentRunCmd.Flags().DurationVar(&agentInterval, "interval", agent.DefaultInterval, "How often the agent reconciles")
+
+	agentCmd.AddCommand(agentInstallCmd)
+	agentCmd.AddCommand(agentUninstallCmd)
+	agentCmd.AddCommand(agentStatusCmd)
+	agentCmd.AddCommand(agentRunCmd)
+}
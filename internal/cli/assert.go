@@ -0,0 +1,207 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/hjames9/kraze/internal/state"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	assertLabels []string
+	assertOutput string
+)
+
+// assertReport is the output of 'kraze assert -o json|yaml'
+type assertReport struct {
+	Passed   bool                  `json:"passed"`
+	Services []serviceAssertReport `json:"services"`
+}
+
+// serviceAssertReport is the outcome of one service's assertions
+type serviceAssertReport struct {
+	Name    string                      `json:"name"`
+	Passed  bool                        `json:"passed"`
+	Error   string                      `json:"error,omitempty"` // Why the assertions could not be checked
+	Results []providers.AssertionResult `json:"results"`
+}
+
+var assertCmd = &cobra.Command{
+	Use:   "assert [services...]",
+	Short: "Check services' assertions",
+	Long: `Check the assertions declared with 'assertions:' in kraze.yml: resources each service is
+expected to have once it's ready, with expected field values. Each assertion is retried until
+it passes or its timeout expires, like 'kraze up' does after a service becomes ready.
+
+Exits non-zero if any assertion fails, with what was found instead.
+
+Examples:
+  kraze assert                       # Check the assertions of all enabled services
+  kraze assert api worker            # Check the assertions of specific services
+  kraze assert --label tier=backend  # Check services with label tier=backend
+  kraze assert -o json               # Machine-readable results for CI`,
+	ValidArgsFunction: getServiceNames,
+	RunE:              runAssert,
+}
+
+func runAssert(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	format, err := parseOutputFormat(assertOutput)
+	if err != nil {
+		return err
+	}
+
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanupPack()
+
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := requireCluster(cfg, "assert"); err != nil {
+		return err
+	}
+
+	if len(args) > 0 && len(assertLabels) > 0 {
+		return fmt.Errorf("cannot specify both service names and labels, use one or the other")
+	}
+	selected := cfg.Services
+	if len(assertLabels) > 0 {
+		if selected, err = cfg.FilterServicesByLabels(assertLabels); err != nil {
+			return fmt.Errorf("failed to filter services by labels: %w", err)
+		}
+	} else if len(args) > 0 {
+		if selected, err = cfg.FilterServices(args); err != nil {
+			return fmt.Errorf("failed to filter services: %w", err)
+		}
+	}
+
+	kubeconfig, err := getShellKubeconfig(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	recordClusterActivity(cfg.Cluster.Name)
+
+	clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	st, err := state.Load(ctx, clientset, cfg.Cluster.Name, cfg.StateBackend)
+	if err != nil {
+		Verbose("Warning: failed to load cluster state: %v", err)
+	}
+
+	report := assertReport{Passed: true}
+	for _, name := range sortedServiceNames(selected) {
+		svc := selected[name]
+		if !svc.IsEnabled() || len(svc.Assertions) == 0 {
+			continue
+		}
+		if dryRun {
+			fmt.Printf("[DRY RUN] Would check %d assertion(s) of '%s'\n", len(svc.Assertions), name)
+			continue
+		}
+
+		Verbose("Checking assertions of '%s'...", name)
+		entry := serviceAssertReport{Name: name}
+		entry.Results, err = providers.RunAssertions(ctx, kubeconfig, &svc)
+		if err != nil {
+			entry.Error = err.Error()
+		} else {
+			entry.Passed = providers.AssertionsPassed(entry.Results)
+			recordAssertions(ctx, clientset, st, name, entry.Results)
+		}
+		if !entry.Passed {
+			report.Passed = false
+		}
+		report.Services = append(report.Services, entry)
+	}
+	if dryRun {
+		return nil
+	}
+
+	if format.isStructured() {
+		if err := printStructured(format, report); err != nil {
+			return err
+		}
+	} else if len(report.Services) == 0 {
+		fmt.Println("No services with assertions")
+	} else {
+		printAssertSummary(report)
+	}
+
+	if !report.Passed {
+		var failed []string
+		for _, entry := range report.Services {
+			if !entry.Passed {
+				failed = append(failed, entry.Name)
+			}
+		}
+		return fmt.Errorf("assertions failed: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// printAssertSummary prints each service's assertion results
+func printAssertSummary(report assertReport) {
+	for _, entry := range report.Services {
+		fmt.Printf("\n%s\n", entry.Name)
+		if entry.Error != "" {
+			fmt.Printf("  %s %s\n", color.Cross(), entry.Error)
+			continue
+		}
+		for _, result := range entry.Results {
+			if result.Passed {
+				fmt.Printf("  %s %s\n", color.Checkmark(), result.Assertion)
+			} else {
+				fmt.Printf("  %s %s: %s\n", color.Cross(), result.Assertion, result.Message)
+			}
+		}
+	}
+}
+
+// recordAssertions saves a service's assertion results in the cluster state,
+// for 'kraze status' to show
+func recordAssertions(ctx context.Context, clientset kubernetes.Interface, st *state.ClusterState, serviceName string, results []providers.AssertionResult) {
+	if st == nil {
+		return
+	}
+	records := make([]state.AssertionResult, 0, len(results))
+	for _, result := range results {
+		records = append(records, state.AssertionResult{Assertion: result.Assertion, Passed: result.Passed, Message: result.Message})
+	}
+
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+	st.SetAssertions(serviceName, records)
+	if err := st.Save(ctx, clientset); err != nil {
+		Verbose("Warning: failed to save cluster state (assertions): %v", err)
+	}
+}
+
+// assertionsError describes a service's failed assertions
+func assertionsError(serviceName string, results []providers.AssertionResult) error {
+	var failed []string
+	for _, result := range results {
+		if !result.Passed {
+			failed = append(failed, fmt.Sprintf("%s: %s", result.Assertion, result.Message))
+		}
+	}
+	return fmt.Errorf("assertions of '%s' failed:\n  %s", serviceName, strings.Join(failed, "\n  "))
+}
+
+func init() {
+	assertCmd.Flags().StringSliceVarP(&assertLabels, "label", "l", []string{}, "Filter services by label (format: key=value, can be specified multiple times)")
+	registerLabelCompletion(assertCmd)
+	addOutputFlag(assertCmd, &assertOutput)
+}
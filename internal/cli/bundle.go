@@ -0,0 +1,218 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/pack"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/spf13/cobra"
+)
+
+var bundleOutput string
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Package a kraze deployment with its images for offline installs",
+	Long: `Bundle a kraze deployment with everything it needs to install without
+network access into a single .tar.gz archive.
+
+The bundle includes everything 'kraze pack' does, plus:
+  - The kind node image
+  - Every image the services use, built or pulled at bundle time
+  - The charts of the built-in add-ons (e.g., cert-manager, metallb)
+  - The cluster's preload_images
+  - The images of the helper pods kraze runs (dependency probes, snapshots)
+
+Install it on a machine without network access:
+  kraze bundle -o myapp-bundle.tar.gz
+  kraze up --from-bundle myapp-bundle.tar.gz
+
+Images pinned by digest, or run with imagePullPolicy: Always, are still pulled
+by the cluster; use tags with the default pull policy for offline installs.`,
+	RunE: runBundle,
+}
+
+func init() {
+	bundleCmd.Flags().StringVarP(&bundleOutput, "output", "o", "", "Output file path (default: <cluster-name>-bundle.tar.gz)")
+}
+
+func runBundle(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	cfgPaths, err := resolveConfigFiles(cmd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if cfg.Cluster.IsExternal() || cfg.Cluster.IsNone() {
+		return fmt.Errorf("'kraze bundle' packages a kind cluster, so it doesn't work with cluster.external or cluster.none (use 'kraze pack')")
+	}
+	if err := cluster.CheckDockerAvailable(ctx); err != nil {
+		return err
+	}
+
+	outputPath := bundleOutput
+	if outputPath == "" {
+		outputPath = cfg.Cluster.Name + "-bundle.tar.gz"
+	}
+	absOutput, err := filepath.Abs(outputPath)
+	if err != nil {
+		return fmt.Errorf("resolving output path: %w", err)
+	}
+
+	if hasExtraMounts(cfg) {
+		fmt.Printf("Warning: cluster.config[].extraMounts are not bundled (they are runtime host paths).\n")
+		fmt.Printf("         The target machine must have these paths available.\n\n")
+	}
+	summariseRemoteAssets(cfg)
+
+	kindMgr := cluster.NewKindManager()
+	images, err := collectBundleImages(ctx, cfg, kindMgr)
+	if err != nil {
+		return err
+	}
+
+	// The node image is saved by tag, as loading an archive drops registry digests
+	nodeImage := kindMgr.NodeImage(&cfg.Cluster)
+	if err := ensureLocalImage(ctx, kindMgr, nodeImage, cfg.Registries); err != nil {
+		return err
+	}
+	nodeImage, err = cluster.TagWithoutDigest(ctx, nodeImage)
+	if err != nil {
+		return err
+	}
+
+	stagingDir, err := os.MkdirTemp("", "kraze-bundle-images-*")
+	if err != nil {
+		return fmt.Errorf("creating temp dir for images: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+
+	bundleImages := &pack.BundleImages{
+		NodeImage:        nodeImage,
+		NodeImageArchive: filepath.Join(stagingDir, "node.tar"),
+		Images:           images,
+		ImagesArchive:    filepath.Join(stagingDir, "images.tar"),
+	}
+	fmt.Printf("Saving node image %s...\n", nodeImage)
+	if err := cluster.SaveImages(ctx, bundleImages.NodeImageArchive, []string{nodeImage}); err != nil {
+		return err
+	}
+	if len(images) > 0 {
+		fmt.Printf("Saving %d image(s)...\n", len(images))
+		if err := cluster.SaveImages(ctx, bundleImages.ImagesArchive, images); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Bundling to %s...\n", outputPath)
+	if err := pack.CreateBundle(cfgPaths, cfg, version, absOutput, bundleImages, verbose); err != nil {
+		return fmt.Errorf("bundling failed: %w", err)
+	}
+
+	info, err := os.Stat(absOutput)
+	if err == nil {
+		fmt.Printf("Created %s (%s, %d image(s) and the node image)\n", outputPath, humanBytes(info.Size()), len(images))
+	} else {
+		fmt.Printf("Created %s\n", outputPath)
+	}
+	return nil
+}
+
+// collectBundleImages builds the images of the enabled services and pulls the
+// other images they use, the preload images and the images of kraze's helper
+// pods, returning every image the bundle saves sorted
+func collectBundleImages(ctx context.Context, cfg *config.Config, kindMgr *cluster.KindManager) ([]string, error) {
+	imgMgr := cluster.NewImageManager(verbose)
+	for _, name := range cfg.GetAllServiceNames() {
+		svc := cfg.Services[name]
+		if !svc.IsEnabled() || svc.Build == nil {
+			continue
+		}
+		fmt.Printf("Building %s...\n", svc.Build.Image)
+		if _, err := imgMgr.BuildImage(ctx, svc.Build, false); err != nil {
+			return nil, fmt.Errorf("failed to build image for '%s': %w", name, err)
+		}
+	}
+
+	inventory, err := imageInventoryFor(ctx, cfg, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	images := append(slices.Clone(cfg.Cluster.PreloadImages), providers.HelperImages()...)
+	for _, entry := range inventory {
+		images = append(images, entry.Image)
+	}
+	slices.Sort(images)
+	images = slices.Compact(images)
+
+	for _, image := range images {
+		if err := ensureLocalImage(ctx, kindMgr, image, cfg.Registries); err != nil {
+			return nil, err
+		}
+		if cluster.ParseImageReference(image).Digest != "" {
+			fmt.Printf("%s %s is pinned by digest, which a loaded image doesn't keep; the cluster will still try to pull it\n", color.Warning(), image)
+		}
+	}
+	return images, nil
+}
+
+// ensureLocalImage pulls an image unless it's in the local daemon already
+func ensureLocalImage(ctx context.Context, kindMgr *cluster.KindManager, image string, registries []config.RegistryConfig) error {
+	info, err := cluster.NewImageManager(verbose).GetImageInfo(ctx, image)
+	if err != nil {
+		return err
+	}
+	if info.InLocalDaemon {
+		return nil
+	}
+	fmt.Printf("Pulling %s...\n", image)
+	if err := kindMgr.PullImage(ctx, image, registries); err != nil {
+		return fmt.Errorf("failed to pull %s: %w", image, err)
+	}
+	return nil
+}
+
+// loadBundleImages loads the images saved in a bundle into the local daemon,
+// from where 'kraze up' loads them into the cluster
+func loadBundleImages(ctx context.Context, bundle *pack.Bundle) error {
+	fmt.Printf("Loading %d image(s) and the node image from the bundle...\n", len(bundle.Metadata.Images))
+	for _, archive := range bundle.ImageArchives() {
+		if err := cluster.LoadImageArchive(ctx, archive); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadBundleNodeImages loads the bundled images no service uses, the preload
+// images and those of kraze's helper pods, into the nodes of the cluster.
+// Service images are loaded as their services install.
+func loadBundleNodeImages(ctx context.Context, kindMgr *cluster.KindManager, cfg *config.Config, bundle *pack.Bundle) error {
+	var images []string
+	for _, image := range append(slices.Clone(cfg.Cluster.PreloadImages), providers.HelperImages()...) {
+		if slices.Contains(bundle.Metadata.Images, image) && !slices.Contains(images, image) {
+			images = append(images, image)
+		}
+	}
+	if len(images) == 0 {
+		return nil
+	}
+	Verbose("Loading bundled image(s) %s into the cluster...", strings.Join(images, ", "))
+	if err := kindMgr.LoadImages(ctx, cfg.Cluster.Name, images); err != nil {
+		return fmt.Errorf("failed to load bundled images into the cluster: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/spf13/cobra"
+)
+
+var (
+	chartPushRepo      string
+	chartPushVersion   string
+	chartPushPlainHTTP bool
+	chartPushOutput    string
+)
+
+var chartCmd = &cobra.Command{
+	Use:   "chart",
+	Short: "Work with the local Helm charts of helm services",
+}
+
+var chartPushCmd = &cobra.Command{
+	Use:   "push [services...]",
+	Short: "Package local charts and push them to an OCI repository",
+	Long: `Package the local charts of helm services (services with 'path') and push them
+to an OCI repository, so CI and teammates can install the same chart with
+'repo' and 'chart' instead of a filesystem path.
+
+The repository comes from --repo, or charts.repository in the config:
+
+  charts:
+    repository: oci://localhost:5000/charts
+    plain_http: true    # Registry without TLS
+
+Charts are versioned from their content: the Chart.yaml version gets a
+prerelease suffix with a hash of the chart's files (e.g. 1.2.0-kraze-3f9a1c2b4d5e),
+so unchanged charts keep their version and every change gets a new one. Use
+--version to push a release version instead. Registry credentials are read
+from 'helm registry login'.
+
+Without service names, every enabled service with a local chart is pushed.
+
+Examples:
+  kraze chart push                                  # Push all local charts
+  kraze chart push api --repo oci://ghcr.io/org/charts
+  kraze chart push api --version 1.4.0              # Push a release version`,
+	ValidArgsFunction: getServiceNames,
+	RunE:              runChartPush,
+}
+
+func runChartPush(cmd *cobra.Command, args []string) error {
+	format, err := parseOutputFormat(chartPushOutput)
+	if err != nil {
+		return err
+	}
+
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanupPack()
+
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	repository := chartPushRepo
+	plainHTTP := chartPushPlainHTTP
+	if repository == "" {
+		repository = cfg.Charts.Repository
+		plainHTTP = plainHTTP || cfg.Charts.PlainHTTP
+	}
+	if repository == "" {
+		return fmt.Errorf("no chart repository: pass --repo or set charts.repository in the config")
+	}
+
+	services, err := localChartServices(cfg, args)
+	if err != nil {
+		return err
+	}
+	if len(services) == 0 {
+		return fmt.Errorf("no enabled helm services with a local chart")
+	}
+
+	if dryRun {
+		for _, svc := range services {
+			fmt.Printf("[DRY RUN] Would push chart '%s' (%s) to %s\n", svc.Name, svc.Path, repository)
+		}
+		return nil
+	}
+
+	helm := providers.NewHelmProviderForPacking(verbose, cfg.Registries)
+	var pushed []*providers.PushedChart
+	for _, svc := range services {
+		Verbose("Packaging chart for '%s' from %s", svc.Name, svc.Path)
+		result, err := helm.PushChart(svc, repository, chartPushVersion, plainHTTP)
+		if err != nil {
+			return fmt.Errorf("service '%s': %w", svc.Name, err)
+		}
+		pushed = append(pushed, result)
+		if !format.isStructured() {
+			fmt.Printf("%s Pushed %s\n", color.Checkmark(), result.Ref())
+		}
+	}
+
+	if format.isStructured() {
+		return printStructured(format, pushed)
+	}
+
+	fmt.Printf("\nReference the pushed charts with:\n")
+	for _, result := range pushed {
+		fmt.Printf("  %s:\n    type: helm\n    repo: %s\n    chart: %s\n    version: %s\n", result.Service, result.Repository, result.Chart, result.Version)
+	}
+	return nil
+}
+
+// localChartServices returns the named services, or every enabled service with a
+// local chart, sorted by name. Named services must be helm services with a local chart.
+func localChartServices(cfg *config.Config, names []string) ([]*config.ServiceConfig, error) {
+	if len(names) == 0 {
+		for _, name := range cfg.GetAllServiceNames() {
+			svc := cfg.Services[name]
+			if svc.IsEnabled() && svc.IsLocalChart() {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	var services []*config.ServiceConfig
+	for _, name := range names {
+		svc, exists := cfg.Services[name]
+		if !exists {
+			return nil, fmt.Errorf("service '%s' not found in configuration", name)
+		}
+		if !svc.IsLocalChart() {
+			return nil, fmt.Errorf("service '%s' does not use a local Helm chart", name)
+		}
+		services = append(services, &svc)
+	}
+	return services, nil
+}
+
+func init() {
+	chartPushCmd.Flags().StringVar(&chartPushRepo, "repo", "", "OCI repository to push to (default: charts.repository from the config)")
+	chartPushCmd.Flags().StringVar(&chartPushVersion, "version", "", "Chart version to push (default: Chart.yaml version with a content hash suffix)")
+	chartPushCmd.Flags().BoolVar(&chartPushPlainHTTP, "plain-http", false, "Push over HTTP instead of HTTPS")
+	addOutputFlag(chartPushCmd, &chartPushOutput)
+	chartCmd.AddCommand(chartPushCmd)
+}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/spf13/cobra"
+)
+
+var chartDocsAll bool
+
+var chartDocsCmd = &cobra.Command{
+	Use:   "chart-docs SERVICE [keys...]",
+	Short: "Explain the chart values a helm service overrides",
+	Long: `Show the documentation of the chart values a helm service sets, taken from the
+comments in the chart's values.yaml and the parameter tables in its README,
+next to the chart default and the service's value.
+
+Local charts are read from disk; remote charts are pulled (no cluster needed).
+Values are taken from the service's values files or values_inline.
+
+Keys that the chart does not define are flagged: they are usually typos or were
+copied from a different chart version, and Helm silently ignores them.
+
+Pass keys (or key prefixes) to limit the output, and --all to also list the
+chart's values that the service does not override.
+
+Examples:
+  kraze chart-docs postgres                 # Document postgres's overridden values
+  kraze chart-docs postgres auth            # Only keys under auth
+  kraze chart-docs redis --all              # Every value of the chart
+  kraze chart-docs redis --all replica      # Every value under replica`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: getServiceNames,
+	RunE:              runChartDocs,
+}
+
+func runChartDocs(cmd *cobra.Command, args []string) error {
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanupPack()
+
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	svc, exists := cfg.Services[args[0]]
+	if !exists {
+		return fmt.Errorf("service '%s' not found in configuration", args[0])
+	}
+	if !svc.IsHelm() {
+		return fmt.Errorf("service '%s' is a %s service, chart-docs only applies to helm services", svc.Name, svc.Type)
+	}
+
+	userValues, err := providers.LoadServiceValues(&svc)
+	if err != nil {
+		return fmt.Errorf("failed to load values: %w", err)
+	}
+
+	Verbose("Fetching chart for service '%s'...", svc.Name)
+	docs, err := providers.FetchChartDocs(&svc, cfg.Registries, verbose)
+	if err != nil {
+		return err
+	}
+
+	valueDocs, err := providers.DocumentChartValues(docs, userValues, chartDocsAll)
+	if err != nil {
+		return err
+	}
+	valueDocs = filterValueDocs(valueDocs, args[1:])
+
+	fmt.Printf("Chart: %s", color.Cyan(docs.Name))
+	if svc.Version != "" {
+		fmt.Printf(" (version %s)", svc.Version)
+	}
+	fmt.Println()
+	if len(docs.Values) == 0 {
+		fmt.Printf("%s Chart has no values.yaml\n", color.Warning())
+	}
+
+	if len(valueDocs) == 0 {
+		if chartDocsAll || len(args) > 1 {
+			fmt.Println("\nNo matching values")
+		} else {
+			fmt.Println("\nService does not override any values, use --all to list the chart's values")
+		}
+		return nil
+	}
+
+	unknown := 0
+	for _, doc := range valueDocs {
+		printValueDoc(doc)
+		if doc.Overridden && !doc.InChart {
+			unknown++
+		}
+	}
+
+	if unknown > 0 {
+		fmt.Printf("\n%s %d overridden key(s) not defined by the chart; Helm ignores values the templates don't use\n", color.Warning(), unknown)
+	}
+	return nil
+}
+
+// filterValueDocs keeps the docs whose key equals or is nested under one of the prefixes
+func filterValueDocs(docs []providers.ChartValueDoc, prefixes []string) []providers.ChartValueDoc {
+	if len(prefixes) == 0 {
+		return docs
+	}
+	var filtered []providers.ChartValueDoc
+	for _, doc := range docs {
+		for _, prefix := range prefixes {
+			if doc.Key == prefix || strings.HasPrefix(doc.Key, prefix+".") {
+				filtered = append(filtered, doc)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// printValueDoc prints one documented value
+func printValueDoc(doc providers.ChartValueDoc) {
+	fmt.Println()
+	if doc.Overridden {
+		fmt.Printf("%s\n", color.Cyan(doc.Key))
+		fmt.Printf("  Value:    %s\n", indentContinuation(doc.Value, "            "))
+	} else {
+		fmt.Printf("%s\n", doc.Key)
+	}
+
+	switch {
+	case doc.Default != "":
+		fmt.Printf("  Default:  %s\n", indentContinuation(doc.Default, "            "))
+	case doc.InChart:
+		fmt.Printf("  Default:  (not set, free-form)\n")
+	default:
+		fmt.Printf("  %s Not defined by the chart\n", color.Warning())
+	}
+
+	if doc.Comment != "" {
+		if doc.CommentFrom != "" {
+			fmt.Printf("  From %s:\n", doc.CommentFrom)
+		}
+		for _, line := range strings.Split(doc.Comment, "\n") {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+	for _, line := range doc.Readme {
+		fmt.Printf("  README: %s\n", line)
+	}
+}
+
+// indentContinuation indents every line after the first of a multi-line value
+func indentContinuation(value, indent string) string {
+	return strings.ReplaceAll(value, "\n", "\n"+indent)
+}
+
+func init() {
+	chartDocsCmd.Flags().BoolVar(&chartDocsAll, "all", false, "Also list the chart's values that the service does not override")
+}
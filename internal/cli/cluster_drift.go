@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/hjames9/kraze/internal/state"
+	"golang.org/x/term"
+)
+
+// createKindCluster creates a kind cluster and adds it to ~/.kube/config
+func createKindCluster(ctx context.Context, kindMgr *cluster.KindManager, clusterCfg *config.ClusterConfig) error {
+	if err := kindMgr.CheckNodeImageVersion(clusterCfg); err != nil {
+		return fmt.Errorf("%w\nSet cluster.version (or cluster.node_image) to a release in that range", err)
+	}
+
+	if err := kindMgr.CreateCluster(ctx, clusterCfg); err != nil {
+		return fmt.Errorf("failed to create cluster: %w", err)
+	}
+
+	// Update ~/.kube/config with cluster access (Use container IP)
+	Verbose("Updating kubeconfig...")
+	if err := kindMgr.UpdateKubeconfigFile(clusterCfg.Name); err != nil {
+		Verbose("Warning: failed to update kubeconfig: %v", err)
+	} else {
+		Verbose("Kubeconfig updated (context: kind-%s)", clusterCfg.Name)
+	}
+	return nil
+}
+
+// recreateDriftedCluster compares a kind cluster with the checksum of the
+// cluster config it was created from. A drifted cluster is recreated with
+// --recreate-cluster or when confirmed at a terminal; otherwise kraze warns
+// and installs into it as is.
+func recreateDriftedCluster(ctx context.Context, kindMgr *cluster.KindManager, clusterCfg *config.ClusterConfig) error {
+	drifted, err := hasClusterDrifted(ctx, kindMgr, clusterCfg)
+	if err != nil {
+		Verbose("Warning: failed to check the cluster for config drift: %v", err)
+		return nil
+	}
+	if !drifted {
+		return nil
+	}
+
+	fmt.Printf("%s The cluster config (nodes, ports, networking, CAs or registries) changed since cluster '%s' was created\n", color.Warning(), clusterCfg.Name)
+	if !upRecreateCluster && !confirmRecreateCluster() {
+		fmt.Printf("  Installing into the cluster as it is; run 'kraze up --recreate-cluster' to recreate it\n")
+		return nil
+	}
+
+	fmt.Printf("Recreating cluster '%s'...\n", clusterCfg.Name)
+	if err := kindMgr.DeleteCluster(clusterCfg.Name); err != nil {
+		return fmt.Errorf("failed to delete cluster: %w", err)
+	}
+	return createKindCluster(ctx, kindMgr, clusterCfg)
+}
+
+// hasClusterDrifted returns true if the cluster's state records a checksum of
+// another cluster config
+func hasClusterDrifted(ctx context.Context, kindMgr *cluster.KindManager, clusterCfg *config.ClusterConfig) (bool, error) {
+	kubeconfig, err := kindMgr.GetKubeConfig(clusterCfg.Name, false)
+	if err != nil {
+		return false, fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig)
+	if err != nil {
+		return false, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	st, err := state.Load(ctx, clientset, clusterCfg.Name, "")
+	if err != nil || st == nil {
+		return false, err
+	}
+	return st.HasClusterDrifted(clusterCfg.Checksum()), nil
+}
+
+// confirmRecreateCluster asks whether to recreate a drifted cluster. Without a
+// terminal the answer is no.
+func confirmRecreateCluster() bool {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false
+	}
+
+	fmt.Printf("Recreate it now? Everything installed in it is deleted [y/N] ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -1,32 +1,172 @@
 package cli
 
 import (
+	"context"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/hjames9/kraze/internal/cluster"
 	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/state"
 	"github.com/spf13/cobra"
 )
 
+// completionTimeout bounds the cluster lookups of shell completion, so an
+// unreachable cluster never hangs the shell
+const completionTimeout = 3 * time.Second
+
 // getServiceNames returns a list of service names from the config file for shell completion
 func getServiceNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	cfgPaths, err := resolveConfigFiles(cmd)
+	cfg, err := completionConfig(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return serviceCompletions(cfg), cobra.ShellCompDirectiveNoFileComp
+}
+
+// getDownServiceNames also completes services still installed in the cluster
+// that were removed from the config, which 'kraze down' removes too
+func getDownServiceNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := completionConfig(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	services := serviceCompletions(cfg)
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	if st := loadStateForListing(ctx, cfg); st != nil {
+		services = append(services, removedServiceCompletions(cfg, st)...)
+		sort.Strings(services)
+	}
+	return services, cobra.ShellCompDirectiveNoFileComp
+}
+
+// getLabelCompletions completes --label with the key=value pairs of the
+// services in the config, after any pairs already given before a comma
+func getLabelCompletions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := completionConfig(cmd)
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 
-	// Parse config
-	cfg, err := config.ParseMultiple(cfgPaths)
+	prefix := toComplete[:strings.LastIndex(toComplete, ",")+1]
+	return labelCompletions(cfg, prefix), cobra.ShellCompDirectiveNoFileComp
+}
+
+// getSuffixCompletions completes --suffix with the variants that have state in
+// the cluster or a cluster of their own
+func getSuffixCompletions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := completionConfig(cmd)
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 
-	// Extract service names
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	descriptions := make(map[string]string)
+	if clusters, err := cluster.NewKindManager().ListClusters(); err == nil {
+		for _, name := range clusters {
+			if suffix, ok := strings.CutPrefix(name, cfg.Cluster.Name+"-"); ok {
+				descriptions[suffix] = "cluster " + name
+			}
+		}
+	}
+	if clientset, _ := clientsetForListing(ctx, cfg); clientset != nil {
+		if suffixes, err := state.ListVariants(ctx, clientset); err == nil {
+			for _, suffix := range suffixes {
+				descriptions[suffix] = "in cluster " + cfg.Cluster.Name
+			}
+		}
+	}
+	return describedCompletions(descriptions), cobra.ShellCompDirectiveNoFileComp
+}
+
+// getClusterNames completes kind cluster names
+func getClusterNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	clusters, err := cluster.NewKindManager().ListClusters()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	sort.Strings(clusters)
+	return clusters, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completionConfig parses the config files the command would use
+func completionConfig(cmd *cobra.Command) (*config.Config, error) {
+	cfgPaths, err := resolveConfigFiles(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return config.ParseMultiple(cfgPaths)
+}
+
+// serviceCompletions returns completion candidates for each service, using the
+// "name\tdescription" form so shells that support it show the service summary
+func serviceCompletions(cfg *config.Config) []string {
 	services := make([]string, 0, len(cfg.Services))
-	for name := range cfg.Services {
-		services = append(services, name)
+	for name, svc := range cfg.Services {
+		if summary := svc.GetSummary(); summary != "" {
+			services = append(services, name+"\t"+summary)
+		} else {
+			services = append(services, name)
+		}
 	}
 
-	return services, cobra.ShellCompDirectiveNoFileComp
+	sort.Strings(services)
+	return services
+}
+
+// removedServiceCompletions returns the services installed in the cluster that
+// are no longer in the config
+func removedServiceCompletions(cfg *config.Config, st *state.ClusterState) []string {
+	var services []string
+	for _, name := range st.GetInstalledServices() {
+		if _, ok := cfg.Services[name]; !ok {
+			services = append(services, name+"\tinstalled, no longer in the config")
+		}
+	}
+	return services
+}
+
+// labelCompletions returns each key=value label of the services, described with
+// the services carrying it
+func labelCompletions(cfg *config.Config, prefix string) []string {
+	services := make(map[string][]string)
+	for name, svc := range cfg.Services {
+		for key, value := range svc.Labels {
+			label := key + "=" + value
+			services[label] = append(services[label], name)
+		}
+	}
+
+	descriptions := make(map[string]string, len(services))
+	for label, names := range services {
+		sort.Strings(names)
+		descriptions[prefix+label] = strings.Join(names, ", ")
+	}
+	return describedCompletions(descriptions)
+}
+
+// describedCompletions returns sorted "value\tdescription" candidates
+func describedCompletions(descriptions map[string]string) []string {
+	completions := make([]string, 0, len(descriptions))
+	for value, description := range descriptions {
+		completions = append(completions, value+"\t"+description)
+	}
+	sort.Strings(completions)
+	return completions
+}
+
+// registerLabelCompletion completes the --label flag of a command
+func registerLabelCompletion(cmd *cobra.Command) {
+	cmd.RegisterFlagCompletionFunc("label", getLabelCompletions)
 }
 
 var completionCmd = &cobra.Command{
@@ -67,20 +207,14 @@ PowerShell:
   # To load completions for every new session, run:
   PS> kraze completion powershell > kraze.ps1
   # and source this file from your PowerShell profile.
+
+Or let kraze put the script in place and update your shell's startup file:
+  $ kraze completion install
 `,
 	DisableFlagsInUseLine: true,
 	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
 	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
-	Run: func(cmd *cobra.Command, args []string) {
-		switch args[0] {
-		case "bash":
-			cmd.Root().GenBashCompletion(os.Stdout)
-		case "zsh":
-			cmd.Root().GenZshCompletion(os.Stdout)
-		case "fish":
-			cmd.Root().GenFishCompletion(os.Stdout, true)
-		case "powershell":
-			cmd.Root().GenPowerShellCompletionWithDesc(os.Stdout)
-		}
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return writeCompletionScript(cmd.Root(), args[0], os.Stdout)
 	},
 }
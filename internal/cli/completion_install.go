@@ -0,0 +1,218 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/spf13/cobra"
+)
+
+// Markers around the lines kraze adds to a shell's startup file, so
+// reinstalling replaces them instead of adding them again
+const (
+	rcBlockStart = "# >>> kraze completion >>>"
+	rcBlockEnd   = "# <<< kraze completion <<<"
+)
+
+// completionTarget is where a shell's completion script goes and how the shell loads it
+type completionTarget struct {
+	script  string   // Path of the completion script
+	rcFile  string   // Startup file that loads the script, or "" if the shell finds it on its own
+	rcLines []string // Lines added to rcFile
+}
+
+var completionInstallCmd = &cobra.Command{
+	Use:   "install [bash|zsh|fish|powershell]",
+	Short: "Install shell completion for the current user",
+	Long: `Write the completion script for a shell to where it loads completions from, and add
+the lines that load it to the shell's startup file when the shell needs them. The shell is
+taken from $SHELL when not given (PowerShell on Windows).
+
+  bash        ~/.local/share/bash-completion/completions/kraze, sourced from ~/.bashrc
+  zsh         ~/.zsh/completions/_kraze, added to fpath in ~/.zshrc
+  fish        ~/.config/fish/completions/kraze.fish, loaded by fish on its own
+  powershell  kraze.ps1 next to the PowerShell profile, dot-sourced from the profile
+
+Running it again refreshes the script and leaves a single copy of the startup lines.
+No network access is needed, so it works for air-gapped installs.
+
+Examples:
+  kraze completion install        # Install for the shell in $SHELL
+  kraze completion install zsh    # Install for zsh`,
+	DisableFlagsInUseLine: true,
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	Args:                  cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+	RunE:                  runCompletionInstall,
+}
+
+func runCompletionInstall(cmd *cobra.Command, args []string) error {
+	shell := ""
+	if len(args) > 0 {
+		shell = args[0]
+	} else {
+		var err error
+		if shell, err = detectShell(os.Getenv("SHELL"), runtime.GOOS); err != nil {
+			return err
+		}
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to get home directory: %w", err)
+	}
+	target, err := completionTargetFor(shell, home, os.Getenv, runtime.GOOS)
+	if err != nil {
+		return err
+	}
+
+	var script bytes.Buffer
+	if err := writeCompletionScript(cmd.Root(), shell, &script); err != nil {
+		return err
+	}
+
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would write %s completion to %s\n", shell, target.script)
+		if target.rcFile != "" {
+			fmt.Printf("[DRY RUN] Would load it from %s\n", target.rcFile)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target.script), 0o755); err != nil {
+		return fmt.Errorf("failed to create completion directory: %w", err)
+	}
+	if err := os.WriteFile(target.script, script.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write completion script: %w", err)
+	}
+	fmt.Printf("%s Wrote %s completion to %s\n", color.Checkmark(), shell, target.script)
+
+	if target.rcFile != "" {
+		existing, err := os.ReadFile(target.rcFile)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read %s: %w", target.rcFile, err)
+		}
+		updated := upsertRCBlock(string(existing), target.rcLines)
+		if updated != string(existing) {
+			if err := os.MkdirAll(filepath.Dir(target.rcFile), 0o755); err != nil {
+				return fmt.Errorf("failed to create %s: %w", filepath.Dir(target.rcFile), err)
+			}
+			if err := os.WriteFile(target.rcFile, []byte(updated), 0o644); err != nil {
+				return fmt.Errorf("failed to update %s: %w", target.rcFile, err)
+			}
+			fmt.Printf("%s Loaded from %s\n", color.Checkmark(), target.rcFile)
+		}
+	}
+
+	fmt.Println("Start a new shell for completion to take effect.")
+	return nil
+}
+
+// writeCompletionScript generates a shell's completion script
+func writeCompletionScript(root *cobra.Command, shell string, out io.Writer) error {
+	switch shell {
+	case "bash":
+		return root.GenBashCompletion(out)
+	case "zsh":
+		return root.GenZshCompletion(out)
+	case "fish":
+		return root.GenFishCompletion(out, true)
+	case "powershell":
+		return root.GenPowerShellCompletionWithDesc(out)
+	}
+	return fmt.Errorf("unsupported shell '%s' (use bash, zsh, fish or powershell)", shell)
+}
+
+// detectShell returns the shell to install completion for from $SHELL
+func detectShell(shellPath, goos string) (string, error) {
+	if shellPath == "" {
+		if goos == "windows" {
+			return "powershell", nil
+		}
+		return "", fmt.Errorf("$SHELL is not set, name the shell: kraze completion install [bash|zsh|fish|powershell]")
+	}
+
+	switch name := strings.TrimSuffix(filepath.Base(shellPath), ".exe"); name {
+	case "bash", "zsh", "fish":
+		return name, nil
+	case "pwsh", "powershell":
+		return "powershell", nil
+	default:
+		return "", fmt.Errorf("unsupported shell '%s', name the shell: kraze completion install [bash|zsh|fish|powershell]", name)
+	}
+}
+
+// completionTargetFor returns where a shell's completion is installed for the user
+func completionTargetFor(shell, home string, getenv func(string) string, goos string) (completionTarget, error) {
+	dataHome := getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	configHome := getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(home, ".config")
+	}
+
+	switch shell {
+	case "bash":
+		script := filepath.Join(dataHome, "bash-completion", "completions", "kraze")
+		return completionTarget{
+			script:  script,
+			rcFile:  filepath.Join(home, ".bashrc"),
+			rcLines: []string{fmt.Sprintf("[ -f %q ] && . %q", script, script)},
+		}, nil
+	case "zsh":
+		zdotdir := getenv("ZDOTDIR")
+		if zdotdir == "" {
+			zdotdir = home
+		}
+		dir := filepath.Join(home, ".zsh", "completions")
+		return completionTarget{
+			script:  filepath.Join(dir, "_kraze"),
+			rcFile:  filepath.Join(zdotdir, ".zshrc"),
+			rcLines: []string{fmt.Sprintf("fpath=(%q $fpath)", dir), "autoload -Uz compinit && compinit"},
+		}, nil
+	case "fish":
+		return completionTarget{script: filepath.Join(configHome, "fish", "completions", "kraze.fish")}, nil
+	case "powershell":
+		profileDir := filepath.Join(configHome, "powershell")
+		if goos == "windows" {
+			profileDir = filepath.Join(home, "Documents", "PowerShell")
+		}
+		script := filepath.Join(profileDir, "kraze.ps1")
+		return completionTarget{
+			script:  script,
+			rcFile:  filepath.Join(profileDir, "Microsoft.PowerShell_profile.ps1"),
+			rcLines: []string{fmt.Sprintf(". '%s'", script)},
+		}, nil
+	}
+	return completionTarget{}, fmt.Errorf("unsupported shell '%s' (use bash, zsh, fish or powershell)", shell)
+}
+
+// upsertRCBlock returns a startup file's content with kraze's block set to lines,
+// replacing the block from an earlier install or appending it
+func upsertRCBlock(content string, lines []string) string {
+	block := rcBlockStart + "\n" + strings.Join(lines, "\n") + "\n" + rcBlockEnd + "\n"
+
+	start := strings.Index(content, rcBlockStart)
+	if start >= 0 {
+		if end := strings.Index(content[start:], rcBlockEnd); end >= 0 {
+			rest := strings.TrimPrefix(content[start+end+len(rcBlockEnd):], "\n")
+			return content[:start] + block + rest
+		}
+	}
+
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + block
+}
+
+func init() {
+	completionCmd.AddCommand(completionInstallCmd)
+}
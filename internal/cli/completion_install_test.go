@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDetectShell(test *testing.T) {
+	tests := []struct {
+		name      string
+		shellPath string
+		goos      string
+		expected  string
+		wantErr   bool
+	}{
+		{name: "bash", shellPath: "/bin/bash", goos: "linux", expected: "bash"},
+		{name: "zsh from homebrew", shellPath: "/opt/homebrew/bin/zsh", goos: "darwin", expected: "zsh"},
+		{name: "fish", shellPath: "/usr/bin/fish", goos: "linux", expected: "fish"},
+		{name: "pwsh", shellPath: "/usr/local/bin/pwsh", goos: "linux", expected: "powershell"},
+		{name: "windows without SHELL", goos: "windows", expected: "powershell"},
+		{name: "unix without SHELL", goos: "linux", wantErr: true},
+		{name: "unsupported shell", shellPath: "/bin/tcsh", goos: "linux", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			got, err := detectShell(tt.shellPath, tt.goos)
+			if (err != nil) != tt.wantErr {
+				test.Fatalf("detectShell() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				test.Errorf("detectShell() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompletionTargetFor(test *testing.T) {
+	home := filepath.Join("/home", "dev")
+	env := map[string]string{}
+	getenv := func(key string) string { return env[key] }
+
+	tests := []struct {
+		name     string
+		shell    string
+		goos     string
+		env      map[string]string
+		expected completionTarget
+	}{
+		{
+			name:  "bash",
+			shell: "bash",
+			goos:  "linux",
+			expected: completionTarget{
+				script:  filepath.Join(home, ".local", "share", "bash-completion", "completions", "kraze"),
+				rcFile:  filepath.Join(home, ".bashrc"),
+				rcLines: []string{`[ -f "` + filepath.Join(home, ".local", "share", "bash-completion", "completions", "kraze") + `" ] && . "` + filepath.Join(home, ".local", "share", "bash-completion", "completions", "kraze") + `"`},
+			},
+		},
+		{
+			name:  "zsh with ZDOTDIR",
+			shell: "zsh",
+			goos:  "darwin",
+			env:   map[string]string{"ZDOTDIR": filepath.Join(home, ".config", "zsh")},
+			expected: completionTarget{
+				script:  filepath.Join(home, ".zsh", "completions", "_kraze"),
+				rcFile:  filepath.Join(home, ".config", "zsh", ".zshrc"),
+				rcLines: []string{`fpath=("` + filepath.Join(home, ".zsh", "completions") + `" $fpath)`, "autoload -Uz compinit && compinit"},
+			},
+		},
+		{
+			name:     "fish with XDG_CONFIG_HOME",
+			shell:    "fish",
+			goos:     "linux",
+			env:      map[string]string{"XDG_CONFIG_HOME": filepath.Join(home, "cfg")},
+			expected: completionTarget{script: filepath.Join(home, "cfg", "fish", "completions", "kraze.fish")},
+		},
+		{
+			name:  "powershell on windows",
+			shell: "powershell",
+			goos:  "windows",
+			expected: completionTarget{
+				script:  filepath.Join(home, "Documents", "PowerShell", "kraze.ps1"),
+				rcFile:  filepath.Join(home, "Documents", "PowerShell", "Microsoft.PowerShell_profile.ps1"),
+				rcLines: []string{". '" + filepath.Join(home, "Documents", "PowerShell", "kraze.ps1") + "'"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			env = tt.env
+			got, err := completionTargetFor(tt.shell, home, getenv, tt.goos)
+			if err != nil {
+				test.Fatalf("completionTargetFor() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				test.Errorf("completionTargetFor() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestUpsertRCBlock(test *testing.T) {
+	lines := []string{"source ~/.kraze.bash"}
+	block := rcBlockStart + "\nsource ~/.kraze.bash\n" + rcBlockEnd + "\n"
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "empty file", content: "", expected: block},
+		{name: "appends after a missing newline", content: "alias k=kubectl", expected: "alias k=kubectl\n" + block},
+		{
+			name:     "replaces an earlier block in place",
+			content:  "export A=1\n" + rcBlockStart + "\nsource /old/path\n" + rcBlockEnd + "\nexport B=2\n",
+			expected: "export A=1\n" + block + "export B=2\n",
+		},
+		{name: "already installed", content: "export A=1\n" + block, expected: "export A=1\n" + block},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := upsertRCBlock(tt.content, lines); got != tt.expected {
+				test.Errorf("upsertRCBlock() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
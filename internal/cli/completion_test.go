@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/state"
+)
+
+func TestLabelCompletions(test *testing.T) {
+	cfg := &config.Config{Services: map[string]config.ServiceConfig{
+		"api":      {Name: "api", Labels: map[string]string{"tier": "backend", "team": "core"}},
+		"worker":   {Name: "worker", Labels: map[string]string{"tier": "backend"}},
+		"postgres": {Name: "postgres", Labels: map[string]string{"tier": "data"}},
+		"redis":    {Name: "redis"},
+	}}
+
+	tests := []struct {
+		name     string
+		prefix   string
+		expected []string
+	}{
+		{
+			name:   "first label",
+			prefix: "",
+			expected: []string{
+				"team=core\tapi",
+				"tier=backend\tapi, worker",
+				"tier=data\tpostgres",
+			},
+		},
+		{
+			name:   "after a comma",
+			prefix: "team=core,",
+			expected: []string{
+				"team=core,team=core\tapi",
+				"team=core,tier=backend\tapi, worker",
+				"team=core,tier=data\tpostgres",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			result := labelCompletions(cfg, tt.prefix)
+			if !reflect.DeepEqual(result, tt.expected) {
+				test.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestRemovedServiceCompletions(test *testing.T) {
+	cfg := &config.Config{Services: map[string]config.ServiceConfig{
+		"api": {Name: "api"},
+	}}
+	st := state.New("dev", false, false, 0, false, 0)
+	st.MarkServiceInstalled("api")
+	st.MarkServiceInstalled("legacy")
+
+	result := removedServiceCompletions(cfg, st)
+	expected := []string{"legacy\tinstalled, no longer in the config"}
+	if !reflect.DeepEqual(result, expected) {
+		test.Errorf("Expected %q, got %q", expected, result)
+	}
+}
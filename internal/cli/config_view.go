@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var configViewResolved bool
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration kraze uses",
+}
+
+var configViewCmd = &cobra.Command{
+	Use:   "view",
+	Short: "Print the configuration after includes, overrides and variables",
+	Long: `Print the configuration as kraze sees it: the config files merged, included
+and extended services added, ${NAME} variables expanded, relative paths
+resolved, and presets, add-ons and global settings such as resources_override
+applied.
+
+With --resolved, the settings kraze defaults are filled in too, and each value
+is followed by a comment saying where it came from: a file and line (with the
+variables it was expanded from), or the preset, add-on or default that set it.
+Use it to answer "why is kraze using that value".
+
+Values under keys that look like credentials (password, token, secret, ...)
+and the values of variables with such names are masked.
+
+Examples:
+  kraze config view
+  kraze config view --resolved
+  kraze config view --resolved -f kraze.yml -f kraze.override.yml
+  kraze config view --resolved --suffix pr-42   # As a variant sees it`,
+	Args: cobra.NoArgs,
+	RunE: runConfigView,
+}
+
+func runConfigView(cmd *cobra.Command, args []string) error {
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanupPack()
+
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if configViewResolved {
+		cfg.ApplyDefaults()
+	}
+
+	data, err := cfg.View(configViewResolved)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "# Config files: %s\n", strings.Join(cfgPaths, ", "))
+	_, err = os.Stdout.Write(data)
+	return err
+}
+
+func init() {
+	configViewCmd.Flags().BoolVar(&configViewResolved, "resolved", false, "Fill in defaults and annotate each value with where it came from")
+	configCmd.AddCommand(configViewCmd)
+}
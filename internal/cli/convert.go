@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/compose"
+	"github.com/spf13/cobra"
+)
+
+var (
+	convertOutputDir string
+	convertNamespace string
+	convertForce     bool
+)
+
+var convertCmd = &cobra.Command{
+	Use:   "convert",
+	Short: "Convert other environment definitions to kraze",
+}
+
+var convertComposeCmd = &cobra.Command{
+	Use:   "compose [compose-file]",
+	Short: "Convert a docker-compose file to manifests and a kraze.yml",
+	Long: `Convert the services of a docker-compose file (default: docker-compose.yml or
+compose.yaml) into Kubernetes manifests and a kraze.yml with a manifests service
+for each, so a compose project can move to kraze one service at a time.
+
+Each compose service becomes a Deployment, with a Service named after it for its
+ports and expose entries (so other services keep reaching it by the same host
+name), a ConfigMap for its env_file and PersistentVolumeClaims for its named
+volumes. Published ports become kraze port-forwards, depends_on becomes
+depends_on, healthchecks become readiness probes and build sections become kraze
+builds. Anything that can't be converted faithfully is reported as a warning.
+
+The kraze.yml is written to -f (default: kraze.yml) and the manifests to
+--output-dir. Existing files are only overwritten with --force.
+
+Examples:
+  kraze convert compose                         # docker-compose.yml to kraze.yml and k8s/
+  kraze convert compose compose.prod.yaml -f prod.yml --output-dir k8s/prod`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runConvertCompose,
+}
+
+func init() {
+	convertComposeCmd.Flags().StringVar(&convertOutputDir, "output-dir", "k8s", "Directory to write the manifests to")
+	convertComposeCmd.Flags().StringVar(&convertNamespace, "namespace", "", "Namespace of the services (default: the compose project name)")
+	convertComposeCmd.Flags().BoolVar(&convertForce, "force", false, "Overwrite existing files")
+	convertCmd.AddCommand(convertComposeCmd)
+}
+
+func runConvertCompose(cmd *cobra.Command, args []string) error {
+	composePath, err := findComposeFile(args)
+	if err != nil {
+		return err
+	}
+	configPath := "kraze.yml"
+	if len(configFiles) > 0 {
+		configPath = configFiles[0]
+	}
+
+	project, err := compose.Load(composePath)
+	if err != nil {
+		return err
+	}
+	configDir, err := filepath.Abs(filepath.Dir(configPath))
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", configPath, err)
+	}
+	manifestDir := convertOutputDir
+	if !filepath.IsAbs(manifestDir) {
+		// Manifest paths in kraze.yml are relative to its directory
+		absDir, err := filepath.Abs(manifestDir)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", manifestDir, err)
+		}
+		if manifestDir, err = filepath.Rel(configDir, absDir); err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", convertOutputDir, err)
+		}
+	}
+
+	result, err := compose.Convert(project, compose.Options{
+		Namespace:   convertNamespace,
+		ManifestDir: manifestDir,
+		ConfigDir:   configDir,
+		Source:      filepath.Base(composePath),
+	})
+	if err != nil {
+		return err
+	}
+
+	files := map[string][]byte{configPath: result.Config}
+	for name, manifest := range result.Manifests {
+		files[filepath.Join(convertOutputDir, name)] = manifest
+	}
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would convert %d service(s) from %s, writing:\n", len(project.Services), composePath)
+		for _, path := range paths {
+			fmt.Printf("  - %s\n", path)
+		}
+	} else {
+		if !convertForce {
+			for _, path := range paths {
+				if _, err := os.Stat(path); err == nil {
+					return fmt.Errorf("%s already exists, use --force to overwrite it", path)
+				}
+			}
+		}
+		if err := os.MkdirAll(convertOutputDir, 0o755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", convertOutputDir, err)
+		}
+		for _, path := range paths {
+			if err := os.WriteFile(path, files[path], 0o644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", path, err)
+			}
+			Verbose("Wrote %s", path)
+		}
+		fmt.Printf("%s Converted %d service(s) from %s to %s and %s\n", color.Checkmark(), len(project.Services), composePath, configPath, convertOutputDir)
+	}
+
+	for _, warning := range result.Warnings {
+		fmt.Printf("%s %s\n", color.Warning(), warning)
+	}
+	return nil
+}
+
+// findComposeFile returns the compose file to convert, looking for the names
+// docker compose uses when none is given
+func findComposeFile(args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	for _, name := range []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"} {
+		if _, err := os.Stat(name); err == nil {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("no compose file found (looked for compose.yaml, compose.yml, docker-compose.yaml and docker-compose.yml)")
+}
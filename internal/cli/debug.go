@@ -0,0 +1,271 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+var (
+	debugImage  string
+	debugPod    string
+	debugTarget string
+	debugCopy   bool
+	debugTTL    time.Duration
+	debugKeep   bool
+)
+
+var debugCmd = &cobra.Command{
+	Use:   "debug SERVICE [-- COMMAND...]",
+	Short: "Attach a debug container to a pod of a service",
+	Long: `Attach an interactive debug container running a toolbox image to a pod of a
+service, like 'kubectl debug'. Useful for distroless images that have no shell.
+
+By default an ephemeral container is injected into the first running pod and
+shares the process namespace of the target container (--target, default the
+first container), so its processes and filesystem (/proc/<pid>/root) are
+visible from the toolbox shell. Ephemeral containers cannot be removed from a
+pod; the debug process is killed once --ttl elapses and the container stays
+terminated until the pod is replaced.
+
+With --copy, a copy of the pod is created instead, with the toolbox container
+added, probes removed and the process namespace shared. A command after --
+replaces the target container's command in the copy (e.g. to keep a crashing
+container alive). The copy is deleted on exit unless --keep is set, and is
+always terminated by Kubernetes once --ttl elapses.
+
+Examples:
+  kraze debug api                              # Toolbox shell in a running api pod
+  kraze debug api --image nicolaka/netshoot    # Use a networking toolbox
+  kraze debug api --target sidecar             # Share the sidecar's process namespace
+  kraze debug api -- ps aux                    # Run a command instead of a shell
+  kraze debug api --copy -- sleep infinity     # Debug a copy with the app kept idle
+  kraze debug api --copy --keep --ttl 4h       # Keep the copy around for 4 hours`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if dash := cmd.ArgsLenAtDash(); dash >= 0 && dash != 1 {
+			return fmt.Errorf("expected exactly one service before --")
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
+	ValidArgsFunction: getServiceNames,
+	RunE:              runDebug,
+}
+
+func runDebug(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if debugImage == "" {
+		return fmt.Errorf("--image cannot be empty")
+	}
+	if debugTTL < 0 {
+		return fmt.Errorf("--ttl cannot be negative")
+	}
+	if debugKeep && !debugCopy {
+		return fmt.Errorf("--keep only applies with --copy")
+	}
+
+	var command []string
+	if cmd.ArgsLenAtDash() >= 0 {
+		command = args[cmd.ArgsLenAtDash():]
+	} else if len(args) > 1 {
+		return fmt.Errorf("use -- to separate the command from the service, e.g. 'kraze debug %s -- %s'", args[0], args[1])
+	}
+
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanupPack()
+
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := requireCluster(cfg, "debug"); err != nil {
+		return err
+	}
+
+	svc, exists := cfg.Services[args[0]]
+	if !exists {
+		return fmt.Errorf("service '%s' not found in configuration", args[0])
+	}
+
+	kubeconfig, err := getShellKubeconfig(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		mode := "an ephemeral debug container"
+		if debugCopy {
+			mode = "a debug copy of a pod"
+		}
+		fmt.Fprintf(os.Stderr, "[DRY RUN] Would start %s (%s) for service '%s'\n", mode, debugImage, svc.Name)
+		return nil
+	}
+
+	restConfig, err := providers.GetRESTConfigFromKubeconfigContent(kubeconfig)
+	if err != nil {
+		return err
+	}
+	clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+
+	debugCtx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	if debugTTL > 0 {
+		var cancelTTL context.CancelFunc
+		debugCtx, cancelTTL = context.WithTimeout(debugCtx, debugTTL)
+		defer cancelTTL()
+	}
+
+	// An open debug session counts as activity for the idle auto-stop
+	if !cfg.Cluster.IsExternal() {
+		go keepClusterActive(debugCtx, cfg.Cluster.Name)
+	}
+
+	opts := providers.DebugOptions{
+		Image:   debugImage,
+		Pod:     debugPod,
+		Target:  debugTarget,
+		Copy:    debugCopy,
+		Command: command,
+		TTL:     debugTTL,
+	}
+	if debugCopy {
+		fmt.Fprintf(os.Stderr, "Creating debug copy of a %s pod with %s...\n", color.Cyan(svc.Name), debugImage)
+	} else {
+		fmt.Fprintf(os.Stderr, "Adding ephemeral debug container (%s) to a %s pod...\n", debugImage, color.Cyan(svc.Name))
+	}
+
+	target, err := providers.StartDebugContainer(debugCtx, clientset, &svc, opts)
+	if target != nil {
+		defer cleanupDebugTarget(clientset, target)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "%s Attached to %s in pod %s (expires in %s)\n", color.Checkmark(), target.Container, target.Pod, formatDebugTTL(debugTTL))
+	if !debugCopy {
+		fmt.Fprintf(os.Stderr, "   Target container filesystem: /proc/1/root (or /proc/<pid>/root)\n")
+	}
+
+	streams := providers.DebugStreams{Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr}
+	stdinFd := int(os.Stdin.Fd())
+	if term.IsTerminal(stdinFd) {
+		state, err := term.MakeRaw(stdinFd)
+		if err != nil {
+			return fmt.Errorf("failed to set terminal to raw mode: %w", err)
+		}
+		defer term.Restore(stdinFd, state)
+
+		sizes := newTerminalSizeQueue(debugCtx, int(os.Stdout.Fd()))
+		streams.TTY = true
+		streams.SizeQueue = sizes
+	}
+
+	err = providers.AttachDebugContainer(debugCtx, restConfig, clientset, target, streams)
+	if debugCtx.Err() == context.DeadlineExceeded {
+		fmt.Fprintf(os.Stderr, "\r\n%s Debug session expired after %s\r\n", color.Warning(), debugTTL)
+		return nil
+	}
+	if debugCtx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// cleanupDebugTarget deletes a debug pod copy unless --keep is set
+func cleanupDebugTarget(clientset kubernetes.Interface, target *providers.DebugTarget) {
+	if !target.Copy {
+		return
+	}
+	if debugKeep {
+		fmt.Fprintf(os.Stderr, "Keeping debug pod %s (delete with 'kubectl delete pod -n %s %s')\n", target.Pod, target.Namespace, target.Pod)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := providers.DeleteDebugCopy(ctx, clientset, target); err != nil {
+		fmt.Fprintf(os.Stderr, "%s %v\n", color.Warning(), err)
+		return
+	}
+	Verbose("Deleted debug pod %s", target.Pod)
+}
+
+// formatDebugTTL describes a debug session lifetime for messages
+func formatDebugTTL(ttl time.Duration) string {
+	if ttl <= 0 {
+		return "never"
+	}
+	return ttl.String()
+}
+
+// terminalSizeQueue reports terminal size changes to the attached container. The
+// size is polled rather than read from SIGWINCH so this also works on Windows.
+type terminalSizeQueue struct {
+	sizes chan remotecommand.TerminalSize
+}
+
+func newTerminalSizeQueue(ctx context.Context, fd int) *terminalSizeQueue {
+	queue := &terminalSizeQueue{sizes: make(chan remotecommand.TerminalSize, 1)}
+	go func() {
+		defer close(queue.sizes)
+		ticker := time.NewTicker(250 * time.Millisecond)
+		defer ticker.Stop()
+
+		var last remotecommand.TerminalSize
+		for {
+			if width, height, err := term.GetSize(fd); err == nil {
+				size := remotecommand.TerminalSize{Width: uint16(width), Height: uint16(height)}
+				if size != last {
+					last = size
+					select {
+					case queue.sizes <- size:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return queue
+}
+
+// Next returns the next terminal size, or nil once the session ends
+func (queue *terminalSizeQueue) Next() *remotecommand.TerminalSize {
+	size, ok := <-queue.sizes
+	if !ok {
+		return nil
+	}
+	return &size
+}
+
+func init() {
+	debugCmd.Flags().StringVar(&debugImage, "image", "busybox:1.36", "Toolbox image for the debug container (must provide sh when --ttl is set)")
+	debugCmd.Flags().StringVar(&debugPod, "pod", "", "Pod to debug (default: first running pod of the service)")
+	debugCmd.Flags().StringVar(&debugTarget, "target", "", "Container to debug (default: first container of the pod)")
+	debugCmd.Flags().BoolVar(&debugCopy, "copy", false, "Debug a copy of the pod instead of injecting an ephemeral container")
+	debugCmd.Flags().DurationVar(&debugTTL, "ttl", time.Hour, "How long the debug session may live (0 = unlimited)")
+	debugCmd.Flags().BoolVar(&debugKeep, "keep", false, "Keep the pod copy after exiting (with --copy)")
+}
@@ -0,0 +1,446 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/hjames9/kraze/internal/state"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var describeOutput string
+
+// describeReport is everything kraze knows about a service, printed by
+// 'kraze describe' (and as-is with -o json|yaml)
+type describeReport struct {
+	Name         string                        `json:"name"`
+	Type         string                        `json:"type"`
+	Namespace    string                        `json:"namespace"`
+	Enabled      bool                          `json:"enabled"`
+	Description  string                        `json:"description,omitempty"`
+	Owner        string                        `json:"owner,omitempty"`
+	Links        []string                      `json:"links,omitempty"`
+	Source       describeSource                `json:"source"`
+	Labels       map[string]string             `json:"labels,omitempty"`
+	Dependencies []describeDependency          `json:"dependencies,omitempty"`
+	Values       map[string]interface{}        `json:"values,omitempty"` // Values passed to the chart (helm services)
+	Cluster      string                        `json:"cluster"`
+	Unavailable  string                        `json:"cluster_unavailable,omitempty"` // Why the cluster couldn't be read
+	Installed    bool                          `json:"installed"`
+	Ready        bool                          `json:"ready"`
+	Message      string                        `json:"message,omitempty"`
+	Release      *providers.ReleaseInfo        `json:"release,omitempty"`
+	InstalledAt  *time.Time                    `json:"installed_at,omitempty"` // When kraze last installed the service
+	Reason       string                        `json:"install_reason,omitempty"`
+	Images       []imageInventory              `json:"images,omitempty"`
+	Running      []describeRunningImage        `json:"running_images,omitempty"`
+	Resources    []providers.ResourceReadiness `json:"resources,omitempty"`
+	Events       []describeEvent               `json:"warning_events,omitempty"`
+}
+
+// describeSource is where a service's chart or manifests come from
+type describeSource struct {
+	Chart   string   `json:"chart,omitempty"`
+	Repo    string   `json:"repo,omitempty"`
+	Version string   `json:"version,omitempty"`
+	Path    string   `json:"path,omitempty"`
+	Paths   []string `json:"paths,omitempty"`
+}
+
+// describeDependency is a service the described service depends on
+type describeDependency struct {
+	Name      string `json:"name"`
+	Condition string `json:"condition"`
+	Probe     string `json:"probe,omitempty"`
+}
+
+// describeRunningImage is an image the service's pods run, with the digest
+// or image ID the node resolved
+type describeRunningImage struct {
+	Image   string `json:"image"`
+	Digest  string `json:"digest,omitempty"`
+	ImageID string `json:"image_id,omitempty"`
+}
+
+// describeEvent is a recent warning event about the service
+type describeEvent struct {
+	Reason   string    `json:"reason"`
+	Object   string    `json:"object"`
+	Message  string    `json:"message"`
+	Count    int32     `json:"count,omitempty"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+var describeCmd = &cobra.Command{
+	Use:   "describe <service>",
+	Short: "Show everything kraze knows about a service",
+	Long: `Show a service's source (chart, repository and version, or manifest paths),
+namespace, labels, dependencies and the values passed to its chart, and, from
+the cluster: its installed Helm revision, the images it uses (local and in the
+cluster, with digests), the resources it owns with their readiness, and recent
+warning events.
+
+Without a running cluster only the configuration is shown.
+
+Examples:
+  kraze describe postgres
+  kraze describe api -o json   # Machine-readable, for scripts`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: getServiceNames,
+	RunE:              runDescribe,
+}
+
+func runDescribe(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	format, err := parseOutputFormat(describeOutput)
+	if err != nil {
+		return err
+	}
+
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanupPack()
+
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	svc, ok := cfg.Services[args[0]]
+	if !ok {
+		return fmt.Errorf("service '%s' not found in configuration", args[0])
+	}
+
+	report := describeConfig(cfg, &svc)
+	if svc.IsHelm() {
+		values, err := providers.LoadServiceValues(&svc)
+		if err != nil {
+			return fmt.Errorf("failed to load values: %w", err)
+		}
+		// Credentials and SOPS-decrypted values are masked, as in 'kraze config view'
+		report.Values = config.MaskValues(values, svc.EncryptedValues()...)
+	}
+
+	if cfg.Cluster.IsNone() {
+		report.Unavailable = "cluster.none is set"
+	} else if kubeconfig, err := getShellKubeconfig(ctx, cfg); err != nil {
+		report.Unavailable = err.Error()
+	} else {
+		recordClusterActivity(cfg.Cluster.Name)
+		if err := describeCluster(ctx, cfg, &svc, kubeconfig, &report); err != nil {
+			return err
+		}
+	}
+
+	if format.isStructured() {
+		return printStructured(format, report)
+	}
+	printDescribeReport(&report)
+	return nil
+}
+
+// describeConfig fills in what the config says about a service
+func describeConfig(cfg *config.Config, svc *config.ServiceConfig) describeReport {
+	report := describeReport{
+		Name:        svc.Name,
+		Type:        svc.Type,
+		Namespace:   svc.GetNamespace(),
+		Enabled:     svc.IsEnabled(),
+		Description: strings.TrimSpace(svc.Description),
+		Owner:       svc.Owner,
+		Links:       svc.Links,
+		Labels:      svc.Labels,
+		Cluster:     cfg.Cluster.Name,
+		Source: describeSource{
+			Chart:   svc.Chart,
+			Repo:    svc.Repo,
+			Version: svc.Version,
+			Path:    svc.Path,
+			Paths:   svc.Paths,
+		},
+	}
+	for _, name := range svc.DependsOn.Names() {
+		condition := svc.DependsOn.Condition(name)
+		dependency := describeDependency{Name: name, Condition: config.ConditionServiceStarted, Probe: condition.ProbeDescription()}
+		if condition.IsHealthCheck() {
+			dependency.Condition = config.ConditionServiceHealthy
+		}
+		report.Dependencies = append(report.Dependencies, dependency)
+	}
+	return report
+}
+
+// describeCluster fills in what the cluster and kraze's state say about a service
+func describeCluster(ctx context.Context, cfg *config.Config, svc *config.ServiceConfig, kubeconfig string, report *describeReport) error {
+	clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	st, err := state.Load(ctx, clientset, cfg.Cluster.Name, cfg.StateBackend)
+	if err != nil {
+		Verbose("Warning: failed to load cluster state: %v", err)
+	}
+
+	status := serviceStatus(ctx, cfg, svc, kubeconfig, clientset, st)
+	if status.Error != "" {
+		return fmt.Errorf("%s", status.Error)
+	}
+	report.Installed, report.Ready, report.Message, report.Reason = status.Installed, status.Ready, status.Message, status.Reason
+
+	var kindMgr *cluster.KindManager
+	if !cfg.Cluster.IsExternal() {
+		kindMgr = cluster.NewKindManager()
+	}
+	if report.Images, err = imageInventoryFor(ctx, cfg, kindMgr, []string{svc.Name}); err != nil {
+		Verbose("Warning: failed to list the images of '%s': %v", svc.Name, err)
+	}
+	if !report.Installed {
+		return nil
+	}
+
+	opts := &providers.ProviderOptions{
+		ClusterName:    cfg.Cluster.Name,
+		KubeConfig:     kubeconfig,
+		Verbose:        verbose,
+		Quiet:          true,
+		ReadinessRules: cfg.Readiness,
+	}
+	var refs []providers.ResourceRef
+	if svc.IsHelm() {
+		if report.Release, err = providers.DescribeRelease(svc, opts); err != nil {
+			return err
+		}
+		refs = report.Release.Resources
+	} else if st != nil {
+		refs = resourceRefs(st.GetAppliedResources(svc.Name))
+	}
+	if st != nil && st.IsServiceInstalled(svc.Name) {
+		installedAt := st.Services[svc.Name].UpdatedAt
+		report.InstalledAt = &installedAt
+	}
+	if report.Resources, err = providers.ResourcesReadiness(ctx, refs, opts); err != nil {
+		return err
+	}
+
+	running, err := providers.ServiceRunningImages(ctx, clientset, svc)
+	if err != nil {
+		Verbose("Warning: failed to read the running images of '%s': %v", svc.Name, err)
+	}
+	for _, image := range running {
+		report.Running = append(report.Running, describeRunningImage{Image: image.Image, Digest: image.Digest, ImageID: image.ImageID})
+	}
+
+	events, err := serviceWarningEvents(ctx, clientset, svc)
+	if err != nil {
+		Verbose("Warning: failed to list events of '%s': %v", svc.Name, err)
+	}
+	report.Events = events
+	return nil
+}
+
+// serviceWarningEvents returns the most recent warning events about a service
+func serviceWarningEvents(ctx context.Context, clientset kubernetes.Interface, svc *config.ServiceConfig) ([]describeEvent, error) {
+	pods, err := providers.ListServicePods(ctx, clientset, svc)
+	if err != nil {
+		return nil, err
+	}
+	list, err := clientset.CoreV1().Events(svc.GetNamespace()).List(ctx, metav1.ListOptions{FieldSelector: "type=" + corev1.EventTypeWarning})
+	if err != nil {
+		return nil, err
+	}
+
+	var events []describeEvent
+	for _, event := range serviceEvents(svc, pods, list.Items) {
+		events = append(events, describeEvent{
+			Reason:   event.Reason,
+			Object:   strings.ToLower(event.InvolvedObject.Kind) + "/" + event.InvolvedObject.Name,
+			Message:  strings.TrimSpace(event.Message),
+			Count:    event.Count,
+			LastSeen: eventTime(event),
+		})
+	}
+	return events, nil
+}
+
+// printDescribeReport prints a service's description as sections
+func printDescribeReport(report *describeReport) {
+	fmt.Printf("Service:    %s\n", report.Name)
+	fmt.Printf("Type:       %s\n", report.Type)
+	fmt.Printf("Namespace:  %s\n", report.Namespace)
+	if !report.Enabled {
+		fmt.Printf("Enabled:    false\n")
+	}
+	if report.Owner != "" {
+		fmt.Printf("Owner:      %s\n", report.Owner)
+	}
+	for _, link := range report.Links {
+		fmt.Printf("Link:       %s\n", link)
+	}
+	source := report.Source
+	switch {
+	case source.Chart != "" && source.Repo != "":
+		fmt.Printf("Chart:      %s from %s", source.Chart, source.Repo)
+		if source.Version != "" {
+			fmt.Printf(" (version %s)", source.Version)
+		}
+		fmt.Println()
+	case source.Path != "":
+		fmt.Printf("Path:       %s\n", source.Path)
+	}
+	for _, path := range source.Paths {
+		fmt.Printf("Path:       %s\n", path)
+	}
+	if len(report.Labels) > 0 {
+		pairs := make([]string, 0, len(report.Labels))
+		for _, key := range sortedKeys(report.Labels) {
+			pairs = append(pairs, key+"="+report.Labels[key])
+		}
+		fmt.Printf("Labels:     %s\n", strings.Join(pairs, ", "))
+	}
+
+	if report.Description != "" {
+		fmt.Println("\nDescription:")
+		for _, line := range strings.Split(report.Description, "\n") {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+
+	if len(report.Dependencies) > 0 {
+		fmt.Println("\nDepends on:")
+		for _, dependency := range report.Dependencies {
+			line := fmt.Sprintf("  %s (%s", dependency.Name, dependency.Condition)
+			if dependency.Probe != "" {
+				line += ": " + dependency.Probe
+			}
+			fmt.Println(line + ")")
+		}
+	}
+
+	if len(report.Values) > 0 {
+		fmt.Println("\nValues:")
+		if data, err := yaml.Marshal(report.Values); err == nil {
+			for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+				fmt.Printf("  %s\n", line)
+			}
+		}
+	}
+
+	fmt.Printf("\nCluster:    %s\n", report.Cluster)
+	if report.Unavailable != "" {
+		fmt.Printf("  Not available: %s\n", report.Unavailable)
+		return
+	}
+	switch {
+	case !report.Installed:
+		fmt.Println("Status:     not installed")
+	case report.Ready:
+		fmt.Println("Status:     ready")
+	default:
+		fmt.Printf("Status:     not ready")
+		if report.Message != "" {
+			fmt.Printf(" (%s)", report.Message)
+		}
+		fmt.Println()
+	}
+	if release := report.Release; release != nil {
+		fmt.Printf("Revision:   %d (%s, deployed %s)\n", release.Revision, release.Status, release.DeployedAt.Local().Format(time.DateTime))
+		if release.Chart != nil {
+			fmt.Printf("Installed:  %s %s", release.Chart.Name, release.Chart.Version)
+			if release.Chart.AppVersion != "" {
+				fmt.Printf(" (app %s)", release.Chart.AppVersion)
+			}
+			fmt.Println()
+		}
+	} else if report.InstalledAt != nil {
+		fmt.Printf("Installed:  %s\n", report.InstalledAt.Local().Format(time.DateTime))
+	}
+	if report.Reason != "" {
+		fmt.Printf("Reason:     %s\n", report.Reason)
+	}
+
+	if len(report.Images) > 0 {
+		fmt.Println("\nImages:")
+		fmt.Printf("  %-50s %-22s %s\n", "IMAGE", "LOCAL", "CLUSTER")
+		for _, image := range report.Images {
+			local := "-"
+			switch {
+			case image.Digest != "":
+				local = shortDigest(image.Digest)
+			case image.ID != "":
+				local = shortImageID(image.ID)
+			}
+			clusterState := image.Cluster
+			if clusterState == "" {
+				clusterState = "-"
+			}
+			fmt.Printf("  %-50s %-22s %s\n", image.Image, local, clusterState)
+		}
+	}
+	if len(report.Running) > 0 {
+		fmt.Println("\nRunning images:")
+		for _, image := range report.Running {
+			id := image.Digest
+			if id == "" {
+				id = image.ImageID
+			}
+			fmt.Printf("  %s@%s\n", image.Image, id)
+		}
+	}
+
+	if len(report.Resources) > 0 {
+		fmt.Println("\nResources:")
+		resources := append([]providers.ResourceReadiness(nil), report.Resources...)
+		sort.SliceStable(resources, func(left, right int) bool {
+			if resources[left].Kind != resources[right].Kind {
+				return resources[left].Kind < resources[right].Kind
+			}
+			return resources[left].Name < resources[right].Name
+		})
+		for _, resource := range resources {
+			name := resource.Kind + "/" + resource.Name
+			if resource.Namespace != "" && resource.Namespace != report.Namespace {
+				name = resource.Namespace + "/" + name
+			}
+			line := fmt.Sprintf("  %-50s %s", name, resource.Status)
+			if resource.Message != "" {
+				line += ": " + resource.Message
+			}
+			fmt.Println(line)
+		}
+	}
+
+	if len(report.Events) > 0 {
+		fmt.Println("\nWarning events:")
+		for _, event := range report.Events {
+			count := ""
+			if event.Count > 1 {
+				count = fmt.Sprintf(" (x%d)", event.Count)
+			}
+			fmt.Printf("  %s  %s %s%s: %s\n", event.LastSeen.Local().Format(time.TimeOnly), event.Reason, event.Object, count, event.Message)
+		}
+	}
+}
+
+// shortDigest shortens a registry digest for tables
+func shortDigest(digest string) string {
+	if len(digest) > len("sha256:")+12 {
+		return digest[:len("sha256:")+12]
+	}
+	return digest
+}
+
+func init() {
+	addOutputFlag(describeCmd, &describeOutput)
+}
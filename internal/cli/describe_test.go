@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+)
+
+func TestDescribeConfigOwnership(test *testing.T) {
+	cfg := &config.Config{Cluster: config.ClusterConfig{Name: "dev"}}
+	svc := &config.ServiceConfig{
+		Name:        "api",
+		Type:        "manifests",
+		Path:        "./manifests",
+		Description: "Public API\n",
+		Owner:       "platform-team",
+		Links:       []string{"https://runbooks.example.com/api"},
+	}
+
+	report := describeConfig(cfg, svc)
+	if report.Description != "Public API" || report.Owner != "platform-team" || len(report.Links) != 1 {
+		test.Errorf("describeConfig() = description %q, owner %q, links %v", report.Description, report.Owner, report.Links)
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		test.Fatalf("json.Marshal() error = %v", err)
+	}
+	for _, field := range []string{`"description":"Public API"`, `"owner":"platform-team"`, `"links":["https://runbooks.example.com/api"]`} {
+		if !strings.Contains(string(data), field) {
+			test.Errorf("JSON report %s is missing %s", data, field)
+		}
+	}
+}
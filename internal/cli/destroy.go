@@ -4,15 +4,19 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/hjames9/kraze/internal/cluster"
 	"github.com/hjames9/kraze/internal/color"
 	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/events"
 	"github.com/hjames9/kraze/internal/providers"
 	"github.com/hjames9/kraze/internal/state"
 	"github.com/spf13/cobra"
 )
 
+var destroyVolumes bool
+
 var destroyCmd = &cobra.Command{
 	Use:   "destroy",
 	Short: "Delete the cluster and clean up state",
@@ -26,7 +30,13 @@ For external clusters (cluster.external.enabled: true):
   - Only delete the state file (preserves the external cluster)
 
 WARNING: For kind clusters, this will permanently delete the cluster and all data.
-Services do not need to be uninstalled first - the entire cluster is removed.`,
+Services do not need to be uninstalled first - the entire cluster is removed.
+
+The data of local-path volumes lives in the node containers and goes with them,
+unless the provisioner's directory is mounted from this machine with
+extraMounts. --volumes removes that data from the nodes before they're deleted,
+so it doesn't outlive the cluster on the host, and reports the space reclaimed.
+For external clusters use 'kraze down --volumes' instead.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
 
@@ -43,18 +53,36 @@ Services do not need to be uninstalled first - the entire cluster is removed.`,
 		if err != nil {
 			return fmt.Errorf("failed to parse config: %w", err)
 		}
+		if err := requireCluster(cfg, "destroy"); err != nil {
+			return err
+		}
+		if suffix := config.VariantSuffix(); suffix != "" && cfg.Cluster.Variant == "" {
+			return fmt.Errorf("cluster '%s' is shared by all variants; use 'kraze down --suffix %s' to remove variant '%s', or --suffix-cluster if it has its own cluster", cfg.Cluster.Name, suffix, suffix)
+		}
 
 		isExternal := cfg.Cluster.IsExternal()
+		if destroyVolumes && isExternal {
+			return fmt.Errorf("--volumes only applies to kind clusters; use 'kraze down --volumes' to delete the volumes of an external cluster's services")
+		}
 
 		if dryRun {
 			if isExternal {
 				fmt.Printf("[DRY RUN] Would delete state for external cluster '%s' (cluster preserved)\n", cfg.Cluster.Name)
 			} else {
 				fmt.Printf("[DRY RUN] Would destroy kind cluster '%s' and state\n", cfg.Cluster.Name)
+				if destroyVolumes {
+					fmt.Printf("[DRY RUN] Would remove the data of its local-path volumes first\n")
+				}
 			}
 			return nil
 		}
 
+		unlock, err := lockCluster(ctx, cfg.Cluster.Name, "destroy", waitForLock)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
 		// Delete cluster state ConfigMap (must be done before cluster deletion for external clusters)
 		if isExternal {
 			// External cluster - delete state ConfigMap before cluster is removed
@@ -68,8 +96,8 @@ Services do not need to be uninstalled first - the entire cluster is removed.`,
 			if err != nil {
 				fmt.Printf("Warning: failed to get kubeconfig for external cluster: %v\n", err)
 			} else {
-				// Create clientset from kubeconfig content (external cluster uses TLS verification)
-				clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig, false)
+				// Create clientset from kubeconfig content
+				clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig)
 				if err != nil {
 					fmt.Printf("Warning: failed to create Kubernetes client: %v\n", err)
 				} else {
@@ -88,10 +116,17 @@ Services do not need to be uninstalled first - the entire cluster is removed.`,
 				return err
 			}
 
+			kindMgr := cluster.NewKindManager()
+			if destroyVolumes {
+				removeVolumeData(ctx, kindMgr, cfg)
+			}
+
 			// Delete kind cluster
 			Verbose("Deleting kind cluster...")
-			kindMgr := cluster.NewKindManager()
-			if err := kindMgr.DeleteCluster(cfg.Cluster.Name); err != nil {
+			start := time.Now()
+			err := kindMgr.DeleteCluster(cfg.Cluster.Name)
+			emitEvent(ctx, cfg, events.Finished(events.ClusterDelete, cfg.Cluster.Name, "", start, err))
+			if err != nil {
 				return fmt.Errorf("failed to delete cluster: %w", err)
 			}
 			Verbose("Kind cluster deleted (cluster state ConfigMap deleted with cluster)")
@@ -107,3 +142,48 @@ Services do not need to be uninstalled first - the entire cluster is removed.`,
 		return nil
 	},
 }
+
+// removeVolumeData removes the data of a kind cluster's local-path volumes from
+// its nodes and reports the space reclaimed
+func removeVolumeData(ctx context.Context, kindMgr *cluster.KindManager, cfg *config.Config) {
+	exists, err := kindMgr.ClusterExists(cfg.Cluster.Name)
+	if err != nil || !exists {
+		return
+	}
+	kubeconfig, err := getClusterKubeconfigQuiet(ctx, kindMgr, cfg)
+	if err != nil {
+		fmt.Printf("%s Warning: not removing volume data: %v\n", color.Warning(), err)
+		return
+	}
+	clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig)
+	if err != nil {
+		fmt.Printf("%s Warning: not removing volume data: failed to create Kubernetes client: %v\n", color.Warning(), err)
+		return
+	}
+	volumes, err := providers.ListVolumes(ctx, clientset)
+	if err != nil {
+		fmt.Printf("%s Warning: not removing volume data: %v\n", color.Warning(), err)
+		return
+	}
+
+	usage := measureVolumes(ctx, volumes)
+	var reclaimed int64
+	removed := 0
+	for _, volume := range volumes {
+		if !volume.LocalPath || volume.Node == "" || volume.Path == "" {
+			continue
+		}
+		if err := cluster.RemoveVolumeData(ctx, volume.Node, volume.Path); err != nil {
+			fmt.Printf("%s Warning: %v\n", color.Warning(), err)
+			continue
+		}
+		removed++
+		reclaimed += usage[volume.Name]
+	}
+	fmt.Printf("%s Removed the data of %d volume(s), reclaimed %s\n", color.Checkmark(), removed, humanBytes(reclaimed))
+}
+
+func init() {
+	addLockFlag(destroyCmd)
+	destroyCmd.Flags().BoolVar(&destroyVolumes, "volumes", false, "Remove the data of local-path volumes from the nodes first and report the disk space reclaimed (kind only)")
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package cli
+
+import "syscall"
+
+// detachedSysProcAttr returns process attributes that start a child in its own
+// session so it survives the terminal that launched it
+func detachedSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
+
+// terminateProcess asks a background kraze process to shut down gracefully
+func terminateProcess(pid int) error {
+	return syscall.Kill(pid, syscall.SIGTERM)
+}
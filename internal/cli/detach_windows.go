@@ -0,0 +1,29 @@
+//go:build windows
+
+package cli
+
+import (
+	"os"
+	"syscall"
+)
+
+const (
+	createNewProcessGroup = 0x00000200
+	detachedProcess       = 0x00000008
+)
+
+// detachedSysProcAttr returns process attributes that start a child without a
+// console so it survives the terminal that launched it
+func detachedSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: createNewProcessGroup | detachedProcess}
+}
+
+// terminateProcess stops a background kraze process. Windows has no SIGTERM,
+// so the process is killed and its status files are cleaned up by the caller.
+func terminateProcess(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return process.Kill()
+}
@@ -0,0 +1,482 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	osexec "os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	devInterval time.Duration
+	devTimeout  string
+)
+
+var devCmd = &cobra.Command{
+	Use:   "dev [services...]",
+	Short: "Watch sources and reload services into the cluster on change",
+	Long: `Watch a service's sources and keep the cluster in sync while you edit.
+
+When a watched file changes, kraze:
+  1. Runs the service's dev.build command (if any) to rebuild its images
+  2. Reloads local images whose hash changed into the kind cluster
+  3. Re-applies the service if its manifests, chart or values files changed
+  4. Restarts the service's Deployments, StatefulSets and DaemonSets so pods
+     pick up reloaded images
+
+Services to watch are configured with a dev section:
+
+  services:
+    api:
+      type: manifests
+      path: ./k8s/api
+      images: [api:dev]
+      dev:
+        watch: [./api]
+        ignore: ["*_test.go", "node_modules"]
+        build: docker build -t api:dev ./api
+
+Without arguments, all services with a dev section are watched. Naming a
+service without a dev section watches only its manifests, chart and values.
+
+Examples:
+  kraze dev                  # Watch every service with a dev section
+  kraze dev api worker       # Watch only api and worker
+  kraze dev --interval 2s    # Poll for changes every 2 seconds`,
+	ValidArgsFunction: getServiceNames,
+	RunE:              runDev,
+}
+
+// devTarget is a service being watched by `kraze dev`
+type devTarget struct {
+	service  config.ServiceConfig
+	paths    []string // All watched paths: dev.watch plus the service's definition files
+	snapshot map[string]fileStamp
+}
+
+// devSession holds what reloading a service needs
+type devSession struct {
+	cfg        *config.Config
+	kubeconfig string
+	clientset  kubernetes.Interface
+	kindMgr    *cluster.KindManager
+	imgMgr     *cluster.ImageManager
+}
+
+func runDev(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if devInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanupPack()
+
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := requireCluster(cfg, "dev"); err != nil {
+		return err
+	}
+
+	if cfg.Cluster.IsExternal() {
+		return fmt.Errorf("kraze dev requires a kind cluster: images cannot be loaded into external cluster '%s'", cfg.Cluster.Name)
+	}
+
+	targets, err := selectDevTargets(cfg, args)
+	if err != nil {
+		return err
+	}
+
+	kubeconfig, err := getShellKubeconfig(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+
+	session := &devSession{
+		cfg:        cfg,
+		kubeconfig: kubeconfig,
+		clientset:  clientset,
+		kindMgr:    cluster.NewKindManager(),
+		imgMgr:     cluster.NewImageManager(verbose),
+	}
+
+	devCtx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	go keepClusterActive(devCtx, cfg.Cluster.Name)
+
+	for _, target := range targets {
+		target.snapshot = snapshotPaths(target.paths, devIgnorePatterns(&target.service))
+		fmt.Printf("%s Watching '%s' (%d path(s), %d file(s))\n", color.Checkmark(), target.service.Name, len(target.paths), len(target.snapshot))
+		Verbose("Paths for '%s': %v", target.service.Name, target.paths)
+	}
+	fmt.Println("Press Ctrl+C to stop")
+
+	ticker := time.NewTicker(devInterval)
+	defer ticker.Stop()
+
+	// Changes are collected until a poll sees no new ones, so a save that touches
+	// several files (or a build that writes outputs) triggers a single reload
+	pending := make(map[*devTarget][]string)
+	for {
+		select {
+		case <-devCtx.Done():
+			fmt.Println()
+			return nil
+		case <-ticker.C:
+		}
+
+		settled := true
+		for _, target := range targets {
+			current := snapshotPaths(target.paths, devIgnorePatterns(&target.service))
+			if changed := diffSnapshots(target.snapshot, current); len(changed) > 0 {
+				pending[target] = append(pending[target], changed...)
+				target.snapshot = current
+				settled = false
+			}
+		}
+		if !settled || len(pending) == 0 {
+			continue
+		}
+
+		for _, target := range targets {
+			changed, ok := pending[target]
+			if !ok {
+				continue
+			}
+			delete(pending, target)
+			sort.Strings(changed)
+			changed = slices.Compact(changed)
+			if err := session.reload(devCtx, &target.service, changed); err != nil && devCtx.Err() == nil {
+				fmt.Printf("%s %s\n", color.Cross(), err)
+			}
+			// Ignore files the reload itself wrote (e.g. build outputs inside watched paths)
+			target.snapshot = snapshotPaths(target.paths, devIgnorePatterns(&target.service))
+		}
+	}
+}
+
+// selectDevTargets returns the services to watch: the named ones, or every enabled
+// service with a dev section
+func selectDevTargets(cfg *config.Config, names []string) ([]*devTarget, error) {
+	if len(names) == 0 {
+		for _, name := range cfg.GetAllServiceNames() {
+			svc := cfg.Services[name]
+			if svc.Dev != nil && svc.IsEnabled() {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("no services have a 'dev' section; add one (see 'kraze dev --help') or name the services to watch")
+		}
+		sort.Strings(names)
+	}
+
+	targets := make([]*devTarget, 0, len(names))
+	for _, name := range names {
+		svc, ok := cfg.Services[name]
+		if !ok {
+			return nil, fmt.Errorf("service '%s' not found in configuration", name)
+		}
+
+		var paths []string
+		if svc.Dev != nil {
+			paths = append(paths, svc.Dev.Watch...)
+		}
+		paths = append(paths, svc.GetDefinitionPaths()...)
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("service '%s' has nothing to watch: add a 'dev' section with watch paths", name)
+		}
+
+		targets = append(targets, &devTarget{service: svc, paths: paths})
+	}
+	return targets, nil
+}
+
+// reload brings one service up to date after its watched files changed
+func (session *devSession) reload(ctx context.Context, svc *config.ServiceConfig, changed []string) error {
+	definitionChanged := false
+	sourceChanged := false
+	for _, path := range changed {
+		if isUnderAny(path, svc.GetDefinitionPaths()) {
+			definitionChanged = true
+		} else {
+			sourceChanged = true
+		}
+	}
+
+	fmt.Printf("\n[%s] %s: %d file(s) changed\n", time.Now().Format("15:04:05"), color.Cyan(svc.Name), len(changed))
+	for _, path := range changed {
+		Verbose("  %s", path)
+	}
+
+	if sourceChanged && svc.Dev != nil && svc.Dev.Build != "" {
+		fmt.Printf("Building: %s\n", svc.Dev.Build)
+		if err := runDevBuild(ctx, svc.Dev); err != nil {
+			return fmt.Errorf("build for '%s' failed: %w", svc.Name, err)
+		}
+	} else if sourceChanged && svc.Build != nil {
+		fmt.Printf("Building: %s\n", svc.Build.Image)
+		if _, err := session.imgMgr.BuildImage(ctx, svc.Build, false); err != nil {
+			return fmt.Errorf("build for '%s' failed: %w", svc.Name, err)
+		}
+	}
+
+	// Wait for commands changing the cluster from other terminals rather than
+	// failing the reload
+	unlock, err := lockCluster(ctx, session.cfg.Cluster.Name, "dev", true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	loaded, err := session.reloadImages(ctx, svc)
+	if err != nil {
+		return err
+	}
+	for _, img := range loaded {
+		fmt.Printf("%s Reloaded image '%s'\n", color.Checkmark(), img)
+	}
+
+	if definitionChanged {
+		fmt.Printf("Re-applying '%s'...\n", svc.Name)
+		if err := session.reinstall(ctx, svc); err != nil {
+			return fmt.Errorf("failed to re-apply '%s': %w", svc.Name, err)
+		}
+		fmt.Printf("%s Re-applied '%s'\n", color.Checkmark(), svc.Name)
+	}
+
+	if len(loaded) > 0 {
+		restarted, err := providers.RestartServiceWorkloads(ctx, session.clientset, svc)
+		if err != nil {
+			return fmt.Errorf("failed to restart '%s': %w", svc.Name, err)
+		}
+		if len(restarted) == 0 {
+			fmt.Printf("%s No workloads found to restart for '%s'\n", color.Warning(), svc.Name)
+		} else {
+			fmt.Printf("%s Restarted %s\n", color.Checkmark(), strings.Join(restarted, ", "))
+		}
+	} else if !definitionChanged {
+		fmt.Printf("No image changes for '%s'\n", svc.Name)
+	}
+
+	return nil
+}
+
+// reloadImages loads the service's local images some node lacks or has an
+// older version of, returning the images that were loaded
+func (session *devSession) reloadImages(ctx context.Context, svc *config.ServiceConfig) ([]string, error) {
+	images, err := session.imgMgr.GetImagesForService(ctx, svc, session.kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find images for '%s': %w", svc.Name, err)
+	}
+
+	clusterName := session.cfg.Cluster.Name
+	var changed []string
+	for _, img := range images {
+		info, err := session.imgMgr.GetImageInfo(ctx, img)
+		if err != nil || !info.InLocalDaemon {
+			continue
+		}
+
+		imageState, err := session.kindMgr.NodesImageState(ctx, clusterName, img, nil)
+		if err != nil {
+			Verbose("Warning: failed to compare image '%s' with the cluster: %v", img, err)
+		}
+		if imageState == cluster.ImageCurrent {
+			continue
+		}
+
+		// Untag first so the tag moves to the new image while running pods keep the old one
+		if imageState == cluster.ImageStale {
+			if err := session.kindMgr.UntagImage(ctx, clusterName, img); err != nil {
+				Verbose("Warning: failed to untag old image '%s': %v", img, err)
+			}
+		}
+		changed = append(changed, img)
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	// A rebuild usually changes several images built from one base, whose
+	// shared layers a single save writes once
+	if err := session.kindMgr.LoadImages(ctx, clusterName, changed); err != nil {
+		return nil, fmt.Errorf("failed to load images: %w", err)
+	}
+	return changed, nil
+}
+
+// reinstall re-applies a service after its manifests, chart or values changed
+func (session *devSession) reinstall(ctx context.Context, svc *config.ServiceConfig) error {
+	timeout := devTimeout
+	if svc.WaitTimeout != "" {
+		timeout = svc.WaitTimeout
+	}
+	pendingTimeout, err := svc.GetPendingTimeout()
+	if err != nil {
+		return err
+	}
+
+	provider, err := providers.NewProvider(svc, &providers.ProviderOptions{
+		ClusterName:     session.cfg.Cluster.Name,
+		KubeConfig:      session.kubeconfig,
+		Wait:            true,
+		Timeout:         timeout,
+		Verbose:         verbose,
+		Quiet:           !verbose,
+		ReadinessRules:  session.cfg.Readiness,
+		Workloads:       svc.Workloads,
+		PendingTimeout:  pendingTimeout,
+		SuspendCronJobs: session.cfg.ShouldSuspendCronJobs(svc),
+		Registries:      session.cfg.Registries,
+		OnOptionalNotReady: func(waitErr *providers.ResourceWaitError) {
+			fmt.Printf("%s Optional %s/%s of '%s' isn't ready: %v\n", color.Warning(), waitErr.Kind, waitErr.Name, svc.Name, waitErr)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if err := provider.Install(ctx, svc); err != nil {
+		return err
+	}
+	if session.cfg.ShouldSuspendCronJobs(svc) {
+		reportSuspendedCronJobs(ctx, session.clientset, svc, Verbose)
+	}
+	return nil
+}
+
+// runDevBuild runs a service's build command from the config directory
+func runDevBuild(ctx context.Context, dev *config.DevConfig) error {
+	var cmd *osexec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = osexec.CommandContext(ctx, "cmd", "/C", dev.Build)
+	} else {
+		cmd = osexec.CommandContext(ctx, "sh", "-c", dev.Build)
+	}
+	cmd.Dir = dev.Dir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// fileStamp identifies a version of a watched file
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// devIgnorePatterns returns the ignore patterns for a service; VCS metadata is always skipped
+func devIgnorePatterns(svc *config.ServiceConfig) []string {
+	patterns := []string{".git"}
+	if svc.Dev != nil {
+		patterns = append(patterns, svc.Dev.Ignore...)
+	}
+	return patterns
+}
+
+// snapshotPaths records every file under the given files and directories, skipping
+// ignored names and relative paths. Missing paths are skipped, so a file that
+// appears later shows up as a change.
+func snapshotPaths(paths, ignore []string) map[string]fileStamp {
+	snapshot := make(map[string]fileStamp)
+	for _, root := range paths {
+		_ = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			rel, relErr := filepath.Rel(root, path)
+			if relErr != nil {
+				rel = path
+			}
+			if path != root && isIgnored(entry.Name(), filepath.ToSlash(rel), ignore) {
+				if entry.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if entry.IsDir() {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return nil
+			}
+			snapshot[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+			return nil
+		})
+	}
+	return snapshot
+}
+
+// isIgnored returns true if a file's name or path relative to its watch root matches a pattern
+func isIgnored(name, rel string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+		if matched, _ := filepath.Match(pattern, rel); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// diffSnapshots returns the sorted files that were added, removed or modified
+func diffSnapshots(previous, current map[string]fileStamp) []string {
+	var changed []string
+	for path, stamp := range current {
+		if old, ok := previous[path]; !ok || !old.modTime.Equal(stamp.modTime) || old.size != stamp.size {
+			changed = append(changed, path)
+		}
+	}
+	for path := range previous {
+		if _, ok := current[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// isUnderAny returns true if path is one of roots or inside one of them
+func isUnderAny(path string, roots []string) bool {
+	for _, root := range roots {
+		rel, err := filepath.Rel(root, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	devCmd.Flags().DurationVar(&devInterval, "interval", time.Second, "How often to check watched paths for changes")
+	devCmd.Flags().StringVar(&devTimeout, "timeout", "5m", "Timeout for waiting on re-applied services")
+}
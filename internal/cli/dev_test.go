@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/hjames9/kraze/internal/config"
+)
+
+func TestSnapshotPathsAndDiff(test *testing.T) {
+	root := test.TempDir()
+	write := func(rel, content string) string {
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			test.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			test.Fatal(err)
+		}
+		return path
+	}
+
+	mainFile := write("main.go", "package main")
+	write("main_test.go", "package main")
+	write("node_modules/dep/index.js", "x")
+	write(".git/HEAD", "ref")
+	write("build/out.log", "log")
+	standalone := filepath.Join(test.TempDir(), "values.yaml")
+	if err := os.WriteFile(standalone, []byte("replicas: 1"), 0644); err != nil {
+		test.Fatal(err)
+	}
+
+	ignore := []string{".git", "*_test.go", "node_modules", "build/*.log"}
+	before := snapshotPaths([]string{root, standalone, filepath.Join(root, "missing")}, ignore)
+
+	expected := map[string]bool{mainFile: true, standalone: true}
+	if len(before) != len(expected) {
+		test.Fatalf("snapshot = %v, want only %v", before, expected)
+	}
+	for path := range before {
+		if !expected[path] {
+			test.Errorf("unexpected file in snapshot: %s", path)
+		}
+	}
+
+	// Modify one file, add one and ignore changes in ignored paths
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(mainFile, later, later); err != nil {
+		test.Fatal(err)
+	}
+	added := write("pkg/util.go", "package pkg")
+	write("node_modules/dep/other.js", "y")
+
+	after := snapshotPaths([]string{root, standalone}, ignore)
+	changed := diffSnapshots(before, after)
+	if want := []string{mainFile, added}; !reflect.DeepEqual(changed, sortedCopy(want)) {
+		test.Errorf("diffSnapshots() = %v, want %v", changed, sortedCopy(want))
+	}
+
+	// Removing a file is a change too
+	if err := os.Remove(standalone); err != nil {
+		test.Fatal(err)
+	}
+	removed := diffSnapshots(after, snapshotPaths([]string{root, standalone}, ignore))
+	if !reflect.DeepEqual(removed, []string{standalone}) {
+		test.Errorf("diffSnapshots() after removal = %v, want [%s]", removed, standalone)
+	}
+}
+
+func sortedCopy(values []string) []string {
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+func TestIsUnderAny(test *testing.T) {
+	roots := []string{filepath.FromSlash("/app/k8s"), filepath.FromSlash("/app/values.yaml")}
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{path: "/app/k8s/deployment.yaml", expected: true},
+		{path: "/app/k8s", expected: true},
+		{path: "/app/values.yaml", expected: true},
+		{path: "/app/k8s-extra/x.yaml", expected: false},
+		{path: "/app/src/main.go", expected: false},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.path, func(test *testing.T) {
+			if got := isUnderAny(filepath.FromSlash(tt.path), roots); got != tt.expected {
+				test.Errorf("isUnderAny(%s) = %v, want %v", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSelectDevTargets(test *testing.T) {
+	disabled := false
+	cfg := &config.Config{
+		Services: map[string]config.ServiceConfig{
+			"api":    {Name: "api", Type: "manifests", Path: "/app/k8s/api", Dev: &config.DevConfig{Watch: []string{"/app/api"}}},
+			"worker": {Name: "worker", Type: "manifests", Path: "/app/k8s/worker", Dev: &config.DevConfig{Watch: []string{"/app/worker"}}},
+			"old":    {Name: "old", Type: "manifests", Path: "/app/k8s/old", Dev: &config.DevConfig{Watch: []string{"/app/old"}}, Enabled: &disabled},
+			"redis":  {Name: "redis", Type: "helm", Repo: "https://charts.example.com", Chart: "redis"},
+			"web":    {Name: "web", Type: "manifests", Path: "/app/k8s/web"},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedNames []string
+		expectedPaths [][]string
+		expectError   bool
+	}{
+		{
+			name:          "all enabled services with a dev section",
+			expectedNames: []string{"api", "worker"},
+			expectedPaths: [][]string{{"/app/api", "/app/k8s/api"}, {"/app/worker", "/app/k8s/worker"}},
+		},
+		{
+			name:          "named service without a dev section watches its manifests",
+			args:          []string{"web"},
+			expectedNames: []string{"web"},
+			expectedPaths: [][]string{{"/app/k8s/web"}},
+		},
+		{
+			name:        "remote chart without a dev section has nothing to watch",
+			args:        []string{"redis"},
+			expectError: true,
+		},
+		{
+			name:        "unknown service",
+			args:        []string{"missing"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			targets, err := selectDevTargets(cfg, tt.args)
+			if (err != nil) != tt.expectError {
+				test.Fatalf("selectDevTargets() error = %v, expectError %v", err, tt.expectError)
+			}
+			if tt.expectError {
+				return
+			}
+			if len(targets) != len(tt.expectedNames) {
+				test.Fatalf("got %d targets, want %v", len(targets), tt.expectedNames)
+			}
+			for itr, target := range targets {
+				if target.service.Name != tt.expectedNames[itr] {
+					test.Errorf("target %d = %s, want %s", itr, target.service.Name, tt.expectedNames[itr])
+				}
+				if !reflect.DeepEqual(target.paths, tt.expectedPaths[itr]) {
+					test.Errorf("paths for %s = %v, want %v", target.service.Name, target.paths, tt.expectedPaths[itr])
+				}
+			}
+		})
+	}
+}
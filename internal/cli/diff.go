@@ -0,0 +1,265 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/graph"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/hjames9/kraze/internal/state"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffLabels         []string
+	diffForceConflicts bool
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [services...]",
+	Short: "Show how 'kraze up' would change the cluster",
+	Long: `Compare the cluster's live objects with what 'kraze up' would apply, and print a
+colorized unified diff for each resource that would be added, changed or deleted.
+
+Changes are predicted with a server-side dry-run apply, so fields the API server defaults
+or other tools own don't show up as drift. For Helm charts, resources of the deployed
+release the chart no longer renders are shown as deleted; for manifests, resources the last
+install applied that the manifests no longer contain. Secret values are masked.
+
+Exits non-zero when anything would change, so it can gate CI on drift
+(the same as 'kraze up --diff-only').
+
+Examples:
+  kraze diff                        # Diff all services
+  kraze diff api                    # Diff a service and its dependencies
+  kraze diff --label tier=backend   # Diff services with label tier=backend`,
+	ValidArgsFunction: getServiceNames,
+	RunE:              runDiff,
+}
+
+// diffSummary counts the resources a diff would change
+type diffSummary struct {
+	toAdd    int
+	toChange int
+	toDelete int
+}
+
+// total returns the number of resources that would change
+func (summary diffSummary) total() int {
+	return summary.toAdd + summary.toChange + summary.toDelete
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanupPack()
+
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := requireCluster(cfg, "diff"); err != nil {
+		return err
+	}
+	applySnapshotValues(cfg)
+
+	if len(args) > 0 && len(diffLabels) > 0 {
+		return fmt.Errorf("cannot specify both service names and labels, use one or the other")
+	}
+	if len(diffLabels) > 0 {
+		filteredServices, err := cfg.FilterServicesByLabelsWithDependencies(diffLabels)
+		if err != nil {
+			return fmt.Errorf("failed to filter services by labels: %w", err)
+		}
+		cfg.Services = filteredServices
+	} else if len(args) > 0 {
+		filteredServices, err := cfg.FilterServicesWithDependencies(args)
+		if err != nil {
+			return fmt.Errorf("failed to filter services: %w", err)
+		}
+		cfg.Services = filteredServices
+	}
+
+	depGraph := graph.NewDependencyGraph(cfg.Services)
+	if err := depGraph.Validate(); err != nil {
+		return fmt.Errorf("dependency validation failed: %w", err)
+	}
+	return diffAgainstCluster(ctx, cfg, depGraph, diffForceConflicts, true)
+}
+
+// diffAgainstCluster prints how installing the services would change the
+// existing cluster, and fails when anything would
+func diffAgainstCluster(ctx context.Context, cfg *config.Config, depGraph *graph.DependencyGraph, forceConflicts, prune bool) error {
+	serviceLevels, err := depGraph.TopologicalSortByLevel()
+	if err != nil {
+		return fmt.Errorf("failed to resolve dependencies: %w", err)
+	}
+	var orderedServices []*config.ServiceConfig
+	for _, level := range serviceLevels {
+		orderedServices = append(orderedServices, level...)
+	}
+
+	kubeconfig, err := getShellKubeconfig(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	st, err := state.Load(ctx, clientset, cfg.Cluster.Name, cfg.StateBackend)
+	if err != nil {
+		Verbose("Warning: failed to load cluster state: %v", err)
+	}
+	kubeconfig, err = impersonatedKubeconfig(kubeconfig, &cfg.Cluster)
+	if err != nil {
+		return err
+	}
+
+	summary, err := printServiceDiffs(ctx, cfg, orderedServices, kubeconfig, st, forceConflicts, prune)
+	if err != nil {
+		return err
+	}
+	if summary.total() > 0 {
+		return fmt.Errorf("%d resource(s) differ from the cluster", summary.total())
+	}
+	return nil
+}
+
+// printServiceDiffs prints the diff of each enabled service against the
+// cluster, in install order, followed by a summary
+func printServiceDiffs(ctx context.Context, cfg *config.Config, services []*config.ServiceConfig, kubeconfig string, st *state.ClusterState, forceConflicts, prune bool) (diffSummary, error) {
+	var summary diffSummary
+	seenNamespaces := make(map[providers.ResourceRef]bool)
+
+	for _, svc := range services {
+		if !svc.IsEnabled() {
+			continue
+		}
+		opts := &providers.ProviderOptions{
+			ClusterName:     cfg.Cluster.Name,
+			KubeConfig:      kubeconfig,
+			Verbose:         verbose,
+			Quiet:           true,
+			ForceConflicts:  forceConflicts,
+			SuspendCronJobs: cfg.ShouldSuspendCronJobs(svc),
+			Registries:      cfg.Registries,
+		}
+		if prune && st != nil {
+			opts.PruneResources = resourceRefs(st.GetAppliedResources(svc.Name))
+		}
+
+		Verbose("Diffing '%s'...", svc.Name)
+		diffs, err := providers.DiffService(ctx, svc, opts)
+		if err != nil {
+			return summary, fmt.Errorf("failed to diff service '%s': %w", svc.Name, err)
+		}
+
+		printed := false
+		for _, diff := range diffs {
+			// Services sharing a namespace would all report creating it
+			if strings.HasPrefix(string(diff.Ref), "v1:Namespace:") {
+				if seenNamespaces[diff.Ref] {
+					continue
+				}
+				seenNamespaces[diff.Ref] = true
+			}
+			if !printed {
+				fmt.Printf("%s\n", color.Bold(fmt.Sprintf("Service '%s':", svc.Name)))
+				printed = true
+			}
+			printResourceDiff(diff)
+
+			switch diff.Action {
+			case providers.DiffAdd:
+				summary.toAdd++
+			case providers.DiffChange:
+				summary.toChange++
+			case providers.DiffDelete:
+				summary.toDelete++
+			}
+		}
+	}
+
+	printDiffSummary(summary)
+	return summary, nil
+}
+
+// printResourceDiff prints one resource's change with a colorized diff
+func printResourceDiff(diff providers.ResourceDiff) {
+	switch diff.Action {
+	case providers.DiffAdd:
+		fmt.Printf("  %s %s (add)\n", color.Green("+"), diff.Ref)
+	case providers.DiffDelete:
+		fmt.Printf("  %s %s (delete)\n", color.Red("-"), diff.Ref)
+	default:
+		fmt.Printf("  %s %s (change)\n", color.Yellow("~"), diff.Ref)
+	}
+	if diff.Error != "" {
+		fmt.Printf("    %s %s\n", color.Cross(), diff.Error)
+	}
+	if diff.Diff != "" {
+		fmt.Print(colorizeDiff(diff.Diff))
+	}
+	fmt.Println()
+}
+
+// colorizeDiff colors the added, removed and hunk header lines of a unified diff
+func colorizeDiff(diff string) string {
+	lines := strings.SplitAfter(diff, "\n")
+	for itr, line := range lines {
+		text, newline := strings.CutSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(text, "+++"), strings.HasPrefix(text, "---"):
+			text = color.Bold(text)
+		case strings.HasPrefix(text, "+"):
+			text = color.Green(text)
+		case strings.HasPrefix(text, "-"):
+			text = color.Red(text)
+		case strings.HasPrefix(text, "@@"):
+			text = color.Cyan(text)
+		default:
+			continue
+		}
+		if newline {
+			text += "\n"
+		}
+		lines[itr] = text
+	}
+	return strings.Join(lines, "")
+}
+
+func printDiffSummary(summary diffSummary) {
+	fmt.Printf("%s", color.Bold("Diff:"))
+
+	parts := []string{}
+	if summary.toAdd > 0 {
+		parts = append(parts, color.Green(fmt.Sprintf("%d to add", summary.toAdd)))
+	}
+	if summary.toChange > 0 {
+		parts = append(parts, color.Yellow(fmt.Sprintf("%d to change", summary.toChange)))
+	}
+	if summary.toDelete > 0 {
+		parts = append(parts, color.Red(fmt.Sprintf("%d to delete", summary.toDelete)))
+	}
+
+	if len(parts) > 0 {
+		fmt.Printf(" %s\n", strings.Join(parts, ", "))
+	} else {
+		fmt.Println(" No changes")
+	}
+}
+
+func init() {
+	diffCmd.Flags().StringSliceVarP(&diffLabels, "label", "l", []string{}, "Filter services by label (format: key=value, can be specified multiple times)")
+	registerLabelCompletion(diffCmd)
+	diffCmd.Flags().BoolVar(&diffForceConflicts, "force-conflicts", false, "Diff as if taking ownership of fields changed by other tools, like 'kraze up --force-conflicts'")
+	addImpersonationFlags(diffCmd)
+}
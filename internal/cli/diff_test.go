@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	fatihcolor "github.com/fatih/color"
+)
+
+func TestColorizeDiff(test *testing.T) {
+	previous := fatihcolor.NoColor
+	fatihcolor.NoColor = false
+	defer func() { fatihcolor.NoColor = previous }()
+
+	diff := "--- live/ConfigMap/app/settings\n+++ kraze/ConfigMap/app/settings\n@@ -1,2 +1,2 @@\n data:\n-  LOG_LEVEL: info\n+  LOG_LEVEL: debug\n"
+	got := colorizeDiff(diff)
+
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	if len(lines) != 6 {
+		test.Fatalf("colorizeDiff() returned %d lines, want 6:\n%s", len(lines), got)
+	}
+	for itr, want := range []string{"\x1b[1m", "\x1b[1m", "\x1b[36m", " data:", "\x1b[31m", "\x1b[32m"} {
+		if !strings.HasPrefix(lines[itr], want) {
+			test.Errorf("line %d = %q, want prefix %q", itr, lines[itr], want)
+		}
+	}
+	if !strings.HasSuffix(got, "\n") {
+		test.Error("colorizeDiff() dropped the trailing newline")
+	}
+}
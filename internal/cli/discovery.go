@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/hjames9/kraze/internal/state"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// discoveryMutex serializes discovery ConfigMap writes during parallel installs
+var discoveryMutex sync.Mutex
+
+// discoveryServices returns the enabled services, whose Services make up the discovery map
+func discoveryServices(cfg *config.Config) []*config.ServiceConfig {
+	var services []*config.ServiceConfig
+	for _, name := range cfg.GetAllServiceNames() {
+		svc := cfg.Services[name]
+		if svc.IsEnabled() {
+			services = append(services, &svc)
+		}
+	}
+	return services
+}
+
+// writeDiscoveryMaps writes the discovery ConfigMap with the services
+// installed so far to the namespaces, creating those services create
+func writeDiscoveryMaps(ctx context.Context, cfg *config.Config, clientset kubernetes.Interface, namespaces map[string]bool) error {
+	discoveryMutex.Lock()
+	defer discoveryMutex.Unlock()
+
+	entries, err := providers.DiscoverServices(ctx, clientset, discoveryServices(cfg))
+	if err != nil {
+		return err
+	}
+	vars := providers.DiscoveryVars(entries, nil)
+	for _, namespace := range slices.Sorted(maps.Keys(namespaces)) {
+		if namespaces[namespace] {
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+			if _, err := clientset.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+				return fmt.Errorf("failed to create namespace: %w", err)
+			}
+		}
+		if err := providers.WriteDiscoveryMap(ctx, clientset, state.Name(), cfg.Discovery.GetConfigMap(), namespace, vars); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshDiscovery rewrites the discovery ConfigMap in the namespaces of the
+// installed services and the host .env file, or removes them when nothing is installed
+func refreshDiscovery(ctx context.Context, cfg *config.Config, clientset kubernetes.Interface, st *state.ClusterState) error {
+	namespaces := make(map[string]bool)
+	for _, svc := range discoveryServices(cfg) {
+		if st.IsServiceInstalled(svc.Name) {
+			namespaces[svc.GetNamespace()] = false
+		}
+	}
+	if len(namespaces) == 0 {
+		if err := providers.DeleteDiscoveryMaps(ctx, clientset, state.Name()); err != nil {
+			return err
+		}
+		if cfg.Discovery.EnvFile != "" {
+			if err := os.Remove(cfg.Discovery.EnvFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to remove discovery env file: %w", err)
+			}
+		}
+		return nil
+	}
+
+	if err := writeDiscoveryMaps(ctx, cfg, clientset, namespaces); err != nil {
+		return err
+	}
+	if cfg.Discovery.EnvFile == "" {
+		return nil
+	}
+	return writeDiscoveryEnvFile(ctx, cfg, clientset)
+}
+
+// writeDiscoveryEnvFile writes the discovery variables for processes on the
+// host: forwarded ports point at 127.0.0.1, the rest at in-cluster DNS names
+// that only resolve inside the cluster
+func writeDiscoveryEnvFile(ctx context.Context, cfg *config.Config, clientset kubernetes.Interface) error {
+	services := discoveryServices(cfg)
+	entries, err := providers.DiscoverServices(ctx, clientset, services)
+	if err != nil {
+		return err
+	}
+	forwards := make(map[string][]config.PortForward, len(services))
+	for _, svc := range services {
+		forwards[svc.Name] = svc.GetPortForwards()
+	}
+
+	vars := providers.DiscoveryVars(entries, forwards)
+	var content strings.Builder
+	fmt.Fprintf(&content, "# Written by kraze from the services of cluster '%s'; changes are overwritten\n", cfg.Cluster.Name)
+	for _, key := range slices.Sorted(maps.Keys(vars)) {
+		fmt.Fprintf(&content, "%s=%s\n", key, vars[key])
+	}
+	if err := os.WriteFile(cfg.Discovery.EnvFile, []byte(content.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write discovery env file: %w", err)
+	}
+	Verbose("Wrote %d discovery variable(s) to %s", len(vars), cfg.Discovery.EnvFile)
+	return nil
+}
+
+// warnDiscovery reports a failed discovery update, which doesn't fail the command
+func warnDiscovery(err error) {
+	if err != nil {
+		fmt.Printf("%s Warning: failed to update the discovery map: %v\n", color.Warning(), err)
+	}
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/pack"
+	"github.com/spf13/cobra"
+)
+
+var doctorOutput string
+
+// doctorReport is the output of 'kraze doctor -o json|yaml'
+type doctorReport struct {
+	Config string                `json:"config,omitempty"`
+	Checks []cluster.DoctorCheck `json:"checks"`
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that this machine can run a kraze cluster",
+	Long: `Run preflight checks on the host and print pass/fail with remediation hints.
+
+Checks the container runtime (Docker or Podman), the cgroup version, inotify
+limits, free disk space, the kind release and node image, host ports (6443 and
+any extraPortMappings), GPU prerequisites, proxy environment variables, and
+whether the kubeconfig and ~/.kraze can be written.
+
+With -f, or a kraze.yml in the current directory, the checks also use the
+cluster configuration. Exits non-zero when any check fails.
+
+Examples:
+  kraze doctor                   # Check the host
+  kraze doctor -f kraze.yml      # Also check the config's ports, GPU and node image
+  kraze doctor -o json           # Machine-readable results`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	format, err := parseOutputFormat(doctorOutput)
+	if err != nil {
+		return err
+	}
+
+	cfg, cfgPaths, err := loadDoctorConfig()
+	if err != nil {
+		return err
+	}
+	var clusterCfg *config.ClusterConfig
+	if cfg != nil {
+		clusterCfg = &cfg.Cluster
+	}
+
+	report := doctorReport{
+		Config: strings.Join(cfgPaths, ", "),
+		Checks: cluster.NewKindManager().RunDoctorChecks(ctx, clusterCfg),
+	}
+	passed, warned, failed := 0, 0, 0
+	for _, check := range report.Checks {
+		switch check.Status {
+		case cluster.DoctorPass:
+			passed++
+		case cluster.DoctorWarn:
+			warned++
+		case cluster.DoctorFail:
+			failed++
+		}
+	}
+
+	if format.isStructured() {
+		if err := printStructured(format, report); err != nil {
+			return err
+		}
+	} else {
+		if report.Config != "" {
+			fmt.Printf("Config: %s\n\n", report.Config)
+		} else {
+			fmt.Printf("No kraze.yml found, running host checks only\n\n")
+		}
+		printDoctorChecks(report.Checks)
+		fmt.Printf("\n%d passed, %d warning(s), %d failed\n", passed, warned, failed)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d doctor check(s) failed", failed)
+	}
+	return nil
+}
+
+// loadDoctorConfig loads the -f config files, or kraze.yml from the current
+// directory. Without either, doctor runs host checks only, so unlike other
+// commands it doesn't look up config paths from running clusters.
+func loadDoctorConfig() (*config.Config, []string, error) {
+	paths := configFiles
+	if len(paths) == 0 {
+		if _, err := os.Stat("kraze.yml"); err != nil {
+			return nil, nil, nil
+		}
+		paths = []string{"kraze.yml"}
+	}
+
+	extracted, cleanup, err := pack.MaybeExtract(paths)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cleanup()
+
+	cfg, err := config.ParseMultiple(extracted)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	return cfg, paths, nil
+}
+
+// printDoctorChecks prints one line per check, with a hint under failures and warnings
+func printDoctorChecks(checks []cluster.DoctorCheck) {
+	width := 0
+	for _, check := range checks {
+		width = max(width, len(check.Name))
+	}
+
+	for _, check := range checks {
+		icon := color.Checkmark()
+		switch check.Status {
+		case cluster.DoctorFail:
+			icon = color.Cross()
+		case cluster.DoctorWarn:
+			icon = color.Warning()
+		case cluster.DoctorSkip:
+			icon = color.Gray("-")
+		}
+		fmt.Printf("%s %-*s  %s\n", icon, width, check.Name, check.Message)
+		if check.Hint != "" && check.Status != cluster.DoctorPass {
+			fmt.Printf("  %-*s  hint: %s\n", width, "", check.Hint)
+		}
+	}
+}
+
+func init() {
+	addOutputFlag(doctorCmd, &doctorOutput)
+}
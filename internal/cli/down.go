@@ -2,25 +2,35 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/hjames9/kraze/internal/agent"
 	"github.com/hjames9/kraze/internal/cluster"
 	"github.com/hjames9/kraze/internal/color"
 	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/events"
 	"github.com/hjames9/kraze/internal/graph"
 	"github.com/hjames9/kraze/internal/providers"
 	"github.com/hjames9/kraze/internal/state"
 	"github.com/hjames9/kraze/internal/ui"
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 var (
 	downKeepCRDs                 bool
+	downForce                    bool
 	downLabels                   []string
 	downNamespaceDeletionTimeout time.Duration
+	downVolumes                  bool
+	downFromState                bool
+	downCluster                  string
+	downContext                  string
 )
 
 var downCmd = &cobra.Command{
@@ -31,16 +41,42 @@ var downCmd = &cobra.Command{
 If no services are specified, all services will be uninstalled.
 Services will be uninstalled in reverse dependency order.
 
+Uninstalling keeps PersistentVolumeClaims, since Helm and StatefulSets leave
+them behind. With --volumes, the claims of each uninstalled service (labeled
+with it, mounted by its pods, or created by its StatefulSets) are deleted too,
+then the volumes they were bound to, and on kind clusters the data of
+local-path volumes on the nodes; the reclaimed disk space is reported. When
+all services are uninstalled, claims no pod mounts in the namespaces being
+cleaned up and volumes released by earlier runs are deleted as well. See
+'kraze volume ls' for what is using space.
+
+With --from-state, the services recorded in the cluster state are uninstalled
+without reading kraze.yml, for cleaning up after a deleted branch or a config
+that no longer parses: Helm releases named after the services, the resources
+their manifests applied, and the namespaces kraze created for them. Without a
+config, name the cluster with --cluster (kind) or --context (kubeconfig context).
+
 You can filter services by name or by labels:
   kraze down service1 service2    # Uninstall specific services
   kraze down --label env=dev      # Uninstall services with label env=dev
-  kraze down --label tier=backend # Uninstall services with label tier=backend`,
-	ValidArgsFunction: getServiceNames,
-	RunE:              runDown,
+  kraze down --label tier=backend # Uninstall services with label tier=backend
+  kraze down operator --force     # Uninstall even if other services still use its CRDs
+  kraze down --volumes            # Also delete the services' volumes and their data
+  kraze down --from-state --cluster dev  # Uninstall what the state of cluster 'dev' records, without a config
+  kraze down --as system:serviceaccount:team-a:deployer  # Uninstall as a service account`,
+	ValidArgsFunction: getDownServiceNames,
+	RunE:              withRecording(runDown),
 }
 
 func runDown(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	ctx := cmd.Context()
+
+	if downFromState {
+		return runDownFromState(cmd, args)
+	}
+	if downCluster != "" || downContext != "" {
+		return fmt.Errorf("--cluster and --context only apply with --from-state")
+	}
 
 	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
 	if err != nil {
@@ -54,6 +90,9 @@ func runDown(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to parse config: %w", err)
 	}
+	if err := requireCluster(cfg, "down"); err != nil {
+		return err
+	}
 
 	// Check Docker availability (only for kind clusters, not external)
 	if !cfg.Cluster.IsExternal() {
@@ -66,6 +105,7 @@ func runDown(cmd *cobra.Command, args []string) error {
 
 	// Filter services if specified
 	requestedServices := args
+	var removedServices []string
 	specificServicesRequested := len(requestedServices) > 0 || len(downLabels) > 0
 
 	// Check if both service names and labels are specified
@@ -84,9 +124,20 @@ func runDown(cmd *cobra.Command, args []string) error {
 		Verbose("Found %d service(s) matching labels", len(filteredServices))
 	} else if len(requestedServices) > 0 {
 		Verbose("Services to uninstall: %v", requestedServices)
-		filteredServices, err := cfg.FilterServices(requestedServices)
-		if err != nil {
-			return fmt.Errorf("failed to filter services: %w", err)
+		// Services removed from the config are looked up in the cluster state instead
+		var configuredServices []string
+		for _, name := range requestedServices {
+			if _, ok := cfg.Services[name]; ok {
+				configuredServices = append(configuredServices, name)
+			} else {
+				removedServices = append(removedServices, name)
+			}
+		}
+		filteredServices := make(map[string]config.ServiceConfig)
+		if len(configuredServices) > 0 {
+			if filteredServices, err = cfg.FilterServices(configuredServices); err != nil {
+				return fmt.Errorf("failed to filter services: %w", err)
+			}
 		}
 		cfg.Services = filteredServices
 	} else {
@@ -114,9 +165,21 @@ func runDown(cmd *cobra.Command, args []string) error {
 		for name := range cfg.Services {
 			fmt.Printf("  - %s\n", name)
 		}
+		for _, name := range removedServices {
+			fmt.Printf("  - %s (no longer in the config)\n", name)
+		}
+		if downVolumes {
+			fmt.Printf("[DRY RUN] Would also delete their volume claims and volumes\n")
+		}
 		return nil
 	}
 
+	unlock, err := lockCluster(ctx, cfg.Cluster.Name, "down", waitForLock)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	var orderedServices []*config.ServiceConfig
 
 	if specificServicesRequested {
@@ -183,23 +246,33 @@ func runDown(cmd *cobra.Command, args []string) error {
 			return nil
 		}
 
+		if err := checkClusterHealth(ctx, kindMgr, &cfg.Cluster); err != nil {
+			return err
+		}
+
 		// Get kubeconfig for the cluster
 		kubeconfig, err = kindMgr.GetKubeConfig(cfg.Cluster.Name, false)
 		if err != nil {
 			return fmt.Errorf("failed to get kubeconfig: %w", err)
 		}
+		recordClusterActivity(cfg.Cluster.Name)
 	}
 
 	// Create Kubernetes clientset for cluster state management
 	// Use the kubeconfig content (not file path)
-	// Only skip TLS verification for kind clusters (not external clusters)
-	clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig, !isExternal)
+	clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig)
 	if err != nil {
 		return fmt.Errorf("failed to create Kubernetes client: %w", err)
 	}
 
+	// Uninstall services as the impersonated identity, if any
+	kubeconfig, err = impersonatedKubeconfig(kubeconfig, &cfg.Cluster)
+	if err != nil {
+		return err
+	}
+
 	// Load cluster state
-	st, err := state.Load(ctx, clientset, cfg.Cluster.Name)
+	st, err := state.Load(ctx, clientset, cfg.Cluster.Name, cfg.StateBackend)
 	if err != nil {
 		Verbose("Warning: failed to load cluster state: %v", err)
 		st = state.New(cfg.Cluster.Name, cfg.Cluster.IsExternal(), false, 0, false, 0)
@@ -208,6 +281,11 @@ func runDown(cmd *cobra.Command, args []string) error {
 		// ConfigMap doesn't exist yet (Load returns nil, nil in this case)
 		st = state.New(cfg.Cluster.Name, cfg.Cluster.IsExternal(), false, 0, false, 0)
 	}
+	for _, name := range removedServices {
+		if !st.IsServiceInstalled(name) {
+			return fmt.Errorf("service '%s' not found in the config or the cluster state", name)
+		}
+	}
 
 	// Collect namespaces to clean up BEFORE uninstalling (since uninstall removes from state)
 	// For local dev environments, aggressively clean up namespaces when uninstalling services
@@ -220,6 +298,7 @@ func runDown(cmd *cobra.Command, args []string) error {
 		for _, svc := range orderedServices {
 			serviceNames = append(serviceNames, svc.Name)
 		}
+		serviceNames = append(serviceNames, removedServices...)
 		namespacesToCleanup = st.GetNamespacesForServices(serviceNames)
 	} else {
 		// Uninstalling all services - clean up all namespaces (count will be 0 for all)
@@ -238,6 +317,23 @@ func runDown(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Record which namespaces kraze created BEFORE uninstalling (uninstall removes the
+	// services recording it). Any other namespace existed before kraze and is never
+	// deleted; only resources carrying kraze's ownership labels are removed from it.
+	createdNamespaces := make(map[string]bool, len(namespacesToCleanup))
+	for ns := range namespacesToCleanup {
+		createdNamespaces[ns] = st.IsNamespaceCreated(ns)
+	}
+
+	// The in-cluster agent would re-apply the services being removed
+	forgotten := append([]string(nil), removedServices...)
+	for _, svc := range orderedServices {
+		forgotten = append(forgotten, svc.Name)
+	}
+	if err := agent.UpdateDesired(ctx, clientset, state.Name(), nil, forgotten); err != nil {
+		fmt.Printf("%s Failed to update the kraze agent: %v\n", color.Warning(), err)
+	}
+
 	// Create progress manager
 	progress := ui.NewProgressManager(verbose, plain, len(orderedServices))
 
@@ -250,6 +346,8 @@ func runDown(cmd *cobra.Command, args []string) error {
 	}
 
 	uninstalledCount := 0
+	var refused []*providers.CRDsInUseError
+	var volumeClaims []corev1.PersistentVolumeClaim
 
 	// Uninstall each service in reverse dependency order
 	for itr, svc := range orderedServices {
@@ -259,11 +357,12 @@ func runDown(cmd *cobra.Command, args []string) error {
 
 		// Create provider options
 		providerOpts := &providers.ProviderOptions{
-			ClusterName: cfg.Cluster.Name,
-			KubeConfig:  kubeconfig,
-			Verbose:     verbose,
-			KeepCRDs:    downKeepCRDs,
-			Quiet:       !verbose, // Suppress intermediate output unless verbose
+			ClusterName:      cfg.Cluster.Name,
+			KubeConfig:       kubeconfig,
+			Verbose:          verbose,
+			KeepCRDs:         downKeepCRDs,
+			ForceCRDDeletion: downForce,
+			Quiet:            !verbose, // Suppress intermediate output unless verbose
 		}
 
 		// Create provider for this service
@@ -286,15 +385,55 @@ func runDown(cmd *cobra.Command, args []string) error {
 			continue
 		}
 
+		if err := runServiceHooks(ctx, config.HookPreUninstall, svc, itr, cfg.Cluster.Name, kubeconfig, ui.StatusUninstalling, progress); err != nil {
+			// The service stays installed, so its namespace must stay too
+			delete(namespacesToCleanup, svc.GetNamespace())
+			progress.Verbose("Warning: not uninstalling '%s': %v", svc.Name, err)
+			progress.UpdateService(itr, svc.Name, ui.StatusFailed, "pre_uninstall hook failed")
+			continue
+		}
+
+		// Find the service's claims while its pods and StatefulSets still exist
+		var claims []corev1.PersistentVolumeClaim
+		if downVolumes {
+			if claims, err = providers.ServiceClaims(ctx, clientset, svc); err != nil {
+				progress.Verbose("Warning: failed to find the volume claims of '%s': %v", svc.Name, err)
+			}
+		}
+
 		// Update status to show we're removing resources
 		progress.UpdateService(itr, svc.Name, ui.StatusUninstalling, "Removing resources")
 
 		// Uninstall the service
-		if err := provider.Uninstall(ctx, svc); err != nil {
+		start := time.Now()
+		err = provider.Uninstall(ctx, svc)
+		emitEvent(ctx, cfg, events.Finished(events.ServiceUninstall, cfg.Cluster.Name, svc.Name, start, err))
+		if err != nil {
+			var inUse *providers.CRDsInUseError
+			if errors.As(err, &inUse) {
+				// The service stays installed, so its namespace must stay too
+				delete(namespacesToCleanup, svc.GetNamespace())
+				refused = append(refused, inUse)
+				progress.UpdateService(itr, svc.Name, ui.StatusFailed, "CRDs still in use")
+				continue
+			}
 			progress.Verbose("Warning: failed to uninstall '%s': %v", svc.Name, err)
 			progress.UpdateService(itr, svc.Name, ui.StatusFailed, err.Error())
 			continue
 		}
+		volumeClaims = append(volumeClaims, claims...)
+
+		// Remove leftover kraze-labeled resources from a pre-existing namespace
+		for _, selector := range st.GetOwnedSelectors(svc.Name) {
+			deleted, err := providers.DeleteLabeledResources(ctx, kubeconfig, svc.GetNamespace(), selector)
+			if err != nil {
+				progress.Verbose("Warning: failed to remove resources labeled '%s' for '%s': %v", selector, svc.Name, err)
+				continue
+			}
+			if deleted > 0 {
+				progress.Verbose("Removed %d leftover resource(s) labeled '%s' from namespace '%s'", deleted, selector, svc.GetNamespace())
+			}
+		}
 
 		// Update cluster state
 		st.MarkServiceUninstalled(svc.Name)
@@ -302,6 +441,13 @@ func runDown(cmd *cobra.Command, args []string) error {
 			progress.Verbose("Warning: failed to save cluster state: %v", err)
 		}
 
+		// The service is gone either way, so a failed post_uninstall hook is only reported
+		if err := runServiceHooks(ctx, config.HookPostUninstall, svc, itr, cfg.Cluster.Name, kubeconfig, ui.StatusUninstalling, progress); err != nil {
+			progress.Verbose("Warning: %v", err)
+			progress.UpdateService(itr, svc.Name, ui.StatusFailed, "Removed, but post_uninstall hook failed")
+			continue
+		}
+
 		// Mark service as uninstalled
 		progress.UpdateService(itr, svc.Name, ui.StatusReady, "Removed")
 		uninstalledCount++
@@ -310,115 +456,227 @@ func runDown(cmd *cobra.Command, args []string) error {
 	// Finish progress display
 	progress.Finish(uninstalledCount)
 
-	// Clean up namespaces
-	// For local dev environments, aggressively delete namespaces used by uninstalled services
-	// Only delete if no other services are using the namespace
-	if len(namespacesToCleanup) > 0 {
-		fmt.Printf("\nCleaning up namespaces...\n")
-
-		// Collect namespaces to delete (filter out those still in use)
-		var namespacesToDelete []string
-		skippedNamespaces := 0
-
-		for ns, otherServicesCount := range namespacesToCleanup {
-			// Skip namespace if other services are still using it
-			if otherServicesCount > 0 {
-				progress.Verbose("Skipping namespace '%s' (still used by %d other service(s))", ns, otherServicesCount)
-				skippedNamespaces++
-				continue
+	// Removed services are uninstalled as recorded in the cluster state: their
+	// Helm release, the resources their manifests applied and their labeled leftovers
+	for _, name := range removedServices {
+		namespace := recordedNamespace(st, name)
+		providerOpts := &providers.ProviderOptions{
+			ClusterName:      cfg.Cluster.Name,
+			KubeConfig:       kubeconfig,
+			Verbose:          verbose,
+			KeepCRDs:         downKeepCRDs,
+			ForceCRDDeletion: downForce,
+			Quiet:            !verbose,
+		}
+		start := time.Now()
+		err := providers.UninstallRecorded(ctx, name, namespace, st.GetAppliedResources(name), providerOpts)
+		emitEvent(ctx, cfg, events.Finished(events.ServiceUninstall, cfg.Cluster.Name, name, start, err))
+		if err != nil {
+			// The service stays installed, so its namespace must stay too
+			delete(namespacesToCleanup, namespace)
+			fmt.Printf("%s Failed to uninstall '%s' (no longer in the config): %v\n", color.Warning(), name, err)
+			continue
+		}
+		for _, selector := range st.GetOwnedSelectors(name) {
+			if _, err := providers.DeleteLabeledResources(ctx, kubeconfig, namespace, selector); err != nil {
+				fmt.Printf("%s Warning: failed to remove resources labeled '%s' for '%s': %v\n", color.Warning(), selector, name, err)
 			}
+		}
+		st.MarkServiceUninstalled(name)
+		if err := st.Save(ctx, clientset); err != nil {
+			Verbose("Warning: failed to save cluster state: %v", err)
+		}
+		fmt.Printf("%s Removed '%s' (no longer in the config)\n", color.Checkmark(), name)
+	}
 
-			// Check if namespace still exists
-			exists, err := providers.CheckNamespaceExists(ctx, kubeconfig, ns)
-			if err != nil {
-				fmt.Printf("%s Warning: failed to check if namespace '%s' exists: %v\n", color.Warning(), ns, err)
-				continue
-			}
+	for _, inUse := range refused {
+		fmt.Printf("\n%s %v\n", color.Warning(), inUse)
+	}
 
-			if !exists {
-				progress.Verbose("Namespace '%s' already deleted", ns)
-				continue
+	// Drop the removed services from the discovery map, or remove it with the last service
+	if cfg.Discovery != nil {
+		warnDiscovery(refreshDiscovery(ctx, cfg, clientset, st))
+	}
+
+	if downVolumes {
+		fmt.Printf("\nDeleting volumes...\n")
+		var released []corev1.PersistentVolume
+		if !specificServicesRequested {
+			volumeClaims = append(volumeClaims, orphanedClaims(ctx, clientset, namespacesToCleanup, createdNamespaces, volumeClaims)...)
+
+			// kraze owns a kind cluster, so any released volume in it is left over
+			var scope map[string]bool
+			if isExternal {
+				scope = make(map[string]bool, len(namespacesToCleanup))
+				for ns := range namespacesToCleanup {
+					scope[ns] = true
+				}
 			}
+			if released, err = providers.ReleasedVolumes(ctx, clientset, scope); err != nil {
+				fmt.Printf("%s Warning: %v\n", color.Warning(), err)
+			}
+		}
+		reclaimVolumes(ctx, clientset, volumeClaims, released, !isExternal)
+	}
+
+	// Clean up namespaces
+	// For local dev environments, aggressively delete namespaces kraze created for uninstalled services
+	// Only delete if no other services are using the namespace
+	cleanupNamespaces(ctx, kubeconfig, namespacesToCleanup, createdNamespaces, progress)
+
+	return nil
+}
+
+// cleanupNamespaces deletes the namespaces kraze created that no remaining
+// service uses, in parallel. Namespaces kraze didn't create are never deleted.
+func cleanupNamespaces(ctx context.Context, kubeconfig string, namespacesToCleanup map[string]int, createdNamespaces map[string]bool, progress ui.ProgressManager) {
+	if len(namespacesToCleanup) == 0 {
+		return
+	}
+	fmt.Printf("\nCleaning up namespaces...\n")
 
-			namespacesToDelete = append(namespacesToDelete, ns)
+	// Collect namespaces to delete (filter out those still in use)
+	var namespacesToDelete []string
+	skippedNamespaces := 0
+
+	for ns, otherServicesCount := range namespacesToCleanup {
+		// Never delete a namespace kraze didn't create, however empty it looks
+		if !createdNamespaces[ns] {
+			progress.Verbose("Keeping namespace '%s' (not created by kraze)", ns)
+			continue
 		}
 
-		// Delete namespaces in parallel
-		if len(namespacesToDelete) > 0 {
-			var wg sync.WaitGroup
-			var mu sync.Mutex
-			deletedCount := 0
-			timeoutCount := 0
-			errorCount := 0
+		// Skip namespace if other services are still using it
+		if otherServicesCount > 0 {
+			progress.Verbose("Skipping namespace '%s' (still used by %d other service(s))", ns, otherServicesCount)
+			skippedNamespaces++
+			continue
+		}
 
-			for _, ns := range namespacesToDelete {
-				wg.Add(1)
-				go func(namespace string) {
-					defer wg.Done()
+		// Check if namespace still exists
+		exists, err := providers.CheckNamespaceExists(ctx, kubeconfig, ns)
+		if err != nil {
+			fmt.Printf("%s Warning: failed to check if namespace '%s' exists: %v\n", color.Warning(), ns, err)
+			continue
+		}
 
-					progress.Verbose("Deleting namespace '%s' (including all remaining resources)...", namespace)
+		if !exists {
+			progress.Verbose("Namespace '%s' already deleted", ns)
+			continue
+		}
 
-					// Delete the namespace (cascades to all resources including secrets, configmaps, etc.)
-					if err := providers.DeleteNamespace(ctx, kubeconfig, namespace); err != nil {
-						fmt.Printf("%s Warning: failed to delete namespace '%s': %v\n", color.Warning(), namespace, err)
+		namespacesToDelete = append(namespacesToDelete, ns)
+	}
+
+	// Delete namespaces in parallel
+	if len(namespacesToDelete) > 0 {
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		deletedCount := 0
+		timeoutCount := 0
+		errorCount := 0
+
+		for _, ns := range namespacesToDelete {
+			wg.Add(1)
+			go func(namespace string) {
+				defer wg.Done()
+
+				progress.Verbose("Deleting namespace '%s' (including all remaining resources)...", namespace)
+
+				// Delete the namespace (cascades to all resources including secrets, configmaps, etc.)
+				if err := providers.DeleteNamespace(ctx, kubeconfig, namespace); err != nil {
+					fmt.Printf("%s Warning: failed to delete namespace '%s': %v\n", color.Warning(), namespace, err)
+					mu.Lock()
+					errorCount++
+					mu.Unlock()
+					return
+				}
+
+				// Wait for deletion (unless timeout is 0)
+				if downNamespaceDeletionTimeout > 0 {
+					if err := providers.WaitForNamespaceDeletion(ctx, kubeconfig, namespace, downNamespaceDeletionTimeout); err != nil {
+						fmt.Printf("%s Warning: namespace '%s' still terminating after %v\n", color.Warning(), namespace, downNamespaceDeletionTimeout)
 						mu.Lock()
-						errorCount++
+						timeoutCount++
 						mu.Unlock()
-						return
-					}
-
-					// Wait for deletion (unless timeout is 0)
-					if downNamespaceDeletionTimeout > 0 {
-						if err := providers.WaitForNamespaceDeletion(ctx, kubeconfig, namespace, downNamespaceDeletionTimeout); err != nil {
-							fmt.Printf("%s Warning: namespace '%s' still terminating after %v\n", color.Warning(), namespace, downNamespaceDeletionTimeout)
-							mu.Lock()
-							timeoutCount++
-							mu.Unlock()
-						} else {
-							fmt.Printf("%s Deleted namespace '%s'\n", color.Checkmark(), namespace)
-							mu.Lock()
-							deletedCount++
-							mu.Unlock()
-						}
 					} else {
-						fmt.Printf("%s Namespace '%s' deletion initiated\n", color.Checkmark(), namespace)
+						fmt.Printf("%s Deleted namespace '%s'\n", color.Checkmark(), namespace)
 						mu.Lock()
 						deletedCount++
 						mu.Unlock()
 					}
-				}(ns)
-			}
-
-			// Wait for all deletions to complete
-			wg.Wait()
-
-			// Print summary
-			if deletedCount > 0 || timeoutCount > 0 {
-				if downNamespaceDeletionTimeout > 0 {
-					fmt.Printf("%s Deleted %d namespace(s)", color.Checkmark(), deletedCount)
-					if timeoutCount > 0 {
-						fmt.Printf(" (%d still terminating)", timeoutCount)
-					}
 				} else {
-					fmt.Printf("%s Initiated deletion of %d namespace(s)", color.Checkmark(), deletedCount)
+					fmt.Printf("%s Namespace '%s' deletion initiated\n", color.Checkmark(), namespace)
+					mu.Lock()
+					deletedCount++
+					mu.Unlock()
 				}
-				if skippedNamespaces > 0 {
-					fmt.Printf(" (skipped %d still in use)", skippedNamespaces)
+			}(ns)
+		}
+
+		// Wait for all deletions to complete
+		wg.Wait()
+
+		// Print summary
+		if deletedCount > 0 || timeoutCount > 0 {
+			if downNamespaceDeletionTimeout > 0 {
+				fmt.Printf("%s Deleted %d namespace(s)", color.Checkmark(), deletedCount)
+				if timeoutCount > 0 {
+					fmt.Printf(" (%d still terminating)", timeoutCount)
 				}
-				fmt.Printf("\n")
+			} else {
+				fmt.Printf("%s Initiated deletion of %d namespace(s)", color.Checkmark(), deletedCount)
 			}
-		} else if skippedNamespaces > 0 {
-			fmt.Printf("No namespaces deleted (%d still in use by other services)\n", skippedNamespaces)
-		} else {
-			fmt.Printf("No namespaces to clean up\n")
+			if skippedNamespaces > 0 {
+				fmt.Printf(" (skipped %d still in use)", skippedNamespaces)
+			}
+			fmt.Printf("\n")
 		}
+	} else if skippedNamespaces > 0 {
+		fmt.Printf("No namespaces deleted (%d still in use by other services)\n", skippedNamespaces)
+	} else {
+		fmt.Printf("No namespaces to clean up\n")
 	}
+}
 
-	return nil
+// orphanedClaims returns the claims no pod mounts in the namespaces kraze
+// created and is deleting, other than those already found
+func orphanedClaims(ctx context.Context, clientset kubernetes.Interface, namespaces map[string]int, created map[string]bool, found []corev1.PersistentVolumeClaim) []corev1.PersistentVolumeClaim {
+	known := make(map[string]bool, len(found))
+	for _, pvc := range found {
+		known[pvc.Namespace+"/"+pvc.Name] = true
+	}
+
+	var orphans []corev1.PersistentVolumeClaim
+	for ns, otherServicesCount := range namespaces {
+		if !created[ns] || otherServicesCount > 0 {
+			continue
+		}
+		claims, err := providers.OrphanedClaims(ctx, clientset, ns)
+		if err != nil {
+			fmt.Printf("%s Warning: %v\n", color.Warning(), err)
+			continue
+		}
+		for _, pvc := range claims {
+			if !known[pvc.Namespace+"/"+pvc.Name] {
+				orphans = append(orphans, pvc)
+			}
+		}
+	}
+	return orphans
 }
 
 func init() {
+	addLockFlag(downCmd)
 	downCmd.Flags().BoolVar(&downKeepCRDs, "keep-crds", false, "Keep CRDs when uninstalling Helm charts")
+	downCmd.Flags().BoolVar(&downForce, "force", false, "Uninstall services even if other services' custom resources still use CRDs they would delete")
 	downCmd.Flags().StringSliceVarP(&downLabels, "label", "l", []string{}, "Filter services by label (format: key=value, can be specified multiple times)")
+	registerLabelCompletion(downCmd)
 	downCmd.Flags().DurationVar(&downNamespaceDeletionTimeout, "namespace-deletion-timeout", 30*time.Second, "How long to wait for each namespace to be deleted (0 = don't wait, e.g., 30s, 1m)")
+	downCmd.Flags().BoolVar(&downVolumes, "volumes", false, "Also delete the services' PersistentVolumeClaims, their volumes and local-path data, and report the disk space reclaimed")
+	downCmd.Flags().BoolVar(&downFromState, "from-state", false, "Uninstall the services recorded in the cluster state without reading their config (e.g., after kraze.yml was deleted)")
+	downCmd.Flags().StringVar(&downCluster, "cluster", "", "With --from-state, the kind cluster to clean up when there is no config")
+	downCmd.Flags().StringVar(&downContext, "context", "", "With --from-state, the kubeconfig context of an external cluster to clean up when there is no config")
+	addImpersonationFlags(downCmd)
+	addRecordFlags(downCmd)
 }
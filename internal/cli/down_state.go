@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hjames9/kraze/internal/agent"
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/hjames9/kraze/internal/state"
+	"github.com/hjames9/kraze/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// runDownFromState uninstalls the services recorded in the cluster state
+// without reading their config: Helm releases named after them, the resources
+// their manifests applied and the namespaces kraze created for them. Used
+// when kraze.yml was deleted (with its branch) or no longer parses.
+func runDownFromState(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if len(downLabels) > 0 {
+		return fmt.Errorf("--label needs the services' config, name the services to uninstall instead")
+	}
+	if downVolumes {
+		return fmt.Errorf("--volumes needs the services' config; with --from-state, volume claims are deleted along with the namespaces kraze created")
+	}
+
+	clusterCfg, err := fromStateCluster(cmd)
+	if err != nil {
+		return err
+	}
+	kubeconfig, err := getShellKubeconfig(ctx, &config.Config{Cluster: *clusterCfg})
+	if err != nil {
+		return err
+	}
+	clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+
+	st, err := state.Load(ctx, clientset, clusterCfg.Name, "")
+	if err != nil {
+		return fmt.Errorf("failed to load cluster state: %w", err)
+	}
+	if st == nil || len(st.GetInstalledServices()) == 0 {
+		fmt.Printf("No services recorded in the state of cluster '%s', nothing to uninstall\n", clusterCfg.Name)
+		return nil
+	}
+	names, err := recordedServices(st, args)
+	if err != nil {
+		return err
+	}
+
+	var namespacesToCleanup map[string]int
+	if len(args) > 0 {
+		namespacesToCleanup = st.GetNamespacesForServices(names)
+	} else {
+		namespacesToCleanup = st.GetAllNamespacesUsedForCleanup()
+	}
+	createdNamespaces := make(map[string]bool, len(namespacesToCleanup))
+	for ns := range namespacesToCleanup {
+		createdNamespaces[ns] = st.IsNamespaceCreated(ns)
+	}
+
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would uninstall %d service(s) recorded in the cluster state\n", len(names))
+		for _, name := range names {
+			fmt.Printf("  - %s (namespace '%s', %d recorded resource(s))\n", name, recordedNamespace(st, name), len(st.GetAppliedResources(name)))
+		}
+		for ns, otherServicesCount := range namespacesToCleanup {
+			if createdNamespaces[ns] && otherServicesCount == 0 {
+				fmt.Printf("[DRY RUN] Would delete namespace '%s'\n", ns)
+			}
+		}
+		return nil
+	}
+
+	unlock, err := lockCluster(ctx, clusterCfg.Name, "down", waitForLock)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if !clusterCfg.IsExternal() {
+		recordClusterActivity(clusterCfg.Name)
+	}
+
+	kubeconfig, err = impersonatedKubeconfig(kubeconfig, clusterCfg)
+	if err != nil {
+		return err
+	}
+
+	// The in-cluster agent would re-apply the services being removed
+	if err := agent.UpdateDesired(ctx, clientset, state.Name(), nil, names); err != nil {
+		fmt.Printf("%s Failed to update the kraze agent: %v\n", color.Warning(), err)
+	}
+
+	progress := ui.NewProgressManager(verbose, plain, len(names))
+	progress.Start(len(names), "Uninstalling")
+	for itr, name := range names {
+		progress.UpdateService(itr, name, ui.StatusPending, "")
+	}
+
+	uninstalledCount := 0
+	for itr, name := range names {
+		namespace := recordedNamespace(st, name)
+		progress.UpdateService(itr, name, ui.StatusUninstalling, "Removing recorded resources")
+		progress.Verbose("Uninstalling '%s' from namespace '%s' as recorded in the cluster state...", name, namespace)
+
+		providerOpts := &providers.ProviderOptions{
+			ClusterName:      clusterCfg.Name,
+			KubeConfig:       kubeconfig,
+			Verbose:          verbose,
+			KeepCRDs:         downKeepCRDs,
+			ForceCRDDeletion: downForce,
+			Quiet:            !verbose,
+		}
+		if err := providers.UninstallRecorded(ctx, name, namespace, st.GetAppliedResources(name), providerOpts); err != nil {
+			// The service stays installed, so its namespace must stay too
+			delete(namespacesToCleanup, namespace)
+			progress.Verbose("Warning: failed to uninstall '%s': %v", name, err)
+			progress.UpdateService(itr, name, ui.StatusFailed, err.Error())
+			continue
+		}
+
+		// Remove leftover kraze-labeled resources from a pre-existing namespace
+		for _, selector := range st.GetOwnedSelectors(name) {
+			deleted, err := providers.DeleteLabeledResources(ctx, kubeconfig, namespace, selector)
+			if err != nil {
+				progress.Verbose("Warning: failed to remove resources labeled '%s' for '%s': %v", selector, name, err)
+				continue
+			}
+			if deleted > 0 {
+				progress.Verbose("Removed %d leftover resource(s) labeled '%s' from namespace '%s'", deleted, selector, namespace)
+			}
+		}
+
+		st.MarkServiceUninstalled(name)
+		if err := st.Save(ctx, clientset); err != nil {
+			progress.Verbose("Warning: failed to save cluster state: %v", err)
+		}
+		progress.UpdateService(itr, name, ui.StatusReady, "Removed")
+		uninstalledCount++
+	}
+	progress.Finish(uninstalledCount)
+
+	cleanupNamespaces(ctx, kubeconfig, namespacesToCleanup, createdNamespaces, progress)
+	return nil
+}
+
+// fromStateCluster returns the cluster 'kraze down --from-state' cleans up:
+// the kind cluster named by --cluster, the kubeconfig context named by
+// --context, or else the config's cluster
+func fromStateCluster(cmd *cobra.Command) (*config.ClusterConfig, error) {
+	switch {
+	case downCluster != "" && downContext != "":
+		return nil, fmt.Errorf("cannot specify both --cluster and --context")
+	case downCluster != "":
+		return &config.ClusterConfig{Name: downCluster}, nil
+	case downContext != "":
+		return &config.ClusterConfig{Name: downContext, External: &config.ExternalClusterConfig{Enabled: true, Context: downContext}}, nil
+	}
+
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("%w (without a config, name the cluster with --cluster or --context)", err)
+	}
+	defer cleanupPack()
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config (name the cluster with --cluster or --context instead): %w", err)
+	}
+	if err := requireCluster(cfg, "down"); err != nil {
+		return nil, err
+	}
+	return &cfg.Cluster, nil
+}
+
+// recordedServices returns the requested services, or all installed ones, most
+// recently installed first: the reverse of the order 'kraze up' installed them in
+func recordedServices(st *state.ClusterState, requested []string) ([]string, error) {
+	names := requested
+	if len(names) == 0 {
+		names = st.GetInstalledServices()
+	}
+	for _, name := range names {
+		if !st.IsServiceInstalled(name) {
+			return nil, fmt.Errorf("service '%s' isn't recorded in the cluster state", name)
+		}
+	}
+
+	ordered := append([]string(nil), names...)
+	sort.SliceStable(ordered, func(left, right int) bool {
+		leftAt, rightAt := st.Services[ordered[left]].UpdatedAt, st.Services[ordered[right]].UpdatedAt
+		if !leftAt.Equal(rightAt) {
+			return leftAt.After(rightAt)
+		}
+		return ordered[left] < ordered[right]
+	})
+	return ordered, nil
+}
+
+// recordedNamespace returns the namespace a service was installed in, which
+// states written before namespaces were tracked don't have
+func recordedNamespace(st *state.ClusterState, name string) string {
+	if namespace := st.Services[name].Namespace; namespace != "" {
+		return namespace
+	}
+	return "default"
+}
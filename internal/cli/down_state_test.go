@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hjames9/kraze/internal/state"
+)
+
+func TestRecordedServices(test *testing.T) {
+	st := state.New("dev", false, false, 0, false, 0)
+	installedAt := time.Now()
+	for itr, name := range []string{"postgres", "redis", "api", "web"} {
+		st.MarkServiceInstalledWithNamespace(name, "app", true)
+		svc := st.Services[name]
+		svc.UpdatedAt = installedAt.Add(time.Duration(itr) * time.Minute)
+		st.Services[name] = svc
+	}
+	// Installed in the same dependency level, so at the same time
+	redis := st.Services["redis"]
+	redis.UpdatedAt = st.Services["postgres"].UpdatedAt
+	st.Services["redis"] = redis
+
+	tests := []struct {
+		name      string
+		requested []string
+		expected  []string
+		wantErr   string
+	}{
+		{name: "all, most recently installed first", expected: []string{"web", "api", "postgres", "redis"}},
+		{name: "requested", requested: []string{"postgres", "api"}, expected: []string{"api", "postgres"}},
+		{name: "not recorded", requested: []string{"api", "billing"}, wantErr: "service 'billing' isn't recorded"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			names, err := recordedServices(st, tt.requested)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					test.Errorf("recordedServices() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				test.Fatalf("recordedServices() error = %v", err)
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				test.Errorf("recordedServices() = %v, want %v", names, tt.expected)
+			}
+		})
+	}
+}
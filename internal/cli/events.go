@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/events"
+)
+
+// eventsCloseTimeout bounds how long kraze waits to flush events on exit
+const eventsCloseTimeout = 5 * time.Second
+
+var (
+	eventDispatcher *events.Dispatcher
+	eventSinksMutex sync.Mutex
+)
+
+// emitEvent delivers an event to the sinks of the config's events block.
+// Delivery failures are warnings and never fail the command.
+func emitEvent(ctx context.Context, cfg *config.Config, event events.Event) {
+	if cfg.Events == nil {
+		return
+	}
+	// Still delivered when the command was interrupted
+	ctx = context.WithoutCancel(ctx)
+
+	eventSinksMutex.Lock()
+	if eventDispatcher == nil {
+		dispatcher, err := events.New(ctx, cfg.Events, version)
+		if err != nil {
+			eventSinksMutex.Unlock()
+			fmt.Fprintf(os.Stderr, "%s Warning: events are not delivered: %v\n", color.Warning(), err)
+			return
+		}
+		eventDispatcher = dispatcher
+	}
+	dispatcher := eventDispatcher
+	eventSinksMutex.Unlock()
+
+	if err := dispatcher.Emit(ctx, event); err != nil {
+		fmt.Fprintf(os.Stderr, "%s Warning: failed to deliver %s event: %v\n", color.Warning(), event.Type, err)
+	}
+}
+
+// closeEvents flushes the event sinks opened by the command
+func closeEvents() {
+	eventSinksMutex.Lock()
+	defer eventSinksMutex.Unlock()
+	if eventDispatcher == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), eventsCloseTimeout)
+	defer cancel()
+	if err := eventDispatcher.Close(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "%s Warning: failed to flush events: %v\n", color.Warning(), err)
+	}
+	eventDispatcher = nil
+}
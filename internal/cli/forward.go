@@ -0,0 +1,622 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// forwardHeartbeatInterval is how often the daemon refreshes its status file
+	forwardHeartbeatInterval = 5 * time.Second
+	// forwardPodCheckInterval is how often the daemon checks that the forwarded pod still exists
+	forwardPodCheckInterval = 5 * time.Second
+	// forwardMaxBackoff caps the delay between reconnect attempts
+	forwardMaxBackoff = 30 * time.Second
+	// forwardStableAfter is how long a tunnel must stay up before the reconnect backoff resets
+	forwardStableAfter = 30 * time.Second
+)
+
+var forwardCmd = &cobra.Command{
+	Use:   "forward",
+	Short: "Manage background port-forwards declared in the config",
+	Long: `Run the port-forwards declared with 'ports' in kraze.yml under a background
+daemon that keeps running after the CLI exits.
+
+The daemon re-establishes tunnels when pods are restarted or rolled, and writes
+a status file that is shown by 'kraze forward status' and 'kraze status'.
+
+Declare forwards per service as [LOCAL_PORT:]REMOTE_PORT:
+  services:
+    postgres:
+      type: helm
+      ...
+      ports: ["5432"]
+    web:
+      type: manifests
+      ...
+      ports: ["8080:80", "8443:443"]
+
+Examples:
+  kraze forward start            # Forward all declared ports in the background
+  kraze forward start postgres   # Forward only postgres's ports
+  kraze forward status           # Show tunnels and their state
+  kraze forward stop             # Stop the daemon`,
+}
+
+var forwardStartCmd = &cobra.Command{
+	Use:               "start [services...]",
+	Short:             "Start the port-forward daemon",
+	ValidArgsFunction: getServiceNames,
+	RunE:              runForwardStart,
+}
+
+var forwardStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the port-forward daemon",
+	Args:  cobra.NoArgs,
+	RunE:  runForwardStop,
+}
+
+var forwardStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the state of background port-forwards",
+	Args:  cobra.NoArgs,
+	RunE:  runForwardStatus,
+}
+
+// forwardDaemonCmd is the background process started by 'kraze forward start'
+var forwardDaemonCmd = &cobra.Command{
+	Use:    "daemon [services...]",
+	Short:  "Run port-forwards in the foreground (internal)",
+	Hidden: true,
+	RunE:   runForwardDaemon,
+}
+
+// forwardTarget is a single declared port forward
+type forwardTarget struct {
+	service config.ServiceConfig
+	port    config.PortForward
+}
+
+// declaredForwards returns the port forwards declared by the given services (all
+// enabled services if none are named), sorted by local port
+func declaredForwards(cfg *config.Config, serviceNames []string) ([]forwardTarget, error) {
+	names := serviceNames
+	if len(names) == 0 {
+		names = cfg.GetAllServiceNames()
+	}
+
+	var targets []forwardTarget
+	localPorts := make(map[int]string)
+	for _, name := range names {
+		svc, exists := cfg.Services[name]
+		if !exists {
+			return nil, fmt.Errorf("service '%s' not found in configuration", name)
+		}
+		if !svc.IsEnabled() {
+			continue
+		}
+		for _, pf := range svc.GetPortForwards() {
+			if other, taken := localPorts[pf.LocalPort]; taken {
+				return nil, fmt.Errorf("local port %d is declared by both '%s' and '%s'", pf.LocalPort, other, name)
+			}
+			localPorts[pf.LocalPort] = name
+			targets = append(targets, forwardTarget{service: svc, port: pf})
+		}
+	}
+
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].port.LocalPort < targets[j].port.LocalPort
+	})
+	return targets, nil
+}
+
+// getClusterKubeconfigQuiet returns the kubeconfig for the configured cluster without progress output
+func getClusterKubeconfigQuiet(ctx context.Context, kindMgr *cluster.KindManager, cfg *config.Config) (string, error) {
+	if cfg.Cluster.IsExternal() {
+		kubeconfig, err := kindMgr.GetKubeconfigForExternalCluster(&cfg.Cluster)
+		if err != nil {
+			return "", fmt.Errorf("failed to get kubeconfig for external cluster: %w", err)
+		}
+		return kubeconfig, nil
+	}
+
+	if err := checkClusterHealth(ctx, kindMgr, &cfg.Cluster); err != nil {
+		return "", err
+	}
+	kubeconfig, err := kindMgr.GetKubeConfigQuiet(cfg.Cluster.Name, false, true)
+	if err != nil {
+		return "", fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	return kubeconfig, nil
+}
+
+func runForwardStart(cmd *cobra.Command, args []string) error {
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanupPack()
+
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := requireCluster(cfg, "forward"); err != nil {
+		return err
+	}
+
+	targets, err := declaredForwards(cfg, args)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("no port-forwards declared, add 'ports' to services in the config")
+	}
+
+	existing, err := cluster.LoadForwardStatus(cfg.Cluster.Name)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.IsLive(time.Now()) {
+		return fmt.Errorf("port-forward daemon is already running (pid %d), run 'kraze forward stop' first", existing.PID)
+	}
+
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would start port-forward daemon for cluster '%s':\n", cfg.Cluster.Name)
+		printForwardTargets(targets)
+		return nil
+	}
+
+	if !cfg.Cluster.IsExternal() {
+		if err := cluster.CheckDockerAvailable(context.Background()); err != nil {
+			return err
+		}
+		exists, err := cluster.NewKindManager().ClusterExists(cfg.Cluster.Name)
+		if err != nil {
+			return fmt.Errorf("failed to check cluster: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("cluster '%s' is not running", cfg.Cluster.Name)
+		}
+	}
+
+	pid, err := launchForwardDaemon(cmd, cfg.Cluster.Name, args)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s Port-forward daemon started (pid %d)\n", color.Checkmark(), pid)
+	printForwardTargets(targets)
+	fmt.Printf("\nRun 'kraze ports' to check the tunnels, 'kraze forward stop' to stop them\n")
+	return nil
+}
+
+// printForwardTargets prints one line per declared forward
+func printForwardTargets(targets []forwardTarget) {
+	for _, target := range targets {
+		fmt.Printf("  localhost:%d -> %s/%s:%d\n", target.port.LocalPort, target.service.GetNamespace(), target.service.Name, target.port.RemotePort)
+	}
+}
+
+// launchForwardDaemon starts the daemon for the given services (all if none) and
+// waits briefly for its first status. The daemon re-reads the config itself, so
+// it is passed the unextracted config paths.
+func launchForwardDaemon(cmd *cobra.Command, clusterName string, serviceNames []string) (int, error) {
+	rawPaths, err := resolveConfigFiles(cmd)
+	if err != nil {
+		return 0, err
+	}
+	daemonArgs := []string{"forward", "daemon"}
+	for _, cfgPath := range rawPaths {
+		absPath, err := filepath.Abs(cfgPath)
+		if err != nil {
+			return 0, fmt.Errorf("failed to resolve config path: %w", err)
+		}
+		daemonArgs = append(daemonArgs, "-f", absPath)
+	}
+	daemonArgs = append(daemonArgs, serviceNames...)
+
+	pid, err := startForwardDaemon(clusterName, daemonArgs)
+	if err != nil {
+		return 0, err
+	}
+
+	// Give the daemon a moment to write its first status
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		status, err := cluster.LoadForwardStatus(clusterName)
+		if err == nil && status != nil && status.PID == pid {
+			break
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+	return pid, nil
+}
+
+// startForwardDaemon launches the detached daemon process and returns its pid
+func startForwardDaemon(clusterName string, daemonArgs []string) (int, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return 0, fmt.Errorf("failed to locate kraze executable: %w", err)
+	}
+
+	dir, err := cluster.ClusterDataDir(clusterName)
+	if err != nil {
+		return 0, err
+	}
+
+	logFile, err := os.OpenFile(filepath.Join(dir, "forward.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open port-forward log: %w", err)
+	}
+	defer logFile.Close()
+
+	daemonCmd := osexec.Command(executable, daemonArgs...)
+	daemonCmd.Stdout = logFile
+	daemonCmd.Stderr = logFile
+	daemonCmd.SysProcAttr = detachedSysProcAttr()
+
+	if err := daemonCmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start port-forward daemon: %w", err)
+	}
+
+	pid := daemonCmd.Process.Pid
+	return pid, daemonCmd.Process.Release()
+}
+
+func runForwardStop(cmd *cobra.Command, args []string) error {
+	cfg, cleanup, err := parseConfigForForward(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	status, err := cluster.LoadForwardStatus(cfg.Cluster.Name)
+	if err != nil {
+		return err
+	}
+	if status == nil || !status.IsLive(time.Now()) {
+		// Clean up a status file left by a daemon that died
+		if err := cluster.RemoveForwardStatus(cfg.Cluster.Name); err != nil {
+			return err
+		}
+		fmt.Printf("No port-forward daemon is running for cluster '%s'\n", cfg.Cluster.Name)
+		return nil
+	}
+
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would stop port-forward daemon (pid %d)\n", status.PID)
+		return nil
+	}
+
+	if err := stopForwardDaemon(cfg.Cluster.Name, status.PID); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s Port-forward daemon stopped\n", color.Checkmark())
+	return nil
+}
+
+// stopForwardDaemon terminates a running daemon and removes its status file
+func stopForwardDaemon(clusterName string, pid int) error {
+	if err := terminateProcess(pid); err != nil {
+		return fmt.Errorf("failed to stop port-forward daemon (pid %d): %w", pid, err)
+	}
+
+	// The daemon removes its status file on shutdown; wait briefly, then make sure it is gone
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		current, err := cluster.LoadForwardStatus(clusterName)
+		if err == nil && current == nil {
+			break
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+	return cluster.RemoveForwardStatus(clusterName)
+}
+
+func runForwardStatus(cmd *cobra.Command, args []string) error {
+	cfg, cleanup, err := parseConfigForForward(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	status, err := cluster.LoadForwardStatus(cfg.Cluster.Name)
+	if err != nil {
+		return err
+	}
+	if status == nil || !status.IsLive(time.Now()) {
+		fmt.Printf("No port-forward daemon is running for cluster '%s'\n", cfg.Cluster.Name)
+		return nil
+	}
+
+	printForwardStatus(status)
+	return nil
+}
+
+// parseConfigForForward parses the config for forward subcommands that only need the cluster name
+func parseConfigForForward(cmd *cobra.Command) (*config.Config, func(), error) {
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return nil, cleanupPack, err
+	}
+
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		cleanupPack()
+		return nil, func() {}, fmt.Errorf("failed to parse config: %w", err)
+	}
+	return cfg, cleanupPack, nil
+}
+
+// printForwardStatus prints a table of the daemon's port forwards
+func printForwardStatus(status *cluster.ForwardDaemonStatus) {
+	fmt.Printf("Port-forward daemon: pid %d, running since %s\n\n", status.PID, status.Started.Format(time.RFC3339))
+	printForwardTable(status.Forwards)
+}
+
+// printForwardTable prints port forwards with their state and last error
+func printForwardTable(forwards []cluster.ForwardStatus) {
+	fmt.Printf("%-20s %-8s %-8s %-13s %-9s %s\n", "SERVICE", "LOCAL", "REMOTE", "STATE", "RESTARTS", "POD")
+	fmt.Println("--------------------------------------------------------------------------------")
+	for _, fwd := range forwards {
+		pod := fwd.Pod
+		if pod == "" {
+			pod = "-"
+		}
+		fmt.Printf("%-20s %-8d %-8d %-13s %-9d %s\n", fwd.Service, fwd.LocalPort, fwd.RemotePort, fwd.State, fwd.Restarts, pod)
+		if fwd.State != cluster.ForwardStateActive && fwd.LastError != "" {
+			fmt.Printf("  %s %s\n", color.Warning(), fwd.LastError)
+		}
+	}
+}
+
+// forwardDaemon maintains a set of port forwards and reports their state
+type forwardDaemon struct {
+	clusterName string
+	cfg         *config.Config
+	kindMgr     *cluster.KindManager
+	persist     bool // Write the status file (false for foreground 'kraze port-forward')
+
+	mu     sync.Mutex
+	status *cluster.ForwardDaemonStatus
+}
+
+func runForwardDaemon(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, cleanup, err := parseConfigForForward(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	targets, err := declaredForwards(cfg, args)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("no port-forwards declared")
+	}
+
+	daemon := newForwardDaemon(cfg, targets, true)
+	if err := daemon.save(); err != nil {
+		return err
+	}
+	defer cluster.RemoveForwardStatus(cfg.Cluster.Name)
+
+	fmt.Printf("%s: port-forward daemon started for cluster '%s' (%d forward(s))\n", daemon.status.Started.Format(time.RFC3339), cfg.Cluster.Name, len(targets))
+	daemon.run(ctx, targets)
+	fmt.Printf("%s: port-forward daemon stopped\n", time.Now().Format(time.RFC3339))
+	return nil
+}
+
+// newForwardDaemon returns a daemon tracking the given forwards, all initially connecting
+func newForwardDaemon(cfg *config.Config, targets []forwardTarget, persist bool) *forwardDaemon {
+	now := time.Now()
+	daemon := &forwardDaemon{
+		clusterName: cfg.Cluster.Name,
+		cfg:         cfg,
+		kindMgr:     cluster.NewKindManager(),
+		persist:     persist,
+		status: &cluster.ForwardDaemonStatus{
+			PID:       os.Getpid(),
+			Started:   now,
+			Heartbeat: now,
+		},
+	}
+	for _, target := range targets {
+		daemon.status.Forwards = append(daemon.status.Forwards, cluster.ForwardStatus{
+			Service:    target.service.Name,
+			Namespace:  target.service.GetNamespace(),
+			LocalPort:  target.port.LocalPort,
+			RemotePort: target.port.RemotePort,
+			State:      cluster.ForwardStateConnecting,
+			Since:      now,
+		})
+	}
+	return daemon
+}
+
+// run maintains every forward until ctx is cancelled, refreshing the heartbeat
+func (daemon *forwardDaemon) run(ctx context.Context, targets []forwardTarget) {
+	var wg sync.WaitGroup
+	for itr, target := range targets {
+		wg.Add(1)
+		go func(idx int, target forwardTarget) {
+			defer wg.Done()
+			daemon.maintain(ctx, idx, target)
+		}(itr, target)
+	}
+
+	ticker := time.NewTicker(forwardHeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-ticker.C:
+			daemon.mu.Lock()
+			daemon.status.Heartbeat = time.Now()
+			daemon.mu.Unlock()
+			if err := daemon.save(); err != nil {
+				fmt.Printf("Warning: %v\n", err)
+			}
+		}
+	}
+}
+
+// save writes the current status file
+func (daemon *forwardDaemon) save() error {
+	if !daemon.persist {
+		return nil
+	}
+	daemon.mu.Lock()
+	defer daemon.mu.Unlock()
+	return cluster.SaveForwardStatus(daemon.clusterName, daemon.status)
+}
+
+// update records a state change for one forward and persists it
+func (daemon *forwardDaemon) update(idx int, state, pod string, cause error) {
+	daemon.mu.Lock()
+	fwd := &daemon.status.Forwards[idx]
+	if fwd.State != state {
+		fwd.Since = time.Now()
+	}
+	if state == cluster.ForwardStateReconnecting && fwd.State == cluster.ForwardStateActive {
+		fwd.Restarts++
+	}
+	fwd.State = state
+	fwd.Pod = pod
+	fwd.LastError = ""
+	if cause != nil {
+		fwd.LastError = cause.Error()
+	}
+	daemon.mu.Unlock()
+
+	if cause != nil {
+		fmt.Printf("%s: %s %d:%d %s: %v\n", time.Now().Format(time.RFC3339), fwd.Service, fwd.LocalPort, fwd.RemotePort, state, cause)
+	} else {
+		fmt.Printf("%s: %s %d:%d %s (pod %s)\n", time.Now().Format(time.RFC3339), fwd.Service, fwd.LocalPort, fwd.RemotePort, state, pod)
+	}
+
+	if err := daemon.save(); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+}
+
+// maintain keeps a single port forward running until ctx is cancelled,
+// reconnecting with exponential backoff when the tunnel or its pod goes away
+func (daemon *forwardDaemon) maintain(ctx context.Context, idx int, target forwardTarget) {
+	backoff := time.Second
+
+	for ctx.Err() == nil {
+		started := time.Now()
+		err := daemon.forwardOnce(ctx, idx, target)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if time.Since(started) > forwardStableAfter {
+			backoff = time.Second
+		}
+		daemon.update(idx, cluster.ForwardStateReconnecting, "", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > forwardMaxBackoff {
+			backoff = forwardMaxBackoff
+		}
+	}
+}
+
+// forwardOnce selects a pod and forwards to it until the tunnel breaks, the pod
+// goes away or ctx is cancelled. It returns the reason the forward ended.
+func (daemon *forwardDaemon) forwardOnce(ctx context.Context, idx int, target forwardTarget) error {
+	// Refresh the kubeconfig each time since the API server port changes when a kind cluster restarts
+	kubeconfig, err := getClusterKubeconfigQuiet(ctx, daemon.kindMgr, daemon.cfg)
+	if err != nil {
+		return err
+	}
+
+	pods, err := providers.GetPodsForService(ctx, kubeconfig, &target.service)
+	if err != nil {
+		return err
+	}
+	pod := pods[0]
+
+	fwdCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	go watchForwardedPod(fwdCtx, cancel, kubeconfig, &target.service, pod)
+
+	daemon.update(idx, cluster.ForwardStateActive, pod, nil)
+	err = providers.PortForward(fwdCtx, kubeconfig, target.service.GetNamespace(), pod, []string{target.port.String()})
+	if cause := context.Cause(fwdCtx); cause != nil && cause != context.Canceled {
+		return cause
+	}
+	if err == nil {
+		return fmt.Errorf("port-forward to pod %s ended", pod)
+	}
+	return err
+}
+
+// watchForwardedPod cancels the forward when its pod is deleted or replaced,
+// so the daemon reconnects to a new pod after a rollout
+func watchForwardedPod(ctx context.Context, cancel context.CancelCauseFunc, kubeconfig string, svc *config.ServiceConfig, pod string) {
+	ticker := time.NewTicker(forwardPodCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pods, err := providers.GetPodsForService(ctx, kubeconfig, svc)
+			if err != nil {
+				// Transient API errors are ignored; a broken tunnel is detected by the forwarder itself
+				continue
+			}
+			found := false
+			for _, name := range pods {
+				if name == pod {
+					found = true
+					break
+				}
+			}
+			if !found {
+				cancel(fmt.Errorf("pod %s is gone", pod))
+				return
+			}
+		}
+	}
+}
+
+func init() {
+	forwardCmd.AddCommand(forwardStartCmd)
+	forwardCmd.AddCommand(forwardStopCmd)
+	forwardCmd.AddCommand(forwardStatusCmd)
+	forwardCmd.AddCommand(forwardDaemonCmd)
+}
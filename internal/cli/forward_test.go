@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+)
+
+func TestDeclaredForwards(test *testing.T) {
+	disabled := false
+	cfg := &config.Config{
+		Services: map[string]config.ServiceConfig{
+			"web":    {Name: "web", Type: "manifests", Ports: []string{"8443:443", "8080:80"}},
+			"db":     {Name: "db", Type: "helm", Ports: []string{"5432"}},
+			"cache":  {Name: "cache", Type: "helm"},
+			"legacy": {Name: "legacy", Type: "helm", Ports: []string{"9000"}, Enabled: &disabled},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		services []string
+		expected []string // service:local:remote in order
+	}{
+		{
+			name:     "all services sorted by local port",
+			expected: []string{"db:5432:5432", "web:8080:80", "web:8443:443"},
+		},
+		{
+			name:     "selected service",
+			services: []string{"db"},
+			expected: []string{"db:5432:5432"},
+		},
+		{
+			name:     "service without ports",
+			services: []string{"cache"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			targets, err := declaredForwards(cfg, tt.services)
+			if err != nil {
+				test.Fatalf("unexpected error: %v", err)
+			}
+
+			var got []string
+			for _, target := range targets {
+				got = append(got, target.service.Name+":"+target.port.String())
+			}
+			if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				test.Errorf("declaredForwards() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDeclaredForwardsErrors(test *testing.T) {
+	cfg := &config.Config{
+		Services: map[string]config.ServiceConfig{
+			"a": {Name: "a", Type: "helm", Ports: []string{"8080:80"}},
+			"b": {Name: "b", Type: "helm", Ports: []string{"8080:3000"}},
+		},
+	}
+
+	if _, err := declaredForwards(cfg, []string{"a", "b"}); err == nil || !strings.Contains(err.Error(), "local port 8080") {
+		test.Errorf("expected local port conflict error, got %v", err)
+	}
+	if _, err := declaredForwards(cfg, []string{"missing"}); err == nil {
+		test.Errorf("expected error for unknown service")
+	}
+}
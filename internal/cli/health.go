@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+)
+
+// checkedClusters holds the clusters whose health was checked by this process
+var checkedClusters sync.Map
+
+// checkClusterHealth checks a kind cluster once per command for the effects of
+// a container runtime restart (stopped node containers, moved or lost port
+// mappings) and reports what was fixed on stderr. It returns an error wrapping
+// cluster.ErrClusterStopped for a cluster stopped with 'kraze stop' or auto-stop.
+func checkClusterHealth(ctx context.Context, kindMgr *cluster.KindManager, cfg *config.ClusterConfig) error {
+	if cfg.IsExternal() {
+		return nil
+	}
+	if _, checked := checkedClusters.LoadOrStore(cfg.Name, true); checked {
+		return nil
+	}
+
+	report, err := kindMgr.CheckHealth(ctx, cfg)
+	if err != nil {
+		checkedClusters.Delete(cfg.Name)
+		if errors.Is(err, cluster.ErrClusterStopped) {
+			return err
+		}
+		return fmt.Errorf("cluster health check failed: %w", err)
+	}
+	if !report.Changed() {
+		return nil
+	}
+
+	if len(report.StartedNodes) > 0 {
+		fmt.Fprintf(os.Stderr, "%s Restarted stopped node containers of cluster '%s': %s\n", color.Checkmark(), cfg.Name, strings.Join(report.StartedNodes, ", "))
+	}
+	for _, path := range slices.Sorted(maps.Keys(report.Kubeconfigs)) {
+		fmt.Fprintf(os.Stderr, "%s Updated %s: API server moved from %s\n", color.Checkmark(), path, report.Kubeconfigs[path])
+	}
+	if len(report.LostPorts) > 0 {
+		fmt.Fprintf(os.Stderr, "%s Host port mappings of cluster '%s' were lost (is another process using the port?):\n", color.Warning(), cfg.Name)
+		for _, port := range report.LostPorts {
+			fmt.Fprintf(os.Stderr, "  %s\n", port)
+		}
+		fmt.Fprintf(os.Stderr, "  Free the ports and restart the node containers, or recreate the cluster with 'kraze destroy && kraze up'\n")
+	}
+	return nil
+}
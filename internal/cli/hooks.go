@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/hjames9/kraze/internal/telemetry"
+	"github.com/hjames9/kraze/internal/ui"
+)
+
+// runServiceHooks runs a service's hooks of one phase in order. The latest line
+// a hook prints is shown next to the service and every line in verbose output.
+// Hooks with on_failure: ignore only warn when they fail.
+func runServiceHooks(ctx context.Context, phase string, svc *config.ServiceConfig, serviceIndex int, clusterName, kubeconfig string, status ui.ServiceStatus, progress ui.ProgressManager) error {
+	hooks := svc.Hooks.Get(phase)
+	for itr := range hooks {
+		hook := &hooks[itr]
+		progress.UpdateService(serviceIndex, svc.Name, status, fmt.Sprintf("Running %s hook '%s'", phase, hook.Description()))
+		progress.Verbose("Running %s hook '%s' of '%s'", phase, hook.Description(), svc.Name)
+
+		hookCtx := &providers.HookContext{
+			ClusterName: clusterName,
+			KubeConfig:  kubeconfig,
+			Phase:       phase,
+			Service:     svc,
+			OnOutput: func(line string) {
+				progress.UpdateService(serviceIndex, svc.Name, status, fmt.Sprintf("%s: %s", hook.Description(), line))
+				progress.Verbose("  %s [%s] %s", svc.Name, phase, line)
+			},
+		}
+		hookSpanCtx, span := telemetry.StartSpan(ctx, "service.hook", telemetry.Service(svc.Name))
+		err := providers.RunHook(hookSpanCtx, hookCtx, hook)
+		telemetry.EndSpan(span, err)
+		if err == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if hook.IgnoresFailure() {
+			progress.Verbose("%s %v (on_failure: ignore)", color.Warning(), err)
+			continue
+		}
+		return err
+	}
+	return nil
+}
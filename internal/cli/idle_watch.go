@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/spf13/cobra"
+)
+
+// idleWatchInterval is how often the idle watcher checks for inactivity
+var idleWatchInterval = time.Minute
+
+// idleWatchCmd is the background process started by 'kraze up --auto-stop'.
+// It is hidden because users never need to run it directly.
+var idleWatchCmd = &cobra.Command{
+	Use:               "idle-watch CLUSTER",
+	Short:             "Stop a kind cluster after it has been idle (internal)",
+	Hidden:            true,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: getClusterNames,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runIdleWatch(args[0])
+	},
+}
+
+// runIdleWatch stops the cluster once it has been idle for its auto-stop
+// duration. Its output goes to idle-watch.log, so every reason it exits is logged.
+func runIdleWatch(clusterName string) error {
+	kindMgr := cluster.NewKindManager()
+	pid := os.Getpid()
+
+	for {
+		// Cluster was deleted or stopped by other means
+		running, err := kindMgr.IsClusterRunning(clusterName)
+		if err != nil {
+			logIdleWatch("cluster '%s': failed to check whether it's running, retrying: %v", clusterName, err)
+			time.Sleep(idleWatchInterval)
+			continue
+		}
+		if !running {
+			logIdleWatch("cluster '%s' is no longer running, exiting", clusterName)
+			return nil
+		}
+
+		// The idle check and the decision to stop happen under the activity lock,
+		// so activity recorded meanwhile either comes first and keeps the cluster
+		// running, or finds it marked stopped
+		var exit string
+		var unlock func()
+		var idleSince time.Time
+		err = cluster.UpdateActivity(clusterName, func(rec *cluster.ActivityRecord) bool {
+			now := time.Now()
+			switch {
+			case rec.WatcherPID != pid && rec.HasLiveWatcher(now):
+				exit = fmt.Sprintf("watcher %d has taken over", rec.WatcherPID)
+				return false
+			case rec.GetAutoStop() == 0:
+				exit = "auto-stop was disabled"
+				return false
+			}
+
+			// A command changing the cluster (e.g. a long 'kraze up') counts as activity,
+			// so the cluster is only stopped while no command holds its lock
+			if rec.IsIdle(now) {
+				if release, err := lockCluster(context.Background(), clusterName, "idle-watch", false); err == nil {
+					unlock, idleSince = release, rec.LastActivity
+					rec.WatcherPID = 0
+					rec.Stopped = true
+					return true
+				}
+			}
+			rec.WatcherPID = pid
+			rec.WatcherHeartbeat = now
+			return true
+		})
+		if err != nil {
+			if unlock != nil {
+				unlock()
+			}
+			logIdleWatch("cluster '%s': failed to update the activity record, exiting: %v", clusterName, err)
+			return err
+		}
+		if exit != "" {
+			logIdleWatch("cluster '%s': %s, exiting", clusterName, exit)
+			return nil
+		}
+
+		if unlock != nil {
+			logIdleWatch("cluster '%s' idle since %s, stopping", clusterName, idleSince.Format(time.RFC3339))
+			err := kindMgr.StopCluster(clusterName)
+			unlock()
+			if err != nil {
+				logIdleWatch("cluster '%s': failed to stop: %v", clusterName, err)
+				if err := cluster.SetStopped(clusterName, false); err != nil {
+					logIdleWatch("cluster '%s': failed to clear the stopped mark: %v", clusterName, err)
+				}
+				return err
+			}
+			return nil
+		}
+
+		time.Sleep(idleWatchInterval)
+	}
+}
+
+// logIdleWatch writes a timestamped line to the idle watcher's log
+func logIdleWatch(format string, args ...any) {
+	fmt.Printf("%s: %s\n", time.Now().Format(time.RFC3339), fmt.Sprintf(format, args...))
+}
+
+// ensureIdleWatcher starts a background idle watcher for the cluster if auto-stop is
+// configured and no watcher is currently running
+func ensureIdleWatcher(clusterName string) error {
+	rec, err := cluster.LoadActivity(clusterName)
+	if err != nil {
+		return err
+	}
+	if rec.GetAutoStop() == 0 || rec.HasLiveWatcher(time.Now()) {
+		return nil
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate kraze executable: %w", err)
+	}
+
+	dir, err := cluster.ClusterDataDir(clusterName)
+	if err != nil {
+		return err
+	}
+
+	logFile, err := os.OpenFile(filepath.Join(dir, "idle-watch.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open idle watcher log: %w", err)
+	}
+	defer logFile.Close()
+
+	watchCmd := osexec.Command(executable, "idle-watch", clusterName)
+	watchCmd.Stdout = logFile
+	watchCmd.Stderr = logFile
+	watchCmd.SysProcAttr = detachedSysProcAttr()
+
+	if err := watchCmd.Start(); err != nil {
+		return fmt.Errorf("failed to start idle watcher: %w", err)
+	}
+
+	// Saved under the activity lock, since the watcher may already be stamping its heartbeat
+	watcherPID := watchCmd.Process.Pid
+	err = cluster.UpdateActivity(clusterName, func(rec *cluster.ActivityRecord) bool {
+		rec.WatcherPID = watcherPID
+		rec.WatcherHeartbeat = time.Now()
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	return watchCmd.Process.Release()
+}
+
+// recordClusterActivity resets the idle timer for a cluster. Failures are only
+// reported in verbose mode since activity tracking must never block a command.
+func recordClusterActivity(clusterName string) {
+	if err := cluster.RecordActivity(clusterName); err != nil {
+		Verbose("Warning: failed to record cluster activity: %v", err)
+	}
+}
+
+// keepClusterActive records cluster activity periodically until ctx is cancelled,
+// so long-running commands prevent the idle auto-stop
+func keepClusterActive(ctx context.Context, clusterName string) {
+	recordClusterActivity(clusterName)
+
+	ticker := time.NewTicker(idleWatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			recordClusterActivity(clusterName)
+		}
+	}
+}
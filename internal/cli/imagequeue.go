@@ -0,0 +1,399 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/state"
+	"github.com/hjames9/kraze/internal/telemetry"
+	"github.com/hjames9/kraze/internal/ui"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// imageResult is what preparing a service's images produced
+type imageResult struct {
+	images []string          // Every image the service uses
+	hashes map[string]string // Local image hashes, recorded in cluster state
+	bytes  int64             // Size of the images loaded into the cluster
+	err    error
+}
+
+// imageJob prepares the images of one service. It runs exactly once, on the
+// background worker or on the service's own install goroutine, whichever claims it first.
+type imageJob struct {
+	svc     *config.ServiceConfig
+	claimed atomic.Bool
+	done    chan struct{}
+	result  imageResult
+}
+
+// imageQueue builds, detects and loads service images on a background worker,
+// in install order. Image IO for later services then overlaps the API applies
+// and readiness waits of earlier levels instead of blocking them. A service
+// whose install starts before the worker reaches it steals its own job.
+type imageQueue struct {
+	cfg        *config.Config
+	kubeconfig string
+	st         *state.ClusterState
+	kindMgr    *cluster.KindManager
+	imgMgr     *cluster.ImageManager
+	progress   ui.ProgressManager
+
+	jobs  map[string]*imageJob
+	order []*imageJob
+
+	// prepare does the work of a job, replaceable in tests
+	prepare func(ctx context.Context, svc *config.ServiceConfig, report func(message string)) imageResult
+
+	// loaded tracks images loaded onto every node during this run, so services
+	// sharing an image load it once
+	loadedMu sync.Mutex
+	loaded   map[string]string
+
+	// nodes are the cluster's nodes when it has several, listed once for placing images
+	nodesOnce sync.Once
+	nodes     []corev1.Node
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// newImageQueue queues the image jobs of services, in install order
+func newImageQueue(services []*config.ServiceConfig, cfg *config.Config, kubeconfig string, st *state.ClusterState, kindMgr *cluster.KindManager, imgMgr *cluster.ImageManager, progress ui.ProgressManager) *imageQueue {
+	queue := &imageQueue{
+		cfg:        cfg,
+		kubeconfig: kubeconfig,
+		st:         st,
+		kindMgr:    kindMgr,
+		imgMgr:     imgMgr,
+		progress:   progress,
+		jobs:       make(map[string]*imageJob, len(services)),
+		loaded:     make(map[string]string),
+	}
+	queue.prepare = queue.prepareServiceImages
+
+	for _, svc := range services {
+		job := &imageJob{svc: svc, done: make(chan struct{})}
+		queue.jobs[svc.Name] = job
+		queue.order = append(queue.order, job)
+	}
+	return queue
+}
+
+// Start runs the queued jobs in order on a background worker until Stop is called
+func (queue *imageQueue) Start(ctx context.Context) {
+	ctx, queue.cancel = context.WithCancel(ctx)
+	queue.wg.Add(1)
+	go func() {
+		defer queue.wg.Done()
+		for _, job := range queue.order {
+			if ctx.Err() != nil {
+				return
+			}
+			queue.run(ctx, job, func(message string) {
+				queue.progress.Verbose("Preparing images for '%s': %s", job.svc.Name, message)
+			})
+		}
+	}()
+}
+
+// Stop cancels background work and waits for the worker to exit
+func (queue *imageQueue) Stop() {
+	if queue.cancel != nil {
+		queue.cancel()
+	}
+	queue.wg.Wait()
+}
+
+// Await returns the images of a service once they are prepared, running the
+// job on the calling goroutine if the worker hasn't started it yet
+func (queue *imageQueue) Await(ctx context.Context, svc *config.ServiceConfig, report func(message string)) imageResult {
+	job, exists := queue.jobs[svc.Name]
+	if !exists {
+		return queue.prepare(ctx, svc, report)
+	}
+
+	queue.run(ctx, job, report)
+	select {
+	case <-job.done:
+		return job.result
+	default:
+		report("Waiting for images")
+	}
+
+	select {
+	case <-job.done:
+		return job.result
+	case <-ctx.Done():
+		return imageResult{err: ctx.Err()}
+	}
+}
+
+// run does a job unless another goroutine already claimed it
+func (queue *imageQueue) run(ctx context.Context, job *imageJob, report func(message string)) {
+	if !job.claimed.CompareAndSwap(false, true) {
+		return
+	}
+	job.result = queue.prepare(ctx, job.svc, report)
+	close(job.done)
+}
+
+// prepareServiceImages builds a service's image if configured, then loads the
+// local images it uses into the cluster when they are missing or changed
+func (queue *imageQueue) prepareServiceImages(ctx context.Context, svc *config.ServiceConfig, report func(message string)) (result imageResult) {
+	ctx, span := telemetry.StartSpan(ctx, "service.images", telemetry.Service(svc.Name))
+	defer func() { telemetry.EndSpan(span, result.err) }()
+
+	cfg, st, kindMgr, imgMgr, progress := queue.cfg, queue.st, queue.kindMgr, queue.imgMgr, queue.progress
+
+	// Build the service's image before detecting and loading images
+	if svc.Build != nil && !upNoBuild {
+		report(fmt.Sprintf("Building %s", svc.Build.Image))
+		built, err := imgMgr.BuildImage(ctx, svc.Build, upBuild)
+		if err != nil {
+			return imageResult{err: fmt.Errorf("failed to build image for '%s': %w", svc.Name, err)}
+		}
+		if built {
+			progress.Verbose("%s Built image '%s'", color.Checkmark(), svc.Build.Image)
+		}
+	}
+
+	// Extract images from service configuration
+	serviceImages, err := imgMgr.GetImagesForService(ctx, svc, queue.kubeconfig)
+	if err != nil {
+		progress.Verbose("Warning: failed to extract images for '%s': %v", svc.Name, err)
+		serviceImages = []string{}
+	}
+	if len(serviceImages) == 0 {
+		return imageResult{}
+	}
+
+	// Check if context was cancelled before expensive Docker operations
+	if ctx.Err() != nil {
+		return imageResult{err: ctx.Err()}
+	}
+
+	progress.Verbose("Detected %d image(s) for service '%s': %v", len(serviceImages), svc.Name, serviceImages)
+
+	// Inspecting images only reads from Docker and the cluster, so it runs
+	// without dockerMutex alongside other services' loads
+	imageHashes := make(map[string]string)
+	imageSizes := make(map[string]int64)
+	localImages := make([]string, 0)
+	imagesToLoad := make([]string, 0)
+	imagesToRemove := make([]string, 0) // Track images that need to be removed before reloading
+
+	// Where the images go is only worked out once a local image needs it
+	var placement map[string][]string
+	placed := false
+
+	// Only process local images (built locally, no registry source).
+	// Registry images (those with RepoDigests) are pulled directly by kind's containerd
+	// and do not need to be loaded via the host Docker daemon.
+	for _, img := range serviceImages {
+		imgInfo, err := imgMgr.GetImageInfo(ctx, img)
+		if err != nil {
+			progress.Verbose("Warning: failed to get info for image '%s': %v", img, err)
+			continue
+		}
+		currentHash := imgInfo.SHA256
+		if currentHash != "" {
+			imageHashes[img] = currentHash
+		}
+		imageSizes[img] = imgInfo.Size
+
+		if !imgInfo.InLocalDaemon {
+			// Image is not in the local Docker daemon — kind will pull it from the registry.
+			progress.Verbose("Image '%s' not found in local daemon, kind will pull from registry", img)
+			continue
+		}
+
+		// Image is present in the local Docker daemon (built locally or pre-pulled).
+		// Load it into the kind cluster rather than relying on kind's containerd
+		// to pull from the registry — the registry may be unreachable from inside
+		// the kind node network.
+		localImages = append(localImages, img)
+		if cfg.Cluster.IsExternal() {
+			// External cluster - use state file comparison
+			stateMutex.Lock()
+			changed := st.HasImageHashChanged(svc.Name, img, currentHash)
+			stateMutex.Unlock()
+			if changed {
+				progress.Verbose("Image '%s' changed (state file), but external cluster - skipping auto-load", img)
+			} else {
+				progress.Verbose("Image '%s' unchanged (hash matches state), skipping", img)
+			}
+			continue
+		}
+
+		// Kind cluster - compare with the copies on the nodes the image goes to
+		if !placed {
+			placement, placed = queue.imagePlacement(ctx, svc), true
+		}
+		imageState, err := kindMgr.NodesImageState(ctx, cfg.Cluster.Name, img, imageNodes(placement, img))
+		switch {
+		case err != nil:
+			progress.Verbose("Warning: failed to compare image '%s' with the cluster: %v", img, err)
+			imagesToLoad = append(imagesToLoad, img)
+		case imageState == cluster.ImageMissing:
+			progress.Verbose("Image '%s' missing from a node it runs on, will load", img)
+			imagesToLoad = append(imagesToLoad, img)
+		case imageState == cluster.ImageStale:
+			progress.Verbose("Image '%s' changed (a node has an older version), will reload", img)
+			imagesToLoad = append(imagesToLoad, img)
+			imagesToRemove = append(imagesToRemove, img) // Remove old image before loading new one
+		default:
+			progress.Verbose("Image '%s' unchanged (every node it runs on has it), skipping load", img)
+		}
+	}
+
+	if len(imagesToLoad) == 0 {
+		if len(localImages) > 0 {
+			progress.Verbose("All %d local image(s) already loaded (hashes match)", len(localImages))
+		}
+		return imageResult{images: serviceImages, hashes: imageHashes}
+	}
+
+	// Lock for Docker operations that change the cluster (untag/save/load)
+	dockerMutex.Lock()
+	defer dockerMutex.Unlock()
+
+	// Untag old images that have changed (must be done before loading)
+	// This removes the tag reference but leaves the image data so running pods aren't affected
+	for _, img := range imagesToRemove {
+		if queue.loadedThisRun(img, imageHashes[img]) {
+			continue
+		}
+		progress.Verbose("Untagging old version of image '%s'...", img)
+		if err := kindMgr.UntagImage(ctx, cfg.Cluster.Name, img); err != nil {
+			progress.Verbose("Warning: failed to untag old image '%s': %v", img, err)
+			progress.Verbose("  This may cause the tag to point to the old image even after loading the new one")
+		} else {
+			progress.Verbose("%s Old tag removed for '%s' (image data kept for running pods)", color.Checkmark(), img)
+		}
+	}
+
+	// Load images that need to be loaded, onto the nodes their pods can run on
+	report(fmt.Sprintf("Loading %d image(s)", len(imagesToLoad)))
+	if !placed {
+		placement = queue.imagePlacement(ctx, svc)
+	}
+	var loadedBytes int64
+	for _, batch := range batchImagesByNodes(imagesToLoad, placement, len(queue.nodes), func(img string) bool {
+		return queue.loadedThisRun(img, imageHashes[img])
+	}) {
+		target := "every node"
+		if batch.nodeNames != nil {
+			target = strings.Join(batch.nodeNames, ", ")
+		}
+		progress.Verbose("Loading image(s) %s onto %s...", strings.Join(batch.images, ", "), target)
+
+		// One save of all the images writes their shared layers once; if it
+		// fails, load them one at a time so one bad image doesn't block the rest
+		loaded := batch.images
+		if err := kindMgr.LoadImagesToNodes(ctx, cfg.Cluster.Name, batch.images, batch.nodeNames); err != nil {
+			if len(batch.images) == 1 {
+				progress.Verbose("Warning: failed to load image '%s': %v", batch.images[0], err)
+				continue
+			}
+			progress.Verbose("Warning: failed to load images together, loading them one at a time: %v", err)
+			loaded = nil
+			for _, img := range batch.images {
+				if err := kindMgr.LoadImageToNodes(ctx, cfg.Cluster.Name, img, batch.nodeNames); err != nil {
+					progress.Verbose("Warning: failed to load image '%s': %v", img, err)
+					continue
+				}
+				loaded = append(loaded, img)
+			}
+		}
+
+		for _, img := range loaded {
+			progress.Verbose("%s Image '%s' loaded", color.Checkmark(), img)
+			if batch.nodeNames == nil {
+				// Another service may need the image on other nodes
+				queue.markLoaded(img, imageHashes[img])
+			}
+			loadedBytes += imageSizes[img]
+		}
+	}
+	progress.Verbose("%s Images loaded successfully", color.Checkmark())
+
+	return imageResult{images: serviceImages, hashes: imageHashes, bytes: loadedBytes}
+}
+
+// imageBatch is a set of images loaded onto the same nodes (nil for every node)
+type imageBatch struct {
+	images    []string
+	nodeNames []string
+}
+
+// batchImagesByNodes groups images by the nodes they're loaded onto, so each
+// group can be saved once. Images already loaded this run are skipped.
+func batchImagesByNodes(images []string, placement map[string][]string, clusterNodes int, loaded func(string) bool) []imageBatch {
+	var batches []imageBatch
+	batchIndex := make(map[string]int)
+	for _, img := range images {
+		if loaded(img) {
+			continue
+		}
+		nodeNames := imageNodes(placement, img)
+		if len(nodeNames) == 0 || len(nodeNames) >= clusterNodes {
+			nodeNames = nil
+		}
+		key := strings.Join(nodeNames, ",")
+		if idx, exists := batchIndex[key]; exists {
+			batches[idx].images = append(batches[idx].images, img)
+			continue
+		}
+		batchIndex[key] = len(batches)
+		batches = append(batches, imageBatch{images: []string{img}, nodeNames: nodeNames})
+	}
+	return batches
+}
+
+// imagePlacement maps a service's images to the nodes its pods can be
+// scheduled on. Returns nil, meaning every node, on single-node clusters or
+// when the service can't be rendered.
+func (queue *imageQueue) imagePlacement(ctx context.Context, svc *config.ServiceConfig) map[string][]string {
+	queue.nodesOnce.Do(func() {
+		nodes, err := listClusterNodes(ctx, queue.kubeconfig)
+		if err != nil {
+			queue.progress.Verbose("Warning: loading images onto every node: %v", err)
+			return
+		}
+		if len(nodes) > 1 {
+			queue.nodes = nodes
+		}
+	})
+	if len(queue.nodes) == 0 {
+		return nil
+	}
+
+	placement, err := serviceImagePlacement(ctx, queue.cfg, queue.kubeconfig, svc, queue.nodes)
+	if err != nil {
+		queue.progress.Verbose("Warning: loading images of '%s' onto every node: %v", svc.Name, err)
+		return nil
+	}
+	return placement
+}
+
+// loadedThisRun reports whether an image with this hash was already loaded by another service
+func (queue *imageQueue) loadedThisRun(img, hash string) bool {
+	queue.loadedMu.Lock()
+	defer queue.loadedMu.Unlock()
+	loadedHash, exists := queue.loaded[img]
+	return exists && loadedHash == hash
+}
+
+// markLoaded records that an image was loaded during this run
+func (queue *imageQueue) markLoaded(img, hash string) {
+	queue.loadedMu.Lock()
+	defer queue.loadedMu.Unlock()
+	queue.loaded[img] = hash
+}
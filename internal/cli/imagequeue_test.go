@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/ui"
+)
+
+func TestImageQueue(test *testing.T) {
+	services := []*config.ServiceConfig{{Name: "slow"}, {Name: "api"}, {Name: "web"}}
+	queue := newImageQueue(services, &config.Config{}, "", nil, nil, nil, ui.NewProgressManager(false, true, 1))
+
+	release := make(chan struct{})
+	var calls sync.Map
+	var workerJobs atomic.Int32
+	queue.prepare = func(ctx context.Context, svc *config.ServiceConfig, report func(string)) imageResult {
+		count, _ := calls.LoadOrStore(svc.Name, new(atomic.Int32))
+		count.(*atomic.Int32).Add(1)
+		if svc.Name == "slow" {
+			// Hold the worker, like a large docker save
+			workerJobs.Add(1)
+			<-release
+		}
+		return imageResult{images: []string{svc.Name + ":latest"}}
+	}
+
+	queue.Start(context.Background())
+	defer queue.Stop()
+
+	// Wait until the worker is busy with the first job
+	for workerJobs.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// A later service steals its own job instead of queueing behind the slow one
+	got := queue.Await(context.Background(), services[1], func(string) {})
+	if got.err != nil || len(got.images) != 1 || got.images[0] != "api:latest" {
+		test.Fatalf("Await(api) = %+v", got)
+	}
+
+	close(release)
+	for _, svc := range services {
+		if got := queue.Await(context.Background(), svc, func(string) {}); got.err != nil || got.images[0] != svc.Name+":latest" {
+			test.Errorf("Await(%s) = %+v", svc.Name, got)
+		}
+	}
+
+	queue.Stop()
+	for _, svc := range services {
+		count, _ := calls.Load(svc.Name)
+		if count == nil || count.(*atomic.Int32).Load() != 1 {
+			test.Errorf("prepare(%s) ran %v times, want once", svc.Name, count)
+		}
+	}
+}
+
+func TestImageQueueAwaitCancelled(test *testing.T) {
+	services := []*config.ServiceConfig{{Name: "slow"}}
+	queue := newImageQueue(services, &config.Config{}, "", nil, nil, nil, ui.NewProgressManager(false, true, 1))
+
+	started := make(chan struct{})
+	queue.prepare = func(ctx context.Context, svc *config.ServiceConfig, report func(string)) imageResult {
+		close(started)
+		<-ctx.Done()
+		return imageResult{err: ctx.Err()}
+	}
+	queue.Start(context.Background())
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got := queue.Await(ctx, services[0], func(string) {}); got.err == nil {
+		test.Error("Await() with a cancelled context should fail")
+	}
+	queue.Stop()
+}
+
+func TestBatchImagesByNodes(test *testing.T) {
+	placement := map[string][]string{
+		"api:dev":    {"worker", "worker2"},
+		"worker:dev": {"worker", "worker2"},
+		"gpu:dev":    {"worker2"},
+		"web:dev":    {"control-plane", "worker", "worker2"},
+	}
+	images := []string{"api:dev", "gpu:dev", "web:dev", "worker:dev", "cached:dev", "sidecar:dev"}
+	loaded := func(img string) bool { return img == "cached:dev" }
+
+	got := batchImagesByNodes(images, placement, 3, loaded)
+	expected := []imageBatch{
+		{images: []string{"api:dev", "worker:dev"}, nodeNames: []string{"worker", "worker2"}},
+		{images: []string{"gpu:dev"}, nodeNames: []string{"worker2"}},
+		{images: []string{"web:dev", "sidecar:dev"}},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		test.Errorf("batchImagesByNodes() = %+v, want %+v", got, expected)
+	}
+}
@@ -0,0 +1,476 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	imagesOutput   string
+	imagesPullLoad bool
+)
+
+// imageInventory is an image the services use, as printed by 'kraze images -o json|yaml'
+type imageInventory struct {
+	Image    string   `json:"image"`
+	Services []string `json:"services"`
+	Local    bool     `json:"local"`             // In the local daemon
+	Built    bool     `json:"built"`             // Built locally and never pushed to a registry
+	ID       string   `json:"id,omitempty"`      // Image ID in the local daemon
+	Digest   string   `json:"digest,omitempty"`  // Registry digest of the local copy
+	Cluster  string   `json:"cluster,omitempty"` // current, stale, missing or present; empty without a running kind cluster
+}
+
+// needsLoad returns whether the image is local and the cluster lacks its current version
+func (entry *imageInventory) needsLoad() bool {
+	return entry.Local && (entry.Cluster == cluster.ImageStale || entry.Cluster == cluster.ImageMissing)
+}
+
+var imagesCmd = &cobra.Command{
+	Use:   "images [SERVICE...]",
+	Short: "Show the images services use, locally and in the cluster",
+	Long: `List the images the services use (from charts, values, manifests, images and
+build), with the services using each one, whether the image is in the local
+Docker daemon with its ID and registry digest, and its state in the kind cluster:
+
+  current   the nodes have the local version
+  stale     a node has an older version than the local daemon
+  missing   no node has the image yet
+  present   the cluster pulled the image, which isn't local to compare with
+
+Stale and missing local images are what 'kraze images load' loads. Without a
+running kind cluster only the local details are shown.
+
+Examples:
+  kraze images
+  kraze images api worker   # Only the images of these services
+  kraze images -o wide      # Full image IDs
+  kraze images -o json      # Machine-readable listing`,
+	ValidArgsFunction: getServiceNames,
+	RunE:              runImages,
+}
+
+var imagesLoadCmd = &cobra.Command{
+	Use:   "load [SERVICE...]",
+	Short: "Load stale and missing local images into the kind cluster",
+	Long: `Load the local images of the services that the kind cluster doesn't have, or
+has an older version of, onto every node. Old versions are untagged first and
+their data is kept until no pod uses it; 'kraze images prune' removes it.
+
+Examples:
+  kraze images load
+  kraze images load api
+  kraze images load --dry-run   # Show what would be loaded`,
+	ValidArgsFunction: getServiceNames,
+	RunE:              runImagesLoad,
+}
+
+var imagesPullCmd = &cobra.Command{
+	Use:   "pull [SERVICE...]",
+	Short: "Pull the services' registry images into the local daemon",
+	Long: `Pull the images the services use from their registries into the local Docker
+daemon, refreshing moving tags like 'latest'. Images built locally are
+skipped. With --load the images the cluster then lacks, or has an older
+version of, are loaded into it.
+
+Examples:
+  kraze images pull
+  kraze images pull --load   # Pull, then sync the cluster
+  kraze images pull postgres`,
+	ValidArgsFunction: getServiceNames,
+	RunE:              runImagesPull,
+}
+
+var imagesPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove unused images from the kind nodes",
+	Long: `Remove the images in the kind nodes that no container uses and that no
+service references. Every reload of a changed image leaves the old version
+behind untagged, so node disks fill up over a day of rebuilding; prune frees
+that space. Pinned images and the images kind ships with are always kept.
+
+Examples:
+  kraze images prune
+  kraze images prune --dry-run   # Show what would be removed`,
+	Args: cobra.NoArgs,
+	RunE: runImagesPrune,
+}
+
+func runImages(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	format, err := parseOutputFormat(imagesOutput)
+	if err != nil {
+		return err
+	}
+	cfg, cleanup, err := loadImagesConfig(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	// Local details need Docker, and the cluster state a running kind cluster
+	var kindMgr *cluster.KindManager
+	if err := cluster.CheckDockerAvailable(ctx); err != nil {
+		Verbose("Docker not available, showing referenced images only: %v", err)
+	} else if !cfg.Cluster.IsNone() && !cfg.Cluster.IsExternal() {
+		kindMgr, err = runningKindCluster(ctx, cfg)
+		if err != nil {
+			Verbose("Not comparing with the cluster: %v", err)
+		}
+	}
+
+	inventory, err := imageInventoryFor(ctx, cfg, kindMgr, args)
+	if err != nil {
+		return err
+	}
+
+	if format.isStructured() {
+		return printStructured(format, struct {
+			Cluster string           `json:"cluster"`
+			Images  []imageInventory `json:"images"`
+		}{cfg.Cluster.Name, inventory})
+	}
+
+	idWidth := 12
+	if format == outputWide {
+		idWidth = 71
+	}
+	fmt.Printf("Cluster: %s\n\n", cfg.Cluster.Name)
+	fmt.Printf("%-60s %-6s %-*s %-8s %s\n", "IMAGE", "LOCAL", idWidth, "IMAGE ID", "CLUSTER", "SERVICES")
+	fmt.Println(strings.Repeat("-", 100+idWidth))
+
+	toLoad := 0
+	for _, entry := range inventory {
+		local, id, state := "no", "-", entry.Cluster
+		if entry.Local {
+			local = "yes"
+			id = entry.ID
+			if format != outputWide {
+				id = shortImageID(id)
+			}
+		}
+		if state == "" {
+			state = "-"
+		}
+		if entry.needsLoad() {
+			toLoad++
+		}
+		fmt.Printf("%-60s %-6s %-*s %-8s %s\n", entry.Image, local, idWidth, id, state, strings.Join(entry.Services, ", "))
+	}
+
+	fmt.Printf("\n%d image(s)", len(inventory))
+	if toLoad > 0 {
+		fmt.Printf(", %d to load (run 'kraze images load')", toLoad)
+	}
+	fmt.Println()
+	return nil
+}
+
+func runImagesLoad(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	cfg, cleanup, err := loadImagesConfig(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	kindMgr, err := requireKindCluster(ctx, cfg, "images load")
+	if err != nil {
+		return err
+	}
+	inventory, err := imageInventoryFor(ctx, cfg, kindMgr, args)
+	if err != nil {
+		return err
+	}
+	return loadInventoryImages(ctx, kindMgr, cfg.Cluster.Name, inventory)
+}
+
+func runImagesPull(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	cfg, cleanup, err := loadImagesConfig(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if err := cluster.CheckDockerAvailable(ctx); err != nil {
+		return err
+	}
+	var kindMgr *cluster.KindManager
+	if imagesPullLoad {
+		// Fail before pulling anything when there's nothing to load into
+		if kindMgr, err = requireKindCluster(ctx, cfg, "images pull --load"); err != nil {
+			return err
+		}
+	}
+
+	inventory, err := imageInventoryFor(ctx, cfg, nil, args)
+	if err != nil {
+		return err
+	}
+
+	pullMgr := cluster.NewKindManager()
+	pulled, failed := 0, 0
+	for _, entry := range inventory {
+		if entry.Built {
+			Verbose("Skipping '%s': built locally", entry.Image)
+			continue
+		}
+		if dryRun {
+			fmt.Printf("[DRY RUN] Would pull %s\n", entry.Image)
+			continue
+		}
+		fmt.Printf("Pulling %s...\n", entry.Image)
+		if err := pullMgr.PullImage(ctx, entry.Image, cfg.Registries); err != nil {
+			fmt.Fprintf(os.Stderr, "%s Failed to pull %s: %v\n", color.Warning(), entry.Image, err)
+			failed++
+			continue
+		}
+		pulled++
+	}
+	if !dryRun {
+		fmt.Printf("\n%s Pulled %d image(s)\n", color.Checkmark(), pulled)
+	}
+
+	if imagesPullLoad {
+		if inventory, err = imageInventoryFor(ctx, cfg, kindMgr, args); err != nil {
+			return err
+		}
+		if err := loadInventoryImages(ctx, kindMgr, cfg.Cluster.Name, inventory); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to pull %d image(s)", failed)
+	}
+	return nil
+}
+
+func runImagesPrune(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	cfg, cleanup, err := loadImagesConfig(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	kindMgr, err := requireKindCluster(ctx, cfg, "images prune")
+	if err != nil {
+		return err
+	}
+
+	// Images of disabled services are kept too, so enabling one again doesn't
+	// need a reload
+	imgMgr := cluster.NewImageManager(verbose)
+	referenced, _, err := referencedImages(ctx, cfg, imgMgr, cfg.GetAllServiceNames())
+	if err != nil {
+		return err
+	}
+	keep := make([]string, 0, len(referenced))
+	for _, entry := range referenced {
+		keep = append(keep, entry.Image)
+	}
+
+	if !dryRun {
+		unlock, err := lockCluster(ctx, cfg.Cluster.Name, "images prune", waitForLock)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		recordClusterActivity(cfg.Cluster.Name)
+	}
+
+	pruned, err := kindMgr.PruneImages(ctx, cfg.Cluster.Name, keep, dryRun)
+	var freed int64
+	for _, image := range pruned {
+		name := "<none>"
+		if len(image.RepoTags) > 0 {
+			name = strings.Join(image.RepoTags, ", ")
+		}
+		if dryRun {
+			fmt.Printf("[DRY RUN] Would remove %s %s from %s (%s)\n", shortImageID(image.ID), name, image.Node, formatImageSize(fmt.Sprint(image.Size)))
+		} else {
+			Verbose("Removed %s %s from %s", shortImageID(image.ID), name, image.Node)
+		}
+		freed += image.Size
+	}
+	if err != nil {
+		return fmt.Errorf("failed to prune images: %w", err)
+	}
+
+	if dryRun {
+		fmt.Printf("\n[DRY RUN] Would remove %d image(s), freeing %s\n", len(pruned), formatImageSize(fmt.Sprint(freed)))
+		return nil
+	}
+	fmt.Printf("%s Removed %d unused image(s) from cluster '%s', freeing %s\n", color.Checkmark(), len(pruned), cfg.Cluster.Name, formatImageSize(fmt.Sprint(freed)))
+	return nil
+}
+
+// loadImagesConfig parses the config of an images command
+func loadImagesConfig(cmd *cobra.Command) (*config.Config, func(), error) {
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		cleanupPack()
+		return nil, nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	return cfg, cleanupPack, nil
+}
+
+// requireKindCluster checks that the config's kind cluster exists and runs,
+// for commands that change the images in its nodes
+func requireKindCluster(ctx context.Context, cfg *config.Config, command string) (*cluster.KindManager, error) {
+	if err := requireCluster(cfg, command); err != nil {
+		return nil, err
+	}
+	if cfg.Cluster.IsExternal() {
+		return nil, fmt.Errorf("'kraze %s' only works with kind clusters, since images can't be loaded into external clusters", command)
+	}
+	if err := cluster.CheckDockerAvailable(ctx); err != nil {
+		return nil, err
+	}
+	return runningKindCluster(ctx, cfg)
+}
+
+// runningKindCluster returns a kind manager for the config's cluster once it
+// has checked that the cluster exists and is healthy
+func runningKindCluster(ctx context.Context, cfg *config.Config) (*cluster.KindManager, error) {
+	kindMgr := cluster.NewKindManager()
+	exists, err := kindMgr.ClusterExists(cfg.Cluster.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check cluster: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("cluster '%s' does not exist. Run 'kraze up' first", cfg.Cluster.Name)
+	}
+	if err := checkClusterHealth(ctx, kindMgr, &cfg.Cluster); err != nil {
+		return nil, err
+	}
+	return kindMgr, nil
+}
+
+// imageInventoryFor returns the images of the named services (every enabled
+// service when there are none) with their local details, and their state in
+// the cluster when kindMgr is set. Without Docker only the references are filled in.
+func imageInventoryFor(ctx context.Context, cfg *config.Config, kindMgr *cluster.KindManager, names []string) ([]imageInventory, error) {
+	imgMgr := cluster.NewImageManager(verbose)
+	referenced, _, err := referencedImages(ctx, cfg, imgMgr, names)
+	if err != nil {
+		return nil, err
+	}
+	dockerAvailable := cluster.CheckDockerAvailable(ctx) == nil
+
+	inventory := make([]imageInventory, 0, len(referenced))
+	for _, ref := range referenced {
+		entry := imageInventory{Image: ref.Image, Services: ref.Services}
+		if dockerAvailable {
+			info, err := imgMgr.GetImageInfo(ctx, ref.Image)
+			if err != nil {
+				return nil, fmt.Errorf("failed to inspect image '%s': %w", ref.Image, err)
+			}
+			entry.Local, entry.Built, entry.ID = info.InLocalDaemon, info.IsLocal, info.ID
+			if info.InLocalDaemon && !info.IsLocal {
+				entry.Digest = info.SHA256
+			}
+		}
+		if kindMgr != nil {
+			if entry.Cluster, err = kindMgr.ClusterImageState(ctx, cfg.Cluster.Name, ref.Image); err != nil {
+				return nil, fmt.Errorf("failed to check image '%s' in cluster: %w", ref.Image, err)
+			}
+		}
+		inventory = append(inventory, entry)
+	}
+	return inventory, nil
+}
+
+// loadInventoryImages loads the local images the cluster lacks or has an
+// older version of, untagging the old versions first
+func loadInventoryImages(ctx context.Context, kindMgr *cluster.KindManager, clusterName string, inventory []imageInventory) error {
+	var toLoad []string
+	for _, entry := range inventory {
+		if entry.needsLoad() {
+			toLoad = append(toLoad, entry.Image)
+		}
+	}
+	if len(toLoad) == 0 {
+		fmt.Printf("%s Cluster '%s' has the current version of every local image\n", color.Checkmark(), clusterName)
+		return nil
+	}
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would load %d image(s): %s\n", len(toLoad), strings.Join(toLoad, ", "))
+		return nil
+	}
+
+	unlock, err := lockCluster(ctx, clusterName, "images load", waitForLock)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	recordClusterActivity(clusterName)
+
+	for _, entry := range inventory {
+		if entry.Local && entry.Cluster == cluster.ImageStale {
+			Verbose("Untagging old version of image '%s'...", entry.Image)
+			if err := kindMgr.UntagImage(ctx, clusterName, entry.Image); err != nil {
+				fmt.Fprintf(os.Stderr, "%s Failed to untag old image '%s': %v\n", color.Warning(), entry.Image, err)
+			}
+		}
+	}
+
+	// One save of all the images writes their shared layers once; if it
+	// fails, load them one at a time so one bad image doesn't block the rest
+	fmt.Printf("Loading image(s) %s...\n", strings.Join(toLoad, ", "))
+	if err := kindMgr.LoadImages(ctx, clusterName, toLoad); err != nil {
+		if len(toLoad) == 1 {
+			return fmt.Errorf("failed to load image: %w", err)
+		}
+		Verbose("Failed to load images together, loading them one at a time: %v", err)
+		var errs []error
+		for _, image := range toLoad {
+			if err := kindMgr.LoadImage(ctx, clusterName, image); err != nil {
+				errs = append(errs, fmt.Errorf("failed to load image '%s': %w", image, err))
+			}
+		}
+		if len(errs) > 0 {
+			return errors.Join(errs...)
+		}
+	}
+
+	fmt.Printf("%s Loaded %d image(s) into cluster '%s'\n", color.Checkmark(), len(toLoad), clusterName)
+	return nil
+}
+
+// shortImageID shortens an image ID to the 12 characters Docker shows
+func shortImageID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+func init() {
+	addOutputFlag(imagesCmd, &imagesOutput)
+	imagesPullCmd.Flags().BoolVar(&imagesPullLoad, "load", false, "Load the images the cluster then lacks or has an older version of")
+	addLockFlag(imagesLoadCmd)
+	addLockFlag(imagesPullCmd)
+	addLockFlag(imagesPruneCmd)
+	imagesCmd.AddCommand(imagesLoadCmd)
+	imagesCmd.AddCommand(imagesPullCmd)
+	imagesCmd.AddCommand(imagesPruneCmd)
+}
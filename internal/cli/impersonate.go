@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/spf13/cobra"
+)
+
+// Identity to install and uninstall services as, overriding cluster.impersonate
+var (
+	impersonateUser   string
+	impersonateGroups []string
+)
+
+// addImpersonationFlags adds kubectl-style --as/--as-group to a command
+func addImpersonationFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&impersonateUser, "as", "", "User or service account (system:serviceaccount:<namespace>:<name>) to act as, overriding cluster.impersonate")
+	cmd.Flags().StringArrayVar(&impersonateGroups, "as-group", []string{}, "Group to act as (can be specified multiple times, requires --as or cluster.impersonate)")
+}
+
+// impersonatedKubeconfig returns the kubeconfig services are installed with: the
+// cluster kubeconfig acting as --as/--as-group or cluster.impersonate. Cluster
+// state is still read and written with the caller's own identity.
+func impersonatedKubeconfig(kubeconfig string, clusterCfg *config.ClusterConfig) (string, error) {
+	user := impersonateUser
+	groups := impersonateGroups
+	if user == "" {
+		user = clusterCfg.Impersonate.UserName()
+		if len(groups) == 0 && clusterCfg.Impersonate != nil {
+			groups = clusterCfg.Impersonate.Groups
+		}
+	}
+	if user == "" {
+		if len(groups) > 0 {
+			return "", fmt.Errorf("--as-group requires --as or cluster.impersonate")
+		}
+		return kubeconfig, nil
+	}
+
+	impersonated, err := providers.ImpersonateKubeconfig(kubeconfig, user, groups)
+	if err != nil {
+		return "", fmt.Errorf("failed to impersonate '%s': %w", user, err)
+	}
+	message := "Acting as " + user
+	if len(groups) > 0 {
+		message += fmt.Sprintf(" (groups: %s)", strings.Join(groups, ", "))
+	}
+	fmt.Println(color.Info(message))
+	return impersonated, nil
+}
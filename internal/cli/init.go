@@ -42,10 +42,14 @@ For external clusters (cluster.external.enabled: true):
 		if err != nil {
 			return fmt.Errorf("failed to parse config: %w", err)
 		}
+		if err := requireCluster(cfg, "init"); err != nil {
+			return err
+		}
 		Verbose("Configuration parsed successfully")
 
 		isExternal := cfg.Cluster.IsExternal()
 		kindMgr := cluster.NewKindManager()
+		kindMgr.UseRegistries(cfg.Registries)
 
 		if isExternal {
 			// External cluster mode
@@ -114,15 +118,20 @@ For external clusters (cluster.external.enabled: true):
 		if len(cfg.Cluster.PreloadImages) > 0 {
 			fmt.Printf("\nPreloading %d image(s) into cluster...\n", len(cfg.Cluster.PreloadImages))
 
-			for itr, image := range cfg.Cluster.PreloadImages {
-				fmt.Printf("[%d/%d] Loading image '%s'...\n", itr+1, len(cfg.Cluster.PreloadImages), image)
-
-				if err := kindMgr.LoadImage(ctx, cfg.Cluster.Name, image); err != nil {
-					// Don't fail cluster creation if image loading fails
-					fmt.Printf("Warning: failed to load image '%s': %v\n", image, err)
-					fmt.Printf("  You can load it later with: kraze load-image %s\n", image)
-				} else {
-					fmt.Printf("%s Image '%s' loaded successfully\n", color.Checkmark(), image)
+			// Save the images together so layers they share are transferred once,
+			// falling back to one at a time so one missing image doesn't block the rest
+			if err := kindMgr.LoadImages(ctx, cfg.Cluster.Name, cfg.Cluster.PreloadImages); err != nil {
+				Verbose("Failed to load images together, loading them one at a time: %v", err)
+				for itr, image := range cfg.Cluster.PreloadImages {
+					fmt.Printf("[%d/%d] Loading image '%s'...\n", itr+1, len(cfg.Cluster.PreloadImages), image)
+
+					if err := kindMgr.LoadImage(ctx, cfg.Cluster.Name, image); err != nil {
+						// Don't fail cluster creation if image loading fails
+						fmt.Printf("Warning: failed to load image '%s': %v\n", image, err)
+						fmt.Printf("  You can load it later with: kraze load-image %s\n", image)
+					} else {
+						fmt.Printf("%s Image '%s' loaded successfully\n", color.Checkmark(), image)
+					}
 				}
 			}
 
@@ -147,8 +156,7 @@ For external clusters (cluster.external.enabled: true):
 		}
 
 		// Create Kubernetes clientset from kubeconfig content
-		// Only skip TLS verification for kind clusters (not external clusters)
-		clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig, !isExternal)
+		clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig)
 		if err != nil {
 			return fmt.Errorf("failed to create Kubernetes client: %w", err)
 		}
@@ -158,10 +166,15 @@ For external clusters (cluster.external.enabled: true):
 		amdEnabled := cfg.Cluster.GPU.IsAMDEnabled()
 		st := state.New(cfg.Cluster.Name, isExternal, nvidiaEnabled, 0, amdEnabled, 0)
 		st.SetConfigPaths(cfgPaths)
+		st.UseBackend(cfg.StateBackend)
 		if err := st.Save(ctx, clientset); err != nil {
 			return fmt.Errorf("failed to save cluster state: %w", err)
 		}
-		Verbose("Cluster state ConfigMap created in kube-system namespace")
+		if st.Backend() == state.BackendCRD {
+			Verbose("Cluster state %s created", state.EnvironmentKind)
+		} else {
+			Verbose("Cluster state ConfigMap created in kube-system namespace")
+		}
 
 		if isExternal {
 			fmt.Printf("\n%s External cluster initialized successfully\n", color.Checkmark())
@@ -0,0 +1,318 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/hjames9/kraze/internal/state"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	listLabels []string
+	listOutput string
+)
+
+// listReport is the output of 'kraze list -o json|yaml'
+type listReport struct {
+	Cluster  string           `json:"cluster"`
+	Services []serviceListing `json:"services"`
+}
+
+// serviceListing describes one configured service
+type serviceListing struct {
+	Name        string            `json:"name"`
+	Type        string            `json:"type"`
+	Namespace   string            `json:"namespace"`
+	Enabled     bool              `json:"enabled"`
+	Installed   *bool             `json:"installed,omitempty"` // Unset when the cluster state could not be read
+	DependsOn   []string          `json:"depends_on,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Owner       string            `json:"owner,omitempty"`
+	Links       []string          `json:"links,omitempty"`
+	ImageHashes map[string]string `json:"image_hashes,omitempty"`
+}
+
+var listCmd = &cobra.Command{
+	Use:     "list [services...]",
+	Aliases: []string{"ls"},
+	Short:   "List services defined in the configuration",
+	Long: `List all services defined in kraze.yml with their type, namespace, labels,
+dependencies and enabled status.
+
+Unlike 'kraze status', this command does not query the resources of each
+service. The INSTALLED column is read from the cluster state when the cluster
+is reachable, and shows '-' otherwise.
+
+Examples:
+  kraze list                        # List all services
+  kraze list redis postgres         # List specific services
+  kraze list --label tier=backend   # List services with label tier=backend
+  kraze list -o json                # Machine-readable listing`,
+	ValidArgsFunction: getServiceNames,
+	RunE:              runList,
+}
+
+func runList(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	format, err := parseOutputFormat(listOutput)
+	if err != nil {
+		return err
+	}
+
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanupPack()
+	Verbose("Listing services from config file(s): %s", strings.Join(cfgPaths, ", "))
+
+	// Parse configuration
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := requireCluster(cfg, "list"); err != nil {
+		return err
+	}
+
+	// Check if both service names and labels are specified
+	if len(args) > 0 && len(listLabels) > 0 {
+		return fmt.Errorf("cannot specify both service names and labels, use one or the other")
+	}
+
+	if len(listLabels) > 0 {
+		Verbose("Filtering services by labels: %v", listLabels)
+		filteredServices, err := cfg.FilterServicesByLabels(listLabels)
+		if err != nil {
+			return fmt.Errorf("failed to filter services by labels: %w", err)
+		}
+		cfg.Services = filteredServices
+	} else if len(args) > 0 {
+		Verbose("Filtering services: %v", args)
+		filteredServices, err := cfg.FilterServices(args)
+		if err != nil {
+			return fmt.Errorf("failed to filter services: %w", err)
+		}
+		cfg.Services = filteredServices
+	}
+
+	// Installed status is informational, so state loading is best-effort
+	st := loadStateForListing(ctx, cfg)
+
+	report := listReport{Cluster: cfg.Cluster.Name, Services: []serviceListing{}}
+	for _, name := range sortedServiceNames(cfg.Services) {
+		svc := cfg.Services[name]
+		entry := serviceListing{
+			Name:        name,
+			Type:        svc.Type,
+			Namespace:   svc.GetNamespace(),
+			Enabled:     svc.IsEnabled(),
+			DependsOn:   svc.DependsOn.Names(),
+			Labels:      svc.Labels,
+			Description: svc.Description,
+			Owner:       svc.Owner,
+			Links:       svc.Links,
+		}
+		if st != nil {
+			installed := st.IsServiceInstalled(name)
+			entry.Installed = &installed
+			entry.ImageHashes = st.Services[name].ImageHashes
+		}
+		report.Services = append(report.Services, entry)
+	}
+
+	if format.isStructured() {
+		return printStructured(format, report)
+	}
+
+	fmt.Printf("Cluster: %s\n\n", cfg.Cluster.Name)
+	printServiceListing(report.Services, format == outputWide)
+
+	enabledCount := 0
+	installedCount := 0
+	for _, entry := range report.Services {
+		if entry.Enabled {
+			enabledCount++
+		}
+		if entry.Installed != nil && *entry.Installed {
+			installedCount++
+		}
+	}
+
+	fmt.Println()
+	if st != nil {
+		fmt.Printf("Summary: %d service(s), %d enabled, %d installed\n", len(cfg.Services), enabledCount, installedCount)
+	} else {
+		fmt.Printf("Summary: %d service(s), %d enabled\n", len(cfg.Services), enabledCount)
+	}
+
+	return nil
+}
+
+// printServiceListing prints the services table; wide adds the owner and the
+// number of images loaded for each service
+func printServiceListing(services []serviceListing, wide bool) {
+	if wide {
+		fmt.Printf("%-20s %-10s %-15s %-8s %-10s %-20s %-25s %-20s %-7s %s\n",
+			"SERVICE", "TYPE", "NAMESPACE", "ENABLED", "INSTALLED", "DEPENDS ON", "LABELS", "OWNER", "IMAGES", "DESCRIPTION")
+		fmt.Println("----------------------------------------------------------------------------------------------------------------------------------------------------")
+	} else {
+		fmt.Printf("%-20s %-10s %-15s %-8s %-10s %-20s %-25s %s\n",
+			"SERVICE", "TYPE", "NAMESPACE", "ENABLED", "INSTALLED", "DEPENDS ON", "LABELS", "DESCRIPTION")
+		fmt.Println("--------------------------------------------------------------------------------------------------------------------")
+	}
+
+	for _, entry := range services {
+		enabledStr := "No"
+		if entry.Enabled {
+			enabledStr = "Yes"
+		}
+
+		installedStr := "-"
+		if entry.Installed != nil {
+			installedStr = "No"
+			if *entry.Installed {
+				installedStr = "Yes"
+			}
+		}
+
+		summary := (&config.ServiceConfig{Description: entry.Description}).GetSummary()
+		if wide {
+			owner := entry.Owner
+			if owner == "" {
+				owner = "-"
+			}
+			images := "-"
+			if len(entry.ImageHashes) > 0 {
+				images = fmt.Sprintf("%d", len(entry.ImageHashes))
+			}
+			fmt.Printf("%-20s %-10s %-15s %-8s %-10s %-20s %-25s %-20s %-7s %s\n",
+				entry.Name, entry.Type, entry.Namespace, enabledStr, installedStr,
+				formatListValue(entry.DependsOn), formatLabels(entry.Labels), owner, images, summary)
+		} else {
+			fmt.Printf("%-20s %-10s %-15s %-8s %-10s %-20s %-25s %s\n",
+				entry.Name, entry.Type, entry.Namespace, enabledStr, installedStr,
+				formatListValue(entry.DependsOn), formatLabels(entry.Labels), summary)
+		}
+
+		if verbose {
+			if entry.Owner != "" {
+				fmt.Printf("  owner: %s\n", entry.Owner)
+			}
+			for _, link := range entry.Links {
+				fmt.Printf("  link:  %s\n", link)
+			}
+		}
+	}
+}
+
+// loadStateForListing loads the cluster state if the cluster is reachable.
+// Returns nil if the state cannot be determined.
+func loadStateForListing(ctx context.Context, cfg *config.Config) *state.ClusterState {
+	clientset, exists := clientsetForListing(ctx, cfg)
+	if !exists {
+		Verbose("Cluster '%s' does not exist, no services are installed", cfg.Cluster.Name)
+		return state.New(cfg.Cluster.Name, cfg.Cluster.IsExternal(), false, 0, false, 0)
+	}
+	if clientset == nil {
+		return nil
+	}
+
+	st, err := state.Load(ctx, clientset, cfg.Cluster.Name, cfg.StateBackend)
+	if err != nil {
+		Verbose("Warning: failed to load cluster state: %v", err)
+		return nil
+	}
+	if st == nil {
+		// ConfigMap doesn't exist yet (Load returns nil, nil in this case)
+		st = state.New(cfg.Cluster.Name, cfg.Cluster.IsExternal(), false, 0, false, 0)
+	}
+
+	return st
+}
+
+// clientsetForListing connects to the cluster for read-only listings. It
+// returns false if the kind cluster doesn't exist, and a nil clientset if the
+// cluster can't be reached.
+func clientsetForListing(ctx context.Context, cfg *config.Config) (kubernetes.Interface, bool) {
+	kindMgr := cluster.NewKindManager()
+
+	var kubeconfig string
+	var err error
+	if cfg.Cluster.IsExternal() {
+		kubeconfig, err = kindMgr.GetKubeconfigForExternalCluster(&cfg.Cluster)
+		if err != nil {
+			Verbose("Warning: failed to get kubeconfig for external cluster: %v", err)
+			return nil, true
+		}
+	} else {
+		if err := cluster.CheckDockerAvailable(ctx); err != nil {
+			Verbose("Docker is not available, skipping installed status: %v", err)
+			return nil, true
+		}
+
+		exists, err := kindMgr.ClusterExists(cfg.Cluster.Name)
+		if err != nil {
+			Verbose("Warning: failed to check cluster: %v", err)
+			return nil, true
+		}
+		if !exists {
+			return nil, false
+		}
+
+		if err := checkClusterHealth(ctx, kindMgr, &cfg.Cluster); err != nil {
+			Verbose("Warning: %v", err)
+			return nil, true
+		}
+		kubeconfig, err = kindMgr.GetKubeConfig(cfg.Cluster.Name, false)
+		if err != nil {
+			Verbose("Warning: failed to get kubeconfig: %v", err)
+			return nil, true
+		}
+	}
+
+	clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig)
+	if err != nil {
+		Verbose("Warning: failed to create Kubernetes client: %v", err)
+		return nil, true
+	}
+	return clientset, true
+}
+
+// formatLabels formats labels as a sorted, comma-separated list of key=value pairs
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return "-"
+	}
+
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+// formatListValue formats a string slice as a comma-separated list, or "-" if empty
+func formatListValue(values []string) string {
+	if len(values) == 0 {
+		return "-"
+	}
+	return strings.Join(values, ",")
+}
+
+func init() {
+	addOutputFlag(listCmd, &listOutput)
+	listCmd.Flags().StringSliceVarP(&listLabels, "label", "l", []string{}, "Filter services by label (format: key=value, can be specified multiple times)")
+	registerLabelCompletion(listCmd)
+}
@@ -3,6 +3,7 @@ package cli
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/hjames9/kraze/internal/cluster"
@@ -10,21 +11,41 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var listImagesOutput string
+
+// imageListing is an image loaded in the cluster, as printed by 'kraze list-images -o json|yaml'
+type imageListing struct {
+	ID        string   `json:"id"`
+	Tags      []string `json:"tags"`
+	SizeBytes int64    `json:"size_bytes"`
+}
+
 var listImagesCmd = &cobra.Command{
-	Use:     "list-images",
-	Aliases: []string{"images"},
-	Short:   "List images loaded in the kind cluster",
+	Use:   "list-images",
+	Short: "List images loaded in the kind cluster",
 	Long: `Display all Docker images currently loaded in the kind cluster nodes.
 
+With cluster.none set, lists the images the services reference instead
+(from charts, values, manifests, images and build), without Docker or a cluster.
+
 Examples:
   kraze list-images
-  kraze images`,
+  kraze list-images -o wide   # Full image IDs
+  kraze list-images -o json   # Machine-readable listing
+
+See 'kraze images' for the images the services use and whether the
+cluster has their current version.`,
 	RunE: runListImages,
 }
 
 func runListImages(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
+	format, err := parseOutputFormat(listImagesOutput)
+	if err != nil {
+		return err
+	}
+
 	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
 	if err != nil {
 		return err
@@ -36,6 +57,10 @@ func runListImages(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to parse config: %w", err)
 	}
 
+	if cfg.Cluster.IsNone() {
+		return listConfigImages(ctx, cfg, format)
+	}
+
 	if cfg.Cluster.IsExternal() {
 		return fmt.Errorf("list-images is only supported for kind clusters, not external clusters")
 	}
@@ -59,26 +84,47 @@ func runListImages(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to list images: %w", err)
 	}
 
+	if format.isStructured() {
+		listing := make([]imageListing, 0, len(images))
+		for _, img := range images {
+			entry := imageListing{ID: img.ID, Tags: img.RepoTags}
+			if entry.Tags == nil {
+				entry.Tags = []string{}
+			}
+			fmt.Sscanf(img.Size, "%d", &entry.SizeBytes)
+			listing = append(listing, entry)
+		}
+		return printStructured(format, struct {
+			Cluster string         `json:"cluster"`
+			Images  []imageListing `json:"images"`
+		}{cfg.Cluster.Name, listing})
+	}
+
+	idWidth := 20
+	if format == outputWide {
+		idWidth = 72
+	}
+
 	fmt.Printf("Cluster: %s\n\n", cfg.Cluster.Name)
-	fmt.Printf("%-72s %-20s %s\n", "IMAGE", "IMAGE ID", "SIZE")
-	fmt.Println(strings.Repeat("-", 100))
+	fmt.Printf("%-72s %-*s %s\n", "IMAGE", idWidth, "IMAGE ID", "SIZE")
+	fmt.Println(strings.Repeat("-", 80+idWidth))
 
 	for _, img := range images {
 		// Truncate image ID for display (keep sha256: prefix + first 12 chars of hash)
 		id := img.ID
-		if strings.HasPrefix(id, "sha256:") && len(id) > 19 {
+		if format != outputWide && strings.HasPrefix(id, "sha256:") && len(id) > 19 {
 			id = id[7:19]
 		}
 
 		size := formatImageSize(img.Size)
 
 		if len(img.RepoTags) == 0 {
-			fmt.Printf("%-72s %-20s %s\n", "<none>", id, size)
+			fmt.Printf("%-72s %-*s %s\n", "<none>", idWidth, id, size)
 			continue
 		}
 		for i, tag := range img.RepoTags {
 			if i == 0 {
-				fmt.Printf("%-72s %-20s %s\n", tag, id, size)
+				fmt.Printf("%-72s %-*s %s\n", tag, idWidth, id, size)
 			} else {
 				fmt.Printf("%-72s\n", tag)
 			}
@@ -89,6 +135,79 @@ func runListImages(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// configImage is an image referenced by the config, as printed by
+// 'kraze list-images -o json|yaml' with cluster.none
+type configImage struct {
+	Image    string   `json:"image"`
+	Services []string `json:"services"`
+}
+
+// listConfigImages lists the images the enabled services reference, for configs without a cluster
+func listConfigImages(ctx context.Context, cfg *config.Config, format outputFormat) error {
+	imgMgr := cluster.NewImageManager(verbose)
+	listing, enabled, err := referencedImages(ctx, cfg, imgMgr, nil)
+	if err != nil {
+		return err
+	}
+
+	if format.isStructured() {
+		if listing == nil {
+			listing = []configImage{}
+		}
+		return printStructured(format, struct {
+			Cluster string        `json:"cluster"`
+			Images  []configImage `json:"images"`
+		}{cfg.Cluster.Name, listing})
+	}
+
+	fmt.Printf("Cluster: %s (cluster.none, images referenced by the config)\n\n", cfg.Cluster.Name)
+	fmt.Printf("%-72s %s\n", "IMAGE", "SERVICES")
+	fmt.Println(strings.Repeat("-", 100))
+	for _, entry := range listing {
+		fmt.Printf("%-72s %s\n", entry.Image, strings.Join(entry.Services, ", "))
+	}
+	fmt.Printf("\n%d image(s) referenced by %d service(s)\n", len(listing), enabled)
+	return nil
+}
+
+// referencedImages returns the images the named services reference (every
+// enabled service when names is empty), sorted, with the services using each
+// one, and the number of services looked at
+func referencedImages(ctx context.Context, cfg *config.Config, imgMgr *cluster.ImageManager, names []string) ([]configImage, int, error) {
+	if len(names) == 0 {
+		for _, name := range cfg.GetAllServiceNames() {
+			if svc := cfg.Services[name]; svc.IsEnabled() {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	var listing []configImage
+	index := make(map[string]int)
+	for _, name := range names {
+		svc, exists := cfg.Services[name]
+		if !exists {
+			return nil, 0, fmt.Errorf("service '%s' not found in configuration", name)
+		}
+		images, err := imgMgr.GetImagesForService(ctx, &svc, "")
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get images for service '%s': %w", name, err)
+		}
+		for _, image := range images {
+			idx, seen := index[image]
+			if !seen {
+				idx = len(listing)
+				index[image] = idx
+				listing = append(listing, configImage{Image: image})
+			}
+			listing[idx].Services = append(listing[idx].Services, name)
+		}
+	}
+	sort.Slice(listing, func(i, j int) bool { return listing[i].Image < listing[j].Image })
+	return listing, len(names), nil
+}
+
 // formatImageSize converts a byte count string to a human-readable size
 func formatImageSize(sizeStr string) string {
 	if sizeStr == "" {
@@ -109,3 +228,7 @@ func formatImageSize(sizeStr string) string {
 		return fmt.Sprintf("%d B", bytes)
 	}
 }
+
+func init() {
+	addOutputFlag(listImagesCmd, &listImagesOutput)
+}
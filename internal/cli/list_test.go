@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"testing"
+)
+
+func TestFormatLabels(test *testing.T) {
+	tests := []struct {
+		name     string
+		labels   map[string]string
+		expected string
+	}{
+		{
+			name:     "no labels",
+			labels:   nil,
+			expected: "-",
+		},
+		{
+			name:     "single label",
+			labels:   map[string]string{"tier": "backend"},
+			expected: "tier=backend",
+		},
+		{
+			name:     "multiple labels are sorted",
+			labels:   map[string]string{"tier": "backend", "env": "dev"},
+			expected: "env=dev,tier=backend",
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			result := formatLabels(tt.labels)
+			if result != tt.expected {
+				test.Errorf("Expected '%s', got '%s'", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestFormatListValue(test *testing.T) {
+	tests := []struct {
+		name     string
+		values   []string
+		expected string
+	}{
+		{
+			name:     "empty",
+			values:   nil,
+			expected: "-",
+		},
+		{
+			name:     "multiple values",
+			values:   []string{"postgres", "redis"},
+			expected: "postgres,redis",
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			result := formatListValue(tt.values)
+			if result != tt.expected {
+				test.Errorf("Expected '%s', got '%s'", tt.expected, result)
+			}
+		})
+	}
+}
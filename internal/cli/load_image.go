@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/hjames9/kraze/internal/cluster"
 	"github.com/hjames9/kraze/internal/color"
@@ -52,6 +53,9 @@ func runLoadImage(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to parse config: %w", err)
 	}
+	if err := requireCluster(cfg, "load-image"); err != nil {
+		return err
+	}
 
 	// Check Docker availability (load-image always requires Docker/kind)
 	Verbose("Checking Docker availability...")
@@ -77,16 +81,12 @@ func runLoadImage(cmd *cobra.Command, args []string) error {
 	if !exists {
 		return fmt.Errorf("cluster '%s' does not exist. Run 'kraze up' first", clusterName)
 	}
+	recordClusterActivity(clusterName)
 
-	// Load each image
-	for _, image := range images {
-		fmt.Printf("Loading image '%s'...\n", image)
-
-		if err := kindMgr.LoadImage(ctx, clusterName, image); err != nil {
-			return fmt.Errorf("failed to load image '%s': %w", image, err)
-		}
-
-		fmt.Printf("%s Image '%s' loaded successfully\n", color.Checkmark(), image)
+	// Save the images together so layers they share are transferred once
+	fmt.Printf("Loading image(s) %s...\n", strings.Join(images, ", "))
+	if err := kindMgr.LoadImages(ctx, clusterName, images); err != nil {
+		return fmt.Errorf("failed to load images: %w", err)
 	}
 
 	fmt.Printf("\n%s Successfully loaded %d image(s) into cluster '%s'\n", color.Checkmark(), len(images), clusterName)
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/spf13/cobra"
+)
+
+// waitForLock makes a command that changes the cluster wait for another one to
+// finish instead of failing
+var waitForLock bool
+
+// heldClusterLocks are the cluster locks this process holds, so a command that
+// runs another (snapshot restore runs up) doesn't wait on itself
+var heldClusterLocks = make(map[string]*cluster.ClusterLock)
+
+// addLockFlag registers --wait-for-lock on a command that changes the cluster
+func addLockFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&waitForLock, "wait-for-lock", false, "Wait for another kraze command changing the cluster to finish instead of failing")
+}
+
+// lockCluster takes the cluster's lock for a command that changes it, waiting for
+// another command holding it with wait set, and returns the function releasing it. Read-only commands (status, logs, list, ...) never
+// take it, so they run alongside.
+func lockCluster(ctx context.Context, clusterName, command string, wait bool) (func(), error) {
+	if _, held := heldClusterLocks[clusterName]; held {
+		return func() {}, nil
+	}
+
+	lock, err := cluster.AcquireClusterLock(ctx, clusterName, command, wait, func(holder *cluster.LockHolder) {
+		if holder != nil {
+			fmt.Fprintf(os.Stderr, "Waiting for %s to finish...\n", holder)
+		} else {
+			fmt.Fprintf(os.Stderr, "Waiting for another kraze command to finish with cluster '%s'...\n", clusterName)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	heldClusterLocks[clusterName] = lock
+	return func() {
+		delete(heldClusterLocks, clusterName)
+		lock.Release()
+	}, nil
+}
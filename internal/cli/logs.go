@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logsFollow     bool
+	logsSince      time.Duration
+	logsTail       int64
+	logsContainer  string
+	logsTimestamps bool
+	logsSelf       bool
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs [SERVICE]",
+	Short: "Show logs from all pods of a service",
+	Long: `Show logs from every container in every pod of a service, interleaved line by
+line and prefixed with [pod/container] (colored per pod).
+
+Pods are found through the Helm release label for helm services, and through
+the workloads kraze labeled for manifests services.
+
+Init containers are included, and with --follow, logs are streamed until
+interrupted: pods created later (for example by a rollout) and restarted
+containers are picked up automatically. As with kubectl, -f is --follow here;
+pass config files with --file.
+
+With --self, kraze's own log of its last run is shown instead. Runs are logged
+to ~/.kraze/logs with --save-log or KRAZE_SAVE_LOG=true; older logs are gzipped
+and the oldest removed once they take more than 50MB.
+
+Examples:
+  kraze logs api                  # All logs from the api service
+  kraze logs api -f               # Stream logs until Ctrl+C
+  kraze logs api --since 10m      # Logs from the last 10 minutes
+  kraze logs api --tail 50        # Last 50 lines per container
+  kraze logs api -c migrate       # Only the migrate container
+  kraze logs --self --tail 100    # The end of kraze's log of its last run`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if logsSelf {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	ValidArgsFunction: getServiceNames,
+	RunE:              runLogs,
+}
+
+func runLogs(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if logsSelf {
+		if logsFollow || logsSince != 0 || logsContainer != "" {
+			return fmt.Errorf("--self can't be combined with --follow, --since or --container")
+		}
+		return printLastRunLog(os.Stdout, logsTail)
+	}
+
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanupPack()
+
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := requireCluster(cfg, "logs"); err != nil {
+		return err
+	}
+
+	svc, exists := cfg.Services[args[0]]
+	if !exists {
+		return fmt.Errorf("service '%s' not found in configuration", args[0])
+	}
+
+	kubeconfig, err := getShellKubeconfig(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+
+	logCtx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	// Following logs counts as activity for the idle auto-stop
+	if logsFollow && !cfg.Cluster.IsExternal() {
+		go keepClusterActive(logCtx, cfg.Cluster.Name)
+	}
+
+	opts := providers.LogOptions{
+		Follow:     logsFollow,
+		Since:      logsSince,
+		Tail:       logsTail,
+		Container:  logsContainer,
+		Timestamps: logsTimestamps,
+	}
+	return providers.StreamServiceLogs(logCtx, clientset, &svc, opts, os.Stdout)
+}
+
+func init() {
+	// -f follows like kubectl logs -f, so the config file flag loses its shorthand here
+	logsCmd.Flags().StringArrayVar(&configFiles, "file", []string{}, "Path to kraze configuration file (can be specified multiple times)")
+	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "Stream logs until interrupted, including pods created later and restarted containers")
+	logsCmd.Flags().DurationVar(&logsSince, "since", 0, "Only show logs newer than this duration (e.g., 30s, 10m, 1h)")
+	logsCmd.Flags().Int64Var(&logsTail, "tail", -1, "Lines to show from the end of each container's log (-1 = all)")
+	logsCmd.Flags().StringVarP(&logsContainer, "container", "c", "", "Only show logs from this container")
+	logsCmd.Flags().BoolVar(&logsTimestamps, "timestamps", false, "Include timestamps on each line")
+	logsCmd.Flags().BoolVar(&logsSelf, "self", false, "Show kraze's own log of its last run (see --save-log)")
+}
@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var manCmd = &cobra.Command{
+	Use:   "man <directory>",
+	Short: "Generate man pages",
+	Long: `Generate a section 1 man page for kraze and each of its commands into a directory,
+from the same help text the commands print. Pages are named like kraze-up.1.
+
+The date in the pages is taken from SOURCE_DATE_EPOCH when set, so packaged pages are
+reproducible. No network access is needed, so it works for air-gapped installs.
+
+Examples:
+  kraze man ./man                     # Generate pages into ./man
+  kraze man ~/.local/share/man/man1   # Install them for the current user (then: man kraze-up)`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMan,
+}
+
+func runMan(cmd *cobra.Command, args []string) error {
+	dir := args[0]
+	date, err := manDate(os.Getenv("SOURCE_DATE_EPOCH"))
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would write %d man page(s) to %s\n", len(manCommands(cmd.Root())), dir)
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	commands := manCommands(cmd.Root())
+	for _, command := range commands {
+		path := filepath.Join(dir, manPageName(command)+".1")
+		if err := os.WriteFile(path, []byte(manPage(command, date)), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		Verbose("Wrote %s", path)
+	}
+
+	fmt.Printf("%s Wrote %d man page(s) to %s\n", color.Checkmark(), len(commands), dir)
+	return nil
+}
+
+// manDate returns the date printed in man pages, from SOURCE_DATE_EPOCH when set
+func manDate(sourceDateEpoch string) (time.Time, error) {
+	if sourceDateEpoch == "" {
+		return time.Now(), nil
+	}
+	seconds, err := strconv.ParseInt(sourceDateEpoch, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH '%s': %w", sourceDateEpoch, err)
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
+
+// manCommands returns a command and its available subcommands, depth first
+func manCommands(cmd *cobra.Command) []*cobra.Command {
+	commands := []*cobra.Command{cmd}
+	for _, child := range cmd.Commands() {
+		if !child.IsAvailableCommand() || child.IsAdditionalHelpTopicCommand() {
+			continue
+		}
+		commands = append(commands, manCommands(child)...)
+	}
+	return commands
+}
+
+// manPageName returns a command's page name, like kraze-completion-install
+func manPageName(cmd *cobra.Command) string {
+	return strings.ReplaceAll(cmd.CommandPath(), " ", "-")
+}
+
+// manPage renders a command's help as a roff man page
+func manPage(cmd *cobra.Command, date time.Time) string {
+	var page strings.Builder
+	name := manPageName(cmd)
+
+	fmt.Fprintf(&page, ".TH %q \"1\" %q \"kraze %s\" \"kraze Manual\"\n",
+		strings.ToUpper(name), date.Format("Jan 2006"), roffEscape(version))
+	fmt.Fprintf(&page, ".SH NAME\n%s \\- %s\n", roffEscape(name), roffEscape(cmd.Short))
+	fmt.Fprintf(&page, ".SH SYNOPSIS\n.B %s\n", roffEscape(cmd.UseLine()))
+
+	description := cmd.Long
+	if description == "" {
+		description = cmd.Short
+	}
+	// Help text is laid out for a terminal, so it keeps its line breaks
+	fmt.Fprintf(&page, ".SH DESCRIPTION\n.nf\n%s\n.fi\n", roffText(description))
+
+	if cmd.HasAvailableLocalFlags() {
+		page.WriteString(".SH OPTIONS\n")
+		writeManFlags(&page, cmd.LocalFlags())
+	}
+	if cmd.HasAvailableInheritedFlags() {
+		page.WriteString(".SH OPTIONS INHERITED FROM PARENT COMMANDS\n")
+		writeManFlags(&page, cmd.InheritedFlags())
+	}
+
+	var related []string
+	if cmd.HasParent() {
+		related = append(related, manPageName(cmd.Parent()))
+	}
+	for _, child := range cmd.Commands() {
+		if child.IsAvailableCommand() && !child.IsAdditionalHelpTopicCommand() {
+			related = append(related, manPageName(child))
+		}
+	}
+	if len(related) > 0 {
+		refs := make([]string, 0, len(related))
+		for _, ref := range related {
+			refs = append(refs, fmt.Sprintf("\\fB%s\\fP(1)", roffEscape(ref)))
+		}
+		fmt.Fprintf(&page, ".SH SEE ALSO\n%s\n", strings.Join(refs, ", "))
+	}
+
+	return page.String()
+}
+
+// writeManFlags writes a tagged paragraph for each visible flag
+func writeManFlags(page *strings.Builder, flags *pflag.FlagSet) {
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if flag.Hidden {
+			return
+		}
+		names := "\\fB\\-\\-" + roffEscape(flag.Name) + "\\fP"
+		if flag.Shorthand != "" && flag.ShorthandDeprecated == "" {
+			names = "\\fB\\-" + flag.Shorthand + "\\fP, " + names
+		}
+		varName, usage := pflag.UnquoteUsage(flag)
+		if varName != "" {
+			names += " \\fI" + roffEscape(varName) + "\\fP"
+		}
+		if flag.DefValue != "" && flag.DefValue != "false" && flag.DefValue != "[]" && flag.DefValue != "0" && flag.DefValue != "0s" {
+			usage += fmt.Sprintf(" (default %s)", flag.DefValue)
+		}
+		fmt.Fprintf(page, ".TP\n%s\n%s\n", names, roffText(usage))
+	})
+}
+
+// roffEscape escapes backslashes and hyphens for roff
+func roffEscape(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\e`)
+	return strings.ReplaceAll(text, "-", `\-`)
+}
+
+// roffText escapes text and keeps lines starting with a dot or quote from
+// being read as roff requests
+func roffText(text string) string {
+	lines := strings.Split(roffEscape(strings.TrimRight(text, "\n")), "\n")
+	for itr, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[itr] = `\&` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
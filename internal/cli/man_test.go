@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestManPage(test *testing.T) {
+	root := &cobra.Command{Use: "kraze", Short: "Local Kubernetes"}
+	root.PersistentFlags().BoolP("verbose", "v", false, "Verbose output")
+	parent := &cobra.Command{Use: "completion", Short: "Generate completion script", Run: func(*cobra.Command, []string) {}}
+	child := &cobra.Command{
+		Use:   "install [shell]",
+		Short: "Install shell completion",
+		Long:  "Install completion.\n.bashrc is updated\n  kraze completion install --dry-run",
+		Run:   func(*cobra.Command, []string) {},
+	}
+	child.Flags().StringP("dir", "d", "~/.zsh", "Where to write the `path`")
+	child.Flags().Bool("hidden-flag", false, "Hidden")
+	child.Flags().MarkHidden("hidden-flag")
+	root.AddCommand(parent)
+	parent.AddCommand(child)
+
+	page := manPage(child, time.Date(2026, time.March, 4, 0, 0, 0, 0, time.UTC))
+
+	for _, want := range []string{
+		`.TH "KRAZE-COMPLETION-INSTALL" "1" "Mar 2026" "kraze `,
+		".SH NAME\nkraze\\-completion\\-install \\- Install shell completion\n",
+		".SH SYNOPSIS\n.B kraze completion install [shell] [flags]\n",
+		".nf\nInstall completion.\n\\&.bashrc is updated\n  kraze completion install \\-\\-dry\\-run\n.fi\n",
+		".TP\n\\fB\\-d\\fP, \\fB\\-\\-dir\\fP \\fIpath\\fP\nWhere to write the path (default ~/.zsh)\n",
+		".SH OPTIONS INHERITED FROM PARENT COMMANDS\n.TP\n\\fB\\-v\\fP, \\fB\\-\\-verbose\\fP\nVerbose output\n",
+		".SH SEE ALSO\n\\fBkraze\\-completion\\fP(1)\n",
+	} {
+		if !strings.Contains(page, want) {
+			test.Errorf("man page missing %q:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "hidden") {
+		test.Errorf("man page lists a hidden flag:\n%s", page)
+	}
+
+	var names []string
+	for _, command := range manCommands(root) {
+		names = append(names, manPageName(command))
+	}
+	if got := strings.Join(names, " "); got != "kraze kraze-completion kraze-completion-install" {
+		test.Errorf("manCommands() = %s", got)
+	}
+}
+
+func TestManDate(test *testing.T) {
+	tests := []struct {
+		name     string
+		epoch    string
+		expected string
+		wantErr  bool
+	}{
+		{name: "reproducible build", epoch: "1767225600", expected: "Jan 2026"},
+		{name: "invalid", epoch: "yesterday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			got, err := manDate(tt.epoch)
+			if (err != nil) != tt.wantErr {
+				test.Fatalf("manDate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.Format("Jan 2006") != tt.expected {
+				test.Errorf("manDate() = %s, want %s", got.Format("Jan 2006"), tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,315 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/hjames9/kraze/internal/state"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// metricsScrapeTimeout bounds how long collecting metrics may take for one scrape
+const metricsScrapeTimeout = 30 * time.Second
+
+var metricsListen string
+
+// metricFamily is a Prometheus metric with its samples
+type metricFamily struct {
+	name    string
+	help    string
+	kind    string // gauge or counter
+	samples []metricSample
+}
+
+// metricSample is one value of a metric family
+type metricSample struct {
+	labels map[string]string
+	value  float64
+}
+
+// add appends a sample to the family
+func (family *metricFamily) add(labels map[string]string, value float64) {
+	family.samples = append(family.samples, metricSample{labels: labels, value: value})
+}
+
+var metricsCmd = &cobra.Command{
+	Use:   "metrics",
+	Short: "Print environment metrics in Prometheus format",
+	Long: `Print metrics of the kraze environment in the Prometheus text format: whether the cluster
+is up and for how long, and for each service whether it's installed and ready, its ready and
+desired replicas, how long its last install took and the size of the images it loaded.
+
+By default the metrics are printed once. With --listen, kraze serves them on /metrics and
+collects them again on every scrape, so a Prometheus agent on the machine can monitor the
+environment.
+
+Examples:
+  kraze metrics                       # Print the metrics once
+  kraze metrics --listen :9464        # Serve them on http://localhost:9464/metrics
+  kraze metrics --listen 127.0.0.1:9464`,
+	Args: cobra.NoArgs,
+	RunE: runMetrics,
+}
+
+func runMetrics(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	verboseToStderr = true // stdout is the metrics
+
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanupPack()
+
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := requireCluster(cfg, "metrics"); err != nil {
+		return err
+	}
+
+	if metricsListen == "" {
+		return writeMetrics(os.Stdout, collectMetrics(ctx, cfg))
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(writer http.ResponseWriter, request *http.Request) {
+		scrapeCtx, cancel := context.WithTimeout(request.Context(), metricsScrapeTimeout)
+		defer cancel()
+
+		var body bytes.Buffer
+		if err := writeMetrics(&body, collectMetrics(scrapeCtx, cfg)); err != nil {
+			http.Error(writer, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writer.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		writer.Write(body.Bytes())
+	})
+
+	server := &http.Server{Addr: metricsListen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	fmt.Fprintf(os.Stderr, "%s Serving metrics on http://%s/metrics (Ctrl+C to stop)\n", color.Checkmark(), metricsAddress(metricsListen))
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve metrics: %w", err)
+	}
+	return nil
+}
+
+// metricsAddress returns a browsable address for a listen address like ":9464"
+func metricsAddress(listen string) string {
+	if strings.HasPrefix(listen, ":") {
+		return "localhost" + listen
+	}
+	return listen
+}
+
+// collectMetrics gathers the metrics of the cluster and its services. Failures
+// are reported through the metrics (cluster down, service not ready) rather than
+// as errors, so scrapes keep working while the environment is stopped.
+func collectMetrics(ctx context.Context, cfg *config.Config) []*metricFamily {
+	clusterUp := &metricFamily{name: "kraze_cluster_up", help: "Whether the cluster is running and reachable", kind: "gauge"}
+	clusterUptime := &metricFamily{name: "kraze_cluster_uptime_seconds", help: "Time since all cluster nodes became Ready", kind: "gauge"}
+	clusterCreated := &metricFamily{name: "kraze_cluster_created_timestamp_seconds", help: "When the cluster was created, as a Unix timestamp", kind: "gauge"}
+	installed := &metricFamily{name: "kraze_service_installed", help: "Whether the service is installed", kind: "gauge"}
+	ready := &metricFamily{name: "kraze_service_ready", help: "Whether the service is ready", kind: "gauge"}
+	replicas := &metricFamily{name: "kraze_service_replicas", help: "Ready and desired replicas across the service's Deployments, StatefulSets and DaemonSets", kind: "gauge"}
+	installDuration := &metricFamily{name: "kraze_service_install_duration_seconds", help: "How long the service's last install took, including waiting for readiness", kind: "gauge"}
+	imageLoadBytes := &metricFamily{name: "kraze_service_image_load_bytes", help: "Size of the images the service's last install loaded into the cluster", kind: "gauge"}
+	lastInstall := &metricFamily{name: "kraze_service_last_install_timestamp_seconds", help: "When the service was last installed, as a Unix timestamp", kind: "gauge"}
+	families := []*metricFamily{clusterUp, clusterUptime, clusterCreated, installed, ready, replicas, installDuration, imageLoadBytes, lastInstall}
+
+	clusterLabels := map[string]string{"cluster": cfg.Cluster.Name}
+	services := sortedServiceNames(cfg.Services)
+
+	kubeconfig, err := getShellKubeconfig(ctx, cfg)
+	var clientset kubernetes.Interface
+	if err == nil {
+		clientset, err = providers.GetClientsetFromKubeconfigContent(kubeconfig)
+	}
+	if err == nil {
+		_, err = clientset.Discovery().ServerVersion()
+	}
+	if err != nil {
+		Verbose("Cluster '%s' is not reachable: %v", cfg.Cluster.Name, err)
+		clusterUp.add(clusterLabels, 0)
+		for _, name := range services {
+			if svc := cfg.Services[name]; svc.IsEnabled() {
+				ready.add(serviceMetricLabels(cfg, &svc), 0)
+			}
+		}
+		return families
+	}
+	clusterUp.add(clusterLabels, 1)
+
+	if since, err := nodesReadySince(ctx, clientset); err != nil {
+		Verbose("Warning: failed to read node conditions: %v", err)
+	} else if !since.IsZero() {
+		clusterUptime.add(clusterLabels, time.Since(since).Seconds())
+	}
+	if namespace, err := clientset.CoreV1().Namespaces().Get(ctx, "kube-system", metav1.GetOptions{}); err != nil {
+		Verbose("Warning: failed to read cluster creation time: %v", err)
+	} else {
+		clusterCreated.add(clusterLabels, float64(namespace.CreationTimestamp.Unix()))
+	}
+
+	st, err := state.Load(ctx, clientset, cfg.Cluster.Name, cfg.StateBackend)
+	if err != nil {
+		Verbose("Warning: failed to load cluster state: %v", err)
+	}
+
+	for _, name := range services {
+		svc := cfg.Services[name]
+		if !svc.IsEnabled() {
+			continue
+		}
+		labels := serviceMetricLabels(cfg, &svc)
+
+		provider, err := providers.NewProvider(&svc, &providers.ProviderOptions{ClusterName: cfg.Cluster.Name, KubeConfig: kubeconfig, Verbose: verbose, Quiet: true})
+		if err != nil {
+			Verbose("Warning: failed to create provider for '%s': %v", name, err)
+			continue
+		}
+		status, err := provider.Status(ctx, &svc)
+		if err != nil {
+			Verbose("Warning: failed to get status of '%s': %v", name, err)
+			continue
+		}
+		installed.add(labels, boolMetric(status.Installed))
+		ready.add(labels, boolMetric(status.Ready))
+
+		if status.Installed {
+			if count, err := providers.ServiceReplicas(ctx, clientset, &svc); err != nil {
+				Verbose("Warning: failed to count replicas of '%s': %v", name, err)
+			} else if count.Workloads > 0 {
+				replicas.add(withLabel(labels, "state", "ready"), float64(count.Ready))
+				replicas.add(withLabel(labels, "state", "desired"), float64(count.Desired))
+			}
+		}
+
+		if st == nil {
+			continue
+		}
+		if meta, exists := st.Services[name]; exists && meta.Installed {
+			installDuration.add(labels, meta.InstallSeconds)
+			imageLoadBytes.add(labels, float64(meta.ImageLoadBytes))
+			lastInstall.add(labels, float64(meta.UpdatedAt.Unix()))
+		}
+	}
+
+	return families
+}
+
+// nodesReadySince returns when the last node of the cluster became Ready, or
+// zero if a node isn't Ready
+func nodesReadySince(ctx context.Context, clientset kubernetes.Interface) (time.Time, error) {
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var since time.Time
+	for _, node := range nodes.Items {
+		nodeReady := false
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == corev1.NodeReady && condition.Status == corev1.ConditionTrue {
+				nodeReady = true
+				if condition.LastTransitionTime.After(since) {
+					since = condition.LastTransitionTime.Time
+				}
+			}
+		}
+		if !nodeReady {
+			return time.Time{}, nil
+		}
+	}
+	return since, nil
+}
+
+// serviceMetricLabels returns the labels identifying a service's samples
+func serviceMetricLabels(cfg *config.Config, svc *config.ServiceConfig) map[string]string {
+	return map[string]string{
+		"cluster":   cfg.Cluster.Name,
+		"service":   svc.Name,
+		"namespace": svc.GetNamespace(),
+		"type":      svc.Type,
+	}
+}
+
+// withLabel returns a copy of labels with one more label
+func withLabel(labels map[string]string, name, value string) map[string]string {
+	copied := make(map[string]string, len(labels)+1)
+	for key, existing := range labels {
+		copied[key] = existing
+	}
+	copied[name] = value
+	return copied
+}
+
+// boolMetric converts a flag to a 0 or 1 sample
+func boolMetric(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+// writeMetrics writes metric families in the Prometheus text exposition format,
+// skipping families without samples
+func writeMetrics(out io.Writer, families []*metricFamily) error {
+	var buf bytes.Buffer
+	for _, family := range families {
+		if len(family.samples) == 0 {
+			continue
+		}
+		fmt.Fprintf(&buf, "# HELP %s %s\n", family.name, family.help)
+		fmt.Fprintf(&buf, "# TYPE %s %s\n", family.name, family.kind)
+		for _, sample := range family.samples {
+			fmt.Fprintf(&buf, "%s%s %s\n", family.name, formatMetricLabels(sample.labels), strconv.FormatFloat(sample.value, 'g', -1, 64))
+		}
+	}
+	_, err := out.Write(buf.Bytes())
+	return err
+}
+
+// formatMetricLabels formats labels as {name="value",...} sorted by name
+func formatMetricLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	pairs := make([]string, len(names))
+	for itr, name := range names {
+		pairs[itr] = fmt.Sprintf(`%s="%s"`, name, escaper.Replace(labels[name]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func init() {
+	metricsCmd.Flags().StringVar(&metricsListen, "listen", "", "Serve the metrics on this address (e.g., :9464) instead of printing them once")
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWriteMetrics(test *testing.T) {
+	ready := &metricFamily{name: "kraze_service_ready", help: "Whether the service is ready", kind: "gauge"}
+	ready.add(map[string]string{"service": "api", "cluster": "dev"}, 1)
+	ready.add(map[string]string{"service": "web", "cluster": "dev", "namespace": `a"b\c`}, 0)
+	bytesLoaded := &metricFamily{name: "kraze_service_image_load_bytes", help: "Size of loaded images", kind: "gauge"}
+	bytesLoaded.add(map[string]string{"service": "api"}, 123456789)
+	empty := &metricFamily{name: "kraze_cluster_uptime_seconds", help: "Uptime", kind: "gauge"}
+
+	var out bytes.Buffer
+	if err := writeMetrics(&out, []*metricFamily{ready, empty, bytesLoaded}); err != nil {
+		test.Fatalf("writeMetrics() error = %v", err)
+	}
+
+	expected := `# HELP kraze_service_ready Whether the service is ready
+# TYPE kraze_service_ready gauge
+kraze_service_ready{cluster="dev",service="api"} 1
+kraze_service_ready{cluster="dev",namespace="a\"b\\c",service="web"} 0
+# HELP kraze_service_image_load_bytes Size of loaded images
+# TYPE kraze_service_image_load_bytes gauge
+kraze_service_image_load_bytes{service="api"} 1.23456789e+08
+`
+	if out.String() != expected {
+		test.Errorf("writeMetrics() =\n%s\nwant\n%s", out.String(), expected)
+	}
+}
+
+func TestNodesReadySince(test *testing.T) {
+	earlier := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	later := metav1.NewTime(time.Now().Add(-time.Minute).Truncate(time.Second))
+	node := func(name string, status corev1.ConditionStatus, since metav1.Time) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: status, LastTransitionTime: since},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		nodes    []*corev1.Node
+		expected time.Time
+	}{
+		{name: "last node to become ready", nodes: []*corev1.Node{node("control-plane", corev1.ConditionTrue, earlier), node("worker", corev1.ConditionTrue, later)}, expected: later.Time},
+		{name: "node not ready", nodes: []*corev1.Node{node("control-plane", corev1.ConditionTrue, earlier), node("worker", corev1.ConditionFalse, later)}},
+		{name: "no nodes"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			for _, node := range tt.nodes {
+				clientset.Tracker().Add(node)
+			}
+			since, err := nodesReadySince(context.Background(), clientset)
+			if err != nil {
+				test.Fatalf("nodesReadySince() error = %v", err)
+			}
+			if !since.Equal(tt.expected) {
+				test.Errorf("nodesReadySince() = %v, want %v", since, tt.expected)
+			}
+		})
+	}
+}
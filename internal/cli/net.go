@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	netLimitRate  string
+	netLimitNodes []string
+)
+
+var netCmd = &cobra.Command{
+	Use:   "net",
+	Short: "Simulate slow networks on the cluster's nodes",
+}
+
+var netLimitCmd = &cobra.Command{
+	Use:   "limit",
+	Short: "Throttle the bandwidth of the cluster's nodes",
+	Long: `Throttle the traffic the kind nodes receive from outside the cluster, such as
+image pulls from registries, to test how the application behaves with slow
+pulls and to reproduce image pull timeouts locally.
+
+The limit is set with tc on each node's interface on the kind network. Traffic
+between nodes (the API server, pod-to-pod) isn't limited, and images loaded
+with 'kraze load-image' don't go through the network. Downloads by pods from
+outside the cluster are throttled too. Running limit again replaces the limit.
+Limits last until 'kraze net unlimit', or until the nodes restart (e.g.,
+'kraze stop' and 'kraze start').
+
+Rates use tc units: bit, kbit, mbit, gbit, or bps, kbps, mbps, gbps for bytes
+per second.
+
+Examples:
+  kraze net limit --rate 1mbit                        # Throttle every node to 1 Mbit/s
+  kraze net limit --rate 256kbit --node dev-worker    # Throttle one node
+  kraze net status                                    # Show the limit of each node
+  kraze net unlimit                                   # Remove the limits`,
+	Args: cobra.NoArgs,
+	RunE: runNetLimit,
+}
+
+var netUnlimitCmd = &cobra.Command{
+	Use:   "unlimit",
+	Short: "Remove the bandwidth limits of the cluster's nodes",
+	Long: `Remove the bandwidth limits set by 'kraze net limit'.
+
+Examples:
+  kraze net unlimit                     # Remove the limits of every node
+  kraze net unlimit --node dev-worker   # Remove the limit of one node`,
+	Args: cobra.NoArgs,
+	RunE: runNetUnlimit,
+}
+
+var netStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the bandwidth limit of each node",
+	Args:  cobra.NoArgs,
+	RunE:  runNetStatus,
+}
+
+func runNetLimit(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if netLimitRate == "" {
+		return fmt.Errorf("--rate is required (e.g., --rate 1mbit)")
+	}
+	if _, err := cluster.ParseRate(netLimitRate); err != nil {
+		return err
+	}
+
+	cfg, kindMgr, cleanup, err := loadNetCluster(ctx, cmd, "net limit")
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would limit the bandwidth of %s in cluster '%s' to %s\n", describeNetNodes(netLimitNodes), cfg.Cluster.Name, netLimitRate)
+		return nil
+	}
+
+	limited, err := kindMgr.LimitBandwidth(ctx, cfg.Cluster.Name, netLimitRate, netLimitNodes)
+	for _, node := range limited {
+		fmt.Printf("%s Limited %s to %s\n", color.Checkmark(), node, netLimitRate)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println("Run 'kraze net unlimit' to remove the limit")
+	return nil
+}
+
+func runNetUnlimit(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	cfg, kindMgr, cleanup, err := loadNetCluster(ctx, cmd, "net unlimit")
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would remove the bandwidth limit of %s in cluster '%s'\n", describeNetNodes(netLimitNodes), cfg.Cluster.Name)
+		return nil
+	}
+
+	unlimited, err := kindMgr.UnlimitBandwidth(ctx, cfg.Cluster.Name, netLimitNodes)
+	for _, node := range unlimited {
+		fmt.Printf("%s Removed the bandwidth limit of %s\n", color.Checkmark(), node)
+	}
+	return err
+}
+
+func runNetStatus(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	cfg, kindMgr, cleanup, err := loadNetCluster(ctx, cmd, "net status")
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	limits, err := kindMgr.NetLimits(ctx, cfg.Cluster.Name)
+	if err != nil {
+		return err
+	}
+	for _, limit := range limits {
+		rate := limit.Rate
+		if rate == "" {
+			rate = "unlimited"
+		}
+		fmt.Printf("%-30s %s\n", limit.Node, rate)
+	}
+	return nil
+}
+
+// loadNetCluster parses the config and returns the kind manager of its running
+// cluster. Bandwidth is limited on kind nodes, so external clusters aren't supported.
+func loadNetCluster(ctx context.Context, cmd *cobra.Command, command string) (*config.Config, *cluster.KindManager, func(), error) {
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		cleanupPack()
+		return nil, nil, nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := requireCluster(cfg, command); err != nil {
+		cleanupPack()
+		return nil, nil, nil, err
+	}
+	if cfg.Cluster.IsExternal() {
+		cleanupPack()
+		return nil, nil, nil, fmt.Errorf("'kraze %s' only works with kind clusters, since it changes the nodes' network settings", command)
+	}
+	if err := cluster.CheckDockerAvailable(ctx); err != nil {
+		cleanupPack()
+		return nil, nil, nil, err
+	}
+	kindMgr, err := runningKindCluster(ctx, cfg)
+	if err != nil {
+		cleanupPack()
+		return nil, nil, nil, err
+	}
+	return cfg, kindMgr, cleanupPack, nil
+}
+
+// describeNetNodes names the nodes a net command changes
+func describeNetNodes(nodes []string) string {
+	if len(nodes) == 0 {
+		return "every node"
+	}
+	return "node(s) " + strings.Join(nodes, ", ")
+}
+
+func init() {
+	netLimitCmd.Flags().StringVar(&netLimitRate, "rate", "", "Bandwidth limit in tc units (e.g., 1mbit, 512kbit, 2mbps)")
+	netLimitCmd.Flags().StringSliceVar(&netLimitNodes, "node", nil, "Only limit these nodes (can be specified multiple times, default: every node)")
+	netUnlimitCmd.Flags().StringSliceVar(&netLimitNodes, "node", nil, "Only remove the limit of these nodes (can be specified multiple times, default: every node)")
+
+	netCmd.AddCommand(netLimitCmd)
+	netCmd.AddCommand(netUnlimitCmd)
+	netCmd.AddCommand(netStatusCmd)
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/providers"
+)
+
+// requireCluster rejects commands that need a cluster when cluster.none is set
+func requireCluster(cfg *config.Config, command string) error {
+	if cfg.Cluster.IsNone() {
+		return fmt.Errorf("'kraze %s' needs a cluster, but cluster.none is set (only validate, plan, render, describe, list-images and images work without one)", command)
+	}
+	return nil
+}
+
+// lintOfflineRender renders every enabled service without a cluster and scans
+// the result for APIs removed or deprecated in the Kubernetes version a kind
+// cluster would run. Render failures are returned as errors, since 'kraze up'
+// would fail on them too.
+func lintOfflineRender(ctx context.Context, cfg *config.Config) (int, []config.LintFinding, error) {
+	kubeVersion, err := config.ParseKubernetesVersion(cluster.NewKindManager().KubernetesVersion(&cfg.Cluster))
+	if err != nil {
+		Verbose("Skipping API deprecation scan: %v", err)
+	}
+
+	names := cfg.GetAllServiceNames()
+	sort.Strings(names)
+
+	rendered := 0
+	var findings []config.LintFinding
+	for _, name := range names {
+		svc := cfg.Services[name]
+		if !svc.IsEnabled() {
+			continue
+		}
+		resources, err := providers.RenderOffline(ctx, &svc, &providers.ProviderOptions{
+			ClusterName: cfg.Cluster.Name,
+			Verbose:     verbose,
+			Quiet:       true,
+			Registries:  cfg.Registries,
+		})
+		if err != nil {
+			return rendered, findings, fmt.Errorf("service '%s': %w", name, err)
+		}
+		rendered++
+		Verbose("Rendered '%s': %d resource(s)", name, len(resources))
+
+		if kubeVersion == nil {
+			continue
+		}
+		for _, finding := range providers.ScanDeprecatedAPIs(resources, kubeVersion) {
+			if cfg.IsLintRuleIgnored(finding.RuleID) {
+				continue
+			}
+			finding.Field = fmt.Sprintf("services.%s %s", name, finding.Field)
+			findings = append(findings, finding)
+		}
+	}
+	return rendered, findings, nil
+}
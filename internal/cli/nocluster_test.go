@@ -0,0 +1,19 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+)
+
+func TestRequireCluster(test *testing.T) {
+	if err := requireCluster(&config.Config{Cluster: config.ClusterConfig{Name: "dev"}}, "up"); err != nil {
+		test.Errorf("requireCluster() with a kind cluster error = %v", err)
+	}
+
+	err := requireCluster(&config.Config{Cluster: config.ClusterConfig{Name: "dev", None: true}}, "up")
+	if err == nil || !strings.Contains(err.Error(), "'kraze up'") {
+		test.Errorf("requireCluster() with cluster.none error = %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// outputFormat selects how read commands print their results
+type outputFormat string
+
+const (
+	outputTable outputFormat = ""
+	outputWide  outputFormat = "wide"
+	outputJSON  outputFormat = "json"
+	outputYAML  outputFormat = "yaml"
+)
+
+// verboseToStderr sends verbose messages to stderr so they don't corrupt JSON or YAML output
+var verboseToStderr bool
+
+// addOutputFlag adds -o/--output to a read command
+func addOutputFlag(cmd *cobra.Command, target *string) {
+	cmd.Flags().StringVarP(target, "output", "o", "", "Output format: json, yaml or wide (default: table)")
+	cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json", "yaml", "wide"}, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// parseOutputFormat validates an --output value. Structured formats route verbose
+// messages to stderr so stdout stays machine-readable.
+func parseOutputFormat(name string) (outputFormat, error) {
+	switch format := outputFormat(name); format {
+	case outputTable, outputWide:
+		return format, nil
+	case outputJSON, outputYAML:
+		verboseToStderr = true
+		return format, nil
+	}
+	return "", fmt.Errorf("invalid output format '%s' (expected json, yaml or wide)", name)
+}
+
+// isStructured reports whether the format is machine-readable
+func (format outputFormat) isStructured() bool {
+	return format == outputJSON || format == outputYAML
+}
+
+// printStructured writes value to stdout as JSON or YAML
+func printStructured(format outputFormat, value interface{}) error {
+	return writeStructured(os.Stdout, format, value)
+}
+
+// writeStructured encodes value as JSON or YAML. YAML is converted from the JSON
+// encoding, so both formats use the same field names and order.
+func writeStructured(out io.Writer, format outputFormat, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	if format == outputJSON {
+		_, err = fmt.Fprintf(out, "%s\n", data)
+		return err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	blockStyle(&node)
+
+	encoder := yaml.NewEncoder(out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	return encoder.Close()
+}
+
+// blockStyle clears the flow style that parsing JSON leaves on mappings and sequences
+func blockStyle(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode {
+		node.Style = 0
+	}
+	if node.Kind == yaml.ScalarNode && node.Style == yaml.DoubleQuotedStyle {
+		node.Style = 0
+	}
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseOutputFormat(test *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    outputFormat
+		wantErr bool
+	}{
+		{name: "default table", value: "", want: outputTable},
+		{name: "wide", value: "wide", want: outputWide},
+		{name: "json", value: "json", want: outputJSON},
+		{name: "yaml", value: "yaml", want: outputYAML},
+		{name: "unknown", value: "xml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			defer func() { verboseToStderr = false }()
+			got, err := parseOutputFormat(tt.value)
+			if (err != nil) != tt.wantErr {
+				test.Fatalf("parseOutputFormat(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				test.Errorf("parseOutputFormat(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteStructured(test *testing.T) {
+	value := struct {
+		Name     string            `json:"name"`
+		Version  string            `json:"version"`
+		Ready    bool              `json:"ready"`
+		Replicas int               `json:"replicas"`
+		Labels   map[string]string `json:"labels"`
+		Ports    []int             `json:"ports"`
+		Message  string            `json:"message,omitempty"`
+	}{
+		Name:     "api",
+		Version:  "1.0",
+		Ready:    true,
+		Replicas: 2,
+		Labels:   map[string]string{"tier": "backend"},
+		Ports:    []int{8080},
+	}
+
+	tests := []struct {
+		name   string
+		format outputFormat
+		want   string
+	}{
+		{
+			name:   "json",
+			format: outputJSON,
+			want: `{
+  "name": "api",
+  "version": "1.0",
+  "ready": true,
+  "replicas": 2,
+  "labels": {
+    "tier": "backend"
+  },
+  "ports": [
+    8080
+  ]
+}
+`,
+		},
+		{
+			name:   "yaml keeps json field names and quotes ambiguous strings",
+			format: outputYAML,
+			want: `name: api
+version: "1.0"
+ready: true
+replicas: 2
+labels:
+  tier: backend
+ports:
+  - 8080
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			var out bytes.Buffer
+			if err := writeStructured(&out, tt.format, value); err != nil {
+				test.Fatalf("writeStructured() error = %v", err)
+			}
+			if out.String() != tt.want {
+				test.Errorf("writeStructured() =\n%s\nwant\n%s", out.String(), tt.want)
+			}
+		})
+	}
+}
@@ -27,7 +27,8 @@ The package includes:
   - Remote Helm charts (pulled from OCI/HTTPS registries)
   - Remote HTTP manifests (downloaded at pack time)
 
-Container images are NOT bundled — they are fetched from registries at deploy time.
+Container images are NOT bundled — they are fetched from registries at deploy time
+(use 'kraze bundle' for offline installs).
 
 The recipient can run the package directly:
   kraze up -f myapp.tar.gz
@@ -124,6 +125,8 @@ func summariseRemoteAssets(cfg *config.Config) {
 // humanBytes returns a human-readable byte size string.
 func humanBytes(n int64) string {
 	switch {
+	case n >= 1024*1024*1024:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1024*1024*1024))
 	case n >= 1024*1024:
 		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
 	case n >= 1024:
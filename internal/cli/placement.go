@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/providers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// listClusterNodes returns the nodes of the cluster, for placing images
+func listClusterNodes(ctx context.Context, kubeconfig string) ([]corev1.Node, error) {
+	clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	return nodes.Items, nil
+}
+
+// serviceImagePlacement renders a service and maps each image of its workloads
+// to the nodes their pods can be scheduled on
+func serviceImagePlacement(ctx context.Context, cfg *config.Config, kubeconfig string, svc *config.ServiceConfig, nodes []corev1.Node) (map[string][]string, error) {
+	provider, err := providers.NewProvider(svc, &providers.ProviderOptions{
+		ClusterName: cfg.Cluster.Name,
+		KubeConfig:  kubeconfig,
+		Verbose:     verbose,
+		Quiet:       true,
+		Registries:  cfg.Registries,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resources, err := provider.Render(ctx, svc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render service: %w", err)
+	}
+	return providers.ImagePlacement(resources, nodes), nil
+}
+
+// imageNodes returns the nodes an image is placed on, matching references
+// the way containerd names them (nginx:1.27 is docker.io/library/nginx:1.27).
+// Returns nil if the image isn't placed, meaning every node.
+func imageNodes(placement map[string][]string, image string) []string {
+	if nodeNames, exists := placement[image]; exists {
+		return nodeNames
+	}
+	name := cluster.ClusterImageName(image)
+	for placed, nodeNames := range placement {
+		if cluster.ClusterImageName(placed) == name {
+			return nodeNames
+		}
+	}
+	return nil
+}
+
+// mergeNodeNames returns the sorted union of two lists of node names
+func mergeNodeNames(a, b []string) []string {
+	merged := append(slices.Clone(a), b...)
+	sort.Strings(merged)
+	return slices.Compact(merged)
+}
+
+// printImagePlacement prints which nodes each image would be loaded onto
+func printImagePlacement(placement map[string][]string, nodes []corev1.Node) {
+	nodeNames := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		nodeNames = append(nodeNames, node.Name)
+	}
+	sort.Strings(nodeNames)
+
+	fmt.Println(color.Bold("Image placement:"))
+	for _, line := range placementMatrix(placement, nodeNames) {
+		fmt.Printf("  %s\n", line)
+	}
+}
+
+// placementMatrix formats image placement as a table with one row per image
+// and one column per node, marking the nodes that need each image
+func placementMatrix(placement map[string][]string, nodeNames []string) []string {
+	images := make([]string, 0, len(placement))
+	imageWidth := len("IMAGE")
+	for image := range placement {
+		images = append(images, image)
+		imageWidth = max(imageWidth, len(image))
+	}
+	sort.Strings(images)
+
+	header := []string{fmt.Sprintf("%-*s", imageWidth, "IMAGE")}
+	header = append(header, nodeNames...)
+	lines := []string{strings.TrimRight(strings.Join(header, "  "), " ")}
+
+	counts := make(map[string]int, len(nodeNames))
+	for _, image := range images {
+		onNode := make(map[string]bool, len(placement[image]))
+		for _, name := range placement[image] {
+			onNode[name] = true
+		}
+
+		row := []string{fmt.Sprintf("%-*s", imageWidth, image)}
+		for _, name := range nodeNames {
+			mark := "-"
+			if onNode[name] {
+				mark = "✓"
+				counts[name]++
+			}
+			row = append(row, mark+strings.Repeat(" ", max(len(name)-1, 0)))
+		}
+		lines = append(lines, strings.TrimRight(strings.Join(row, "  "), " "))
+	}
+
+	// How many images each node holds, to compare disk usage
+	total := []string{fmt.Sprintf("%-*s", imageWidth, "TOTAL")}
+	for _, name := range nodeNames {
+		total = append(total, fmt.Sprintf("%-*d", len(name), counts[name]))
+	}
+	return append(lines, strings.TrimRight(strings.Join(total, "  "), " "))
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestImageNodes(test *testing.T) {
+	placement := map[string][]string{
+		"nginx:1.27":          {"kraze-worker"},
+		"ghcr.io/org/api:dev": {"kraze-worker2"},
+	}
+
+	tests := []struct {
+		name     string
+		image    string
+		expected []string
+	}{
+		{name: "exact reference", image: "nginx:1.27", expected: []string{"kraze-worker"}},
+		{name: "fully qualified docker hub reference", image: "docker.io/library/nginx:1.27", expected: []string{"kraze-worker"}},
+		{name: "other registry", image: "ghcr.io/org/api:dev", expected: []string{"kraze-worker2"}},
+		{name: "image not placed means every node", image: "redis:7"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := imageNodes(placement, tt.image); !reflect.DeepEqual(got, tt.expected) {
+				test.Errorf("imageNodes(%q) = %v, want %v", tt.image, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPlacementMatrix(test *testing.T) {
+	placement := map[string][]string{
+		"trainer:dev":  {"kraze-worker"},
+		"api:dev":      {"kraze-worker", "kraze-worker2"},
+		"agent:latest": {"kraze-control-plane", "kraze-worker", "kraze-worker2"},
+	}
+	nodeNames := []string{"kraze-control-plane", "kraze-worker", "kraze-worker2"}
+
+	expected := []string{
+		"IMAGE         kraze-control-plane  kraze-worker  kraze-worker2",
+		"agent:latest  ✓                    ✓             ✓",
+		"api:dev       -                    ✓             ✓",
+		"trainer:dev   -                    ✓             -",
+		"TOTAL         1                    3             2",
+	}
+	if got := placementMatrix(placement, nodeNames); !reflect.DeepEqual(got, expected) {
+		test.Errorf("placementMatrix() =\n%v\nwant\n%v", got, expected)
+	}
+}
+
+func TestMergeNodeNames(test *testing.T) {
+	got := mergeNodeNames([]string{"kraze-worker2"}, []string{"kraze-worker", "kraze-worker2"})
+	expected := []string{"kraze-worker", "kraze-worker2"}
+	if !reflect.DeepEqual(got, expected) {
+		test.Errorf("mergeNodeNames() = %v, want %v", got, expected)
+	}
+}
@@ -28,6 +28,10 @@ Displays:
 - Dependency levels and parallel execution groups
 - Namespaces that would be created
 - Cluster status and network configuration
+- On multi-node clusters, which nodes each image would be loaded onto
+
+With cluster.none set, no cluster or Docker is needed and every service is
+planned as a new install.
 
 You can filter services by name or by labels:
   kraze plan service1 service2      # Plan specific services
@@ -47,6 +51,7 @@ type ServicePlanInfo struct {
 	NamespaceAction string // "create", "exists"
 	DependsOn       []string
 	Details         string
+	Reason          config.SelectionReason // Why the service is part of the plan
 }
 
 func runPlan(cmd *cobra.Command, args []string) error {
@@ -66,8 +71,8 @@ func runPlan(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to parse config: %w", err)
 	}
 
-	// Check Docker availability (only for kind clusters, not external)
-	if !cfg.Cluster.IsExternal() {
+	// Check Docker availability (only for kind clusters, not external or none)
+	if !cfg.Cluster.IsExternal() && !cfg.Cluster.IsNone() {
 		Verbose("Checking Docker availability...")
 		if err := cluster.CheckDockerAvailable(ctx); err != nil {
 			return err
@@ -104,6 +109,11 @@ func runPlan(cmd *cobra.Command, args []string) error {
 		Verbose("No services specified, will plan all services")
 	}
 
+	reasons, err := config.SelectionReasons(cfg.Services, requestedServices, planLabels)
+	if err != nil {
+		return err
+	}
+
 	// Create dependency graph
 	depGraph := graph.NewDependencyGraph(cfg.Services)
 
@@ -127,7 +137,10 @@ func runPlan(cmd *cobra.Command, args []string) error {
 
 	// Try to get kubeconfig and load state from cluster
 	var kubeconfig string
-	if isExternal {
+	if cfg.Cluster.IsNone() {
+		// No cluster: everything is planned as a fresh install
+		st = state.New(cfg.Cluster.Name, false, false, 0, false, 0)
+	} else if isExternal {
 		// External cluster
 		kubeconfig, err = kindMgr.GetKubeconfigForExternalCluster(&cfg.Cluster)
 		if err != nil {
@@ -149,6 +162,9 @@ func runPlan(cmd *cobra.Command, args []string) error {
 			st = state.New(cfg.Cluster.Name, isExternal, false, 0, false, 0)
 			kubeconfig = "" // Clear to skip state loading
 		} else {
+			if err := checkClusterHealth(ctx, kindMgr, &cfg.Cluster); err != nil {
+				Verbose("Warning: %v", err)
+			}
 			kubeconfig, err = kindMgr.GetKubeConfig(cfg.Cluster.Name, false)
 			if err != nil {
 				Verbose("Warning: failed to get kubeconfig: %v", err)
@@ -161,13 +177,13 @@ func runPlan(cmd *cobra.Command, args []string) error {
 
 	// If we have kubeconfig, try to load state from cluster
 	if kubeconfig != "" {
-		clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig, !isExternal)
+		clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig)
 		if err != nil {
 			Verbose("Warning: failed to create Kubernetes client: %v", err)
 			Verbose("Treating as empty state (no services installed)")
 			st = state.New(cfg.Cluster.Name, isExternal, false, 0, false, 0)
 		} else {
-			st, err = state.Load(ctx, clientset, cfg.Cluster.Name)
+			st, err = state.Load(ctx, clientset, cfg.Cluster.Name, cfg.StateBackend)
 			if err != nil {
 				Verbose("Warning: failed to load cluster state: %v", err)
 				Verbose("Treating as empty state (no services installed)")
@@ -184,7 +200,7 @@ func runPlan(cmd *cobra.Command, args []string) error {
 	if isExternal {
 		// For external clusters, check if we successfully got kubeconfig
 		clusterExists = (kubeconfig != "")
-	} else {
+	} else if !cfg.Cluster.IsNone() {
 		// For kind clusters, check if cluster exists
 		exists, err := kindMgr.ClusterExists(cfg.Cluster.Name)
 		if err != nil {
@@ -223,6 +239,7 @@ func runPlan(cmd *cobra.Command, args []string) error {
 		}
 
 		info := analyzeService(ctx, &svcCopy, st, cfg)
+		info.Reason = reasons[name]
 		serviceInfos[name] = info
 
 		switch info.Action {
@@ -252,9 +269,20 @@ func runPlan(cmd *cobra.Command, args []string) error {
 		fmt.Println()
 	}
 
+	// Show which nodes each image lands on when there are several to choose from
+	if kubeconfig != "" {
+		planImagePlacement(ctx, cfg, kubeconfig, serviceLevels)
+	}
+
 	// Print summary
 	printPlanSummary(toAdd, toChange, noChange, skipped)
 
+	fmt.Println()
+	if cfg.Cluster.IsNone() {
+		fmt.Printf("Disable cluster.none and run %s to execute this plan.\n", color.Bold("kraze up"))
+	} else {
+		fmt.Printf("Run %s to execute this plan.\n", color.Bold("kraze up"))
+	}
 	return nil
 }
 
@@ -264,6 +292,8 @@ func printClusterPlan(cfg *config.Config, exists bool) {
 	existsStr := "no, will be created"
 	if exists {
 		existsStr = "yes"
+	} else if cfg.Cluster.IsNone() {
+		existsStr = "none (cluster.none is set, nothing will be installed)"
 	}
 	fmt.Printf("  Status: %s\n", existsStr)
 
@@ -293,7 +323,7 @@ func analyzeService(ctx context.Context, svc *config.ServiceConfig, st *state.Cl
 		Name:      svc.Name,
 		Type:      svc.Type,
 		Namespace: svc.GetNamespace(),
-		DependsOn: svc.DependsOn,
+		DependsOn: svc.DependsOn.Names(),
 	}
 
 	// Determine if service is installed
@@ -387,6 +417,47 @@ func printServicePlan(info *ServicePlanInfo) {
 	if len(info.DependsOn) > 0 {
 		fmt.Printf("      Depends on: %s\n", strings.Join(info.DependsOn, ", "))
 	}
+
+	// Explain why the service is included when only some were selected
+	if info.Reason.Kind != "" && info.Reason.Kind != config.ReasonAll {
+		fmt.Printf("      Reason: %s\n", info.Reason)
+	}
+}
+
+// planImagePlacement prints the image placement matrix of the enabled
+// services on multi-node clusters
+func planImagePlacement(ctx context.Context, cfg *config.Config, kubeconfig string, serviceLevels [][]*config.ServiceConfig) {
+	nodes, err := listClusterNodes(ctx, kubeconfig)
+	if err != nil {
+		Verbose("Warning: skipping image placement: %v", err)
+		return
+	}
+	if len(nodes) < 2 {
+		return
+	}
+
+	placement := make(map[string][]string)
+	for _, level := range serviceLevels {
+		for _, svc := range level {
+			if !svc.IsEnabled() {
+				continue
+			}
+			servicePlacement, err := serviceImagePlacement(ctx, cfg, kubeconfig, svc, nodes)
+			if err != nil {
+				Verbose("Warning: skipping image placement for '%s': %v", svc.Name, err)
+				continue
+			}
+			for image, nodeNames := range servicePlacement {
+				placement[image] = mergeNodeNames(placement[image], nodeNames)
+			}
+		}
+	}
+	if len(placement) == 0 {
+		return
+	}
+
+	printImagePlacement(placement, nodes)
+	fmt.Println()
 }
 
 func printPlanSummary(toAdd, toChange, noChange, skipped int) {
@@ -411,11 +482,9 @@ func printPlanSummary(toAdd, toChange, noChange, skipped int) {
 	} else {
 		fmt.Println(" No changes")
 	}
-
-	fmt.Println()
-	fmt.Printf("Run %s to execute this plan.\n", color.Bold("kraze up"))
 }
 
 func init() {
 	planCmd.Flags().StringSliceVarP(&planLabels, "label", "l", []string{}, "Filter services by label (format: key=value, can be specified multiple times)")
+	registerLabelCompletion(planCmd)
 }
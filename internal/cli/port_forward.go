@@ -5,8 +5,6 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
-	"strconv"
-	"strings"
 	"syscall"
 
 	"github.com/hjames9/kraze/internal/cluster"
@@ -21,65 +19,28 @@ var (
 )
 
 var portForwardCmd = &cobra.Command{
-	Use:   "port-forward SERVICE [LOCAL_PORT:]REMOTE_PORT [...[LOCAL_PORT:]REMOTE_PORT]",
+	Use:   "port-forward SERVICE [[LOCAL_PORT:]REMOTE_PORT...]",
 	Short: "Forward one or more local ports to a service",
-	Long: `Forward one or more local ports to a service running in the cluster.
+	Long: `Forward one or more local ports to a service running in the cluster, in the
+foreground until Ctrl+C.
 
-The command will find a pod associated with the service and forward the specified ports.
+Without port arguments, the ports declared with 'ports' in kraze.yml are forwarded.
+The command finds a pod of the service and reconnects to a new pod when it is
+restarted or replaced by a rollout. With --pod, the forward is pinned to that pod
+and ends when the pod goes away.
+
+For forwards that keep running after the CLI exits, use 'kraze forward start' or
+'kraze up --forward', and 'kraze ports' to list them.
 
 Examples:
+  kraze port-forward redis                # Forward the ports declared for redis
   kraze port-forward redis 6379           # Forward local 6379 to remote 6379
   kraze port-forward redis 6380:6379      # Forward local 6380 to remote 6379
   kraze port-forward web 8080:80 8443:443 # Forward multiple ports
   kraze port-forward redis 6379 --pod redis-master-0  # Forward to specific pod`,
-	Args: cobra.MinimumNArgs(2),
-	RunE: runPortForward,
-}
-
-// PortMapping represents a port forwarding mapping
-type PortMapping struct {
-	LocalPort  int
-	RemotePort int
-}
-
-func parsePortMapping(portStr string) (*PortMapping, error) {
-	parts := strings.Split(portStr, ":")
-
-	var localPort, remotePort int
-	var err error
-
-	if len(parts) == 1 {
-		// Just remote port, use same for local
-		remotePort, err = strconv.Atoi(parts[0])
-		if err != nil {
-			return nil, fmt.Errorf("invalid port number '%s': %w", parts[0], err)
-		}
-		localPort = remotePort
-	} else if len(parts) == 2 {
-		// Local:Remote
-		localPort, err = strconv.Atoi(parts[0])
-		if err != nil {
-			return nil, fmt.Errorf("invalid local port '%s': %w", parts[0], err)
-		}
-		remotePort, err = strconv.Atoi(parts[1])
-		if err != nil {
-			return nil, fmt.Errorf("invalid remote port '%s': %w", parts[1], err)
-		}
-	} else {
-		return nil, fmt.Errorf("invalid port mapping '%s': expected format [LOCAL_PORT:]REMOTE_PORT", portStr)
-	}
-
-	if localPort < 1 || localPort > 65535 {
-		return nil, fmt.Errorf("local port %d out of range (1-65535)", localPort)
-	}
-	if remotePort < 1 || remotePort > 65535 {
-		return nil, fmt.Errorf("remote port %d out of range (1-65535)", remotePort)
-	}
-
-	return &PortMapping{
-		LocalPort:  localPort,
-		RemotePort: remotePort,
-	}, nil
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: getServiceNames,
+	RunE:              runPortForward,
 }
 
 func runPortForward(cmd *cobra.Command, args []string) error {
@@ -91,9 +52,9 @@ func runPortForward(cmd *cobra.Command, args []string) error {
 	Verbose("Port-forwarding to service: %s", serviceName)
 
 	// Parse port mappings
-	var portMappings []*PortMapping
+	var portMappings []config.PortForward
 	for _, portSpec := range portSpecs {
-		mapping, err := parsePortMapping(portSpec)
+		mapping, err := config.ParsePortForward(portSpec)
 		if err != nil {
 			return err
 		}
@@ -110,6 +71,9 @@ func runPortForward(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to parse config: %w", err)
 	}
+	if err := requireCluster(cfg, "port-forward"); err != nil {
+		return err
+	}
 
 	// Check Docker availability (only for kind clusters, not external)
 	if !cfg.Cluster.IsExternal() {
@@ -126,6 +90,14 @@ func runPortForward(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("service '%s' not found in configuration", serviceName)
 	}
 
+	// Fall back to the ports declared in the config
+	if len(portMappings) == 0 {
+		portMappings = svc.GetPortForwards()
+		if len(portMappings) == 0 {
+			return fmt.Errorf("no ports given and service '%s' declares none, add 'ports' to it in the config", serviceName)
+		}
+	}
+
 	// Check if cluster exists
 	kindMgr := cluster.NewKindManager()
 
@@ -146,6 +118,9 @@ func runPortForward(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("failed to get kubeconfig for external cluster: %w", err)
 		}
 	} else {
+		if err := checkClusterHealth(ctx, kindMgr, &cfg.Cluster); err != nil {
+			return err
+		}
 		kubeconfig, err = kindMgr.GetKubeConfig(cfg.Cluster.Name, false)
 		if err != nil {
 			return fmt.Errorf("failed to get kubeconfig: %w", err)
@@ -175,68 +150,56 @@ func runPortForward(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("service '%s' is not installed", serviceName)
 	}
 
-	// Get pod name
-	podName := portForwardPod
-	if podName == "" {
-		// Auto-select a pod from the service
-		pods, err := providers.GetPodsForService(ctx, kubeconfig, &svc)
-		if err != nil {
-			return fmt.Errorf("failed to get pods for service: %w", err)
-		}
+	// Create a context that will be cancelled on interrupt
+	pfCtx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer cancel()
 
-		if len(pods) == 0 {
-			return fmt.Errorf("no pods found for service '%s'", serviceName)
+	// An open port-forward counts as activity for the idle auto-stop
+	if !cfg.Cluster.IsExternal() {
+		go keepClusterActive(pfCtx, cfg.Cluster.Name)
+	}
+
+	if portForwardPod == "" {
+		// Follow the service's pods, reconnecting when they are restarted or rolled
+		targets := make([]forwardTarget, len(portMappings))
+		for itr, mapping := range portMappings {
+			targets[itr] = forwardTarget{service: svc, port: mapping}
 		}
 
-		// Use the first pod
-		podName = pods[0]
-		if len(pods) > 1 {
-			fmt.Printf("Multiple pods found, using '%s' (use --pod to specify)\n", podName)
-			Verbose("Available pods: %v", pods)
+		fmt.Printf("Forwarding to %s/%s (reconnects when pods restart):\n", svc.GetNamespace(), svc.Name)
+		for _, mapping := range portMappings {
+			fmt.Printf("  localhost:%d -> :%d\n", mapping.LocalPort, mapping.RemotePort)
 		}
+		fmt.Println("\nPress Ctrl+C to stop forwarding")
+
+		newForwardDaemon(cfg, targets, false).run(pfCtx, targets)
+		fmt.Println("\nStopping port-forward...")
+		return nil
 	}
 
 	// Build port-forward arguments
 	ports := make([]string, len(portMappings))
 	for i, mapping := range portMappings {
-		ports[i] = fmt.Sprintf("%d:%d", mapping.LocalPort, mapping.RemotePort)
+		ports[i] = mapping.String()
 	}
 
-	fmt.Printf("Forwarding from %s/%s:\n", svc.GetNamespace(), podName)
+	fmt.Printf("Forwarding from %s/%s:\n", svc.GetNamespace(), portForwardPod)
 	for _, mapping := range portMappings {
 		fmt.Printf("  localhost:%d -> :%d\n", mapping.LocalPort, mapping.RemotePort)
 	}
 	fmt.Println("\nPress Ctrl+C to stop forwarding")
 
-	// Set up signal handling
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-
-	// Create a context that will be cancelled on interrupt
-	pfCtx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	// Start port forwarding in a goroutine
-	errChan := make(chan error, 1)
-	go func() {
-		err := providers.PortForward(pfCtx, kubeconfig, svc.GetNamespace(), podName, ports)
-		if err != nil {
-			errChan <- err
-		}
-	}()
-
-	// Wait for interrupt or error
-	select {
-	case <-sigChan:
-		fmt.Println("\nStopping port-forward...")
-		cancel()
-		return nil
-	case err := <-errChan:
+	if err := providers.PortForward(pfCtx, kubeconfig, svc.GetNamespace(), portForwardPod, ports); err != nil && pfCtx.Err() == nil {
 		return fmt.Errorf("port-forward failed: %w", err)
 	}
+	if pfCtx.Err() != nil {
+		fmt.Println("\nStopping port-forward...")
+	}
+	return nil
 }
 
 func init() {
 	portForwardCmd.Flags().StringSliceVarP(&portForwardLabels, "label", "l", []string{}, "Filter services by label (format: key=value)")
+	registerLabelCompletion(portForwardCmd)
 	portForwardCmd.Flags().StringVarP(&portForwardPod, "pod", "p", "", "Specific pod name to forward to (optional, auto-selects if not specified)")
 }
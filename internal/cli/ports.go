@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/spf13/cobra"
+)
+
+// forwardStateInactive marks declared forwards that no daemon is running
+const forwardStateInactive = "inactive"
+
+var portsOutput string
+
+// portsReport is the output of 'kraze ports -o json|yaml'
+type portsReport struct {
+	Cluster  string                  `json:"cluster"`
+	Daemon   *portsDaemon            `json:"daemon,omitempty"` // Unset when no daemon is running
+	Forwards []cluster.ForwardStatus `json:"forwards"`
+}
+
+// portsDaemon describes the running port-forward daemon
+type portsDaemon struct {
+	PID     int       `json:"pid"`
+	Started time.Time `json:"started"`
+}
+
+var portsCmd = &cobra.Command{
+	Use:   "ports [services...]",
+	Short: "List declared port-forwards and their state",
+	Long: `List the port-forwards declared with 'ports' in kraze.yml, with the state of
+each tunnel in the background daemon started by 'kraze forward start' or
+'kraze up --forward'.
+
+Forwards that are declared but not run by the daemon are shown as inactive.
+Forwards run by the daemon that are no longer declared (for example after the
+config was edited) are listed as well.
+
+Examples:
+  kraze ports              # All declared forwards
+  kraze ports postgres     # Only postgres's forwards
+  kraze ports -o wide      # Add namespaces, state changes and errors
+  kraze ports -o json      # Machine-readable listing`,
+	ValidArgsFunction: getServiceNames,
+	RunE:              runPorts,
+}
+
+func runPorts(cmd *cobra.Command, args []string) error {
+	format, err := parseOutputFormat(portsOutput)
+	if err != nil {
+		return err
+	}
+
+	cfg, cleanup, err := parseConfigForForward(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	targets, err := declaredForwards(cfg, args)
+	if err != nil {
+		return err
+	}
+
+	status, err := cluster.LoadForwardStatus(cfg.Cluster.Name)
+	if err != nil {
+		return err
+	}
+	if status != nil && !status.IsLive(time.Now()) {
+		status = nil
+	}
+
+	listing := forwardListing(targets, status, len(args) == 0)
+
+	if format.isStructured() {
+		report := portsReport{Cluster: cfg.Cluster.Name, Forwards: listing}
+		if status != nil {
+			report.Daemon = &portsDaemon{PID: status.PID, Started: status.Started}
+		}
+		return printStructured(format, report)
+	}
+
+	if len(listing) == 0 {
+		fmt.Println("No port-forwards declared, add 'ports' to services in the config")
+		return nil
+	}
+
+	if format == outputWide {
+		printForwardTableWide(listing)
+	} else {
+		printForwardTable(listing)
+	}
+
+	if status == nil {
+		fmt.Printf("\nNo port-forward daemon is running, start one with 'kraze forward start'\n")
+	} else {
+		fmt.Printf("\nPort-forward daemon: pid %d, running since %s\n", status.PID, status.Started.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// forwardListing merges the declared forwards with the daemon's status (nil when
+// no daemon is running). Forwards the daemon runs that are no longer declared are
+// appended when includeUndeclared is set. Entries are sorted by local port.
+func forwardListing(targets []forwardTarget, status *cluster.ForwardDaemonStatus, includeUndeclared bool) []cluster.ForwardStatus {
+	running := make(map[int]cluster.ForwardStatus)
+	if status != nil {
+		for _, fwd := range status.Forwards {
+			running[fwd.LocalPort] = fwd
+		}
+	}
+
+	listing := make([]cluster.ForwardStatus, 0, len(targets))
+	for _, target := range targets {
+		fwd, ok := running[target.port.LocalPort]
+		if ok && fwd.Service == target.service.Name && fwd.RemotePort == target.port.RemotePort {
+			listing = append(listing, fwd)
+		} else {
+			listing = append(listing, cluster.ForwardStatus{
+				Service:    target.service.Name,
+				Namespace:  target.service.GetNamespace(),
+				LocalPort:  target.port.LocalPort,
+				RemotePort: target.port.RemotePort,
+				State:      forwardStateInactive,
+			})
+		}
+		delete(running, target.port.LocalPort)
+	}
+
+	if includeUndeclared && status != nil {
+		for _, fwd := range status.Forwards {
+			if _, leftover := running[fwd.LocalPort]; leftover {
+				listing = append(listing, fwd)
+			}
+		}
+	}
+
+	sort.Slice(listing, func(i, j int) bool {
+		return listing[i].LocalPort < listing[j].LocalPort
+	})
+	return listing
+}
+
+// printForwardTableWide prints port forwards with their namespace, when they
+// entered their state and their last error
+func printForwardTableWide(forwards []cluster.ForwardStatus) {
+	fmt.Printf("%-20s %-15s %-8s %-8s %-13s %-9s %-25s %-40s %s\n", "SERVICE", "NAMESPACE", "LOCAL", "REMOTE", "STATE", "RESTARTS", "SINCE", "POD", "LAST ERROR")
+	fmt.Println("--------------------------------------------------------------------------------------------------------------------------------------------")
+	for _, fwd := range forwards {
+		pod, since, lastError := fwd.Pod, "-", fwd.LastError
+		if pod == "" {
+			pod = "-"
+		}
+		if !fwd.Since.IsZero() {
+			since = fwd.Since.Format(time.RFC3339)
+		}
+		if lastError == "" {
+			lastError = "-"
+		}
+		fmt.Printf("%-20s %-15s %-8d %-8d %-13s %-9d %-25s %-40s %s\n", fwd.Service, fwd.Namespace, fwd.LocalPort, fwd.RemotePort, fwd.State, fwd.Restarts, since, pod, lastError)
+	}
+}
+
+func init() {
+	addOutputFlag(portsCmd, &portsOutput)
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/hjames9/kraze/internal/config"
+)
+
+func TestForwardListing(test *testing.T) {
+	db := config.ServiceConfig{Name: "db", Type: "helm", Namespace: "data"}
+	web := config.ServiceConfig{Name: "web", Type: "manifests"}
+	targets := []forwardTarget{
+		{service: web, port: config.PortForward{LocalPort: 8080, RemotePort: 80}},
+		{service: db, port: config.PortForward{LocalPort: 5432, RemotePort: 5432}},
+	}
+
+	daemon := &cluster.ForwardDaemonStatus{
+		PID:       42,
+		Heartbeat: time.Now(),
+		Forwards: []cluster.ForwardStatus{
+			{Service: "db", LocalPort: 5432, RemotePort: 5432, State: cluster.ForwardStateActive, Pod: "db-0"},
+			{Service: "old", LocalPort: 9000, RemotePort: 9000, State: cluster.ForwardStateReconnecting},
+		},
+	}
+
+	tests := []struct {
+		name              string
+		status            *cluster.ForwardDaemonStatus
+		includeUndeclared bool
+		expected          []string // service:local:state
+	}{
+		{
+			name:     "no daemon",
+			expected: []string{"db:5432:inactive", "web:8080:inactive"},
+		},
+		{
+			name:              "daemon state merged with undeclared forwards",
+			status:            daemon,
+			includeUndeclared: true,
+			expected:          []string{"db:5432:active", "web:8080:inactive", "old:9000:reconnecting"},
+		},
+		{
+			name:     "undeclared forwards hidden when filtering by service",
+			status:   daemon,
+			expected: []string{"db:5432:active", "web:8080:inactive"},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			var got []string
+			for _, fwd := range forwardListing(targets, tt.status, tt.includeUndeclared) {
+				got = append(got, fmt.Sprintf("%s:%d:%s", fwd.Service, fwd.LocalPort, fwd.State))
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				test.Errorf("forwardListing() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
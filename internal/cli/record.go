@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// maxRunRecordings is how many recorded runs are kept in ~/.kraze/runs
+const maxRunRecordings = 50
+
+var (
+	recordRun    bool
+	recordFormat string
+)
+
+// runMetadata describes a recorded run, stored next to the recording as <id>.json
+type runMetadata struct {
+	ID              string    `json:"id"`
+	Command         string    `json:"command"`
+	Args            []string  `json:"args,omitempty"`
+	ConfigFiles     []string  `json:"config_files,omitempty"`
+	Cluster         string    `json:"cluster,omitempty"`
+	KrazeVersion    string    `json:"kraze_version"`
+	Platform        string    `json:"platform"`
+	Started         time.Time `json:"started"`
+	Finished        time.Time `json:"finished"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Status          string    `json:"status"` // succeeded or failed
+	Error           string    `json:"error,omitempty"`
+	Format          string    `json:"format"`
+	Recording       string    `json:"recording"` // File name of the recording in the same directory
+}
+
+// addRecordFlags adds the --record flags to a command wrapped with withRecording
+func addRecordFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&recordRun, "record", false, "Record the run's output and metadata under ~/.kraze/runs/")
+	cmd.Flags().StringVar(&recordFormat, "record-format", string(ui.RecordingCast), "Recording format: cast (asciicast v2, replay with 'asciinema play') or text")
+	cmd.RegisterFlagCompletionFunc("record-format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{string(ui.RecordingCast), string(ui.RecordingText)}, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// withRecording wraps a command's RunE so that, with --record, everything it
+// prints is recorded to ~/.kraze/runs/<id>.cast (or .txt) with <id>.json metadata
+func withRecording(run func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if !recordRun {
+			return run(cmd, args)
+		}
+
+		format, err := ui.ParseRecordingFormat(recordFormat)
+		if err != nil {
+			return err
+		}
+		dir, err := runsDir()
+		if err != nil {
+			return err
+		}
+
+		// Cursor-up redraws make unreadable transcripts, so text recordings use scrolling output
+		if format == ui.RecordingText {
+			plain = true
+		}
+
+		id := newRunID(dir, cmd.Name(), time.Now())
+		recordingName := id + format.Extension()
+		commandLine := strings.TrimSpace("kraze " + cmd.Name() + " " + strings.Join(args, " "))
+
+		rec, err := ui.StartRecording(filepath.Join(dir, recordingName), format, commandLine)
+		if err != nil {
+			return err
+		}
+
+		runErr := run(cmd, args)
+		if err := rec.Stop(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+
+		meta := runMetadata{
+			ID:           id,
+			Command:      commandLine,
+			Args:         args,
+			KrazeVersion: version,
+			Platform:     runtime.GOOS + "/" + runtime.GOARCH,
+			Started:      rec.Started(),
+			Finished:     time.Now(),
+			Status:       "succeeded",
+			Format:       string(format),
+			Recording:    recordingName,
+		}
+		meta.DurationSeconds = meta.Finished.Sub(meta.Started).Seconds()
+		if runErr != nil {
+			meta.Status = "failed"
+			meta.Error = runErr.Error()
+		}
+		meta.ConfigFiles, meta.Cluster = recordedConfig(cmd)
+
+		if err := writeRunMetadata(dir, &meta); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Run recorded to %s\n", filepath.Join(dir, recordingName))
+		}
+		if err := pruneRunRecordings(dir, maxRunRecordings); err != nil {
+			Verbose("Failed to prune old run recordings: %v", err)
+		}
+
+		return runErr
+	}
+}
+
+// runsDir returns ~/.kraze/runs, creating it if needed
+func runsDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	dir := filepath.Join(homeDir, ".kraze", "runs")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create runs directory: %w", err)
+	}
+	return dir, nil
+}
+
+// newRunID returns a sortable run id such as 20261018-153012-up, adding a
+// counter when a run with the same id already exists
+func newRunID(dir, command string, started time.Time) string {
+	base := started.Format("20060102-150405") + "-" + command
+	id := base
+	for itr := 2; ; itr++ {
+		if _, err := os.Stat(filepath.Join(dir, id+".json")); os.IsNotExist(err) {
+			return id
+		}
+		id = fmt.Sprintf("%s-%d", base, itr)
+	}
+}
+
+// recordedConfig returns the config files and cluster name of a run, best effort
+func recordedConfig(cmd *cobra.Command) ([]string, string) {
+	cfgPaths, err := resolveConfigFiles(cmd)
+	if err != nil {
+		return nil, ""
+	}
+	for itr, cfgPath := range cfgPaths {
+		if absPath, err := filepath.Abs(cfgPath); err == nil {
+			cfgPaths[itr] = absPath
+		}
+	}
+
+	extracted, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	defer cleanupPack()
+	if err != nil {
+		return cfgPaths, ""
+	}
+	cfg, err := config.ParseMultiple(extracted)
+	if err != nil {
+		return cfgPaths, ""
+	}
+	return cfgPaths, cfg.Cluster.Name
+}
+
+// writeRunMetadata writes <id>.json next to the recording
+func writeRunMetadata(dir string, meta *runMetadata) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode run metadata: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, meta.ID+".json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write run metadata: %w", err)
+	}
+	return nil
+}
+
+// pruneRunRecordings removes the oldest recorded runs so at most keep remain.
+// Run ids sort chronologically, so the oldest runs come first by name.
+func pruneRunRecordings(dir string, keep int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		if name := entry.Name(); strings.HasSuffix(name, ".json") {
+			ids = append(ids, strings.TrimSuffix(name, ".json"))
+		}
+	}
+	if len(ids) <= keep {
+		return nil
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids[:len(ids)-keep] {
+		for _, ext := range []string{".json", ui.RecordingCast.Extension(), ui.RecordingText.Extension()} {
+			if err := os.Remove(filepath.Join(dir, id+ext)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
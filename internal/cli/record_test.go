@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestNewRunID(test *testing.T) {
+	dir := test.TempDir()
+	started := time.Date(2026, 10, 18, 15, 30, 12, 0, time.Local)
+
+	if id := newRunID(dir, "up", started); id != "20261018-153012-up" {
+		test.Errorf("newRunID() = %s, want 20261018-153012-up", id)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "20261018-153012-up.json"), []byte("{}"), 0644); err != nil {
+		test.Fatal(err)
+	}
+	if id := newRunID(dir, "up", started); id != "20261018-153012-up-2" {
+		test.Errorf("newRunID() with existing run = %s, want 20261018-153012-up-2", id)
+	}
+}
+
+func TestPruneRunRecordings(test *testing.T) {
+	dir := test.TempDir()
+	runs := []string{"20261016-090000-up", "20261017-090000-down", "20261018-090000-up"}
+	for _, id := range runs {
+		for _, ext := range []string{".json", ".cast"} {
+			if err := os.WriteFile(filepath.Join(dir, id+ext), []byte("x"), 0644); err != nil {
+				test.Fatal(err)
+			}
+		}
+	}
+
+	if err := pruneRunRecordings(dir, 2); err != nil {
+		test.Fatalf("pruneRunRecordings() error = %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		test.Fatal(err)
+	}
+	var remaining []string
+	for _, entry := range entries {
+		remaining = append(remaining, entry.Name())
+	}
+	sort.Strings(remaining)
+
+	expected := []string{
+		"20261017-090000-down.cast", "20261017-090000-down.json",
+		"20261018-090000-up.cast", "20261018-090000-up.json",
+	}
+	if len(remaining) != len(expected) {
+		test.Fatalf("remaining = %v, want %v", remaining, expected)
+	}
+	for itr := range expected {
+		if remaining[itr] != expected[itr] {
+			test.Errorf("remaining = %v, want %v", remaining, expected)
+			break
+		}
+	}
+}
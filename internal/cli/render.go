@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/graph"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var (
+	renderLabels    []string
+	renderOutputDir string
+)
+
+var renderCmd = &cobra.Command{
+	Use:   "render [services...]",
+	Short: "Print the manifests kraze would apply",
+	Long: `Render everything 'kraze up' would apply, without a cluster or Docker: Helm charts
+templated with their merged values (including CRDs and hooks), and raw manifests with
+their namespaces, kraze's tracking labels and config hash annotations filled in.
+Namespaces kraze would create are included before the first service that uses them.
+
+Services are rendered in dependency order. By default everything is printed to stdout
+as one YAML stream; with --output-dir each service is written to its own file, prefixed
+with its position in the install order (01-postgres.yaml, 02-api.yaml, ...).
+
+Charts render like 'helm template' (default capabilities, first install). Secrets from
+a service's secrets section are not rendered, since resolving them reads secret stores.
+
+Examples:
+  kraze render                          # Render all services to stdout
+  kraze render api | kubectl diff -f -  # Compare a service with what's running
+  kraze render --label tier=backend     # Render services with label tier=backend
+  kraze render --output-dir ./deploy    # Write one file per service for GitOps`,
+	ValidArgsFunction: getServiceNames,
+	RunE:              runRender,
+}
+
+func runRender(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanupPack()
+
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	if len(args) > 0 && len(renderLabels) > 0 {
+		return fmt.Errorf("cannot specify both service names and labels, use one or the other")
+	}
+	if len(renderLabels) > 0 {
+		filteredServices, err := cfg.FilterServicesByLabelsWithDependencies(renderLabels)
+		if err != nil {
+			return fmt.Errorf("failed to filter services by labels: %w", err)
+		}
+		cfg.Services = filteredServices
+	} else if len(args) > 0 {
+		filteredServices, err := cfg.FilterServicesWithDependencies(args)
+		if err != nil {
+			return fmt.Errorf("failed to filter services: %w", err)
+		}
+		cfg.Services = filteredServices
+	}
+
+	depGraph := graph.NewDependencyGraph(cfg.Services)
+	if err := depGraph.Validate(); err != nil {
+		return fmt.Errorf("dependency validation failed: %w", err)
+	}
+	serviceLevels, err := depGraph.TopologicalSortByLevel()
+	if err != nil {
+		return fmt.Errorf("failed to resolve dependencies: %w", err)
+	}
+
+	if renderOutputDir != "" && !dryRun {
+		if err := os.MkdirAll(renderOutputDir, 0o755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", renderOutputDir, err)
+		}
+	}
+
+	seenNamespaces := make(map[string]bool)
+	position := 0
+	for _, level := range serviceLevels {
+		for _, svc := range level {
+			if !svc.IsEnabled() {
+				Verbose("Skipping disabled service '%s'", svc.Name)
+				continue
+			}
+			position++
+
+			resources, err := providers.RenderForApply(ctx, svc, &providers.ProviderOptions{
+				ClusterName:     cfg.Cluster.Name,
+				Verbose:         verbose,
+				Quiet:           true,
+				SuspendCronJobs: cfg.ShouldSuspendCronJobs(svc),
+				Registries:      cfg.Registries,
+			})
+			if err != nil {
+				return fmt.Errorf("service '%s': %w", svc.Name, err)
+			}
+			resources = withServiceNamespace(svc, resources, seenNamespaces)
+
+			data, err := providers.MarshalResources(resources)
+			if err != nil {
+				return fmt.Errorf("service '%s': %w", svc.Name, err)
+			}
+
+			if renderOutputDir == "" {
+				if position > 1 {
+					fmt.Println("---")
+				}
+				fmt.Printf("# Service: %s\n%s", svc.Name, data)
+				continue
+			}
+
+			path := filepath.Join(renderOutputDir, renderFileName(position, svc.Name))
+			if dryRun {
+				fmt.Printf("[DRY RUN] Would write %d resource(s) for '%s' to %s\n", len(resources), svc.Name, path)
+				continue
+			}
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", path, err)
+			}
+			fmt.Printf("%s Wrote %d resource(s) for '%s' to %s\n", color.Checkmark(), len(resources), svc.Name, path)
+		}
+	}
+	return nil
+}
+
+// withServiceNamespace puts the service's namespace in front of its resources
+// when kraze would create it and no earlier service already did
+func withServiceNamespace(svc *config.ServiceConfig, resources []*unstructured.Unstructured, seen map[string]bool) []*unstructured.Unstructured {
+	namespace := svc.GetNamespace()
+	if !svc.ShouldCreateNamespace() || seen[namespace] {
+		return resources
+	}
+	seen[namespace] = true
+	for _, obj := range resources {
+		// The manifests may define the namespace themselves
+		if obj.GetKind() == "Namespace" && obj.GetName() == namespace {
+			return resources
+		}
+	}
+	return append([]*unstructured.Unstructured{providers.NamespaceObject(namespace)}, resources...)
+}
+
+// renderFileName names a service's file so a directory listing follows install order
+func renderFileName(position int, serviceName string) string {
+	return fmt.Sprintf("%02d-%s.yaml", position, strings.ReplaceAll(serviceName, string(filepath.Separator), "_"))
+}
+
+func init() {
+	renderCmd.Flags().StringSliceVarP(&renderLabels, "label", "l", []string{}, "Filter services by label (format: key=value, can be specified multiple times)")
+	registerLabelCompletion(renderCmd)
+	renderCmd.Flags().StringVarP(&renderOutputDir, "output-dir", "d", "", "Write one file per service to this directory instead of stdout")
+}
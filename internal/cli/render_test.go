@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/providers"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestWithServiceNamespace(test *testing.T) {
+	noCreate := false
+	deployment := &unstructured.Unstructured{}
+	deployment.SetKind("Deployment")
+	deployment.SetName("api")
+
+	tests := []struct {
+		name      string
+		service   *config.ServiceConfig
+		resources []*unstructured.Unstructured
+		seen      map[string]bool
+		expected  []string
+	}{
+		{
+			name:      "namespace kraze creates",
+			service:   &config.ServiceConfig{Name: "api", Namespace: "app"},
+			resources: []*unstructured.Unstructured{deployment},
+			seen:      map[string]bool{},
+			expected:  []string{"Namespace/app", "Deployment/api"},
+		},
+		{
+			name:      "created by an earlier service",
+			service:   &config.ServiceConfig{Name: "api", Namespace: "app"},
+			resources: []*unstructured.Unstructured{deployment},
+			seen:      map[string]bool{"app": true},
+			expected:  []string{"Deployment/api"},
+		},
+		{
+			name:      "create_namespace disabled",
+			service:   &config.ServiceConfig{Name: "api", Namespace: "app", CreateNamespace: &noCreate},
+			resources: []*unstructured.Unstructured{deployment},
+			seen:      map[string]bool{},
+			expected:  []string{"Deployment/api"},
+		},
+		{
+			name:      "defined by the manifests",
+			service:   &config.ServiceConfig{Name: "api", Namespace: "app"},
+			resources: []*unstructured.Unstructured{providers.NamespaceObject("app"), deployment},
+			seen:      map[string]bool{},
+			expected:  []string{"Namespace/app", "Deployment/api"},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			got := withServiceNamespace(tt.service, tt.resources, tt.seen)
+			if len(got) != len(tt.expected) {
+				test.Fatalf("withServiceNamespace() returned %d resources, want %v", len(got), tt.expected)
+			}
+			for itr, obj := range got {
+				if ref := obj.GetKind() + "/" + obj.GetName(); ref != tt.expected[itr] {
+					test.Errorf("resource %d = %s, want %s", itr, ref, tt.expected[itr])
+				}
+			}
+			if !tt.seen["app"] && tt.service.ShouldCreateNamespace() {
+				test.Error("namespace not recorded as seen")
+			}
+		})
+	}
+}
+
+func TestRenderFileName(test *testing.T) {
+	if got := renderFileName(3, "postgres"); got != "03-postgres.yaml" {
+		test.Errorf("renderFileName() = %s, want 03-postgres.yaml", got)
+	}
+}
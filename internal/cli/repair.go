@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var repairCmd = &cobra.Command{
+	Use:   "repair",
+	Short: "Re-apply known fixes to a broken kind cluster",
+	Long: `Run the fixes kraze applies while creating a cluster against an existing one.
+Useful after a host reboot, a Docker restart, or a cluster that came up half-broken.
+
+Repair starts stopped node containers, re-applies the kubelet cgroup workaround,
+restarts containerd or the kubelet where they aren't active, reconnects the
+control-plane to the host network, restarts control-plane components that aren't
+running, then re-runs update-ca-certificates, re-creates the containerd hosts.toml
+files for insecure registries, re-copies registry credentials (picking up a fresh
+'docker login'), and re-patches ~/.kube/config.
+
+Every step is idempotent, so repair is safe to run on a healthy cluster.
+
+Examples:
+  kraze repair
+  kraze repair -f dev.yml`,
+	Args: cobra.NoArgs,
+	RunE: runRepair,
+}
+
+func runRepair(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanupPack()
+	Verbose("Repairing cluster from config file(s): %s", strings.Join(cfgPaths, ", "))
+
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := requireCluster(cfg, "repair"); err != nil {
+		return err
+	}
+
+	if cfg.Cluster.IsExternal() {
+		return fmt.Errorf("repair is only supported for kind clusters, not external clusters")
+	}
+
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would repair kind cluster '%s'\n", cfg.Cluster.Name)
+		return nil
+	}
+
+	unlock, err := lockCluster(ctx, cfg.Cluster.Name, "repair", waitForLock)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	Verbose("Checking Docker availability...")
+	if err := cluster.CheckDockerAvailable(ctx); err != nil {
+		return err
+	}
+
+	kindMgr := cluster.NewKindManager()
+	kindMgr.UseRegistries(cfg.Registries)
+
+	exists, err := kindMgr.ClusterExists(cfg.Cluster.Name)
+	if err != nil {
+		return fmt.Errorf("failed to check cluster: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("cluster '%s' does not exist. Run 'kraze up' first", cfg.Cluster.Name)
+	}
+
+	fmt.Printf("Repairing kind cluster '%s'...\n", cfg.Cluster.Name)
+	steps := kindMgr.RepairCluster(ctx, &cfg.Cluster)
+	fmt.Println()
+	printRepairSteps(steps)
+
+	failed := 0
+	for _, step := range steps {
+		if step.Status == cluster.RepairFailed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d repair step(s) failed", failed)
+	}
+
+	recordClusterActivity(cfg.Cluster.Name)
+	fmt.Printf("\n%s Cluster '%s' repaired\n", color.Checkmark(), cfg.Cluster.Name)
+	return nil
+}
+
+// printRepairSteps prints one line per repair step with a status icon
+func printRepairSteps(steps []cluster.RepairStep) {
+	width := 0
+	for _, step := range steps {
+		width = max(width, len(step.Name))
+	}
+
+	for _, step := range steps {
+		icon := color.Checkmark()
+		switch step.Status {
+		case cluster.RepairFixed:
+			icon = color.Cyan("↻")
+		case cluster.RepairFailed:
+			icon = color.Cross()
+		case cluster.RepairSkipped:
+			icon = color.Gray("-")
+		}
+		fmt.Printf("%s %-*s  %s\n", icon, width, step.Name, step.Message)
+	}
+}
+
+func init() {
+	addLockFlag(repairCmd)
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/hjames9/kraze/internal/config"
 	"github.com/hjames9/kraze/internal/pack"
 	"github.com/hjames9/kraze/internal/providers"
 	"github.com/hjames9/kraze/internal/state"
@@ -16,10 +17,14 @@ import (
 
 var (
 	// Global flags
-	configFiles []string
-	verbose     bool
-	dryRun      bool
-	plain       bool
+	configFiles   []string
+	verbose       bool
+	dryRun        bool
+	plain         bool
+	variables     []string
+	suffix        string
+	suffixCluster bool
+	offline       bool
 
 	// Version information
 	version   string
@@ -38,12 +43,30 @@ Manage kind clusters and orchestrate the installation, upgrade, and removal of
 services defined in a declarative YAML configuration file.`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		startRunLog(cmd)
+		overrides, err := config.ParseVariableOverrides(variables)
+		if err != nil {
+			return err
+		}
+		config.SetVariableOverrides(overrides)
+		if err := config.SetVariant(suffix, suffixCluster); err != nil {
+			return err
+		}
+		state.UseVariant(suffix)
+		providers.UseOffline(offline)
+		return startTrace(cmd)
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() error {
-	return rootCmd.Execute()
+	err := rootCmd.Execute()
+	finishTrace(err)
+	closeEvents()
+	finishRunLog(err)
+	return err
 }
 
 func init() {
@@ -52,21 +75,61 @@ func init() {
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
 	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Show what would happen without executing")
 	rootCmd.PersistentFlags().BoolVar(&plain, "plain", false, "Use plain scrolling output instead of interactive mode")
+	rootCmd.PersistentFlags().BoolVar(&traceRun, "trace", false, "Print a timing breakdown of the command's phases when it finishes")
+	rootCmd.PersistentFlags().BoolVar(&saveLog, "save-log", false, "Write this run's verbose log to ~/.kraze/logs, even without -v (or set KRAZE_SAVE_LOG=true); show it with 'kraze logs --self'")
+	rootCmd.PersistentFlags().StringVar(&suffix, "suffix", "", "Run a variant of the environment whose namespaces (and state) get this suffix, so several can share a cluster (e.g., --suffix pr-123)")
+	rootCmd.PersistentFlags().BoolVar(&suffixCluster, "suffix-cluster", false, "Give the --suffix variant its own kind cluster, named with the suffix and with host ports picked by Docker")
+	rootCmd.RegisterFlagCompletionFunc("suffix", getSuffixCompletions)
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "Read remote manifests from the download cache instead of fetching them (fails for manifests that were never downloaded)")
+	rootCmd.PersistentFlags().StringArrayVar(&variables, "var", []string{}, "Set a ${NAME} variable for the config and values files, overriding the environment (format: NAME=VALUE, can be specified multiple times)")
 
 	// Add subcommands
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(upCmd)
 	rootCmd.AddCommand(downCmd)
 	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(describeCmd)
+	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(destroyCmd)
+	rootCmd.AddCommand(stopCmd)
+	rootCmd.AddCommand(startCmd)
+	rootCmd.AddCommand(repairCmd)
+	rootCmd.AddCommand(shellCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(configCmd)
 	rootCmd.AddCommand(planCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(renderCmd)
 	rootCmd.AddCommand(loadImageCmd)
 	rootCmd.AddCommand(listImagesCmd)
+	rootCmd.AddCommand(imagesCmd)
 	rootCmd.AddCommand(portForwardCmd)
+	rootCmd.AddCommand(forwardCmd)
+	rootCmd.AddCommand(agentCmd)
+	rootCmd.AddCommand(portsCmd)
+	rootCmd.AddCommand(logsCmd)
+	rootCmd.AddCommand(devCmd)
+	rootCmd.AddCommand(debugCmd)
+	rootCmd.AddCommand(runCronCmd)
+	rootCmd.AddCommand(waitCmd)
+	rootCmd.AddCommand(assertCmd)
+	rootCmd.AddCommand(seedCmd)
+	rootCmd.AddCommand(metricsCmd)
+	rootCmd.AddCommand(chartDocsCmd)
+	rootCmd.AddCommand(chartCmd)
 	rootCmd.AddCommand(completionCmd)
+	rootCmd.AddCommand(manCmd)
 	rootCmd.AddCommand(packCmd)
+	rootCmd.AddCommand(bundleCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(trustCmd)
+	rootCmd.AddCommand(snapshotCmd)
+	rootCmd.AddCommand(volumeCmd)
+	rootCmd.AddCommand(netCmd)
+	rootCmd.AddCommand(sbomCmd)
+	rootCmd.AddCommand(convertCmd)
+	rootCmd.AddCommand(idleWatchCmd)
 }
 
 // resolveConfigFiles returns the absolute paths to the config files to use.
@@ -108,11 +171,11 @@ func resolveConfigFiles(cmd *cobra.Command) ([]string, error) {
 			if err != nil {
 				continue
 			}
-			clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig, true)
+			clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig)
 			if err != nil {
 				continue
 			}
-			st, err := state.Load(ctx, clientset, clusterName)
+			st, err := state.Load(ctx, clientset, clusterName, "")
 			if err != nil || st == nil {
 				continue
 			}
@@ -201,9 +264,15 @@ func IsDryRun() bool {
 	return dryRun
 }
 
-// Verbose prints a message only if verbose mode is enabled
+// Verbose prints a message only if verbose mode is enabled, and writes it to
+// the run log when there is one
 func Verbose(format string, args ...interface{}) {
+	writeRunLog(format, args...)
 	if verbose {
+		if verboseToStderr {
+			fmt.Fprintf(os.Stderr, "[VERBOSE] "+format+"\n", args...)
+			return
+		}
 		fmt.Printf("[VERBOSE] "+format+"\n", args...)
 	}
 }
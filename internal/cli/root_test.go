@@ -92,6 +92,16 @@ func TestCommandRegistration(test *testing.T) {
 		"validate",
 		"version",
 		"load-image",
+		"list",
+		"stop",
+		"start",
+		"shell",
+		"forward",
+		"ports",
+		"logs",
+		"dev",
+		"debug",
+		"chart-docs",
 	}
 
 	commandMap := make(map[string]bool)
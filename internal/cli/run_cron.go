@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+)
+
+var runCronCmd = &cobra.Command{
+	Use:   "run-cron <service> [cronjob]",
+	Short: "Run a service's CronJob once, now",
+	Long: `Create a Job from a CronJob of a service, like 'kubectl create job --from=cronjob/<name>'.
+Suspended CronJobs can be run too, which pairs with suspend_cronjobs:
+
+  suspend_cronjobs: true        # All services
+  services:
+    reports:
+      type: helm
+      path: ./charts/reports
+      suspend_cronjobs: true    # Or per service (overrides the global setting)
+
+The cronjob name can be omitted when the service has exactly one CronJob.
+
+Examples:
+  kraze run-cron reports                  # Run the service's only CronJob
+  kraze run-cron reports nightly-export   # Run a specific CronJob`,
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: getServiceNames,
+	RunE:              runRunCron,
+}
+
+func runRunCron(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanupPack()
+
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := requireCluster(cfg, "run-cron"); err != nil {
+		return err
+	}
+
+	svc, exists := cfg.Services[args[0]]
+	if !exists {
+		return fmt.Errorf("service '%s' not found in configuration", args[0])
+	}
+
+	kubeconfig, err := getShellKubeconfig(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+
+	var name string
+	if len(args) > 1 {
+		name = args[1]
+	} else {
+		cronJobs, err := providers.ServiceCronJobs(ctx, clientset, &svc)
+		if err != nil {
+			return err
+		}
+		switch len(cronJobs) {
+		case 0:
+			return fmt.Errorf("service '%s' has no cronjobs in namespace '%s'", svc.Name, svc.GetNamespace())
+		case 1:
+			name = cronJobs[0].Name
+		default:
+			var names []string
+			for _, cronJob := range cronJobs {
+				names = append(names, cronJob.Name)
+			}
+			return fmt.Errorf("service '%s' has several cronjobs, pick one of: %s", svc.Name, strings.Join(names, ", "))
+		}
+	}
+
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would create a job from cronjob '%s' in namespace '%s'\n", name, svc.GetNamespace())
+		return nil
+	}
+
+	job, err := providers.RunCronJob(ctx, clientset, &svc, name)
+	if err != nil {
+		return err
+	}
+
+	recordClusterActivity(cfg.Cluster.Name)
+	fmt.Printf("%s Created job '%s' from cronjob '%s'\n", color.Checkmark(), job.Name, name)
+	fmt.Printf("  Follow it with: kubectl logs -f job/%s -n %s\n", job.Name, job.Namespace)
+	return nil
+}
+
+// reportSuspendedCronJobs lists the CronJobs a service was installed with
+// suspended. Failures are reported as warnings since the service itself is installed.
+func reportSuspendedCronJobs(ctx context.Context, clientset kubernetes.Interface, svc *config.ServiceConfig, logf func(format string, args ...interface{})) {
+	suspended, err := providers.SuspendedCronJobs(ctx, clientset, svc)
+	if err != nil {
+		logf("%s Failed to list the cronjobs of '%s': %v", color.Warning(), svc.Name, err)
+	}
+	for _, name := range suspended {
+		logf("Suspended cronjob '%s' of '%s' (run it with 'kraze run-cron %s %s')", name, svc.Name, svc.Name, name)
+	}
+}
@@ -0,0 +1,307 @@
+package cli
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// maxRunLogBytes caps the total size of the logs kept in ~/.kraze/logs
+	maxRunLogBytes = 50 * 1024 * 1024
+	// maxRunLogs is how many run logs are kept in ~/.kraze/logs
+	maxRunLogs = 200
+	// runLogActiveWindow is how long a log is left alone after it was last
+	// written, since another kraze process may still be writing it
+	runLogActiveWindow = time.Hour
+
+	runLogExtension           = ".log"
+	compressedRunLogExtension = ".log.gz"
+)
+
+var (
+	saveLog bool
+
+	// runLog is the log of this run when per-run file logging is enabled
+	runLog *os.File
+)
+
+// runLogEnabled reports whether this run writes its verbose log to ~/.kraze/logs,
+// with --save-log or KRAZE_SAVE_LOG=true
+func runLogEnabled() bool {
+	if saveLog {
+		return true
+	}
+	enabled, _ := strconv.ParseBool(os.Getenv("KRAZE_SAVE_LOG"))
+	return enabled
+}
+
+// logsDir returns ~/.kraze/logs, creating it if needed
+func logsDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	dir := filepath.Join(homeDir, ".kraze", "logs")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create logs directory: %w", err)
+	}
+	return dir, nil
+}
+
+// startRunLog opens this run's log, which gets every verbose message whether or
+// not -v is given, and compresses and prunes the logs of earlier runs. Failing
+// to log never fails the command.
+func startRunLog(cmd *cobra.Command) {
+	if !runLogEnabled() || runLog != nil {
+		return
+	}
+	dir, err := logsDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return
+	}
+
+	started := time.Now()
+	base := started.Format("20060102-150405") + "-" + cmd.Name()
+	name := base
+	for itr := 2; ; itr++ {
+		runLog, err = os.OpenFile(filepath.Join(dir, name+runLogExtension), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if !os.IsExist(err) {
+			break
+		}
+		name = fmt.Sprintf("%s-%d", base, itr)
+	}
+	if err != nil {
+		runLog = nil
+		fmt.Fprintf(os.Stderr, "Warning: failed to create run log: %v\n", err)
+		return
+	}
+
+	fmt.Fprintf(runLog, "# %s\n", strings.Join(append([]string{"kraze"}, os.Args[1:]...), " "))
+	fmt.Fprintf(runLog, "# kraze %s, %s/%s, started %s\n", version, runtime.GOOS, runtime.GOARCH, started.Format(time.RFC3339))
+
+	if err := rotateRunLogs(dir, filepath.Base(runLog.Name()), started); err != nil {
+		writeRunLog("Failed to rotate run logs: %v", err)
+	}
+}
+
+// finishRunLog records how the run ended and closes its log
+func finishRunLog(runErr error) {
+	if runLog == nil {
+		return
+	}
+	if runErr != nil {
+		writeRunLog("Failed: %v", runErr)
+	} else {
+		writeRunLog("Succeeded")
+	}
+	runLog.Close()
+	runLog = nil
+}
+
+// writeRunLog appends a timestamped line to this run's log, if there is one
+func writeRunLog(format string, args ...interface{}) {
+	if runLog == nil {
+		return
+	}
+	fmt.Fprintf(runLog, "%s "+format+"\n", append([]interface{}{time.Now().Format("15:04:05.000")}, args...)...)
+}
+
+// runLogFile is a log in ~/.kraze/logs
+type runLogFile struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+// rotateRunLogs gzips the logs of earlier runs, then removes the oldest logs
+// until at most maxRunLogs remain and they take at most maxRunLogBytes. The
+// current log and logs written within runLogActiveWindow are left alone.
+func rotateRunLogs(dir, current string, now time.Time) error {
+	logs, err := listRunLogs(dir)
+	if err != nil {
+		return err
+	}
+	isActive := func(log runLogFile) bool {
+		return log.name == current || now.Sub(log.modTime) < runLogActiveWindow
+	}
+
+	for itr, log := range logs {
+		if !strings.HasSuffix(log.name, runLogExtension) || isActive(log) {
+			continue
+		}
+		compressed, err := compressRunLog(dir, log.name)
+		if err != nil {
+			return err
+		}
+		logs[itr] = compressed
+	}
+
+	var total int64
+	for _, log := range logs {
+		total += log.size
+	}
+	count := len(logs)
+	for _, log := range logs {
+		if count <= maxRunLogs && total <= maxRunLogBytes {
+			break
+		}
+		if isActive(log) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, log.name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		count--
+		total -= log.size
+	}
+	return nil
+}
+
+// listRunLogs returns the logs in dir, oldest first. Names start with the run's
+// start time, so they sort chronologically.
+func listRunLogs(dir string) ([]runLogFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var logs []runLogFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || (!strings.HasSuffix(name, runLogExtension) && !strings.HasSuffix(name, compressedRunLogExtension)) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		logs = append(logs, runLogFile{name: name, size: info.Size(), modTime: info.ModTime()})
+	}
+	sort.Slice(logs, func(i, j int) bool {
+		return logs[i].name < logs[j].name
+	})
+	return logs, nil
+}
+
+// compressRunLog replaces a log with a gzipped copy, keeping its modification time
+func compressRunLog(dir, name string) (runLogFile, error) {
+	source := filepath.Join(dir, name)
+	target := source + ".gz"
+
+	in, err := os.Open(source)
+	if err != nil {
+		return runLogFile{}, err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return runLogFile{}, err
+	}
+
+	out, err := os.Create(target)
+	if err != nil {
+		return runLogFile{}, err
+	}
+	writer := gzip.NewWriter(out)
+	_, err = io.Copy(writer, in)
+	if closeErr := writer.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(target)
+		return runLogFile{}, fmt.Errorf("failed to compress %s: %w", name, err)
+	}
+
+	in.Close()
+	if err := os.Remove(source); err != nil {
+		return runLogFile{}, err
+	}
+	os.Chtimes(target, info.ModTime(), info.ModTime())
+
+	compressed, err := os.Stat(target)
+	if err != nil {
+		return runLogFile{}, err
+	}
+	return runLogFile{name: name + ".gz", size: compressed.Size(), modTime: info.ModTime()}, nil
+}
+
+// printLastRunLog prints the log of the last kraze run before this one, or its
+// last tail lines when tail is not negative
+func printLastRunLog(out io.Writer, tail int64) error {
+	dir, err := logsDir()
+	if err != nil {
+		return err
+	}
+	logs, err := listRunLogs(dir)
+	if err != nil {
+		return err
+	}
+	current := ""
+	if runLog != nil {
+		current = filepath.Base(runLog.Name())
+	}
+
+	for itr := len(logs) - 1; itr >= 0; itr-- {
+		if logs[itr].name == current {
+			continue
+		}
+		path := filepath.Join(dir, logs[itr].name)
+		fmt.Fprintf(os.Stderr, "Showing %s\n", path)
+		return copyRunLog(out, path, tail)
+	}
+	return fmt.Errorf("no kraze logs in %s; enable them with --save-log or KRAZE_SAVE_LOG=true", dir)
+}
+
+// copyRunLog writes a plain or gzipped log to out
+func copyRunLog(out io.Writer, path string, tail int64) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+	if tail < 0 {
+		_, err = io.Copy(out, reader)
+		return err
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if int64(len(lines)) > tail {
+			lines = lines[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	for _, line := range lines {
+		fmt.Fprintln(out, line)
+	}
+	return nil
+}
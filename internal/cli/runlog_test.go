@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotateRunLogs(test *testing.T) {
+	dir := test.TempDir()
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.Local)
+	logs := []struct {
+		name string
+		age  time.Duration
+		size int
+	}{
+		{name: "20261016-090000-up.log.gz", age: 48 * time.Hour, size: maxRunLogBytes},
+		{name: "20261017-090000-up.log", age: 24 * time.Hour, size: 1024},
+		{name: "20261018-113000-forward.log", age: 30 * time.Minute, size: 1024},
+		{name: "20261018-120000-up.log", size: 10},
+		{name: "notes.txt", age: 72 * time.Hour, size: 10},
+	}
+	for _, itr := range logs {
+		path := filepath.Join(dir, itr.name)
+		if err := os.WriteFile(path, bytes.Repeat([]byte("x"), itr.size), 0644); err != nil {
+			test.Fatal(err)
+		}
+		modTime := now.Add(-itr.age)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			test.Fatal(err)
+		}
+	}
+
+	if err := rotateRunLogs(dir, "20261018-120000-up.log", now); err != nil {
+		test.Fatalf("rotateRunLogs() error = %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		test.Fatal(err)
+	}
+	var remaining []string
+	for _, entry := range entries {
+		remaining = append(remaining, entry.Name())
+	}
+	sort.Strings(remaining)
+
+	// The oldest log is removed to get under the size cap, the finished log is
+	// compressed, and the recently written and current logs are left alone
+	expected := []string{"20261017-090000-up.log.gz", "20261018-113000-forward.log", "20261018-120000-up.log", "notes.txt"}
+	if strings.Join(remaining, ",") != strings.Join(expected, ",") {
+		test.Errorf("Expected %v to remain, got %v", expected, remaining)
+	}
+
+	var out bytes.Buffer
+	if err := copyRunLog(&out, filepath.Join(dir, "20261017-090000-up.log.gz"), -1); err != nil {
+		test.Fatalf("copyRunLog() error = %v", err)
+	}
+	if out.Len() != 1024 {
+		test.Errorf("Expected the compressed log to read back 1024 bytes, got %d", out.Len())
+	}
+}
+
+func TestCopyRunLogTail(test *testing.T) {
+	path := filepath.Join(test.TempDir(), "run.log")
+	if err := os.WriteFile(path, []byte("one\ntwo\nthree\n"), 0644); err != nil {
+		test.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		tail     int64
+		expected string
+	}{
+		{name: "all", tail: -1, expected: "one\ntwo\nthree\n"},
+		{name: "last two", tail: 2, expected: "two\nthree\n"},
+		{name: "more than the log", tail: 10, expected: "one\ntwo\nthree\n"},
+		{name: "none", tail: 0, expected: ""},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			var out bytes.Buffer
+			if err := copyRunLog(&out, path, tt.tail); err != nil {
+				test.Fatalf("copyRunLog() error = %v", err)
+			}
+			if out.String() != tt.expected {
+				test.Errorf("Expected %q, got %q", tt.expected, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/hjames9/kraze/internal/sbom"
+	"github.com/hjames9/kraze/internal/state"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	sbomFormat string
+	sbomOutput string
+)
+
+var sbomCmd = &cobra.Command{
+	Use:   "sbom [services...]",
+	Short: "Write a software bill of materials of the charts and images the environment runs",
+	Long: `Write a combined SBOM of the installed services: the Helm charts (and subcharts)
+their releases were installed from, and the container images their pods run,
+so security teams can audit exactly what runs locally.
+
+Images are identified by the digest the nodes resolved when pulling them, or by
+their image ID when they were built or loaded locally. Services whose pods
+aren't running list the images found in their config instead, without digests.
+kraze doesn't look inside images; feed the SBOM to a scanner such as syft or
+grype for their packages.
+
+Formats:
+  cyclonedx  CycloneDX 1.5 JSON (default)
+  spdx       SPDX 2.3 JSON
+
+Examples:
+  kraze sbom > environment.cdx.json
+  kraze sbom --format spdx -o environment.spdx.json
+  kraze sbom api worker               # Only some services`,
+	ValidArgsFunction: getServiceNames,
+	RunE:              runSBOM,
+}
+
+func runSBOM(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if !slices.Contains(sbom.Formats, sbomFormat) {
+		return fmt.Errorf("unknown SBOM format '%s' (expected %s)", sbomFormat, strings.Join(sbom.Formats, " or "))
+	}
+	// The SBOM goes to stdout unless written to a file
+	if sbomOutput == "" {
+		verboseToStderr = true
+	}
+
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanupPack()
+
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := requireCluster(cfg, "sbom"); err != nil {
+		return err
+	}
+	for _, name := range args {
+		if _, ok := cfg.Services[name]; !ok {
+			return fmt.Errorf("service '%s' not found in config", name)
+		}
+	}
+
+	kubeconfig, err := getShellKubeconfig(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	st, err := state.Load(ctx, clientset, cfg.Cluster.Name, cfg.StateBackend)
+	if err != nil {
+		return fmt.Errorf("failed to load cluster state: %w", err)
+	}
+	if st == nil || len(st.GetInstalledServices()) == 0 {
+		return fmt.Errorf("no services are installed in cluster '%s'", cfg.Cluster.Name)
+	}
+
+	names := args
+	if len(names) == 0 {
+		names = cfg.GetAllServiceNames()
+	}
+	sort.Strings(names)
+
+	inv := &sbom.Inventory{Cluster: cfg.Cluster.Name}
+	for _, name := range names {
+		svc := cfg.Services[name]
+		if !svc.IsEnabled() || !st.IsServiceInstalled(name) {
+			Verbose("Skipping '%s' (not installed)", name)
+			continue
+		}
+		if err := addServiceToInventory(ctx, inv, &svc, cfg, kubeconfig, clientset); err != nil {
+			return fmt.Errorf("failed to inventory '%s': %w", name, err)
+		}
+	}
+	inv.Sort()
+
+	data, err := sbom.Write(inv, sbomFormat, sbom.Document{Timestamp: time.Now(), ToolVersion: version})
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if sbomOutput == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(sbomOutput, data, 0644); err != nil {
+		return fmt.Errorf("failed to write SBOM: %w", err)
+	}
+	fmt.Printf("%s Wrote %s SBOM of %d chart(s) and %d image(s) to %s\n", color.Checkmark(), sbomFormat, len(inv.Charts), len(inv.Images), sbomOutput)
+	return nil
+}
+
+// addServiceToInventory adds the chart and images of an installed service,
+// merging them with those of services already added
+func addServiceToInventory(ctx context.Context, inv *sbom.Inventory, svc *config.ServiceConfig, cfg *config.Config, kubeconfig string, clientset kubernetes.Interface) error {
+	if svc.IsHelm() {
+		opts := &providers.ProviderOptions{ClusterName: cfg.Cluster.Name, KubeConfig: kubeconfig, Verbose: verbose, Quiet: true, Registries: cfg.Registries}
+		metadata, err := providers.ReleaseChart(svc, opts)
+		if err != nil {
+			return err
+		}
+		repository := ""
+		if svc.IsRemoteChart() {
+			repository = svc.Repo
+		}
+		addChart(inv, sbomChart(metadata, repository), svc.Name)
+	}
+
+	running, err := providers.ServiceRunningImages(ctx, clientset, svc)
+	if err != nil {
+		return err
+	}
+	if len(running) == 0 {
+		// Nothing runs yet (or only CronJobs), so fall back to what the config references
+		images, err := cluster.NewImageManager(verbose).GetImagesForService(ctx, svc, "")
+		if err != nil {
+			return err
+		}
+		Verbose("No running pods for '%s', listing %d image(s) from its config without digests", svc.Name, len(images))
+		for _, image := range images {
+			running = append(running, providers.RunningImage{Image: image})
+		}
+	}
+	for _, image := range running {
+		addImage(inv, sbom.Image{Name: image.Image, Digest: image.Digest, ImageID: image.ImageID}, svc.Name)
+	}
+	return nil
+}
+
+// sbomChart converts release chart metadata; subcharts come from the same repository
+func sbomChart(metadata *providers.ChartMetadata, repository string) sbom.Chart {
+	chart := sbom.Chart{Name: metadata.Name, Version: metadata.Version, AppVersion: metadata.AppVersion, Repository: repository}
+	for itr := range metadata.Dependencies {
+		chart.Dependencies = append(chart.Dependencies, sbomChart(&metadata.Dependencies[itr], ""))
+	}
+	return chart
+}
+
+// addChart adds a chart to the inventory, or the service to a chart already in it
+func addChart(inv *sbom.Inventory, chart sbom.Chart, service string) {
+	for itr := range inv.Charts {
+		existing := &inv.Charts[itr]
+		if existing.Name == chart.Name && existing.Version == chart.Version && existing.Repository == chart.Repository {
+			existing.Services = append(existing.Services, service)
+			return
+		}
+	}
+	chart.Services = []string{service}
+	inv.Charts = append(inv.Charts, chart)
+}
+
+// addImage adds an image to the inventory, or the service to an image already in it
+func addImage(inv *sbom.Inventory, image sbom.Image, service string) {
+	for itr := range inv.Images {
+		existing := &inv.Images[itr]
+		if existing.Name == image.Name && existing.Digest == image.Digest && existing.ImageID == image.ImageID {
+			if !slices.Contains(existing.Services, service) {
+				existing.Services = append(existing.Services, service)
+			}
+			return
+		}
+	}
+	image.Services = []string{service}
+	inv.Images = append(inv.Images, image)
+}
+
+func init() {
+	sbomCmd.Flags().StringVar(&sbomFormat, "format", sbom.FormatCycloneDX, "SBOM format: cyclonedx or spdx")
+	sbomCmd.Flags().StringVarP(&sbomOutput, "output", "o", "", "File to write the SBOM to (default: stdout)")
+	sbomCmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return sbom.Formats, cobra.ShellCompDirectiveNoFileComp
+	})
+}
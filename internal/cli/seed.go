@@ -0,0 +1,216 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/graph"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/hjames9/kraze/internal/state"
+	"github.com/hjames9/kraze/internal/telemetry"
+	"github.com/hjames9/kraze/internal/ui"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	seedForce  bool
+	seedLabels []string
+)
+
+var seedCmd = &cobra.Command{
+	Use:   "seed [services...]",
+	Short: "Load services' fixtures",
+	Long: `Load the fixtures declared with 'fixtures:' in kraze.yml: SQL files run with the database
+client in the service's pod, HTTP requests sent from inside the cluster, and seed manifests
+applied in the service's namespace.
+
+'kraze up' loads fixtures once each service is ready. A fixture whose files and settings
+haven't changed since it last loaded is skipped, so re-runs don't duplicate data; use
+--force to load every fixture again. Services load in dependency order, and each
+service's fixtures in the order they're declared.
+
+Examples:
+  kraze seed                       # Load new and changed fixtures of all installed services
+  kraze seed postgres              # Load the fixtures of a specific service
+  kraze seed postgres --force      # Load all of its fixtures again
+  kraze seed --label tier=data     # Load fixtures of services with label tier=data`,
+	ValidArgsFunction: getServiceNames,
+	RunE:              runSeed,
+}
+
+func runSeed(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanupPack()
+
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := requireCluster(cfg, "seed"); err != nil {
+		return err
+	}
+
+	if len(args) > 0 && len(seedLabels) > 0 {
+		return fmt.Errorf("cannot specify both service names and labels, use one or the other")
+	}
+	selected := cfg.Services
+	if len(seedLabels) > 0 {
+		if selected, err = cfg.FilterServicesByLabels(seedLabels); err != nil {
+			return fmt.Errorf("failed to filter services by labels: %w", err)
+		}
+	} else if len(args) > 0 {
+		if selected, err = cfg.FilterServices(args); err != nil {
+			return fmt.Errorf("failed to filter services: %w", err)
+		}
+	}
+
+	// Services seed in dependency order, so fixtures can refer to earlier services' data
+	orderedServices, err := graph.NewDependencyGraph(cfg.Services).TopologicalSort()
+	if err != nil {
+		return fmt.Errorf("failed to resolve dependencies: %w", err)
+	}
+
+	kubeconfig, err := getShellKubeconfig(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	st, err := state.Load(ctx, clientset, cfg.Cluster.Name, cfg.StateBackend)
+	if err != nil {
+		return fmt.Errorf("failed to load cluster state: %w", err)
+	}
+
+	var services []*config.ServiceConfig
+	for _, svc := range orderedServices {
+		if _, ok := selected[svc.Name]; !ok || !svc.IsEnabled() || len(svc.Fixtures) == 0 {
+			continue
+		}
+		if !st.IsServiceInstalled(svc.Name) {
+			if len(args) > 0 {
+				return fmt.Errorf("service '%s' isn't installed, run 'kraze up %s' first", svc.Name, svc.Name)
+			}
+			Verbose("Skipping '%s': not installed", svc.Name)
+			continue
+		}
+		services = append(services, svc)
+	}
+	if len(services) == 0 {
+		fmt.Println("No installed services with fixtures")
+		return nil
+	}
+
+	if dryRun {
+		for _, svc := range services {
+			for itr := range svc.Fixtures {
+				fixture := &svc.Fixtures[itr]
+				action := "load"
+				if !seedForce && fixtureUnchanged(st, svc.Name, fixture) {
+					action = "skip unchanged"
+				}
+				fmt.Printf("[DRY RUN] Would %s %s fixture '%s' of '%s'\n", action, fixture.Kind(), fixture.Key(), svc.Name)
+			}
+		}
+		return nil
+	}
+	recordClusterActivity(cfg.Cluster.Name)
+
+	progress := ui.NewProgressManager(verbose, plain, len(services))
+	defer progress.Stop()
+	progress.Start(len(services), "Seeding")
+
+	seeded := 0
+	for index, svc := range services {
+		loaded, err := runServiceFixtures(ctx, svc, index, cfg.Cluster.Name, kubeconfig, clientset, st, seedForce, progress)
+		if err != nil {
+			progress.UpdateService(index, svc.Name, ui.StatusFailed, "Fixture failed")
+			progress.Stop()
+			return err
+		}
+		if loaded == 0 {
+			progress.UpdateService(index, svc.Name, ui.StatusSkipped, "Fixtures unchanged")
+		} else {
+			progress.UpdateService(index, svc.Name, ui.StatusReady, fmt.Sprintf("Loaded %d fixture(s)", loaded))
+		}
+		seeded++
+	}
+	progress.Finish(seeded)
+	return nil
+}
+
+// runServiceFixtures loads a service's fixtures in order and returns how many
+// loaded. Fixtures unchanged since they last loaded are skipped unless force
+// is set. Each checksum is saved as soon as its fixture loads, so a failed run
+// resumes at the fixture that failed.
+func runServiceFixtures(ctx context.Context, svc *config.ServiceConfig, serviceIndex int, clusterName, kubeconfig string, clientset kubernetes.Interface, st *state.ClusterState, force bool, progress ui.ProgressManager) (int, error) {
+	loaded := 0
+	for itr := range svc.Fixtures {
+		fixture := &svc.Fixtures[itr]
+		checksum, err := providers.FixtureChecksum(fixture)
+		if err != nil {
+			return loaded, fmt.Errorf("fixture '%s' of '%s': %w", fixture.Key(), svc.Name, err)
+		}
+		stateMutex.Lock()
+		unchanged := st.GetFixtureChecksum(svc.Name, fixture.Key()) == checksum
+		stateMutex.Unlock()
+		if !force && unchanged {
+			progress.Verbose("Skipping unchanged fixture '%s' of '%s'", fixture.Key(), svc.Name)
+			continue
+		}
+
+		progress.UpdateService(serviceIndex, svc.Name, ui.StatusInstalling, fmt.Sprintf("Loading fixture '%s'", fixture.Key()))
+		progress.Verbose("Loading %s fixture '%s' of '%s'", fixture.Kind(), fixture.Key(), svc.Name)
+		fixtureCtx := &providers.FixtureContext{
+			ClusterName: clusterName,
+			KubeConfig:  kubeconfig,
+			Service:     svc,
+			OnOutput: func(line string) {
+				progress.UpdateService(serviceIndex, svc.Name, ui.StatusInstalling, fmt.Sprintf("%s: %s", fixture.Key(), line))
+				progress.Verbose("  %s [fixture] %s", svc.Name, line)
+			},
+		}
+		fixtureSpanCtx, span := telemetry.StartSpan(ctx, "service.fixture", telemetry.Service(svc.Name))
+		err = providers.LoadFixture(fixtureSpanCtx, fixtureCtx, fixture)
+		telemetry.EndSpan(span, err)
+		if err != nil {
+			if ctx.Err() != nil {
+				return loaded, ctx.Err()
+			}
+			return loaded, fmt.Errorf("failed to seed '%s': %w", svc.Name, err)
+		}
+		loaded++
+
+		stateMutex.Lock()
+		st.SetFixtureChecksum(svc.Name, fixture.Key(), checksum)
+		if err := st.Save(ctx, clientset); err != nil {
+			progress.Verbose("Warning: failed to save cluster state (fixtures): %v", err)
+		}
+		stateMutex.Unlock()
+	}
+	return loaded, nil
+}
+
+// fixtureUnchanged returns true if a fixture loaded before with the same content
+func fixtureUnchanged(st *state.ClusterState, serviceName string, fixture *config.FixtureConfig) bool {
+	checksum, err := providers.FixtureChecksum(fixture)
+	if err != nil {
+		return false
+	}
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+	return st.GetFixtureChecksum(serviceName, fixture.Key()) == checksum
+}
+
+func init() {
+	seedCmd.Flags().BoolVar(&seedForce, "force", false, "Load every fixture, including those unchanged since they last loaded")
+	seedCmd.Flags().StringSliceVarP(&seedLabels, "label", "l", []string{}, "Filter services by label (format: key=value, can be specified multiple times)")
+}
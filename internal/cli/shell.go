@@ -0,0 +1,264 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	shellNamespace string
+	shellPrint     bool
+	shellProgram   string
+)
+
+// shellAliases are defined in interactive kraze shells and printed by 'kraze shell --print'
+var shellAliases = [][2]string{
+	{"k", "kubectl"},
+	{"kgp", "kubectl get pods"},
+	{"kns", "kubectl config set-context --current --namespace"},
+}
+
+var shellCmd = &cobra.Command{
+	Use:   "shell [service]",
+	Short: "Open a subshell with KUBECONFIG pointing at the cluster",
+	Long: `Open a subshell whose KUBECONFIG points at a kraze-managed kubeconfig for
+the cluster, isolated from your primary kubeconfig (~/.kube/config).
+
+The kubeconfig is written to ~/.kraze/clusters/<cluster>/kubeconfig with the
+current namespace set to the given service's namespace (or --namespace).
+Changes made inside the shell, such as switching namespaces, never affect
+your primary kubeconfig.
+
+bash and zsh shells also get a prompt prefix and these aliases:
+  k     kubectl
+  kgp   kubectl get pods
+  kns   kubectl config set-context --current --namespace
+
+Use --print to emit export lines instead of starting a subshell.
+
+Examples:
+  kraze shell                    # Subshell in the default namespace
+  kraze shell postgres           # Subshell in the postgres service's namespace
+  kraze shell -n monitoring      # Subshell in the monitoring namespace
+  eval "$(kraze shell --print)"  # Export into the current shell`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: getServiceNames,
+	RunE:              runShell,
+}
+
+func runShell(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanupPack()
+
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := requireCluster(cfg, "shell"); err != nil {
+		return err
+	}
+
+	if len(args) > 0 && shellNamespace != "" {
+		return fmt.Errorf("cannot specify both a service and --namespace, use one or the other")
+	}
+
+	namespace := shellNamespace
+	if len(args) > 0 {
+		svc, exists := cfg.Services[args[0]]
+		if !exists {
+			return fmt.Errorf("service '%s' not found in configuration", args[0])
+		}
+		namespace = svc.GetNamespace()
+	}
+
+	kubeconfig, err := getShellKubeconfig(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		fmt.Fprintf(os.Stderr, "[DRY RUN] Would write kubeconfig for cluster '%s' and start a subshell\n", cfg.Cluster.Name)
+		return nil
+	}
+
+	kubeconfigPath, err := cluster.WriteIsolatedKubeconfig(cfg.Cluster.Name, kubeconfig, namespace)
+	if err != nil {
+		return err
+	}
+
+	if shellPrint {
+		for _, line := range shellExportLines(kubeconfigPath, cfg.Cluster.Name) {
+			fmt.Println(line)
+		}
+		return nil
+	}
+
+	if os.Getenv("KRAZE_SHELL") != "" {
+		fmt.Fprintf(os.Stderr, "Warning: already inside a kraze shell for cluster '%s'\n", os.Getenv("KRAZE_SHELL"))
+	}
+
+	return spawnKrazeShell(cfg.Cluster.Name, kubeconfigPath, namespace)
+}
+
+// getShellKubeconfig returns the kubeconfig content for the configured cluster.
+// Messages go to stderr so that --print output can be passed to eval.
+func getShellKubeconfig(ctx context.Context, cfg *config.Config) (string, error) {
+	kindMgr := cluster.NewKindManager()
+
+	if cfg.Cluster.IsExternal() {
+		kubeconfig, err := kindMgr.GetKubeconfigForExternalCluster(&cfg.Cluster)
+		if err != nil {
+			return "", fmt.Errorf("failed to get kubeconfig for external cluster: %w", err)
+		}
+		return kubeconfig, nil
+	}
+
+	if err := cluster.CheckDockerAvailable(ctx); err != nil {
+		return "", err
+	}
+
+	exists, err := kindMgr.ClusterExists(cfg.Cluster.Name)
+	if err != nil {
+		return "", fmt.Errorf("failed to check cluster: %w", err)
+	}
+	if !exists {
+		return "", fmt.Errorf("cluster '%s' does not exist, run 'kraze up' first", cfg.Cluster.Name)
+	}
+
+	if err := checkClusterHealth(ctx, kindMgr, &cfg.Cluster); err != nil {
+		return "", err
+	}
+
+	kubeconfig, err := kindMgr.GetKubeConfigQuiet(cfg.Cluster.Name, false, true)
+	if err != nil {
+		return "", fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	recordClusterActivity(cfg.Cluster.Name)
+
+	return kubeconfig, nil
+}
+
+// shellExportLines returns POSIX shell lines that point the current shell at the cluster
+func shellExportLines(kubeconfigPath, clusterName string) []string {
+	lines := []string{
+		fmt.Sprintf("export KUBECONFIG=%s", shellQuote(kubeconfigPath)),
+		fmt.Sprintf("export KRAZE_SHELL=%s", shellQuote(clusterName)),
+	}
+	for _, alias := range shellAliases {
+		lines = append(lines, fmt.Sprintf("alias %s=%s", alias[0], shellQuote(alias[1])))
+	}
+	return lines
+}
+
+// shellRCContent returns an rc script that loads the user's own rc file and then
+// adds the kraze aliases and prompt prefix
+func shellRCContent(userRC, clusterName string) string {
+	var rc strings.Builder
+	rc.WriteString(fmt.Sprintf("[ -f %s ] && . %s\n", shellQuote(userRC), shellQuote(userRC)))
+	for _, alias := range shellAliases {
+		rc.WriteString(fmt.Sprintf("alias %s=%s\n", alias[0], shellQuote(alias[1])))
+	}
+	prefix := fmt.Sprintf("(kraze:%s) ", clusterName)
+	if strings.HasSuffix(userRC, ".zshrc") {
+		rc.WriteString(fmt.Sprintf("PROMPT=%s\"$PROMPT\"\n", shellQuote(prefix)))
+	} else {
+		rc.WriteString(fmt.Sprintf("PS1=%s\"$PS1\"\n", shellQuote(prefix)))
+	}
+	return rc.String()
+}
+
+// shellQuote quotes a value for POSIX shells
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// spawnKrazeShell starts an interactive subshell with KUBECONFIG set and waits for it to exit
+func spawnKrazeShell(clusterName, kubeconfigPath, namespace string) error {
+	program := shellProgram
+	if program == "" {
+		program = os.Getenv("SHELL")
+	}
+	if program == "" {
+		if runtime.GOOS == "windows" {
+			program = os.Getenv("COMSPEC")
+			if program == "" {
+				program = "cmd.exe"
+			}
+		} else {
+			program = "/bin/sh"
+		}
+	}
+
+	env := append(os.Environ(),
+		"KUBECONFIG="+kubeconfigPath,
+		"KRAZE_SHELL="+clusterName,
+	)
+
+	var shellArgs []string
+	homeDir, _ := os.UserHomeDir()
+	dataDir := filepath.Dir(kubeconfigPath)
+
+	switch strings.TrimSuffix(filepath.Base(program), ".exe") {
+	case "bash":
+		rcPath := filepath.Join(dataDir, "shellrc")
+		if err := os.WriteFile(rcPath, []byte(shellRCContent(filepath.Join(homeDir, ".bashrc"), clusterName)), 0644); err != nil {
+			return fmt.Errorf("failed to write shell rc file: %w", err)
+		}
+		shellArgs = []string{"--rcfile", rcPath, "-i"}
+	case "zsh":
+		zdotdir := filepath.Join(dataDir, "zsh")
+		if err := os.MkdirAll(zdotdir, 0755); err != nil {
+			return fmt.Errorf("failed to create zsh config directory: %w", err)
+		}
+		userZdotdir := os.Getenv("ZDOTDIR")
+		if userZdotdir == "" {
+			userZdotdir = homeDir
+		}
+		if err := os.WriteFile(filepath.Join(zdotdir, ".zshrc"), []byte(shellRCContent(filepath.Join(userZdotdir, ".zshrc"), clusterName)), 0644); err != nil {
+			return fmt.Errorf("failed to write shell rc file: %w", err)
+		}
+		env = append(env, "ZDOTDIR="+zdotdir)
+	}
+
+	nsInfo := ""
+	if namespace != "" {
+		nsInfo = fmt.Sprintf(", namespace '%s'", namespace)
+	}
+	fmt.Printf("Starting kraze shell for cluster '%s'%s (exit to return)\n", clusterName, nsInfo)
+	fmt.Printf("KUBECONFIG=%s\n", kubeconfigPath)
+
+	shell := osexec.Command(program, shellArgs...)
+	shell.Stdin = os.Stdin
+	shell.Stdout = os.Stdout
+	shell.Stderr = os.Stderr
+	shell.Env = env
+
+	if err := shell.Run(); err != nil {
+		// A non-zero exit from the last command in the shell is not a kraze failure
+		if _, ok := err.(*osexec.ExitError); ok {
+			return nil
+		}
+		return fmt.Errorf("failed to run shell %s: %w", program, err)
+	}
+	return nil
+}
+
+func init() {
+	shellCmd.Flags().StringVarP(&shellNamespace, "namespace", "n", "", "Namespace to set as the current namespace")
+	shellCmd.Flags().BoolVarP(&shellPrint, "print", "p", false, "Print export lines instead of starting a subshell")
+	shellCmd.Flags().StringVar(&shellProgram, "shell", "", "Shell to start (default: $SHELL)")
+}
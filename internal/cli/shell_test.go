@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShellQuote(test *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{name: "plain", value: "/home/dev/.kraze/kubeconfig", expected: "'/home/dev/.kraze/kubeconfig'"},
+		{name: "spaces", value: "/Users/my user/kubeconfig", expected: "'/Users/my user/kubeconfig'"},
+		{name: "single quote", value: "it's", expected: `'it'\''s'`},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := shellQuote(tt.value); got != tt.expected {
+				test.Errorf("shellQuote(%q) = %q, expected %q", tt.value, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestShellExportLines(test *testing.T) {
+	lines := shellExportLines("/tmp/kubeconfig", "dev")
+
+	if lines[0] != "export KUBECONFIG='/tmp/kubeconfig'" {
+		test.Errorf("first line = %q, expected KUBECONFIG export", lines[0])
+	}
+	if lines[1] != "export KRAZE_SHELL='dev'" {
+		test.Errorf("second line = %q, expected KRAZE_SHELL export", lines[1])
+	}
+	if len(lines) != 2+len(shellAliases) {
+		test.Errorf("expected %d lines, got %d", 2+len(shellAliases), len(lines))
+	}
+}
+
+func TestShellRCContent(test *testing.T) {
+	tests := []struct {
+		name   string
+		userRC string
+		prompt string
+	}{
+		{name: "bash", userRC: "/home/dev/.bashrc", prompt: "PS1='(kraze:dev) '\"$PS1\""},
+		{name: "zsh", userRC: "/home/dev/.zshrc", prompt: "PROMPT='(kraze:dev) '\"$PROMPT\""},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			rc := shellRCContent(tt.userRC, "dev")
+			if !strings.HasPrefix(rc, "[ -f '"+tt.userRC+"' ]") {
+				test.Errorf("rc should source the user's rc file first, got:\n%s", rc)
+			}
+			if !strings.Contains(rc, "alias k='kubectl'") {
+				test.Errorf("rc should define the k alias, got:\n%s", rc)
+			}
+			if !strings.Contains(rc, tt.prompt) {
+				test.Errorf("rc should contain %q, got:\n%s", tt.prompt, rc)
+			}
+		})
+	}
+}
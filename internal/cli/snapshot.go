@@ -0,0 +1,532 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/pack"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/hjames9/kraze/internal/snapshot"
+	"github.com/hjames9/kraze/internal/state"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// restoredConfigFileName is where a restored snapshot's config package is kept,
+// under ~/.kraze/clusters/<cluster-name>, so later commands find it without -f
+const restoredConfigFileName = "snapshot-config.tar.gz"
+
+var (
+	snapshotOutput    string
+	snapshotNoVolumes bool
+
+	// snapshotValues holds the Helm release values of a snapshot being restored,
+	// by service name. runUp installs those services with exactly these values.
+	snapshotValues map[string]string
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Save the environment to an archive, or restore it into a fresh cluster",
+	Long: `Save the full environment to a snapshot archive, or restore a snapshot into a
+fresh kind cluster. Useful for sharing a broken environment with a teammate or
+resetting to a known state every night.
+
+A snapshot contains:
+  - The config, packaged like 'kraze pack' (charts, manifests, values files)
+  - The installed services, with their Helm release values and applied manifests
+  - The data of the PersistentVolumeClaims in the services' namespaces
+  - The list of images that were loaded into the cluster`,
+}
+
+var snapshotSaveCmd = &cobra.Command{
+	Use:   "save",
+	Short: "Save the installed services and their volumes to a snapshot archive",
+	Long: `Save the installed services, their Helm release values and applied manifests,
+and the data of the PersistentVolumeClaims in their namespaces to a snapshot.
+
+Volume data is copied with tar through a short-lived helper pod (busybox) that
+mounts each claim read-only, while workloads keep running. For a consistent
+copy of a busy database, stop writes to it first. Container images are not
+included; only their names and hashes are recorded.
+
+Examples:
+  kraze snapshot save                      # Writes <cluster>-<timestamp>.snapshot.tgz
+  kraze snapshot save -o broken-env.tgz    # Choose the file name
+  kraze snapshot save --no-volumes         # Services only, without PVC data`,
+	Args: cobra.NoArgs,
+	RunE: runSnapshotSave,
+}
+
+var snapshotRestoreCmd = &cobra.Command{
+	Use:   "restore <snapshot>",
+	Short: "Restore a snapshot into a fresh kind cluster",
+	Long: `Create the snapshot's kind cluster, restore its volumes, and install its
+services with the Helm values they were saved with.
+
+The cluster must not exist yet; run 'kraze destroy' first to reset an existing
+environment. Volumes are restored before any service starts, so workloads come
+up on the saved data. Images that were built locally must be present on this
+machine (or buildable from the config's build blocks).
+
+The snapshot's config is kept in ~/.kraze/clusters/<cluster>/, so 'kraze status',
+'kraze down' and other commands work without -f afterwards.
+
+Examples:
+  kraze snapshot restore broken-env.tgz
+  kraze destroy && kraze snapshot restore nightly.snapshot.tgz`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSnapshotRestore,
+}
+
+func runSnapshotSave(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	cfgPaths, err := resolveConfigFiles(cmd)
+	if err != nil {
+		return err
+	}
+	extracted, cleanupPack, err := pack.MaybeExtract(cfgPaths)
+	if err != nil {
+		return err
+	}
+	defer cleanupPack()
+
+	cfg, err := config.ParseMultiple(extracted)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := requireCluster(cfg, "snapshot save"); err != nil {
+		return err
+	}
+
+	outputPath := snapshotOutput
+	if outputPath == "" {
+		outputPath = fmt.Sprintf("%s-%s.snapshot.tgz", cfg.Cluster.Name, time.Now().Format("20060102-150405"))
+	}
+	absOutput, err := filepath.Abs(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve output path: %w", err)
+	}
+
+	kubeconfig, err := getShellKubeconfig(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	clientset, restConfig, err := snapshotClients(cfg, kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	st, err := state.Load(ctx, clientset, cfg.Cluster.Name, cfg.StateBackend)
+	if err != nil {
+		return fmt.Errorf("failed to load cluster state: %w", err)
+	}
+	if st == nil || len(st.GetInstalledServices()) == 0 {
+		return fmt.Errorf("no services are installed in cluster '%s', nothing to snapshot", cfg.Cluster.Name)
+	}
+
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would save %d service(s) of cluster '%s' to %s\n", len(st.GetInstalledServices()), cfg.Cluster.Name, outputPath)
+		return nil
+	}
+
+	staging, err := os.MkdirTemp("", "kraze-snapshot-*")
+	if err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	fmt.Printf("Saving snapshot of cluster '%s'...\n", cfg.Cluster.Name)
+
+	// Package the config the same way 'kraze pack' does, reusing an existing package as-is
+	packagePath := filepath.Join(staging, snapshot.PackageFile)
+	if len(cfgPaths) == 1 && extracted[0] != cfgPaths[0] {
+		err = copyFile(cfgPaths[0], packagePath)
+	} else {
+		err = pack.CreatePackage(cfgPaths, cfg, version, packagePath, verbose)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to package config: %w", err)
+	}
+	fmt.Printf("%s Config packaged\n", color.Checkmark())
+
+	meta := &snapshot.Metadata{
+		Version:      snapshot.FormatVersion,
+		KrazeVersion: version,
+		CreatedAt:    time.Now().UTC(),
+		ClusterName:  cfg.Cluster.Name,
+	}
+	if serverVersion, err := clientset.Discovery().ServerVersion(); err == nil {
+		meta.KubernetesVersion = serverVersion.GitVersion
+	}
+
+	installed := st.GetInstalledServices()
+	sort.Strings(installed)
+	for _, name := range installed {
+		svc, exists := cfg.Services[name]
+		if !exists {
+			fmt.Printf("%s Service '%s' is installed but not in the config, skipping\n", color.Warning(), name)
+			continue
+		}
+
+		entry, err := saveServiceSnapshot(ctx, staging, &svc, cfg, kubeconfig, st)
+		if err != nil {
+			return fmt.Errorf("failed to snapshot service '%s': %w", name, err)
+		}
+		meta.Services = append(meta.Services, *entry)
+		fmt.Printf("%s Service '%s' saved\n", color.Checkmark(), name)
+	}
+
+	if !snapshotNoVolumes {
+		volumes, err := saveVolumes(ctx, staging, meta.Services, restConfig, clientset)
+		if err != nil {
+			return err
+		}
+		meta.Volumes = volumes
+	}
+
+	if err := snapshot.WriteMetadata(staging, meta); err != nil {
+		return err
+	}
+	if err := pack.WriteDirArchive(staging, absOutput); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	size := ""
+	if info, err := os.Stat(absOutput); err == nil {
+		size = fmt.Sprintf(" (%s)", humanBytes(info.Size()))
+	}
+	fmt.Printf("\n%s Snapshot written to %s%s\n", color.Checkmark(), outputPath, size)
+	fmt.Printf("  %d service(s), %d volume(s)\n", len(meta.Services), len(meta.Volumes))
+	fmt.Printf("\nTo restore: kraze destroy && kraze snapshot restore %s\n", outputPath)
+	return nil
+}
+
+// saveServiceSnapshot writes a service's release values and applied manifests
+// into the staging directory and returns its snapshot entry
+func saveServiceSnapshot(ctx context.Context, staging string, svc *config.ServiceConfig, cfg *config.Config, kubeconfig string, st *state.ClusterState) (*snapshot.Service, error) {
+	meta := st.Services[svc.Name]
+	entry := &snapshot.Service{
+		Name:             svc.Name,
+		Type:             svc.Type,
+		Namespace:        svc.GetNamespace(),
+		CreatedNamespace: meta.CreatedNamespace,
+		Images:           st.GetImageHashes(svc.Name),
+	}
+
+	captured, err := providers.SnapshotService(ctx, svc, &providers.ProviderOptions{
+		ClusterName: cfg.Cluster.Name,
+		KubeConfig:  kubeconfig,
+		Verbose:     verbose,
+		Quiet:       true,
+		Registries:  cfg.Registries,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if captured.Values != nil {
+		entry.Values = snapshot.ServicePath(svc.Name, "values.yaml")
+		if err := writeStagingFile(staging, entry.Values, captured.Values); err != nil {
+			return nil, err
+		}
+	}
+	entry.Manifest = snapshot.ServicePath(svc.Name, "manifest.yaml")
+	if err := writeStagingFile(staging, entry.Manifest, captured.Manifest); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// saveVolumes copies the data of the bound claims in the services' namespaces
+func saveVolumes(ctx context.Context, staging string, services []snapshot.Service, restConfig *rest.Config, clientset kubernetes.Interface) ([]snapshot.Volume, error) {
+	seen := make(map[string]bool)
+	var namespaces []string
+	for _, svc := range services {
+		if !seen[svc.Namespace] {
+			seen[svc.Namespace] = true
+			namespaces = append(namespaces, svc.Namespace)
+		}
+	}
+	sort.Strings(namespaces)
+
+	var volumes []snapshot.Volume
+	for _, namespace := range namespaces {
+		claims, err := providers.ListBoundClaims(ctx, clientset, namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		for idx := range claims {
+			pvc := &claims[idx]
+			vol := snapshot.VolumeFromPVC(pvc)
+			fmt.Printf("  Copying volume %s/%s...\n", pvc.Namespace, pvc.Name)
+
+			dataPath := filepath.Join(staging, filepath.FromSlash(vol.Data))
+			if err := os.MkdirAll(filepath.Dir(dataPath), 0755); err != nil {
+				return nil, fmt.Errorf("failed to create volume directory: %w", err)
+			}
+			out, err := os.Create(dataPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create volume archive: %w", err)
+			}
+			err = providers.BackupVolume(ctx, restConfig, clientset, pvc, out)
+			out.Close()
+			if err != nil {
+				return nil, fmt.Errorf("failed to copy volume %s/%s: %w", pvc.Namespace, pvc.Name, err)
+			}
+
+			volumes = append(volumes, vol)
+			fmt.Printf("%s Volume %s/%s saved\n", color.Checkmark(), pvc.Namespace, pvc.Name)
+		}
+	}
+	return volumes, nil
+}
+
+func runSnapshotRestore(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	staging, err := os.MkdirTemp("", "kraze-snapshot-*")
+	if err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	if err := pack.ExtractArchive(args[0], staging); err != nil {
+		return fmt.Errorf("failed to extract snapshot: %w", err)
+	}
+	meta, err := snapshot.ReadMetadata(staging)
+	if err != nil {
+		return err
+	}
+
+	kindMgr := cluster.NewKindManager()
+	exists, err := kindMgr.ClusterExists(meta.ClusterName)
+	if err != nil {
+		return fmt.Errorf("failed to check cluster: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("cluster '%s' already exists, a snapshot is restored into a fresh cluster: run 'kraze destroy' first", meta.ClusterName)
+	}
+
+	fmt.Printf("Snapshot of cluster '%s' taken %s with kraze %s\n", meta.ClusterName, meta.CreatedAt.Local().Format("2006-01-02 15:04"), meta.KrazeVersion)
+	fmt.Printf("  %d service(s), %d volume(s)\n", len(meta.Services), len(meta.Volumes))
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would create cluster '%s', restore its volumes and install its services\n", meta.ClusterName)
+		return nil
+	}
+
+	unlock, err := lockCluster(ctx, meta.ClusterName, "snapshot restore", waitForLock)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Keep the config package where later commands find it
+	dataDir, err := cluster.ClusterDataDir(meta.ClusterName)
+	if err != nil {
+		return err
+	}
+	packagePath := filepath.Join(dataDir, restoredConfigFileName)
+	if err := copyFile(filepath.Join(staging, snapshot.PackageFile), packagePath); err != nil {
+		return fmt.Errorf("failed to store snapshot config: %w", err)
+	}
+
+	cfgPaths, cleanupPack, err := pack.MaybeExtract([]string{packagePath})
+	if err != nil {
+		return err
+	}
+	defer cleanupPack()
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		return fmt.Errorf("failed to parse snapshot config: %w", err)
+	}
+	if err := requireCluster(cfg, "snapshot restore"); err != nil {
+		return err
+	}
+	if cfg.Cluster.IsExternal() {
+		return fmt.Errorf("snapshots can only be restored into kind clusters, cluster '%s' is external", cfg.Cluster.Name)
+	}
+	if cfg.Cluster.Name != meta.ClusterName {
+		return fmt.Errorf("snapshot config names cluster '%s' but the snapshot was taken of '%s'", cfg.Cluster.Name, meta.ClusterName)
+	}
+
+	if err := cluster.CheckDockerAvailable(ctx); err != nil {
+		return err
+	}
+	if err := kindMgr.CheckNodeImageVersion(&cfg.Cluster); err != nil {
+		return fmt.Errorf("%w\nSet cluster.version (or cluster.node_image) to a release in that range", err)
+	}
+	fmt.Printf("Creating cluster '%s'...\n", cfg.Cluster.Name)
+	kindMgr.UseRegistries(cfg.Registries)
+	if err := kindMgr.CreateCluster(ctx, &cfg.Cluster); err != nil {
+		return fmt.Errorf("failed to create cluster: %w", err)
+	}
+	if err := kindMgr.UpdateKubeconfigFile(cfg.Cluster.Name); err != nil {
+		Verbose("Warning: failed to update kubeconfig: %v", err)
+	}
+
+	kubeconfig, err := kindMgr.GetKubeConfig(cfg.Cluster.Name, false)
+	if err != nil {
+		return fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	clientset, restConfig, err := snapshotClients(cfg, kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	// Record which namespaces kraze created in the original environment, so the
+	// namespaces created here for volumes are still removed by 'kraze down'
+	st := state.New(cfg.Cluster.Name, false, cfg.Cluster.GPU.IsNvidiaEnabled(), 0, cfg.Cluster.GPU.IsAMDEnabled(), 0)
+	for _, svc := range meta.Services {
+		st.Services[svc.Name] = state.ServiceMetadata{Name: svc.Name, Namespace: svc.Namespace, CreatedNamespace: svc.CreatedNamespace}
+	}
+	if err := st.Save(ctx, clientset); err != nil {
+		return fmt.Errorf("failed to save cluster state: %w", err)
+	}
+
+	for _, vol := range meta.Volumes {
+		if err := restoreVolume(ctx, staging, vol, restConfig, clientset); err != nil {
+			return err
+		}
+		fmt.Printf("%s Volume %s/%s restored\n", color.Checkmark(), vol.Namespace, vol.Name)
+	}
+
+	snapshotValues = make(map[string]string)
+	var services []string
+	for _, svc := range meta.Services {
+		services = append(services, svc.Name)
+		if svc.Values == "" {
+			continue
+		}
+		values, err := os.ReadFile(filepath.Join(staging, filepath.FromSlash(svc.Values)))
+		if err != nil {
+			return fmt.Errorf("failed to read saved values of '%s': %w", svc.Name, err)
+		}
+		snapshotValues[svc.Name] = string(values)
+	}
+	defer func() { snapshotValues = nil }()
+
+	printSnapshotImages(meta.Services)
+
+	// Install the snapshot's services through the regular up flow
+	configFiles = []string{packagePath}
+	return runUp(cmd, services)
+}
+
+// restoreVolume recreates a claim and extracts its saved data into it
+func restoreVolume(ctx context.Context, staging string, vol snapshot.Volume, restConfig *rest.Config, clientset kubernetes.Interface) error {
+	pvc, err := vol.PVC()
+	if err != nil {
+		return err
+	}
+	data, err := os.Open(filepath.Join(staging, filepath.FromSlash(vol.Data)))
+	if err != nil {
+		return fmt.Errorf("failed to read saved data of volume %s/%s: %w", vol.Namespace, vol.Name, err)
+	}
+	defer data.Close()
+
+	fmt.Printf("  Restoring volume %s/%s...\n", vol.Namespace, vol.Name)
+	if err := providers.RestoreVolume(ctx, restConfig, clientset, pvc, data); err != nil {
+		return fmt.Errorf("failed to restore volume %s/%s: %w", vol.Namespace, vol.Name, err)
+	}
+	return nil
+}
+
+// applySnapshotValues replaces the values of Helm services being restored from
+// a snapshot with the values their releases were saved with
+func applySnapshotValues(cfg *config.Config) {
+	for name, values := range snapshotValues {
+		svc, exists := cfg.Services[name]
+		if !exists || !svc.IsHelm() {
+			continue
+		}
+		svc.Values = config.ValuesField{}
+		svc.ValuesInline = values
+		cfg.Services[name] = svc
+		Verbose("Using the saved release values for '%s'", name)
+	}
+}
+
+// printSnapshotImages lists the images the snapshot's cluster had loaded
+func printSnapshotImages(services []snapshot.Service) {
+	seen := make(map[string]bool)
+	var images []string
+	for _, svc := range services {
+		for img := range svc.Images {
+			if !seen[img] {
+				seen[img] = true
+				images = append(images, img)
+			}
+		}
+	}
+	if len(images) == 0 {
+		return
+	}
+	sort.Strings(images)
+	fmt.Printf("The snapshot's cluster had %d local image(s) loaded: %s\n", len(images), strings.Join(images, ", "))
+	fmt.Printf("Images that aren't built by the config must be available on this machine\n\n")
+}
+
+// snapshotClients returns the clientset and REST config used for snapshots
+func snapshotClients(cfg *config.Config, kubeconfig string) (kubernetes.Interface, *rest.Config, error) {
+	restConfig, err := providers.GetRESTConfigFromKubeconfigContent(kubeconfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create REST config: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	return clientset, restConfig, nil
+}
+
+// writeStagingFile writes a file at an archive path inside the staging directory
+func writeStagingFile(staging, archivePath string, data []byte) error {
+	path := filepath.Join(staging, filepath.FromSlash(archivePath))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", archivePath, err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", archivePath, err)
+	}
+	return nil
+}
+
+// copyFile copies a regular file
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func init() {
+	snapshotSaveCmd.Flags().StringVarP(&snapshotOutput, "output", "o", "", "Snapshot file to write (default: <cluster>-<timestamp>.snapshot.tgz)")
+	snapshotSaveCmd.Flags().BoolVar(&snapshotNoVolumes, "no-volumes", false, "Don't copy PersistentVolumeClaim data")
+	addLockFlag(snapshotRestoreCmd)
+	snapshotCmd.AddCommand(snapshotSaveCmd)
+	snapshotCmd.AddCommand(snapshotRestoreCmd)
+}
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/events"
+	"github.com/spf13/cobra"
+)
+
+var startCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start a stopped kind cluster",
+	Long: `Start the node containers of a kind cluster that was stopped with 'kraze stop'
+or by the idle auto-stop, and wait for the API server to become ready.
+
+Examples:
+  kraze start
+  kraze start -f dev.yml`,
+	RunE: runStart,
+}
+
+func runStart(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanupPack()
+	Verbose("Starting cluster from config file(s): %s", strings.Join(cfgPaths, ", "))
+
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := requireCluster(cfg, "start"); err != nil {
+		return err
+	}
+
+	if cfg.Cluster.IsExternal() {
+		return fmt.Errorf("start is only supported for kind clusters, not external clusters")
+	}
+
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would start kind cluster '%s'\n", cfg.Cluster.Name)
+		return nil
+	}
+
+	unlock, err := lockCluster(ctx, cfg.Cluster.Name, "start", waitForLock)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	Verbose("Checking Docker availability...")
+	if err := cluster.CheckDockerAvailable(ctx); err != nil {
+		return err
+	}
+
+	kindMgr := cluster.NewKindManager()
+
+	exists, err := kindMgr.ClusterExists(cfg.Cluster.Name)
+	if err != nil {
+		return fmt.Errorf("failed to check cluster: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("cluster '%s' does not exist. Run 'kraze up' first", cfg.Cluster.Name)
+	}
+
+	if err := startStoppedCluster(ctx, kindMgr, cfg); err != nil {
+		return err
+	}
+
+	recordClusterActivity(cfg.Cluster.Name)
+	return nil
+}
+
+// startStoppedCluster starts the cluster's node containers if they are stopped and
+// waits for the API server. Does nothing if the cluster is already running.
+func startStoppedCluster(ctx context.Context, kindMgr *cluster.KindManager, cfg *config.Config) (err error) {
+	clusterName := cfg.Cluster.Name
+	running, err := kindMgr.IsClusterRunning(clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to check cluster: %w", err)
+	}
+	if running {
+		Verbose("Cluster '%s' is already running", clusterName)
+		return nil
+	}
+
+	fmt.Printf("Starting kind cluster '%s'...\n", clusterName)
+	start := time.Now()
+	defer func() { emitEvent(ctx, cfg, events.Finished(events.ClusterStart, clusterName, "", start, err)) }()
+	if err := kindMgr.StartCluster(clusterName); err != nil {
+		return err
+	}
+
+	if err := kindMgr.WaitForClusterReady(ctx, clusterName, 2*time.Minute); err != nil {
+		return err
+	}
+
+	// Restarted nodes start with the kernel defaults of their network namespace
+	if err := kindMgr.ApplyNodeSysctls(ctx, &cfg.Cluster); err != nil {
+		fmt.Printf("%s Could not set node sysctls: %v\n", color.Warning(), err)
+	}
+
+	// Container IPs may change across restarts
+	Verbose("Updating kubeconfig...")
+	if err := kindMgr.UpdateKubeconfigFile(clusterName); err != nil {
+		Verbose("Warning: failed to update kubeconfig: %v", err)
+	}
+
+	fmt.Printf("%s Cluster '%s' started\n", color.Checkmark(), clusterName)
+	return nil
+}
+
+func init() {
+	addLockFlag(startCmd)
+}
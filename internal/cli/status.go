@@ -3,18 +3,59 @@ package cli
 import (
 	"context"
 	"fmt"
+	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/hjames9/kraze/internal/color"
 	"github.com/hjames9/kraze/internal/config"
 	"github.com/hjames9/kraze/internal/providers"
+	"github.com/hjames9/kraze/internal/state"
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
 )
 
 var (
 	statusLabels []string
+	statusOutput string
+	statusWatch  bool
 )
 
+// statusReport is the output of 'kraze status -o json|yaml'
+type statusReport struct {
+	Cluster  string                  `json:"cluster"`
+	Running  bool                    `json:"running"`
+	Services []serviceStatusReport   `json:"services"`
+	Forwards []cluster.ForwardStatus `json:"forwards,omitempty"`
+	State    *stateSizeReport        `json:"state,omitempty"`
+}
+
+// stateSizeReport is how much space kraze's state takes in the cluster
+type stateSizeReport struct {
+	Backend    string `json:"backend"`              // configmap or crd
+	Bytes      int    `json:"bytes"`                // Stored size, compressed in ConfigMaps
+	ConfigMaps int    `json:"configmaps,omitempty"` // ConfigMaps the state is split across
+}
+
+// serviceStatusReport is the status of one service
+type serviceStatusReport struct {
+	Name        string                  `json:"name"`
+	Type        string                  `json:"type"`
+	Namespace   string                  `json:"namespace"`
+	Enabled     bool                    `json:"enabled"`
+	Installed   bool                    `json:"installed"`
+	Ready       bool                    `json:"ready"`
+	Message     string                  `json:"message,omitempty"`
+	Error       string                  `json:"error,omitempty"` // Why the status could not be determined
+	Replicas    *providers.ReplicaCount `json:"replicas,omitempty"`
+	ImageHashes map[string]string       `json:"image_hashes,omitempty"`
+	Assertions  []state.AssertionResult `json:"assertions,omitempty"`     // Outcome of the service's assertions when last checked
+	Reason      string                  `json:"install_reason,omitempty"` // Why the last install included the service
+}
+
 var statusCmd = &cobra.Command{
 	Use:     "status [services...]",
 	Aliases: []string{"ps"},
@@ -24,13 +65,30 @@ var statusCmd = &cobra.Command{
 You can filter services by name or by labels:
   kraze status service1 service2    # Show status of specific services
   kraze status --label env=dev      # Show status of services with label env=dev
-  kraze status --label tier=backend # Show status of services with label tier=backend`,
-	RunE: runStatus,
+  kraze status --label tier=backend # Show status of services with label tier=backend
+  kraze status -o wide              # Add namespace, replica and image columns
+  kraze status -o json              # Machine-readable status for scripts and CI
+  kraze status --watch              # Live dashboard of pods, restarts, events and images`,
+	ValidArgsFunction: getServiceNames,
+	RunE:              runStatus,
 }
 
 func runStatus(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
+	format, err := parseOutputFormat(statusOutput)
+	if err != nil {
+		return err
+	}
+	if statusWatch {
+		if format.isStructured() {
+			return fmt.Errorf("--watch can't be combined with -o %s", statusOutput)
+		}
+		if !isatty.IsTerminal(os.Stdout.Fd()) || !isatty.IsTerminal(os.Stdin.Fd()) {
+			return fmt.Errorf("--watch needs an interactive terminal; use 'kraze wait' or 'kraze status -o json' in scripts")
+		}
+	}
+
 	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
 	if err != nil {
 		return err
@@ -43,6 +101,9 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to parse config: %w", err)
 	}
+	if err := requireCluster(cfg, "status"); err != nil {
+		return err
+	}
 
 	// Check Docker availability (only for kind clusters, not external)
 	if !cfg.Cluster.IsExternal() {
@@ -88,98 +149,248 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to check cluster: %w", err)
 	}
 
+	report := statusReport{Cluster: cfg.Cluster.Name, Running: clusterExists}
+
 	if !clusterExists {
+		if format.isStructured() {
+			for _, name := range sortedServiceNames(cfg.Services) {
+				svc := cfg.Services[name]
+				report.Services = append(report.Services, serviceStatusReport{Name: name, Type: svc.Type, Namespace: svc.GetNamespace(), Enabled: svc.IsEnabled()})
+			}
+			return printStructured(format, report)
+		}
 		fmt.Printf("Cluster '%s' is not running\n", cfg.Cluster.Name)
 		fmt.Println("\nNo services are currently deployed.")
 		return nil
 	}
 
+	if err := checkClusterHealth(ctx, kindMgr, &cfg.Cluster); err != nil {
+		return err
+	}
+
 	// Get kubeconfig
 	kubeconfig, err := kindMgr.GetKubeConfig(cfg.Cluster.Name, false)
 	if err != nil {
 		return fmt.Errorf("failed to get kubeconfig: %w", err)
 	}
+	recordClusterActivity(cfg.Cluster.Name)
 
-	fmt.Printf("Cluster: %s\n\n", cfg.Cluster.Name)
-
-	// Print header
-	fmt.Printf("%-20s %-12s %-10s %-10s %s\n", "SERVICE", "TYPE", "INSTALLED", "READY", "MESSAGE")
-	fmt.Println("--------------------------------------------------------------------------------")
+	if statusWatch {
+		clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig)
+		if err != nil {
+			return fmt.Errorf("failed to create Kubernetes client: %w", err)
+		}
+		return runStatusDashboard(ctx, cfg, kubeconfig, clientset)
+	}
 
-	// Track counts for summary
-	installedCount := 0
-	readyCount := 0
+	// Replica counts and image hashes are only shown in wide and structured output
+	var clientset kubernetes.Interface
+	var st *state.ClusterState
+	if format != outputTable {
+		clientset, err = providers.GetClientsetFromKubeconfigContent(kubeconfig)
+		if err != nil {
+			return fmt.Errorf("failed to create Kubernetes client: %w", err)
+		}
+		if st, err = state.Load(ctx, clientset, cfg.Cluster.Name, cfg.StateBackend); err != nil {
+			Verbose("Warning: failed to load cluster state: %v", err)
+		} else if st != nil {
+			size, configMaps := st.StoredSize()
+			report.State = &stateSizeReport{Backend: st.Backend(), Bytes: size}
+			if st.Backend() == state.BackendConfigMap {
+				report.State.ConfigMaps = configMaps
+			}
+		}
+	}
 
 	// Check status of each service
-	for name, svc := range cfg.Services {
-		// Skip disabled services but show them in the status
-		if !svc.IsEnabled() {
-			Verbose("Service '%s' is disabled (skipping status check)", name)
-			fmt.Printf("%-20s %-12s %-10s %-10s %s\n",
-				name, svc.Type, "N/A", "N/A", "DISABLED")
-			continue
-		}
+	for _, name := range sortedServiceNames(cfg.Services) {
+		svc := cfg.Services[name]
+		report.Services = append(report.Services, serviceStatus(ctx, cfg, &svc, kubeconfig, clientset, st))
+	}
 
-		// Create provider options
-		providerOpts := &providers.ProviderOptions{
-			ClusterName: cfg.Cluster.Name,
-			KubeConfig:  kubeconfig,
-			Verbose:     verbose,
-		}
+	// Show background port-forwards started by 'kraze forward start'
+	forwardStatus, err := cluster.LoadForwardStatus(cfg.Cluster.Name)
+	if err != nil {
+		Verbose("Warning: failed to read port-forward status: %v", err)
+		forwardStatus = nil
+	} else if forwardStatus != nil && !forwardStatus.IsLive(time.Now()) {
+		forwardStatus = nil
+	}
+	if forwardStatus != nil {
+		report.Forwards = forwardStatus.Forwards
+	}
 
-		// Create provider
-		provider, err := providers.NewProvider(&svc, providerOpts)
-		if err != nil {
-			fmt.Printf("%-20s %-12s %-10s %-10s %s\n",
-				name, svc.Type, "ERROR", "ERROR", fmt.Sprintf("Failed to create provider: %v", err))
-			continue
-		}
+	if format.isStructured() {
+		return printStructured(format, report)
+	}
 
-		// Get status from provider
-		status, err := provider.Status(ctx, &svc)
-		if err != nil {
-			fmt.Printf("%-20s %-12s %-10s %-10s %s\n",
-				name, svc.Type, "ERROR", "ERROR", fmt.Sprintf("Failed to get status: %v", err))
-			continue
-		}
+	fmt.Printf("Cluster: %s\n\n", cfg.Cluster.Name)
+	printStatusTable(report.Services, format == outputWide)
+	fmt.Println()
 
-		// Count installed and ready services
-		if status.Installed {
+	// Summary based on actual status checks (not state file)
+	installedCount := 0
+	readyCount := 0
+	for _, entry := range report.Services {
+		if entry.Installed {
 			installedCount++
 		}
-		if status.Ready {
+		if entry.Ready {
 			readyCount++
 		}
-
-		// Format output
-		installedStr := "No"
-		if status.Installed {
-			installedStr = "Yes"
+	}
+	fmt.Printf("Summary: %d/%d services installed, %d ready\n", installedCount, len(cfg.Services), readyCount)
+	if report.State != nil && format == outputWide {
+		if report.State.Backend == state.BackendCRD {
+			fmt.Printf("State: %d KiB in a %s\n", (report.State.Bytes+1023)/1024, state.EnvironmentKind)
+		} else {
+			fmt.Printf("State: %d KiB compressed in %d ConfigMap(s)\n", (report.State.Bytes+1023)/1024, report.State.ConfigMaps)
 		}
-
-		readyStr := "No"
-		if status.Ready {
-			readyStr = "Yes"
+	}
+	if st != nil {
+		if warning := st.SizeWarning(); warning != "" {
+			fmt.Printf("%s %s\n", color.Warning(), warning)
 		}
+	}
+
+	if forwardStatus != nil {
+		fmt.Println()
+		printForwardStatus(forwardStatus)
+	}
+
+	return nil
+}
+
+// serviceStatus checks the status of one service. Replicas are only counted
+// with a clientset, and image hashes and assertions only read with a state.
+func serviceStatus(ctx context.Context, cfg *config.Config, svc *config.ServiceConfig, kubeconfig string, clientset kubernetes.Interface, st *state.ClusterState) serviceStatusReport {
+	entry := serviceStatusReport{Name: svc.Name, Type: svc.Type, Namespace: svc.GetNamespace(), Enabled: svc.IsEnabled()}
+
+	// Skip disabled services but show them in the status
+	if !svc.IsEnabled() {
+		Verbose("Service '%s' is disabled (skipping status check)", svc.Name)
+		return entry
+	}
+
+	// Create provider options
+	providerOpts := &providers.ProviderOptions{
+		ClusterName: cfg.Cluster.Name,
+		KubeConfig:  kubeconfig,
+		Verbose:     verbose,
+	}
+
+	// Create provider
+	provider, err := providers.NewProvider(svc, providerOpts)
+	if err != nil {
+		entry.Error = fmt.Sprintf("Failed to create provider: %v", err)
+		return entry
+	}
 
-		// Truncate message if too long
-		message := status.Message
-		if len(message) > 40 {
-			message = message[:37] + "..."
+	// Get status from provider
+	status, err := provider.Status(ctx, svc)
+	if err != nil {
+		entry.Error = fmt.Sprintf("Failed to get status: %v", err)
+		return entry
+	}
+	entry.Installed = status.Installed
+	entry.Ready = status.Ready
+	entry.Message = status.Message
+
+	if clientset != nil && status.Installed {
+		replicas, err := providers.ServiceReplicas(ctx, clientset, svc)
+		if err != nil {
+			Verbose("Warning: failed to count replicas of '%s': %v", svc.Name, err)
+		} else if replicas.Workloads > 0 {
+			entry.Replicas = &replicas
+		}
+	}
+	if st != nil {
+		entry.ImageHashes = st.Services[svc.Name].ImageHashes
+		entry.Assertions = st.Services[svc.Name].Assertions
+		entry.Reason = st.Services[svc.Name].InstallReason
+		if st.IsServicePartial(svc.Name) {
+			entry.Message = "Install interrupted, run 'kraze up --resume'"
 		}
+	}
+	return entry
+}
 
-		fmt.Printf("%-20s %-12s %-10s %-10s %s\n",
-			name, svc.Type, installedStr, readyStr, message)
+// printStatusTable prints service statuses; wide adds the namespace, replica,
+// image and passed assertion counts and the install reason, and does not
+// truncate messages
+func printStatusTable(services []serviceStatusReport, wide bool) {
+	if wide {
+		fmt.Printf("%-20s %-12s %-15s %-10s %-10s %-9s %-7s %-8s %-24s %s\n", "SERVICE", "TYPE", "NAMESPACE", "INSTALLED", "READY", "REPLICAS", "IMAGES", "ASSERTS", "REASON", "MESSAGE")
+		fmt.Println("--------------------------------------------------------------------------------------------------------------------------------------------------")
+	} else {
+		fmt.Printf("%-20s %-12s %-10s %-10s %s\n", "SERVICE", "TYPE", "INSTALLED", "READY", "MESSAGE")
+		fmt.Println("--------------------------------------------------------------------------------")
 	}
 
-	fmt.Println()
+	for _, entry := range services {
+		installedStr, readyStr, message := "No", "No", entry.Message
+		switch {
+		case !entry.Enabled:
+			installedStr, readyStr, message = "N/A", "N/A", "DISABLED"
+		case entry.Error != "":
+			installedStr, readyStr, message = "ERROR", "ERROR", entry.Error
+		default:
+			if entry.Installed {
+				installedStr = "Yes"
+			}
+			if entry.Ready {
+				readyStr = "Yes"
+			}
+		}
 
-	// Summary based on actual status checks (not state file)
-	fmt.Printf("Summary: %d/%d services installed, %d ready\n", installedCount, len(cfg.Services), readyCount)
+		if !wide {
+			// Truncate message if too long
+			if entry.Error == "" && len(message) > 40 {
+				message = message[:37] + "..."
+			}
+			fmt.Printf("%-20s %-12s %-10s %-10s %s\n", entry.Name, entry.Type, installedStr, readyStr, message)
+			continue
+		}
 
-	return nil
+		replicas := "-"
+		if entry.Replicas != nil {
+			replicas = entry.Replicas.String()
+		}
+		images := "-"
+		if len(entry.ImageHashes) > 0 {
+			images = fmt.Sprintf("%d", len(entry.ImageHashes))
+		}
+		asserts := "-"
+		if len(entry.Assertions) > 0 {
+			passed := 0
+			for _, result := range entry.Assertions {
+				if result.Passed {
+					passed++
+				}
+			}
+			asserts = fmt.Sprintf("%d/%d", passed, len(entry.Assertions))
+		}
+		reason := "-"
+		if entry.Reason != "" && entry.Installed {
+			reason = entry.Reason
+		}
+		fmt.Printf("%-20s %-12s %-15s %-10s %-10s %-9s %-7s %-8s %-24s %s\n", entry.Name, entry.Type, entry.Namespace, installedStr, readyStr, replicas, images, asserts, reason, message)
+	}
+}
+
+// sortedServiceNames returns the service names in alphabetical order
+func sortedServiceNames(services map[string]config.ServiceConfig) []string {
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 func init() {
+	addOutputFlag(statusCmd, &statusOutput)
 	statusCmd.Flags().StringSliceVarP(&statusLabels, "label", "l", []string{}, "Filter services by label (format: key=value, can be specified multiple times)")
+	registerLabelCompletion(statusCmd)
+	statusCmd.Flags().BoolVarP(&statusWatch, "watch", "w", false, "Show a live dashboard that refreshes as pods and events change (q to quit)")
 }
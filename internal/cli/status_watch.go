@@ -0,0 +1,465 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/hjames9/kraze/internal/state"
+	"golang.org/x/term"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// dashboardRefreshInterval is how often the dashboard refreshes without
+	// watch events, for status that isn't watched (e.g. Helm release status)
+	dashboardRefreshInterval = 10 * time.Second
+
+	// dashboardSettle batches a burst of watch events into one refresh
+	dashboardSettle = 300 * time.Millisecond
+
+	// dashboardRewatchDelay is how long a dropped watch waits before restarting
+	dashboardRewatchDelay = 5 * time.Second
+
+	// dashboardEventCount is how many recent events the selected service shows
+	dashboardEventCount = 8
+)
+
+// dashboardService is one service's row and details on the dashboard
+type dashboardService struct {
+	status   serviceStatusReport
+	pods     []corev1.Pod
+	events   []corev1.Event
+	restarts int32
+}
+
+// dashboard is the state of 'kraze status --watch'
+type dashboard struct {
+	cluster  string
+	services []dashboardService
+	selected int
+	updated  time.Time
+	err      string // Why the last refresh failed
+}
+
+// runStatusDashboard shows a full-screen dashboard of the services that
+// refreshes when their pods or events change, until q or Ctrl+C
+func runStatusDashboard(ctx context.Context, cfg *config.Config, kubeconfig string, clientset kubernetes.Interface) error {
+	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	stdin := int(os.Stdin.Fd())
+	oldState, err := term.MakeRaw(stdin)
+	if err != nil {
+		return fmt.Errorf("failed to read keys from the terminal: %w", err)
+	}
+	defer term.Restore(stdin, oldState)
+
+	// Alternate screen without a cursor, so the shell's scrollback is untouched
+	fmt.Fprint(os.Stdout, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(os.Stdout, "\x1b[?25h\x1b[?1049l")
+
+	// Verbose messages would scroll the dashboard away
+	verboseWas := verbose
+	verbose = false
+	defer func() { verbose = verboseWas }()
+
+	keys := make(chan byte)
+	go readKeys(ctx, os.Stdin, keys)
+
+	changed := make(chan struct{}, 1)
+	for _, namespace := range dashboardNamespaces(cfg) {
+		go watchDashboard(ctx, changed, func(ctx context.Context) (watch.Interface, error) {
+			return clientset.CoreV1().Pods(namespace).Watch(ctx, metav1.ListOptions{})
+		})
+		go watchDashboard(ctx, changed, func(ctx context.Context) (watch.Interface, error) {
+			return clientset.CoreV1().Events(namespace).Watch(ctx, metav1.ListOptions{})
+		})
+	}
+
+	board := &dashboard{cluster: cfg.Cluster.Name}
+	type collected struct {
+		services []dashboardService
+		err      error
+	}
+	results := make(chan collected, 1)
+	collecting, stale := false, true
+	ticker := time.NewTicker(dashboardRefreshInterval)
+	defer ticker.Stop()
+	var escape []byte
+
+	for {
+		if stale && !collecting {
+			collecting, stale = true, false
+			go func() {
+				services, err := collectDashboard(ctx, cfg, kubeconfig, clientset)
+				select {
+				case results <- collected{services: services, err: err}:
+				case <-ctx.Done():
+				}
+			}()
+		}
+		board.draw(os.Stdout)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case result := <-results:
+			collecting = false
+			if result.err != nil {
+				board.err = result.err.Error()
+				break
+			}
+			board.services = result.services
+			board.updated = time.Now()
+			board.err = ""
+			board.selected = min(board.selected, max(len(board.services)-1, 0))
+		case <-changed:
+			// Let the rest of the burst arrive before refreshing
+			time.Sleep(dashboardSettle)
+			select {
+			case <-changed:
+			default:
+			}
+			stale = true
+		case <-ticker.C:
+			stale = true
+		case key := <-keys:
+			escape = append(escape, key)
+			switch action := dashboardKey(escape); action {
+			case "":
+				continue // Incomplete escape sequence
+			case "quit":
+				return nil
+			case "up":
+				board.selected = max(board.selected-1, 0)
+			case "down":
+				board.selected = min(board.selected+1, max(len(board.services)-1, 0))
+			case "refresh":
+				stale = true
+			}
+			escape = escape[:0]
+		}
+	}
+}
+
+// dashboardKey maps the bytes of a key press to a dashboard action, returning
+// "" while an escape sequence is incomplete and "none" for other keys
+func dashboardKey(pressed []byte) string {
+	switch {
+	case len(pressed) == 1 && (pressed[0] == 'q' || pressed[0] == 3): // Ctrl+C
+		return "quit"
+	case len(pressed) == 1 && pressed[0] == 'k':
+		return "up"
+	case len(pressed) == 1 && pressed[0] == 'j':
+		return "down"
+	case len(pressed) == 1 && pressed[0] == 'r':
+		return "refresh"
+	case pressed[0] == 0x1b && len(pressed) < 3:
+		return ""
+	case len(pressed) == 3 && pressed[0] == 0x1b && pressed[1] == '[' && pressed[2] == 'A':
+		return "up"
+	case len(pressed) == 3 && pressed[0] == 0x1b && pressed[1] == '[' && pressed[2] == 'B':
+		return "down"
+	}
+	return "none"
+}
+
+// readKeys sends the bytes typed on the terminal until ctx is done
+func readKeys(ctx context.Context, in io.Reader, keys chan<- byte) {
+	buf := make([]byte, 16)
+	for {
+		n, err := in.Read(buf)
+		if err != nil {
+			return
+		}
+		for _, key := range buf[:n] {
+			select {
+			case keys <- key:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// watchDashboard signals changed on every watch event, restarting the watch
+// when the API server closes it
+func watchDashboard(ctx context.Context, changed chan<- struct{}, start func(ctx context.Context) (watch.Interface, error)) {
+	for ctx.Err() == nil {
+		if watcher, err := start(ctx); err == nil {
+			for range watcher.ResultChan() {
+				select {
+				case changed <- struct{}{}:
+				default:
+				}
+			}
+			watcher.Stop()
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(dashboardRewatchDelay):
+		}
+	}
+}
+
+// dashboardNamespaces returns the namespaces of the enabled services
+func dashboardNamespaces(cfg *config.Config) []string {
+	seen := make(map[string]bool)
+	var namespaces []string
+	for _, svc := range cfg.Services {
+		if namespace := svc.GetNamespace(); svc.IsEnabled() && !seen[namespace] {
+			seen[namespace] = true
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// collectDashboard checks the status of every service, with the pods and recent
+// events of the installed ones
+func collectDashboard(ctx context.Context, cfg *config.Config, kubeconfig string, clientset kubernetes.Interface) ([]dashboardService, error) {
+	st, err := state.Load(ctx, clientset, cfg.Cluster.Name, cfg.StateBackend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cluster state: %w", err)
+	}
+
+	namespaceEvents := make(map[string][]corev1.Event)
+	var services []dashboardService
+	for _, name := range sortedServiceNames(cfg.Services) {
+		svc := cfg.Services[name]
+		entry := dashboardService{status: serviceStatus(ctx, cfg, &svc, kubeconfig, clientset, st)}
+		if !entry.status.Installed {
+			services = append(services, entry)
+			continue
+		}
+
+		if pods, err := providers.ListServicePods(ctx, clientset, &svc); err == nil {
+			entry.pods = pods
+		}
+		for _, pod := range entry.pods {
+			for _, container := range pod.Status.ContainerStatuses {
+				entry.restarts += container.RestartCount
+			}
+		}
+
+		namespace := svc.GetNamespace()
+		if _, listed := namespaceEvents[namespace]; !listed {
+			events, err := clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{})
+			if err == nil {
+				namespaceEvents[namespace] = events.Items
+			} else {
+				namespaceEvents[namespace] = nil
+			}
+		}
+		entry.events = serviceEvents(&svc, entry.pods, namespaceEvents[namespace])
+		services = append(services, entry)
+	}
+	return services, nil
+}
+
+// serviceEvents returns the most recent events about a service: those of its
+// pods, and of objects named after the service (its workloads and their
+// ReplicaSets, usually)
+func serviceEvents(svc *config.ServiceConfig, pods []corev1.Pod, events []corev1.Event) []corev1.Event {
+	podNames := make(map[string]bool, len(pods))
+	for _, pod := range pods {
+		podNames[pod.Name] = true
+	}
+
+	var matched []corev1.Event
+	for _, event := range events {
+		if podNames[event.InvolvedObject.Name] || strings.HasPrefix(event.InvolvedObject.Name, svc.Name) {
+			matched = append(matched, event)
+		}
+	}
+	sort.SliceStable(matched, func(left, right int) bool {
+		return eventTime(matched[left]).After(eventTime(matched[right]))
+	})
+	if len(matched) > dashboardEventCount {
+		matched = matched[:dashboardEventCount]
+	}
+	return matched
+}
+
+// eventTime returns when an event last happened
+func eventTime(event corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// draw renders the dashboard over the whole terminal
+func (board *dashboard) draw(out io.Writer) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		width, height = 120, 40
+	}
+
+	var frame strings.Builder
+	frame.WriteString("\x1b[H")
+	for _, line := range board.render(width, height, time.Now()) {
+		frame.WriteString(line)
+		frame.WriteString("\x1b[K\r\n")
+	}
+	frame.WriteString("\x1b[J")
+	fmt.Fprint(out, frame.String())
+}
+
+// render returns the dashboard's lines for a terminal of the given size
+func (board *dashboard) render(width, height int, now time.Time) []string {
+	var lines []string
+	add := func(format string, args ...any) {
+		lines = append(lines, fitWidth(fmt.Sprintf(format, args...), width))
+	}
+
+	updated := "loading..."
+	if !board.updated.IsZero() {
+		updated = "updated " + board.updated.Format("15:04:05")
+	}
+	add("kraze status: cluster %s (%s)   q quit, ↑/↓ select, r refresh", board.cluster, updated)
+	if board.err != "" {
+		add("Error: %s", board.err)
+	}
+	add("")
+
+	// The service table gets up to half the screen, the selected service the rest
+	add("  %-20s %-10s %-10s %-6s %-9s %-9s %-7s %s", "SERVICE", "TYPE", "INSTALLED", "READY", "REPLICAS", "RESTARTS", "IMAGES", "MESSAGE")
+	rows := max(min(len(board.services), height/2-len(lines)), 1)
+	first := max(0, min(board.selected-rows+1, len(board.services)-rows))
+	for itr := first; itr < min(first+rows, len(board.services)); itr++ {
+		entry := board.services[itr]
+		line := fitWidth(dashboardRow(entry), width)
+		if itr == board.selected {
+			line = "\x1b[7m" + line + "\x1b[0m"
+		}
+		lines = append(lines, line)
+	}
+	if len(board.services) == 0 {
+		return lines
+	}
+
+	selected := board.services[board.selected]
+	add("")
+	add("Pods of %s (namespace %s)", selected.status.Name, selected.status.Namespace)
+	if len(selected.pods) == 0 {
+		add("  No pods")
+	} else {
+		add("  %-45s %-10s %-6s %-9s %s", "NAME", "PHASE", "READY", "RESTARTS", "AGE")
+		for _, pod := range selected.pods {
+			ready, restarts := 0, int32(0)
+			for _, container := range pod.Status.ContainerStatuses {
+				if container.Ready {
+					ready++
+				}
+				restarts += container.RestartCount
+			}
+			add("  %-45s %-10s %-6s %-9d %s", pod.Name, podPhase(&pod), fmt.Sprintf("%d/%d", ready, len(pod.Spec.Containers)), restarts, duration.HumanDuration(now.Sub(pod.CreationTimestamp.Time)))
+		}
+	}
+
+	add("")
+	add("Recent events")
+	if len(selected.events) == 0 {
+		add("  No events")
+	}
+	for _, event := range selected.events {
+		add("  %-6s %-8s %-20s %s/%s: %s", duration.HumanDuration(now.Sub(eventTime(event))), event.Type, event.Reason, strings.ToLower(event.InvolvedObject.Kind), event.InvolvedObject.Name, strings.TrimSpace(event.Message))
+	}
+
+	if len(selected.status.ImageHashes) > 0 {
+		add("")
+		add("Images")
+		for _, image := range sortedKeys(selected.status.ImageHashes) {
+			add("  %s %s", image, selected.status.ImageHashes[image])
+		}
+	}
+
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	return lines
+}
+
+// dashboardRow formats a service's row in the dashboard table
+func dashboardRow(entry dashboardService) string {
+	status := entry.status
+	installed, ready, message := "No", "No", status.Message
+	switch {
+	case !status.Enabled:
+		installed, ready, message = "N/A", "N/A", "DISABLED"
+	case status.Error != "":
+		installed, ready, message = "ERROR", "ERROR", status.Error
+	default:
+		if status.Installed {
+			installed = "Yes"
+		}
+		if status.Ready {
+			ready = "Yes"
+		}
+	}
+
+	replicas, restarts, images := "-", "-", "-"
+	if status.Replicas != nil {
+		replicas = status.Replicas.String()
+	}
+	if status.Installed {
+		restarts = fmt.Sprintf("%d", entry.restarts)
+	}
+	if len(status.ImageHashes) > 0 {
+		images = fmt.Sprintf("%d", len(status.ImageHashes))
+	}
+	return fmt.Sprintf("  %-20s %-10s %-10s %-6s %-9s %-9s %-7s %s", status.Name, status.Type, installed, ready, replicas, restarts, images, message)
+}
+
+// podPhase returns the pod's phase, or the reason a container is waiting or
+// terminated (e.g. CrashLoopBackOff), like kubectl's STATUS column
+func podPhase(pod *corev1.Pod) string {
+	for _, container := range pod.Status.ContainerStatuses {
+		if container.State.Waiting != nil && container.State.Waiting.Reason != "" {
+			return container.State.Waiting.Reason
+		}
+		if container.State.Terminated != nil && container.State.Terminated.Reason != "" && pod.Status.Phase != corev1.PodSucceeded {
+			return container.State.Terminated.Reason
+		}
+	}
+	return string(pod.Status.Phase)
+}
+
+// fitWidth cuts a line to the terminal width, since wrapped lines would shift
+// the rest of the dashboard
+func fitWidth(line string, width int) string {
+	runes := []rune(line)
+	if len(runes) <= width {
+		return line
+	}
+	return string(runes[:max(width-1, 0)]) + "…"
+}
+
+// sortedKeys returns the keys of a map in alphabetical order
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hjames9/kraze/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDashboardKey(test *testing.T) {
+	tests := []struct {
+		name     string
+		pressed  []byte
+		expected string
+	}{
+		{name: "q quits", pressed: []byte("q"), expected: "quit"},
+		{name: "ctrl+c quits", pressed: []byte{3}, expected: "quit"},
+		{name: "k moves up", pressed: []byte("k"), expected: "up"},
+		{name: "j moves down", pressed: []byte("j"), expected: "down"},
+		{name: "r refreshes", pressed: []byte("r"), expected: "refresh"},
+		{name: "arrow up", pressed: []byte("\x1b[A"), expected: "up"},
+		{name: "arrow down", pressed: []byte("\x1b[B"), expected: "down"},
+		{name: "incomplete escape", pressed: []byte("\x1b["), expected: ""},
+		{name: "other arrow", pressed: []byte("\x1b[C"), expected: "none"},
+		{name: "other key", pressed: []byte("x"), expected: "none"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := dashboardKey(tt.pressed); got != tt.expected {
+				test.Errorf("dashboardKey(%q) = %q, want %q", tt.pressed, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestServiceEvents(test *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	event := func(name, kind, involved string, ago time.Duration) corev1.Event {
+		return corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name},
+			InvolvedObject: corev1.ObjectReference{Kind: kind, Name: involved},
+			LastTimestamp:  metav1.NewTime(now.Add(-ago)),
+		}
+	}
+
+	svc := &config.ServiceConfig{Name: "api"}
+	pods := []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "backend-7d9f"}}}
+	events := []corev1.Event{
+		event("old", "Deployment", "api", time.Hour),
+		event("pod", "Pod", "backend-7d9f", time.Minute),
+		event("other", "Pod", "web-5c4b", time.Second),
+		event("replicaset", "ReplicaSet", "api-6b8c", 10*time.Minute),
+	}
+
+	got := serviceEvents(svc, pods, events)
+	var names []string
+	for _, event := range got {
+		names = append(names, event.Name)
+	}
+	if strings.Join(names, ",") != "pod,replicaset,old" {
+		test.Errorf("serviceEvents() = %v, want [pod replicaset old]", names)
+	}
+}
+
+func TestDashboardRender(test *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	board := &dashboard{
+		cluster:  "dev",
+		selected: 1,
+		updated:  now,
+		services: []dashboardService{
+			{status: serviceStatusReport{Name: "api", Type: "helm", Namespace: "default", Enabled: true, Installed: true, Ready: true}},
+			{
+				status: serviceStatusReport{
+					Name: "web", Type: "manifests", Namespace: "shop", Enabled: true, Installed: true,
+					ImageHashes: map[string]string{"web:dev": "sha256:abc"},
+				},
+				restarts: 4,
+				pods: []corev1.Pod{{
+					ObjectMeta: metav1.ObjectMeta{Name: "web-5c4b", CreationTimestamp: metav1.NewTime(now.Add(-5 * time.Minute))},
+					Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "web"}}},
+					Status: corev1.PodStatus{
+						Phase: corev1.PodRunning,
+						ContainerStatuses: []corev1.ContainerStatus{{
+							RestartCount: 4,
+							State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+						}},
+					},
+				}},
+				events: []corev1.Event{{
+					Type:           corev1.EventTypeWarning,
+					Reason:         "BackOff",
+					Message:        "Back-off restarting failed container",
+					InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web-5c4b"},
+					LastTimestamp:  metav1.NewTime(now.Add(-30 * time.Second)),
+				}},
+			},
+		},
+	}
+
+	lines := board.render(200, 40, now)
+	output := strings.Join(lines, "\n")
+	for _, expected := range []string{
+		"cluster dev",
+		"Pods of web (namespace shop)",
+		"CrashLoopBackOff",
+		"BackOff",
+		"pod/web-5c4b: Back-off restarting failed container",
+		"web:dev sha256:abc",
+	} {
+		if !strings.Contains(output, expected) {
+			test.Errorf("render() is missing %q:\n%s", expected, output)
+		}
+	}
+
+	selected := false
+	for _, line := range lines {
+		if strings.HasPrefix(line, "\x1b[7m") {
+			selected = strings.Contains(line, "web")
+		}
+	}
+	if !selected {
+		test.Errorf("render() should highlight the selected service 'web':\n%s", output)
+	}
+
+	for _, line := range board.render(40, 10, now) {
+		plain := strings.TrimSuffix(strings.TrimPrefix(line, "\x1b[7m"), "\x1b[0m")
+		if len([]rune(plain)) > 40 {
+			test.Errorf("line is wider than the terminal: %q", plain)
+		}
+	}
+	if got := len(board.render(40, 10, now)); got > 10 {
+		test.Errorf("render() returned %d lines for a 10-line terminal", got)
+	}
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/events"
+	"github.com/spf13/cobra"
+)
+
+var stopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the kind cluster without deleting it",
+	Long: `Stop the node containers of the kind cluster, freeing CPU and memory while
+keeping the cluster, its state and all installed services.
+
+Run 'kraze start' (or 'kraze up') to resume the cluster.
+
+Clusters can also be stopped automatically after a period of inactivity with
+'kraze up --auto-stop <duration>'.
+
+Examples:
+  kraze stop
+  kraze stop -f dev.yml`,
+	RunE: runStop,
+}
+
+func runStop(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanupPack()
+	Verbose("Stopping cluster from config file(s): %s", strings.Join(cfgPaths, ", "))
+
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := requireCluster(cfg, "stop"); err != nil {
+		return err
+	}
+
+	if cfg.Cluster.IsExternal() {
+		return fmt.Errorf("stop is only supported for kind clusters, not external clusters")
+	}
+
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would stop kind cluster '%s'\n", cfg.Cluster.Name)
+		return nil
+	}
+
+	unlock, err := lockCluster(ctx, cfg.Cluster.Name, "stop", waitForLock)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	Verbose("Checking Docker availability...")
+	if err := cluster.CheckDockerAvailable(ctx); err != nil {
+		return err
+	}
+
+	kindMgr := cluster.NewKindManager()
+
+	exists, err := kindMgr.ClusterExists(cfg.Cluster.Name)
+	if err != nil {
+		return fmt.Errorf("failed to check cluster: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("cluster '%s' does not exist", cfg.Cluster.Name)
+	}
+
+	fmt.Printf("Stopping kind cluster '%s'...\n", cfg.Cluster.Name)
+	start := time.Now()
+	err = kindMgr.StopCluster(cfg.Cluster.Name)
+	emitEvent(ctx, cfg, events.Finished(events.ClusterStop, cfg.Cluster.Name, "", start, err))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s Cluster '%s' stopped\n", color.Checkmark(), cfg.Cluster.Name)
+	fmt.Printf("\nTo resume: kraze start\n")
+	return nil
+}
+
+func init() {
+	addLockFlag(stopCmd)
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/telemetry"
+	"github.com/spf13/cobra"
+)
+
+// traceExportTimeout bounds how long kraze waits to flush spans to an OTLP collector on exit
+const traceExportTimeout = 5 * time.Second
+
+var (
+	traceRun     bool
+	traceSession *telemetry.Session
+)
+
+// startTrace starts tracing the command when --trace is set or an OTLP endpoint
+// is configured, and makes the root span the parent of the command's spans
+func startTrace(cmd *cobra.Command) error {
+	if !traceRun && !telemetry.OTLPConfigured() {
+		return nil
+	}
+	// Shell completion runs on every tab press; don't trace it
+	if cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd {
+		return nil
+	}
+
+	ctx, session, err := telemetry.Start(cmd.Context(), cmd.CommandPath(), version)
+	if err != nil {
+		return err
+	}
+	traceSession = session
+	cmd.SetContext(ctx)
+	return nil
+}
+
+// finishTrace ends the command's root span, flushes spans to the OTLP collector,
+// and prints the timing breakdown for --trace. The breakdown goes to stderr so
+// structured output on stdout stays parseable.
+func finishTrace(runErr error) {
+	if traceSession == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), traceExportTimeout)
+	defer cancel()
+	if err := traceSession.End(ctx, runErr); err != nil {
+		fmt.Fprintf(os.Stderr, "%s %v\n", color.Warning(), err)
+	}
+	if traceRun {
+		telemetry.PrintBreakdown(os.Stderr, traceSession.Breakdown())
+	}
+}
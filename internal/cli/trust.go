@@ -0,0 +1,318 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/localca"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	trustInstall   bool
+	trustUninstall bool
+	trustYes       bool
+	trustIngressCA string
+)
+
+var trustCmd = &cobra.Command{
+	Use:   "trust",
+	Short: "Export the cluster CA and add it to this machine's trust stores",
+	Long: `Export the cluster's CA certificate to ~/.kraze/clusters/<cluster>/ca.crt so
+host tooling (curl --cacert, SDKs, browsers) can verify the cluster.
+
+With --ingress-ca, the CA certificate stored in a secret (its ca.crt key, or
+tls.crt when that is itself a CA) is also exported, to ingress-ca.crt. This is
+the CA that signs your ingress certificates, for example a cert-manager CA
+issuer, and trusting it makes https://myapp.localtest.me load without
+certificate warnings.
+
+The local CA that signs the certificates of services with local_tls
+(~/.kraze/ca/rootCA.pem) is included once a service has used it. It is shared
+by all clusters, so trusting it once covers every local_tls host.
+
+--install adds the exported CAs to the trust stores of this machine:
+  Linux    the system store (update-ca-certificates, update-ca-trust or
+           p11-kit), plus the NSS databases of Chrome/Chromium and Firefox
+           when certutil is installed
+  macOS    the System keychain, plus Firefox profiles when certutil is installed
+  Windows  the current user's Root store
+
+The commands are shown and confirmed before anything runs; system stores need
+sudo on Linux and macOS. --uninstall removes the CAs again, using the exported
+files, so it also works after the cluster is destroyed. Pass --yes to skip the
+confirmation, for example in scripts.
+
+Examples:
+  kraze trust                                    # Export the cluster CA
+  kraze trust --ingress-ca cert-manager/root-ca  # Also export the ingress CA
+  kraze trust --install --ingress-ca cert-manager/root-ca
+  kraze trust --uninstall                        # Remove the CAs from the trust stores`,
+	Args: cobra.NoArgs,
+	RunE: runTrust,
+}
+
+func runTrust(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if trustInstall && trustUninstall {
+		return fmt.Errorf("cannot specify both --install and --uninstall")
+	}
+	if trustUninstall && trustIngressCA != "" {
+		return fmt.Errorf("--ingress-ca cannot be used with --uninstall, exported CAs are removed automatically")
+	}
+
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanupPack()
+
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := requireCluster(cfg, "trust"); err != nil {
+		return err
+	}
+
+	var cas []*cluster.TrustedCA
+	if trustUninstall {
+		cas, err = loadExportedCAs(cfg.Cluster.Name)
+		if err != nil {
+			return err
+		}
+		if len(cas) == 0 {
+			return fmt.Errorf("no CA has been exported for cluster '%s', nothing to uninstall", cfg.Cluster.Name)
+		}
+	} else {
+		cas, err = exportClusterCAs(ctx, cfg)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !trustInstall && !trustUninstall {
+		fmt.Printf("\nRun 'kraze trust --install' to add %s to this machine's trust stores\n", pluralizeCA(len(cas)))
+		return nil
+	}
+
+	var plans []cluster.TrustPlan
+	for _, ca := range cas {
+		plans = append(plans, cluster.PlanTrustStore(ca, trustInstall))
+	}
+	steps := printTrustPlans(plans)
+	if len(steps) == 0 {
+		return fmt.Errorf("no trust store can be updated automatically on this machine")
+	}
+
+	if dryRun {
+		fmt.Printf("\n[DRY RUN] Would run the commands above\n")
+		return nil
+	}
+
+	action := "Add the CA certificates above to these trust stores?"
+	if trustUninstall {
+		action = "Remove the CA certificates above from these trust stores?"
+	}
+	confirmed, err := confirmTrust(action)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Println("Aborted, no trust store was changed")
+		return nil
+	}
+
+	failed := 0
+	for _, step := range steps {
+		fmt.Printf("\n%s %s\n", color.Gray("$"), strings.Join(step.Command, " "))
+		if err := cluster.RunTrustStep(ctx, step); err != nil {
+			fmt.Printf("%s %v\n", color.Cross(), err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d trust store command(s) failed", failed, len(steps))
+	}
+
+	if trustInstall {
+		fmt.Printf("\n%s Installed %s, restart your browser to pick up the change\n", color.Checkmark(), pluralizeCA(len(cas)))
+	} else {
+		fmt.Printf("\n%s Removed %s from the trust stores\n", color.Checkmark(), pluralizeCA(len(cas)))
+	}
+	return nil
+}
+
+// exportClusterCAs exports the cluster CA, and the --ingress-ca CA if given
+func exportClusterCAs(ctx context.Context, cfg *config.Config) ([]*cluster.TrustedCA, error) {
+	kubeconfig, err := getShellKubeconfig(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	caData, err := cluster.ClusterCAFromKubeconfig(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	clusterCA, err := cluster.ExportCA(cfg.Cluster.Name, cluster.ClusterCAFileName, "kraze-"+cfg.Cluster.Name, caData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export cluster CA: %w", err)
+	}
+	printTrustedCA("cluster CA", clusterCA)
+	cas := []*cluster.TrustedCA{clusterCA}
+
+	localCA, err := loadLocalCA()
+	if err != nil {
+		return nil, err
+	}
+	if localCA != nil {
+		printTrustedCA("local TLS CA", localCA)
+		cas = append(cas, localCA)
+	}
+
+	if trustIngressCA == "" {
+		return cas, nil
+	}
+
+	namespace, name, err := parseSecretRef(trustIngressCA)
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	secret, err := clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ingress CA secret '%s': %w", trustIngressCA, err)
+	}
+	ingressData := secret.Data["ca.crt"]
+	if len(ingressData) == 0 {
+		ingressData = secret.Data["tls.crt"]
+	}
+	if len(ingressData) == 0 {
+		return nil, fmt.Errorf("secret '%s' has no ca.crt or tls.crt", trustIngressCA)
+	}
+
+	ingressCA, err := cluster.ExportCA(cfg.Cluster.Name, cluster.IngressCAFileName, "kraze-"+cfg.Cluster.Name+"-ingress", ingressData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export ingress CA from secret '%s': %w", trustIngressCA, err)
+	}
+	printTrustedCA("ingress CA", ingressCA)
+	return append(cas, ingressCA), nil
+}
+
+// loadExportedCAs returns the CAs previously exported for a cluster
+func loadExportedCAs(clusterName string) ([]*cluster.TrustedCA, error) {
+	var cas []*cluster.TrustedCA
+	for _, itr := range []struct{ fileName, name string }{
+		{cluster.ClusterCAFileName, "kraze-" + clusterName},
+		{cluster.IngressCAFileName, "kraze-" + clusterName + "-ingress"},
+	} {
+		ca, err := cluster.LoadExportedCA(clusterName, itr.fileName, itr.name)
+		if err != nil {
+			return nil, err
+		}
+		if ca != nil {
+			cas = append(cas, ca)
+		}
+	}
+
+	localCA, err := loadLocalCA()
+	if err != nil {
+		return nil, err
+	}
+	if localCA != nil {
+		cas = append(cas, localCA)
+	}
+	return cas, nil
+}
+
+// loadLocalCA returns the CA that signs local_tls certificates, or nil if no
+// service has used local_tls yet
+func loadLocalCA() (*cluster.TrustedCA, error) {
+	dir, err := localca.DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	return cluster.LoadCAFile(filepath.Join(dir, localca.CertFileName), "kraze-local-ca")
+}
+
+// parseSecretRef splits a <namespace>/<name> secret reference
+func parseSecretRef(ref string) (string, string, error) {
+	namespace, name, found := strings.Cut(ref, "/")
+	if !found || namespace == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("invalid secret '%s', expected <namespace>/<name>", ref)
+	}
+	return namespace, name, nil
+}
+
+// printTrustedCA prints where a CA was exported and how to recognise it
+func printTrustedCA(label string, ca *cluster.TrustedCA) {
+	fmt.Printf("%s Exported %s to %s\n", color.Checkmark(), label, ca.Path)
+	fmt.Printf("   Subject: %s\n", ca.Subject)
+	fmt.Printf("   SHA-256: %s\n", ca.SHA256)
+	fmt.Printf("   Expires: %s\n", ca.NotAfter.Format("2006-01-02"))
+}
+
+// printTrustPlans prints the commands that will run and any notes, returning the steps
+func printTrustPlans(plans []cluster.TrustPlan) []cluster.TrustStep {
+	var steps []cluster.TrustStep
+	var notes []string
+	for _, plan := range plans {
+		steps = append(steps, plan.Steps...)
+		notes = append(notes, plan.Notes...)
+	}
+
+	if len(steps) > 0 {
+		fmt.Printf("\nThe following commands will run:\n")
+		for _, step := range steps {
+			fmt.Printf("  %s\n", strings.Join(step.Command, " "))
+		}
+	}
+	for _, note := range notes {
+		fmt.Printf("%s %s\n", color.Warning(), note)
+	}
+	return steps
+}
+
+// confirmTrust asks before changing trust stores. Without a terminal, --yes is required.
+func confirmTrust(question string) (bool, error) {
+	if trustYes {
+		return true, nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false, fmt.Errorf("refusing to change trust stores without confirmation, re-run with --yes")
+	}
+
+	fmt.Printf("\n%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+// pluralizeCA returns "the CA" or "the N CAs"
+func pluralizeCA(count int) string {
+	if count == 1 {
+		return "the CA"
+	}
+	return fmt.Sprintf("the %d CAs", count)
+}
+
+func init() {
+	trustCmd.Flags().BoolVar(&trustInstall, "install", false, "Add the exported CAs to this machine's trust stores")
+	trustCmd.Flags().BoolVar(&trustUninstall, "uninstall", false, "Remove previously exported CAs from this machine's trust stores")
+	trustCmd.Flags().BoolVarP(&trustYes, "yes", "y", false, "Don't ask for confirmation before changing trust stores")
+	trustCmd.Flags().StringVar(&trustIngressCA, "ingress-ca", "", "Also export the CA in this secret (<namespace>/<name>), e.g. a cert-manager CA issuer's secret")
+}
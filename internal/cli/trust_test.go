@@ -0,0 +1,30 @@
+package cli
+
+import "testing"
+
+func TestParseSecretRef(test *testing.T) {
+	tests := []struct {
+		ref           string
+		wantNamespace string
+		wantName      string
+		wantErr       bool
+	}{
+		{ref: "cert-manager/root-ca", wantNamespace: "cert-manager", wantName: "root-ca"},
+		{ref: "root-ca", wantErr: true},
+		{ref: "/root-ca", wantErr: true},
+		{ref: "cert-manager/", wantErr: true},
+		{ref: "a/b/c", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.ref, func(test *testing.T) {
+			namespace, name, err := parseSecretRef(tt.ref)
+			if (err != nil) != tt.wantErr {
+				test.Fatalf("parseSecretRef(%q) error = %v, wantErr %v", tt.ref, err, tt.wantErr)
+			}
+			if namespace != tt.wantNamespace || name != tt.wantName {
+				test.Errorf("parseSecretRef(%q) = %q, %q", tt.ref, namespace, name)
+			}
+		})
+	}
+}
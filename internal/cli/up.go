@@ -3,6 +3,12 @@ package cli
 import (
 	"context"
 	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -10,10 +16,12 @@ import (
 	"github.com/hjames9/kraze/internal/cluster"
 	"github.com/hjames9/kraze/internal/color"
 	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/events"
 	"github.com/hjames9/kraze/internal/graph"
 	"github.com/hjames9/kraze/internal/pack"
 	"github.com/hjames9/kraze/internal/providers"
 	"github.com/hjames9/kraze/internal/state"
+	"github.com/hjames9/kraze/internal/telemetry"
 	"github.com/hjames9/kraze/internal/ui"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -21,11 +29,27 @@ import (
 )
 
 var (
-	upWait    bool
-	upTimeout string
-	upNoWait  bool
-	upNoDeps  bool
-	upLabels  []string
+	upWait            bool
+	upTimeout         string
+	upNoWait          bool
+	upNoDeps          bool
+	upLabels          []string
+	upAutoStop        time.Duration
+	upRecreateCluster bool
+	upForceConflicts  bool
+	upNoPrune         bool
+	upSkipAssertions  bool
+	upSkipFixtures    bool
+	upForward         bool
+	upNoBuild         bool
+	upBuild           bool
+	upSkipAPICheck    bool
+	upDiff            bool
+	upDiffOnly        bool
+	upRetry           int
+	upLogsDir         string
+	upResume          bool
+	upFromBundle      string
 )
 
 var upCmd = &cobra.Command{
@@ -40,28 +64,74 @@ You can filter services by name or by labels:
   kraze up service1 service2      # Install specific services (with dependencies)
   kraze up service1 --no-deps     # Install service1 only, skip dependencies
   kraze up --label env=dev        # Install services with label env=dev
-  kraze up --label tier=backend   # Install services with label tier=backend`,
+  kraze up --label tier=backend   # Install services with label tier=backend
+  kraze up --auto-stop 2h         # Stop the cluster after 2 hours of inactivity
+  kraze up --forward              # Start the declared port-forwards in the background
+  kraze up --build                # Rebuild images of services with a build block
+  kraze up --skip-api-check       # Install even if a service uses removed Kubernetes APIs
+  kraze up --force-conflicts      # Take ownership of manifest fields changed by other tools
+  kraze up --diff                 # Show how each resource will change before applying
+  kraze up --diff-only            # Only show the changes, exiting non-zero if there are any
+  kraze up --retry 3              # Retry installs that fail transiently (webhooks, registries, API server)
+  kraze up --resume               # Continue a run that was interrupted with Ctrl-C
+  kraze up --from-bundle app-bundle.tar.gz  # Install from a 'kraze bundle' without network access
+  kraze up --as system:serviceaccount:team-a:deployer  # Install as a service account to test its RBAC`,
 	ValidArgsFunction: getServiceNames,
-	RunE:              runUp,
+	RunE:              withRecording(runUp),
 }
 
 func runUp(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	ctx := cmd.Context()
 
-	cfgPaths, err := resolveConfigFiles(cmd)
-	if err != nil {
-		return err
+	if upBuild && upNoBuild {
+		return fmt.Errorf("--build and --no-build cannot be used together")
+	}
+	if upRetry < 0 {
+		return fmt.Errorf("--retry must not be negative")
 	}
 
-	// Keep the original paths (archive or yml) to persist in cluster state so
-	// future commands can re-resolve the config without -f.
-	originalCfgPaths := cfgPaths
+	var cfgPaths, originalCfgPaths []string
+	var bundle *pack.Bundle
+	if upFromBundle != "" {
+		if len(configFiles) > 0 {
+			return fmt.Errorf("--from-bundle uses the config files of the bundle, so it can't be combined with -f")
+		}
+		if upBuild {
+			return fmt.Errorf("--from-bundle installs the images of the bundle, so it can't be combined with --build")
+		}
+		bundlePath, err := filepath.Abs(upFromBundle)
+		if err != nil {
+			return fmt.Errorf("resolving bundle path: %w", err)
+		}
+		var cleanupBundle func()
+		bundle, cleanupBundle, err = pack.OpenBundle(bundlePath)
+		if err != nil {
+			return err
+		}
+		defer cleanupBundle()
+
+		// Later commands re-resolve the config from the bundle, which they
+		// extract without its images
+		cfgPaths, originalCfgPaths = bundle.ConfigFiles, []string{bundlePath}
+		upNoBuild = true
+		providers.UseOffline(true)
+	} else {
+		resolved, err := resolveConfigFiles(cmd)
+		if err != nil {
+			return err
+		}
+
+		// Keep the original paths (archive or yml) to persist in cluster state so
+		// future commands can re-resolve the config without -f.
+		originalCfgPaths = resolved
 
-	cfgPaths, cleanupPack, err := pack.MaybeExtract(cfgPaths)
-	if err != nil {
-		return err
+		extracted, cleanupPack, err := pack.MaybeExtract(resolved)
+		if err != nil {
+			return err
+		}
+		defer cleanupPack()
+		cfgPaths = extracted
 	}
-	defer cleanupPack()
 
 	Verbose("Starting services from config file(s): %s", strings.Join(cfgPaths, ", "))
 
@@ -70,19 +140,53 @@ func runUp(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to parse config: %w", err)
 	}
+	if err := requireCluster(cfg, "up"); err != nil {
+		return err
+	}
+	if bundle != nil {
+		bundle.Apply(cfg)
+	}
+	applySnapshotValues(cfg)
+
+	// Surface conflicting or deprecated settings before doing any work
+	if findings := cfg.RunLint(cfgPaths); len(findings) > 0 {
+		printLintFindings(findings)
+		fmt.Println()
+	}
 
 	// Check Docker availability (only for kind clusters, not external)
 	if !cfg.Cluster.IsExternal() {
-		Verbose("Checking Docker availability...")
+		runtimeName := cluster.DetectContainerRuntime().DisplayName()
+		Verbose("Checking %s availability...", runtimeName)
 		if err := cluster.CheckDockerAvailable(ctx); err != nil {
 			return err
 		}
-		Verbose("Docker is available")
+		Verbose("%s is available", runtimeName)
+	}
+	if bundle != nil && !dryRun && !upDiffOnly {
+		if err := loadBundleImages(ctx, bundle); err != nil {
+			return err
+		}
 	}
 
 	// Filter services if specified (including dependencies)
 	requestedServices := args
 
+	// Continue an interrupted run with the services it was started with
+	var resumeRun *state.InterruptedRun
+	if upResume {
+		if len(requestedServices) > 0 || len(upLabels) > 0 || upNoDeps {
+			return fmt.Errorf("--resume continues the interrupted run with its own services, so it can't be combined with service names, --label or --no-deps")
+		}
+		resumeRun, err = loadInterruptedRun(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		requestedServices, upLabels, upNoDeps = resumeRun.Services, resumeRun.Labels, resumeRun.NoDeps
+		fmt.Printf("Resuming the run interrupted at %s (%d service(s) left: %s)\n",
+			resumeRun.InterruptedAt.Local().Format(time.DateTime), len(resumeRun.Pending), strings.Join(resumeRun.Pending, ", "))
+	}
+
 	// Check if both service names and labels are specified
 	if len(requestedServices) > 0 && len(upLabels) > 0 {
 		return fmt.Errorf("cannot specify both service names and labels, use one or the other")
@@ -123,7 +227,7 @@ func runUp(cmd *cobra.Command, args []string) error {
 
 			// Clear dependency references since we're intentionally ignoring them
 			for name, svc := range filteredServices {
-				svc.DependsOn = nil
+				svc.DependsOn = config.DependsOnField{}
 				filteredServices[name] = svc
 			}
 
@@ -141,6 +245,12 @@ func runUp(cmd *cobra.Command, args []string) error {
 		Verbose("No services specified, will install all services")
 	}
 
+	// Record why each service is part of this run
+	reasons, err := config.SelectionReasons(cfg.Services, requestedServices, upLabels)
+	if err != nil {
+		return err
+	}
+
 	// Filter out disabled services
 	disabledCount := 0
 	enabledServices := make(map[string]config.ServiceConfig)
@@ -157,14 +267,31 @@ func runUp(cmd *cobra.Command, args []string) error {
 	}
 	cfg.Services = enabledServices
 
+	// Only show how installing would change the cluster, failing on drift
+	if upDiffOnly {
+		depGraph := graph.NewDependencyGraph(cfg.Services)
+		if !upNoDeps {
+			if err := depGraph.Validate(); err != nil {
+				return fmt.Errorf("dependency validation failed: %w", err)
+			}
+		}
+		return diffAgainstCluster(ctx, cfg, depGraph, upForceConflicts, !upNoPrune)
+	}
+
 	if dryRun {
 		fmt.Printf("[DRY RUN] Would install %d service(s)\n", len(cfg.Services))
-		for name := range cfg.Services {
-			fmt.Printf("  - %s\n", name)
+		for _, name := range sortedServiceNames(cfg.Services) {
+			fmt.Printf("  - %s (%s)\n", name, reasons[name])
 		}
 		return nil
 	}
 
+	unlock, err := lockCluster(ctx, cfg.Cluster.Name, "up", waitForLock)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	// Create dependency graph
 	depGraph := graph.NewDependencyGraph(cfg.Services)
 
@@ -183,6 +310,11 @@ func runUp(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to resolve dependencies: %w", err)
 	}
 
+	// Services an interrupted run already installed aren't installed again
+	if resumeRun != nil {
+		serviceLevels = pendingLevels(serviceLevels, resumeRun.Pending)
+	}
+
 	// Flatten for progress tracking
 	var orderedServices []*config.ServiceConfig
 	for _, level := range serviceLevels {
@@ -194,6 +326,7 @@ func runUp(cmd *cobra.Command, args []string) error {
 
 	// Create or verify cluster
 	kindMgr := cluster.NewKindManager()
+	kindMgr.UseRegistries(cfg.Registries)
 	isExternal := cfg.Cluster.IsExternal()
 	var kubeconfig string
 
@@ -221,19 +354,34 @@ func runUp(cmd *cobra.Command, args []string) error {
 
 		if !exists {
 			fmt.Printf("Cluster '%s' does not exist, creating it...\n", cfg.Cluster.Name)
-			if err := kindMgr.CreateCluster(ctx, &cfg.Cluster); err != nil {
-				return fmt.Errorf("failed to create cluster: %w", err)
-			}
-
-			// Update ~/.kube/config with cluster access (Use container IP)
-			Verbose("Updating kubeconfig...")
-			if err := kindMgr.UpdateKubeconfigFile(cfg.Cluster.Name); err != nil {
-				Verbose("Warning: failed to update kubeconfig: %v", err)
-			} else {
-				Verbose("Kubeconfig updated (context: kind-%s)", cfg.Cluster.Name)
+			start := time.Now()
+			err := createKindCluster(ctx, kindMgr, &cfg.Cluster)
+			emitEvent(ctx, cfg, events.Finished(events.ClusterCreate, cfg.Cluster.Name, "", start, err))
+			if err != nil {
+				return err
 			}
 		} else {
 			Verbose("Cluster '%s' already exists", cfg.Cluster.Name)
+
+			// Resume the cluster if it was stopped by 'kraze stop' or the idle watcher
+			if err := startStoppedCluster(ctx, kindMgr, cfg); err != nil {
+				return err
+			}
+			if err := checkClusterHealth(ctx, kindMgr, &cfg.Cluster); err != nil {
+				return err
+			}
+
+			if err := recreateDriftedCluster(ctx, kindMgr, &cfg.Cluster); err != nil {
+				return err
+			}
+		}
+
+		// Without network access the nodes can't pull the images kraze
+		// itself runs or the preload images, so load them from the bundle
+		if bundle != nil {
+			if err := loadBundleNodeImages(ctx, kindMgr, cfg, bundle); err != nil {
+				return err
+			}
 		}
 
 		// Get kubeconfig for the cluster (will be patched with container IP)
@@ -241,18 +389,43 @@ func runUp(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("failed to get kubeconfig: %w", err)
 		}
+
+		// Track activity and (re)start the idle watcher if auto-stop is configured
+		if cmd.Flags().Changed("auto-stop") {
+			if err := cluster.SetAutoStop(cfg.Cluster.Name, upAutoStop); err != nil {
+				return fmt.Errorf("failed to configure auto-stop: %w", err)
+			}
+			if upAutoStop > 0 {
+				fmt.Printf("Cluster will be stopped after %v of inactivity\n", upAutoStop)
+			}
+		} else {
+			recordClusterActivity(cfg.Cluster.Name)
+		}
+		if err := ensureIdleWatcher(cfg.Cluster.Name); err != nil {
+			fmt.Printf("%s Failed to start idle watcher: %v\n", color.Warning(), err)
+		}
 	}
 
 	// Create Kubernetes clientset for cluster state management
 	// Use the kubeconfig content (not file path)
-	// Only skip TLS verification for kind clusters (not external clusters)
-	clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig, !isExternal)
+	clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig)
 	if err != nil {
 		return fmt.Errorf("failed to create Kubernetes client: %w", err)
 	}
 
+	// Install services as the impersonated identity, if any
+	kubeconfig, err = impersonatedKubeconfig(kubeconfig, &cfg.Cluster)
+	if err != nil {
+		return err
+	}
+
+	// Fail before installing anything when the cluster runs an unsupported Kubernetes version
+	if err := checkServerKubernetesVersion(clientset, &cfg.Cluster); err != nil {
+		return err
+	}
+
 	// Load or create cluster state
-	st, err := state.Load(ctx, clientset, cfg.Cluster.Name)
+	st, err := state.Load(ctx, clientset, cfg.Cluster.Name, cfg.StateBackend)
 	if err != nil {
 		return fmt.Errorf("failed to load cluster state: %w", err)
 	}
@@ -260,7 +433,15 @@ func runUp(cmd *cobra.Command, args []string) error {
 		nvidiaEnabled := cfg.Cluster.GPU.IsNvidiaEnabled()
 		amdEnabled := cfg.Cluster.GPU.IsAMDEnabled()
 		st = state.New(cfg.Cluster.Name, cfg.Cluster.IsExternal(), nvidiaEnabled, 0, amdEnabled, 0)
+		if !cfg.Cluster.IsExternal() {
+			st.SetClusterChecksum(cfg.Cluster.Checksum())
+		}
 	} else if !cfg.Cluster.IsExternal() {
+		// Clusters created before checksums were recorded take the current config as theirs
+		if st.ClusterChecksum == "" {
+			st.SetClusterChecksum(cfg.Cluster.Checksum())
+		}
+
 		// GPU config mismatch check (GPU requires cluster recreation)
 		nvidiaEnabled := cfg.Cluster.GPU.IsNvidiaEnabled()
 		amdEnabled := cfg.Cluster.GPU.IsAMDEnabled()
@@ -278,11 +459,34 @@ func runUp(cmd *cobra.Command, args []string) error {
 	}
 
 	// Store the original config paths (before pack extraction) so future commands
-	// can locate the config or archive without -f.
+	// can locate the config or archive without -f. The state moves to the
+	// configured backend when it's kept in the other one.
 	st.SetConfigPaths(originalCfgPaths)
+	st.UseBackend(cfg.StateBackend)
 	if saveErr := st.Save(ctx, clientset); saveErr != nil {
 		Verbose("Warning: failed to store config paths in cluster state: %v", saveErr)
 	}
+	if warning := st.SizeWarning(); warning != "" {
+		fmt.Printf("%s %s\n", color.Warning(), warning)
+	}
+
+	// Keep the in-cluster agent from reverting services while they change
+	agentInstalled := pauseAgent(ctx, clientset)
+
+	// Fail before installing anything when a service uses an API the cluster no longer serves
+	if !upSkipAPICheck {
+		if err := scanServiceAPIs(ctx, cfg, orderedServices, kubeconfig, clientset); err != nil {
+			return err
+		}
+	}
+
+	// Show how the install will change the cluster before applying anything
+	if upDiff {
+		if _, err := printServiceDiffs(ctx, cfg, orderedServices, kubeconfig, st, upForceConflicts, !upNoPrune); err != nil {
+			return err
+		}
+		fmt.Println()
+	}
 
 	// Determine global wait behavior from CLI flags
 	globalWait := upWait && !upNoWait
@@ -291,14 +495,65 @@ func runUp(cmd *cobra.Command, args []string) error {
 		globalWait = false
 	}
 
-	// Create image manager for automatic image loading
-	imgMgr := cluster.NewImageManager(verbose)
+	// Provider output goes to a stream per service, so services installed in
+	// parallel don't interleave it. With a logs directory it only goes to the
+	// services' log files, and the progress display shows the summaries.
+	var console io.Writer = os.Stdout
+	if upLogsDir != "" {
+		console = nil
+	}
+	serviceLogs, err := ui.NewServiceLogs(upLogsDir, console)
+	if err != nil {
+		return err
+	}
+
+	// Build and load images on a background queue, ahead of the API work that needs them
+	images := newImageQueue(orderedServices, cfg, kubeconfig, st, kindMgr, cluster.NewImageManager(verbose), progress)
+
+	// Print workload lint findings once the progress display is done, including
+	// when an install fails (they often explain why pods never became ready)
+	workloadFindings = nil
+	overriddenResources = make(map[string][]providers.ResourceChange)
+	optionalNotReady = make(map[string][]string)
+	defer func() {
+		if len(optionalNotReady) > 0 {
+			fmt.Printf("\n%s Optional workloads that aren't ready:\n", color.Warning())
+			printOptionalNotReady(optionalNotReady)
+		}
+		if len(overriddenResources) > 0 {
+			fmt.Printf("\nRequests and limits changed by resources_override:\n")
+			printResourceChanges(overriddenResources)
+		}
+		if len(workloadFindings) > 0 {
+			fmt.Printf("\nWorkload lint findings:\n")
+			printLintFindings(workloadFindings)
+		}
+	}()
 
 	defer progress.Stop()
 
 	// Start progress display
 	progress.Start(len(orderedServices), "Installing")
 
+	// Ctrl-C from here on stops the installs in flight cleanly, and records the
+	// run so 'kraze up --resume' can continue it
+	ctx, stopInterrupt := interruptContext(ctx)
+	defer stopInterrupt()
+	run := &state.InterruptedRun{Services: requestedServices, Labels: upLabels, NoDeps: upNoDeps}
+	completed := make(map[string]bool)
+	failRun := func(err error) error {
+		if ctx.Err() == nil {
+			return err
+		}
+		if saveErr := recordInterruptedRun(ctx, st, clientset, run, orderedServices, completed); saveErr != nil {
+			return fmt.Errorf("interrupted, and %w", saveErr)
+		}
+		return fmt.Errorf("interrupted with %d service(s) not installed, run 'kraze up --resume' to continue", len(run.Pending))
+	}
+
+	images.Start(ctx)
+	defer images.Stop()
+
 	// Initialize all services as pending
 	for i, svc := range orderedServices {
 		progress.UpdateService(i, svc.Name, ui.StatusPending, "")
@@ -316,9 +571,10 @@ func runUp(cmd *cobra.Command, args []string) error {
 			svc := level[0]
 			itr := serviceIndex
 
-			if err := installService(ctx, svc, itr, cfg, kubeconfig, st, clientset, kindMgr, imgMgr, progress, globalWait, globalTimeout, verbose); err != nil {
-				return fmt.Errorf("failed to install service '%s' in level %d: %w", svc.Name, levelNum, err)
+			if err := installService(ctx, svc, itr, reasons[svc.Name].String(), cfg, kubeconfig, st, clientset, images, progress, serviceLogs, false, globalWait, globalTimeout, verbose); err != nil {
+				return failRun(fmt.Errorf("failed to install service '%s' in level %d: %w", svc.Name, levelNum, err))
 			}
+			completed[svc.Name] = true
 			successCount++
 			serviceIndex++
 		} else {
@@ -331,7 +587,7 @@ func runUp(cmd *cobra.Command, args []string) error {
 
 			var wg sync.WaitGroup
 			errChan := make(chan serviceError, len(level))
-			successChan := make(chan bool, len(level))
+			successChan := make(chan string, len(level))
 
 			// Print "Installing" headers in order before launching goroutines so
 			// [N/total] lines appear sequentially in scrolling output, not in
@@ -349,11 +605,11 @@ func runUp(cmd *cobra.Command, args []string) error {
 				go func(service *config.ServiceConfig, idx int) {
 					defer wg.Done()
 
-					if err := installService(ctx, service, idx, cfg, kubeconfig, st, clientset, kindMgr, imgMgr, progress, globalWait, globalTimeout, verbose); err != nil {
+					if err := installService(ctx, service, idx, reasons[service.Name].String(), cfg, kubeconfig, st, clientset, images, progress, serviceLogs, true, globalWait, globalTimeout, verbose); err != nil {
 						progress.Verbose("Service '%s' failed in level %d: %v", service.Name, levelNum, err)
 						errChan <- serviceError{serviceName: service.Name, err: err}
 					} else {
-						successChan <- true
+						successChan <- service.Name
 					}
 				}(svc, itr)
 			}
@@ -366,11 +622,17 @@ func runUp(cmd *cobra.Command, args []string) error {
 			// Check for errors (fail-fast with context)
 			if len(errChan) > 0 {
 				svcErr := <-errChan
-				return fmt.Errorf("failed to install service '%s' in level %d: %w", svcErr.serviceName, levelNum, svcErr.err)
+				for name := range successChan {
+					completed[name] = true
+				}
+				return failRun(fmt.Errorf("failed to install service '%s' in level %d: %w", svcErr.serviceName, levelNum, svcErr.err))
 			}
 
 			// Count successes
-			successCount += len(successChan)
+			for name := range successChan {
+				completed[name] = true
+				successCount++
+			}
 		}
 
 		// Apply post-ready delay after each level
@@ -382,19 +644,60 @@ func runUp(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Nothing is left to resume once the interrupted run's services are installed
+	if st.InterruptedRun != nil && installedAll(st.InterruptedRun.Pending, completed) {
+		st.SetInterruptedRun(nil)
+		if err := st.Save(ctx, clientset); err != nil {
+			Verbose("Warning: failed to save cluster state: %v", err)
+		}
+	}
+
 	// Finish progress display
 	progress.Finish(successCount)
 
+	// Include the services installed last, and write the host .env file
+	if cfg.Discovery != nil {
+		warnDiscovery(refreshDiscovery(ctx, cfg, clientset, st))
+	}
+
+	if agentInstalled {
+		var installed []*config.ServiceConfig
+		for _, svc := range orderedServices {
+			if st.IsServiceInstalled(svc.Name) {
+				installed = append(installed, svc)
+			}
+		}
+		if err := publishAgentServices(ctx, cfg, installed, kubeconfig, clientset); err != nil {
+			fmt.Printf("%s %v\n", color.Warning(), err)
+		}
+	}
+
+	if upForward {
+		startUpForwards(cmd, orderedServices)
+	}
+
 	fmt.Printf("\nTo check status: kraze status\n")
 	fmt.Printf("To tear down:    kraze down\n")
 
 	return nil
 }
 
-// Mutex for protecting shared resources during parallel installation
+// Mutex for protecting shared resources during parallel installation.
+// dockerMutex only covers operations that change cluster images (untag/save/load).
 var (
 	dockerMutex sync.Mutex
 	stateMutex  sync.Mutex
+	lintMutex   sync.Mutex
+
+	// workloadFindings collects install-time lint findings across parallel installs
+	workloadFindings []config.LintFinding
+
+	// overriddenResources collects what resources_override changed, by service
+	overriddenResources map[string][]providers.ResourceChange
+
+	// optionalNotReady collects the optional workloads that didn't become ready, by service
+	optionalNotReady      map[string][]string
+	optionalNotReadyMutex sync.Mutex
 )
 
 // installService installs a single service - can be called from a goroutine
@@ -402,17 +705,38 @@ func installService(
 	ctx context.Context,
 	svc *config.ServiceConfig,
 	serviceIndex int,
+	reason string,
 	cfg *config.Config,
 	kubeconfig string,
 	st *state.ClusterState,
 	clientset kubernetes.Interface,
-	kindMgr *cluster.KindManager,
-	imgMgr *cluster.ImageManager,
+	images *imageQueue,
 	progress ui.ProgressManager,
+	logs *ui.ServiceLogs,
+	parallel bool,
 	globalWait bool,
 	globalTimeout string,
 	verbose bool,
-) error {
+) (err error) {
+	ctx, span := telemetry.StartSpan(ctx, "service.install", telemetry.Service(svc.Name))
+	start := time.Now()
+	defer func() {
+		telemetry.EndSpan(span, err)
+		emitEvent(ctx, cfg, events.Finished(events.ServiceInstall, cfg.Cluster.Name, svc.Name, start, err))
+	}()
+
+	// Services installed in parallel prefix their console lines with their name
+	output, err := logs.Open(svc.Name, parallel)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		output.Close()
+		if path := logs.Path(svc.Name); err != nil && path != "" {
+			err = fmt.Errorf("%w (full log: %s)", err, path)
+		}
+	}()
+
 	// Update progress to show we're installing this service
 	progress.UpdateService(serviceIndex, svc.Name, ui.StatusInstalling, fmt.Sprintf("(%s)", svc.Type))
 	progress.Verbose("Installing '%s' (%s)...", svc.Name, svc.Type)
@@ -424,6 +748,10 @@ func installService(
 		progress.Verbose("Service '%s' has wait=%v configured", svc.Name, serviceWait)
 	}
 
+	if svc.IsHelm() && svc.ShouldWaitForJobs() {
+		progress.Verbose("Service '%s' has wait_for_jobs configured, Helm waits for its resources and Jobs before post-install/upgrade hooks", svc.Name)
+	}
+
 	// Determine timeout for this service (precedence: service config > CLI flag)
 	serviceTimeout := globalTimeout
 	if svc.WaitTimeout != "" {
@@ -431,14 +759,73 @@ func installService(
 		progress.Verbose("Service '%s' has wait_timeout=%s configured", svc.Name, serviceTimeout)
 	}
 
+	// Services that others depend on always wait for their migration Jobs,
+	// so dependents never start against an unmigrated schema
+	dependents := cfg.GetDependents(svc.Name)
+	if !serviceWait && len(dependents) > 0 {
+		progress.Verbose("Service '%s' has dependents %v, will wait for its migration jobs", svc.Name, dependents)
+	}
+
+	pendingTimeout, err := svc.GetPendingTimeout()
+	if err != nil {
+		return err
+	}
+
+	// Resources the last install applied, pruned when the manifests no longer contain them
+	stateMutex.Lock()
+	previousResources := st.GetAppliedResources(svc.Name)
+	stateMutex.Unlock()
+	var appliedResources []providers.ResourceRef
+
 	// Create provider options
 	providerOpts := &providers.ProviderOptions{
-		ClusterName: cfg.Cluster.Name,
-		KubeConfig:  kubeconfig,
-		Wait:        serviceWait,
-		Timeout:     serviceTimeout,
-		Verbose:     verbose,
-		Quiet:       !verbose, // Suppress intermediate output unless verbose
+		ClusterName:       cfg.Cluster.Name,
+		KubeConfig:        kubeconfig,
+		Wait:              serviceWait,
+		Timeout:           serviceTimeout,
+		Verbose:           verbose || upLogsDir != "",
+		Quiet:             !verbose && upLogsDir == "", // Suppress intermediate output unless verbose or logged
+		Output:            output,
+		WaitForMigrations: len(dependents) > 0,
+		ForceConflicts:    upForceConflicts,
+		ReadinessRules:    cfg.Readiness,
+		Workloads:         svc.Workloads,
+		PendingTimeout:    pendingTimeout,
+		SuspendCronJobs:   cfg.ShouldSuspendCronJobs(svc),
+		Registries:        cfg.Registries,
+		OnWarningEvent: func(notice providers.EventNotice) {
+			// Show the latest warning next to the service and the full event in verbose output
+			progress.UpdateService(serviceIndex, svc.Name, ui.StatusInstalling, fmt.Sprintf("Warning: %s %s", notice.Reason, notice.Name))
+			progress.Verbose("%s %s: %s", color.Warning(), svc.Name, notice.String())
+		},
+		OnLintFinding: func(finding config.LintFinding) {
+			if cfg.IsLintRuleIgnored(finding.RuleID) {
+				return
+			}
+			finding.Field = fmt.Sprintf("services.%s %s", svc.Name, finding.Field)
+			progress.Verbose("%s %s", color.Warning(), finding.String())
+			lintMutex.Lock()
+			workloadFindings = append(workloadFindings, finding)
+			lintMutex.Unlock()
+		},
+		OnOptionalNotReady: func(waitErr *providers.ResourceWaitError) {
+			progress.Verbose("%s %s: optional %s/%s isn't ready, continuing: %v", color.Warning(), svc.Name, waitErr.Kind, waitErr.Name, waitErr)
+			optionalNotReadyMutex.Lock()
+			optionalNotReady[svc.Name] = append(optionalNotReady[svc.Name], waitErr.Error())
+			optionalNotReadyMutex.Unlock()
+		},
+		OnApplied: func(refs []providers.ResourceRef) {
+			appliedResources = refs
+		},
+		OnResourcesOverridden: func(changes []providers.ResourceChange) {
+			progress.Verbose("resources_override changed %d request(s) and limit(s) of '%s'", len(changes), svc.Name)
+			lintMutex.Lock()
+			overriddenResources[svc.Name] = changes
+			lintMutex.Unlock()
+		},
+	}
+	if !upNoPrune {
+		providerOpts.PruneResources = resourceRefs(previousResources)
 	}
 
 	// Create provider for this service
@@ -447,146 +834,30 @@ func installService(
 		return fmt.Errorf("failed to create provider for '%s': %w", svc.Name, err)
 	}
 
-	// Extract images from service configuration (read-only, no lock needed)
-	serviceImages, err := imgMgr.GetImagesForService(ctx, svc, kubeconfig)
-	if err != nil {
-		progress.Verbose("Warning: failed to extract images for '%s': %v", svc.Name, err)
-		serviceImages = []string{}
-	}
-
-	if len(serviceImages) > 0 {
-		// Check if context was cancelled before expensive Docker operations
+	// Wait for the service's images, which the image queue may already have
+	// built and loaded while earlier services were applying or waiting
+	_, awaitSpan := telemetry.StartSpan(ctx, "service.await_images", telemetry.Service(svc.Name))
+	prepared := images.Await(ctx, svc, func(message string) {
+		progress.UpdateService(serviceIndex, svc.Name, ui.StatusInstalling, message)
+	})
+	telemetry.EndSpan(awaitSpan, prepared.err)
+	if prepared.err != nil {
 		if ctx.Err() != nil {
 			progress.UpdateService(serviceIndex, svc.Name, ui.StatusFailed, "Cancelled")
 			return ctx.Err()
 		}
+		progress.UpdateService(serviceIndex, svc.Name, ui.StatusFailed, "Build failed")
+		return prepared.err
+	}
 
-		// Lock for Docker operations (pull/load) to avoid race conditions
-		dockerMutex.Lock()
-
-		progress.Verbose("Detected %d image(s) for service '%s': %v", len(serviceImages), svc.Name, serviceImages)
-
-		// Get image info and hashes for all detected images
-		imageHashes := make(map[string]string)
-		localImages := make([]string, 0)
-
-		for _, img := range serviceImages {
-			imgInfo, err := imgMgr.GetImageInfo(ctx, img)
-			if err != nil {
-				progress.Verbose("Warning: failed to get info for image '%s': %v", img, err)
-				continue
-			}
-
-			// Store the hash
-			if imgInfo.SHA256 != "" {
-				imageHashes[img] = imgInfo.SHA256
-			}
-
-			// Collect images present in local daemon (built or pre-pulled)
-			if imgInfo.InLocalDaemon {
-				localImages = append(localImages, img)
-			}
-		}
-
-		// Determine which images need to be loaded
-		imagesToLoad := make([]string, 0)
-		imagesToRemove := make([]string, 0) // Track images that need to be removed before reloading
-
-		// Only process local images (built locally, no registry source).
-		// Registry images (those with RepoDigests) are pulled directly by kind's containerd
-		// and do not need to be loaded via the host Docker daemon.
-		for _, img := range serviceImages {
-			currentHash := imageHashes[img]
-			imgInfo, _ := imgMgr.GetImageInfo(ctx, img)
-
-			if imgInfo != nil && imgInfo.InLocalDaemon {
-				// Image is present in the local Docker daemon (built locally or pre-pulled).
-				// Load it into the kind cluster rather than relying on kind's containerd
-				// to pull from the registry — the registry may be unreachable from inside
-				// the kind node network.
-				needsLoad := false
-				needsRemove := false
-				if cfg.Cluster.IsExternal() {
-					// External cluster - use state file comparison
-					needsLoad = st.HasImageHashChanged(svc.Name, img, currentHash)
-					if needsLoad {
-						progress.Verbose("Image '%s' changed (state file), but external cluster - skipping auto-load", img)
-						needsLoad = false
-					} else {
-						progress.Verbose("Image '%s' unchanged (hash matches state), skipping", img)
-					}
-				} else {
-					// Kind cluster - compare with actual cluster
-					clusterHash, err := imgMgr.GetClusterImageHash(ctx, cfg.Cluster.Name, img)
-					if err != nil {
-						progress.Verbose("Warning: failed to get cluster image hash for '%s': %v", img, err)
-						needsLoad = true
-					} else if clusterHash == "" {
-						progress.Verbose("Image '%s' not found in cluster, will load", img)
-						needsLoad = true
-					} else if clusterHash != currentHash {
-						progress.Verbose("Image '%s' changed (cluster: %s, local: %s), will reload", img, clusterHash[:12], currentHash[:12])
-						needsLoad = true
-						needsRemove = true // Remove old image before loading new one
-					} else {
-						progress.Verbose("Image '%s' unchanged (hash matches cluster), skipping load", img)
-					}
-				}
-
-				if needsLoad {
-					imagesToLoad = append(imagesToLoad, img)
-					if needsRemove {
-						imagesToRemove = append(imagesToRemove, img)
-					}
-				}
-			} else {
-				// Image is not in the local Docker daemon — kind will pull it from the registry.
-				progress.Verbose("Image '%s' not found in local daemon, kind will pull from registry", img)
-			}
-		}
-
-		// Untag old images that have changed (must be done before loading)
-		// This removes the tag reference but leaves the image data so running pods aren't affected
-		if len(imagesToRemove) > 0 {
-			progress.Verbose("Untagging %d outdated image(s) from cluster...", len(imagesToRemove))
-			for _, img := range imagesToRemove {
-				progress.Verbose("Untagging old version of image '%s'...", img)
-				if err := kindMgr.UntagImage(ctx, cfg.Cluster.Name, img); err != nil {
-					progress.Verbose("Warning: failed to untag old image '%s': %v", img, err)
-					progress.Verbose("  This may cause the tag to point to the old image even after loading the new one")
-				} else {
-					progress.Verbose("%s Old tag removed for '%s' (image data kept for running pods)", color.Checkmark(), img)
-				}
-			}
-		}
-
-		// Load images that need to be loaded
-		if len(imagesToLoad) > 0 {
-			progress.UpdateService(serviceIndex, svc.Name, ui.StatusInstalling, fmt.Sprintf("Loading %d image(s)", len(imagesToLoad)))
-
-			for _, img := range imagesToLoad {
-				progress.Verbose("Loading image '%s'...", img)
-				if err := kindMgr.LoadImage(ctx, cfg.Cluster.Name, img); err != nil {
-					progress.Verbose("Warning: failed to load image '%s': %v", img, err)
-				} else {
-					progress.Verbose("%s Image '%s' loaded", color.Checkmark(), img)
-				}
-			}
-
-			progress.Verbose("%s Images loaded successfully", color.Checkmark())
-		} else if len(localImages) > 0 {
-			progress.Verbose("All %d local image(s) already loaded (hashes match)", len(localImages))
-		}
-
-		dockerMutex.Unlock()
-
+	if len(prepared.images) > 0 {
 		// Restart any pods stuck in ImagePullBackOff for this service's images.
-		// Using serviceImages (not just imagesToLoad) covers images that were already
+		// Using all of the service's images (not just the loaded ones) covers images that were already
 		// in the cluster — stale stuck pods from previous runs won't be caught otherwise.
-		restartImagePullBackOffPods(ctx, clientset, svc.GetNamespace(), serviceImages, progress)
+		restartImagePullBackOffPods(ctx, clientset, svc.GetNamespace(), prepared.images, progress)
 
 		// Store image hashes in state for future comparisons
-		if len(imageHashes) > 0 {
+		if len(prepared.hashes) > 0 {
 			defer func(serviceName string, hashes map[string]string) {
 				stateMutex.Lock()
 				defer stateMutex.Unlock()
@@ -597,10 +868,15 @@ func installService(
 						progress.Verbose("Warning: failed to save cluster state (image hashes): %v", err)
 					}
 				}
-			}(svc.Name, imageHashes)
+			}(svc.Name, prepared.hashes)
 		}
 	}
 
+	// Wait until dependencies with a service_healthy condition pass their probes
+	if err := waitForDependencies(ctx, svc, serviceIndex, cfg, kubeconfig, progress); err != nil {
+		return err
+	}
+
 	// Check if namespace exists before installing (to track if we'll create it)
 	namespace := svc.GetNamespace()
 	namespaceExists, err := providers.CheckNamespaceExists(ctx, kubeconfig, namespace)
@@ -620,23 +896,83 @@ func installService(
 		return ctx.Err()
 	}
 
+	// Publish where the services installed so far are, so the service's pods find its dependencies
+	if cfg.Discovery != nil {
+		if err := writeDiscoveryMaps(ctx, cfg, clientset, map[string]bool{namespace: willCreateNamespace}); err != nil {
+			progress.Verbose("Warning: failed to update the discovery map: %v", err)
+		}
+	}
+
+	if err := runServiceHooks(ctx, config.HookPreInstall, svc, serviceIndex, cfg.Cluster.Name, kubeconfig, ui.StatusInstalling, progress); err != nil {
+		progress.UpdateService(serviceIndex, svc.Name, ui.StatusFailed, "pre_install hook failed")
+		return fmt.Errorf("failed to install '%s': %w", svc.Name, err)
+	}
+
 	// Update status to show we're applying resources
 	progress.UpdateService(serviceIndex, svc.Name, ui.StatusInstalling, "Applying resources")
 
 	// Install the service
-	if err := provider.Install(ctx, svc); err != nil {
+	installStart := time.Now()
+	if err := installWithRetries(ctx, provider, svc, serviceIndex, progress); err != nil {
+		if ctx.Err() != nil {
+			progress.UpdateService(serviceIndex, svc.Name, ui.StatusFailed, "Interrupted")
+			recoverInterruptedInstall(ctx, svc, providerOpts, willCreateNamespace, st, clientset, progress)
+			return ctx.Err()
+		}
 		progress.UpdateService(serviceIndex, svc.Name, ui.StatusFailed, err.Error())
 		return fmt.Errorf("failed to install '%s': %w", svc.Name, err)
 	}
 
+	if providerOpts.SuspendCronJobs {
+		reportSuspendedCronJobs(ctx, clientset, svc, progress.Verbose)
+	}
+
 	// Update cluster state with namespace tracking (protected by mutex)
 	stateMutex.Lock()
 	st.MarkServiceInstalledWithNamespace(svc.Name, namespace, willCreateNamespace)
+	if !st.IsNamespaceCreated(namespace) {
+		// Pre-existing namespace: record which resources are ours so down only removes those
+		st.SetOwnedSelectors(svc.Name, providers.OwnedResourceSelectors(svc))
+	}
+	st.SetInstallStats(svc.Name, time.Since(installStart), prepared.bytes)
+	st.SetAppliedResources(svc.Name, recordedResources(previousResources, appliedResources, !upNoPrune))
+	st.SetInstallReason(svc.Name, reason)
 	if err := st.Save(ctx, clientset); err != nil {
 		progress.Verbose("Warning: failed to save cluster state: %v", err)
 	}
 	stateMutex.Unlock()
 
+	// Check the service's assertions once it's ready; without waiting it may not be yet
+	if serviceWait && len(svc.Assertions) > 0 && !upSkipAssertions {
+		progress.UpdateService(serviceIndex, svc.Name, ui.StatusInstalling, fmt.Sprintf("Checking %d assertion(s)", len(svc.Assertions)))
+		results, err := providers.RunAssertions(ctx, kubeconfig, svc)
+		if err != nil {
+			progress.UpdateService(serviceIndex, svc.Name, ui.StatusFailed, "Assertions failed")
+			return fmt.Errorf("failed to check assertions of '%s': %w", svc.Name, err)
+		}
+		for _, result := range results {
+			progress.Verbose("%s: %s", svc.Name, result.String())
+		}
+		recordAssertions(ctx, clientset, st, svc.Name, results)
+		if !providers.AssertionsPassed(results) {
+			progress.UpdateService(serviceIndex, svc.Name, ui.StatusFailed, "Assertions failed")
+			return assertionsError(svc.Name, results)
+		}
+	}
+
+	if err := runServiceHooks(ctx, config.HookPostInstall, svc, serviceIndex, cfg.Cluster.Name, kubeconfig, ui.StatusInstalling, progress); err != nil {
+		progress.UpdateService(serviceIndex, svc.Name, ui.StatusFailed, "post_install hook failed")
+		return fmt.Errorf("failed to install '%s': %w", svc.Name, err)
+	}
+
+	// Fixtures need a ready service; without waiting, 'kraze seed' loads them later
+	if serviceWait && len(svc.Fixtures) > 0 && !upSkipFixtures {
+		if _, err := runServiceFixtures(ctx, svc, serviceIndex, cfg.Cluster.Name, kubeconfig, clientset, st, false, progress); err != nil {
+			progress.UpdateService(serviceIndex, svc.Name, ui.StatusFailed, "Fixture failed")
+			return err
+		}
+	}
+
 	// Mark service as ready
 	progress.UpdateService(serviceIndex, svc.Name, ui.StatusReady, "Deployed")
 
@@ -655,6 +991,63 @@ func installService(
 	return nil
 }
 
+// installWithRetries installs a service, retrying failures that may go away
+// on their own as many times as the service's retries or --retry allow
+func installWithRetries(ctx context.Context, provider providers.Provider, svc *config.ServiceConfig, serviceIndex int, progress ui.ProgressManager) error {
+	retries := svc.GetRetries(upRetry)
+	for attempt := 1; ; attempt++ {
+		err := provider.Install(ctx, svc)
+		if err == nil || attempt > retries || !providers.IsTransientError(err) || ctx.Err() != nil {
+			if err != nil && attempt > 1 {
+				return fmt.Errorf("%w (after %d attempts)", err, attempt)
+			}
+			return err
+		}
+
+		delay := svc.GetRetryBackoff(attempt)
+		progress.Verbose("%s Install of '%s' failed (attempt %d/%d), retrying in %v: %v", color.Warning(), svc.Name, attempt, retries+1, delay, err)
+		progress.UpdateService(serviceIndex, svc.Name, ui.StatusInstalling, fmt.Sprintf("Retrying in %v (attempt %d/%d failed)", delay, attempt, retries+1))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		progress.UpdateService(serviceIndex, svc.Name, ui.StatusInstalling, fmt.Sprintf("Applying resources (attempt %d/%d)", attempt+1, retries+1))
+	}
+}
+
+// waitForDependencies blocks until each dependency of a service with a
+// service_healthy condition passes its probe. Dependencies are installed in
+// earlier levels, so this only waits for them to accept connections.
+func waitForDependencies(ctx context.Context, svc *config.ServiceConfig, serviceIndex int, cfg *config.Config, kubeconfig string, progress ui.ProgressManager) error {
+	for _, name := range svc.DependsOn.Names() {
+		condition := svc.DependsOn.Condition(name)
+		if !condition.IsHealthCheck() {
+			continue
+		}
+		dependency, exists := cfg.Services[name]
+		if !exists {
+			progress.Verbose("Dependency '%s' of '%s' is not part of this run, skipping its probe", name, svc.Name)
+			continue
+		}
+
+		progress.UpdateService(serviceIndex, svc.Name, ui.StatusInstalling, fmt.Sprintf("Waiting for %s (%s)", name, condition.ProbeDescription()))
+		waitCtx, span := telemetry.StartSpan(ctx, "wait.dependency", telemetry.Service(name))
+		err := providers.WaitForDependency(waitCtx, kubeconfig, &dependency, condition)
+		telemetry.EndSpan(span, err)
+		if err != nil {
+			if ctx.Err() != nil {
+				progress.UpdateService(serviceIndex, svc.Name, ui.StatusFailed, "Cancelled")
+				return ctx.Err()
+			}
+			progress.UpdateService(serviceIndex, svc.Name, ui.StatusFailed, fmt.Sprintf("Dependency '%s' not healthy", name))
+			return err
+		}
+		progress.Verbose("%s Dependency '%s' of '%s' is healthy", color.Checkmark(), name, svc.Name)
+	}
+	return nil
+}
+
 // restartImagePullBackOffPods deletes pods in the given namespace that are stuck
 // in ImagePullBackOff or ErrImagePull for one of the just-loaded images. Deleting
 // them causes the ReplicaSet controller to recreate them immediately, bypassing
@@ -708,10 +1101,232 @@ func restartImagePullBackOffPods(ctx context.Context, clientset kubernetes.Inter
 	}
 }
 
+// startUpForwards (re)starts the port-forward daemon for the installed services that
+// declare ports, keeping the forwards of services an already running daemon serves.
+// Failures are reported as warnings since the services themselves are up.
+func startUpForwards(cmd *cobra.Command, installed []*config.ServiceConfig) {
+	// Re-parse since up filters cfg.Services down to the services being installed
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		fmt.Printf("%s Not starting port-forwards: %v\n", color.Warning(), err)
+		return
+	}
+	defer cleanupPack()
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		fmt.Printf("%s Not starting port-forwards: failed to parse config: %v\n", color.Warning(), err)
+		return
+	}
+
+	names := make([]string, 0, len(installed))
+	seen := make(map[string]bool)
+	for _, svc := range installed {
+		names = append(names, svc.Name)
+		seen[svc.Name] = true
+	}
+
+	existing, err := cluster.LoadForwardStatus(cfg.Cluster.Name)
+	if err != nil {
+		fmt.Printf("%s Failed to read port-forward status: %v\n", color.Warning(), err)
+		return
+	}
+	if existing != nil && existing.IsLive(time.Now()) {
+		for _, fwd := range existing.Forwards {
+			if _, declared := cfg.Services[fwd.Service]; declared && !seen[fwd.Service] {
+				names = append(names, fwd.Service)
+				seen[fwd.Service] = true
+			}
+		}
+	} else {
+		existing = nil
+	}
+
+	targets, err := declaredForwards(cfg, names)
+	if err != nil {
+		fmt.Printf("%s Not starting port-forwards: %v\n", color.Warning(), err)
+		return
+	}
+	if len(targets) == 0 {
+		Verbose("No port-forwards declared for the installed services")
+		return
+	}
+
+	// Restart a running daemon so it picks up newly installed services
+	if existing != nil {
+		if err := stopForwardDaemon(cfg.Cluster.Name, existing.PID); err != nil {
+			fmt.Printf("%s %v\n", color.Warning(), err)
+			return
+		}
+	}
+
+	pid, err := launchForwardDaemon(cmd, cfg.Cluster.Name, names)
+	if err != nil {
+		fmt.Printf("%s Failed to start port-forwards: %v\n", color.Warning(), err)
+		return
+	}
+
+	fmt.Printf("\n%s Port-forwards running in the background (pid %d)\n", color.Checkmark(), pid)
+	printForwardTargets(targets)
+	fmt.Printf("Run 'kraze ports' to check them, 'kraze forward stop' to stop them\n")
+}
+
+// resourceRefs converts applied resources recorded in the cluster state
+func resourceRefs(recorded []string) []providers.ResourceRef {
+	refs := make([]providers.ResourceRef, len(recorded))
+	for itr, ref := range recorded {
+		refs[itr] = providers.ResourceRef(ref)
+	}
+	return refs
+}
+
+// recordedResources returns the resources to record for a service after an
+// install: those it applied and, when pruning is off, those applied before, so
+// a later install can still prune them
+func recordedResources(previous []string, applied []providers.ResourceRef, prune bool) []string {
+	var recorded []string
+	if !prune {
+		for _, ref := range providers.StaleResources(resourceRefs(previous), applied) {
+			recorded = append(recorded, string(ref))
+		}
+	}
+	for _, ref := range applied {
+		recorded = append(recorded, string(ref))
+	}
+	sort.Strings(recorded)
+	return recorded
+}
+
+// printResourceChanges prints what resources_override changed, by service
+func printResourceChanges(changes map[string][]providers.ResourceChange) {
+	names := make([]string, 0, len(changes))
+	for name := range changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("  %s:\n", name)
+		for _, change := range changes[name] {
+			fmt.Printf("    %s\n", change.String())
+		}
+	}
+}
+
+// printOptionalNotReady prints why each service's optional workloads aren't ready
+func printOptionalNotReady(notReady map[string][]string) {
+	for _, name := range slices.Sorted(maps.Keys(notReady)) {
+		fmt.Printf("  %s:\n", name)
+		for _, reason := range notReady[name] {
+			fmt.Printf("    %s\n", reason)
+		}
+	}
+}
+
 func init() {
+	addLockFlag(upCmd)
 	upCmd.Flags().BoolVar(&upWait, "wait", true, "Wait for services to be ready")
 	upCmd.Flags().BoolVar(&upNoWait, "no-wait", false, "Don't wait for services to be ready")
 	upCmd.Flags().StringVar(&upTimeout, "timeout", "10m", "Timeout for wait operations")
 	upCmd.Flags().BoolVar(&upNoDeps, "no-deps", false, "Don't install dependencies (only install specified services)")
 	upCmd.Flags().StringSliceVarP(&upLabels, "label", "l", []string{}, "Filter services by label (format: key=value, can be specified multiple times)")
+	registerLabelCompletion(upCmd)
+	upCmd.Flags().BoolVar(&upForceConflicts, "force-conflicts", false, "Take ownership of manifest fields managed by other field managers during server-side apply")
+	upCmd.Flags().BoolVar(&upSkipAssertions, "skip-assertions", false, "Don't check the services' assertions after they become ready")
+	upCmd.Flags().BoolVar(&upSkipFixtures, "skip-fixtures", false, "Don't load the services' fixtures after they become ready")
+	upCmd.Flags().BoolVar(&upNoPrune, "no-prune", false, "Don't delete resources of manifests services that their manifests no longer contain")
+	upCmd.Flags().BoolVar(&upBuild, "build", false, "Rebuild images of services with a 'build' block even if their build context is unchanged")
+	upCmd.Flags().BoolVar(&upNoBuild, "no-build", false, "Don't build images of services with a 'build' block")
+	upCmd.Flags().BoolVar(&upSkipAPICheck, "skip-api-check", false, "Don't scan rendered resources for APIs the cluster has removed or deprecated")
+	upCmd.Flags().BoolVar(&upDiff, "diff", false, "Show a diff of the changes to each resource before applying them")
+	upCmd.Flags().BoolVar(&upDiffOnly, "diff-only", false, "Show the diff without applying anything, exiting non-zero if anything would change")
+	upCmd.Flags().BoolVar(&upForward, "forward", false, "Start the port-forwards declared with 'ports' in a background daemon after installing")
+	upCmd.Flags().BoolVar(&upRecreateCluster, "recreate-cluster", false, "Recreate the kind cluster if its nodes, ports, networking, CAs or registries changed since it was created")
+	upCmd.Flags().IntVar(&upRetry, "retry", 0, "Retry installs that fail transiently this many times (services' 'retries' take precedence)")
+	upCmd.Flags().StringVar(&upFromBundle, "from-bundle", "", "Install from a bundle made by 'kraze bundle' without network access, loading its images instead of building or pulling any")
+	upCmd.Flags().BoolVar(&upResume, "resume", false, "Continue the last run that was interrupted with Ctrl-C, installing the services it didn't finish")
+	upCmd.Flags().StringVar(&upLogsDir, "logs-dir", "", "Write each service's full install output to <dir>/<service>.log, showing only summaries")
+	upCmd.Flags().DurationVar(&upAutoStop, "auto-stop", 0, "Stop the kind cluster after this long without kraze activity (e.g., 2h; 0 disables)")
+	addRecordFlags(upCmd)
+	addImpersonationFlags(upCmd)
+}
+
+// checkServerKubernetesVersion verifies that the cluster's API server version is
+// within cluster.kubernetes
+func checkServerKubernetesVersion(clientset kubernetes.Interface, clusterCfg *config.ClusterConfig) error {
+	if clusterCfg.Kubernetes == "" {
+		return nil
+	}
+
+	serverVersion, err := clientset.Discovery().ServerVersion()
+	if err != nil {
+		return fmt.Errorf("failed to get Kubernetes version of cluster '%s': %w", clusterCfg.Name, err)
+	}
+	Verbose("Cluster '%s' runs Kubernetes %s (required: %s)", clusterCfg.Name, serverVersion.GitVersion, clusterCfg.Kubernetes)
+
+	source := fmt.Sprintf("cluster '%s'", clusterCfg.Name)
+	if err := clusterCfg.CheckKubernetesVersion(serverVersion.GitVersion, source); err != nil {
+		if clusterCfg.IsExternal() {
+			return fmt.Errorf("%w\nUse an external cluster in that range or adjust cluster.kubernetes", err)
+		}
+		return fmt.Errorf("%w\nSet cluster.version to a release in that range and recreate the cluster: kraze destroy && kraze up", err)
+	}
+	return nil
+}
+
+// scanServiceAPIs renders every service and checks the API versions of its
+// resources against the cluster's Kubernetes version. Deprecated APIs are
+// reported as warnings; APIs the cluster has removed fail the run.
+func scanServiceAPIs(ctx context.Context, cfg *config.Config, services []*config.ServiceConfig, kubeconfig string, clientset kubernetes.Interface) error {
+	serverVersion, err := clientset.Discovery().ServerVersion()
+	if err != nil {
+		Verbose("Skipping API deprecation scan, failed to get cluster version: %v", err)
+		return nil
+	}
+	kubeVersion, err := config.ParseKubernetesVersion(serverVersion.GitVersion)
+	if err != nil {
+		Verbose("Skipping API deprecation scan: %v", err)
+		return nil
+	}
+
+	var findings []config.LintFinding
+	removed := 0
+	for _, svc := range services {
+		provider, err := providers.NewProvider(svc, &providers.ProviderOptions{
+			ClusterName: cfg.Cluster.Name,
+			KubeConfig:  kubeconfig,
+			Verbose:     verbose,
+			Quiet:       true,
+			Registries:  cfg.Registries,
+		})
+		if err != nil {
+			Verbose("Skipping API deprecation scan for '%s': %v", svc.Name, err)
+			continue
+		}
+		// Render failures are reported by the install itself
+		resources, err := provider.Render(ctx, svc)
+		if err != nil {
+			Verbose("Skipping API deprecation scan for '%s': %v", svc.Name, err)
+			continue
+		}
+
+		for _, finding := range providers.ScanDeprecatedAPIs(resources, kubeVersion) {
+			if cfg.IsLintRuleIgnored(finding.RuleID) {
+				continue
+			}
+			if finding.RuleID == providers.RuleRemovedAPI {
+				removed++
+			}
+			finding.Field = fmt.Sprintf("services.%s %s", svc.Name, finding.Field)
+			findings = append(findings, finding)
+		}
+	}
+
+	if len(findings) == 0 {
+		return nil
+	}
+	printLintFindings(findings)
+	fmt.Println()
+	if removed > 0 {
+		return fmt.Errorf("%d resource(s) use APIs that Kubernetes %d.%d no longer serves; migrate them, set cluster.version to an older release, or pass --skip-api-check", removed, kubeVersion.Major(), kubeVersion.Minor())
+	}
+	return nil
 }
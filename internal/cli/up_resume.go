@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/hjames9/kraze/internal/state"
+	"github.com/hjames9/kraze/internal/ui"
+	"k8s.io/client-go/kubernetes"
+)
+
+// interruptContext returns a context that's cancelled on the first Ctrl-C (or
+// SIGTERM), so the installs in flight stop and clean up instead of kraze dying
+// halfway through them. A second Ctrl-C gets the default handling and exits.
+func interruptContext(parent context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(parent)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-signals:
+			signal.Stop(signals)
+			fmt.Fprintf(os.Stderr, "\n%s Interrupted, stopping the installs in progress (press Ctrl-C again to exit now)...\n", color.Warning())
+			cancel()
+		case <-done:
+		}
+	}()
+	return ctx, func() {
+		close(done)
+		signal.Stop(signals)
+		cancel()
+	}
+}
+
+// loadInterruptedRun returns the interrupted 'kraze up' recorded in the cluster state
+func loadInterruptedRun(ctx context.Context, cfg *config.Config) (*state.InterruptedRun, error) {
+	kubeconfig, err := getShellKubeconfig(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	st, err := state.Load(ctx, clientset, cfg.Cluster.Name, cfg.StateBackend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cluster state: %w", err)
+	}
+	if st == nil || st.InterruptedRun == nil {
+		return nil, fmt.Errorf("no interrupted 'kraze up' to resume in cluster '%s'", cfg.Cluster.Name)
+	}
+	return st.InterruptedRun, nil
+}
+
+// pendingLevels keeps the services of each dependency level that are pending,
+// dropping levels left empty. The other services stay in the config, so
+// pending services still wait for their dependencies' probes.
+func pendingLevels(levels [][]*config.ServiceConfig, pending []string) [][]*config.ServiceConfig {
+	isPending := make(map[string]bool, len(pending))
+	for _, name := range pending {
+		isPending[name] = true
+	}
+
+	var kept [][]*config.ServiceConfig
+	for _, level := range levels {
+		var services []*config.ServiceConfig
+		for _, svc := range level {
+			if isPending[svc.Name] {
+				services = append(services, svc)
+			}
+		}
+		if len(services) > 0 {
+			kept = append(kept, services)
+		}
+	}
+	return kept
+}
+
+// installedAll returns true if every service was installed
+func installedAll(services []string, installed map[string]bool) bool {
+	for _, name := range services {
+		if !installed[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// recoverInterruptedInstall cleans up after a service's install was interrupted
+// while it applied resources. A Helm release is rolled back (or removed if it
+// was a first install), so it isn't left pending. The service is marked partial
+// in the state unless nothing of it is left, so 'kraze down' still removes it.
+func recoverInterruptedInstall(ctx context.Context, svc *config.ServiceConfig, opts *providers.ProviderOptions, createdNamespace bool, st *state.ClusterState, clientset kubernetes.Interface, progress ui.ProgressManager) {
+	// The run's context is cancelled, but cleaning up must still reach the cluster
+	ctx = context.WithoutCancel(ctx)
+
+	stateMutex.Lock()
+	wasInstalled := st.IsServiceInstalled(svc.Name)
+	stateMutex.Unlock()
+
+	removed := false
+	if svc.IsHelm() {
+		recovery, err := providers.RecoverInterruptedRelease(svc, opts)
+		switch {
+		case err != nil:
+			progress.Verbose("%s Failed to clean up the interrupted release of '%s': %v", color.Warning(), svc.Name, err)
+		case recovery != nil:
+			progress.Verbose("Interrupted install of '%s': %s", svc.Name, recovery)
+			removed = !wasInstalled && recovery.Uninstalled
+		}
+	}
+	if removed {
+		return
+	}
+
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+	st.MarkServicePartial(svc.Name, svc.GetNamespace(), createdNamespace)
+	if err := st.Save(ctx, clientset); err != nil {
+		progress.Verbose("Warning: failed to save cluster state: %v", err)
+	}
+}
+
+// recordInterruptedRun saves the services an interrupted 'kraze up' didn't
+// finish, so 'kraze up --resume' can continue with them
+func recordInterruptedRun(ctx context.Context, st *state.ClusterState, clientset kubernetes.Interface, run *state.InterruptedRun, ordered []*config.ServiceConfig, completed map[string]bool) error {
+	run.Pending = nil
+	for _, svc := range ordered {
+		if !completed[svc.Name] {
+			run.Pending = append(run.Pending, svc.Name)
+		}
+	}
+	run.InterruptedAt = time.Now()
+
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+	st.SetInterruptedRun(run)
+	if err := st.Save(context.WithoutCancel(ctx), clientset); err != nil {
+		return fmt.Errorf("failed to save cluster state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+)
+
+func TestPendingLevels(test *testing.T) {
+	service := func(name string) *config.ServiceConfig {
+		return &config.ServiceConfig{Name: name, Type: "helm"}
+	}
+	levels := [][]*config.ServiceConfig{
+		{service("postgres"), service("redis")},
+		{service("api")},
+		{service("web"), service("worker")},
+	}
+
+	tests := []struct {
+		name     string
+		pending  []string
+		expected [][]string
+	}{
+		{name: "nothing pending"},
+		{name: "first level done", pending: []string{"api", "web", "worker"}, expected: [][]string{{"api"}, {"web", "worker"}}},
+		{name: "partly done level", pending: []string{"redis", "worker"}, expected: [][]string{{"redis"}, {"worker"}}},
+		{name: "unknown service", pending: []string{"cache"}},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			kept := pendingLevels(levels, tt.pending)
+			if len(kept) != len(tt.expected) {
+				test.Fatalf("pendingLevels() has %d level(s), want %d", len(kept), len(tt.expected))
+			}
+			for itr, level := range kept {
+				if len(level) != len(tt.expected[itr]) {
+					test.Fatalf("level %d has %d service(s), want %v", itr, len(level), tt.expected[itr])
+				}
+				for index, svc := range level {
+					if svc.Name != tt.expected[itr][index] {
+						test.Errorf("level %d service %d = %s, want %s", itr, index, svc.Name, tt.expected[itr][index])
+					}
+				}
+			}
+		})
+	}
+
+	if !installedAll([]string{"api", "web"}, map[string]bool{"api": true, "web": true, "db": true}) {
+		test.Error("installedAll() = false with every service installed")
+	}
+	if installedAll([]string{"api", "web"}, map[string]bool{"api": true}) {
+		test.Error("installedAll() = true with web not installed")
+	}
+}
@@ -6,8 +6,11 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sversion "k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
 	"k8s.io/client-go/kubernetes/fake"
 
+	"github.com/hjames9/kraze/internal/config"
 	"github.com/hjames9/kraze/internal/ui"
 )
 
@@ -181,3 +184,30 @@ func TestRestartImagePullBackOffPods(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckServerKubernetesVersion(test *testing.T) {
+	tests := []struct {
+		name          string
+		kubernetes    string
+		serverVersion string
+		wantErr       bool
+	}{
+		{name: "no range configured", kubernetes: "", serverVersion: "v1.20.0"},
+		{name: "server inside range", kubernetes: ">=1.28 <1.32", serverVersion: "v1.31.4"},
+		{name: "managed server inside range", kubernetes: ">=1.28 <1.32", serverVersion: "v1.29.8-gke.1211000"},
+		{name: "server above range", kubernetes: ">=1.28 <1.32", serverVersion: "v1.33.1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			clientset := fake.NewClientset()
+			clientset.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &k8sversion.Info{GitVersion: tt.serverVersion}
+
+			clusterCfg := &config.ClusterConfig{Name: "test", Kubernetes: tt.kubernetes}
+			err := checkServerKubernetesVersion(clientset, clusterCfg)
+			if (err != nil) != tt.wantErr {
+				test.Errorf("checkServerKubernetesVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -9,11 +10,45 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	validateStrict bool
+	validateSchema bool
+)
+
 var validateCmd = &cobra.Command{
 	Use:   "validate",
 	Short: "Validate kraze.yml configuration",
-	Long:  `Validate the syntax and structure of your kraze.yml configuration file.`,
+	Long: `Validate the syntax and structure of your kraze.yml configuration file.
+
+Each file is first checked against the JSON Schema of kraze.yml for unknown
+fields, values of the wrong type, invalid durations and unknown values, and
+errors point at the line and column of the offending YAML. Print the schema
+with --schema to let editors complete and check kraze.yml.
+
+Validation also runs lint rules that flag conflicting or deprecated settings.
+Each rule has a stable ID that can be suppressed in the config:
+
+  lint:
+    ignore: [KZ002]
+
+With cluster.none set, every enabled service is also rendered (charts like
+'helm template') and checked for APIs that the Kubernetes version a kind
+cluster would run has removed or deprecated (KZ205, KZ206).
+
+Examples:
+  kraze validate
+  kraze validate --strict   # Fail if any lint rule reports a finding
+  kraze validate --schema > kraze.schema.json`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if validateSchema {
+			schema, err := config.Schema()
+			if err != nil {
+				return err
+			}
+			fmt.Print(string(schema))
+			return nil
+		}
+
 		cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
 		if err != nil {
 			return err
@@ -22,9 +57,16 @@ var validateCmd = &cobra.Command{
 
 		Verbose("Validating configuration file(s): %s", strings.Join(cfgPaths, ", "))
 
+		if err := checkConfigSchema(cfgPaths); err != nil {
+			return err
+		}
+
 		// Parse configuration file
 		cfg, err := config.ParseMultiple(cfgPaths)
 		if err != nil {
+			if file, line, column, found := config.LocateError(cfgPaths, err); found {
+				return fmt.Errorf("%s:%d:%d: %w", file, line, column, err)
+			}
 			return fmt.Errorf("validation failed: %w", err)
 		}
 
@@ -34,11 +76,42 @@ var validateCmd = &cobra.Command{
 		if cfg.Cluster.Version != "" {
 			fmt.Printf("Kubernetes version: %s\n", cfg.Cluster.Version)
 		}
+		if cfg.Cluster.Kubernetes != "" {
+			fmt.Printf("Tolerated Kubernetes versions: %s\n", cfg.Cluster.Kubernetes)
+		}
+		if cfg.Cluster.Preset != "" {
+			preset, _ := config.GetClusterPreset(cfg.Cluster.Preset)
+			fmt.Printf("Preset: %s (%s)\n", preset.Name, preset.Description)
+		}
+		if addons := cfg.Cluster.AddonNames(); len(addons) > 0 {
+			fmt.Printf("Add-ons: %s\n", strings.Join(addons, ", "))
+		}
 		if cfg.Cluster.NodeImage != "" {
 			fmt.Printf("Node image: %s\n", cfg.Cluster.NodeImage)
 		}
+		if topology := cfg.Cluster.Topology; topology != nil {
+			fmt.Printf("Topology: region %s, zones %s\n", topology.GetRegion(), strings.Join(topology.Zones, ", "))
+		}
 		fmt.Printf("Services: %d\n", len(cfg.Services))
 
+		findings := cfg.RunLint(cfgPaths)
+
+		// Without a cluster, check the services render and use APIs the
+		// Kubernetes version a kind cluster would run still serves
+		if cfg.Cluster.IsNone() {
+			rendered, renderFindings, err := lintOfflineRender(context.Background(), cfg)
+			if err != nil {
+				return fmt.Errorf("validation failed: %w", err)
+			}
+			fmt.Printf("Rendered: %d service(s) without a cluster\n", rendered)
+			findings = append(findings, renderFindings...)
+		}
+
+		if len(findings) > 0 {
+			fmt.Println()
+			printLintFindings(findings)
+		}
+
 		if verbose {
 			fmt.Println("\nServices:")
 			enabledCount := 0
@@ -47,8 +120,14 @@ var validateCmd = &cobra.Command{
 				if svc.IsEnabled() {
 					enabledCount++
 					fmt.Printf("  - %s (%s)\n", name, svc.Type)
-					if len(svc.DependsOn) > 0 {
-						fmt.Printf("    depends_on: %v\n", svc.DependsOn)
+					if summary := svc.GetSummary(); summary != "" {
+						fmt.Printf("    description: %s\n", summary)
+					}
+					if svc.Owner != "" {
+						fmt.Printf("    owner: %s\n", svc.Owner)
+					}
+					if !svc.DependsOn.IsEmpty() {
+						fmt.Printf("    depends_on: %v\n", svc.DependsOn.Names())
 					}
 				} else {
 					disabledCount++
@@ -59,6 +138,47 @@ var validateCmd = &cobra.Command{
 			}
 		}
 
+		if validateStrict && len(findings) > 0 {
+			return fmt.Errorf("validation failed: %d lint finding(s) in strict mode", len(findings))
+		}
+
 		return nil
 	},
 }
+
+// checkConfigSchema checks each config file against the JSON Schema of
+// kraze.yml, printing every problem before failing
+func checkConfigSchema(cfgPaths []string) error {
+	var problems []*config.SchemaError
+	for _, cfgPath := range cfgPaths {
+		fileProblems, err := config.CheckSchema(cfgPath)
+		if err != nil {
+			return fmt.Errorf("validation failed: %s: %w", cfgPath, err)
+		}
+		problems = append(problems, fileProblems...)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+
+	for _, problem := range problems {
+		fmt.Printf("%s %s\n", color.Cross(), problem.Error())
+	}
+	return fmt.Errorf("validation failed: %d schema error(s)", len(problems))
+}
+
+// printLintFindings prints lint findings as warnings with their fix hints
+func printLintFindings(findings []config.LintFinding) {
+	for _, finding := range findings {
+		fmt.Printf("%s %s\n", color.Warning(), finding.String())
+		if finding.Hint != "" {
+			fmt.Printf("    hint: %s\n", finding.Hint)
+		}
+	}
+	fmt.Printf("  (suppress a rule with 'lint: {ignore: [<rule-id>]}' in the config)\n")
+}
+
+func init() {
+	validateCmd.Flags().BoolVar(&validateStrict, "strict", false, "Treat lint findings as errors")
+	validateCmd.Flags().BoolVar(&validateSchema, "schema", false, "Print the JSON Schema of kraze.yml instead of validating")
+}
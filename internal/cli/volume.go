@@ -0,0 +1,326 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hjames9/kraze/internal/cluster"
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// volumeReleaseTimeout bounds how long --volumes waits for provisioners to
+// delete the volumes of deleted claims
+const volumeReleaseTimeout = time.Minute
+
+var volumeLsOutput string
+
+// volumeListing is a volume as printed by 'kraze volume ls -o json|yaml'
+type volumeListing struct {
+	providers.Volume
+	UsedBytes *int64 `json:"used_bytes,omitempty"` // Measured on kind nodes
+}
+
+var volumeCmd = &cobra.Command{
+	Use:   "volume",
+	Short: "Inspect the cluster's persistent volumes",
+}
+
+var volumeLsCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   "List persistent volumes and claims, and the disk space they use",
+	Long: `List the PersistentVolumes of the cluster with the claims bound to them, and
+claims still waiting for a volume, largest first.
+
+On kind clusters the space used by local volumes (kind's default local-path
+storage) is measured on the nodes. Released volumes are left over from deleted
+claims; 'kraze down --volumes' deletes them along with the services' claims.
+
+Examples:
+  kraze volume ls
+  kraze volume ls -o wide   # Storage class, reclaim policy and path on the node
+  kraze volume ls -o json   # Machine-readable listing`,
+	Args: cobra.NoArgs,
+	RunE: runVolumeLs,
+}
+
+func runVolumeLs(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	format, err := parseOutputFormat(volumeLsOutput)
+	if err != nil {
+		return err
+	}
+
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanupPack()
+
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := requireCluster(cfg, "volume ls"); err != nil {
+		return err
+	}
+
+	kindMgr := cluster.NewKindManager()
+	if !cfg.Cluster.IsExternal() {
+		if err := cluster.CheckDockerAvailable(ctx); err != nil {
+			return err
+		}
+		exists, err := kindMgr.ClusterExists(cfg.Cluster.Name)
+		if err != nil {
+			return fmt.Errorf("failed to check cluster: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("cluster '%s' does not exist. Run 'kraze up' first", cfg.Cluster.Name)
+		}
+	}
+	kubeconfig, err := getClusterKubeconfigQuiet(ctx, kindMgr, cfg)
+	if err != nil {
+		return err
+	}
+	clientset, err := providers.GetClientsetFromKubeconfigContent(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+
+	volumes, err := providers.ListVolumes(ctx, clientset)
+	if err != nil {
+		return err
+	}
+	var usage map[string]int64
+	if !cfg.Cluster.IsExternal() {
+		usage = measureVolumes(ctx, volumes)
+	}
+	listing := make([]volumeListing, 0, len(volumes))
+	for _, volume := range volumes {
+		entry := volumeListing{Volume: volume}
+		if used, ok := usage[volume.Name]; ok {
+			entry.UsedBytes = &used
+		}
+		listing = append(listing, entry)
+	}
+	sortVolumeListing(listing)
+
+	if format.isStructured() {
+		return printStructured(format, struct {
+			Cluster string          `json:"cluster"`
+			Volumes []volumeListing `json:"volumes"`
+		}{cfg.Cluster.Name, listing})
+	}
+
+	if len(listing) == 0 {
+		fmt.Printf("No persistent volumes in cluster '%s'\n", cfg.Cluster.Name)
+		return nil
+	}
+
+	fmt.Printf("Cluster: %s\n\n", cfg.Cluster.Name)
+	header := fmt.Sprintf("%-20s %-32s %-16s %-42s %-10s %-9s %-9s %-20s", "NAMESPACE", "CLAIM", "SERVICE", "VOLUME", "STATUS", "CAPACITY", "USED", "NODE")
+	if format == outputWide {
+		header += fmt.Sprintf(" %-14s %-8s %s", "STORAGECLASS", "RECLAIM", "PATH")
+	}
+	fmt.Println(strings.TrimRight(header, " "))
+
+	var total int64
+	released := 0
+	for _, entry := range listing {
+		used := "-"
+		if entry.UsedBytes != nil {
+			used = humanBytes(*entry.UsedBytes)
+			total += *entry.UsedBytes
+		}
+		if entry.Status == string(corev1.VolumeReleased) {
+			released++
+		}
+		line := fmt.Sprintf("%-20s %-32s %-16s %-42s %-10s %-9s %-9s %-20s",
+			orDash(entry.Namespace), orDash(entry.Claim), orDash(entry.Service), orDash(entry.Name),
+			orDash(entry.Status), orDash(entry.Capacity), used, orDash(entry.Node))
+		if format == outputWide {
+			line += fmt.Sprintf(" %-14s %-8s %s", orDash(entry.StorageClass), orDash(entry.ReclaimPolicy), orDash(entry.Path))
+		}
+		fmt.Println(strings.TrimRight(line, " "))
+	}
+
+	fmt.Printf("\n%d volume(s)", len(listing))
+	if usage != nil {
+		fmt.Printf(", %s used on the nodes", humanBytes(total))
+	}
+	if released > 0 {
+		fmt.Printf(", %d released (reclaim with 'kraze down --volumes')", released)
+	}
+	fmt.Println()
+	return nil
+}
+
+// orDash returns value, or "-" when it's empty
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// sortVolumeListing puts the volumes using the most space first, then orders by claim
+func sortVolumeListing(listing []volumeListing) {
+	sort.SliceStable(listing, func(i, j int) bool {
+		var left, right int64 = -1, -1
+		if listing[i].UsedBytes != nil {
+			left = *listing[i].UsedBytes
+		}
+		if listing[j].UsedBytes != nil {
+			right = *listing[j].UsedBytes
+		}
+		return left > right
+	})
+}
+
+// measureVolumes returns the bytes used by the local volumes on kind nodes, by
+// volume name. Volumes that can't be measured are left out.
+func measureVolumes(ctx context.Context, volumes []providers.Volume) map[string]int64 {
+	usage := make(map[string]int64)
+	for _, volume := range volumes {
+		if volume.Name == "" || volume.Node == "" || volume.Path == "" {
+			continue
+		}
+		used, err := cluster.VolumeUsage(ctx, volume.Node, volume.Path)
+		if err != nil {
+			Verbose("Warning: %v", err)
+			continue
+		}
+		usage[volume.Name] = used
+	}
+	return usage
+}
+
+// reclaimVolumes deletes claims and then the volumes they were bound to, plus
+// volumes released earlier. On kind clusters the data of local-path volumes is
+// removed from the nodes and the reclaimed space is measured beforehand.
+func reclaimVolumes(ctx context.Context, clientset kubernetes.Interface, claims []corev1.PersistentVolumeClaim, released []corev1.PersistentVolume, kind bool) {
+	if len(claims) == 0 && len(released) == 0 {
+		fmt.Printf("No volumes to delete\n")
+		return
+	}
+
+	bound, err := providers.ClaimVolumes(ctx, clientset, claims)
+	if err != nil {
+		fmt.Printf("%s Warning: %v\n", color.Warning(), err)
+	}
+	candidates := make(map[string]providers.Volume)
+	var volumes []providers.Volume
+	for _, pv := range append(bound, released...) {
+		if _, ok := candidates[pv.Name]; ok {
+			continue
+		}
+		volume := providers.DescribeVolume(&pv)
+		candidates[pv.Name] = volume
+		volumes = append(volumes, volume)
+	}
+
+	// Measure first, provisioners delete the data as soon as the claims are gone
+	var usage map[string]int64
+	if kind {
+		usage = measureVolumes(ctx, volumes)
+	}
+
+	leftover, err := providers.DeleteClaims(ctx, clientset, claims, volumeReleaseTimeout)
+	if err != nil {
+		fmt.Printf("%s Warning: %v\n", color.Warning(), err)
+	}
+	leftover = append(leftover, released...)
+
+	// Volumes still around are released ones, or ones whose claim is still in use
+	removed := make(map[string]bool)
+	for _, pv := range bound {
+		removed[pv.Name] = true
+	}
+	handled := make(map[string]bool)
+	var keptData []string
+	for _, pv := range leftover {
+		if handled[pv.Name] {
+			continue
+		}
+		handled[pv.Name] = true
+		volume := providers.DescribeVolume(&pv)
+		if err := providers.DeleteVolume(ctx, clientset, &pv); err != nil {
+			fmt.Printf("%s Warning: %v\n", color.Warning(), err)
+			removed[pv.Name] = false
+			continue
+		}
+		removed[pv.Name] = true
+		if volume.Path == "" {
+			continue
+		}
+		if !kind || !volume.LocalPath || volume.Node == "" {
+			// Not kraze's to delete: a hostPath or local volume someone created by hand
+			keptData = append(keptData, fmt.Sprintf("%s (%s)", pv.Name, strings.TrimPrefix(volume.Node+":"+volume.Path, ":")))
+			removed[pv.Name] = false
+			continue
+		}
+		if err := cluster.RemoveVolumeData(ctx, volume.Node, volume.Path); err != nil {
+			fmt.Printf("%s Warning: %v\n", color.Warning(), err)
+			removed[pv.Name] = false
+		}
+	}
+	for _, pv := range bound {
+		if handled[pv.Name] {
+			continue
+		}
+		// Deleted by the provisioner, unless the claim is still mounted somewhere
+		if gone, err := volumeGone(ctx, clientset, pv.Name); err != nil || !gone {
+			removed[pv.Name] = false
+		}
+	}
+
+	var reclaimed int64
+	capacity := resource.Quantity{}
+	volumeCount := 0
+	for name, ok := range removed {
+		if !ok {
+			continue
+		}
+		volumeCount++
+		reclaimed += usage[name]
+		if quantity, err := resource.ParseQuantity(candidates[name].Capacity); err == nil {
+			capacity.Add(quantity)
+		}
+	}
+
+	fmt.Printf("%s Deleted %d volume claim(s) and %d volume(s)", color.Checkmark(), len(claims), volumeCount)
+	if kind {
+		fmt.Printf(", reclaimed %s\n", humanBytes(reclaimed))
+	} else {
+		fmt.Printf(" (%s provisioned)\n", capacity.String())
+	}
+	if len(keptData) > 0 {
+		fmt.Printf("%s Data of %d volume(s) was left on the nodes: %s\n", color.Warning(), len(keptData), strings.Join(keptData, ", "))
+	}
+}
+
+// volumeGone reports whether a PersistentVolume no longer exists
+func volumeGone(ctx context.Context, clientset kubernetes.Interface, name string) (bool, error) {
+	_, err := clientset.CoreV1().PersistentVolumes().Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return true, nil
+	}
+	return false, err
+}
+
+func init() {
+	addOutputFlag(volumeLsCmd, &volumeLsOutput)
+	volumeCmd.AddCommand(volumeLsCmd)
+}
@@ -0,0 +1,268 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/graph"
+	"github.com/hjames9/kraze/internal/providers"
+	"github.com/spf13/cobra"
+)
+
+var (
+	waitTimeout string
+	waitLabels  []string
+	waitOutput  string
+)
+
+// waitReport is the output of 'kraze wait -o json|yaml'
+type waitReport struct {
+	Ready    bool                `json:"ready"`
+	Timeout  string              `json:"timeout"`
+	Services []serviceWaitReport `json:"services"`
+}
+
+// serviceWaitReport is the outcome of waiting for one service
+type serviceWaitReport struct {
+	Name     string                  `json:"name"`
+	Ready    bool                    `json:"ready"`
+	Duration string                  `json:"duration"`
+	TimedOut bool                    `json:"timed_out,omitempty"`
+	Resource *waitResourceReport     `json:"resource,omitempty"` // The resource that did not become ready
+	Error    string                  `json:"error,omitempty"`
+	Events   []providers.EventNotice `json:"events,omitempty"` // Warning events seen while waiting
+	Optional []optionalWaitReport    `json:"optional_not_ready,omitempty"`
+}
+
+// optionalWaitReport names an optional resource that did not become ready
+type optionalWaitReport struct {
+	waitResourceReport
+	Error string `json:"error"`
+}
+
+// waitResourceReport names a resource that did not become ready
+type waitResourceReport struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+var waitCmd = &cobra.Command{
+	Use:   "wait [services...]",
+	Short: "Wait for services to become ready",
+	Long: `Wait for services that are already applied to become ready, using the same readiness
+checks and failure diagnostics as 'kraze up'. This is for resources applied by other means,
+such as CI steps, kubectl or helm, with kraze used only to wait for them.
+
+Helm services are waited on through their release, or their rendered chart when there is no
+release. Manifests services are waited on through their rendered manifests.
+
+Exits non-zero if any service isn't ready within the timeout, with a report of the resource
+each one was waiting on and the Warning events seen.
+
+Examples:
+  kraze wait                          # Wait for all enabled services
+  kraze wait api worker --timeout 2m  # Wait for specific services
+  kraze wait --label tier=backend     # Wait for services with label tier=backend
+  kraze wait -o json                  # Machine-readable report for scripts and CI`,
+	ValidArgsFunction: getServiceNames,
+	RunE:              runWait,
+}
+
+func runWait(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	format, err := parseOutputFormat(waitOutput)
+	if err != nil {
+		return err
+	}
+	timeout, err := time.ParseDuration(waitTimeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout '%s': %w", waitTimeout, err)
+	}
+
+	cfgPaths, cleanupPack, err := resolveAndExtractConfigFiles(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanupPack()
+
+	cfg, err := config.ParseMultiple(cfgPaths)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := requireCluster(cfg, "wait"); err != nil {
+		return err
+	}
+
+	if len(args) > 0 && len(waitLabels) > 0 {
+		return fmt.Errorf("cannot specify both service names and labels, use one or the other")
+	}
+	selected := cfg.Services
+	if len(waitLabels) > 0 {
+		if selected, err = cfg.FilterServicesByLabels(waitLabels); err != nil {
+			return fmt.Errorf("failed to filter services by labels: %w", err)
+		}
+	} else if len(args) > 0 {
+		if selected, err = cfg.FilterServices(args); err != nil {
+			return fmt.Errorf("failed to filter services: %w", err)
+		}
+	}
+
+	// Wait in dependency order, so a failing dependency is reported first
+	ordered, err := graph.NewDependencyGraph(cfg.Services).TopologicalSort()
+	if err != nil {
+		return fmt.Errorf("failed to resolve dependencies: %w", err)
+	}
+
+	kubeconfig, err := getShellKubeconfig(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	recordClusterActivity(cfg.Cluster.Name)
+
+	if dryRun {
+		for _, svc := range ordered {
+			if _, ok := selected[svc.Name]; ok && svc.IsEnabled() {
+				fmt.Printf("[DRY RUN] Would wait for '%s' (timeout: %v)\n", svc.Name, timeout)
+			}
+		}
+		return nil
+	}
+
+	// The wait engine prints progress and pod diagnostics to stdout, which must stay
+	// machine-readable for structured output
+	stdout := os.Stdout
+	if format.isStructured() {
+		os.Stdout = os.Stderr
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	report := waitReport{Ready: true, Timeout: timeout.String()}
+	for _, svc := range ordered {
+		if _, ok := selected[svc.Name]; !ok {
+			continue
+		}
+		if !svc.IsEnabled() {
+			Verbose("Service '%s' is disabled (skipping)", svc.Name)
+			continue
+		}
+
+		entry := waitForService(waitCtx, svc, kubeconfig, cfg.Readiness, timeout, format.isStructured())
+		if !entry.Ready {
+			report.Ready = false
+		}
+		report.Services = append(report.Services, entry)
+	}
+	os.Stdout = stdout
+
+	if format.isStructured() {
+		if err := printStructured(format, report); err != nil {
+			return err
+		}
+	} else {
+		printWaitSummary(report)
+	}
+
+	if !report.Ready {
+		var failed []string
+		for _, entry := range report.Services {
+			if !entry.Ready {
+				failed = append(failed, entry.Name)
+			}
+		}
+		return fmt.Errorf("services not ready: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// waitForService waits for one service and reports the outcome
+func waitForService(ctx context.Context, svc *config.ServiceConfig, kubeconfig string, rules map[string]config.ReadinessRule, timeout time.Duration, quiet bool) serviceWaitReport {
+	entry := serviceWaitReport{Name: svc.Name}
+	if !quiet {
+		fmt.Printf("\nWaiting for '%s'...\n", svc.Name)
+	}
+	pendingTimeout, err := svc.GetPendingTimeout()
+	if err != nil {
+		entry.Duration = "0s"
+		entry.Error = err.Error()
+		return entry
+	}
+
+	// Notices come from the event and pod watches concurrently
+	var eventsMutex sync.Mutex
+	opts := &providers.ProviderOptions{
+		KubeConfig:     kubeconfig,
+		Wait:           true,
+		Timeout:        timeout.String(),
+		Verbose:        verbose,
+		Quiet:          quiet,
+		ReadinessRules: rules,
+		Workloads:      svc.Workloads,
+		PendingTimeout: pendingTimeout,
+		OnOptionalNotReady: func(waitErr *providers.ResourceWaitError) {
+			entry.Optional = append(entry.Optional, optionalWaitReport{
+				waitResourceReport: waitResourceReport{Kind: waitErr.Kind, Namespace: waitErr.Namespace, Name: waitErr.Name},
+				Error:              waitErr.Error(),
+			})
+		},
+		OnWarningEvent: func(notice providers.EventNotice) {
+			eventsMutex.Lock()
+			entry.Events = append(entry.Events, notice)
+			eventsMutex.Unlock()
+			if !quiet {
+				fmt.Printf("    %s %s\n", color.Warning(), notice.String())
+			}
+		},
+	}
+
+	start := time.Now()
+	err = providers.WaitForService(ctx, svc, opts)
+	entry.Duration = time.Since(start).Round(time.Millisecond).String()
+	if err == nil {
+		entry.Ready = true
+		return entry
+	}
+
+	entry.Error = err.Error()
+	var waitErr *providers.ResourceWaitError
+	if errors.As(err, &waitErr) {
+		entry.TimedOut = waitErr.Timeout
+		entry.Resource = &waitResourceReport{Kind: waitErr.Kind, Namespace: waitErr.Namespace, Name: waitErr.Name}
+	}
+	return entry
+}
+
+// printWaitSummary prints the outcome of each service and why failed ones aren't ready
+func printWaitSummary(report waitReport) {
+	fmt.Println()
+	for _, entry := range report.Services {
+		if entry.Ready {
+			fmt.Printf("%s %s is ready (%s)\n", color.Checkmark(), entry.Name, entry.Duration)
+			for _, optional := range entry.Optional {
+				fmt.Printf("    %s optional %s\n", color.Warning(), optional.Error)
+			}
+			continue
+		}
+		fmt.Printf("%s %s is not ready: %s\n", color.Cross(), entry.Name, entry.Error)
+		for _, notice := range entry.Events {
+			fmt.Printf("    %s %s\n", color.Warning(), notice.String())
+		}
+	}
+}
+
+func init() {
+	waitCmd.Flags().StringVar(&waitTimeout, "timeout", "10m", "Timeout for all services to become ready")
+	waitCmd.Flags().StringSliceVarP(&waitLabels, "label", "l", []string{}, "Filter services by label (format: key=value, can be specified multiple times)")
+	registerLabelCompletion(waitCmd)
+	addOutputFlag(waitCmd, &waitOutput)
+}
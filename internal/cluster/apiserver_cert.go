@@ -0,0 +1,196 @@
+package cluster
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hjames9/kraze/internal/config"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
+)
+
+const (
+	apiServerCertPath = "/etc/kubernetes/pki/apiserver.crt"
+	clusterCACertPath = "/etc/kubernetes/pki/ca.crt"
+	clusterCAKeyPath  = "/etc/kubernetes/pki/ca.key"
+
+	// servingCertTimeout bounds the wait for the API server to pick up a re-issued certificate
+	servingCertTimeout = 90 * time.Second
+)
+
+// apiServerCertSANs returns the names and addresses the API server certificate
+// is issued for, on top of the node's own name and address that kubeadm adds.
+// kind's defaults are repeated because kubeadm patches replace lists: the
+// loopback address of the cluster's IP family (both for dual-stack) and the
+// address the API server is published on, from the kind networking config.
+func apiServerCertSANs(cfg *config.ClusterConfig, networking v1alpha4.Networking) []string {
+	sans := []string{"localhost"}
+	if networking.IPFamily != v1alpha4.IPv6Family {
+		sans = append(sans, "127.0.0.1")
+	}
+	if networking.IPFamily == v1alpha4.IPv6Family || networking.IPFamily == v1alpha4.DualStackFamily {
+		sans = append(sans, "::1")
+	}
+	if ip := net.ParseIP(networking.APIServerAddress); ip != nil && !ip.IsUnspecified() && !slices.Contains(sans, ip.String()) {
+		sans = append(sans, ip.String())
+	}
+	sans = append(sans, cfg.Name+"-control-plane")
+	if cfg.IPv4Address != "" {
+		sans = append(sans, cfg.IPv4Address)
+	}
+	return sans
+}
+
+// apiServerCertSANsPatch is the kubeadm patch adding the SANs to the API server certificate
+func apiServerCertSANsPatch(sans []string) string {
+	quoted := make([]string, 0, len(sans))
+	for _, san := range sans {
+		quoted = append(quoted, fmt.Sprintf("%q", san))
+	}
+	return fmt.Sprintf("kind: ClusterConfiguration\napiServer:\n  certSANs: [%s]\n", strings.Join(quoted, ", "))
+}
+
+// certificateCovers returns true if a certificate is valid for a host name or IP address
+func certificateCovers(cert *x509.Certificate, host string) bool {
+	return cert.VerifyHostname(host) == nil
+}
+
+// addCertificateSAN re-issues a certificate with an extra name or IP address,
+// signed by the CA and keeping its key, subject, usages and validity
+func addCertificateSAN(certPEM, caCertPEM, caKeyPEM []byte, host string) ([]byte, error) {
+	certs, err := certutil.ParseCertsPEM(certPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	caCerts, err := certutil.ParseCertsPEM(caCertPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CA certificate: %w", err)
+	}
+	caKey, err := keyutil.ParsePrivateKeyPEM(caKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CA key: %w", err)
+	}
+	signer, ok := caKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("CA key can't sign certificates")
+	}
+
+	cert := certs[0]
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 62))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               cert.Subject,
+		NotBefore:             cert.NotBefore,
+		NotAfter:              cert.NotAfter,
+		KeyUsage:              cert.KeyUsage,
+		ExtKeyUsage:           cert.ExtKeyUsage,
+		BasicConstraintsValid: true,
+		DNSNames:              slices.Clone(cert.DNSNames),
+		IPAddresses:           slices.Clone(cert.IPAddresses),
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		template.IPAddresses = append(template.IPAddresses, ip)
+	} else {
+		template.DNSNames = append(template.DNSNames, host)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, caCerts[0], cert.PublicKey, signer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign certificate: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: certutil.CertificateBlockType, Bytes: der}), nil
+}
+
+// ensureAPIServerSAN makes the API server certificate valid for the address a
+// kubeconfig connects to, so clients keep verifying TLS. Clusters created by
+// kraze usually already cover it; others, or a container IP from a network
+// attached after creation, get the certificate re-issued with the address added.
+func (kind *KindManager) ensureAPIServerSAN(ctx context.Context, clusterName, host string) error {
+	kind.sansMu.Lock()
+	defer kind.sansMu.Unlock()
+	if kind.coveredSANs[clusterName+"/"+host] {
+		return nil
+	}
+
+	containerName := clusterName + "-control-plane"
+	certPEM, err := nodeExec(ctx, containerName, "cat", apiServerCertPath)
+	if err != nil {
+		return fmt.Errorf("failed to read API server certificate: %w", err)
+	}
+	certs, err := certutil.ParseCertsPEM([]byte(certPEM))
+	if err != nil {
+		return fmt.Errorf("failed to parse API server certificate: %w", err)
+	}
+
+	if !certificateCovers(certs[0], host) {
+		caCertPEM, err := nodeExec(ctx, containerName, "cat", clusterCACertPath)
+		if err != nil {
+			return fmt.Errorf("failed to read cluster CA: %w", err)
+		}
+		caKeyPEM, err := nodeExec(ctx, containerName, "cat", clusterCAKeyPath)
+		if err != nil {
+			return fmt.Errorf("failed to read cluster CA key: %w", err)
+		}
+		reissued, err := addCertificateSAN([]byte(certPEM), []byte(caCertPEM), []byte(caKeyPEM), host)
+		if err != nil {
+			return err
+		}
+		if err := nodeWriteFile(ctx, containerName, apiServerCertPath, string(reissued)); err != nil {
+			return fmt.Errorf("failed to write API server certificate: %w", err)
+		}
+
+		// kube-apiserver reloads its serving certificate when the file changes
+		if err := waitForServingCert(ctx, host, []byte(caCertPEM)); err != nil {
+			return err
+		}
+	}
+
+	if kind.coveredSANs == nil {
+		kind.coveredSANs = make(map[string]bool)
+	}
+	kind.coveredSANs[clusterName+"/"+host] = true
+	return nil
+}
+
+// waitForServingCert waits until the API server at host presents a certificate
+// that verifies against the cluster CA for that host
+func waitForServingCert(ctx context.Context, host string, caCertPEM []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, servingCertTimeout)
+	defer cancel()
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCertPEM) {
+		return fmt.Errorf("failed to parse cluster CA")
+	}
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: 5 * time.Second},
+		Config:    &tls.Config{RootCAs: pool, ServerName: host},
+	}
+
+	err := pollUntil(ctx, time.Second, func(ctx context.Context) (bool, error) {
+		conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, "6443"))
+		if err != nil {
+			return false, err
+		}
+		conn.Close()
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("API server did not serve the re-issued certificate: %w", err)
+	}
+	return nil
+}
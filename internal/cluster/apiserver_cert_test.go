@@ -0,0 +1,160 @@
+package cluster
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hjames9/kraze/internal/config"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
+)
+
+// testPKI issues a CA and an API server certificate like kubeadm's
+func testPKI(test *testing.T) (certPEM, caCertPEM, caKeyPEM []byte) {
+	test.Helper()
+
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		test.Fatalf("failed to generate CA key: %v", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "kubernetes"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		test.Fatalf("failed to create CA: %v", err)
+	}
+	caCert, _ := x509.ParseCertificate(caDER)
+
+	serverKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		test.Fatalf("failed to generate server key: %v", err)
+	}
+	serverTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "kube-apiserver"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{"kind-control-plane", "localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("172.18.0.2")},
+	}
+	serverDER, err := x509.CreateCertificate(rand.Reader, serverTemplate, caCert, &serverKey.PublicKey, caKey)
+	if err != nil {
+		test.Fatalf("failed to create server certificate: %v", err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: certutil.CertificateBlockType, Bytes: serverDER})
+	caCertPEM = pem.EncodeToMemory(&pem.Block{Type: certutil.CertificateBlockType, Bytes: caDER})
+	caKeyPEM = pem.EncodeToMemory(&pem.Block{Type: keyutil.RSAPrivateKeyBlockType, Bytes: x509.MarshalPKCS1PrivateKey(caKey)})
+	return certPEM, caCertPEM, caKeyPEM
+}
+
+func TestAddCertificateSAN(test *testing.T) {
+	certPEM, caCertPEM, caKeyPEM := testPKI(test)
+	original, _ := certutil.ParseCertsPEM(certPEM)
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(caCertPEM)
+
+	tests := []struct {
+		name string
+		host string
+	}{
+		{name: "container IP on another network", host: "10.89.0.5"},
+		{name: "host name", host: "kind.example.test"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if certificateCovers(original[0], tt.host) {
+				test.Fatalf("original certificate already covers %s", tt.host)
+			}
+
+			reissued, err := addCertificateSAN(certPEM, caCertPEM, caKeyPEM, tt.host)
+			if err != nil {
+				test.Fatalf("addCertificateSAN() error = %v", err)
+			}
+			certs, err := certutil.ParseCertsPEM(reissued)
+			if err != nil {
+				test.Fatalf("failed to parse re-issued certificate: %v", err)
+			}
+			cert := certs[0]
+
+			for _, host := range []string{tt.host, "kind-control-plane", "localhost", "127.0.0.1", "172.18.0.2"} {
+				if _, err := cert.Verify(x509.VerifyOptions{DNSName: host, Roots: roots}); err != nil {
+					test.Errorf("re-issued certificate does not verify for %s: %v", host, err)
+				}
+			}
+			if !reflect.DeepEqual(cert.PublicKey, original[0].PublicKey) {
+				test.Error("re-issued certificate has a different key")
+			}
+			if cert.Subject.CommonName != original[0].Subject.CommonName || !cert.NotAfter.Equal(original[0].NotAfter) {
+				test.Errorf("re-issued certificate changed subject or validity: %s until %s", cert.Subject, cert.NotAfter)
+			}
+		})
+	}
+}
+
+func TestAPIServerCertSANs(test *testing.T) {
+	tests := []struct {
+		name       string
+		config     *config.ClusterConfig
+		networking v1alpha4.Networking
+		expected   string
+	}{
+		{
+			name:     "default network",
+			config:   &config.ClusterConfig{Name: "dev"},
+			expected: "kind: ClusterConfiguration\napiServer:\n  certSANs: [\"localhost\", \"127.0.0.1\", \"dev-control-plane\"]\n",
+		},
+		{
+			name:     "static address on a custom network",
+			config:   &config.ClusterConfig{Name: "dev", Network: "corp", IPv4Address: "172.30.0.10"},
+			expected: "kind: ClusterConfiguration\napiServer:\n  certSANs: [\"localhost\", \"127.0.0.1\", \"dev-control-plane\", \"172.30.0.10\"]\n",
+		},
+		{
+			name:       "IPv6 cluster",
+			config:     &config.ClusterConfig{Name: "dev"},
+			networking: v1alpha4.Networking{IPFamily: v1alpha4.IPv6Family},
+			expected:   "kind: ClusterConfiguration\napiServer:\n  certSANs: [\"localhost\", \"::1\", \"dev-control-plane\"]\n",
+		},
+		{
+			name:       "dual-stack cluster published on a host address",
+			config:     &config.ClusterConfig{Name: "dev"},
+			networking: v1alpha4.Networking{IPFamily: v1alpha4.DualStackFamily, APIServerAddress: "192.168.1.20"},
+			expected:   "kind: ClusterConfiguration\napiServer:\n  certSANs: [\"localhost\", \"127.0.0.1\", \"::1\", \"192.168.1.20\", \"dev-control-plane\"]\n",
+		},
+		{
+			name:       "API server on every address",
+			config:     &config.ClusterConfig{Name: "dev"},
+			networking: v1alpha4.Networking{APIServerAddress: "0.0.0.0"},
+			expected:   "kind: ClusterConfiguration\napiServer:\n  certSANs: [\"localhost\", \"127.0.0.1\", \"dev-control-plane\"]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := apiServerCertSANsPatch(apiServerCertSANs(tt.config, tt.networking)); got != tt.expected {
+				test.Errorf("apiServerCertSANsPatch() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
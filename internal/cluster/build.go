@@ -0,0 +1,314 @@
+package cluster
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	osexec "os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/telemetry"
+)
+
+// BuildHashLabel is the image label that records the build context hash an image was built from
+const BuildHashLabel = "kraze.build-hash"
+
+// maxBuildOutputLines is how much of a failed build's output is included in the error
+const maxBuildOutputLines = 20
+
+// HashBuildContext returns a content hash of a service's build: every file of the
+// context not excluded by .dockerignore, the Dockerfile, build args and target
+func (im *ImageManager) HashBuildContext(build *config.BuildConfig) (string, error) {
+	patterns, err := readDockerignore(build.Context)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	err = filepath.WalkDir(build.Context, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(build.Context, filePath)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		if dockerignored(patterns, rel) {
+			if entry.IsDir() && !hasNegatedPatterns(patterns) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case entry.IsDir():
+			return nil
+		case info.Mode()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(filePath)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(hash, "link %s %s\n", rel, target)
+		case info.Mode().IsRegular():
+			fmt.Fprintf(hash, "file %s %o %d\n", rel, info.Mode().Perm(), info.Size())
+			if err := hashFile(hash, filePath); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash build context %s: %w", build.Context, err)
+	}
+
+	// The Dockerfile may live outside the context or be excluded by .dockerignore
+	switch build.GetType() {
+	case config.BuildDocker:
+		fmt.Fprintf(hash, "dockerfile %s\n", build.Dockerfile)
+		if err := hashFile(hash, build.GetDockerfile()); err != nil {
+			return "", fmt.Errorf("failed to read Dockerfile: %w", err)
+		}
+	case config.BuildKo:
+		fmt.Fprintf(hash, "ko %s\n", build.GetImportPath())
+	case config.BuildBuildpacks:
+		fmt.Fprintf(hash, "buildpacks %s\n", build.GetBuilder())
+	}
+
+	fmt.Fprintf(hash, "target %s\n", build.Target)
+	argNames := make([]string, 0, len(build.Args))
+	for name := range build.Args {
+		argNames = append(argNames, name)
+	}
+	sort.Strings(argNames)
+	for _, name := range argNames {
+		fmt.Fprintf(hash, "arg %s=%s\n", name, build.Args[name])
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// hashFile writes a file's contents to the hash
+func hashFile(hash io.Writer, filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(hash, file)
+	return err
+}
+
+// BuildImage builds a service's image unless the local image was already built
+// from the same context hash (or force is set). Returns whether a build ran.
+func (im *ImageManager) BuildImage(ctx context.Context, build *config.BuildConfig, force bool) (built bool, err error) {
+	ctx, span := telemetry.StartSpan(ctx, "image.build", telemetry.Image(build.Image))
+	defer func() { telemetry.EndSpan(span, err) }()
+
+	contextHash, err := im.HashBuildContext(build)
+	if err != nil {
+		return false, err
+	}
+
+	if !force {
+		if existing := im.imageBuildHash(ctx, build.Image); existing == contextHash {
+			if im.verbose {
+				fmt.Printf("Image '%s' is up to date (build context unchanged)\n", build.Image)
+			}
+			return false, nil
+		}
+	}
+
+	switch build.GetType() {
+	case config.BuildKo:
+		err = im.buildKoImage(ctx, build, contextHash)
+	case config.BuildBuildpacks:
+		err = im.buildBuildpacksImage(ctx, build, contextHash)
+	default:
+		args := buildImageArgs(build, contextHash)
+		if im.verbose {
+			fmt.Printf("Building image '%s': %s %s\n", build.Image, DetectContainerRuntime(), strings.Join(args, " "))
+		}
+		cmd := runtimeCommandContext(ctx, args...)
+		cmd.Env = append(os.Environ(), "DOCKER_BUILDKIT=1")
+		_, err = im.runBuild(cmd)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to build image '%s': %w", build.Image, err)
+	}
+	return true, nil
+}
+
+// runBuild runs a build command, streaming its output when verbose. Returns
+// what the command wrote to stdout, and on failure the error with the last
+// lines of its output.
+func (im *ImageManager) runBuild(cmd *osexec.Cmd) (string, error) {
+	var stdout, output bytes.Buffer
+	if im.verbose {
+		cmd.Stdout = io.MultiWriter(&stdout, os.Stdout)
+		cmd.Stderr = os.Stderr
+	} else {
+		cmd.Stdout = io.MultiWriter(&stdout, &output)
+		cmd.Stderr = &output
+	}
+
+	if err := cmd.Run(); err != nil {
+		if tail := lastLines(output.String(), maxBuildOutputLines); tail != "" {
+			return "", fmt.Errorf("%w\n%s", err, tail)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// buildImageArgs returns the runtime CLI arguments that build an image, labelled with its context hash
+func buildImageArgs(build *config.BuildConfig, contextHash string) []string {
+	args := []string{"build", "-t", build.Image, "-f", build.GetDockerfile(), "--label", BuildHashLabel + "=" + contextHash}
+	if build.Target != "" {
+		args = append(args, "--target", build.Target)
+	}
+
+	argNames := make([]string, 0, len(build.Args))
+	for name := range build.Args {
+		argNames = append(argNames, name)
+	}
+	sort.Strings(argNames)
+	for _, name := range argNames {
+		args = append(args, "--build-arg", name+"="+build.Args[name])
+	}
+
+	return append(args, build.Context)
+}
+
+// imageBuildHash returns the build context hash label of a local image, empty
+// when the image does not exist or was not built by kraze
+func (im *ImageManager) imageBuildHash(ctx context.Context, image string) string {
+	output, err := runtimeCommandContext(ctx, "image", "inspect", "--format", "{{json .Config.Labels}}", image).Output()
+	if err != nil {
+		return ""
+	}
+	var labels map[string]string
+	if err := json.Unmarshal(bytes.TrimSpace(output), &labels); err != nil {
+		return ""
+	}
+	return labels[BuildHashLabel]
+}
+
+// lastLines returns the last n non-empty lines of output
+func lastLines(output string, n int) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// readDockerignore reads the exclusion patterns of a build context's .dockerignore
+func readDockerignore(contextDir string) ([]string, error) {
+	file, err := os.Open(filepath.Join(contextDir, ".dockerignore"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .dockerignore: %w", err)
+	}
+	defer file.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		negated := strings.HasPrefix(line, "!")
+		line = strings.TrimPrefix(line, "!")
+		line = path.Clean(strings.TrimPrefix(filepath.ToSlash(strings.TrimSpace(line)), "/"))
+		if negated {
+			line = "!" + line
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read .dockerignore: %w", err)
+	}
+	return patterns, nil
+}
+
+// hasNegatedPatterns reports whether any pattern re-includes files, in which
+// case excluded directories still have to be walked
+func hasNegatedPatterns(patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "!") {
+			return true
+		}
+	}
+	return false
+}
+
+// dockerignored reports whether a slash-separated context path is excluded.
+// As with Docker, the last matching pattern wins and a pattern that matches a
+// directory excludes everything under it.
+func dockerignored(patterns []string, rel string) bool {
+	ignored := false
+	for _, pattern := range patterns {
+		negated := strings.HasPrefix(pattern, "!")
+		if matchIgnorePattern(strings.TrimPrefix(pattern, "!"), rel) {
+			ignored = !negated
+		}
+	}
+	return ignored
+}
+
+// matchIgnorePattern matches a .dockerignore pattern against a path or any of its parent directories
+func matchIgnorePattern(pattern, rel string) bool {
+	patternParts := strings.Split(pattern, "/")
+	pathParts := strings.Split(rel, "/")
+	for end := len(pathParts); end > 0; end-- {
+		if matchIgnoreParts(patternParts, pathParts[:end]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchIgnoreParts matches path segments, with ** matching any number of segments
+func matchIgnoreParts(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for skip := 0; skip <= len(parts); skip++ {
+			if matchIgnoreParts(pattern[1:], parts[skip:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pattern[0], parts[0]); err != nil || !ok {
+		return false
+	}
+	return matchIgnoreParts(pattern[1:], parts[1:])
+}
@@ -0,0 +1,167 @@
+package cluster
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+)
+
+func TestDockerignored(test *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		rel      string
+		want     bool
+	}{
+		{name: "no patterns", patterns: nil, rel: "main.go", want: false},
+		{name: "exact file", patterns: []string{"secret.txt"}, rel: "secret.txt", want: true},
+		{name: "wildcard", patterns: []string{"*.log"}, rel: "debug.log", want: true},
+		{name: "wildcard only at root", patterns: []string{"*.log"}, rel: "logs/debug.log", want: false},
+		{name: "double star", patterns: []string{"**/*.log"}, rel: "logs/deep/debug.log", want: true},
+		{name: "directory excludes contents", patterns: []string{"node_modules"}, rel: "node_modules/pkg/index.js", want: true},
+		{name: "negation re-includes", patterns: []string{"*.md", "!README.md"}, rel: "README.md", want: false},
+		{name: "last match wins", patterns: []string{"!README.md", "*.md"}, rel: "README.md", want: true},
+		{name: "unrelated file", patterns: []string{"dist", "*.tmp"}, rel: "src/app.go", want: false},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := dockerignored(tt.patterns, tt.rel); got != tt.want {
+				test.Errorf("dockerignored(%v, %q) = %v, want %v", tt.patterns, tt.rel, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadDockerignore(test *testing.T) {
+	dir := test.TempDir()
+	content := "# comment\n\n/dist\n!keep/\n./tmp/*.log\n"
+	if err := os.WriteFile(filepath.Join(dir, ".dockerignore"), []byte(content), 0644); err != nil {
+		test.Fatal(err)
+	}
+
+	patterns, err := readDockerignore(dir)
+	if err != nil {
+		test.Fatalf("readDockerignore() error = %v", err)
+	}
+	want := []string{"dist", "!keep", "tmp/*.log"}
+	if !reflect.DeepEqual(patterns, want) {
+		test.Errorf("readDockerignore() = %v, want %v", patterns, want)
+	}
+
+	patterns, err = readDockerignore(test.TempDir())
+	if err != nil || patterns != nil {
+		test.Errorf("readDockerignore() without file = %v, %v, want nil, nil", patterns, err)
+	}
+}
+
+func TestHashBuildContext(test *testing.T) {
+	dir := test.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			test.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			test.Fatal(err)
+		}
+	}
+	write("Dockerfile", "FROM scratch\nCOPY . /\n")
+	write(".dockerignore", "*.log\n")
+	write("src/main.go", "package main\n")
+
+	imgMgr := NewImageManager(false)
+	build := &config.BuildConfig{Image: "app:dev", Context: dir}
+	hash := func() string {
+		test.Helper()
+		value, err := imgMgr.HashBuildContext(build)
+		if err != nil {
+			test.Fatalf("HashBuildContext() error = %v", err)
+		}
+		return value
+	}
+
+	base := hash()
+	if hash() != base {
+		test.Fatal("HashBuildContext() is not stable")
+	}
+
+	write("debug.log", "ignored\n")
+	if hash() != base {
+		test.Error("HashBuildContext() changed for a .dockerignore'd file")
+	}
+
+	write("src/main.go", "package main\n\nfunc main() {}\n")
+	changed := hash()
+	if changed == base {
+		test.Error("HashBuildContext() did not change when a source file changed")
+	}
+
+	build.Args = map[string]string{"VERSION": "1"}
+	withArgs := hash()
+	if withArgs == changed {
+		test.Error("HashBuildContext() did not change when build args changed")
+	}
+
+	build.Target = "runtime"
+	if hash() == withArgs {
+		test.Error("HashBuildContext() did not change when the target changed")
+	}
+
+	build.Dockerfile = "missing.Dockerfile"
+	if _, err := imgMgr.HashBuildContext(build); err == nil {
+		test.Error("HashBuildContext() with missing Dockerfile should fail")
+	}
+}
+
+func TestBuildImageArgs(test *testing.T) {
+	build := &config.BuildConfig{
+		Image:      "api:dev",
+		Context:    "/src/api",
+		Dockerfile: "docker/Dockerfile.dev",
+		Args:       map[string]string{"VERSION": "1.2", "GO_VERSION": "1.26"},
+		Target:     "runtime",
+	}
+
+	got := buildImageArgs(build, "abc")
+	want := []string{
+		"build", "-t", "api:dev", "-f", filepath.Join("/src/api", "docker/Dockerfile.dev"),
+		"--label", "kraze.build-hash=abc",
+		"--target", "runtime",
+		"--build-arg", "GO_VERSION=1.26",
+		"--build-arg", "VERSION=1.2",
+		"/src/api",
+	}
+	if !reflect.DeepEqual(got, want) {
+		test.Errorf("buildImageArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestKoBuild(test *testing.T) {
+	build := &config.BuildConfig{Type: "ko", Image: "api:dev", Context: "/src/api", ImportPath: "./cmd/api"}
+	want := []string{"build", "./cmd/api", "--local", "--bare", "--tags", "0123456789abcdef", "--platform", "linux/" + runtime.GOARCH}
+	if got := koBuildArgs(build, "0123456789abcdef0123"); !reflect.DeepEqual(got, want) {
+		test.Errorf("koBuildArgs() = %v, want %v", got, want)
+	}
+
+	output := "2026/10/18 12:00:00 Loading ko.local:0123456789abcdef\nko.local:0123456789abcdef@sha256:feed\n"
+	if got := koImageReference(output); got != "ko.local:0123456789abcdef" {
+		test.Errorf("koImageReference() = %q, want ko.local:0123456789abcdef", got)
+	}
+}
+
+func TestPackBuildArgs(test *testing.T) {
+	build := &config.BuildConfig{Type: "buildpacks", Image: "web:dev", Context: "/src/web", Args: map[string]string{"BP_NODE_VERSION": "22", "BP_GO_TARGETS": "./cmd/web"}}
+	want := []string{
+		"build", "web:dev", "--path", "/src/web", "--builder", config.DefaultBuildpacksBuilder, "--pull-policy", "if-not-present",
+		"--env", "BP_GO_TARGETS=./cmd/web",
+		"--env", "BP_NODE_VERSION=22",
+	}
+	if got := packBuildArgs(build); !reflect.DeepEqual(got, want) {
+		test.Errorf("packBuildArgs() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,158 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/config"
+)
+
+// koLocalRepo makes ko build into the local daemon instead of pushing
+const koLocalRepo = "ko.local"
+
+// buildKoImage builds a Go main package with ko into the local daemon, then
+// tags the result as the build's image
+func (im *ImageManager) buildKoImage(ctx context.Context, build *config.BuildConfig, contextHash string) error {
+	if _, err := osexec.LookPath("ko"); err != nil {
+		return fmt.Errorf("ko builds need ko, which is not installed (see https://ko.build)")
+	}
+
+	args := koBuildArgs(build, contextHash)
+	if im.verbose {
+		fmt.Printf("Building image '%s': ko %s\n", build.Image, strings.Join(args, " "))
+	}
+	cmd := osexec.CommandContext(ctx, "ko", args...)
+	cmd.Dir = build.Context
+	cmd.Env = append(os.Environ(), buildEnv(build)...)
+	cmd.Env = append(cmd.Env, "KO_DOCKER_REPO="+koLocalRepo)
+	output, err := im.runBuild(cmd)
+	if err != nil {
+		return err
+	}
+
+	reference := koImageReference(output)
+	if reference == "" {
+		return fmt.Errorf("ko did not print the image it built")
+	}
+	return im.labelImage(ctx, reference, build.Image, contextHash)
+}
+
+// koBuildArgs returns the ko CLI arguments that build a package for the
+// platform of the kind nodes. The context hash tags the image, so builds of
+// different services don't share a tag.
+func koBuildArgs(build *config.BuildConfig, contextHash string) []string {
+	return []string{"build", build.GetImportPath(), "--local", "--bare",
+		"--tags", shortHash(contextHash), "--platform", "linux/" + runtime.GOARCH}
+}
+
+// koImageReference returns the image ko printed as the last line of its
+// output, without the digest the local daemon doesn't know the image by
+func koImageReference(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	reference := strings.TrimSpace(lines[len(lines)-1])
+	reference, _, _ = strings.Cut(reference, "@")
+	return reference
+}
+
+// buildBuildpacksImage builds the context with Cloud Native Buildpacks through
+// the pack CLI
+func (im *ImageManager) buildBuildpacksImage(ctx context.Context, build *config.BuildConfig, contextHash string) error {
+	if _, err := osexec.LookPath("pack"); err != nil {
+		return fmt.Errorf("buildpacks builds need pack, which is not installed (see https://buildpacks.io/docs/for-platform-operators/how-to/integrate-ci/pack/)")
+	}
+
+	args := packBuildArgs(build)
+	if im.verbose {
+		fmt.Printf("Building image '%s': pack %s\n", build.Image, strings.Join(args, " "))
+	}
+	if _, err := im.runBuild(osexec.CommandContext(ctx, "pack", args...)); err != nil {
+		return err
+	}
+	return im.labelImage(ctx, build.Image, build.Image, contextHash)
+}
+
+// packBuildArgs returns the pack CLI arguments that build an image, with the
+// build's args as buildpack environment variables. The builder is only pulled
+// when missing, so rebuilds work offline.
+func packBuildArgs(build *config.BuildConfig) []string {
+	args := []string{"build", build.Image, "--path", build.Context, "--builder", build.GetBuilder(), "--pull-policy", "if-not-present"}
+	for _, env := range buildEnv(build) {
+		args = append(args, "--env", env)
+	}
+	return args
+}
+
+// buildEnv returns a build's args as sorted NAME=VALUE pairs
+func buildEnv(build *config.BuildConfig) []string {
+	env := make([]string, 0, len(build.Args))
+	for name, value := range build.Args {
+		env = append(env, name+"="+value)
+	}
+	sort.Strings(env)
+	return env
+}
+
+// labelImage tags source as image with the build hash label, which ko and
+// pack can't set themselves, so unchanged contexts skip the next build
+func (im *ImageManager) labelImage(ctx context.Context, source, image, contextHash string) error {
+	dir, err := os.MkdirTemp("", "kraze-label-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM "+source+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write Dockerfile: %w", err)
+	}
+
+	cmd := runtimeCommandContext(ctx, "build", "-t", image, "--label", BuildHashLabel+"="+contextHash, dir)
+	if _, err := im.runBuild(cmd); err != nil {
+		return fmt.Errorf("failed to label image: %w", err)
+	}
+	return nil
+}
+
+// shortHash returns the first 16 characters of a hash
+func shortHash(hash string) string {
+	if len(hash) > 16 {
+		return hash[:16]
+	}
+	return hash
+}
+
+// agentBaseImage is the base of the kraze agent image: glibc and CA
+// certificates, so a kraze binary built with or without cgo runs on it
+const agentBaseImage = "gcr.io/distroless/base-debian12:nonroot"
+
+// BuildAgentImage builds the kraze agent image from a linux kraze binary, with
+// the binary as its entrypoint
+func (im *ImageManager) BuildAgentImage(ctx context.Context, binary, image string) error {
+	dir, err := os.MkdirTemp("", "kraze-agent-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	data, err := os.ReadFile(binary)
+	if err != nil {
+		return fmt.Errorf("failed to read kraze binary: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "kraze"), data, 0755); err != nil {
+		return fmt.Errorf("failed to copy kraze binary: %w", err)
+	}
+	dockerfile := "FROM " + agentBaseImage + "\nCOPY kraze /kraze\nENTRYPOINT [\"/kraze\"]\n"
+	if err := os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte(dockerfile), 0644); err != nil {
+		return fmt.Errorf("failed to write Dockerfile: %w", err)
+	}
+
+	cmd := runtimeCommandContext(ctx, "build", "-t", image, dir)
+	if _, err := im.runBuild(cmd); err != nil {
+		return fmt.Errorf("failed to build agent image: %w", err)
+	}
+	return nil
+}
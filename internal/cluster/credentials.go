@@ -0,0 +1,315 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+)
+
+// kubeletDockerConfigPath is where the kubelet looks for registry credentials in kind nodes
+const kubeletDockerConfigPath = "/var/lib/kubelet/config.json"
+
+// identityTokenUsername is the username credential helpers return for identity
+// (OAuth refresh) tokens, which the kubelet can't use
+const identityTokenUsername = "<token>"
+
+// dockerConfigFile is the subset of ~/.docker/config.json that holds credentials
+type dockerConfigFile struct {
+	Auths       map[string]dockerAuthEntry `json:"auths"`
+	CredsStore  string                     `json:"credsStore,omitempty"`
+	CredHelpers map[string]string          `json:"credHelpers,omitempty"`
+}
+
+// dockerAuthEntry is one registry's credentials in a Docker config file
+type dockerAuthEntry struct {
+	Auth          string `json:"auth,omitempty"`
+	Username      string `json:"username,omitempty"`
+	Password      string `json:"password,omitempty"`
+	IdentityToken string `json:"identitytoken,omitempty"`
+}
+
+// usable returns true if the entry has credentials the kubelet can use
+func (entry dockerAuthEntry) usable() bool {
+	return entry.Auth != "" || entry.Username != "" && entry.Password != ""
+}
+
+// credentialHelper runs a docker-credential-<helper> action with input on stdin
+type credentialHelper func(ctx context.Context, helper, action, input string) ([]byte, error)
+
+// runCredentialHelper runs a Docker credential helper binary from PATH
+func runCredentialHelper(ctx context.Context, helper, action, input string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "docker-credential-"+helper, action)
+	cmd.Stdin = strings.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String() + string(out)); msg != "" {
+			return nil, fmt.Errorf("docker-credential-%s %s: %s", helper, action, msg)
+		}
+		return nil, fmt.Errorf("docker-credential-%s %s: %w", helper, action, err)
+	}
+	return out, nil
+}
+
+// defaultDockerConfigPath returns $DOCKER_CONFIG/config.json or ~/.docker/config.json
+func defaultDockerConfigPath() (string, error) {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return filepath.Join(dir, "config.json"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".docker", "config.json"), nil
+}
+
+// registryHost normalizes a Docker config server key or registry name to a
+// host, treating the Docker Hub aliases as docker.io
+func registryHost(server string) string {
+	return config.NormalizeRegistryHost(server)
+}
+
+// resolveRegistryCredentials reads a Docker config file and returns the
+// credentials of the selected registries (all when none are given) as a
+// kubelet-readable config, resolving credential helpers and stores. Registries
+// whose credentials can't be used are returned as warnings.
+func resolveRegistryCredentials(ctx context.Context, path string, registries []string, helper credentialHelper) ([]byte, []string, []string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to read Docker config: %w", err)
+	}
+	var dockerConfig dockerConfigFile
+	if err := json.Unmarshal(data, &dockerConfig); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to parse Docker config %s: %w", path, err)
+	}
+
+	servers := make(map[string]bool)
+	for server := range dockerConfig.Auths {
+		servers[server] = true
+	}
+	for server := range dockerConfig.CredHelpers {
+		servers[server] = true
+	}
+	if dockerConfig.CredsStore != "" {
+		out, err := helper(ctx, dockerConfig.CredsStore, "list", "")
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to list credentials: %w", err)
+		}
+		var stored map[string]string
+		if err := json.Unmarshal(out, &stored); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to parse docker-credential-%s list output: %w", dockerConfig.CredsStore, err)
+		}
+		for server := range stored {
+			servers[server] = true
+		}
+	}
+
+	wanted := make(map[string]bool)
+	for _, registry := range registries {
+		wanted[registryHost(registry)] = true
+	}
+
+	auths := make(map[string]dockerAuthEntry)
+	found := make(map[string]bool)
+	var copied, warnings []string
+	for _, server := range sortedKeys(servers) {
+		host := registryHost(server)
+		if len(wanted) > 0 && !wanted[host] {
+			continue
+		}
+
+		entry := dockerConfig.Auths[server]
+		if !entry.usable() {
+			name := dockerConfig.CredHelpers[server]
+			if name == "" {
+				name = dockerConfig.CredHelpers[host]
+			}
+			if name == "" {
+				name = dockerConfig.CredsStore
+			}
+			if name == "" {
+				if entry.IdentityToken != "" {
+					warnings = append(warnings, fmt.Sprintf("%s: identity tokens can't be used by the kubelet", server))
+				}
+				continue
+			}
+
+			entry, err = helperCredentials(ctx, helper, name, server)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("%s: %v", server, err))
+				continue
+			}
+		}
+
+		auth := entry.Auth
+		if auth == "" {
+			auth = base64.StdEncoding.EncodeToString([]byte(entry.Username + ":" + entry.Password))
+		}
+		auths[server] = dockerAuthEntry{Auth: auth}
+		found[host] = true
+		copied = append(copied, server)
+	}
+
+	for _, host := range sortedKeys(wanted) {
+		if !found[host] {
+			warnings = append(warnings, fmt.Sprintf("%s: no credentials in %s (run 'docker login %s')", host, path, host))
+		}
+	}
+
+	if len(auths) == 0 {
+		return nil, nil, warnings, fmt.Errorf("no usable registry credentials in %s", path)
+	}
+	content, err := json.MarshalIndent(dockerConfigFile{Auths: auths}, "", "  ")
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to encode registry credentials: %w", err)
+	}
+	return content, copied, warnings, nil
+}
+
+// helperCredentials gets a server's username and secret from a credential helper
+func helperCredentials(ctx context.Context, helper credentialHelper, name, server string) (dockerAuthEntry, error) {
+	out, err := helper(ctx, name, "get", server)
+	if err != nil {
+		return dockerAuthEntry{}, err
+	}
+	var creds struct {
+		Username string `json:"Username"`
+		Secret   string `json:"Secret"`
+	}
+	if err := json.Unmarshal(out, &creds); err != nil {
+		return dockerAuthEntry{}, fmt.Errorf("failed to parse docker-credential-%s output: %w", name, err)
+	}
+	if creds.Username == identityTokenUsername {
+		return dockerAuthEntry{}, fmt.Errorf("identity tokens can't be used by the kubelet")
+	}
+	if creds.Username == "" || creds.Secret == "" {
+		return dockerAuthEntry{}, fmt.Errorf("docker-credential-%s returned no credentials", name)
+	}
+	return dockerAuthEntry{Username: creds.Username, Password: creds.Secret}, nil
+}
+
+// sortedKeys returns the keys of a set in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// registryAuths returns the credentials of the registries listed in the
+// config as kubelet config entries, keyed by host
+func registryAuths(ctx context.Context, registries []config.RegistryConfig) (map[string]dockerAuthEntry, error) {
+	auths := make(map[string]dockerAuthEntry, len(registries))
+	for _, reg := range registries {
+		password, err := reg.ResolvePassword(ctx)
+		if err != nil {
+			return nil, err
+		}
+		server := registryHost(reg.Host)
+		if server == "docker.io" {
+			// The key Docker and the kubelet use for Docker Hub
+			server = "https://index.docker.io/v1/"
+		}
+		auths[server] = dockerAuthEntry{Auth: base64.StdEncoding.EncodeToString([]byte(reg.Username + ":" + password))}
+	}
+	return auths, nil
+}
+
+// nodeRegistryCredentials returns the kubelet config with the host's Docker
+// credentials when registry_credentials is enabled, and the credentials of
+// the registries listed in the config, which win for the same registry
+func (kind *KindManager) nodeRegistryCredentials(ctx context.Context, creds *config.RegistryCredentialsConfig) ([]byte, []string, error) {
+	auths := make(map[string]dockerAuthEntry)
+	var copied []string
+	if creds.IsEnabled() {
+		path := creds.Config
+		if path == "" {
+			var err error
+			if path, err = defaultDockerConfigPath(); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		content, fromDocker, warnings, err := resolveRegistryCredentials(ctx, path, creds.Registries, runCredentialHelper)
+		for _, warning := range warnings {
+			fmt.Printf("%s Registry credentials: %s\n", color.Warning(), warning)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		var dockerConfig dockerConfigFile
+		if err := json.Unmarshal(content, &dockerConfig); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode registry credentials: %w", err)
+		}
+		auths, copied = dockerConfig.Auths, fromDocker
+	}
+
+	listed, err := registryAuths(ctx, kind.registries)
+	if err != nil {
+		return nil, nil, err
+	}
+	for server, entry := range listed {
+		// Drop the Docker config's entry for the registry, whatever its key
+		for existing := range auths {
+			if registryHost(existing) == registryHost(server) {
+				delete(auths, existing)
+				copied = slices.DeleteFunc(copied, func(name string) bool { return name == existing })
+			}
+		}
+		auths[server] = entry
+		copied = append(copied, server)
+	}
+	sort.Strings(copied)
+
+	content, err := json.MarshalIndent(dockerConfigFile{Auths: auths}, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode registry credentials: %w", err)
+	}
+	return content, copied, nil
+}
+
+// copyRegistryCredentials writes the host's Docker registry credentials and
+// those of the registries listed in the config to the kubelet's config.json in
+// every node and restarts the kubelet, so image pulls by pods authenticate
+// without imagePullSecrets. Returns the copied registries.
+func (kind *KindManager) copyRegistryCredentials(ctx context.Context, clusterName string, creds *config.RegistryCredentialsConfig) ([]string, error) {
+	content, copied, err := kind.nodeRegistryCredentials(ctx, creds)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("Copying registry credentials into cluster nodes...\n")
+	nodes, err := kind.provider.ListInternalNodes(clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster nodes: %w", err)
+	}
+
+	script := fmt.Sprintf("umask 077 && cat > '%s' && systemctl restart kubelet", kubeletDockerConfigPath)
+	for _, node := range nodes {
+		if _, err := nodeExecWithPolicy(ctx, defaultNodeExecPolicy, node.String(), content, "sh", "-c", script); err != nil {
+			return nil, fmt.Errorf("failed to write registry credentials to node %s: %w", node.String(), err)
+		}
+	}
+
+	// Pods can't be scheduled until the restarted kubelets are back
+	if err := kind.waitForSystemdUnits(ctx, clusterName, "kubelet"); err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("%s Registry credentials copied for %s\n", color.Checkmark(), strings.Join(copied, ", "))
+	return copied, nil
+}
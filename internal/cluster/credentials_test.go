@@ -0,0 +1,199 @@
+package cluster
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+)
+
+// fakeCredentialHelper serves list and get from a map of server to username:secret
+func fakeCredentialHelper(store map[string][2]string) credentialHelper {
+	return func(ctx context.Context, helper, action, input string) ([]byte, error) {
+		switch action {
+		case "list":
+			servers := make(map[string]string)
+			for server, creds := range store {
+				servers[server] = creds[0]
+			}
+			return json.Marshal(servers)
+		case "get":
+			creds, ok := store[input]
+			if !ok {
+				return nil, fmt.Errorf("credentials not found in native keychain")
+			}
+			return json.Marshal(map[string]string{"ServerURL": input, "Username": creds[0], "Secret": creds[1]})
+		}
+		return nil, fmt.Errorf("unexpected action %s", action)
+	}
+}
+
+func TestRegistryHost(test *testing.T) {
+	tests := []struct {
+		server   string
+		expected string
+	}{
+		{server: "https://index.docker.io/v1/", expected: "docker.io"},
+		{server: "docker.io", expected: "docker.io"},
+		{server: "ghcr.io", expected: "ghcr.io"},
+		{server: "https://registry.corp.com:5000/v2/", expected: "registry.corp.com:5000"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.server, func(test *testing.T) {
+			if got := registryHost(tt.server); got != tt.expected {
+				test.Errorf("registryHost(%q) = %q, want %q", tt.server, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResolveRegistryCredentials(test *testing.T) {
+	basic := base64.StdEncoding.EncodeToString([]byte("ci:secret"))
+	store := map[string][2]string{
+		"https://index.docker.io/v1/": {"jane", "hub-token"},
+		"registry.corp.com":           {"<token>", "refresh-token"},
+	}
+
+	tests := []struct {
+		name         string
+		dockerConfig string
+		registries   []string
+		expected     map[string]string
+		warnings     int
+		wantErr      bool
+	}{
+		{
+			name:         "inline auths",
+			dockerConfig: `{"auths": {"ghcr.io": {"auth": "` + basic + `"}, "quay.io": {"username": "ci", "password": "secret"}}}`,
+			expected:     map[string]string{"ghcr.io": basic, "quay.io": basic},
+		},
+		{
+			name:         "selected registries",
+			dockerConfig: `{"auths": {"ghcr.io": {"auth": "` + basic + `"}, "quay.io": {"auth": "` + basic + `"}}}`,
+			registries:   []string{"ghcr.io", "gcr.io"},
+			expected:     map[string]string{"ghcr.io": basic},
+			warnings:     1,
+		},
+		{
+			name:         "credential store",
+			dockerConfig: `{"auths": {"https://index.docker.io/v1/": {}, "registry.corp.com": {}}, "credsStore": "desktop"}`,
+			expected:     map[string]string{"https://index.docker.io/v1/": base64.StdEncoding.EncodeToString([]byte("jane:hub-token"))},
+			warnings:     1,
+		},
+		{
+			name:         "docker hub alias",
+			dockerConfig: `{"credHelpers": {"https://index.docker.io/v1/": "osxkeychain"}}`,
+			registries:   []string{"docker.io"},
+			expected:     map[string]string{"https://index.docker.io/v1/": base64.StdEncoding.EncodeToString([]byte("jane:hub-token"))},
+		},
+		{
+			name:         "nothing usable",
+			dockerConfig: `{"auths": {"ghcr.io": {"identitytoken": "refresh"}}}`,
+			warnings:     1,
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			path := filepath.Join(test.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(tt.dockerConfig), 0600); err != nil {
+				test.Fatal(err)
+			}
+
+			content, _, warnings, err := resolveRegistryCredentials(context.Background(), path, tt.registries, fakeCredentialHelper(store))
+			if len(warnings) != tt.warnings {
+				test.Errorf("warnings = %v, want %d", warnings, tt.warnings)
+			}
+			if (err != nil) != tt.wantErr {
+				test.Fatalf("resolveRegistryCredentials() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			var written dockerConfigFile
+			if err := json.Unmarshal(content, &written); err != nil {
+				test.Fatal(err)
+			}
+			got := make(map[string]string)
+			for server, entry := range written.Auths {
+				got[server] = entry.Auth
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				test.Errorf("auths = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNodeRegistryCredentials(test *testing.T) {
+	encode := func(creds string) string { return base64.StdEncoding.EncodeToString([]byte(creds)) }
+	path := filepath.Join(test.TempDir(), "config.json")
+	dockerConfig := `{"auths": {"https://ghcr.io": {"auth": "` + encode("jane:old") + `"}, "quay.io": {"auth": "` + encode("ci:quay") + `"}}}`
+	if err := os.WriteFile(path, []byte(dockerConfig), 0600); err != nil {
+		test.Fatal(err)
+	}
+	registries := []config.RegistryConfig{
+		{Host: "ghcr.io", Username: "octocat", Password: "token"},
+		{Host: "index.docker.io", Username: "jane", Password: "hub"},
+	}
+
+	tests := []struct {
+		name     string
+		creds    *config.RegistryCredentialsConfig
+		expected map[string]string
+		copied   []string
+	}{
+		{
+			name: "listed registries only",
+			expected: map[string]string{
+				"ghcr.io":                     encode("octocat:token"),
+				"https://index.docker.io/v1/": encode("jane:hub"),
+			},
+			copied: []string{"ghcr.io", "https://index.docker.io/v1/"},
+		},
+		{
+			name:  "listed registries win over docker login",
+			creds: &config.RegistryCredentialsConfig{Enabled: true, Config: path},
+			expected: map[string]string{
+				"ghcr.io":                     encode("octocat:token"),
+				"https://index.docker.io/v1/": encode("jane:hub"),
+				"quay.io":                     encode("ci:quay"),
+			},
+			copied: []string{"ghcr.io", "https://index.docker.io/v1/", "quay.io"},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			kind := &KindManager{registries: registries}
+			content, copied, err := kind.nodeRegistryCredentials(context.Background(), tt.creds)
+			if err != nil {
+				test.Fatalf("nodeRegistryCredentials() error = %v", err)
+			}
+			if !reflect.DeepEqual(copied, tt.copied) {
+				test.Errorf("copied = %v, want %v", copied, tt.copied)
+			}
+
+			var written dockerConfigFile
+			if err := json.Unmarshal(content, &written); err != nil {
+				test.Fatal(err)
+			}
+			got := make(map[string]string)
+			for server, entry := range written.Auths {
+				got[server] = entry.Auth
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				test.Errorf("auths = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
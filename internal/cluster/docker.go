@@ -114,20 +114,28 @@ func getDockerClientWithFallback(ctx context.Context) (*client.Client, error) {
 		"Supported alternatives: Docker, Docker Desktop, Colima, Podman\n\n"+
 		"If using a non-standard socket path, set DOCKER_HOST:\n"+
 		"  export DOCKER_HOST=unix:///path/to/docker.sock\n\n"+
+		"To use Podman without a socket, set %s=podman\n\n"+
 		"Install Docker: https://docs.docker.com/get-docker/\n"+
 		"Install Colima: https://github.com/abiosoft/colima",
 		os.Getenv("DOCKER_HOST"),
 		triedPaths,
-		lastErr)
+		lastErr,
+		runtimeEnvVar)
 }
 
-// CheckDockerAvailable checks if Docker is installed and running
-// Returns an error with helpful message if Docker is not available
+// CheckDockerAvailable checks if the container runtime is installed and running
+// Returns an error with helpful message if it is not available
 // Automatically detects and tries common Docker socket paths for:
 // - Docker / Docker Desktop
 // - Colima (macOS)
 // - Podman (macOS/Linux)
+// When the runtime is Podman, the podman CLI is checked instead, since rootless
+// Podman usually has no API socket running
 func CheckDockerAvailable(ctx context.Context) error {
+	if DetectContainerRuntime() == RuntimePodman {
+		return checkPodmanAvailable(ctx)
+	}
+
 	// Try to connect to Docker daemon using multiple socket paths
 	cli, err := getDockerClientWithFallback(ctx)
 	if err != nil {
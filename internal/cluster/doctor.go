@@ -0,0 +1,481 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/hjames9/kraze/internal/config"
+	"k8s.io/client-go/tools/clientcmd"
+	kindversion "sigs.k8s.io/kind/pkg/cmd/kind/version"
+)
+
+// DoctorStatus is the outcome of a 'kraze doctor' check
+type DoctorStatus string
+
+const (
+	DoctorPass DoctorStatus = "pass"
+	DoctorWarn DoctorStatus = "warn"
+	DoctorFail DoctorStatus = "fail"
+	DoctorSkip DoctorStatus = "skip"
+)
+
+// DoctorCheck is the result of one environment check
+type DoctorCheck struct {
+	Name    string       `json:"name"`
+	Status  DoctorStatus `json:"status"`
+	Message string       `json:"message"`
+	Hint    string       `json:"hint,omitempty"`
+}
+
+// Host limits below which kind clusters are known to misbehave (see kind's known issues)
+const (
+	minInotifyWatches   = 524288
+	minInotifyInstances = 512
+	lowDiskBytes        = 10 << 30 // Warn: a few node images and workloads fill this quickly
+	minDiskBytes        = 2 << 30  // Fail: kubelet starts evicting pods on disk pressure
+	apiServerPort       = 6443
+)
+
+// RunDoctorChecks checks that the host can run a kraze cluster. cfg may be nil
+// when no config file was found, in which case config-specific checks are skipped.
+func (kind *KindManager) RunDoctorChecks(ctx context.Context, cfg *config.ClusterConfig) []DoctorCheck {
+	external := cfg != nil && cfg.IsExternal()
+
+	runtimeCheck := checkContainerRuntime(ctx)
+	runtimeReady := runtimeCheck.Status == DoctorPass
+	checks := []DoctorCheck{runtimeCheck}
+
+	if external {
+		for _, name := range []string{"cgroups", "inotify limits", "disk space", "kind", "host ports"} {
+			checks = append(checks, DoctorCheck{Name: name, Status: DoctorSkip, Message: "not needed for an external cluster"})
+		}
+	} else {
+		if runtimeReady {
+			checks = append(checks, checkCgroups(ctx), checkDiskSpace(ctx))
+		} else {
+			checks = append(checks,
+				DoctorCheck{Name: "cgroups", Status: DoctorSkip, Message: "container runtime is not reachable"},
+				DoctorCheck{Name: "disk space", Status: DoctorSkip, Message: "container runtime is not reachable"})
+		}
+		checks = append(checks, checkInotify(), kind.checkKindVersion(cfg), checkHostPorts(cfg))
+		checks = append(checks, kind.checkGPUPrerequisites(cfg)...)
+	}
+
+	checks = append(checks, checkProxyEnv(os.Getenv, cfg), checkKubeconfigWritable())
+	return checks
+}
+
+// checkContainerRuntime checks that Docker (or Podman) is installed and reachable
+func checkContainerRuntime(ctx context.Context) DoctorCheck {
+	runtime := DetectContainerRuntime()
+	check := DoctorCheck{Name: "container runtime"}
+	if err := CheckDockerAvailable(ctx); err != nil {
+		check.Status = DoctorFail
+		check.Message = fmt.Sprintf("%s is not reachable: %s", runtime.DisplayName(), firstLine(err.Error()))
+		check.Hint = fmt.Sprintf("start %s (or Colima), set DOCKER_HOST for a non-standard socket, or set %s=podman", runtime.DisplayName(), runtimeEnvVar)
+		return check
+	}
+
+	check.Status = DoctorPass
+	check.Message = fmt.Sprintf("%s is reachable", runtime.DisplayName())
+	if versionLine := runtimeVersion(string(runtime)); versionLine != "" {
+		check.Message = fmt.Sprintf("%s is reachable", versionLine)
+	}
+	return check
+}
+
+// checkCgroups reports the cgroup version the container runtime uses for the nodes
+func checkCgroups(ctx context.Context) DoctorCheck {
+	format := "{{.CgroupVersion}}"
+	if DetectContainerRuntime() == RuntimePodman {
+		format = "{{.Host.CgroupsVersion}}"
+	}
+	output, err := runtimeCommandContext(ctx, "info", "--format", format).Output()
+	if err != nil {
+		return DoctorCheck{Name: "cgroups", Status: DoctorSkip, Message: fmt.Sprintf("could not read the runtime's cgroup version: %v", err)}
+	}
+	return cgroupCheck(strings.TrimSpace(string(output)))
+}
+
+// cgroupCheck evaluates a runtime-reported cgroup version ("2", "v2", "1", "v1")
+func cgroupCheck(version string) DoctorCheck {
+	switch strings.TrimPrefix(version, "v") {
+	case "2":
+		return DoctorCheck{Name: "cgroups", Status: DoctorPass, Message: "cgroup v2"}
+	case "1":
+		return DoctorCheck{
+			Name:    "cgroups",
+			Status:  DoctorWarn,
+			Message: "cgroup v1: Kubernetes 1.34 needs kraze's kubelet cgroup workaround and 1.35+ kubelets refuse to start by default",
+			Hint:    "boot the host with cgroup v2 (systemd.unified_cgroup_hierarchy=1), or pin cluster.version below 1.35",
+		}
+	}
+	return DoctorCheck{Name: "cgroups", Status: DoctorSkip, Message: fmt.Sprintf("unknown cgroup version '%s'", version)}
+}
+
+// checkInotify checks the inotify limits that kubelet and many pods' file watchers need
+func checkInotify() DoctorCheck {
+	watches, watchesErr := readProcInt("/proc/sys/fs/inotify/max_user_watches")
+	instances, instancesErr := readProcInt("/proc/sys/fs/inotify/max_user_instances")
+	if watchesErr != nil || instancesErr != nil {
+		// Not Linux: Docker Desktop, Colima and Podman machines set their own limits
+		return DoctorCheck{Name: "inotify limits", Status: DoctorSkip, Message: "not a Linux host; the container runtime's VM manages inotify limits"}
+	}
+	return inotifyCheck(watches, instances)
+}
+
+// inotifyCheck evaluates the host's inotify limits against kind's recommendations
+func inotifyCheck(watches, instances int) DoctorCheck {
+	check := DoctorCheck{Name: "inotify limits", Status: DoctorPass, Message: fmt.Sprintf("max_user_watches=%d, max_user_instances=%d", watches, instances)}
+	if watches < minInotifyWatches || instances < minInotifyInstances {
+		check.Status = DoctorWarn
+		check.Message += " (pods may fail with 'too many open files')"
+		check.Hint = fmt.Sprintf("sudo sysctl fs.inotify.max_user_watches=%d fs.inotify.max_user_instances=%d", max(watches, minInotifyWatches), max(instances, minInotifyInstances))
+	}
+	return check
+}
+
+// readProcInt reads an integer from a /proc file
+func readProcInt(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+// checkDiskSpace checks free space where the container runtime stores images.
+// When that directory isn't on this host (a Docker Desktop or Podman VM), the
+// home directory is checked instead.
+func checkDiskSpace(ctx context.Context) DoctorCheck {
+	format := "{{.DockerRootDir}}"
+	if DetectContainerRuntime() == RuntimePodman {
+		format = "{{.Store.GraphRoot}}"
+	}
+	path := ""
+	if output, err := runtimeCommandContext(ctx, "info", "--format", format).Output(); err == nil {
+		path = strings.TrimSpace(string(output))
+	}
+	if _, err := os.Stat(path); path == "" || err != nil {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return DoctorCheck{Name: "disk space", Status: DoctorSkip, Message: fmt.Sprintf("could not find a directory to check: %v", err)}
+		}
+		path = home
+	}
+
+	free, err := freeDiskBytes(path)
+	if err != nil {
+		return DoctorCheck{Name: "disk space", Status: DoctorSkip, Message: fmt.Sprintf("could not read free space of %s: %v", path, err)}
+	}
+	return diskSpaceCheck(path, free)
+}
+
+// diskSpaceCheck evaluates the free space of a directory
+func diskSpaceCheck(path string, free uint64) DoctorCheck {
+	check := DoctorCheck{Name: "disk space", Status: DoctorPass, Message: fmt.Sprintf("%s free on %s", formatBytes(free), path)}
+	switch {
+	case free < minDiskBytes:
+		check.Status = DoctorFail
+	case free < lowDiskBytes:
+		check.Status = DoctorWarn
+	default:
+		return check
+	}
+	check.Hint = "free up space, e.g. with 'docker system prune' (or 'podman system prune')"
+	return check
+}
+
+// formatBytes formats a byte count in GiB or MiB
+func formatBytes(value uint64) string {
+	if value >= 1<<30 {
+		return fmt.Sprintf("%.1f GiB", float64(value)/(1<<30))
+	}
+	return fmt.Sprintf("%.1f MiB", float64(value)/(1<<20))
+}
+
+// checkKindVersion checks the node image against the embedded kind release, the
+// cluster.kubernetes range, and any kind CLI on the PATH
+func (kind *KindManager) checkKindVersion(cfg *config.ClusterConfig) DoctorCheck {
+	if cfg == nil {
+		cfg = &config.ClusterConfig{}
+	}
+	if err := kind.CheckNodeImageVersion(cfg); err != nil {
+		return DoctorCheck{Name: "kind", Status: DoctorFail, Message: err.Error(), Hint: "change cluster.version or cluster.node_image, or widen cluster.kubernetes"}
+	}
+
+	cliVersion := ""
+	if output, err := osexec.Command("kind", "version").Output(); err == nil {
+		cliVersion = strings.TrimSpace(string(output))
+	}
+	defaultTag := kind.parseK8sVersion(&config.ClusterConfig{})
+	return kindVersionCheck(kindversion.Version(), cliVersion, kind.parseK8sVersion(cfg), defaultTag)
+}
+
+// kindVersionCheck compares the node image's Kubernetes version with the default
+// image of the embedded kind release, and the kind CLI (e.g. "kind v0.29.0 go1.24.2
+// linux/amd64") with the embedded release
+func kindVersionCheck(embedded, cliVersion, nodeTag, defaultTag string) DoctorCheck {
+	check := DoctorCheck{Name: "kind", Status: DoctorPass, Message: fmt.Sprintf("kind %s, node image %s", embedded, nodeTag)}
+
+	nodeVersion, nodeErr := config.ParseKubernetesVersion(nodeTag)
+	defaultVersion, defaultErr := config.ParseKubernetesVersion(defaultTag)
+	if nodeErr == nil && defaultErr == nil && minorOf(nodeVersion) > minorOf(defaultVersion) {
+		check.Status = DoctorWarn
+		check.Message = fmt.Sprintf("node image %s is newer than kind %s supports (up to %s)", nodeTag, embedded, defaultTag)
+		check.Hint = "use a node image released for this kind version, or upgrade kraze"
+		return check
+	}
+
+	if fields := strings.Fields(cliVersion); len(fields) >= 2 {
+		cli, cliErr := semver.NewVersion(strings.TrimPrefix(fields[1], "v"))
+		own, ownErr := semver.NewVersion(embedded)
+		if cliErr == nil && ownErr == nil && (cli.Major() != own.Major() || cli.Minor() != own.Minor()) {
+			check.Status = DoctorWarn
+			check.Message = fmt.Sprintf("kind CLI %s differs from the kind %s built into kraze", fields[1], embedded)
+			check.Hint = "manage kraze clusters with kraze, or install a matching kind CLI"
+		}
+	}
+	return check
+}
+
+// minorOf returns a comparable major/minor number
+func minorOf(version *semver.Version) uint64 {
+	return version.Major()*1000 + version.Minor()
+}
+
+// checkHostPorts checks that the API server port and the configured host port
+// mappings are free. A taken mapping fails cluster creation; a taken 6443 usually
+// means another local cluster whose kubeconfig contexts are easy to confuse.
+func checkHostPorts(cfg *config.ClusterConfig) DoctorCheck {
+	check := DoctorCheck{Name: "host ports", Status: DoctorPass}
+	checked := []string{strconv.Itoa(apiServerPort)}
+	var taken []string
+	if cfg != nil {
+		for _, node := range cfg.Config {
+			for _, mapping := range node.ExtraPortMappings {
+				if mapping.HostPort <= 0 {
+					continue
+				}
+				network := "tcp"
+				if strings.EqualFold(mapping.Protocol, "udp") {
+					network = "udp"
+				}
+				address := mapping.ListenAddress
+				if address == "" {
+					address = "0.0.0.0"
+				}
+				label := fmt.Sprintf("%d/%s", mapping.HostPort, network)
+				checked = append(checked, label)
+				if !portAvailable(network, net.JoinHostPort(address, strconv.Itoa(int(mapping.HostPort)))) {
+					taken = append(taken, label)
+				}
+			}
+		}
+	}
+
+	if len(taken) > 0 {
+		check.Status = DoctorFail
+		check.Message = fmt.Sprintf("host port(s) %s already in use; creating the cluster will fail", strings.Join(taken, ", "))
+		check.Hint = "stop the process holding the port (find it with 'ss -tlnp' or 'lsof -i :<port>'), or change extraPortMappings"
+		return check
+	}
+	if !portAvailable("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(apiServerPort))) {
+		check.Status = DoctorWarn
+		check.Message = fmt.Sprintf("port %d is in use, likely by another Kubernetes API server", apiServerPort)
+		check.Hint = "make sure kubectl targets the kraze cluster's context (kraze shell uses an isolated kubeconfig)"
+		return check
+	}
+	check.Message = fmt.Sprintf("%s available", strings.Join(checked, ", "))
+	return check
+}
+
+// portAvailable reports whether a port can be bound on this host
+func portAvailable(network, address string) bool {
+	if network == "udp" {
+		conn, err := net.ListenPacket(network, address)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return false
+	}
+	listener.Close()
+	return true
+}
+
+// checkGPUPrerequisites checks the host drivers of the GPUs the cluster enables
+func (kind *KindManager) checkGPUPrerequisites(cfg *config.ClusterConfig) []DoctorCheck {
+	if cfg == nil {
+		return nil
+	}
+	var checks []DoctorCheck
+	gpuCheck := func(name string, validate func() error) {
+		check := DoctorCheck{Name: name, Status: DoctorPass, Message: "prerequisites found"}
+		if err := validate(); err != nil {
+			message, hint, _ := strings.Cut(err.Error(), "\n")
+			check.Status = DoctorFail
+			check.Message = message
+			check.Hint = strings.TrimSpace(hint)
+		}
+		checks = append(checks, check)
+	}
+	if cfg.GPU.IsNvidiaEnabled() {
+		gpuCheck("NVIDIA GPU", kind.validateNvidiaGPUPrerequisites)
+	}
+	if cfg.GPU.IsAMDEnabled() {
+		gpuCheck("AMD GPU", kind.validateAMDGPUPrerequisites)
+	}
+	if err := checkRootlessPodmanPorts(cfg); err != nil {
+		message, hint, _ := strings.Cut(err.Error(), "\n")
+		checks = append(checks, DoctorCheck{Name: "rootless ports", Status: DoctorFail, Message: message, Hint: strings.TrimSpace(hint)})
+	}
+	return checks
+}
+
+// requiredNoProxy are the hosts that must bypass a proxy for kubectl and in-cluster traffic to work
+var requiredNoProxy = []string{"localhost", "127.0.0.1", ".svc"}
+
+// checkProxyEnv checks the proxy environment variables for malformed URLs,
+// conflicting upper/lower case values, and a NO_PROXY missing local addresses
+func checkProxyEnv(getenv func(string) string, cfg *config.ClusterConfig) DoctorCheck {
+	check := DoctorCheck{Name: "proxy", Status: DoctorPass}
+
+	proxies := map[string]string{}
+	for _, name := range []string{"HTTP_PROXY", "HTTPS_PROXY"} {
+		upper, lower := getenv(name), getenv(strings.ToLower(name))
+		if upper != "" && lower != "" && upper != lower {
+			check.Status = DoctorWarn
+			check.Message = fmt.Sprintf("%s and %s differ; tools disagree on which one wins", name, strings.ToLower(name))
+			check.Hint = fmt.Sprintf("set %s and %s to the same value", name, strings.ToLower(name))
+			return check
+		}
+		if value := firstNonEmpty(upper, lower); value != "" {
+			proxies[name] = value
+		}
+	}
+	if len(proxies) == 0 {
+		check.Message = "no proxy configured"
+		return check
+	}
+
+	for _, name := range []string{"HTTP_PROXY", "HTTPS_PROXY"} {
+		value, ok := proxies[name]
+		if !ok {
+			continue
+		}
+		parsed, err := url.Parse(value)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			check.Status = DoctorFail
+			check.Message = fmt.Sprintf("%s '%s' is not a valid proxy URL", name, value)
+			check.Hint = fmt.Sprintf("use a full URL, e.g. %s=http://proxy.example.com:3128", name)
+			return check
+		}
+	}
+
+	noProxy := firstNonEmpty(getenv("NO_PROXY"), getenv("no_proxy"))
+	entries := map[string]bool{}
+	for _, entry := range strings.Split(noProxy, ",") {
+		entries[strings.TrimSpace(entry)] = true
+	}
+	var missing []string
+	for _, host := range requiredNoProxy {
+		if !entries[host] {
+			missing = append(missing, host)
+		}
+	}
+	if len(missing) > 0 {
+		check.Status = DoctorWarn
+		check.Message = fmt.Sprintf("NO_PROXY is missing %s, so local and in-cluster traffic goes through the proxy", strings.Join(missing, ", "))
+		check.Hint = fmt.Sprintf("export NO_PROXY=%s", strings.Trim(noProxy+","+strings.Join(missing, ","), ","))
+		return check
+	}
+
+	check.Message = "proxy environment looks consistent"
+	if cfg != nil && !cfg.IsExternal() && (cfg.Proxy == nil || (cfg.Proxy.Enabled != nil && !*cfg.Proxy.Enabled)) {
+		check.Message += " (not applied to cluster nodes; set cluster.proxy.enabled: true if they need it)"
+	}
+	return check
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// checkKubeconfigWritable checks that kraze can update ~/.kube/config and its own data directory
+func checkKubeconfigWritable() DoctorCheck {
+	check := DoctorCheck{Name: "kubeconfig", Status: DoctorPass}
+	paths := []string{clientcmd.RecommendedHomeFile}
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, ".kraze"))
+	}
+
+	for _, path := range paths {
+		if err := checkWritable(path); err != nil {
+			check.Status = DoctorFail
+			check.Message = fmt.Sprintf("%s is not writable: %v", path, err)
+			check.Hint = fmt.Sprintf("fix the ownership, e.g. sudo chown -R $USER %s", filepath.Dir(path))
+			return check
+		}
+	}
+	check.Message = fmt.Sprintf("%s writable", strings.Join(paths, " and "))
+	return check
+}
+
+// checkWritable reports whether a file (or directory) can be written, or created
+// in its nearest existing parent directory, without modifying anything
+func checkWritable(path string) error {
+	info, err := os.Stat(path)
+	if err == nil && !info.IsDir() {
+		file, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+		return file.Close()
+	}
+
+	dir := path
+	for err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return err
+		}
+		dir = parent
+		_, err = os.Stat(dir)
+	}
+
+	probe, err := os.CreateTemp(dir, ".kraze-doctor-*")
+	if err != nil {
+		return err
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// firstLine returns the first line of a (possibly multi-line) message
+func firstLine(message string) string {
+	line, _, _ := strings.Cut(message, "\n")
+	return strings.TrimSpace(line)
+}
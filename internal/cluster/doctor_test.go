@@ -0,0 +1,191 @@
+package cluster
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+)
+
+func TestCgroupCheck(test *testing.T) {
+	tests := []struct {
+		version string
+		want    DoctorStatus
+	}{
+		{version: "2", want: DoctorPass},
+		{version: "v2", want: DoctorPass},
+		{version: "1", want: DoctorWarn},
+		{version: "v1", want: DoctorWarn},
+		{version: "", want: DoctorSkip},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.version, func(test *testing.T) {
+			if got := cgroupCheck(tt.version); got.Status != tt.want {
+				test.Errorf("cgroupCheck(%q) = %s, want %s", tt.version, got.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestInotifyCheck(test *testing.T) {
+	if got := inotifyCheck(524288, 512); got.Status != DoctorPass {
+		test.Errorf("inotifyCheck() at the recommended limits = %s, want pass", got.Status)
+	}
+
+	got := inotifyCheck(8192, 1024)
+	if got.Status != DoctorWarn {
+		test.Fatalf("inotifyCheck() with low watches = %s, want warn", got.Status)
+	}
+	// Limits that are already high enough are kept in the hint
+	if !strings.Contains(got.Hint, "max_user_watches=524288") || !strings.Contains(got.Hint, "max_user_instances=1024") {
+		test.Errorf("inotifyCheck() hint = %q", got.Hint)
+	}
+}
+
+func TestDiskSpaceCheck(test *testing.T) {
+	tests := []struct {
+		name string
+		free uint64
+		want DoctorStatus
+	}{
+		{name: "plenty", free: 50 << 30, want: DoctorPass},
+		{name: "low", free: 5 << 30, want: DoctorWarn},
+		{name: "nearly full", free: 512 << 20, want: DoctorFail},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := diskSpaceCheck("/var/lib/docker", tt.free); got.Status != tt.want {
+				test.Errorf("diskSpaceCheck(%d) = %s, want %s", tt.free, got.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestKindVersionCheck(test *testing.T) {
+	tests := []struct {
+		name       string
+		cliVersion string
+		nodeTag    string
+		want       DoctorStatus
+	}{
+		{name: "default image, no CLI", nodeTag: "v1.36.1", want: DoctorPass},
+		{name: "older image", nodeTag: "v1.33.1", want: DoctorPass},
+		{name: "newer image", nodeTag: "v1.37.0", want: DoctorWarn},
+		{name: "matching CLI", cliVersion: "kind v0.32.1 go1.26.3 linux/amd64", nodeTag: "v1.36.1", want: DoctorPass},
+		{name: "older CLI", cliVersion: "kind v0.29.0 go1.24.2 linux/amd64", nodeTag: "v1.36.1", want: DoctorWarn},
+		{name: "untagged image", nodeTag: "registry.local/node", want: DoctorPass},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			got := kindVersionCheck("0.32.0-alpha", tt.cliVersion, tt.nodeTag, "v1.36.1")
+			if got.Status != tt.want {
+				test.Errorf("kindVersionCheck() = %s (%s), want %s", got.Status, got.Message, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckProxyEnv(test *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want DoctorStatus
+	}{
+		{name: "no proxy", env: nil, want: DoctorPass},
+		{
+			name: "complete",
+			env:  map[string]string{"HTTPS_PROXY": "http://proxy:3128", "NO_PROXY": "localhost,127.0.0.1,.svc,.corp"},
+			want: DoctorPass,
+		},
+		{
+			name: "lowercase only",
+			env:  map[string]string{"http_proxy": "http://proxy:3128", "no_proxy": "localhost,127.0.0.1,.svc"},
+			want: DoctorPass,
+		},
+		{
+			name: "missing no_proxy entries",
+			env:  map[string]string{"HTTP_PROXY": "http://proxy:3128", "NO_PROXY": "localhost"},
+			want: DoctorWarn,
+		},
+		{
+			name: "case mismatch",
+			env:  map[string]string{"HTTP_PROXY": "http://a:3128", "http_proxy": "http://b:3128"},
+			want: DoctorWarn,
+		},
+		{
+			name: "not a URL",
+			env:  map[string]string{"HTTPS_PROXY": "proxy:3128", "NO_PROXY": "localhost,127.0.0.1,.svc"},
+			want: DoctorFail,
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			got := checkProxyEnv(func(name string) string { return tt.env[name] }, nil)
+			if got.Status != tt.want {
+				test.Errorf("checkProxyEnv() = %s (%s), want %s", got.Status, got.Message, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckWritable(test *testing.T) {
+	dir := test.TempDir()
+
+	file := filepath.Join(dir, "config")
+	if err := os.WriteFile(file, []byte("apiVersion: v1\n"), 0600); err != nil {
+		test.Fatal(err)
+	}
+	if err := checkWritable(file); err != nil {
+		test.Errorf("checkWritable(existing file) error = %v", err)
+	}
+	if err := checkWritable(filepath.Join(dir, "missing", "nested", "config")); err != nil {
+		test.Errorf("checkWritable(missing file) error = %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if len(entries) != 1 {
+		test.Errorf("checkWritable() left %d entries behind, want only the original file", len(entries)-1)
+	}
+
+	if runtime.GOOS == "windows" || os.Getuid() == 0 {
+		return
+	}
+	readOnly := filepath.Join(dir, "readonly")
+	if err := os.Mkdir(readOnly, 0555); err != nil {
+		test.Fatal(err)
+	}
+	if err := checkWritable(filepath.Join(readOnly, "config")); err == nil {
+		test.Error("checkWritable() in a read-only directory should fail")
+	}
+}
+
+func TestCheckHostPorts(test *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		test.Fatal(err)
+	}
+	defer listener.Close()
+	port := int32(listener.Addr().(*net.TCPAddr).Port)
+
+	cfg := &config.ClusterConfig{
+		Config: []config.KindNode{{
+			Role:              "control-plane",
+			ExtraPortMappings: []config.PortMapping{{ContainerPort: 80, HostPort: port, ListenAddress: "127.0.0.1"}},
+		}},
+	}
+	got := checkHostPorts(cfg)
+	if got.Status != DoctorFail {
+		test.Errorf("checkHostPorts() with a taken port = %s (%s), want fail", got.Status, got.Message)
+	}
+}
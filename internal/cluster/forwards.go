@@ -0,0 +1,103 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// forwardsFileName is the per-cluster status file written by the port-forward daemon
+const forwardsFileName = "forwards.json"
+
+// ForwardDaemonHeartbeatTimeout is how long a port-forward daemon heartbeat stays valid
+const ForwardDaemonHeartbeatTimeout = 30 * time.Second
+
+// Port forward states reported in the status file
+const (
+	ForwardStateConnecting   = "connecting"
+	ForwardStateActive       = "active"
+	ForwardStateReconnecting = "reconnecting"
+)
+
+// ForwardStatus is the state of a single port forward managed by the daemon
+type ForwardStatus struct {
+	Service    string    `json:"service"`
+	Namespace  string    `json:"namespace"`
+	LocalPort  int       `json:"local_port"`
+	RemotePort int       `json:"remote_port"`
+	Pod        string    `json:"pod,omitempty"`
+	State      string    `json:"state"`
+	LastError  string    `json:"last_error,omitempty"`
+	Restarts   int       `json:"restarts"`
+	Since      time.Time `json:"since"` // When the forward entered its current state
+}
+
+// ForwardDaemonStatus is the status file shared between the port-forward daemon
+// and the commands that report on it
+type ForwardDaemonStatus struct {
+	PID       int             `json:"pid"`
+	Started   time.Time       `json:"started"`
+	Heartbeat time.Time       `json:"heartbeat"`
+	Forwards  []ForwardStatus `json:"forwards"`
+}
+
+// IsLive returns true if the daemon reported a heartbeat recently
+func (status *ForwardDaemonStatus) IsLive(now time.Time) bool {
+	return status.PID != 0 && now.Sub(status.Heartbeat) < ForwardDaemonHeartbeatTimeout
+}
+
+// LoadForwardStatus reads the port-forward daemon status for a cluster.
+// Returns nil if no daemon status has been written.
+func LoadForwardStatus(clusterName string) (*ForwardDaemonStatus, error) {
+	dir, err := ClusterDataDir(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, forwardsFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read port-forward status: %w", err)
+	}
+
+	var status ForwardDaemonStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, fmt.Errorf("failed to parse port-forward status: %w", err)
+	}
+	return &status, nil
+}
+
+// SaveForwardStatus writes the port-forward daemon status for a cluster atomically
+func SaveForwardStatus(clusterName string, status *ForwardDaemonStatus) error {
+	dir, err := ClusterDataDir(clusterName)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal port-forward status: %w", err)
+	}
+
+	if err := writeFileAtomic(dir, forwardsFileName, data); err != nil {
+		return fmt.Errorf("failed to write port-forward status: %w", err)
+	}
+	return nil
+}
+
+// RemoveForwardStatus deletes the port-forward daemon status file for a cluster
+func RemoveForwardStatus(clusterName string) error {
+	dir, err := ClusterDataDir(clusterName)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(filepath.Join(dir, forwardsFileName)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove port-forward status: %w", err)
+	}
+	return nil
+}
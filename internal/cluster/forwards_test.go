@@ -0,0 +1,28 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+)
+
+func TestForwardDaemonStatusIsLive(test *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		status   ForwardDaemonStatus
+		expected bool
+	}{
+		{name: "recent heartbeat", status: ForwardDaemonStatus{PID: 42, Heartbeat: now.Add(-5 * time.Second)}, expected: true},
+		{name: "stale heartbeat", status: ForwardDaemonStatus{PID: 42, Heartbeat: now.Add(-time.Minute)}, expected: false},
+		{name: "no pid", status: ForwardDaemonStatus{Heartbeat: now}, expected: false},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := tt.status.IsLive(now); got != tt.expected {
+				test.Errorf("IsLive() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
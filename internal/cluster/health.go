@@ -0,0 +1,238 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/config"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// ErrClusterStopped is returned by CheckHealth for a cluster stopped on purpose
+var ErrClusterStopped = errors.New("cluster is stopped")
+
+// HealthReport describes what CheckHealth found and fixed after the container
+// runtime restarted under a cluster
+type HealthReport struct {
+	StartedNodes []string          // Node containers that were stopped and have been started
+	LostPorts    []string          // Host port mappings the runtime no longer publishes
+	Kubeconfigs  map[string]string // Kubeconfig files re-pointed, to the server they pointed at before
+}
+
+// Changed returns whether anything was found or fixed
+func (report *HealthReport) Changed() bool {
+	return len(report.StartedNodes) > 0 || len(report.LostPorts) > 0 || len(report.Kubeconfigs) > 0
+}
+
+// hostPortBinding is a port binding as reported by docker and podman inspect
+type hostPortBinding struct {
+	HostIP   string `json:"HostIp"`
+	HostPort string `json:"HostPort"`
+}
+
+// nodeInspect is the part of a node container's inspect output CheckHealth uses
+type nodeInspect struct {
+	State struct {
+		Running bool `json:"Running"`
+	} `json:"State"`
+	HostConfig struct {
+		PortBindings map[string][]hostPortBinding `json:"PortBindings"`
+	} `json:"HostConfig"`
+	NetworkSettings struct {
+		Ports map[string][]hostPortBinding `json:"Ports"`
+	} `json:"NetworkSettings"`
+}
+
+// CheckHealth checks a kind cluster for what a Docker Desktop (or podman
+// machine) restart leaves behind: it starts node containers that stopped
+// without 'kraze stop', reports fixed host ports that are no longer published,
+// and re-points kubeconfigs at the API server's current address, so commands
+// don't fail with connection refused errors. A cluster stopped on purpose is
+// left alone and ErrClusterStopped returned.
+func (kind *KindManager) CheckHealth(ctx context.Context, cfg *config.ClusterConfig) (*HealthReport, error) {
+	report := &HealthReport{Kubeconfigs: make(map[string]string)}
+	nodes, err := kind.provider.ListNodes(cfg.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster nodes: %w", err)
+	}
+	if len(nodes) == 0 {
+		return report, nil
+	}
+
+	var stopped []string
+	for _, node := range nodes {
+		inspect, err := inspectNode(ctx, node.String())
+		if err != nil {
+			return nil, err
+		}
+		if !inspect.State.Running {
+			stopped = append(stopped, node.String())
+		}
+	}
+
+	if len(stopped) > 0 {
+		if rec, err := LoadActivity(cfg.Name); err == nil && rec.Stopped {
+			return nil, fmt.Errorf("%w: cluster '%s' is stopped, run 'kraze start' first", ErrClusterStopped, cfg.Name)
+		}
+		for _, containerName := range stopped {
+			if output, err := runtimeCommandContext(ctx, "start", containerName).CombinedOutput(); err != nil {
+				return nil, fmt.Errorf("failed to start node %s: %w (output: %s)", containerName, err, strings.TrimSpace(string(output)))
+			}
+		}
+		if err := kind.waitForNodeContainers(ctx, cfg.Name); err != nil {
+			return nil, err
+		}
+		if err := kind.waitForAPIServer(ctx, cfg.Name); err != nil {
+			return nil, err
+		}
+		report.StartedNodes = stopped
+	} else if rec, err := LoadActivity(cfg.Name); err == nil && rec.Stopped {
+		// Started outside kraze (docker start), so it's no longer stopped on purpose
+		if err := SetStopped(cfg.Name, false); err != nil {
+			return nil, err
+		}
+	}
+
+	// Ports are only published while a container runs, so they're compared
+	// after the stopped nodes have been started
+	for _, node := range nodes {
+		inspect, err := inspectNode(ctx, node.String())
+		if err != nil {
+			return nil, err
+		}
+		for _, port := range lostPortMappings(inspect.HostConfig.PortBindings, inspect.NetworkSettings.Ports) {
+			report.LostPorts = append(report.LostPorts, node.String()+" "+port)
+		}
+	}
+	return kind.checkKubeconfigs(cfg.Name, report)
+}
+
+// inspectNode returns the inspect output of a node container
+func inspectNode(ctx context.Context, containerName string) (*nodeInspect, error) {
+	output, err := runtimeCommandContext(ctx, "inspect", containerName).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect node %s: %w", containerName, err)
+	}
+	var inspect []nodeInspect
+	if err := json.Unmarshal(output, &inspect); err != nil || len(inspect) == 0 {
+		return nil, fmt.Errorf("failed to parse inspect output of node %s", containerName)
+	}
+	return &inspect[0], nil
+}
+
+// checkKubeconfigs re-points ~/.kube/config and the isolated kubeconfig of
+// 'kraze shell' when the API server's address changed, as it does when the
+// runtime publishes the API server port on a new host port or the control
+// plane gets a new container IP
+func (kind *KindManager) checkKubeconfigs(clusterName string, report *HealthReport) (*HealthReport, error) {
+	clusterKey := "kind-" + clusterName
+
+	// ~/.kube/config holds the internal kubeconfig (see UpdateKubeconfigFile)
+	server, err := kind.currentServer(clusterName, true)
+	if err != nil {
+		return nil, err
+	}
+	homeFile := clientcmd.RecommendedHomeFile
+	unlock, err := lockKubeconfig(homeFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock kubeconfig: %w", err)
+	}
+	previous, err := repointKubeconfig(homeFile, clusterKey, server)
+	unlock()
+	if err != nil {
+		return nil, err
+	}
+	if previous != "" {
+		report.Kubeconfigs[homeFile] = previous
+	}
+
+	dir, err := ClusterDataDir(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	server, err = kind.currentServer(clusterName, false)
+	if err != nil {
+		return nil, err
+	}
+	isolatedFile := filepath.Join(dir, kubeconfigFileName)
+	previous, err = repointKubeconfig(isolatedFile, clusterKey, server)
+	if err != nil {
+		return nil, err
+	}
+	if previous != "" {
+		report.Kubeconfigs[isolatedFile] = previous
+	}
+	return report, nil
+}
+
+// currentServer returns the API server address kraze would write to a kubeconfig now
+func (kind *KindManager) currentServer(clusterName string, internal bool) (string, error) {
+	content, err := kind.GetKubeConfigQuiet(clusterName, internal, true)
+	if err != nil {
+		return "", err
+	}
+	kubeconfig, err := clientcmd.Load([]byte(content))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+	cluster, ok := kubeconfig.Clusters["kind-"+clusterName]
+	if !ok {
+		return "", fmt.Errorf("kubeconfig has no cluster kind-%s", clusterName)
+	}
+	return cluster.Server, nil
+}
+
+// lostPortMappings returns the fixed host ports a container asked for that
+// the runtime no longer publishes, as "hostPort->containerPort/proto". Random
+// host ports (empty or 0) are skipped, since they're expected to change.
+func lostPortMappings(bindings, published map[string][]hostPortBinding) []string {
+	var lost []string
+	for containerPort, wanted := range bindings {
+		for _, binding := range wanted {
+			if binding.HostPort == "" || binding.HostPort == "0" {
+				continue
+			}
+			found := false
+			for _, actual := range published[containerPort] {
+				if actual.HostPort == binding.HostPort {
+					found = true
+					break
+				}
+			}
+			if !found {
+				lost = append(lost, binding.HostPort+"->"+containerPort)
+			}
+		}
+	}
+	sort.Strings(lost)
+	return lost
+}
+
+// repointKubeconfig sets the server of a cluster in a kubeconfig file and
+// returns the server it replaced, or "" if the file or cluster doesn't exist
+// or already points there. Contexts and credentials are left as they are.
+func repointKubeconfig(path, clusterKey, server string) (string, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	kubeconfig, err := clientcmd.LoadFromFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to load kubeconfig %s: %w", path, err)
+	}
+	cluster, ok := kubeconfig.Clusters[clusterKey]
+	if !ok || cluster.Server == server || server == "" {
+		return "", nil
+	}
+	previous := cluster.Server
+	cluster.Server = server
+	if err := writeKubeconfigFile(path, kubeconfig); err != nil {
+		return "", fmt.Errorf("failed to write kubeconfig %s: %w", path, err)
+	}
+	return previous, nil
+}
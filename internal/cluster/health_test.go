@@ -0,0 +1,87 @@
+package cluster
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestLostPortMappings(test *testing.T) {
+	tests := []struct {
+		name      string
+		bindings  map[string][]hostPortBinding
+		published map[string][]hostPortBinding
+		expected  []string
+	}{
+		{
+			name:      "all published",
+			bindings:  map[string][]hostPortBinding{"80/tcp": {{HostPort: "8080"}}},
+			published: map[string][]hostPortBinding{"80/tcp": {{HostIP: "0.0.0.0", HostPort: "8080"}, {HostIP: "::", HostPort: "8080"}}},
+		},
+		{
+			name:      "random host ports may change",
+			bindings:  map[string][]hostPortBinding{"6443/tcp": {{HostIP: "127.0.0.1", HostPort: ""}}},
+			published: map[string][]hostPortBinding{"6443/tcp": {{HostIP: "127.0.0.1", HostPort: "52113"}}},
+		},
+		{
+			name:      "moved",
+			bindings:  map[string][]hostPortBinding{"80/tcp": {{HostPort: "8080"}}, "443/tcp": {{HostPort: "8443"}}},
+			published: map[string][]hostPortBinding{"80/tcp": {{HostPort: "32768"}}, "443/tcp": {{HostPort: "8443"}}},
+			expected:  []string{"8080->80/tcp"},
+		},
+		{
+			name:     "not published",
+			bindings: map[string][]hostPortBinding{"80/tcp": {{HostPort: "8080"}}, "443/tcp": {{HostPort: "8443"}}},
+			expected: []string{"8080->80/tcp", "8443->443/tcp"},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := lostPortMappings(tt.bindings, tt.published); !reflect.DeepEqual(got, tt.expected) {
+				test.Errorf("lostPortMappings() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRepointKubeconfig(test *testing.T) {
+	dir := test.TempDir()
+	path := filepath.Join(dir, "config")
+	if err := os.WriteFile(path, []byte(testKubeconfig), 0600); err != nil {
+		test.Fatal(err)
+	}
+
+	previous, err := repointKubeconfig(path, "kind-dev", "https://127.0.0.1:6443")
+	if err != nil || previous != "" {
+		test.Fatalf("repointKubeconfig() = %q, %v, want no change", previous, err)
+	}
+
+	previous, err = repointKubeconfig(path, "kind-dev", "https://127.0.0.1:52113")
+	if err != nil {
+		test.Fatalf("repointKubeconfig() error = %v", err)
+	}
+	if previous != "https://127.0.0.1:6443" {
+		test.Errorf("repointKubeconfig() = %q, want the old server", previous)
+	}
+	kubeconfig, err := clientcmd.LoadFromFile(path)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if server := kubeconfig.Clusters["kind-dev"].Server; server != "https://127.0.0.1:52113" {
+		test.Errorf("server = %q, want https://127.0.0.1:52113", server)
+	}
+	if kubeconfig.CurrentContext != "kind-dev" || kubeconfig.AuthInfos["kind-dev"].Token != "abc" {
+		test.Error("repointKubeconfig() changed more than the server")
+	}
+
+	if previous, err := repointKubeconfig(path, "kind-other", "https://127.0.0.1:1"); err != nil || previous != "" {
+		test.Errorf("repointKubeconfig() of a missing cluster = %q, %v", previous, err)
+	}
+	if previous, err := repointKubeconfig(filepath.Join(dir, "missing"), "kind-dev", "https://127.0.0.1:1"); err != nil || previous != "" {
+		test.Errorf("repointKubeconfig() of a missing file = %q, %v", previous, err)
+	}
+}
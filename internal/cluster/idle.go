@@ -0,0 +1,217 @@
+package cluster
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// activityFileName is the per-cluster file that tracks the last kraze activity
+// and the auto-stop configuration
+const activityFileName = "activity.json"
+
+// activityLockFileName serializes changes to the activity record, so a command
+// recording activity and the idle watcher deciding to stop can't interleave
+const activityLockFileName = "activity.lock"
+
+// activityLockTimeout bounds how long a change to the activity record waits for another
+const activityLockTimeout = 10 * time.Second
+
+// IdleWatcherHeartbeatTimeout is how long a watcher heartbeat stays valid. A new
+// watcher is only started when the previous one has not reported within this window.
+const IdleWatcherHeartbeatTimeout = 3 * time.Minute
+
+// ActivityRecord tracks when a cluster was last used and when it should be stopped
+type ActivityRecord struct {
+	LastActivity     time.Time `json:"last_activity"`
+	AutoStop         string    `json:"auto_stop,omitempty"` // Idle duration after which the cluster is stopped (e.g., "2h")
+	WatcherPID       int       `json:"watcher_pid,omitempty"`
+	WatcherHeartbeat time.Time `json:"watcher_heartbeat,omitempty"`
+	Stopped          bool      `json:"stopped,omitempty"` // Stopped on purpose (kraze stop or auto-stop), so it isn't restarted behind the user's back
+}
+
+// GetAutoStop returns the parsed auto-stop duration, or 0 if auto-stop is disabled
+func (rec *ActivityRecord) GetAutoStop() time.Duration {
+	if rec.AutoStop == "" {
+		return 0
+	}
+	duration, err := time.ParseDuration(rec.AutoStop)
+	if err != nil || duration < 0 {
+		return 0
+	}
+	return duration
+}
+
+// IsIdle returns true if auto-stop is enabled and no activity was recorded within the auto-stop window
+func (rec *ActivityRecord) IsIdle(now time.Time) bool {
+	autoStop := rec.GetAutoStop()
+	if autoStop == 0 || rec.LastActivity.IsZero() {
+		return false
+	}
+	return now.Sub(rec.LastActivity) >= autoStop
+}
+
+// HasLiveWatcher returns true if an idle watcher reported a heartbeat recently
+func (rec *ActivityRecord) HasLiveWatcher(now time.Time) bool {
+	return rec.WatcherPID != 0 && now.Sub(rec.WatcherHeartbeat) < IdleWatcherHeartbeatTimeout
+}
+
+// ClusterDataDir returns the host-side data directory for a cluster
+// (~/.kraze/clusters/<cluster-name>/), creating it if needed
+func ClusterDataDir(clusterName string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+
+	dir := filepath.Join(homeDir, ".kraze", "clusters", clusterName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create kraze directory: %w", err)
+	}
+	return dir, nil
+}
+
+// LoadActivity reads the activity record for a cluster.
+// Returns an empty record if none has been written yet.
+func LoadActivity(clusterName string) (*ActivityRecord, error) {
+	dir, err := ClusterDataDir(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, activityFileName))
+	if os.IsNotExist(err) {
+		return &ActivityRecord{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read activity record: %w", err)
+	}
+
+	var rec ActivityRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("failed to parse activity record: %w", err)
+	}
+	return &rec, nil
+}
+
+// SaveActivity writes the activity record for a cluster atomically
+func SaveActivity(clusterName string, rec *ActivityRecord) error {
+	dir, err := ClusterDataDir(clusterName)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal activity record: %w", err)
+	}
+
+	if err := writeFileAtomic(dir, activityFileName, data); err != nil {
+		return fmt.Errorf("failed to write activity record: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to dir/name via a temp file and rename so
+// concurrent readers never see a partially written file
+func writeFileAtomic(dir, name string, data []byte) error {
+	tmpFile, err := os.CreateTemp(dir, name+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile.Name(), filepath.Join(dir, name))
+}
+
+// lockActivity takes the cluster's activity lock and returns the function releasing it
+func lockActivity(dir string) (func(), error) {
+	file, err := os.OpenFile(filepath.Join(dir, activityLockFileName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open activity lock: %w", err)
+	}
+
+	deadline := time.Now().Add(activityLockTimeout)
+	for {
+		err := tryLockFile(file)
+		if err == nil {
+			return func() {
+				unlockFile(file)
+				file.Close()
+			}, nil
+		}
+		if !errors.Is(err, errLockHeld) || time.Now().After(deadline) {
+			file.Close()
+			return nil, fmt.Errorf("failed to lock activity record: %w", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// UpdateActivity changes the activity record of a cluster under its activity
+// lock: update gets the current record (an empty one if it's missing or
+// corrupt) and returns whether to save it
+func UpdateActivity(clusterName string, update func(rec *ActivityRecord) bool) error {
+	dir, err := ClusterDataDir(clusterName)
+	if err != nil {
+		return err
+	}
+	unlock, err := lockActivity(dir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	rec, err := LoadActivity(clusterName)
+	if err != nil {
+		// A corrupt record shouldn't block commands; start fresh
+		rec = &ActivityRecord{}
+	}
+	if !update(rec) {
+		return nil
+	}
+	return SaveActivity(clusterName, rec)
+}
+
+// RecordActivity marks the cluster as used now, resetting its idle timer
+func RecordActivity(clusterName string) error {
+	return UpdateActivity(clusterName, func(rec *ActivityRecord) bool {
+		rec.LastActivity = time.Now()
+		return true
+	})
+}
+
+// SetAutoStop configures the idle duration after which the cluster is stopped.
+// A zero duration disables auto-stop.
+func SetAutoStop(clusterName string, autoStop time.Duration) error {
+	return UpdateActivity(clusterName, func(rec *ActivityRecord) bool {
+		rec.LastActivity = time.Now()
+		rec.AutoStop = ""
+		if autoStop > 0 {
+			rec.AutoStop = autoStop.String()
+		}
+		return true
+	})
+}
+
+// SetStopped records whether kraze stopped the cluster on purpose
+func SetStopped(clusterName string, stopped bool) error {
+	return UpdateActivity(clusterName, func(rec *ActivityRecord) bool {
+		if rec.Stopped == stopped {
+			return false
+		}
+		rec.Stopped = stopped
+		return true
+	})
+}
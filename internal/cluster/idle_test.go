@@ -0,0 +1,171 @@
+package cluster
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestActivityRecordIsIdle(test *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		rec      ActivityRecord
+		expected bool
+	}{
+		{
+			name:     "auto-stop disabled",
+			rec:      ActivityRecord{LastActivity: now.Add(-10 * time.Hour)},
+			expected: false,
+		},
+		{
+			name:     "recent activity",
+			rec:      ActivityRecord{LastActivity: now.Add(-30 * time.Minute), AutoStop: "2h"},
+			expected: false,
+		},
+		{
+			name:     "idle past auto-stop",
+			rec:      ActivityRecord{LastActivity: now.Add(-3 * time.Hour), AutoStop: "2h"},
+			expected: true,
+		},
+		{
+			name:     "no activity recorded",
+			rec:      ActivityRecord{AutoStop: "2h"},
+			expected: false,
+		},
+		{
+			name:     "invalid auto-stop",
+			rec:      ActivityRecord{LastActivity: now.Add(-3 * time.Hour), AutoStop: "soon"},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			result := tt.rec.IsIdle(now)
+			if result != tt.expected {
+				test.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestActivityRecordHasLiveWatcher(test *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		rec      ActivityRecord
+		expected bool
+	}{
+		{
+			name:     "no watcher",
+			rec:      ActivityRecord{},
+			expected: false,
+		},
+		{
+			name:     "recent heartbeat",
+			rec:      ActivityRecord{WatcherPID: 1234, WatcherHeartbeat: now.Add(-time.Minute)},
+			expected: true,
+		},
+		{
+			name:     "stale heartbeat",
+			rec:      ActivityRecord{WatcherPID: 1234, WatcherHeartbeat: now.Add(-time.Hour)},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			result := tt.rec.HasLiveWatcher(now)
+			if result != tt.expected {
+				test.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestActivityRoundTrip(test *testing.T) {
+	test.Setenv("HOME", test.TempDir())
+
+	rec, err := LoadActivity("idle-test")
+	if err != nil {
+		test.Fatalf("LoadActivity failed: %v", err)
+	}
+	if !rec.LastActivity.IsZero() || rec.AutoStop != "" {
+		test.Fatalf("Expected empty record, got %+v", rec)
+	}
+
+	if err := SetAutoStop("idle-test", 2*time.Hour); err != nil {
+		test.Fatalf("SetAutoStop failed: %v", err)
+	}
+	if err := RecordActivity("idle-test"); err != nil {
+		test.Fatalf("RecordActivity failed: %v", err)
+	}
+
+	rec, err = LoadActivity("idle-test")
+	if err != nil {
+		test.Fatalf("LoadActivity failed: %v", err)
+	}
+	if rec.GetAutoStop() != 2*time.Hour {
+		test.Errorf("Expected auto-stop 2h, got %v", rec.GetAutoStop())
+	}
+	if time.Since(rec.LastActivity) > time.Minute {
+		test.Errorf("Expected recent activity, got %v", rec.LastActivity)
+	}
+
+	if err := SetAutoStop("idle-test", 0); err != nil {
+		test.Fatalf("SetAutoStop failed: %v", err)
+	}
+	rec, _ = LoadActivity("idle-test")
+	if rec.GetAutoStop() != 0 {
+		test.Errorf("Expected auto-stop disabled, got %v", rec.GetAutoStop())
+	}
+}
+
+func TestSetStopped(test *testing.T) {
+	test.Setenv("HOME", test.TempDir())
+
+	if err := SetStopped("idle-test", true); err != nil {
+		test.Fatalf("SetStopped failed: %v", err)
+	}
+	// Recording activity keeps the marker
+	if err := RecordActivity("idle-test"); err != nil {
+		test.Fatalf("RecordActivity failed: %v", err)
+	}
+	if rec, _ := LoadActivity("idle-test"); !rec.Stopped {
+		test.Error("Expected the cluster to be marked stopped")
+	}
+
+	if err := SetStopped("idle-test", false); err != nil {
+		test.Fatalf("SetStopped failed: %v", err)
+	}
+	if rec, _ := LoadActivity("idle-test"); rec.Stopped {
+		test.Error("Expected the stopped marker to be cleared")
+	}
+}
+
+func TestUpdateActivitySerializes(test *testing.T) {
+	test.Setenv("HOME", test.TempDir())
+
+	// Without the activity lock, concurrent read-modify-writes would lose updates
+	const updates = 20
+	var group sync.WaitGroup
+	for range updates {
+		group.Go(func() {
+			err := UpdateActivity("idle-test", func(rec *ActivityRecord) bool {
+				rec.WatcherPID++
+				return true
+			})
+			if err != nil {
+				test.Errorf("UpdateActivity failed: %v", err)
+			}
+		})
+	}
+	group.Wait()
+
+	if rec, _ := LoadActivity("idle-test"); rec.WatcherPID != updates {
+		test.Errorf("Expected %d updates, got %d", updates, rec.WatcherPID)
+	}
+}
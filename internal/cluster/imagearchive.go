@@ -0,0 +1,67 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/config"
+	"sigs.k8s.io/kind/pkg/apis/config/defaults"
+)
+
+// NodeImage returns the node image a new cluster uses, kind's default when the
+// config doesn't pick one
+func (kind *KindManager) NodeImage(cfg *config.ClusterConfig) string {
+	if image := kind.getNodeImage(cfg); image != "" {
+		return image
+	}
+	return defaults.Image
+}
+
+// SaveImages writes the local images to one archive, so the layers they share
+// are stored once
+func SaveImages(ctx context.Context, archivePath string, imageRefs []string) error {
+	args := append([]string{"save", "-o", archivePath}, imageRefs...)
+	if output, err := runtimeCommandContext(ctx, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to save %s: %w (output: %s)", describeImages(imageRefs), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// LoadImageArchive loads the images of an archive written by SaveImages into
+// the local daemon
+func LoadImageArchive(ctx context.Context, archivePath string) error {
+	if output, err := runtimeCommandContext(ctx, "load", "-i", archivePath).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to load images from %s: %w (output: %s)", archivePath, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// TagWithoutDigest tags a local image pinned by digest with its tag alone and
+// returns that reference. Saved archives keep tags but not registry digests,
+// so an image is only found by digest again after a pull. Images without a
+// digest are returned as is.
+func TagWithoutDigest(ctx context.Context, imageRef string) (string, error) {
+	tagged := digestlessRef(imageRef)
+	if tagged == imageRef {
+		return imageRef, nil
+	}
+	if output, err := runtimeCommandContext(ctx, "tag", imageRef, tagged).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to tag %s as %s: %w (output: %s)", imageRef, tagged, err, strings.TrimSpace(string(output)))
+	}
+	return tagged, nil
+}
+
+// digestlessRef drops the digest of an image reference, keeping its tag, or
+// tagging it "bundle" when it has none
+func digestlessRef(imageRef string) string {
+	name, _, pinned := strings.Cut(imageRef, "@")
+	if !pinned {
+		return imageRef
+	}
+	// A colon after the last slash starts the tag, one before it a registry port
+	if strings.LastIndex(name, ":") <= strings.LastIndex(name, "/") {
+		return name + ":bundle"
+	}
+	return name
+}
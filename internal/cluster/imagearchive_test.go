@@ -0,0 +1,26 @@
+package cluster
+
+import "testing"
+
+func TestDigestlessRef(test *testing.T) {
+	digest := "@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	tests := []struct {
+		name  string
+		image string
+		want  string
+	}{
+		{name: "tag only", image: "kindest/node:v1.35.0", want: "kindest/node:v1.35.0"},
+		{name: "tag and digest", image: "kindest/node:v1.35.0" + digest, want: "kindest/node:v1.35.0"},
+		{name: "digest only", image: "kindest/node" + digest, want: "kindest/node:bundle"},
+		{name: "registry port without tag", image: "localhost:5000/node" + digest, want: "localhost:5000/node:bundle"},
+		{name: "registry port with tag", image: "localhost:5000/node:v1" + digest, want: "localhost:5000/node:v1"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := digestlessRef(tt.image); got != tt.want {
+				test.Errorf("digestlessRef(%q) = %q, want %q", tt.image, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kind/pkg/cluster/images/layeraware"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+)
+
+// maxParallelNodeLoads bounds how many nodes read one shared image stream
+const maxParallelNodeLoads = 4
+
+// errAllNodesFailed stops an image stream once no node is reading it
+var errAllNodesFailed = errors.New("image load failed on every node")
+
+// ClusterImageName returns the name containerd in kind nodes uses for an
+// image, adding the docker.io prefix to Docker Hub images
+func ClusterImageName(imageName string) string {
+	ref := ParseImageReference(imageName)
+	if !ref.IsDockerHub() || strings.HasPrefix(imageName, "docker.io/") {
+		return imageName
+	}
+	// Official images live under docker.io/library/
+	if !strings.Contains(imageName, "/") {
+		return "docker.io/library/" + imageName
+	}
+	return "docker.io/" + imageName
+}
+
+// imageDigests identifies an image by its ID followed by the digests of the
+// manifests it was pulled or loaded by. Docker's classic store, Podman and
+// crictl report the config digest as the ID, while Docker's containerd store
+// reports the manifest digest, which crictl lists among the repo digests of a
+// loaded image, so two copies are the same image when any digest matches.
+type imageDigests []string
+
+// newImageDigests normalizes an image ID and repo digests ("name@sha256:...")
+// to sha256: digests, or returns nil for an image without an ID
+func newImageDigests(id string, repoDigests []string) imageDigests {
+	if id == "" {
+		return nil
+	}
+	digests := imageDigests{normalizeDigest(id)}
+	for _, repoDigest := range repoDigests {
+		if _, digest, found := strings.Cut(repoDigest, "@"); found {
+			digests = append(digests, normalizeDigest(digest))
+		}
+	}
+	return digests
+}
+
+// normalizeDigest adds the sha256: prefix Podman leaves out of image IDs
+func normalizeDigest(digest string) string {
+	if strings.Contains(digest, ":") {
+		return digest
+	}
+	return "sha256:" + digest
+}
+
+// sameImage returns whether two copies share a config or manifest digest
+func (digests imageDigests) sameImage(other imageDigests) bool {
+	for _, digest := range digests {
+		if slices.Contains(other, digest) {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeImageDigests returns the digests of an image in a node's containerd, or
+// nil if the node doesn't have it
+func nodeImageDigests(ctx context.Context, containerName, imageName string) (imageDigests, error) {
+	output, err := runtimeCommandContext(ctx, "exec", containerName, "crictl", "inspecti", ClusterImageName(imageName)).Output()
+	if err != nil {
+		// Image doesn't exist in the node
+		return nil, nil
+	}
+	return parseNodeImageDigests(output)
+}
+
+// parseNodeImageDigests parses the JSON output of `crictl inspecti`
+func parseNodeImageDigests(output []byte) (imageDigests, error) {
+	var inspectData struct {
+		Status struct {
+			ID          string   `json:"id"`
+			RepoDigests []string `json:"repoDigests"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(output, &inspectData); err != nil {
+		return nil, fmt.Errorf("failed to parse crictl inspecti output: %w", err)
+	}
+	return newImageDigests(inspectData.Status.ID, inspectData.Status.RepoDigests), nil
+}
+
+// localImageDigests returns the digests of an image in the local container runtime
+func localImageDigests(ctx context.Context, imageRef string) (imageDigests, error) {
+	output, err := runtimeCommandContext(ctx, "image", "inspect", "--format", "{{.Id}}{{range .RepoDigests}} {{.}}{{end}}", imageRef).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect image '%s': %w", imageRef, err)
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("failed to inspect image '%s': no image ID", imageRef)
+	}
+	return newImageDigests(fields[0], fields[1:]), nil
+}
+
+// selectNodes returns the nodes with the given names. Kind names each node's
+// container after its Kubernetes node.
+func selectNodes(allNodes []nodes.Node, names []string) []nodes.Node {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	var selected []nodes.Node
+	for _, node := range allNodes {
+		if wanted[node.String()] {
+			selected = append(selected, node)
+		}
+	}
+	return selected
+}
+
+// nodesMissingImage returns the nodes that don't already have the local image
+func nodesMissingImage(ctx context.Context, allNodes []nodes.Node, imageRef string) []nodes.Node {
+	local, err := localImageDigests(ctx, imageRef)
+	if err != nil {
+		// Without the local digests nothing can be skipped
+		return allNodes
+	}
+
+	var missing []nodes.Node
+	for _, node := range allNodes {
+		nodeDigests, err := nodeImageDigests(ctx, node.String(), imageRef)
+		if err != nil || !local.sameImage(nodeDigests) {
+			missing = append(missing, node)
+		}
+	}
+	return missing
+}
+
+// nodeImageGroup is a set of nodes missing the same images
+type nodeImageGroup struct {
+	images []string
+	nodes  []nodes.Node
+}
+
+// groupNodesByMissingImages groups the nodes by the images they don't already
+// have, leaving out nodes that have them all
+func groupNodesByMissingImages(ctx context.Context, allNodes []nodes.Node, imageRefs []string) []nodeImageGroup {
+	missing := make(map[string][]string, len(allNodes))
+	for _, imageRef := range imageRefs {
+		for _, node := range nodesMissingImage(ctx, allNodes, imageRef) {
+			missing[node.String()] = append(missing[node.String()], imageRef)
+		}
+	}
+	return groupNodesByImages(allNodes, missing)
+}
+
+// groupNodesByImages groups nodes with the same images, keeping the order of
+// the nodes and skipping those without images
+func groupNodesByImages(allNodes []nodes.Node, nodeImages map[string][]string) []nodeImageGroup {
+	var groups []nodeImageGroup
+	groupIndex := make(map[string]int)
+	for _, node := range allNodes {
+		images := nodeImages[node.String()]
+		if len(images) == 0 {
+			continue
+		}
+		key := strings.Join(images, "\n")
+		if idx, exists := groupIndex[key]; exists {
+			groups[idx].nodes = append(groups[idx].nodes, node)
+			continue
+		}
+		groupIndex[key] = len(groups)
+		groups = append(groups, nodeImageGroup{images: images, nodes: []nodes.Node{node}})
+	}
+	return groups
+}
+
+// describeImages names the images of a save in errors
+func describeImages(imageRefs []string) string {
+	if len(imageRefs) == 1 {
+		return fmt.Sprintf("image '%s'", imageRefs[0])
+	}
+	return fmt.Sprintf("images '%s'", strings.Join(imageRefs, "', '"))
+}
+
+// loadImagesToNodes saves the images to one temp archive and loads it onto
+// the nodes. Nodes that already hold some of the images' layers get only the
+// missing layers, while the others share a single read of the archive, in
+// batches of concurrent loads.
+func loadImagesToNodes(ctx context.Context, imageRefs []string, targets []nodes.Node) error {
+	tmpDir, err := os.MkdirTemp("", "kind-image-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	imageTar := filepath.Join(tmpDir, "image.tar")
+	args := append([]string{"save", "-o", imageTar}, imageRefs...)
+	if err := runtimeCommandContext(ctx, args...).Run(); err != nil {
+		return fmt.Errorf("failed to save %s: %w (make sure the images exist locally)", describeImages(imageRefs), err)
+	}
+
+	metadata, err := layeraware.InspectArchive(imageTar)
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s: %w", describeImages(imageRefs), err)
+	}
+	var fresh []nodes.Node
+	var plans []*layeraware.TransferPlan
+	for _, node := range targets {
+		plan, err := layeraware.PlanTransfer(metadata, node)
+		if err != nil || len(plan.ExistingBlobs) == 0 {
+			fresh = append(fresh, node)
+			continue
+		}
+		plans = append(plans, plan)
+	}
+
+	for start := 0; start < len(fresh); start += maxParallelNodeLoads {
+		end := min(start+maxParallelNodeLoads, len(fresh))
+		if err := streamArchive(imageTar, fresh[start:end], nodeutils.LoadImageArchive); err != nil {
+			return fmt.Errorf("failed to load %s: %w", describeImages(imageRefs), err)
+		}
+	}
+
+	errs := make([]error, len(plans))
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, maxParallelNodeLoads)
+	for idx, plan := range plans {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+			if err := layeraware.ExecuteTransfer(imageTar, plan, nil); err != nil {
+				errs[idx] = fmt.Errorf("failed to load %s onto node %s: %w", describeImages(imageRefs), plan.Node.String(), err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// streamArchive reads an image archive once for the loads of a batch of nodes
+func streamArchive(archivePath string, batch []nodes.Node, load func(nodes.Node, io.Reader) error) error {
+	archive, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open image archive: %w", err)
+	}
+	defer archive.Close()
+	return fanOutImage(archive, batch, load)
+}
+
+// fanOutImage copies an image archive to a load per node, returning the first
+// node failure once every load has finished
+func fanOutImage(archive io.Reader, batch []nodes.Node, load func(nodes.Node, io.Reader) error) error {
+	writers := make([]*io.PipeWriter, len(batch))
+	loadErrs := make([]error, len(batch))
+	var wg sync.WaitGroup
+	for idx, node := range batch {
+		reader, writer := io.Pipe()
+		writers[idx] = writer
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			loadErrs[idx] = load(node, reader)
+			// Unblock the writer if the load stopped reading early
+			reader.CloseWithError(fmt.Errorf("load on node %s ended", node.String()))
+		}()
+	}
+
+	_, copyErr := io.Copy(&fanOutWriter{writers: writers, failed: make([]bool, len(writers))}, archive)
+	for _, writer := range writers {
+		writer.Close()
+	}
+	wg.Wait()
+
+	for idx, err := range loadErrs {
+		if err != nil {
+			return fmt.Errorf("failed to load image onto node %s: %w", batch[idx].String(), err)
+		}
+	}
+	if copyErr != nil && !errors.Is(copyErr, errAllNodesFailed) {
+		return fmt.Errorf("failed to stream image: %w", copyErr)
+	}
+	return nil
+}
+
+// fanOutWriter writes to every pipe that is still being read. Unlike
+// io.MultiWriter, one node failing doesn't stop the stream to the others.
+type fanOutWriter struct {
+	writers []*io.PipeWriter
+	failed  []bool
+}
+
+func (fan *fanOutWriter) Write(data []byte) (int, error) {
+	active := 0
+	for idx, writer := range fan.writers {
+		if fan.failed[idx] {
+			continue
+		}
+		if _, err := writer.Write(data); err != nil {
+			fan.failed[idx] = true
+			continue
+		}
+		active++
+	}
+	if active == 0 {
+		return 0, errAllNodesFailed
+	}
+	return len(data), nil
+}
@@ -0,0 +1,223 @@
+package cluster
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+)
+
+// fakeNode is a kind node that only has a name
+type fakeNode struct {
+	nodes.Node
+	name string
+}
+
+func (node fakeNode) String() string {
+	return node.name
+}
+
+func TestClusterImageName(test *testing.T) {
+	tests := []struct {
+		image    string
+		expected string
+	}{
+		{image: "nginx:1.27", expected: "docker.io/library/nginx:1.27"},
+		{image: "bitnami/redis:7", expected: "docker.io/bitnami/redis:7"},
+		{image: "docker.io/library/nginx:1.27", expected: "docker.io/library/nginx:1.27"},
+		{image: "ghcr.io/org/app:v1", expected: "ghcr.io/org/app:v1"},
+		{image: "localhost:5000/app:dev", expected: "localhost:5000/app:dev"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.image, func(test *testing.T) {
+			if got := ClusterImageName(tt.image); got != tt.expected {
+				test.Errorf("ClusterImageName(%q) = %q, want %q", tt.image, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSameImage(test *testing.T) {
+	tests := []struct {
+		name     string
+		local    imageDigests
+		node     imageDigests
+		expected bool
+	}{
+		{name: "docker and crictl ids", local: newImageDigests("sha256:abc", nil), node: newImageDigests("sha256:abc", nil), expected: true},
+		{name: "podman id without prefix", local: newImageDigests("abc", nil), node: newImageDigests("sha256:abc", nil), expected: true},
+		{
+			name:     "containerd store manifest digest",
+			local:    newImageDigests("sha256:manifest", []string{"nginx@sha256:manifest"}),
+			node:     newImageDigests("sha256:config", []string{"docker.io/library/import-2026-10-18@sha256:manifest"}),
+			expected: true,
+		},
+		{
+			name:     "pulled image repo digest",
+			local:    newImageDigests("sha256:config", []string{"nginx@sha256:manifest"}),
+			node:     newImageDigests("sha256:other", []string{"docker.io/library/nginx@sha256:manifest"}),
+			expected: true,
+		},
+		{name: "different ids", local: newImageDigests("sha256:abc", nil), node: newImageDigests("sha256:def", nil)},
+		{name: "missing on node", local: newImageDigests("sha256:abc", nil), node: newImageDigests("", nil)},
+		{name: "both empty", local: newImageDigests("", nil), node: newImageDigests("", nil)},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := tt.local.sameImage(tt.node); got != tt.expected {
+				test.Errorf("sameImage(%v, %v) = %v, want %v", tt.local, tt.node, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseNodeImageDigests(test *testing.T) {
+	output := `{"status": {"id": "sha256:config", "repoTags": ["docker.io/library/nginx:1.27"], "repoDigests": ["docker.io/library/nginx@sha256:manifest"]}}`
+	digests, err := parseNodeImageDigests([]byte(output))
+	if err != nil {
+		test.Fatalf("parseNodeImageDigests() error: %v", err)
+	}
+	if strings.Join(digests, ",") != "sha256:config,sha256:manifest" {
+		test.Errorf("parseNodeImageDigests() = %v, want the ID and the manifest digest", digests)
+	}
+}
+
+func TestSelectNodes(test *testing.T) {
+	all := []nodes.Node{fakeNode{name: "kraze-control-plane"}, fakeNode{name: "kraze-worker"}, fakeNode{name: "kraze-worker2"}}
+
+	tests := []struct {
+		name     string
+		names    []string
+		expected []string
+	}{
+		{name: "one worker", names: []string{"kraze-worker2"}, expected: []string{"kraze-worker2"}},
+		{name: "keeps cluster order", names: []string{"kraze-worker2", "kraze-control-plane"}, expected: []string{"kraze-control-plane", "kraze-worker2"}},
+		{name: "unknown node", names: []string{"other-worker"}},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			var got []string
+			for _, node := range selectNodes(all, tt.names) {
+				got = append(got, node.String())
+			}
+			if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				test.Errorf("selectNodes(%v) = %v, want %v", tt.names, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGroupNodesByImages(test *testing.T) {
+	all := []nodes.Node{fakeNode{name: "control-plane"}, fakeNode{name: "worker"}, fakeNode{name: "worker2"}, fakeNode{name: "worker3"}}
+	nodeImages := map[string][]string{
+		"control-plane": {"api:dev", "worker:dev"},
+		"worker":        {"api:dev"},
+		"worker2":       {"api:dev", "worker:dev"},
+	}
+
+	var got []string
+	for _, group := range groupNodesByImages(all, nodeImages) {
+		var names []string
+		for _, node := range group.nodes {
+			names = append(names, node.String())
+		}
+		got = append(got, strings.Join(group.images, "+")+"="+strings.Join(names, ","))
+	}
+
+	expected := []string{"api:dev+worker:dev=control-plane,worker2", "api:dev=worker"}
+	if strings.Join(got, " ") != strings.Join(expected, " ") {
+		test.Errorf("groupNodesByImages() = %v, want %v", got, expected)
+	}
+}
+
+func TestFanOutImage(test *testing.T) {
+	// Larger than a single copy buffer so failures land mid-stream
+	archive := bytes.Repeat([]byte("layer"), 64*1024)
+	batch := []nodes.Node{fakeNode{name: "control-plane"}, fakeNode{name: "worker"}, fakeNode{name: "worker2"}}
+
+	tests := []struct {
+		name      string
+		failNodes map[string]bool
+		expectErr string
+	}{
+		{name: "all nodes load"},
+		{name: "one node fails", failNodes: map[string]bool{"worker": true}, expectErr: "failed to load image onto node worker"},
+		{name: "every node fails", failNodes: map[string]bool{"control-plane": true, "worker": true, "worker2": true}, expectErr: "failed to load image onto node control-plane"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			var mu sync.Mutex
+			received := make(map[string][]byte)
+			load := func(node nodes.Node, reader io.Reader) error {
+				if tt.failNodes[node.String()] {
+					// Read a little, then give up like a failed import would
+					_, _ = reader.Read(make([]byte, 16))
+					return errors.New("ctr import failed")
+				}
+				data, err := io.ReadAll(reader)
+				mu.Lock()
+				received[node.String()] = data
+				mu.Unlock()
+				return err
+			}
+
+			err := fanOutImage(bytes.NewReader(archive), batch, load)
+			if tt.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+					test.Fatalf("fanOutImage() error = %v, want %q", err, tt.expectErr)
+				}
+			} else if err != nil {
+				test.Fatalf("fanOutImage() error = %v", err)
+			}
+
+			for _, node := range batch {
+				if tt.failNodes[node.String()] {
+					continue
+				}
+				if !bytes.Equal(received[node.String()], archive) {
+					test.Errorf("node %s received %d bytes, want %d", node.String(), len(received[node.String()]), len(archive))
+				}
+			}
+		})
+	}
+}
+
+func TestStreamArchive(test *testing.T) {
+	archivePath := filepath.Join(test.TempDir(), "image.tar")
+	if err := os.WriteFile(archivePath, []byte("image archive"), 0o644); err != nil {
+		test.Fatal(err)
+	}
+	batch := []nodes.Node{fakeNode{name: "worker"}, fakeNode{name: "worker2"}}
+
+	var mu sync.Mutex
+	received := make(map[string]string)
+	err := streamArchive(archivePath, batch, func(node nodes.Node, reader io.Reader) error {
+		data, err := io.ReadAll(reader)
+		mu.Lock()
+		received[node.String()] = string(data)
+		mu.Unlock()
+		return err
+	})
+	if err != nil {
+		test.Fatalf("streamArchive() error: %v", err)
+	}
+	for _, node := range batch {
+		if received[node.String()] != "image archive" {
+			test.Errorf("node %s received %q, want the archive", node.String(), received[node.String()])
+		}
+	}
+
+	if err := streamArchive(filepath.Join(test.TempDir(), "missing.tar"), batch, nil); err == nil {
+		test.Error("expected an error for a missing archive")
+	}
+}
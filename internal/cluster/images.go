@@ -8,9 +8,9 @@ import (
 	"io"
 	"log/slog"
 	"os"
-	osexec "os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/hjames9/kraze/internal/config"
@@ -41,6 +41,7 @@ type ImageReference struct {
 type ImageInfo struct {
 	Reference     ImageReference
 	SHA256        string // Image digest/hash from Docker
+	ID            string // Image ID in the local daemon
 	IsLocal       bool   // True if image was built locally (no RepoDigests — never pushed to a registry)
 	InLocalDaemon bool   // True if image physically exists in the local Docker daemon (built or pulled)
 	Size          int64  // Image size in bytes
@@ -143,7 +144,7 @@ func (im *ImageManager) GetImageInfo(ctx context.Context, imageName string) (*Im
 	}
 
 	// Check if image exists locally using docker inspect
-	cmd := osexec.CommandContext(ctx, "docker", "inspect", imageName)
+	cmd := runtimeCommandContext(ctx, "inspect", imageName)
 	output, err := cmd.Output()
 
 	if err != nil {
@@ -171,10 +172,12 @@ func (im *ImageManager) GetImageInfo(ctx context.Context, imageName string) (*Im
 		// Extract SHA256 from ID (format: sha256:abc123...)
 		if inspectData[0].ID != "" {
 			info.SHA256 = inspectData[0].ID
+			info.ID = inspectData[0].ID
 		}
 
-		// If we have repo digests, use the first one
-		if len(inspectData[0].RepoDigests) > 0 {
+		// If we have repo digests, use the first one. Podman records a localhost/
+		// digest for images it built, which doesn't mean they came from a registry.
+		if len(inspectData[0].RepoDigests) > 0 && !strings.HasPrefix(inspectData[0].RepoDigests[0], "localhost/") {
 			digestParts := strings.SplitN(inspectData[0].RepoDigests[0], "@", 2)
 			if len(digestParts) == 2 {
 				info.SHA256 = digestParts[1]
@@ -191,62 +194,19 @@ func (im *ImageManager) GetImageInfo(ctx context.Context, imageName string) (*Im
 	return info, nil
 }
 
-// GetClusterImageHash retrieves the SHA256 hash of an image loaded in the cluster
-// Returns empty string if image is not found in the cluster
-func (im *ImageManager) GetClusterImageHash(ctx context.Context, clusterName, imageName string) (string, error) {
-	// Normalize image name for cluster lookup
-	// crictl uses docker.io/ prefix for Docker Hub images
-	ref := ParseImageReference(imageName)
-	clusterImageName := imageName
-
-	// Add docker.io prefix if it's a Docker Hub image without explicit registry
-	if ref.IsDockerHub() && !strings.HasPrefix(imageName, "docker.io/") {
-		// If it's library/* (official images), use docker.io/library/
-		if !strings.Contains(imageName, "/") {
-			clusterImageName = "docker.io/library/" + imageName
-		} else {
-			clusterImageName = "docker.io/" + imageName
-		}
-	}
-
-	// Get the control plane container name
-	containerName := clusterName + "-control-plane"
-
-	// Check if image exists in cluster using crictl inspecti (inspect image, not container)
-	cmd := osexec.CommandContext(ctx, "docker", "exec", containerName, "crictl", "inspecti", clusterImageName)
-	output, err := cmd.Output()
-
-	if err != nil {
-		// Image doesn't exist in cluster
-		return "", nil
-	}
-
-	// Parse crictl inspecti output to get image ID
-	var inspectData struct {
-		Status struct {
-			ID string `json:"id"`
-		} `json:"status"`
-	}
-
-	if err := json.Unmarshal(output, &inspectData); err != nil {
-		return "", fmt.Errorf("failed to parse crictl inspecti output: %w", err)
-	}
-
-	return inspectData.Status.ID, nil
-}
-
 // ClusterImage represents an image loaded in a kind cluster node
 type ClusterImage struct {
 	ID       string
 	RepoTags []string
 	Size     string
+	Pinned   bool // Kept by the kubelet's image garbage collection, like the pause image
 }
 
 // ListClusterImages returns all images currently loaded in the kind cluster
 func (im *ImageManager) ListClusterImages(ctx context.Context, clusterName string) ([]ClusterImage, error) {
 	containerName := clusterName + "-control-plane"
 
-	cmd := osexec.CommandContext(ctx, "docker", "exec", containerName, "crictl", "images", "-o", "json")
+	cmd := runtimeCommandContext(ctx, "exec", containerName, "crictl", "images", "-o", "json")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list cluster images: %w", err)
@@ -262,6 +222,7 @@ func parseClusterImages(output []byte) ([]ClusterImage, error) {
 			ID       string   `json:"id"`
 			RepoTags []string `json:"repoTags"`
 			Size     string   `json:"size"`
+			Pinned   bool     `json:"pinned"`
 		} `json:"images"`
 	}
 
@@ -275,6 +236,7 @@ func parseClusterImages(output []byte) ([]ClusterImage, error) {
 			ID:       img.ID,
 			RepoTags: img.RepoTags,
 			Size:     img.Size,
+			Pinned:   img.Pinned,
 		})
 	}
 	return images, nil
@@ -325,6 +287,16 @@ func (im *ImageManager) ExtractImagesFromValues(valuesPath string) ([]string, er
 	return images, nil
 }
 
+// extractImagesFromServiceValues extracts image references from one of a
+// service's values files, after expanding its ${NAME} references
+func (im *ImageManager) extractImagesFromServiceValues(svc *config.ServiceConfig, valuesPath string) ([]string, error) {
+	data, err := svc.ReadValuesFile(valuesPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read values file: %w", err)
+	}
+	return im.ExtractImagesFromYAMLString(string(data))
+}
+
 // extractImagesRecursive recursively searches for image definitions in values
 func (im *ImageManager) extractImagesRecursive(data interface{}, images *[]string) {
 	switch v := data.(type) {
@@ -440,7 +412,7 @@ func (im *ImageManager) ExtractImagesFromHelmChart(ctx context.Context, svc *con
 	vals := make(map[string]interface{})
 	if !svc.Values.IsEmpty() {
 		for _, valuesFile := range svc.Values.Files() {
-			valuesData, err := os.ReadFile(valuesFile)
+			valuesData, err := svc.ReadValuesFile(valuesFile)
 			if err != nil {
 				return nil, fmt.Errorf("failed to read values file %s: %w", valuesFile, err)
 			}
@@ -657,7 +629,7 @@ func (im *ImageManager) extractImagesFromLocalChart(svc *config.ServiceConfig) (
 
 	if !svc.Values.IsEmpty() {
 		for _, valuesFile := range svc.Values.Files() {
-			data, err := os.ReadFile(valuesFile)
+			data, err := svc.ReadValuesFile(valuesFile)
 			if err != nil {
 				if im.verbose {
 					fmt.Printf("Warning: Failed to read values file %s: %v\n", valuesFile, err)
@@ -838,7 +810,7 @@ func (im *ImageManager) GetImagesForService(ctx context.Context, svc *config.Ser
 
 		// Method 1: Extract from inline values
 		if svc.ValuesInline != "" {
-			inlineImages, err := im.ExtractImagesFromYAMLString(svc.ValuesInline)
+			inlineImages, err := im.ExtractImagesFromYAMLString(svc.Build.SubstituteImage(svc.ValuesInline))
 			if err != nil {
 				if im.verbose {
 					fmt.Printf("Warning: Failed to extract images from inline values: %v\n", err)
@@ -851,7 +823,7 @@ func (im *ImageManager) GetImagesForService(ctx context.Context, svc *config.Ser
 		// Method 2: Extract from values files
 		if !svc.Values.IsEmpty() {
 			for _, valuesFile := range svc.Values.Files() {
-				valuesImages, err := im.ExtractImagesFromValues(valuesFile)
+				valuesImages, err := im.extractImagesFromServiceValues(svc, valuesFile)
 				if err != nil {
 					if im.verbose {
 						fmt.Printf("Warning: Failed to extract images from values file %s: %v\n", valuesFile, err)
@@ -910,6 +882,13 @@ func (im *ImageManager) GetImagesForService(ctx context.Context, svc *config.Ser
 	// automatic extraction cannot reach.
 	images = append(images, svc.Images...)
 
+	// The image built from the service's build block is always loaded, in
+	// place of the ko:// references a ko build replaces
+	if svc.Build != nil {
+		images = slices.DeleteFunc(images, config.IsKoReference)
+		images = append(images, svc.Build.Image)
+	}
+
 	// Deduplicate
 	images = DeduplicateImages(images)
 
@@ -0,0 +1,235 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// States of an image in a kind cluster, as reported by ClusterImageState
+const (
+	ImageCurrent = "current" // The nodes that have the image have the local version
+	ImageStale   = "stale"   // A node has a different version than the local daemon
+	ImageMissing = "missing" // No node has the image
+	ImagePresent = "present" // Nodes have the image, which isn't in the local daemon to compare with
+)
+
+// protectedImagePrefixes are the images kind bakes into its node image. Some,
+// like the local-path provisioner's helper, only run now and then, and nodes
+// without registry access couldn't pull them back, so prune never removes them.
+var protectedImagePrefixes = []string{"docker.io/kindest/", "registry.k8s.io/"}
+
+// ClusterImageState compares an image in the local daemon with the copies in
+// the nodes of a kind cluster
+func (kind *KindManager) ClusterImageState(ctx context.Context, clusterName, imageName string) (string, error) {
+	nodes, err := kind.provider.ListInternalNodes(clusterName)
+	if err != nil {
+		return "", fmt.Errorf("failed to list cluster nodes: %w", err)
+	}
+
+	// An image not in the local daemon is only looked for in the nodes
+	local, err := localImageDigests(ctx, imageName)
+	if err != nil {
+		local = nil
+	}
+
+	copies := make([]imageDigests, 0, len(nodes))
+	for _, node := range nodes {
+		nodeDigests, err := nodeImageDigests(ctx, node.String(), imageName)
+		if err != nil {
+			return "", err
+		}
+		copies = append(copies, nodeDigests)
+	}
+	return imageState(local, copies), nil
+}
+
+// imageState decides the state of an image from its local digests (nil when
+// it isn't local) and its digests in each node (nil when the node doesn't
+// have it). Images loaded onto some of the nodes only are current, since
+// kraze places images on the nodes their pods can run on.
+func imageState(local imageDigests, copies []imageDigests) string {
+	found := false
+	for _, nodeDigests := range copies {
+		if nodeDigests == nil {
+			continue
+		}
+		found = true
+		if local != nil && !local.sameImage(nodeDigests) {
+			return ImageStale
+		}
+	}
+	switch {
+	case !found:
+		return ImageMissing
+	case local == nil:
+		return ImagePresent
+	default:
+		return ImageCurrent
+	}
+}
+
+// NodesImageState compares an image in the local daemon with its copies on
+// the named nodes of a kind cluster, or on every node when nodeNames is empty.
+// Unlike ClusterImageState, the image is only current when every one of the
+// nodes has the local version, and missing when any of them lacks it.
+func (kind *KindManager) NodesImageState(ctx context.Context, clusterName, imageName string, nodeNames []string) (string, error) {
+	nodes, err := kind.provider.ListInternalNodes(clusterName)
+	if err != nil {
+		return "", fmt.Errorf("failed to list cluster nodes: %w", err)
+	}
+	if len(nodeNames) > 0 {
+		nodes = selectNodes(nodes, nodeNames)
+	}
+
+	local, err := localImageDigests(ctx, imageName)
+	if err != nil {
+		return "", err
+	}
+	copies := make([]imageDigests, 0, len(nodes))
+	for _, node := range nodes {
+		nodeDigests, err := nodeImageDigests(ctx, node.String(), imageName)
+		if err != nil {
+			return "", err
+		}
+		copies = append(copies, nodeDigests)
+	}
+	return nodesImageState(local, copies), nil
+}
+
+// nodesImageState decides the state of a local image from its digests in
+// each node it should be on (nil when the node doesn't have it)
+func nodesImageState(local imageDigests, copies []imageDigests) string {
+	missing := len(copies) == 0
+	for _, nodeDigests := range copies {
+		if nodeDigests == nil {
+			missing = true
+			continue
+		}
+		if !local.sameImage(nodeDigests) {
+			return ImageStale
+		}
+	}
+	if missing {
+		return ImageMissing
+	}
+	return ImageCurrent
+}
+
+// PrunedImage is an image removed from a node by PruneImages
+type PrunedImage struct {
+	Node     string
+	ID       string
+	RepoTags []string
+	Size     int64
+}
+
+// PruneImages removes the images in the nodes of a kind cluster that no
+// container uses and that aren't among keep, which names images the way the
+// config does. This includes the untagged copies left behind each time a
+// changed image is reloaded. Pinned images and the images kind ships with are
+// always kept. With dryRun the images are only returned.
+func (kind *KindManager) PruneImages(ctx context.Context, clusterName string, keep []string, dryRun bool) ([]PrunedImage, error) {
+	nodes, err := kind.provider.ListInternalNodes(clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster nodes: %w", err)
+	}
+
+	keepNames := make(map[string]bool, len(keep))
+	for _, image := range keep {
+		keepNames[ClusterImageName(image)] = true
+	}
+
+	var pruned []PrunedImage
+	for _, node := range nodes {
+		output, err := nodeExec(ctx, node.String(), "crictl", "images", "-o", "json")
+		if err != nil {
+			return pruned, fmt.Errorf("failed to list images on node %s: %w", node.String(), err)
+		}
+		images, err := parseClusterImages([]byte(output))
+		if err != nil {
+			return pruned, err
+		}
+		output, err = nodeExec(ctx, node.String(), "crictl", "ps", "-a", "-o", "json")
+		if err != nil {
+			return pruned, fmt.Errorf("failed to list containers on node %s: %w", node.String(), err)
+		}
+		inUse, err := parseContainerImages([]byte(output))
+		if err != nil {
+			return pruned, err
+		}
+
+		for _, image := range pruneCandidates(images, inUse, keepNames) {
+			if !dryRun {
+				if _, err := nodeExec(ctx, node.String(), "crictl", "rmi", image.ID); err != nil {
+					return pruned, fmt.Errorf("failed to remove image %s from node %s: %w", image.ID, node.String(), err)
+				}
+			}
+			size, _ := strconv.ParseInt(image.Size, 10, 64)
+			pruned = append(pruned, PrunedImage{Node: node.String(), ID: image.ID, RepoTags: image.RepoTags, Size: size})
+		}
+	}
+	return pruned, nil
+}
+
+// parseContainerImages returns the image IDs and names the containers in the
+// JSON output of `crictl ps -a -o json` were created from
+func parseContainerImages(output []byte) (map[string]bool, error) {
+	var result struct {
+		Containers []struct {
+			ImageRef string `json:"imageRef"`
+			Image    struct {
+				Image string `json:"image"`
+			} `json:"image"`
+		} `json:"containers"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse crictl ps output: %w", err)
+	}
+
+	inUse := make(map[string]bool)
+	for _, container := range result.Containers {
+		for _, ref := range []string{container.ImageRef, container.Image.Image} {
+			if ref != "" {
+				inUse[strings.TrimPrefix(ref, "sha256:")] = true
+			}
+		}
+	}
+	return inUse, nil
+}
+
+// pruneCandidates returns the images that can be removed from a node: not
+// pinned, not shipped with kind, not used by a container and not kept
+func pruneCandidates(images []ClusterImage, inUse, keep map[string]bool) []ClusterImage {
+	var candidates []ClusterImage
+	for _, image := range images {
+		if image.Pinned || inUse[strings.TrimPrefix(image.ID, "sha256:")] {
+			continue
+		}
+		removable := true
+		for _, tag := range image.RepoTags {
+			if keep[tag] || inUse[tag] || isProtectedImage(tag) {
+				removable = false
+				break
+			}
+		}
+		if removable {
+			candidates = append(candidates, image)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].ID < candidates[j].ID })
+	return candidates
+}
+
+// isProtectedImage returns whether an image came with the kind node image
+func isProtectedImage(name string) bool {
+	for _, prefix := range protectedImagePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
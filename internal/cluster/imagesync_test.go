@@ -0,0 +1,98 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestImageState(test *testing.T) {
+	current := newImageDigests("sha256:aaa", []string{"app@sha256:manifest"})
+	old := newImageDigests("sha256:bbb", nil)
+	tests := []struct {
+		name     string
+		local    imageDigests
+		copies   []imageDigests
+		expected string
+	}{
+		{name: "loaded everywhere", local: newImageDigests("sha256:aaa", nil), copies: []imageDigests{current, current}, expected: ImageCurrent},
+		{name: "loaded on some nodes", local: newImageDigests("aaa", nil), copies: []imageDigests{current, nil}, expected: ImageCurrent},
+		{name: "containerd store manifest digest", local: newImageDigests("sha256:manifest", nil), copies: []imageDigests{current}, expected: ImageCurrent},
+		{name: "old copy on a node", local: newImageDigests("sha256:aaa", nil), copies: []imageDigests{current, old}, expected: ImageStale},
+		{name: "not loaded", local: newImageDigests("sha256:aaa", nil), copies: []imageDigests{nil, nil}, expected: ImageMissing},
+		{name: "pulled by the cluster", copies: []imageDigests{newImageDigests("sha256:ccc", nil)}, expected: ImagePresent},
+		{name: "neither local nor in cluster", copies: []imageDigests{nil}, expected: ImageMissing},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := imageState(tt.local, tt.copies); got != tt.expected {
+				test.Errorf("imageState() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNodesImageState(test *testing.T) {
+	local := newImageDigests("sha256:aaa", nil)
+	tests := []struct {
+		name     string
+		copies   []imageDigests
+		expected string
+	}{
+		{name: "on every node", copies: []imageDigests{local, local}, expected: ImageCurrent},
+		{name: "missing from a node", copies: []imageDigests{local, nil}, expected: ImageMissing},
+		{name: "old copy on a node", copies: []imageDigests{nil, newImageDigests("sha256:bbb", nil)}, expected: ImageStale},
+		{name: "no nodes", expected: ImageMissing},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := nodesImageState(local, tt.copies); got != tt.expected {
+				test.Errorf("nodesImageState() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseContainerImages(test *testing.T) {
+	output := `{"containers": [
+		{"id": "c1", "imageRef": "sha256:aaa", "image": {"image": "sha256:aaa"}},
+		{"id": "c2", "imageRef": "sha256:bbb", "image": {"image": "docker.io/library/api:dev"}}
+	]}`
+
+	inUse, err := parseContainerImages([]byte(output))
+	if err != nil {
+		test.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]bool{"aaa": true, "bbb": true, "docker.io/library/api:dev": true}
+	if !reflect.DeepEqual(inUse, expected) {
+		test.Errorf("parseContainerImages() = %v, want %v", inUse, expected)
+	}
+
+	if _, err := parseContainerImages([]byte("not json")); err == nil {
+		test.Error("expected error for invalid JSON")
+	}
+}
+
+func TestPruneCandidates(test *testing.T) {
+	images := []ClusterImage{
+		{ID: "sha256:pause", RepoTags: []string{"registry.k8s.io/pause:3.10"}, Pinned: true},
+		{ID: "sha256:helper", RepoTags: []string{"docker.io/kindest/local-path-helper:v2024"}},
+		{ID: "sha256:running", RepoTags: []string{"docker.io/library/api:dev"}},
+		{ID: "sha256:old", RepoTags: nil},
+		{ID: "sha256:kept", RepoTags: []string{"docker.io/library/worker:dev"}},
+		{ID: "sha256:orphan", RepoTags: []string{"docker.io/library/removed:v1"}},
+		{ID: "sha256:bytag", RepoTags: []string{"docker.io/library/cron:v1"}},
+	}
+	inUse := map[string]bool{"running": true, "docker.io/library/cron:v1": true}
+	keep := map[string]bool{"docker.io/library/worker:dev": true}
+
+	var ids []string
+	for _, image := range pruneCandidates(images, inUse, keep) {
+		ids = append(ids, image.ID)
+	}
+	expected := []string{"sha256:old", "sha256:orphan"}
+	if !reflect.DeepEqual(ids, expected) {
+		test.Errorf("pruneCandidates() = %v, want %v", ids, expected)
+	}
+}
@@ -4,15 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	osexec "os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/hjames9/kraze/internal/color"
 	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/telemetry"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -23,25 +27,39 @@ import (
 	"sigs.k8s.io/kind/pkg/apis/config/defaults"
 	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
 	"sigs.k8s.io/kind/pkg/cluster"
-	"sigs.k8s.io/kind/pkg/cluster/images"
 	kindexec "sigs.k8s.io/kind/pkg/exec"
+	sigsyaml "sigs.k8s.io/yaml"
 )
 
 // KindManager manages kind cluster operations
 type KindManager struct {
 	provider      *cluster.Provider
-	customNetwork string // Custom Docker network name (set during cluster creation)
+	customNetwork string                  // Custom Docker network name (set during cluster creation)
+	registries    []config.RegistryConfig // Private registries whose credentials go into the nodes
+
+	// coveredSANs are the cluster/address pairs the API server certificate is known to cover
+	sansMu      sync.Mutex
+	coveredSANs map[string]bool
 }
 
 // NewKindManager creates a new kind cluster manager
 func NewKindManager() *KindManager {
 	return &KindManager{
-		provider: cluster.NewProvider(),
+		provider: cluster.NewProvider(kindProviderOption()),
 	}
 }
 
+// UseRegistries sets the private registries whose credentials the kubelets of
+// the clusters created or repaired get, for the images pods pull from them
+func (kind *KindManager) UseRegistries(registries []config.RegistryConfig) {
+	kind.registries = registries
+}
+
 // CreateCluster creates a new kind cluster based on the configuration
-func (kind *KindManager) CreateCluster(ctx context.Context, cfg *config.ClusterConfig) error {
+func (kind *KindManager) CreateCluster(ctx context.Context, cfg *config.ClusterConfig) (err error) {
+	ctx, span := telemetry.StartSpan(ctx, "cluster.create")
+	defer func() { telemetry.EndSpan(span, err) }()
+
 	// Store custom network name for kubeconfig patching
 	if cfg.Network != "" {
 		kind.customNetwork = cfg.Network
@@ -59,6 +77,10 @@ func (kind *KindManager) CreateCluster(ctx context.Context, cfg *config.ClusterC
 		}
 	}
 
+	if err := checkRootlessPodmanPorts(cfg); err != nil {
+		return err
+	}
+
 	// Check if cluster already exists
 	exists, err := kind.ClusterExists(cfg.Name)
 	if err != nil {
@@ -84,23 +106,25 @@ func (kind *KindManager) CreateCluster(ctx context.Context, cfg *config.ClusterC
 
 	fmt.Printf("Creating kind cluster '%s' (Kubernetes %s)...\n", cfg.Name, kind.parseK8sVersion(cfg))
 
-	// Create cluster in background so we can apply cgroup workaround during init
+	// Create cluster in background so we can apply cgroup workaround during init.
+	// createCtx ends when create returns, which stops the node container wait.
+	createCtx, cancelCreate := context.WithCancel(ctx)
+	defer cancelCreate()
 	createErr := make(chan error, 1)
+	_, nodesSpan := telemetry.StartSpan(ctx, "kind.create")
 	go func() {
 		createErr <- kind.provider.Create(cfg.Name, createOpts...)
+		cancelCreate()
 	}()
 
-	// Wait for container to exist, then apply cgroup workaround
+	// Apply the cgroup workaround as soon as the node containers have booted.
 	// This prevents Kubernetes 1.34.0+ kubelet failures on cgroup v1 systems
-	time.Sleep(10 * time.Second) // Give kind time to create the container
-
-	if err := kind.ensureKubeletCgroupDirectories(cfg.Name); err != nil {
-		// Log but don't fail - cluster might still work without this
-		fmt.Printf("Note: Could not create kubelet cgroup directories (cluster may still succeed): %v\n", err)
-	}
+	kind.applyCgroupWorkaroundWhenReady(createCtx, cfg.Name)
 
 	// Wait for cluster creation to complete
-	if err := <-createErr; err != nil {
+	err = <-createErr
+	telemetry.EndSpan(nodesSpan, err)
+	if err != nil {
 		return fmt.Errorf("failed to create cluster: %w", enrichClusterCreateError(err))
 	}
 
@@ -112,22 +136,27 @@ func (kind *KindManager) CreateCluster(ctx context.Context, cfg *config.ClusterC
 		fmt.Printf("Warning: Could not connect to host network: %v\n", err)
 	}
 
-	// Give the API server a few seconds to be fully ready after network changes
 	// kind's CreateWithWaitForReady already waits, but connecting to a new network
-	// might need a moment for routing to stabilize
+	// changes routing, so check the API server answers on the address kraze uses
 	fmt.Printf("Waiting for cluster to fully stabilize...\n")
-	time.Sleep(5 * time.Second)
+	apiCtx, apiSpan := telemetry.StartSpan(ctx, "cluster.wait_api_server")
+	apiErr := kind.waitForAPIServer(apiCtx, cfg.Name)
+	telemetry.EndSpan(apiSpan, apiErr)
+	if apiErr != nil {
+		fmt.Printf("%s %v\n", color.Warning(), apiErr)
+	}
 
 	// Update CA certificates if custom CAs were mounted
 	// This is done after cluster init to avoid interfering with kubeadm init
 	// Note: We don't reload containerd - the CAs will be picked up on next image pull
 	if len(cfg.CACertificates) > 0 {
-		// Give a bit more time for all systemd services to be fully up
-		// This ensures update-ca-certificates has all dependencies ready
+		// update-ca-certificates needs containerd and the kubelet fully up
 		fmt.Printf("Preparing to update CA certificates...\n")
-		time.Sleep(3 * time.Second)
+		if err := kind.waitForSystemdUnits(ctx, cfg.Name, nodeUnits...); err != nil {
+			return fmt.Errorf("failed to update CA certificates: %w", err)
+		}
 
-		if err := kind.updateCACertificates(cfg.Name); err != nil {
+		if err := kind.updateCACertificates(ctx, cfg.Name); err != nil {
 			// This is a critical error - without CA certificates, application images won't pull
 			return fmt.Errorf("failed to update CA certificates: %w", err)
 		}
@@ -136,19 +165,31 @@ func (kind *KindManager) CreateCluster(ctx context.Context, cfg *config.ClusterC
 	// Configure insecure registries if specified
 	// This is done after cluster init to avoid interfering with kubeadm
 	if len(cfg.InsecureRegistries) > 0 {
-		if err := kind.configureInsecureRegistries(cfg.Name, cfg.InsecureRegistries); err != nil {
+		if err := kind.configureInsecureRegistries(ctx, cfg.Name, cfg.InsecureRegistries); err != nil {
 			fmt.Printf("Warning: Could not configure insecure registries: %v\n", err)
 		}
 	}
 
+	// Copy registry credentials once the kubelet is up, since copying restarts it
+	if cfg.RegistryCredentials.IsEnabled() || len(kind.registries) > 0 {
+		if _, err := kind.copyRegistryCredentials(ctx, cfg.Name, cfg.RegistryCredentials); err != nil {
+			fmt.Printf("Warning: Could not copy registry credentials: %v\n", err)
+		}
+	}
+
 	// Configure proxy if specified
 	httpProxy, httpsProxy, noProxy := kind.getEffectiveProxyConfig(cfg)
 	if httpProxy != "" || httpsProxy != "" || noProxy != "" {
-		if err := kind.configureProxy(cfg.Name, httpProxy, httpsProxy, noProxy); err != nil {
+		if err := kind.configureProxy(ctx, cfg.Name, httpProxy, httpsProxy, noProxy); err != nil {
 			fmt.Printf("Warning: Could not configure proxy: %v\n", err)
 		}
 	}
 
+	// Set the node sysctls once the nodes are up
+	if err := kind.ApplyNodeSysctls(ctx, cfg); err != nil {
+		fmt.Printf("Warning: Could not set node sysctls: %v\n", err)
+	}
+
 	// Register NVIDIA RuntimeClass if NVIDIA GPU support is enabled
 	if cfg.GPU.IsNvidiaEnabled() {
 		fmt.Printf("Registering NVIDIA RuntimeClass...\n")
@@ -185,6 +226,69 @@ func (kind *KindManager) DeleteCluster(clusterName string) error {
 	return nil
 }
 
+// StopCluster stops the node containers of a kind cluster without deleting them,
+// recording that it was stopped on purpose. The cluster and its workloads
+// resume when started again with StartCluster.
+func (kind *KindManager) StopCluster(clusterName string) error {
+	nodes, err := kind.provider.ListNodes(clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to list cluster nodes: %w", err)
+	}
+	if len(nodes) == 0 {
+		return fmt.Errorf("no nodes found in cluster '%s'", clusterName)
+	}
+
+	for _, node := range nodes {
+		cmd := runtimeCommand("stop", node.String())
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to stop node %s: %w (output: %s)", node.String(), err, strings.TrimSpace(string(output)))
+		}
+	}
+	return SetStopped(clusterName, true)
+}
+
+// StartCluster starts the node containers of a previously stopped kind cluster
+func (kind *KindManager) StartCluster(clusterName string) error {
+	nodes, err := kind.provider.ListNodes(clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to list cluster nodes: %w", err)
+	}
+	if len(nodes) == 0 {
+		return fmt.Errorf("no nodes found in cluster '%s'", clusterName)
+	}
+
+	for _, node := range nodes {
+		cmd := runtimeCommand("start", node.String())
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to start node %s: %w (output: %s)", node.String(), err, strings.TrimSpace(string(output)))
+		}
+	}
+	return SetStopped(clusterName, false)
+}
+
+// IsClusterRunning returns true if all node containers of the cluster are running
+func (kind *KindManager) IsClusterRunning(clusterName string) (bool, error) {
+	nodes, err := kind.provider.ListNodes(clusterName)
+	if err != nil {
+		return false, fmt.Errorf("failed to list cluster nodes: %w", err)
+	}
+	if len(nodes) == 0 {
+		return false, nil
+	}
+
+	for _, node := range nodes {
+		cmd := runtimeCommand("inspect", "-f", "{{.State.Running}}", node.String())
+		output, err := cmd.Output()
+		if err != nil {
+			return false, fmt.Errorf("failed to inspect node %s: %w", node.String(), err)
+		}
+		if strings.TrimSpace(string(output)) != "true" {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 // ListClusters returns a list of all kind clusters
 func (kind *KindManager) ListClusters() ([]string, error) {
 	clusters, err := kind.provider.List()
@@ -254,11 +358,14 @@ func (kind *KindManager) GetKubeConfigQuiet(clusterName string, internal bool, q
 // Returns true if running in a containerized environment (dev containers, CI)
 // Returns false if running natively on macOS, Windows, or Linux host
 func (kind *KindManager) shouldPatchKubeconfig() bool {
-	// Check if we're running inside a Docker container
-	// The /.dockerenv file exists in Docker containers
+	// Check if we're running inside a Docker or Podman container
+	// The /.dockerenv file exists in Docker containers, /run/.containerenv in Podman ones
 	if _, err := os.Stat("/.dockerenv"); err == nil {
 		return true
 	}
+	if _, err := os.Stat("/run/.containerenv"); err == nil {
+		return true
+	}
 
 	// Default: don't patch (use kind's original config)
 	// This works on macOS, Windows, and Linux native hosts where kind sets up port forwarding
@@ -281,7 +388,7 @@ func (kind *KindManager) patchKubeconfigForNativeHost(clusterName, kubeconfig st
 
 	// Get the Docker port mapping for the API server (port 6443)
 	// Docker shows something like: 127.0.0.1:53549->6443/tcp
-	cmd := osexec.Command("docker", "port", containerName, "6443")
+	cmd := runtimeCommand("port", containerName, "6443")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to get port mapping for container %s: %w", containerName, err)
@@ -336,7 +443,7 @@ func (kind *KindManager) patchKubeconfigWithContainerIP(clusterName, kubeconfig
 	}
 
 	for _, network := range networksToTry {
-		cmd := osexec.Command("docker", "inspect", containerName,
+		cmd := runtimeCommand("inspect", containerName,
 			"-f", fmt.Sprintf("{{.NetworkSettings.Networks.%s.IPAddress}}", network))
 
 		output, err := cmd.Output()
@@ -347,6 +454,9 @@ func (kind *KindManager) patchKubeconfigWithContainerIP(clusterName, kubeconfig
 				if shouldPrint {
 					fmt.Printf("%s Using container IP %s from '%s' network\n", color.Checkmark(), containerIP, network)
 				}
+				if err := kind.ensureAPIServerSAN(context.Background(), clusterName, containerIP); err != nil {
+					fmt.Printf("%s API server certificate does not cover %s, TLS verification will fail: %v\n", color.Warning(), containerIP, err)
+				}
 				patchedConfig := kubeconfig
 
 				// Replace hostname with container IP:6443
@@ -362,7 +472,7 @@ func (kind *KindManager) patchKubeconfigWithContainerIP(clusterName, kubeconfig
 	}
 
 	// Fallback: get any available IP
-	cmd := osexec.Command("docker", "inspect", containerName,
+	cmd := runtimeCommand("inspect", containerName,
 		"-f", "{{range .NetworkSettings.Networks}}{{.IPAddress}} {{end}}")
 
 	output, err := cmd.Output()
@@ -376,6 +486,9 @@ func (kind *KindManager) patchKubeconfigWithContainerIP(clusterName, kubeconfig
 		return "", fmt.Errorf("no container IP found")
 	}
 	containerIP := ips[0]
+	if err := kind.ensureAPIServerSAN(context.Background(), clusterName, containerIP); err != nil {
+		fmt.Printf("%s API server certificate does not cover %s, TLS verification will fail: %v\n", color.Warning(), containerIP, err)
+	}
 
 	// Replace hostname and URL addresses with container IP
 	patchedConfig := kubeconfig
@@ -403,16 +516,17 @@ func (kind *KindManager) UpdateKubeconfigFile(clusterName string) error {
 		return fmt.Errorf("failed to parse kubeconfig: %w", err)
 	}
 
-	// Add insecure-skip-tls-verify to the cluster (needed because cert is for 127.0.0.1, not container IP)
-	contextName := "kind-" + clusterName
-	if cluster, exists := config.Clusters[contextName]; exists {
-		cluster.InsecureSkipTLSVerify = true
-		cluster.CertificateAuthorityData = nil // Remove CA data when using insecure
-	}
-
 	// Get path to user's kubeconfig
 	kubeconfigPath := clientcmd.RecommendedHomeFile
 
+	// Hold the kubeconfig lock while merging, so concurrent kraze and kubectl
+	// changes aren't lost
+	unlock, err := lockKubeconfig(kubeconfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to lock kubeconfig: %w", err)
+	}
+	defer unlock()
+
 	// Load existing kubeconfig or create new one
 	pathOptions := clientcmd.NewDefaultPathOptions()
 	existingConfig, err := pathOptions.GetStartingConfig()
@@ -434,10 +548,10 @@ func (kind *KindManager) UpdateKubeconfigFile(clusterName string) error {
 	}
 
 	// Set current context to the new cluster
-	existingConfig.CurrentContext = contextName
+	existingConfig.CurrentContext = "kind-" + clusterName
 
 	// Write the merged config back
-	if err := clientcmd.WriteToFile(*existingConfig, kubeconfigPath); err != nil {
+	if err := writeKubeconfigFile(kubeconfigPath, existingConfig); err != nil {
 		return fmt.Errorf("failed to write kubeconfig: %w", err)
 	}
 
@@ -465,23 +579,19 @@ func (kind *KindManager) WaitForClusterReady(ctx context.Context, clusterName st
 		return fmt.Errorf("failed to create clientset: %w", err)
 	}
 
-	// Try to connect with retries
-	deadline := time.Now().Add(timeout)
-	retryInterval := 2 * time.Second
-
-	for time.Now().Before(deadline) {
-		// Try to get server version as a health check
+	// Use server version as a health check, retrying until the timeout
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err = pollUntil(ctx, 2*time.Second, func(ctx context.Context) (bool, error) {
 		_, err := clientset.Discovery().ServerVersion()
-		if err == nil {
-			fmt.Printf("%s Cluster API server is ready\n", color.Checkmark())
-			return nil
-		}
-
-		// Wait before retrying
-		time.Sleep(retryInterval)
+		return err == nil, err
+	})
+	if err != nil {
+		return fmt.Errorf("cluster API server not ready after %v: %w", timeout, err)
 	}
 
-	return fmt.Errorf("cluster API server not ready after %v", timeout)
+	fmt.Printf("%s Cluster API server is ready\n", color.Checkmark())
+	return nil
 }
 
 // parseK8sVersion returns a human-readable Kubernetes version string for display.
@@ -502,6 +612,30 @@ func (kind *KindManager) parseK8sVersion(cfg *config.ClusterConfig) string {
 	return image
 }
 
+// KubernetesVersion returns the Kubernetes version tag of the node image a new
+// cluster would use (e.g. "v1.36.1"), which may not be a version for custom images
+func (kind *KindManager) KubernetesVersion(cfg *config.ClusterConfig) string {
+	return kind.parseK8sVersion(cfg)
+}
+
+// CheckNodeImageVersion verifies that the node image a new cluster would use is
+// within cluster.kubernetes. Images without a version tag are left to the server
+// version check once the cluster is running.
+func (kind *KindManager) CheckNodeImageVersion(cfg *config.ClusterConfig) error {
+	if cfg.Kubernetes == "" {
+		return nil
+	}
+	tag := kind.parseK8sVersion(cfg)
+	if _, err := config.ParseKubernetesVersion(tag); err != nil {
+		return nil
+	}
+	image := kind.getNodeImage(cfg)
+	if image == "" {
+		image = defaults.Image
+	}
+	return cfg.CheckKubernetesVersion(tag, "node image "+image)
+}
+
 // getNodeImage determines which node image to use based on configuration
 // Priority: node_image > version > default (empty string, let kind decide)
 func (kind *KindManager) getNodeImage(cfg *config.ClusterConfig) string {
@@ -521,6 +655,10 @@ func (kind *KindManager) getNodeImage(cfg *config.ClusterConfig) string {
 
 // buildKindConfig converts kraze cluster config to kind v1alpha4 config
 func (kind *KindManager) buildKindConfig(cfg *config.ClusterConfig) (*v1alpha4.Cluster, error) {
+	if cfg.KindConfig != "" {
+		return kind.buildKindConfigFromFile(cfg)
+	}
+
 	kindCfg := &v1alpha4.Cluster{
 		TypeMeta: v1alpha4.TypeMeta{
 			APIVersion: "kind.x-k8s.io/v1alpha4",
@@ -543,7 +681,12 @@ func (kind *KindManager) buildKindConfig(cfg *config.ClusterConfig) (*v1alpha4.C
 	kindCfg.ContainerdConfigPatches = kind.buildContainerdConfigPatches(cfg)
 
 	// Add kubeadm config patches for proxy configuration
-	kindCfg.KubeadmConfigPatches = kind.buildKubeadmConfigPatches(cfg)
+	kindCfg.KubeadmConfigPatches = kind.buildKubeadmConfigPatches(cfg, kindCfg.Networking)
+
+	// Add the feature gates, runtime config and patches kraze passes through as is
+	if err := applyPassthrough(kindCfg, cfg); err != nil {
+		return nil, err
+	}
 
 	// Determine which node image to use
 	nodeImage := kind.getNodeImage(cfg)
@@ -573,6 +716,7 @@ func (kind *KindManager) buildKindConfig(cfg *config.ClusterConfig) (*v1alpha4.C
 			node.Image = nodeImage
 		}
 		kindCfg.Nodes = append(kindCfg.Nodes, node)
+		applyTopology(kindCfg.Nodes, []config.KindNode{{Role: "control-plane"}}, cfg.Topology)
 		return kindCfg, nil
 	}
 
@@ -587,7 +731,8 @@ func (kind *KindManager) buildKindConfig(cfg *config.ClusterConfig) (*v1alpha4.C
 		}
 	}
 
-	// Convert kraze nodes to kind nodes
+	// Convert kraze nodes to kind nodes, keeping the kraze node of each for the topology
+	var sources []config.KindNode
 	for _, node := range cfg.Config {
 		kindNode := kind.buildKindNode(node)
 
@@ -612,19 +757,159 @@ func (kind *KindManager) buildKindConfig(cfg *config.ClusterConfig) (*v1alpha4.C
 		if node.Replicas > 0 {
 			for itr := 0; itr < node.Replicas; itr++ {
 				kindCfg.Nodes = append(kindCfg.Nodes, kindNode)
+				sources = append(sources, node)
 			}
 		} else {
 			kindCfg.Nodes = append(kindCfg.Nodes, kindNode)
+			sources = append(sources, node)
 		}
 	}
+	applyTopology(kindCfg.Nodes, sources, cfg.Topology)
 
 	return kindCfg, nil
 }
 
-// PullImage pulls a Docker image from a remote registry
-func (kind *KindManager) PullImage(ctx context.Context, imageName string) error {
-	// Use docker pull command
-	cmd := osexec.CommandContext(ctx, "docker", "pull", imageName)
+// buildKindConfigFromFile uses cluster.kind_config as is, adding only what
+// kraze needs: the cluster name, node image, CA, GODEBUG and GPU mounts, the
+// containerd and kubeadm patches for CAs, registries and proxies, and the
+// settings kraze passes through
+func (kind *KindManager) buildKindConfigFromFile(cfg *config.ClusterConfig) (*v1alpha4.Cluster, error) {
+	data, err := os.ReadFile(cfg.KindConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kind config: %w", err)
+	}
+	kindCfg := &v1alpha4.Cluster{}
+	if err := sigsyaml.UnmarshalStrict(data, kindCfg); err != nil {
+		return nil, fmt.Errorf("failed to parse kind config %s: %w", cfg.KindConfig, err)
+	}
+	if kindCfg.APIVersion != config.KindConfigAPIVersion || kindCfg.Kind != config.KindConfigKind {
+		return nil, fmt.Errorf("%s is not a kind config (expected apiVersion: %s and kind: %s)", cfg.KindConfig, config.KindConfigAPIVersion, config.KindConfigKind)
+	}
+
+	// kraze tracks the cluster by its own name
+	kindCfg.Name = cfg.Name
+
+	// Explicit kraze networking settings win over the file's
+	if cfg.Networking != nil {
+		kindCfg.Networking.DisableDefaultCNI = kindCfg.Networking.DisableDefaultCNI || cfg.Networking.DisableDefaultCNI
+		if cfg.Networking.PodSubnet != "" {
+			kindCfg.Networking.PodSubnet = cfg.Networking.PodSubnet
+		}
+		if cfg.Networking.ServiceSubnet != "" {
+			kindCfg.Networking.ServiceSubnet = cfg.Networking.ServiceSubnet
+		}
+	}
+
+	kindCfg.ContainerdConfigPatches = append(kindCfg.ContainerdConfigPatches, kind.buildContainerdConfigPatches(cfg)...)
+	kindCfg.KubeadmConfigPatches = append(kindCfg.KubeadmConfigPatches, kind.buildKubeadmConfigPatches(cfg, kindCfg.Networking)...)
+	if err := applyPassthrough(kindCfg, cfg); err != nil {
+		return nil, err
+	}
+
+	godebugMount, err := kind.buildGODEBUGMount(cfg.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GODEBUG configuration: %w", err)
+	}
+	allMounts := append(kind.buildCAMounts(cfg), godebugMount)
+	gpuMounts := kind.buildGPUMounts(cfg)
+
+	sources := cfg.Config
+	if len(kindCfg.Nodes) == 0 {
+		kindCfg.Nodes = []v1alpha4.Node{{Role: v1alpha4.ControlPlaneRole}}
+		sources = []config.KindNode{{Role: "control-plane"}}
+	}
+
+	hasWorker := slices.ContainsFunc(kindCfg.Nodes, func(node v1alpha4.Node) bool { return node.Role == v1alpha4.WorkerRole })
+	nodeImage := kind.getNodeImage(cfg)
+	kindDir := filepath.Dir(cfg.KindConfig)
+	for itr := range kindCfg.Nodes {
+		node := &kindCfg.Nodes[itr]
+		if node.Image == "" {
+			node.Image = nodeImage
+		}
+
+		// Relative host paths are relative to the kind config file, not the working directory
+		for mountItr := range node.ExtraMounts {
+			node.ExtraMounts[mountItr].HostPath = config.ResolveKindHostPath(kindDir, node.ExtraMounts[mountItr].HostPath)
+		}
+		node.ExtraMounts = append(node.ExtraMounts, allMounts...)
+
+		// A variant cluster leaves picking host ports to Docker, like cluster.config
+		if cfg.Variant != "" {
+			for portItr := range node.ExtraPortMappings {
+				node.ExtraPortMappings[portItr].HostPort = 0
+			}
+		}
+
+		if node.Role == v1alpha4.WorkerRole || (!hasWorker && node.Role == v1alpha4.ControlPlaneRole) {
+			node.ExtraMounts = append(node.ExtraMounts, gpuMounts...)
+			if cfg.GPU.IsNvidiaEnabled() {
+				node.GPUs = "all"
+			}
+		}
+	}
+	if len(sources) == len(kindCfg.Nodes) {
+		// Topology replaces the node patches with its reservations, so keep the file's first
+		filePatches := make([][]string, len(kindCfg.Nodes))
+		for itr, node := range kindCfg.Nodes {
+			filePatches[itr] = node.KubeadmConfigPatches
+		}
+		applyTopology(kindCfg.Nodes, sources, cfg.Topology)
+		for itr := range kindCfg.Nodes {
+			if len(filePatches[itr]) > 0 && !slices.Equal(filePatches[itr], kindCfg.Nodes[itr].KubeadmConfigPatches) {
+				kindCfg.Nodes[itr].KubeadmConfigPatches = append(slices.Clone(filePatches[itr]), kindCfg.Nodes[itr].KubeadmConfigPatches...)
+			}
+		}
+	}
+
+	return kindCfg, nil
+}
+
+// applyTopology labels the nodes with their synthetic zone and region, and
+// reserves the zone's skew on them. Replicas share their labels map, so every
+// node gets its own.
+func applyTopology(nodes []v1alpha4.Node, sources []config.KindNode, topology *config.TopologyConfig) {
+	if topology == nil {
+		return
+	}
+
+	roles := make([]string, len(sources))
+	for itr, source := range sources {
+		roles[itr] = source.Role
+	}
+	for itr, zone := range topology.NodeZones(roles) {
+		labels := maps.Clone(nodes[itr].Labels)
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels[config.RegionLabel] = topology.GetRegion()
+		if zone != "" {
+			labels[config.ZoneLabel] = zone
+		}
+		nodes[itr].Labels = labels
+
+		if skew := topology.Skew[zone]; zone != "" && len(skew) > 0 {
+			nodes[itr].KubeadmConfigPatches = nodeKubeadmPatches(sources[itr].WithReservedSkew(skew))
+		}
+	}
+}
+
+// PullImage pulls a Docker image from a remote registry. Images from one of
+// the given registries are pulled with its credentials, others with the
+// runtime's own (from 'docker login').
+func (kind *KindManager) PullImage(ctx context.Context, imageName string, registries []config.RegistryConfig) (err error) {
+	ctx, span := telemetry.StartSpan(ctx, "image.pull", telemetry.Image(imageName))
+	defer func() { telemetry.EndSpan(span, err) }()
+
+	// Podman may refuse ambiguous short names without a TTY, so pull them fully qualified
+	pullRef := imageName
+	if DetectContainerRuntime() == RuntimePodman {
+		pullRef = localImageArchiveRef(imageName)
+	}
+	if reg := config.FindRegistry(registries, config.ImageRegistryHost(imageName)); reg != nil {
+		return pullImageWithCredentials(ctx, pullRef, reg)
+	}
+	cmd := runtimeCommandContext(ctx, "pull", pullRef)
 
 	// Suppress output unless there's an error
 	var stderr strings.Builder
@@ -637,8 +922,33 @@ func (kind *KindManager) PullImage(ctx context.Context, imageName string) error
 	return nil
 }
 
-// LoadImage loads a Docker image into the kind cluster
+// LoadImage loads a Docker image into the kind cluster. Nodes that already
+// have the image are skipped. A single node gets a layer-aware transfer; when
+// several nodes need the image, one save is streamed to all of them at once.
 func (kind *KindManager) LoadImage(ctx context.Context, clusterName, imageName string) error {
+	return kind.LoadImagesToNodes(ctx, clusterName, []string{imageName}, nil)
+}
+
+// LoadImages loads several Docker images into the kind cluster with one save
+// per node group, so layers the images share are written and transferred once
+func (kind *KindManager) LoadImages(ctx context.Context, clusterName string, imageNames []string) error {
+	return kind.LoadImagesToNodes(ctx, clusterName, imageNames, nil)
+}
+
+// LoadImageToNodes loads a Docker image onto the named nodes of the kind
+// cluster, or onto every node when nodeNames is empty
+func (kind *KindManager) LoadImageToNodes(ctx context.Context, clusterName, imageName string, nodeNames []string) error {
+	return kind.LoadImagesToNodes(ctx, clusterName, []string{imageName}, nodeNames)
+}
+
+// LoadImagesToNodes loads Docker images onto the named nodes of the kind
+// cluster, or onto every node when nodeNames is empty. Nodes missing the same
+// images share a single save of all of them, which writes the layers the
+// images have in common once.
+func (kind *KindManager) LoadImagesToNodes(ctx context.Context, clusterName string, imageNames []string, nodeNames []string) (err error) {
+	ctx, span := telemetry.StartSpan(ctx, "image.load", telemetry.Image(strings.Join(imageNames, ",")))
+	defer func() { telemetry.EndSpan(span, err) }()
+
 	// Get cluster nodes
 	nodes, err := kind.provider.ListInternalNodes(clusterName)
 	if err != nil {
@@ -648,16 +958,33 @@ func (kind *KindManager) LoadImage(ctx context.Context, clusterName, imageName s
 	if len(nodes) == 0 {
 		return fmt.Errorf("no nodes found in cluster '%s'", clusterName)
 	}
+	if len(nodeNames) > 0 {
+		if nodes = selectNodes(nodes, nodeNames); len(nodes) == 0 {
+			return fmt.Errorf("none of the nodes %s found in cluster '%s'", strings.Join(nodeNames, ", "), clusterName)
+		}
+	}
 
-	// Create a temporary directory for the image tar
-	tmpDir, err := os.MkdirTemp("", "kind-image-")
-	if err != nil {
-		return fmt.Errorf("failed to create temp dir: %w", err)
+	saveImageRefs := make([]string, 0, len(imageNames))
+	for _, imageName := range imageNames {
+		saveImageRef, cleanup := saveableImageRef(imageName)
+		defer cleanup()
+		saveImageRefs = append(saveImageRefs, saveImageRef)
+	}
+
+	// Skip nodes that already have the exact images, and batch the rest by
+	// the images they're missing
+	for _, group := range groupNodesByMissingImages(ctx, nodes, saveImageRefs) {
+		if err = loadImagesToNodes(ctx, group.images, group.nodes); err != nil {
+			return err
+		}
 	}
-	defer os.RemoveAll(tmpDir)
+	return nil
+}
 
-	// Save the image as a tar archive
-	imageTar := filepath.Join(tmpDir, "image.tar")
+// saveableImageRef returns the reference to save an image by, tagging it when
+// needed, and a function that removes any temporary tag
+func saveableImageRef(imageName string) (string, func()) {
+	cleanup := func() {}
 
 	// Normalize image name - strip digest if present, keeping only repo:tag
 	saveImageRef := imageName
@@ -668,10 +995,10 @@ func (kind *KindManager) LoadImage(ctx context.Context, clusterName, imageName s
 			repoTag := parts[0]
 
 			// Check if this reference exists locally
-			inspectCmd := osexec.Command("docker", "inspect", imageName)
+			inspectCmd := runtimeCommand("inspect", imageName)
 			if err := inspectCmd.Run(); err == nil {
 				// Image exists, tag it with the repo:tag format
-				tagCmd := osexec.Command("docker", "tag", imageName, repoTag)
+				tagCmd := runtimeCommand("tag", imageName, repoTag)
 				if err := tagCmd.Run(); err == nil {
 					saveImageRef = repoTag
 				}
@@ -679,42 +1006,27 @@ func (kind *KindManager) LoadImage(ctx context.Context, clusterName, imageName s
 		}
 	}
 
-	// Use docker save to export the image
-	saveCmd := osexec.Command("docker", "save", "-o", imageTar, saveImageRef)
-	if err := saveCmd.Run(); err != nil {
-		return fmt.Errorf("failed to save image '%s': %w (make sure the image exists locally)", imageName, err)
-	}
-
-	// Load the image onto all nodes using layer-aware transfer
-	results, err := images.LoadImageLayerAware(imageTar, nodes, nil)
-	if err != nil {
-		return fmt.Errorf("failed to load image '%s': %w", imageName, err)
-	}
-	for _, result := range results {
-		if result.Error != nil {
-			return fmt.Errorf("failed to load image onto node %s: %w", result.Node.String(), result.Error)
+	// Podman saves unqualified local images as localhost/<name>; tag them with the
+	// name kubelet resolves so pods referencing <name> find the loaded image
+	if DetectContainerRuntime() == RuntimePodman {
+		if qualifiedRef := localImageArchiveRef(saveImageRef); qualifiedRef != saveImageRef {
+			if runtimeCommand("image", "exists", qualifiedRef).Run() != nil {
+				if err := runtimeCommand("tag", saveImageRef, qualifiedRef).Run(); err == nil {
+					cleanup = func() { _ = runtimeCommand("untag", qualifiedRef, qualifiedRef).Run() }
+				}
+			}
+			saveImageRef = qualifiedRef
 		}
 	}
 
-	return nil
+	return saveImageRef, cleanup
 }
 
 // UntagImage removes the tag reference from an image without removing the image itself
 // This allows running containers to continue using the old image while new containers get updated tags
 func (kind *KindManager) UntagImage(ctx context.Context, clusterName, imageName string) error {
-	// Normalize image name - add docker.io prefix if needed (same logic as GetClusterImageHash)
-	ref := ParseImageReference(imageName)
-	clusterImageName := imageName
-
-	// Add docker.io prefix if it's a Docker Hub image without explicit registry
-	if ref.IsDockerHub() && !strings.HasPrefix(imageName, "docker.io/") {
-		// If it's library/* (official images), use docker.io/library/
-		if !strings.Contains(imageName, "/") {
-			clusterImageName = "docker.io/library/" + imageName
-		} else {
-			clusterImageName = "docker.io/" + imageName
-		}
-	}
+	// Normalize image name the same way containerd names it in the nodes
+	nodeImageName := ClusterImageName(imageName)
 
 	// Get the control plane container name
 	containerName := clusterName + "-control-plane"
@@ -722,18 +1034,20 @@ func (kind *KindManager) UntagImage(ctx context.Context, clusterName, imageName
 	// Use ctr (containerd CLI) to remove the tag reference
 	// This removes the tag but leaves the actual image data if it's in use
 	// Using ctr instead of crictl because ctr has more granular control
-	cmd := osexec.CommandContext(ctx, "docker", "exec", containerName,
-		"ctr", "-n", "k8s.io", "images", "rm", clusterImageName)
-	output, err := cmd.CombinedOutput()
+	// Idempotency check: if the image is not present there is nothing to untag
+	if !nodeTest(ctx, containerName, "sh", "-c",
+		fmt.Sprintf("ctr -n k8s.io images ls -q | grep -qxF '%s'", nodeImageName)) {
+		return nil
+	}
 
-	if err != nil {
-		outputStr := string(output)
-		// If image doesn't exist, that's fine - nothing to untag
-		if strings.Contains(outputStr, "not found") || strings.Contains(outputStr, "No such image") {
+	if _, err := nodeExec(ctx, containerName, "ctr", "-n", "k8s.io", "images", "rm", nodeImageName); err != nil {
+		// The image may have been removed between the check and a retry
+		var execErr *NodeExecError
+		if errors.As(err, &execErr) &&
+			(strings.Contains(execErr.Stderr, "not found") || strings.Contains(execErr.Stderr, "No such image")) {
 			return nil
 		}
-		// Other errors are problems
-		return fmt.Errorf("failed to untag image: %w (output: %s)", err, outputStr)
+		return fmt.Errorf("failed to untag image: %w", err)
 	}
 
 	return nil
@@ -742,7 +1056,7 @@ func (kind *KindManager) UntagImage(ctx context.Context, clusterName, imageName
 // ensureKubeletCgroupDirectories creates the cgroup directories that kubelet expects
 // This is a workaround for Kubernetes 1.34.0+ race condition on cgroup v1 systems
 // where kubelet fails to start because the cgroup directories don't exist yet
-func (kind *KindManager) ensureKubeletCgroupDirectories(clusterName string) error {
+func (kind *KindManager) ensureKubeletCgroupDirectories(ctx context.Context, clusterName string) error {
 	nodes, err := kind.provider.ListInternalNodes(clusterName)
 	if err != nil {
 		return fmt.Errorf("failed to list cluster nodes: %w", err)
@@ -753,27 +1067,23 @@ func (kind *KindManager) ensureKubeletCgroupDirectories(clusterName string) erro
 
 		// First check if we're using cgroup v1 or v2
 		// Only cgroup v1 needs this workaround
-		checkCmd := osexec.Command("docker", "exec", containerName, "test", "-d", "/sys/fs/cgroup/systemd")
-		if err := checkCmd.Run(); err != nil {
+		if !nodeTest(ctx, containerName, "test", "-d", "/sys/fs/cgroup/systemd") {
 			// Not cgroup v1 (likely v2), skip this workaround
 			continue
 		}
 
 		// Check if the directory already exists
 		cgroupPath := "/sys/fs/cgroup/systemd/kubelet.slice/kubelet-kubepods.slice"
-		testCmd := osexec.Command("docker", "exec", containerName, "test", "-d", cgroupPath)
-		if err := testCmd.Run(); err == nil {
+		if nodeTest(ctx, containerName, "test", "-d", cgroupPath) {
 			// Directory already exists, no need to create it
 			continue
 		}
 
 		// Create the kubelet cgroup directory structure
 		// This prevents: "Failed to start ContainerManager: cgroup [...] has some missing paths"
-		mkdirCmd := osexec.Command("docker", "exec", containerName, "mkdir", "-p", cgroupPath)
-		if output, err := mkdirCmd.CombinedOutput(); err != nil {
+		if _, err := nodeExec(ctx, containerName, "mkdir", "-p", cgroupPath); err != nil {
 			// This is a workaround, so we return error but don't fail hard
-			return fmt.Errorf("failed to create kubelet cgroup directory %s in node %s: %w\nOutput: %s",
-				cgroupPath, containerName, err, string(output))
+			return fmt.Errorf("failed to create kubelet cgroup directory %s: %w", cgroupPath, err)
 		}
 	}
 
@@ -783,7 +1093,7 @@ func (kind *KindManager) ensureKubeletCgroupDirectories(clusterName string) erro
 // updateCACertificates runs update-ca-certificates in all nodes
 // This updates the system CA trust store with custom certificates mounted via extraMounts
 // Note: We don't reload containerd - CAs will be automatically used on next image pull
-func (kind *KindManager) updateCACertificates(clusterName string) error {
+func (kind *KindManager) updateCACertificates(ctx context.Context, clusterName string) error {
 	fmt.Printf("Updating CA certificates in cluster nodes...\n")
 
 	// Get cluster nodes
@@ -796,19 +1106,10 @@ func (kind *KindManager) updateCACertificates(clusterName string) error {
 	for _, node := range nodes {
 		containerName := node.String()
 
-		// Use timeout to prevent hanging - update-ca-certificates should complete in seconds
-		// We use 30 seconds to be safe, but it typically completes in <1 second
-		cmd := osexec.Command("timeout", "30", "docker", "exec", containerName, "update-ca-certificates")
-		output, err := cmd.CombinedOutput()
-
-		if err != nil {
-			// Check if it was a timeout
-			if exitErr, ok := err.(*osexec.ExitError); ok && exitErr.ExitCode() == 124 {
-				return fmt.Errorf("update-ca-certificates timed out after 30 seconds in node %s\nOutput: %s",
-					containerName, string(output))
-			}
-			return fmt.Errorf("failed to update CA certificates in node %s: %w\nOutput: %s",
-				containerName, err, string(output))
+		// Each attempt is bounded by the node exec timeout to prevent hanging -
+		// update-ca-certificates typically completes in <1 second
+		if _, err := nodeExec(ctx, containerName, "update-ca-certificates"); err != nil {
+			return fmt.Errorf("failed to update CA certificates: %w", err)
 		}
 
 		fmt.Printf("  Node %s: CA certificates updated\n", containerName)
@@ -818,10 +1119,13 @@ func (kind *KindManager) updateCACertificates(clusterName string) error {
 	return nil
 }
 
+// containerdCertsDir is the containerd v2 registry host configuration directory in kind nodes
+const containerdCertsDir = "/etc/containerd/certs.d"
+
 // configureInsecureRegistries configures containerd to skip TLS verification for specified registries
 // Uses the newer containerd v2 config_path format with hosts.toml files
 // This is done AFTER cluster init to avoid breaking Docker Hub access during kubeadm init
-func (kind *KindManager) configureInsecureRegistries(clusterName string, registries []string) error {
+func (kind *KindManager) configureInsecureRegistries(ctx context.Context, clusterName string, registries []string) error {
 	fmt.Printf("Configuring insecure registries in cluster nodes...\n")
 
 	// Get cluster nodes
@@ -836,14 +1140,16 @@ func (kind *KindManager) configureInsecureRegistries(clusterName string, registr
 
 		// First, update containerd config to use config_path for v2 registry format
 		// This must be done before creating the hosts.toml files
-		configPatch := `[plugins."io.containerd.grpc.v1.cri".registry]
-  config_path = "/etc/containerd/certs.d"`
+		configPatch := fmt.Sprintf(`
+[plugins."io.containerd.grpc.v1.cri".registry]
+  config_path = "%s"
+`, containerdCertsDir)
 
-		patchCmd := osexec.Command("docker", "exec", containerName, "sh", "-c",
-			fmt.Sprintf("cat >> /etc/containerd/config.toml << 'EOF'\n%sEOF", configPatch))
-		if output, err := patchCmd.CombinedOutput(); err != nil {
-			return fmt.Errorf("failed to patch containerd config in node %s: %w\nOutput: %s",
-				containerName, err, string(output))
+		// Only append if not already present so retries and re-runs don't duplicate the section
+		patchScript := fmt.Sprintf("grep -qF 'config_path = \"%s\"' /etc/containerd/config.toml || cat >> /etc/containerd/config.toml",
+			containerdCertsDir)
+		if _, err := nodeExecWithPolicy(ctx, defaultNodeExecPolicy, containerName, []byte(configPatch), "sh", "-c", patchScript); err != nil {
+			return fmt.Errorf("failed to patch containerd config: %w", err)
 		}
 
 		// For each registry, create a hosts.toml file
@@ -856,13 +1162,6 @@ func (kind *KindManager) configureInsecureRegistries(clusterName string, registr
 			}
 			server := fmt.Sprintf("%s://%s", protocol, registry)
 
-			// Create the certs.d directory for this registry
-			mkdirCmd := osexec.Command("docker", "exec", containerName, "mkdir", "-p", fmt.Sprintf("/etc/containerd/certs.d/%s", registry))
-			if output, err := mkdirCmd.CombinedOutput(); err != nil {
-				return fmt.Errorf("failed to create certs.d directory for %s in node %s: %w\nOutput: %s",
-					registry, containerName, err, string(output))
-			}
-
 			// Create hosts.toml content
 			hostsToml := fmt.Sprintf(`server = "%s"
 
@@ -870,20 +1169,16 @@ func (kind *KindManager) configureInsecureRegistries(clusterName string, registr
   skip_verify = true
 `, server, server)
 
-			// Write hosts.toml file
-			writeCmd := osexec.Command("docker", "exec", containerName, "sh", "-c",
-				fmt.Sprintf("cat > /etc/containerd/certs.d/%s/hosts.toml << 'EOF'\n%sEOF", registry, hostsToml))
-			if output, err := writeCmd.CombinedOutput(); err != nil {
-				return fmt.Errorf("failed to write hosts.toml for %s in node %s: %w\nOutput: %s",
-					registry, containerName, err, string(output))
+			// Write hosts.toml file (creates the certs.d directory for this registry)
+			hostsPath := fmt.Sprintf("%s/%s/hosts.toml", containerdCertsDir, registry)
+			if err := nodeWriteFile(ctx, containerName, hostsPath, hostsToml); err != nil {
+				return fmt.Errorf("failed to write hosts.toml for %s: %w", registry, err)
 			}
 		}
 
 		// Reload containerd to pick up the new configuration
-		killCmd := osexec.Command("docker", "exec", containerName, "pkill", "-HUP", "containerd")
-		if output, err := killCmd.CombinedOutput(); err != nil {
-			return fmt.Errorf("failed to reload containerd configuration in node %s: %w\nOutput: %s",
-				containerName, err, string(output))
+		if _, err := nodeExec(ctx, containerName, "pkill", "-HUP", "containerd"); err != nil {
+			return fmt.Errorf("failed to reload containerd configuration: %w", err)
 		}
 	}
 
@@ -893,7 +1188,7 @@ func (kind *KindManager) configureInsecureRegistries(clusterName string, registr
 
 // configureProxy configures containerd to use HTTP/HTTPS proxy
 // This is applied AFTER cluster initialization to avoid breaking kubeadm init
-func (kind *KindManager) configureProxy(clusterName, httpProxy, httpsProxy, noProxy string) error {
+func (kind *KindManager) configureProxy(ctx context.Context, clusterName, httpProxy, httpsProxy, noProxy string) error {
 	fmt.Printf("Configuring proxy settings in cluster nodes...\n")
 
 	// Inform user about proxy configuration source
@@ -911,13 +1206,6 @@ func (kind *KindManager) configureProxy(clusterName, httpProxy, httpsProxy, noPr
 	for _, node := range nodes {
 		containerName := node.String()
 
-		// Create systemd drop-in directory for containerd
-		mkdirCmd := osexec.Command("docker", "exec", containerName, "mkdir", "-p", "/etc/systemd/system/containerd.service.d")
-		if output, err := mkdirCmd.CombinedOutput(); err != nil {
-			return fmt.Errorf("failed to create systemd drop-in directory in node %s: %w\nOutput: %s",
-				containerName, err, string(output))
-		}
-
 		// Create http-proxy.conf file with environment variables
 		var proxyConf strings.Builder
 		proxyConf.WriteString("[Service]\n")
@@ -925,19 +1213,14 @@ func (kind *KindManager) configureProxy(clusterName, httpProxy, httpsProxy, noPr
 		proxyConf.WriteString("Environment=\"HTTPS_PROXY=" + httpsProxy + "\"\n")
 		proxyConf.WriteString("Environment=\"NO_PROXY=" + noProxy + "\"\n")
 
-		// Write the proxy configuration file
-		writeCmd := osexec.Command("docker", "exec", containerName, "sh", "-c",
-			fmt.Sprintf("cat > /etc/systemd/system/containerd.service.d/http-proxy.conf << 'EOF'\n%sEOF", proxyConf.String()))
-		if output, err := writeCmd.CombinedOutput(); err != nil {
-			return fmt.Errorf("failed to write proxy config in node %s: %w\nOutput: %s",
-				containerName, err, string(output))
+		// Write the proxy configuration file into the systemd drop-in directory for containerd
+		if err := nodeWriteFile(ctx, containerName, "/etc/systemd/system/containerd.service.d/http-proxy.conf", proxyConf.String()); err != nil {
+			return fmt.Errorf("failed to write proxy config: %w", err)
 		}
 
 		// Reload systemd daemon to pick up the new drop-in file
-		reloadCmd := osexec.Command("docker", "exec", containerName, "systemctl", "daemon-reload")
-		if output, err := reloadCmd.CombinedOutput(); err != nil {
-			return fmt.Errorf("failed to reload systemd daemon in node %s: %w\nOutput: %s",
-				containerName, err, string(output))
+		if _, err := nodeExec(ctx, containerName, "systemctl", "daemon-reload"); err != nil {
+			return fmt.Errorf("failed to reload systemd daemon: %w", err)
 		}
 
 		// Note: We do NOT restart containerd here because it would kill all running containers
@@ -1043,14 +1326,9 @@ func (kind *KindManager) buildAMDGPUMounts(cfg *config.ClusterConfig) []v1alpha4
 // - Registry certificates with non-standard serial numbers
 func (kind *KindManager) buildGODEBUGMount(clusterName string) (v1alpha4.Mount, error) {
 	// Create a cluster-specific directory in ~/.kraze/clusters/<cluster-name>/
-	homeDir, err := os.UserHomeDir()
+	krazeDir, err := ClusterDataDir(clusterName)
 	if err != nil {
-		return v1alpha4.Mount{}, fmt.Errorf("failed to get user home directory: %w", err)
-	}
-
-	krazeDir := filepath.Join(homeDir, ".kraze", "clusters", clusterName)
-	if err := os.MkdirAll(krazeDir, 0755); err != nil {
-		return v1alpha4.Mount{}, fmt.Errorf("failed to create kraze directory: %w", err)
+		return v1alpha4.Mount{}, err
 	}
 
 	// Create the systemd drop-in file
@@ -1178,10 +1456,6 @@ func (kind *KindManager) registerNvidiaRuntimeClass(ctx context.Context, cluster
 	if err != nil {
 		return fmt.Errorf("failed to build REST config: %w", err)
 	}
-	// Use insecure TLS since kubeconfig uses the container IP (same pattern as UpdateKubeconfigFile)
-	restConfig.TLSClientConfig.Insecure = true
-	restConfig.TLSClientConfig.CAData = nil
-
 	dynClient, err := dynamic.NewForConfig(restConfig)
 	if err != nil {
 		return fmt.Errorf("failed to create dynamic client: %w", err)
@@ -1312,8 +1586,10 @@ func (kind *KindManager) getEffectiveProxyConfig(cfg *config.ClusterConfig) (htt
 //
 // Note: CA certificates are also configured AFTER cluster initialization
 // They are mounted via extraMounts and updated in the post-init phase
-func (kind *KindManager) buildKubeadmConfigPatches(cfg *config.ClusterConfig) []string {
-	var patches []string
+func (kind *KindManager) buildKubeadmConfigPatches(cfg *config.ClusterConfig, networking v1alpha4.Networking) []string {
+	// Issue the API server certificate for the addresses kraze connects to, so
+	// kubeconfigs can verify TLS
+	patches := []string{apiServerCertSANsPatch(apiServerCertSANs(cfg, networking))}
 
 	// Note: We intentionally do NOT configure proxy or CA certificates here
 	// Both are applied after cluster initialization to avoid interfering with kubeadm init
@@ -1365,6 +1641,9 @@ func (kind *KindManager) buildKindNode(node config.KindNode) v1alpha4.Node {
 		kindNode.Labels = node.Labels
 	}
 
+	// Pass the node's kubeadm patches, kubelet reservations and flags to this node only
+	kindNode.KubeadmConfigPatches = nodeKubeadmPatches(node)
+
 	return kindNode
 }
 
@@ -1399,7 +1678,7 @@ func (kind *KindManager) connectToHostNetwork(clusterName string, networkName st
 
 	for _, network := range networksToTry {
 		// Check if network exists
-		checkCmd := osexec.Command("docker", "network", "inspect", network)
+		checkCmd := runtimeCommand("network", "inspect", network)
 		if err := checkCmd.Run(); err != nil {
 			continue
 		}
@@ -1408,10 +1687,10 @@ func (kind *KindManager) connectToHostNetwork(clusterName string, networkName st
 		var connectCmd *osexec.Cmd
 		if ipv4Address != "" {
 			// Connect with static IP
-			connectCmd = osexec.Command("docker", "network", "connect", "--ip", ipv4Address, network, containerName)
+			connectCmd = runtimeCommand("network", "connect", "--ip", ipv4Address, network, containerName)
 		} else {
 			// Connect with dynamic IP
-			connectCmd = osexec.Command("docker", "network", "connect", network, containerName)
+			connectCmd = runtimeCommand("network", "connect", network, containerName)
 		}
 
 		if err := connectCmd.Run(); err != nil {
@@ -1446,7 +1725,7 @@ func (kind *KindManager) connectToHostNetwork(clusterName string, networkName st
 // rather than through the kind-internal network, which may have no NAT.
 func (kind *KindManager) fixDefaultRouteForCluster(clusterName, networkName string) error {
 	// Get the gateway IP for this network
-	out, err := osexec.Command("docker", "network", "inspect", networkName,
+	out, err := runtimeCommand("network", "inspect", networkName,
 		"--format", "{{range .IPAM.Config}}{{.Gateway}}{{end}}").Output()
 	if err != nil {
 		return fmt.Errorf("inspect network %s: %w", networkName, err)
@@ -1457,18 +1736,17 @@ func (kind *KindManager) fixDefaultRouteForCluster(clusterName, networkName stri
 	}
 
 	// Get all nodes in this cluster
-	nodesOut, err := osexec.Command("kind", "get", "nodes", "--name", clusterName).Output()
-	if err != nil {
-		// Fall back to just the control-plane
-		nodesOut = []byte(clusterName + "-control-plane")
+	nodes := []string{clusterName + "-control-plane"}
+	if clusterNodes, err := kind.provider.ListNodes(clusterName); err == nil && len(clusterNodes) > 0 {
+		nodes = nodes[:0]
+		for _, node := range clusterNodes {
+			nodes = append(nodes, node.String())
+		}
 	}
-	nodes := strings.Fields(string(nodesOut))
 
 	for _, node := range nodes {
-		routeOut, err := osexec.Command("docker", "exec", node,
-			"ip", "route", "replace", "default", "via", gateway).CombinedOutput()
-		if err != nil {
-			fmt.Printf("Warning: Could not update default route in node '%s': %v: %s\n", node, err, routeOut)
+		if _, err := nodeExec(context.Background(), node, "ip", "route", "replace", "default", "via", gateway); err != nil {
+			fmt.Printf("Warning: Could not update default route: %v\n", err)
 		} else {
 			fmt.Printf("%s Default route in '%s' updated to use '%s' gateway (%s)\n",
 				color.Checkmark(), node, networkName, gateway)
@@ -1479,7 +1757,7 @@ func (kind *KindManager) fixDefaultRouteForCluster(clusterName, networkName stri
 
 func (kind *KindManager) ensureNetworkExists(networkName string, subnet string) error {
 	// Check if network already exists
-	checkCmd := osexec.Command("docker", "network", "inspect", networkName)
+	checkCmd := runtimeCommand("network", "inspect", networkName)
 	if err := checkCmd.Run(); err == nil {
 		// Network exists
 		return nil
@@ -1491,10 +1769,10 @@ func (kind *KindManager) ensureNetworkExists(networkName string, subnet string)
 		return fmt.Errorf("network '%s' does not exist and no subnet specified", networkName)
 	}
 
-	fmt.Printf("Creating Docker network '%s' with subnet %s...\n", networkName, subnet)
+	fmt.Printf("Creating %s network '%s' with subnet %s...\n", DetectContainerRuntime().DisplayName(), networkName, subnet)
 
 	// Create the network with subnet
-	createCmd := osexec.Command("docker", "network", "create",
+	createCmd := runtimeCommand("network", "create",
 		"--driver", "bridge",
 		"--subnet", subnet,
 		networkName)
@@ -1503,7 +1781,7 @@ func (kind *KindManager) ensureNetworkExists(networkName string, subnet string)
 		return fmt.Errorf("failed to create network: %w\nOutput: %s", err, string(output))
 	}
 
-	fmt.Printf("%s Created Docker network '%s'\n", color.Checkmark(), networkName)
+	fmt.Printf("%s Created %s network '%s'\n", color.Checkmark(), DetectContainerRuntime().DisplayName(), networkName)
 	return nil
 }
 
@@ -1512,26 +1790,36 @@ func (kind *KindManager) ensureNetworkExists(networkName string, subnet string)
 // have outbound internet access via the host's NAT rules.
 // Inside a container (dev container / CI), connects to the container's own networks
 // so the kind cluster is reachable on the same Docker network as the host container.
+// Podman's kind network is NATted by netavark itself, so bare metal Podman hosts
+// need no extra network.
 func (kind *KindManager) detectNetworks() []string {
+	runtime := DetectContainerRuntime()
 	if kind.isRunningInContainer() {
 		// We're in a container - try to get its networks
 		currentNetworks := kind.getCurrentContainerNetworks()
 		if len(currentNetworks) > 0 {
 			// Found networks from current container - use those plus bridge as fallback
-			return append(currentNetworks, "bridge")
+			return append(currentNetworks, runtime.DefaultNetwork())
 		}
 	}
 
+	if runtime == RuntimePodman {
+		return nil
+	}
+
 	// Bare metal host or fallback: use bridge for internet connectivity
 	return []string{"bridge"}
 }
 
 // isRunningInContainer checks if we're running inside a Docker container
 func (kind *KindManager) isRunningInContainer() bool {
-	// Method 1: Check for /.dockerenv file
+	// Method 1: Check for /.dockerenv (Docker) or /run/.containerenv (Podman)
 	if _, err := os.Stat("/.dockerenv"); err == nil {
 		return true
 	}
+	if _, err := os.Stat("/run/.containerenv"); err == nil {
+		return true
+	}
 
 	// Method 2: Check /proc/self/cgroup for docker/containerd
 	data, err := os.ReadFile("/proc/self/cgroup")
@@ -1539,6 +1827,7 @@ func (kind *KindManager) isRunningInContainer() bool {
 		content := string(data)
 		if strings.Contains(content, "/docker/") ||
 			strings.Contains(content, "/containerd/") ||
+			strings.Contains(content, "/libpod-") ||
 			strings.Contains(content, "/kubepods/") {
 			return true
 		}
@@ -1556,7 +1845,7 @@ func (kind *KindManager) getCurrentContainerNetworks() []string {
 	}
 
 	// Try to inspect this container by hostname
-	cmd := osexec.Command("docker", "inspect", hostname,
+	cmd := runtimeCommand("inspect", hostname,
 		"-f", "{{range $net, $config := .NetworkSettings.Networks}}{{$net}} {{end}}")
 
 	output, err := cmd.Output()
@@ -1575,6 +1864,7 @@ func (kind *KindManager) getCurrentContainerNetworks() []string {
 	// Filter out common low-priority networks, preferring user-defined ones
 	filtered := make([]string, 0)
 	var bridgeFound bool
+	defaultNetwork := DetectContainerRuntime().DefaultNetwork()
 
 	for _, net := range networks {
 		// Skip "none" and "host" networks
@@ -1582,7 +1872,7 @@ func (kind *KindManager) getCurrentContainerNetworks() []string {
 			continue
 		}
 		// Prioritize non-bridge networks
-		if net == "bridge" {
+		if net == defaultNetwork {
 			bridgeFound = true
 			continue
 		}
@@ -1591,7 +1881,7 @@ func (kind *KindManager) getCurrentContainerNetworks() []string {
 
 	// If we only found bridge, include it
 	if len(filtered) == 0 && bridgeFound {
-		filtered = append(filtered, "bridge")
+		filtered = append(filtered, defaultNetwork)
 	}
 
 	return filtered
@@ -2,6 +2,9 @@ package cluster
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/hjames9/kraze/internal/config"
@@ -137,6 +140,35 @@ func TestBuildKindNode(test *testing.T) {
 				}
 			},
 		},
+		{
+			name: "node with kubelet reservations",
+			input: config.KindNode{
+				Role:           "worker",
+				SystemReserved: map[string]string{"memory": "1Gi", "cpu": "500m"},
+			},
+			validate: func(test *testing.T, node v1alpha4.Node) {
+				if len(node.KubeadmConfigPatches) != 4 {
+					test.Fatalf("KubeadmConfigPatches: got %d, want 4", len(node.KubeadmConfigPatches))
+				}
+				if !strings.Contains(node.KubeadmConfigPatches[2], `system-reserved: "cpu=500m,memory=1Gi"`) {
+					test.Errorf("KubeadmConfigPatches[2] missing system-reserved:\n%s", node.KubeadmConfigPatches[2])
+				}
+				if !strings.Contains(node.KubeadmConfigPatches[3], "- name: \"system-reserved\"\n      value: \"cpu=500m,memory=1Gi\"") {
+					test.Errorf("KubeadmConfigPatches[3] missing the v1beta4 system-reserved:\n%s", node.KubeadmConfigPatches[3])
+				}
+			},
+		},
+		{
+			name: "node without reservations has no patches",
+			input: config.KindNode{
+				Role: "worker",
+			},
+			validate: func(test *testing.T, node v1alpha4.Node) {
+				if len(node.KubeadmConfigPatches) != 0 {
+					test.Errorf("KubeadmConfigPatches: got %v, want none", node.KubeadmConfigPatches)
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -285,6 +317,101 @@ func TestBuildKindConfig_APIVersion(test *testing.T) {
 	}
 }
 
+func TestBuildKindConfigFromFile(test *testing.T) {
+	km := NewKindManager()
+	dir := test.TempDir()
+	kindConfig := filepath.Join(dir, "kind.yaml")
+	content := `apiVersion: kind.x-k8s.io/v1alpha4
+kind: Cluster
+name: ignored
+featureGates:
+  InPlacePodVerticalScaling: true
+networking:
+  podSubnet: 10.100.0.0/16
+nodes:
+  - role: control-plane
+    image: kindest/node:v1.31.0
+    kubeadmConfigPatches:
+      - |
+        kind: InitConfiguration
+  - role: worker
+    extraMounts:
+      - hostPath: ./data
+        containerPath: /data
+`
+	if err := os.WriteFile(kindConfig, []byte(content), 0644); err != nil {
+		test.Fatal(err)
+	}
+
+	result, err := km.buildKindConfig(&config.ClusterConfig{
+		Name:       "test-cluster",
+		KindConfig: kindConfig,
+		NodeImage:  "kindest/node:v1.32.0",
+		Networking: &config.NetworkingConfig{ServiceSubnet: "10.200.0.0/16"},
+	})
+	if err != nil {
+		test.Fatalf("buildKindConfig failed: %v", err)
+	}
+
+	if result.Name != "test-cluster" {
+		test.Errorf("Name: got %q, want the kraze cluster name", result.Name)
+	}
+	if !result.FeatureGates["InPlacePodVerticalScaling"] {
+		test.Error("FeatureGates: lost the file's feature gates")
+	}
+	if result.Networking.PodSubnet != "10.100.0.0/16" || result.Networking.ServiceSubnet != "10.200.0.0/16" {
+		test.Errorf("Networking: got %+v, want the file's pod subnet and kraze's service subnet", result.Networking)
+	}
+	if len(result.Nodes) != 2 {
+		test.Fatalf("Nodes: got %d, want 2", len(result.Nodes))
+	}
+	if result.Nodes[0].Image != "kindest/node:v1.31.0" || result.Nodes[1].Image != "kindest/node:v1.32.0" {
+		test.Errorf("Images: got %q and %q, want the file's image kept and node_image filling the other", result.Nodes[0].Image, result.Nodes[1].Image)
+	}
+	if len(result.Nodes[0].KubeadmConfigPatches) != 1 {
+		test.Errorf("Node[0].KubeadmConfigPatches: got %d, want the file's patch", len(result.Nodes[0].KubeadmConfigPatches))
+	}
+
+	worker := result.Nodes[1]
+	if worker.ExtraMounts[0].HostPath != filepath.Join(dir, "data") {
+		test.Errorf("Node[1].ExtraMounts[0].HostPath: got %q, want it relative to the kind config", worker.ExtraMounts[0].HostPath)
+	}
+	hasGODEBUG := false
+	for _, mount := range worker.ExtraMounts {
+		if strings.HasSuffix(mount.ContainerPath, "godebug.conf") {
+			hasGODEBUG = true
+		}
+	}
+	if !hasGODEBUG {
+		test.Errorf("Node[1].ExtraMounts: got %+v, want the GODEBUG drop-in", worker.ExtraMounts)
+	}
+}
+
+func TestBuildKindConfigFromFile_Invalid(test *testing.T) {
+	km := NewKindManager()
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "wrong kind", content: "apiVersion: v1\nkind: ConfigMap\n", expected: "is not a kind config"},
+		{name: "unknown field", content: "apiVersion: kind.x-k8s.io/v1alpha4\nkind: Cluster\nnodez: []\n", expected: "failed to parse kind config"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			kindConfig := filepath.Join(test.TempDir(), "kind.yaml")
+			if err := os.WriteFile(kindConfig, []byte(tt.content), 0644); err != nil {
+				test.Fatal(err)
+			}
+			_, err := km.buildKindConfig(&config.ClusterConfig{Name: "test-cluster", KindConfig: kindConfig})
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				test.Errorf("buildKindConfig() error = %v, want %q", err, tt.expected)
+			}
+		})
+	}
+}
+
 func TestPatchKubeconfigWithContainerIP(test *testing.T) {
 	km := NewKindManager()
 
@@ -831,9 +958,9 @@ func TestBuildKindConfigWithCorporateFeatures(test *testing.T) {
 				if len(cluster.Nodes[0].ExtraMounts) != 2 {
 					test.Errorf("Expected 2 extra mounts (CA + GODEBUG), got %d", len(cluster.Nodes[0].ExtraMounts))
 				}
-				// CA certificates are updated post-init, so no kubeadm patches expected
-				if len(cluster.KubeadmConfigPatches) != 0 {
-					test.Errorf("Expected 0 kubeadm config patches, got %d", len(cluster.KubeadmConfigPatches))
+				// CA certificates are updated post-init, so only the API server SANs are patched
+				if len(cluster.KubeadmConfigPatches) != 1 || !strings.Contains(cluster.KubeadmConfigPatches[0], "certSANs") {
+					test.Errorf("Expected only the certSANs kubeadm patch, got %v", cluster.KubeadmConfigPatches)
 				}
 			},
 		},
@@ -896,9 +1023,9 @@ func TestBuildKindConfigWithCorporateFeatures(test *testing.T) {
 				if len(cluster.ContainerdConfigPatches) != 0 {
 					test.Errorf("Expected 0 containerd config patches, got %d", len(cluster.ContainerdConfigPatches))
 				}
-				// Both CA certs and proxy are configured post-init, so no kubeadm patches expected
-				if len(cluster.KubeadmConfigPatches) != 0 {
-					test.Errorf("Expected 0 kubeadm config patches, got %d", len(cluster.KubeadmConfigPatches))
+				// Both CA certs and proxy are configured post-init, so only the API server SANs are patched
+				if len(cluster.KubeadmConfigPatches) != 1 || !strings.Contains(cluster.KubeadmConfigPatches[0], "certSANs") {
+					test.Errorf("Expected only the certSANs kubeadm patch, got %v", cluster.KubeadmConfigPatches)
 				}
 			},
 		},
@@ -1081,3 +1208,90 @@ func TestParseK8sVersion(test *testing.T) {
 		})
 	}
 }
+
+func TestCheckNodeImageVersion(test *testing.T) {
+	km := NewKindManager()
+
+	tests := []struct {
+		name    string
+		cfg     config.ClusterConfig
+		wantErr bool
+	}{
+		{
+			name: "no range configured",
+			cfg:  config.ClusterConfig{Version: "1.20.0"},
+		},
+		{
+			name: "version inside range",
+			cfg:  config.ClusterConfig{Version: "1.31.0", Kubernetes: ">=1.28 <1.32"},
+		},
+		{
+			name:    "node_image outside range",
+			cfg:     config.ClusterConfig{NodeImage: "kindest/node:v1.33.1", Kubernetes: ">=1.28 <1.32"},
+			wantErr: true,
+		},
+		{
+			name:    "default image outside range",
+			cfg:     config.ClusterConfig{Kubernetes: "<1.30"},
+			wantErr: true,
+		},
+		{
+			name: "custom image without version tag is checked later",
+			cfg:  config.ClusterConfig{NodeImage: "registry.corp.com/kind-node:latest", Kubernetes: "<1.30"},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			err := km.CheckNodeImageVersion(&tt.cfg)
+			if (err != nil) != tt.wantErr {
+				test.Errorf("CheckNodeImageVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildKindConfigWithTopology(test *testing.T) {
+	km := NewKindManager()
+
+	cfg := &config.ClusterConfig{
+		Name: "test",
+		Config: []config.KindNode{
+			{Role: "control-plane"},
+			{Role: "worker", Replicas: 3, Labels: map[string]string{"tier": "apps"}},
+		},
+		Topology: &config.TopologyConfig{
+			Zones: []string{"zone-a", "zone-b", "zone-c"},
+			Skew:  map[string]map[string]string{"zone-c": {"memory": "2Gi"}},
+		},
+	}
+	result, err := km.buildKindConfig(cfg)
+	if err != nil {
+		test.Fatalf("buildKindConfig failed: %v", err)
+	}
+	if len(result.Nodes) != 4 {
+		test.Fatalf("Expected 4 nodes, got %d", len(result.Nodes))
+	}
+
+	controlPlane := result.Nodes[0]
+	if _, zoned := controlPlane.Labels[config.ZoneLabel]; zoned {
+		test.Errorf("control-plane should have no zone with workers present, got %v", controlPlane.Labels)
+	}
+	if controlPlane.Labels[config.RegionLabel] != config.DefaultTopologyRegion {
+		test.Errorf("control-plane region: got %q, want %q", controlPlane.Labels[config.RegionLabel], config.DefaultTopologyRegion)
+	}
+
+	for itr, zone := range []string{"zone-a", "zone-b", "zone-c"} {
+		worker := result.Nodes[itr+1]
+		if worker.Labels[config.ZoneLabel] != zone {
+			test.Errorf("worker %d zone: got %q, want %q", itr, worker.Labels[config.ZoneLabel], zone)
+		}
+		if worker.Labels["tier"] != "apps" {
+			test.Errorf("worker %d lost its own labels: %v", itr, worker.Labels)
+		}
+		skewed := len(worker.KubeadmConfigPatches) > 0 && strings.Contains(worker.KubeadmConfigPatches[0], "system-reserved: \"memory=2Gi\"")
+		if skewed != (zone == "zone-c") {
+			test.Errorf("worker %d in %s: skew patches = %q", itr, zone, worker.KubeadmConfigPatches)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package cluster
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// kubeconfigFileName is the per-cluster kubeconfig written for 'kraze shell'
+const kubeconfigFileName = "kubeconfig"
+
+// kubeconfigLockTimeout bounds how long updating a kubeconfig waits for another writer
+const kubeconfigLockTimeout = 10 * time.Second
+
+// WriteIsolatedKubeconfig writes a standalone kubeconfig for the cluster to
+// ~/.kraze/clusters/<cluster-name>/kubeconfig and returns its path.
+// The current context's namespace is set to namespace (if non-empty), so the
+// user's primary kubeconfig is never modified.
+func WriteIsolatedKubeconfig(clusterName, kubeconfigContent, namespace string) (string, error) {
+	content, err := setKubeconfigNamespace(kubeconfigContent, namespace)
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := ClusterDataDir(clusterName)
+	if err != nil {
+		return "", err
+	}
+
+	// Written atomically, since a shell in another terminal may be using it
+	if err := writeFileAtomic(dir, kubeconfigFileName, content); err != nil {
+		return "", fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+	return filepath.Join(dir, kubeconfigFileName), nil
+}
+
+// lockKubeconfig takes the <path>.lock file kubectl uses to serialize changes to
+// a kubeconfig, and returns the function releasing it
+func lockKubeconfig(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(kubeconfigLockTimeout)
+	for {
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			file.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%s is held by another process (remove it if no kubectl or kraze command is running)", lockPath)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// writeKubeconfigFile writes a kubeconfig atomically, so kubectl and kraze
+// commands in other terminals never read a partially written file
+func writeKubeconfigFile(path string, kubeconfig *clientcmdapi.Config) error {
+	content, err := clientcmd.Write(*kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to serialize kubeconfig: %w", err)
+	}
+	// Replace the file a symlinked kubeconfig points to, not the link
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return writeFileAtomic(filepath.Dir(path), filepath.Base(path), content)
+}
+
+// setKubeconfigNamespace sets the namespace of the current context
+func setKubeconfigNamespace(kubeconfigContent, namespace string) ([]byte, error) {
+	kubeconfig, err := clientcmd.Load([]byte(kubeconfigContent))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+
+	if namespace != "" {
+		context, exists := kubeconfig.Contexts[kubeconfig.CurrentContext]
+		if !exists {
+			return nil, fmt.Errorf("kubeconfig has no current context")
+		}
+		context.Namespace = namespace
+	}
+
+	content, err := clientcmd.Write(*kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize kubeconfig: %w", err)
+	}
+	return content, nil
+}
@@ -0,0 +1,108 @@
+package cluster
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://127.0.0.1:6443
+  name: kind-dev
+contexts:
+- context:
+    cluster: kind-dev
+    user: kind-dev
+  name: kind-dev
+current-context: kind-dev
+users:
+- name: kind-dev
+  user:
+    token: abc
+`
+
+func TestSetKubeconfigNamespace(test *testing.T) {
+	tests := []struct {
+		name      string
+		namespace string
+		expected  string
+	}{
+		{name: "set namespace", namespace: "monitoring", expected: "monitoring"},
+		{name: "empty keeps context namespace", namespace: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			content, err := setKubeconfigNamespace(testKubeconfig, tt.namespace)
+			if err != nil {
+				test.Fatalf("unexpected error: %v", err)
+			}
+
+			kubeconfig, err := clientcmd.Load(content)
+			if err != nil {
+				test.Fatalf("result is not a valid kubeconfig: %v", err)
+			}
+			if got := kubeconfig.Contexts["kind-dev"].Namespace; got != tt.expected {
+				test.Errorf("namespace = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSetKubeconfigNamespaceNoCurrentContext(test *testing.T) {
+	if _, err := setKubeconfigNamespace("apiVersion: v1\nkind: Config\n", "default"); err == nil {
+		test.Errorf("expected error for kubeconfig without current context")
+	}
+}
+
+func TestWriteKubeconfigFileFollowsSymlink(test *testing.T) {
+	dir := test.TempDir()
+	target := filepath.Join(dir, "dotfiles-kubeconfig")
+	link := filepath.Join(dir, "config")
+	if err := os.WriteFile(target, []byte("apiVersion: v1\nkind: Config\n"), 0600); err != nil {
+		test.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		test.Skipf("symlinks not supported: %v", err)
+	}
+
+	kubeconfig, err := clientcmd.Load([]byte(testKubeconfig))
+	if err != nil {
+		test.Fatal(err)
+	}
+	if err := writeKubeconfigFile(link, kubeconfig); err != nil {
+		test.Fatalf("writeKubeconfigFile failed: %v", err)
+	}
+
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		test.Errorf("expected %s to still be a symlink", link)
+	}
+	written, err := clientcmd.LoadFromFile(target)
+	if err != nil {
+		test.Fatalf("target is not a valid kubeconfig: %v", err)
+	}
+	if written.CurrentContext != "kind-dev" {
+		test.Errorf("current context = %q, expected kind-dev", written.CurrentContext)
+	}
+}
+
+func TestLockKubeconfig(test *testing.T) {
+	path := filepath.Join(test.TempDir(), ".kube", "config")
+
+	unlock, err := lockKubeconfig(path)
+	if err != nil {
+		test.Fatalf("lockKubeconfig failed: %v", err)
+	}
+	if _, err := os.Stat(path + ".lock"); err != nil {
+		test.Errorf("expected lock file: %v", err)
+	}
+	unlock()
+	if _, err := os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		test.Errorf("expected lock file to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// lockFileName is the per-cluster file held locked while a command changes the cluster
+const lockFileName = "lock"
+
+// lockHolderFileName records which command holds the cluster's lock
+const lockHolderFileName = "lock.json"
+
+// lockPollInterval is how often a waiting command retries a held lock
+const lockPollInterval = 500 * time.Millisecond
+
+// errLockHeld is returned by tryLockFile when another process holds the lock
+var errLockHeld = errors.New("lock is held")
+
+// LockHolder describes the command holding a cluster's lock
+type LockHolder struct {
+	Command string    `json:"command"`
+	PID     int       `json:"pid"`
+	Since   time.Time `json:"since"`
+}
+
+// String describes the holder as "kraze up (pid 123, started 2m ago)"
+func (holder *LockHolder) String() string {
+	return fmt.Sprintf("kraze %s (pid %d, started %s ago)", holder.Command, holder.PID, time.Since(holder.Since).Round(time.Second))
+}
+
+// ClusterLockedError is returned when another command is changing the cluster
+type ClusterLockedError struct {
+	ClusterName string
+	Holder      *LockHolder // nil if the holder couldn't be read
+}
+
+func (err *ClusterLockedError) Error() string {
+	holder := "another kraze command"
+	if err.Holder != nil {
+		holder = err.Holder.String()
+	}
+	return fmt.Sprintf("cluster '%s' is being changed by %s; wait for it to finish or use --wait-for-lock", err.ClusterName, holder)
+}
+
+// ClusterLock is an exclusive lock on a cluster, held by commands that change it
+// (up, down, start, stop, ...) so two of them never run against the same cluster
+// at once. Read-only commands don't take it. The operating system releases the
+// lock when the process exits, so a crashed command never leaves it behind.
+type ClusterLock struct {
+	file *os.File
+}
+
+// AcquireClusterLock takes the cluster's lock for a command. If another command
+// holds it, it fails with a ClusterLockedError, or with wait set, waits until the
+// lock is released or ctx is done, calling onWait once with the holder.
+func AcquireClusterLock(ctx context.Context, clusterName, command string, wait bool, onWait func(holder *LockHolder)) (*ClusterLock, error) {
+	dir, err := ClusterDataDir(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(filepath.Join(dir, lockFileName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cluster lock: %w", err)
+	}
+
+	notified := false
+	for {
+		err := tryLockFile(file)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errLockHeld) {
+			file.Close()
+			return nil, fmt.Errorf("failed to lock cluster: %w", err)
+		}
+
+		holder, _ := readLockHolder(clusterName)
+		if !wait {
+			file.Close()
+			return nil, &ClusterLockedError{ClusterName: clusterName, Holder: holder}
+		}
+		if !notified && onWait != nil {
+			onWait(holder)
+			notified = true
+		}
+
+		select {
+		case <-ctx.Done():
+			file.Close()
+			return nil, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+
+	// Record the holder for commands that find the lock taken. The holder file is
+	// separate from the lock file since Windows doesn't allow reading a locked file.
+	data, err := json.Marshal(&LockHolder{Command: command, PID: os.Getpid(), Since: time.Now()})
+	if err == nil {
+		err = writeFileAtomic(dir, lockHolderFileName, data)
+	}
+	if err != nil {
+		unlockFile(file)
+		file.Close()
+		return nil, fmt.Errorf("failed to record cluster lock holder: %w", err)
+	}
+
+	return &ClusterLock{file: file}, nil
+}
+
+// readLockHolder returns the command that last took the cluster's lock, which
+// only means something while the lock is held
+func readLockHolder(clusterName string) (*LockHolder, error) {
+	dir, err := ClusterDataDir(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, lockHolderFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var holder LockHolder
+	if err := json.Unmarshal(data, &holder); err != nil {
+		return nil, fmt.Errorf("failed to parse cluster lock holder: %w", err)
+	}
+	return &holder, nil
+}
+
+// Release removes the holder record and releases the lock
+func (lock *ClusterLock) Release() {
+	if lock == nil || lock.file == nil {
+		return
+	}
+	os.Remove(filepath.Join(filepath.Dir(lock.file.Name()), lockHolderFileName))
+	unlockFile(lock.file)
+	lock.file.Close()
+	lock.file = nil
+}
@@ -0,0 +1,66 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestClusterLock(test *testing.T) {
+	test.Setenv("HOME", test.TempDir())
+	ctx := context.Background()
+
+	lock, err := AcquireClusterLock(ctx, "lock-test", "up", false, nil)
+	if err != nil {
+		test.Fatalf("AcquireClusterLock failed: %v", err)
+	}
+
+	// A second command fails, naming the holder
+	_, err = AcquireClusterLock(ctx, "lock-test", "down", false, nil)
+	var locked *ClusterLockedError
+	if !errors.As(err, &locked) {
+		test.Fatalf("Expected ClusterLockedError, got %v", err)
+	}
+	if locked.Holder == nil || locked.Holder.Command != "up" {
+		test.Errorf("Expected holder 'up', got %+v", locked.Holder)
+	}
+
+	// Other clusters aren't affected
+	other, err := AcquireClusterLock(ctx, "other-cluster", "up", false, nil)
+	if err != nil {
+		test.Fatalf("Expected lock on another cluster to succeed: %v", err)
+	}
+	other.Release()
+
+	// A waiting command gives up when its context is done
+	waitCtx, cancel := context.WithTimeout(ctx, 2*lockPollInterval)
+	defer cancel()
+	var waitedOn *LockHolder
+	_, err = AcquireClusterLock(waitCtx, "lock-test", "down", true, func(holder *LockHolder) { waitedOn = holder })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		test.Errorf("Expected deadline exceeded, got %v", err)
+	}
+	if waitedOn == nil || waitedOn.Command != "up" {
+		test.Errorf("Expected onWait with holder 'up', got %+v", waitedOn)
+	}
+
+	// A waiting command takes the lock once it's released
+	go func() {
+		time.Sleep(lockPollInterval)
+		lock.Release()
+	}()
+	next, err := AcquireClusterLock(ctx, "lock-test", "down", true, nil)
+	if err != nil {
+		test.Fatalf("Expected lock after release, got %v", err)
+	}
+	holder, err := readLockHolder("lock-test")
+	if err != nil || holder == nil || holder.Command != "down" {
+		test.Errorf("Expected holder 'down', got %+v (%v)", holder, err)
+	}
+	next.Release()
+
+	if holder, _ := readLockHolder("lock-test"); holder != nil {
+		test.Errorf("Expected no holder after release, got %+v", holder)
+	}
+}
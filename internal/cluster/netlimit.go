@@ -0,0 +1,204 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// netLimitDevice is the node interface on the kind network, which image pulls
+// and every other download from outside the cluster arrive on
+const netLimitDevice = "eth0"
+
+// minNetLimitBurst is the smallest police burst in bytes; smaller bursts drop
+// most full-size packets and stall TCP instead of slowing it down
+const minNetLimitBurst = 32 * 1024
+
+// rateUnits are the multipliers (to bits per second) of tc rate units
+var rateUnits = map[string]float64{
+	"bit":  1,
+	"kbit": 1e3,
+	"mbit": 1e6,
+	"gbit": 1e9,
+	"bps":  8,
+	"kbps": 8e3,
+	"mbps": 8e6,
+	"gbps": 8e9,
+}
+
+// ratePattern matches a tc rate such as 1mbit, 512kbit or 2.5mbps
+var ratePattern = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)([a-z]+)$`)
+
+// policeRatePattern finds the rate of a police action in 'tc filter show' output
+var policeRatePattern = regexp.MustCompile(`police .*?rate (\S+)`)
+
+// NodeNetLimit is the bandwidth limit of one node, as reported by NetLimits
+type NodeNetLimit struct {
+	Node string
+	Rate string // tc rate (e.g., 1Mbit), empty when the node isn't limited
+}
+
+// ParseRate parses a tc rate (e.g., 1mbit, 512kbit, 2mbps) into bits per second
+func ParseRate(rate string) (float64, error) {
+	match := ratePattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(rate)))
+	if match == nil {
+		return 0, fmt.Errorf("invalid rate '%s' (expected a number and a unit, e.g., 1mbit, 512kbit or 2mbps)", rate)
+	}
+	unit, ok := rateUnits[match[2]]
+	if !ok {
+		return 0, fmt.Errorf("invalid rate '%s': unknown unit '%s' (expected bit, kbit, mbit, gbit, bps, kbps, mbps or gbps)", rate, match[2])
+	}
+	value, err := strconv.ParseFloat(match[1], 64)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("invalid rate '%s': must be greater than zero", rate)
+	}
+	return value * unit, nil
+}
+
+// netLimitScript returns the shell script that replaces the node's ingress
+// policing with one limiting traffic from outside the given networks to rate.
+// The burst is a tenth of a second of traffic, so short bursts don't stall TCP.
+func netLimitScript(rate string, bitsPerSecond float64, clusterNetworks []*net.IPNet) string {
+	burst := max(int(bitsPerSecond/8/10), minNetLimitBurst)
+
+	lines := []string{
+		"set -e",
+		fmt.Sprintf("tc qdisc del dev %s ingress 2>/dev/null || true", netLimitDevice),
+		fmt.Sprintf("tc qdisc add dev %s handle ffff: ingress", netLimitDevice),
+	}
+	// Traffic between nodes (the API server, pod-to-pod) passes unthrottled
+	for _, network := range clusterNetworks {
+		protocol, match := "ip", "ip"
+		if network.IP.To4() == nil {
+			protocol, match = "ipv6", "ip6"
+		}
+		lines = append(lines, fmt.Sprintf("tc filter add dev %s parent ffff: protocol %s prio 1 u32 match %s src %s action pass",
+			netLimitDevice, protocol, match, network.String()))
+	}
+	for _, protocol := range []string{"ip", "ipv6"} {
+		lines = append(lines, fmt.Sprintf("tc filter add dev %s parent ffff: protocol %s prio 10 u32 match u32 0 0 police rate %s burst %d drop flowid :1",
+			netLimitDevice, protocol, rate, burst))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// nodeNetworks returns the networks of the node's addresses on the kind network
+func nodeNetworks(output string) []*net.IPNet {
+	var networks []*net.IPNet
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		for itr := 0; itr+1 < len(fields); itr++ {
+			if fields[itr] != "inet" && fields[itr] != "inet6" {
+				continue
+			}
+			ip, network, err := net.ParseCIDR(fields[itr+1])
+			if err != nil || ip.IsLinkLocalUnicast() {
+				continue
+			}
+			networks = append(networks, network)
+		}
+	}
+	return networks
+}
+
+// LimitBandwidth throttles the traffic the named nodes (all when none are
+// named) receive from outside the kind network, such as image pulls, to rate.
+// Traffic between nodes isn't limited. Limits last until UnlimitBandwidth or
+// the node restarts.
+func (kind *KindManager) LimitBandwidth(ctx context.Context, clusterName, rate string, nodeNames []string) ([]string, error) {
+	bitsPerSecond, err := ParseRate(rate)
+	if err != nil {
+		return nil, err
+	}
+	rate = strings.ToLower(strings.TrimSpace(rate))
+	nodes, err := kind.netLimitNodes(clusterName, nodeNames)
+	if err != nil {
+		return nil, err
+	}
+
+	var limited []string
+	for _, node := range nodes {
+		addrs, err := nodeExecWithPolicy(ctx, defaultNodeExecPolicy, node, nil, "ip", "-o", "addr", "show", "dev", netLimitDevice)
+		if err != nil {
+			return limited, fmt.Errorf("failed to read the addresses of node %s: %w", node, err)
+		}
+		networks := nodeNetworks(addrs)
+		if len(networks) == 0 {
+			return limited, fmt.Errorf("node %s has no address on %s", node, netLimitDevice)
+		}
+		if _, err := nodeExecWithPolicy(ctx, defaultNodeExecPolicy, node, nil, "sh", "-c", netLimitScript(rate, bitsPerSecond, networks)); err != nil {
+			return limited, fmt.Errorf("failed to limit the bandwidth of node %s: %w", node, err)
+		}
+		limited = append(limited, node)
+	}
+	return limited, nil
+}
+
+// UnlimitBandwidth removes the bandwidth limits of the named nodes (all when
+// none are named)
+func (kind *KindManager) UnlimitBandwidth(ctx context.Context, clusterName string, nodeNames []string) ([]string, error) {
+	nodes, err := kind.netLimitNodes(clusterName, nodeNames)
+	if err != nil {
+		return nil, err
+	}
+
+	script := fmt.Sprintf("tc qdisc del dev %s ingress 2>/dev/null || true", netLimitDevice)
+	var unlimited []string
+	for _, node := range nodes {
+		if _, err := nodeExecWithPolicy(ctx, defaultNodeExecPolicy, node, nil, "sh", "-c", script); err != nil {
+			return unlimited, fmt.Errorf("failed to remove the bandwidth limit of node %s: %w", node, err)
+		}
+		unlimited = append(unlimited, node)
+	}
+	return unlimited, nil
+}
+
+// NetLimits returns the bandwidth limit of every node of the cluster
+func (kind *KindManager) NetLimits(ctx context.Context, clusterName string) ([]NodeNetLimit, error) {
+	nodes, err := kind.netLimitNodes(clusterName, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	limits := make([]NodeNetLimit, 0, len(nodes))
+	for _, node := range nodes {
+		// Nodes that were never limited have no ingress qdisc to show filters of
+		script := fmt.Sprintf("tc filter show dev %s parent ffff: 2>/dev/null || true", netLimitDevice)
+		output, err := nodeExecWithPolicy(ctx, defaultNodeExecPolicy, node, nil, "sh", "-c", script)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the bandwidth limit of node %s: %w", node, err)
+		}
+		limit := NodeNetLimit{Node: node}
+		if match := policeRatePattern.FindStringSubmatch(output); match != nil {
+			limit.Rate = match[1]
+		}
+		limits = append(limits, limit)
+	}
+	return limits, nil
+}
+
+// netLimitNodes returns the names of the named nodes of the cluster, or of all
+// of them when none are named
+func (kind *KindManager) netLimitNodes(clusterName string, nodeNames []string) ([]string, error) {
+	nodes, err := kind.provider.ListInternalNodes(clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster nodes: %w", err)
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("no nodes found in cluster '%s'", clusterName)
+	}
+	if len(nodeNames) > 0 {
+		if nodes = selectNodes(nodes, nodeNames); len(nodes) == 0 {
+			return nil, fmt.Errorf("none of the nodes %s found in cluster '%s'", strings.Join(nodeNames, ", "), clusterName)
+		}
+	}
+
+	names := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		names = append(names, node.String())
+	}
+	return names, nil
+}
@@ -0,0 +1,74 @@
+package cluster
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestParseRate(test *testing.T) {
+	tests := []struct {
+		rate     string
+		expected float64
+		wantErr  bool
+	}{
+		{rate: "1mbit", expected: 1e6},
+		{rate: "512kbit", expected: 512e3},
+		{rate: "2.5Mbps", expected: 20e6},
+		{rate: "100bit", expected: 100},
+		{rate: "0mbit", wantErr: true},
+		{rate: "1mb", wantErr: true},
+		{rate: "fast", wantErr: true},
+		{rate: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.rate, func(test *testing.T) {
+			got, err := ParseRate(tt.rate)
+			if (err != nil) != tt.wantErr {
+				test.Fatalf("ParseRate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				test.Errorf("ParseRate() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNodeNetworks(test *testing.T) {
+	output := `12: eth0    inet 172.18.0.3/16 brd 172.18.255.255 scope global eth0\       valid_lft forever preferred_lft forever
+12: eth0    inet6 fc00:f853:ccd:e793::3/64 scope global nodad \       valid_lft forever preferred_lft forever
+12: eth0    inet6 fe80::42:acff:fe12:3/64 scope link \       valid_lft forever preferred_lft forever
+`
+	networks := nodeNetworks(output)
+	var got []string
+	for _, network := range networks {
+		got = append(got, network.String())
+	}
+	if expected := "172.18.0.0/16 fc00:f853:ccd:e793::/64"; strings.Join(got, " ") != expected {
+		test.Errorf("nodeNetworks() = %v, want %s", got, expected)
+	}
+}
+
+func TestNetLimitScript(test *testing.T) {
+	_, v4, _ := net.ParseCIDR("172.18.0.0/16")
+	_, v6, _ := net.ParseCIDR("fc00:f853:ccd:e793::/64")
+
+	script := netLimitScript("1mbit", 1e6, []*net.IPNet{v4, v6})
+	for _, expected := range []string{
+		"tc qdisc add dev eth0 handle ffff: ingress",
+		"protocol ip prio 1 u32 match ip src 172.18.0.0/16 action pass",
+		"protocol ipv6 prio 1 u32 match ip6 src fc00:f853:ccd:e793::/64 action pass",
+		"protocol ip prio 10 u32 match u32 0 0 police rate 1mbit burst 32768 drop",
+		"protocol ipv6 prio 10 u32 match u32 0 0 police rate 1mbit burst 32768 drop",
+	} {
+		if !strings.Contains(script, expected) {
+			test.Errorf("script is missing %q:\n%s", expected, script)
+		}
+	}
+
+	// Fast rates get a tenth of a second of burst
+	if script := netLimitScript("100mbit", 100e6, []*net.IPNet{v4}); !strings.Contains(script, "burst 1250000 ") {
+		test.Errorf("script for 100mbit:\n%s", script)
+	}
+}
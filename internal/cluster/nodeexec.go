@@ -0,0 +1,171 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	osexec "os/exec"
+	"path"
+	"strings"
+	"time"
+)
+
+// NodeExecError is returned when a command run inside a kind node fails.
+// It carries the captured stderr and exit code so callers can report or
+// inspect the failure instead of parsing combined output.
+type NodeExecError struct {
+	Node     string   // Node container name
+	Command  []string // Command run inside the node
+	ExitCode int      // Exit code of the runtime exec (-1 if it could not be started)
+	Stderr   string   // Captured stderr of the last attempt
+	Attempts int      // Number of attempts made
+	Err      error    // Underlying error
+}
+
+func (err *NodeExecError) Error() string {
+	msg := fmt.Sprintf("command %q failed in node %s", strings.Join(err.Command, " "), err.Node)
+	if err.Attempts > 1 {
+		msg += fmt.Sprintf(" after %d attempts", err.Attempts)
+	}
+	msg += fmt.Sprintf(": %v", err.Err)
+	if err.Stderr != "" {
+		msg += fmt.Sprintf("\nOutput: %s", err.Stderr)
+	}
+	return msg
+}
+
+func (err *NodeExecError) Unwrap() error {
+	return err.Err
+}
+
+// nodeExecPolicy controls retries for node exec operations
+type nodeExecPolicy struct {
+	Attempts       int           // Total attempts including the first
+	InitialBackoff time.Duration // Delay before the first retry
+	MaxBackoff     time.Duration // Upper bound for the exponential backoff
+	Timeout        time.Duration // Per-attempt timeout (0 = none)
+}
+
+// defaultNodeExecPolicy covers the window right after boot where nodes accept
+// exec but systemd, containerd or the filesystem are not fully ready
+var defaultNodeExecPolicy = nodeExecPolicy{
+	Attempts:       5,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     8 * time.Second,
+	Timeout:        30 * time.Second,
+}
+
+// backoff returns the delay before the given retry (1-based)
+func (policy nodeExecPolicy) backoff(retry int) time.Duration {
+	delay := policy.InitialBackoff
+	for i := 1; i < retry; i++ {
+		delay *= 2
+		if delay >= policy.MaxBackoff {
+			return policy.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// isPermanentExecFailure returns true for exit codes that retrying cannot fix:
+// 126 (command not executable) and 127 (command not found)
+func isPermanentExecFailure(exitCode int) bool {
+	return exitCode == 126 || exitCode == 127
+}
+
+// nodeExec runs a command inside a kind node with bounded retries and
+// exponential backoff, returning stdout. Commands must be idempotent since
+// they may run more than once.
+func nodeExec(ctx context.Context, node string, args ...string) (string, error) {
+	return nodeExecWithPolicy(ctx, defaultNodeExecPolicy, node, nil, args...)
+}
+
+// nodeExecWithPolicy runs a command inside a kind node using the given retry policy.
+// If stdin is non-nil it is passed to the command on every attempt.
+func nodeExecWithPolicy(ctx context.Context, policy nodeExecPolicy, node string, stdin []byte, args ...string) (string, error) {
+	runtimeArgs := []string{"exec"}
+	if stdin != nil {
+		runtimeArgs = append(runtimeArgs, "-i")
+	}
+	runtimeArgs = append(runtimeArgs, node)
+	runtimeArgs = append(runtimeArgs, args...)
+
+	execErr := &NodeExecError{Node: node, Command: args}
+
+	for attempt := 1; attempt <= policy.Attempts; attempt++ {
+		execErr.Attempts = attempt
+
+		stdout, stderr, exitCode, err := runRuntimeAttempt(ctx, policy.Timeout, stdin, runtimeArgs)
+		if err == nil {
+			return stdout, nil
+		}
+
+		execErr.ExitCode = exitCode
+		execErr.Stderr = strings.TrimSpace(stderr)
+		execErr.Err = err
+
+		if isPermanentExecFailure(exitCode) || attempt == policy.Attempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			execErr.Err = ctx.Err()
+			return "", execErr
+		case <-time.After(policy.backoff(attempt)):
+		}
+	}
+
+	return "", execErr
+}
+
+// runRuntimeAttempt runs a single container runtime command with an optional timeout
+func runRuntimeAttempt(ctx context.Context, timeout time.Duration, stdin []byte, runtimeArgs []string) (string, string, int, error) {
+	attemptCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		attemptCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd := runtimeCommandContext(attemptCtx, runtimeArgs...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+
+	err := cmd.Run()
+	if err == nil {
+		return stdout.String(), stderr.String(), 0, nil
+	}
+
+	if attemptCtx.Err() == context.DeadlineExceeded {
+		return stdout.String(), stderr.String(), -1, fmt.Errorf("timed out after %v", timeout)
+	}
+
+	exitCode := -1
+	var exitErr *osexec.ExitError
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitCode()
+	}
+	return stdout.String(), stderr.String(), exitCode, err
+}
+
+// nodeTest runs a check command (such as "test -d <path>") inside a node and
+// reports whether it succeeded. Checks are not retried since a non-zero exit
+// is an expected answer rather than a failure.
+func nodeTest(ctx context.Context, node string, args ...string) bool {
+	_, _, _, err := runRuntimeAttempt(ctx, defaultNodeExecPolicy.Timeout, nil, append([]string{"exec", node}, args...))
+	return err == nil
+}
+
+// nodeWriteFile writes content to a file inside a node, creating parent
+// directories as needed. Overwriting makes it safe to retry.
+func nodeWriteFile(ctx context.Context, node, filePath, content string) error {
+	script := fmt.Sprintf("mkdir -p '%s' && cat > '%s'", path.Dir(filePath), filePath)
+	_, err := nodeExecWithPolicy(ctx, defaultNodeExecPolicy, node, []byte(content), "sh", "-c", script)
+	return err
+}
@@ -0,0 +1,80 @@
+package cluster
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNodeExecPolicyBackoff(test *testing.T) {
+	policy := nodeExecPolicy{
+		Attempts:       5,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     3 * time.Second,
+	}
+
+	tests := []struct {
+		name     string
+		retry    int
+		expected time.Duration
+	}{
+		{name: "first retry", retry: 1, expected: 500 * time.Millisecond},
+		{name: "second retry doubles", retry: 2, expected: time.Second},
+		{name: "third retry doubles", retry: 3, expected: 2 * time.Second},
+		{name: "capped at max", retry: 4, expected: 3 * time.Second},
+		{name: "stays capped", retry: 10, expected: 3 * time.Second},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := policy.backoff(tt.retry); got != tt.expected {
+				test.Errorf("backoff(%d) = %v, expected %v", tt.retry, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsPermanentExecFailure(test *testing.T) {
+	tests := []struct {
+		name     string
+		exitCode int
+		expected bool
+	}{
+		{name: "generic failure", exitCode: 1, expected: false},
+		{name: "docker could not start", exitCode: -1, expected: false},
+		{name: "not executable", exitCode: 126, expected: true},
+		{name: "command not found", exitCode: 127, expected: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := isPermanentExecFailure(tt.exitCode); got != tt.expected {
+				test.Errorf("isPermanentExecFailure(%d) = %v, expected %v", tt.exitCode, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNodeExecErrorMessage(test *testing.T) {
+	cause := errors.New("exit status 1")
+	err := &NodeExecError{
+		Node:     "dev-control-plane",
+		Command:  []string{"mkdir", "-p", "/tmp/x"},
+		ExitCode: 1,
+		Stderr:   "read-only file system",
+		Attempts: 3,
+		Err:      cause,
+	}
+
+	msg := err.Error()
+	for _, want := range []string{"mkdir -p /tmp/x", "dev-control-plane", "after 3 attempts", "read-only file system"} {
+		if !strings.Contains(msg, want) {
+			test.Errorf("Error() = %q, expected it to contain %q", msg, want)
+		}
+	}
+
+	if !errors.Is(err, cause) {
+		test.Errorf("expected NodeExecError to unwrap to the underlying error")
+	}
+}
@@ -0,0 +1,123 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/config"
+	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// sysctlConfPath is where the node sysctls are written, so they're kept with
+// the node container and can be applied again when it restarts
+const sysctlConfPath = "/etc/sysctl.d/99-kraze.conf"
+
+// applyPassthrough adds the cluster-wide settings kraze passes to kind as is:
+// feature gates, API server runtime config, kubeadm patches and the kubelet
+// configuration
+func applyPassthrough(kindCfg *v1alpha4.Cluster, cfg *config.ClusterConfig) error {
+	if len(cfg.FeatureGates) > 0 {
+		if kindCfg.FeatureGates == nil {
+			kindCfg.FeatureGates = make(map[string]bool, len(cfg.FeatureGates))
+		}
+		maps.Copy(kindCfg.FeatureGates, cfg.FeatureGates)
+	}
+	if len(cfg.RuntimeConfig) > 0 {
+		if kindCfg.RuntimeConfig == nil {
+			kindCfg.RuntimeConfig = make(map[string]string, len(cfg.RuntimeConfig))
+		}
+		maps.Copy(kindCfg.RuntimeConfig, cfg.RuntimeConfig)
+	}
+	kindCfg.KubeadmConfigPatches = append(kindCfg.KubeadmConfigPatches, cfg.KubeadmConfigPatches...)
+
+	patch, err := kubeletConfigPatch(cfg.KubeletConfig)
+	if err != nil {
+		return err
+	}
+	if patch != "" {
+		kindCfg.KubeadmConfigPatches = append(kindCfg.KubeadmConfigPatches, patch)
+	}
+	return nil
+}
+
+// kubeletConfigPatch returns a kubeadm patch setting the KubeletConfiguration
+// fields of every node, or "" when none are set
+func kubeletConfigPatch(fields map[string]interface{}) (string, error) {
+	if len(fields) == 0 {
+		return "", nil
+	}
+	data, err := sigsyaml.Marshal(fields)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode kubelet_config: %w", err)
+	}
+	return "kind: KubeletConfiguration\n" + string(data), nil
+}
+
+// nodeKubeadmPatches returns the kubeadm patches of a node: its own, then the
+// ones passing its kubelet flags for each kubeadm API version
+func nodeKubeadmPatches(node config.KindNode) []string {
+	patches := slices.Clone(node.KubeadmConfigPatches)
+	return append(patches, kubeletArgsPatches(node)...)
+}
+
+// nodeSysctls returns the sysctls of each node of cluster.config by the
+// container name kind gives it. Nodes are named in creation order with
+// replicas expanded: the first node of a role after the role and the next ones
+// numbered from 2 (e.g., dev-worker, dev-worker2).
+func nodeSysctls(clusterName string, nodes []config.KindNode) map[string]map[string]string {
+	sysctls := make(map[string]map[string]string)
+	counts := make(map[string]int)
+	for _, node := range nodes {
+		role := node.Role
+		if role != "worker" {
+			role = "control-plane"
+		}
+		for range max(node.Replicas, 1) {
+			counts[role]++
+			name := clusterName + "-" + role
+			if counts[role] > 1 {
+				name += fmt.Sprint(counts[role])
+			}
+			if len(node.Sysctls) > 0 {
+				sysctls[name] = node.Sysctls
+			}
+		}
+	}
+	return sysctls
+}
+
+// sysctlConf formats sysctls as a sysctl.d file, sorted by name
+func sysctlConf(sysctls map[string]string) string {
+	var conf strings.Builder
+	conf.WriteString("# Written by kraze from cluster.config sysctls\n")
+	for _, name := range slices.Sorted(maps.Keys(sysctls)) {
+		fmt.Fprintf(&conf, "%s = %s\n", name, sysctls[name])
+	}
+	return conf.String()
+}
+
+// ApplyNodeSysctls sets the sysctls of each node of cluster.config. They're
+// written to a sysctl.d file in the node and applied from it, so they can be
+// applied again after the node restarts. Sysctls that aren't namespaced (most
+// vm.*, fs.* and kernel.*) change the Docker host's kernel, shared by every
+// node.
+func (kind *KindManager) ApplyNodeSysctls(ctx context.Context, cfg *config.ClusterConfig) error {
+	if cfg.KindConfig != "" {
+		// A kind config file can't set sysctls
+		return nil
+	}
+	sysctls := nodeSysctls(cfg.Name, cfg.Config)
+	for _, name := range slices.Sorted(maps.Keys(sysctls)) {
+		if _, err := nodeExecWithPolicy(ctx, defaultNodeExecPolicy, name, []byte(sysctlConf(sysctls[name])), "tee", sysctlConfPath); err != nil {
+			return fmt.Errorf("failed to write sysctls to node %s: %w", name, err)
+		}
+		if _, err := nodeExec(ctx, name, "sysctl", "-p", sysctlConfPath); err != nil {
+			return fmt.Errorf("failed to apply sysctls in node %s: %w", name, err)
+		}
+	}
+	return nil
+}
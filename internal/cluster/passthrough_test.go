@@ -0,0 +1,115 @@
+package cluster
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+)
+
+func TestBuildKindConfigWithPassthrough(test *testing.T) {
+	km := NewKindManager()
+	nodePatch := "kind: JoinConfiguration\nnodeRegistration:\n  taints: []\n"
+	cfg := &config.ClusterConfig{
+		Name:                 "test",
+		FeatureGates:         map[string]bool{"InPlacePodVerticalScaling": true},
+		RuntimeConfig:        map[string]string{"api/alpha": "true"},
+		KubeadmConfigPatches: []string{"kind: ClusterConfiguration\napiServer:\n  extraArgs:\n    v: \"4\"\n"},
+		KubeletConfig:        map[string]interface{}{"maxPods": 250},
+		Config: []config.KindNode{
+			{Role: "control-plane"},
+			{Role: "worker", KubeadmConfigPatches: []string{nodePatch}, KubeletExtraArgs: map[string]string{"max-pods": "150"}},
+		},
+	}
+
+	cluster, err := km.buildKindConfig(cfg)
+	if err != nil {
+		test.Fatalf("buildKindConfig() error: %v", err)
+	}
+	if !reflect.DeepEqual(cluster.FeatureGates, cfg.FeatureGates) {
+		test.Errorf("FeatureGates = %v, want %v", cluster.FeatureGates, cfg.FeatureGates)
+	}
+	if !reflect.DeepEqual(cluster.RuntimeConfig, cfg.RuntimeConfig) {
+		test.Errorf("RuntimeConfig = %v, want %v", cluster.RuntimeConfig, cfg.RuntimeConfig)
+	}
+
+	// The certSANs patch, the cluster's own patch, then the kubelet configuration
+	if len(cluster.KubeadmConfigPatches) != 3 {
+		test.Fatalf("KubeadmConfigPatches = %q, want 3 patches", cluster.KubeadmConfigPatches)
+	}
+	if cluster.KubeadmConfigPatches[1] != cfg.KubeadmConfigPatches[0] {
+		test.Errorf("KubeadmConfigPatches[1] = %q, want the cluster's patch", cluster.KubeadmConfigPatches[1])
+	}
+	if want := "kind: KubeletConfiguration\nmaxPods: 250\n"; cluster.KubeadmConfigPatches[2] != want {
+		test.Errorf("KubeadmConfigPatches[2] = %q, want %q", cluster.KubeadmConfigPatches[2], want)
+	}
+
+	worker := cluster.Nodes[1]
+	if len(worker.KubeadmConfigPatches) != 5 || worker.KubeadmConfigPatches[0] != nodePatch {
+		test.Fatalf("worker patches = %q, want its own patch then the kubelet flags", worker.KubeadmConfigPatches)
+	}
+	if !strings.Contains(worker.KubeadmConfigPatches[1], `max-pods: "150"`) {
+		test.Errorf("worker patches = %q, want max-pods", worker.KubeadmConfigPatches)
+	}
+	if want := "apiVersion: kubeadm.k8s.io/v1beta4\nkind: InitConfiguration\nnodeRegistration:\n  kubeletExtraArgs:\n    - name: \"max-pods\"\n      value: \"150\"\n"; worker.KubeadmConfigPatches[2] != want {
+		test.Errorf("worker patches[2] = %q, want the v1beta4 form %q", worker.KubeadmConfigPatches[2], want)
+	}
+	if len(cluster.Nodes[0].KubeadmConfigPatches) != 0 {
+		test.Errorf("control-plane patches = %q, want none", cluster.Nodes[0].KubeadmConfigPatches)
+	}
+}
+
+func TestNodeSysctls(test *testing.T) {
+	watches := map[string]string{"fs.inotify.max_user_watches": "524288"}
+	forwarding := map[string]string{"net.ipv4.ip_forward": "1"}
+
+	tests := []struct {
+		name     string
+		nodes    []config.KindNode
+		expected map[string]map[string]string
+	}{
+		{
+			name:     "no sysctls",
+			nodes:    []config.KindNode{{Role: "control-plane"}, {Role: "worker", Replicas: 2}},
+			expected: map[string]map[string]string{},
+		},
+		{
+			name: "numbered replicas",
+			nodes: []config.KindNode{
+				{Role: "control-plane", Sysctls: forwarding},
+				{Role: "worker", Replicas: 2, Sysctls: watches},
+			},
+			expected: map[string]map[string]string{
+				"dev-control-plane": forwarding,
+				"dev-worker":        watches,
+				"dev-worker2":       watches,
+			},
+		},
+		{
+			name: "roles counted across entries",
+			nodes: []config.KindNode{
+				{Role: "control-plane"},
+				{Role: "worker"},
+				{Role: "worker", Sysctls: watches},
+			},
+			expected: map[string]map[string]string{"dev-worker2": watches},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if sysctls := nodeSysctls("dev", tt.nodes); !reflect.DeepEqual(sysctls, tt.expected) {
+				test.Errorf("nodeSysctls() = %v, want %v", sysctls, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSysctlConf(test *testing.T) {
+	conf := sysctlConf(map[string]string{"vm.max_map_count": "262144", "fs.inotify.max_user_watches": "524288"})
+	want := "# Written by kraze from cluster.config sysctls\nfs.inotify.max_user_watches = 524288\nvm.max_map_count = 262144\n"
+	if conf != want {
+		test.Errorf("sysctlConf() = %q, want %q", conf, want)
+	}
+}
@@ -0,0 +1,89 @@
+package cluster
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/hjames9/kraze/internal/config"
+)
+
+// dockerHubServer is the server key Docker uses for Docker Hub credentials
+const dockerHubServer = "https://index.docker.io/v1/"
+
+// pullImageWithCredentials pulls an image with a configured registry's
+// credentials, without storing them in the user's Docker or Podman config
+func pullImageWithCredentials(ctx context.Context, pullRef string, reg *config.RegistryConfig) error {
+	password, err := reg.ResolvePassword(ctx)
+	if err != nil {
+		return err
+	}
+	host := config.NormalizeRegistryHost(reg.Host)
+
+	if DetectContainerRuntime() == RuntimePodman {
+		return podmanPullWithAuthFile(ctx, pullRef, host, reg.Username, password)
+	}
+
+	cli, err := getDockerClientWithFallback(ctx)
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	server := host
+	if host == "docker.io" {
+		server = dockerHubServer
+	}
+	auth, err := registry.EncodeAuthConfig(registry.AuthConfig{Username: reg.Username, Password: password, ServerAddress: server})
+	if err != nil {
+		return fmt.Errorf("failed to encode credentials for %s: %w", host, err)
+	}
+	reader, err := cli.ImagePull(ctx, pullRef, image.PullOptions{RegistryAuth: auth})
+	if err != nil {
+		return fmt.Errorf("failed to pull image: %w", err)
+	}
+	defer reader.Close()
+
+	// Pull errors (e.g., denied) arrive in the progress stream
+	if err := jsonmessage.DisplayJSONMessagesStream(reader, io.Discard, 0, false, nil); err != nil {
+		return fmt.Errorf("failed to pull image: %w", err)
+	}
+	return nil
+}
+
+// podmanPullWithAuthFile pulls an image with Podman, passing the credentials
+// in a temporary auth file so they don't show up in the process list
+func podmanPullWithAuthFile(ctx context.Context, pullRef, host, username, password string) error {
+	dir, err := os.MkdirTemp("", "kraze-auth-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	content, err := json.Marshal(dockerConfigFile{Auths: map[string]dockerAuthEntry{
+		host: {Auth: base64.StdEncoding.EncodeToString([]byte(username + ":" + password))},
+	}})
+	if err != nil {
+		return fmt.Errorf("failed to encode credentials for %s: %w", host, err)
+	}
+	authFile := filepath.Join(dir, "auth.json")
+	if err := os.WriteFile(authFile, content, 0600); err != nil {
+		return fmt.Errorf("failed to write auth file: %w", err)
+	}
+
+	cmd := runtimeCommandContext(ctx, "pull", "--authfile", authFile, pullRef)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to pull image: %w\n%s", err, stderr.String())
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hjames9/kraze/internal/color"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Readiness poll intervals and limits used while creating a cluster. Polls return
+// as soon as their condition holds, so these only bound how long a slow machine
+// may take before the step is reported as failed.
+const (
+	nodeContainerPollInterval = 250 * time.Millisecond
+	nodeContainerTimeout      = 2 * time.Minute
+	apiServerPollInterval     = 500 * time.Millisecond
+	apiServerTimeout          = 2 * time.Minute
+	systemdPollInterval       = 500 * time.Millisecond
+	systemdTimeout            = time.Minute
+)
+
+// nodeUnits are the systemd units that must be active in a node before it is
+// reconfigured after kubeadm init
+var nodeUnits = []string{"containerd", "kubelet"}
+
+// pollUntil calls condition every interval until it reports done, returns an
+// error, or ctx ends. The condition is checked once immediately. When ctx ends,
+// the last condition error (if any) is wrapped into the returned error.
+func pollUntil(ctx context.Context, interval time.Duration, condition func(ctx context.Context) (bool, error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		done, err := condition(ctx)
+		if done {
+			return nil
+		}
+		if err != nil {
+			lastErr = err
+		}
+
+		select {
+		case <-ctx.Done():
+			if lastErr != nil {
+				return fmt.Errorf("%w: %w", ctx.Err(), lastErr)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// systemdBooted reports whether `systemctl is-system-running` output means
+// systemd is up as PID 1 and accepting commands. A degraded system still runs
+// units, so it counts as booted.
+func systemdBooted(state string) bool {
+	switch strings.TrimSpace(state) {
+	case "initializing", "starting", "running", "degraded":
+		return true
+	}
+	return false
+}
+
+// parseUnitStates pairs the output of `systemctl is-active <units>` (one state
+// per line, in order) with the units, returning the ones that are not active
+func parseUnitStates(units []string, output string) []string {
+	states := strings.Split(strings.TrimSpace(output), "\n")
+	var inactive []string
+	for idx, unit := range units {
+		if idx >= len(states) || strings.TrimSpace(states[idx]) != "active" {
+			inactive = append(inactive, unit)
+		}
+	}
+	return inactive
+}
+
+// nodeOutput runs a command inside a node once and returns its stdout even when
+// the command exits non-zero, for status commands that report through both
+func nodeOutput(ctx context.Context, node string, args ...string) string {
+	stdout, _, _, _ := runRuntimeAttempt(ctx, defaultNodeExecPolicy.Timeout, nil, append([]string{"exec", node}, args...))
+	return stdout
+}
+
+// waitForNodeContainers waits until every node container of the cluster exists,
+// is running and has booted systemd. This is the earliest point the kubelet
+// cgroup workaround can be applied, and it comes well before kubeadm starts the
+// kubelet. Cancelling ctx (for example when kind's create returns) stops the wait.
+func (kind *KindManager) waitForNodeContainers(ctx context.Context, clusterName string) error {
+	ctx, cancel := context.WithTimeout(ctx, nodeContainerTimeout)
+	defer cancel()
+
+	err := pollUntil(ctx, nodeContainerPollInterval, func(ctx context.Context) (bool, error) {
+		nodes, err := kind.provider.ListInternalNodes(clusterName)
+		if err != nil {
+			return false, err
+		}
+		if len(nodes) == 0 {
+			return false, fmt.Errorf("no node containers yet")
+		}
+
+		for _, node := range nodes {
+			containerName := node.String()
+			if !systemdBooted(nodeOutput(ctx, containerName, "systemctl", "is-system-running")) {
+				return false, fmt.Errorf("node %s has not booted yet", containerName)
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("node containers not ready: %w", err)
+	}
+	return nil
+}
+
+// waitForAPIServer polls the API server's /readyz endpoint through the kubeconfig
+// kraze itself uses, so it also covers routing set up by connectToHostNetwork
+func (kind *KindManager) waitForAPIServer(ctx context.Context, clusterName string) error {
+	ctx, cancel := context.WithTimeout(ctx, apiServerTimeout)
+	defer cancel()
+
+	kubeconfig, err := kind.GetKubeConfigQuiet(clusterName, false, true)
+	if err != nil {
+		return fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig([]byte(kubeconfig))
+	if err != nil {
+		return fmt.Errorf("failed to create REST config: %w", err)
+	}
+	restConfig.Timeout = 5 * time.Second
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create clientset: %w", err)
+	}
+
+	err = pollUntil(ctx, apiServerPollInterval, func(ctx context.Context) (bool, error) {
+		_, err := clientset.Discovery().RESTClient().Get().AbsPath("/readyz").DoRaw(ctx)
+		return err == nil, err
+	})
+	if err != nil {
+		return fmt.Errorf("API server not ready: %w", err)
+	}
+	return nil
+}
+
+// waitForSystemdUnits waits until the given units are active in every node of the cluster
+func (kind *KindManager) waitForSystemdUnits(ctx context.Context, clusterName string, units ...string) error {
+	ctx, cancel := context.WithTimeout(ctx, systemdTimeout)
+	defer cancel()
+
+	nodes, err := kind.provider.ListInternalNodes(clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to list cluster nodes: %w", err)
+	}
+
+	for _, node := range nodes {
+		containerName := node.String()
+		err := pollUntil(ctx, systemdPollInterval, func(ctx context.Context) (bool, error) {
+			args := append([]string{"systemctl", "is-active"}, units...)
+			inactive := parseUnitStates(units, nodeOutput(ctx, containerName, args...))
+			if len(inactive) > 0 {
+				return false, fmt.Errorf("%s not active", strings.Join(inactive, ", "))
+			}
+			return true, nil
+		})
+		if err != nil {
+			return fmt.Errorf("node %s: %w", containerName, err)
+		}
+	}
+	return nil
+}
+
+// applyCgroupWorkaroundWhenReady waits for the node containers and applies the
+// kubelet cgroup workaround. It runs alongside kind's create and gives up
+// quietly once create has returned.
+func (kind *KindManager) applyCgroupWorkaroundWhenReady(ctx context.Context, clusterName string) {
+	if err := kind.waitForNodeContainers(ctx, clusterName); err != nil {
+		if ctx.Err() == nil {
+			fmt.Printf("%s Could not detect node containers (cluster may still succeed): %v\n", color.Warning(), err)
+		}
+		return
+	}
+
+	if err := kind.ensureKubeletCgroupDirectories(ctx, clusterName); err != nil && ctx.Err() == nil {
+		// Log but don't fail - cluster might still work without this
+		fmt.Printf("Note: Could not create kubelet cgroup directories (cluster may still succeed): %v\n", err)
+	}
+}
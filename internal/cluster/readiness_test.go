@@ -0,0 +1,80 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPollUntil(test *testing.T) {
+	calls := 0
+	err := pollUntil(context.Background(), time.Millisecond, func(ctx context.Context) (bool, error) {
+		calls++
+		return calls == 3, nil
+	})
+	if err != nil || calls != 3 {
+		test.Errorf("pollUntil() = %v after %d calls, want nil after 3", err, calls)
+	}
+
+	// An immediately ready condition doesn't wait for the first tick
+	start := time.Now()
+	if err := pollUntil(context.Background(), time.Hour, func(ctx context.Context) (bool, error) { return true, nil }); err != nil {
+		test.Errorf("pollUntil() ready condition error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		test.Errorf("pollUntil() ready condition took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = pollUntil(ctx, time.Millisecond, func(ctx context.Context) (bool, error) {
+		return false, errors.New("connection refused")
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "connection refused") {
+		test.Errorf("pollUntil() timeout error = %v, want deadline exceeded with the last error", err)
+	}
+}
+
+func TestSystemdBooted(test *testing.T) {
+	tests := []struct {
+		state string
+		want  bool
+	}{
+		{state: "running\n", want: true},
+		{state: "degraded\n", want: true},
+		{state: "starting\n", want: true},
+		{state: "offline\n", want: false},
+		{state: "", want: false},
+	}
+
+	for _, tt := range tests {
+		test.Run(strings.TrimSpace(tt.state), func(test *testing.T) {
+			if got := systemdBooted(tt.state); got != tt.want {
+				test.Errorf("systemdBooted(%q) = %v, want %v", tt.state, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUnitStates(test *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{name: "all active", output: "active\nactive\n", want: nil},
+		{name: "kubelet activating", output: "active\nactivating\n", want: []string{"kubelet"}},
+		{name: "no output", output: "", want: []string{"containerd", "kubelet"}},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := parseUnitStates(nodeUnits, tt.output); !reflect.DeepEqual(got, tt.want) {
+				test.Errorf("parseUnitStates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/config"
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+)
+
+// RepairStatus is the outcome of a 'kraze repair' step
+type RepairStatus string
+
+const (
+	RepairOK      RepairStatus = "ok"       // Nothing needed fixing
+	RepairFixed   RepairStatus = "repaired" // A fix was applied
+	RepairSkipped RepairStatus = "skipped"  // Not applicable to this cluster
+	RepairFailed  RepairStatus = "failed"
+)
+
+// RepairStep is the result of one repair
+type RepairStep struct {
+	Name    string       `json:"name"`
+	Status  RepairStatus `json:"status"`
+	Message string       `json:"message"`
+}
+
+// controlPlaneComponents are the static pods kubelet runs on control-plane nodes
+var controlPlaneComponents = []string{"etcd", "kube-apiserver", "kube-controller-manager", "kube-scheduler"}
+
+// RepairCluster re-applies the fixes kraze makes while creating a cluster to an
+// existing one. Every step is idempotent, so repair can run on a healthy cluster.
+// Steps that need a working node or API server are skipped when an earlier one
+// failed.
+func (kind *KindManager) RepairCluster(ctx context.Context, cfg *config.ClusterConfig) []RepairStep {
+	if cfg.Network != "" {
+		kind.customNetwork = cfg.Network
+	}
+
+	nodesStep := kind.repairNodeContainers(ctx, cfg.Name)
+	steps := []RepairStep{nodesStep}
+	if nodesStep.Status == RepairFailed {
+		for _, name := range []string{"kubelet cgroups", "node services", "networks", "control plane", "CA certificates", "registry hosts", "registry credentials", "kubeconfig"} {
+			steps = append(steps, RepairStep{Name: name, Status: RepairSkipped, Message: "node containers are not running"})
+		}
+		return steps
+	}
+
+	steps = append(steps,
+		kind.repairCgroups(ctx, cfg.Name),
+		kind.repairNodeServices(ctx, cfg.Name),
+		kind.repairNetworks(cfg),
+		kind.repairControlPlane(ctx, cfg.Name))
+
+	if err := kind.waitForAPIServer(ctx, cfg.Name); err != nil {
+		for _, name := range []string{"CA certificates", "registry hosts", "registry credentials", "kubeconfig"} {
+			steps = append(steps, RepairStep{Name: name, Status: RepairSkipped, Message: firstLine(err.Error())})
+		}
+		return steps
+	}
+
+	return append(steps,
+		kind.repairCACertificates(ctx, cfg),
+		kind.repairRegistryHosts(ctx, cfg),
+		kind.repairRegistryCredentials(ctx, cfg),
+		kind.repairKubeconfig(cfg.Name))
+}
+
+// repairNodeContainers starts node containers that are stopped and waits for them to boot
+func (kind *KindManager) repairNodeContainers(ctx context.Context, clusterName string) RepairStep {
+	step := RepairStep{Name: "node containers"}
+	running, err := kind.IsClusterRunning(clusterName)
+	if err != nil {
+		return failedStep(step, err)
+	}
+	if running {
+		step.Status = RepairOK
+		step.Message = "all node containers are running"
+		return step
+	}
+
+	if err := kind.StartCluster(clusterName); err != nil {
+		return failedStep(step, err)
+	}
+	if err := kind.waitForNodeContainers(ctx, clusterName); err != nil {
+		return failedStep(step, err)
+	}
+	step.Status = RepairFixed
+	step.Message = "started stopped node containers"
+	return step
+}
+
+// repairCgroups re-applies the kubelet cgroup workaround for cgroup v1 hosts
+func (kind *KindManager) repairCgroups(ctx context.Context, clusterName string) RepairStep {
+	step := RepairStep{Name: "kubelet cgroups"}
+	if err := kind.ensureKubeletCgroupDirectories(ctx, clusterName); err != nil {
+		return failedStep(step, err)
+	}
+	step.Status = RepairOK
+	step.Message = "kubelet cgroup directories are in place"
+	return step
+}
+
+// repairNodeServices restarts containerd and the kubelet in nodes where they are not active
+func (kind *KindManager) repairNodeServices(ctx context.Context, clusterName string) RepairStep {
+	step := RepairStep{Name: "node services"}
+	nodes, err := kind.provider.ListInternalNodes(clusterName)
+	if err != nil {
+		return failedStep(step, fmt.Errorf("failed to list cluster nodes: %w", err))
+	}
+
+	var restarted []string
+	for _, node := range nodes {
+		containerName := node.String()
+		args := append([]string{"systemctl", "is-active"}, nodeUnits...)
+		for _, unit := range parseUnitStates(nodeUnits, nodeOutput(ctx, containerName, args...)) {
+			if _, err := nodeExec(ctx, containerName, "systemctl", "restart", unit); err != nil {
+				return failedStep(step, fmt.Errorf("failed to restart %s: %w", unit, err))
+			}
+			restarted = append(restarted, containerName+"/"+unit)
+		}
+	}
+	if len(restarted) > 0 {
+		if err := kind.waitForSystemdUnits(ctx, clusterName, nodeUnits...); err != nil {
+			return failedStep(step, err)
+		}
+		step.Status = RepairFixed
+		step.Message = "restarted " + strings.Join(restarted, ", ")
+		return step
+	}
+	step.Status = RepairOK
+	step.Message = strings.Join(nodeUnits, " and ") + " are active on every node"
+	return step
+}
+
+// repairNetworks reconnects the control-plane to the host network when it lost the connection
+func (kind *KindManager) repairNetworks(cfg *config.ClusterConfig) RepairStep {
+	step := RepairStep{Name: "networks"}
+	wanted := kind.detectNetworks()
+	if cfg.Network != "" {
+		wanted = []string{cfg.Network}
+	}
+	if len(wanted) == 0 {
+		step.Status = RepairSkipped
+		step.Message = "no host network to connect"
+		return step
+	}
+
+	output, err := runtimeCommand("inspect", cfg.Name+"-control-plane",
+		"-f", "{{range $net, $config := .NetworkSettings.Networks}}{{$net}} {{end}}").Output()
+	if err != nil {
+		return failedStep(step, fmt.Errorf("failed to inspect control-plane networks: %w", err))
+	}
+	if connected := connectedNetwork(strings.Fields(string(output)), wanted); connected != "" {
+		step.Status = RepairOK
+		step.Message = fmt.Sprintf("connected to '%s'", connected)
+		return step
+	}
+
+	if err := kind.connectToHostNetwork(cfg.Name, cfg.Network, cfg.Subnet, cfg.IPv4Address); err != nil {
+		return failedStep(step, err)
+	}
+	step.Status = RepairFixed
+	step.Message = fmt.Sprintf("reconnected to %s", strings.Join(wanted, " or "))
+	return step
+}
+
+// connectedNetwork returns the first wanted network the container is attached to
+func connectedNetwork(attached, wanted []string) string {
+	for _, network := range wanted {
+		if slices.Contains(attached, network) {
+			return network
+		}
+	}
+	return ""
+}
+
+// repairControlPlane removes the pod sandboxes of control-plane components that
+// have no running container, so kubelet recreates them from their static manifests
+func (kind *KindManager) repairControlPlane(ctx context.Context, clusterName string) RepairStep {
+	step := RepairStep{Name: "control plane"}
+	nodes, err := kind.provider.ListInternalNodes(clusterName)
+	if err != nil {
+		return failedStep(step, fmt.Errorf("failed to list cluster nodes: %w", err))
+	}
+
+	var restarted []string
+	for _, node := range nodes {
+		if role, err := node.Role(); err != nil || role != constants.ControlPlaneNodeRoleValue {
+			continue
+		}
+		containerName := node.String()
+		for _, component := range controlPlaneComponents {
+			running := nodeOutput(ctx, containerName, "crictl", "ps", "--name", "^"+component+"$", "--state", "running", "-q")
+			if strings.TrimSpace(running) != "" {
+				continue
+			}
+
+			for _, podID := range strings.Fields(nodeOutput(ctx, containerName, "crictl", "pods", "--name", component+"-", "-q")) {
+				if _, err := nodeExec(ctx, containerName, "crictl", "stopp", podID); err != nil {
+					return failedStep(step, fmt.Errorf("failed to stop %s: %w", component, err))
+				}
+				if _, err := nodeExec(ctx, containerName, "crictl", "rmp", podID); err != nil {
+					return failedStep(step, fmt.Errorf("failed to remove %s: %w", component, err))
+				}
+			}
+			restarted = append(restarted, containerName+"/"+component)
+		}
+	}
+
+	if len(restarted) > 0 {
+		step.Status = RepairFixed
+		step.Message = "restarted " + strings.Join(restarted, ", ")
+		return step
+	}
+	step.Status = RepairOK
+	step.Message = "all control-plane components are running"
+	return step
+}
+
+// repairCACertificates re-runs update-ca-certificates when custom CAs are mounted
+func (kind *KindManager) repairCACertificates(ctx context.Context, cfg *config.ClusterConfig) RepairStep {
+	step := RepairStep{Name: "CA certificates"}
+	if len(cfg.CACertificates) == 0 {
+		step.Status = RepairSkipped
+		step.Message = "no custom CA certificates configured"
+		return step
+	}
+	if err := kind.updateCACertificates(ctx, cfg.Name); err != nil {
+		return failedStep(step, err)
+	}
+	step.Status = RepairFixed
+	step.Message = fmt.Sprintf("trust store updated with %d certificate(s)", len(cfg.CACertificates))
+	return step
+}
+
+// repairRegistryHosts re-creates the containerd hosts.toml files for insecure registries
+func (kind *KindManager) repairRegistryHosts(ctx context.Context, cfg *config.ClusterConfig) RepairStep {
+	step := RepairStep{Name: "registry hosts"}
+	if len(cfg.InsecureRegistries) == 0 {
+		step.Status = RepairSkipped
+		step.Message = "no insecure registries configured"
+		return step
+	}
+	if err := kind.configureInsecureRegistries(ctx, cfg.Name, cfg.InsecureRegistries); err != nil {
+		return failedStep(step, err)
+	}
+	step.Status = RepairFixed
+	step.Message = "hosts.toml written for " + strings.Join(cfg.InsecureRegistries, ", ")
+	return step
+}
+
+// repairRegistryCredentials re-copies the registry credentials, picking up
+// tokens refreshed by 'docker login' or a password command since the cluster
+// was created
+func (kind *KindManager) repairRegistryCredentials(ctx context.Context, cfg *config.ClusterConfig) RepairStep {
+	step := RepairStep{Name: "registry credentials"}
+	if !cfg.RegistryCredentials.IsEnabled() && len(kind.registries) == 0 {
+		step.Status = RepairSkipped
+		step.Message = "registry credentials are not enabled"
+		return step
+	}
+	copied, err := kind.copyRegistryCredentials(ctx, cfg.Name, cfg.RegistryCredentials)
+	if err != nil {
+		return failedStep(step, err)
+	}
+	step.Status = RepairFixed
+	step.Message = "kubelet credentials written for " + strings.Join(copied, ", ")
+	return step
+}
+
+// repairKubeconfig re-patches ~/.kube/config, since container IPs may have changed
+func (kind *KindManager) repairKubeconfig(clusterName string) RepairStep {
+	step := RepairStep{Name: "kubeconfig"}
+	if err := kind.UpdateKubeconfigFile(clusterName); err != nil {
+		return failedStep(step, err)
+	}
+	step.Status = RepairFixed
+	step.Message = "updated context kind-" + clusterName
+	return step
+}
+
+// failedStep marks a step failed with the first line of the error
+func failedStep(step RepairStep, err error) RepairStep {
+	step.Status = RepairFailed
+	step.Message = firstLine(err.Error())
+	return step
+}
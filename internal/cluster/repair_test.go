@@ -0,0 +1,39 @@
+package cluster
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConnectedNetwork(test *testing.T) {
+	tests := []struct {
+		name     string
+		attached []string
+		wanted   []string
+		expected string
+	}{
+		{name: "connected to wanted network", attached: []string{"kind", "bridge"}, wanted: []string{"bridge"}, expected: "bridge"},
+		{name: "first wanted network wins", attached: []string{"kind", "devnet", "bridge"}, wanted: []string{"devnet", "bridge"}, expected: "devnet"},
+		{name: "fallback network", attached: []string{"kind", "bridge"}, wanted: []string{"devnet", "bridge"}, expected: "bridge"},
+		{name: "lost connection", attached: []string{"kind"}, wanted: []string{"bridge"}},
+		{name: "no networks attached", wanted: []string{"bridge"}},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := connectedNetwork(tt.attached, tt.wanted); got != tt.expected {
+				test.Errorf("connectedNetwork() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFailedStep(test *testing.T) {
+	step := failedStep(RepairStep{Name: "networks"}, &NodeExecError{Node: "dev-control-plane", Command: []string{"true"}, Stderr: "boom", Err: errors.New("test error")})
+	if step.Status != RepairFailed {
+		test.Errorf("status = %q, want %q", step.Status, RepairFailed)
+	}
+	if step.Message != `command "true" failed in node dev-control-plane: test error` {
+		test.Errorf("message = %q, want the first line of the error", step.Message)
+	}
+}
@@ -0,0 +1,69 @@
+package cluster
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/config"
+)
+
+// kindDiskEvictionDefaults are the eviction thresholds kind's KubeletConfiguration
+// sets to turn off disk-based eviction. The --eviction-hard flag replaces the whole
+// map, so they are kept unless the node overrides them.
+var kindDiskEvictionDefaults = map[string]string{
+	"nodefs.available":  "0%",
+	"nodefs.inodesFree": "0%",
+	"imagefs.available": "0%",
+}
+
+// kubeletArgsPatches returns kubeadm patches that pass a node's reservations,
+// eviction thresholds and kubeletExtraArgs to its kubelet, the explicit flags
+// winning over the ones kraze derives. Both the init and join configurations
+// are patched, since kind generates both for every node and which one is used
+// depends on the node's position in the cluster. kubeletExtraArgs is a map up
+// to kubeadm v1beta3 and a list of name/value pairs from v1beta4, so there is a
+// patch for each apiVersion and kind only applies the one matching the config
+// it generates for the node image.
+func kubeletArgsPatches(node config.KindNode) []string {
+	args := make(map[string]string)
+	if len(node.SystemReserved) > 0 {
+		args["system-reserved"] = joinKubeletMap(node.SystemReserved, "=")
+	}
+	if len(node.KubeReserved) > 0 {
+		args["kube-reserved"] = joinKubeletMap(node.KubeReserved, "=")
+	}
+	if len(node.EvictionHard) > 0 {
+		thresholds := maps.Clone(kindDiskEvictionDefaults)
+		maps.Copy(thresholds, node.EvictionHard)
+		args["eviction-hard"] = joinKubeletMap(thresholds, "<")
+	}
+	maps.Copy(args, node.KubeletExtraArgs)
+	if len(args) == 0 {
+		return nil
+	}
+
+	var argsMap, argsList strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(args)) {
+		fmt.Fprintf(&argsMap, "    %s: %q\n", name, args[name])
+		fmt.Fprintf(&argsList, "    - name: %q\n      value: %q\n", name, args[name])
+	}
+
+	var patches []string
+	for _, kind := range []string{"InitConfiguration", "JoinConfiguration"} {
+		patches = append(patches,
+			fmt.Sprintf("apiVersion: kubeadm.k8s.io/v1beta3\nkind: %s\nnodeRegistration:\n  kubeletExtraArgs:\n%s", kind, argsMap.String()),
+			fmt.Sprintf("apiVersion: kubeadm.k8s.io/v1beta4\nkind: %s\nnodeRegistration:\n  kubeletExtraArgs:\n%s", kind, argsList.String()))
+	}
+	return patches
+}
+
+// joinKubeletMap formats a map as a kubelet flag value (e.g., "cpu=500m,memory=1Gi")
+func joinKubeletMap(values map[string]string, separator string) string {
+	pairs := make([]string, 0, len(values))
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		pairs = append(pairs, key+separator+values[key])
+	}
+	return strings.Join(pairs, ",")
+}
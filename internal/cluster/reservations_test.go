@@ -0,0 +1,77 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+)
+
+// argsPatches returns the init and join patches setting kubeletExtraArgs, in
+// the v1beta3 map form and the v1beta4 list form
+func argsPatches(argsMap, argsList string) []string {
+	var patches []string
+	for _, kind := range []string{"InitConfiguration", "JoinConfiguration"} {
+		patches = append(patches,
+			"apiVersion: kubeadm.k8s.io/v1beta3\nkind: "+kind+"\nnodeRegistration:\n  kubeletExtraArgs:\n"+argsMap,
+			"apiVersion: kubeadm.k8s.io/v1beta4\nkind: "+kind+"\nnodeRegistration:\n  kubeletExtraArgs:\n"+argsList)
+	}
+	return patches
+}
+
+func TestKubeletArgsPatches(test *testing.T) {
+	tests := []struct {
+		name     string
+		node     config.KindNode
+		expected []string
+	}{
+		{
+			name: "no reservations",
+			node: config.KindNode{Role: "control-plane"},
+		},
+		{
+			name: "reserved resources",
+			node: config.KindNode{
+				Role:           "control-plane",
+				SystemReserved: map[string]string{"memory": "1Gi", "cpu": "500m"},
+				KubeReserved:   map[string]string{"memory": "512Mi"},
+			},
+			expected: argsPatches(
+				"    kube-reserved: \"memory=512Mi\"\n    system-reserved: \"cpu=500m,memory=1Gi\"\n",
+				"    - name: \"kube-reserved\"\n      value: \"memory=512Mi\"\n    - name: \"system-reserved\"\n      value: \"cpu=500m,memory=1Gi\"\n",
+			),
+		},
+		{
+			name: "eviction thresholds keep kind's disk defaults",
+			node: config.KindNode{
+				Role:         "worker",
+				EvictionHard: map[string]string{"memory.available": "300Mi", "nodefs.available": "5%"},
+			},
+			expected: argsPatches(
+				"    eviction-hard: \"imagefs.available<0%,memory.available<300Mi,nodefs.available<5%,nodefs.inodesFree<0%\"\n",
+				"    - name: \"eviction-hard\"\n      value: \"imagefs.available<0%,memory.available<300Mi,nodefs.available<5%,nodefs.inodesFree<0%\"\n",
+			),
+		},
+		{
+			name: "kubelet flags win over derived ones",
+			node: config.KindNode{
+				Role:             "worker",
+				SystemReserved:   map[string]string{"memory": "1Gi"},
+				KubeletExtraArgs: map[string]string{"max-pods": "250", "system-reserved": "memory=2Gi"},
+			},
+			expected: argsPatches(
+				"    max-pods: \"250\"\n    system-reserved: \"memory=2Gi\"\n",
+				"    - name: \"max-pods\"\n      value: \"250\"\n    - name: \"system-reserved\"\n      value: \"memory=2Gi\"\n",
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			patches := kubeletArgsPatches(tt.node)
+			if !reflect.DeepEqual(patches, tt.expected) {
+				test.Errorf("kubeletArgsPatches() = %q, want %q", patches, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hjames9/kraze/internal/config"
+	"sigs.k8s.io/kind/pkg/cluster"
+)
+
+// ContainerRuntime identifies the container engine that runs the kind node containers
+type ContainerRuntime string
+
+const (
+	RuntimeDocker ContainerRuntime = "docker"
+	RuntimePodman ContainerRuntime = "podman"
+)
+
+// runtimeEnvVar selects the container runtime explicitly. kind's own
+// KIND_EXPERIMENTAL_PROVIDER is honored as a fallback so kraze and the kind CLI
+// agree on which engine owns the nodes.
+const (
+	runtimeEnvVar      = "KRAZE_CONTAINER_RUNTIME"
+	kindProviderEnvVar = "KIND_EXPERIMENTAL_PROVIDER"
+)
+
+var (
+	detectedRuntime   ContainerRuntime
+	detectRuntimeOnce sync.Once
+
+	rootlessPodman     bool
+	detectRootlessOnce sync.Once
+)
+
+// DetectContainerRuntime returns the container runtime kraze drives, detecting it
+// once per process. An explicit KRAZE_CONTAINER_RUNTIME (or KIND_EXPERIMENTAL_PROVIDER)
+// wins; otherwise Docker is preferred when installed, then Podman, matching kind's
+// own auto-detection.
+func DetectContainerRuntime() ContainerRuntime {
+	detectRuntimeOnce.Do(func() {
+		detectedRuntime = resolveContainerRuntime(os.Getenv, runtimeVersion)
+	})
+	return detectedRuntime
+}
+
+// resolveContainerRuntime picks a runtime from the environment and the installed CLIs
+func resolveContainerRuntime(getenv func(string) string, version func(binary string) string) ContainerRuntime {
+	for _, envVar := range []string{runtimeEnvVar, kindProviderEnvVar} {
+		switch ContainerRuntime(strings.ToLower(strings.TrimSpace(getenv(envVar)))) {
+		case RuntimeDocker:
+			return RuntimeDocker
+		case RuntimePodman:
+			return RuntimePodman
+		}
+	}
+
+	dockerVersion := version(string(RuntimeDocker))
+	if strings.HasPrefix(dockerVersion, "Docker version") {
+		return RuntimeDocker
+	}
+	// podman-docker installs a `docker` shim that reports podman's version
+	if strings.HasPrefix(dockerVersion, "podman version") || strings.HasPrefix(version(string(RuntimePodman)), "podman version") {
+		return RuntimePodman
+	}
+
+	// Nothing detected: keep Docker so error messages point at the common setup
+	return RuntimeDocker
+}
+
+// runtimeVersion returns the first line of `<binary> -v`, or "" if it isn't installed
+func runtimeVersion(binary string) string {
+	output, err := osexec.Command(binary, "-v").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.SplitN(string(output), "\n", 2)[0])
+}
+
+// DisplayName returns the runtime name for user-facing messages
+func (runtime ContainerRuntime) DisplayName() string {
+	if runtime == RuntimePodman {
+		return "Podman"
+	}
+	return "Docker"
+}
+
+// DefaultNetwork returns the runtime's default bridge network
+func (runtime ContainerRuntime) DefaultNetwork() string {
+	if runtime == RuntimePodman {
+		return "podman"
+	}
+	return "bridge"
+}
+
+// kindProviderOption returns the kind node provider matching the detected runtime
+func kindProviderOption() cluster.ProviderOption {
+	if DetectContainerRuntime() == RuntimePodman {
+		return cluster.ProviderWithPodman()
+	}
+	return cluster.ProviderWithDocker()
+}
+
+// runtimeCommand builds a command for the detected container runtime CLI
+func runtimeCommand(args ...string) *osexec.Cmd {
+	return osexec.Command(string(DetectContainerRuntime()), args...)
+}
+
+// runtimeCommandContext builds a context-bound command for the detected container runtime CLI
+func runtimeCommandContext(ctx context.Context, args ...string) *osexec.Cmd {
+	return osexec.CommandContext(ctx, string(DetectContainerRuntime()), args...)
+}
+
+// IsRootlessPodman returns true if the runtime is Podman running without root
+func IsRootlessPodman() bool {
+	if DetectContainerRuntime() != RuntimePodman {
+		return false
+	}
+	detectRootlessOnce.Do(func() {
+		output, err := runtimeCommand("info", "--format", "{{.Host.Security.Rootless}}").Output()
+		rootlessPodman = err == nil && strings.TrimSpace(string(output)) == "true"
+	})
+	return rootlessPodman
+}
+
+// checkPodmanAvailable checks that the podman CLI can reach its engine. Podman is
+// daemonless, so unlike Docker there may be no API socket to ping.
+func checkPodmanAvailable(ctx context.Context) error {
+	output, err := runtimeCommandContext(ctx, "info", "--format", "{{.Host.Security.Rootless}}").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("podman is not available: %w\n\n"+
+			"Output: %s\n\n"+
+			"kraze was configured to use Podman (%s or auto-detection).\n"+
+			"On macOS, start the Podman machine:\n"+
+			"  podman machine start\n\n"+
+			"Install Podman: https://podman.io/docs/installation",
+			err, strings.TrimSpace(string(output)), runtimeEnvVar)
+	}
+	return nil
+}
+
+// checkRootlessPodmanPorts returns an error if a rootless Podman cluster maps host
+// ports the user isn't allowed to bind. Rootless engines can only bind ports at or
+// above net.ipv4.ip_unprivileged_port_start, and kind's own error doesn't say why.
+func checkRootlessPodmanPorts(cfg *config.ClusterConfig) error {
+	if !IsRootlessPodman() {
+		return nil
+	}
+
+	data, err := os.ReadFile("/proc/sys/net/ipv4/ip_unprivileged_port_start")
+	if err != nil {
+		// Not Linux (e.g. a Podman machine on macOS): the VM handles binding
+		return nil
+	}
+	return validateUnprivilegedPorts(cfg, strings.TrimSpace(string(data)))
+}
+
+// validateUnprivilegedPorts checks host port mappings against the lowest port a rootless user may bind
+func validateUnprivilegedPorts(cfg *config.ClusterConfig, unprivilegedPortStart string) error {
+	lowest, err := strconv.Atoi(unprivilegedPortStart)
+	if err != nil {
+		return nil
+	}
+
+	for _, node := range cfg.Config {
+		for _, mapping := range node.ExtraPortMappings {
+			if mapping.HostPort > 0 && int(mapping.HostPort) < lowest {
+				return fmt.Errorf("rootless Podman cannot bind host port %d (ports below %d are privileged)\n"+
+					"  Allow it with: sudo sysctl net.ipv4.ip_unprivileged_port_start=%d\n"+
+					"  Or map the container port to a host port >= %d",
+					mapping.HostPort, lowest, mapping.HostPort, lowest)
+			}
+		}
+	}
+	return nil
+}
+
+// localImageArchiveRef returns the reference to save a Podman image under so it
+// lands in the cluster with the name Kubernetes resolves. Podman stores
+// unqualified local builds as localhost/<name>, which kubelet would never match
+// for a pod that says <name>; Docker Hub style names are qualified instead.
+func localImageArchiveRef(imageName string) string {
+	return ClusterImageName(imageName)
+}
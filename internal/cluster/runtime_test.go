@@ -0,0 +1,127 @@
+package cluster
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+)
+
+func TestResolveContainerRuntime(test *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		versions map[string]string
+		expected ContainerRuntime
+	}{
+		{
+			name:     "docker installed",
+			versions: map[string]string{"docker": "Docker version 27.3.1, build ce12230", "podman": "podman version 5.2.0"},
+			expected: RuntimeDocker,
+		},
+		{
+			name:     "only podman installed",
+			versions: map[string]string{"podman": "podman version 5.2.0"},
+			expected: RuntimePodman,
+		},
+		{
+			name:     "podman-docker shim",
+			versions: map[string]string{"docker": "podman version 5.2.0"},
+			expected: RuntimePodman,
+		},
+		{
+			name:     "explicit override beats detection",
+			env:      map[string]string{runtimeEnvVar: "Podman"},
+			versions: map[string]string{"docker": "Docker version 27.3.1, build ce12230"},
+			expected: RuntimePodman,
+		},
+		{
+			name:     "kind provider variable",
+			env:      map[string]string{kindProviderEnvVar: "podman"},
+			versions: map[string]string{"docker": "Docker version 27.3.1, build ce12230"},
+			expected: RuntimePodman,
+		},
+		{
+			name:     "kraze variable wins over kind's",
+			env:      map[string]string{runtimeEnvVar: "docker", kindProviderEnvVar: "podman"},
+			expected: RuntimeDocker,
+		},
+		{
+			name:     "unsupported provider falls back to detection",
+			env:      map[string]string{kindProviderEnvVar: "nerdctl"},
+			versions: map[string]string{"podman": "podman version 5.2.0"},
+			expected: RuntimePodman,
+		},
+		{
+			name:     "nothing installed",
+			expected: RuntimeDocker,
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			version := func(binary string) string { return tt.versions[binary] }
+
+			if got := resolveContainerRuntime(getenv, version); got != tt.expected {
+				test.Errorf("resolveContainerRuntime() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateUnprivilegedPorts(test *testing.T) {
+	clusterWithPorts := func(hostPorts ...int32) *config.ClusterConfig {
+		node := config.KindNode{Role: "control-plane"}
+		for _, hostPort := range hostPorts {
+			node.ExtraPortMappings = append(node.ExtraPortMappings, config.PortMapping{ContainerPort: 80, HostPort: hostPort})
+		}
+		return &config.ClusterConfig{Name: "dev", Config: []config.KindNode{node}}
+	}
+
+	tests := []struct {
+		name        string
+		cfg         *config.ClusterConfig
+		portStart   string
+		expectError bool
+	}{
+		{name: "no port mappings", cfg: clusterWithPorts(), portStart: "1024"},
+		{name: "unprivileged host ports", cfg: clusterWithPorts(8080, 8443), portStart: "1024"},
+		{name: "privileged host port", cfg: clusterWithPorts(8080, 80), portStart: "1024", expectError: true},
+		{name: "privileged ports allowed by sysctl", cfg: clusterWithPorts(80, 443), portStart: "80"},
+		{name: "unparseable sysctl", cfg: clusterWithPorts(80), portStart: ""},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			err := validateUnprivilegedPorts(tt.cfg, tt.portStart)
+			if (err != nil) != tt.expectError {
+				test.Fatalf("validateUnprivilegedPorts() error = %v, expectError %v", err, tt.expectError)
+			}
+			if err != nil && !strings.Contains(err.Error(), "ip_unprivileged_port_start") {
+				test.Errorf("expected the sysctl fix in the error, got %q", err.Error())
+			}
+		})
+	}
+}
+
+func TestLocalImageArchiveRef(test *testing.T) {
+	tests := []struct {
+		image    string
+		expected string
+	}{
+		{image: "myapp:latest", expected: "docker.io/library/myapp:latest"},
+		{image: "acme/api:1.2", expected: "docker.io/acme/api:1.2"},
+		{image: "docker.io/library/nginx:1.27", expected: "docker.io/library/nginx:1.27"},
+		{image: "ghcr.io/acme/api:1.2", expected: "ghcr.io/acme/api:1.2"},
+		{image: "localhost:5000/api:dev", expected: "localhost:5000/api:dev"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.image, func(test *testing.T) {
+			if got := localImageArchiveRef(tt.image); got != tt.expected {
+				test.Errorf("localImageArchiveRef(%q) = %q, want %q", tt.image, got, tt.expected)
+			}
+		})
+	}
+}
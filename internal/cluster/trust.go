@@ -0,0 +1,321 @@
+package cluster
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Files the cluster CAs are exported to under ~/.kraze/clusters/<cluster-name>
+const (
+	ClusterCAFileName = "ca.crt"
+	IngressCAFileName = "ingress-ca.crt"
+)
+
+// TrustedCA is a CA certificate exported from a cluster for host tooling
+type TrustedCA struct {
+	Name     string    `json:"name"` // Nickname used in trust stores (e.g., kraze-dev)
+	Path     string    `json:"path"`
+	Subject  string    `json:"subject"`
+	SHA256   string    `json:"sha256"`
+	NotAfter time.Time `json:"not_after"`
+	sha1     string
+	serial   string
+}
+
+// TrustStep is one command that adds a CA to, or removes it from, a trust store
+type TrustStep struct {
+	Store   string   `json:"store"`
+	Command []string `json:"command"`
+}
+
+// TrustPlan lists the trust store commands for a CA, plus notes about stores
+// that can't be updated automatically
+type TrustPlan struct {
+	Steps []TrustStep `json:"steps"`
+	Notes []string    `json:"notes,omitempty"`
+}
+
+// trustEnv is what trust store detection looks at, so it can be faked in tests
+type trustEnv struct {
+	goos     string
+	homeDir  string
+	root     bool
+	hasPath  func(name string) bool
+	exists   func(path string) bool
+	globDirs func(pattern string) []string
+}
+
+// ClusterCAFromKubeconfig returns the PEM CA data of the kubeconfig's current cluster
+func ClusterCAFromKubeconfig(kubeconfigContent string) ([]byte, error) {
+	kubeconfig, err := clientcmd.Load([]byte(kubeconfigContent))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+
+	current, exists := kubeconfig.Contexts[kubeconfig.CurrentContext]
+	if !exists {
+		return nil, fmt.Errorf("kubeconfig has no current context")
+	}
+	cluster, exists := kubeconfig.Clusters[current.Cluster]
+	if !exists {
+		return nil, fmt.Errorf("kubeconfig has no cluster '%s'", current.Cluster)
+	}
+
+	if len(cluster.CertificateAuthorityData) > 0 {
+		return cluster.CertificateAuthorityData, nil
+	}
+	if cluster.CertificateAuthority != "" {
+		data, err := os.ReadFile(cluster.CertificateAuthority)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cluster CA: %w", err)
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("kubeconfig cluster '%s' has no certificate authority", current.Cluster)
+}
+
+// parseCACertificate returns the first certificate in PEM data, which must be a CA
+func parseCACertificate(data []byte) (*x509.Certificate, []byte, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, nil, fmt.Errorf("no PEM certificate found")
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		if !cert.IsCA {
+			return nil, nil, fmt.Errorf("certificate '%s' is not a CA", cert.Subject.String())
+		}
+		return cert, pem.EncodeToMemory(block), nil
+	}
+}
+
+// newTrustedCA describes a parsed CA certificate stored at path
+func newTrustedCA(name, path string, cert *x509.Certificate) *TrustedCA {
+	sha256Sum := sha256.Sum256(cert.Raw)
+	sha1Sum := sha1.Sum(cert.Raw)
+	return &TrustedCA{
+		Name:     name,
+		Path:     path,
+		Subject:  cert.Subject.String(),
+		SHA256:   formatFingerprint(sha256Sum[:]),
+		NotAfter: cert.NotAfter,
+		sha1:     strings.ToUpper(hex.EncodeToString(sha1Sum[:])),
+		serial:   hex.EncodeToString(cert.SerialNumber.Bytes()),
+	}
+}
+
+// formatFingerprint formats a digest as colon-separated upper-case hex
+func formatFingerprint(sum []byte) string {
+	parts := make([]string, len(sum))
+	for idx, b := range sum {
+		parts[idx] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":")
+}
+
+// ExportCA writes a CA certificate to ~/.kraze/clusters/<cluster-name>/<fileName>
+// and returns its description. name is the nickname used in trust stores.
+func ExportCA(clusterName, fileName, name string, data []byte) (*TrustedCA, error) {
+	cert, certPEM, err := parseCACertificate(data)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := ClusterDataDir(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, fileName)
+	if err := os.WriteFile(path, certPEM, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write CA certificate: %w", err)
+	}
+	return newTrustedCA(name, path, cert), nil
+}
+
+// LoadExportedCA reads a CA previously written by ExportCA.
+// Returns nil if it was never exported.
+func LoadExportedCA(clusterName, fileName, name string) (*TrustedCA, error) {
+	dir, err := ClusterDataDir(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	return LoadCAFile(filepath.Join(dir, fileName), name)
+}
+
+// LoadCAFile reads a CA certificate file. Returns nil if it doesn't exist.
+func LoadCAFile(path, name string) (*TrustedCA, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+	}
+
+	cert, _, err := parseCACertificate(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return newTrustedCA(name, path, cert), nil
+}
+
+// PlanTrustStore returns the commands that add ca to (or, with install false,
+// remove it from) the trust stores of this machine
+func PlanTrustStore(ca *TrustedCA, install bool) TrustPlan {
+	homeDir, _ := os.UserHomeDir()
+	env := trustEnv{
+		goos:    runtime.GOOS,
+		homeDir: homeDir,
+		root:    runtime.GOOS != "windows" && os.Geteuid() == 0,
+		hasPath: func(name string) bool {
+			_, err := exec.LookPath(name)
+			return err == nil
+		},
+		exists: func(path string) bool {
+			_, err := os.Stat(path)
+			return err == nil
+		},
+		globDirs: func(pattern string) []string {
+			matches, _ := filepath.Glob(pattern)
+			return matches
+		},
+	}
+	return trustStorePlan(env, ca, install)
+}
+
+// trustStorePlan builds the trust store commands for the operating system in env
+func trustStorePlan(env trustEnv, ca *TrustedCA, install bool) TrustPlan {
+	var plan TrustPlan
+	privileged := func(args ...string) []string {
+		if env.root {
+			return args
+		}
+		return append([]string{"sudo"}, args...)
+	}
+
+	switch env.goos {
+	case "linux":
+		switch {
+		case env.exists("/usr/local/share/ca-certificates") && env.hasPath("update-ca-certificates"):
+			// Debian, Ubuntu and Alpine
+			target := "/usr/local/share/ca-certificates/" + ca.Name + ".crt"
+			if install {
+				plan.add("system", privileged("cp", ca.Path, target))
+				plan.add("system", privileged("update-ca-certificates"))
+			} else {
+				plan.add("system", privileged("rm", "-f", target))
+				plan.add("system", privileged("update-ca-certificates", "--fresh"))
+			}
+		case env.exists("/etc/pki/ca-trust/source/anchors") && env.hasPath("update-ca-trust"):
+			// Fedora, RHEL and derivatives
+			target := "/etc/pki/ca-trust/source/anchors/" + ca.Name + ".pem"
+			if install {
+				plan.add("system", privileged("cp", ca.Path, target))
+			} else {
+				plan.add("system", privileged("rm", "-f", target))
+			}
+			plan.add("system", privileged("update-ca-trust", "extract"))
+		case env.hasPath("trust"):
+			// p11-kit (Arch and others)
+			if install {
+				plan.add("system", privileged("trust", "anchor", "--store", ca.Path))
+			} else {
+				plan.add("system", privileged("trust", "anchor", "--remove", ca.Path))
+			}
+		default:
+			plan.Notes = append(plan.Notes, fmt.Sprintf("no supported system trust store tool found, add %s to your system trust store manually", ca.Path))
+		}
+		plan.addNSS(env, ca, install, append(
+			[]string{filepath.Join(env.homeDir, ".pki", "nssdb")},
+			append(env.globDirs(filepath.Join(env.homeDir, ".mozilla", "firefox", "*")),
+				env.globDirs(filepath.Join(env.homeDir, "snap", "firefox", "common", ".mozilla", "firefox", "*"))...)...,
+		))
+	case "darwin":
+		keychain := "/Library/Keychains/System.keychain"
+		if install {
+			plan.add("system", privileged("security", "add-trusted-cert", "-d", "-r", "trustRoot", "-k", keychain, ca.Path))
+		} else {
+			plan.add("system", privileged("security", "remove-trusted-cert", "-d", ca.Path))
+			plan.add("system", privileged("security", "delete-certificate", "-Z", ca.sha1, keychain))
+		}
+		plan.addNSS(env, ca, install, env.globDirs(filepath.Join(env.homeDir, "Library", "Application Support", "Firefox", "Profiles", "*")))
+	case "windows":
+		// The current user's root store needs no elevation; Windows asks for
+		// confirmation itself. Chrome and Edge use this store, and Firefox does
+		// by default through security.enterprise_roots.enabled.
+		if install {
+			plan.add("system", []string{"certutil", "-user", "-addstore", "-f", "Root", ca.Path})
+		} else {
+			plan.add("system", []string{"certutil", "-user", "-delstore", "Root", ca.serial})
+		}
+	default:
+		plan.Notes = append(plan.Notes, fmt.Sprintf("trust stores on %s are not supported, add %s manually", env.goos, ca.Path))
+	}
+	return plan
+}
+
+// add appends a step to the plan
+func (plan *TrustPlan) add(store string, command []string) {
+	plan.Steps = append(plan.Steps, TrustStep{Store: store, Command: command})
+}
+
+// addNSS adds steps for the NSS databases (Chrome/Chromium on Linux and Firefox
+// profiles) found among dirs, which don't use the system trust store
+func (plan *TrustPlan) addNSS(env trustEnv, ca *TrustedCA, install bool, dirs []string) {
+	var databases []string
+	for _, dir := range dirs {
+		if env.exists(filepath.Join(dir, "cert9.db")) {
+			databases = append(databases, dir)
+		}
+	}
+	if len(databases) == 0 {
+		return
+	}
+	if !env.hasPath("certutil") {
+		plan.Notes = append(plan.Notes, fmt.Sprintf("found %d browser certificate database(s) but no certutil, install NSS tools (libnss3-tools or nss-tools) to update them", len(databases)))
+		return
+	}
+
+	for _, db := range databases {
+		store := "nss:" + db
+		if install {
+			plan.add(store, []string{"certutil", "-A", "-d", "sql:" + db, "-t", "C,,", "-n", ca.Name, "-i", ca.Path})
+		} else {
+			plan.add(store, []string{"certutil", "-D", "-d", "sql:" + db, "-n", ca.Name})
+		}
+	}
+}
+
+// RunTrustStep runs a trust store command attached to the terminal, so sudo and
+// the Windows confirmation dialog can prompt the user
+func RunTrustStep(ctx context.Context, step TrustStep) error {
+	cmd := exec.CommandContext(ctx, step.Command[0], step.Command[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %w", strings.Join(step.Command, " "), err)
+	}
+	return nil
+}
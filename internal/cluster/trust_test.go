@@ -0,0 +1,250 @@
+package cluster
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// trustTestCertificate returns a PEM self-signed certificate
+func trustTestCertificate(test *testing.T, isCA bool) []byte {
+	test.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		test.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(4242),
+		Subject:               pkix.Name{CommonName: "kubernetes"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		test.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestClusterCAFromKubeconfig(test *testing.T) {
+	caPEM := trustTestCertificate(test, true)
+	kubeconfig := fmt.Sprintf(`apiVersion: v1
+kind: Config
+current-context: kind-dev
+clusters:
+- name: kind-dev
+  cluster:
+    server: https://127.0.0.1:6443
+    certificate-authority-data: %s
+contexts:
+- name: kind-dev
+  context:
+    cluster: kind-dev
+    user: kind-dev
+users:
+- name: kind-dev
+  user: {}
+`, base64.StdEncoding.EncodeToString(caPEM))
+
+	got, err := ClusterCAFromKubeconfig(kubeconfig)
+	if err != nil {
+		test.Fatalf("ClusterCAFromKubeconfig() error = %v", err)
+	}
+	if string(got) != string(caPEM) {
+		test.Errorf("ClusterCAFromKubeconfig() returned different CA data")
+	}
+
+	noCA := strings.Replace(kubeconfig, "    certificate-authority-data:", "    insecure-skip-tls-verify: true\n    x:", 1)
+	if _, err := ClusterCAFromKubeconfig(noCA); err == nil {
+		test.Error("ClusterCAFromKubeconfig() without a CA should fail")
+	}
+}
+
+func TestParseCACertificate(test *testing.T) {
+	ca := trustTestCertificate(test, true)
+	leaf := trustTestCertificate(test, false)
+	key := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: []byte("key")})
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{name: "CA", data: ca},
+		{name: "key before CA", data: append(append([]byte{}, key...), ca...)},
+		{name: "leaf certificate", data: leaf, wantErr: true},
+		{name: "not PEM", data: []byte("not a certificate"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			cert, certPEM, err := parseCACertificate(tt.data)
+			if (err != nil) != tt.wantErr {
+				test.Fatalf("parseCACertificate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if cert.Subject.CommonName != "kubernetes" || string(certPEM) != string(ca) {
+				test.Errorf("parseCACertificate() = %s, %q", cert.Subject, certPEM)
+			}
+		})
+	}
+}
+
+func TestTrustStorePlan(test *testing.T) {
+	ca := &TrustedCA{Name: "kraze-dev", Path: "/home/dev/.kraze/clusters/dev/ca.crt", sha1: "ABCD", serial: "1092"}
+	profile := "/home/dev/.mozilla/firefox/abc.default"
+
+	tests := []struct {
+		name      string
+		goos      string
+		root      bool
+		tools     []string
+		paths     []string
+		install   bool
+		wantSteps [][]string
+		wantNotes int
+	}{
+		{
+			name:    "debian install",
+			goos:    "linux",
+			tools:   []string{"update-ca-certificates"},
+			paths:   []string{"/usr/local/share/ca-certificates"},
+			install: true,
+			wantSteps: [][]string{
+				{"sudo", "cp", ca.Path, "/usr/local/share/ca-certificates/kraze-dev.crt"},
+				{"sudo", "update-ca-certificates"},
+			},
+		},
+		{
+			name:  "fedora uninstall as root",
+			goos:  "linux",
+			root:  true,
+			tools: []string{"update-ca-trust"},
+			paths: []string{"/etc/pki/ca-trust/source/anchors"},
+			wantSteps: [][]string{
+				{"rm", "-f", "/etc/pki/ca-trust/source/anchors/kraze-dev.pem"},
+				{"update-ca-trust", "extract"},
+			},
+		},
+		{
+			name:    "p11-kit with firefox",
+			goos:    "linux",
+			tools:   []string{"trust", "certutil"},
+			paths:   []string{profile + "/cert9.db"},
+			install: true,
+			wantSteps: [][]string{
+				{"sudo", "trust", "anchor", "--store", ca.Path},
+				{"certutil", "-A", "-d", "sql:" + profile, "-t", "C,,", "-n", "kraze-dev", "-i", ca.Path},
+			},
+		},
+		{
+			name:      "no tools",
+			goos:      "linux",
+			paths:     []string{profile + "/cert9.db"},
+			install:   true,
+			wantNotes: 2,
+		},
+		{
+			name: "macOS uninstall",
+			goos: "darwin",
+			wantSteps: [][]string{
+				{"sudo", "security", "remove-trusted-cert", "-d", ca.Path},
+				{"sudo", "security", "delete-certificate", "-Z", "ABCD", "/Library/Keychains/System.keychain"},
+			},
+		},
+		{
+			name:      "windows install",
+			goos:      "windows",
+			install:   true,
+			wantSteps: [][]string{{"certutil", "-user", "-addstore", "-f", "Root", ca.Path}},
+		},
+		{
+			name:      "unsupported",
+			goos:      "plan9",
+			install:   true,
+			wantNotes: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			env := trustEnv{
+				goos:    tt.goos,
+				homeDir: "/home/dev",
+				root:    tt.root,
+				hasPath: func(name string) bool {
+					for _, tool := range tt.tools {
+						if tool == name {
+							return true
+						}
+					}
+					return false
+				},
+				exists: func(path string) bool {
+					for _, existing := range tt.paths {
+						if existing == path {
+							return true
+						}
+					}
+					return false
+				},
+				globDirs: func(pattern string) []string {
+					if pattern == "/home/dev/.mozilla/firefox/*" {
+						return []string{profile}
+					}
+					return nil
+				},
+			}
+
+			plan := trustStorePlan(env, ca, tt.install)
+			var got [][]string
+			for _, step := range plan.Steps {
+				got = append(got, step.Command)
+			}
+			if !reflect.DeepEqual(got, tt.wantSteps) {
+				test.Errorf("trustStorePlan() steps = %v, want %v", got, tt.wantSteps)
+			}
+			if len(plan.Notes) != tt.wantNotes {
+				test.Errorf("trustStorePlan() notes = %v, want %d", plan.Notes, tt.wantNotes)
+			}
+		})
+	}
+}
+
+func TestExportCA(test *testing.T) {
+	test.Setenv("HOME", test.TempDir())
+	test.Setenv("USERPROFILE", test.TempDir())
+
+	exported, err := ExportCA("dev", ClusterCAFileName, "kraze-dev", trustTestCertificate(test, true))
+	if err != nil {
+		test.Fatalf("ExportCA() error = %v", err)
+	}
+
+	loaded, err := LoadExportedCA("dev", ClusterCAFileName, "kraze-dev")
+	if err != nil {
+		test.Fatalf("LoadExportedCA() error = %v", err)
+	}
+	if loaded == nil || loaded.SHA256 != exported.SHA256 || loaded.serial != "1092" {
+		test.Errorf("LoadExportedCA() = %+v, want %+v", loaded, exported)
+	}
+
+	missing, err := LoadExportedCA("dev", IngressCAFileName, "kraze-dev-ingress")
+	if err != nil || missing != nil {
+		test.Errorf("LoadExportedCA() for a missing file = %v, %v, want nil, nil", missing, err)
+	}
+}
@@ -0,0 +1,55 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// checkVolumePath refuses paths that can't be a volume's own directory, so a
+// bad PersistentVolume never turns into rm -rf of a system directory
+func checkVolumePath(volumePath string) error {
+	cleaned := path.Clean(volumePath)
+	if !path.IsAbs(cleaned) || strings.Count(cleaned, "/") < 2 {
+		return fmt.Errorf("refusing to touch volume path '%s': not a volume directory", volumePath)
+	}
+	return nil
+}
+
+// VolumeUsage returns how many bytes a volume's directory uses inside a kind node
+func VolumeUsage(ctx context.Context, node, volumePath string) (int64, error) {
+	if err := checkVolumePath(volumePath); err != nil {
+		return 0, err
+	}
+	output, err := nodeExec(ctx, node, "du", "-sk", path.Clean(volumePath))
+	if err != nil {
+		return 0, fmt.Errorf("failed to measure %s on %s: %w", volumePath, node, err)
+	}
+	return parseDiskUsage(output)
+}
+
+// parseDiskUsage reads the size in KiB from the output of du -sk
+func parseDiskUsage(output string) (int64, error) {
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("unexpected du output: %q", output)
+	}
+	kib, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected du output: %q", output)
+	}
+	return kib * 1024, nil
+}
+
+// RemoveVolumeData deletes a volume's directory inside a kind node
+func RemoveVolumeData(ctx context.Context, node, volumePath string) error {
+	if err := checkVolumePath(volumePath); err != nil {
+		return err
+	}
+	if _, err := nodeExec(ctx, node, "rm", "-rf", "--", path.Clean(volumePath)); err != nil {
+		return fmt.Errorf("failed to remove %s on %s: %w", volumePath, node, err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package cluster
+
+import "testing"
+
+func TestCheckVolumePath(test *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{name: "local-path volume", path: "/var/local-path-provisioner/pvc-123_app_data"},
+		{name: "two levels", path: "/mnt/data"},
+		{name: "root", path: "/", wantErr: true},
+		{name: "top-level directory", path: "/var", wantErr: true},
+		{name: "climbs to top-level directory", path: "/var/data/../../etc", wantErr: true},
+		{name: "relative", path: "var/data", wantErr: true},
+		{name: "empty", path: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			err := checkVolumePath(tt.path)
+			if (err != nil) != tt.wantErr {
+				test.Errorf("checkVolumePath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseDiskUsage(test *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected int64
+		wantErr  bool
+	}{
+		{name: "du output", output: "2048\t/var/local-path-provisioner/pvc-1\n", expected: 2048 * 1024},
+		{name: "empty directory", output: "4\t/data", expected: 4096},
+		{name: "no output", output: "", wantErr: true},
+		{name: "not a number", output: "du: cannot access", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			got, err := parseDiskUsage(tt.output)
+			if (err != nil) != tt.wantErr {
+				test.Fatalf("parseDiskUsage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				test.Errorf("parseDiskUsage() = %d, expected %d", got, tt.expected)
+			}
+		})
+	}
+}
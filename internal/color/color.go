@@ -1,6 +1,8 @@
 package color
 
 import (
+	"hash/fnv"
+
 	"github.com/fatih/color"
 )
 
@@ -61,3 +63,24 @@ func Error(msg string) string {
 func Info(msg string) string {
 	return Cyan(IconInfo) + " " + msg
 }
+
+// keyPalette are the colors ForKey chooses from (red is left out so keyed text
+// isn't mistaken for errors)
+var keyPalette = []func(a ...interface{}) string{
+	Cyan,
+	Green,
+	Yellow,
+	color.New(color.FgBlue).SprintFunc(),
+	color.New(color.FgMagenta).SprintFunc(),
+	color.New(color.FgHiCyan).SprintFunc(),
+	color.New(color.FgHiGreen).SprintFunc(),
+	color.New(color.FgHiMagenta).SprintFunc(),
+}
+
+// ForKey returns a color function chosen deterministically from the key, so the
+// same key (e.g. a pod name) is always printed in the same color
+func ForKey(key string) func(a ...interface{}) string {
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+	return keyPalette[hash.Sum32()%uint32(len(keyPalette))]
+}
@@ -137,3 +137,14 @@ func TestBold(test *testing.T) {
 		test.Errorf("Bold() does not contain text: %s", text)
 	}
 }
+
+func TestForKey(test *testing.T) {
+	first := ForKey("web-5d8f7-abcde")("text")
+	second := ForKey("web-5d8f7-abcde")("text")
+	if first != second {
+		test.Errorf("ForKey() is not deterministic: %q != %q", first, second)
+	}
+	if !strings.Contains(first, "text") {
+		test.Errorf("ForKey() output %q does not contain the text", first)
+	}
+}
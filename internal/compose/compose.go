@@ -0,0 +1,477 @@
+package compose
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Project is a docker-compose file
+type Project struct {
+	Name     string // Name of the project (default: the compose file's directory)
+	Services map[string]*Service
+	Dir      string // Directory of the compose file, which relative paths are relative to
+
+	// Unsupported lists the keys of each service that aren't converted
+	Unsupported map[string][]string
+}
+
+// Service is a compose service. Fields with several compose syntaxes are
+// normalized to one when parsed.
+type Service struct {
+	Image       string       `yaml:"image"`
+	Build       *Build       `yaml:"build"`
+	Entrypoint  Command      `yaml:"entrypoint"`
+	Command     Command      `yaml:"command"`
+	Environment Mapping      `yaml:"environment"`
+	EnvFile     StringList   `yaml:"env_file"`
+	Ports       []Port       `yaml:"ports"`
+	Expose      StringList   `yaml:"expose"`
+	DependsOn   DependsOn    `yaml:"depends_on"`
+	Healthcheck *Healthcheck `yaml:"healthcheck"`
+	Volumes     []Volume     `yaml:"volumes"`
+	Deploy      *Deploy      `yaml:"deploy"`
+	WorkingDir  string       `yaml:"working_dir"`
+	Restart     string       `yaml:"restart"`
+	Profiles    []string     `yaml:"profiles"`
+	Labels      Mapping      `yaml:"labels"`
+}
+
+// supportedKeys are the service keys that are converted (or knowingly dropped,
+// like container_name, which Kubernetes names itself)
+var supportedKeys = map[string]bool{
+	"image": true, "build": true, "entrypoint": true, "command": true, "environment": true,
+	"env_file": true, "ports": true, "expose": true, "depends_on": true, "healthcheck": true,
+	"volumes": true, "deploy": true, "working_dir": true, "restart": true, "profiles": true,
+	"labels": true, "container_name": true, "networks": true, "hostname": true,
+}
+
+// Build is the build section of a service
+type Build struct {
+	Context    string  `yaml:"context"`
+	Dockerfile string  `yaml:"dockerfile"`
+	Args       Mapping `yaml:"args"`
+	Target     string  `yaml:"target"`
+}
+
+// UnmarshalYAML accepts the context path on its own or the full build section
+func (build *Build) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		build.Context = node.Value
+		return nil
+	}
+	type plain Build
+	return node.Decode((*plain)(build))
+}
+
+// Command is an entrypoint or command, split into arguments
+type Command []string
+
+// UnmarshalYAML accepts a list of arguments or a string split like a shell would
+func (command *Command) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		args, err := splitCommand(node.Value)
+		if err != nil {
+			return err
+		}
+		*command = args
+		return nil
+	}
+	var args []string
+	if err := node.Decode(&args); err != nil {
+		return err
+	}
+	*command = args
+	return nil
+}
+
+// splitCommand splits a command string into arguments, honoring quotes and
+// backslash escapes but no other shell syntax, like compose
+func splitCommand(command string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	var quote rune
+	inArg, escaped := false, false
+	for _, char := range command {
+		switch {
+		case escaped:
+			current.WriteRune(char)
+			escaped = false
+		case char == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			} else {
+				current.WriteRune(char)
+			}
+		case char == '"' || char == '\'':
+			quote, inArg = char, true
+		case char == ' ' || char == '\t' || char == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(char)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in command '%s'", command)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// Mapping is an environment, labels or build args section
+type Mapping map[string]string
+
+// UnmarshalYAML accepts a map or a list of KEY=VALUE entries. Entries without
+// a value take it from the environment, like compose.
+func (mapping *Mapping) UnmarshalYAML(node *yaml.Node) error {
+	result := make(Mapping)
+	switch node.Kind {
+	case yaml.SequenceNode:
+		var entries []string
+		if err := node.Decode(&entries); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			key, value, ok := strings.Cut(entry, "=")
+			if !ok {
+				value = os.Getenv(key)
+			}
+			result[key] = value
+		}
+	case yaml.MappingNode:
+		var entries map[string]*string
+		if err := node.Decode(&entries); err != nil {
+			return err
+		}
+		for key, value := range entries {
+			if value == nil {
+				result[key] = os.Getenv(key)
+			} else {
+				result[key] = *value
+			}
+		}
+	default:
+		return fmt.Errorf("line %d: expected a map or a list of KEY=VALUE", node.Line)
+	}
+	*mapping = result
+	return nil
+}
+
+// StringList is a string or a list of strings
+type StringList []string
+
+// UnmarshalYAML accepts a single value or a list
+func (list *StringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*list = StringList{node.Value}
+		return nil
+	}
+	var values []string
+	if err := node.Decode(&values); err != nil {
+		return err
+	}
+	*list = values
+	return nil
+}
+
+// Port is a published or exposed port of a service
+type Port struct {
+	HostIP    string
+	Published int32 // Port on the host, 0 if not published
+	Target    int32 // Port of the container
+	Protocol  string
+}
+
+// UnmarshalYAML accepts the short "[[IP:]HOST:]CONTAINER[/PROTOCOL]" syntax and
+// the long syntax. Port ranges aren't supported.
+func (port *Port) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.MappingNode {
+		var long struct {
+			HostIP    string `yaml:"host_ip"`
+			Published string `yaml:"published"`
+			Target    int32  `yaml:"target"`
+			Protocol  string `yaml:"protocol"`
+		}
+		if err := node.Decode(&long); err != nil {
+			return err
+		}
+		published, err := parsePortNumber(long.Published, true)
+		if err != nil {
+			return err
+		}
+		*port = Port{HostIP: long.HostIP, Published: published, Target: long.Target, Protocol: long.Protocol}
+	} else {
+		parsed, err := parsePort(node.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		*port = parsed
+	}
+	if port.Protocol == "" {
+		port.Protocol = "tcp"
+	}
+	return nil
+}
+
+// parsePort parses the short port syntax
+func parsePort(value string) (Port, error) {
+	var port Port
+	spec, protocol, _ := strings.Cut(value, "/")
+	port.Protocol = protocol
+
+	parts := strings.Split(spec, ":")
+	if strings.HasPrefix(spec, "[") {
+		// [::1]:8080:80
+		if end := strings.Index(spec, "]:"); end >= 0 {
+			port.HostIP = spec[1:end]
+			parts = strings.Split(spec[end+2:], ":")
+		}
+	}
+	var err error
+	switch len(parts) {
+	case 1:
+		port.Target, err = parsePortNumber(parts[0], false)
+	case 2:
+		if port.Published, err = parsePortNumber(parts[0], true); err == nil {
+			port.Target, err = parsePortNumber(parts[1], false)
+		}
+	case 3:
+		port.HostIP = parts[0]
+		if port.Published, err = parsePortNumber(parts[1], true); err == nil {
+			port.Target, err = parsePortNumber(parts[2], false)
+		}
+	default:
+		err = fmt.Errorf("invalid port '%s'", value)
+	}
+	if err != nil {
+		return Port{}, fmt.Errorf("invalid port '%s': %w", value, err)
+	}
+	return port, nil
+}
+
+// parsePortNumber parses one port. An empty published port leaves picking it to Docker.
+func parsePortNumber(value string, optional bool) (int32, error) {
+	if value == "" && optional {
+		return 0, nil
+	}
+	if strings.Contains(value, "-") {
+		return 0, fmt.Errorf("port ranges are not supported")
+	}
+	number, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || number < 1 || number > 65535 {
+		return 0, fmt.Errorf("'%s' is not a port", value)
+	}
+	return int32(number), nil
+}
+
+// DependsOn is the services a service depends on
+type DependsOn []string
+
+// UnmarshalYAML accepts a list of services or a map of services to conditions
+func (dependsOn *DependsOn) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.MappingNode {
+		var conditions map[string]yaml.Node
+		if err := node.Decode(&conditions); err != nil {
+			return err
+		}
+		names := make([]string, 0, len(conditions))
+		for name := range conditions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		*dependsOn = names
+		return nil
+	}
+	var names []string
+	if err := node.Decode(&names); err != nil {
+		return err
+	}
+	*dependsOn = names
+	return nil
+}
+
+// Healthcheck is the health check of a service
+type Healthcheck struct {
+	Test        Command `yaml:"-"`
+	Interval    string  `yaml:"interval"`
+	Timeout     string  `yaml:"timeout"`
+	Retries     int32   `yaml:"retries"`
+	StartPeriod string  `yaml:"start_period"`
+	Disable     bool    `yaml:"disable"`
+}
+
+// UnmarshalYAML reads the test as ["CMD", ...], ["CMD-SHELL", "..."], ["NONE"]
+// or a string run by the shell
+func (healthcheck *Healthcheck) UnmarshalYAML(node *yaml.Node) error {
+	type plain Healthcheck
+	var raw struct {
+		plain `yaml:",inline"`
+		Test  yaml.Node `yaml:"test"`
+	}
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+	*healthcheck = Healthcheck(raw.plain)
+
+	switch raw.Test.Kind {
+	case 0:
+	case yaml.ScalarNode:
+		healthcheck.Test = Command{"/bin/sh", "-c", raw.Test.Value}
+	default:
+		var test []string
+		if err := raw.Test.Decode(&test); err != nil {
+			return err
+		}
+		if len(test) == 0 {
+			break
+		}
+		switch test[0] {
+		case "NONE":
+			healthcheck.Disable = true
+		case "CMD":
+			healthcheck.Test = test[1:]
+		case "CMD-SHELL":
+			healthcheck.Test = Command{"/bin/sh", "-c", strings.Join(test[1:], " ")}
+		default:
+			return fmt.Errorf("line %d: healthcheck test must start with CMD, CMD-SHELL or NONE", raw.Test.Line)
+		}
+	}
+	return nil
+}
+
+// Volume is a volume mounted into a service
+type Volume struct {
+	Type     string // volume, bind or tmpfs
+	Source   string // Volume name, or host path of a bind mount
+	Target   string // Path in the container
+	ReadOnly bool
+}
+
+// UnmarshalYAML accepts the short "[SOURCE:]TARGET[:MODE]" syntax and the long syntax
+func (volume *Volume) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.MappingNode {
+		var long struct {
+			Type     string `yaml:"type"`
+			Source   string `yaml:"source"`
+			Target   string `yaml:"target"`
+			ReadOnly bool   `yaml:"read_only"`
+		}
+		if err := node.Decode(&long); err != nil {
+			return err
+		}
+		*volume = Volume{Type: long.Type, Source: long.Source, Target: long.Target, ReadOnly: long.ReadOnly}
+		if volume.Type == "" {
+			volume.Type = "volume"
+		}
+		return nil
+	}
+
+	parts := strings.Split(node.Value, ":")
+	switch len(parts) {
+	case 1:
+		*volume = Volume{Type: "volume", Target: parts[0]}
+	case 2, 3:
+		*volume = Volume{Source: parts[0], Target: parts[1]}
+		if len(parts) == 3 {
+			volume.ReadOnly = strings.Contains(parts[2], "ro")
+		}
+		if isHostPath(volume.Source) {
+			volume.Type = "bind"
+		} else {
+			volume.Type = "volume"
+		}
+	default:
+		return fmt.Errorf("line %d: invalid volume '%s'", node.Line, node.Value)
+	}
+	return nil
+}
+
+// isHostPath returns true if a short syntax volume source is a path rather than a volume name
+func isHostPath(source string) bool {
+	return strings.HasPrefix(source, ".") || strings.HasPrefix(source, "/") || strings.HasPrefix(source, "~") || filepath.IsAbs(source)
+}
+
+// Deploy is the deploy section of a service
+type Deploy struct {
+	Replicas  *int32 `yaml:"replicas"`
+	Resources struct {
+		Limits       Resources `yaml:"limits"`
+		Reservations Resources `yaml:"reservations"`
+	} `yaml:"resources"`
+}
+
+// Resources are CPU and memory limits or reservations
+type Resources struct {
+	CPUs   string `yaml:"cpus"`
+	Memory string `yaml:"memory"`
+}
+
+// Load reads a compose file
+func Load(path string) (*Project, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read compose file: %w", err)
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve compose file path: %w", err)
+	}
+
+	var raw struct {
+		Name     string               `yaml:"name"`
+		Services map[string]yaml.Node `yaml:"services"`
+	}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(raw.Services) == 0 {
+		return nil, fmt.Errorf("%s has no services", path)
+	}
+
+	project := &Project{
+		Name:        raw.Name,
+		Dir:         filepath.Dir(absPath),
+		Services:    make(map[string]*Service, len(raw.Services)),
+		Unsupported: make(map[string][]string),
+	}
+	if project.Name == "" {
+		project.Name = filepath.Base(project.Dir)
+	}
+
+	for name, node := range raw.Services {
+		var keys map[string]yaml.Node
+		if err := node.Decode(&keys); err != nil {
+			return nil, fmt.Errorf("service '%s': %w", name, err)
+		}
+		for key := range keys {
+			if !supportedKeys[key] {
+				project.Unsupported[name] = append(project.Unsupported[name], key)
+			}
+		}
+		sort.Strings(project.Unsupported[name])
+
+		var service Service
+		if err := node.Decode(&service); err != nil {
+			return nil, fmt.Errorf("service '%s': %w", name, err)
+		}
+		if service.Image == "" && service.Build == nil {
+			return nil, fmt.Errorf("service '%s' has neither an image nor a build", name)
+		}
+		project.Services[name] = &service
+	}
+	return project, nil
+}
@@ -0,0 +1,178 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeCompose writes a compose file into a temporary directory and loads it
+func writeCompose(test *testing.T, content string) *Project {
+	test.Helper()
+	dir := test.TempDir()
+	path := filepath.Join(dir, "docker-compose.yml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		test.Fatal(err)
+	}
+	project, err := Load(path)
+	if err != nil {
+		test.Fatalf("Load() error = %v", err)
+	}
+	return project
+}
+
+func TestSplitCommand(test *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		expected []string
+	}{
+		{name: "words", command: "npm run start", expected: []string{"npm", "run", "start"}},
+		{name: "double quotes", command: `sh -c "echo hi there"`, expected: []string{"sh", "-c", "echo hi there"}},
+		{name: "single quotes", command: `echo 'a "b"'`, expected: []string{"echo", `a "b"`}},
+		{name: "escaped space", command: `ls my\ dir`, expected: []string{"ls", "my dir"}},
+		{name: "empty argument", command: `echo ""`, expected: []string{"echo", ""}},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			result, err := splitCommand(tt.command)
+			if err != nil {
+				test.Fatalf("splitCommand() error = %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				test.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+
+	if _, err := splitCommand(`echo "open`); err == nil {
+		test.Error("Expected an error for an unterminated quote")
+	}
+}
+
+func TestParsePort(test *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected Port
+		errors   bool
+	}{
+		{name: "container only", value: "80", expected: Port{Target: 80}},
+		{name: "published", value: "8080:80", expected: Port{Published: 8080, Target: 80}},
+		{name: "host IP and protocol", value: "127.0.0.1:5353:53/udp", expected: Port{HostIP: "127.0.0.1", Published: 5353, Target: 53, Protocol: "udp"}},
+		{name: "IPv6 host IP", value: "[::1]:8080:80", expected: Port{HostIP: "::1", Published: 8080, Target: 80}},
+		{name: "range", value: "3000-3005", errors: true},
+		{name: "not a port", value: "http", errors: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			result, err := parsePort(tt.value)
+			if tt.errors {
+				if err == nil {
+					test.Errorf("Expected an error, got %+v", result)
+				}
+				return
+			}
+			if err != nil {
+				test.Fatalf("parsePort() error = %v", err)
+			}
+			if result != tt.expected {
+				test.Errorf("Expected %+v, got %+v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestLoad(test *testing.T) {
+	project := writeCompose(test, `
+services:
+  web:
+    build: ./web
+    command: ["serve", "--port", "80"]
+    environment:
+      - MODE=dev
+      - EMPTY=
+    ports:
+      - 8080:80
+      - target: 443
+        published: "8443"
+    depends_on: [cache]
+    healthcheck:
+      test: curl -f http://localhost
+      interval: 10s
+    volumes:
+      - data:/data
+      - ./src:/src:ro
+      - /tmp/scratch
+    privileged: true
+  cache:
+    image: redis:7
+    depends_on:
+      web:
+        condition: service_started
+`)
+
+	if project.Name != filepath.Base(project.Dir) {
+		test.Errorf("Expected the project to be named after its directory, got '%s'", project.Name)
+	}
+	web := project.Services["web"]
+	if web.Build == nil || web.Build.Context != "./web" {
+		test.Errorf("Expected build context ./web, got %+v", web.Build)
+	}
+	if !reflect.DeepEqual([]string(web.Command), []string{"serve", "--port", "80"}) {
+		test.Errorf("Unexpected command %q", web.Command)
+	}
+	if !reflect.DeepEqual(web.Environment, Mapping{"MODE": "dev", "EMPTY": ""}) {
+		test.Errorf("Unexpected environment %v", web.Environment)
+	}
+	expectedPorts := []Port{{Published: 8080, Target: 80, Protocol: "tcp"}, {Published: 8443, Target: 443, Protocol: "tcp"}}
+	if !reflect.DeepEqual(web.Ports, expectedPorts) {
+		test.Errorf("Expected ports %+v, got %+v", expectedPorts, web.Ports)
+	}
+	if !reflect.DeepEqual([]string(web.Healthcheck.Test), []string{"/bin/sh", "-c", "curl -f http://localhost"}) {
+		test.Errorf("Unexpected healthcheck test %q", web.Healthcheck.Test)
+	}
+	expectedVolumes := []Volume{
+		{Type: "volume", Source: "data", Target: "/data"},
+		{Type: "bind", Source: "./src", Target: "/src", ReadOnly: true},
+		{Type: "volume", Target: "/tmp/scratch"},
+	}
+	if !reflect.DeepEqual(web.Volumes, expectedVolumes) {
+		test.Errorf("Expected volumes %+v, got %+v", expectedVolumes, web.Volumes)
+	}
+	if !reflect.DeepEqual(project.Unsupported["web"], []string{"privileged"}) {
+		test.Errorf("Expected privileged to be unsupported, got %v", project.Unsupported["web"])
+	}
+	if !reflect.DeepEqual([]string(project.Services["cache"].DependsOn), []string{"web"}) {
+		test.Errorf("Unexpected depends_on %v", project.Services["cache"].DependsOn)
+	}
+}
+
+func TestLoadErrors(test *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		errContains string
+	}{
+		{name: "no services", content: "volumes: {}\n", errContains: "has no services"},
+		{name: "no image", content: "services:\n  web:\n    command: serve\n", errContains: "neither an image nor a build"},
+		{name: "bad healthcheck", content: "services:\n  web:\n    image: nginx\n    healthcheck:\n      test: [\"RUN\", \"true\"]\n", errContains: "CMD, CMD-SHELL or NONE"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			path := filepath.Join(test.TempDir(), "docker-compose.yml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				test.Fatal(err)
+			}
+			_, err := Load(path)
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				test.Errorf("Load() error = %v, want one containing %q", err, tt.errContains)
+			}
+		})
+	}
+}
@@ -0,0 +1,591 @@
+package compose
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hjames9/kraze/internal/config"
+	"gopkg.in/yaml.v3"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+const (
+	// nameLabel selects the pods of a converted service
+	nameLabel = "app.kubernetes.io/name"
+
+	// defaultVolumeSize is the size of the PersistentVolumeClaim of a named volume
+	defaultVolumeSize = "1Gi"
+)
+
+// Options control how a project is converted
+type Options struct {
+	Namespace   string // Namespace of every service (default: the project name)
+	ManifestDir string // Directory the manifests are written to, as referenced from kraze.yml
+	ConfigDir   string // Directory kraze.yml is written to, which build contexts are made relative to
+	Source      string // Compose file name, mentioned in kraze.yml
+}
+
+// Result is a converted project
+type Result struct {
+	Config    []byte            // kraze.yml
+	Manifests map[string][]byte // Manifests of each service by file name
+	Warnings  []string          // What couldn't be converted faithfully
+}
+
+// krazeConfig is the kraze.yml written for a project
+type krazeConfig struct {
+	Cluster struct {
+		Name string `yaml:"name"`
+	} `yaml:"cluster"`
+	Services map[string]krazeService `yaml:"services"`
+}
+
+// krazeService is a manifests service of the kraze.yml written for a project
+type krazeService struct {
+	Type      string              `yaml:"type"`
+	Namespace string              `yaml:"namespace"`
+	Path      string              `yaml:"path"`
+	DependsOn []string            `yaml:"depends_on,omitempty"`
+	Ports     []string            `yaml:"ports,omitempty"`
+	Labels    map[string]string   `yaml:"labels,omitempty"`
+	Build     *config.BuildConfig `yaml:"build,omitempty"`
+}
+
+// invalidNameChars are the characters compose allows in names that Kubernetes doesn't
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// Name turns a compose service or volume name into a Kubernetes name
+func Name(name string) string {
+	return strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// Convert translates the services of a project into a Deployment each, with a
+// Service for their ports, a ConfigMap for their env_file and
+// PersistentVolumeClaims for their named volumes, and a kraze.yml with a
+// manifests service for each
+func Convert(project *Project, opts Options) (*Result, error) {
+	namespace := opts.Namespace
+	if namespace == "" {
+		namespace = Name(project.Name)
+	}
+
+	names := make([]string, 0, len(project.Services))
+	kubeNames := make(map[string]string, len(project.Services))
+	seen := make(map[string]string)
+	for name := range project.Services {
+		kubeName := Name(name)
+		if kubeName == "" {
+			return nil, fmt.Errorf("service '%s' has no characters valid in a Kubernetes name", name)
+		}
+		if other, ok := seen[kubeName]; ok {
+			return nil, fmt.Errorf("services '%s' and '%s' both become '%s' in Kubernetes", other, name, kubeName)
+		}
+		seen[kubeName] = name
+		kubeNames[name] = kubeName
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	converter := &converter{
+		project:   project,
+		opts:      opts,
+		namespace: namespace,
+		kubeNames: kubeNames,
+		claimed:   make(map[string]string),
+	}
+	result := &Result{Manifests: make(map[string][]byte, len(names))}
+	cfg := krazeConfig{Services: make(map[string]krazeService, len(names))}
+	cfg.Cluster.Name = Name(project.Name)
+
+	for _, name := range names {
+		if kubeNames[name] != name {
+			converter.warn(name, "other services reach it as '%s'", kubeNames[name])
+		}
+		for _, key := range project.Unsupported[name] {
+			converter.warn(name, "'%s' is not converted", key)
+		}
+
+		objects, svc, err := converter.convertService(name, project.Services[name])
+		if err != nil {
+			return nil, fmt.Errorf("service '%s': %w", name, err)
+		}
+		manifest, err := marshalObjects(objects)
+		if err != nil {
+			return nil, fmt.Errorf("service '%s': %w", name, err)
+		}
+		fileName := kubeNames[name] + ".yaml"
+		result.Manifests[fileName] = manifest
+		svc.Path = filepath.ToSlash(filepath.Join(opts.ManifestDir, fileName))
+		cfg.Services[kubeNames[name]] = svc
+	}
+
+	var buffer bytes.Buffer
+	source := opts.Source
+	if source == "" {
+		source = "docker-compose.yml"
+	}
+	fmt.Fprintf(&buffer, "# Converted from %s with 'kraze convert compose'\n", source)
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(cfg); err != nil {
+		return nil, fmt.Errorf("failed to write kraze.yml: %w", err)
+	}
+	encoder.Close()
+	result.Config = buffer.Bytes()
+	result.Warnings = converter.warnings
+	return result, nil
+}
+
+// converter holds what the services of a project share while they are converted
+type converter struct {
+	project   *Project
+	opts      Options
+	namespace string
+	kubeNames map[string]string // Kubernetes name of each compose service
+	claimed   map[string]string // Service whose manifest holds each named volume's claim
+	warnings  []string
+}
+
+// warn records something about a service that wasn't converted faithfully
+func (conv *converter) warn(service, format string, args ...any) {
+	conv.warnings = append(conv.warnings, fmt.Sprintf("%s: %s", service, fmt.Sprintf(format, args...)))
+}
+
+// convertService returns the objects of a service and its kraze service
+func (conv *converter) convertService(name string, service *Service) ([]runtime.Object, krazeService, error) {
+	kubeName := conv.kubeNames[name]
+	svc := krazeService{Type: "manifests", Namespace: conv.namespace}
+	var objects []runtime.Object
+
+	for _, dependency := range service.DependsOn {
+		kubeDependency, ok := conv.kubeNames[dependency]
+		if !ok {
+			return nil, svc, fmt.Errorf("depends on unknown service '%s'", dependency)
+		}
+		svc.DependsOn = append(svc.DependsOn, kubeDependency)
+	}
+	if len(service.Profiles) > 0 {
+		svc.Labels = map[string]string{"profile": service.Profiles[0]}
+		conv.warn(name, "kraze installs it by default; select it with 'kraze up -l profile=%s'", service.Profiles[0])
+		if len(service.Profiles) > 1 {
+			conv.warn(name, "only the first of its profiles is kept as a label")
+		}
+	}
+	if service.Restart == "no" || strings.HasPrefix(service.Restart, "on-failure") {
+		conv.warn(name, "restart '%s' is not converted; Deployment pods are always restarted", service.Restart)
+	}
+
+	image := service.Image
+	if service.Build != nil {
+		build, err := conv.convertBuild(name, service)
+		if err != nil {
+			return nil, svc, err
+		}
+		svc.Build = build
+		if build != nil {
+			image = build.Image
+		}
+	}
+	if image == "" {
+		return nil, svc, fmt.Errorf("no image to run")
+	}
+
+	container := corev1.Container{
+		Name:       kubeName,
+		Image:      image,
+		Command:    service.Entrypoint,
+		Args:       service.Command,
+		WorkingDir: service.WorkingDir,
+	}
+
+	// Environment, with env_file entries in a ConfigMap the explicit ones override
+	if len(service.EnvFile) > 0 {
+		data, err := conv.readEnvFiles(service.EnvFile)
+		if err != nil {
+			return nil, svc, err
+		}
+		configMap := &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Name: kubeName + "-env", Labels: map[string]string{nameLabel: kubeName}},
+			Data:       data,
+		}
+		objects = append(objects, configMap)
+		container.EnvFrom = []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: configMap.Name}}}}
+	}
+	for _, key := range sortedKeys(service.Environment) {
+		container.Env = append(container.Env, corev1.EnvVar{Name: key, Value: service.Environment[key]})
+	}
+
+	// Ports, published ones also forwarded by kraze
+	ports, err := servicePorts(service)
+	if err != nil {
+		return nil, svc, err
+	}
+	var servicePortSpecs []corev1.ServicePort
+	for _, port := range ports {
+		protocol := corev1.Protocol(strings.ToUpper(port.Protocol))
+		container.Ports = append(container.Ports, corev1.ContainerPort{ContainerPort: port.Target, Protocol: protocol})
+		servicePortSpecs = append(servicePortSpecs, corev1.ServicePort{
+			Name:       fmt.Sprintf("%s-%d", strings.ToLower(port.Protocol), port.Target),
+			Port:       port.Target,
+			TargetPort: intstr.FromInt32(port.Target),
+			Protocol:   protocol,
+		})
+	}
+	for _, port := range service.Ports {
+		if port.Published == 0 {
+			continue
+		}
+		if port.Protocol != "tcp" {
+			conv.warn(name, "%s port %d can't be forwarded", port.Protocol, port.Published)
+			continue
+		}
+		svc.Ports = append(svc.Ports, fmt.Sprintf("%d:%d", port.Published, port.Target))
+	}
+	if len(ports) == 0 && conv.isDependency(name) {
+		conv.warn(name, "other services depend on it, but it has no ports or expose for a Service to reach it by")
+	}
+
+	if service.Healthcheck != nil && !service.Healthcheck.Disable && len(service.Healthcheck.Test) > 0 {
+		probe, err := readinessProbe(service.Healthcheck)
+		if err != nil {
+			return nil, svc, err
+		}
+		container.ReadinessProbe = probe
+	}
+
+	if service.Deploy != nil {
+		resources, err := conv.resources(service.Deploy)
+		if err != nil {
+			return nil, svc, err
+		}
+		container.Resources = resources
+	}
+
+	volumes, claims := conv.convertVolumes(name, service, &container)
+	objects = append(objects, claims...)
+
+	labels := map[string]string{nameLabel: kubeName}
+	deployment := &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        kubeName,
+			Labels:      labels,
+			Annotations: service.Labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{container},
+					Volumes:    volumes,
+				},
+			},
+		},
+	}
+	if service.Deploy != nil && service.Deploy.Replicas != nil {
+		deployment.Spec.Replicas = service.Deploy.Replicas
+	}
+	if len(claims) > 0 && (deployment.Spec.Replicas == nil || *deployment.Spec.Replicas <= 1) {
+		// A second pod can't mount a ReadWriteOnce volume while the old one holds it
+		deployment.Spec.Strategy = appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+	}
+	objects = append(objects, deployment)
+
+	// The Service is named after the compose service, so other services reach it by the same host name
+	if len(servicePortSpecs) > 0 {
+		objects = append(objects, &corev1.Service{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+			ObjectMeta: metav1.ObjectMeta{Name: kubeName, Labels: labels},
+			Spec: corev1.ServiceSpec{
+				Selector: labels,
+				Ports:    servicePortSpecs,
+			},
+		})
+	}
+	return objects, svc, nil
+}
+
+// convertBuild returns the kraze build of a service, whose image is the
+// service's image or <name>:dev
+func (conv *converter) convertBuild(name string, service *Service) (*config.BuildConfig, error) {
+	build := service.Build
+	if strings.Contains(build.Context, "://") || strings.HasPrefix(build.Context, "git@") {
+		conv.warn(name, "remote build context '%s' is not converted", build.Context)
+		return nil, nil
+	}
+
+	image := service.Image
+	if image == "" {
+		image = conv.kubeNames[name] + ":dev"
+	}
+	buildContext := build.Context
+	if buildContext == "" {
+		buildContext = "."
+	}
+	if !filepath.IsAbs(buildContext) {
+		buildContext = filepath.Join(conv.project.Dir, buildContext)
+	}
+	if conv.opts.ConfigDir != "" {
+		relative, err := filepath.Rel(conv.opts.ConfigDir, buildContext)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve build context: %w", err)
+		}
+		buildContext = relative
+	}
+
+	return &config.BuildConfig{
+		Image:      image,
+		Context:    filepath.ToSlash(buildContext),
+		Dockerfile: build.Dockerfile,
+		Args:       build.Args,
+		Target:     build.Target,
+	}, nil
+}
+
+// isDependency returns true if another service depends on a service
+func (conv *converter) isDependency(name string) bool {
+	for _, service := range conv.project.Services {
+		for _, dependency := range service.DependsOn {
+			if dependency == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// readEnvFiles reads env_file entries, later files overriding earlier ones
+func (conv *converter) readEnvFiles(paths []string) (map[string]string, error) {
+	data := make(map[string]string)
+	for _, path := range paths {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(conv.project.Dir, path)
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read env_file: %w", err)
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+			if !ok {
+				value = os.Getenv(key)
+			}
+			data[strings.TrimSpace(key)] = unquote(strings.TrimSpace(value))
+		}
+		file.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read env_file: %w", err)
+		}
+	}
+	return data, nil
+}
+
+// unquote removes matching quotes around an env_file value
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// servicePorts returns the distinct container ports of ports and expose
+func servicePorts(service *Service) ([]Port, error) {
+	var ports []Port
+	seen := make(map[string]bool)
+	add := func(port Port) {
+		key := fmt.Sprintf("%d/%s", port.Target, port.Protocol)
+		if !seen[key] {
+			seen[key] = true
+			ports = append(ports, port)
+		}
+	}
+	for _, port := range service.Ports {
+		add(port)
+	}
+	for _, expose := range service.Expose {
+		port, err := parsePort(expose)
+		if err != nil {
+			return nil, err
+		}
+		if port.Protocol == "" {
+			port.Protocol = "tcp"
+		}
+		add(port)
+	}
+	return ports, nil
+}
+
+// readinessProbe turns a healthcheck into an exec readiness probe
+func readinessProbe(healthcheck *Healthcheck) (*corev1.Probe, error) {
+	probe := &corev1.Probe{
+		ProbeHandler:     corev1.ProbeHandler{Exec: &corev1.ExecAction{Command: healthcheck.Test}},
+		FailureThreshold: healthcheck.Retries,
+	}
+	for _, field := range []struct {
+		name   string
+		value  string
+		target *int32
+	}{
+		{"interval", healthcheck.Interval, &probe.PeriodSeconds},
+		{"timeout", healthcheck.Timeout, &probe.TimeoutSeconds},
+		{"start_period", healthcheck.StartPeriod, &probe.InitialDelaySeconds},
+	} {
+		if field.value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(field.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid healthcheck %s '%s': %w", field.name, field.value, err)
+		}
+		*field.target = max(int32(duration.Round(time.Second)/time.Second), 1)
+	}
+	return probe, nil
+}
+
+// resources turns deploy.resources into container resources
+func (conv *converter) resources(deploy *Deploy) (corev1.ResourceRequirements, error) {
+	var requirements corev1.ResourceRequirements
+	for _, itr := range []struct {
+		resources Resources
+		target    *corev1.ResourceList
+	}{
+		{deploy.Resources.Limits, &requirements.Limits},
+		{deploy.Resources.Reservations, &requirements.Requests},
+	} {
+		list := corev1.ResourceList{}
+		if itr.resources.CPUs != "" {
+			cpu, err := resource.ParseQuantity(itr.resources.CPUs)
+			if err != nil {
+				return requirements, fmt.Errorf("invalid cpus '%s': %w", itr.resources.CPUs, err)
+			}
+			list[corev1.ResourceCPU] = cpu
+		}
+		if itr.resources.Memory != "" {
+			memory, err := memoryQuantity(itr.resources.Memory)
+			if err != nil {
+				return requirements, err
+			}
+			list[corev1.ResourceMemory] = memory
+		}
+		if len(list) > 0 {
+			*itr.target = list
+		}
+	}
+	return requirements, nil
+}
+
+// memoryQuantity converts a compose byte value (e.g. 512m, 1gb) to a quantity
+func memoryQuantity(value string) (resource.Quantity, error) {
+	number := strings.TrimSuffix(strings.ToLower(value), "b")
+	suffix := ""
+	if len(number) > 0 {
+		switch number[len(number)-1] {
+		case 'k':
+			suffix = "Ki"
+		case 'm':
+			suffix = "Mi"
+		case 'g':
+			suffix = "Gi"
+		}
+		if suffix != "" {
+			number = number[:len(number)-1]
+		}
+	}
+	if _, err := strconv.ParseFloat(number, 64); err != nil {
+		return resource.Quantity{}, fmt.Errorf("invalid memory '%s'", value)
+	}
+	return resource.ParseQuantity(number + suffix)
+}
+
+// convertVolumes mounts the volumes of a service into its container. Named
+// volumes become PersistentVolumeClaims, created with the first service that
+// mounts them; the rest become emptyDirs.
+func (conv *converter) convertVolumes(name string, service *Service, container *corev1.Container) ([]corev1.Volume, []runtime.Object) {
+	var volumes []corev1.Volume
+	var claims []runtime.Object
+	for itr, volume := range service.Volumes {
+		podVolume := corev1.Volume{Name: fmt.Sprintf("volume-%d", itr)}
+		switch {
+		case volume.Type == "volume" && volume.Source != "":
+			claimName := Name(volume.Source)
+			podVolume.Name = claimName
+			podVolume.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claimName, ReadOnly: volume.ReadOnly}
+			if owner, ok := conv.claimed[claimName]; ok {
+				conv.warn(name, "shares volume '%s' with %s; its ReadWriteOnce claim keeps their pods on one node", volume.Source, owner)
+				break
+			}
+			conv.claimed[claimName] = name
+			claims = append(claims, &corev1.PersistentVolumeClaim{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolumeClaim"},
+				ObjectMeta: metav1.ObjectMeta{Name: claimName},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(defaultVolumeSize)},
+					},
+				},
+			})
+		case volume.Type == "bind":
+			podVolume.EmptyDir = &corev1.EmptyDirVolumeSource{}
+			conv.warn(name, "bind mount '%s' is an emptyDir; mount it into the nodes with extraMounts and use a hostPath to share files", volume.Source)
+		case volume.Type == "tmpfs":
+			podVolume.EmptyDir = &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}
+		default:
+			podVolume.EmptyDir = &corev1.EmptyDirVolumeSource{}
+		}
+		volumes = append(volumes, podVolume)
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      podVolume.Name,
+			MountPath: volume.Target,
+			ReadOnly:  volume.ReadOnly,
+		})
+	}
+	return volumes, claims
+}
+
+// marshalObjects writes objects as a multi-document YAML manifest
+func marshalObjects(objects []runtime.Object) ([]byte, error) {
+	var buffer bytes.Buffer
+	for itr, object := range objects {
+		data, err := sigsyaml.Marshal(object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write manifest: %w", err)
+		}
+		if itr > 0 {
+			buffer.WriteString("---\n")
+		}
+		buffer.Write(data)
+	}
+	return buffer.Bytes(), nil
+}
+
+// sortedKeys returns the keys of a mapping in order
+func sortedKeys(mapping Mapping) []string {
+	keys := make([]string, 0, len(mapping))
+	for key := range mapping {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,154 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestName(test *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "valid", input: "web", expected: "web"},
+		{name: "underscores", input: "my_api", expected: "my-api"},
+		{name: "upper case and dots", input: "Web.Frontend", expected: "web-frontend"},
+		{name: "leading separator", input: "_worker_", expected: "worker"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if result := Name(tt.input); result != tt.expected {
+				test.Errorf("Expected '%s', got '%s'", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestMemoryQuantity(test *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+		errors   bool
+	}{
+		{name: "megabytes", value: "512m", expected: "512Mi"},
+		{name: "gigabytes with b", value: "1gb", expected: "1Gi"},
+		{name: "bytes", value: "1024", expected: "1024"},
+		{name: "invalid", value: "lots", errors: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			result, err := memoryQuantity(tt.value)
+			if tt.errors {
+				if err == nil {
+					test.Errorf("Expected an error, got %s", result.String())
+				}
+				return
+			}
+			if err != nil {
+				test.Fatalf("memoryQuantity() error = %v", err)
+			}
+			if result.String() != tt.expected {
+				test.Errorf("Expected %s, got %s", tt.expected, result.String())
+			}
+		})
+	}
+}
+
+func TestConvert(test *testing.T) {
+	project := writeCompose(test, `
+name: shop
+services:
+  my_api:
+    build:
+      context: ./api
+    ports:
+      - 8080:80
+    env_file: api.env
+    depends_on: [db]
+    volumes:
+      - ./src:/src
+    restart: "no"
+  db:
+    image: postgres:16
+    expose: ["5432"]
+    volumes:
+      - data:/var/lib/postgresql/data
+    healthcheck:
+      test: ["CMD", "pg_isready"]
+volumes:
+  data: {}
+`)
+	if err := os.WriteFile(filepath.Join(project.Dir, "api.env"), []byte("# comment\nLOG_LEVEL=debug\nNAME=\"shop\"\n"), 0644); err != nil {
+		test.Fatal(err)
+	}
+
+	result, err := Convert(project, Options{ManifestDir: "k8s", ConfigDir: project.Dir})
+	if err != nil {
+		test.Fatalf("Convert() error = %v", err)
+	}
+
+	var cfg krazeConfig
+	if err := yaml.Unmarshal(result.Config, &cfg); err != nil {
+		test.Fatalf("kraze.yml doesn't parse: %v", err)
+	}
+	if cfg.Cluster.Name != "shop" {
+		test.Errorf("Expected cluster 'shop', got '%s'", cfg.Cluster.Name)
+	}
+	api, ok := cfg.Services["my-api"]
+	if !ok {
+		test.Fatalf("Expected service 'my-api', got %v", cfg.Services)
+	}
+	if api.Namespace != "shop" || api.Path != "k8s/my-api.yaml" || api.Type != "manifests" {
+		test.Errorf("Unexpected service %+v", api)
+	}
+	if len(api.DependsOn) != 1 || api.DependsOn[0] != "db" {
+		test.Errorf("Expected depends_on [db], got %v", api.DependsOn)
+	}
+	if len(api.Ports) != 1 || api.Ports[0] != "8080:80" {
+		test.Errorf("Expected port 8080:80, got %v", api.Ports)
+	}
+	if api.Build == nil || api.Build.Context != "api" {
+		test.Errorf("Expected build context 'api', got %+v", api.Build)
+	}
+
+	manifestContains := map[string][]string{
+		"my-api.yaml": {"kind: Deployment", "kind: Service", "kind: ConfigMap", "LOG_LEVEL: debug", "NAME: shop", "emptyDir"},
+		"db.yaml":     {"kind: Deployment", "kind: Service", "kind: PersistentVolumeClaim", "claimName: data", "readinessProbe", "pg_isready"},
+	}
+	for file, expected := range manifestContains {
+		manifest := string(result.Manifests[file])
+		for _, itr := range expected {
+			if !strings.Contains(manifest, itr) {
+				test.Errorf("Expected %s to contain %q:\n%s", file, itr, manifest)
+			}
+		}
+	}
+
+	warnings := strings.Join(result.Warnings, "\n")
+	for _, expected := range []string{"my_api: other services reach it as 'my-api'", "bind mount './src'", "restart 'no'"} {
+		if !strings.Contains(warnings, expected) {
+			test.Errorf("Expected a warning containing %q, got:\n%s", expected, warnings)
+		}
+	}
+}
+
+func TestConvertNameCollision(test *testing.T) {
+	project := &Project{
+		Name: "app",
+		Services: map[string]*Service{
+			"my_api": {Image: "api"},
+			"my-api": {Image: "api"},
+		},
+	}
+	if _, err := Convert(project, Options{}); err == nil || !strings.Contains(err.Error(), "both become 'my-api'") {
+		test.Errorf("Expected a collision error, got %v", err)
+	}
+}
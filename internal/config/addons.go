@@ -0,0 +1,368 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// AddonPrefix marks a dependency on a cluster add-on (e.g., "addon:ingress-nginx")
+const AddonPrefix = "addon:"
+
+// Labels of the services added for add-ons: the add-on's name, and the preset
+// that enabled it, if any
+const (
+	addonLabel  = "kraze.dev/addon"
+	presetLabel = "kraze.dev/preset"
+)
+
+// defaultPodSubnet is the pod subnet of kind clusters unless cluster.networking sets one
+const defaultPodSubnet = "10.244.0.0/16"
+
+// ClusterAddon is a platform component shipped with kraze. Enabled add-ons are
+// added as services, so they take part in the dependency graph like any other
+// service and apps can depend on them with "addon:<name>".
+type ClusterAddon struct {
+	Name        string
+	Description string
+	CNI         bool                                       // Network plugin: needs the default CNI disabled, and every other service depends on it
+	Ingress     bool                                       // Ingress controller: binds host ports 80/443 on the ingress node
+	service     func(cluster *ClusterConfig) ServiceConfig // Service installing the add-on for a cluster
+}
+
+// ingressNginxValues configures ingress-nginx for kind: bind ports 80/443 on
+// the node labelled ingress-ready so the host port mappings reach it
+const ingressNginxValues = `controller:
+  hostPort:
+    enabled: true
+  service:
+    type: NodePort
+  nodeSelector:
+    ingress-ready: "true"
+  tolerations:
+    - key: node-role.kubernetes.io/control-plane
+      operator: Equal
+      effect: NoSchedule
+  watchIngressWithoutClass: true
+  publishService:
+    enabled: false
+  extraArgs:
+    publish-status-address: localhost
+`
+
+// traefikValues configures Traefik for kind like ingressNginxValues. The
+// entrypoints listen on 8000/8443 in the pod and 80/443 on the node.
+const traefikValues = `ports:
+  web:
+    hostPort: 80
+  websecure:
+    hostPort: 443
+service:
+  type: NodePort
+nodeSelector:
+  ingress-ready: "true"
+tolerations:
+  - key: node-role.kubernetes.io/control-plane
+    operator: Equal
+    effect: NoSchedule
+ingressClass:
+  enabled: true
+  isDefaultClass: true
+providers:
+  kubernetesIngress:
+    publishedService:
+      enabled: false
+`
+
+// ingressNodeLabel marks the node ingress add-ons run on, as in kind's ingress guide
+const ingressNodeLabel = "ingress-ready"
+
+// ingressPorts are the ports ingress add-ons bind on the ingress node
+var ingressPorts = []int32{80, 443}
+
+// clusterAddons are the built-in add-ons, keyed by name. Their charts are
+// pinned, so a new chart release can't change a cluster that kraze.yml didn't
+// change; a service with the add-on's name installs another version.
+var clusterAddons = map[string]ClusterAddon{
+	"ingress-nginx": {
+		Name:        "ingress-nginx",
+		Description: "Ingress controller bound to host ports 80/443 on the node labelled ingress-ready",
+		Ingress:     true,
+		service: func(cluster *ClusterConfig) ServiceConfig {
+			return ServiceConfig{
+				Type:         "helm",
+				Namespace:    "ingress-nginx",
+				Repo:         "https://kubernetes.github.io/ingress-nginx",
+				Chart:        "ingress-nginx",
+				Version:      "4.12.1",
+				ValuesInline: ingressNginxValues,
+			}
+		},
+	},
+	"traefik": {
+		Name:        "traefik",
+		Description: "Traefik ingress controller bound to host ports 80/443 on the node labelled ingress-ready",
+		Ingress:     true,
+		service: func(cluster *ClusterConfig) ServiceConfig {
+			return ServiceConfig{
+				Type:         "helm",
+				Namespace:    "traefik",
+				Repo:         "https://traefik.github.io/charts",
+				Chart:        "traefik",
+				Version:      "34.4.1",
+				ValuesInline: traefikValues,
+			}
+		},
+	},
+	"cert-manager": {
+		Name:        "cert-manager",
+		Description: "Certificate controller, with its CRDs",
+		service: func(cluster *ClusterConfig) ServiceConfig {
+			return ServiceConfig{
+				Type:         "helm",
+				Namespace:    "cert-manager",
+				Repo:         "https://charts.jetstack.io",
+				Chart:        "cert-manager",
+				Version:      "v1.17.1",
+				ValuesInline: "crds:\n  enabled: true\n",
+			}
+		},
+	},
+	"metallb": {
+		Name:        "metallb",
+		Description: "LoadBalancer implementation (address pools are configured by a service that depends on it)",
+		service: func(cluster *ClusterConfig) ServiceConfig {
+			return ServiceConfig{
+				Type:      "helm",
+				Namespace: "metallb-system",
+				Repo:      "https://metallb.github.io/metallb",
+				Chart:     "metallb",
+				Version:   "0.14.9",
+			}
+		},
+	},
+	"calico": {
+		Name:        "calico",
+		Description: "Calico network plugin, installed by the Tigera operator",
+		CNI:         true,
+		service: func(cluster *ClusterConfig) ServiceConfig {
+			podSubnet := defaultPodSubnet
+			if cluster.Networking != nil && cluster.Networking.PodSubnet != "" {
+				podSubnet = cluster.Networking.PodSubnet
+			}
+			return ServiceConfig{
+				Type:      "helm",
+				Namespace: "tigera-operator",
+				Repo:      "https://docs.tigera.io/calico/charts",
+				Chart:     "tigera-operator",
+				Version:   "v3.29.3",
+				ValuesInline: fmt.Sprintf(`installation:
+  calicoNetwork:
+    ipPools:
+      - cidr: %s
+        encapsulation: VXLAN
+`, podSubnet),
+			}
+		},
+	},
+}
+
+// ClusterAddons returns all built-in add-ons sorted by name
+func ClusterAddons() []ClusterAddon {
+	names := make([]string, 0, len(clusterAddons))
+	for name := range clusterAddons {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	addons := make([]ClusterAddon, 0, len(names))
+	for _, name := range names {
+		addons = append(addons, clusterAddons[name])
+	}
+	return addons
+}
+
+// GetClusterAddon returns the built-in add-on with the given name
+func GetClusterAddon(name string) (ClusterAddon, bool) {
+	addon, exists := clusterAddons[name]
+	return addon, exists
+}
+
+// AddonNames returns the sorted names of the add-ons enabled through
+// cluster.addons and the cluster preset
+func (cluster *ClusterConfig) AddonNames() []string {
+	names := append([]string(nil), cluster.Addons...)
+	if preset, exists := GetClusterPreset(cluster.Preset); exists {
+		names = append(names, preset.Addons...)
+	}
+	sort.Strings(names)
+	return slices.Compact(names)
+}
+
+// applyClusterAddons adds the services of the enabled add-ons, and resolves
+// "addon:" dependencies to them. A service with the same name as an add-on
+// replaces the add-on. A CNI add-on becomes a dependency of every other
+// service, since no pod can start before the network plugin. An ingress add-on
+// gets its node wired up (see wireIngressNode).
+func (cfg *Config) applyClusterAddons() error {
+	names := cfg.Cluster.AddonNames()
+	enabled := make(map[string]bool, len(names))
+	var cni, ingress string
+	for _, name := range names {
+		addon, exists := GetClusterAddon(name)
+		if !exists {
+			available := make([]string, 0, len(clusterAddons))
+			for _, a := range ClusterAddons() {
+				available = append(available, a.Name)
+			}
+			return &ValidationError{
+				Field:   "cluster.addons",
+				Message: fmt.Sprintf("unknown add-on '%s' (available: %s)", name, strings.Join(available, ", ")),
+			}
+		}
+		if addon.CNI {
+			if cni != "" {
+				return &ValidationError{Field: "cluster.addons", Message: fmt.Sprintf("only one CNI add-on can be enabled (got '%s' and '%s')", cni, name)}
+			}
+			if cfg.Cluster.IsExternal() {
+				return &ValidationError{Field: "cluster.addons", Message: fmt.Sprintf("CNI add-on '%s' is only available for kind clusters, not external clusters", name)}
+			}
+			if cfg.Cluster.Networking == nil || !cfg.Cluster.Networking.DisableDefaultCNI {
+				return &ValidationError{Field: "cluster.addons", Message: fmt.Sprintf("CNI add-on '%s' requires cluster.networking.disableDefaultCNI: true", name)}
+			}
+			cni = name
+		}
+		if addon.Ingress {
+			if ingress != "" {
+				return &ValidationError{Field: "cluster.addons", Message: fmt.Sprintf("only one ingress add-on can be enabled, since both bind ports 80/443 (got '%s' and '%s')", ingress, name)}
+			}
+			ingress = name
+		}
+		enabled[name] = true
+
+		if _, exists := cfg.Services[name]; exists {
+			continue
+		}
+		if cfg.Services == nil {
+			cfg.Services = make(map[string]ServiceConfig)
+		}
+		svc := addon.service(&cfg.Cluster)
+		svc.Name = name
+		svc.Description = addon.Description
+		svc.Labels = map[string]string{addonLabel: name}
+		if preset, exists := GetClusterPreset(cfg.Cluster.Preset); exists && slices.Contains(preset.Addons, name) {
+			svc.Labels[presetLabel] = preset.Name
+		}
+		cfg.Services[name] = svc
+		cfg.deriveOrigins(originPath("services", name), svc, "add-on "+name, false)
+	}
+
+	// kind_config files are used as is, so they wire the ingress node themselves
+	if ingress != "" && !cfg.Cluster.IsExternal() && !cfg.Cluster.IsNone() && cfg.Cluster.KindConfig == "" {
+		nodes, err := wireIngressNode(cfg.Cluster.Config)
+		if err != nil {
+			return &ValidationError{Field: "cluster.addons", Message: fmt.Sprintf("ingress add-on '%s': %v", ingress, err)}
+		}
+		cfg.Cluster.Config = nodes
+		cfg.deriveOrigins("cluster.config", nodes, "add-on "+ingress, false)
+	}
+
+	for name, svc := range cfg.Services {
+		for _, dep := range svc.DependsOn.Names() {
+			addonName, isAddonRef := strings.CutPrefix(dep, AddonPrefix)
+			if !isAddonRef {
+				continue
+			}
+			if !enabled[addonName] {
+				message := fmt.Sprintf("add-on '%s' is not enabled (add it to cluster.addons)", addonName)
+				if _, exists := GetClusterAddon(addonName); !exists {
+					message = fmt.Sprintf("unknown add-on '%s'", addonName)
+				}
+				return &ValidationError{Field: fmt.Sprintf("service '%s' depends_on", name), Message: message}
+			}
+			svc.DependsOn.rename(dep, addonName)
+		}
+		if cni != "" && name != cni && !slices.Contains(svc.DependsOn.Names(), cni) {
+			svc.DependsOn.names = append(slices.Clone(svc.DependsOn.names), cni)
+		}
+		cfg.Services[name] = svc
+	}
+
+	return nil
+}
+
+// wireIngressNode labels the node ingress add-ons run on ingress-ready and maps
+// host ports 80 and 443 to it, so there's no kind boilerplate to copy around.
+// The node is the one already labelled, or else the first control-plane, or
+// other, node that isn't replicated. Existing mappings of the ports are kept,
+// so they can use other host ports. The nodes are copied, not changed in place.
+func wireIngressNode(nodes []KindNode) ([]KindNode, error) {
+	if len(nodes) == 0 {
+		nodes = []KindNode{{Role: "control-plane"}}
+	}
+
+	target := slices.IndexFunc(nodes, func(node KindNode) bool { return node.Labels[ingressNodeLabel] == "true" })
+	if target < 0 {
+		target = slices.IndexFunc(nodes, func(node KindNode) bool { return node.Role == "control-plane" && node.Replicas <= 1 })
+	}
+	if target < 0 {
+		target = slices.IndexFunc(nodes, func(node KindNode) bool { return node.Replicas <= 1 })
+	}
+	if target < 0 {
+		return nil, fmt.Errorf("every node is replicated, and replicas can't all bind host ports 80/443; add a node with %s: \"true\"", ingressNodeLabel)
+	}
+
+	nodes = slices.Clone(nodes)
+	node := &nodes[target]
+	node.Labels = copyStringMap(node.Labels)
+	if node.Labels == nil {
+		node.Labels = make(map[string]string)
+	}
+	node.Labels[ingressNodeLabel] = "true"
+
+	mappings := slices.Clone(node.ExtraPortMappings)
+	for _, port := range ingressPorts {
+		mapped := slices.ContainsFunc(mappings, func(pm PortMapping) bool {
+			return pm.ContainerPort == port && (pm.Protocol == "" || strings.EqualFold(pm.Protocol, "TCP"))
+		})
+		if mapped {
+			continue
+		}
+		if node.Replicas > 1 {
+			return nil, fmt.Errorf("the %s node labelled %s has %d replicas, which can't all bind host port %d; map the port yourself or label a single node", node.Role, ingressNodeLabel, node.Replicas, port)
+		}
+		mappings = append(mappings, PortMapping{ContainerPort: port, HostPort: port, Protocol: "TCP"})
+	}
+	node.ExtraPortMappings = mappings
+	return nodes, nil
+}
+
+// rename replaces a dependency's name, keeping its condition
+func (d *DependsOnField) rename(from, to string) {
+	names := make([]string, len(d.names))
+	for itr, name := range d.names {
+		if name == from {
+			name = to
+		}
+		names[itr] = name
+	}
+	d.names = compactStrings(names)
+	if condition, exists := d.conditions[from]; exists {
+		delete(d.conditions, from)
+		d.conditions[to] = condition
+	}
+}
+
+// compactStrings removes repeated strings, keeping the first occurrence
+func compactStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := values[:0]
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
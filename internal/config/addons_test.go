@@ -0,0 +1,223 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseClusterAddons(test *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expectError string
+		check       func(test *testing.T, cfg *Config)
+	}{
+		{
+			name: "addon dependency resolves to the add-on service",
+			content: `cluster:
+  name: dev
+  addons: [cert-manager]
+services:
+  app:
+    type: manifests
+    path: app.yaml
+    depends_on: [addon:cert-manager]
+`,
+			check: func(test *testing.T, cfg *Config) {
+				addon, exists := cfg.Services["cert-manager"]
+				if !exists || !addon.IsHelm() {
+					test.Fatalf("expected cert-manager add-on service, got %+v", addon)
+				}
+				if addon.Labels["kraze.dev/addon"] != "cert-manager" {
+					test.Errorf("expected kraze.dev/addon label, got %v", addon.Labels)
+				}
+				if deps := cfg.Services["app"].DependsOn.Names(); !slices.Equal(deps, []string{"cert-manager"}) {
+					test.Errorf("expected dependency on cert-manager, got %v", deps)
+				}
+			},
+		},
+		{
+			name: "addon dependency keeps its condition",
+			content: `cluster:
+  name: dev
+  preset: ingress-dev
+services:
+  app:
+    type: manifests
+    path: app.yaml
+    depends_on:
+      addon:ingress-nginx:
+        condition: service_healthy
+        probe: http://ingress-nginx-controller.ingress-nginx/healthz
+`,
+			check: func(test *testing.T, cfg *Config) {
+				app := cfg.Services["app"]
+				if condition := app.DependsOn.Condition("ingress-nginx"); condition.Condition != "service_healthy" {
+					test.Errorf("expected service_healthy condition, got %+v", condition)
+				}
+				if cfg.Services["ingress-nginx"].Labels["kraze.dev/preset"] != "ingress-dev" {
+					test.Errorf("expected kraze.dev/preset label on the preset's add-on")
+				}
+			},
+		},
+		{
+			name: "CNI addon is a dependency of every other service",
+			content: `cluster:
+  name: dev
+  addons: [calico, metallb]
+  networking:
+    disableDefaultCNI: true
+    podSubnet: 10.10.0.0/16
+services:
+  app:
+    type: manifests
+    path: app.yaml
+`,
+			check: func(test *testing.T, cfg *Config) {
+				for _, name := range []string{"app", "metallb"} {
+					if !slices.Contains(cfg.Services[name].DependsOn.Names(), "calico") {
+						test.Errorf("expected %s to depend on calico, got %v", name, cfg.Services[name].DependsOn.Names())
+					}
+				}
+				calico := cfg.Services["calico"]
+				if !calico.DependsOn.IsEmpty() {
+					test.Errorf("expected calico to have no dependencies, got %v", calico.DependsOn.Names())
+				}
+				if !strings.Contains(calico.ValuesInline, "10.10.0.0/16") {
+					test.Errorf("expected calico values to use the pod subnet, got %s", calico.ValuesInline)
+				}
+			},
+		},
+		{
+			name: "CNI addon requires the default CNI disabled",
+			content: `cluster:
+  name: dev
+  addons: [calico]
+`,
+			expectError: "requires cluster.networking.disableDefaultCNI",
+		},
+		{
+			name: "dependency on addon that isn't enabled",
+			content: `cluster:
+  name: dev
+services:
+  app:
+    type: manifests
+    path: app.yaml
+    depends_on: [addon:metallb]
+`,
+			expectError: "add-on 'metallb' is not enabled",
+		},
+		{
+			name: "ingress addon wires up the control-plane node",
+			content: `cluster:
+  name: dev
+  addons: [ingress-nginx]
+`,
+			check: func(test *testing.T, cfg *Config) {
+				if len(cfg.Cluster.Config) != 1 {
+					test.Fatalf("expected a single control-plane node, got %+v", cfg.Cluster.Config)
+				}
+				node := cfg.Cluster.Config[0]
+				if node.Role != "control-plane" || node.Labels["ingress-ready"] != "true" {
+					test.Errorf("expected control-plane labelled ingress-ready, got %+v", node)
+				}
+				want := []PortMapping{{ContainerPort: 80, HostPort: 80, Protocol: "TCP"}, {ContainerPort: 443, HostPort: 443, Protocol: "TCP"}}
+				if !slices.Equal(node.ExtraPortMappings, want) {
+					test.Errorf("expected port mappings %v, got %v", want, node.ExtraPortMappings)
+				}
+			},
+		},
+		{
+			name: "ingress addon keeps explicit mappings and labelled node",
+			content: `cluster:
+  name: dev
+  addons: [traefik]
+  config:
+    - role: control-plane
+    - role: worker
+      labels:
+        ingress-ready: "true"
+      extraPortMappings:
+        - containerPort: 80
+          hostPort: 8080
+`,
+			check: func(test *testing.T, cfg *Config) {
+				if mappings := cfg.Cluster.Config[0].ExtraPortMappings; len(mappings) != 0 {
+					test.Errorf("expected no mappings on the control-plane, got %v", mappings)
+				}
+				want := []PortMapping{{ContainerPort: 80, HostPort: 8080}, {ContainerPort: 443, HostPort: 443, Protocol: "TCP"}}
+				if mappings := cfg.Cluster.Config[1].ExtraPortMappings; !slices.Equal(mappings, want) {
+					test.Errorf("expected port mappings %v, got %v", want, mappings)
+				}
+				if cfg.Services["traefik"].Namespace != "traefik" {
+					test.Errorf("expected traefik add-on service, got %+v", cfg.Services["traefik"])
+				}
+			},
+		},
+		{
+			name: "ingress addon skips replicated control planes",
+			content: `cluster:
+  name: dev
+  preset: ha-3node
+  addons: [ingress-nginx]
+`,
+			check: func(test *testing.T, cfg *Config) {
+				for _, node := range cfg.Cluster.Config {
+					wired := node.Labels["ingress-ready"] == "true"
+					if wired != (node.Role == "worker") {
+						test.Errorf("expected only the worker to be wired, got %+v", node)
+					}
+				}
+			},
+		},
+		{
+			name: "two ingress addons",
+			content: `cluster:
+  name: dev
+  addons: [ingress-nginx, traefik]
+`,
+			expectError: "only one ingress add-on",
+		},
+		{
+			name: "unknown addon",
+			content: `cluster:
+  name: dev
+  addons: [linkerd]
+`,
+			expectError: "unknown add-on 'linkerd'",
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			configFile := filepath.Join(test.TempDir(), "kraze.yml")
+			if err := os.WriteFile(configFile, []byte(tt.content), 0644); err != nil {
+				test.Fatalf("failed to write config: %v", err)
+			}
+
+			cfg, err := Parse(configFile)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					test.Fatalf("expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				test.Fatalf("unexpected error: %v", err)
+			}
+			tt.check(test, cfg)
+		})
+	}
+}
+
+func TestClusterAddonsPinned(test *testing.T) {
+	for _, addon := range ClusterAddons() {
+		if svc := addon.service(&ClusterConfig{}); svc.Version == "" {
+			test.Errorf("add-on '%s' installs chart '%s' without a version", addon.Name, svc.Chart)
+		}
+	}
+}
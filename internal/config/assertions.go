@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// DefaultAssertionTimeout is how long an assertion is retried unless it sets a timeout
+const DefaultAssertionTimeout = 30 * time.Second
+
+// AssertionConfig is a resource a service is expected to have once it's ready,
+// checked by 'kraze up' and 'kraze assert' like a kuttl or chainsaw assert file
+type AssertionConfig struct {
+	APIVersion string         `yaml:"api_version,omitempty"` // API version of the resource (default: v1)
+	Kind       string         `yaml:"kind"`                  // Kind of the resource (e.g., Deployment)
+	Name       string         `yaml:"name,omitempty"`        // Name of the resource
+	Selector   string         `yaml:"selector,omitempty"`    // Label selector instead of a name: at least one resource must match, and all matching must pass
+	Namespace  string         `yaml:"namespace,omitempty"`   // Namespace of the resource (default: the service's namespace)
+	Fields     map[string]any `yaml:"fields,omitempty"`      // Expected values by JSONPath without braces (e.g., status.readyReplicas: 2)
+	Absent     bool           `yaml:"absent,omitempty"`      // The resource must not exist
+	Timeout    string         `yaml:"timeout,omitempty"`     // How long to retry until the assertion passes (default: 30s)
+}
+
+// GetAPIVersion returns the API version of the asserted resource
+func (assertion *AssertionConfig) GetAPIVersion() string {
+	if assertion.APIVersion != "" {
+		return assertion.APIVersion
+	}
+	return "v1"
+}
+
+// GetTimeout returns how long to retry the assertion
+func (assertion *AssertionConfig) GetTimeout() (time.Duration, error) {
+	if assertion.Timeout == "" {
+		return DefaultAssertionTimeout, nil
+	}
+	return time.ParseDuration(assertion.Timeout)
+}
+
+// Target describes the asserted resource as Kind/name or Kind[selector]
+func (assertion *AssertionConfig) Target() string {
+	if assertion.Selector != "" {
+		return fmt.Sprintf("%s[%s]", assertion.Kind, assertion.Selector)
+	}
+	return assertion.Kind + "/" + assertion.Name
+}
+
+// FieldPaths returns the asserted field paths in alphabetical order
+func (assertion *AssertionConfig) FieldPaths() []string {
+	paths := make([]string, 0, len(assertion.Fields))
+	for path := range assertion.Fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// ParseFieldPath parses an assertion's field path as a kubectl JSONPath
+// expression, accepting it with or without the leading dot and braces
+func ParseFieldPath(path string) (*jsonpath.JSONPath, error) {
+	expression := strings.TrimSpace(path)
+	if !strings.HasPrefix(expression, "{") {
+		expression = "{." + strings.TrimPrefix(expression, ".") + "}"
+	}
+	parser := jsonpath.New("assertion")
+	if err := parser.Parse(expression); err != nil {
+		return nil, err
+	}
+	return parser, nil
+}
+
+// validate checks that the assertion names a resource and something to expect of it
+func (assertion *AssertionConfig) validate(field string) error {
+	if assertion.Kind == "" {
+		return &ValidationError{Field: field + ".kind", Message: "kind is required"}
+	}
+	if (assertion.Name == "") == (assertion.Selector == "") {
+		return &ValidationError{Field: field, Message: "exactly one of name or selector is required"}
+	}
+	if assertion.Selector != "" {
+		if _, err := labels.Parse(assertion.Selector); err != nil {
+			return &ValidationError{Field: field + ".selector", Message: fmt.Sprintf("invalid label selector '%s': %v", assertion.Selector, err)}
+		}
+	}
+	if assertion.Absent && len(assertion.Fields) > 0 {
+		return &ValidationError{Field: field, Message: "absent and fields are mutually exclusive"}
+	}
+	for _, path := range assertion.FieldPaths() {
+		if _, err := ParseFieldPath(path); err != nil {
+			return &ValidationError{Field: field + ".fields", Message: fmt.Sprintf("invalid path '%s': %v", path, err)}
+		}
+	}
+	if timeout, err := assertion.GetTimeout(); err != nil || timeout <= 0 {
+		return &ValidationError{Field: field + ".timeout", Message: fmt.Sprintf("invalid duration '%s'", assertion.Timeout)}
+	}
+	return nil
+}
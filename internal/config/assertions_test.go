@@ -0,0 +1,72 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateAssertion(test *testing.T) {
+	tests := []struct {
+		name      string
+		assertion AssertionConfig
+		wantErr   string
+	}{
+		{
+			name:      "fields of a named resource",
+			assertion: AssertionConfig{APIVersion: "apps/v1", Kind: "Deployment", Name: "api", Fields: map[string]any{"status.readyReplicas": 2}},
+		},
+		{
+			name:      "filter expression",
+			assertion: AssertionConfig{APIVersion: "apps/v1", Kind: "Deployment", Name: "api", Fields: map[string]any{`status.conditions[?(@.type=="Available")].status`: "True"}},
+		},
+		{
+			name:      "absent resources by selector",
+			assertion: AssertionConfig{Kind: "Pod", Selector: "app=legacy", Absent: true},
+		},
+		{
+			name:      "missing kind",
+			assertion: AssertionConfig{Name: "api"},
+			wantErr:   "kind is required",
+		},
+		{
+			name:      "name and selector",
+			assertion: AssertionConfig{Kind: "Pod", Name: "api", Selector: "app=api"},
+			wantErr:   "exactly one of name or selector",
+		},
+		{
+			name:      "invalid selector",
+			assertion: AssertionConfig{Kind: "Pod", Selector: "app in (api"},
+			wantErr:   "invalid label selector",
+		},
+		{
+			name:      "absent with fields",
+			assertion: AssertionConfig{Kind: "ConfigMap", Name: "api", Absent: true, Fields: map[string]any{"data.mode": "dev"}},
+			wantErr:   "mutually exclusive",
+		},
+		{
+			name:      "invalid path",
+			assertion: AssertionConfig{Kind: "ConfigMap", Name: "api", Fields: map[string]any{"data[": "dev"}},
+			wantErr:   "invalid path",
+		},
+		{
+			name:      "invalid timeout",
+			assertion: AssertionConfig{Kind: "ConfigMap", Name: "api", Timeout: "soon"},
+			wantErr:   "invalid duration",
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			err := tt.assertion.validate("assertions[0]")
+			if tt.wantErr == "" {
+				if err != nil {
+					test.Errorf("validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				test.Errorf("validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
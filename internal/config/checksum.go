@@ -0,0 +1,66 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+)
+
+// Checksum returns a hash of the cluster settings that only take effect when
+// a kind cluster is created: the nodes with their ports and mounts, the node
+// image, networking, CA certificates, registries, proxy, GPUs and the settings
+// passed through to kind. A cluster created from a config with another
+// checksum runs on an outdated topology.
+func (c *ClusterConfig) Checksum() string {
+	var kindConfig string
+	if c.KindConfig != "" {
+		// The file's networking and patches aren't in Config
+		if data, err := os.ReadFile(c.KindConfig); err == nil {
+			sum := sha256.Sum256(data)
+			kindConfig = hex.EncodeToString(sum[:])
+		}
+	}
+
+	settings := struct {
+		Version            string
+		NodeImage          string
+		Nodes              []KindNode
+		KindConfig         string
+		Networking         *NetworkingConfig
+		Topology           *TopologyConfig
+		Network            string
+		IPv4Address        string
+		Subnet             string
+		CACertificates     []string
+		InsecureRegistries []string
+		Proxy              *ProxyConfig
+		GPU                *GPUConfig
+		// Omitted when unset, so clusters created before they existed don't drift
+		FeatureGates         map[string]bool        `json:",omitempty"`
+		RuntimeConfig        map[string]string      `json:",omitempty"`
+		KubeadmConfigPatches []string               `json:",omitempty"`
+		KubeletConfig        map[string]interface{} `json:",omitempty"`
+	}{
+		Version:              c.Version,
+		NodeImage:            c.NodeImage,
+		Nodes:                c.Config,
+		KindConfig:           kindConfig,
+		Networking:           c.Networking,
+		Topology:             c.Topology,
+		Network:              c.Network,
+		IPv4Address:          c.IPv4Address,
+		Subnet:               c.Subnet,
+		CACertificates:       c.CACertificates,
+		InsecureRegistries:   c.InsecureRegistries,
+		Proxy:                c.Proxy,
+		GPU:                  c.GPU,
+		FeatureGates:         c.FeatureGates,
+		RuntimeConfig:        c.RuntimeConfig,
+		KubeadmConfigPatches: c.KubeadmConfigPatches,
+		KubeletConfig:        c.KubeletConfig,
+	}
+	data, _ := json.Marshal(settings)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
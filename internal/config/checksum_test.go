@@ -0,0 +1,86 @@
+package config
+
+import "testing"
+
+func TestClusterChecksum(test *testing.T) {
+	base := func() ClusterConfig {
+		return ClusterConfig{
+			Name: "dev",
+			Config: []KindNode{{
+				Role:              "control-plane",
+				ExtraPortMappings: []PortMapping{{ContainerPort: 80, HostPort: 8080}},
+			}},
+			InsecureRegistries: []string{"registry.local:5000"},
+		}
+	}
+	checksum := func(change func(cluster *ClusterConfig)) string {
+		cluster := base()
+		change(&cluster)
+		return cluster.Checksum()
+	}
+	unchanged := checksum(func(*ClusterConfig) {})
+
+	tests := []struct {
+		name    string
+		change  func(cluster *ClusterConfig)
+		drifted bool
+	}{
+		{
+			name:    "host port",
+			change:  func(cluster *ClusterConfig) { cluster.Config[0].ExtraPortMappings[0].HostPort = 9090 },
+			drifted: true,
+		},
+		{
+			name:    "worker added",
+			change:  func(cluster *ClusterConfig) { cluster.Config = append(cluster.Config, KindNode{Role: "worker"}) },
+			drifted: true,
+		},
+		{
+			name:    "networking",
+			change:  func(cluster *ClusterConfig) { cluster.Networking = &NetworkingConfig{PodSubnet: "10.200.0.0/16"} },
+			drifted: true,
+		},
+		{
+			name:    "CA certificates",
+			change:  func(cluster *ClusterConfig) { cluster.CACertificates = []string{"/etc/ssl/corp.pem"} },
+			drifted: true,
+		},
+		{
+			name:    "registries",
+			change:  func(cluster *ClusterConfig) { cluster.InsecureRegistries = nil },
+			drifted: true,
+		},
+		{
+			name: "feature gates",
+			change: func(cluster *ClusterConfig) {
+				cluster.FeatureGates = map[string]bool{"InPlacePodVerticalScaling": true}
+			},
+			drifted: true,
+		},
+		{
+			name: "node sysctls",
+			change: func(cluster *ClusterConfig) {
+				cluster.Config[0].Sysctls = map[string]string{"vm.max_map_count": "262144"}
+			},
+			drifted: true,
+		},
+		{
+			name:    "preloaded images",
+			change:  func(cluster *ClusterConfig) { cluster.PreloadImages = []string{"redis:7"} },
+			drifted: false,
+		},
+		{
+			name:    "add-ons",
+			change:  func(cluster *ClusterConfig) { cluster.Addons = []string{"metallb"} },
+			drifted: false,
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if drifted := checksum(tt.change) != unchanged; drifted != tt.drifted {
+				test.Errorf("Expected drifted=%v, got %v", tt.drifted, drifted)
+			}
+		})
+	}
+}
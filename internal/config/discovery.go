@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// defaultDiscoveryConfigMap is the name of the discovery ConfigMap
+const defaultDiscoveryConfigMap = "kraze-discovery"
+
+// DiscoveryConfig publishes where each service can be reached in a ConfigMap
+// (<SERVICE>_HOST, <SERVICE>_PORT, ...) in every service namespace, so
+// services find each other without hardcoding namespace-qualified hostnames
+type DiscoveryConfig struct {
+	ConfigMap string `yaml:"config_map,omitempty"` // Default: kraze-discovery
+	EnvFile   string `yaml:"env_file,omitempty"`   // Host .env file written after up, relative to the config file
+	Inject    bool   `yaml:"inject,omitempty"`     // Add the ConfigMap to every service's containers with envFrom (services can override it)
+}
+
+// GetConfigMap returns the name of the discovery ConfigMap
+func (discovery *DiscoveryConfig) GetConfigMap() string {
+	if discovery.ConfigMap != "" {
+		return discovery.ConfigMap
+	}
+	return defaultDiscoveryConfigMap
+}
+
+// applyDiscovery validates the discovery block and sets the ConfigMap each
+// injected service's containers load, so providers only look at the service
+func (cfg *Config) applyDiscovery() error {
+	for name, svc := range cfg.Services {
+		if svc.InjectDiscovery != nil && *svc.InjectDiscovery && cfg.Discovery == nil {
+			return &ValidationError{Field: fmt.Sprintf("services.%s.inject_discovery", name), Message: "requires a top-level discovery block"}
+		}
+	}
+	if cfg.Discovery == nil {
+		return nil
+	}
+	configMap := cfg.Discovery.GetConfigMap()
+	if errs := validation.IsDNS1123Subdomain(configMap); len(errs) > 0 {
+		return &ValidationError{Field: "discovery.config_map", Message: fmt.Sprintf("invalid ConfigMap name '%s': %s", configMap, strings.Join(errs, "; "))}
+	}
+	for name, svc := range cfg.Services {
+		inject := cfg.Discovery.Inject
+		if svc.InjectDiscovery != nil {
+			inject = *svc.InjectDiscovery
+		}
+		svc.DiscoveryConfigMap = ""
+		if inject {
+			svc.DiscoveryConfigMap = configMap
+		}
+		cfg.Services[name] = svc
+	}
+	return nil
+}
+
+// DiscoveryKey returns the variable prefix of a service: its name in upper
+// case with everything but letters and digits replaced by underscores
+func DiscoveryKey(name string) string {
+	key := []byte(strings.ToUpper(name))
+	for itr, char := range key {
+		if (char < 'A' || char > 'Z') && (char < '0' || char > '9') {
+			key[itr] = '_'
+		}
+	}
+	if len(key) > 0 && key[0] >= '0' && key[0] <= '9' {
+		return "_" + string(key)
+	}
+	return string(key)
+}
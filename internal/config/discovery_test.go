@@ -0,0 +1,97 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyDiscovery(test *testing.T) {
+	dir := writeConfigFiles(test, map[string]string{
+		"kraze.yml": `cluster:
+  name: dev
+discovery:
+  env_file: .env.kraze
+  inject: true
+services:
+  api:
+    type: manifests
+    path: api.yaml
+  postgres:
+    type: manifests
+    path: postgres.yaml
+    inject_discovery: false
+`,
+	})
+
+	cfg, err := Parse(filepath.Join(dir, "kraze.yml"))
+	if err != nil {
+		test.Fatalf("Parse() error = %v", err)
+	}
+	if got := cfg.Services["api"].DiscoveryConfigMap; got != "kraze-discovery" {
+		test.Errorf("api DiscoveryConfigMap = %q, want kraze-discovery", got)
+	}
+	if got := cfg.Services["postgres"].DiscoveryConfigMap; got != "" {
+		test.Errorf("postgres DiscoveryConfigMap = %q, want none", got)
+	}
+	if want := filepath.Join(dir, ".env.kraze"); cfg.Discovery.EnvFile != want {
+		test.Errorf("EnvFile = %q, want %q", cfg.Discovery.EnvFile, want)
+	}
+}
+
+func TestApplyDiscoveryErrors(test *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name: "invalid config map",
+			config: `discovery:
+  config_map: Kraze_Discovery
+services: {}
+`,
+			wantErr: "discovery.config_map",
+		},
+		{
+			name: "inject without discovery",
+			config: `services:
+  api:
+    type: manifests
+    path: api.yaml
+    inject_discovery: true
+`,
+			wantErr: "requires a top-level discovery block",
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			dir := writeConfigFiles(test, map[string]string{"kraze.yml": "cluster:\n  name: dev\n" + tt.config})
+			_, err := Parse(filepath.Join(dir, "kraze.yml"))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				test.Errorf("Parse() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDiscoveryKey(test *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "api", want: "API"},
+		{name: "user-service", want: "USER_SERVICE"},
+		{name: "redis.cache", want: "REDIS_CACHE"},
+		{name: "3scale", want: "_3SCALE"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := DiscoveryKey(tt.name); got != tt.want {
+				test.Errorf("DiscoveryKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,19 +3,120 @@ package config
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"regexp"
 	"strings"
+	"sync"
 )
 
 // envVarPattern matches ${VAR_NAME} or ${VAR_NAME:-default}
 var envVarPattern = regexp.MustCompile(`\$\{([A-Z_][A-Z0-9_]*)(:-([^}]*))?\}`)
 
-// ExpandEnvVars expands environment variables in the given string
-// Supports formats:
+// variableNamePattern matches the variable names envVarPattern expands
+var variableNamePattern = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+
+// variableOverrides are set with --var and take precedence over the environment
+var variableOverrides map[string]string
+
+// SetVariableOverrides sets variables (from --var NAME=VALUE) that take
+// precedence over the environment when expanding ${NAME} references
+func SetVariableOverrides(vars map[string]string) {
+	variableOverrides = vars
+}
+
+// ParseVariableOverrides parses NAME=VALUE pairs as given to --var
+func ParseVariableOverrides(pairs []string) (map[string]string, error) {
+	vars := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || !variableNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid variable '%s': expected NAME=VALUE with an upper-case NAME", pair)
+		}
+		vars[name] = value
+	}
+	return vars, nil
+}
+
+// Variables resolves ${NAME} references in a config file and the values files
+// it refers to. Lookups check --var overrides, then the environment, then the
+// built-in variables derived from the config file:
 //
-//	${VAR_NAME} - expands to value of VAR_NAME or empty string if not set
-//	${VAR_NAME:-default} - expands to value of VAR_NAME or 'default' if not set
-func ExpandEnvVars(s string) string {
+//	KRAZE_CLUSTER_NAME - cluster.name of the config file
+//	KRAZE_CONFIG_DIR   - absolute directory of the config file
+//	KRAZE_SUFFIX       - the --suffix of the variant being run (empty otherwise)
+//	GIT_SHA            - short commit of the repository holding the config file
+//	GIT_BRANCH         - current branch of that repository
+//	HOME               - the user's home directory (also on Windows)
+//
+// Values files skip the environment and HOME unless their service sets
+// values_env, since charts often carry ${HOME}-like strings of their own.
+type Variables struct {
+	ClusterName string
+	ConfigDir   string
+
+	gitOnce sync.Once
+	gitSHA  string
+	branch  string
+}
+
+// lookup resolves a variable by name. The environment (and HOME) is only
+// read with hostEnv.
+func (vars *Variables) lookup(name string, hostEnv bool) (string, bool) {
+	if value, ok := variableOverrides[name]; ok {
+		return value, true
+	}
+	if !hostEnv && name == "HOME" {
+		return "", false
+	}
+	if value, ok := os.LookupEnv(name); ok && hostEnv {
+		return value, true
+	}
+	if vars == nil {
+		return "", false
+	}
+
+	switch name {
+	case "KRAZE_CLUSTER_NAME":
+		return vars.ClusterName, vars.ClusterName != ""
+	case "KRAZE_CONFIG_DIR":
+		return vars.ConfigDir, vars.ConfigDir != ""
+	case "KRAZE_SUFFIX":
+		return variant.suffix, true
+	case "GIT_SHA", "GIT_BRANCH":
+		vars.gitOnce.Do(vars.readGit)
+		if name == "GIT_SHA" {
+			return vars.gitSHA, vars.gitSHA != ""
+		}
+		return vars.branch, vars.branch != ""
+	case "HOME":
+		home, err := os.UserHomeDir()
+		return home, err == nil
+	}
+	return "", false
+}
+
+// readGit looks up the commit and branch of the config directory's repository.
+// Outside a repository (or without git) both stay empty.
+func (vars *Variables) readGit() {
+	if vars.ConfigDir == "" {
+		return
+	}
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = vars.ConfigDir
+		out, err := cmd.Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+	vars.gitSHA = git("rev-parse", "--short", "HEAD")
+	vars.branch = git("rev-parse", "--abbrev-ref", "HEAD")
+}
+
+// expand replaces ${NAME} and ${NAME:-default} references. Unresolved
+// references without a default become empty, unless keepUnresolved is set.
+func (vars *Variables) expand(s string, keepUnresolved, hostEnv bool) string {
 	return envVarPattern.ReplaceAllStringFunc(s, func(match string) string {
 		// Extract variable name and default value
 		matches := envVarPattern.FindStringSubmatch(match)
@@ -24,21 +125,41 @@ func ExpandEnvVars(s string) string {
 		}
 
 		varName := matches[1]
-		defaultValue := ""
-		if len(matches) >= 4 {
-			defaultValue = matches[3]
-		}
-
-		// Get environment variable value
-		value, exists := os.LookupEnv(varName)
-		if exists {
+		if value, exists := vars.lookup(varName, hostEnv); exists {
 			return value
 		}
 
-		return defaultValue
+		if matches[2] == "" && keepUnresolved {
+			return match
+		}
+		return matches[3]
 	})
 }
 
+// Expand expands variables in s; unset variables without a default become empty
+func (vars *Variables) Expand(s string) string {
+	return vars.expand(s, false, true)
+}
+
+// ExpandValues expands variables in a values file. References to unset
+// variables without a default are kept as-is, since values often carry ${...}
+// that is meant for the application or a shell rather than for kraze. The
+// environment is only read with hostEnv (the service's values_env), so only
+// --var values and the built-ins are substituted by default.
+func (vars *Variables) ExpandValues(data []byte, hostEnv bool) []byte {
+	return []byte(vars.expand(string(data), true, hostEnv))
+}
+
+// ExpandEnvVars expands environment variables in the given string
+// Supports formats:
+//
+//	${VAR_NAME} - expands to value of VAR_NAME or empty string if not set
+//	${VAR_NAME:-default} - expands to value of VAR_NAME or 'default' if not set
+func ExpandEnvVars(s string) string {
+	var vars *Variables
+	return vars.Expand(s)
+}
+
 // ExpandEnvVarsInBytes expands environment variables in byte slice
 // This is useful for expanding before YAML parsing
 func ExpandEnvVarsInBytes(data []byte) []byte {
@@ -118,6 +239,8 @@ func ExpandEnvVarsInConfig(cfg *Config) {
 		svc.Version = ExpandEnvVars(svc.Version)
 		svc.Path = ExpandEnvVars(svc.Path)
 		svc.PostReadyDelay = ExpandEnvVars(svc.PostReadyDelay)
+		svc.PendingTimeout = ExpandEnvVars(svc.PendingTimeout)
+		svc.RetryBackoff = ExpandEnvVars(svc.RetryBackoff)
 
 		// Expand values files
 		if !svc.Values.IsEmpty() {
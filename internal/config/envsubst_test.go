@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -94,3 +95,96 @@ func TestExpandEnvVarsInStruct(test *testing.T) {
 		test.Errorf("Expected chart 'redis' (from default), got '%s'", chart)
 	}
 }
+
+func TestVariables(test *testing.T) {
+	test.Setenv("APP_TAG", "from-env")
+	test.Setenv("GIT_SHA", "abc1234")
+	test.Setenv("KRAZE_TEST_HOST_ONLY", "from-host")
+	SetVariableOverrides(map[string]string{"APP_TAG": "from-var"})
+	defer SetVariableOverrides(nil)
+
+	vars := &Variables{ClusterName: "dev", ConfigDir: "/work/app"}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "override wins over environment", input: "${APP_TAG}", expected: "from-var"},
+		{name: "environment wins over built-in", input: "${GIT_SHA}", expected: "abc1234"},
+		{name: "cluster name built-in", input: "${KRAZE_CLUSTER_NAME}-db", expected: "dev-db"},
+		{name: "config dir built-in", input: "${KRAZE_CONFIG_DIR}/charts", expected: "/work/app/charts"},
+		{name: "unset without default", input: "x${KRAZE_UNSET_VARIABLE}y", expected: "xy"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if result := vars.Expand(tt.input); result != tt.expected {
+				test.Errorf("Expand(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+
+	values := "image: app:${APP_TAG}\nscript: echo ${KRAZE_UNSET_VARIABLE}\nmode: ${KRAZE_UNSET_MODE:-dev}\nhost: ${KRAZE_TEST_HOST_ONLY}\nhome: ${HOME}\n"
+	expected := "image: app:from-var\nscript: echo ${KRAZE_UNSET_VARIABLE}\nmode: dev\nhost: ${KRAZE_TEST_HOST_ONLY}\nhome: ${HOME}\n"
+	if result := string(vars.ExpandValues([]byte(values), false)); result != expected {
+		test.Errorf("ExpandValues() = %q, want %q", result, expected)
+	}
+	if result := string(vars.ExpandValues([]byte("host: ${KRAZE_TEST_HOST_ONLY}\n"), true)); result != "host: from-host\n" {
+		test.Errorf("ExpandValues() with the environment = %q, want the host value", result)
+	}
+}
+
+func TestParseVariableOverrides(test *testing.T) {
+	vars, err := ParseVariableOverrides([]string{"GIT_SHA=abc", "EMPTY=", "URL=http://x?a=b"})
+	if err != nil {
+		test.Fatalf("ParseVariableOverrides() error = %v", err)
+	}
+	if vars["GIT_SHA"] != "abc" || vars["EMPTY"] != "" || vars["URL"] != "http://x?a=b" {
+		test.Errorf("ParseVariableOverrides() = %v", vars)
+	}
+
+	for _, invalid := range []string{"NOVALUE", "lower=x", "=x"} {
+		if _, err := ParseVariableOverrides([]string{invalid}); err == nil {
+			test.Errorf("ParseVariableOverrides(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestParseExpandsBuiltins(test *testing.T) {
+	dir := test.TempDir()
+	configPath := filepath.Join(dir, "kraze.yml")
+	content := `cluster:
+  name: ${TEAM:-payments}-dev
+services:
+  db:
+    type: helm
+    repo: oci://registry.example.com/charts
+    chart: postgres
+    namespace: ${KRAZE_CLUSTER_NAME}
+    values: values.yaml
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		test.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "values.yaml"), []byte("fullnameOverride: ${KRAZE_CLUSTER_NAME}-db\n"), 0644); err != nil {
+		test.Fatal(err)
+	}
+
+	cfg, err := Parse(configPath)
+	if err != nil {
+		test.Fatalf("Parse() error = %v", err)
+	}
+	svc := cfg.Services["db"]
+	if svc.Namespace != "payments-dev" {
+		test.Errorf("namespace = %q, want payments-dev", svc.Namespace)
+	}
+
+	data, err := svc.ReadValuesFile(svc.Values.Files()[0])
+	if err != nil {
+		test.Fatal(err)
+	}
+	if string(data) != "fullnameOverride: payments-dev-db\n" {
+		test.Errorf("ReadValuesFile() = %q", data)
+	}
+}
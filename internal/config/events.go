@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// EventsConfig delivers install, uninstall and cluster lifecycle events to
+// sinks outside kraze, so platform teams can follow local environments
+type EventsConfig struct {
+	Webhook *WebhookConfig `yaml:"webhook,omitempty"` // POST each event as JSON
+	File    string         `yaml:"file,omitempty"`    // Append each event as a JSON line, relative to the config file
+	OTLP    string         `yaml:"otlp,omitempty"`    // Export each event as a span to this OTLP/HTTP endpoint (e.g., http://localhost:4318)
+}
+
+// WebhookConfig is an HTTP endpoint events are posted to
+type WebhookConfig struct {
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers,omitempty"` // e.g., Authorization: "Bearer ${EVENTS_TOKEN}"
+}
+
+// validate checks the sinks' URLs
+func (events *EventsConfig) validate() error {
+	if events == nil {
+		return nil
+	}
+	if events.Webhook != nil {
+		if err := validateSinkURL(events.Webhook.URL); err != nil {
+			return &ValidationError{Field: "events.webhook.url", Message: err.Error()}
+		}
+	}
+	if events.OTLP != "" {
+		if err := validateSinkURL(events.OTLP); err != nil {
+			return &ValidationError{Field: "events.otlp", Message: err.Error()}
+		}
+	}
+	return nil
+}
+
+// validateSinkURL checks an event sink's URL is an absolute http(s) URL
+func validateSinkURL(rawURL string) error {
+	if rawURL == "" {
+		return fmt.Errorf("url is required")
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("'%s' must be an http:// or https:// URL", rawURL)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEventsConfig(test *testing.T) {
+	dir := writeConfigFiles(test, map[string]string{
+		"kraze.yml": `cluster:
+  name: dev
+services: {}
+events:
+  webhook:
+    url: https://hooks.example.com/kraze
+    headers:
+      Authorization: Bearer token
+  file: logs/events.jsonl
+  otlp: http://localhost:4318
+`,
+	})
+
+	cfg, err := Parse(filepath.Join(dir, "kraze.yml"))
+	if err != nil {
+		test.Fatalf("Parse() error = %v", err)
+	}
+	if want := filepath.Join(dir, "logs", "events.jsonl"); cfg.Events.File != want {
+		test.Errorf("File = %q, want %q", cfg.Events.File, want)
+	}
+	if cfg.Events.Webhook.Headers["Authorization"] != "Bearer token" {
+		test.Errorf("Headers = %v", cfg.Events.Webhook.Headers)
+	}
+}
+
+func TestEventsConfigErrors(test *testing.T) {
+	tests := []struct {
+		name    string
+		events  string
+		wantErr string
+	}{
+		{name: "webhook without url", events: "  webhook:\n    headers: {X-Team: platform}\n", wantErr: "events.webhook.url"},
+		{name: "webhook not http", events: "  webhook:\n    url: ftp://example.com/hook\n", wantErr: "must be an http:// or https:// URL"},
+		{name: "otlp without scheme", events: "  otlp: localhost:4318\n", wantErr: "events.otlp"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			dir := writeConfigFiles(test, map[string]string{"kraze.yml": "cluster:\n  name: dev\nservices: {}\nevents:\n" + tt.events})
+			_, err := Parse(filepath.Join(dir, "kraze.yml"))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				test.Errorf("Parse() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
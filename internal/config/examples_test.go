@@ -252,7 +252,7 @@ func TestMultiConfigExample(test *testing.T) {
 	// Cross-file dependency: pgvector depends_on postgres (defined in app config)
 	if pgvector, ok := cfg.Services["pgvector"]; ok {
 		found := false
-		for _, dep := range pgvector.DependsOn {
+		for _, dep := range pgvector.DependsOn.Names() {
 			if dep == "postgres" {
 				found = true
 			}
@@ -313,7 +313,7 @@ func TestDependenciesExample(test *testing.T) {
 	// Should have at least one service with dependencies
 	var hasDependencies bool
 	for _, service := range cfg.Services {
-		if len(service.DependsOn) > 0 {
+		if !service.DependsOn.IsEmpty() {
 			hasDependencies = true
 			break
 		}
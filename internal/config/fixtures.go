@@ -0,0 +1,221 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultFixtureTimeout is how long one attempt of a fixture may run unless it sets a timeout
+const DefaultFixtureTimeout = 5 * time.Minute
+
+// DefaultFixtureHTTPImage is the image HTTP fixtures send their request from
+const DefaultFixtureHTTPImage = "curlimages/curl:8.10.1"
+
+// Databases SQL fixtures load into
+const (
+	FixtureEnginePostgres = "postgres"
+	FixtureEngineMySQL    = "mysql"
+)
+
+// FixtureConfig is seed data loaded into a service once it's ready. Fixtures
+// load in order, and one whose content hasn't changed since it last loaded
+// successfully is skipped.
+type FixtureConfig struct {
+	Name      string             `yaml:"name,omitempty"`      // Identifies the fixture in state and output (default: its file or URL)
+	SQL       *FixtureSQLConfig  `yaml:"sql,omitempty"`       // SQL file run with the database client in the service's pod
+	HTTP      *FixtureHTTPConfig `yaml:"http,omitempty"`      // Request sent from inside the cluster
+	Manifests []string           `yaml:"manifests,omitempty"` // Seed manifest files applied in the service's namespace
+	Timeout   string             `yaml:"timeout,omitempty"`   // Timeout of each attempt (default: 5m)
+	Retries   int                `yaml:"retries,omitempty"`   // Attempts after the first one fails
+}
+
+// FixtureSQLConfig is a SQL file fed to psql or mysql in the service's first running pod
+type FixtureSQLConfig struct {
+	File     string `yaml:"file"`               // SQL file, relative to the config file
+	Engine   string `yaml:"engine,omitempty"`   // postgres (default) or mysql
+	Database string `yaml:"database,omitempty"` // Database to connect to (default: the user's)
+	User     string `yaml:"user,omitempty"`     // Database user (default: postgres, or root for mysql)
+}
+
+// FixtureHTTPConfig is a request sent by a short-lived Job in the service's namespace
+type FixtureHTTPConfig struct {
+	URL     string            `yaml:"url"`               // In-cluster URL, e.g. http://api.app:8080/seed
+	Method  string            `yaml:"method,omitempty"`  // Request method (default: POST)
+	Body    string            `yaml:"body,omitempty"`    // File sent as the request body, relative to the config file
+	Headers map[string]string `yaml:"headers,omitempty"` // Request headers
+	Image   string            `yaml:"image,omitempty"`   // Image with sh and curl (default: curlimages/curl)
+}
+
+// Key identifies the fixture among the service's fixtures
+func (fixture *FixtureConfig) Key() string {
+	if fixture.Name != "" {
+		return fixture.Name
+	}
+	switch {
+	case fixture.SQL != nil:
+		return filepath.Base(fixture.SQL.File)
+	case fixture.HTTP != nil:
+		return fixture.HTTP.GetMethod() + " " + fixture.HTTP.URL
+	case len(fixture.Manifests) > 0:
+		return filepath.Base(fixture.Manifests[0])
+	}
+	return ""
+}
+
+// Kind returns sql, http or manifests
+func (fixture *FixtureConfig) Kind() string {
+	switch {
+	case fixture.SQL != nil:
+		return "sql"
+	case fixture.HTTP != nil:
+		return "http"
+	}
+	return "manifests"
+}
+
+// Files returns the files the fixture reads, in order
+func (fixture *FixtureConfig) Files() []string {
+	var files []string
+	if fixture.SQL != nil {
+		files = append(files, fixture.SQL.File)
+	}
+	if fixture.HTTP != nil && fixture.HTTP.Body != "" {
+		files = append(files, fixture.HTTP.Body)
+	}
+	return append(files, fixture.Manifests...)
+}
+
+// GetTimeout returns the timeout of each attempt of the fixture
+func (fixture *FixtureConfig) GetTimeout() (time.Duration, error) {
+	if fixture.Timeout == "" {
+		return DefaultFixtureTimeout, nil
+	}
+	return time.ParseDuration(fixture.Timeout)
+}
+
+// GetEngine returns the database the SQL is for
+func (sql *FixtureSQLConfig) GetEngine() string {
+	if sql.Engine == "" {
+		return FixtureEnginePostgres
+	}
+	return sql.Engine
+}
+
+// GetUser returns the database user, defaulting to the engine's superuser
+func (sql *FixtureSQLConfig) GetUser() string {
+	if sql.User != "" {
+		return sql.User
+	}
+	if sql.GetEngine() == FixtureEngineMySQL {
+		return "root"
+	}
+	return "postgres"
+}
+
+// GetMethod returns the request method
+func (request *FixtureHTTPConfig) GetMethod() string {
+	if request.Method == "" {
+		return http.MethodPost
+	}
+	return strings.ToUpper(request.Method)
+}
+
+// GetImage returns the image the request is sent from
+func (request *FixtureHTTPConfig) GetImage() string {
+	if request.Image == "" {
+		return DefaultFixtureHTTPImage
+	}
+	return request.Image
+}
+
+// resolvePaths makes the fixture's files relative to the config file's directory
+func (fixture *FixtureConfig) resolvePaths(dir string) {
+	resolve := func(path string) string {
+		if path == "" || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(dir, path)
+	}
+	if fixture.SQL != nil {
+		fixture.SQL.File = resolve(fixture.SQL.File)
+	}
+	if fixture.HTTP != nil {
+		fixture.HTTP.Body = resolve(fixture.HTTP.Body)
+	}
+	for itr := range fixture.Manifests {
+		fixture.Manifests[itr] = resolve(fixture.Manifests[itr])
+	}
+}
+
+// validateFixtures checks every fixture and that their keys are unique
+func validateFixtures(fixtures []FixtureConfig) error {
+	seen := make(map[string]bool, len(fixtures))
+	for itr := range fixtures {
+		field := fmt.Sprintf("fixtures[%d]", itr)
+		if err := fixtures[itr].validate(field); err != nil {
+			return err
+		}
+		key := fixtures[itr].Key()
+		if seen[key] {
+			return &ValidationError{Field: field + ".name", Message: fmt.Sprintf("duplicate fixture '%s', set a unique name", key)}
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// validate checks that the fixture loads exactly one thing
+func (fixture *FixtureConfig) validate(field string) error {
+	sources := 0
+	if fixture.SQL != nil {
+		sources++
+	}
+	if fixture.HTTP != nil {
+		sources++
+	}
+	if len(fixture.Manifests) > 0 {
+		sources++
+	}
+	if sources != 1 {
+		return &ValidationError{Field: field, Message: "exactly one of sql, http or manifests is required"}
+	}
+
+	if sql := fixture.SQL; sql != nil {
+		if sql.File == "" {
+			return &ValidationError{Field: field + ".sql.file", Message: "file is required"}
+		}
+		switch sql.GetEngine() {
+		case FixtureEnginePostgres, FixtureEngineMySQL:
+		default:
+			return &ValidationError{Field: field + ".sql.engine", Message: fmt.Sprintf("must be '%s' or '%s', got '%s'", FixtureEnginePostgres, FixtureEngineMySQL, sql.Engine)}
+		}
+	}
+	if request := fixture.HTTP; request != nil {
+		parsed, err := url.Parse(request.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return &ValidationError{Field: field + ".http.url", Message: fmt.Sprintf("must be an http(s) URL, got '%s'", request.URL)}
+		}
+		for name := range request.Headers {
+			if name == "" || strings.ContainsAny(name, " :\n") {
+				return &ValidationError{Field: field + ".http.headers", Message: fmt.Sprintf("invalid header name '%s'", name)}
+			}
+		}
+	}
+	for itr, path := range fixture.Manifests {
+		if IsHTTPURL(path) {
+			return &ValidationError{Field: fmt.Sprintf("%s.manifests[%d]", field, itr), Message: "must be a local file"}
+		}
+	}
+
+	if timeout, err := fixture.GetTimeout(); err != nil || timeout <= 0 {
+		return &ValidationError{Field: field + ".timeout", Message: fmt.Sprintf("invalid duration '%s'", fixture.Timeout)}
+	}
+	if fixture.Retries < 0 {
+		return &ValidationError{Field: field + ".retries", Message: "must not be negative"}
+	}
+	return nil
+}
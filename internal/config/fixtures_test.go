@@ -0,0 +1,155 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateFixtures(test *testing.T) {
+	tests := []struct {
+		name     string
+		fixtures []FixtureConfig
+		wantErr  string
+	}{
+		{
+			name: "one of each",
+			fixtures: []FixtureConfig{
+				{SQL: &FixtureSQLConfig{File: "seed/users.sql"}},
+				{SQL: &FixtureSQLConfig{File: "seed/orders.sql", Engine: "mysql", Database: "shop"}, Retries: 2},
+				{HTTP: &FixtureHTTPConfig{URL: "http://api.app:8080/seed", Body: "seed/catalog.json"}, Timeout: "1m"},
+				{Manifests: []string{"seed/job.yaml"}},
+			},
+		},
+		{
+			name:     "nothing to load",
+			fixtures: []FixtureConfig{{Name: "empty"}},
+			wantErr:  "exactly one of sql, http or manifests",
+		},
+		{
+			name:     "sql and manifests",
+			fixtures: []FixtureConfig{{SQL: &FixtureSQLConfig{File: "a.sql"}, Manifests: []string{"seed.yaml"}}},
+			wantErr:  "exactly one of sql, http or manifests",
+		},
+		{
+			name:     "sql without file",
+			fixtures: []FixtureConfig{{SQL: &FixtureSQLConfig{Database: "app"}}},
+			wantErr:  "file is required",
+		},
+		{
+			name:     "unknown engine",
+			fixtures: []FixtureConfig{{SQL: &FixtureSQLConfig{File: "a.sql", Engine: "oracle"}}},
+			wantErr:  "must be 'postgres' or 'mysql'",
+		},
+		{
+			name:     "relative url",
+			fixtures: []FixtureConfig{{HTTP: &FixtureHTTPConfig{URL: "/seed"}}},
+			wantErr:  "must be an http(s) URL",
+		},
+		{
+			name:     "invalid header",
+			fixtures: []FixtureConfig{{HTTP: &FixtureHTTPConfig{URL: "http://api/seed", Headers: map[string]string{"X Token": "1"}}}},
+			wantErr:  "invalid header name",
+		},
+		{
+			name:     "remote manifest",
+			fixtures: []FixtureConfig{{Manifests: []string{"https://example.com/seed.yaml"}}},
+			wantErr:  "must be a local file",
+		},
+		{
+			name:     "invalid timeout",
+			fixtures: []FixtureConfig{{SQL: &FixtureSQLConfig{File: "a.sql"}, Timeout: "soon"}},
+			wantErr:  "invalid duration",
+		},
+		{
+			name: "duplicate file",
+			fixtures: []FixtureConfig{
+				{SQL: &FixtureSQLConfig{File: "seed/users.sql"}},
+				{SQL: &FixtureSQLConfig{File: "other/users.sql"}},
+			},
+			wantErr: "duplicate fixture 'users.sql'",
+		},
+		{
+			name: "duplicate file with names",
+			fixtures: []FixtureConfig{
+				{SQL: &FixtureSQLConfig{File: "seed/users.sql"}},
+				{Name: "more users", SQL: &FixtureSQLConfig{File: "other/users.sql"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			err := validateFixtures(tt.fixtures)
+			if tt.wantErr == "" {
+				if err != nil {
+					test.Errorf("validateFixtures() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				test.Errorf("validateFixtures() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFixtureKey(test *testing.T) {
+	tests := []struct {
+		fixture  FixtureConfig
+		expected string
+	}{
+		{fixture: FixtureConfig{Name: "users", SQL: &FixtureSQLConfig{File: "seed/users.sql"}}, expected: "users"},
+		{fixture: FixtureConfig{SQL: &FixtureSQLConfig{File: "seed/users.sql"}}, expected: "users.sql"},
+		{fixture: FixtureConfig{HTTP: &FixtureHTTPConfig{URL: "http://api/seed", Method: "put"}}, expected: "PUT http://api/seed"},
+		{fixture: FixtureConfig{Manifests: []string{"seed/job.yaml", "seed/data.yaml"}}, expected: "job.yaml"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.expected, func(test *testing.T) {
+			if got := tt.fixture.Key(); got != tt.expected {
+				test.Errorf("Key() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseFixtures(test *testing.T) {
+	dir := writeConfigFiles(test, map[string]string{
+		"kraze.yml": `
+cluster:
+  name: dev
+services:
+  postgres:
+    type: manifests
+    path: ./k8s
+    fixtures:
+      - sql:
+          file: seed/users.sql
+          database: app
+      - name: catalog
+        http:
+          url: http://api.app:8080/seed
+          body: seed/catalog.json
+      - manifests: [seed/job.yaml]
+`,
+	})
+
+	cfg, err := Parse(filepath.Join(dir, "kraze.yml"))
+	if err != nil {
+		test.Fatalf("Parse() error = %v", err)
+	}
+	fixtures := cfg.Services["postgres"].Fixtures
+	if len(fixtures) != 3 {
+		test.Fatalf("fixtures = %+v, want 3", fixtures)
+	}
+	if expected := filepath.Join(dir, "seed", "users.sql"); fixtures[0].SQL.File != expected {
+		test.Errorf("sql file = %q, want %q", fixtures[0].SQL.File, expected)
+	}
+	if expected := filepath.Join(dir, "seed", "catalog.json"); fixtures[1].HTTP.Body != expected {
+		test.Errorf("http body = %q, want %q", fixtures[1].HTTP.Body, expected)
+	}
+	if expected := filepath.Join(dir, "seed", "job.yaml"); fixtures[2].Manifests[0] != expected {
+		test.Errorf("manifest = %q, want %q", fixtures[2].Manifests[0], expected)
+	}
+}
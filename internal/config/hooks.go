@@ -0,0 +1,147 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultHookTimeout is how long one attempt of a hook may run unless it sets a timeout
+const DefaultHookTimeout = 5 * time.Minute
+
+// Hook phases, in the order they run around an install and an uninstall
+const (
+	HookPreInstall    = "pre_install"
+	HookPostInstall   = "post_install"
+	HookPreUninstall  = "pre_uninstall"
+	HookPostUninstall = "post_uninstall"
+)
+
+// Hook failure policies
+const (
+	HookFailurePolicyFail   = "fail"
+	HookFailurePolicyIgnore = "ignore"
+)
+
+// HooksConfig runs local commands or in-cluster Jobs around a service's install
+// and uninstall, such as database migrations after install or seeding data
+type HooksConfig struct {
+	PreInstall    []HookConfig `yaml:"pre_install,omitempty"`    // Before the service's resources are applied
+	PostInstall   []HookConfig `yaml:"post_install,omitempty"`   // After the service is installed (and ready, when waiting)
+	PreUninstall  []HookConfig `yaml:"pre_uninstall,omitempty"`  // Before the service's resources are removed
+	PostUninstall []HookConfig `yaml:"post_uninstall,omitempty"` // After the service's resources are removed
+}
+
+// HookConfig is a local shell command or an in-cluster Job
+type HookConfig struct {
+	Name      string         `yaml:"name,omitempty"`       // Shown in progress output (default: the command or the Job's image)
+	Command   string         `yaml:"command,omitempty"`    // Local shell command, run from the config file's directory
+	Job       *HookJobConfig `yaml:"job,omitempty"`        // Job run in the service's namespace
+	Timeout   string         `yaml:"timeout,omitempty"`    // Timeout of each attempt (default: 5m)
+	Retries   int            `yaml:"retries,omitempty"`    // Attempts after the first one fails
+	OnFailure string         `yaml:"on_failure,omitempty"` // fail (default) stops the service, ignore only warns
+	Dir       string         `yaml:"-"`                    // Directory the command runs in (set to the config file's directory)
+}
+
+// HookJobConfig is the container a Job hook runs
+type HookJobConfig struct {
+	Image          string            `yaml:"image"`
+	Command        []string          `yaml:"command,omitempty"`
+	Args           []string          `yaml:"args,omitempty"`
+	Env            map[string]string `yaml:"env,omitempty"`
+	ServiceAccount string            `yaml:"service_account,omitempty"` // ServiceAccount the Job runs as (default: the namespace's default)
+}
+
+// Get returns the hooks of a phase
+func (hooks *HooksConfig) Get(phase string) []HookConfig {
+	if hooks == nil {
+		return nil
+	}
+	switch phase {
+	case HookPreInstall:
+		return hooks.PreInstall
+	case HookPostInstall:
+		return hooks.PostInstall
+	case HookPreUninstall:
+		return hooks.PreUninstall
+	case HookPostUninstall:
+		return hooks.PostUninstall
+	}
+	return nil
+}
+
+// HookPhases are the hook phases in the order they run
+var HookPhases = []string{HookPreInstall, HookPostInstall, HookPreUninstall, HookPostUninstall}
+
+// setDir sets the directory local commands run in
+func (hooks *HooksConfig) setDir(dir string) {
+	for _, phase := range HookPhases {
+		phaseHooks := hooks.Get(phase)
+		for itr := range phaseHooks {
+			phaseHooks[itr].Dir = dir
+		}
+	}
+}
+
+// validate checks every hook of every phase
+func (hooks *HooksConfig) validate() error {
+	for _, phase := range HookPhases {
+		phaseHooks := hooks.Get(phase)
+		for itr := range phaseHooks {
+			if err := phaseHooks[itr].validate(fmt.Sprintf("hooks.%s[%d]", phase, itr)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// GetTimeout returns the timeout of each attempt of the hook
+func (hook *HookConfig) GetTimeout() (time.Duration, error) {
+	if hook.Timeout == "" {
+		return DefaultHookTimeout, nil
+	}
+	return time.ParseDuration(hook.Timeout)
+}
+
+// IgnoresFailure returns true if a failing hook only produces a warning
+func (hook *HookConfig) IgnoresFailure() bool {
+	return hook.OnFailure == HookFailurePolicyIgnore
+}
+
+// Description names the hook in progress output and errors
+func (hook *HookConfig) Description() string {
+	if hook.Name != "" {
+		return hook.Name
+	}
+	if hook.Job != nil {
+		return "job " + hook.Job.Image
+	}
+	command := strings.TrimSpace(hook.Command)
+	if line, _, found := strings.Cut(command, "\n"); found {
+		command = line + " ..."
+	}
+	return command
+}
+
+// validate checks that the hook runs exactly one thing with a valid policy
+func (hook *HookConfig) validate(field string) error {
+	if (strings.TrimSpace(hook.Command) == "") == (hook.Job == nil) {
+		return &ValidationError{Field: field, Message: "exactly one of command or job is required"}
+	}
+	if hook.Job != nil && hook.Job.Image == "" {
+		return &ValidationError{Field: field + ".job.image", Message: "image is required"}
+	}
+	if timeout, err := hook.GetTimeout(); err != nil || timeout <= 0 {
+		return &ValidationError{Field: field + ".timeout", Message: fmt.Sprintf("invalid duration '%s'", hook.Timeout)}
+	}
+	if hook.Retries < 0 {
+		return &ValidationError{Field: field + ".retries", Message: "must not be negative"}
+	}
+	switch hook.OnFailure {
+	case "", HookFailurePolicyFail, HookFailurePolicyIgnore:
+	default:
+		return &ValidationError{Field: field + ".on_failure", Message: fmt.Sprintf("must be '%s' or '%s', got '%s'", HookFailurePolicyFail, HookFailurePolicyIgnore, hook.OnFailure)}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateHook(test *testing.T) {
+	tests := []struct {
+		name    string
+		hook    HookConfig
+		wantErr string
+	}{
+		{
+			name: "local command",
+			hook: HookConfig{Command: "make migrate", Timeout: "2m", Retries: 2},
+		},
+		{
+			name: "job",
+			hook: HookConfig{Job: &HookJobConfig{Image: "migrate/migrate:v4", Args: []string{"up"}}, OnFailure: "ignore"},
+		},
+		{
+			name:    "command and job",
+			hook:    HookConfig{Command: "make migrate", Job: &HookJobConfig{Image: "migrate/migrate:v4"}},
+			wantErr: "exactly one of command or job",
+		},
+		{
+			name:    "nothing to run",
+			hook:    HookConfig{Name: "empty"},
+			wantErr: "exactly one of command or job",
+		},
+		{
+			name:    "job without image",
+			hook:    HookConfig{Job: &HookJobConfig{Command: []string{"seed"}}},
+			wantErr: "image is required",
+		},
+		{
+			name:    "invalid timeout",
+			hook:    HookConfig{Command: "true", Timeout: "soon"},
+			wantErr: "invalid duration",
+		},
+		{
+			name:    "negative retries",
+			hook:    HookConfig{Command: "true", Retries: -1},
+			wantErr: "must not be negative",
+		},
+		{
+			name:    "unknown failure policy",
+			hook:    HookConfig{Command: "true", OnFailure: "retry"},
+			wantErr: "must be 'fail' or 'ignore'",
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			err := tt.hook.validate("hooks.pre_install[0]")
+			if tt.wantErr == "" {
+				if err != nil {
+					test.Errorf("validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				test.Errorf("validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHookDescription(test *testing.T) {
+	tests := []struct {
+		hook     HookConfig
+		expected string
+	}{
+		{hook: HookConfig{Name: "migrate", Command: "make migrate"}, expected: "migrate"},
+		{hook: HookConfig{Command: "  make migrate  "}, expected: "make migrate"},
+		{hook: HookConfig{Command: "set -e\nmake seed\n"}, expected: "set -e ..."},
+		{hook: HookConfig{Job: &HookJobConfig{Image: "migrate/migrate:v4"}}, expected: "job migrate/migrate:v4"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.expected, func(test *testing.T) {
+			if got := tt.hook.Description(); got != tt.expected {
+				test.Errorf("Description() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseHooks(test *testing.T) {
+	dir := writeConfigFiles(test, map[string]string{
+		"kraze.yml": `
+cluster:
+  name: dev
+services:
+  api:
+    type: manifests
+    path: ./k8s
+    hooks:
+      pre_install:
+        - command: ./scripts/check.sh
+      post_install:
+        - name: migrate
+          job:
+            image: migrate/migrate:v4
+            args: [up]
+          retries: 2
+`,
+	})
+
+	cfg, err := Parse(filepath.Join(dir, "kraze.yml"))
+	if err != nil {
+		test.Fatalf("Parse() error = %v", err)
+	}
+	hooks := cfg.Services["api"].Hooks
+	if pre := hooks.Get(HookPreInstall); len(pre) != 1 || pre[0].Dir != dir {
+		test.Errorf("pre_install = %+v, want one hook running from %s", pre, dir)
+	}
+	if post := hooks.Get(HookPostInstall); len(post) != 1 || post[0].Job.Image != "migrate/migrate:v4" || post[0].Retries != 2 {
+		test.Errorf("post_install = %+v, want the migrate job", post)
+	}
+	if uninstall := hooks.Get(HookPreUninstall); len(uninstall) != 0 {
+		test.Errorf("pre_uninstall = %+v, want none", uninstall)
+	}
+}
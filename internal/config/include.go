@@ -0,0 +1,402 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Remote includes are fetched again once their cached copy is older than this
+const includeCacheTTL = time.Hour
+
+// includeClient fetches remote includes; the timeout keeps a stalled server
+// from hanging config parsing
+var includeClient = &http.Client{Timeout: 30 * time.Second}
+
+// gitSourcePrefix marks an include fetched from a git repository, as in
+// git::https://github.com/acme/catalog.git//services.yml?ref=v1
+const gitSourcePrefix = "git::"
+
+// includeCacheDir returns the directory remote includes are cached in
+var includeCacheDir = func() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".kraze", "includes"), nil
+}
+
+// ExtendsConfig names the service a service inherits its definition from
+// Supports both: extends: postgres and extends: {service: postgres, file: catalog.yml}
+type ExtendsConfig struct {
+	Service string `yaml:"service"`
+	File    string `yaml:"file,omitempty"` // Config file defining the service (local path, http(s) or git:: URL); defaults to this config
+}
+
+// UnmarshalYAML implements custom unmarshaling for a service name or a service and file
+func (extends *ExtendsConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var service string
+	if err := unmarshal(&service); err == nil {
+		extends.Service = service
+		return nil
+	}
+
+	type plain ExtendsConfig
+	if err := unmarshal((*plain)(extends)); err != nil {
+		return fmt.Errorf("extends must be a service name or a map with service and file")
+	}
+	return nil
+}
+
+// loadConfigFile parses a config file with its relative paths resolved against
+// its directory and its includes and extends applied. origin is the URL the
+// file was downloaded from, if any, and chain the files being loaded, to catch
+// include cycles (just the file itself when empty).
+func loadConfigFile(configPath, origin string, chain []string) (*Config, error) {
+	if len(chain) == 0 {
+		absPath, err := filepath.Abs(configPath)
+		if err != nil {
+			absPath = configPath
+		}
+		chain = []string{absPath}
+	}
+
+	data, vars, err := readAndExpand(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := unmarshalConfig(data, &cfg); err != nil {
+		return nil, err
+	}
+	cfg.origins = readOrigins(configPath, displayPath(chain[len(chain)-1]))
+
+	// Set service names from map keys
+	for name, svc := range cfg.Services {
+		svc.Name = name
+		svc.Vars = vars
+		cfg.Services[name] = svc
+	}
+
+	// Paths are resolved per file, before services from other files are mixed in
+	if err := cfg.ResolvePaths(configPath); err != nil {
+		return nil, fmt.Errorf("failed to resolve paths: %w", err)
+	}
+
+	if err := cfg.applyIncludes(configPath, origin, chain); err != nil {
+		return nil, err
+	}
+	if err := cfg.applyExtends(configPath, origin, chain); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// applyIncludes adds the services of the included files. A service the config
+// defines itself overrides an included one of the same name, like extends.
+func (cfg *Config) applyIncludes(configPath, origin string, chain []string) error {
+	included := make(map[string]ServiceConfig)
+	includedFrom := make(map[string]string)
+
+	for _, source := range cfg.Include {
+		other, err := loadConfigSource(source, configPath, origin, chain)
+		if err != nil {
+			return fmt.Errorf("failed to include '%s': %w", source, err)
+		}
+		for name, svc := range other.Services {
+			if previous, exists := includedFrom[name]; exists {
+				return fmt.Errorf("service '%s' is included from both '%s' and '%s'", name, previous, source)
+			}
+			included[name] = svc
+			includedFrom[name] = source
+			cfg.copyOrigins(other, originPath("services", name), originPath("services", name))
+		}
+	}
+
+	if len(included) > 0 && cfg.Services == nil {
+		cfg.Services = make(map[string]ServiceConfig)
+	}
+	for name, base := range included {
+		svc, exists := cfg.Services[name]
+		if !exists {
+			cfg.Services[name] = base
+			continue
+		}
+		if svc.Extends != nil {
+			return fmt.Errorf("service '%s': %w", name, &ValidationError{
+				Field:   "extends",
+				Message: fmt.Sprintf("the service overrides the one included from '%s', so it can't also extend another", includedFrom[name]),
+			})
+		}
+		cfg.Services[name] = extendService(base, svc)
+	}
+	return nil
+}
+
+// applyExtends replaces each service that extends another with the base
+// service overridden by its own fields
+func (cfg *Config) applyExtends(configPath, origin string, chain []string) error {
+	var resolve func(name string, extending []string) error
+	resolve = func(name string, extending []string) error {
+		svc := cfg.Services[name]
+		if svc.Extends == nil {
+			return nil
+		}
+		if slices.Contains(extending, name) {
+			return fmt.Errorf("service '%s': %w", name, &ValidationError{
+				Field:   "extends",
+				Message: fmt.Sprintf("cycle: %s", strings.Join(append(extending, name), " -> ")),
+			})
+		}
+		if svc.Extends.Service == "" {
+			return fmt.Errorf("service '%s': %w", name, &ValidationError{Field: "extends.service", Message: "the service to extend is required"})
+		}
+
+		var base ServiceConfig
+		var found bool
+		baseConfig := cfg
+		if svc.Extends.File != "" {
+			other, err := loadConfigSource(svc.Extends.File, configPath, origin, chain)
+			if err != nil {
+				return fmt.Errorf("service '%s': failed to load '%s': %w", name, svc.Extends.File, err)
+			}
+			base, found = other.Services[svc.Extends.Service]
+			baseConfig = other
+		} else {
+			if err := resolve(svc.Extends.Service, append(extending, name)); err != nil {
+				return err
+			}
+			base, found = cfg.Services[svc.Extends.Service]
+		}
+		if !found {
+			return fmt.Errorf("service '%s': %w", name, &ValidationError{
+				Field:   "extends",
+				Message: fmt.Sprintf("service '%s' not found", svc.Extends.Service),
+			})
+		}
+
+		// A disabled base is a template, so extending it doesn't disable the service
+		extended := extendService(base, svc)
+		extended.Enabled = svc.Enabled
+		cfg.Services[name] = extended
+		cfg.copyOrigins(baseConfig, originPath("services", svc.Extends.Service), originPath("services", name))
+		if svc.Enabled == nil {
+			delete(cfg.origins, originPath(originPath("services", name), "enabled"))
+		}
+		return nil
+	}
+
+	for _, name := range cfg.sortedServiceNames() {
+		if err := resolve(name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extendService returns the base service with the fields the service sets
+// replacing the base's. Labels are merged, and the service's values files are
+// layered over the base's so they override its values.
+func extendService(base, svc ServiceConfig) ServiceConfig {
+	extended := base
+	extendedValue := reflect.ValueOf(&extended).Elem()
+	svcValue := reflect.ValueOf(svc)
+	for itr := 0; itr < svcValue.NumField(); itr++ {
+		if field := svcValue.Field(itr); !field.IsZero() {
+			extendedValue.Field(itr).Set(field)
+		}
+	}
+
+	extended.Extends = nil
+	extended.Labels = overlayStringMap(copyStringMap(base.Labels), svc.Labels)
+	extended.Values = ValuesField{files: slices.Concat(base.Values.Files(), svc.Values.Files())}
+	extended.Paths = slices.Clone(extended.Paths)
+	return extended
+}
+
+// loadConfigSource loads an included or extended config file, relative to the
+// config (or the URL it came from) that references it
+func loadConfigSource(source, configPath, origin string, chain []string) (*Config, error) {
+	if origin != "" && !IsHTTPURL(source) && !strings.HasPrefix(source, gitSourcePrefix) && !filepath.IsAbs(source) {
+		base, err := url.Parse(origin)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL '%s': %w", origin, err)
+		}
+		ref, err := url.Parse(filepath.ToSlash(source))
+		if err != nil {
+			return nil, fmt.Errorf("invalid path '%s': %w", source, err)
+		}
+		source = base.ResolveReference(ref).String()
+	}
+
+	var path, key, fileOrigin string
+	var err error
+	switch {
+	case strings.HasPrefix(source, gitSourcePrefix):
+		path, err = fetchGitSource(source)
+		key = source
+	case IsHTTPURL(source):
+		path, err = fetchHTTPSource(source)
+		key, fileOrigin = source, source
+	default:
+		path = source
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(configPath), path)
+		}
+		if absPath, absErr := filepath.Abs(path); absErr == nil {
+			path = absPath
+		}
+		key = path
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if slices.Contains(chain, key) {
+		return nil, fmt.Errorf("include cycle: %s", strings.Join(append(slices.Clone(chain), key), " -> "))
+	}
+	return loadConfigFile(path, fileOrigin, append(slices.Clone(chain), key))
+}
+
+// fetchHTTPSource downloads a config file into the include cache, reusing a
+// recent copy. A stale copy is used when the download fails.
+func fetchHTTPSource(source string) (string, error) {
+	cacheDir, err := includeCacheDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(cacheDir, includeCacheKey(source)+".yml")
+	if isFreshCache(path) {
+		return path, nil
+	}
+
+	data, err := downloadFile(source)
+	if err != nil {
+		if _, statErr := os.Stat(path); statErr == nil {
+			return path, nil
+		}
+		return "", err
+	}
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create include cache: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to cache '%s': %w", source, err)
+	}
+	return path, nil
+}
+
+// fetchGitSource clones the repository of a git:: source into the include
+// cache, reusing a recent clone, and returns the path of the file in it
+// (kraze.yml when the source doesn't name one). A stale clone is used when
+// cloning fails.
+func fetchGitSource(source string) (string, error) {
+	repo, file, ref, err := parseGitSource(source)
+	if err != nil {
+		return "", err
+	}
+	cacheDir, err := includeCacheDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(cacheDir, includeCacheKey(repo+"?ref="+ref))
+	path := filepath.Join(dir, filepath.FromSlash(file))
+	if isFreshCache(dir) {
+		return path, nil
+	}
+
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create include cache: %w", err)
+	}
+	cloneDir, err := os.MkdirTemp(cacheDir, "clone-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create include cache: %w", err)
+	}
+	defer os.RemoveAll(cloneDir)
+
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	cmd := exec.Command("git", append(args, repo, cloneDir)...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if _, statErr := os.Stat(dir); statErr == nil {
+			return path, nil
+		}
+		return "", fmt.Errorf("failed to clone %s: %w: %s", repo, err, strings.TrimSpace(string(output)))
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return "", fmt.Errorf("failed to replace cached clone of %s: %w", repo, err)
+	}
+	if err := os.Rename(cloneDir, dir); err != nil {
+		return "", fmt.Errorf("failed to cache clone of %s: %w", repo, err)
+	}
+	return path, nil
+}
+
+// parseGitSource splits git::<repo>[//<file>][?ref=<branch or tag>] into its parts
+func parseGitSource(source string) (string, string, string, error) {
+	repo := strings.TrimPrefix(source, gitSourcePrefix)
+
+	ref := ""
+	if base, query, found := strings.Cut(repo, "?"); found {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return "", "", "", fmt.Errorf("invalid git source '%s': %w", source, err)
+		}
+		repo, ref = base, values.Get("ref")
+	}
+
+	// The file follows a double slash after the scheme's
+	file := "kraze.yml"
+	start := 0
+	if idx := strings.Index(repo, "://"); idx >= 0 {
+		start = idx + len("://")
+	}
+	if idx := strings.Index(repo[start:], "//"); idx >= 0 {
+		repo, file = repo[:start+idx], repo[start+idx+len("//"):]
+	}
+
+	if repo == "" || file == "" {
+		return "", "", "", fmt.Errorf("invalid git source '%s': expected git::<repository>//<file>?ref=<ref>", source)
+	}
+	return repo, file, ref, nil
+}
+
+// downloadFile fetches a remote config file
+func downloadFile(source string) ([]byte, error) {
+	resp, err := includeClient.Get(source) //nolint:noctx
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch URL: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d fetching %s", resp.StatusCode, source)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// includeCacheKey returns the name a remote source is cached under
+func includeCacheKey(source string) string {
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:8])
+}
+
+// isFreshCache reports whether a cached file or clone was fetched within includeCacheTTL
+func isFreshCache(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && time.Since(info.ModTime()) < includeCacheTTL
+}
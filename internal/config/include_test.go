@@ -0,0 +1,293 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const catalogConfig = `
+services:
+  postgres:
+    type: helm
+    repo: oci://registry-1.docker.io/bitnamicharts
+    chart: postgresql
+    version: 16.0.0
+    namespace: data
+    values: values/postgres.yaml
+    labels:
+      tier: data
+      team: platform
+
+  redis:
+    type: manifests
+    path: ./manifests/redis
+    namespace: data
+
+  kafka-template:
+    type: helm
+    repo: oci://registry-1.docker.io/bitnamicharts
+    chart: kafka
+    enabled: false
+`
+
+// writeConfigFiles writes files (name to content) into a temporary directory
+// and returns it
+func writeConfigFiles(test *testing.T, files map[string]string) string {
+	test.Helper()
+	dir := test.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			test.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			test.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestParseInclude(test *testing.T) {
+	dir := writeConfigFiles(test, map[string]string{
+		"catalog/services.yml": catalogConfig,
+		"kraze.yml": `
+include:
+  - catalog/services.yml
+cluster:
+  name: dev
+services:
+  postgres:
+    version: 16.1.0
+    values: values/local.yaml
+    labels:
+      team: app
+  api:
+    type: manifests
+    path: ./k8s
+    depends_on: [postgres, redis]
+`,
+	})
+
+	cfg, err := Parse(filepath.Join(dir, "kraze.yml"))
+	if err != nil {
+		test.Fatalf("Parse() error = %v", err)
+	}
+
+	if len(cfg.Services) != 4 {
+		test.Errorf("Parse() returned %d services, want 4", len(cfg.Services))
+	}
+	postgres := cfg.Services["postgres"]
+	if postgres.Chart != "postgresql" || postgres.Version != "16.1.0" {
+		test.Errorf("postgres chart = %s %s, want postgresql 16.1.0", postgres.Chart, postgres.Version)
+	}
+	wantValues := []string{filepath.Join(dir, "catalog", "values", "postgres.yaml"), filepath.Join(dir, "values", "local.yaml")}
+	if !reflect.DeepEqual(postgres.Values.Files(), wantValues) {
+		test.Errorf("postgres values = %v, want %v", postgres.Values.Files(), wantValues)
+	}
+	if wantLabels := map[string]string{"tier": "data", "team": "app"}; !reflect.DeepEqual(postgres.Labels, wantLabels) {
+		test.Errorf("postgres labels = %v, want %v", postgres.Labels, wantLabels)
+	}
+	if redis := cfg.Services["redis"]; redis.Path != filepath.Join(dir, "catalog", "manifests", "redis") {
+		test.Errorf("redis path = %s, want it relative to the catalog", redis.Path)
+	}
+	if api := cfg.Services["api"]; api.Path != filepath.Join(dir, "k8s") {
+		test.Errorf("api path = %s, want it relative to kraze.yml", api.Path)
+	}
+}
+
+func TestParseExtends(test *testing.T) {
+	dir := writeConfigFiles(test, map[string]string{
+		"catalog.yml": catalogConfig,
+		"kraze.yml": `
+cluster:
+  name: dev
+services:
+  orders-db:
+    extends:
+      service: postgres
+      file: catalog.yml
+    namespace: orders
+  events:
+    extends:
+      service: kafka-template
+      file: catalog.yml
+  events-replay:
+    extends: events
+    labels:
+      purpose: replay
+`,
+	})
+
+	cfg, err := Parse(filepath.Join(dir, "kraze.yml"))
+	if err != nil {
+		test.Fatalf("Parse() error = %v", err)
+	}
+
+	if _, exists := cfg.Services["postgres"]; exists {
+		test.Error("extending a service from a file added the file's services")
+	}
+	ordersDB := cfg.Services["orders-db"]
+	if ordersDB.Name != "orders-db" || ordersDB.Namespace != "orders" || ordersDB.Chart != "postgresql" || ordersDB.Extends != nil {
+		test.Errorf("orders-db = %+v, want postgres in namespace orders", ordersDB)
+	}
+	if events := cfg.Services["events"]; !events.IsEnabled() {
+		test.Error("extending a disabled template disabled the service")
+	}
+	replay := cfg.Services["events-replay"]
+	if replay.Chart != "kafka" || replay.Labels["purpose"] != "replay" {
+		test.Errorf("events-replay = %+v, want kafka with purpose label", replay)
+	}
+}
+
+func TestParseIncludeErrors(test *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected string
+	}{
+		{
+			name: "include cycle",
+			files: map[string]string{
+				"kraze.yml": "include: [other.yml]\ncluster:\n  name: dev\n",
+				"other.yml": "include: [kraze.yml]\n",
+			},
+			expected: "include cycle",
+		},
+		{
+			name: "service in two includes",
+			files: map[string]string{
+				"kraze.yml": "include: [a.yml, b.yml]\ncluster:\n  name: dev\n",
+				"a.yml":     catalogConfig,
+				"b.yml":     catalogConfig,
+			},
+			expected: "is included from both",
+		},
+		{
+			name: "extends cycle",
+			files: map[string]string{
+				"kraze.yml": "cluster:\n  name: dev\nservices:\n  a:\n    extends: b\n  b:\n    extends: a\n",
+			},
+			expected: "cycle: a -> b -> a",
+		},
+		{
+			name: "unknown base",
+			files: map[string]string{
+				"kraze.yml": "cluster:\n  name: dev\nservices:\n  a:\n    extends: missing\n",
+			},
+			expected: "service 'missing' not found",
+		},
+		{
+			name: "missing include",
+			files: map[string]string{
+				"kraze.yml": "include: [missing.yml]\ncluster:\n  name: dev\n",
+			},
+			expected: "failed to include 'missing.yml'",
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			dir := writeConfigFiles(test, tt.files)
+			_, err := Parse(filepath.Join(dir, "kraze.yml"))
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				test.Errorf("Parse() error = %v, want %q", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseIncludeHTTP(test *testing.T) {
+	cacheDir := test.TempDir()
+	previous := includeCacheDir
+	includeCacheDir = func() (string, error) { return cacheDir, nil }
+	defer func() { includeCacheDir = previous }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/catalog/kraze.yml":
+			writer.Write([]byte("include: [redis.yml]\n"))
+		case "/catalog/redis.yml":
+			writer.Write([]byte("services:\n  redis:\n    type: helm\n    repo: https://charts.example.com\n    chart: redis\n"))
+		default:
+			http.NotFound(writer, request)
+		}
+	}))
+	defer server.Close()
+
+	dir := writeConfigFiles(test, map[string]string{
+		"kraze.yml": "include: [" + server.URL + "/catalog/kraze.yml]\ncluster:\n  name: dev\n",
+	})
+	cfg, err := Parse(filepath.Join(dir, "kraze.yml"))
+	if err != nil {
+		test.Fatalf("Parse() error = %v", err)
+	}
+	if cfg.Services["redis"].Chart != "redis" {
+		test.Errorf("Parse() services = %v, want redis from the nested include", cfg.GetAllServiceNames())
+	}
+}
+
+func TestParseIncludeGit(test *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		test.Skip("git not installed")
+	}
+	cacheDir := test.TempDir()
+	previous := includeCacheDir
+	includeCacheDir = func() (string, error) { return cacheDir, nil }
+	defer func() { includeCacheDir = previous }()
+
+	repo := writeConfigFiles(test, map[string]string{"services/catalog.yml": catalogConfig})
+	for _, args := range [][]string{
+		{"init", "--quiet", "--initial-branch", "main"},
+		{"add", "."},
+		{"-c", "user.name=kraze", "-c", "user.email=kraze@example.com", "commit", "--quiet", "-m", "catalog"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if output, err := cmd.CombinedOutput(); err != nil {
+			test.Fatalf("git %v: %v: %s", args, err, output)
+		}
+	}
+
+	dir := writeConfigFiles(test, map[string]string{
+		"kraze.yml": "include: [\"git::file://" + filepath.ToSlash(repo) + "//services/catalog.yml?ref=main\"]\ncluster:\n  name: dev\n",
+	})
+	cfg, err := Parse(filepath.Join(dir, "kraze.yml"))
+	if err != nil {
+		test.Fatalf("Parse() error = %v", err)
+	}
+	redis := cfg.Services["redis"]
+	if !strings.HasPrefix(redis.Path, cacheDir) {
+		test.Errorf("redis path = %s, want it inside the cached clone", redis.Path)
+	}
+}
+
+func TestParseGitSource(test *testing.T) {
+	tests := []struct {
+		source string
+		repo   string
+		file   string
+		ref    string
+	}{
+		{source: "git::https://github.com/acme/catalog.git//services.yml?ref=v1", repo: "https://github.com/acme/catalog.git", file: "services.yml", ref: "v1"},
+		{source: "git::https://github.com/acme/catalog.git", repo: "https://github.com/acme/catalog.git", file: "kraze.yml"},
+		{source: "git::git@github.com:acme/catalog.git//data/kraze.yml", repo: "git@github.com:acme/catalog.git", file: "data/kraze.yml"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.source, func(test *testing.T) {
+			repo, file, ref, err := parseGitSource(tt.source)
+			if err != nil {
+				test.Fatalf("parseGitSource() error = %v", err)
+			}
+			if repo != tt.repo || file != tt.file || ref != tt.ref {
+				test.Errorf("parseGitSource() = %q, %q, %q, want %q, %q, %q", repo, file, ref, tt.repo, tt.file, tt.ref)
+			}
+		})
+	}
+}
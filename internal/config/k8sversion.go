@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// kubernetesVersionPattern extracts major.minor[.patch] from versions such as
+// v1.31.2, 1.30 or v1.29.4-gke.1043000
+var kubernetesVersionPattern = regexp.MustCompile(`^v?(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// ParseKubernetesVersion parses a Kubernetes release version, ignoring any
+// pre-release or vendor suffix (managed clusters report e.g. v1.30.2-eks-1552ad0)
+func ParseKubernetesVersion(version string) (*semver.Version, error) {
+	match := kubernetesVersionPattern.FindStringSubmatch(strings.TrimSpace(version))
+	if match == nil {
+		return nil, fmt.Errorf("invalid Kubernetes version '%s'", version)
+	}
+	patch := match[3]
+	if patch == "" {
+		patch = "0"
+	}
+	return semver.NewVersion(fmt.Sprintf("%s.%s.%s", match[1], match[2], patch))
+}
+
+// KubernetesConstraint parses the cluster's tolerated Kubernetes version range,
+// returning nil when no range is configured
+func (cluster *ClusterConfig) KubernetesConstraint() (*semver.Constraints, error) {
+	if strings.TrimSpace(cluster.Kubernetes) == "" {
+		return nil, nil
+	}
+	constraint, err := semver.NewConstraint(cluster.Kubernetes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Kubernetes version range '%s': %w", cluster.Kubernetes, err)
+	}
+	return constraint, nil
+}
+
+// CheckKubernetesVersion returns an error when version is outside the cluster's
+// tolerated Kubernetes version range. source describes where the version came
+// from (e.g. "node image kindest/node:v1.33.1") for the error message.
+func (cluster *ClusterConfig) CheckKubernetesVersion(version, source string) error {
+	constraint, err := cluster.KubernetesConstraint()
+	if err != nil || constraint == nil {
+		return err
+	}
+
+	parsed, err := ParseKubernetesVersion(version)
+	if err != nil {
+		return fmt.Errorf("cannot check %s against cluster.kubernetes '%s': %w", source, cluster.Kubernetes, err)
+	}
+	if !constraint.Check(parsed) {
+		return fmt.Errorf("%s runs Kubernetes %s, which is outside the range '%s' required by cluster.kubernetes", source, parsed, cluster.Kubernetes)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package config
+
+import "testing"
+
+func TestParseKubernetesVersion(test *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		want    string
+		wantErr bool
+	}{
+		{name: "git version", version: "v1.31.2", want: "1.31.2"},
+		{name: "without v prefix", version: "1.30.0", want: "1.30.0"},
+		{name: "major minor only", version: "1.29", want: "1.29.0"},
+		{name: "vendor suffix", version: "v1.30.2-eks-1552ad0", want: "1.30.2"},
+		{name: "gke suffix", version: "v1.29.4-gke.1043000", want: "1.29.4"},
+		{name: "not a version", version: "latest", wantErr: true},
+		{name: "empty", version: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			got, err := ParseKubernetesVersion(tt.version)
+			if (err != nil) != tt.wantErr {
+				test.Fatalf("ParseKubernetesVersion(%q) error = %v, wantErr %v", tt.version, err, tt.wantErr)
+			}
+			if !tt.wantErr && got.String() != tt.want {
+				test.Errorf("ParseKubernetesVersion(%q) = %s, want %s", tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckKubernetesVersion(test *testing.T) {
+	tests := []struct {
+		name       string
+		kubernetes string
+		version    string
+		wantErr    bool
+	}{
+		{name: "no range", kubernetes: "", version: "v1.20.0"},
+		{name: "inside range", kubernetes: ">=1.28 <1.32", version: "v1.31.9"},
+		{name: "lower bound", kubernetes: ">=1.28 <1.32", version: "v1.28.0"},
+		{name: "below range", kubernetes: ">=1.28 <1.32", version: "v1.27.16", wantErr: true},
+		{name: "upper bound excluded", kubernetes: ">=1.28 <1.32", version: "v1.32.0", wantErr: true},
+		{name: "managed cluster suffix", kubernetes: ">=1.28, <1.32", version: "v1.30.2-eks-1552ad0"},
+		{name: "tilde range", kubernetes: "~1.30", version: "v1.31.0", wantErr: true},
+		{name: "unparsable version", kubernetes: ">=1.28", version: "custom", wantErr: true},
+		{name: "invalid range", kubernetes: ">>1.28", version: "v1.30.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			cluster := &ClusterConfig{Name: "test", Kubernetes: tt.kubernetes}
+			err := cluster.CheckKubernetesVersion(tt.version, "cluster 'test'")
+			if (err != nil) != tt.wantErr {
+				test.Errorf("CheckKubernetesVersion(%q) error = %v, wantErr %v", tt.version, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Kind configuration file type
+const (
+	KindConfigAPIVersion = "kind.x-k8s.io/v1alpha4"
+	KindConfigKind       = "Cluster"
+)
+
+// kindConfigFile is the part of a kind v1alpha4 file kraze reads itself; kind
+// reads the whole file when the cluster is created
+type kindConfigFile struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Nodes      []struct {
+		KindNode `yaml:",inline"`
+		Image    string `yaml:"image,omitempty"`
+	} `yaml:"nodes"`
+}
+
+// applyKindConfig reads the nodes of cluster.kind_config into cluster.config,
+// so host port checks, topology and node roles see the nodes the file creates
+func (cfg *Config) applyKindConfig() error {
+	cluster := &cfg.Cluster
+	if cluster.KindConfig == "" || cluster.IsExternal() || cluster.IsNone() {
+		// Reported by lint as a kind-only setting
+		return nil
+	}
+	if len(cluster.Config) > 0 {
+		return &ValidationError{Field: "cluster.kind_config", Message: "can't be combined with cluster.config; define the nodes in the kind config file"}
+	}
+	if cluster.Preset != "" {
+		return &ValidationError{Field: "cluster.kind_config", Message: "can't be combined with cluster.preset"}
+	}
+
+	data, err := os.ReadFile(cluster.KindConfig)
+	if err != nil {
+		return &ValidationError{Field: "cluster.kind_config", Message: fmt.Sprintf("failed to read kind config: %v", err)}
+	}
+	var file kindConfigFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return &ValidationError{Field: "cluster.kind_config", Message: fmt.Sprintf("failed to parse %s: %v", cluster.KindConfig, err)}
+	}
+	if file.APIVersion != KindConfigAPIVersion || file.Kind != KindConfigKind {
+		return &ValidationError{
+			Field:   "cluster.kind_config",
+			Message: fmt.Sprintf("%s is not a kind config (expected apiVersion: %s and kind: %s)", cluster.KindConfig, KindConfigAPIVersion, KindConfigKind),
+		}
+	}
+
+	kindDir := filepath.Dir(cluster.KindConfig)
+	for _, node := range file.Nodes {
+		kindNode := node.KindNode
+		// kind applies the file's own patches; kraze adds only its own to the node
+		kindNode.KubeadmConfigPatches = nil
+		for itr, mount := range kindNode.ExtraMounts {
+			kindNode.ExtraMounts[itr].HostPath = ResolveKindHostPath(kindDir, mount.HostPath)
+		}
+		cluster.Config = append(cluster.Config, kindNode)
+		cfg.deriveOrigins(itemPath("cluster.config", len(cluster.Config)-1), kindNode, "kind_config "+displayPath(cluster.KindConfig), false)
+
+		// Report the Kubernetes version of the nodes the file pins
+		if cluster.NodeImage == "" && cluster.Version == "" && node.Image != "" {
+			cluster.NodeImage = node.Image
+			cfg.deriveOrigins("cluster.node_image", node.Image, "kind_config "+displayPath(cluster.KindConfig), false)
+		}
+	}
+	return nil
+}
+
+// ResolveKindHostPath resolves a relative extraMounts host path in a kind
+// config file against the file's directory
+func ResolveKindHostPath(kindDir, hostPath string) string {
+	if hostPath == "" || filepath.IsAbs(hostPath) {
+		return hostPath
+	}
+	return filepath.Join(kindDir, hostPath)
+}
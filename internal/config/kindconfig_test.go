@@ -0,0 +1,94 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const kindConfigYAML = `apiVersion: kind.x-k8s.io/v1alpha4
+kind: Cluster
+featureGates:
+  InPlacePodVerticalScaling: true
+nodes:
+  - role: control-plane
+    image: kindest/node:v1.31.0
+    extraPortMappings:
+      - containerPort: 30080
+        hostPort: 8080
+  - role: worker
+    extraMounts:
+      - hostPath: ./data
+        containerPath: /data
+`
+
+func TestParseKindConfig(test *testing.T) {
+	dir := writeConfigFiles(test, map[string]string{
+		"cluster/kind.yaml": kindConfigYAML,
+		"kraze.yml":         "cluster:\n  name: dev\n  kind_config: cluster/kind.yaml\n",
+	})
+
+	cfg, err := Parse(filepath.Join(dir, "kraze.yml"))
+	if err != nil {
+		test.Fatalf("Parse() error = %v", err)
+	}
+
+	cluster := cfg.Cluster
+	if cluster.KindConfig != filepath.Join(dir, "cluster", "kind.yaml") {
+		test.Errorf("KindConfig = %s, want it relative to kraze.yml", cluster.KindConfig)
+	}
+	if len(cluster.Config) != 2 || cluster.Config[1].Role != "worker" {
+		test.Fatalf("Config = %+v, want the file's control-plane and worker", cluster.Config)
+	}
+	if cluster.Config[0].ExtraPortMappings[0].HostPort != 8080 {
+		test.Errorf("Config[0] port mappings = %+v, want host port 8080", cluster.Config[0].ExtraPortMappings)
+	}
+	if hostPath := cluster.Config[1].ExtraMounts[0].HostPath; hostPath != filepath.Join(dir, "cluster", "data") {
+		test.Errorf("Config[1] mount = %s, want it relative to the kind config", hostPath)
+	}
+	if cluster.NodeImage != "kindest/node:v1.31.0" {
+		test.Errorf("NodeImage = %s, want the file's image", cluster.NodeImage)
+	}
+}
+
+func TestParseKindConfigErrors(test *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected string
+	}{
+		{
+			name: "with cluster config",
+			files: map[string]string{
+				"kind.yaml": kindConfigYAML,
+				"kraze.yml": "cluster:\n  name: dev\n  kind_config: kind.yaml\n  config:\n    - role: control-plane\n",
+			},
+			expected: "can't be combined with cluster.config",
+		},
+		{
+			name: "not a kind config",
+			files: map[string]string{
+				"kind.yaml": "apiVersion: v1\nkind: ConfigMap\n",
+				"kraze.yml": "cluster:\n  name: dev\n  kind_config: kind.yaml\n",
+			},
+			expected: "is not a kind config",
+		},
+		{
+			name: "missing file",
+			files: map[string]string{
+				"kraze.yml": "cluster:\n  name: dev\n  kind_config: kind.yaml\n",
+			},
+			expected: "failed to read kind config",
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			dir := writeConfigFiles(test, tt.files)
+			_, err := Parse(filepath.Join(dir, "kraze.yml"))
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				test.Errorf("Parse() error = %v, want %q", err, tt.expected)
+			}
+		})
+	}
+}
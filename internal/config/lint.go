@@ -0,0 +1,328 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LintFinding is a single issue reported by a lint rule
+type LintFinding struct {
+	RuleID  string // Stable rule identifier (e.g., "KZ001")
+	Field   string // Config field the finding refers to
+	Message string // What is wrong
+	Hint    string // How to fix it
+}
+
+func (finding LintFinding) String() string {
+	return fmt.Sprintf("[%s] %s: %s", finding.RuleID, finding.Field, finding.Message)
+}
+
+// LintRule describes a lint check with a stable ID that can be suppressed
+// via the top-level lint.ignore list
+type LintRule struct {
+	ID          string
+	Name        string
+	Description string
+	check       func(cfg *Config, configPaths []string) []LintFinding
+}
+
+// lintRules is the ordered list of all lint rules.
+// Rule IDs are stable: never renumber or reuse an ID once released.
+// KZ0xx are conflicting settings, KZ1xx are deprecations.
+var lintRules = []LintRule{
+	{
+		ID:          "KZ001",
+		Name:        "external-kind-settings",
+		Description: "kind-only cluster settings are ignored for external clusters and cluster.none",
+		check:       lintExternalKindSettings,
+	},
+	{
+		ID:          "KZ002",
+		Name:        "subnet-without-network",
+		Description: "cluster.subnet only takes effect when cluster.network is set",
+		check:       lintSubnetWithoutNetwork,
+	},
+	{
+		ID:          "KZ003",
+		Name:        "ipv4-address-without-network",
+		Description: "cluster.ipv4_address is applied to an auto-detected network when cluster.network is not set",
+		check:       lintIPv4WithoutNetwork,
+	},
+	{
+		ID:          "KZ004",
+		Name:        "keep-crds-manifests",
+		Description: "keep_crds only applies to helm services",
+		check:       lintKeepCRDsManifests,
+	},
+	{
+		ID:          "KZ005",
+		Name:        "helm-fields-on-manifests",
+		Description: "helm chart fields are ignored for manifests services",
+		check:       lintHelmFieldsOnManifests,
+	},
+	{
+		ID:          "KZ006",
+		Name:        "paths-on-helm",
+		Description: "paths is ignored for helm services",
+		check:       lintPathsOnHelm,
+	},
+	{
+		ID:          "KZ007",
+		Name:        "wait-timeout-without-wait",
+		Description: "wait_timeout has no effect when wait is false",
+		check:       lintWaitTimeoutWithoutWait,
+	},
+	{
+		ID:          "KZ101",
+		Name:        "legacy-state-file",
+		Description: "local .kraze.state files are no longer used since v0.6.0",
+		check:       lintLegacyStateFile,
+	},
+}
+
+// LintRules returns all lint rules in ID order
+func LintRules() []LintRule {
+	return lintRules
+}
+
+// RunLint checks the configuration for conflicting or deprecated settings.
+// configPaths are the config files the configuration was parsed from (used by
+// rules that inspect the files' surroundings). Rules listed in lint.ignore are skipped.
+func (cfg *Config) RunLint(configPaths []string) []LintFinding {
+	var findings []LintFinding
+	for _, rule := range lintRules {
+		if cfg.IsLintRuleIgnored(rule.ID) {
+			continue
+		}
+		for _, finding := range rule.check(cfg, configPaths) {
+			finding.RuleID = rule.ID
+			findings = append(findings, finding)
+		}
+	}
+
+	return findings
+}
+
+// IsLintRuleIgnored returns true if the rule ID is listed in lint.ignore
+func (cfg *Config) IsLintRuleIgnored(ruleID string) bool {
+	for _, id := range cfg.Lint.Ignore {
+		if strings.EqualFold(strings.TrimSpace(id), ruleID) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedServiceNames returns service names in a stable order so findings are deterministic
+func (cfg *Config) sortedServiceNames() []string {
+	names := cfg.GetAllServiceNames()
+	sort.Strings(names)
+	return names
+}
+
+func lintExternalKindSettings(cfg *Config, _ []string) []LintFinding {
+	if !cfg.Cluster.IsExternal() && !cfg.Cluster.IsNone() {
+		return nil
+	}
+
+	cluster := cfg.Cluster
+	var fields []string
+	if cluster.Version != "" {
+		fields = append(fields, "version")
+	}
+	if cluster.NodeImage != "" {
+		fields = append(fields, "node_image")
+	}
+	if len(cluster.Config) > 0 {
+		fields = append(fields, "config")
+	}
+	if cluster.KindConfig != "" {
+		fields = append(fields, "kind_config")
+	}
+	if cluster.Networking != nil {
+		fields = append(fields, "networking")
+	}
+	if len(cluster.PreloadImages) > 0 {
+		fields = append(fields, "preload_images")
+	}
+	if cluster.Network != "" {
+		fields = append(fields, "network")
+	}
+	if cluster.Subnet != "" {
+		fields = append(fields, "subnet")
+	}
+	if cluster.IPv4Address != "" {
+		fields = append(fields, "ipv4_address")
+	}
+	if len(cluster.CACertificates) > 0 {
+		fields = append(fields, "ca_certificates")
+	}
+	if len(cluster.InsecureRegistries) > 0 {
+		fields = append(fields, "insecure_registries")
+	}
+	if cluster.Proxy != nil {
+		fields = append(fields, "proxy")
+	}
+	if len(cluster.FeatureGates) > 0 {
+		fields = append(fields, "feature_gates")
+	}
+	if len(cluster.RuntimeConfig) > 0 {
+		fields = append(fields, "runtime_config")
+	}
+	if len(cluster.KubeadmConfigPatches) > 0 {
+		fields = append(fields, "kubeadm_config_patches")
+	}
+	if len(cluster.KubeletConfig) > 0 {
+		fields = append(fields, "kubelet_config")
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	if cluster.IsNone() {
+		return []LintFinding{{
+			Field:   "cluster",
+			Message: fmt.Sprintf("cluster.none ignores kind-only settings: %s", strings.Join(fields, ", ")),
+			Hint:    "remove these settings or disable cluster.none to create a kind cluster",
+		}}
+	}
+
+	return []LintFinding{{
+		Field:   "cluster",
+		Message: fmt.Sprintf("external cluster ignores kind-only settings: %s", strings.Join(fields, ", ")),
+		Hint:    "remove these settings or disable cluster.external to create a kind cluster",
+	}}
+}
+
+func lintSubnetWithoutNetwork(cfg *Config, _ []string) []LintFinding {
+	if cfg.Cluster.IsExternal() || cfg.Cluster.Subnet == "" || cfg.Cluster.Network != "" {
+		return nil
+	}
+
+	return []LintFinding{{
+		Field:   "cluster.subnet",
+		Message: "subnet is set but network is not, so the subnet is ignored",
+		Hint:    "set cluster.network to the Docker network that should be created with this subnet",
+	}}
+}
+
+func lintIPv4WithoutNetwork(cfg *Config, _ []string) []LintFinding {
+	if cfg.Cluster.IsExternal() || cfg.Cluster.IPv4Address == "" || cfg.Cluster.Network != "" {
+		return nil
+	}
+
+	return []LintFinding{{
+		Field:   "cluster.ipv4_address",
+		Message: "ipv4_address is set but network is not, so the address is applied to whichever network is auto-detected",
+		Hint:    "set cluster.network so the static address is assigned on a known network",
+	}}
+}
+
+func lintKeepCRDsManifests(cfg *Config, _ []string) []LintFinding {
+	var findings []LintFinding
+	for _, name := range cfg.sortedServiceNames() {
+		svc := cfg.Services[name]
+		if svc.IsManifests() && svc.KeepCRDs != nil {
+			findings = append(findings, LintFinding{
+				Field:   fmt.Sprintf("services.%s.keep_crds", name),
+				Message: "keep_crds has no effect on manifests services",
+				Hint:    "remove keep_crds; CRDs in manifests are deleted with the rest of the service's resources",
+			})
+		}
+	}
+	return findings
+}
+
+func lintHelmFieldsOnManifests(cfg *Config, _ []string) []LintFinding {
+	var findings []LintFinding
+	for _, name := range cfg.sortedServiceNames() {
+		svc := cfg.Services[name]
+		if !svc.IsManifests() {
+			continue
+		}
+
+		var fields []string
+		if svc.Repo != "" {
+			fields = append(fields, "repo")
+		}
+		if svc.Chart != "" {
+			fields = append(fields, "chart")
+		}
+		if svc.Version != "" {
+			fields = append(fields, "version")
+		}
+		if !svc.Values.IsEmpty() {
+			fields = append(fields, "values")
+		}
+		if svc.ValuesInline != "" {
+			fields = append(fields, "values_inline")
+		}
+		if svc.WaitForJobs != nil {
+			fields = append(fields, "wait_for_jobs")
+		}
+
+		if len(fields) > 0 {
+			findings = append(findings, LintFinding{
+				Field:   fmt.Sprintf("services.%s", name),
+				Message: fmt.Sprintf("manifests service ignores helm fields: %s", strings.Join(fields, ", ")),
+				Hint:    "remove these fields or change the service type to helm",
+			})
+		}
+	}
+	return findings
+}
+
+func lintPathsOnHelm(cfg *Config, _ []string) []LintFinding {
+	var findings []LintFinding
+	for _, name := range cfg.sortedServiceNames() {
+		svc := cfg.Services[name]
+		if svc.IsHelm() && len(svc.Paths) > 0 {
+			findings = append(findings, LintFinding{
+				Field:   fmt.Sprintf("services.%s.paths", name),
+				Message: "paths is ignored for helm services",
+				Hint:    "use path for a local chart directory",
+			})
+		}
+	}
+	return findings
+}
+
+func lintWaitTimeoutWithoutWait(cfg *Config, _ []string) []LintFinding {
+	var findings []LintFinding
+	for _, name := range cfg.sortedServiceNames() {
+		svc := cfg.Services[name]
+		if svc.Wait != nil && !*svc.Wait && svc.WaitTimeout != "" {
+			findings = append(findings, LintFinding{
+				Field:   fmt.Sprintf("services.%s.wait_timeout", name),
+				Message: "wait_timeout is set but wait is false",
+				Hint:    "remove wait_timeout or set wait: true",
+			})
+		}
+	}
+	return findings
+}
+
+func lintLegacyStateFile(_ *Config, configPaths []string) []LintFinding {
+	var findings []LintFinding
+	seen := make(map[string]bool)
+	for _, cfgPath := range configPaths {
+		stateFile := filepath.Join(filepath.Dir(cfgPath), ".kraze.state")
+		if seen[stateFile] {
+			continue
+		}
+		seen[stateFile] = true
+
+		if _, err := os.Stat(stateFile); err == nil {
+			findings = append(findings, LintFinding{
+				Field:   stateFile,
+				Message: "legacy state file is no longer used (state is stored in the cluster since v0.6.0)",
+				Hint:    "delete the file; clusters created before v0.6.0 should be recreated",
+			})
+		}
+	}
+	return findings
+}
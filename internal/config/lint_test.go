@@ -0,0 +1,137 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunLint(test *testing.T) {
+	boolPtr := func(b bool) *bool { return &b }
+
+	tests := []struct {
+		name     string
+		cfg      Config
+		expected []string
+	}{
+		{
+			name: "clean config",
+			cfg: Config{
+				Cluster: ClusterConfig{Name: "dev"},
+				Services: map[string]ServiceConfig{
+					"redis": {Name: "redis", Type: "helm", Repo: "oci://example.com", Chart: "redis"},
+				},
+			},
+			expected: nil,
+		},
+		{
+			name: "external cluster with kind settings",
+			cfg: Config{
+				Cluster: ClusterConfig{
+					Name:      "dev",
+					NodeImage: "kindest/node:v1.31.0",
+					Config:    []KindNode{{Role: "control-plane"}},
+					External:  &ExternalClusterConfig{Enabled: true},
+				},
+			},
+			expected: []string{"KZ001"},
+		},
+		{
+			name: "no cluster with kind settings",
+			cfg: Config{
+				Cluster: ClusterConfig{Name: "dev", None: true, PreloadImages: []string{"redis:7"}},
+			},
+			expected: []string{"KZ001"},
+		},
+		{
+			name: "subnet and ipv4 address without network",
+			cfg: Config{
+				Cluster: ClusterConfig{Name: "dev", Subnet: "172.30.0.0/16", IPv4Address: "172.30.0.10"},
+			},
+			expected: []string{"KZ002", "KZ003"},
+		},
+		{
+			name: "manifests with keep_crds and helm fields",
+			cfg: Config{
+				Cluster: ClusterConfig{Name: "dev"},
+				Services: map[string]ServiceConfig{
+					"app": {Name: "app", Type: "manifests", Path: "./k8s", KeepCRDs: boolPtr(true), Chart: "app", WaitForJobs: boolPtr(true)},
+				},
+			},
+			expected: []string{"KZ004", "KZ005"},
+		},
+		{
+			name: "helm with paths and wait_timeout without wait",
+			cfg: Config{
+				Cluster: ClusterConfig{Name: "dev"},
+				Services: map[string]ServiceConfig{
+					"chart": {Name: "chart", Type: "helm", Path: "./chart", Paths: []string{"./a.yaml"}, Wait: boolPtr(false), WaitTimeout: "5m"},
+				},
+			},
+			expected: []string{"KZ006", "KZ007"},
+		},
+		{
+			name: "suppressed rule",
+			cfg: Config{
+				Cluster: ClusterConfig{Name: "dev", Subnet: "172.30.0.0/16", IPv4Address: "172.30.0.10"},
+				Lint:    LintConfig{Ignore: []string{"kz002"}},
+			},
+			expected: []string{"KZ003"},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			findings := tt.cfg.RunLint(nil)
+			if len(findings) != len(tt.expected) {
+				test.Fatalf("Expected %d finding(s), got %d: %v", len(tt.expected), len(findings), findings)
+			}
+			for i, finding := range findings {
+				if finding.RuleID != tt.expected[i] {
+					test.Errorf("Expected finding %d to be %s, got %s", i, tt.expected[i], finding.RuleID)
+				}
+			}
+		})
+	}
+}
+
+func TestRunLintLegacyStateFile(test *testing.T) {
+	tmpDir := test.TempDir()
+	cfgPath := filepath.Join(tmpDir, "kraze.yml")
+
+	cfg := Config{Cluster: ClusterConfig{Name: "dev"}}
+
+	if findings := cfg.RunLint([]string{cfgPath}); len(findings) != 0 {
+		test.Fatalf("Expected no findings without state file, got %v", findings)
+	}
+
+	if err := os.WriteFile(filepath.Join(tmpDir, ".kraze.state"), []byte("{}"), 0644); err != nil {
+		test.Fatalf("Failed to write state file: %v", err)
+	}
+
+	findings := cfg.RunLint([]string{cfgPath})
+	if len(findings) != 1 || findings[0].RuleID != "KZ101" {
+		test.Errorf("Expected a single KZ101 finding, got %v", findings)
+	}
+}
+
+func TestIsLintRuleIgnored(test *testing.T) {
+	cfg := &Config{Lint: LintConfig{Ignore: []string{" kz201 ", "KZ003"}}}
+
+	tests := []struct {
+		ruleID   string
+		expected bool
+	}{
+		{ruleID: "KZ201", expected: true},
+		{ruleID: "KZ003", expected: true},
+		{ruleID: "KZ001", expected: false},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.ruleID, func(test *testing.T) {
+			if got := cfg.IsLintRuleIgnored(tt.ruleID); got != tt.expected {
+				test.Errorf("IsLintRuleIgnored(%q) = %v, want %v", tt.ruleID, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// LocalTLSConfig mints a certificate for a service's ingress hosts from
+// kraze's local CA (see kraze trust), stores it in a TLS Secret and points the
+// service's Ingresses at it, so https works without cert-manager
+type LocalTLSConfig struct {
+	Hosts      []string `yaml:"hosts"`                 // DNS names (*.app.localtest.me for one level of subdomains) or IP addresses
+	SecretName string   `yaml:"secret_name,omitempty"` // Default: <service>-tls
+}
+
+// GetSecretName returns the name of the TLS Secret
+func (tls *LocalTLSConfig) GetSecretName(serviceName string) string {
+	if tls.SecretName != "" {
+		return tls.SecretName
+	}
+	return serviceName + "-tls"
+}
+
+// validateLocalTLS checks the hosts and that the Secret doesn't clash with the secrets block
+func (srv *ServiceConfig) validateLocalTLS() error {
+	if srv.LocalTLS == nil {
+		return nil
+	}
+	if len(srv.LocalTLS.Hosts) == 0 {
+		return &ValidationError{Field: "local_tls.hosts", Message: "at least one host is required"}
+	}
+	seen := make(map[string]bool, len(srv.LocalTLS.Hosts))
+	for _, host := range srv.LocalTLS.Hosts {
+		if seen[host] {
+			return &ValidationError{Field: "local_tls.hosts", Message: fmt.Sprintf("host '%s' is listed more than once", host)}
+		}
+		seen[host] = true
+		if net.ParseIP(host) != nil {
+			continue
+		}
+		if errs := validation.IsDNS1123Subdomain(strings.TrimPrefix(host, "*.")); len(errs) > 0 {
+			return &ValidationError{Field: "local_tls.hosts", Message: fmt.Sprintf("invalid host '%s': %s", host, strings.Join(errs, "; "))}
+		}
+	}
+
+	secretName := srv.LocalTLS.GetSecretName(srv.Name)
+	if errs := validation.IsDNS1123Subdomain(secretName); len(errs) > 0 {
+		return &ValidationError{Field: "local_tls.secret_name", Message: fmt.Sprintf("invalid Secret name '%s': %s", secretName, strings.Join(errs, "; "))}
+	}
+	for _, secret := range srv.Secrets {
+		if secret.Name == secretName {
+			return &ValidationError{Field: "local_tls.secret_name", Message: fmt.Sprintf("Secret '%s' is also defined in secrets", secretName)}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateLocalTLS(test *testing.T) {
+	tests := []struct {
+		name        string
+		localTLS    *LocalTLSConfig
+		secrets     []SecretConfig
+		errContains string
+	}{
+		{name: "hosts and IP", localTLS: &LocalTLSConfig{Hosts: []string{"app.localtest.me", "*.app.localtest.me", "127.0.0.1"}}},
+		{name: "no hosts", localTLS: &LocalTLSConfig{}, errContains: "at least one host"},
+		{name: "invalid host", localTLS: &LocalTLSConfig{Hosts: []string{"app_local"}}, errContains: "invalid host 'app_local'"},
+		{name: "duplicate host", localTLS: &LocalTLSConfig{Hosts: []string{"a.test", "a.test"}}, errContains: "more than once"},
+		{name: "invalid secret name", localTLS: &LocalTLSConfig{Hosts: []string{"a.test"}, SecretName: "Web_TLS"}, errContains: "invalid Secret name"},
+		{
+			name:        "clashes with secrets",
+			localTLS:    &LocalTLSConfig{Hosts: []string{"a.test"}},
+			secrets:     []SecretConfig{{Name: "web-tls"}},
+			errContains: "also defined in secrets",
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			srv := &ServiceConfig{Name: "web", LocalTLS: tt.localTLS, Secrets: tt.secrets}
+			err := srv.validateLocalTLS()
+			if tt.errContains == "" {
+				if err != nil {
+					test.Errorf("validateLocalTLS() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				test.Errorf("validateLocalTLS() error = %v, want one containing %q", err, tt.errContains)
+			}
+		})
+	}
+}
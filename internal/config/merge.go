@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 )
 
@@ -37,6 +38,13 @@ func ParseMultiple(paths []string) (*Config, error) {
 	}
 	merged.Cluster = mergedCluster
 
+	// Values set in several files come from the first one, like most merged settings
+	for _, cfg := range configs {
+		for path, origin := range cfg.origins {
+			merged.setOrigin(path, origin)
+		}
+	}
+
 	// Merge services (duplicate names across files = error).
 	merged.Services = make(map[string]ServiceConfig)
 	for i, cfg := range configs {
@@ -48,6 +56,102 @@ func ParseMultiple(paths []string) (*Config, error) {
 		}
 	}
 
+	// Merge lint suppressions (union across files).
+	for _, cfg := range configs {
+		merged.Lint.Ignore = unionStrings(merged.Lint.Ignore, cfg.Lint.Ignore)
+	}
+
+	// CronJobs are suspended if any file asks for it.
+	for _, cfg := range configs {
+		merged.SuspendCronJobs = merged.SuspendCronJobs || cfg.SuspendCronJobs
+	}
+
+	// The first file that sets a global resources_override wins.
+	for _, cfg := range configs {
+		if cfg.ResourcesOverride != nil {
+			merged.ResourcesOverride = cfg.ResourcesOverride
+			break
+		}
+	}
+
+	// The first file that sets a discovery block wins.
+	for _, cfg := range configs {
+		if cfg.Discovery != nil {
+			merged.Discovery = cfg.Discovery
+			break
+		}
+	}
+
+	// The first file that sets a state backend wins.
+	for _, cfg := range configs {
+		if cfg.StateBackend != "" {
+			merged.StateBackend = cfg.StateBackend
+			break
+		}
+	}
+
+	// The first file that sets an events block wins.
+	for _, cfg := range configs {
+		if cfg.Events != nil {
+			merged.Events = cfg.Events
+			break
+		}
+	}
+
+	// Readiness rules of all files are combined; the first file with a rule for a kind wins.
+	for _, cfg := range configs {
+		for key, rule := range cfg.Readiness {
+			if merged.Readiness == nil {
+				merged.Readiness = make(map[string]ReadinessRule)
+			}
+			if _, exists := merged.Readiness[key]; !exists {
+				merged.Readiness[key] = rule
+			}
+		}
+	}
+
+	// Registries of all files are combined; the first file with credentials for a host wins.
+	for _, cfg := range configs {
+		for _, reg := range cfg.Registries {
+			if FindRegistry(merged.Registries, reg.Host) == nil {
+				merged.Registries = append(merged.Registries, reg)
+			}
+		}
+	}
+
+	// The first file that sets a chart repository wins.
+	for _, cfg := range configs {
+		if cfg.Charts.Repository != "" {
+			merged.Charts = cfg.Charts
+			break
+		}
+	}
+
+	// Expand the kind config file, cluster preset and add-ons once all explicit settings are merged.
+	if err := merged.applyKindConfig(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if err := merged.applyClusterPreset(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if err := merged.applyClusterAddons(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if err := merged.applyResourcesOverride(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if err := merged.applyDiscovery(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if err := merged.applyVariant(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Zones are checked against the merged nodes
+	if err := merged.Cluster.validateTopology(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
 	// Run cross-reference validation on the fully merged config.
 	if err := merged.validateCrossRefs(); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
@@ -61,22 +165,11 @@ func ParseMultiple(paths []string) (*Config, error) {
 // enabled/disabled constraints). Used as the first pass in ParseMultiple so
 // that services in one file can legitimately reference services in another.
 func parseWithoutCrossRefValidation(configPath string) (*Config, error) {
-	data, err := readAndExpand(configPath)
+	cfg, err := loadConfigFile(configPath, "", nil)
 	if err != nil {
 		return nil, err
 	}
 
-	var cfg Config
-	if err := unmarshalConfig(data, &cfg); err != nil {
-		return nil, err
-	}
-
-	// Set service names from map keys.
-	for name, svc := range cfg.Services {
-		svc.Name = name
-		cfg.Services[name] = svc
-	}
-
 	// Validate GPU config if present (can be done per-file).
 	if cfg.Cluster.GPU.IsAnyEnabled() && cfg.Cluster.IsExternal() {
 		return nil, &ValidationError{
@@ -85,19 +178,24 @@ func parseWithoutCrossRefValidation(configPath string) (*Config, error) {
 		}
 	}
 
+	if err := cfg.validateReadiness(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Events.validate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.validateRegistries(); err != nil {
+		return nil, err
+	}
+
 	// Validate individual service configs (type, required fields) but not cross-refs.
 	for _, svc := range cfg.Services {
 		if err := svc.Validate(); err != nil {
-			return nil, fmt.Errorf("service '%s': %w", svc.Name, err)
+			return nil, &ServiceValidationError{Service: svc.Name, Err: err}
 		}
 	}
 
-	// Resolve relative paths based on this file's directory.
-	if err := cfg.ResolvePaths(configPath); err != nil {
-		return nil, fmt.Errorf("failed to resolve paths: %w", err)
-	}
-
-	return &cfg, nil
+	return cfg, nil
 }
 
 // mergeClusterConfigs merges cluster configurations from multiple files.
@@ -112,7 +210,7 @@ func mergeClusterConfigs(configs []*Config) (ClusterConfig, error) {
 		// cluster.name: first-file wins, silently ignore others.
 		// (already set from base)
 
-		// version, node_image: first-file wins; error on conflict.
+		// version, node_image, kubernetes: first-file wins; error on conflict.
 		if other.Version != "" {
 			if base.Version == "" {
 				base.Version = other.Version
@@ -128,10 +226,19 @@ func mergeClusterConfigs(configs []*Config) (ClusterConfig, error) {
 			}
 		}
 
+		if other.Kubernetes != "" {
+			if base.Kubernetes == "" {
+				base.Kubernetes = other.Kubernetes
+			} else if base.Kubernetes != other.Kubernetes {
+				return ClusterConfig{}, fmt.Errorf("cluster.kubernetes conflict between config file 1 (%s) and file %d (%s)", base.Kubernetes, fileIdx, other.Kubernetes)
+			}
+		}
+
 		// Lists: concatenate + deduplicate.
 		base.CACertificates = unionStrings(base.CACertificates, other.CACertificates)
 		base.InsecureRegistries = unionStrings(base.InsecureRegistries, other.InsecureRegistries)
 		base.PreloadImages = unionStrings(base.PreloadImages, other.PreloadImages)
+		base.Addons = unionStrings(base.Addons, other.Addons)
 
 		// GPU: OR per-vendor enabled flags.
 		base.GPU = mergeGPUConfigs(base.GPU, other.GPU)
@@ -170,7 +277,55 @@ func mergeClusterConfigs(configs []*Config) (ClusterConfig, error) {
 			}
 		}
 
+		// No cluster: any file can turn it on.
+		base.None = base.None || other.None
+
+		// Registry credentials: must agree.
+		if other.RegistryCredentials != nil {
+			if base.RegistryCredentials == nil {
+				base.RegistryCredentials = other.RegistryCredentials
+			} else if !reflect.DeepEqual(base.RegistryCredentials, other.RegistryCredentials) {
+				return ClusterConfig{}, fmt.Errorf("cluster.registry_credentials conflict between config file 1 and file %d", fileIdx)
+			}
+		}
+
+		// Topology: must agree.
+		if other.Topology != nil {
+			if base.Topology == nil {
+				base.Topology = other.Topology
+			} else if !reflect.DeepEqual(base.Topology, other.Topology) {
+				return ClusterConfig{}, fmt.Errorf("cluster.topology conflict between config file 1 and file %d", fileIdx)
+			}
+		}
+
+		// Impersonation: must agree.
+		if other.Impersonate != nil {
+			if base.Impersonate == nil {
+				base.Impersonate = other.Impersonate
+			} else if !reflect.DeepEqual(base.Impersonate, other.Impersonate) {
+				return ClusterConfig{}, fmt.Errorf("cluster.impersonate conflict between config file 1 and file %d", fileIdx)
+			}
+		}
+
+		// Settings passed through to kind: union; error if the same key has a different value.
+		if base.FeatureGates, err = mergeClusterMap(base.FeatureGates, other.FeatureGates, "cluster.feature_gates", fileIdx); err != nil {
+			return ClusterConfig{}, err
+		}
+		if base.RuntimeConfig, err = mergeClusterMap(base.RuntimeConfig, other.RuntimeConfig, "cluster.runtime_config", fileIdx); err != nil {
+			return ClusterConfig{}, err
+		}
+		if base.KubeletConfig, err = mergeClusterMap(base.KubeletConfig, other.KubeletConfig, "cluster.kubelet_config", fileIdx); err != nil {
+			return ClusterConfig{}, err
+		}
+		base.KubeadmConfigPatches = unionStrings(base.KubeadmConfigPatches, other.KubeadmConfigPatches)
+
 		// Network identity fields: must agree if both set.
+		if err := mergeStringField(&base.Preset, other.Preset, "cluster.preset", fileIdx); err != nil {
+			return ClusterConfig{}, err
+		}
+		if err := mergeStringField(&base.KindConfig, other.KindConfig, "cluster.kind_config", fileIdx); err != nil {
+			return ClusterConfig{}, err
+		}
 		if err := mergeStringField(&base.Network, other.Network, "cluster.network", fileIdx); err != nil {
 			return ClusterConfig{}, err
 		}
@@ -279,7 +434,8 @@ func mergeStringField(dst *string, src, fieldName string, fileIdx int) error {
 //   - replicas: error on conflict if both non-zero and differ
 //   - extraPortMappings: union; error if same containerPort+protocol has conflicting hostPort/listenAddress
 //   - extraMounts: union; error if same containerPath has conflicting hostPath/readOnly
-//   - labels: union; error if same key has different value
+//   - labels, systemReserved, kubeReserved, evictionHard, kubeletExtraArgs, sysctls: union; error if same key has different value
+//   - kubeadmConfigPatches: union
 //
 // Nodes present in only one slice are included as-is.
 func mergeKindNodes(base, other []KindNode, fileIdx int) ([]KindNode, error) {
@@ -331,11 +487,31 @@ func mergeKindNodes(base, other []KindNode, fileIdx int) ([]KindNode, error) {
 		b.ExtraMounts = mergedMounts
 
 		// labels: union with conflict detection.
-		mergedLabels, err := mergeLabels(b.Labels, o.Labels, o.Role, fileIdx)
+		mergedLabels, err := mergeNodeMap(b.Labels, o.Labels, o.Role, "labels", fileIdx)
 		if err != nil {
 			return nil, err
 		}
 		b.Labels = mergedLabels
+
+		// kubelet reservations: union with conflict detection.
+		if b.SystemReserved, err = mergeNodeMap(b.SystemReserved, o.SystemReserved, o.Role, "systemReserved", fileIdx); err != nil {
+			return nil, err
+		}
+		if b.KubeReserved, err = mergeNodeMap(b.KubeReserved, o.KubeReserved, o.Role, "kubeReserved", fileIdx); err != nil {
+			return nil, err
+		}
+		if b.EvictionHard, err = mergeNodeMap(b.EvictionHard, o.EvictionHard, o.Role, "evictionHard", fileIdx); err != nil {
+			return nil, err
+		}
+
+		// Passthrough: union of kubelet flags and sysctls with conflict detection, and of patches.
+		if b.KubeletExtraArgs, err = mergeNodeMap(b.KubeletExtraArgs, o.KubeletExtraArgs, o.Role, "kubeletExtraArgs", fileIdx); err != nil {
+			return nil, err
+		}
+		if b.Sysctls, err = mergeNodeMap(b.Sysctls, o.Sysctls, o.Role, "sysctls", fileIdx); err != nil {
+			return nil, err
+		}
+		b.KubeadmConfigPatches = unionStrings(b.KubeadmConfigPatches, o.KubeadmConfigPatches)
 	}
 
 	return result, nil
@@ -404,9 +580,9 @@ func mergeMounts(base, other []Mount, role string, fileIdx int) ([]Mount, error)
 	return result, nil
 }
 
-// mergeLabels unions two label maps.
+// mergeNodeMap unions two node string maps (labels, kubelet reservations).
 // Conflict: same key with different value.
-func mergeLabels(base, other map[string]string, role string, fileIdx int) (map[string]string, error) {
+func mergeNodeMap(base, other map[string]string, role, field string, fileIdx int) (map[string]string, error) {
 	if len(other) == 0 {
 		return base, nil
 	}
@@ -417,7 +593,28 @@ func mergeLabels(base, other map[string]string, role string, fileIdx int) (map[s
 	}
 	for k, v := range other {
 		if existing, exists := result[k]; exists && existing != v {
-			return nil, fmt.Errorf("cluster.config role=%q labels key=%q conflict between config file 1 (%q) and file %d (%q)", role, k, existing, fileIdx, v)
+			return nil, fmt.Errorf("cluster.config role=%q %s key=%q conflict between config file 1 (%q) and file %d (%q)", role, field, k, existing, fileIdx, v)
+		}
+		result[k] = v
+	}
+
+	return result, nil
+}
+
+// mergeClusterMap unions two cluster-level maps.
+// Conflict: same key with a different value.
+func mergeClusterMap[V any](base, other map[string]V, field string, fileIdx int) (map[string]V, error) {
+	if len(other) == 0 {
+		return base, nil
+	}
+
+	result := make(map[string]V, len(base)+len(other))
+	for k, v := range base {
+		result[k] = v
+	}
+	for k, v := range other {
+		if existing, exists := result[k]; exists && !reflect.DeepEqual(existing, v) {
+			return nil, fmt.Errorf("%s key=%q conflict between config file 1 (%v) and file %d (%v)", field, k, existing, fileIdx, v)
 		}
 		result[k] = v
 	}
@@ -463,10 +660,10 @@ func unionNoProxy(a, b string) string {
 // across the fully merged service map.
 func (cfg *Config) validateCrossRefs() error {
 	for _, svc := range cfg.Services {
-		for _, dep := range svc.DependsOn {
+		for _, dep := range svc.DependsOn.Names() {
 			if _, exists := cfg.Services[dep]; !exists {
 				return &ValidationError{
-					Field:   fmt.Sprintf("service '%s' depends_on", svc.Name),
+					Field:   fmt.Sprintf("services.%s.depends_on.%s", svc.Name, dep),
 					Message: fmt.Sprintf("dependency '%s' not found in services", dep),
 				}
 			}
@@ -477,10 +674,10 @@ func (cfg *Config) validateCrossRefs() error {
 		if !svc.IsEnabled() {
 			continue
 		}
-		for _, depName := range svc.DependsOn {
+		for _, depName := range svc.DependsOn.Names() {
 			if depSvc, exists := cfg.Services[depName]; exists && !depSvc.IsEnabled() {
 				return &ValidationError{
-					Field:   fmt.Sprintf("service '%s' depends_on", svc.Name),
+					Field:   fmt.Sprintf("services.%s.depends_on.%s", svc.Name, depName),
 					Message: fmt.Sprintf("depends on disabled service '%s'", depName),
 				}
 			}
@@ -120,8 +120,8 @@ services:
 		t.Fatalf("cross-file dependency should be valid, got error: %v", err)
 	}
 	app := cfg.Services["app"]
-	if len(app.DependsOn) != 1 || app.DependsOn[0] != "redis" {
-		t.Errorf("expected depends_on=[redis], got %v", app.DependsOn)
+	if len(app.DependsOn.Names()) != 1 || app.DependsOn.Names()[0] != "redis" {
+		t.Errorf("expected depends_on=[redis], got %v", app.DependsOn.Names())
 	}
 }
 
@@ -317,6 +317,34 @@ services:
 	}
 }
 
+func TestParseMultipleImpersonateConflictError(t *testing.T) {
+	dir := t.TempDir()
+	a := writeTemp(t, dir, "a.yml", `
+cluster:
+  name: dev
+  impersonate:
+    service_account: team-a/deployer
+services:
+  redis:
+    type: manifests
+    path: .
+`)
+	b := writeTemp(t, dir, "b.yml", `
+cluster:
+  name: dev
+  impersonate:
+    user: jane
+services:
+  postgres:
+    type: manifests
+    path: .
+`)
+	_, err := ParseMultiple([]string{a, b})
+	if err == nil {
+		t.Error("expected error for cluster.impersonate conflict, got nil")
+	}
+}
+
 func TestParseMultipleEmptyPathsError(t *testing.T) {
 	_, err := ParseMultiple([]string{})
 	if err == nil {
@@ -574,6 +602,39 @@ services:
 	}
 }
 
+func TestParseMultipleKindNodeReservationsConflictError(t *testing.T) {
+	dir := t.TempDir()
+	a := writeTemp(t, dir, "a.yml", `
+cluster:
+  name: dev
+  config:
+    - role: control-plane
+      systemReserved:
+        memory: 1Gi
+services:
+  redis:
+    type: manifests
+    path: .
+`)
+	b := writeTemp(t, dir, "b.yml", `
+cluster:
+  name: dev
+  config:
+    - role: control-plane
+      systemReserved:
+        cpu: 500m
+        memory: 2Gi
+services:
+  postgres:
+    type: manifests
+    path: .
+`)
+	_, err := ParseMultiple([]string{a, b})
+	if err == nil || !strings.Contains(err.Error(), "systemReserved") {
+		t.Errorf("expected systemReserved conflict error, got %v", err)
+	}
+}
+
 func TestParseMultipleKindNodeDistinctRoles(t *testing.T) {
 	dir := t.TempDir()
 	a := writeTemp(t, dir, "a.yml", `
@@ -626,3 +687,111 @@ func splitNoProxy(s string) []string {
 	}
 	return result
 }
+
+func TestParseMultipleChartsFirstFileWins(t *testing.T) {
+	dir := t.TempDir()
+	a := writeTemp(t, dir, "a.yml", `
+cluster:
+  name: dev
+services:
+  redis:
+    type: manifests
+    path: .
+`)
+	b := writeTemp(t, dir, "b.yml", `
+charts:
+  repository: oci://localhost:5000/charts
+  plain_http: true
+services:
+  api:
+    type: manifests
+    path: .
+`)
+	c := writeTemp(t, dir, "c.yml", `
+charts:
+  repository: oci://ghcr.io/org/charts
+services:
+  web:
+    type: manifests
+    path: .
+`)
+	cfg, err := ParseMultiple([]string{a, b, c})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Charts.Repository != "oci://localhost:5000/charts" || !cfg.Charts.PlainHTTP {
+		t.Errorf("expected charts from b.yml (first file that sets it), got %+v", cfg.Charts)
+	}
+}
+
+func TestParseMultiplePassthroughUnion(t *testing.T) {
+	dir := t.TempDir()
+	a := writeTemp(t, dir, "a.yml", `
+cluster:
+  name: dev
+  feature_gates:
+    InPlacePodVerticalScaling: true
+  config:
+    - role: worker
+      sysctls:
+        fs.inotify.max_user_watches: "524288"
+services:
+  redis:
+    type: manifests
+    path: .
+`)
+	b := writeTemp(t, dir, "b.yml", `
+cluster:
+  name: dev
+  feature_gates:
+    InPlacePodVerticalScaling: true
+    UserNamespacesSupport: true
+  config:
+    - role: worker
+      kubeletExtraArgs:
+        max-pods: "250"
+services:
+  postgres:
+    type: manifests
+    path: .
+`)
+	cfg, err := ParseMultiple([]string{a, b})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Cluster.FeatureGates) != 2 {
+		t.Errorf("feature_gates = %v, want both gates", cfg.Cluster.FeatureGates)
+	}
+	worker := cfg.Cluster.Config[0]
+	if worker.Sysctls["fs.inotify.max_user_watches"] != "524288" || worker.KubeletExtraArgs["max-pods"] != "250" {
+		t.Errorf("worker = %+v, want the sysctls and kubelet flags of both files", worker)
+	}
+}
+
+func TestParseMultiplePassthroughConflictError(t *testing.T) {
+	dir := t.TempDir()
+	a := writeTemp(t, dir, "a.yml", `
+cluster:
+  name: dev
+  feature_gates:
+    InPlacePodVerticalScaling: true
+services:
+  redis:
+    type: manifests
+    path: .
+`)
+	b := writeTemp(t, dir, "b.yml", `
+cluster:
+  name: dev
+  feature_gates:
+    InPlacePodVerticalScaling: false
+services:
+  postgres:
+    type: manifests
+    path: .
+`)
+	_, err := ParseMultiple([]string{a, b})
+	if err == nil || !strings.Contains(err.Error(), "cluster.feature_gates") {
+		t.Errorf("expected feature_gates conflict error, got %v", err)
+	}
+}
@@ -0,0 +1,399 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Origin is where a value of the parsed config came from: a line of a config
+// file, or what kraze derived it from when no file wrote it
+type Origin struct {
+	File   string   // Config file, or the URL of an included one
+	Line   int      // Line of the value's key (or list item) in File
+	Vars   []string // ${NAME} variables the value was expanded from
+	Source string   // What set a value no file wrote (e.g., "preset ingress-dev", "default")
+}
+
+// String describes the origin, as in "kraze.yml:12 ${DB_HOST}" or "add-on traefik"
+func (origin Origin) String() string {
+	if origin.Source != "" {
+		return origin.Source
+	}
+	description := fmt.Sprintf("%s:%d", origin.File, origin.Line)
+	for _, name := range origin.Vars {
+		description += " ${" + name + "}"
+	}
+	return description
+}
+
+// secretKeyPattern matches keys whose values are masked in config views
+var secretKeyPattern = regexp.MustCompile(`(?i)(password|passwd|secret|token|api[_-]?key|authorization|credential|private[_-]?key)`)
+
+// maskedValue replaces secret values in config views
+const maskedValue = "********"
+
+// originPath returns the path of a map value under prefix, as origins are keyed
+// ("services.api.namespace")
+func originPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// itemPath returns the path of a list item under prefix ("services.api.ports[0]")
+func itemPath(prefix string, index int) string {
+	return prefix + "[" + strconv.Itoa(index) + "]"
+}
+
+// walkYAML calls fn for every map value and list item under node with its
+// path, the key node (nil for list items) and the value node. Aliases are
+// followed, and merge keys (<<) add their values to the map that merges them
+// before its own keys are walked.
+func walkYAML(prefix string, node *yaml.Node, fn func(path string, key, value *yaml.Node)) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			walkYAML(prefix, child, fn)
+		}
+	case yaml.AliasNode:
+		walkYAML(prefix, node.Alias, fn)
+	case yaml.MappingNode:
+		for itr := 0; itr+1 < len(node.Content); itr += 2 {
+			if node.Content[itr].Value == "<<" {
+				walkYAML(prefix, node.Content[itr+1], fn)
+			}
+		}
+		for itr := 0; itr+1 < len(node.Content); itr += 2 {
+			key, value := node.Content[itr], node.Content[itr+1]
+			if key.Value == "<<" {
+				continue
+			}
+			path := originPath(prefix, key.Value)
+			fn(path, key, value)
+			walkYAML(path, value, fn)
+		}
+	case yaml.SequenceNode:
+		for itr, item := range node.Content {
+			path := itemPath(prefix, itr)
+			fn(path, nil, item)
+			walkYAML(path, item, fn)
+		}
+	}
+}
+
+// readOrigins returns the origin of every value a config file writes, keyed
+// by path. Explicit keys win over the ones a merge key (<<) adds.
+func readOrigins(configPath, display string) map[string]Origin {
+	origins := make(map[string]Origin)
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return origins
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return origins
+	}
+
+	// Merged values are walked first, so overwriting keeps the explicit ones
+	walkYAML("", &doc, func(path string, key, value *yaml.Node) {
+		line := value.Line
+		if key != nil {
+			line = key.Line
+		}
+		origin := Origin{File: display, Line: line}
+		if value.Kind == yaml.ScalarNode {
+			for _, match := range envVarPattern.FindAllStringSubmatch(value.Value, -1) {
+				origin.Vars = append(origin.Vars, match[1])
+			}
+		}
+		origins[path] = origin
+	})
+	return origins
+}
+
+// displayPath shortens a config file path to one relative to the working
+// directory when it's below it
+func displayPath(path string) string {
+	if !filepath.IsAbs(path) {
+		return path
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// hasPathPrefix returns whether path is prefix or a value under it
+func hasPathPrefix(path, prefix string) bool {
+	rest, found := strings.CutPrefix(path, prefix)
+	return found && (rest == "" || rest[0] == '.' || rest[0] == '[')
+}
+
+// setOrigin records the origin of a path unless it already has one
+func (cfg *Config) setOrigin(path string, origin Origin) {
+	if cfg.origins == nil {
+		cfg.origins = make(map[string]Origin)
+	}
+	if _, exists := cfg.origins[path]; !exists {
+		cfg.origins[path] = origin
+	}
+}
+
+// copyOrigins gives the values under to the origins of the same values under
+// from in another (or the same) config, for values that have none yet
+func (cfg *Config) copyOrigins(other *Config, from, to string) {
+	if other == nil {
+		return
+	}
+	copied := make(map[string]Origin)
+	for path, origin := range other.origins {
+		if hasPathPrefix(path, from) {
+			copied[to+strings.TrimPrefix(path, from)] = origin
+		}
+	}
+	for path, origin := range copied {
+		cfg.setOrigin(path, origin)
+	}
+}
+
+// deriveOrigins records source as the origin of value, found at path, and
+// of everything under it that no file wrote. With replace it also replaces
+// file origins, for values kraze changed.
+func (cfg *Config) deriveOrigins(path string, value interface{}, source string, replace bool) {
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return
+	}
+	origin := Origin{Source: source}
+	set := cfg.setOrigin
+	if replace {
+		set = func(path string, origin Origin) {
+			if cfg.origins == nil {
+				cfg.origins = make(map[string]Origin)
+			}
+			cfg.origins[path] = origin
+		}
+	}
+	set(path, origin)
+	walkYAML(path, &node, func(child string, key, value *yaml.Node) {
+		set(child, origin)
+	})
+}
+
+// OriginOf returns where the value at a path (as in "services.api.namespace")
+// came from
+func (cfg *Config) OriginOf(path string) (Origin, bool) {
+	origin, exists := cfg.origins[path]
+	return origin, exists
+}
+
+// ApplyDefaults sets the service settings kraze defaults when the config
+// leaves them out, so a view of the config shows the values in effect. Wait
+// and its timeout are the defaults of 'kraze up', which its flags change.
+func (cfg *Config) ApplyDefaults() {
+	for _, name := range cfg.sortedServiceNames() {
+		svc := cfg.Services[name]
+		prefix := originPath("services", name)
+		setDefault := func(key string, value interface{}) {
+			cfg.deriveOrigins(originPath(prefix, key), value, "default", false)
+		}
+		if svc.Namespace == "" {
+			svc.Namespace = svc.GetNamespace()
+			setDefault("namespace", svc.Namespace)
+		}
+		if svc.Enabled == nil {
+			enabled := true
+			svc.Enabled = &enabled
+			setDefault("enabled", enabled)
+		}
+		if svc.CreateNamespace == nil {
+			createNamespace := svc.ShouldCreateNamespace()
+			svc.CreateNamespace = &createNamespace
+			setDefault("create_namespace", createNamespace)
+		}
+		if svc.Wait == nil {
+			wait := true
+			svc.Wait = &wait
+			setDefault("wait", wait)
+		}
+		if svc.WaitTimeout == "" {
+			svc.WaitTimeout = "10m"
+			setDefault("wait_timeout", svc.WaitTimeout)
+		}
+		if svc.PostReadyDelay == "" {
+			delay, _ := svc.GetPostReadyDelay()
+			svc.PostReadyDelay = delay.String()
+			setDefault("post_ready_delay", svc.PostReadyDelay)
+		}
+		if svc.PendingTimeout == "" {
+			timeout, _ := svc.GetPendingTimeout()
+			svc.PendingTimeout = timeout.String()
+			setDefault("pending_timeout", svc.PendingTimeout)
+		}
+		if svc.RetryBackoff == "" {
+			svc.RetryBackoff = defaultRetryBackoff.String()
+			setDefault("retry_backoff", svc.RetryBackoff)
+		}
+		if svc.SuspendCronJobs == nil {
+			suspend := cfg.ShouldSuspendCronJobs(&svc)
+			svc.SuspendCronJobs = &suspend
+			// Inherited from the global setting when the config has one
+			if origin, exists := cfg.origins["suspend_cronjobs"]; exists {
+				cfg.setOrigin(originPath(prefix, "suspend_cronjobs"), origin)
+			} else {
+				setDefault("suspend_cronjobs", suspend)
+			}
+		}
+		cfg.Services[name] = svc
+	}
+}
+
+// View renders the config as YAML with secret values masked: values under
+// keys that look like credentials, and the values of variables whose names
+// do. With annotate, each value is followed by a comment saying where it came from.
+func (cfg *Config) View(annotate bool) ([]byte, error) {
+	var doc yaml.Node
+	if err := doc.Encode(cfg); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	walkYAML("", &doc, func(path string, key, value *yaml.Node) {
+		origin, known := cfg.origins[path]
+		if value.Kind == yaml.ScalarNode {
+			if key != nil && secretKeyPattern.MatchString(key.Value) && value.Value != "" {
+				value.Value, value.Style = maskedValue, 0
+			} else if known {
+				value.Value = maskSecretVars(value.Value, origin.Vars)
+			}
+			if key != nil && key.Value == "values_inline" {
+				value.Value = maskInlineValues(value.Value)
+			}
+		}
+		if !annotate {
+			return
+		}
+		comment := "default"
+		if known {
+			comment = origin.String()
+		}
+		// Comments go after the value, or after the key when the value spans
+		// lines. List items that are collections are described by their values.
+		if value.Kind == yaml.ScalarNode && value.Style&(yaml.LiteralStyle|yaml.FoldedStyle) == 0 && !strings.Contains(value.Value, "\n") {
+			value.LineComment = comment
+		} else if key != nil {
+			key.LineComment = comment
+		}
+	})
+
+	data, err := yaml.Marshal(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	return data, nil
+}
+
+// maskInlineValues masks the credentials in a service's values_inline, which
+// is YAML in a string. Values that don't parse are masked whole.
+func maskInlineValues(inline string) string {
+	var values map[string]interface{}
+	if err := yaml.Unmarshal([]byte(inline), &values); err != nil {
+		return maskedValue
+	}
+	if values == nil {
+		return inline
+	}
+	data, err := yaml.Marshal(MaskValues(values))
+	if err != nil {
+		return maskedValue
+	}
+	return string(data)
+}
+
+// maskSecretVars replaces the values of secret-looking variables a value was expanded from
+func maskSecretVars(value string, vars []string) string {
+	names := make([]string, 0, len(vars))
+	for _, name := range vars {
+		if secretKeyPattern.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	// Longer values first, so one that contains another is masked whole
+	sort.Slice(names, func(i, j int) bool {
+		a, _ := (*Variables)(nil).lookup(names[i], true)
+		b, _ := (*Variables)(nil).lookup(names[j], true)
+		return len(a) > len(b)
+	})
+	for _, name := range names {
+		if secret, ok := (*Variables)(nil).lookup(name, true); ok && secret != "" {
+			value = strings.ReplaceAll(value, secret, maskedValue)
+		}
+	}
+	return value
+}
+
+// MaskValues returns a copy of chart values with secret values masked, as View
+// does for the config: values under keys that look like credentials, and the
+// values encrypted (ENC[...]) in any of the SOPS files in encrypted, which
+// appear decrypted in the merged values
+func MaskValues(values map[string]interface{}, encrypted ...map[string]interface{}) map[string]interface{} {
+	sources := make([]interface{}, len(encrypted))
+	for itr, file := range encrypted {
+		sources[itr] = file
+	}
+	masked, _ := maskValue(values, false, sources).(map[string]interface{})
+	return masked
+}
+
+// maskValue masks a value when secret is set or a SOPS file encrypted it,
+// recursing into maps and lists with the SOPS files' values at the same path
+func maskValue(value interface{}, secret bool, encrypted []interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		masked := make(map[string]interface{}, len(typed))
+		for key, child := range typed {
+			var children []interface{}
+			for _, source := range encrypted {
+				if sourceMap, ok := source.(map[string]interface{}); ok {
+					children = append(children, sourceMap[key])
+				}
+			}
+			masked[key] = maskValue(child, secret || secretKeyPattern.MatchString(key), children)
+		}
+		return masked
+	case []interface{}:
+		masked := make([]interface{}, len(typed))
+		for itr, child := range typed {
+			var children []interface{}
+			for _, source := range encrypted {
+				if sourceList, ok := source.([]interface{}); ok && itr < len(sourceList) {
+					children = append(children, sourceList[itr])
+				}
+			}
+			masked[itr] = maskValue(child, secret, children)
+		}
+		return masked
+	case nil:
+		return nil
+	}
+	if secret && fmt.Sprint(value) != "" {
+		return maskedValue
+	}
+	for _, source := range encrypted {
+		if text, ok := source.(string); ok && strings.HasPrefix(text, "ENC[") {
+			return maskedValue
+		}
+	}
+	return value
+}
@@ -0,0 +1,185 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOriginOf(test *testing.T) {
+	test.Setenv("APP_NS", "apps")
+	dir := writeConfigFiles(test, map[string]string{
+		"kraze.yml": `
+cluster:
+  name: dev
+  preset: ingress-dev
+x-common: &common
+  namespace: ${APP_NS}
+  labels:
+    tier: backend
+services:
+  api:
+    <<: *common
+    type: manifests
+    path: ./k8s
+    labels:
+      tier: frontend
+`,
+		"kraze.override.yml": `
+services:
+  cache:
+    type: helm
+    chart: redis
+    repo: https://charts.bitnami.com/bitnami
+`,
+	})
+
+	cfg, err := ParseMultiple([]string{filepath.Join(dir, "kraze.yml"), filepath.Join(dir, "kraze.override.yml")})
+	if err != nil {
+		test.Fatalf("ParseMultiple() error = %v", err)
+	}
+	cfg.ApplyDefaults()
+
+	tests := []struct {
+		name   string
+		path   string
+		file   string
+		line   int
+		vars   []string
+		source string
+	}{
+		{name: "plain value", path: "services.api.type", file: "kraze.yml", line: 12},
+		{name: "merged with a variable", path: "services.api.namespace", file: "kraze.yml", line: 6, vars: []string{"APP_NS"}},
+		{name: "explicit key wins over merge", path: "services.api.labels.tier", file: "kraze.yml", line: 15},
+		{name: "second file", path: "services.cache.chart", file: "kraze.override.yml", line: 5},
+		{name: "preset", path: "cluster.config[0].role", source: "preset ingress-dev"},
+		{name: "default", path: "services.cache.wait_timeout", source: "default"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			origin, found := cfg.OriginOf(tt.path)
+			if !found {
+				test.Fatalf("OriginOf(%q) found no origin", tt.path)
+			}
+			if origin.Source != tt.source {
+				test.Errorf("Source = %q, want %q", origin.Source, tt.source)
+			}
+			if tt.source != "" {
+				return
+			}
+			if filepath.Base(origin.File) != tt.file || origin.Line != tt.line {
+				test.Errorf("origin = %s:%d, want %s:%d", origin.File, origin.Line, tt.file, tt.line)
+			}
+			if !reflect.DeepEqual(origin.Vars, tt.vars) {
+				test.Errorf("Vars = %v, want %v", origin.Vars, tt.vars)
+			}
+		})
+	}
+}
+
+func TestView(test *testing.T) {
+	test.Setenv("DB_PASSWORD", "hunter2")
+	dir := writeConfigFiles(test, map[string]string{
+		"kraze.yml": `
+cluster:
+  name: dev
+services:
+  db:
+    type: helm
+    chart: postgresql
+    repo: https://charts.bitnami.com/bitnami
+    hooks:
+      post_install:
+        - command: 'psql postgres://app:${DB_PASSWORD}@db/app'
+        - job:
+            image: curlimages/curl
+            env:
+              API_TOKEN: plain-secret
+  web:
+    type: helm
+    chart: nginx
+    repo: https://charts.bitnami.com/bitnami
+    values_inline: |
+      replicaCount: 2
+      auth:
+        password: inline-secret
+`,
+	})
+
+	cfg, err := Parse(filepath.Join(dir, "kraze.yml"))
+	if err != nil {
+		test.Fatalf("Parse() error = %v", err)
+	}
+	cfg.ApplyDefaults()
+
+	tests := []struct {
+		name     string
+		annotate bool
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "masked",
+			contains: []string{"API_TOKEN: '" + maskedValue + "'", "app:" + maskedValue + "@db", "password: '" + maskedValue + "'", "replicaCount: 2"},
+			excludes: []string{"plain-secret", "hunter2", "inline-secret", "kraze.yml:"},
+		},
+		{
+			name:     "annotated",
+			annotate: true,
+			contains: []string{"chart: postgresql # ", "kraze.yml:7", "kraze.yml:11 ${DB_PASSWORD}", "wait_timeout: 10m # default"},
+			excludes: []string{"plain-secret", "hunter2", "inline-secret"},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			data, err := cfg.View(tt.annotate)
+			if err != nil {
+				test.Fatalf("View() error = %v", err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(string(data), want) {
+					test.Errorf("View() missing %q in:\n%s", want, data)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(string(data), unwanted) {
+					test.Errorf("View() contains %q in:\n%s", unwanted, data)
+				}
+			}
+		})
+	}
+}
+
+func TestMaskValues(test *testing.T) {
+	values := map[string]interface{}{
+		"replicaCount": 2,
+		"auth":         map[string]interface{}{"username": "app", "password": "hunter2"},
+		"credentials":  map[string]interface{}{"user": "app", "key": "abc"},
+		"database":     map[string]interface{}{"url": "postgres://app:hunter2@db/app", "pool": 5},
+		"hosts":        []interface{}{"a.example.com", "b.example.com"},
+		"apiKey":       "",
+	}
+	// A SOPS file encrypting the database URL but not its pool size (unencrypted_suffix)
+	encrypted := map[string]interface{}{
+		"database": map[string]interface{}{"url": "ENC[AES256_GCM,data:abc,iv:1=,tag:2=,type:str]", "pool": 5},
+		"sops":     map[string]interface{}{"mac": "ENC[AES256_GCM,data:abc,iv:1=,tag:2=,type:str]"},
+	}
+
+	expected := map[string]interface{}{
+		"replicaCount": 2,
+		"auth":         map[string]interface{}{"username": "app", "password": maskedValue},
+		"credentials":  map[string]interface{}{"user": maskedValue, "key": maskedValue},
+		"database":     map[string]interface{}{"url": maskedValue, "pool": 5},
+		"hosts":        []interface{}{"a.example.com", "b.example.com"},
+		"apiKey":       "",
+	}
+	if masked := MaskValues(values, encrypted); !reflect.DeepEqual(masked, expected) {
+		test.Errorf("MaskValues() = %v, want %v", masked, expected)
+	}
+	if values["auth"].(map[string]interface{})["password"] != "hunter2" {
+		test.Error("MaskValues() changed the values it was given")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -11,20 +12,30 @@ import (
 
 // Parse reads and parses a kraze.yml configuration file
 func Parse(configPath string) (*Config, error) {
-	data, err := readAndExpand(configPath)
+	config, err := loadConfigFile(configPath, "", nil)
 	if err != nil {
 		return nil, err
 	}
 
-	var config Config
-	if err := unmarshalConfig(data, &config); err != nil {
-		return nil, err
+	// Expand the kind config file, cluster preset and add-ons before validation
+	// so the file's nodes and add-on services are checked too
+	if err := config.applyKindConfig(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
 	}
-
-	// Set service names from map keys
-	for name, svc := range config.Services {
-		svc.Name = name
-		config.Services[name] = svc
+	if err := config.applyClusterPreset(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if err := config.applyClusterAddons(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if err := config.applyResourcesOverride(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if err := config.applyDiscovery(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if err := config.applyVariant(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
 	// Validate configuration
@@ -32,21 +43,34 @@ func Parse(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
-	// Resolve relative paths
-	if err := config.ResolvePaths(configPath); err != nil {
-		return nil, fmt.Errorf("failed to resolve paths: %w", err)
-	}
-
-	return &config, nil
+	return config, nil
 }
 
-// readAndExpand reads a config file and expands environment variables.
-func readAndExpand(configPath string) ([]byte, error) {
+// readAndExpand reads a config file and expands variables. It returns the
+// variables so that values files can later be expanded the same way.
+func readAndExpand(configPath string) ([]byte, *Variables, error) {
 	data, err := os.ReadFile(configPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	vars := &Variables{ConfigDir: filepath.Dir(configPath)}
+	if absDir, err := filepath.Abs(vars.ConfigDir); err == nil {
+		vars.ConfigDir = absDir
+	}
+
+	// KRAZE_CLUSTER_NAME comes from the file itself, so peek at cluster.name
+	// (which may use other variables) before the real expansion
+	var peek struct {
+		Cluster struct {
+			Name string `yaml:"name"`
+		} `yaml:"cluster"`
 	}
-	return ExpandEnvVarsInBytes(data), nil
+	if err := yaml.Unmarshal([]byte(vars.Expand(string(data))), &peek); err == nil {
+		vars.ClusterName = peek.Cluster.Name
+	}
+
+	return []byte(vars.Expand(string(data))), vars, nil
 }
 
 // unmarshalConfig parses YAML bytes into a Config struct.
@@ -64,6 +88,52 @@ func (cfg *Config) Validate() error {
 		return &ValidationError{Field: "cluster.name", Message: "cluster name is required"}
 	}
 
+	// Validate the tolerated Kubernetes version range against the configured version
+	if _, err := cfg.Cluster.KubernetesConstraint(); err != nil {
+		return &ValidationError{Field: "cluster.kubernetes", Message: err.Error()}
+	}
+	if cfg.Cluster.Version != "" && cfg.Cluster.NodeImage == "" {
+		if err := cfg.Cluster.CheckKubernetesVersion(cfg.Cluster.Version, "cluster.version "+cfg.Cluster.Version); err != nil {
+			return &ValidationError{Field: "cluster.version", Message: err.Error()}
+		}
+	}
+
+	if cfg.Cluster.IsNone() && cfg.Cluster.IsExternal() {
+		return &ValidationError{Field: "cluster.none", Message: "cluster.none and cluster.external are mutually exclusive"}
+	}
+
+	if cfg.Cluster.Impersonate != nil {
+		if err := cfg.Cluster.Impersonate.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for idx := range cfg.Cluster.Config {
+		if err := cfg.Cluster.Config[idx].ValidateReservations(fmt.Sprintf("cluster.config[%d]", idx)); err != nil {
+			return err
+		}
+		if err := cfg.Cluster.Config[idx].ValidatePassthrough(fmt.Sprintf("cluster.config[%d]", idx)); err != nil {
+			return err
+		}
+	}
+	if err := cfg.Cluster.validatePassthrough(); err != nil {
+		return err
+	}
+	if err := cfg.Cluster.validateTopology(); err != nil {
+		return err
+	}
+
+	if cfg.Charts.Repository != "" && !IsOCIURL(cfg.Charts.Repository) {
+		return &ValidationError{Field: "charts.repository", Message: fmt.Sprintf("'%s' must be an oci:// URL", cfg.Charts.Repository)}
+	}
+
+	if cfg.Cluster.RegistryCredentials.IsEnabled() && cfg.Cluster.IsExternal() {
+		return &ValidationError{
+			Field:   "cluster.registry_credentials",
+			Message: "copying registry credentials into nodes is only available for kind clusters, not external clusters",
+		}
+	}
+
 	// Validate GPU config
 	if cfg.Cluster.GPU.IsAnyEnabled() {
 		if cfg.Cluster.IsExternal() {
@@ -74,10 +144,26 @@ func (cfg *Config) Validate() error {
 		}
 	}
 
+	switch cfg.StateBackend {
+	case "", "configmap", "crd":
+	default:
+		return &ValidationError{Field: "state_backend", Message: fmt.Sprintf("unknown state backend '%s' (expected configmap or crd)", cfg.StateBackend)}
+	}
+
+	if err := cfg.validateReadiness(); err != nil {
+		return err
+	}
+	if err := cfg.Events.validate(); err != nil {
+		return err
+	}
+	if err := cfg.validateRegistries(); err != nil {
+		return err
+	}
+
 	// Validate each service
 	for _, svc := range cfg.Services {
 		if err := svc.Validate(); err != nil {
-			return fmt.Errorf("service '%s': %w", svc.Name, err)
+			return &ServiceValidationError{Service: svc.Name, Err: err}
 		}
 	}
 
@@ -116,9 +202,70 @@ func (cfg *Config) ResolvePaths(configPath string) error {
 			}
 		}
 
+		// Resolve the build context; the Dockerfile stays relative to the context
+		if svc.Build != nil && svc.Build.Context != "" && !filepath.IsAbs(svc.Build.Context) {
+			svc.Build.Context = filepath.Join(configDir, svc.Build.Context)
+		}
+
+		// Resolve dev watch paths; the build command runs from the config directory
+		if svc.Dev != nil {
+			for itr, path := range svc.Dev.Watch {
+				if !filepath.IsAbs(path) {
+					svc.Dev.Watch[itr] = filepath.Join(configDir, path)
+				}
+			}
+			svc.Dev.Dir = configDir
+		}
+
+		// Resolve the post-render command if it's a relative path rather than a name
+		// looked up in PATH; it runs from the config directory
+		if svc.PostRender != nil {
+			exec := svc.PostRender.Exec
+			if exec != "" && !filepath.IsAbs(exec) && strings.ContainsAny(exec, `/\`) {
+				svc.PostRender.Exec = filepath.Join(configDir, exec)
+			}
+			svc.PostRender.Dir = configDir
+		}
+
+		// Local hook commands run from the config directory
+		if svc.Hooks != nil {
+			svc.Hooks.setDir(configDir)
+		}
+
+		// Fixture files are read relative to the config file
+		for itr := range svc.Fixtures {
+			svc.Fixtures[itr].resolvePaths(configDir)
+		}
+
+		// Secret files are read relative to the config file
+		for _, secret := range svc.Secrets {
+			for key, source := range secret.Data {
+				if source.File != "" && !filepath.IsAbs(source.File) {
+					source.File = filepath.Join(configDir, source.File)
+					secret.Data[key] = source
+				}
+			}
+		}
+
 		cfg.Services[name] = svc
 	}
 
+	if cfg.Cluster.KindConfig != "" && !filepath.IsAbs(cfg.Cluster.KindConfig) {
+		cfg.Cluster.KindConfig = filepath.Join(configDir, cfg.Cluster.KindConfig)
+	}
+
+	if cfg.Discovery != nil && cfg.Discovery.EnvFile != "" && !filepath.IsAbs(cfg.Discovery.EnvFile) {
+		cfg.Discovery.EnvFile = filepath.Join(configDir, cfg.Discovery.EnvFile)
+	}
+
+	if cfg.Events != nil && cfg.Events.File != "" && !filepath.IsAbs(cfg.Events.File) {
+		cfg.Events.File = filepath.Join(configDir, cfg.Events.File)
+	}
+
+	if creds := cfg.Cluster.RegistryCredentials; creds != nil && creds.Config != "" && !filepath.IsAbs(creds.Config) {
+		creds.Config = filepath.Join(configDir, creds.Config)
+	}
+
 	return nil
 }
 
@@ -137,6 +284,21 @@ func (cfg *Config) GetAllServiceNames() []string {
 	return names
 }
 
+// GetDependents returns the sorted names of services that depend directly on the given service
+func (cfg *Config) GetDependents(name string) []string {
+	var dependents []string
+	for svcName, svc := range cfg.Services {
+		for _, dep := range svc.DependsOn.Names() {
+			if dep == name {
+				dependents = append(dependents, svcName)
+				break
+			}
+		}
+	}
+	sort.Strings(dependents)
+	return dependents
+}
+
 // FilterServices returns services matching the given names
 // If no names provided, returns all services
 func (cfg *Config) FilterServices(names []string) (map[string]ServiceConfig, error) {
@@ -183,7 +345,7 @@ func (cfg *Config) FilterServicesWithDependencies(names []string) (map[string]Se
 		filtered[name] = svc
 
 		// Recursively add dependencies
-		for _, dep := range svc.DependsOn {
+		for _, dep := range svc.DependsOn.Names() {
 			if err := addServiceWithDeps(dep); err != nil {
 				return err
 			}
@@ -231,13 +393,9 @@ func (cfg *Config) FilterServicesByLabels(labelSelectors []string) (map[string]S
 	}
 
 	// Parse label selectors
-	requiredLabels := make(map[string]string)
-	for _, selector := range labelSelectors {
-		parts := strings.SplitN(selector, "=", 2)
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid label selector '%s': must be in format 'key=value'", selector)
-		}
-		requiredLabels[parts[0]] = parts[1]
+	requiredLabels, err := parseLabelSelectors(labelSelectors)
+	if err != nil {
+		return nil, err
 	}
 
 	// Filter services that match all required labels
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -76,8 +77,52 @@ services:
 		test.Fatal("Expected 'api' service")
 	}
 
-	if len(api.DependsOn) != 1 || api.DependsOn[0] != "redis" {
-		test.Errorf("Expected api to depend on redis, got %v", api.DependsOn)
+	if len(api.DependsOn.Names()) != 1 || api.DependsOn.Names()[0] != "redis" {
+		test.Errorf("Expected api to depend on redis, got %v", api.DependsOn.Names())
+	}
+}
+
+func TestParseDependsOnConditions(test *testing.T) {
+	configFile := filepath.Join(test.TempDir(), "kraze.yml")
+	content := `
+cluster:
+  name: test
+services:
+  postgres:
+    type: manifests
+    path: ./postgres
+  redis:
+    type: manifests
+    path: ./redis
+  api:
+    type: manifests
+    path: ./api
+    depends_on:
+      postgres:
+        condition: service_healthy
+        probe: tcp://postgres:5432
+        timeout: 2m
+      redis: {}
+`
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		test.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err := Parse(configFile)
+	if err != nil {
+		test.Fatalf("Failed to parse config: %v", err)
+	}
+
+	api := cfg.Services["api"]
+	if names := api.DependsOn.Names(); len(names) != 2 || names[0] != "postgres" || names[1] != "redis" {
+		test.Errorf("Expected api to depend on [postgres redis], got %v", names)
+	}
+	postgres := api.DependsOn.Condition("postgres")
+	if !postgres.IsHealthCheck() || postgres.Probe != "tcp://postgres:5432" || postgres.Timeout != "2m" {
+		test.Errorf("Unexpected postgres condition: %+v", postgres)
+	}
+	if api.DependsOn.Condition("redis").IsHealthCheck() {
+		test.Error("Expected redis to have no health check")
 	}
 }
 
@@ -107,7 +152,7 @@ func TestFilterServices(test *testing.T) {
 		Services: map[string]ServiceConfig{
 			"redis":    {Name: "redis", Type: "helm"},
 			"postgres": {Name: "postgres", Type: "helm"},
-			"api":      {Name: "api", Type: "manifests", DependsOn: []string{"redis"}},
+			"api":      {Name: "api", Type: "manifests", DependsOn: NewDependsOn("redis")},
 		},
 	}
 
@@ -161,8 +206,8 @@ func TestFilterServicesNoDependencies(test *testing.T) {
 		Services: map[string]ServiceConfig{
 			"redis":    {Name: "redis", Type: "helm"},
 			"postgres": {Name: "postgres", Type: "helm"},
-			"api":      {Name: "api", Type: "manifests", DependsOn: []string{"redis", "postgres"}},
-			"frontend": {Name: "frontend", Type: "manifests", DependsOn: []string{"api"}},
+			"api":      {Name: "api", Type: "manifests", DependsOn: NewDependsOn("redis", "postgres")},
+			"frontend": {Name: "frontend", Type: "manifests", DependsOn: NewDependsOn("api")},
 		},
 	}
 
@@ -194,8 +239,8 @@ func TestFilterServicesNoDependenciesMultiple(test *testing.T) {
 		Services: map[string]ServiceConfig{
 			"redis":    {Name: "redis", Type: "helm"},
 			"postgres": {Name: "postgres", Type: "helm"},
-			"api":      {Name: "api", Type: "manifests", DependsOn: []string{"redis"}},
-			"frontend": {Name: "frontend", Type: "manifests", DependsOn: []string{"api"}},
+			"api":      {Name: "api", Type: "manifests", DependsOn: NewDependsOn("redis")},
+			"frontend": {Name: "frontend", Type: "manifests", DependsOn: NewDependsOn("api")},
 		},
 	}
 
@@ -226,7 +271,7 @@ func TestFilterServicesNoDependenciesEmpty(test *testing.T) {
 	cfg := &Config{
 		Services: map[string]ServiceConfig{
 			"redis": {Name: "redis", Type: "helm"},
-			"api":   {Name: "api", Type: "manifests", DependsOn: []string{"redis"}},
+			"api":   {Name: "api", Type: "manifests", DependsOn: NewDependsOn("redis")},
 		},
 	}
 
@@ -255,6 +300,35 @@ func TestFilterServicesNoDependenciesNonexistent(test *testing.T) {
 	}
 }
 
+func TestGetDependents(test *testing.T) {
+	cfg := &Config{
+		Services: map[string]ServiceConfig{
+			"postgres": {Name: "postgres", Type: "helm"},
+			"worker":   {Name: "worker", Type: "manifests", DependsOn: NewDependsOn("postgres")},
+			"api":      {Name: "api", Type: "manifests", DependsOn: NewDependsOn("postgres", "worker")},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		service  string
+		expected []string
+	}{
+		{name: "multiple dependents", service: "postgres", expected: []string{"api", "worker"}},
+		{name: "single dependent", service: "worker", expected: []string{"api"}},
+		{name: "no dependents", service: "api", expected: nil},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			result := cfg.GetDependents(tt.service)
+			if strings.Join(result, ",") != strings.Join(tt.expected, ",") {
+				test.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
 func TestResolvePaths(test *testing.T) {
 	tmpDir := test.TempDir()
 	configFile := filepath.Join(tmpDir, "kraze.yml")
@@ -276,3 +350,57 @@ func TestResolvePaths(test *testing.T) {
 		test.Errorf("Expected path '%s', got '%s'", expected, cfg.Services["api"].Path)
 	}
 }
+
+func TestResolvePathsDev(test *testing.T) {
+	tmpDir := test.TempDir()
+	configFile := filepath.Join(tmpDir, "kraze.yml")
+	absWatch := filepath.Join(test.TempDir(), "shared")
+
+	cfg := &Config{
+		Services: map[string]ServiceConfig{
+			"api": {
+				Name: "api",
+				Type: "manifests",
+				Path: "./manifests",
+				Dev:  &DevConfig{Watch: []string{"./src", absWatch}, Build: "make image"},
+			},
+		},
+	}
+
+	cfg.ResolvePaths(configFile)
+
+	dev := cfg.Services["api"].Dev
+	if dev.Watch[0] != filepath.Join(tmpDir, "src") {
+		test.Errorf("Expected relative watch path resolved against the config dir, got '%s'", dev.Watch[0])
+	}
+	if dev.Watch[1] != absWatch {
+		test.Errorf("Expected absolute watch path unchanged, got '%s'", dev.Watch[1])
+	}
+	if dev.Dir != tmpDir {
+		test.Errorf("Expected build dir '%s', got '%s'", tmpDir, dev.Dir)
+	}
+}
+
+func TestResolvePathsPostRender(test *testing.T) {
+	tmpDir := test.TempDir()
+	configFile := filepath.Join(tmpDir, "kraze.yml")
+
+	cfg := &Config{
+		Services: map[string]ServiceConfig{
+			"script": {Name: "script", Type: "helm", Path: "./chart", PostRender: &PostRenderConfig{Exec: "./hack/post-render.sh"}},
+			"binary": {Name: "binary", Type: "helm", Path: "./chart", PostRender: &PostRenderConfig{Exec: "kustomize", Args: []string{"build", "."}}},
+		},
+	}
+
+	cfg.ResolvePaths(configFile)
+
+	if exec := cfg.Services["script"].PostRender.Exec; exec != filepath.Join(tmpDir, "hack", "post-render.sh") {
+		test.Errorf("Expected relative exec path resolved against the config dir, got '%s'", exec)
+	}
+	if exec := cfg.Services["binary"].PostRender.Exec; exec != "kustomize" {
+		test.Errorf("Expected command name looked up in PATH unchanged, got '%s'", exec)
+	}
+	if dir := cfg.Services["binary"].PostRender.Dir; dir != tmpDir {
+		test.Errorf("Expected post-render dir '%s', got '%s'", tmpDir, dir)
+	}
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// featureGatePattern matches a Kubernetes feature gate name (e.g., InPlacePodVerticalScaling)
+var featureGatePattern = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+
+// sysctlPattern matches a kernel parameter name (e.g., net.ipv4.ip_forward or
+// net.ipv4.conf.eth0/100.forwarding, where / stands for a dot in a name)
+var sysctlPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[A-Za-z0-9_/-]+)+$`)
+
+// validatePassthrough checks the kind settings kraze passes through to every node
+func (c *ClusterConfig) validatePassthrough() error {
+	for name := range c.FeatureGates {
+		if !featureGatePattern.MatchString(name) {
+			return &ValidationError{Field: "cluster.feature_gates", Message: fmt.Sprintf("'%s' is not a feature gate name (e.g., InPlacePodVerticalScaling)", name)}
+		}
+	}
+	for key := range c.RuntimeConfig {
+		if strings.TrimSpace(key) == "" || strings.ContainsAny(key, "=,") {
+			return &ValidationError{Field: "cluster.runtime_config", Message: fmt.Sprintf("'%s' is not an API group version or resource (e.g., api/alpha)", key)}
+		}
+	}
+	if err := validateKubeadmPatches("cluster.kubeadm_config_patches", c.KubeadmConfigPatches); err != nil {
+		return err
+	}
+	for _, key := range []string{"apiVersion", "kind"} {
+		if _, exists := c.KubeletConfig[key]; exists {
+			return &ValidationError{Field: "cluster.kubelet_config." + key, Message: "is set by kraze; list only KubeletConfiguration fields"}
+		}
+	}
+	return nil
+}
+
+// ValidatePassthrough checks the node's kubelet flags, kubeadm patches and sysctls
+func (node *KindNode) ValidatePassthrough(field string) error {
+	for name := range node.KubeletExtraArgs {
+		if name == "" || strings.HasPrefix(name, "-") {
+			return &ValidationError{Field: field + ".kubeletExtraArgs", Message: fmt.Sprintf("'%s' must be a kubelet flag name without dashes (e.g., max-pods)", name)}
+		}
+	}
+	if err := validateKubeadmPatches(field+".kubeadmConfigPatches", node.KubeadmConfigPatches); err != nil {
+		return err
+	}
+	for name, value := range node.Sysctls {
+		if !sysctlPattern.MatchString(name) {
+			return &ValidationError{Field: field + ".sysctls", Message: fmt.Sprintf("'%s' is not a kernel parameter name (e.g., fs.inotify.max_user_watches)", name)}
+		}
+		if strings.TrimSpace(value) == "" || strings.ContainsAny(value, "\n\r") {
+			return &ValidationError{Field: field + ".sysctls." + name, Message: "must be a single-line value"}
+		}
+	}
+	return nil
+}
+
+// validateKubeadmPatches checks that each patch is a YAML document naming the
+// kubeadm kind it patches, which kind needs to match it
+func validateKubeadmPatches(field string, patches []string) error {
+	for itr, patch := range patches {
+		var doc map[string]interface{}
+		if err := yaml.Unmarshal([]byte(patch), &doc); err != nil {
+			return &ValidationError{Field: fmt.Sprintf("%s[%d]", field, itr), Message: fmt.Sprintf("invalid YAML: %v", err)}
+		}
+		if kind, _ := doc["kind"].(string); kind == "" {
+			return &ValidationError{Field: fmt.Sprintf("%s[%d]", field, itr), Message: "must set kind (e.g., ClusterConfiguration, InitConfiguration, JoinConfiguration or KubeletConfiguration)"}
+		}
+		if message := kubeletExtraArgsFormError(doc); message != "" {
+			return &ValidationError{Field: fmt.Sprintf("%s[%d].nodeRegistration.kubeletExtraArgs", field, itr), Message: message}
+		}
+	}
+	return nil
+}
+
+// kubeletExtraArgsFormError checks a versioned patch sets kubeletExtraArgs in
+// the form of its kubeadm API version: a map up to v1beta3, a list of
+// name/value pairs from v1beta4. Patches without apiVersion are converted by
+// kind, so either form works for them.
+func kubeletExtraArgsFormError(doc map[string]interface{}) string {
+	registration, _ := doc["nodeRegistration"].(map[string]interface{})
+	args, exists := registration["kubeletExtraArgs"]
+	if !exists {
+		return ""
+	}
+	apiVersion, _ := doc["apiVersion"].(string)
+	_, isList := args.([]interface{})
+	switch {
+	case apiVersion == "kubeadm.k8s.io/v1beta4" && !isList:
+		return "kubeadm v1beta4 takes a list of name/value pairs (e.g., [{name: max-pods, value: \"250\"}])"
+	case strings.HasPrefix(apiVersion, "kubeadm.k8s.io/") && apiVersion != "kubeadm.k8s.io/v1beta4" && isList:
+		return fmt.Sprintf("kubeadm %s takes a map of flags (e.g., {max-pods: \"250\"})", strings.TrimPrefix(apiVersion, "kubeadm.k8s.io/"))
+	}
+	return ""
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidatePassthrough(test *testing.T) {
+	tests := []struct {
+		name    string
+		cluster ClusterConfig
+		wantErr string
+	}{
+		{
+			name: "cluster settings",
+			cluster: ClusterConfig{
+				FeatureGates:         map[string]bool{"InPlacePodVerticalScaling": true},
+				RuntimeConfig:        map[string]string{"api/alpha": "true"},
+				KubeadmConfigPatches: []string{"kind: ClusterConfiguration\napiServer:\n  extraArgs:\n    v: \"4\"\n"},
+				KubeletConfig:        map[string]interface{}{"maxPods": 250},
+			},
+		},
+		{
+			name: "node settings",
+			cluster: ClusterConfig{Config: []KindNode{{
+				Role:                 "worker",
+				KubeletExtraArgs:     map[string]string{"max-pods": "250"},
+				KubeadmConfigPatches: []string{"kind: JoinConfiguration\nnodeRegistration:\n  taints: []\n"},
+				Sysctls:              map[string]string{"fs.inotify.max_user_watches": "524288", "net.ipv4.conf.eth0/100.forwarding": "1"},
+			}}},
+		},
+		{
+			name:    "feature gate name",
+			cluster: ClusterConfig{FeatureGates: map[string]bool{"ephemeral containers": true}},
+			wantErr: "not a feature gate name",
+		},
+		{
+			name:    "runtime config key",
+			cluster: ClusterConfig{RuntimeConfig: map[string]string{"api/alpha=true": "true"}},
+			wantErr: "not an API group version",
+		},
+		{
+			name:    "patch without kind",
+			cluster: ClusterConfig{KubeadmConfigPatches: []string{"apiServer:\n  extraArgs: {}\n"}},
+			wantErr: "must set kind",
+		},
+		{
+			name:    "invalid patch",
+			cluster: ClusterConfig{Config: []KindNode{{Role: "worker", KubeadmConfigPatches: []string{"kind: [JoinConfiguration"}}}},
+			wantErr: "invalid YAML",
+		},
+		{
+			name:    "v1beta4 patch with map kubeletExtraArgs",
+			cluster: ClusterConfig{Config: []KindNode{{Role: "worker", KubeadmConfigPatches: []string{"apiVersion: kubeadm.k8s.io/v1beta4\nkind: JoinConfiguration\nnodeRegistration:\n  kubeletExtraArgs:\n    max-pods: \"250\"\n"}}}},
+			wantErr: "list of name/value pairs",
+		},
+		{
+			name:    "v1beta3 patch with list kubeletExtraArgs",
+			cluster: ClusterConfig{KubeadmConfigPatches: []string{"apiVersion: kubeadm.k8s.io/v1beta3\nkind: InitConfiguration\nnodeRegistration:\n  kubeletExtraArgs:\n  - name: max-pods\n    value: \"250\"\n"}},
+			wantErr: "v1beta3 takes a map",
+		},
+		{
+			name:    "kubelet config kind",
+			cluster: ClusterConfig{KubeletConfig: map[string]interface{}{"kind": "KubeletConfiguration"}},
+			wantErr: "is set by kraze",
+		},
+		{
+			name:    "kubelet flag with dashes",
+			cluster: ClusterConfig{Config: []KindNode{{Role: "worker", KubeletExtraArgs: map[string]string{"--max-pods": "250"}}}},
+			wantErr: "without dashes",
+		},
+		{
+			name:    "sysctl name",
+			cluster: ClusterConfig{Config: []KindNode{{Role: "worker", Sysctls: map[string]string{"max_user_watches": "524288"}}}},
+			wantErr: "not a kernel parameter name",
+		},
+		{
+			name:    "multi-line sysctl value",
+			cluster: ClusterConfig{Config: []KindNode{{Role: "worker", Sysctls: map[string]string{"vm.max_map_count": "262144\nkernel.panic = 1"}}}},
+			wantErr: "single-line value",
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			err := tt.cluster.validatePassthrough()
+			for idx := range tt.cluster.Config {
+				if err == nil {
+					err = tt.cluster.Config[idx].ValidatePassthrough("cluster.config[0]")
+				}
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					test.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				test.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
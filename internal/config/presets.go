@@ -0,0 +1,206 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ClusterPreset is a named cluster template shipped with kraze. It expands to
+// a node layout and optional add-on services. Explicit cluster fields and
+// services in the config always take precedence over the preset.
+type ClusterPreset struct {
+	Name        string
+	Description string
+	Nodes       []KindNode
+	Addons      []string // Built-in add-ons enabled by the preset (see ClusterAddons)
+}
+
+// clusterPresets are the built-in presets, keyed by name
+var clusterPresets = map[string]ClusterPreset{
+	"multi-node": {
+		Name:        "multi-node",
+		Description: "1 control-plane node and 2 worker nodes",
+		Nodes: []KindNode{
+			{Role: "control-plane"},
+			{Role: "worker", Replicas: 2},
+		},
+	},
+	"ha-3node": {
+		Name:        "ha-3node",
+		Description: "3 control-plane nodes (highly available API server) and 1 worker node",
+		Nodes: []KindNode{
+			{Role: "control-plane", Replicas: 3},
+			{Role: "worker"},
+		},
+	},
+	"ingress-dev": {
+		Name:        "ingress-dev",
+		Description: "single node with host ports 80/443 mapped and ingress-nginx installed",
+		Nodes: []KindNode{
+			{
+				Role: "control-plane",
+				ExtraPortMappings: []PortMapping{
+					{ContainerPort: 80, HostPort: 80, Protocol: "TCP"},
+					{ContainerPort: 443, HostPort: 443, Protocol: "TCP"},
+				},
+				Labels: map[string]string{"ingress-ready": "true"},
+			},
+		},
+		Addons: []string{"ingress-nginx"},
+	},
+}
+
+// ClusterPresets returns all built-in cluster presets sorted by name
+func ClusterPresets() []ClusterPreset {
+	names := make([]string, 0, len(clusterPresets))
+	for name := range clusterPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	presets := make([]ClusterPreset, 0, len(names))
+	for _, name := range names {
+		presets = append(presets, clusterPresets[name])
+	}
+	return presets
+}
+
+// GetClusterPreset returns the built-in preset with the given name
+func GetClusterPreset(name string) (ClusterPreset, bool) {
+	preset, exists := clusterPresets[name]
+	return preset, exists
+}
+
+// applyClusterPreset expands cluster.preset into the cluster node layout.
+// Explicit node settings override the preset per role. The preset's add-ons
+// are added by applyClusterAddons.
+func (cfg *Config) applyClusterPreset() error {
+	if cfg.Cluster.Preset == "" {
+		return nil
+	}
+
+	preset, exists := GetClusterPreset(cfg.Cluster.Preset)
+	if !exists {
+		names := make([]string, 0, len(clusterPresets))
+		for _, p := range ClusterPresets() {
+			names = append(names, p.Name)
+		}
+		return &ValidationError{
+			Field:   "cluster.preset",
+			Message: fmt.Sprintf("unknown preset '%s' (available: %s)", cfg.Cluster.Preset, strings.Join(names, ", ")),
+		}
+	}
+
+	if cfg.Cluster.IsExternal() {
+		return &ValidationError{
+			Field:   "cluster.preset",
+			Message: "cluster presets are only available for kind clusters, not external clusters",
+		}
+	}
+
+	cfg.Cluster.Config = overlayKindNodes(preset.Nodes, cfg.Cluster.Config)
+	cfg.deriveOrigins("cluster.config", cfg.Cluster.Config, "preset "+preset.Name, false)
+
+	return nil
+}
+
+// overlayKindNodes merges explicit node settings over preset nodes per role.
+// Explicit replicas win, and explicit port mappings, mounts, labels, kubelet
+// reservations, flags and sysctls replace preset entries with the same key.
+// Explicit kubeadm patches are added to the preset's. Roles not in the preset
+// are appended.
+func overlayKindNodes(preset, explicit []KindNode) []KindNode {
+	result := make([]KindNode, 0, len(preset)+len(explicit))
+	byRole := make(map[string]int, len(preset))
+	for _, node := range preset {
+		byRole[node.Role] = len(result)
+		result = append(result, KindNode{
+			Role:                 node.Role,
+			Replicas:             node.Replicas,
+			ExtraPortMappings:    append([]PortMapping(nil), node.ExtraPortMappings...),
+			ExtraMounts:          append([]Mount(nil), node.ExtraMounts...),
+			Labels:               copyStringMap(node.Labels),
+			SystemReserved:       copyStringMap(node.SystemReserved),
+			KubeReserved:         copyStringMap(node.KubeReserved),
+			EvictionHard:         copyStringMap(node.EvictionHard),
+			KubeletExtraArgs:     copyStringMap(node.KubeletExtraArgs),
+			Sysctls:              copyStringMap(node.Sysctls),
+			KubeadmConfigPatches: append([]string(nil), node.KubeadmConfigPatches...),
+		})
+	}
+
+	for _, node := range explicit {
+		idx, exists := byRole[node.Role]
+		if !exists {
+			result = append(result, node)
+			continue
+		}
+		base := &result[idx]
+
+		if node.Replicas != 0 {
+			base.Replicas = node.Replicas
+		}
+
+		for _, pm := range node.ExtraPortMappings {
+			replaced := false
+			for itr, existing := range base.ExtraPortMappings {
+				if existing.ContainerPort == pm.ContainerPort && strings.EqualFold(existing.Protocol, pm.Protocol) {
+					base.ExtraPortMappings[itr] = pm
+					replaced = true
+					break
+				}
+			}
+			if !replaced {
+				base.ExtraPortMappings = append(base.ExtraPortMappings, pm)
+			}
+		}
+
+		for _, mount := range node.ExtraMounts {
+			replaced := false
+			for itr, existing := range base.ExtraMounts {
+				if existing.ContainerPath == mount.ContainerPath {
+					base.ExtraMounts[itr] = mount
+					replaced = true
+					break
+				}
+			}
+			if !replaced {
+				base.ExtraMounts = append(base.ExtraMounts, mount)
+			}
+		}
+
+		base.Labels = overlayStringMap(base.Labels, node.Labels)
+		base.SystemReserved = overlayStringMap(base.SystemReserved, node.SystemReserved)
+		base.KubeReserved = overlayStringMap(base.KubeReserved, node.KubeReserved)
+		base.EvictionHard = overlayStringMap(base.EvictionHard, node.EvictionHard)
+		base.KubeletExtraArgs = overlayStringMap(base.KubeletExtraArgs, node.KubeletExtraArgs)
+		base.Sysctls = overlayStringMap(base.Sysctls, node.Sysctls)
+		base.KubeadmConfigPatches = unionStrings(base.KubeadmConfigPatches, node.KubeadmConfigPatches)
+	}
+
+	return result
+}
+
+// overlayStringMap sets the entries of explicit over base, allocating base if needed
+func overlayStringMap(base, explicit map[string]string) map[string]string {
+	for key, value := range explicit {
+		if base == nil {
+			base = make(map[string]string)
+		}
+		base[key] = value
+	}
+	return base
+}
+
+// copyStringMap returns a shallow copy of a string map (nil stays nil)
+func copyStringMap(src map[string]string) map[string]string {
+	if src == nil {
+		return nil
+	}
+	dst := make(map[string]string, len(src))
+	for key, value := range src {
+		dst[key] = value
+	}
+	return dst
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseClusterPreset(test *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expectError string
+		check       func(test *testing.T, cfg *Config)
+	}{
+		{
+			name: "ha-3node expands node layout",
+			content: `cluster:
+  name: dev
+  preset: ha-3node
+services: {}
+`,
+			check: func(test *testing.T, cfg *Config) {
+				if len(cfg.Cluster.Config) != 2 {
+					test.Fatalf("expected 2 node roles, got %d", len(cfg.Cluster.Config))
+				}
+				if cfg.Cluster.Config[0].Role != "control-plane" || cfg.Cluster.Config[0].Replicas != 3 {
+					test.Errorf("expected 3 control-plane replicas, got %+v", cfg.Cluster.Config[0])
+				}
+			},
+		},
+		{
+			name: "ingress-dev adds addon and merges explicit ports",
+			content: `cluster:
+  name: dev
+  preset: ingress-dev
+  config:
+    - role: control-plane
+      extraPortMappings:
+        - containerPort: 443
+          hostPort: 8443
+        - containerPort: 30080
+          hostPort: 8080
+services:
+  app:
+    type: manifests
+    path: app.yaml
+    depends_on: [ingress-nginx]
+`,
+			check: func(test *testing.T, cfg *Config) {
+				addon, exists := cfg.Services["ingress-nginx"]
+				if !exists {
+					test.Fatalf("expected ingress-nginx add-on service")
+				}
+				if addon.Name != "ingress-nginx" || !addon.IsHelm() {
+					test.Errorf("unexpected add-on: %+v", addon)
+				}
+
+				ports := map[int32]int32{}
+				for _, pm := range cfg.Cluster.Config[0].ExtraPortMappings {
+					ports[pm.ContainerPort] = pm.HostPort
+				}
+				expected := map[int32]int32{80: 80, 443: 8443, 30080: 8080}
+				for containerPort, hostPort := range expected {
+					if ports[containerPort] != hostPort {
+						test.Errorf("containerPort %d: expected hostPort %d, got %d", containerPort, hostPort, ports[containerPort])
+					}
+				}
+				if cfg.Cluster.Config[0].Labels["ingress-ready"] != "true" {
+					test.Errorf("expected ingress-ready label from preset")
+				}
+			},
+		},
+		{
+			name: "explicit service replaces addon",
+			content: `cluster:
+  name: dev
+  preset: ingress-dev
+services:
+  ingress-nginx:
+    type: manifests
+    path: ingress.yaml
+`,
+			check: func(test *testing.T, cfg *Config) {
+				svc := cfg.Services["ingress-nginx"]
+				if !svc.IsManifests() {
+					test.Errorf("expected explicit ingress-nginx service to win over the add-on")
+				}
+			},
+		},
+		{
+			name: "unknown preset",
+			content: `cluster:
+  name: dev
+  preset: huge
+`,
+			expectError: "unknown preset 'huge'",
+		},
+		{
+			name: "external cluster",
+			content: `cluster:
+  name: dev
+  preset: multi-node
+  external:
+    enabled: true
+`,
+			expectError: "only available for kind clusters",
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			configFile := filepath.Join(test.TempDir(), "kraze.yml")
+			if err := os.WriteFile(configFile, []byte(tt.content), 0644); err != nil {
+				test.Fatalf("failed to write config: %v", err)
+			}
+
+			cfg, err := Parse(configFile)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					test.Fatalf("expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				test.Fatalf("unexpected error: %v", err)
+			}
+			tt.check(test, cfg)
+		})
+	}
+}
+
+func TestApplyClusterPresetDoesNotMutatePreset(test *testing.T) {
+	cfg := &Config{Cluster: ClusterConfig{
+		Name:   "dev",
+		Preset: "ingress-dev",
+		Config: []KindNode{{Role: "control-plane", Labels: map[string]string{"tier": "edge"}}},
+	}}
+	if err := cfg.applyClusterPreset(); err != nil {
+		test.Fatalf("unexpected error: %v", err)
+	}
+
+	preset, _ := GetClusterPreset("ingress-dev")
+	if _, exists := preset.Nodes[0].Labels["tier"]; exists {
+		test.Errorf("applying a preset must not modify the built-in preset")
+	}
+}
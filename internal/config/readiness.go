@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ReadinessRule tells the wait engine when resources of a kind are ready, for
+// operators whose custom resources don't report the conditions kstatus reads
+// (Ready, Reconciling, Stalled). Either a condition or a JSONPath is checked.
+type ReadinessRule struct {
+	Condition string   `yaml:"condition,omitempty"` // Ready when status.conditions[type=<condition>] has the wanted status
+	Status    string   `yaml:"status,omitempty"`    // Wanted condition status (default: "True")
+	JSONPath  string   `yaml:"jsonpath,omitempty"`  // Field checked instead of a condition (e.g., .status.phase)
+	Value     string   `yaml:"value,omitempty"`     // Ready when the field has this value
+	Failed    []string `yaml:"failed,omitempty"`    // Field values (or condition reasons) meaning the resource failed and won't become ready
+}
+
+// GetStatus returns the condition status the rule waits for
+func (rule ReadinessRule) GetStatus() string {
+	if rule.Status != "" {
+		return rule.Status
+	}
+	return "True"
+}
+
+// ReadinessKey returns the key of readiness rules for a kind: Kind.group, or
+// just Kind for the core group
+func ReadinessKey(kind, group string) string {
+	if group == "" {
+		return kind
+	}
+	return kind + "." + group
+}
+
+// validateReadiness checks each readiness rule names a kind and checks one thing
+func (cfg *Config) validateReadiness() error {
+	keys := make([]string, 0, len(cfg.Readiness))
+	for key := range cfg.Readiness {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		rule := cfg.Readiness[key]
+		field := "readiness." + key
+		kind, _, _ := strings.Cut(key, ".")
+		if kind == "" || strings.ToUpper(kind[:1]) != kind[:1] {
+			return &ValidationError{Field: field, Message: "key must be Kind.group (e.g., Kafka.kafka.strimzi.io), or Kind for the core group"}
+		}
+		switch {
+		case rule.Condition != "" && rule.JSONPath != "":
+			return &ValidationError{Field: field, Message: "condition and jsonpath are mutually exclusive"}
+		case rule.Condition != "":
+			if rule.Value != "" {
+				return &ValidationError{Field: field + ".value", Message: "value is only used with jsonpath, use status for conditions"}
+			}
+		case rule.JSONPath != "":
+			if _, err := ParseFieldPath(rule.JSONPath); err != nil {
+				return &ValidationError{Field: field + ".jsonpath", Message: fmt.Sprintf("invalid JSONPath: %v", err)}
+			}
+			if rule.Value == "" {
+				return &ValidationError{Field: field + ".value", Message: "value is required with jsonpath"}
+			}
+			if rule.Status != "" {
+				return &ValidationError{Field: field + ".status", Message: "status is only used with condition"}
+			}
+			if slices.Contains(rule.Failed, rule.Value) {
+				return &ValidationError{Field: field + ".failed", Message: fmt.Sprintf("'%s' can't be both the ready and a failed value", rule.Value)}
+			}
+		default:
+			return &ValidationError{Field: field, Message: "either condition or jsonpath is required"}
+		}
+	}
+	return nil
+}
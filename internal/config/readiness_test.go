@@ -0,0 +1,65 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateReadiness(test *testing.T) {
+	tests := []struct {
+		name      string
+		readiness string
+		wantErr   string
+	}{
+		{
+			name: "condition and jsonpath rules",
+			readiness: `  Kafka.kafka.strimzi.io:
+    condition: Ready
+    failed: [NotReady]
+  Database.example.com:
+    jsonpath: .status.phase
+    value: Running
+    failed: [Failed]
+  Service:
+    jsonpath: '{.spec.clusterIP}'
+    value: None
+`,
+		},
+		{name: "lower case kind", readiness: "  kafka.kafka.strimzi.io:\n    condition: Ready\n", wantErr: "key must be Kind.group"},
+		{name: "nothing to check", readiness: "  Kafka.kafka.strimzi.io:\n    status: \"True\"\n", wantErr: "either condition or jsonpath"},
+		{name: "both", readiness: "  Kafka.kafka.strimzi.io:\n    condition: Ready\n    jsonpath: .status.phase\n", wantErr: "mutually exclusive"},
+		{name: "jsonpath without value", readiness: "  Database.example.com:\n    jsonpath: .status.phase\n", wantErr: "value is required"},
+		{name: "invalid jsonpath", readiness: "  Database.example.com:\n    jsonpath: '{.status[}'\n    value: Running\n", wantErr: "invalid JSONPath"},
+		{name: "value with condition", readiness: "  Kafka.kafka.strimzi.io:\n    condition: Ready\n    value: \"True\"\n", wantErr: "use status for conditions"},
+		{name: "ready value failed", readiness: "  Database.example.com:\n    jsonpath: .status.phase\n    value: Running\n    failed: [Running]\n", wantErr: "both the ready and a failed value"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			dir := writeConfigFiles(test, map[string]string{"kraze.yml": "cluster:\n  name: dev\nservices: {}\nreadiness:\n" + tt.readiness})
+			cfg, err := Parse(filepath.Join(dir, "kraze.yml"))
+			if tt.wantErr == "" {
+				if err != nil {
+					test.Fatalf("Parse() error = %v", err)
+				}
+				if cfg.Readiness["Kafka.kafka.strimzi.io"].GetStatus() != "True" {
+					test.Errorf("Expected the condition status to default to True")
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				test.Errorf("Parse() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadinessKey(test *testing.T) {
+	if got := ReadinessKey("Kafka", "kafka.strimzi.io"); got != "Kafka.kafka.strimzi.io" {
+		test.Errorf("ReadinessKey() = %q", got)
+	}
+	if got := ReadinessKey("Service", ""); got != "Service" {
+		test.Errorf("ReadinessKey() = %q", got)
+	}
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Why a service is part of a run
+const (
+	ReasonAll        = "all"        // No services were selected, so all of them run
+	ReasonRequested  = "requested"  // Named on the command line
+	ReasonLabel      = "label"      // Matched the label selectors
+	ReasonDependency = "dependency" // Pulled in by a selected service that depends on it
+)
+
+// SelectionReason explains why a service is part of a run
+type SelectionReason struct {
+	Kind         string   `json:"kind"`
+	Labels       []string `json:"labels,omitempty"`        // Label selectors the service matched
+	DependencyOf []string `json:"dependency_of,omitempty"` // Services of the run that depend on it directly
+}
+
+func (reason SelectionReason) String() string {
+	switch reason.Kind {
+	case ReasonAll:
+		return "all services"
+	case ReasonLabel:
+		return "label match (" + strings.Join(reason.Labels, ", ") + ")"
+	case ReasonDependency:
+		return "dependency of " + strings.Join(reason.DependencyOf, ", ")
+	}
+	return reason.Kind
+}
+
+// SelectionReasons returns why each selected service is part of a run that
+// selected the given service names or label selectors, plus their dependencies
+func SelectionReasons(selected map[string]ServiceConfig, names, labelSelectors []string) (map[string]SelectionReason, error) {
+	requiredLabels, err := parseLabelSelectors(labelSelectors)
+	if err != nil {
+		return nil, err
+	}
+	requested := make(map[string]bool, len(names))
+	for _, name := range names {
+		requested[name] = true
+	}
+
+	reasons := make(map[string]SelectionReason, len(selected))
+	for name, svc := range selected {
+		switch {
+		case len(names) == 0 && len(labelSelectors) == 0:
+			reasons[name] = SelectionReason{Kind: ReasonAll}
+		case requested[name]:
+			reasons[name] = SelectionReason{Kind: ReasonRequested}
+		case len(labelSelectors) > 0 && matchesLabels(svc.Labels, requiredLabels):
+			reasons[name] = SelectionReason{Kind: ReasonLabel, Labels: labelSelectors}
+		default:
+			var dependents []string
+			for dependent, dependentSvc := range selected {
+				for _, dep := range dependentSvc.DependsOn.Names() {
+					if dep == name {
+						dependents = append(dependents, dependent)
+						break
+					}
+				}
+			}
+			sort.Strings(dependents)
+			reasons[name] = SelectionReason{Kind: ReasonDependency, DependencyOf: dependents}
+		}
+	}
+	return reasons, nil
+}
+
+// parseLabelSelectors parses key=value label selectors
+func parseLabelSelectors(labelSelectors []string) (map[string]string, error) {
+	requiredLabels := make(map[string]string, len(labelSelectors))
+	for _, selector := range labelSelectors {
+		parts := strings.SplitN(selector, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid label selector '%s': must be in format 'key=value'", selector)
+		}
+		if err := validateLabel(parts[0], parts[1]); err != nil {
+			return nil, fmt.Errorf("invalid label selector '%s': %w", selector, err)
+		}
+		requiredLabels[parts[0]] = parts[1]
+	}
+	return requiredLabels, nil
+}
+
+// validateLabel checks a label's key and value follow the Kubernetes syntax
+func validateLabel(key, value string) error {
+	if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+		return fmt.Errorf("invalid label key '%s': %s", key, strings.Join(errs, "; "))
+	}
+	if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+		return fmt.Errorf("invalid label value '%s': %s", value, strings.Join(errs, "; "))
+	}
+	return nil
+}
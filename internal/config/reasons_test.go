@@ -0,0 +1,64 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSelectionReasons(test *testing.T) {
+	cfg := &Config{Services: map[string]ServiceConfig{
+		"postgres": {Name: "postgres", Labels: map[string]string{"tier": "data"}},
+		"redis":    {Name: "redis"},
+		"api":      {Name: "api", Labels: map[string]string{"tier": "backend"}, DependsOn: NewDependsOn("postgres", "redis")},
+		"worker":   {Name: "worker", Labels: map[string]string{"tier": "backend"}, DependsOn: NewDependsOn("redis")},
+	}}
+
+	tests := []struct {
+		name     string
+		names    []string
+		labels   []string
+		expected map[string]string
+	}{
+		{
+			name:     "everything",
+			expected: map[string]string{"postgres": "all services", "redis": "all services", "api": "all services", "worker": "all services"},
+		},
+		{
+			name:     "requested with dependencies",
+			names:    []string{"api"},
+			expected: map[string]string{"api": "requested", "postgres": "dependency of api", "redis": "dependency of api"},
+		},
+		{
+			name:     "label match",
+			labels:   []string{"tier=backend"},
+			expected: map[string]string{"api": "label match (tier=backend)", "worker": "label match (tier=backend)", "postgres": "dependency of api", "redis": "dependency of api, worker"},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			var selected map[string]ServiceConfig
+			var err error
+			if len(tt.labels) > 0 {
+				selected, err = cfg.FilterServicesByLabelsWithDependencies(tt.labels)
+			} else {
+				selected, err = cfg.FilterServicesWithDependencies(tt.names)
+			}
+			if err != nil {
+				test.Fatal(err)
+			}
+
+			reasons, err := SelectionReasons(selected, tt.names, tt.labels)
+			if err != nil {
+				test.Fatalf("SelectionReasons() error = %v", err)
+			}
+			got := make(map[string]string, len(reasons))
+			for name, reason := range reasons {
+				got[name] = reason.String()
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				test.Errorf("SelectionReasons() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// RegistryConfig holds the credentials of a private OCI registry. Helm uses
+// them to pull and push charts, 'kraze images pull' to pull images and the
+// kubelets of kind nodes to pull pod images, so none needs a prior
+// 'helm registry login' or 'docker login'.
+type RegistryConfig struct {
+	Host            string `yaml:"host"`                       // Registry host (e.g., ghcr.io, 123456789012.dkr.ecr.us-east-1.amazonaws.com)
+	Username        string `yaml:"username"`                   // Username (e.g., AWS for ECR, 00000000-0000-0000-0000-000000000000 for ACR tokens)
+	Password        string `yaml:"password,omitempty"`         // Password or token, usually ${ENV_VAR}
+	PasswordCommand string `yaml:"password_command,omitempty"` // Shell command printing the password (e.g., aws ecr get-login-password)
+}
+
+// NormalizeRegistryHost returns the host of a registry name, image reference
+// or Docker config server key, treating the Docker Hub aliases as docker.io
+func NormalizeRegistryHost(server string) string {
+	host := strings.TrimPrefix(strings.TrimPrefix(server, "https://"), "http://")
+	host = strings.TrimPrefix(host, "oci://")
+	host, _, _ = strings.Cut(host, "/")
+	switch host {
+	case "index.docker.io", "registry-1.docker.io", "registry.hub.docker.com":
+		return "docker.io"
+	}
+	return host
+}
+
+// ImageRegistryHost returns the registry host of an image reference, which
+// is docker.io for references without one (e.g., postgres:16, bitnami/redis)
+func ImageRegistryHost(image string) string {
+	first, _, found := strings.Cut(image, "/")
+	if !found || !strings.ContainsAny(first, ".:") && first != "localhost" {
+		return "docker.io"
+	}
+	return NormalizeRegistryHost(first)
+}
+
+// FindRegistry returns the registry configured for a host, or nil
+func FindRegistry(registries []RegistryConfig, host string) *RegistryConfig {
+	host = NormalizeRegistryHost(host)
+	for itr := range registries {
+		if NormalizeRegistryHost(registries[itr].Host) == host {
+			return &registries[itr]
+		}
+	}
+	return nil
+}
+
+// registryPasswords caches the output of password commands, which often call
+// cloud APIs, for the rest of the run
+var (
+	registryPasswordsMu sync.Mutex
+	registryPasswords   = make(map[string]string)
+)
+
+// ResolvePassword returns the registry's password, running its password
+// command on first use
+func (reg *RegistryConfig) ResolvePassword(ctx context.Context) (string, error) {
+	if reg.PasswordCommand == "" {
+		if reg.Password == "" {
+			return "", fmt.Errorf("registry '%s' has an empty password (is its environment variable set?)", reg.Host)
+		}
+		return reg.Password, nil
+	}
+
+	registryPasswordsMu.Lock()
+	defer registryPasswordsMu.Unlock()
+	if password, ok := registryPasswords[reg.PasswordCommand]; ok {
+		return password, nil
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", reg.PasswordCommand)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", reg.PasswordCommand)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("password command of registry '%s' failed: %s", reg.Host, msg)
+		}
+		return "", fmt.Errorf("password command of registry '%s' failed: %w", reg.Host, err)
+	}
+	password := strings.TrimSpace(string(out))
+	if password == "" {
+		return "", fmt.Errorf("password command of registry '%s' printed nothing", reg.Host)
+	}
+	registryPasswords[reg.PasswordCommand] = password
+	return password, nil
+}
+
+// validateRegistries checks each registry names a host once and has a way to
+// get its password. Empty passwords are only reported when they're used, so
+// configs with unset token variables still work for everything else.
+func (cfg *Config) validateRegistries() error {
+	seen := make(map[string]bool)
+	for itr, reg := range cfg.Registries {
+		field := fmt.Sprintf("registries[%d]", itr)
+		if reg.Host == "" {
+			return &ValidationError{Field: field + ".host", Message: "host is required"}
+		}
+		if strings.Contains(reg.Host, "://") || strings.Contains(reg.Host, "/") {
+			return &ValidationError{Field: field + ".host", Message: fmt.Sprintf("'%s' must be a registry host without a scheme or path (e.g., ghcr.io)", reg.Host)}
+		}
+		host := NormalizeRegistryHost(reg.Host)
+		if seen[host] {
+			return &ValidationError{Field: field + ".host", Message: fmt.Sprintf("duplicate registry '%s'", host)}
+		}
+		seen[host] = true
+		if reg.Username == "" {
+			return &ValidationError{Field: field + ".username", Message: "username is required"}
+		}
+		if reg.Password != "" && reg.PasswordCommand != "" {
+			return &ValidationError{Field: field, Message: "password and password_command are mutually exclusive"}
+		}
+	}
+	return nil
+}
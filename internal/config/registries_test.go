@@ -0,0 +1,171 @@
+package config
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateRegistries(test *testing.T) {
+	tests := []struct {
+		name       string
+		registries []RegistryConfig
+		wantErr    string
+	}{
+		{
+			name: "password and command",
+			registries: []RegistryConfig{
+				{Host: "ghcr.io", Username: "octocat", Password: "ghp_token"},
+				{Host: "123456789012.dkr.ecr.us-east-1.amazonaws.com", Username: "AWS", PasswordCommand: "aws ecr get-login-password"},
+			},
+		},
+		{
+			name:       "unset password variable",
+			registries: []RegistryConfig{{Host: "ghcr.io", Username: "octocat"}},
+		},
+		{
+			name:       "missing host",
+			registries: []RegistryConfig{{Username: "octocat", Password: "token"}},
+			wantErr:    "host is required",
+		},
+		{
+			name:       "host with scheme",
+			registries: []RegistryConfig{{Host: "oci://ghcr.io/org", Username: "octocat", Password: "token"}},
+			wantErr:    "without a scheme or path",
+		},
+		{
+			name:       "missing username",
+			registries: []RegistryConfig{{Host: "ghcr.io", Password: "token"}},
+			wantErr:    "username is required",
+		},
+		{
+			name:       "password and password command",
+			registries: []RegistryConfig{{Host: "ghcr.io", Username: "octocat", Password: "token", PasswordCommand: "gh auth token"}},
+			wantErr:    "mutually exclusive",
+		},
+		{
+			name: "docker hub aliases",
+			registries: []RegistryConfig{
+				{Host: "docker.io", Username: "me", Password: "token"},
+				{Host: "index.docker.io", Username: "me", Password: "token"},
+			},
+			wantErr: "duplicate registry 'docker.io'",
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			cfg := &Config{Registries: tt.registries}
+			err := cfg.validateRegistries()
+			if tt.wantErr == "" {
+				if err != nil {
+					test.Errorf("validateRegistries() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				test.Errorf("validateRegistries() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestImageRegistryHost(test *testing.T) {
+	tests := []struct {
+		image    string
+		expected string
+	}{
+		{image: "postgres:16", expected: "docker.io"},
+		{image: "bitnami/redis:7.2", expected: "docker.io"},
+		{image: "docker.io/library/nginx", expected: "docker.io"},
+		{image: "ghcr.io/org/api:1.0", expected: "ghcr.io"},
+		{image: "localhost:5000/api", expected: "localhost:5000"},
+		{image: "localhost/api", expected: "localhost"},
+		{image: "myacr.azurecr.io/team/api@sha256:abc", expected: "myacr.azurecr.io"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.image, func(test *testing.T) {
+			if got := ImageRegistryHost(tt.image); got != tt.expected {
+				test.Errorf("ImageRegistryHost() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFindRegistry(test *testing.T) {
+	registries := []RegistryConfig{
+		{Host: "ghcr.io", Username: "octocat"},
+		{Host: "index.docker.io", Username: "me"},
+	}
+
+	if reg := FindRegistry(registries, "ghcr.io"); reg == nil || reg.Username != "octocat" {
+		test.Errorf("FindRegistry(ghcr.io) = %+v", reg)
+	}
+	if reg := FindRegistry(registries, "registry-1.docker.io"); reg == nil || reg.Username != "me" {
+		test.Errorf("FindRegistry(registry-1.docker.io) = %+v", reg)
+	}
+	if reg := FindRegistry(registries, "quay.io"); reg != nil {
+		test.Errorf("FindRegistry(quay.io) = %+v, want nil", reg)
+	}
+}
+
+func TestResolvePassword(test *testing.T) {
+	ctx := context.Background()
+
+	reg := &RegistryConfig{Host: "ghcr.io", Username: "octocat", Password: "ghp_token"}
+	if password, err := reg.ResolvePassword(ctx); err != nil || password != "ghp_token" {
+		test.Errorf("ResolvePassword() = %q, %v", password, err)
+	}
+
+	reg = &RegistryConfig{Host: "example.azurecr.io", Username: "token", PasswordCommand: "echo acr-token"}
+	if password, err := reg.ResolvePassword(ctx); err != nil || password != "acr-token" {
+		test.Errorf("ResolvePassword() with command = %q, %v", password, err)
+	}
+
+	reg = &RegistryConfig{Host: "ghcr.io", Username: "octocat"}
+	if _, err := reg.ResolvePassword(ctx); err == nil || !strings.Contains(err.Error(), "empty password") {
+		test.Errorf("ResolvePassword() without a password error = %v", err)
+	}
+
+	reg = &RegistryConfig{Host: "ghcr.io", Username: "octocat", PasswordCommand: "exit 3"}
+	if _, err := reg.ResolvePassword(ctx); err == nil {
+		test.Error("ResolvePassword() with a failing command succeeded")
+	}
+}
+
+func TestMergeRegistries(test *testing.T) {
+	dir := writeConfigFiles(test, map[string]string{
+		"kraze.yml": `
+cluster:
+  name: dev
+registries:
+  - host: ghcr.io
+    username: octocat
+    password: first
+services: {}
+`,
+		"kraze.local.yml": `
+registries:
+  - host: ghcr.io
+    username: other
+    password: second
+  - host: quay.io
+    username: robot
+    password: third
+services: {}
+`,
+	})
+
+	cfg, err := ParseMultiple([]string{filepath.Join(dir, "kraze.yml"), filepath.Join(dir, "kraze.local.yml")})
+	if err != nil {
+		test.Fatalf("ParseMultiple() error = %v", err)
+	}
+	if len(cfg.Registries) != 2 {
+		test.Fatalf("registries = %+v, want 2", cfg.Registries)
+	}
+	if reg := FindRegistry(cfg.Registries, "ghcr.io"); reg.Password != "first" {
+		test.Errorf("ghcr.io password = %q, want the first file's", reg.Password)
+	}
+}
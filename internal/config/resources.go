@@ -0,0 +1,130 @@
+package config
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ResourcesOverride caps or strips the CPU and memory requests and limits of a
+// service's rendered workloads before they're applied, for charts that ask for
+// more than a laptop kind cluster has
+type ResourcesOverride struct {
+	MaxRequests   *ResourceCap `yaml:"max_requests,omitempty"`   // Requests above these are lowered to them
+	MaxLimits     *ResourceCap `yaml:"max_limits,omitempty"`     // Limits above these are lowered to them (and requests with them)
+	StripRequests *bool        `yaml:"strip_requests,omitempty"` // Remove requests altogether, and the limits they'd default to
+	StripLimits   *bool        `yaml:"strip_limits,omitempty"`   // Remove limits altogether
+	Disabled      bool         `yaml:"disabled,omitempty"`       // Service only: ignore the global resources_override
+}
+
+// ResourceCap is the most CPU and memory a container may request or be limited to
+type ResourceCap struct {
+	CPU    string `yaml:"cpu,omitempty"`    // e.g. 500m
+	Memory string `yaml:"memory,omitempty"` // e.g. 512Mi
+}
+
+// IsEnabled returns whether the override changes anything
+func (override *ResourcesOverride) IsEnabled() bool {
+	if override == nil || override.Disabled {
+		return false
+	}
+	return override.MaxRequests != nil || override.MaxLimits != nil || override.ShouldStripRequests() || override.ShouldStripLimits()
+}
+
+// ShouldStripRequests returns whether requests are removed
+func (override *ResourcesOverride) ShouldStripRequests() bool {
+	return override != nil && override.StripRequests != nil && *override.StripRequests
+}
+
+// ShouldStripLimits returns whether limits are removed
+func (override *ResourcesOverride) ShouldStripLimits() bool {
+	return override != nil && override.StripLimits != nil && *override.StripLimits
+}
+
+// Validate checks that the caps are valid quantities
+func (override *ResourcesOverride) Validate(field string) error {
+	if override == nil {
+		return nil
+	}
+	caps := []struct {
+		name string
+		cap  *ResourceCap
+	}{{"max_requests", override.MaxRequests}, {"max_limits", override.MaxLimits}}
+	for _, itr := range caps {
+		if itr.cap == nil {
+			continue
+		}
+		for key, value := range map[string]string{"cpu": itr.cap.CPU, "memory": itr.cap.Memory} {
+			if value == "" {
+				continue
+			}
+			if _, err := resource.ParseQuantity(value); err != nil {
+				return &ValidationError{Field: fmt.Sprintf("%s.%s.%s", field, itr.name, key), Message: fmt.Sprintf("'%s' is not a valid quantity", value)}
+			}
+		}
+	}
+	return nil
+}
+
+// mergeResourcesOverride returns a service's override on top of the global
+// one: each setting the service makes replaces the global setting
+func mergeResourcesOverride(global, service *ResourcesOverride) *ResourcesOverride {
+	if service != nil && service.Disabled {
+		return service
+	}
+	if global == nil {
+		return service
+	}
+	if service == nil {
+		merged := *global
+		return &merged
+	}
+
+	merged := *service
+	merged.MaxRequests = mergeResourceCap(global.MaxRequests, service.MaxRequests)
+	merged.MaxLimits = mergeResourceCap(global.MaxLimits, service.MaxLimits)
+	if merged.StripRequests == nil {
+		merged.StripRequests = global.StripRequests
+	}
+	if merged.StripLimits == nil {
+		merged.StripLimits = global.StripLimits
+	}
+	return &merged
+}
+
+// mergeResourceCap returns the service's caps, with the global caps for the resources it doesn't cap
+func mergeResourceCap(global, service *ResourceCap) *ResourceCap {
+	if global == nil {
+		return service
+	}
+	if service == nil {
+		merged := *global
+		return &merged
+	}
+	merged := *service
+	if merged.CPU == "" {
+		merged.CPU = global.CPU
+	}
+	if merged.Memory == "" {
+		merged.Memory = global.Memory
+	}
+	return &merged
+}
+
+// applyResourcesOverride folds the global resources_override into each
+// service's, so providers only look at the service
+func (cfg *Config) applyResourcesOverride() error {
+	if err := cfg.ResourcesOverride.Validate("resources_override"); err != nil {
+		return err
+	}
+	for name, svc := range cfg.Services {
+		svc.ResourcesOverride = mergeResourcesOverride(cfg.ResourcesOverride, svc.ResourcesOverride)
+		cfg.Services[name] = svc
+		if svc.ResourcesOverride != nil {
+			path := originPath(originPath("services", name), "resources_override")
+			cfg.copyOrigins(cfg, "resources_override", path)
+			cfg.deriveOrigins(path, svc.ResourcesOverride, "resources_override", false)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplyResourcesOverride(test *testing.T) {
+	dir := writeConfigFiles(test, map[string]string{
+		"kraze.yml": `cluster:
+  name: dev
+resources_override:
+  max_requests:
+    cpu: 500m
+    memory: 512Mi
+  strip_limits: true
+services:
+  api:
+    type: manifests
+    path: api.yaml
+  search:
+    type: manifests
+    path: search.yaml
+    resources_override:
+      max_requests:
+        memory: 2Gi
+      strip_limits: false
+  operator:
+    type: manifests
+    path: operator.yaml
+    resources_override:
+      disabled: true
+`,
+	})
+
+	cfg, err := Parse(filepath.Join(dir, "kraze.yml"))
+	if err != nil {
+		test.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		service      string
+		enabled      bool
+		maxRequests  *ResourceCap
+		stripsLimits bool
+	}{
+		{service: "api", enabled: true, maxRequests: &ResourceCap{CPU: "500m", Memory: "512Mi"}, stripsLimits: true},
+		{service: "search", enabled: true, maxRequests: &ResourceCap{CPU: "500m", Memory: "2Gi"}},
+		{service: "operator"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.service, func(test *testing.T) {
+			override := cfg.Services[tt.service].ResourcesOverride
+			if override.IsEnabled() != tt.enabled {
+				test.Fatalf("Expected enabled = %v, got %+v", tt.enabled, override)
+			}
+			if !tt.enabled {
+				return
+			}
+			if !reflect.DeepEqual(override.MaxRequests, tt.maxRequests) {
+				test.Errorf("Expected max_requests %+v, got %+v", tt.maxRequests, override.MaxRequests)
+			}
+			if override.ShouldStripLimits() != tt.stripsLimits {
+				test.Errorf("Expected strip_limits = %v", tt.stripsLimits)
+			}
+		})
+	}
+
+	if cfg.ResourcesOverride.MaxRequests.Memory != "512Mi" {
+		test.Error("Expected merging into services to leave the global override unchanged")
+	}
+}
+
+func TestResourcesOverrideValidate(test *testing.T) {
+	tests := []struct {
+		name        string
+		override    *ResourcesOverride
+		errContains string
+	}{
+		{name: "nil", override: nil},
+		{name: "valid", override: &ResourcesOverride{MaxLimits: &ResourceCap{CPU: "1", Memory: "1Gi"}}},
+		{name: "invalid cpu", override: &ResourcesOverride{MaxRequests: &ResourceCap{CPU: "lots"}}, errContains: "resources_override.max_requests.cpu"},
+		{name: "invalid memory", override: &ResourcesOverride{MaxLimits: &ResourceCap{Memory: "1 GB"}}, errContains: "resources_override.max_limits.memory"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			err := tt.override.Validate("resources_override")
+			if tt.errContains == "" {
+				if err != nil {
+					test.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				test.Errorf("Validate() error = %v, want one containing %q", err, tt.errContains)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// defaultRetryBackoff is the delay before the first retry of a failed install
+	defaultRetryBackoff = 10 * time.Second
+
+	// maxRetryBackoff caps the delay between retries as it doubles
+	maxRetryBackoff = 2 * time.Minute
+)
+
+// GetRetries returns how many times a transiently failed install is retried:
+// the service's retries, or fallback (the --retry flag) when it sets none
+func (srv *ServiceConfig) GetRetries(fallback int) int {
+	if srv.Retries != nil {
+		return *srv.Retries
+	}
+	return fallback
+}
+
+// GetRetryBackoff returns the delay before a retry (1 for the first): the
+// service's retry_backoff (default 10s), doubled after each retry up to 2
+// minutes, or the backoff itself if it's longer
+func (srv *ServiceConfig) GetRetryBackoff(retry int) time.Duration {
+	delay := defaultRetryBackoff
+	if srv.RetryBackoff != "" {
+		if parsed, err := time.ParseDuration(srv.RetryBackoff); err == nil {
+			delay = parsed
+		}
+	}
+	limit := max(maxRetryBackoff, delay)
+	for itr := 1; itr < retry; itr++ {
+		delay *= 2
+		if delay >= limit {
+			return limit
+		}
+	}
+	return delay
+}
+
+// validateRetries checks retries and retry_backoff
+func (srv *ServiceConfig) validateRetries() error {
+	if srv.Retries != nil && *srv.Retries < 0 {
+		return &ValidationError{Field: "retries", Message: "must not be negative"}
+	}
+	if srv.RetryBackoff == "" {
+		return nil
+	}
+	backoff, err := time.ParseDuration(srv.RetryBackoff)
+	if err != nil {
+		return &ValidationError{Field: "retry_backoff", Message: fmt.Sprintf("invalid duration '%s': %v", srv.RetryBackoff, err)}
+	}
+	if backoff <= 0 {
+		return &ValidationError{Field: "retry_backoff", Message: "must be positive"}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetRetries(test *testing.T) {
+	zero, three := 0, 3
+	tests := []struct {
+		name     string
+		retries  *int
+		fallback int
+		expected int
+	}{
+		{name: "defaults to flag", fallback: 2, expected: 2},
+		{name: "service setting wins", retries: &three, fallback: 1, expected: 3},
+		{name: "service disables retries", retries: &zero, fallback: 5, expected: 0},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			svc := &ServiceConfig{Retries: tt.retries}
+			if got := svc.GetRetries(tt.fallback); got != tt.expected {
+				test.Errorf("GetRetries(%d) = %d, expected %d", tt.fallback, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetRetryBackoff(test *testing.T) {
+	tests := []struct {
+		name     string
+		backoff  string
+		retry    int
+		expected time.Duration
+	}{
+		{name: "default first retry", retry: 1, expected: 10 * time.Second},
+		{name: "default doubles", retry: 3, expected: 40 * time.Second},
+		{name: "default capped", retry: 10, expected: 2 * time.Minute},
+		{name: "configured", backoff: "2s", retry: 1, expected: 2 * time.Second},
+		{name: "configured doubles", backoff: "2s", retry: 2, expected: 4 * time.Second},
+		{name: "longer than the cap", backoff: "5m", retry: 3, expected: 5 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			svc := &ServiceConfig{RetryBackoff: tt.backoff}
+			if got := svc.GetRetryBackoff(tt.retry); got != tt.expected {
+				test.Errorf("GetRetryBackoff(%d) = %v, expected %v", tt.retry, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateRetries(test *testing.T) {
+	negative, three := -1, 3
+	tests := []struct {
+		name    string
+		retries *int
+		backoff string
+		field   string
+	}{
+		{name: "valid", retries: &three, backoff: "15s"},
+		{name: "unset"},
+		{name: "negative retries", retries: &negative, field: "retries"},
+		{name: "invalid backoff", backoff: "soon", field: "retry_backoff"},
+		{name: "zero backoff", backoff: "0s", field: "retry_backoff"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			svc := &ServiceConfig{Name: "api", Retries: tt.retries, RetryBackoff: tt.backoff}
+			err := svc.validateRetries()
+			if tt.field == "" {
+				if err != nil {
+					test.Errorf("validateRetries() error = %v", err)
+				}
+				return
+			}
+			validationErr, ok := err.(*ValidationError)
+			if !ok || validationErr.Field != tt.field {
+				test.Errorf("validateRetries() error = %v, expected a %s error", err, tt.field)
+			}
+		})
+	}
+}
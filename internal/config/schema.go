@@ -0,0 +1,506 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaURL is the draft the JSON Schema of kraze.yml follows
+const SchemaURL = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonSchema is a JSON Schema object
+type jsonSchema map[string]interface{}
+
+// durationPattern matches Go durations (e.g., 30s, 1h30m)
+const durationPattern = `^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+
+// extensionPattern matches x- keys, which are ignored so files can hold YAML
+// anchors and tool settings
+const extensionPattern = "^x-"
+
+// Fields whose values are durations or one of a fixed set, keyed by Go type
+// and YAML key
+var (
+	schemaDurations = map[string]bool{
+		"ServiceConfig.wait_timeout":     true,
+		"ServiceConfig.post_ready_delay": true,
+		"ServiceConfig.pending_timeout":  true,
+		"ServiceConfig.retry_backoff":    true,
+		"DependencyCondition.timeout":    true,
+		"HookConfig.timeout":             true,
+		"AssertionConfig.timeout":        true,
+		"WorkloadReadiness.wait_timeout": true,
+		"FixtureConfig.timeout":          true,
+	}
+	schemaEnums = map[string][]string{
+		"Config.state_backend":          {"configmap", "crd"},
+		"ServiceConfig.type":            {"helm", "manifests"},
+		"BuildConfig.type":              {BuildDocker, BuildKo, BuildBuildpacks},
+		"DependencyCondition.condition": {ConditionServiceStarted, ConditionServiceHealthy},
+		"KindNode.role":                 {"control-plane", "worker"},
+	}
+)
+
+// Schema returns the JSON Schema of kraze.yml, generated from the config types
+func Schema() ([]byte, error) {
+	schema := schemaFor(reflect.TypeOf(Config{}), map[reflect.Type]bool{})
+	schema["$schema"] = SchemaURL
+	schema["title"] = "kraze.yml"
+	schema["description"] = "Configuration of a kraze development environment"
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode schema: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// schemaFor returns the schema of a type. seen holds the structs being
+// generated, so a recursive type accepts anything instead of looping.
+func schemaFor(typ reflect.Type, seen map[reflect.Type]bool) jsonSchema {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	// Types with their own UnmarshalYAML accept more than one shape
+	switch typ {
+	case reflect.TypeOf(ValuesField{}):
+		return jsonSchema{"anyOf": []interface{}{
+			jsonSchema{"type": "string"},
+			jsonSchema{"type": "array", "items": jsonSchema{"type": "string"}},
+		}}
+	case reflect.TypeOf(DependsOnField{}):
+		return jsonSchema{"anyOf": []interface{}{
+			jsonSchema{"type": "array", "items": jsonSchema{"type": "string"}},
+			jsonSchema{"type": "object", "additionalProperties": schemaFor(reflect.TypeOf(DependencyCondition{}), seen)},
+		}}
+	case reflect.TypeOf(ExtendsConfig{}):
+		return jsonSchema{"anyOf": []interface{}{
+			jsonSchema{"type": "string"},
+			structSchema(typ, seen),
+		}}
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return jsonSchema{"type": "string"}
+	case reflect.Bool:
+		return jsonSchema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return jsonSchema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return jsonSchema{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return jsonSchema{"type": "array", "items": schemaFor(typ.Elem(), seen)}
+	case reflect.Map:
+		return jsonSchema{"type": "object", "additionalProperties": schemaFor(typ.Elem(), seen)}
+	case reflect.Struct:
+		if seen[typ] {
+			return jsonSchema{}
+		}
+		return structSchema(typ, seen)
+	}
+	return jsonSchema{}
+}
+
+// structSchema returns the schema of a struct's YAML fields, rejecting others
+func structSchema(typ reflect.Type, seen map[reflect.Type]bool) jsonSchema {
+	seen[typ] = true
+	defer delete(seen, typ)
+
+	properties := jsonSchema{}
+	addStructProperties(typ, properties, seen)
+	return jsonSchema{
+		"type":                 "object",
+		"properties":           properties,
+		"patternProperties":    jsonSchema{extensionPattern: jsonSchema{}},
+		"additionalProperties": false,
+	}
+}
+
+// addStructProperties adds the schema of each YAML field of a struct,
+// including the fields of inlined structs
+func addStructProperties(typ reflect.Type, properties jsonSchema, seen map[reflect.Type]bool) {
+	for itr := 0; itr < typ.NumField(); itr++ {
+		field := typ.Field(itr)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("yaml")
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(options, "inline") {
+			addStructProperties(field.Type, properties, seen)
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		schema := schemaFor(field.Type, seen)
+		key := typ.Name() + "." + name
+		if schemaDurations[key] {
+			schema["pattern"] = durationPattern
+		}
+		if values, exists := schemaEnums[key]; exists {
+			enum := make([]interface{}, len(values))
+			for idx, value := range values {
+				enum[idx] = value
+			}
+			schema["enum"] = enum
+		}
+		properties[name] = schema
+	}
+}
+
+// SchemaError is a problem with a config file's YAML, at the line and column
+// of the offending value
+type SchemaError struct {
+	File    string
+	Line    int
+	Column  int
+	Field   string
+	Message string
+}
+
+func (err *SchemaError) Error() string {
+	position := fmt.Sprintf("%s:%d:%d", err.File, err.Line, err.Column)
+	if err.Field != "" {
+		return fmt.Sprintf("%s: %s: %s", position, err.Field, err.Message)
+	}
+	return position + ": " + err.Message
+}
+
+// CheckSchema checks a config file against the JSON Schema of kraze.yml:
+// unknown fields, values of the wrong type, invalid durations and values
+// outside a fixed set. Variables are expanded first, like when parsing.
+func CheckSchema(configPath string) ([]*SchemaError, error) {
+	data, _, err := readAndExpand(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if len(document.Content) == 0 {
+		return nil, nil
+	}
+
+	var schema jsonSchema
+	raw, err := Schema()
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, fmt.Errorf("failed to decode schema: %w", err)
+	}
+
+	var problems []*SchemaError
+	checkNode(document.Content[0], schema, "", func(node *yaml.Node, field, message string) {
+		problems = append(problems, &SchemaError{File: configPath, Line: node.Line, Column: node.Column, Field: field, Message: message})
+	})
+	return problems, nil
+}
+
+// checkNode checks a YAML node against a schema, reporting each problem
+func checkNode(node *yaml.Node, schema jsonSchema, field string, report func(node *yaml.Node, field, message string)) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+
+	if alternatives, exists := schema["anyOf"].([]interface{}); exists {
+		checkAlternatives(node, alternatives, field, report)
+		return
+	}
+
+	switch schema["type"] {
+	case "object":
+		if node.Kind != yaml.MappingNode {
+			report(node, field, "expected a mapping, got "+nodeKind(node))
+			return
+		}
+		checkMapping(node, schema, field, report)
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			report(node, field, "expected a list, got "+nodeKind(node))
+			return
+		}
+		items, _ := schema["items"].(map[string]interface{})
+		for idx, item := range node.Content {
+			checkNode(item, items, itemPath(field, idx), report)
+		}
+	case "string", "boolean", "integer", "number":
+		if node.Kind != yaml.ScalarNode {
+			report(node, field, fmt.Sprintf("expected a %s, got %s", schema["type"], nodeKind(node)))
+			return
+		}
+		checkScalar(node, schema, field, report)
+	}
+}
+
+// checkMapping checks the keys of a mapping against an object schema
+func checkMapping(node *yaml.Node, schema jsonSchema, field string, report func(node *yaml.Node, field, message string)) {
+	properties, _ := schema["properties"].(map[string]interface{})
+	additional := schema["additionalProperties"]
+
+	for idx := 0; idx+1 < len(node.Content); idx += 2 {
+		key, value := node.Content[idx], node.Content[idx+1]
+		child := originPath(field, key.Value)
+
+		// Merge keys bring in the keys of an anchored mapping
+		if key.Tag == "!!merge" {
+			if value.Kind == yaml.AliasNode {
+				value = value.Alias
+			}
+			merged := []*yaml.Node{value}
+			if value.Kind == yaml.SequenceNode {
+				merged = value.Content
+			}
+			for _, mapping := range merged {
+				checkNode(mapping, schema, field, report)
+			}
+			continue
+		}
+
+		if property, exists := properties[key.Value].(map[string]interface{}); exists {
+			checkNode(value, property, child, report)
+			continue
+		}
+		if strings.HasPrefix(key.Value, "x-") && properties != nil {
+			continue
+		}
+		switch additional := additional.(type) {
+		case map[string]interface{}:
+			checkNode(value, additional, child, report)
+		case bool:
+			if !additional {
+				message := fmt.Sprintf("unknown field '%s'", key.Value)
+				if suggestion := closestKey(key.Value, properties); suggestion != "" {
+					message += fmt.Sprintf(" (did you mean '%s'?)", suggestion)
+				}
+				report(key, field, message)
+			}
+		}
+	}
+}
+
+// checkScalar checks a scalar's type, pattern and allowed values. Strings
+// accept any scalar, since YAML decodes numbers and booleans into strings.
+func checkScalar(node *yaml.Node, schema jsonSchema, field string, report func(node *yaml.Node, field, message string)) {
+	switch schema["type"] {
+	case "boolean":
+		if node.Tag != "!!bool" {
+			report(node, field, fmt.Sprintf("expected true or false, got '%s'", node.Value))
+			return
+		}
+	case "integer":
+		if node.Tag != "!!int" {
+			report(node, field, fmt.Sprintf("expected an integer, got '%s'", node.Value))
+			return
+		}
+	case "number":
+		if node.Tag != "!!int" && node.Tag != "!!float" {
+			report(node, field, fmt.Sprintf("expected a number, got '%s'", node.Value))
+			return
+		}
+	}
+
+	if pattern, exists := schema["pattern"].(string); exists && !regexp.MustCompile(pattern).MatchString(node.Value) {
+		if pattern == durationPattern {
+			report(node, field, fmt.Sprintf("invalid duration '%s' (e.g., 30s, 5m, 1h30m)", node.Value))
+		} else {
+			report(node, field, fmt.Sprintf("'%s' doesn't match %s", node.Value, pattern))
+		}
+		return
+	}
+
+	if enum, exists := schema["enum"].([]interface{}); exists {
+		values := make([]string, len(enum))
+		for idx, value := range enum {
+			values[idx] = fmt.Sprint(value)
+			if values[idx] == node.Value {
+				return
+			}
+		}
+		report(node, field, fmt.Sprintf("unknown value '%s' (expected %s)", node.Value, strings.Join(values, ", ")))
+	}
+}
+
+// checkAlternatives checks a node against the alternatives of an anyOf,
+// reporting the problems of the alternative with the node's shape
+func checkAlternatives(node *yaml.Node, alternatives []interface{}, field string, report func(node *yaml.Node, field, message string)) {
+	var shapes []string
+	for _, alternative := range alternatives {
+		schema, _ := alternative.(map[string]interface{})
+		if schemaKind(schema) == nodeKind(node) {
+			checkNode(node, schema, field, report)
+			return
+		}
+		shapes = append(shapes, schemaKind(schema))
+	}
+	report(node, field, fmt.Sprintf("expected a %s, got %s", strings.Join(shapes, " or a "), nodeKind(node)))
+}
+
+// nodeKind names the shape of a YAML node
+func nodeKind(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "mapping"
+	case yaml.SequenceNode:
+		return "list"
+	}
+	return "scalar"
+}
+
+// schemaKind names the shape of YAML a schema accepts
+func schemaKind(schema jsonSchema) string {
+	switch schema["type"] {
+	case "object":
+		return "mapping"
+	case "array":
+		return "list"
+	}
+	return "scalar"
+}
+
+// closestKey returns the property a misspelled key most likely meant, if any
+// is within two edits
+func closestKey(key string, properties map[string]interface{}) string {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	best, bestDistance := "", 3
+	for _, name := range names {
+		if distance := editDistance(key, name); distance < bestDistance {
+			best, bestDistance = name, distance
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(left, right string) int {
+	previous := make([]int, len(right)+1)
+	for idx := range previous {
+		previous[idx] = idx
+	}
+	for row := 1; row <= len(left); row++ {
+		current := make([]int, len(right)+1)
+		current[0] = row
+		for col := 1; col <= len(right); col++ {
+			cost := 1
+			if left[row-1] == right[col-1] {
+				cost = 0
+			}
+			current[col] = min(previous[col]+1, current[col-1]+1, previous[col-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(right)]
+}
+
+// Locate returns the line and column of a dotted field path (as used by
+// ValidationError, e.g. services.api.depends_on.db or cluster.config[1].role)
+// in a config file. A path that doesn't fully resolve returns the position of
+// its deepest part that does, with exact false.
+func Locate(configPath, field string) (line, column int, exact bool, err error) {
+	line, column, resolved, err := locate(configPath, field)
+	return line, column, resolved == field, err
+}
+
+// locate returns the position of the longest prefix of a path that a config
+// file writes, and that prefix: the key of a map value, or a list item
+func locate(configPath, field string) (line, column int, resolved string, err error) {
+	data, _, err := readAndExpand(configPath)
+	if err != nil {
+		return 0, 0, "", err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return 0, 0, "", fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	positions := make(map[string]*yaml.Node)
+	walkYAML("", &doc, func(path string, key, value *yaml.Node) {
+		if key != nil {
+			positions[path] = key
+			return
+		}
+		positions[path] = value
+		// Lists of names, like depends_on, are also addressed by the name
+		if value.Kind == yaml.ScalarNode {
+			positions[originPath(path[:strings.LastIndex(path, "[")], value.Value)] = value
+		}
+	})
+
+	for prefix := field; prefix != ""; prefix = parentPath(prefix) {
+		if node, exists := positions[prefix]; exists {
+			return node.Line, node.Column, prefix, nil
+		}
+	}
+	return 0, 0, "", nil
+}
+
+// parentPath strips the last key or list index from a path
+func parentPath(path string) string {
+	if strings.HasSuffix(path, "]") {
+		if open := strings.LastIndex(path, "["); open >= 0 {
+			return path[:open]
+		}
+	}
+	if dot := strings.LastIndex(path, "."); dot >= 0 {
+		return path[:dot]
+	}
+	return ""
+}
+
+// ErrorField returns the dotted field path a validation error is about, with
+// the service's path for errors of a service, or "" if it names none
+func ErrorField(err error) string {
+	var field string
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		field = validationErr.Field
+	}
+	var serviceErr *ServiceValidationError
+	if errors.As(err, &serviceErr) {
+		return originPath("services."+serviceErr.Service, field)
+	}
+	return field
+}
+
+// LocateError returns the file, line and column of the field a validation
+// error is about, from the config file that writes most of the field
+func LocateError(configPaths []string, err error) (file string, line, column int, found bool) {
+	field := ErrorField(err)
+	if field == "" {
+		return "", 0, 0, false
+	}
+	longest := ""
+	for _, configPath := range configPaths {
+		fieldLine, fieldColumn, resolved, locateErr := locate(configPath, field)
+		if locateErr != nil || len(resolved) <= len(longest) {
+			continue
+		}
+		file, line, column, found = configPath, fieldLine, fieldColumn, true
+		longest = resolved
+	}
+	return file, line, column, found
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSchemaUpToDate(test *testing.T) {
+	schema, err := Schema()
+	if err != nil {
+		test.Fatalf("Schema() error = %v", err)
+	}
+	published, err := os.ReadFile(filepath.Join("..", "..", "docs", "kraze.schema.json"))
+	if err != nil {
+		test.Fatalf("Failed to read the published schema: %v", err)
+	}
+	if !bytes.Equal(schema, published) {
+		test.Error("docs/kraze.schema.json is out of date, regenerate it with 'make schema'")
+	}
+}
+
+func TestCheckSchema(test *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name: "valid",
+			content: `
+x-defaults: &defaults
+  wait_timeout: 5m
+cluster:
+  name: dev
+services:
+  api:
+    <<: *defaults
+    type: manifests
+    path: ./k8s
+    values: [base.yaml]
+    depends_on:
+      db:
+        condition: service_healthy
+        timeout: 2m
+  db:
+    type: helm
+    repo: https://charts.bitnami.com/bitnami
+    chart: postgresql
+    extends: postgres
+    values: values.yaml
+    depends_on: []
+`,
+		},
+		{
+			name: "unknown fields",
+			content: `
+cluster:
+  name: dev
+  extra_ports: []
+services:
+  api:
+    type: manifests
+    pth: ./k8s
+`,
+			expected: []string{
+				"4:3: cluster: unknown field 'extra_ports'",
+				"8:5: services.api: unknown field 'pth' (did you mean 'path'?)",
+			},
+		},
+		{
+			name: "wrong types",
+			content: `
+cluster:
+  name: dev
+  addons: cert-manager
+services:
+  api:
+    type: manifests
+    path: ./k8s
+    wait: sometimes
+    retries: many
+    depends_on: db
+`,
+			expected: []string{
+				"4:11: cluster.addons: expected a list, got scalar",
+				"9:11: services.api.wait: expected true or false, got 'sometimes'",
+				"10:14: services.api.retries: expected an integer, got 'many'",
+				"11:17: services.api.depends_on: expected a list or a mapping, got scalar",
+			},
+		},
+		{
+			name: "durations and enums",
+			content: `
+cluster:
+  name: dev
+state_backend: etcd
+services:
+  api:
+    type: kustomize
+    path: ./k8s
+    wait_timeout: 5 minutes
+    depends_on:
+      db:
+        condition: service_ready
+        timeout: 10
+`,
+			expected: []string{
+				"4:16: state_backend: unknown value 'etcd' (expected configmap, crd)",
+				"7:11: services.api.type: unknown value 'kustomize' (expected helm, manifests)",
+				"9:19: services.api.wait_timeout: invalid duration '5 minutes' (e.g., 30s, 5m, 1h30m)",
+				"12:20: services.api.depends_on.db.condition: unknown value 'service_ready' (expected service_started, service_healthy)",
+				"13:18: services.api.depends_on.db.timeout: invalid duration '10' (e.g., 30s, 5m, 1h30m)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			dir := writeConfigFiles(test, map[string]string{"kraze.yml": tt.content})
+			configPath := filepath.Join(dir, "kraze.yml")
+
+			problems, err := CheckSchema(configPath)
+			if err != nil {
+				test.Fatalf("CheckSchema() error = %v", err)
+			}
+			var got []string
+			for _, problem := range problems {
+				got = append(got, strings.TrimPrefix(problem.Error(), configPath+":"))
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.expected, "\n") {
+				test.Errorf("CheckSchema() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.expected, "\n"))
+			}
+		})
+	}
+}
+
+func TestLocateError(test *testing.T) {
+	dir := writeConfigFiles(test, map[string]string{
+		"kraze.yml": `
+cluster:
+  name: dev
+services:
+  api:
+    type: manifests
+    path: ./k8s
+    depends_on: [cache]
+`,
+		"kraze.override.yml": `
+services:
+  db:
+    type: helm
+    path: ./charts/db
+    repo: https://charts.example.com
+`,
+	})
+	base := filepath.Join(dir, "kraze.yml")
+	override := filepath.Join(dir, "kraze.override.yml")
+
+	tests := []struct {
+		name     string
+		override string
+		file     string
+		line     int
+		column   int
+		message  string
+	}{
+		{name: "path and repo", override: override, file: override, line: 3, column: 3, message: "cannot specify both 'path' and 'repo'"},
+		{name: "unknown dependency", file: base, line: 8, column: 18, message: "dependency 'cache' not found"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			paths := []string{base}
+			if tt.override != "" {
+				paths = append(paths, tt.override)
+			}
+			_, err := ParseMultiple(paths)
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				test.Fatalf("ParseMultiple() error = %v, want %q", err, tt.message)
+			}
+			file, line, column, found := LocateError(paths, err)
+			if !found || file != tt.file || line != tt.line || column != tt.column {
+				test.Errorf("LocateError() = %s:%d:%d (found %v), want %s:%d:%d", file, line, column, found, tt.file, tt.line, tt.column)
+			}
+		})
+	}
+}
+
+func TestServiceLabelsValidation(test *testing.T) {
+	tests := []struct {
+		name    string
+		labels  map[string]string
+		wantErr string
+	}{
+		{name: "valid", labels: map[string]string{"tier": "data", "app.kubernetes.io/part-of": "shop"}},
+		{name: "invalid key", labels: map[string]string{"tier!": "data"}, wantErr: "invalid label key 'tier!'"},
+		{name: "invalid value", labels: map[string]string{"tier": "data store"}, wantErr: "invalid label value 'data store'"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			svc := ServiceConfig{Name: "api", Type: "manifests", Path: "./k8s", Labels: tt.labels}
+			err := svc.Validate()
+			if tt.wantErr == "" && err != nil {
+				test.Errorf("Validate() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				test.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
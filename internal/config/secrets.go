@@ -0,0 +1,114 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// SecretConfig is a Kubernetes Secret kraze creates in the service's namespace
+// before installing it, from values kept outside the repository
+type SecretConfig struct {
+	Name string                  `yaml:"name"`           // Name of the Secret
+	Type string                  `yaml:"type,omitempty"` // Secret type (default: Opaque)
+	Data map[string]SecretSource `yaml:"data"`           // Keys of the Secret and where each value comes from
+}
+
+// SecretSource is where the value of a Secret key comes from. Exactly one field is set.
+type SecretSource struct {
+	Env         string `yaml:"env,omitempty"`         // Environment variable
+	File        string `yaml:"file,omitempty"`        // File, relative to kraze.yml (decrypted when SOPS-encrypted)
+	OnePassword string `yaml:"onepassword,omitempty"` // 1Password secret reference read with the op CLI (e.g., op://dev/api/password)
+	Vault       string `yaml:"vault,omitempty"`       // Vault KV path and field read with the vault CLI (e.g., secret/api#password)
+}
+
+// GetType returns the Secret's type
+func (secret *SecretConfig) GetType() string {
+	if secret.Type != "" {
+		return secret.Type
+	}
+	return "Opaque"
+}
+
+// Keys returns the Secret's keys in alphabetical order
+func (secret *SecretConfig) Keys() []string {
+	keys := make([]string, 0, len(secret.Data))
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Describe returns where the value comes from, without the value
+func (source SecretSource) Describe() string {
+	switch {
+	case source.Env != "":
+		return "env " + source.Env
+	case source.File != "":
+		return "file " + source.File
+	case source.OnePassword != "":
+		return "1Password " + source.OnePassword
+	case source.Vault != "":
+		return "Vault " + source.Vault
+	}
+	return "nothing"
+}
+
+// VaultPathAndField splits a Vault reference into its KV path and field
+func (source SecretSource) VaultPathAndField() (string, string) {
+	path, field, _ := strings.Cut(source.Vault, "#")
+	return path, field
+}
+
+// validate checks that every source is set exactly once and well-formed
+func (source SecretSource) validate(field string) error {
+	set := 0
+	for _, value := range []string{source.Env, source.File, source.OnePassword, source.Vault} {
+		if value != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return &ValidationError{Field: field, Message: "exactly one of env, file, onepassword or vault is required"}
+	}
+	if source.OnePassword != "" && !strings.HasPrefix(source.OnePassword, "op://") {
+		return &ValidationError{Field: field + ".onepassword", Message: fmt.Sprintf("'%s' is not a secret reference (op://vault/item/field)", source.OnePassword)}
+	}
+	if source.Vault != "" {
+		if path, vaultField := source.VaultPathAndField(); path == "" || vaultField == "" {
+			return &ValidationError{Field: field + ".vault", Message: fmt.Sprintf("'%s' must be a KV path and field (path#field)", source.Vault)}
+		}
+	}
+	return nil
+}
+
+// validateSecrets checks the service's secrets have valid, unique names and keys
+func (srv *ServiceConfig) validateSecrets() error {
+	seen := make(map[string]bool, len(srv.Secrets))
+	for itr, secret := range srv.Secrets {
+		field := fmt.Sprintf("secrets[%d]", itr)
+		if errs := validation.IsDNS1123Subdomain(secret.Name); len(errs) > 0 {
+			return &ValidationError{Field: field + ".name", Message: fmt.Sprintf("invalid Secret name '%s': %s", secret.Name, strings.Join(errs, "; "))}
+		}
+		if seen[secret.Name] {
+			return &ValidationError{Field: field + ".name", Message: fmt.Sprintf("Secret '%s' is defined more than once", secret.Name)}
+		}
+		seen[secret.Name] = true
+
+		if len(secret.Data) == 0 {
+			return &ValidationError{Field: field + ".data", Message: "at least one key is required"}
+		}
+		for _, key := range secret.Keys() {
+			if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+				return &ValidationError{Field: field + ".data", Message: fmt.Sprintf("invalid key '%s': %s", key, strings.Join(errs, "; "))}
+			}
+			if err := secret.Data[key].validate(field + ".data." + key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
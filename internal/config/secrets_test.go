@@ -0,0 +1,78 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateSecrets(test *testing.T) {
+	tests := []struct {
+		name    string
+		secrets []SecretConfig
+		wantErr string
+	}{
+		{
+			name: "every source",
+			secrets: []SecretConfig{{Name: "api-credentials", Data: map[string]SecretSource{
+				"DATABASE_URL": {Env: "DATABASE_URL"},
+				"tls.crt":      {File: "./certs/tls.crt"},
+				"API_KEY":      {OnePassword: "op://dev/api/credential"},
+				"TOKEN":        {Vault: "secret/api#token"},
+			}}},
+		},
+		{
+			name:    "invalid name",
+			secrets: []SecretConfig{{Name: "API_Credentials", Data: map[string]SecretSource{"key": {Env: "KEY"}}}},
+			wantErr: "invalid Secret name",
+		},
+		{
+			name: "duplicate name",
+			secrets: []SecretConfig{
+				{Name: "api", Data: map[string]SecretSource{"key": {Env: "KEY"}}},
+				{Name: "api", Data: map[string]SecretSource{"other": {Env: "OTHER"}}},
+			},
+			wantErr: "defined more than once",
+		},
+		{
+			name:    "no data",
+			secrets: []SecretConfig{{Name: "api"}},
+			wantErr: "at least one key",
+		},
+		{
+			name:    "invalid key",
+			secrets: []SecretConfig{{Name: "api", Data: map[string]SecretSource{"bad key": {Env: "KEY"}}}},
+			wantErr: "invalid key",
+		},
+		{
+			name:    "two sources",
+			secrets: []SecretConfig{{Name: "api", Data: map[string]SecretSource{"key": {Env: "KEY", File: "key.txt"}}}},
+			wantErr: "exactly one of",
+		},
+		{
+			name:    "1Password reference without scheme",
+			secrets: []SecretConfig{{Name: "api", Data: map[string]SecretSource{"key": {OnePassword: "dev/api/key"}}}},
+			wantErr: "op://vault/item/field",
+		},
+		{
+			name:    "Vault reference without field",
+			secrets: []SecretConfig{{Name: "api", Data: map[string]SecretSource{"key": {Vault: "secret/api"}}}},
+			wantErr: "path#field",
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			svc := &ServiceConfig{Name: "api", Secrets: tt.secrets}
+			err := svc.validateSecrets()
+			if tt.wantErr == "" {
+				if err != nil {
+					test.Errorf("validateSecrets() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				test.Errorf("validateSecrets() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// sopsDecrypt decrypts a SOPS-encrypted file with the sops CLI, which finds
+// the age, GPG or cloud KMS keys the same way it does on the command line.
+// Replaced in tests.
+var sopsDecrypt = func(ctx context.Context, path string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sops", "--decrypt", path)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, fmt.Errorf("%s is encrypted with SOPS, but sops is not installed (see https://github.com/getsops/sops)", path)
+		}
+		return nil, fmt.Errorf("failed to decrypt %s with sops: %s", path, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// IsSOPSEncrypted returns true if YAML or JSON content was encrypted by SOPS,
+// which adds a top-level sops section with the message authentication code
+func IsSOPSEncrypted(data []byte) bool {
+	if !bytes.Contains(data, []byte("sops")) {
+		return false
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc map[string]any
+		if err := decoder.Decode(&doc); err != nil {
+			return false // End of the documents, or not YAML
+		}
+		if metadata, ok := doc["sops"].(map[string]any); ok {
+			if _, ok := metadata["mac"]; ok {
+				return true
+			}
+		}
+	}
+}
+
+// DecryptIfSOPS returns a file's content decrypted in memory when it's
+// SOPS-encrypted, so the plaintext never touches the disk
+func DecryptIfSOPS(ctx context.Context, path string, data []byte) ([]byte, error) {
+	if !IsSOPSEncrypted(data) {
+		return data, nil
+	}
+	return sopsDecrypt(ctx, path)
+}
+
+// EncryptedValues returns the service's SOPS-encrypted values files as they
+// are on disk, without decrypting them: the keys are plain and the encrypted
+// values read ENC[...], which tells which values to mask (see MaskValues)
+func (svc *ServiceConfig) EncryptedValues() []map[string]interface{} {
+	var encrypted []map[string]interface{}
+	for _, path := range svc.Values.Files() {
+		data, err := os.ReadFile(path)
+		if err != nil || !IsSOPSEncrypted(data) {
+			continue
+		}
+		var values map[string]interface{}
+		if err := yaml.Unmarshal(data, &values); err == nil {
+			encrypted = append(encrypted, values)
+		}
+	}
+	return encrypted
+}
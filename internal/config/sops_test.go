@@ -0,0 +1,96 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sopsEncryptedValues = `password: ENC[AES256_GCM,data:Tr7o,iv:1=,tag:2=,type:str]
+sops:
+    age:
+        - recipient: age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p
+    lastmodified: "2026-01-01T00:00:00Z"
+    mac: ENC[AES256_GCM,data:abc,iv:1=,tag:2=,type:str]
+    version: 3.9.0
+`
+
+func TestIsSOPSEncrypted(test *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected bool
+	}{
+		{name: "encrypted yaml", data: sopsEncryptedValues, expected: true},
+		{name: "encrypted json", data: `{"password": "ENC[...]", "sops": {"mac": "ENC[...]", "version": "3.9.0"}}`, expected: true},
+		{name: "encrypted second document", data: "kind: Namespace\n---\n" + sopsEncryptedValues, expected: true},
+		{name: "plain values", data: "replicaCount: 2\n", expected: false},
+		{name: "sops key without mac", data: "sops:\n  enabled: true\n", expected: false},
+		{name: "not yaml", data: "sops: [", expected: false},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := IsSOPSEncrypted([]byte(tt.data)); got != tt.expected {
+				test.Errorf("IsSOPSEncrypted() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReadValuesFileDecryptsSOPS(test *testing.T) {
+	dir := test.TempDir()
+	encrypted := filepath.Join(dir, "secrets.yaml")
+	plain := filepath.Join(dir, "values.yaml")
+	if err := os.WriteFile(encrypted, []byte(sopsEncryptedValues), 0644); err != nil {
+		test.Fatal(err)
+	}
+	if err := os.WriteFile(plain, []byte("replicaCount: 2\n"), 0644); err != nil {
+		test.Fatal(err)
+	}
+
+	original := sopsDecrypt
+	defer func() { sopsDecrypt = original }()
+	var decrypted []string
+	sopsDecrypt = func(ctx context.Context, path string) ([]byte, error) {
+		decrypted = append(decrypted, path)
+		return []byte("password: hunter2\n"), nil
+	}
+
+	svc := &ServiceConfig{Name: "api"}
+	data, err := svc.ReadValuesFile(encrypted)
+	if err != nil {
+		test.Fatalf("ReadValuesFile() error = %v", err)
+	}
+	if string(data) != "password: hunter2\n" {
+		test.Errorf("ReadValuesFile() = %q, want the decrypted values", data)
+	}
+
+	if data, err = svc.ReadValuesFile(plain); err != nil || string(data) != "replicaCount: 2\n" {
+		test.Errorf("ReadValuesFile() = %q, %v, want the plain values", data, err)
+	}
+	if len(decrypted) != 1 || decrypted[0] != encrypted {
+		test.Errorf("decrypted %v, want only %s", decrypted, encrypted)
+	}
+}
+
+func TestEncryptedValues(test *testing.T) {
+	dir := test.TempDir()
+	encrypted := filepath.Join(dir, "secrets.yaml")
+	plain := filepath.Join(dir, "values.yaml")
+	if err := os.WriteFile(encrypted, []byte(sopsEncryptedValues), 0644); err != nil {
+		test.Fatal(err)
+	}
+	if err := os.WriteFile(plain, []byte("replicaCount: 2\n"), 0644); err != nil {
+		test.Fatal(err)
+	}
+
+	svc := &ServiceConfig{Name: "api", Values: ValuesField{files: []string{plain, encrypted}}}
+	files := svc.EncryptedValues()
+	if len(files) != 1 || !strings.HasPrefix(fmt.Sprint(files[0]["password"]), "ENC[") {
+		test.Errorf("EncryptedValues() = %v, want the encrypted file as on disk", files)
+	}
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// ZoneLabel is the well-known node label topology spread constraints and
+	// zone-aware workloads read the zone from
+	ZoneLabel = "topology.kubernetes.io/zone"
+
+	// RegionLabel is the well-known node label for the region
+	RegionLabel = "topology.kubernetes.io/region"
+
+	// DefaultTopologyRegion is the region of the nodes unless cluster.topology.region sets one
+	DefaultTopologyRegion = "kraze"
+)
+
+// TopologyConfig labels kind nodes with synthetic zones and a region, so
+// topology spread constraints and zone-aware logic can be exercised locally
+type TopologyConfig struct {
+	Region string                       `yaml:"region,omitempty"` // Region of every node (default: kraze)
+	Zones  []string                     `yaml:"zones"`            // Zones assigned round-robin to the worker nodes (to all nodes without workers)
+	Skew   map[string]map[string]string `yaml:"skew,omitempty"`   // Extra systemReserved per zone, shrinking its nodes' allocatable (e.g., {zone-c: {cpu: "1", memory: 2Gi}})
+}
+
+// GetRegion returns the region of the nodes
+func (topology *TopologyConfig) GetRegion() string {
+	if topology.Region != "" {
+		return topology.Region
+	}
+	return DefaultTopologyRegion
+}
+
+// NodeZones assigns zones to nodes given their roles in creation order (with
+// replicas expanded): round-robin over the workers, or over all nodes when
+// there are no workers, since the control-plane then runs the workloads. Nodes
+// without a zone get "".
+func (topology *TopologyConfig) NodeZones(roles []string) []string {
+	zones := make([]string, len(roles))
+	if topology == nil || len(topology.Zones) == 0 {
+		return zones
+	}
+
+	hasWorker := slices.Contains(roles, "worker")
+	next := 0
+	for itr, role := range roles {
+		if hasWorker && role != "worker" {
+			continue
+		}
+		zones[itr] = topology.Zones[next%len(topology.Zones)]
+		next++
+	}
+	return zones
+}
+
+// NodeRoles returns the role of every node the cluster creates, with replicas expanded
+func (cluster *ClusterConfig) NodeRoles() []string {
+	if len(cluster.Config) == 0 {
+		return []string{"control-plane"}
+	}
+
+	var roles []string
+	for _, node := range cluster.Config {
+		role := node.Role
+		if role != "worker" {
+			role = "control-plane"
+		}
+		for itr := 0; itr < max(node.Replicas, 1); itr++ {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// validateTopology checks the zones, the skew and that every zone gets a node
+func (cluster *ClusterConfig) validateTopology() error {
+	topology := cluster.Topology
+	if topology == nil {
+		return nil
+	}
+	if cluster.IsExternal() {
+		return &ValidationError{Field: "cluster.topology", Message: "topology simulation is only available for kind clusters, not external clusters"}
+	}
+	if len(topology.Zones) == 0 {
+		return &ValidationError{Field: "cluster.topology.zones", Message: "at least one zone is required"}
+	}
+
+	seen := make(map[string]bool, len(topology.Zones))
+	for _, zone := range topology.Zones {
+		if zone == "" {
+			return &ValidationError{Field: "cluster.topology.zones", Message: "zone names can't be empty"}
+		}
+		if seen[zone] {
+			return &ValidationError{Field: "cluster.topology.zones", Message: fmt.Sprintf("zone '%s' is listed more than once", zone)}
+		}
+		seen[zone] = true
+	}
+
+	zoned := 0
+	for _, zone := range topology.NodeZones(cluster.NodeRoles()) {
+		if zone != "" {
+			zoned++
+		}
+	}
+	if zoned < len(topology.Zones) {
+		return &ValidationError{
+			Field:   "cluster.topology.zones",
+			Message: fmt.Sprintf("%d zones need at least %d worker nodes, but the cluster has %d (add worker replicas in cluster.config)", len(topology.Zones), len(topology.Zones), zoned),
+		}
+	}
+
+	for zone, reserved := range topology.Skew {
+		if !seen[zone] {
+			return &ValidationError{Field: "cluster.topology.skew", Message: fmt.Sprintf("zone '%s' is not in cluster.topology.zones", zone)}
+		}
+		for key, value := range reserved {
+			if !slices.Contains(reservableResources, key) {
+				return &ValidationError{Field: "cluster.topology.skew." + zone, Message: fmt.Sprintf("unknown resource '%s' (supported: %s)", key, strings.Join(reservableResources, ", "))}
+			}
+			if _, err := resource.ParseQuantity(value); err != nil {
+				return &ValidationError{Field: "cluster.topology.skew." + zone + "." + key, Message: fmt.Sprintf("'%s' is not a valid quantity", value)}
+			}
+		}
+	}
+	return nil
+}
+
+// WithReservedSkew returns the node with a zone's skew added to its systemReserved
+func (node KindNode) WithReservedSkew(skew map[string]string) KindNode {
+	if len(skew) == 0 {
+		return node
+	}
+
+	reserved := make(map[string]string, len(node.SystemReserved)+len(skew))
+	for key, value := range node.SystemReserved {
+		reserved[key] = value
+	}
+	for key, value := range skew {
+		total := resource.MustParse(value)
+		if existing, err := resource.ParseQuantity(reserved[key]); err == nil {
+			total.Add(existing)
+		}
+		reserved[key] = total.String()
+	}
+	node.SystemReserved = reserved
+	return node
+}
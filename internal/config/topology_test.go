@@ -0,0 +1,122 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNodeZones(test *testing.T) {
+	tests := []struct {
+		name     string
+		zones    []string
+		roles    []string
+		expected []string
+	}{
+		{
+			name:     "round-robin over workers",
+			zones:    []string{"zone-a", "zone-b"},
+			roles:    []string{"control-plane", "worker", "worker", "worker"},
+			expected: []string{"", "zone-a", "zone-b", "zone-a"},
+		},
+		{
+			name:     "control-plane nodes without workers",
+			zones:    []string{"zone-a", "zone-b", "zone-c"},
+			roles:    []string{"control-plane", "control-plane", "control-plane"},
+			expected: []string{"zone-a", "zone-b", "zone-c"},
+		},
+		{
+			name:     "no zones",
+			roles:    []string{"control-plane", "worker"},
+			expected: []string{"", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			topology := &TopologyConfig{Zones: tt.zones}
+			if got := topology.NodeZones(tt.roles); !reflect.DeepEqual(got, tt.expected) {
+				test.Errorf("NodeZones() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateTopology(test *testing.T) {
+	workers := func(replicas int) []KindNode {
+		return []KindNode{{Role: "control-plane"}, {Role: "worker", Replicas: replicas}}
+	}
+
+	tests := []struct {
+		name    string
+		cluster ClusterConfig
+		wantErr string
+	}{
+		{
+			name:    "zones across workers",
+			cluster: ClusterConfig{Config: workers(3), Topology: &TopologyConfig{Zones: []string{"a", "b", "c"}}},
+		},
+		{
+			name:    "skewed zone",
+			cluster: ClusterConfig{Config: workers(2), Topology: &TopologyConfig{Zones: []string{"a", "b"}, Skew: map[string]map[string]string{"b": {"memory": "2Gi"}}}},
+		},
+		{
+			name:    "more zones than workers",
+			cluster: ClusterConfig{Config: workers(2), Topology: &TopologyConfig{Zones: []string{"a", "b", "c"}}},
+			wantErr: "need at least 3 worker nodes",
+		},
+		{
+			name:    "no zones",
+			cluster: ClusterConfig{Config: workers(2), Topology: &TopologyConfig{}},
+			wantErr: "at least one zone",
+		},
+		{
+			name:    "duplicate zone",
+			cluster: ClusterConfig{Config: workers(2), Topology: &TopologyConfig{Zones: []string{"a", "a"}}},
+			wantErr: "more than once",
+		},
+		{
+			name:    "skew of unknown zone",
+			cluster: ClusterConfig{Config: workers(2), Topology: &TopologyConfig{Zones: []string{"a", "b"}, Skew: map[string]map[string]string{"c": {"cpu": "1"}}}},
+			wantErr: "not in cluster.topology.zones",
+		},
+		{
+			name:    "skew of unknown resource",
+			cluster: ClusterConfig{Config: workers(2), Topology: &TopologyConfig{Zones: []string{"a", "b"}, Skew: map[string]map[string]string{"a": {"gpu": "1"}}}},
+			wantErr: "unknown resource",
+		},
+		{
+			name:    "external cluster",
+			cluster: ClusterConfig{External: &ExternalClusterConfig{Enabled: true}, Topology: &TopologyConfig{Zones: []string{"a"}}},
+			wantErr: "only available for kind clusters",
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			err := tt.cluster.validateTopology()
+			if tt.wantErr == "" {
+				if err != nil {
+					test.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				test.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithReservedSkew(test *testing.T) {
+	node := KindNode{Role: "worker", SystemReserved: map[string]string{"memory": "1Gi"}}
+	skewed := node.WithReservedSkew(map[string]string{"memory": "2Gi", "cpu": "500m"})
+
+	expected := map[string]string{"memory": "3Gi", "cpu": "500m"}
+	if !reflect.DeepEqual(skewed.SystemReserved, expected) {
+		test.Errorf("SystemReserved = %v, want %v", skewed.SystemReserved, expected)
+	}
+	if node.SystemReserved["memory"] != "1Gi" {
+		test.Errorf("original node was modified: %v", node.SystemReserved)
+	}
+}
@@ -1,32 +1,107 @@
 package config
 
 import (
+	"context"
 	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // Config represents the complete kraze.yml structure
 type Config struct {
+	Include  []string                 `yaml:"include,omitempty"` // Other kraze files whose services are added (local paths, http(s) or git:: URLs)
 	Cluster  ClusterConfig            `yaml:"cluster"`
 	Services map[string]ServiceConfig `yaml:"services"`
+	Lint     LintConfig               `yaml:"lint,omitempty"`
+	Charts   ChartsConfig             `yaml:"charts,omitempty"`
+
+	// Registries are credentials for private OCI registries, used for Helm
+	// chart pulls and pushes and for image pulls, on the host and in kind nodes
+	Registries []RegistryConfig `yaml:"registries,omitempty"`
+
+	// SuspendCronJobs installs every service's CronJobs suspended (services can override it)
+	SuspendCronJobs bool `yaml:"suspend_cronjobs,omitempty"`
+
+	// ResourcesOverride caps or strips the requests and limits of every
+	// service's rendered workloads (services can override it)
+	ResourcesOverride *ResourcesOverride `yaml:"resources_override,omitempty"`
+
+	// Discovery publishes the in-cluster address of every service in a
+	// ConfigMap (and optionally a host .env file)
+	Discovery *DiscoveryConfig `yaml:"discovery,omitempty"`
+
+	// Readiness tells the wait engine when resources of a kind are ready,
+	// keyed by Kind.group (e.g., Kafka.kafka.strimzi.io)
+	Readiness map[string]ReadinessRule `yaml:"readiness,omitempty"`
+
+	// Events delivers install, uninstall and cluster lifecycle events to a
+	// webhook, a JSONL file or an OpenTelemetry collector
+	Events *EventsConfig `yaml:"events,omitempty"`
+
+	// StateBackend is where kraze keeps the cluster state: a ConfigMap in
+	// kube-system (configmap, the default) or a KrazeEnvironment custom
+	// resource (crd), which kraze installs the CRD for
+	StateBackend string `yaml:"state_backend,omitempty"`
+
+	// origins records where each value came from, for 'kraze config view'
+	origins map[string]Origin
+}
+
+// ChartsConfig configures where `kraze chart push` publishes local charts
+type ChartsConfig struct {
+	Repository string `yaml:"repository,omitempty"` // OCI repository (e.g., oci://localhost:5000/charts)
+	PlainHTTP  bool   `yaml:"plain_http,omitempty"` // Push over HTTP, for local registries without TLS
+}
+
+// LintConfig controls which lint rules are reported
+type LintConfig struct {
+	Ignore []string `yaml:"ignore,omitempty"` // Rule IDs to suppress (e.g., ["KZ002"])
 }
 
 // ClusterConfig represents the cluster configuration
 type ClusterConfig struct {
-	Name               string                 `yaml:"name"`
-	Version            string                 `yaml:"version,omitempty"`
-	NodeImage          string                 `yaml:"node_image,omitempty"`
-	Config             []KindNode             `yaml:"config,omitempty"`
-	Networking         *NetworkingConfig      `yaml:"networking,omitempty"`
-	PreloadImages      []string               `yaml:"preload_images,omitempty"`
-	External           *ExternalClusterConfig `yaml:"external,omitempty"`
-	Network            string                 `yaml:"network,omitempty"`             // Docker network name (optional, auto-detected if not specified)
-	IPv4Address        string                 `yaml:"ipv4_address,omitempty"`        // Static IPv4 address for cluster container on Docker network
-	Subnet             string                 `yaml:"subnet,omitempty"`              // Docker network subnet (e.g., "172.1.0.0/16") - creates network if it doesn't exist
-	CACertificates     []string               `yaml:"ca_certificates,omitempty"`     // Paths to CA certificate files to trust in cluster nodes
-	InsecureRegistries []string               `yaml:"insecure_registries,omitempty"` // Registries to skip TLS verification (e.g., ["registry.corp.com"])
-	Proxy              *ProxyConfig           `yaml:"proxy,omitempty"`               // HTTP/HTTPS proxy configuration
-	GPU                *GPUConfig             `yaml:"gpu,omitempty"`                 // GPU support for cluster nodes (nvidia and/or amd)
+	Name                string                     `yaml:"name"`
+	Preset              string                     `yaml:"preset,omitempty"` // Built-in cluster preset (e.g., "ha-3node", "ingress-dev")
+	Addons              []string                   `yaml:"addons,omitempty"` // Built-in add-ons installed as services (e.g., ["cert-manager", "metallb"])
+	Version             string                     `yaml:"version,omitempty"`
+	NodeImage           string                     `yaml:"node_image,omitempty"`
+	Kubernetes          string                     `yaml:"kubernetes,omitempty"` // Tolerated Kubernetes version range (e.g., ">=1.28 <1.32"), checked at up time
+	Config              []KindNode                 `yaml:"config,omitempty"`
+	KindConfig          string                     `yaml:"kind_config,omitempty"` // kind v1alpha4 file used as is, instead of config (kraze adds the mounts and patches it needs)
+	Networking          *NetworkingConfig          `yaml:"networking,omitempty"`
+	Topology            *TopologyConfig            `yaml:"topology,omitempty"` // Synthetic zones and region labelled on the nodes
+	PreloadImages       []string                   `yaml:"preload_images,omitempty"`
+	External            *ExternalClusterConfig     `yaml:"external,omitempty"`
+	None                bool                       `yaml:"none,omitempty"`                 // No cluster: only render, validate, list images and plan (no Docker needed)
+	Network             string                     `yaml:"network,omitempty"`              // Docker network name (optional, auto-detected if not specified)
+	IPv4Address         string                     `yaml:"ipv4_address,omitempty"`         // Static IPv4 address for cluster container on Docker network
+	Subnet              string                     `yaml:"subnet,omitempty"`               // Docker network subnet (e.g., "172.1.0.0/16") - creates network if it doesn't exist
+	CACertificates      []string                   `yaml:"ca_certificates,omitempty"`      // Paths to CA certificate files to trust in cluster nodes
+	InsecureRegistries  []string                   `yaml:"insecure_registries,omitempty"`  // Registries to skip TLS verification (e.g., ["registry.corp.com"])
+	Proxy               *ProxyConfig               `yaml:"proxy,omitempty"`                // HTTP/HTTPS proxy configuration
+	GPU                 *GPUConfig                 `yaml:"gpu,omitempty"`                  // GPU support for cluster nodes (nvidia and/or amd)
+	Impersonate         *ImpersonateConfig         `yaml:"impersonate,omitempty"`          // Identity to install and uninstall services as (like kubectl --as)
+	RegistryCredentials *RegistryCredentialsConfig `yaml:"registry_credentials,omitempty"` // Host Docker credentials copied into the nodes for private image pulls
+	Variant             string                     `yaml:"-"`                              // Suffix of a variant cluster (kraze --suffix-cluster), whose host ports Docker picks
+
+	// Passed to kind as is, for the knobs kraze has no setting of its own for
+	FeatureGates         map[string]bool        `yaml:"feature_gates,omitempty"`          // Kubernetes feature gates of every component (e.g., {InPlacePodVerticalScaling: true})
+	RuntimeConfig        map[string]string      `yaml:"runtime_config,omitempty"`         // API server --runtime-config (e.g., {"api/alpha": "true"})
+	KubeadmConfigPatches []string               `yaml:"kubeadm_config_patches,omitempty"` // kubeadm patches applied to every node
+	KubeletConfig        map[string]interface{} `yaml:"kubelet_config,omitempty"`         // KubeletConfiguration fields of every node (e.g., {maxPods: 250})
 }
 
 // KindNode represents a kind node configuration
@@ -36,6 +111,66 @@ type KindNode struct {
 	ExtraPortMappings []PortMapping     `yaml:"extraPortMappings,omitempty"`
 	ExtraMounts       []Mount           `yaml:"extraMounts,omitempty"`
 	Labels            map[string]string `yaml:"labels,omitempty"`
+
+	// Kubelet reservations, so pods can't starve the API server and system daemons
+	SystemReserved map[string]string `yaml:"systemReserved,omitempty"` // Resources kept for the OS (e.g., {cpu: 500m, memory: 1Gi})
+	KubeReserved   map[string]string `yaml:"kubeReserved,omitempty"`   // Resources kept for the kubelet and container runtime
+	EvictionHard   map[string]string `yaml:"evictionHard,omitempty"`   // Eviction thresholds (e.g., {memory.available: 500Mi})
+
+	// Passed to kubeadm, the kubelet and the kernel of this node as is
+	KubeletExtraArgs     map[string]string `yaml:"kubeletExtraArgs,omitempty" json:",omitempty"`     // Kubelet flags of this node, without dashes (e.g., {max-pods: "250"})
+	KubeadmConfigPatches []string          `yaml:"kubeadmConfigPatches,omitempty" json:",omitempty"` // kubeadm patches applied to this node only
+	Sysctls              map[string]string `yaml:"sysctls,omitempty" json:",omitempty"`              // Kernel parameters set in the node (e.g., {fs.inotify.max_user_watches: "524288"})
+}
+
+// sha256Pattern matches a hex-encoded SHA-256 checksum
+var sha256Pattern = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+// reservableResources are the resources kubelet accepts in systemReserved and kubeReserved
+var reservableResources = []string{"cpu", "memory", "ephemeral-storage", "pid"}
+
+// evictionSignals are the signals kubelet accepts in evictionHard
+var evictionSignals = []string{
+	"memory.available",
+	"nodefs.available", "nodefs.inodesFree",
+	"imagefs.available", "imagefs.inodesFree",
+	"containerfs.available", "containerfs.inodesFree",
+	"pid.available",
+}
+
+// ValidateReservations checks the node's kubelet reservations and eviction thresholds
+func (node *KindNode) ValidateReservations(field string) error {
+	reserved := []struct {
+		name   string
+		values map[string]string
+	}{
+		{"systemReserved", node.SystemReserved},
+		{"kubeReserved", node.KubeReserved},
+	}
+	for _, itr := range reserved {
+		for key, value := range itr.values {
+			if !slices.Contains(reservableResources, key) {
+				return &ValidationError{Field: field + "." + itr.name, Message: fmt.Sprintf("unknown resource '%s' (supported: %s)", key, strings.Join(reservableResources, ", "))}
+			}
+			if _, err := resource.ParseQuantity(value); err != nil {
+				return &ValidationError{Field: field + "." + itr.name + "." + key, Message: fmt.Sprintf("'%s' is not a valid quantity", value)}
+			}
+		}
+	}
+
+	for signal, value := range node.EvictionHard {
+		if !slices.Contains(evictionSignals, signal) {
+			return &ValidationError{Field: field + ".evictionHard", Message: fmt.Sprintf("unknown eviction signal '%s' (supported: %s)", signal, strings.Join(evictionSignals, ", "))}
+		}
+		if percent, isPercent := strings.CutSuffix(value, "%"); isPercent {
+			if number, err := strconv.ParseFloat(percent, 64); err != nil || number < 0 || number > 100 {
+				return &ValidationError{Field: field + ".evictionHard." + signal, Message: fmt.Sprintf("'%s' is not a valid percentage", value)}
+			}
+		} else if _, err := resource.ParseQuantity(value); err != nil {
+			return &ValidationError{Field: field + ".evictionHard." + signal, Message: fmt.Sprintf("'%s' is not a valid quantity or percentage", value)}
+		}
+	}
+	return nil
 }
 
 // PortMapping represents a port mapping from container to host
@@ -94,6 +229,19 @@ func (g *GPUConfig) IsAnyEnabled() bool {
 	return g.IsNvidiaEnabled() || g.IsAMDEnabled()
 }
 
+// RegistryCredentialsConfig copies the host's Docker registry credentials into
+// each node's kubelet, so pods pull private images without imagePullSecrets
+type RegistryCredentialsConfig struct {
+	Enabled    bool     `yaml:"enabled"`
+	Config     string   `yaml:"config,omitempty"`     // Docker config file (default: $DOCKER_CONFIG/config.json or ~/.docker/config.json)
+	Registries []string `yaml:"registries,omitempty"` // Only copy credentials for these registries (default: all)
+}
+
+// IsEnabled returns true if registry credentials should be copied into the nodes
+func (creds *RegistryCredentialsConfig) IsEnabled() bool {
+	return creds != nil && creds.Enabled
+}
+
 // ExternalClusterConfig represents configuration for using an existing cluster
 type ExternalClusterConfig struct {
 	Enabled    bool   `yaml:"enabled"`              // Use external cluster instead of creating one
@@ -101,11 +249,53 @@ type ExternalClusterConfig struct {
 	Context    string `yaml:"context,omitempty"`    // Kubernetes context to use (default: current-context)
 }
 
+// ImpersonateConfig is the user or service account (and groups) that services are
+// installed as, for testing RBAC in shared clusters
+type ImpersonateConfig struct {
+	User           string   `yaml:"user,omitempty"`            // User name (e.g., "jane@example.com")
+	ServiceAccount string   `yaml:"service_account,omitempty"` // Service account as "namespace/name"
+	Groups         []string `yaml:"groups,omitempty"`          // Groups to impersonate (require user or service_account)
+}
+
+// UserName returns the user name to impersonate, expanding service_account to
+// system:serviceaccount:<namespace>:<name>
+func (imp *ImpersonateConfig) UserName() string {
+	if imp == nil {
+		return ""
+	}
+	if namespace, name, ok := strings.Cut(imp.ServiceAccount, "/"); ok {
+		return fmt.Sprintf("system:serviceaccount:%s:%s", namespace, name)
+	}
+	return imp.User
+}
+
+// Validate checks that exactly one identity is set and groups have a user
+func (imp *ImpersonateConfig) Validate() error {
+	if imp.User != "" && imp.ServiceAccount != "" {
+		return &ValidationError{Field: "cluster.impersonate", Message: "user and service_account are mutually exclusive"}
+	}
+	if imp.ServiceAccount != "" {
+		namespace, name, ok := strings.Cut(imp.ServiceAccount, "/")
+		if !ok || namespace == "" || name == "" || strings.Contains(name, "/") {
+			return &ValidationError{Field: "cluster.impersonate.service_account", Message: fmt.Sprintf("'%s' must be in the form namespace/name", imp.ServiceAccount)}
+		}
+	}
+	if imp.UserName() == "" {
+		return &ValidationError{Field: "cluster.impersonate", Message: "user or service_account is required (Kubernetes can't impersonate groups without a user)"}
+	}
+	return nil
+}
+
 // IsExternal returns true if this cluster configuration is for an external cluster
 func (c *ClusterConfig) IsExternal() bool {
 	return c.External != nil && c.External.Enabled
 }
 
+// IsNone returns true if this configuration is used without any cluster
+func (c *ClusterConfig) IsNone() bool {
+	return c.None
+}
+
 // ValuesField represents a values file or array of values files
 // Supports both: values: "single.yaml" and values: ["base.yaml", "override.yaml"]
 type ValuesField struct {
@@ -154,13 +344,188 @@ func (v ValuesField) IsEmpty() bool {
 	return len(v.files) == 0
 }
 
+// Dependency conditions
+const (
+	ConditionServiceStarted = "service_started" // Dependency installed (its resources ready when waiting is enabled)
+	ConditionServiceHealthy = "service_healthy" // Dependency's probe succeeds
+)
+
+// defaultDependencyTimeout bounds how long a dependency's probe is retried
+const defaultDependencyTimeout = 5 * time.Minute
+
+// DependencyCondition is what a service waits for from one of its dependencies
+// before it is installed
+type DependencyCondition struct {
+	Condition string   `yaml:"condition,omitempty"` // service_started (default) or service_healthy
+	Probe     string   `yaml:"probe,omitempty"`     // tcp://host:port or http(s)://host[:port]/path, checked from inside the cluster
+	Exec      []string `yaml:"exec,omitempty"`      // Command run in a pod of the dependency (e.g., ["pg_isready", "-U", "postgres"])
+	Timeout   string   `yaml:"timeout,omitempty"`   // How long to retry the probe (default: 5m)
+}
+
+// IsHealthCheck returns true if the dependency must pass a probe
+func (dc DependencyCondition) IsHealthCheck() bool {
+	return dc.Condition == ConditionServiceHealthy
+}
+
+// GetTimeout returns how long to retry the probe, defaulting to 5 minutes
+func (dc DependencyCondition) GetTimeout() (time.Duration, error) {
+	if dc.Timeout == "" {
+		return defaultDependencyTimeout, nil
+	}
+	return time.ParseDuration(dc.Timeout)
+}
+
+// ProbeDescription returns the probe in a readable form (e.g., "tcp://postgres:5432")
+func (dc DependencyCondition) ProbeDescription() string {
+	if len(dc.Exec) > 0 {
+		return "exec " + strings.Join(dc.Exec, " ")
+	}
+	return dc.Probe
+}
+
+// validate checks the condition's fields
+func (dc DependencyCondition) validate(field string) error {
+	switch dc.Condition {
+	case "", ConditionServiceStarted:
+		if dc.Probe != "" || len(dc.Exec) > 0 {
+			return &ValidationError{Field: field, Message: "probe and exec require condition: service_healthy"}
+		}
+	case ConditionServiceHealthy:
+		if dc.Probe == "" && len(dc.Exec) == 0 {
+			return &ValidationError{Field: field, Message: "condition service_healthy requires a probe or exec"}
+		}
+		if dc.Probe != "" && len(dc.Exec) > 0 {
+			return &ValidationError{Field: field, Message: "probe and exec are mutually exclusive"}
+		}
+		if dc.Probe != "" {
+			if _, err := ParseProbe(dc.Probe); err != nil {
+				return &ValidationError{Field: field + ".probe", Message: err.Error()}
+			}
+		}
+	default:
+		return &ValidationError{Field: field + ".condition", Message: fmt.Sprintf("unknown condition '%s' (supported: %s, %s)", dc.Condition, ConditionServiceStarted, ConditionServiceHealthy)}
+	}
+
+	if timeout, err := dc.GetTimeout(); err != nil || timeout <= 0 {
+		return &ValidationError{Field: field + ".timeout", Message: fmt.Sprintf("invalid duration '%s'", dc.Timeout)}
+	}
+	return nil
+}
+
+// Probe is a parsed dependency probe URL
+type Probe struct {
+	Scheme string // tcp, http or https
+	Host   string
+	Port   string
+	URL    string // Full URL for http(s) probes
+}
+
+// ParseProbe parses a tcp://host:port or http(s):// probe URL
+func ParseProbe(raw string) (*Probe, error) {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid probe '%s': %w", raw, err)
+	}
+
+	probe := &Probe{Scheme: parsed.Scheme, Host: parsed.Hostname(), Port: parsed.Port()}
+	switch parsed.Scheme {
+	case "tcp":
+		if probe.Port == "" {
+			return nil, fmt.Errorf("tcp probe '%s' requires a port (e.g., tcp://postgres:5432)", raw)
+		}
+	case "http", "https":
+		probe.URL = raw
+	default:
+		return nil, fmt.Errorf("unsupported probe '%s' (use tcp://host:port or http(s)://host/path)", raw)
+	}
+	if probe.Host == "" {
+		return nil, fmt.Errorf("probe '%s' requires a host", raw)
+	}
+	return probe, nil
+}
+
+// DependsOnField is a service's dependencies: a list of service names, or a map
+// of service names to the condition to wait for
+type DependsOnField struct {
+	names      []string
+	conditions map[string]DependencyCondition
+}
+
+// NewDependsOn returns dependencies on the named services with no conditions
+func NewDependsOn(names ...string) DependsOnField {
+	return DependsOnField{names: names}
+}
+
+// UnmarshalYAML implements custom unmarshaling for []string or map of conditions
+func (d *DependsOnField) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	// Try unmarshaling as []string first
+	var names []string
+	if err := unmarshal(&names); err == nil {
+		d.names = names
+		return nil
+	}
+
+	// Try unmarshaling as a map of service name to condition
+	var conditions map[string]*DependencyCondition
+	if err := unmarshal(&conditions); err == nil {
+		d.names = make([]string, 0, len(conditions))
+		d.conditions = make(map[string]DependencyCondition, len(conditions))
+		for name, condition := range conditions {
+			d.names = append(d.names, name)
+			if condition != nil {
+				d.conditions[name] = *condition
+			}
+		}
+		slices.Sort(d.names)
+		return nil
+	}
+
+	return fmt.Errorf("depends_on must be a list of service names or a map of service names to conditions")
+}
+
+// MarshalYAML implements custom marshaling
+func (d DependsOnField) MarshalYAML() (interface{}, error) {
+	if len(d.names) == 0 {
+		return nil, nil
+	}
+	if len(d.conditions) == 0 {
+		return d.names, nil
+	}
+	conditions := make(map[string]DependencyCondition, len(d.names))
+	for _, name := range d.names {
+		conditions[name] = d.conditions[name]
+	}
+	return conditions, nil
+}
+
+// Names returns the names of the services depended on
+func (d DependsOnField) Names() []string {
+	return d.names
+}
+
+// Condition returns the condition to wait for from a dependency
+func (d DependsOnField) Condition(name string) DependencyCondition {
+	return d.conditions[name]
+}
+
+// IsEmpty returns true if there are no dependencies
+func (d DependsOnField) IsEmpty() bool {
+	return len(d.names) == 0
+}
+
 // ServiceConfig represents a service definition
 type ServiceConfig struct {
-	Name      string   `yaml:"-"`    // Set from map key
-	Type      string   `yaml:"type"` // helm, manifests
-	Namespace string   `yaml:"namespace,omitempty"`
-	DependsOn []string `yaml:"depends_on,omitempty"`
-	Enabled   *bool    `yaml:"enabled,omitempty"` // Defaults to true; set to false to skip service
+	Name      string         `yaml:"-"`    // Set from map key
+	Type      string         `yaml:"type"` // helm, manifests
+	Namespace string         `yaml:"namespace,omitempty"`
+	DependsOn DependsOnField `yaml:"depends_on,omitempty"` // Service names, or a map of service names to conditions
+	Enabled   *bool          `yaml:"enabled,omitempty"`    // Defaults to true; set to false to skip service
+	Extends   *ExtendsConfig `yaml:"extends,omitempty"`    // Service whose definition this one inherits and overrides
+
+	// Documentation fields (informational only, surfaced by CLI commands)
+	Description string   `yaml:"description,omitempty"` // Human-readable summary of what the service is for
+	Owner       string   `yaml:"owner,omitempty"`       // Team or person responsible for the service
+	Links       []string `yaml:"links,omitempty"`       // Related URLs (runbooks, dashboards, repos)
 
 	// Common fields
 	CreateNamespace *bool             `yaml:"create_namespace,omitempty"` // Defaults to true
@@ -168,6 +533,10 @@ type ServiceConfig struct {
 	Wait            *bool             `yaml:"wait,omitempty"`             // Wait for resources to be ready (defaults to CLI flag)
 	WaitTimeout     string            `yaml:"wait_timeout,omitempty"`     // Timeout for wait operations (e.g., "10m", "5m")
 	PostReadyDelay  string            `yaml:"post_ready_delay,omitempty"` // Delay after service is ready before continuing (e.g., "3s", "5s")
+	PendingTimeout  string            `yaml:"pending_timeout,omitempty"`  // How long a pod may stay Pending for a diagnosed cause before the wait fails (e.g., "5m", default: 60s)
+	Retries         *int              `yaml:"retries,omitempty"`          // Retries of an install that failed transiently (defaults to --retry)
+	RetryBackoff    string            `yaml:"retry_backoff,omitempty"`    // Delay before the first retry, doubled after each (default: 10s)
+	Ports           []string          `yaml:"ports,omitempty"`            // Port forwards as [LOCAL_PORT:]REMOTE_PORT (e.g., ["8080:80"])
 
 	// Helm-specific fields
 	Repo         string      `yaml:"repo,omitempty"`          // Remote Helm repo URL
@@ -175,18 +544,349 @@ type ServiceConfig struct {
 	Version      string      `yaml:"version,omitempty"`       // Chart version
 	Values       ValuesField `yaml:"values,omitempty"`        // Values file path(s) - string or []string
 	ValuesInline string      `yaml:"values_inline,omitempty"` // Inline YAML values
+	ValuesEnv    bool        `yaml:"values_env,omitempty"`    // Expand environment variables in values files, not only --var and built-ins
 	KeepCRDs     *bool       `yaml:"keep_crds,omitempty"`     // Keep CRDs on uninstall (nil = use default)
+	WaitForJobs  *bool       `yaml:"wait_for_jobs,omitempty"` // Let Helm wait for resources and Jobs before post-install/upgrade hooks, like helm --wait --wait-for-jobs
 
 	// Path field used by both Helm (local chart) and Manifests (single file/dir)
 	Path  string   `yaml:"path,omitempty"`  // Local chart path (Helm) or manifest file/directory (Manifests)
 	Paths []string `yaml:"paths,omitempty"` // Multiple manifest files
 
+	// Remote manifests (path is an http(s) URL)
+	SHA256  string            `yaml:"sha256,omitempty"`  // Expected SHA-256 of the downloaded manifest
+	Headers map[string]string `yaml:"headers,omitempty"` // HTTP headers sent with the download (e.g., Authorization: Bearer ${TOKEN})
+
 	// Images is an explicit list of local Docker images to load into the kind
 	// cluster for this service. These supplement the images that kraze detects
 	// automatically from values files and manifests. Useful for images that are
 	// referenced in non-standard places (e.g., extraInitContainers YAML strings,
 	// operator-managed pods, or any location the auto-detector cannot reach).
 	Images []string `yaml:"images,omitempty"`
+
+	// Build builds the service's image locally before it is loaded into the
+	// cluster, like docker-compose's build section
+	Build *BuildConfig `yaml:"build,omitempty"`
+
+	// Dev configures the `kraze dev` watch loop for this service
+	Dev *DevConfig `yaml:"dev,omitempty"`
+
+	// RBAC runs the service's workloads under a generated ServiceAccount with
+	// only the permissions listed, so missing RBAC fails locally too
+	RBAC *RBACConfig `yaml:"rbac,omitempty"`
+
+	// PostRender patches a helm chart's rendered manifests before install, for
+	// third-party charts that can't be forked
+	PostRender *PostRenderConfig `yaml:"post_render,omitempty"`
+
+	// ResourcesOverride caps or strips the requests and limits of the
+	// service's rendered workloads (nil = use the global setting)
+	ResourcesOverride *ResourcesOverride `yaml:"resources_override,omitempty"`
+
+	// SuspendCronJobs installs the service's CronJobs suspended so scheduled
+	// jobs only run when triggered with `kraze run-cron` (nil = use the global setting)
+	SuspendCronJobs *bool `yaml:"suspend_cronjobs,omitempty"`
+
+	// Assertions are resources and field values the service must have once it's
+	// ready, checked with retries as lightweight acceptance tests
+	Assertions []AssertionConfig `yaml:"assertions,omitempty"`
+
+	// Hooks run local commands or in-cluster Jobs before and after the service
+	// installs and uninstalls
+	Hooks *HooksConfig `yaml:"hooks,omitempty"`
+
+	// Workloads mark resources of the service as optional, so they only warn
+	// when they aren't ready, or give them a longer wait_timeout
+	Workloads []WorkloadReadiness `yaml:"workloads,omitempty"`
+
+	// Fixtures are seed data (SQL files, HTTP requests, seed manifests) loaded in
+	// order once the service is ready, skipped on re-runs while unchanged
+	Fixtures []FixtureConfig `yaml:"fixtures,omitempty"`
+
+	// Secrets are Kubernetes Secrets created before the service installs, from
+	// environment variables, files, 1Password or Vault, so credentials stay out of the repo
+	Secrets []SecretConfig `yaml:"secrets,omitempty"`
+
+	// LocalTLS mints a certificate for the service's ingress hosts from kraze's
+	// local CA and adds it to the Ingresses that serve them
+	LocalTLS *LocalTLSConfig `yaml:"local_tls,omitempty"`
+
+	// InjectDiscovery adds the discovery ConfigMap to the service's containers
+	// with envFrom (nil = use discovery.inject)
+	InjectDiscovery *bool `yaml:"inject_discovery,omitempty"`
+
+	// DiscoveryConfigMap is the discovery ConfigMap the service's containers
+	// load, empty when it isn't injected (set from the discovery block)
+	DiscoveryConfigMap string `yaml:"-"`
+
+	// Vars expands ${NAME} references in the service's values files (set to the
+	// variables of the config file that defines the service)
+	Vars *Variables `yaml:"-"`
+}
+
+// ReadValuesFile reads one of the service's values files, decrypted when it's
+// SOPS-encrypted, with ${NAME} references expanded (see Variables.ExpandValues)
+func (svc *ServiceConfig) ReadValuesFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = DecryptIfSOPS(context.Background(), path, data); err != nil {
+		return nil, err
+	}
+	return []byte(svc.Build.SubstituteImage(string(svc.Vars.ExpandValues(data, svc.ValuesEnv)))), nil
+}
+
+// DevConfig configures how `kraze dev` rebuilds and reloads a service when its
+// sources change
+type DevConfig struct {
+	Watch  []string `yaml:"watch,omitempty"`  // Files or directories to watch (relative to the config file)
+	Ignore []string `yaml:"ignore,omitempty"` // Glob patterns to skip, matched against names and relative paths (e.g., ["*.log", "node_modules"])
+	Build  string   `yaml:"build,omitempty"`  // Shell command that rebuilds the service's images (e.g., "docker build -t api:dev ./api")
+	Dir    string   `yaml:"-"`                // Directory the build command runs in (set to the config file's directory)
+}
+
+// RBACConfig describes the ServiceAccount, Role and RoleBinding kraze generates
+// in the service's namespace
+type RBACConfig struct {
+	ServiceAccount string       `yaml:"service_account,omitempty"` // ServiceAccount name (default: the service name)
+	ValuesKey      string       `yaml:"values_key,omitempty"`      // Helm values key the chart reads its ServiceAccount from (default: serviceAccount)
+	Rules          []PolicyRule `yaml:"rules,omitempty"`           // Role rules; without rules the account has no permissions
+}
+
+// PolicyRule is a Role rule, with the same fields as rbac.authorization.k8s.io/v1
+type PolicyRule struct {
+	APIGroups     []string `yaml:"apiGroups,omitempty"`
+	Resources     []string `yaml:"resources"`
+	ResourceNames []string `yaml:"resourceNames,omitempty"`
+	Verbs         []string `yaml:"verbs"`
+}
+
+// GetServiceAccount returns the ServiceAccount name for a service
+func (rbac *RBACConfig) GetServiceAccount(serviceName string) string {
+	if rbac.ServiceAccount != "" {
+		return rbac.ServiceAccount
+	}
+	return serviceName
+}
+
+// GetValuesKey returns the dotted Helm values key that takes the ServiceAccount
+func (rbac *RBACConfig) GetValuesKey() string {
+	if rbac.ValuesKey != "" {
+		return rbac.ValuesKey
+	}
+	return "serviceAccount"
+}
+
+// validate checks the account name and that every rule grants something
+func (rbac *RBACConfig) validate(serviceName string) error {
+	if errs := validation.IsDNS1123Subdomain(rbac.GetServiceAccount(serviceName)); len(errs) > 0 {
+		return &ValidationError{Field: "rbac.service_account", Message: fmt.Sprintf("invalid ServiceAccount name '%s': %s", rbac.GetServiceAccount(serviceName), strings.Join(errs, "; "))}
+	}
+	if strings.HasPrefix(rbac.ValuesKey, ".") || strings.HasSuffix(rbac.ValuesKey, ".") || strings.Contains(rbac.ValuesKey, "..") {
+		return &ValidationError{Field: "rbac.values_key", Message: fmt.Sprintf("invalid values key '%s'", rbac.ValuesKey)}
+	}
+	for itr, rule := range rbac.Rules {
+		if len(rule.Verbs) == 0 {
+			return &ValidationError{Field: fmt.Sprintf("rbac.rules[%d].verbs", itr), Message: "at least one verb is required"}
+		}
+		if len(rule.Resources) == 0 {
+			return &ValidationError{Field: fmt.Sprintf("rbac.rules[%d].resources", itr), Message: "at least one resource is required"}
+		}
+	}
+	return nil
+}
+
+// PostRenderConfig modifies a helm chart's rendered manifests before install.
+// Patches are applied first, then the command.
+type PostRenderConfig struct {
+	Patches []PostRenderPatch `yaml:"patches,omitempty"` // Kustomize patches, applied in order
+	Exec    string            `yaml:"exec,omitempty"`    // Command that reads the manifests on stdin and writes the result to stdout
+	Args    []string          `yaml:"args,omitempty"`    // Arguments for the command
+	Dir     string            `yaml:"-"`                 // Directory the command runs in (set to the config file's directory)
+}
+
+// PostRenderPatch is a strategic merge patch, or a JSON6902 patch when Patch is
+// a list of operations, applied to the resources matching Target
+type PostRenderPatch struct {
+	Target *PatchTarget `yaml:"target,omitempty"` // Required for JSON6902 patches; strategic merge patches match by their own kind and name when omitted
+	Patch  string       `yaml:"patch"`
+}
+
+// PatchTarget selects the resources a patch applies to, like a kustomize patch
+// target. Group, version, kind, name and namespace are regular expressions.
+type PatchTarget struct {
+	Group              string `yaml:"group,omitempty"`
+	Version            string `yaml:"version,omitempty"`
+	Kind               string `yaml:"kind,omitempty"`
+	Name               string `yaml:"name,omitempty"`
+	Namespace          string `yaml:"namespace,omitempty"`
+	LabelSelector      string `yaml:"labelSelector,omitempty"`
+	AnnotationSelector string `yaml:"annotationSelector,omitempty"`
+}
+
+// IsJSON6902 returns true if the patch is a list of JSON6902 operations rather
+// than a strategic merge patch
+func (patch PostRenderPatch) IsJSON6902() bool {
+	var operations []interface{}
+	return yaml.Unmarshal([]byte(patch.Patch), &operations) == nil && len(operations) > 0
+}
+
+// validate checks that every patch is YAML and JSON6902 patches have a target
+func (postRender *PostRenderConfig) validate() error {
+	if len(postRender.Patches) == 0 && postRender.Exec == "" {
+		return &ValidationError{Field: "post_render", Message: "at least one patch or an exec command is required"}
+	}
+	for itr, patch := range postRender.Patches {
+		field := fmt.Sprintf("post_render.patches[%d]", itr)
+		if strings.TrimSpace(patch.Patch) == "" {
+			return &ValidationError{Field: field + ".patch", Message: "patch is required"}
+		}
+		var content interface{}
+		if err := yaml.Unmarshal([]byte(patch.Patch), &content); err != nil {
+			return &ValidationError{Field: field + ".patch", Message: fmt.Sprintf("invalid YAML: %v", err)}
+		}
+		if patch.IsJSON6902() && patch.Target == nil {
+			return &ValidationError{Field: field + ".target", Message: "target is required for JSON6902 patches"}
+		}
+		if target := patch.Target; target != nil {
+			for _, selector := range [][2]string{{"labelSelector", target.LabelSelector}, {"annotationSelector", target.AnnotationSelector}} {
+				if _, err := labels.Parse(selector[1]); err != nil {
+					return &ValidationError{Field: field + ".target." + selector[0], Message: fmt.Sprintf("invalid selector '%s': %v", selector[1], err)}
+				}
+			}
+		}
+	}
+	if postRender.Exec == "" && len(postRender.Args) > 0 {
+		return &ValidationError{Field: "post_render.args", Message: "args require an exec command"}
+	}
+	return nil
+}
+
+// Image builders of a build block
+const (
+	BuildDocker     = "docker"     // Dockerfile built with Docker/BuildKit (default)
+	BuildKo         = "ko"         // Go main package built with ko, no Dockerfile needed
+	BuildBuildpacks = "buildpacks" // Source built with Cloud Native Buildpacks (pack)
+)
+
+// DefaultBuildpacksBuilder is the builder image of buildpacks builds without one
+const DefaultBuildpacksBuilder = "paketobuildpacks/builder-jammy-base"
+
+// koReferencePrefix marks the image references ko builds replace, as in
+// "ko://github.com/acme/api/cmd/api"
+const koReferencePrefix = "ko://"
+
+// BuildConfig describes how to build a service's image with Docker/BuildKit, ko or buildpacks
+type BuildConfig struct {
+	Type       string            `yaml:"type,omitempty"`        // docker (default), ko or buildpacks
+	Image      string            `yaml:"image"`                 // Tag of the built image, e.g. api:dev (must be referenced by the service's values or manifests)
+	Context    string            `yaml:"context"`               // Build context directory (relative to the config file)
+	Dockerfile string            `yaml:"dockerfile,omitempty"`  // Dockerfile path relative to the context (default: Dockerfile)
+	Args       map[string]string `yaml:"args,omitempty"`        // Build arguments (--build-arg), or environment variables of ko and buildpacks builds
+	Target     string            `yaml:"target,omitempty"`      // Target stage of a multi-stage Dockerfile
+	ImportPath string            `yaml:"import_path,omitempty"` // ko: Go main package to build, relative to the context (default: .)
+	Builder    string            `yaml:"builder,omitempty"`     // buildpacks: builder image (default: paketobuildpacks/builder-jammy-base)
+}
+
+// GetType returns the image builder, defaulting to docker
+func (build *BuildConfig) GetType() string {
+	if build.Type == "" {
+		return BuildDocker
+	}
+	return build.Type
+}
+
+// GetImportPath returns the Go package a ko build compiles
+func (build *BuildConfig) GetImportPath() string {
+	if build.ImportPath == "" {
+		return "."
+	}
+	return build.ImportPath
+}
+
+// GetBuilder returns the builder image of a buildpacks build
+func (build *BuildConfig) GetBuilder() string {
+	if build.Builder == "" {
+		return DefaultBuildpacksBuilder
+	}
+	return build.Builder
+}
+
+// SubstituteImage replaces the ko:// references of a ko build's package in
+// manifests or values with the built image, so they can refer to the image
+// the way they would with ko itself. Both "ko://<import path>" and the
+// package's path relative to the context ("ko://./cmd/api") are replaced.
+func (build *BuildConfig) SubstituteImage(content string) string {
+	if build == nil || build.GetType() != BuildKo || !strings.Contains(content, koReferencePrefix) {
+		return content
+	}
+	importPath := build.GetImportPath()
+	references := []string{koReferencePrefix + importPath}
+	if modulePath := goModulePath(build.Context); modulePath != "" && !strings.HasPrefix(importPath, modulePath) {
+		references = append(references, koReferencePrefix+path.Join(modulePath, importPath))
+	}
+	for _, reference := range references {
+		content = replaceImageReference(content, reference, build.Image)
+	}
+	return content
+}
+
+// IsKoReference returns whether an image reference is a ko:// placeholder
+func IsKoReference(image string) bool {
+	return strings.HasPrefix(image, koReferencePrefix)
+}
+
+// replaceImageReference replaces a reference where it is a whole image name,
+// not the start of a longer one
+func replaceImageReference(content, reference, image string) string {
+	var result strings.Builder
+	for {
+		index := strings.Index(content, reference)
+		if index < 0 {
+			result.WriteString(content)
+			return result.String()
+		}
+		end := index + len(reference)
+		result.WriteString(content[:index])
+		if end < len(content) && isImageNameChar(content[end]) {
+			result.WriteString(reference)
+		} else {
+			result.WriteString(image)
+		}
+		content = content[end:]
+	}
+}
+
+// isImageNameChar returns whether a character can continue an image name or Go import path
+func isImageNameChar(char byte) bool {
+	return char == '/' || char == '.' || char == '-' || char == '_' ||
+		(char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9')
+}
+
+// goModulePath returns the module path declared by the go.mod in dir, empty when there is none
+func goModulePath(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if modulePath, found := strings.CutPrefix(strings.TrimSpace(line), "module "); found {
+			return strings.Trim(strings.TrimSpace(modulePath), `"`)
+		}
+	}
+	return ""
+}
+
+// GetDockerfile returns the Dockerfile path, resolved against the build context
+func (build *BuildConfig) GetDockerfile() string {
+	dockerfile := build.Dockerfile
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+	if filepath.IsAbs(dockerfile) {
+		return dockerfile
+	}
+	return filepath.Join(build.Context, dockerfile)
 }
 
 // IsHelm returns true if this service is a Helm chart
@@ -209,6 +909,21 @@ func (srv *ServiceConfig) IsRemoteChart() bool {
 	return srv.IsHelm() && srv.Repo != ""
 }
 
+// GetDefinitionPaths returns the local files that define the service's resources:
+// manifest files/directories, a local chart and values files. Remote paths are skipped.
+func (srv *ServiceConfig) GetDefinitionPaths() []string {
+	var paths []string
+	if srv.Path != "" && !IsHTTPURL(srv.Path) {
+		paths = append(paths, srv.Path)
+	}
+	for _, path := range srv.Paths {
+		if !IsHTTPURL(path) {
+			paths = append(paths, path)
+		}
+	}
+	return append(paths, srv.Values.Files()...)
+}
+
 // GetNamespace returns the namespace for this service, defaulting to "default"
 func (srv *ServiceConfig) GetNamespace() string {
 	if srv.Namespace != "" {
@@ -217,6 +932,12 @@ func (srv *ServiceConfig) GetNamespace() string {
 	return "default"
 }
 
+// ShouldWaitForJobs returns whether Helm waits for the release's resources and
+// Jobs before running post-install and post-upgrade hooks, defaulting to false
+func (srv *ServiceConfig) ShouldWaitForJobs() bool {
+	return srv.WaitForJobs != nil && *srv.WaitForJobs
+}
+
 // ShouldCreateNamespace returns whether to create the namespace, defaulting to true
 func (srv *ServiceConfig) ShouldCreateNamespace() bool {
 	if srv.CreateNamespace != nil {
@@ -225,6 +946,15 @@ func (srv *ServiceConfig) ShouldCreateNamespace() bool {
 	return true // Default to true for local dev convenience
 }
 
+// ShouldSuspendCronJobs returns whether a service's CronJobs are suspended after
+// install, falling back to the global suspend_cronjobs setting
+func (cfg *Config) ShouldSuspendCronJobs(srv *ServiceConfig) bool {
+	if srv.SuspendCronJobs != nil {
+		return *srv.SuspendCronJobs
+	}
+	return cfg.SuspendCronJobs
+}
+
 // IsEnabled returns whether this service is enabled, defaulting to true for backward compatibility
 func (srv *ServiceConfig) IsEnabled() bool {
 	if srv.Enabled != nil {
@@ -233,6 +963,34 @@ func (srv *ServiceConfig) IsEnabled() bool {
 	return true
 }
 
+// GetSummary returns the first line of the service description, suitable for
+// single-line displays such as shell completion hints and tables
+func (srv *ServiceConfig) GetSummary() string {
+	summary := strings.TrimSpace(srv.Description)
+	if idx := strings.IndexByte(summary, '\n'); idx != -1 {
+		summary = strings.TrimSpace(summary[:idx])
+	}
+	return summary
+}
+
+// defaultPendingTimeout tolerates transient Pending states: the scheduler
+// retries unschedulable pods and volumes take a few seconds to provision
+const defaultPendingTimeout = 60 * time.Second
+
+// GetPendingTimeout returns how long a pod may stay Pending for a diagnosed
+// cause before the wait fails, defaulting to 60 seconds
+func (srv *ServiceConfig) GetPendingTimeout() (time.Duration, error) {
+	if srv.PendingTimeout == "" {
+		return defaultPendingTimeout, nil
+	}
+
+	duration, err := time.ParseDuration(srv.PendingTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid pending_timeout '%s': %w", srv.PendingTimeout, err)
+	}
+	return duration, nil
+}
+
 // GetPostReadyDelay returns the post-ready delay duration, defaulting to 3 seconds
 // This delay helps with kube-proxy propagation and service endpoint readiness
 func (srv *ServiceConfig) GetPostReadyDelay() (time.Duration, error) {
@@ -262,6 +1020,12 @@ func (srv *ServiceConfig) Validate() error {
 		return &ValidationError{Field: "type", Message: "type must be 'helm' or 'manifests'"}
 	}
 
+	for _, dep := range srv.DependsOn.Names() {
+		if err := srv.DependsOn.Condition(dep).validate("depends_on." + dep); err != nil {
+			return err
+		}
+	}
+
 	// Helm validation
 	if srv.IsHelm() {
 		if srv.IsLocalChart() && srv.IsRemoteChart() {
@@ -286,10 +1050,200 @@ func (srv *ServiceConfig) Validate() error {
 			return &ValidationError{Field: "manifests", Message: "must specify either 'path' or 'paths' for manifests"}
 		}
 	}
+	remote := srv.IsManifests() && IsHTTPURL(srv.Path)
+	if srv.SHA256 != "" {
+		if !remote {
+			return &ValidationError{Field: "sha256", Message: "sha256 only applies to manifests with a remote (http or https) path"}
+		}
+		if !sha256Pattern.MatchString(srv.SHA256) {
+			return &ValidationError{Field: "sha256", Message: fmt.Sprintf("invalid checksum '%s': expected 64 hexadecimal characters", srv.SHA256)}
+		}
+	}
+	if len(srv.Headers) > 0 && !remote {
+		return &ValidationError{Field: "headers", Message: "headers only apply to manifests with a remote (http or https) path"}
+	}
+
+	// Labels select the service's resources, so they must be valid Kubernetes labels
+	for _, key := range slices.Sorted(maps.Keys(srv.Labels)) {
+		if err := validateLabel(key, srv.Labels[key]); err != nil {
+			return &ValidationError{Field: "labels." + key, Message: err.Error()}
+		}
+	}
+
+	for _, duration := range [][2]string{{"wait_timeout", srv.WaitTimeout}, {"post_ready_delay", srv.PostReadyDelay}, {"pending_timeout", srv.PendingTimeout}} {
+		if duration[1] == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(duration[1]); err != nil {
+			return &ValidationError{Field: duration[0], Message: fmt.Sprintf("invalid duration '%s': %v", duration[1], err)}
+		}
+	}
+	if timeout, _ := srv.GetPendingTimeout(); timeout <= 0 {
+		return &ValidationError{Field: "pending_timeout", Message: "must be positive"}
+	}
+
+	// Port forward validation
+	for _, spec := range srv.Ports {
+		if _, err := ParsePortForward(spec); err != nil {
+			return &ValidationError{Field: "ports", Message: err.Error()}
+		}
+	}
+
+	// Build validation
+	if srv.Build != nil {
+		if srv.Build.Image == "" {
+			return &ValidationError{Field: "build.image", Message: "image tag to build is required"}
+		}
+		if srv.Build.Context == "" {
+			return &ValidationError{Field: "build.context", Message: "build context directory is required"}
+		}
+		switch srv.Build.GetType() {
+		case BuildDocker:
+			if srv.Build.ImportPath != "" || srv.Build.Builder != "" {
+				return &ValidationError{Field: "build", Message: "import_path and builder only apply to ko and buildpacks builds"}
+			}
+		case BuildKo, BuildBuildpacks:
+			if srv.Build.Dockerfile != "" || srv.Build.Target != "" {
+				return &ValidationError{Field: "build", Message: fmt.Sprintf("dockerfile and target don't apply to %s builds", srv.Build.Type)}
+			}
+			if srv.Build.Type == BuildKo && srv.Build.Builder != "" {
+				return &ValidationError{Field: "build.builder", Message: "builder only applies to buildpacks builds"}
+			}
+			if srv.Build.Type == BuildBuildpacks && srv.Build.ImportPath != "" {
+				return &ValidationError{Field: "build.import_path", Message: "import_path only applies to ko builds"}
+			}
+		default:
+			return &ValidationError{Field: "build.type", Message: fmt.Sprintf("unknown build type '%s' (expected docker, ko or buildpacks)", srv.Build.Type)}
+		}
+	}
+
+	// Dev loop validation
+	if srv.Dev != nil {
+		if len(srv.Dev.Watch) == 0 {
+			return &ValidationError{Field: "dev.watch", Message: "at least one path to watch is required"}
+		}
+		for _, pattern := range srv.Dev.Ignore {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return &ValidationError{Field: "dev.ignore", Message: fmt.Sprintf("invalid pattern '%s': %v", pattern, err)}
+			}
+		}
+	}
+
+	// Post-render validation
+	if srv.PostRender != nil {
+		if !srv.IsHelm() {
+			return &ValidationError{Field: "post_render", Message: "post_render is only supported for helm services"}
+		}
+		if err := srv.PostRender.validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := srv.ResourcesOverride.Validate("resources_override"); err != nil {
+		return err
+	}
+	if err := srv.validateRetries(); err != nil {
+		return err
+	}
+
+	// RBAC sandbox validation
+	if srv.RBAC != nil {
+		if err := srv.RBAC.validate(srv.Name); err != nil {
+			return err
+		}
+	}
+
+	if err := srv.validateSecrets(); err != nil {
+		return err
+	}
+	if err := srv.validateLocalTLS(); err != nil {
+		return err
+	}
+
+	// Hook validation
+	if srv.Hooks != nil {
+		if err := srv.Hooks.validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := srv.validateWorkloads(); err != nil {
+		return err
+	}
+
+	if err := validateFixtures(srv.Fixtures); err != nil {
+		return err
+	}
+
+	// Assertion validation
+	for itr := range srv.Assertions {
+		if err := srv.Assertions[itr].validate(fmt.Sprintf("assertions[%d]", itr)); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
+// PortForward is a local to remote port mapping for a service
+type PortForward struct {
+	LocalPort  int
+	RemotePort int
+}
+
+func (pf PortForward) String() string {
+	return fmt.Sprintf("%d:%d", pf.LocalPort, pf.RemotePort)
+}
+
+// ParsePortForward parses a port forward in the form [LOCAL_PORT:]REMOTE_PORT
+func ParsePortForward(spec string) (PortForward, error) {
+	parts := strings.Split(spec, ":")
+
+	var localPort, remotePort int
+	var err error
+
+	if len(parts) == 1 {
+		// Just remote port, use same for local
+		remotePort, err = strconv.Atoi(parts[0])
+		if err != nil {
+			return PortForward{}, fmt.Errorf("invalid port number '%s': %w", parts[0], err)
+		}
+		localPort = remotePort
+	} else if len(parts) == 2 {
+		// Local:Remote
+		localPort, err = strconv.Atoi(parts[0])
+		if err != nil {
+			return PortForward{}, fmt.Errorf("invalid local port '%s': %w", parts[0], err)
+		}
+		remotePort, err = strconv.Atoi(parts[1])
+		if err != nil {
+			return PortForward{}, fmt.Errorf("invalid remote port '%s': %w", parts[1], err)
+		}
+	} else {
+		return PortForward{}, fmt.Errorf("invalid port mapping '%s': expected format [LOCAL_PORT:]REMOTE_PORT", spec)
+	}
+
+	if localPort < 1 || localPort > 65535 {
+		return PortForward{}, fmt.Errorf("local port %d out of range (1-65535)", localPort)
+	}
+	if remotePort < 1 || remotePort > 65535 {
+		return PortForward{}, fmt.Errorf("remote port %d out of range (1-65535)", remotePort)
+	}
+
+	return PortForward{LocalPort: localPort, RemotePort: remotePort}, nil
+}
+
+// GetPortForwards returns the parsed port forwards declared for the service
+func (srv *ServiceConfig) GetPortForwards() []PortForward {
+	forwards := make([]PortForward, 0, len(srv.Ports))
+	for _, spec := range srv.Ports {
+		if pf, err := ParsePortForward(spec); err == nil {
+			forwards = append(forwards, pf)
+		}
+	}
+	return forwards
+}
+
 // ValidationError represents a configuration validation error
 type ValidationError struct {
 	Field   string
@@ -302,3 +1256,17 @@ func (err *ValidationError) Error() string {
 	}
 	return err.Message
 }
+
+// ServiceValidationError is a validation error of one service
+type ServiceValidationError struct {
+	Service string
+	Err     error
+}
+
+func (err *ServiceValidationError) Error() string {
+	return fmt.Sprintf("service '%s': %v", err.Service, err.Err)
+}
+
+func (err *ServiceValidationError) Unwrap() error {
+	return err.Err
+}
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestServiceConfigGetNamespace(test *testing.T) {
@@ -238,6 +241,146 @@ func TestConfigValidate(test *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "no cluster",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test", None: true},
+			},
+			wantErr: false,
+		},
+		{
+			name: "no cluster and external cluster",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test", None: true, External: &ExternalClusterConfig{Enabled: true}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "node reservations",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test", Config: []KindNode{{
+					Role:           "control-plane",
+					SystemReserved: map[string]string{"cpu": "500m", "memory": "1Gi"},
+					KubeReserved:   map[string]string{"memory": "512Mi", "pid": "1000"},
+					EvictionHard:   map[string]string{"memory.available": "300Mi", "nodefs.available": "5%"},
+				}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown reserved resource",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test", Config: []KindNode{{Role: "worker", KubeReserved: map[string]string{"gpu": "1"}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid reserved quantity",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test", Config: []KindNode{{Role: "worker", SystemReserved: map[string]string{"memory": "lots"}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown eviction signal",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test", Config: []KindNode{{Role: "worker", EvictionHard: map[string]string{"memory.free": "100Mi"}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid eviction percentage",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test", Config: []KindNode{{Role: "worker", EvictionHard: map[string]string{"nodefs.available": "150%"}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "pinned remote manifest",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"operator": {Name: "operator", Type: "manifests", Path: "https://example.com/operator.yaml",
+						SHA256: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", Headers: map[string]string{"Authorization": "Bearer token"}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid remote manifest checksum",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"operator": {Name: "operator", Type: "manifests", Path: "https://example.com/operator.yaml", SHA256: "abc123"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "checksum on local manifests",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"operator": {Name: "operator", Type: "manifests", Path: "./k8s", SHA256: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "healthy dependency with probe",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"db":  {Name: "db", Type: "manifests", Path: "db"},
+					"api": {Name: "api", Type: "manifests", Path: "api", DependsOn: DependsOnField{names: []string{"db"}, conditions: map[string]DependencyCondition{"db": {Condition: ConditionServiceHealthy, Exec: []string{"pg_isready"}}}}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "healthy dependency without probe",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"db":  {Name: "db", Type: "manifests", Path: "db"},
+					"api": {Name: "api", Type: "manifests", Path: "api", DependsOn: DependsOnField{names: []string{"db"}, conditions: map[string]DependencyCondition{"db": {Condition: ConditionServiceHealthy}}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "probe without healthy condition",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"db":  {Name: "db", Type: "manifests", Path: "db"},
+					"api": {Name: "api", Type: "manifests", Path: "api", DependsOn: DependsOnField{names: []string{"db"}, conditions: map[string]DependencyCondition{"db": {Probe: "tcp://db:5432"}}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown dependency condition",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"db":  {Name: "db", Type: "manifests", Path: "db"},
+					"api": {Name: "api", Type: "manifests", Path: "api", DependsOn: DependsOnField{names: []string{"db"}, conditions: map[string]DependencyCondition{"db": {Condition: "service_completed"}}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid dependency timeout",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"db":  {Name: "db", Type: "manifests", Path: "db"},
+					"api": {Name: "api", Type: "manifests", Path: "api", DependsOn: DependsOnField{names: []string{"db"}, conditions: map[string]DependencyCondition{"db": {Condition: ConditionServiceHealthy, Probe: "tcp://db:5432", Timeout: "soon"}}}},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "missing cluster name",
 			cfg: &Config{
@@ -285,6 +428,120 @@ func TestConfigValidate(test *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "dev section with watch paths",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"app": {Name: "app", Type: "manifests", Path: "./k8s", Dev: &DevConfig{Watch: []string{"./src"}, Ignore: []string{"*.log"}}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "dev section without watch paths",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"app": {Name: "app", Type: "manifests", Path: "./k8s", Dev: &DevConfig{Build: "make image"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "dev section with malformed ignore pattern",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"app": {Name: "app", Type: "manifests", Path: "./k8s", Dev: &DevConfig{Watch: []string{"./src"}, Ignore: []string{"[a-"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "kubernetes range with matching version",
+			cfg: &Config{
+				Cluster:  ClusterConfig{Name: "test", Version: "1.31.0", Kubernetes: ">=1.28 <1.32"},
+				Services: map[string]ServiceConfig{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "kubernetes range excludes configured version",
+			cfg: &Config{
+				Cluster:  ClusterConfig{Name: "test", Version: "1.33.1", Kubernetes: ">=1.28 <1.32"},
+				Services: map[string]ServiceConfig{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid kubernetes range",
+			cfg: &Config{
+				Cluster:  ClusterConfig{Name: "test", Kubernetes: "between 1.28 and 1.32"},
+				Services: map[string]ServiceConfig{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid build section",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"app": {Name: "app", Type: "manifests", Path: "./k8s", Build: &BuildConfig{Image: "app:dev", Context: "./app"}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "build section without image",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"app": {Name: "app", Type: "manifests", Path: "./k8s", Build: &BuildConfig{Context: "./app"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "build section without context",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"app": {Name: "app", Type: "manifests", Path: "./k8s", Build: &BuildConfig{Image: "app:dev"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid ko build",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"app": {Name: "app", Type: "manifests", Path: "./k8s", Build: &BuildConfig{Type: "ko", Image: "app:dev", Context: "./app", ImportPath: "./cmd/app"}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "buildpacks build with dockerfile",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"app": {Name: "app", Type: "manifests", Path: "./k8s", Build: &BuildConfig{Type: "buildpacks", Image: "app:dev", Context: "./app", Dockerfile: "Dockerfile"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown build type",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"app": {Name: "app", Type: "manifests", Path: "./k8s", Build: &BuildConfig{Type: "bazel", Image: "app:dev", Context: "./app"}},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "nvidia gpu enabled",
 			cfg: &Config{
@@ -356,6 +613,200 @@ func TestConfigValidate(test *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "impersonate service account",
+			cfg: &Config{
+				Cluster:  ClusterConfig{Name: "test", Impersonate: &ImpersonateConfig{ServiceAccount: "team-a/deployer", Groups: []string{"developers"}}},
+				Services: map[string]ServiceConfig{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "impersonate user and service account",
+			cfg: &Config{
+				Cluster:  ClusterConfig{Name: "test", Impersonate: &ImpersonateConfig{User: "jane", ServiceAccount: "team-a/deployer"}},
+				Services: map[string]ServiceConfig{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "impersonate groups without user",
+			cfg: &Config{
+				Cluster:  ClusterConfig{Name: "test", Impersonate: &ImpersonateConfig{Groups: []string{"developers"}}},
+				Services: map[string]ServiceConfig{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "impersonate malformed service account",
+			cfg: &Config{
+				Cluster:  ClusterConfig{Name: "test", Impersonate: &ImpersonateConfig{ServiceAccount: "deployer"}},
+				Services: map[string]ServiceConfig{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "rbac sandbox",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"api": {Name: "api", Type: "manifests", Path: "./k8s", RBAC: &RBACConfig{Rules: []PolicyRule{{Resources: []string{"configmaps"}, Verbs: []string{"get", "list"}}}}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "rbac sandbox without rules",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"api": {Name: "api", Type: "manifests", Path: "./k8s", RBAC: &RBACConfig{}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "rbac rule without verbs",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"api": {Name: "api", Type: "manifests", Path: "./k8s", RBAC: &RBACConfig{Rules: []PolicyRule{{Resources: []string{"pods"}}}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "rbac invalid service account",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"api": {Name: "api", Type: "manifests", Path: "./k8s", RBAC: &RBACConfig{ServiceAccount: "API_Runner"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "rbac invalid values key",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"api": {Name: "api", Type: "helm", Path: "./chart", RBAC: &RBACConfig{ValuesKey: "app..serviceAccount"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "registry credentials",
+			cfg: &Config{
+				Cluster:  ClusterConfig{Name: "test", RegistryCredentials: &RegistryCredentialsConfig{Enabled: true, Registries: []string{"ghcr.io"}}},
+				Services: map[string]ServiceConfig{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "registry credentials on external cluster",
+			cfg: &Config{
+				Cluster:  ClusterConfig{Name: "test", External: &ExternalClusterConfig{Enabled: true}, RegistryCredentials: &RegistryCredentialsConfig{Enabled: true}},
+				Services: map[string]ServiceConfig{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "oci chart repository",
+			cfg: &Config{
+				Cluster:  ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{},
+				Charts:   ChartsConfig{Repository: "oci://localhost:5000/charts", PlainHTTP: true},
+			},
+			wantErr: false,
+		},
+		{
+			name: "http chart repository",
+			cfg: &Config{
+				Cluster:  ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{},
+				Charts:   ChartsConfig{Repository: "https://charts.example.com"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "post render patches and exec",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"redis": {Name: "redis", Type: "helm", Repo: "oci://registry-1.docker.io/bitnamicharts", Chart: "redis", PostRender: &PostRenderConfig{
+						Patches: []PostRenderPatch{
+							{Patch: "apiVersion: apps/v1\nkind: StatefulSet\nmetadata:\n  name: redis-master\n"},
+							{Target: &PatchTarget{Kind: "StatefulSet"}, Patch: "- op: remove\n  path: /spec/template/spec/containers/0/resources\n"},
+						},
+						Exec: "./strip-requests.sh",
+					}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "post render json6902 without target",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"api": {Name: "api", Type: "helm", Path: "./chart", PostRender: &PostRenderConfig{
+						Patches: []PostRenderPatch{{Patch: "- op: remove\n  path: /spec/replicas\n"}},
+					}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "post render empty",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"api": {Name: "api", Type: "helm", Path: "./chart", PostRender: &PostRenderConfig{}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "post render on manifests service",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"api": {Name: "api", Type: "manifests", Path: "./k8s", PostRender: &PostRenderConfig{Exec: "kustomize"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "pending timeout",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"api": {Name: "api", Type: "manifests", Path: "./k8s", PendingTimeout: "5m"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "zero pending timeout",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"api": {Name: "api", Type: "manifests", Path: "./k8s", PendingTimeout: "0s"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid pending timeout",
+			cfg: &Config{
+				Cluster: ClusterConfig{Name: "test"},
+				Services: map[string]ServiceConfig{
+					"api": {Name: "api", Type: "manifests", Path: "./k8s", PendingTimeout: "soon"},
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -367,3 +818,261 @@ func TestConfigValidate(test *testing.T) {
 		})
 	}
 }
+
+func TestServiceConfigGetSummary(test *testing.T) {
+	tests := []struct {
+		name     string
+		svc      ServiceConfig
+		expected string
+	}{
+		{
+			name:     "no description",
+			svc:      ServiceConfig{},
+			expected: "",
+		},
+		{
+			name:     "single line",
+			svc:      ServiceConfig{Description: "Primary PostgreSQL database"},
+			expected: "Primary PostgreSQL database",
+		},
+		{
+			name:     "multi-line uses first line",
+			svc:      ServiceConfig{Description: "  Redis cache\nUsed for session storage.\n"},
+			expected: "Redis cache",
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			result := tt.svc.GetSummary()
+			if result != tt.expected {
+				test.Errorf("Expected '%s', got '%s'", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestServiceConfigGetPendingTimeout(test *testing.T) {
+	tests := []struct {
+		name     string
+		svc      ServiceConfig
+		expected time.Duration
+		wantErr  bool
+	}{
+		{
+			name:     "default",
+			svc:      ServiceConfig{},
+			expected: 60 * time.Second,
+		},
+		{
+			name:     "configured",
+			svc:      ServiceConfig{PendingTimeout: "5m"},
+			expected: 5 * time.Minute,
+		},
+		{
+			name:    "invalid",
+			svc:     ServiceConfig{PendingTimeout: "soon"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			result, err := tt.svc.GetPendingTimeout()
+			if (err != nil) != tt.wantErr {
+				test.Fatalf("GetPendingTimeout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if result != tt.expected {
+				test.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestConfigShouldSuspendCronJobs(test *testing.T) {
+	enabled, disabled := true, false
+	tests := []struct {
+		name     string
+		global   bool
+		service  *bool
+		expected bool
+	}{
+		{name: "default", expected: false},
+		{name: "global", global: true, expected: true},
+		{name: "service", service: &enabled, expected: true},
+		{name: "service overrides global", global: true, service: &disabled, expected: false},
+	}
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			cfg := &Config{SuspendCronJobs: tt.global}
+			if result := cfg.ShouldSuspendCronJobs(&ServiceConfig{SuspendCronJobs: tt.service}); result != tt.expected {
+				test.Errorf("ShouldSuspendCronJobs() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParsePortForward(test *testing.T) {
+	tests := []struct {
+		name        string
+		spec        string
+		expected    PortForward
+		expectError bool
+	}{
+		{name: "remote only", spec: "6379", expected: PortForward{LocalPort: 6379, RemotePort: 6379}},
+		{name: "local and remote", spec: "8080:80", expected: PortForward{LocalPort: 8080, RemotePort: 80}},
+		{name: "not a number", spec: "http", expectError: true},
+		{name: "too many parts", spec: "1:2:3", expectError: true},
+		{name: "local out of range", spec: "70000:80", expectError: true},
+		{name: "remote out of range", spec: "8080:0", expectError: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			got, err := ParsePortForward(tt.spec)
+			if tt.expectError {
+				if err == nil {
+					test.Errorf("ParsePortForward(%q) expected error, got %+v", tt.spec, got)
+				}
+				return
+			}
+			if err != nil {
+				test.Fatalf("ParsePortForward(%q) unexpected error: %v", tt.spec, err)
+			}
+			if got != tt.expected {
+				test.Errorf("ParsePortForward(%q) = %+v, expected %+v", tt.spec, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestImpersonateConfigUserName(test *testing.T) {
+	tests := []struct {
+		name string
+		imp  *ImpersonateConfig
+		want string
+	}{
+		{name: "nil", imp: nil, want: ""},
+		{name: "user", imp: &ImpersonateConfig{User: "jane@example.com"}, want: "jane@example.com"},
+		{name: "service account", imp: &ImpersonateConfig{ServiceAccount: "team-a/deployer"}, want: "system:serviceaccount:team-a:deployer"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := tt.imp.UserName(); got != tt.want {
+				test.Errorf("UserName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseProbe(test *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected *Probe
+		wantErr  bool
+	}{
+		{
+			name:     "tcp",
+			raw:      "tcp://postgres:5432",
+			expected: &Probe{Scheme: "tcp", Host: "postgres", Port: "5432"},
+		},
+		{
+			name:     "http",
+			raw:      "http://api.app:8080/healthz",
+			expected: &Probe{Scheme: "http", Host: "api.app", Port: "8080", URL: "http://api.app:8080/healthz"},
+		},
+		{
+			name:    "tcp without port",
+			raw:     "tcp://postgres",
+			wantErr: true,
+		},
+		{
+			name:    "unsupported scheme",
+			raw:     "grpc://api:9090",
+			wantErr: true,
+		},
+		{
+			name:    "missing host",
+			raw:     "http:///healthz",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			probe, err := ParseProbe(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					test.Errorf("ParseProbe(%q) expected error", tt.raw)
+				}
+				return
+			}
+			if err != nil {
+				test.Fatalf("ParseProbe(%q) error = %v", tt.raw, err)
+			}
+			if *probe != *tt.expected {
+				test.Errorf("ParseProbe(%q) = %+v, want %+v", tt.raw, probe, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBuildSubstituteImage(test *testing.T) {
+	dir := test.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module github.com/acme/api\n\ngo 1.26\n"), 0644); err != nil {
+		test.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		build    *BuildConfig
+		content  string
+		expected string
+	}{
+		{
+			name:     "module import path",
+			build:    &BuildConfig{Type: "ko", Image: "api:dev", Context: dir, ImportPath: "./cmd/api"},
+			content:  "image: ko://github.com/acme/api/cmd/api\n",
+			expected: "image: api:dev\n",
+		},
+		{
+			name:     "relative import path",
+			build:    &BuildConfig{Type: "ko", Image: "api:dev", Context: dir, ImportPath: "./cmd/api"},
+			content:  "image: ko://./cmd/api",
+			expected: "image: api:dev",
+		},
+		{
+			name:     "module root",
+			build:    &BuildConfig{Type: "ko", Image: "api:dev", Context: dir},
+			content:  `{"image": "ko://github.com/acme/api"}`,
+			expected: `{"image": "api:dev"}`,
+		},
+		{
+			name:     "other package is kept",
+			build:    &BuildConfig{Type: "ko", Image: "api:dev", Context: dir},
+			content:  "image: ko://github.com/acme/api/cmd/worker",
+			expected: "image: ko://github.com/acme/api/cmd/worker",
+		},
+		{
+			name:     "docker build",
+			build:    &BuildConfig{Image: "api:dev", Context: dir},
+			content:  "image: ko://github.com/acme/api",
+			expected: "image: ko://github.com/acme/api",
+		},
+		{
+			name:     "no build",
+			content:  "image: ko://github.com/acme/api",
+			expected: "image: ko://github.com/acme/api",
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := tt.build.SubstituteImage(tt.content); got != tt.expected {
+				test.Errorf("SubstituteImage() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// variant is set with --suffix and turns every config parsed into a separate
+// copy of its environment
+var variant struct {
+	suffix          string
+	separateCluster bool
+}
+
+// SetVariant makes configs parsed from now on a variant of their environment:
+// every service namespace gets the suffix, and with separateCluster so does
+// the cluster name. Variants with different suffixes can run side by side.
+func SetVariant(suffix string, separateCluster bool) error {
+	if suffix == "" {
+		if separateCluster {
+			return fmt.Errorf("--suffix-cluster requires --suffix")
+		}
+		variant.suffix, variant.separateCluster = "", false
+		return nil
+	}
+	if errs := validation.IsDNS1123Label(suffix); len(errs) > 0 {
+		return fmt.Errorf("invalid suffix '%s': %s", suffix, strings.Join(errs, ", "))
+	}
+	if suffix == "default" {
+		return fmt.Errorf("invalid suffix 'default': it names the environment run without --suffix")
+	}
+	variant.suffix, variant.separateCluster = suffix, separateCluster
+	return nil
+}
+
+// VariantSuffix returns the suffix set with SetVariant, empty outside a variant
+func VariantSuffix() string {
+	return variant.suffix
+}
+
+// applyVariant renames the namespaces (and with a separate cluster, the
+// cluster) of the config to those of the variant
+func (cfg *Config) applyVariant() error {
+	if variant.suffix == "" {
+		return nil
+	}
+
+	namespaces := make(map[string]string)
+	for name, svc := range cfg.Services {
+		namespace := svc.GetNamespace() + "-" + variant.suffix
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return &ValidationError{
+				Field:   fmt.Sprintf("services.%s.namespace", name),
+				Message: fmt.Sprintf("'%s' with suffix '%s' is not a valid namespace: %s", svc.GetNamespace(), variant.suffix, strings.Join(errs, ", ")),
+			}
+		}
+		namespaces[svc.GetNamespace()] = namespace
+		svc.Namespace = namespace
+		cfg.Services[name] = svc
+		cfg.deriveOrigins(originPath(originPath("services", name), "namespace"), namespace, "--suffix "+variant.suffix, true)
+	}
+
+	// Assertions on resources in another service's namespace follow it
+	for name, svc := range cfg.Services {
+		for itr := range svc.Assertions {
+			if namespace, ok := namespaces[svc.Assertions[itr].Namespace]; ok {
+				svc.Assertions[itr].Namespace = namespace
+			}
+		}
+		cfg.Services[name] = svc
+	}
+
+	if !variant.separateCluster {
+		return nil
+	}
+	if cfg.Cluster.IsExternal() || cfg.Cluster.IsNone() {
+		return &ValidationError{Field: "cluster", Message: "--suffix-cluster requires a kind cluster"}
+	}
+	cfg.Cluster.Name += "-" + variant.suffix
+	cfg.deriveOrigins("cluster.name", cfg.Cluster.Name, "--suffix-cluster "+variant.suffix, true)
+	cfg.Cluster.Variant = variant.suffix
+
+	// Host ports would clash with the other variants, so Docker picks free ones
+	for itr := range cfg.Cluster.Config {
+		mappings := cfg.Cluster.Config[itr].ExtraPortMappings
+		for mapping := range mappings {
+			mappings[mapping].HostPort = 0
+			path := itemPath(originPath(itemPath("cluster.config", itr), "extraPortMappings"), mapping)
+			cfg.deriveOrigins(originPath(path, "hostPort"), 0, "--suffix-cluster "+variant.suffix, true)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyVariant(test *testing.T) {
+	dir := writeConfigFiles(test, map[string]string{
+		"kraze.yml": `cluster:
+  name: dev
+  config:
+    - role: control-plane
+      extraPortMappings:
+        - containerPort: 80
+          hostPort: 8080
+services:
+  api:
+    type: manifests
+    path: api.yaml
+    namespace: backend
+    assertions:
+      - kind: Service
+        name: postgres
+        namespace: data
+  postgres:
+    type: manifests
+    path: postgres.yaml
+    namespace: data
+  tools:
+    type: manifests
+    path: tools-${KRAZE_SUFFIX}.yaml
+`,
+	})
+	path := filepath.Join(dir, "kraze.yml")
+
+	tests := []struct {
+		name            string
+		suffix          string
+		separateCluster bool
+		namespaces      map[string]string
+		clusterName     string
+		hostPort        int32
+		toolsPath       string
+	}{
+		{
+			name:        "no variant",
+			namespaces:  map[string]string{"api": "backend", "postgres": "data", "tools": "default"},
+			clusterName: "dev",
+			hostPort:    8080,
+			toolsPath:   "tools-.yaml",
+		},
+		{
+			name:        "shared cluster",
+			suffix:      "pr-123",
+			namespaces:  map[string]string{"api": "backend-pr-123", "postgres": "data-pr-123", "tools": "default-pr-123"},
+			clusterName: "dev",
+			hostPort:    8080,
+			toolsPath:   "tools-pr-123.yaml",
+		},
+		{
+			name:            "separate cluster",
+			suffix:          "pr-123",
+			separateCluster: true,
+			namespaces:      map[string]string{"api": "backend-pr-123", "postgres": "data-pr-123", "tools": "default-pr-123"},
+			clusterName:     "dev-pr-123",
+			hostPort:        0,
+			toolsPath:       "tools-pr-123.yaml",
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if err := SetVariant(tt.suffix, tt.separateCluster); err != nil {
+				test.Fatalf("SetVariant() error = %v", err)
+			}
+			defer SetVariant("", false)
+
+			cfg, err := Parse(path)
+			if err != nil {
+				test.Fatalf("Parse() error = %v", err)
+			}
+			for name, namespace := range tt.namespaces {
+				svc := cfg.Services[name]
+				if got := svc.GetNamespace(); got != namespace {
+					test.Errorf("service %s namespace = %s, want %s", name, got, namespace)
+				}
+			}
+			if got := cfg.Services["api"].Assertions[0].Namespace; got != tt.namespaces["postgres"] {
+				test.Errorf("assertion namespace = %s, want %s", got, tt.namespaces["postgres"])
+			}
+			if cfg.Cluster.Name != tt.clusterName {
+				test.Errorf("cluster name = %s, want %s", cfg.Cluster.Name, tt.clusterName)
+			}
+			if got := cfg.Cluster.Config[0].ExtraPortMappings[0].HostPort; got != tt.hostPort {
+				test.Errorf("host port = %d, want %d", got, tt.hostPort)
+			}
+			if got := filepath.Base(cfg.Services["tools"].Path); got != tt.toolsPath {
+				test.Errorf("tools path = %s, want %s", got, tt.toolsPath)
+			}
+		})
+	}
+}
+
+func TestSetVariantErrors(test *testing.T) {
+	tests := []struct {
+		name            string
+		suffix          string
+		separateCluster bool
+		errContains     string
+	}{
+		{name: "upper case", suffix: "PR-123", errContains: "invalid suffix"},
+		{name: "dots", suffix: "pr.123", errContains: "invalid suffix"},
+		{name: "reserved", suffix: "default", errContains: "without --suffix"},
+		{name: "cluster without suffix", separateCluster: true, errContains: "requires --suffix"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			err := SetVariant(tt.suffix, tt.separateCluster)
+			defer SetVariant("", false)
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				test.Errorf("SetVariant() error = %v, want one containing %q", err, tt.errContains)
+			}
+		})
+	}
+}
+
+func TestApplyVariantExternalCluster(test *testing.T) {
+	if err := SetVariant("pr-1", true); err != nil {
+		test.Fatal(err)
+	}
+	defer SetVariant("", false)
+
+	cfg := &Config{
+		Cluster:  ClusterConfig{Name: "shared", External: &ExternalClusterConfig{Enabled: true}},
+		Services: map[string]ServiceConfig{},
+	}
+	if err := cfg.applyVariant(); err == nil || !strings.Contains(err.Error(), "requires a kind cluster") {
+		test.Errorf("applyVariant() error = %v, want one about a kind cluster", err)
+	}
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// WorkloadReadiness relaxes how the wait engine treats some of a service's
+// resources, for charts shipping best-effort components (e.g., an optional
+// exporter) that shouldn't fail the install or need longer to become ready
+type WorkloadReadiness struct {
+	Kind        string `yaml:"kind,omitempty"`         // Kind of the resources (default: any kind)
+	Name        string `yaml:"name"`                   // Resource name, or a glob (e.g., "*-exporter")
+	Optional    bool   `yaml:"optional,omitempty"`     // Warn instead of failing when the resources aren't ready
+	WaitTimeout string `yaml:"wait_timeout,omitempty"` // Grace period replacing the service's wait_timeout for the resources
+}
+
+// Matches returns true if the rule applies to the resource
+func (workload WorkloadReadiness) Matches(kind, name string) bool {
+	if workload.Kind != "" && workload.Kind != kind {
+		return false
+	}
+	matched, _ := path.Match(workload.Name, name)
+	return matched
+}
+
+// GetWaitTimeout returns the grace period of the resources, 0 when they use the
+// service's timeout
+func (workload WorkloadReadiness) GetWaitTimeout() time.Duration {
+	timeout, _ := time.ParseDuration(workload.WaitTimeout)
+	return timeout
+}
+
+// FindWorkloadReadiness returns the first rule applying to the resource
+func FindWorkloadReadiness(workloads []WorkloadReadiness, kind, name string) (WorkloadReadiness, bool) {
+	for _, workload := range workloads {
+		if workload.Matches(kind, name) {
+			return workload, true
+		}
+	}
+	return WorkloadReadiness{}, false
+}
+
+// validateWorkloads checks each workload rule names its resources and relaxes
+// their readiness
+func (srv *ServiceConfig) validateWorkloads() error {
+	for itr, workload := range srv.Workloads {
+		field := fmt.Sprintf("workloads[%d]", itr)
+		if workload.Name == "" {
+			return &ValidationError{Field: field + ".name", Message: "name of the resources is required (a glob like '*-exporter' matches several)"}
+		}
+		if _, err := path.Match(workload.Name, ""); err != nil {
+			return &ValidationError{Field: field + ".name", Message: fmt.Sprintf("invalid pattern '%s': %v", workload.Name, err)}
+		}
+		if workload.Kind != "" && strings.ToUpper(workload.Kind[:1]) != workload.Kind[:1] {
+			return &ValidationError{Field: field + ".kind", Message: fmt.Sprintf("'%s' is not a kind (e.g., Deployment)", workload.Kind)}
+		}
+		if !workload.Optional && workload.WaitTimeout == "" {
+			return &ValidationError{Field: field, Message: "set optional or wait_timeout"}
+		}
+		if workload.WaitTimeout != "" {
+			timeout, err := time.ParseDuration(workload.WaitTimeout)
+			if err != nil {
+				return &ValidationError{Field: field + ".wait_timeout", Message: fmt.Sprintf("invalid duration '%s': %v", workload.WaitTimeout, err)}
+			}
+			if timeout <= 0 {
+				return &ValidationError{Field: field + ".wait_timeout", Message: "must be positive"}
+			}
+		}
+		if workload.Optional && srv.ShouldWaitForJobs() {
+			return &ValidationError{Field: field + ".optional", Message: "Helm waits for every resource of the release with wait_for_jobs, so none can be optional"}
+		}
+		if workload.WaitTimeout != "" && srv.ShouldWaitForJobs() {
+			return &ValidationError{Field: field + ".wait_timeout", Message: "Helm waits for the whole release within the service's wait_timeout with wait_for_jobs, so resources can't have their own"}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWorkloadReadinessMatches(test *testing.T) {
+	tests := []struct {
+		name     string
+		workload WorkloadReadiness
+		kind     string
+		resource string
+		expected bool
+	}{
+		{name: "exact name", workload: WorkloadReadiness{Name: "exporter"}, kind: "Deployment", resource: "exporter", expected: true},
+		{name: "other name", workload: WorkloadReadiness{Name: "exporter"}, kind: "Deployment", resource: "api", expected: false},
+		{name: "glob", workload: WorkloadReadiness{Name: "*-exporter"}, kind: "DaemonSet", resource: "monitoring-node-exporter", expected: true},
+		{name: "kind matches", workload: WorkloadReadiness{Kind: "Deployment", Name: "exporter"}, kind: "Deployment", resource: "exporter", expected: true},
+		{name: "kind differs", workload: WorkloadReadiness{Kind: "StatefulSet", Name: "exporter"}, kind: "Deployment", resource: "exporter", expected: false},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := tt.workload.Matches(tt.kind, tt.resource); got != tt.expected {
+				test.Errorf("Matches(%s, %s) = %v, expected %v", tt.kind, tt.resource, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFindWorkloadReadiness(test *testing.T) {
+	workloads := []WorkloadReadiness{
+		{Kind: "Deployment", Name: "indexer", WaitTimeout: "15m"},
+		{Name: "*-exporter", Optional: true},
+		{Name: "*", WaitTimeout: "1m"},
+	}
+
+	workload, ok := FindWorkloadReadiness(workloads, "Deployment", "indexer")
+	if !ok || workload.GetWaitTimeout() != 15*time.Minute || workload.Optional {
+		test.Errorf("indexer = %+v, %v, expected the 15m grace period", workload, ok)
+	}
+	if workload, ok = FindWorkloadReadiness(workloads, "DaemonSet", "node-exporter"); !ok || !workload.Optional {
+		test.Errorf("node-exporter = %+v, %v, expected optional", workload, ok)
+	}
+	if workload, ok = FindWorkloadReadiness(workloads, "StatefulSet", "indexer"); !ok || workload.GetWaitTimeout() != time.Minute {
+		test.Errorf("StatefulSet indexer = %+v, %v, expected the catch-all", workload, ok)
+	}
+	if _, ok = FindWorkloadReadiness(nil, "Deployment", "api"); ok {
+		test.Error("expected no rule without workloads")
+	}
+}
+
+func TestValidateWorkloads(test *testing.T) {
+	waitForJobs := true
+	tests := []struct {
+		name        string
+		workloads   []WorkloadReadiness
+		waitForJobs *bool
+		errContains string
+	}{
+		{name: "optional", workloads: []WorkloadReadiness{{Kind: "Deployment", Name: "*-exporter", Optional: true}}},
+		{name: "grace period", workloads: []WorkloadReadiness{{Name: "indexer", WaitTimeout: "20m"}}},
+		{name: "missing name", workloads: []WorkloadReadiness{{Optional: true}}, errContains: "workloads[0].name"},
+		{name: "bad pattern", workloads: []WorkloadReadiness{{Name: "[exporter", Optional: true}}, errContains: "invalid pattern"},
+		{name: "lowercase kind", workloads: []WorkloadReadiness{{Kind: "deployment", Name: "api", Optional: true}}, errContains: "workloads[0].kind"},
+		{name: "nothing relaxed", workloads: []WorkloadReadiness{{Name: "api"}}, errContains: "set optional or wait_timeout"},
+		{name: "invalid timeout", workloads: []WorkloadReadiness{{Name: "api", WaitTimeout: "soon"}}, errContains: "invalid duration"},
+		{name: "zero timeout", workloads: []WorkloadReadiness{{Name: "api", WaitTimeout: "0s"}}, errContains: "must be positive"},
+		{name: "optional with wait_for_jobs", workloads: []WorkloadReadiness{{Name: "api", Optional: true}}, waitForJobs: &waitForJobs, errContains: "wait_for_jobs"},
+		{name: "grace period with wait_for_jobs", workloads: []WorkloadReadiness{{Name: "api", WaitTimeout: "20m"}}, waitForJobs: &waitForJobs, errContains: "wait_for_jobs"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			svc := &ServiceConfig{Name: "app", Type: "helm", Workloads: tt.workloads, WaitForJobs: tt.waitForJobs}
+			err := svc.validateWorkloads()
+			if tt.errContains == "" {
+				if err != nil {
+					test.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				test.Errorf("error = %v, expected it to contain %q", err, tt.errContains)
+			}
+		})
+	}
+}
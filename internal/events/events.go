@@ -0,0 +1,141 @@
+// Package events delivers install, uninstall and cluster lifecycle events to
+// sinks outside kraze: a webhook, a JSONL file or an OpenTelemetry collector
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/hjames9/kraze/internal/config"
+)
+
+// Event types
+const (
+	ClusterCreate    = "cluster.create"
+	ClusterDelete    = "cluster.delete"
+	ClusterStart     = "cluster.start"
+	ClusterStop      = "cluster.stop"
+	ServiceInstall   = "service.install"
+	ServiceUninstall = "service.uninstall"
+)
+
+// Event statuses
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Event is something kraze did to a cluster or service
+type Event struct {
+	Time       time.Time `json:"time"` // When it finished
+	Type       string    `json:"type"`
+	Cluster    string    `json:"cluster"`
+	Service    string    `json:"service,omitempty"`
+	Status     string    `json:"status"`
+	DurationMS int64     `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+	Host       string    `json:"host,omitempty"`
+	User       string    `json:"user,omitempty"`
+	Version    string    `json:"kraze_version,omitempty"`
+}
+
+// Finished returns the event of something that started at start and just
+// finished, failed if err is not nil
+func Finished(eventType, cluster, service string, start time.Time, err error) Event {
+	now := time.Now()
+	event := Event{
+		Time:       now,
+		Type:       eventType,
+		Cluster:    cluster,
+		Service:    service,
+		Status:     StatusSucceeded,
+		DurationMS: now.Sub(start).Milliseconds(),
+	}
+	if err != nil {
+		event.Status = StatusFailed
+		event.Error = err.Error()
+	}
+	return event
+}
+
+// Start returns when the event started
+func (event Event) Start() time.Time {
+	return event.Time.Add(-time.Duration(event.DurationMS) * time.Millisecond)
+}
+
+// Sink is where events are delivered
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, event Event) error
+	Close(ctx context.Context) error
+}
+
+// Dispatcher delivers events to every configured sink
+type Dispatcher struct {
+	sinks   []Sink
+	host    string
+	user    string
+	version string
+}
+
+// New creates a dispatcher for the sinks of an events block. A nil block
+// gives a dispatcher without sinks.
+func New(ctx context.Context, cfg *config.EventsConfig, version string) (*Dispatcher, error) {
+	dispatcher := &Dispatcher{version: version}
+	dispatcher.host, _ = os.Hostname()
+	if current, err := user.Current(); err == nil {
+		dispatcher.user = current.Username
+	}
+	if cfg == nil {
+		return dispatcher, nil
+	}
+
+	if cfg.Webhook != nil {
+		dispatcher.sinks = append(dispatcher.sinks, NewWebhookSink(cfg.Webhook.URL, cfg.Webhook.Headers))
+	}
+	if cfg.File != "" {
+		dispatcher.sinks = append(dispatcher.sinks, NewFileSink(cfg.File))
+	}
+	if cfg.OTLP != "" {
+		sink, err := NewOTLPSink(ctx, cfg.OTLP, version)
+		if err != nil {
+			return nil, err
+		}
+		dispatcher.sinks = append(dispatcher.sinks, sink)
+	}
+	return dispatcher, nil
+}
+
+// Emit delivers an event to every sink, returning the failures. Sinks are
+// tried in turn, so one failing doesn't keep the event from the others.
+func (dispatcher *Dispatcher) Emit(ctx context.Context, event Event) error {
+	if len(dispatcher.sinks) == 0 {
+		return nil
+	}
+	event.Host = dispatcher.host
+	event.User = dispatcher.user
+	event.Version = dispatcher.version
+
+	var errs []error
+	for _, sink := range dispatcher.sinks {
+		if err := sink.Send(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close flushes and closes the sinks
+func (dispatcher *Dispatcher) Close(ctx context.Context) error {
+	var errs []error
+	for _, sink := range dispatcher.sinks {
+		if err := sink.Close(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
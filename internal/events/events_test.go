@@ -0,0 +1,139 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestFinished(test *testing.T) {
+	start := time.Now().Add(-2 * time.Second)
+	event := Finished(ServiceInstall, "dev", "redis", start, errors.New("timed out"))
+	if event.Status != StatusFailed || event.Error != "timed out" {
+		test.Errorf("Finished() = %+v, want a failed event", event)
+	}
+	if event.DurationMS < 2000 {
+		test.Errorf("DurationMS = %d, want at least 2000", event.DurationMS)
+	}
+	if event.Start().Sub(start).Abs() > time.Millisecond {
+		test.Errorf("Start() = %v, want %v", event.Start(), start)
+	}
+	if event := Finished(ClusterStart, "dev", "", start, nil); event.Status != StatusSucceeded || event.Error != "" {
+		test.Errorf("Finished() = %+v, want a succeeded event", event)
+	}
+}
+
+func TestFileSink(test *testing.T) {
+	path := filepath.Join(test.TempDir(), "logs", "events.jsonl")
+	dispatcher := &Dispatcher{sinks: []Sink{NewFileSink(path)}, host: "laptop", version: "1.0.0"}
+
+	for _, service := range []string{"redis", "api"} {
+		if err := dispatcher.Emit(context.Background(), Finished(ServiceInstall, "dev", service, time.Now(), nil)); err != nil {
+			test.Fatalf("Emit() error = %v", err)
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		test.Fatal(err)
+	}
+	defer file.Close()
+	var services []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			test.Fatalf("Invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		if event.Host != "laptop" || event.Version != "1.0.0" {
+			test.Errorf("Expected the dispatcher to fill in the source, got %+v", event)
+		}
+		services = append(services, event.Service)
+	}
+	if strings.Join(services, ",") != "redis,api" {
+		test.Errorf("services = %v, want redis,api", services)
+	}
+}
+
+func TestWebhookSink(test *testing.T) {
+	var received Event
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		auth = req.Header.Get("Authorization")
+		if err := json.NewDecoder(req.Body).Decode(&received); err != nil {
+			writer.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL, map[string]string{"Authorization": "Bearer token"})
+	if err := sink.Send(context.Background(), Finished(ClusterCreate, "dev", "", time.Now(), nil)); err != nil {
+		test.Fatalf("Send() error = %v", err)
+	}
+	if received.Type != ClusterCreate || received.Cluster != "dev" {
+		test.Errorf("received = %+v", received)
+	}
+	if auth != "Bearer token" {
+		test.Errorf("Authorization = %q, want the configured header", auth)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		http.Error(writer, "no such hook", http.StatusNotFound)
+	}))
+	defer failing.Close()
+	dispatcher := &Dispatcher{sinks: []Sink{NewWebhookSink(failing.URL, nil)}}
+	err := dispatcher.Emit(context.Background(), Finished(ClusterCreate, "dev", "", time.Now(), nil))
+	if err == nil || !strings.Contains(err.Error(), "webhook") || !strings.Contains(err.Error(), "no such hook") {
+		test.Errorf("Emit() error = %v, want the webhook's response", err)
+	}
+}
+
+func TestOTLPSink(test *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	sink, err := newOTLPSink(exporter, "1.0.0")
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	start := time.Now().Add(-3 * time.Second)
+	event := Finished(ServiceInstall, "dev", "redis", start, errors.New("timed out"))
+	if err := sink.Send(context.Background(), event); err != nil {
+		test.Fatalf("Send() error = %v", err)
+	}
+	// The in-memory exporter forgets its spans on shutdown, so flush instead of closing
+	if err := sink.provider.ForceFlush(context.Background()); err != nil {
+		test.Fatalf("ForceFlush() error = %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		test.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name != ServiceInstall || span.Status.Code != codes.Error {
+		test.Errorf("span = %s (%v), want a failed %s span", span.Name, span.Status.Code, ServiceInstall)
+	}
+	if duration := span.EndTime.Sub(span.StartTime); duration < 3*time.Second {
+		test.Errorf("span duration = %v, want the install's duration", duration)
+	}
+	found := false
+	for _, attr := range span.Attributes {
+		if attr.Key == "kraze.service" && attr.Value.AsString() == "redis" {
+			found = true
+		}
+	}
+	if !found {
+		test.Errorf("Expected a kraze.service attribute, got %v", span.Attributes)
+	}
+}
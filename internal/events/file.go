@@ -0,0 +1,56 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileSink appends each event as a JSON line to a file
+type FileSink struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewFileSink creates a sink appending to path
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+// Name returns the sink's name for messages
+func (sink *FileSink) Name() string {
+	return "file " + sink.path
+}
+
+// Send appends the event. Each line is written with a single write to a file
+// opened for appending, so commands in other terminals don't interleave lines.
+func (sink *FileSink) Send(ctx context.Context, event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	line = append(line, '\n')
+
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+	if err := os.MkdirAll(filepath.Dir(sink.path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(sink.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(line); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Close does nothing; the file is opened for each event
+func (sink *FileSink) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,80 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// otlpScope is the instrumentation scope of event spans
+const otlpScope = "github.com/hjames9/kraze/events"
+
+// OTLPSink exports each event as a span covering what it timed, so a
+// collector sees per-service install durations across every environment
+type OTLPSink struct {
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer
+}
+
+// NewOTLPSink creates a sink exporting to an OTLP/HTTP endpoint
+func NewOTLPSink(ctx context.Context, endpoint, version string) (*OTLPSink, error) {
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+	return newOTLPSink(exporter, version)
+}
+
+// newOTLPSink creates a sink exporting to exporter, batching spans until Close
+func newOTLPSink(exporter sdktrace.SpanExporter, version string) (*OTLPSink, error) {
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", "kraze"),
+		attribute.String("service.version", version),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithResource(res), sdktrace.WithBatcher(exporter))
+	return &OTLPSink{provider: provider, tracer: provider.Tracer(otlpScope)}, nil
+}
+
+// Name returns the sink's name for messages
+func (sink *OTLPSink) Name() string {
+	return "otlp"
+}
+
+// Send records the event as a span from its start to its end
+func (sink *OTLPSink) Send(ctx context.Context, event Event) error {
+	attrs := []attribute.KeyValue{
+		attribute.String("kraze.cluster", event.Cluster),
+		attribute.String("kraze.status", event.Status),
+	}
+	if event.Service != "" {
+		attrs = append(attrs, attribute.String("kraze.service", event.Service))
+	}
+	if event.Host != "" {
+		attrs = append(attrs, attribute.String("host.name", event.Host))
+	}
+	if event.User != "" {
+		attrs = append(attrs, attribute.String("user.name", event.User))
+	}
+
+	// Each event is a trace of its own, not part of the command's trace
+	_, span := sink.tracer.Start(ctx, event.Type, trace.WithNewRoot(), trace.WithTimestamp(event.Start()), trace.WithAttributes(attrs...))
+	if event.Error != "" {
+		span.SetStatus(codes.Error, event.Error)
+	}
+	span.End(trace.WithTimestamp(event.Time))
+	return nil
+}
+
+// Close exports the spans not sent yet
+func (sink *OTLPSink) Close(ctx context.Context) error {
+	return sink.provider.Shutdown(ctx)
+}
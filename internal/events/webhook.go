@@ -0,0 +1,64 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// webhookTimeout bounds how long a command waits for the webhook per event
+const webhookTimeout = 5 * time.Second
+
+// WebhookSink posts each event as JSON to a URL
+type WebhookSink struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewWebhookSink creates a sink posting to url with extra headers
+func NewWebhookSink(url string, headers map[string]string) *WebhookSink {
+	return &WebhookSink{url: url, headers: headers, client: &http.Client{Timeout: webhookTimeout}}
+}
+
+// Name returns the sink's name for messages
+func (sink *WebhookSink) Name() string {
+	return "webhook"
+}
+
+// Send posts the event, failing on a non-2xx response
+func (sink *WebhookSink) Send(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sink.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "kraze/"+event.Version)
+	for name, value := range sink.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := sink.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %s: %s", sink.url, resp.Status, bytes.TrimSpace(message))
+	}
+	return nil
+}
+
+// Close does nothing; requests are sent synchronously
+func (sink *WebhookSink) Close(ctx context.Context) error {
+	return nil
+}
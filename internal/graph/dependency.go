@@ -24,7 +24,7 @@ func NewDependencyGraph(services map[string]config.ServiceConfig) *DependencyGra
 	for name, svc := range services {
 		svcCopy := svc
 		graph.services[name] = &svcCopy
-		graph.edges[name] = svc.DependsOn
+		graph.edges[name] = svc.DependsOn.Names()
 	}
 
 	return graph
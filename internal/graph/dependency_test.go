@@ -20,12 +20,12 @@ func TestTopologicalSort(test *testing.T) {
 		"api": {
 			Name:      "api",
 			Type:      "helm",
-			DependsOn: []string{"redis", "postgres"},
+			DependsOn: config.NewDependsOn("redis", "postgres"),
 		},
 		"worker": {
 			Name:      "worker",
 			Type:      "helm",
-			DependsOn: []string{"redis"},
+			DependsOn: config.NewDependsOn("redis"),
 		},
 	}
 
@@ -66,17 +66,17 @@ func TestCycleDetection(test *testing.T) {
 		"a": {
 			Name:      "a",
 			Type:      "helm",
-			DependsOn: []string{"b"},
+			DependsOn: config.NewDependsOn("b"),
 		},
 		"b": {
 			Name:      "b",
 			Type:      "helm",
-			DependsOn: []string{"c"},
+			DependsOn: config.NewDependsOn("c"),
 		},
 		"c": {
 			Name:      "c",
 			Type:      "helm",
-			DependsOn: []string{"a"}, // Creates cycle: a -> b -> c -> a
+			DependsOn: config.NewDependsOn("a"), // Creates cycle: a -> b -> c -> a
 		},
 	}
 
@@ -105,12 +105,12 @@ func TestFilterServices(test *testing.T) {
 		"api": {
 			Name:      "api",
 			Type:      "helm",
-			DependsOn: []string{"redis"},
+			DependsOn: config.NewDependsOn("redis"),
 		},
 		"worker": {
 			Name:      "worker",
 			Type:      "helm",
-			DependsOn: []string{"postgres"},
+			DependsOn: config.NewDependsOn("postgres"),
 		},
 	}
 
@@ -143,7 +143,7 @@ func TestReverseTopologicalSort(test *testing.T) {
 		"api": {
 			Name:      "api",
 			Type:      "helm",
-			DependsOn: []string{"db"},
+			DependsOn: config.NewDependsOn("db"),
 		},
 	}
 
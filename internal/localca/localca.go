@@ -0,0 +1,258 @@
+// Package localca issues locally-trusted TLS certificates, like mkcert: a CA
+// kept in ~/.kraze/ca signs certificates for the hosts services declare, and
+// trusting it once (kraze trust --install) makes them valid in every cluster.
+package localca
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// CertFileName and KeyFileName are the CA files in the CA directory
+	CertFileName = "rootCA.pem"
+	KeyFileName  = "rootCA-key.pem"
+
+	// caValidity is how long the CA is valid
+	caValidity = 10 * 365 * 24 * time.Hour
+	// certValidity is how long issued certificates are valid, the most
+	// browsers accept (the same as mkcert)
+	certValidity = 825 * 24 * time.Hour
+	// renewBefore is how long before they expire certificates are reissued
+	renewBefore = 30 * 24 * time.Hour
+)
+
+// CA is the local certificate authority
+type CA struct {
+	Cert     *x509.Certificate
+	CertPEM  []byte
+	CertPath string
+	key      crypto.Signer
+}
+
+// DefaultDir returns ~/.kraze/ca
+func DefaultDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".kraze", "ca"), nil
+}
+
+// Load reads the CA in dir. Returns nil if it was never created.
+func Load(dir string) (*CA, error) {
+	certPath := filepath.Join(dir, CertFileName)
+	certPEM, err := os.ReadFile(certPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local CA: %w", err)
+	}
+	keyPEM, err := os.ReadFile(filepath.Join(dir, KeyFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local CA key: %w", err)
+	}
+
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return nil, fmt.Errorf("%s is not a PEM certificate", certPath)
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse local CA: %w", err)
+	}
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, fmt.Errorf("%s is not a PEM key", filepath.Join(dir, KeyFileName))
+	}
+	key, err := x509.ParsePKCS8PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse local CA key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("local CA key can't sign certificates")
+	}
+	return &CA{Cert: cert, CertPEM: certPEM, CertPath: certPath, key: signer}, nil
+}
+
+// LoadOrCreate reads the CA in dir, creating it on first use
+func LoadOrCreate(dir string) (*CA, error) {
+	ca, err := Load(dir)
+	if err != nil || ca != nil {
+		return ca, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate local CA key: %w", err)
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"kraze local CA"}, CommonName: "kraze " + owner()},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create local CA: %w", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode local CA key: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create local CA directory: %w", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(filepath.Join(dir, KeyFileName), keyPEM, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write local CA key: %w", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, CertFileName), certPEM, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write local CA: %w", err)
+	}
+	return Load(dir)
+}
+
+// owner describes who the CA belongs to, as user@host
+func owner() string {
+	name := "user"
+	if current, err := user.Current(); err == nil {
+		name = current.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		name += "@" + host
+	}
+	return name
+}
+
+// randomSerial returns a random 128-bit certificate serial number
+func randomSerial() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+	return serial, nil
+}
+
+// Issue returns a PEM certificate and key for hosts, which are DNS names
+// (optionally with a leading wildcard label) or IP addresses
+func (ca *CA) Issue(hosts []string) ([]byte, []byte, error) {
+	if len(hosts) == 0 {
+		return nil, nil, fmt.Errorf("no hosts to issue a certificate for")
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{Organization: []string{"kraze local certificate"}, OrganizationalUnit: []string{owner()}},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(certValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if template.NotAfter.After(ca.Cert.NotAfter) {
+		template.NotAfter = ca.Cert.NotAfter
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.Cert, &key.PublicKey, ca.key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to issue certificate for %s: %w", strings.Join(hosts, ", "), err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), nil
+}
+
+// Covers reports whether certPEM was signed by the CA, is valid for every
+// host, and doesn't expire within the renewal window, so it can be kept
+func (ca *CA) Covers(certPEM []byte, hosts []string, now time.Time) bool {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return false
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil || cert.CheckSignatureFrom(ca.Cert) != nil {
+		return false
+	}
+	if now.Add(renewBefore).After(cert.NotAfter) {
+		return false
+	}
+	// Certificates for hosts that were removed are replaced too
+	if len(cert.DNSNames)+len(cert.IPAddresses) != len(hosts) {
+		return false
+	}
+	for _, host := range hosts {
+		if !certificateNames(cert)[host] {
+			return false
+		}
+	}
+	return true
+}
+
+// certificateNames returns the DNS names and IP addresses a certificate was issued for
+func certificateNames(cert *x509.Certificate) map[string]bool {
+	names := make(map[string]bool, len(cert.DNSNames)+len(cert.IPAddresses))
+	for _, name := range cert.DNSNames {
+		names[name] = true
+	}
+	for _, ip := range cert.IPAddresses {
+		names[ip.String()] = true
+	}
+	return names
+}
+
+// MatchesHost reports whether a certificate for pattern is valid for host,
+// where a leading *. matches exactly one label
+func MatchesHost(pattern, host string) bool {
+	pattern = strings.ToLower(pattern)
+	host = strings.ToLower(host)
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		label, rest, found := strings.Cut(host, ".")
+		return found && label != "" && rest == suffix
+	}
+	return pattern == host
+}
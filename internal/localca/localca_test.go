@@ -0,0 +1,114 @@
+package localca
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+)
+
+func TestLoadOrCreate(test *testing.T) {
+	dir := test.TempDir()
+
+	missing, err := Load(dir)
+	if err != nil || missing != nil {
+		test.Fatalf("Load() of an empty directory = %v, %v, want nil, nil", missing, err)
+	}
+
+	created, err := LoadOrCreate(dir)
+	if err != nil {
+		test.Fatalf("LoadOrCreate() error = %v", err)
+	}
+	if !created.Cert.IsCA {
+		test.Error("Expected a CA certificate")
+	}
+
+	loaded, err := LoadOrCreate(dir)
+	if err != nil {
+		test.Fatalf("LoadOrCreate() error = %v", err)
+	}
+	if !loaded.Cert.Equal(created.Cert) {
+		test.Error("Expected the existing CA to be reused")
+	}
+}
+
+func TestIssue(test *testing.T) {
+	ca, err := LoadOrCreate(test.TempDir())
+	if err != nil {
+		test.Fatal(err)
+	}
+	hosts := []string{"app.localtest.me", "*.app.localtest.me", "127.0.0.1"}
+	certPEM, keyPEM, err := ca.Issue(hosts)
+	if err != nil {
+		test.Fatalf("Issue() error = %v", err)
+	}
+	if block, _ := pem.Decode(keyPEM); block == nil || block.Type != "PRIVATE KEY" {
+		test.Error("Expected a PEM private key")
+	}
+
+	block, _ := pem.Decode(certPEM)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		test.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Cert)
+	for _, host := range []string{"app.localtest.me", "api.app.localtest.me", "127.0.0.1"} {
+		if _, err := cert.Verify(x509.VerifyOptions{DNSName: host, Roots: roots}); err != nil {
+			test.Errorf("Expected the certificate to be valid for %s: %v", host, err)
+		}
+	}
+
+	now := time.Now()
+	tests := []struct {
+		name     string
+		certPEM  []byte
+		hosts    []string
+		now      time.Time
+		expected bool
+	}{
+		{name: "same hosts", certPEM: certPEM, hosts: hosts, now: now, expected: true},
+		{name: "host added", certPEM: certPEM, hosts: append([]string{"other.localtest.me"}, hosts...), now: now},
+		{name: "host removed", certPEM: certPEM, hosts: hosts[:1], now: now},
+		{name: "about to expire", certPEM: certPEM, hosts: hosts, now: cert.NotAfter.Add(-24 * time.Hour)},
+		{name: "not a certificate", certPEM: []byte("garbage"), hosts: hosts, now: now},
+	}
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if result := ca.Covers(tt.certPEM, tt.hosts, tt.now); result != tt.expected {
+				test.Errorf("Covers() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+
+	other, err := LoadOrCreate(test.TempDir())
+	if err != nil {
+		test.Fatal(err)
+	}
+	if other.Covers(certPEM, hosts, now) {
+		test.Error("Expected a certificate of another CA not to be covered")
+	}
+}
+
+func TestMatchesHost(test *testing.T) {
+	tests := []struct {
+		pattern  string
+		host     string
+		expected bool
+	}{
+		{pattern: "app.localtest.me", host: "app.localtest.me", expected: true},
+		{pattern: "App.Localtest.me", host: "app.localtest.me", expected: true},
+		{pattern: "*.localtest.me", host: "app.localtest.me", expected: true},
+		{pattern: "*.localtest.me", host: "localtest.me"},
+		{pattern: "*.localtest.me", host: "api.app.localtest.me"},
+		{pattern: "app.localtest.me", host: "api.localtest.me"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.pattern+" "+tt.host, func(test *testing.T) {
+			if result := MatchesHost(tt.pattern, tt.host); result != tt.expected {
+				test.Errorf("MatchesHost(%s, %s) = %v, want %v", tt.pattern, tt.host, result, tt.expected)
+			}
+		})
+	}
+}
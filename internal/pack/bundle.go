@@ -0,0 +1,109 @@
+package pack
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/config"
+)
+
+// Where a bundle keeps its saved images
+const (
+	nodeImageArchive = bundleDir + "/images/node.tar"
+	imagesArchive    = bundleDir + "/images/images.tar"
+)
+
+// BundleImages are the images saved for a bundle, as written by 'docker save'
+type BundleImages struct {
+	NodeImage        string   // kind node image
+	NodeImageArchive string   // Archive of the node image on disk
+	Images           []string // Images the services use
+	ImagesArchive    string   // Archive of Images on disk (unused without images)
+}
+
+// Bundle is a bundle extracted by OpenBundle
+type Bundle struct {
+	Dir         string          // Directory the bundle was extracted to
+	ConfigFiles []string        // Absolute paths of the extracted config files
+	Metadata    PackageMetadata // The bundle's kraze-package.json
+}
+
+// CreateBundle creates a package like CreatePackage that also carries the
+// saved node and service images, so the deployment installs without network
+// access ('kraze up --from-bundle')
+func CreateBundle(configPaths []string, cfg *config.Config, krazeVersion string, outputPath string, images *BundleImages, verbose bool) error {
+	if images == nil || images.NodeImage == "" {
+		return fmt.Errorf("a bundle needs the saved node image")
+	}
+	return createArchive(configPaths, cfg, krazeVersion, outputPath, images, verbose)
+}
+
+// OpenBundle extracts a bundle made by 'kraze bundle' to a temp directory.
+// The returned cleanup function removes it.
+func OpenBundle(archivePath string) (*Bundle, func(), error) {
+	noop := func() {}
+	tmpDir, err := os.MkdirTemp("", "kraze-bundle-*")
+	if err != nil {
+		return nil, noop, fmt.Errorf("creating temp dir for bundle extraction: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(tmpDir) }
+
+	if err := extractTar(archivePath, tmpDir, nil); err != nil {
+		cleanup()
+		return nil, noop, fmt.Errorf("extracting bundle %s: %w", archivePath, err)
+	}
+	meta, err := readPackageMetadata(tmpDir)
+	if err != nil {
+		cleanup()
+		return nil, noop, err
+	}
+	if meta.NodeImage == "" {
+		cleanup()
+		return nil, noop, fmt.Errorf("%s is a package without images, not a bundle; create one with 'kraze bundle' or use 'kraze up -f %s'", archivePath, archivePath)
+	}
+	configFiles, err := readPackageConfigFiles(tmpDir)
+	if err != nil {
+		cleanup()
+		return nil, noop, err
+	}
+
+	bundle := &Bundle{Dir: tmpDir, Metadata: meta}
+	for _, file := range configFiles {
+		bundle.ConfigFiles = append(bundle.ConfigFiles, filepath.Join(tmpDir, filepath.FromSlash(file)))
+	}
+	return bundle, cleanup, nil
+}
+
+// ImageArchives returns the saved image archives of the bundle on disk, the
+// node image first
+func (bundle *Bundle) ImageArchives() []string {
+	archives := []string{filepath.Join(bundle.Dir, filepath.FromSlash(nodeImageArchive))}
+	if len(bundle.Metadata.Images) > 0 {
+		archives = append(archives, filepath.Join(bundle.Dir, filepath.FromSlash(imagesArchive)))
+	}
+	return archives
+}
+
+// Apply points the config at what the bundle carries: the saved node image,
+// and the pulled chart of every service still referencing a chart repository.
+// Those are the built-in add-ons, which aren't in the config files the bundle
+// rewrote.
+func (bundle *Bundle) Apply(cfg *config.Config) {
+	cfg.Cluster.NodeImage = bundle.Metadata.NodeImage
+	for name, svc := range cfg.Services {
+		archPath, pulled := bundle.Metadata.Charts[name]
+		if !pulled || !svc.IsRemoteChart() {
+			continue
+		}
+		svc.Path = filepath.Join(bundle.Dir, filepath.FromSlash(archPath))
+		svc.Repo, svc.Chart, svc.Version = "", "", ""
+		cfg.Services[name] = svc
+	}
+}
+
+// isImageArchive returns true for the saved images of a bundle
+func isImageArchive(archPath string) bool {
+	return strings.HasPrefix(archPath, bundleDir+"/images/")
+}
@@ -0,0 +1,148 @@
+package pack
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+)
+
+// buildBundle creates a bundle of a config without remote sources, with fake
+// image archives
+func buildBundle(t *testing.T, images []string) string {
+	t.Helper()
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "kraze.yml")
+	writeFile(t, cfgPath, "cluster:\n  name: test\nservices:\n  web:\n    type: manifests\n    path: web.yaml\n")
+	writeFile(t, filepath.Join(dir, "web.yaml"), "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web\n")
+	writeFile(t, filepath.Join(dir, "node.tar"), "node image")
+	writeFile(t, filepath.Join(dir, "images.tar"), "service images")
+
+	cfg, err := config.ParseMultiple([]string{cfgPath})
+	if err != nil {
+		t.Fatalf("ParseMultiple() error: %v", err)
+	}
+	outFile := filepath.Join(t.TempDir(), "test-bundle.tar.gz")
+	err = CreateBundle([]string{cfgPath}, cfg, "test", outFile, &BundleImages{
+		NodeImage:        "kindest/node:v1.35.0",
+		NodeImageArchive: filepath.Join(dir, "node.tar"),
+		Images:           images,
+		ImagesArchive:    filepath.Join(dir, "images.tar"),
+	}, false)
+	if err != nil {
+		t.Fatalf("CreateBundle() error: %v", err)
+	}
+	return outFile
+}
+
+func TestCreateBundle_RequiresNodeImage(t *testing.T) {
+	if err := CreateBundle(nil, nil, "test", filepath.Join(t.TempDir(), "b.tar.gz"), &BundleImages{}, false); err == nil {
+		t.Fatal("expected an error without a node image")
+	}
+}
+
+func TestOpenBundle_RoundTrip(t *testing.T) {
+	bundleFile := buildBundle(t, []string{"nginx:1.27", "redis:7"})
+
+	bundle, cleanup, err := OpenBundle(bundleFile)
+	if err != nil {
+		t.Fatalf("OpenBundle() error: %v", err)
+	}
+	defer cleanup()
+
+	if bundle.Metadata.NodeImage != "kindest/node:v1.35.0" {
+		t.Errorf("NodeImage = %q, want kindest/node:v1.35.0", bundle.Metadata.NodeImage)
+	}
+	if !reflect.DeepEqual(bundle.Metadata.Images, []string{"nginx:1.27", "redis:7"}) {
+		t.Errorf("Images = %v", bundle.Metadata.Images)
+	}
+	if len(bundle.ConfigFiles) != 1 || !strings.HasSuffix(bundle.ConfigFiles[0], "kraze.yml") {
+		t.Fatalf("ConfigFiles = %v, want the extracted kraze.yml", bundle.ConfigFiles)
+	}
+
+	archives := bundle.ImageArchives()
+	if len(archives) != 2 {
+		t.Fatalf("ImageArchives() = %v, want the node and service archives", archives)
+	}
+	for itr, want := range []string{"node image", "service images"} {
+		data, err := os.ReadFile(archives[itr])
+		if err != nil {
+			t.Fatalf("reading %s: %v", archives[itr], err)
+		}
+		if string(data) != want {
+			t.Errorf("%s = %q, want %q", archives[itr], data, want)
+		}
+	}
+}
+
+func TestOpenBundle_NodeImageOnly(t *testing.T) {
+	bundle, cleanup, err := OpenBundle(buildBundle(t, nil))
+	if err != nil {
+		t.Fatalf("OpenBundle() error: %v", err)
+	}
+	defer cleanup()
+
+	if archives := bundle.ImageArchives(); len(archives) != 1 || !strings.HasSuffix(archives[0], "node.tar") {
+		t.Errorf("ImageArchives() = %v, want only the node image", archives)
+	}
+}
+
+func TestOpenBundle_RejectsPackage(t *testing.T) {
+	pkg := buildMinimalPackage(t, "cluster:\n  name: test\nservices: {}\n", nil)
+	if _, _, err := OpenBundle(pkg); err == nil || !strings.Contains(err.Error(), "not a bundle") {
+		t.Errorf("OpenBundle() error = %v, want a package rejected", err)
+	}
+}
+
+func TestMaybeExtract_SkipsBundleImages(t *testing.T) {
+	resolved, cleanup, err := MaybeExtract([]string{buildBundle(t, []string{"nginx:1.27"})})
+	if err != nil {
+		t.Fatalf("MaybeExtract() error: %v", err)
+	}
+	defer cleanup()
+
+	root := filepath.Dir(resolved[0])
+	if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(nodeImageArchive))); !os.IsNotExist(err) {
+		t.Errorf("expected the node image archive to be skipped, got err %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "web.yaml")); err != nil {
+		t.Errorf("expected the manifest to be extracted: %v", err)
+	}
+}
+
+func TestBundleApply(t *testing.T) {
+	bundle := &Bundle{
+		Dir: "/tmp/bundle",
+		Metadata: PackageMetadata{
+			NodeImage: "kindest/node:v1.35.0",
+			Charts:    map[string]string{"cert-manager": bundleDir + "/charts/cert-manager.tgz", "db": bundleDir + "/charts/db.tgz"},
+		},
+	}
+	cfg := &config.Config{
+		Cluster: config.ClusterConfig{Name: "test"},
+		Services: map[string]config.ServiceConfig{
+			"cert-manager": {Name: "cert-manager", Type: "helm", Repo: "https://charts.jetstack.io", Chart: "cert-manager", Version: "v1.16.0"},
+			"db":           {Name: "db", Type: "helm", Path: "/tmp/bundle/.krazepack/charts/db.tgz"},
+			"web":          {Name: "web", Type: "helm", Repo: "https://charts.example.com", Chart: "web"},
+		},
+	}
+
+	bundle.Apply(cfg)
+
+	if cfg.Cluster.NodeImage != "kindest/node:v1.35.0" {
+		t.Errorf("NodeImage = %q, want the bundle's", cfg.Cluster.NodeImage)
+	}
+	certManager := cfg.Services["cert-manager"]
+	if certManager.Path != filepath.Join("/tmp/bundle", bundleDir, "charts", "cert-manager.tgz") || certManager.Repo != "" || certManager.Chart != "" || certManager.Version != "" {
+		t.Errorf("cert-manager = %+v, want the bundled chart", certManager)
+	}
+	if cfg.Services["db"].Path != "/tmp/bundle/.krazepack/charts/db.tgz" {
+		t.Errorf("db path = %q, want it unchanged", cfg.Services["db"].Path)
+	}
+	if cfg.Services["web"].Repo != "https://charts.example.com" {
+		t.Errorf("web = %+v, want a service without a bundled chart unchanged", cfg.Services["web"])
+	}
+}
@@ -36,6 +36,11 @@ type PackageMetadata struct {
 	KrazeVersion string   `json:"kraze_version"`
 	CreatedAt    string   `json:"created_at"`
 	ConfigFiles  []string `json:"config_files"`
+
+	// Bundles only (kraze bundle)
+	NodeImage string            `json:"node_image,omitempty"` // kind node image, saved in the node image archive
+	Images    []string          `json:"images,omitempty"`     // Service images, saved in the images archive
+	Charts    map[string]string `json:"charts,omitempty"`     // Archive path of each pulled remote chart, by service
 }
 
 // remoteChartInfo tracks a pulled Helm chart .tgz.
@@ -212,6 +217,13 @@ func CollectLocalAssets(archiveRoot, firstConfigDir string, cfg *config.Config)
 		}
 	}
 
+	// kind config file — resolved by ResolvePaths, must be inside the project.
+	if cfg.Cluster.KindConfig != "" {
+		if err := addPath(cfg.Cluster.KindConfig, "kind_config "+cfg.Cluster.KindConfig, ""); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// Services
 	for name, svc := range cfg.Services {
 		if !svc.IsEnabled() {
@@ -292,11 +304,22 @@ func isOutsideArchiveRoot(archiveRoot, absPath string) bool {
 //
 // The archive is written to a temp file and atomically renamed on success.
 func CreatePackage(configPaths []string, cfg *config.Config, krazeVersion string, outputPath string, verbose bool) error {
+	return createArchive(configPaths, cfg, krazeVersion, outputPath, nil, verbose)
+}
+
+// createArchive writes a package, with the saved images of a bundle when images is set
+func createArchive(configPaths []string, cfg *config.Config, krazeVersion string, outputPath string, images *BundleImages, verbose bool) error {
 	archiveRoot, err := CommonAncestor(configPaths)
 	if err != nil {
 		return err
 	}
 
+	for _, cfgPath := range configPaths {
+		if err := checkPackable(cfgPath); err != nil {
+			return err
+		}
+	}
+
 	// Guard against .krazepack/ collision in the project.
 	if _, err := os.Stat(filepath.Join(archiveRoot, bundleDir)); err == nil {
 		return fmt.Errorf("directory %q already exists in %s — this name is reserved by kraze pack; rename it first", bundleDir, archiveRoot)
@@ -311,7 +334,7 @@ func CreatePackage(configPaths []string, cfg *config.Config, krazeVersion string
 
 	// Pull remote Helm charts.
 	remoteCharts := make(map[string]remoteChartInfo)
-	helmProvider := providers.NewHelmProviderForPacking(verbose)
+	helmProvider := providers.NewHelmProviderForPacking(verbose, cfg.Registries)
 	for name, svc := range cfg.Services {
 		if !svc.IsEnabled() || !svc.IsRemoteChart() {
 			continue
@@ -441,6 +464,21 @@ func CreatePackage(configPaths []string, cfg *config.Config, krazeVersion string
 		}
 	}
 
+	// Write the saved images of a bundle.
+	if images != nil {
+		if verbose {
+			fmt.Printf("  Adding %d saved image(s)...\n", len(images.Images)+1)
+		}
+		if err := addFileToTar(tw, images.NodeImageArchive, nodeImageArchive); err != nil {
+			return fmt.Errorf("writing node image archive: %w", err)
+		}
+		if len(images.Images) > 0 {
+			if err := addFileToTar(tw, images.ImagesArchive, imagesArchive); err != nil {
+				return fmt.Errorf("writing images archive: %w", err)
+			}
+		}
+	}
+
 	// Write metadata.
 	configFileArchPaths := make([]string, len(configPaths))
 	for i, p := range configPaths {
@@ -453,6 +491,14 @@ func CreatePackage(configPaths []string, cfg *config.Config, krazeVersion string
 		CreatedAt:    time.Now().UTC().Format(time.RFC3339),
 		ConfigFiles:  configFileArchPaths,
 	}
+	if images != nil {
+		meta.NodeImage = images.NodeImage
+		meta.Images = images.Images
+		meta.Charts = make(map[string]string, len(remoteCharts))
+		for name, pc := range remoteCharts {
+			meta.Charts[name] = pc.archPath
+		}
+	}
 	metaBytes, err := json.MarshalIndent(meta, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshaling metadata: %w", err)
@@ -474,6 +520,34 @@ func CreatePackage(configPaths []string, cfg *config.Config, krazeVersion string
 	return os.Rename(tmpOutput, outputPath)
 }
 
+// checkPackable rejects configs composed from other files with include or
+// extends, whose services can't be rewritten in place
+func checkPackable(configPath string) error {
+	rawBytes, err := os.ReadFile(configPath)
+	if err != nil {
+		return err
+	}
+
+	var composition struct {
+		Include  []string `yaml:"include"`
+		Services map[string]struct {
+			Extends interface{} `yaml:"extends"`
+		} `yaml:"services"`
+	}
+	if err := yaml.Unmarshal(rawBytes, &composition); err != nil {
+		return fmt.Errorf("parsing %s: %w", configPath, err)
+	}
+	if len(composition.Include) > 0 {
+		return fmt.Errorf("%s uses 'include', which can't be packed; copy the included services into it first", configPath)
+	}
+	for name, svc := range composition.Services {
+		if svc.Extends != nil {
+			return fmt.Errorf("service '%s' in %s uses 'extends', which can't be packed; copy the base service's settings into it first", name, configPath)
+		}
+	}
+	return nil
+}
+
 // rewriteConfig reads configPath and returns a YAML copy where remote Helm
 // charts, HTTP manifests, and local assets outside the project directory are
 // replaced with .krazepack/-relative local paths.
@@ -634,7 +708,8 @@ func MaybeExtract(cfgPaths []string) ([]string, func(), error) {
 	}
 	cleanup := func() { os.RemoveAll(tmpDir) }
 
-	if err := extractTar(archivePath, tmpDir); err != nil {
+	// The saved images of a bundle are only needed by 'kraze up --from-bundle'
+	if err := extractTar(archivePath, tmpDir, isImageArchive); err != nil {
 		cleanup()
 		return nil, noop, fmt.Errorf("extracting package %s: %w", archivePath, err)
 	}
@@ -658,18 +733,9 @@ func isArchivePath(p string) bool {
 }
 
 func readPackageConfigFiles(dir string) ([]string, error) {
-	metaPath := filepath.Join(dir, MetadataFile)
-	data, err := os.ReadFile(metaPath)
+	meta, err := readPackageMetadata(dir)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return []string{"kraze.yml"}, nil
-		}
-		return nil, fmt.Errorf("reading package metadata: %w", err)
-	}
-
-	var meta PackageMetadata
-	if err := json.Unmarshal(data, &meta); err != nil {
-		return nil, fmt.Errorf("parsing package metadata: %w", err)
+		return nil, err
 	}
 
 	if len(meta.ConfigFiles) == 0 {
@@ -678,6 +744,24 @@ func readPackageConfigFiles(dir string) ([]string, error) {
 	return meta.ConfigFiles, nil
 }
 
+// readPackageMetadata reads kraze-package.json from an extracted package;
+// packages without one get empty metadata
+func readPackageMetadata(dir string) (PackageMetadata, error) {
+	var meta PackageMetadata
+	data, err := os.ReadFile(filepath.Join(dir, MetadataFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return meta, nil
+		}
+		return meta, fmt.Errorf("reading package metadata: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return meta, fmt.Errorf("parsing package metadata: %w", err)
+	}
+	return meta, nil
+}
+
 func downloadURL(url string) ([]byte, error) {
 	resp, err := http.Get(url) //nolint:noctx
 	if err != nil {
@@ -690,6 +774,47 @@ func downloadURL(url string) ([]byte, error) {
 	return io.ReadAll(resp.Body)
 }
 
+// WriteDirArchive packs the files of dir into a .tar.gz at outputPath. The
+// archive is written to a temp file and renamed on success.
+func WriteDirArchive(dir, outputPath string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(outputPath), ".kraze-archive-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	gw := gzip.NewWriter(tmp)
+	tw := tar.NewWriter(gw)
+	err = filepath.WalkDir(dir, func(srcPath string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, srcPath)
+		if err != nil {
+			return err
+		}
+		return addFileToTar(tw, srcPath, filepath.ToSlash(rel))
+	})
+	if err == nil {
+		err = tw.Close()
+	}
+	if err == nil {
+		err = gw.Close()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), outputPath)
+}
+
+// ExtractArchive extracts a .tar.gz written by WriteDirArchive into destDir
+func ExtractArchive(archivePath, destDir string) error {
+	return extractTar(archivePath, destDir, nil)
+}
+
 func addFileToTar(tw *tar.Writer, srcPath, archPath string) error {
 	f, err := os.Open(srcPath)
 	if err != nil {
@@ -731,7 +856,9 @@ func addBytesToTar(tw *tar.Writer, content []byte, archPath string) error {
 	return err
 }
 
-func extractTar(archivePath, destDir string) error {
+// extractTar extracts an archive to destDir, leaving out the entries skip
+// returns true for (skip may be nil)
+func extractTar(archivePath, destDir string, skip func(name string) bool) error {
 	f, err := os.Open(archivePath)
 	if err != nil {
 		return err
@@ -760,6 +887,10 @@ func extractTar(archivePath, destDir string) error {
 			return fmt.Errorf("invalid archive entry: path traversal in %q", header.Name)
 		}
 
+		if skip != nil && skip(filepath.ToSlash(cleanName)) {
+			continue
+		}
+
 		destPath := filepath.Join(destDir, filepath.FromSlash(cleanName))
 
 		switch header.Typeflag {
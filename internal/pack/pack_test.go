@@ -913,7 +913,7 @@ func TestExtractTar_PathTraversal(t *testing.T) {
 	f.Close()
 
 	destDir := t.TempDir()
-	err := extractTar(outFile, destDir)
+	err := extractTar(outFile, destDir, nil)
 	if err == nil {
 		t.Fatal("expected error for path traversal, got nil")
 	}
@@ -921,3 +921,71 @@ func TestExtractTar_PathTraversal(t *testing.T) {
 		t.Errorf("expected 'path traversal' in error, got: %v", err)
 	}
 }
+
+func TestCheckPackable(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "plain config", content: "cluster:\n  name: dev\nservices:\n  api:\n    type: manifests\n    path: ./k8s\n"},
+		{name: "include", content: "include: [catalog.yml]\ncluster:\n  name: dev\n", wantErr: "uses 'include'"},
+		{name: "extends", content: "cluster:\n  name: dev\nservices:\n  db:\n    extends: postgres\n", wantErr: "service 'db'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "kraze.yml")
+			writeFile(t, path, tt.content)
+
+			err := checkPackable(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("checkPackable() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkPackable() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDirArchiveRoundTrip(t *testing.T) {
+	staging := t.TempDir()
+	files := map[string]string{
+		"snapshot.json":                     `{"version":1}`,
+		"services/redis/values.yaml":        "auth:\n  enabled: false\n",
+		"volumes/db/data-postgres-0.tar.gz": "binary",
+	}
+	for name, content := range files {
+		path := filepath.Join(staging, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	archivePath := filepath.Join(t.TempDir(), "dev.snapshot.tgz")
+	if err := WriteDirArchive(staging, archivePath); err != nil {
+		t.Fatalf("WriteDirArchive() error = %v", err)
+	}
+
+	dest := t.TempDir()
+	if err := ExtractArchive(archivePath, dest); err != nil {
+		t.Fatalf("ExtractArchive() error = %v", err)
+	}
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(dest, filepath.FromSlash(name)))
+		if err != nil {
+			t.Errorf("%s missing after extraction: %v", name, err)
+			continue
+		}
+		if string(data) != content {
+			t.Errorf("%s = %q, want %q", name, data, content)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/restmapper"
+)
+
+// Kinds that register APIs later services may depend on. An APIService is usable
+// once the aggregator reports it Available, and a CRD once it is established and
+// its conversion webhook (if any) has a ready endpoint.
+const (
+	kindAPIService = "APIService"
+	kindCRD        = "CustomResourceDefinition"
+)
+
+// discoveryPollInterval is how often discovery is retried while aggregated APIs warm up
+const discoveryPollInterval = 2 * time.Second
+
+// IsAPIRegistration reports whether a resource registers an API (an APIService or a CRD)
+func IsAPIRegistration(obj *unstructured.Unstructured) bool {
+	kind := obj.GetKind()
+	return kind == kindAPIService || kind == kindCRD
+}
+
+// FilterAPIRegistrations returns the APIServices and CRDs found in a list of resources
+func FilterAPIRegistrations(resources []*unstructured.Unstructured) []*unstructured.Unstructured {
+	var registrations []*unstructured.Unstructured
+	for _, obj := range resources {
+		if IsAPIRegistration(obj) {
+			registrations = append(registrations, obj)
+		}
+	}
+	return registrations
+}
+
+// findCondition returns the status and message of a status condition
+func findCondition(status map[string]interface{}, conditionType string) (string, string, bool) {
+	conditions, found, err := unstructured.NestedSlice(status, "conditions")
+	if err != nil || !found {
+		return "", "", false
+	}
+	for _, itr := range conditions {
+		condition, ok := itr.(map[string]interface{})
+		if !ok || condition["type"] != conditionType {
+			continue
+		}
+		conditionStatus, _ := condition["status"].(string)
+		message, _ := condition["message"].(string)
+		return conditionStatus, message, true
+	}
+	return "", "", false
+}
+
+// conversionWebhookService returns the in-cluster service of a CRD's conversion webhook
+func conversionWebhookService(crd *unstructured.Unstructured) (string, string, bool) {
+	strategy, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "strategy")
+	if strategy != "Webhook" {
+		return "", "", false
+	}
+	service, found, _ := unstructured.NestedMap(crd.Object, "spec", "conversion", "webhook", "clientConfig", "service")
+	if !found {
+		return "", "", false
+	}
+	namespace, _ := service["namespace"].(string)
+	name, _ := service["name"].(string)
+	return namespace, name, name != ""
+}
+
+// conversionWebhookReady reports whether a CRD's conversion webhook service has a
+// ready endpoint. CRDs without an in-cluster webhook are always ready.
+func conversionWebhookReady(ctx context.Context, clientset kubernetes.Interface, crd *unstructured.Unstructured) bool {
+	namespace, name, ok := conversionWebhookService(crd)
+	if !ok {
+		return true
+	}
+	return serviceHasReadyEndpoint(ctx, clientset, namespace, name)
+}
+
+// serviceHasReadyEndpoint reports whether a service has an endpoint ready to take requests
+func serviceHasReadyEndpoint(ctx context.Context, clientset kubernetes.Interface, namespace, name string) bool {
+	slices, err := clientset.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + name,
+	})
+	if err != nil {
+		return false
+	}
+	for _, slice := range slices.Items {
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// registeredGroupVersions returns the group/versions served by the APIServices and CRDs in resources
+func registeredGroupVersions(resources []*unstructured.Unstructured) []string {
+	seen := make(map[string]bool)
+	var groupVersions []string
+	add := func(group, version string) {
+		groupVersion := schema.GroupVersion{Group: group, Version: version}.String()
+		if version != "" && !seen[groupVersion] {
+			seen[groupVersion] = true
+			groupVersions = append(groupVersions, groupVersion)
+		}
+	}
+
+	for _, obj := range resources {
+		switch obj.GetKind() {
+		case kindAPIService:
+			group, _, _ := unstructured.NestedString(obj.Object, "spec", "group")
+			version, _, _ := unstructured.NestedString(obj.Object, "spec", "version")
+			add(group, version)
+		case kindCRD:
+			group, _, _ := unstructured.NestedString(obj.Object, "spec", "group")
+			versions, _, _ := unstructured.NestedSlice(obj.Object, "spec", "versions")
+			for _, itr := range versions {
+				version, ok := itr.(map[string]interface{})
+				if !ok || version["served"] == false {
+					continue
+				}
+				name, _ := version["name"].(string)
+				add(group, name)
+			}
+		}
+	}
+	return groupVersions
+}
+
+// waitForDiscovery waits until discovery serves every group/version. The
+// aggregator can report an APIService Available a few seconds before discovery
+// (and so the REST mappers of later services) picks it up.
+func waitForDiscovery(ctx context.Context, discoveryClient discovery.DiscoveryInterface, groupVersions []string, out io.Writer, verbose bool) error {
+	pending := groupVersions
+	for {
+		var remaining []string
+		var lastErr error
+		for _, groupVersion := range pending {
+			if _, err := discoveryClient.ServerResourcesForGroupVersion(groupVersion); err != nil {
+				remaining = append(remaining, groupVersion)
+				lastErr = err
+			}
+		}
+		if len(remaining) == 0 {
+			return nil
+		}
+		pending = remaining
+		if verbose {
+			fmt.Fprintf(out, "    Waiting for discovery to serve %v: %v\n", pending, lastErr)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("API %v is registered but not served by discovery yet: %w", pending, lastErr)
+		case <-time.After(discoveryPollInterval):
+		}
+	}
+}
+
+// restMappingWithReset maps a kind to its resource, resetting the mapper's cached
+// discovery once when the kind is unknown, since it may belong to a CRD or
+// APIService registered after the mapper was created
+func restMappingWithReset(mapper *restmapper.DeferredDiscoveryRESTMapper, gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil && meta.IsNoMatchError(err) {
+		mapper.Reset()
+		mapping, err = mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	}
+	return mapping, err
+}
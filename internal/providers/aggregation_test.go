@@ -0,0 +1,192 @@
+package providers
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const aggregationTestAPIService = `
+apiVersion: apiregistration.k8s.io/v1
+kind: APIService
+metadata:
+  name: v1beta1.metrics.k8s.io
+spec:
+  group: metrics.k8s.io
+  version: v1beta1
+  service:
+    name: metrics-server
+    namespace: kube-system
+`
+
+const aggregationTestCRD = `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+    plural: widgets
+  scope: Namespaced
+  conversion:
+    strategy: Webhook
+    webhook:
+      conversionReviewVersions: [v1]
+      clientConfig:
+        service:
+          name: widget-webhook
+          namespace: widgets
+  versions:
+    - name: v1
+      served: true
+      storage: true
+    - name: v1beta1
+      served: true
+      storage: false
+    - name: v1alpha1
+      served: false
+      storage: false
+`
+
+func TestIsResourceReadyAPIRegistrations(test *testing.T) {
+	tests := []struct {
+		name       string
+		kind       string
+		conditions []interface{}
+		want       bool
+		wantErr    bool
+	}{
+		{
+			name:       "available APIService",
+			kind:       kindAPIService,
+			conditions: []interface{}{map[string]interface{}{"type": "Available", "status": "True"}},
+			want:       true,
+		},
+		{
+			name: "APIService failing discovery",
+			kind: kindAPIService,
+			conditions: []interface{}{map[string]interface{}{
+				"type": "Available", "status": "False", "reason": "FailedDiscoveryCheck",
+				"message": "failing or missing response from https://10.96.0.10:443/apis/metrics.k8s.io/v1beta1",
+			}},
+			want: false,
+		},
+		{
+			name: "established CRD",
+			kind: kindCRD,
+			conditions: []interface{}{
+				map[string]interface{}{"type": "NamesAccepted", "status": "True"},
+				map[string]interface{}{"type": "Established", "status": "True"},
+			},
+			want: true,
+		},
+		{
+			name:       "CRD not yet established",
+			kind:       kindCRD,
+			conditions: []interface{}{map[string]interface{}{"type": "NamesAccepted", "status": "True"}},
+			want:       false,
+		},
+		{
+			name:       "CRD with conflicting names",
+			kind:       kindCRD,
+			conditions: []interface{}{map[string]interface{}{"type": "NamesAccepted", "status": "False", "message": "plural is already in use"}},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			manifest := aggregationTestAPIService
+			if tt.kind == kindCRD {
+				manifest = aggregationTestCRD
+			}
+			obj := lintTestObjects(test, manifest)[0]
+			obj.Object["status"] = map[string]interface{}{"conditions": tt.conditions}
+
+			got, _, err := isResourceReady(obj, tt.kind)
+			if (err != nil) != tt.wantErr {
+				test.Fatalf("isResourceReady() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				test.Errorf("isResourceReady() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisteredGroupVersions(test *testing.T) {
+	resources := lintTestObjects(test, aggregationTestAPIService, aggregationTestCRD, lintTestDeployment)
+
+	if got := len(FilterAPIRegistrations(resources)); got != 2 {
+		test.Errorf("FilterAPIRegistrations() returned %d resources, want 2", got)
+	}
+
+	want := []string{"metrics.k8s.io/v1beta1", "example.com/v1", "example.com/v1beta1"}
+	if got := registeredGroupVersions(resources); !reflect.DeepEqual(got, want) {
+		test.Errorf("registeredGroupVersions() = %v, want %v", got, want)
+	}
+}
+
+func TestConversionWebhookReady(test *testing.T) {
+	crd := lintTestObjects(test, aggregationTestCRD)[0]
+	ready := true
+	notReady := false
+	slice := func(name string, readiness *bool) *discoveryv1.EndpointSlice {
+		return &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "widgets", Labels: map[string]string{discoveryv1.LabelServiceName: "widget-webhook"}},
+			Endpoints:  []discoveryv1.Endpoint{{Addresses: []string{"10.244.0.5"}, Conditions: discoveryv1.EndpointConditions{Ready: readiness}}},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		clientset *fake.Clientset
+		want      bool
+	}{
+		{name: "no endpoints", clientset: fake.NewSimpleClientset(), want: false},
+		{name: "endpoint not ready", clientset: fake.NewSimpleClientset(slice("widget-webhook-1", &notReady)), want: false},
+		{name: "ready endpoint", clientset: fake.NewSimpleClientset(slice("widget-webhook-1", &ready)), want: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := conversionWebhookReady(context.Background(), tt.clientset, crd); got != tt.want {
+				test.Errorf("conversionWebhookReady() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// CRDs without a conversion webhook never wait on endpoints
+	plain := crd.DeepCopy()
+	delete(plain.Object["spec"].(map[string]interface{}), "conversion")
+	if !conversionWebhookReady(context.Background(), fake.NewSimpleClientset(), plain) {
+		test.Error("conversionWebhookReady() without a webhook = false, want true")
+	}
+}
+
+func TestWaitForDiscovery(test *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	discoveryClient := clientset.Discovery().(*fakediscovery.FakeDiscovery)
+	discoveryClient.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "metrics.k8s.io/v1beta1", APIResources: []metav1.APIResource{{Name: "pods", Kind: "PodMetrics"}}},
+	}
+
+	if err := waitForDiscovery(context.Background(), discoveryClient, []string{"metrics.k8s.io/v1beta1"}, io.Discard, false); err != nil {
+		test.Errorf("waitForDiscovery() for a served group = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := waitForDiscovery(ctx, discoveryClient, []string{"custom.metrics.k8s.io/v1beta2"}, io.Discard, false); err == nil {
+		test.Error("waitForDiscovery() for an unserved group should time out")
+	}
+}
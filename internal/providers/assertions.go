@@ -0,0 +1,214 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hjames9/kraze/internal/config"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+)
+
+// assertionInterval is the pause between failed assertion attempts
+const assertionInterval = 2 * time.Second
+
+// AssertionResult is the outcome of one expectation of a service's assertion
+type AssertionResult struct {
+	Assertion string `json:"assertion"`         // What was expected (e.g., "Deployment/api status.readyReplicas = 2")
+	Passed    bool   `json:"passed"`            // Whether the expectation held before the assertion's timeout
+	Message   string `json:"message,omitempty"` // What was found instead
+}
+
+// String formats the result as "PASS Deployment/api status.readyReplicas = 2"
+func (result AssertionResult) String() string {
+	if result.Passed {
+		return "PASS " + result.Assertion
+	}
+	if result.Message == "" {
+		return "FAIL " + result.Assertion
+	}
+	return fmt.Sprintf("FAIL %s (%s)", result.Assertion, result.Message)
+}
+
+// AssertionsPassed returns true if every result passed
+func AssertionsPassed(results []AssertionResult) bool {
+	for _, result := range results {
+		if !result.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// assertionResolver maps a kind to its resource and whether it's namespaced
+type assertionResolver func(gvk schema.GroupVersionKind) (schema.GroupVersionResource, bool, error)
+
+// RunAssertions checks a service's assertions against the cluster, retrying
+// each one until it passes or its timeout expires
+func RunAssertions(ctx context.Context, kubeconfig string, svc *config.ServiceConfig) ([]AssertionResult, error) {
+	restConfig, err := getRESTConfigFromKubeconfig(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery client: %w", err)
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient))
+
+	resolve := func(gvk schema.GroupVersionKind) (schema.GroupVersionResource, bool, error) {
+		mapping, err := restMappingWithReset(mapper, gvk)
+		if err != nil {
+			return schema.GroupVersionResource{}, false, err
+		}
+		return mapping.Resource, mapping.Scope.Name() == meta.RESTScopeNameNamespace, nil
+	}
+	return runAssertions(ctx, dynamicClient, resolve, svc), nil
+}
+
+// runAssertions checks each of a service's assertions in order
+func runAssertions(ctx context.Context, client dynamic.Interface, resolve assertionResolver, svc *config.ServiceConfig) []AssertionResult {
+	var results []AssertionResult
+	for itr := range svc.Assertions {
+		results = append(results, retryAssertion(ctx, client, resolve, svc.GetNamespace(), &svc.Assertions[itr])...)
+	}
+	return results
+}
+
+// retryAssertion evaluates an assertion until all of its expectations pass, its
+// timeout expires or ctx is done, returning the last results
+func retryAssertion(ctx context.Context, client dynamic.Interface, resolve assertionResolver, namespace string, assertion *config.AssertionConfig) []AssertionResult {
+	timeout, err := assertion.GetTimeout()
+	if err != nil {
+		timeout = config.DefaultAssertionTimeout
+	}
+	deadline := time.Now().Add(timeout)
+
+	for {
+		results := evaluateAssertion(ctx, client, resolve, namespace, assertion)
+		if AssertionsPassed(results) || !time.Now().Before(deadline) {
+			return results
+		}
+		select {
+		case <-ctx.Done():
+			return results
+		case <-time.After(min(assertionInterval, time.Until(deadline))):
+		}
+	}
+}
+
+// evaluateAssertion checks an assertion once, with one result per expected
+// field, or a single result for whether the resource exists
+func evaluateAssertion(ctx context.Context, client dynamic.Interface, resolve assertionResolver, namespace string, assertion *config.AssertionConfig) []AssertionResult {
+	target := assertion.Target()
+	failed := func(description, message string) []AssertionResult {
+		return []AssertionResult{{Assertion: description, Message: message}}
+	}
+
+	gvr, namespaced, err := resolve(schema.FromAPIVersionAndKind(assertion.GetAPIVersion(), assertion.Kind))
+	if err != nil {
+		return failed(target, fmt.Sprintf("unknown kind: %v", err))
+	}
+	var resource dynamic.ResourceInterface = client.Resource(gvr)
+	if namespaced {
+		if assertion.Namespace != "" {
+			namespace = assertion.Namespace
+		}
+		resource = client.Resource(gvr).Namespace(namespace)
+	}
+
+	var objs []unstructured.Unstructured
+	if assertion.Name != "" {
+		obj, err := resource.Get(ctx, assertion.Name, metav1.GetOptions{})
+		switch {
+		case err == nil:
+			objs = append(objs, *obj)
+		case !errors.IsNotFound(err):
+			return failed(target, err.Error())
+		}
+	} else {
+		list, err := resource.List(ctx, metav1.ListOptions{LabelSelector: assertion.Selector})
+		if err != nil {
+			return failed(target, err.Error())
+		}
+		objs = list.Items
+	}
+
+	if assertion.Absent {
+		description := target + " is absent"
+		if len(objs) > 0 {
+			return failed(description, "found "+objectNames(objs))
+		}
+		return []AssertionResult{{Assertion: description, Passed: true}}
+	}
+	if len(objs) == 0 {
+		return failed(target+" exists", "not found")
+	}
+	if len(assertion.Fields) == 0 {
+		return []AssertionResult{{Assertion: target + " exists", Passed: true}}
+	}
+
+	var results []AssertionResult
+	for _, path := range assertion.FieldPaths() {
+		expected := fmt.Sprint(assertion.Fields[path])
+		result := AssertionResult{Assertion: fmt.Sprintf("%s %s = %s", target, path, expected), Passed: true}
+		var mismatches []string
+		for _, obj := range objs {
+			actual, err := fieldValue(&obj, path)
+			if err == nil && actual == expected {
+				continue
+			}
+			found := "got " + actual
+			if err != nil {
+				found = "not found"
+			}
+			if assertion.Selector != "" {
+				found = obj.GetName() + ": " + found
+			}
+			mismatches = append(mismatches, found)
+		}
+		if len(mismatches) > 0 {
+			result.Passed = false
+			result.Message = strings.Join(mismatches, ", ")
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// fieldValue returns an object's value at an assertion's field path, with
+// several matches separated by spaces like 'kubectl get -o jsonpath'
+func fieldValue(obj *unstructured.Unstructured, path string) (string, error) {
+	parser, err := config.ParseFieldPath(path)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := parser.Execute(&out, obj.Object); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// objectNames lists the names of objects for messages
+func objectNames(objs []unstructured.Unstructured) string {
+	names := make([]string, 0, len(objs))
+	for _, obj := range objs {
+		names = append(names, obj.GetName())
+	}
+	return strings.Join(names, ", ")
+}
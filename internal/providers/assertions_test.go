@@ -0,0 +1,108 @@
+package providers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestEvaluateAssertion(test *testing.T) {
+	api := newLabeledObject("apps/v1", "Deployment", "shop", "api", map[string]string{"tier": "backend"})
+	unstructured.SetNestedField(api.Object, int64(2), "status", "readyReplicas")
+	unstructured.SetNestedSlice(api.Object, []any{
+		map[string]any{"type": "Available", "status": "True"},
+		map[string]any{"type": "Progressing", "status": "False"},
+	}, "status", "conditions")
+	worker := newLabeledObject("apps/v1", "Deployment", "shop", "worker", map[string]string{"tier": "backend"})
+	unstructured.SetNestedField(worker.Object, int64(0), "status", "readyReplicas")
+
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{{Group: "apps", Version: "v1", Resource: "deployments"}: "DeploymentList"},
+		api, worker)
+	resolve := func(gvk schema.GroupVersionKind) (schema.GroupVersionResource, bool, error) {
+		return schema.GroupVersionResource{Group: gvk.Group, Version: gvk.Version, Resource: strings.ToLower(gvk.Kind) + "s"}, true, nil
+	}
+
+	tests := []struct {
+		name      string
+		assertion config.AssertionConfig
+		expected  []AssertionResult
+	}{
+		{
+			name:      "field matches",
+			assertion: config.AssertionConfig{APIVersion: "apps/v1", Kind: "Deployment", Name: "api", Fields: map[string]any{"status.readyReplicas": 2}},
+			expected:  []AssertionResult{{Assertion: "Deployment/api status.readyReplicas = 2", Passed: true}},
+		},
+		{
+			name:      "filter expression",
+			assertion: config.AssertionConfig{APIVersion: "apps/v1", Kind: "Deployment", Name: "api", Fields: map[string]any{`status.conditions[?(@.type=="Available")].status`: "True"}},
+			expected:  []AssertionResult{{Assertion: `Deployment/api status.conditions[?(@.type=="Available")].status = True`, Passed: true}},
+		},
+		{
+			name:      "field differs",
+			assertion: config.AssertionConfig{APIVersion: "apps/v1", Kind: "Deployment", Name: "api", Fields: map[string]any{"status.readyReplicas": 3}},
+			expected:  []AssertionResult{{Assertion: "Deployment/api status.readyReplicas = 3", Message: "got 2"}},
+		},
+		{
+			name:      "missing field",
+			assertion: config.AssertionConfig{APIVersion: "apps/v1", Kind: "Deployment", Name: "api", Fields: map[string]any{"spec.paused": true}},
+			expected:  []AssertionResult{{Assertion: "Deployment/api spec.paused = true", Message: "not found"}},
+		},
+		{
+			name:      "missing resource",
+			assertion: config.AssertionConfig{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+			expected:  []AssertionResult{{Assertion: "Deployment/web exists", Message: "not found"}},
+		},
+		{
+			name:      "every selected resource must match",
+			assertion: config.AssertionConfig{APIVersion: "apps/v1", Kind: "Deployment", Selector: "tier=backend", Fields: map[string]any{"status.readyReplicas": 2}},
+			expected:  []AssertionResult{{Assertion: "Deployment[tier=backend] status.readyReplicas = 2", Message: "worker: got 0"}},
+		},
+		{
+			name:      "absent",
+			assertion: config.AssertionConfig{APIVersion: "apps/v1", Kind: "Deployment", Name: "legacy", Absent: true},
+			expected:  []AssertionResult{{Assertion: "Deployment/legacy is absent", Passed: true}},
+		},
+		{
+			name:      "not absent",
+			assertion: config.AssertionConfig{APIVersion: "apps/v1", Kind: "Deployment", Selector: "tier=backend", Absent: true},
+			expected:  []AssertionResult{{Assertion: "Deployment[tier=backend] is absent", Message: "found api, worker"}},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			got := evaluateAssertion(context.Background(), client, resolve, "shop", &tt.assertion)
+			if len(got) != len(tt.expected) {
+				test.Fatalf("evaluateAssertion() = %v, want %v", got, tt.expected)
+			}
+			for itr := range got {
+				if got[itr] != tt.expected[itr] {
+					test.Errorf("result %d = %+v, want %+v", itr, got[itr], tt.expected[itr])
+				}
+			}
+		})
+	}
+}
+
+func TestRetryAssertionTimesOut(test *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	resolve := func(gvk schema.GroupVersionKind) (schema.GroupVersionResource, bool, error) {
+		return schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}, true, nil
+	}
+
+	assertion := &config.AssertionConfig{Kind: "ConfigMap", Name: "api", Timeout: "10ms"}
+	results := retryAssertion(context.Background(), client, resolve, "default", assertion)
+	if AssertionsPassed(results) {
+		test.Errorf("retryAssertion() = %v, want a failure", results)
+	}
+	if len(results) != 1 || results[0].String() != "FAIL ConfigMap/api exists (not found)" {
+		test.Errorf("retryAssertion() = %v", results)
+	}
+}
@@ -0,0 +1,126 @@
+package providers
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+	chartv2 "helm.sh/helm/v4/pkg/chart/v2"
+	v2loader "helm.sh/helm/v4/pkg/chart/v2/loader"
+	"helm.sh/helm/v4/pkg/downloader"
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+// chartDependencyMutex serializes dependency builds, since services sharing a
+// local chart would write the same charts/ directory
+var chartDependencyMutex sync.Mutex
+
+// buildChartDependencies downloads the dependencies a local chart declares but
+// doesn't have in its charts/ directory, like 'helm dependency build': versions
+// come from Chart.lock when there is one, otherwise they're resolved and a
+// Chart.lock is written. Credentials come from Helm's repositories.yaml and
+// registry config. Charts with all their dependencies present are left alone.
+func (helm *HelmProvider) buildChartDependencies(chartPath string) error {
+	if info, err := os.Stat(chartPath); err != nil || !info.IsDir() {
+		return nil // Packaged charts carry their dependencies
+	}
+
+	chartDependencyMutex.Lock()
+	defer chartDependencyMutex.Unlock()
+
+	chrt, err := v2loader.LoadDir(chartPath)
+	if err != nil {
+		return fmt.Errorf("failed to load chart: %w", err)
+	}
+	missing := missingChartDependencies(chrt)
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if !helm.opts.Quiet {
+		fmt.Fprintf(helm.opts.output(), "Building chart dependencies: %s\n", strings.Join(missing, ", "))
+	}
+
+	// Dependencies from HTTP repositories that aren't in repositories.yaml are
+	// added, as for remote charts, so a lock file build finds them
+	for _, dep := range chrt.Metadata.Dependencies {
+		if strings.HasPrefix(dep.Repository, "http://") || strings.HasPrefix(dep.Repository, "https://") {
+			if _, err := helm.addHTTPRepository(dep.Repository); err != nil {
+				return fmt.Errorf("failed to add repository for dependency '%s': %w", dep.Name, err)
+			}
+		}
+	}
+
+	registryClient, err := helm.newRegistryClient()
+	if err != nil {
+		return fmt.Errorf("failed to create registry client: %w", err)
+	}
+
+	out := io.Discard
+	if helm.opts.Verbose {
+		out = os.Stdout
+	}
+	manager := &downloader.Manager{
+		Out:              out,
+		ChartPath:        chartPath,
+		Getters:          getter.All(helm.settings),
+		RegistryClient:   registryClient,
+		RepositoryConfig: helm.settings.RepositoryConfig,
+		RepositoryCache:  helm.settings.RepositoryCache,
+		ContentCache:     helm.settings.ContentCache,
+		Debug:            helm.opts.Verbose,
+	}
+	if err := manager.Build(); err != nil {
+		return fmt.Errorf("failed to build chart dependencies: %w", err)
+	}
+	return nil
+}
+
+// missingChartDependencies returns the dependencies in Chart.yaml that aren't
+// in the chart's charts/ directory at the version Chart.lock pins, or else one
+// Chart.yaml's version constraint allows, as "name version"
+func missingChartDependencies(chrt *chartv2.Chart) []string {
+	present := make(map[string][]string, len(chrt.Dependencies()))
+	for _, sub := range chrt.Dependencies() {
+		present[sub.Name()] = append(present[sub.Name()], sub.Metadata.Version)
+	}
+	locked := make(map[string]string)
+	if chrt.Lock != nil {
+		for _, dep := range chrt.Lock.Dependencies {
+			locked[dep.Name] = dep.Version
+		}
+	}
+
+	var missing []string
+	for _, dep := range chrt.Metadata.Dependencies {
+		wanted := dep.Version
+		if version, exists := locked[dep.Name]; exists {
+			wanted = version
+		}
+		if !slices.ContainsFunc(present[dep.Name], func(version string) bool { return versionSatisfies(version, wanted) }) {
+			missing = append(missing, strings.TrimSpace(dep.Name+" "+wanted))
+		}
+	}
+	return missing
+}
+
+// versionSatisfies reports whether a chart version meets a version or version
+// constraint; an empty constraint allows any version
+func versionSatisfies(version, constraint string) bool {
+	if constraint == "" {
+		return true
+	}
+	parsed, err := semver.NewVersion(version)
+	if err != nil {
+		return version == constraint
+	}
+	constraints, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return version == constraint
+	}
+	return constraints.Check(parsed)
+}
@@ -0,0 +1,102 @@
+package providers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/cli"
+)
+
+// writeDependencyTestChart writes a minimal chart with the given Chart.yaml
+func writeDependencyTestChart(test *testing.T, dir, chartYAML string) {
+	test.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, "templates"), 0755); err != nil {
+		test.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Chart.yaml"), []byte(chartYAML), 0644); err != nil {
+		test.Fatal(err)
+	}
+}
+
+func TestBuildChartDependencies(test *testing.T) {
+	root := test.TempDir()
+	writeDependencyTestChart(test, filepath.Join(root, "common"), "apiVersion: v2\nname: common\nversion: 1.0.0\n")
+	umbrella := filepath.Join(root, "umbrella")
+	writeDependencyTestChart(test, umbrella, `apiVersion: v2
+name: umbrella
+version: 0.1.0
+dependencies:
+  - name: common
+    version: 1.0.0
+    repository: file://../common
+`)
+
+	settings := cli.New()
+	settings.RepositoryConfig = filepath.Join(root, "repositories.yaml")
+	settings.RepositoryCache = filepath.Join(root, "cache")
+	helm := &HelmProvider{settings: settings, opts: &ProviderOptions{Quiet: true}}
+
+	if err := helm.buildChartDependencies(umbrella); err != nil {
+		test.Fatalf("buildChartDependencies() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(umbrella, "charts", "common-1.0.0.tgz")); err != nil {
+		test.Errorf("expected the dependency in charts/: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(umbrella, "Chart.lock")); err != nil {
+		test.Errorf("expected a Chart.lock: %v", err)
+	}
+
+	// A chart with its dependencies present is left alone
+	lock := filepath.Join(umbrella, "Chart.lock")
+	if err := os.Remove(lock); err != nil {
+		test.Fatal(err)
+	}
+	if err := helm.buildChartDependencies(umbrella); err != nil {
+		test.Fatalf("buildChartDependencies() error = %v", err)
+	}
+	if _, err := os.Stat(lock); !os.IsNotExist(err) {
+		test.Errorf("expected no rebuild when dependencies are present")
+	}
+
+	// A dependency present at another version than Chart.yaml asks for is rebuilt
+	writeDependencyTestChart(test, filepath.Join(root, "common"), "apiVersion: v2\nname: common\nversion: 2.0.0\n")
+	writeDependencyTestChart(test, umbrella, `apiVersion: v2
+name: umbrella
+version: 0.1.0
+dependencies:
+  - name: common
+    version: ^2.0.0
+    repository: file://../common
+`)
+	if err := helm.buildChartDependencies(umbrella); err != nil {
+		test.Fatalf("buildChartDependencies() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(umbrella, "charts", "common-2.0.0.tgz")); err != nil {
+		test.Errorf("expected the dependency rebuilt at version 2.0.0: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(umbrella, "charts", "common-1.0.0.tgz")); !os.IsNotExist(err) {
+		test.Errorf("expected the old version of the dependency replaced")
+	}
+}
+
+func TestVersionSatisfies(test *testing.T) {
+	tests := []struct {
+		version    string
+		constraint string
+		want       bool
+	}{
+		{version: "1.0.0", constraint: "", want: true},
+		{version: "1.0.0", constraint: "1.0.0", want: true},
+		{version: "1.0.0", constraint: "2.0.0", want: false},
+		{version: "1.4.2", constraint: "~1.4.0", want: true},
+		{version: "2.0.0", constraint: "^1.0.0", want: false},
+		{version: "not-semver", constraint: "not-semver", want: true},
+	}
+
+	for _, tt := range tests {
+		if got := versionSatisfies(tt.version, tt.constraint); got != tt.want {
+			test.Errorf("versionSatisfies(%q, %q) = %v, want %v", tt.version, tt.constraint, got, tt.want)
+		}
+	}
+}
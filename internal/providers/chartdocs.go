@@ -0,0 +1,354 @@
+package providers
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// maxReadmeMatches caps how many README lines are shown per value
+const maxReadmeMatches = 3
+
+// ChartDocs holds the documentation files of a chart
+type ChartDocs struct {
+	Name   string
+	Values []byte // values.yaml with its comments
+	Readme string
+}
+
+// ChartValueDoc documents a single chart value
+type ChartValueDoc struct {
+	Key         string
+	Comment     string   // Comments on the key in values.yaml, or on its nearest documented parent
+	CommentFrom string   // Parent key the comment was taken from, empty when it is the key's own
+	Default     string   // Chart default as YAML, empty when the chart does not set the key
+	InChart     bool     // The key (or a free-form parent map) exists in the chart's values.yaml
+	Value       string   // The service's value as YAML
+	Overridden  bool     // The service sets this key
+	Readme      []string // README lines that mention the key
+}
+
+// FetchChartDocs reads values.yaml and the README of a service's chart, pulling
+// remote charts into a temporary directory first
+func FetchChartDocs(service *config.ServiceConfig, registries []config.RegistryConfig, verbose bool) (*ChartDocs, error) {
+	if !service.IsHelm() {
+		return nil, fmt.Errorf("service '%s' is a %s service, chart docs are only available for helm services", service.Name, service.Type)
+	}
+
+	if service.IsLocalChart() {
+		info, err := os.Stat(service.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read chart %s: %w", service.Path, err)
+		}
+		if info.IsDir() {
+			return readChartDirDocs(service.Path)
+		}
+		return readChartArchiveDocs(service.Path)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "kraze-chart-docs-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	archive, err := NewHelmProviderForPacking(verbose, registries).PullChartToDir(service, tmpDir)
+	if err != nil {
+		return nil, err
+	}
+	return readChartArchiveDocs(archive)
+}
+
+// LoadServiceValues returns the values a helm service passes to its chart
+func LoadServiceValues(service *config.ServiceConfig) (map[string]interface{}, error) {
+	return NewHelmProviderForPacking(false, nil).loadValues(service)
+}
+
+// isReadmeFile reports whether a chart file name is its README
+func isReadmeFile(name string) bool {
+	lower := strings.ToLower(name)
+	return lower == "readme.md" || lower == "readme.txt" || lower == "readme"
+}
+
+// readChartDirDocs reads the docs of an unpacked chart
+func readChartDirDocs(dir string) (*ChartDocs, error) {
+	docs := &ChartDocs{Name: filepath.Base(dir)}
+
+	values, err := os.ReadFile(filepath.Join(dir, "values.yaml"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read values.yaml: %w", err)
+	}
+	docs.Values = values
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chart %s: %w", dir, err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && isReadmeFile(entry.Name()) {
+			readme, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+			}
+			docs.Readme = string(readme)
+			break
+		}
+	}
+	return docs, nil
+}
+
+// readChartArchiveDocs reads the docs of a packaged chart (.tgz), ignoring subcharts
+func readChartArchiveDocs(archivePath string) (*ChartDocs, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open chart archive: %w", err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chart archive %s: %w", archivePath, err)
+	}
+	defer gz.Close()
+
+	docs := &ChartDocs{}
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read chart archive %s: %w", archivePath, err)
+		}
+
+		// Top-level files are <chart>/<file>
+		parts := strings.Split(path.Clean(header.Name), "/")
+		if len(parts) != 2 || header.Typeflag != tar.TypeReg {
+			continue
+		}
+		docs.Name = parts[0]
+
+		switch {
+		case parts[1] == "values.yaml":
+			if docs.Values, err = io.ReadAll(reader); err != nil {
+				return nil, fmt.Errorf("failed to read values.yaml: %w", err)
+			}
+		case isReadmeFile(parts[1]) && docs.Readme == "":
+			readme, err := io.ReadAll(reader)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", parts[1], err)
+			}
+			docs.Readme = string(readme)
+		}
+	}
+	return docs, nil
+}
+
+// chartValueEntry is a key of the chart's values.yaml
+type chartValueEntry struct {
+	comment string
+	node    *yaml.Node
+}
+
+// DocumentChartValues documents the keys the service overrides (with all, every
+// leaf key of the chart as well), sorted by key. Keys under a non-empty map of
+// the chart that the chart does not define are reported with InChart false,
+// which usually means a typo or a value copied from another chart version.
+func DocumentChartValues(docs *ChartDocs, userValues map[string]interface{}, all bool) ([]ChartValueDoc, error) {
+	entries := make(map[string]chartValueEntry)
+	params := make(map[string]string)
+	var chartLeaves []string
+	if len(docs.Values) > 0 {
+		var root yaml.Node
+		if err := yaml.Unmarshal(docs.Values, &root); err != nil {
+			return nil, fmt.Errorf("failed to parse chart values.yaml: %w", err)
+		}
+		if len(root.Content) > 0 {
+			collectValueEntries(root.Content[0], "", entries, params, &chartLeaves)
+		}
+	}
+
+	// "@param key description" annotations often sit on a parent key's comment
+	for key, description := range params {
+		if entry, ok := entries[key]; ok && entry.comment == "" {
+			entry.comment = description
+			entries[key] = entry
+		}
+	}
+
+	userLeaves := make(map[string]interface{})
+	flattenValues(userValues, "", userLeaves)
+
+	keys := make(map[string]bool)
+	for key := range userLeaves {
+		keys[key] = true
+	}
+	if all {
+		for _, key := range chartLeaves {
+			keys[key] = true
+		}
+	}
+
+	result := make([]ChartValueDoc, 0, len(keys))
+	for key := range keys {
+		doc := ChartValueDoc{Key: key}
+		if value, ok := userLeaves[key]; ok {
+			doc.Overridden = true
+			doc.Value = formatValue(value)
+		}
+
+		if entry, ok := entries[key]; ok {
+			doc.InChart = true
+			doc.Comment = entry.comment
+			doc.Default = formatNode(entry.node)
+		} else {
+			// A free-form map in the chart ({} by default) accepts any nested key
+			parent, entry := nearestValueEntry(key, entries)
+			if parent != "" {
+				doc.InChart = entry.node.Kind == yaml.MappingNode && len(entry.node.Content) == 0
+			}
+		}
+		if doc.Comment == "" {
+			for parent := parentKey(key); parent != ""; parent = parentKey(parent) {
+				if entry, ok := entries[parent]; ok && entry.comment != "" {
+					doc.Comment = entry.comment
+					doc.CommentFrom = parent
+					break
+				}
+			}
+		}
+		doc.Readme = readmeMentions(docs.Readme, key)
+
+		result = append(result, doc)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result, nil
+}
+
+// collectValueEntries records every key of a values.yaml mapping with its comments
+// and any "@param" annotations found in them; keys whose values are not non-empty
+// maps are also appended to leaves
+func collectValueEntries(node *yaml.Node, prefix string, entries map[string]chartValueEntry, params map[string]string, leaves *[]string) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for itr := 0; itr+1 < len(node.Content); itr += 2 {
+		keyNode, valueNode := node.Content[itr], node.Content[itr+1]
+		key := keyNode.Value
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		comment := cleanValueComment(strings.Join([]string{keyNode.HeadComment, keyNode.LineComment, valueNode.LineComment}, "\n"), params)
+		entries[key] = chartValueEntry{comment: comment, node: valueNode}
+
+		if valueNode.Kind == yaml.MappingNode && len(valueNode.Content) > 0 {
+			collectValueEntries(valueNode, key, entries, params, leaves)
+		} else {
+			*leaves = append(*leaves, key)
+		}
+	}
+}
+
+// cleanValueComment strips comment markers and helm-docs/readme-generator
+// annotations from a values.yaml comment. "@param key description" lines are
+// moved into params, since they document the named key rather than this one.
+func cleanValueComment(raw string, params map[string]string) string {
+	var lines []string
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#"))
+		line = strings.TrimPrefix(line, "-- ")
+		switch {
+		case line == "", strings.HasPrefix(line, "@section"), strings.HasPrefix(line, "@skip"), strings.HasPrefix(line, "@extra"):
+			continue
+		case strings.HasPrefix(line, "@param "):
+			fields := strings.SplitN(strings.TrimPrefix(line, "@param "), " ", 2)
+			if len(fields) == 2 {
+				params[fields[0]] = strings.TrimSpace(fields[1])
+			}
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// flattenValues flattens nested maps into dotted keys; lists and scalars are leaves
+func flattenValues(values map[string]interface{}, prefix string, out map[string]interface{}) {
+	for key, value := range values {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			flattenValues(nested, key, out)
+			continue
+		}
+		out[key] = value
+	}
+}
+
+// parentKey returns the dotted parent of a key, empty for top-level keys
+func parentKey(key string) string {
+	if idx := strings.LastIndex(key, "."); idx >= 0 {
+		return key[:idx]
+	}
+	return ""
+}
+
+// nearestValueEntry returns the closest parent of key that the chart defines
+func nearestValueEntry(key string, entries map[string]chartValueEntry) (string, chartValueEntry) {
+	for parent := parentKey(key); parent != ""; parent = parentKey(parent) {
+		if entry, ok := entries[parent]; ok {
+			return parent, entry
+		}
+	}
+	return "", chartValueEntry{}
+}
+
+// readmeMentions returns README lines that mention the key in backticks, as in
+// the parameter tables most charts publish
+func readmeMentions(readme, key string) []string {
+	if readme == "" {
+		return nil
+	}
+	needle := "`" + key + "`"
+	var matches []string
+	for _, line := range strings.Split(readme, "\n") {
+		if strings.Contains(line, needle) {
+			matches = append(matches, strings.TrimSpace(line))
+			if len(matches) == maxReadmeMatches {
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// formatValue renders a value as single-line YAML where possible
+func formatValue(value interface{}) string {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// formatNode renders a values.yaml node as YAML without its comments
+func formatNode(node *yaml.Node) string {
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return node.Value
+	}
+	return formatValue(value)
+}
@@ -0,0 +1,176 @@
+package providers
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testChartValues = `## @section Authentication
+## @param auth.password Password for the admin user
+## @param auth.username Admin user name
+auth:
+  password: ""
+  username: admin
+podLabels: {} # Pod labels
+primary:
+  # -- Resource requests and limits
+  resources:
+    limits:
+      memory: 256Mi
+replicas: 1
+`
+
+const testChartReadme = "| Name | Description | Value |\n" +
+	"| `auth.password` | Password for the admin user | `\"\"` |\n" +
+	"| `replicas` | Number of replicas | `1` |\n"
+
+func TestDocumentChartValues(test *testing.T) {
+	docs := &ChartDocs{Name: "db", Values: []byte(testChartValues), Readme: testChartReadme}
+	userValues := map[string]interface{}{
+		"auth":      map[string]interface{}{"password": "secret", "pasword": "typo"},
+		"podLabels": map[string]interface{}{"team": "payments"},
+		"primary":   map[string]interface{}{"resources": map[string]interface{}{"limits": map[string]interface{}{"memory": "512Mi"}}},
+		"extra":     []interface{}{"a"},
+	}
+
+	valueDocs, err := DocumentChartValues(docs, userValues, false)
+	if err != nil {
+		test.Fatalf("DocumentChartValues() error = %v", err)
+	}
+
+	byKey := make(map[string]ChartValueDoc)
+	var keys []string
+	for _, doc := range valueDocs {
+		byKey[doc.Key] = doc
+		keys = append(keys, doc.Key)
+	}
+	expectedKeys := []string{"auth.password", "auth.pasword", "extra", "podLabels.team", "primary.resources.limits.memory"}
+	if !reflect.DeepEqual(keys, expectedKeys) {
+		test.Fatalf("keys = %v, want %v", keys, expectedKeys)
+	}
+
+	tests := []struct {
+		key         string
+		inChart     bool
+		value       string
+		defaultVal  string
+		comment     string
+		commentFrom string
+		readme      int
+	}{
+		{key: "auth.password", inChart: true, value: "secret", defaultVal: `""`, comment: "Password for the admin user", readme: 1},
+		{key: "auth.pasword", inChart: false, value: "typo"},
+		{key: "extra", inChart: false, value: "- a"},
+		{key: "podLabels.team", inChart: true, value: "payments", comment: "Pod labels", commentFrom: "podLabels"},
+		{key: "primary.resources.limits.memory", inChart: true, value: "512Mi", defaultVal: "256Mi", comment: "Resource requests and limits", commentFrom: "primary.resources"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.key, func(test *testing.T) {
+			doc := byKey[tt.key]
+			if !doc.Overridden || doc.Value != tt.value {
+				test.Errorf("value = %q (overridden %v), want %q", doc.Value, doc.Overridden, tt.value)
+			}
+			if doc.InChart != tt.inChart {
+				test.Errorf("InChart = %v, want %v", doc.InChart, tt.inChart)
+			}
+			if doc.Default != tt.defaultVal {
+				test.Errorf("Default = %q, want %q", doc.Default, tt.defaultVal)
+			}
+			if doc.Comment != tt.comment || doc.CommentFrom != tt.commentFrom {
+				test.Errorf("comment = %q from %q, want %q from %q", doc.Comment, doc.CommentFrom, tt.comment, tt.commentFrom)
+			}
+			if len(doc.Readme) != tt.readme {
+				test.Errorf("README matches = %v, want %d", doc.Readme, tt.readme)
+			}
+		})
+	}
+
+	// With all, the chart's own leaf keys are listed as well
+	allDocs, err := DocumentChartValues(docs, nil, true)
+	if err != nil {
+		test.Fatal(err)
+	}
+	var allKeys []string
+	for _, doc := range allDocs {
+		allKeys = append(allKeys, doc.Key)
+		if doc.Overridden {
+			test.Errorf("%s should not be overridden", doc.Key)
+		}
+	}
+	expectedAll := []string{"auth.password", "auth.username", "podLabels", "primary.resources.limits.memory", "replicas"}
+	if !reflect.DeepEqual(allKeys, expectedAll) {
+		test.Errorf("all keys = %v, want %v", allKeys, expectedAll)
+	}
+	for _, doc := range allDocs {
+		if doc.Key == "auth.username" && doc.Comment != "Admin user name" {
+			test.Errorf("line comment = %q, want %q", doc.Comment, "Admin user name")
+		}
+	}
+}
+
+func TestReadChartDocs(test *testing.T) {
+	dir := filepath.Join(test.TempDir(), "db")
+	if err := os.MkdirAll(filepath.Join(dir, "charts", "common"), 0755); err != nil {
+		test.Fatal(err)
+	}
+	files := map[string]string{
+		"values.yaml":               testChartValues,
+		"README.md":                 testChartReadme,
+		"charts/common/values.yaml": "subchart: true\n",
+		"charts/common/README.md":   "subchart readme\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			test.Fatal(err)
+		}
+	}
+
+	fromDir, err := readChartDirDocs(dir)
+	if err != nil {
+		test.Fatalf("readChartDirDocs() error = %v", err)
+	}
+
+	archive := filepath.Join(test.TempDir(), "db-1.0.0.tgz")
+	writeTestChartArchive(test, archive, "db", files)
+	fromArchive, err := readChartArchiveDocs(archive)
+	if err != nil {
+		test.Fatalf("readChartArchiveDocs() error = %v", err)
+	}
+
+	for name, docs := range map[string]*ChartDocs{"dir": fromDir, "archive": fromArchive} {
+		if docs.Name != "db" || string(docs.Values) != testChartValues || docs.Readme != testChartReadme {
+			test.Errorf("%s: unexpected docs %s / %q / %q", name, docs.Name, docs.Values, docs.Readme)
+		}
+	}
+}
+
+func writeTestChartArchive(test *testing.T, archivePath, chartName string, files map[string]string) {
+	file, err := os.Create(archivePath)
+	if err != nil {
+		test.Fatal(err)
+	}
+	defer file.Close()
+
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		header := &tar.Header{Name: chartName + "/" + name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			test.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			test.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		test.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		test.Fatal(err)
+	}
+}
@@ -0,0 +1,135 @@
+package providers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/config"
+	"helm.sh/helm/v4/pkg/action"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
+	"helm.sh/helm/v4/pkg/registry"
+)
+
+// chartVersionHashLength is how many hex characters of the content hash go into auto versions
+const chartVersionHashLength = 12
+
+// PushedChart is a local chart published to an OCI repository
+type PushedChart struct {
+	Service    string `json:"service"`
+	Repository string `json:"repository"`
+	Chart      string `json:"chart"`
+	Version    string `json:"version"`
+}
+
+// Ref returns the full OCI reference of the pushed chart
+func (pushed PushedChart) Ref() string {
+	return fmt.Sprintf("%s/%s:%s", pushed.Repository, pushed.Chart, pushed.Version)
+}
+
+// PushChart packages a service's local chart and pushes it to an OCI repository.
+// Without an explicit version the chart is versioned from its content (see
+// AutoChartVersion), so pushing an unchanged chart again republishes the same
+// version. Does not require a live Kubernetes cluster.
+func (helm *HelmProvider) PushChart(service *config.ServiceConfig, repository, version string, plainHTTP bool) (*PushedChart, error) {
+	if !service.IsLocalChart() {
+		return nil, fmt.Errorf("service %q is not a local Helm chart", service.Name)
+	}
+	if !config.IsOCIURL(repository) {
+		return nil, fmt.Errorf("chart repository '%s' must be an oci:// URL", repository)
+	}
+
+	chrt, err := loader.LoadDir(service.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chart: %w", err)
+	}
+	if version == "" {
+		version = AutoChartVersion(chrt)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "kraze-chart-push-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	pkg := action.NewPackage()
+	pkg.Version = version
+	pkg.Destination = tmpDir
+	archivePath, err := pkg.Run(service.Path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to package chart: %w", err)
+	}
+	archive, err := os.ReadFile(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read packaged chart: %w", err)
+	}
+
+	var clientOpts []registry.ClientOption
+	if plainHTTP {
+		clientOpts = append(clientOpts, registry.ClientOptPlainHTTP())
+	}
+	registryClient, err := helm.newRegistryClient(clientOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create registry client: %w", err)
+	}
+
+	pushed := &PushedChart{
+		Service:    service.Name,
+		Repository: strings.TrimSuffix(repository, "/"),
+		Chart:      chrt.Metadata.Name,
+		Version:    version,
+	}
+	ref := path.Join(strings.TrimPrefix(pushed.Repository, registry.OCIScheme+"://"), pushed.Chart) + ":" + version
+	if _, err := registryClient.Push(archive, ref); err != nil {
+		return nil, fmt.Errorf("failed to push chart to %s: %w", pushed.Ref(), err)
+	}
+	return pushed, nil
+}
+
+// AutoChartVersion returns the chart's version with a prerelease suffix derived
+// from the chart's files (e.g., 1.2.0-kraze-3f9a1c2b4d5e). It is stable for
+// unchanged charts and sorts below the release it is based on.
+func AutoChartVersion(chrt *chart.Chart) string {
+	return withPrerelease(chrt.Metadata.Version, "kraze-"+chartContentHash(chrt))
+}
+
+// withPrerelease appends an identifier to a semver version's prerelease
+func withPrerelease(version, identifier string) string {
+	base, build, hasBuild := strings.Cut(version, "+")
+	if strings.Contains(base, "-") {
+		base += "." + identifier
+	} else {
+		base += "-" + identifier
+	}
+	if hasBuild {
+		return base + "+" + build
+	}
+	return base
+}
+
+// chartContentHash hashes the names and contents of a chart's files, which
+// already exclude anything matched by .helmignore
+func chartContentHash(chrt *chart.Chart) string {
+	files := make(map[string][]byte, len(chrt.Raw))
+	for _, file := range chrt.Raw {
+		files[file.Name] = file.Data
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	hasher := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(hasher, "%s\x00%d\x00", name, len(files[name]))
+		hasher.Write(files[name])
+	}
+	return hex.EncodeToString(hasher.Sum(nil))[:chartVersionHashLength]
+}
@@ -0,0 +1,125 @@
+package providers
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/hjames9/kraze/internal/config"
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
+)
+
+// writeTestChart writes a minimal chart to dir and returns its path
+func writeTestChart(test *testing.T, dir, version, configMap string) string {
+	test.Helper()
+	chartDir := filepath.Join(dir, "api")
+	files := map[string]string{
+		"Chart.yaml":               "apiVersion: v2\nname: api\nversion: " + version + "\n",
+		"values.yaml":              "replicas: 1\n",
+		"templates/configmap.yaml": configMap,
+		".helmignore":              "*.bak\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(chartDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			test.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			test.Fatal(err)
+		}
+	}
+	return chartDir
+}
+
+func TestWithPrerelease(test *testing.T) {
+	tests := []struct {
+		version  string
+		expected string
+	}{
+		{version: "1.2.0", expected: "1.2.0-kraze-abc"},
+		{version: "1.2.0-beta.1", expected: "1.2.0-beta.1.kraze-abc"},
+		{version: "1.2.0+build.7", expected: "1.2.0-kraze-abc+build.7"},
+		{version: "0.1.0-rc1+meta", expected: "0.1.0-rc1.kraze-abc+meta"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.version, func(test *testing.T) {
+			got := withPrerelease(tt.version, "kraze-abc")
+			if got != tt.expected {
+				test.Errorf("withPrerelease(%q) = %q, want %q", tt.version, got, tt.expected)
+			}
+			if _, err := semver.StrictNewVersion(got); err != nil {
+				test.Errorf("withPrerelease(%q) = %q is not valid semver: %v", tt.version, got, err)
+			}
+		})
+	}
+}
+
+func TestAutoChartVersion(test *testing.T) {
+	configMap := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: api\n"
+	chartDir := writeTestChart(test, test.TempDir(), "1.2.0", configMap)
+
+	chrt, err := loader.LoadDir(chartDir)
+	if err != nil {
+		test.Fatal(err)
+	}
+	version := AutoChartVersion(chrt)
+	if !regexp.MustCompile(`^1\.2\.0-kraze-[0-9a-f]{12}$`).MatchString(version) {
+		test.Fatalf("AutoChartVersion() = %q, want 1.2.0-kraze-<hash>", version)
+	}
+
+	// Files matched by .helmignore don't change the version
+	if err := os.WriteFile(filepath.Join(chartDir, "notes.bak"), []byte("scratch"), 0644); err != nil {
+		test.Fatal(err)
+	}
+	ignored, err := loader.LoadDir(chartDir)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if got := AutoChartVersion(ignored); got != version {
+		test.Errorf("ignored file changed the version: %q, want %q", got, version)
+	}
+
+	// Any template change does
+	if err := os.WriteFile(filepath.Join(chartDir, "templates", "configmap.yaml"), []byte(configMap+"data:\n  key: value\n"), 0644); err != nil {
+		test.Fatal(err)
+	}
+	changed, err := loader.LoadDir(chartDir)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if got := AutoChartVersion(changed); got == version {
+		test.Errorf("template change kept version %q", got)
+	}
+}
+
+func TestPushChartValidation(test *testing.T) {
+	helm := NewHelmProviderForPacking(false, nil)
+	chartDir := writeTestChart(test, test.TempDir(), "1.0.0", "")
+
+	tests := []struct {
+		name       string
+		service    *config.ServiceConfig
+		repository string
+	}{
+		{name: "remote chart", service: &config.ServiceConfig{Name: "redis", Type: "helm", Repo: "oci://registry-1.docker.io/bitnamicharts", Chart: "redis"}, repository: "oci://localhost:5000/charts"},
+		{name: "http repository", service: &config.ServiceConfig{Name: "api", Type: "helm", Path: chartDir}, repository: "https://charts.example.com"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if _, err := helm.PushChart(tt.service, tt.repository, "", false); err == nil {
+				test.Error("PushChart() expected an error")
+			}
+		})
+	}
+}
+
+func TestPushedChartRef(test *testing.T) {
+	pushed := PushedChart{Repository: "oci://localhost:5000/charts", Chart: "api", Version: "1.0.0-kraze-abc"}
+	if got := pushed.Ref(); got != "oci://localhost:5000/charts/api:1.0.0-kraze-abc" {
+		test.Errorf("Ref() = %q", got)
+	}
+}
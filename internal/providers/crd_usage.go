@@ -0,0 +1,121 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/config"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// Annotations Helm sets on the resources of a release
+const (
+	helmReleaseNameAnnotation      = "meta.helm.sh/release-name"
+	helmReleaseNamespaceAnnotation = "meta.helm.sh/release-namespace"
+)
+
+// CRDsInUseError is returned when uninstalling a service would delete CRDs
+// whose custom resources belong to other services or namespaces, taking
+// those resources with them
+type CRDsInUseError struct {
+	Service   string
+	Resources []string // Custom resources in use, as Kind/namespace/name (owner)
+}
+
+func (err *CRDsInUseError) Error() string {
+	return fmt.Sprintf("refusing to uninstall '%s': its CRDs are still used by %d custom resource(s) of other services or namespaces, "+
+		"which deleting the CRDs would delete (use --force to uninstall anyway):\n  %s",
+		err.Service, len(err.Resources), strings.Join(err.Resources, "\n  "))
+}
+
+// checkCRDsInUse returns a CRDsInUseError if deleting the named CRDs would
+// delete custom resources that don't belong to the service
+func checkCRDsInUse(ctx context.Context, dynamicClient dynamic.Interface, service *config.ServiceConfig, crdNames []string) error {
+	crdGVR := apiextv1.SchemeGroupVersion.WithResource("customresourcedefinitions")
+
+	var inUse []string
+	for _, crdName := range crdNames {
+		crd, err := dynamicClient.Resource(crdGVR).Get(ctx, crdName, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get CRD %s: %w", crdName, err)
+		}
+		gvr, ok := crdStorageResource(crd)
+		if !ok {
+			continue
+		}
+
+		list, err := dynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to list %s: %w", crdName, err)
+		}
+		for _, item := range list.Items {
+			if owner, foreign := customResourceOwner(&item, service); foreign {
+				inUse = append(inUse, fmt.Sprintf("%s (%s)", NewResourceRef(&item), owner))
+			}
+		}
+	}
+
+	if len(inUse) == 0 {
+		return nil
+	}
+	sort.Strings(inUse)
+	return &CRDsInUseError{Service: service.Name, Resources: inUse}
+}
+
+// crdStorageResource returns the resource a CRD's objects are listed with,
+// at its storage version
+func crdStorageResource(crd *unstructured.Unstructured) (schema.GroupVersionResource, bool) {
+	group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+	plural, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "plural")
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+
+	version := ""
+	for _, entry := range versions {
+		fields, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := fields["name"].(string)
+		if storage, _ := fields["storage"].(bool); storage {
+			version = name
+			break
+		}
+		if served, _ := fields["served"].(bool); served && version == "" {
+			version = name
+		}
+	}
+	if group == "" || plural == "" || version == "" {
+		return schema.GroupVersionResource{}, false
+	}
+	return schema.GroupVersionResource{Group: group, Version: version, Resource: plural}, true
+}
+
+// customResourceOwner describes who a custom resource belongs to, and whether
+// that's someone other than the service: another kraze service or Helm
+// release, or a namespace other than the service's
+func customResourceOwner(obj *unstructured.Unstructured, service *config.ServiceConfig) (string, bool) {
+	if owner := obj.GetLabels()[serviceLabel]; owner != "" {
+		return fmt.Sprintf("service '%s'", owner), owner != service.Name
+	}
+
+	annotations := obj.GetAnnotations()
+	if release := annotations[helmReleaseNameAnnotation]; release != "" {
+		ownRelease := release == service.Name && annotations[helmReleaseNamespaceAnnotation] == service.GetNamespace()
+		return fmt.Sprintf("release '%s'", release), !ownRelease
+	}
+
+	if obj.GetNamespace() == "" {
+		return "cluster-scoped", true
+	}
+	return fmt.Sprintf("namespace '%s'", obj.GetNamespace()), obj.GetNamespace() != service.GetNamespace()
+}
@@ -0,0 +1,111 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestCheckCRDsInUse(test *testing.T) {
+	crd := newLabeledObject("apiextensions.k8s.io/v1", kindCRD, "", "certificates.cert-manager.io", nil)
+	unstructured.SetNestedField(crd.Object, "cert-manager.io", "spec", "group")
+	unstructured.SetNestedField(crd.Object, "certificates", "spec", "names", "plural")
+	unstructured.SetNestedSlice(crd.Object, []interface{}{
+		map[string]interface{}{"name": "v1alpha2", "served": true, "storage": false},
+		map[string]interface{}{"name": "v1", "served": true, "storage": true},
+	}, "spec", "versions")
+
+	own := newLabeledObject("cert-manager.io/v1", "Certificate", "cert-manager", "webhook", nil)
+	otherService := newLabeledObject("cert-manager.io/v1", "Certificate", "app", "api-tls", map[string]string{serviceLabel: "api"})
+	otherNamespace := newLabeledObject("cert-manager.io/v1", "Certificate", "team-b", "ingress", nil)
+
+	listKinds := map[schema.GroupVersionResource]string{
+		{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}: "CustomResourceDefinitionList",
+		{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}:                   "CertificateList",
+	}
+	service := &config.ServiceConfig{Name: "cert-manager", Namespace: "cert-manager"}
+
+	tests := []struct {
+		name     string
+		objects  []runtime.Object
+		crds     []string
+		expected []string
+	}{
+		{
+			name:     "custom resources of other services and namespaces",
+			objects:  []runtime.Object{crd, own, otherService, otherNamespace},
+			crds:     []string{"certificates.cert-manager.io"},
+			expected: []string{"Certificate/app/api-tls (service 'api')", "Certificate/team-b/ingress (namespace 'team-b')"},
+		},
+		{
+			name:    "only the service's own",
+			objects: []runtime.Object{crd, own},
+			crds:    []string{"certificates.cert-manager.io"},
+		},
+		{
+			name:    "CRD already gone",
+			objects: []runtime.Object{otherService},
+			crds:    []string{"certificates.cert-manager.io"},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, tt.objects...)
+			err := checkCRDsInUse(context.Background(), client, service, tt.crds)
+
+			var inUse *CRDsInUseError
+			if len(tt.expected) == 0 {
+				if err != nil {
+					test.Fatalf("checkCRDsInUse() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.As(err, &inUse) {
+				test.Fatalf("checkCRDsInUse() error = %v, want CRDsInUseError", err)
+			}
+			if !reflect.DeepEqual(inUse.Resources, tt.expected) {
+				test.Errorf("checkCRDsInUse() resources = %v, want %v", inUse.Resources, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCustomResourceOwner(test *testing.T) {
+	service := &config.ServiceConfig{Name: "operator", Namespace: "operators"}
+	helmOwned := func(release, namespace string) *unstructured.Unstructured {
+		obj := newLabeledObject("example.com/v1", "Widget", "app", "w", nil)
+		obj.SetAnnotations(map[string]string{helmReleaseNameAnnotation: release, helmReleaseNamespaceAnnotation: namespace})
+		return obj
+	}
+
+	tests := []struct {
+		name     string
+		obj      *unstructured.Unstructured
+		owner    string
+		expected bool
+	}{
+		{name: "labeled for the service", obj: newLabeledObject("example.com/v1", "Widget", "app", "w", map[string]string{serviceLabel: "operator"}), owner: "service 'operator'"},
+		{name: "labeled for another service", obj: newLabeledObject("example.com/v1", "Widget", "operators", "w", map[string]string{serviceLabel: "api"}), owner: "service 'api'", expected: true},
+		{name: "in the service's release", obj: helmOwned("operator", "operators"), owner: "release 'operator'"},
+		{name: "in another release", obj: helmOwned("api", "app"), owner: "release 'api'", expected: true},
+		{name: "in the service's namespace", obj: newLabeledObject("example.com/v1", "Widget", "operators", "w", nil), owner: "namespace 'operators'"},
+		{name: "cluster-scoped", obj: newLabeledObject("example.com/v1", "Widget", "", "w", nil), owner: "cluster-scoped", expected: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			owner, foreign := customResourceOwner(tt.obj, service)
+			if owner != tt.owner || foreign != tt.expected {
+				test.Errorf("customResourceOwner() = %q, %v, want %q, %v", owner, foreign, tt.owner, tt.expected)
+			}
+		})
+	}
+}
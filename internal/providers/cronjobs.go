@@ -0,0 +1,114 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hjames9/kraze/internal/config"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+)
+
+// maxJobNameLength keeps manual Job names usable as the job-name label value
+const maxJobNameLength = 63
+
+// ServiceCronJobs returns the CronJobs of a service, sorted by name
+func ServiceCronJobs(ctx context.Context, clientset kubernetes.Interface, service *config.ServiceConfig) ([]batchv1.CronJob, error) {
+	listOpts := metav1.ListOptions{LabelSelector: serviceWorkloadSelector(service)}
+	cronJobs, err := clientset.BatchV1().CronJobs(service.GetNamespace()).List(ctx, listOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cronjobs: %w", err)
+	}
+	sort.Slice(cronJobs.Items, func(left, right int) bool {
+		return cronJobs.Items[left].Name < cronJobs.Items[right].Name
+	})
+	return cronJobs.Items, nil
+}
+
+// suspendCronJob sets spec.suspend on a rendered CronJob, so it's created
+// suspended and no schedule fires before 'kraze run-cron' triggers it. Reports
+// whether the object changed.
+func suspendCronJob(obj *unstructured.Unstructured) bool {
+	if obj.GetKind() != "CronJob" || obj.GroupVersionKind().Group != "batch" {
+		return false
+	}
+	if suspended, _, _ := unstructured.NestedBool(obj.Object, "spec", "suspend"); suspended {
+		return false
+	}
+	return unstructured.SetNestedField(obj.Object, true, "spec", "suspend") == nil
+}
+
+// SuspendedCronJobs returns the names of a service's suspended CronJobs
+func SuspendedCronJobs(ctx context.Context, clientset kubernetes.Interface, service *config.ServiceConfig) ([]string, error) {
+	cronJobs, err := ServiceCronJobs(ctx, clientset, service)
+	if err != nil {
+		return nil, err
+	}
+	var suspended []string
+	for _, cronJob := range cronJobs {
+		if cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend {
+			suspended = append(suspended, cronJob.Name)
+		}
+	}
+	return suspended, nil
+}
+
+// RunCronJob creates a Job from a CronJob's job template, like
+// 'kubectl create job --from=cronjob/<name>'. Works on suspended CronJobs.
+func RunCronJob(ctx context.Context, clientset kubernetes.Interface, service *config.ServiceConfig, name string) (*batchv1.Job, error) {
+	cronJobs, err := ServiceCronJobs(ctx, clientset, service)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, cronJob := range cronJobs {
+		if cronJob.Name == name {
+			job := manualJob(&cronJob, time.Now())
+			created, err := clientset.BatchV1().Jobs(cronJob.Namespace).Create(ctx, job, metav1.CreateOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create job from cronjob %s: %w", name, err)
+			}
+			return created, nil
+		}
+		names = append(names, cronJob.Name)
+	}
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("service '%s' has no cronjobs in namespace '%s'", service.Name, service.GetNamespace())
+	}
+	return nil, fmt.Errorf("cronjob '%s' not found for service '%s' (available: %v)", name, service.Name, names)
+}
+
+// manualJob builds the Job a manual run of a CronJob creates, owned by the
+// CronJob so it is cleaned up with it
+func manualJob(cronJob *batchv1.CronJob, now time.Time) *batchv1.Job {
+	suffix := "-manual-" + strconv.FormatInt(now.Unix(), 10)
+	base := cronJob.Name
+	if len(base)+len(suffix) > maxJobNameLength {
+		base = base[:maxJobNameLength-len(suffix)]
+	}
+
+	annotations := map[string]string{"cronjob.kubernetes.io/instantiate": "manual"}
+	for key, value := range cronJob.Spec.JobTemplate.Annotations {
+		annotations[key] = value
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        base + suffix,
+			Namespace:   cronJob.Namespace,
+			Labels:      cronJob.Spec.JobTemplate.Labels,
+			Annotations: annotations,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(cronJob, batchv1.SchemeGroupVersion.WithKind("CronJob")),
+			},
+		},
+		Spec: *cronJob.Spec.JobTemplate.Spec.DeepCopy(),
+	}
+}
@@ -0,0 +1,133 @@
+package providers
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hjames9/kraze/internal/config"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testCronJob(name, service string, suspend bool) *batchv1.CronJob {
+	return &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "jobs",
+			Labels:    map[string]string{managedByLabel: "kraze", serviceLabel: service},
+			UID:       types.UID("uid-" + name),
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule: "*/5 * * * *",
+			Suspend:  &suspend,
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": name}},
+			},
+		},
+	}
+}
+
+func TestSuspendCronJob(test *testing.T) {
+	cronJob := func(apiVersion string, spec map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       "CronJob",
+			"metadata":   map[string]interface{}{"name": "cleanup"},
+			"spec":       spec,
+		}}
+	}
+
+	tests := []struct {
+		name            string
+		obj             *unstructured.Unstructured
+		expectedChanged bool
+		expectedSuspend bool
+	}{
+		{name: "scheduled cronjob", obj: cronJob("batch/v1", map[string]interface{}{"schedule": "*/5 * * * *"}), expectedChanged: true, expectedSuspend: true},
+		{name: "already suspended", obj: cronJob("batch/v1", map[string]interface{}{"suspend": true}), expectedSuspend: true},
+		{name: "suspend false", obj: cronJob("batch/v1", map[string]interface{}{"suspend": false}), expectedChanged: true, expectedSuspend: true},
+		{name: "other group", obj: cronJob("example.com/v1", map[string]interface{}{})},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if changed := suspendCronJob(tt.obj); changed != tt.expectedChanged {
+				test.Errorf("suspendCronJob() = %v, want %v", changed, tt.expectedChanged)
+			}
+			if suspended, _, _ := unstructured.NestedBool(tt.obj.Object, "spec", "suspend"); suspended != tt.expectedSuspend {
+				test.Errorf("spec.suspend = %v, want %v", suspended, tt.expectedSuspend)
+			}
+		})
+	}
+}
+
+func TestSuspendedCronJobs(test *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		testCronJob("cleanup", "reports", false),
+		testCronJob("export", "reports", true),
+		testCronJob("digest", "mailer", true),
+	)
+	service := &config.ServiceConfig{Name: "reports", Type: "manifests", Namespace: "jobs"}
+
+	suspended, err := SuspendedCronJobs(context.Background(), clientset, service)
+	if err != nil {
+		test.Fatalf("SuspendedCronJobs() error = %v", err)
+	}
+	if !reflect.DeepEqual(suspended, []string{"export"}) {
+		test.Errorf("SuspendedCronJobs() = %v, want [export]", suspended)
+	}
+}
+
+func TestRunCronJob(test *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset(testCronJob("export", "reports", true))
+	service := &config.ServiceConfig{Name: "reports", Type: "manifests", Namespace: "jobs"}
+
+	job, err := RunCronJob(ctx, clientset, service, "export")
+	if err != nil {
+		test.Fatalf("RunCronJob() error = %v", err)
+	}
+	if !strings.HasPrefix(job.Name, "export-manual-") {
+		test.Errorf("job name = %q, want export-manual-<timestamp>", job.Name)
+	}
+	if job.Annotations["cronjob.kubernetes.io/instantiate"] != "manual" {
+		test.Errorf("job annotations = %v, want the manual instantiate annotation", job.Annotations)
+	}
+	if len(job.OwnerReferences) != 1 || job.OwnerReferences[0].Kind != "CronJob" || job.OwnerReferences[0].Name != "export" {
+		test.Errorf("job owner references = %+v, want the export CronJob", job.OwnerReferences)
+	}
+
+	if _, err := RunCronJob(ctx, clientset, service, "missing"); err == nil || !strings.Contains(err.Error(), "export") {
+		test.Errorf("RunCronJob() for a missing cronjob error = %v, want one listing the available cronjobs", err)
+	}
+}
+
+func TestManualJobName(test *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tests := []struct {
+		name     string
+		cronJob  string
+		expected string
+	}{
+		{name: "short", cronJob: "export", expected: "export-manual-1700000000"},
+		{name: "truncated", cronJob: strings.Repeat("a", 52), expected: strings.Repeat("a", 45) + "-manual-1700000000"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			job := manualJob(testCronJob(tt.cronJob, "reports", false), now)
+			if job.Name != tt.expected {
+				test.Errorf("manualJob() name = %q, want %q", job.Name, tt.expected)
+			}
+			if len(job.Name) > maxJobNameLength {
+				test.Errorf("manualJob() name is %d characters", len(job.Name))
+			}
+		})
+	}
+}
@@ -0,0 +1,338 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/hjames9/kraze/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+const (
+	// debugLabel marks pod copies created by 'kraze debug'
+	debugLabel = "kraze.debug"
+
+	// debugContainerPrefix prefixes the names of injected debug containers
+	debugContainerPrefix = "kraze-debug-"
+
+	// debugStartTimeout bounds how long to wait for the debug container to start
+	debugStartTimeout = 2 * time.Minute
+)
+
+// DebugOptions configures a debug container for a service
+type DebugOptions struct {
+	Image   string        // Toolbox image for the debug container
+	Pod     string        // Pod to debug (empty = first running pod of the service)
+	Target  string        // Container to debug (empty = first container of the pod)
+	Copy    bool          // Debug a copy of the pod instead of injecting an ephemeral container
+	Command []string      // Ephemeral: debug container command. Copy: overrides the target container's command
+	TTL     time.Duration // How long the debug container (or pod copy) may live, 0 = unlimited
+}
+
+// DebugTarget identifies a started debug container
+type DebugTarget struct {
+	Namespace string
+	Pod       string
+	Container string
+	Copy      bool // Pod is a copy created for debugging and should be deleted afterwards
+}
+
+// DebugStreams are the terminal streams attached to a debug container
+type DebugStreams struct {
+	Stdin     io.Reader
+	Stdout    io.Writer
+	Stderr    io.Writer
+	TTY       bool
+	SizeQueue remotecommand.TerminalSizeQueue
+}
+
+// StartDebugContainer injects an ephemeral debug container into a pod of the service,
+// or creates a copy of the pod with a debug container added, and waits until the
+// debug container is running
+func StartDebugContainer(ctx context.Context, clientset kubernetes.Interface, service *config.ServiceConfig, opts DebugOptions) (*DebugTarget, error) {
+	pods, err := ListServicePods(ctx, clientset, service)
+	if err != nil {
+		return nil, err
+	}
+
+	pod, err := pickDebugPod(pods, opts.Pod)
+	if err != nil {
+		return nil, fmt.Errorf("service '%s': %w", service.Name, err)
+	}
+
+	targetName, err := debugTargetContainer(pod, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	containerName := debugContainerPrefix + utilrand.String(5)
+	namespace := pod.Namespace
+
+	var podName string
+	if opts.Copy {
+		podCopy := buildDebugPodCopy(pod, service.Name, targetName, containerName, opts)
+		created, err := clientset.CoreV1().Pods(namespace).Create(ctx, podCopy, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create debug copy of pod %s: %w", pod.Name, err)
+		}
+		podName = created.Name
+	} else {
+		updated := pod.DeepCopy()
+		updated.Spec.EphemeralContainers = append(updated.Spec.EphemeralContainers,
+			buildEphemeralContainer(targetName, containerName, opts))
+		if _, err := clientset.CoreV1().Pods(namespace).UpdateEphemeralContainers(ctx, pod.Name, updated, metav1.UpdateOptions{}); err != nil {
+			return nil, fmt.Errorf("failed to add ephemeral container to pod %s: %w", pod.Name, err)
+		}
+		podName = pod.Name
+	}
+
+	target := &DebugTarget{Namespace: namespace, Pod: podName, Container: containerName, Copy: opts.Copy}
+	if err := waitForDebugContainer(ctx, clientset, target); err != nil {
+		return target, err
+	}
+	return target, nil
+}
+
+// AttachDebugContainer attaches the given streams to a running debug container and
+// returns when the container's process exits or ctx is cancelled
+func AttachDebugContainer(ctx context.Context, restConfig *rest.Config, clientset kubernetes.Interface, target *DebugTarget, streams DebugStreams) error {
+	req := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(target.Namespace).
+		Name(target.Pod).
+		SubResource("attach").
+		VersionedParams(&corev1.PodAttachOptions{
+			Container: target.Container,
+			Stdin:     streams.Stdin != nil,
+			Stdout:    streams.Stdout != nil,
+			Stderr:    streams.Stderr != nil && !streams.TTY,
+			TTY:       streams.TTY,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(restConfig, "POST", req.URL())
+	if err != nil {
+		return fmt.Errorf("failed to create attach executor: %w", err)
+	}
+
+	streamOpts := remotecommand.StreamOptions{
+		Stdin:             streams.Stdin,
+		Stdout:            streams.Stdout,
+		Tty:               streams.TTY,
+		TerminalSizeQueue: streams.SizeQueue,
+	}
+	if !streams.TTY {
+		streamOpts.Stderr = streams.Stderr
+	}
+	if err := executor.StreamWithContext(ctx, streamOpts); err != nil {
+		return fmt.Errorf("failed to attach to %s/%s: %w", target.Pod, target.Container, err)
+	}
+	return nil
+}
+
+// DeleteDebugCopy removes a pod copy created for debugging. No-op for ephemeral containers.
+func DeleteDebugCopy(ctx context.Context, clientset kubernetes.Interface, target *DebugTarget) error {
+	if target == nil || !target.Copy {
+		return nil
+	}
+	gracePeriod := int64(0)
+	err := clientset.CoreV1().Pods(target.Namespace).Delete(ctx, target.Pod, metav1.DeleteOptions{GracePeriodSeconds: &gracePeriod})
+	if err != nil {
+		return fmt.Errorf("failed to delete debug pod %s: %w", target.Pod, err)
+	}
+	return nil
+}
+
+// pickDebugPod returns the named pod, or the first running pod when name is empty
+func pickDebugPod(pods []corev1.Pod, name string) (*corev1.Pod, error) {
+	if name != "" {
+		for itr := range pods {
+			if pods[itr].Name == name {
+				return &pods[itr], nil
+			}
+		}
+		return nil, fmt.Errorf("pod '%s' not found", name)
+	}
+
+	for itr := range pods {
+		if pods[itr].Status.Phase == corev1.PodRunning {
+			return &pods[itr], nil
+		}
+	}
+	if len(pods) == 0 {
+		return nil, fmt.Errorf("no pods found")
+	}
+	return nil, fmt.Errorf("no running pods found (%d pod(s) not running)", len(pods))
+}
+
+// debugTargetContainer validates the requested target container, defaulting to the pod's first container
+func debugTargetContainer(pod *corev1.Pod, name string) (string, error) {
+	if len(pod.Spec.Containers) == 0 {
+		return "", fmt.Errorf("pod %s has no containers", pod.Name)
+	}
+	if name == "" {
+		return pod.Spec.Containers[0].Name, nil
+	}
+	for _, container := range pod.Spec.Containers {
+		if container.Name == name {
+			return name, nil
+		}
+	}
+	names := make([]string, 0, len(pod.Spec.Containers))
+	for _, container := range pod.Spec.Containers {
+		names = append(names, container.Name)
+	}
+	return "", fmt.Errorf("container '%s' not found in pod %s (containers: %s)", name, pod.Name, strings.Join(names, ", "))
+}
+
+// debugCommand wraps the debug command so its process is killed once ttl elapses.
+// Ephemeral containers cannot be removed from a pod, so ending the process is the
+// only way to bound their lifetime. Relies on sh in the toolbox image.
+func debugCommand(command []string, ttl time.Duration) []string {
+	if len(command) == 0 {
+		command = []string{"sh"}
+	}
+	if ttl <= 0 {
+		return command
+	}
+	script := fmt.Sprintf(`(sleep %d; kill -KILL $$) >/dev/null 2>&1 & exec "$@"`, int64(ttl.Seconds()))
+	return append([]string{"sh", "-c", script, "kraze-debug"}, command...)
+}
+
+// buildEphemeralContainer returns an interactive debug container sharing the target container's process namespace
+func buildEphemeralContainer(targetName, containerName string, opts DebugOptions) corev1.EphemeralContainer {
+	return corev1.EphemeralContainer{
+		TargetContainerName: targetName,
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:                     containerName,
+			Image:                    opts.Image,
+			Command:                  debugCommand(opts.Command, opts.TTL),
+			Stdin:                    true,
+			TTY:                      true,
+			ImagePullPolicy:          corev1.PullIfNotPresent,
+			TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		},
+	}
+}
+
+// buildDebugPodCopy returns a standalone copy of pod with a debug container added,
+// the process namespace shared, probes removed and the target's command optionally
+// overridden (e.g. to keep a crashing container alive)
+func buildDebugPodCopy(pod *corev1.Pod, serviceName, targetName, containerName string, opts DebugOptions) *corev1.Pod {
+	spec := pod.Spec.DeepCopy()
+	spec.NodeName = ""
+	spec.EphemeralContainers = nil
+	spec.RestartPolicy = corev1.RestartPolicyNever
+	shareProcessNamespace := true
+	spec.ShareProcessNamespace = &shareProcessNamespace
+	if opts.TTL > 0 {
+		deadline := int64(opts.TTL.Seconds())
+		spec.ActiveDeadlineSeconds = &deadline
+	}
+
+	for itr := range spec.Containers {
+		container := &spec.Containers[itr]
+		container.LivenessProbe = nil
+		container.ReadinessProbe = nil
+		container.StartupProbe = nil
+		if container.Name == targetName && len(opts.Command) > 0 {
+			container.Command = opts.Command
+			container.Args = nil
+		}
+	}
+
+	spec.Containers = append(spec.Containers, corev1.Container{
+		Name:                     containerName,
+		Image:                    opts.Image,
+		Command:                  []string{"sh"},
+		Stdin:                    true,
+		TTY:                      true,
+		ImagePullPolicy:          corev1.PullIfNotPresent,
+		TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+	})
+
+	// Keep only the tracking labels so the copy is not picked up by the
+	// original workload's Service or controller selectors
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      truncateName(pod.Name+"-debug-"+utilrand.String(5), 63),
+			Namespace: pod.Namespace,
+			Labels: map[string]string{
+				managedByLabel: "kraze",
+				serviceLabel:   serviceName,
+				debugLabel:     "true",
+			},
+		},
+		Spec: *spec,
+	}
+}
+
+// truncateName shortens a generated name to max characters, keeping its random suffix
+func truncateName(name string, max int) string {
+	if len(name) <= max {
+		return name
+	}
+	return name[len(name)-max:]
+}
+
+// waitForDebugContainer polls the pod until the debug container is running,
+// failing early when it terminates or cannot pull its image
+func waitForDebugContainer(ctx context.Context, clientset kubernetes.Interface, target *DebugTarget) error {
+	ctx, cancel := context.WithTimeout(ctx, debugStartTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		pod, err := clientset.CoreV1().Pods(target.Namespace).Get(ctx, target.Pod, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get pod %s: %w", target.Pod, err)
+		}
+
+		running, err := debugContainerRunning(pod, target.Container)
+		if err != nil || running {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for debug container %s in pod %s to start", target.Container, target.Pod)
+		case <-ticker.C:
+		}
+	}
+}
+
+// debugContainerRunning reports whether the named container is running in the pod,
+// returning an error once it can no longer start
+func debugContainerRunning(pod *corev1.Pod, containerName string) (bool, error) {
+	statuses := append([]corev1.ContainerStatus{}, pod.Status.ContainerStatuses...)
+	statuses = append(statuses, pod.Status.EphemeralContainerStatuses...)
+
+	for _, status := range statuses {
+		if status.Name != containerName {
+			continue
+		}
+		switch {
+		case status.State.Running != nil:
+			return true, nil
+		case status.State.Terminated != nil:
+			return false, fmt.Errorf("debug container %s exited (%s)", containerName, status.State.Terminated.Reason)
+		case status.State.Waiting != nil && (isImagePullFailure(status.State.Waiting.Reason) || status.State.Waiting.Reason == "InvalidImageName"):
+			return false, fmt.Errorf("debug container %s cannot start: %s: %s", containerName, status.State.Waiting.Reason, status.State.Waiting.Message)
+		}
+	}
+
+	if pod.Status.Phase == corev1.PodFailed || pod.Status.Phase == corev1.PodSucceeded {
+		return false, fmt.Errorf("pod %s is %s", pod.Name, pod.Status.Phase)
+	}
+	return false, nil
+}
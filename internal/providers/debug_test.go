@@ -0,0 +1,257 @@
+package providers
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hjames9/kraze/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func debugTestPod(name string, phase corev1.PodPhase) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "app",
+			Labels:    map[string]string{"app.kubernetes.io/instance": "api", "pod-template-hash": "abc"},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "worker-1",
+			Containers: []corev1.Container{
+				{
+					Name:           "app",
+					Image:          "gcr.io/distroless/static",
+					Command:        []string{"/server"},
+					Args:           []string{"--port", "8080"},
+					LivenessProbe:  &corev1.Probe{},
+					ReadinessProbe: &corev1.Probe{},
+				},
+				{Name: "sidecar", Image: "envoy"},
+			},
+		},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+}
+
+func TestPickDebugPod(test *testing.T) {
+	pods := []corev1.Pod{
+		debugTestPod("api-1", corev1.PodPending),
+		debugTestPod("api-2", corev1.PodRunning),
+	}
+
+	tests := []struct {
+		name        string
+		pods        []corev1.Pod
+		podName     string
+		expected    string
+		expectError bool
+	}{
+		{name: "first running pod", pods: pods, expected: "api-2"},
+		{name: "named pod regardless of phase", pods: pods, podName: "api-1", expected: "api-1"},
+		{name: "named pod missing", pods: pods, podName: "api-3", expectError: true},
+		{name: "no running pods", pods: pods[:1], expectError: true},
+		{name: "no pods", expectError: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			pod, err := pickDebugPod(tt.pods, tt.podName)
+			if (err != nil) != tt.expectError {
+				test.Fatalf("pickDebugPod() error = %v, expectError %v", err, tt.expectError)
+			}
+			if !tt.expectError && pod.Name != tt.expected {
+				test.Errorf("pickDebugPod() = %s, want %s", pod.Name, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDebugTargetContainer(test *testing.T) {
+	pod := debugTestPod("api-1", corev1.PodRunning)
+
+	tests := []struct {
+		name        string
+		target      string
+		expected    string
+		expectError bool
+	}{
+		{name: "defaults to first container", expected: "app"},
+		{name: "named container", target: "sidecar", expected: "sidecar"},
+		{name: "unknown container", target: "db", expectError: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			got, err := debugTargetContainer(&pod, tt.target)
+			if (err != nil) != tt.expectError {
+				test.Fatalf("debugTargetContainer() error = %v, expectError %v", err, tt.expectError)
+			}
+			if got != tt.expected {
+				test.Errorf("debugTargetContainer() = %s, want %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDebugCommand(test *testing.T) {
+	tests := []struct {
+		name     string
+		command  []string
+		ttl      time.Duration
+		expected []string
+	}{
+		{name: "shell without ttl", expected: []string{"sh"}},
+		{name: "command without ttl", command: []string{"curl", "localhost:8080"}, expected: []string{"curl", "localhost:8080"}},
+		{
+			name:     "shell with ttl",
+			ttl:      time.Hour,
+			expected: []string{"sh", "-c", `(sleep 3600; kill -KILL $$) >/dev/null 2>&1 & exec "$@"`, "kraze-debug", "sh"},
+		},
+		{
+			name:     "command with ttl",
+			command:  []string{"tcpdump", "-i", "any"},
+			ttl:      90 * time.Second,
+			expected: []string{"sh", "-c", `(sleep 90; kill -KILL $$) >/dev/null 2>&1 & exec "$@"`, "kraze-debug", "tcpdump", "-i", "any"},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := debugCommand(tt.command, tt.ttl); !reflect.DeepEqual(got, tt.expected) {
+				test.Errorf("debugCommand() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBuildDebugPodCopy(test *testing.T) {
+	pod := debugTestPod("api-1", corev1.PodRunning)
+	opts := DebugOptions{Image: "busybox:1.36", Command: []string{"sleep", "infinity"}, TTL: 30 * time.Minute}
+
+	podCopy := buildDebugPodCopy(&pod, "api", "app", "kraze-debug-abcde", opts)
+
+	if !strings.HasPrefix(podCopy.Name, "api-1-debug-") {
+		test.Errorf("name = %s, want api-1-debug- prefix", podCopy.Name)
+	}
+	expectedLabels := map[string]string{managedByLabel: "kraze", serviceLabel: "api", debugLabel: "true"}
+	if !reflect.DeepEqual(podCopy.Labels, expectedLabels) {
+		test.Errorf("labels = %v, want %v (original selectors must not match the copy)", podCopy.Labels, expectedLabels)
+	}
+	if podCopy.Spec.NodeName != "" {
+		test.Errorf("NodeName = %s, want empty so the copy is scheduled", podCopy.Spec.NodeName)
+	}
+	if podCopy.Spec.ShareProcessNamespace == nil || !*podCopy.Spec.ShareProcessNamespace {
+		test.Error("expected ShareProcessNamespace")
+	}
+	if podCopy.Spec.ActiveDeadlineSeconds == nil || *podCopy.Spec.ActiveDeadlineSeconds != 1800 {
+		test.Errorf("ActiveDeadlineSeconds = %v, want 1800", podCopy.Spec.ActiveDeadlineSeconds)
+	}
+	if len(podCopy.Spec.Containers) != 3 {
+		test.Fatalf("got %d containers, want 3", len(podCopy.Spec.Containers))
+	}
+
+	target := podCopy.Spec.Containers[0]
+	if !reflect.DeepEqual(target.Command, opts.Command) || target.Args != nil {
+		test.Errorf("target command = %v %v, want %v with no args", target.Command, target.Args, opts.Command)
+	}
+	if target.LivenessProbe != nil || target.ReadinessProbe != nil {
+		test.Error("expected probes to be removed")
+	}
+	if sidecar := podCopy.Spec.Containers[1]; sidecar.Command != nil {
+		test.Errorf("sidecar command = %v, want unchanged", sidecar.Command)
+	}
+	toolbox := podCopy.Spec.Containers[2]
+	if toolbox.Name != "kraze-debug-abcde" || toolbox.Image != "busybox:1.36" || !toolbox.Stdin || !toolbox.TTY {
+		test.Errorf("unexpected toolbox container: %+v", toolbox)
+	}
+
+	// The original pod must not be modified
+	if pod.Spec.NodeName != "worker-1" || len(pod.Spec.Containers) != 2 || pod.Spec.Containers[0].LivenessProbe == nil {
+		test.Error("original pod was modified")
+	}
+}
+
+func TestDebugContainerRunning(test *testing.T) {
+	tests := []struct {
+		name        string
+		status      corev1.PodStatus
+		expected    bool
+		expectError bool
+	}{
+		{
+			name: "ephemeral container running",
+			status: corev1.PodStatus{Phase: corev1.PodRunning, EphemeralContainerStatuses: []corev1.ContainerStatus{
+				{Name: "kraze-debug-abcde", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+			}},
+			expected: true,
+		},
+		{
+			name: "still creating",
+			status: corev1.PodStatus{Phase: corev1.PodRunning, EphemeralContainerStatuses: []corev1.ContainerStatus{
+				{Name: "kraze-debug-abcde", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}}},
+			}},
+		},
+		{
+			name: "image pull failure",
+			status: corev1.PodStatus{Phase: corev1.PodRunning, EphemeralContainerStatuses: []corev1.ContainerStatus{
+				{Name: "kraze-debug-abcde", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ErrImagePull"}}},
+			}},
+			expectError: true,
+		},
+		{
+			name: "copy container terminated",
+			status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "kraze-debug-abcde", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error"}}},
+			}},
+			expectError: true,
+		},
+		{name: "copy pod pending", status: corev1.PodStatus{Phase: corev1.PodPending}},
+		{name: "copy pod failed", status: corev1.PodStatus{Phase: corev1.PodFailed}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api-1"}, Status: tt.status}
+			running, err := debugContainerRunning(pod, "kraze-debug-abcde")
+			if (err != nil) != tt.expectError {
+				test.Fatalf("debugContainerRunning() error = %v, expectError %v", err, tt.expectError)
+			}
+			if running != tt.expected {
+				test.Errorf("debugContainerRunning() = %v, want %v", running, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStartDebugContainerCopy(test *testing.T) {
+	pod := debugTestPod("api-1", corev1.PodRunning)
+	clientset := fake.NewSimpleClientset(&pod)
+	service := config.ServiceConfig{Name: "api", Type: "helm", Namespace: "app"}
+
+	// The fake clientset never starts containers, so the wait is cut short
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	target, err := StartDebugContainer(ctx, clientset, &service, DebugOptions{Image: "busybox:1.36", Copy: true})
+	if target == nil {
+		test.Fatalf("StartDebugContainer() returned no target, error = %v", err)
+	}
+	if !target.Copy || target.Pod == "api-1" || !strings.HasPrefix(target.Container, debugContainerPrefix) {
+		test.Errorf("unexpected target: %+v", target)
+	}
+
+	if _, err := clientset.CoreV1().Pods("app").Get(context.Background(), target.Pod, metav1.GetOptions{}); err != nil {
+		test.Fatalf("expected debug copy to exist: %v", err)
+	}
+	if err := DeleteDebugCopy(context.Background(), clientset, target); err != nil {
+		test.Fatalf("DeleteDebugCopy() error = %v", err)
+	}
+	if _, err := clientset.CoreV1().Pods("app").Get(context.Background(), target.Pod, metav1.GetOptions{}); err == nil {
+		test.Error("expected debug copy to be deleted")
+	}
+}
@@ -0,0 +1,151 @@
+package providers
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/hjames9/kraze/internal/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Rule IDs for API versions that the target cluster removed or deprecated. A
+// removed API fails 'kraze up' before anything is applied; lint.ignore can
+// suppress either rule.
+const (
+	RuleRemovedAPI    = "KZ205"
+	RuleDeprecatedAPI = "KZ206"
+)
+
+// apiDeprecation is an API version of a kind that Kubernetes deprecated or removed
+type apiDeprecation struct {
+	apiVersion   string
+	kind         string
+	deprecatedIn string // Kubernetes minor version, e.g. "1.19"
+	removedIn    string // Empty when the API is deprecated but not scheduled for removal
+	replacement  string // apiVersion (or feature) to migrate to
+}
+
+// apiDeprecations lists removed and deprecated built-in APIs, following the
+// Kubernetes deprecated API migration guide
+var apiDeprecations = []apiDeprecation{
+	// Removed in 1.16
+	{"extensions/v1beta1", "Deployment", "1.9", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "DaemonSet", "1.9", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "ReplicaSet", "1.9", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "StatefulSet", "1.9", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "NetworkPolicy", "1.9", "1.16", "networking.k8s.io/v1"},
+	{"extensions/v1beta1", "PodSecurityPolicy", "1.10", "1.16", "policy/v1beta1"},
+	{"apps/v1beta1", "Deployment", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta1", "StatefulSet", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "Deployment", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "DaemonSet", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "ReplicaSet", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "StatefulSet", "1.9", "1.16", "apps/v1"},
+
+	// Removed in 1.22
+	{"extensions/v1beta1", "Ingress", "1.14", "1.22", "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "Ingress", "1.19", "1.22", "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "IngressClass", "1.19", "1.22", "networking.k8s.io/v1"},
+	{"apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "1.16", "1.22", "apiextensions.k8s.io/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "MutatingWebhookConfiguration", "1.16", "1.22", "admissionregistration.k8s.io/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "ValidatingWebhookConfiguration", "1.16", "1.22", "admissionregistration.k8s.io/v1"},
+	{"apiregistration.k8s.io/v1beta1", "APIService", "1.19", "1.22", "apiregistration.k8s.io/v1"},
+	{"authentication.k8s.io/v1beta1", "TokenReview", "1.19", "1.22", "authentication.k8s.io/v1"},
+	{"authorization.k8s.io/v1beta1", "SubjectAccessReview", "1.19", "1.22", "authorization.k8s.io/v1"},
+	{"certificates.k8s.io/v1beta1", "CertificateSigningRequest", "1.19", "1.22", "certificates.k8s.io/v1"},
+	{"coordination.k8s.io/v1beta1", "Lease", "1.19", "1.22", "coordination.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRole", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRoleBinding", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "Role", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "RoleBinding", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"scheduling.k8s.io/v1beta1", "PriorityClass", "1.14", "1.22", "scheduling.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSIDriver", "1.19", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSINode", "1.17", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "StorageClass", "1.19", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "VolumeAttachment", "1.19", "1.22", "storage.k8s.io/v1"},
+
+	// Removed in 1.25
+	{"batch/v1beta1", "CronJob", "1.21", "1.25", "batch/v1"},
+	{"discovery.k8s.io/v1beta1", "EndpointSlice", "1.21", "1.25", "discovery.k8s.io/v1"},
+	{"events.k8s.io/v1beta1", "Event", "1.19", "1.25", "events.k8s.io/v1"},
+	{"autoscaling/v2beta1", "HorizontalPodAutoscaler", "1.22", "1.25", "autoscaling/v2"},
+	{"policy/v1beta1", "PodDisruptionBudget", "1.21", "1.25", "policy/v1"},
+	{"policy/v1beta1", "PodSecurityPolicy", "1.21", "1.25", "Pod Security Admission (namespace labels)"},
+	{"node.k8s.io/v1beta1", "RuntimeClass", "1.20", "1.25", "node.k8s.io/v1"},
+
+	// Removed in 1.26
+	{"autoscaling/v2beta2", "HorizontalPodAutoscaler", "1.23", "1.26", "autoscaling/v2"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "FlowSchema", "1.23", "1.26", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "PriorityLevelConfiguration", "1.23", "1.26", "flowcontrol.apiserver.k8s.io/v1"},
+
+	// Removed in 1.27
+	{"storage.k8s.io/v1beta1", "CSIStorageCapacity", "1.24", "1.27", "storage.k8s.io/v1"},
+
+	// Removed in 1.29
+	{"flowcontrol.apiserver.k8s.io/v1beta2", "FlowSchema", "1.26", "1.29", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta2", "PriorityLevelConfiguration", "1.26", "1.29", "flowcontrol.apiserver.k8s.io/v1"},
+
+	// Removed in 1.32
+	{"flowcontrol.apiserver.k8s.io/v1beta3", "FlowSchema", "1.29", "1.32", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta3", "PriorityLevelConfiguration", "1.29", "1.32", "flowcontrol.apiserver.k8s.io/v1"},
+
+	// Deprecated, still served
+	{"v1", "Endpoints", "1.33", "", "discovery.k8s.io/v1 EndpointSlice"},
+}
+
+// ScanDeprecatedAPIs reports resources that use an API version the given
+// Kubernetes version has removed (RuleRemovedAPI) or deprecated (RuleDeprecatedAPI).
+// Findings are sorted with removed APIs first.
+func ScanDeprecatedAPIs(resources []*unstructured.Unstructured, kubeVersion *semver.Version) []config.LintFinding {
+	var findings []config.LintFinding
+	for _, obj := range resources {
+		for _, rule := range apiDeprecations {
+			if obj.GetAPIVersion() != rule.apiVersion || obj.GetKind() != rule.kind {
+				continue
+			}
+			if finding, ok := rule.check(obj, kubeVersion); ok {
+				findings = append(findings, finding)
+			}
+			break
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].RuleID == RuleRemovedAPI && findings[j].RuleID != RuleRemovedAPI
+	})
+	return findings
+}
+
+// check returns a finding when the rule applies to the Kubernetes version
+func (rule apiDeprecation) check(obj *unstructured.Unstructured, kubeVersion *semver.Version) (config.LintFinding, bool) {
+	field := obj.GetKind() + "/" + obj.GetName()
+	hint := fmt.Sprintf("use %s instead", rule.replacement)
+	running := fmt.Sprintf("%d.%d", kubeVersion.Major(), kubeVersion.Minor())
+
+	if rule.removedIn != "" && atLeastMinor(kubeVersion, rule.removedIn) {
+		return config.LintFinding{
+			RuleID:  RuleRemovedAPI,
+			Field:   field,
+			Message: fmt.Sprintf("%s %s was removed in Kubernetes %s and is not served by this cluster (%s)", rule.apiVersion, rule.kind, rule.removedIn, running),
+			Hint:    hint,
+		}, true
+	}
+	if atLeastMinor(kubeVersion, rule.deprecatedIn) {
+		message := fmt.Sprintf("%s %s is deprecated since Kubernetes %s", rule.apiVersion, rule.kind, rule.deprecatedIn)
+		if rule.removedIn != "" {
+			message += fmt.Sprintf(" and will be removed in %s", rule.removedIn)
+		}
+		return config.LintFinding{RuleID: RuleDeprecatedAPI, Field: field, Message: message, Hint: hint}, true
+	}
+	return config.LintFinding{}, false
+}
+
+// atLeastMinor reports whether version is at or after a "major.minor" release
+func atLeastMinor(version *semver.Version, minor string) bool {
+	threshold, err := semver.NewVersion(minor)
+	if err != nil {
+		return false
+	}
+	return !version.LessThan(threshold)
+}
@@ -0,0 +1,113 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const deprecationTestIngress = `
+apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  name: web
+`
+
+const deprecationTestCronJob = `
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: cleanup
+`
+
+const deprecationTestEndpoints = `
+apiVersion: v1
+kind: Endpoints
+metadata:
+  name: legacy
+`
+
+const deprecationTestCurrent = `
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: api
+---
+apiVersion: example.com/v1beta1
+kind: Widget
+metadata:
+  name: custom
+`
+
+func TestScanDeprecatedAPIs(test *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		docs    []string
+		want    []string // RuleID + " " + Field, in order
+	}{
+		{
+			name:    "removed API",
+			version: "1.31.0",
+			docs:    []string{deprecationTestIngress},
+			want:    []string{"KZ205 Ingress/web"},
+		},
+		{
+			name:    "deprecated but still served",
+			version: "1.23.4",
+			docs:    []string{deprecationTestCronJob},
+			want:    []string{"KZ206 CronJob/cleanup"},
+		},
+		{
+			name:    "before deprecation",
+			version: "1.20.0",
+			docs:    []string{deprecationTestCronJob},
+			want:    nil,
+		},
+		{
+			name:    "removed boundary",
+			version: "1.25.0",
+			docs:    []string{deprecationTestCronJob},
+			want:    []string{"KZ205 CronJob/cleanup"},
+		},
+		{
+			name:    "deprecated without removal",
+			version: "1.33.1",
+			docs:    []string{deprecationTestEndpoints},
+			want:    []string{"KZ206 Endpoints/legacy"},
+		},
+		{
+			name:    "removed sorted first",
+			version: "1.33.1",
+			docs:    []string{deprecationTestEndpoints, deprecationTestIngress},
+			want:    []string{"KZ205 Ingress/web", "KZ206 Endpoints/legacy"},
+		},
+		{
+			name:    "current and unknown APIs",
+			version: "1.33.1",
+			docs:    []string{deprecationTestCurrent},
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			findings := ScanDeprecatedAPIs(lintTestObjects(test, tt.docs...), semver.MustParse(tt.version))
+			var got []string
+			for _, itr := range findings {
+				got = append(got, itr.RuleID+" "+itr.Field)
+				if itr.Hint == "" {
+					test.Errorf("finding %s has no replacement hint", itr.Field)
+				}
+			}
+			if len(got) != len(tt.want) {
+				test.Fatalf("ScanDeprecatedAPIs() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					test.Errorf("finding %d = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
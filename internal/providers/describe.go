@@ -0,0 +1,144 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hjames9/kraze/internal/config"
+	"helm.sh/helm/v4/pkg/action"
+	ri "helm.sh/helm/v4/pkg/release"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Readiness of a resource in the cluster, as reported by ResourcesReadiness
+const (
+	ResourceReady    = "Ready"
+	ResourceNotReady = "NotReady"
+	ResourceFailed   = "Failed"
+	ResourceMissing  = "Missing"
+	ResourcePresent  = "Present" // Exists, and its kind has no readiness
+)
+
+// ReleaseInfo is the installed Helm release of a service
+type ReleaseInfo struct {
+	Revision   int            `json:"revision"`
+	Status     string         `json:"status"`
+	DeployedAt time.Time      `json:"deployed_at"`
+	Chart      *ChartMetadata `json:"chart,omitempty"`
+	Resources  []ResourceRef  `json:"-"` // Resources in the release's manifest
+}
+
+// ResourceReadiness is a resource of a service with its readiness in the cluster
+type ResourceReadiness struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Status    string `json:"status"` // Ready, NotReady, Failed, Missing or Present
+	Message   string `json:"message,omitempty"`
+}
+
+// DescribeRelease returns the revision, chart and resources of a service's
+// installed Helm release
+func DescribeRelease(service *config.ServiceConfig, opts *ProviderOptions) (*ReleaseInfo, error) {
+	helm, err := NewHelmProvider(opts)
+	if err != nil {
+		return nil, err
+	}
+	actionConfig, err := helm.getActionConfig(service.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	rel, err := action.NewGet(actionConfig).Run(service.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get release: %w", err)
+	}
+	acc, err := ri.NewAccessor(rel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read release: %w", err)
+	}
+
+	info := &ReleaseInfo{Revision: acc.Version(), Status: acc.Status(), DeployedAt: acc.DeployedAt()}
+	if info.Chart, err = chartMetadata(acc.Chart()); err != nil {
+		return nil, err
+	}
+
+	resources, err := ParseResources([]byte(acc.Manifest()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse release manifest: %w", err)
+	}
+	manifest, err := NewManifestsProvider(opts)
+	if err != nil {
+		return nil, err
+	}
+	manifest.setDefaultNamespace(resources, service.GetNamespace())
+	info.Resources = ResourceRefs(resources)
+	return info, nil
+}
+
+// ResourcesReadiness reads each resource from the cluster and computes its
+// readiness the way 'kraze up' waits for it, with the readiness rules in opts
+func ResourcesReadiness(ctx context.Context, refs []ResourceRef, opts *ProviderOptions) ([]ResourceReadiness, error) {
+	manifest, err := NewManifestsProvider(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	readiness := make([]ResourceReadiness, 0, len(refs))
+	for _, ref := range refs {
+		obj, ok := ref.parse()
+		if !ok {
+			continue
+		}
+		entry := ResourceReadiness{Kind: obj.GetKind(), Namespace: obj.GetNamespace(), Name: obj.GetName()}
+
+		gvr, err := manifest.getGVR(obj)
+		if err != nil {
+			entry.Status, entry.Message = ResourceMissing, err.Error()
+			readiness = append(readiness, entry)
+			continue
+		}
+		live, err := manifest.dynamicClient.Resource(gvr).Namespace(obj.GetNamespace()).Get(ctx, obj.GetName(), metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			entry.Status = ResourceMissing
+			readiness = append(readiness, entry)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s: %w", ref.String(), err)
+		}
+
+		entry.Status, entry.Message = liveReadiness(live, opts.ReadinessRules)
+		readiness = append(readiness, entry)
+	}
+	return readiness, nil
+}
+
+// liveReadiness returns the readiness of a resource read from the cluster,
+// with a message saying why it isn't ready
+func liveReadiness(obj *unstructured.Unstructured, rules map[string]config.ReadinessRule) (string, string) {
+	if !shouldWaitForResource(obj, rules) {
+		return ResourcePresent, ""
+	}
+
+	var ready bool
+	var message string
+	var err error
+	if rule, ok := readinessRuleFor(rules, obj); ok {
+		ready, message, err = evaluateReadinessRule(obj, rule)
+	} else {
+		ready, message, err = isResourceReady(obj, obj.GetKind())
+	}
+	switch {
+	case err != nil:
+		if message == "" {
+			message = err.Error()
+		}
+		return ResourceFailed, message
+	case ready:
+		return ResourceReady, ""
+	}
+	return ResourceNotReady, message
+}
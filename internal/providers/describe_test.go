@@ -0,0 +1,63 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestLiveReadiness(test *testing.T) {
+	deployment := func(ready int64, available string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": "api", "namespace": "default", "generation": int64(1)},
+			"spec":       map[string]interface{}{"replicas": int64(2)},
+			"status": map[string]interface{}{
+				"observedGeneration": int64(1),
+				"replicas":           int64(2),
+				"updatedReplicas":    int64(2),
+				"readyReplicas":      ready,
+				"availableReplicas":  ready,
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Available", "status": available},
+				},
+			},
+		}}
+	}
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "settings", "namespace": "default"},
+	}}
+	phaseRule := map[string]config.ReadinessRule{
+		"Kafka.kafka.strimzi.io": {JSONPath: ".status.phase", Value: "Running", Failed: []string{"Failed"}},
+	}
+
+	tests := []struct {
+		name       string
+		obj        *unstructured.Unstructured
+		rules      map[string]config.ReadinessRule
+		wantStatus string
+	}{
+		{name: "ready deployment", obj: deployment(2, "True"), wantStatus: ResourceReady},
+		{name: "unready deployment", obj: deployment(1, "False"), wantStatus: ResourceNotReady},
+		{name: "kind without readiness", obj: configMap, wantStatus: ResourcePresent},
+		{name: "rule ready", obj: readinessTestResource(map[string]interface{}{"phase": "Running"}), rules: phaseRule, wantStatus: ResourceReady},
+		{name: "rule not ready", obj: readinessTestResource(map[string]interface{}{"phase": "Provisioning"}), rules: phaseRule, wantStatus: ResourceNotReady},
+		{name: "rule failed", obj: readinessTestResource(map[string]interface{}{"phase": "Failed"}), rules: phaseRule, wantStatus: ResourceFailed},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			status, message := liveReadiness(tt.obj, tt.rules)
+			if status != tt.wantStatus {
+				test.Errorf("liveReadiness() = %s (%s), want %s", status, message, tt.wantStatus)
+			}
+			if (status == ResourceNotReady || status == ResourceFailed) && message == "" {
+				test.Error("Expected a message saying why the resource isn't ready")
+			}
+		})
+	}
+}
@@ -0,0 +1,389 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/pmezard/go-difflib/difflib"
+	"helm.sh/helm/v4/pkg/action"
+	ri "helm.sh/helm/v4/pkg/release"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// Ways applying a service changes a resource
+const (
+	DiffAdd    = "add"
+	DiffChange = "change"
+	DiffDelete = "delete"
+)
+
+// ResourceDiff is how installing a service would change one resource
+type ResourceDiff struct {
+	Ref    ResourceRef
+	Action string // DiffAdd, DiffChange or DiffDelete
+	Diff   string // Unified diff from the live object to the applied one
+	Error  string // Why the change couldn't be predicted (the apply would fail)
+
+	// Conflict is set when the apply would fail because another manager owns
+	// fields the resource sets
+	Conflict bool
+}
+
+// DiffService compares what installing a service would leave in the cluster
+// with what's there now. Changes are predicted with a server-side dry-run
+// apply, so defaulted fields and fields other managers own don't show up as
+// drift. Resources the last install applied that the service no longer
+// renders (from the Helm release, or opts.PruneResources for manifests) are
+// reported as deleted. Helm hooks are left out, as Helm runs them rather than
+// keeping them applied.
+func DiffService(ctx context.Context, service *config.ServiceConfig, opts *ProviderOptions) ([]ResourceDiff, error) {
+	provider, err := NewProvider(service, opts)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := NewManifestsProvider(opts)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := manifest.renderApplied(ctx, provider, service)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := opts.PruneResources
+	if helm, ok := provider.(*HelmProvider); ok {
+		released, err := helm.deployedResources(service)
+		if err != nil {
+			return nil, err
+		}
+		manifest.setDefaultNamespace(released, service.GetNamespace())
+		previous = ResourceRefs(released)
+	}
+
+	var diffs []ResourceDiff
+	for _, obj := range resources {
+		diff, err := manifest.diffResource(ctx, obj)
+		if err != nil {
+			return nil, err
+		}
+		if diff.Action != "" {
+			diffs = append(diffs, diff)
+		}
+	}
+
+	for _, ref := range StaleResources(previous, ResourceRefs(resources)) {
+		obj, ok := ref.parse()
+		if !ok || unprunableKinds[obj.GetKind()] {
+			continue
+		}
+		live, err := manifest.liveObject(ctx, obj)
+		if err != nil {
+			return nil, err
+		}
+		if live != nil {
+			diff, err := newResourceDiff(ref, live, nil)
+			if err != nil {
+				return nil, err
+			}
+			diffs = append(diffs, diff)
+		}
+	}
+	return diffs, nil
+}
+
+// RenderApplied renders a service as installing it leaves it applied: with
+// kraze's and Helm's metadata, the namespace kraze creates for it and each
+// namespaced resource in its namespace. Helm hooks are left out.
+func RenderApplied(ctx context.Context, service *config.ServiceConfig, opts *ProviderOptions) ([]*unstructured.Unstructured, error) {
+	provider, err := NewProvider(service, opts)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := NewManifestsProvider(opts)
+	if err != nil {
+		return nil, err
+	}
+	return manifest.renderApplied(ctx, provider, service)
+}
+
+// DeployedResources returns a service's resources as its last install applied
+// them. For Helm services that's the manifest of the deployed release, which
+// keeps the values charts generate with randAlphaNum or read with lookup where
+// a new render would change them. Other services are rendered as RenderApplied.
+func DeployedResources(ctx context.Context, service *config.ServiceConfig, opts *ProviderOptions) ([]*unstructured.Unstructured, error) {
+	provider, err := NewProvider(service, opts)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := NewManifestsProvider(opts)
+	if err != nil {
+		return nil, err
+	}
+	helm, ok := provider.(*HelmProvider)
+	if !ok {
+		return manifest.renderApplied(ctx, provider, service)
+	}
+
+	resources, err := helm.deployedResources(service)
+	if err != nil {
+		return nil, err
+	}
+	if resources == nil {
+		return nil, fmt.Errorf("service '%s' has no deployed release", service.Name)
+	}
+	addHelmReleaseMetadata(resources, service)
+	if service.ShouldCreateNamespace() {
+		resources = append([]*unstructured.Unstructured{NamespaceObject(service.GetNamespace())}, resources...)
+	}
+	manifest.setDefaultNamespace(resources, service.GetNamespace())
+	return resources, nil
+}
+
+// renderApplied renders a service with provider as installing it leaves it applied
+func (manifest *ManifestsProvider) renderApplied(ctx context.Context, provider Provider, service *config.ServiceConfig) ([]*unstructured.Unstructured, error) {
+	resources, err := provider.Render(ctx, service)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render: %w", err)
+	}
+	if err := decorateForApply(service, resources); err != nil {
+		return nil, err
+	}
+	resources = withoutHooks(resources)
+
+	if _, ok := provider.(*HelmProvider); ok {
+		addHelmReleaseMetadata(resources, service)
+	}
+	if service.ShouldCreateNamespace() {
+		resources = append([]*unstructured.Unstructured{NamespaceObject(service.GetNamespace())}, resources...)
+	}
+	manifest.setDefaultNamespace(resources, service.GetNamespace())
+	return resources, nil
+}
+
+// setDefaultNamespace puts namespaced resources without a namespace in
+// namespace, as Helm does with a release's resources
+func (manifest *ManifestsProvider) setDefaultNamespace(objs []*unstructured.Unstructured, namespace string) {
+	for _, obj := range objs {
+		if obj.GetNamespace() == "" && manifest.isNamespacedResource(obj) {
+			obj.SetNamespace(namespace)
+		}
+	}
+}
+
+// diffResource predicts how applying obj changes the live object
+func (manifest *ManifestsProvider) diffResource(ctx context.Context, obj *unstructured.Unstructured) (ResourceDiff, error) {
+	ref := NewResourceRef(obj)
+	client, err := manifest.resourceClient(obj)
+	if err != nil {
+		// The API isn't served yet, e.g. a custom resource whose CRD the service installs
+		return newResourceDiff(ref, nil, obj)
+	}
+
+	live, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return newResourceDiff(ref, nil, obj)
+	}
+	if err != nil {
+		return ResourceDiff{}, fmt.Errorf("failed to get %s: %w", ref, err)
+	}
+	if obj.GetKind() == "PersistentVolumeClaim" {
+		// Bound claims are never re-applied
+		return ResourceDiff{}, nil
+	}
+
+	applied, err := client.Apply(ctx, obj.GetName(), prepareForApply(obj), metav1.ApplyOptions{
+		FieldManager: fieldManager,
+		Force:        manifest.opts.ForceConflicts,
+		DryRun:       []string{metav1.DryRunAll},
+	})
+	if err != nil {
+		diff := ResourceDiff{Ref: ref, Action: DiffChange, Error: err.Error()}
+		if errors.IsConflict(err) {
+			diff.Conflict = true
+			diff.Error = "fields are owned by another manager, use --force-conflicts to take ownership: " + err.Error()
+		}
+		return diff, nil
+	}
+	return newResourceDiff(ref, live, applied)
+}
+
+// liveObject returns the live version of obj, or nil if it doesn't exist
+func (manifest *ManifestsProvider) liveObject(ctx context.Context, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	client, err := manifest.resourceClient(obj)
+	if err != nil {
+		return nil, nil
+	}
+	live, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", NewResourceRef(obj), err)
+	}
+	return live, nil
+}
+
+// resourceClient returns the dynamic client for obj's resource and namespace
+func (manifest *ManifestsProvider) resourceClient(obj *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
+	gvr, err := manifest.getGVR(obj)
+	if err != nil {
+		return nil, err
+	}
+	if obj.GetNamespace() != "" {
+		return manifest.dynamicClient.Resource(gvr).Namespace(obj.GetNamespace()), nil
+	}
+	return manifest.dynamicClient.Resource(gvr), nil
+}
+
+// deployedResources returns the resources of a service's deployed release, or
+// nil if it has none
+func (helm *HelmProvider) deployedResources(service *config.ServiceConfig) ([]*unstructured.Unstructured, error) {
+	actionConfig, err := helm.getActionConfig(service.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	rel, err := action.NewGet(actionConfig).Run(service.Name)
+	if err != nil {
+		return nil, nil
+	}
+	acc, err := ri.NewAccessor(rel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read release: %w", err)
+	}
+	return parseManifestsYAML(acc.Manifest())
+}
+
+// addHelmReleaseMetadata sets the ownership metadata Helm adds to every
+// resource of a release, so a diff doesn't report it as removed
+func addHelmReleaseMetadata(resources []*unstructured.Unstructured, service *config.ServiceConfig) {
+	for _, obj := range resources {
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[managedByLabel] = "Helm"
+		obj.SetLabels(labels)
+
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[helmReleaseNameAnnotation] = service.Name
+		annotations[helmReleaseNamespaceAnnotation] = service.GetNamespace()
+		obj.SetAnnotations(annotations)
+	}
+}
+
+// newResourceDiff compares the live and applied versions of a resource (nil
+// when it doesn't exist). The diff is empty when nothing would change.
+func newResourceDiff(ref ResourceRef, live, applied *unstructured.Unstructured) (ResourceDiff, error) {
+	diff := ResourceDiff{Ref: ref}
+	switch {
+	case live == nil:
+		diff.Action = DiffAdd
+	case applied == nil:
+		diff.Action = DiffDelete
+	default:
+		diff.Action = DiffChange
+	}
+
+	before, after := diffableObject(live), diffableObject(applied)
+	if before != nil && after != nil && before.GetKind() == "Secret" {
+		maskSecretData(before, after)
+	} else if before != nil && before.GetKind() == "Secret" {
+		maskSecretData(before, nil)
+	} else if after != nil && after.GetKind() == "Secret" {
+		maskSecretData(nil, after)
+	}
+
+	beforeYAML, err := diffYAML(before)
+	if err != nil {
+		return ResourceDiff{}, err
+	}
+	afterYAML, err := diffYAML(after)
+	if err != nil {
+		return ResourceDiff{}, err
+	}
+	if beforeYAML == afterYAML {
+		return ResourceDiff{}, nil
+	}
+
+	diff.Diff, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(beforeYAML),
+		B:        difflib.SplitLines(afterYAML),
+		FromFile: "live/" + ref.String(),
+		ToFile:   "kraze/" + ref.String(),
+		Context:  3,
+	})
+	if err != nil {
+		return ResourceDiff{}, fmt.Errorf("failed to diff %s: %w", ref, err)
+	}
+	return diff, nil
+}
+
+// diffableObject returns a copy of obj without the fields the API server
+// maintains, which would otherwise show up in every diff
+func diffableObject(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	if obj == nil {
+		return nil
+	}
+	clean := obj.DeepCopy()
+	for _, field := range []string{"managedFields", "resourceVersion", "uid", "generation", "creationTimestamp", "selfLink"} {
+		unstructured.RemoveNestedField(clean.Object, "metadata", field)
+	}
+	unstructured.RemoveNestedField(clean.Object, "status")
+	if annotations := clean.GetAnnotations(); annotations != nil {
+		delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+		if len(annotations) == 0 {
+			annotations = nil
+		}
+		clean.SetAnnotations(annotations)
+	}
+	return clean
+}
+
+// maskSecretData replaces Secret values, like kubectl diff does, keeping
+// which keys changed visible
+func maskSecretData(before, after *unstructured.Unstructured) {
+	for _, field := range []string{"data", "stringData"} {
+		var beforeData, afterData map[string]interface{}
+		if before != nil {
+			beforeData, _, _ = unstructured.NestedMap(before.Object, field)
+		}
+		if after != nil {
+			afterData, _, _ = unstructured.NestedMap(after.Object, field)
+		}
+		for key, value := range beforeData {
+			if afterValue, ok := afterData[key]; ok && afterValue != value {
+				beforeData[key] = "*** (before)"
+				afterData[key] = "*** (after)"
+			} else {
+				beforeData[key] = "***"
+			}
+		}
+		for key := range afterData {
+			if afterData[key] != "*** (after)" {
+				afterData[key] = "***"
+			}
+		}
+		if beforeData != nil {
+			_ = unstructured.SetNestedMap(before.Object, beforeData, field)
+		}
+		if afterData != nil {
+			_ = unstructured.SetNestedMap(after.Object, afterData, field)
+		}
+	}
+}
+
+// diffYAML serializes obj for diffing, or returns "" for nil
+func diffYAML(obj *unstructured.Unstructured) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+	data, err := MarshalResources([]*unstructured.Unstructured{obj})
+	return string(data), err
+}
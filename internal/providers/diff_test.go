@@ -0,0 +1,119 @@
+package providers
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestNewResourceDiff(test *testing.T) {
+	configMap := func(value string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":            "settings",
+				"namespace":       "app",
+				"resourceVersion": "42",
+				"uid":             "d6f1",
+				"managedFields":   []interface{}{map[string]interface{}{"manager": "kraze"}},
+			},
+			"data": map[string]interface{}{"LOG_LEVEL": value},
+		}}
+		return obj
+	}
+	secret := func(password, user string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata":   map[string]interface{}{"name": "db", "namespace": "app"},
+			"data":       map[string]interface{}{"password": password, "user": user},
+		}}
+	}
+
+	tests := []struct {
+		name       string
+		live       *unstructured.Unstructured
+		applied    *unstructured.Unstructured
+		action     string
+		contains   []string
+		notContain []string
+	}{
+		{
+			name:     "added",
+			applied:  configMap("debug"),
+			action:   DiffAdd,
+			contains: []string{"+kind: ConfigMap", "+  LOG_LEVEL: debug"},
+		},
+		{
+			name:       "changed",
+			live:       configMap("info"),
+			applied:    configMap("debug"),
+			action:     DiffChange,
+			contains:   []string{"--- live/ConfigMap/app/settings", "+++ kraze/ConfigMap/app/settings", "-  LOG_LEVEL: info", "+  LOG_LEVEL: debug"},
+			notContain: []string{"resourceVersion", "managedFields", "uid"},
+		},
+		{
+			name:    "unchanged apart from server fields",
+			live:    configMap("info"),
+			applied: func() *unstructured.Unstructured { obj := configMap("info"); obj.SetResourceVersion("43"); return obj }(),
+		},
+		{
+			name:     "deleted",
+			live:     configMap("info"),
+			action:   DiffDelete,
+			contains: []string{"-kind: ConfigMap"},
+		},
+		{
+			name:       "secret values are masked",
+			live:       secret("b2xk", "YWRtaW4="),
+			applied:    secret("bmV3", "YWRtaW4="),
+			action:     DiffChange,
+			contains:   []string{"-  password: '*** (before)'", "+  password: '*** (after)'", "   user: '***'"},
+			notContain: []string{"b2xk", "bmV3", "YWRtaW4="},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			ref := ResourceRef("v1:ConfigMap:app:settings")
+			if tt.live != nil && tt.live.GetKind() == "Secret" {
+				ref = ResourceRef("v1:Secret:app:db")
+			}
+			got, err := newResourceDiff(ref, tt.live, tt.applied)
+			if err != nil {
+				test.Fatalf("newResourceDiff() error = %v", err)
+			}
+			if got.Action != tt.action {
+				test.Errorf("newResourceDiff() action = %q, want %q", got.Action, tt.action)
+			}
+			if tt.action == "" && got.Diff != "" {
+				test.Errorf("newResourceDiff() diff = %s, want none", got.Diff)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(got.Diff, want) {
+					test.Errorf("diff missing %q:\n%s", want, got.Diff)
+				}
+			}
+			for _, unwanted := range tt.notContain {
+				if strings.Contains(got.Diff, unwanted) {
+					test.Errorf("diff contains %q:\n%s", unwanted, got.Diff)
+				}
+			}
+		})
+	}
+}
+
+func TestWithoutHooks(test *testing.T) {
+	hook := &unstructured.Unstructured{}
+	hook.SetName("migrate")
+	hook.SetAnnotations(map[string]string{helmHookAnnotation: "pre-upgrade"})
+	deployment := &unstructured.Unstructured{}
+	deployment.SetName("api")
+
+	got := withoutHooks([]*unstructured.Unstructured{hook, deployment})
+	if len(got) != 1 || got[0].GetName() != "api" {
+		test.Errorf("withoutHooks() = %v, want only api", got)
+	}
+}
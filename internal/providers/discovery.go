@@ -0,0 +1,224 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// discoveryLabel marks the discovery ConfigMaps kraze writes
+	discoveryLabel = "kraze.discovery"
+	// discoveryStateLabel names the state (environment or --suffix variant) a
+	// discovery ConfigMap was written for, so variants sharing a cluster don't
+	// delete each other's
+	discoveryStateLabel = "kraze.discovery-state"
+)
+
+// DiscoveryEntry is a Kubernetes Service of a kraze service
+type DiscoveryEntry struct {
+	Service   string // kraze service
+	Name      string // Kubernetes Service
+	Namespace string
+	Headless  bool
+	Ports     []corev1.ServicePort
+}
+
+// Host returns the in-cluster DNS name of the Service
+func (entry DiscoveryEntry) Host() string {
+	return fmt.Sprintf("%s.%s.svc.cluster.local", entry.Name, entry.Namespace)
+}
+
+// DiscoverServices lists the Kubernetes Services of each service, with the
+// main one first: the Service named like the service, else the first one with
+// a cluster IP
+func DiscoverServices(ctx context.Context, clientset kubernetes.Interface, services []*config.ServiceConfig) ([]DiscoveryEntry, error) {
+	var entries []DiscoveryEntry
+	for _, service := range services {
+		namespace := service.GetNamespace()
+		list, err := clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{LabelSelector: serviceWorkloadSelector(service)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Services of '%s': %w", service.Name, err)
+		}
+		var found []DiscoveryEntry
+		for _, svc := range list.Items {
+			if len(svc.Spec.Ports) == 0 {
+				continue
+			}
+			found = append(found, DiscoveryEntry{
+				Service:   service.Name,
+				Name:      svc.Name,
+				Namespace: namespace,
+				Headless:  svc.Spec.ClusterIP == corev1.ClusterIPNone,
+				Ports:     svc.Spec.Ports,
+			})
+		}
+		sort.SliceStable(found, func(i, j int) bool {
+			return discoveryRank(found[i]) < discoveryRank(found[j]) || (discoveryRank(found[i]) == discoveryRank(found[j]) && found[i].Name < found[j].Name)
+		})
+		entries = append(entries, found...)
+	}
+	return entries, nil
+}
+
+// discoveryRank orders a service's Services, lowest first
+func discoveryRank(entry DiscoveryEntry) int {
+	switch {
+	case entry.Name == entry.Service:
+		return 0
+	case !entry.Headless:
+		return 1
+	}
+	return 2
+}
+
+// DiscoveryVars returns the variables for the entries. The main Service of a
+// service sets <SERVICE>_HOST, _PORT and _ADDR (host:port of its first port)
+// and a _PORT_<NAME> for each named port; its other Services set the same with
+// their name (less the service prefix) added, e.g. REDIS_HEADLESS_HOST. Ports
+// forwarded to the host are given as 127.0.0.1 and the local port when
+// forwards has them, for processes outside the cluster.
+func DiscoveryVars(entries []DiscoveryEntry, forwards map[string][]config.PortForward) map[string]string {
+	vars := make(map[string]string)
+	main := make(map[string]bool)
+	for _, entry := range entries {
+		prefix := config.DiscoveryKey(entry.Service)
+		if main[entry.Service] {
+			prefix += "_" + config.DiscoveryKey(strings.TrimPrefix(entry.Name, entry.Service+"-"))
+		}
+		main[entry.Service] = true
+
+		address := func(port int32) (string, string) {
+			for _, forward := range forwards[entry.Service] {
+				if int32(forward.RemotePort) == port {
+					return "127.0.0.1", strconv.Itoa(forward.LocalPort)
+				}
+			}
+			return entry.Host(), strconv.Itoa(int(port))
+		}
+		host, port := address(entry.Ports[0].Port)
+		vars[prefix+"_HOST"] = host
+		vars[prefix+"_PORT"] = port
+		vars[prefix+"_ADDR"] = host + ":" + port
+		for _, servicePort := range entry.Ports {
+			if servicePort.Name == "" {
+				continue
+			}
+			_, port := address(servicePort.Port)
+			vars[prefix+"_PORT_"+config.DiscoveryKey(servicePort.Name)] = port
+		}
+	}
+	return vars
+}
+
+// WriteDiscoveryMap creates or updates the discovery ConfigMap in a namespace
+// for the state stateName
+func WriteDiscoveryMap(ctx context.Context, clientset kubernetes.Interface, stateName, name, namespace string, vars map[string]string) error {
+	client := clientset.CoreV1().ConfigMaps(namespace)
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{managedByLabel: "kraze", discoveryLabel: "true", discoveryStateLabel: stateName},
+		},
+		Data: vars,
+	}
+	existing, err := client.Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		if _, err := client.Create(ctx, configMap, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create ConfigMap %s/%s: %w", namespace, name, err)
+		}
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to get ConfigMap %s/%s: %w", namespace, name, err)
+	}
+	if existing.Labels[discoveryLabel] != "true" {
+		return fmt.Errorf("ConfigMap %s/%s exists and wasn't created by kraze", namespace, name)
+	}
+	if maps.Equal(existing.Data, vars) && existing.Labels[discoveryStateLabel] == stateName {
+		return nil
+	}
+	existing.Labels[discoveryStateLabel] = stateName
+	existing.Data = vars
+	if _, err := client.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update ConfigMap %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
+
+// DeleteDiscoveryMaps deletes the discovery ConfigMaps of the state stateName
+// in every namespace
+func DeleteDiscoveryMaps(ctx context.Context, clientset kubernetes.Interface, stateName string) error {
+	selector := fmt.Sprintf("%s=true,%s=%s", discoveryLabel, discoveryStateLabel, stateName)
+	list, err := clientset.CoreV1().ConfigMaps(metav1.NamespaceAll).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return fmt.Errorf("failed to list discovery ConfigMaps: %w", err)
+	}
+	for _, configMap := range list.Items {
+		err := clientset.CoreV1().ConfigMaps(configMap.Namespace).Delete(ctx, configMap.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete ConfigMap %s/%s: %w", configMap.Namespace, configMap.Name, err)
+		}
+	}
+	return nil
+}
+
+// injectDiscovery adds the service's discovery ConfigMap to the containers and
+// init containers of a workload with envFrom. It's optional, so pods start
+// before the ConfigMap is written, and variables the containers set themselves win.
+func injectDiscovery(obj *unstructured.Unstructured, service *config.ServiceConfig) bool {
+	specPath := podSpecPath(obj.GetKind())
+	if specPath == nil || service.DiscoveryConfigMap == "" {
+		return false
+	}
+
+	changed := false
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, found, err := unstructured.NestedSlice(obj.Object, append(specPath, field)...)
+		if err != nil || !found {
+			continue
+		}
+		fieldChanged := false
+		for _, itr := range containers {
+			container, ok := itr.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			envFrom, _ := container["envFrom"].([]interface{})
+			if hasConfigMapRef(envFrom, service.DiscoveryConfigMap) {
+				continue
+			}
+			// Ahead of the container's own sources, which win on conflicts
+			ref := map[string]interface{}{"configMapRef": map[string]interface{}{"name": service.DiscoveryConfigMap, "optional": true}}
+			container["envFrom"] = append([]interface{}{ref}, envFrom...)
+			fieldChanged = true
+		}
+		if fieldChanged {
+			unstructured.SetNestedSlice(obj.Object, containers, append(specPath, field)...)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// hasConfigMapRef returns whether envFrom already loads a ConfigMap
+func hasConfigMapRef(envFrom []interface{}, name string) bool {
+	for _, itr := range envFrom {
+		source, _ := itr.(map[string]interface{})
+		if ref, ok := source["configMapRef"].(map[string]interface{}); ok && ref["name"] == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package providers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func discoveryTestService(namespace, name, clusterIP string, labels map[string]string, ports ...corev1.ServicePort) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Spec:       corev1.ServiceSpec{ClusterIP: clusterIP, Ports: ports},
+	}
+}
+
+func TestDiscoveryVars(test *testing.T) {
+	ctx := context.Background()
+	redisLabels := map[string]string{"app.kubernetes.io/instance": "redis"}
+	apiLabels := map[string]string{managedByLabel: "kraze", serviceLabel: "user-api"}
+	clientset := fake.NewSimpleClientset(
+		discoveryTestService("data", "redis-headless", corev1.ClusterIPNone, redisLabels, corev1.ServicePort{Name: "tcp-redis", Port: 6379}),
+		discoveryTestService("data", "redis-master", "10.96.0.10", redisLabels, corev1.ServicePort{Name: "tcp-redis", Port: 6379}),
+		discoveryTestService("apps", "user-api", "10.96.0.11", apiLabels, corev1.ServicePort{Name: "http", Port: 80}, corev1.ServicePort{Name: "grpc", Port: 9090}),
+		discoveryTestService("apps", "unrelated", "10.96.0.12", nil, corev1.ServicePort{Port: 80}),
+	)
+	services := []*config.ServiceConfig{
+		{Name: "redis", Type: "helm", Namespace: "data"},
+		{Name: "user-api", Type: "manifests", Namespace: "apps"},
+	}
+
+	entries, err := DiscoverServices(ctx, clientset, services)
+	if err != nil {
+		test.Fatalf("DiscoverServices() error = %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	if want := []string{"redis-master", "redis-headless", "user-api"}; !reflect.DeepEqual(names, want) {
+		test.Fatalf("DiscoverServices() = %v, want %v", names, want)
+	}
+
+	tests := []struct {
+		name     string
+		forwards map[string][]config.PortForward
+		want     map[string]string
+	}{
+		{
+			name: "in cluster",
+			want: map[string]string{
+				"REDIS_HOST":                    "redis-master.data.svc.cluster.local",
+				"REDIS_PORT":                    "6379",
+				"REDIS_ADDR":                    "redis-master.data.svc.cluster.local:6379",
+				"REDIS_PORT_TCP_REDIS":          "6379",
+				"REDIS_HEADLESS_HOST":           "redis-headless.data.svc.cluster.local",
+				"REDIS_HEADLESS_PORT":           "6379",
+				"REDIS_HEADLESS_ADDR":           "redis-headless.data.svc.cluster.local:6379",
+				"REDIS_HEADLESS_PORT_TCP_REDIS": "6379",
+				"USER_API_HOST":                 "user-api.apps.svc.cluster.local",
+				"USER_API_PORT":                 "80",
+				"USER_API_ADDR":                 "user-api.apps.svc.cluster.local:80",
+				"USER_API_PORT_HTTP":            "80",
+				"USER_API_PORT_GRPC":            "9090",
+			},
+		},
+		{
+			name:     "forwarded",
+			forwards: map[string][]config.PortForward{"user-api": {{LocalPort: 8080, RemotePort: 80}}},
+			want: map[string]string{
+				"USER_API_HOST":      "127.0.0.1",
+				"USER_API_PORT":      "8080",
+				"USER_API_ADDR":      "127.0.0.1:8080",
+				"USER_API_PORT_HTTP": "8080",
+				"USER_API_PORT_GRPC": "9090",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			vars := DiscoveryVars(entries, tt.forwards)
+			for key, want := range tt.want {
+				if vars[key] != want {
+					test.Errorf("%s = %q, want %q", key, vars[key], want)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteDiscoveryMap(test *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "apps"}})
+
+	if err := WriteDiscoveryMap(ctx, clientset, "kraze-metadata", "kraze-discovery", "apps", map[string]string{"API_PORT": "80"}); err != nil {
+		test.Fatalf("WriteDiscoveryMap() error = %v", err)
+	}
+	if err := WriteDiscoveryMap(ctx, clientset, "kraze-metadata", "kraze-discovery", "apps", map[string]string{"API_PORT": "8080"}); err != nil {
+		test.Fatalf("WriteDiscoveryMap() error = %v", err)
+	}
+	configMap, err := clientset.CoreV1().ConfigMaps("apps").Get(ctx, "kraze-discovery", metav1.GetOptions{})
+	if err != nil {
+		test.Fatalf("Expected the discovery ConfigMap: %v", err)
+	}
+	if configMap.Data["API_PORT"] != "8080" {
+		test.Errorf("API_PORT = %q, want 8080", configMap.Data["API_PORT"])
+	}
+
+	// A ConfigMap kraze didn't write is never replaced
+	if err := WriteDiscoveryMap(ctx, clientset, "kraze-metadata", "settings", "apps", map[string]string{"API_PORT": "80"}); err == nil {
+		test.Error("WriteDiscoveryMap() expected error for a ConfigMap kraze didn't create")
+	}
+
+	// A --suffix variant's ConfigMap survives deleting the default environment's
+	if err := WriteDiscoveryMap(ctx, clientset, "kraze-metadata-pr-1", "kraze-discovery", "apps-pr-1", map[string]string{"API_PORT": "80"}); err != nil {
+		test.Fatalf("WriteDiscoveryMap() error = %v", err)
+	}
+	if err := DeleteDiscoveryMaps(ctx, clientset, "kraze-metadata"); err != nil {
+		test.Fatalf("DeleteDiscoveryMaps() error = %v", err)
+	}
+	list, _ := clientset.CoreV1().ConfigMaps("apps").List(ctx, metav1.ListOptions{})
+	if len(list.Items) != 1 || list.Items[0].Name != "settings" {
+		test.Errorf("Expected only the settings ConfigMap to be left, got %d", len(list.Items))
+	}
+	if _, err := clientset.CoreV1().ConfigMaps("apps-pr-1").Get(ctx, "kraze-discovery", metav1.GetOptions{}); err != nil {
+		test.Errorf("Expected the variant's discovery ConfigMap to be kept: %v", err)
+	}
+}
+
+func TestInjectDiscovery(test *testing.T) {
+	service := &config.ServiceConfig{Name: "api", DiscoveryConfigMap: "kraze-discovery"}
+	deployment := func(envFrom ...interface{}) *unstructured.Unstructured {
+		container := map[string]interface{}{"name": "api", "image": "api:dev"}
+		if len(envFrom) > 0 {
+			container["envFrom"] = envFrom
+		}
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"kind":     "Deployment",
+			"metadata": map[string]interface{}{"name": "api"},
+			"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+				"containers": []interface{}{container},
+			}}},
+		}}
+	}
+	discoveryRef := map[string]interface{}{"configMapRef": map[string]interface{}{"name": "kraze-discovery", "optional": true}}
+	ownRef := map[string]interface{}{"secretRef": map[string]interface{}{"name": "api-env"}}
+
+	tests := []struct {
+		name    string
+		obj     *unstructured.Unstructured
+		service *config.ServiceConfig
+		changed bool
+		envFrom []interface{}
+	}{
+		{name: "added", obj: deployment(), service: service, changed: true, envFrom: []interface{}{discoveryRef}},
+		{name: "ahead of the container's sources", obj: deployment(ownRef), service: service, changed: true, envFrom: []interface{}{discoveryRef, ownRef}},
+		{name: "already loaded", obj: deployment(discoveryRef), service: service, envFrom: []interface{}{discoveryRef}},
+		{name: "not injected", obj: deployment(), service: &config.ServiceConfig{Name: "api"}},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if changed := injectDiscovery(tt.obj, tt.service); changed != tt.changed {
+				test.Errorf("injectDiscovery() = %v, want %v", changed, tt.changed)
+			}
+			containers, _, _ := unstructured.NestedSlice(tt.obj.Object, "spec", "template", "spec", "containers")
+			envFrom, _ := containers[0].(map[string]interface{})["envFrom"].([]interface{})
+			if !reflect.DeepEqual(envFrom, tt.envFrom) {
+				test.Errorf("envFrom = %v, want %v", envFrom, tt.envFrom)
+			}
+		})
+	}
+
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{"kind": "ConfigMap"}}
+	if injectDiscovery(configMap, service) {
+		test.Error("injectDiscovery() changed a ConfigMap")
+	}
+}
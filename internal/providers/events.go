@@ -0,0 +1,243 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hjames9/kraze/internal/color"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// monitoredEventReasons are the Warning event reasons surfaced while waiting for resources.
+// Noisy startup reasons (e.g. probe failures reported as Unhealthy) are deliberately excluded.
+var monitoredEventReasons = map[string]bool{
+	"FailedScheduling":       true,
+	"FailedMount":            true,
+	"FailedAttachVolume":     true,
+	"FailedCreate":           true,
+	"FailedCreatePodSandBox": true,
+	"OOMKilling":             true,
+	"Evicted":                true,
+	"BackOff":                true,
+	"Failed":                 true,
+}
+
+// EventNotice is a Warning event (or container OOM kill) related to resources being waited on
+type EventNotice struct {
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason"`
+	Message   string `json:"message"`
+}
+
+func (notice EventNotice) String() string {
+	return fmt.Sprintf("%s %s/%s: %s", notice.Reason, notice.Kind, notice.Name, notice.Message)
+}
+
+// eventMonitor watches Warning events and pods in the namespaces of the resources
+// being waited on and reports each distinct related problem once
+type eventMonitor struct {
+	clientset kubernetes.Interface
+	notify    func(EventNotice)
+
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+// startEventMonitor starts watching for Warning events related to the given resources.
+// The returned stop function cancels the watches and waits for them to finish.
+func startEventMonitor(ctx context.Context, clientset kubernetes.Interface, resources []*unstructured.Unstructured, notify func(EventNotice)) func() {
+	monitorCtx, cancel := context.WithCancel(ctx)
+	monitor := &eventMonitor{
+		clientset: clientset,
+		notify:    notify,
+		seen:      make(map[string]bool),
+	}
+
+	namesByNamespace := make(map[string][]string)
+	for _, obj := range resources {
+		if obj.GetNamespace() == "" {
+			continue
+		}
+		namesByNamespace[obj.GetNamespace()] = append(namesByNamespace[obj.GetNamespace()], obj.GetName())
+	}
+
+	var wg sync.WaitGroup
+	for namespace, names := range namesByNamespace {
+		wg.Add(1)
+		go func(namespace string, names []string) {
+			defer wg.Done()
+			monitor.watchNamespace(monitorCtx, namespace, names)
+		}(namespace, names)
+	}
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// watchNamespace watches events and pods in one namespace until ctx is cancelled,
+// re-establishing the watches if the API server closes them
+func (monitor *eventMonitor) watchNamespace(ctx context.Context, namespace string, names []string) {
+	for ctx.Err() == nil {
+		// Start from the current resource version so only new events are reported
+		list, err := monitor.clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{Limit: 1})
+		if err != nil {
+			return
+		}
+
+		eventWatch, err := monitor.clientset.CoreV1().Events(namespace).Watch(ctx, metav1.ListOptions{
+			FieldSelector:   "type=" + corev1.EventTypeWarning,
+			ResourceVersion: list.ResourceVersion,
+		})
+		if err != nil {
+			return
+		}
+
+		podWatch, err := monitor.clientset.CoreV1().Pods(namespace).Watch(ctx, metav1.ListOptions{})
+		if err != nil {
+			eventWatch.Stop()
+			return
+		}
+
+		monitor.consume(ctx, eventWatch, podWatch, names)
+		eventWatch.Stop()
+		podWatch.Stop()
+
+		// Avoid a tight loop if the API server keeps closing the watches
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// consume handles watch results until either watch closes or ctx is cancelled
+func (monitor *eventMonitor) consume(ctx context.Context, eventWatch, podWatch watch.Interface, names []string) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case result, ok := <-eventWatch.ResultChan():
+			if !ok {
+				return
+			}
+			if event, isEvent := result.Object.(*corev1.Event); isEvent {
+				if notice, related := noticeFromEvent(event, names); related {
+					monitor.report(notice)
+				}
+			}
+		case result, ok := <-podWatch.ResultChan():
+			if !ok {
+				return
+			}
+			if pod, isPod := result.Object.(*corev1.Pod); isPod {
+				for _, notice := range oomNoticesFromPod(pod, names) {
+					monitor.report(notice)
+				}
+			}
+		}
+	}
+}
+
+// report notifies about a notice the first time it is seen
+func (monitor *eventMonitor) report(notice EventNotice) {
+	key := strings.Join([]string{notice.Namespace, notice.Kind, notice.Name, notice.Reason, notice.Message}, "/")
+
+	monitor.mu.Lock()
+	if monitor.seen[key] {
+		monitor.mu.Unlock()
+		return
+	}
+	monitor.seen[key] = true
+	monitor.mu.Unlock()
+
+	monitor.notify(notice)
+}
+
+// noticeFromEvent converts a Warning event into a notice if its reason is monitored
+// and its object belongs to one of the tracked resources
+func noticeFromEvent(event *corev1.Event, names []string) (EventNotice, bool) {
+	if event.Type != corev1.EventTypeWarning || !monitoredEventReasons[event.Reason] {
+		return EventNotice{}, false
+	}
+	if !isRelatedObjectName(event.InvolvedObject.Name, names) {
+		return EventNotice{}, false
+	}
+
+	return EventNotice{
+		Namespace: event.InvolvedObject.Namespace,
+		Kind:      event.InvolvedObject.Kind,
+		Name:      event.InvolvedObject.Name,
+		Reason:    event.Reason,
+		Message:   strings.TrimSpace(event.Message),
+	}, true
+}
+
+// oomNoticesFromPod reports containers of a tracked pod that were OOM killed.
+// The kubelet records these in the container status rather than as an event.
+func oomNoticesFromPod(pod *corev1.Pod, names []string) []EventNotice {
+	if !isRelatedObjectName(pod.Name, names) {
+		return nil
+	}
+
+	var notices []EventNotice
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		terminated := status.LastTerminationState.Terminated
+		if terminated == nil {
+			terminated = status.State.Terminated
+		}
+		if terminated == nil || terminated.Reason != "OOMKilled" {
+			continue
+		}
+		notices = append(notices, EventNotice{
+			Namespace: pod.Namespace,
+			Kind:      "Pod",
+			Name:      pod.Name,
+			Reason:    "OOMKilled",
+			Message:   fmt.Sprintf("container '%s' was killed for exceeding its memory limit (restarts: %d)", status.Name, status.RestartCount),
+		})
+	}
+	return notices
+}
+
+// isRelatedObjectName returns true if the object is one of the tracked resources or
+// is named after one (e.g. ReplicaSets and Pods created by a Deployment)
+func isRelatedObjectName(objectName string, names []string) bool {
+	for _, name := range names {
+		if objectName == name || strings.HasPrefix(objectName, name+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// monitorWarningEvents starts an event monitor for the resources using the handler from
+// opts (printing notices when no handler is set and output isn't quiet).
+// Returns a stop function; it is a no-op when there is nowhere to report to.
+func monitorWarningEvents(ctx context.Context, clientset kubernetes.Interface, resources []*unstructured.Unstructured, opts *ProviderOptions) func() {
+	notify := opts.OnWarningEvent
+	if notify == nil && !opts.Quiet {
+		notify = func(notice EventNotice) { printEventNotice(opts.output(), notice) }
+	}
+	if notify == nil {
+		return func() {}
+	}
+	return startEventMonitor(ctx, clientset, resources, notify)
+}
+
+// printEventNotice is the default notice handler used when no handler is configured
+func printEventNotice(out io.Writer, notice EventNotice) {
+	fmt.Fprintf(out, "    %s %s\n", color.Warning(), notice.String())
+}
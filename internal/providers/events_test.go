@@ -0,0 +1,120 @@
+package providers
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNoticeFromEvent(test *testing.T) {
+	names := []string{"web", "db"}
+
+	tests := []struct {
+		name     string
+		event    corev1.Event
+		expected bool
+	}{
+		{
+			name: "failed scheduling for deployment pod",
+			event: corev1.Event{
+				Type:           corev1.EventTypeWarning,
+				Reason:         "FailedScheduling",
+				InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web-7d9c6b5f4-abcde"},
+			},
+			expected: true,
+		},
+		{
+			name: "failed mount for statefulset pod",
+			event: corev1.Event{
+				Type:           corev1.EventTypeWarning,
+				Reason:         "FailedMount",
+				InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "db-0"},
+			},
+			expected: true,
+		},
+		{
+			name: "unrelated object",
+			event: corev1.Event{
+				Type:           corev1.EventTypeWarning,
+				Reason:         "FailedScheduling",
+				InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "webhook-0"},
+			},
+			expected: false,
+		},
+		{
+			name: "normal event",
+			event: corev1.Event{
+				Type:           corev1.EventTypeNormal,
+				Reason:         "Scheduled",
+				InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web-1"},
+			},
+			expected: false,
+		},
+		{
+			name: "unmonitored warning reason",
+			event: corev1.Event{
+				Type:           corev1.EventTypeWarning,
+				Reason:         "Unhealthy",
+				InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web-1"},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			_, related := noticeFromEvent(&tt.event, names)
+			if related != tt.expected {
+				test.Errorf("noticeFromEvent() related = %v, expected %v", related, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOOMNoticesFromPod(test *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-abc", Namespace: "app"},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:                 "app",
+					RestartCount:         2,
+					LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled"}},
+				},
+				{
+					Name:  "sidecar",
+					State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				},
+			},
+		},
+	}
+
+	notices := oomNoticesFromPod(pod, []string{"web"})
+	if len(notices) != 1 {
+		test.Fatalf("expected 1 notice, got %d", len(notices))
+	}
+	if notices[0].Reason != "OOMKilled" || notices[0].Name != "web-abc" {
+		test.Errorf("unexpected notice: %+v", notices[0])
+	}
+
+	if notices := oomNoticesFromPod(pod, []string{"db"}); len(notices) != 0 {
+		test.Errorf("expected no notices for unrelated pod, got %d", len(notices))
+	}
+}
+
+func TestEventMonitorReportsOnce(test *testing.T) {
+	var reported []EventNotice
+	monitor := &eventMonitor{
+		notify: func(notice EventNotice) { reported = append(reported, notice) },
+		seen:   make(map[string]bool),
+	}
+
+	notice := EventNotice{Namespace: "app", Kind: "Pod", Name: "web-1", Reason: "FailedMount", Message: "volume not found"}
+	monitor.report(notice)
+	monitor.report(notice)
+
+	if len(reported) != 1 {
+		test.Errorf("expected duplicate notices to be reported once, got %d", len(reported))
+	}
+}
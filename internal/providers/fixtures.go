@@ -0,0 +1,320 @@
+package providers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hjames9/kraze/internal/config"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+)
+
+// fixturePhase is the hook phase HTTP fixture Jobs are labelled with
+const fixturePhase = "fixture"
+
+// maxFixtureBody bounds the body of an HTTP fixture, which travels in the
+// Job's environment
+const maxFixtureBody = 256 * 1024
+
+// FixtureContext is what fixtures load into
+type FixtureContext struct {
+	ClusterName string
+	KubeConfig  string // Kubeconfig content
+	Service     *config.ServiceConfig
+
+	// OnOutput receives each line the fixture prints
+	OnOutput func(line string)
+}
+
+// FixtureChecksum hashes what a fixture loads: its settings and the content of
+// its files, but not their paths or its timeout and retries
+func FixtureChecksum(fixture *config.FixtureConfig) (string, error) {
+	settings := config.FixtureConfig{}
+	if fixture.SQL != nil {
+		sql := *fixture.SQL
+		sql.File = ""
+		settings.SQL = &sql
+	}
+	if fixture.HTTP != nil {
+		request := *fixture.HTTP
+		request.Body = ""
+		settings.HTTP = &request
+	}
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize fixture: %w", err)
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n%s\n", fixture.Kind(), data)
+	for _, path := range fixture.Files() {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read fixture file: %w", err)
+		}
+		fmt.Fprintf(hash, "%d\n", len(content))
+		hash.Write(content)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// LoadFixture loads a fixture until an attempt succeeds or its retries are
+// used up. Each attempt gets the fixture's timeout.
+func LoadFixture(ctx context.Context, fixtureCtx *FixtureContext, fixture *config.FixtureConfig) error {
+	timeout, err := fixture.GetTimeout()
+	if err != nil {
+		return fmt.Errorf("invalid timeout for fixture '%s': %w", fixture.Key(), err)
+	}
+
+	attempts := fixture.Retries + 1
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		switch {
+		case fixture.SQL != nil:
+			err = loadSQLFixture(attemptCtx, fixtureCtx, fixture.SQL)
+		case fixture.HTTP != nil:
+			err = loadHTTPFixture(attemptCtx, fixtureCtx, fixture.HTTP)
+		default:
+			err = loadManifestsFixture(attemptCtx, fixtureCtx, fixture.Manifests)
+		}
+		if err != nil && attemptCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+			err = fmt.Errorf("timed out after %v", timeout)
+		}
+		cancel()
+
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if attempt >= attempts {
+			return fmt.Errorf("fixture '%s' failed after %d attempt(s): %w", fixture.Key(), attempt, err)
+		}
+		fixtureCtx.output(fmt.Sprintf("Attempt %d/%d failed: %v, retrying", attempt, attempts, err))
+	}
+}
+
+// output passes a line of fixture output on, if anyone is listening
+func (fixtureCtx *FixtureContext) output(line string) {
+	if fixtureCtx.OnOutput != nil {
+		fixtureCtx.OnOutput(line)
+	}
+}
+
+// loadSQLFixture feeds a SQL file to the database client in the service's pod
+func loadSQLFixture(ctx context.Context, fixtureCtx *FixtureContext, sql *config.FixtureSQLConfig) error {
+	restConfig, err := GetRESTConfigFromKubeconfigContent(fixtureCtx.KubeConfig)
+	if err != nil {
+		return err
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create clientset: %w", err)
+	}
+	pod, err := fixturePod(ctx, clientset, fixtureCtx.Service)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(sql.File)
+	if err != nil {
+		return fmt.Errorf("failed to open SQL file: %w", err)
+	}
+	defer file.Close()
+
+	fixtureCtx.output(fmt.Sprintf("Running %s in %s", sql.GetEngine(), pod.Name))
+	reader, writer := io.Pipe()
+	done := make(chan string)
+	go func() {
+		done <- forwardLines(reader, fixtureCtx.output)
+	}()
+	err = execInPod(ctx, restConfig, clientset, pod, sqlFixtureCommand(sql), file, writer)
+	writer.Close()
+	<-done
+	return err
+}
+
+// sqlFixtureCommand returns the database client command reading SQL from stdin.
+// MySQL's password comes from the container's own environment.
+func sqlFixtureCommand(sql *config.FixtureSQLConfig) []string {
+	if sql.GetEngine() == config.FixtureEngineMySQL {
+		script := `MYSQL_PWD="${MYSQL_ROOT_PASSWORD:-$MYSQL_PASSWORD}" exec mysql -u "$0" ${1:+"$1"}`
+		return []string{"sh", "-c", script, sql.GetUser(), sql.Database}
+	}
+	command := []string{"psql", "-X", "-q", "-v", "ON_ERROR_STOP=1", "-U", sql.GetUser()}
+	if sql.Database != "" {
+		command = append(command, "-d", sql.Database)
+	}
+	return command
+}
+
+// fixturePod returns the service's first running pod, by name
+func fixturePod(ctx context.Context, clientset kubernetes.Interface, service *config.ServiceConfig) (*corev1.Pod, error) {
+	selector, err := ServicePodSelector(service)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := clientset.CoreV1().Pods(service.GetNamespace()).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	sort.Slice(pods.Items, func(left, right int) bool { return pods.Items[left].Name < pods.Items[right].Name })
+	for itr := range pods.Items {
+		pod := &pods.Items[itr]
+		if pod.DeletionTimestamp == nil && pod.Status.Phase == corev1.PodRunning {
+			return pod, nil
+		}
+	}
+	return nil, fmt.Errorf("no running pods found for service '%s' in namespace '%s' (selector %s)", service.Name, service.GetNamespace(), selector)
+}
+
+// loadHTTPFixture sends a request from a Job in the service's namespace, so
+// in-cluster URLs resolve
+func loadHTTPFixture(ctx context.Context, fixtureCtx *FixtureContext, request *config.FixtureHTTPConfig) error {
+	var body []byte
+	if request.Body != "" {
+		var err error
+		if body, err = os.ReadFile(request.Body); err != nil {
+			return fmt.Errorf("failed to read request body: %w", err)
+		}
+		if len(body) > maxFixtureBody {
+			return fmt.Errorf("request body is %d bytes, more than the %d an http fixture can send", len(body), maxFixtureBody)
+		}
+	}
+
+	hookCtx := &HookContext{
+		ClusterName: fixtureCtx.ClusterName,
+		KubeConfig:  fixtureCtx.KubeConfig,
+		Phase:       fixturePhase,
+		Service:     fixtureCtx.Service,
+		OnOutput:    fixtureCtx.OnOutput,
+	}
+	return runHookJob(ctx, hookCtx, &config.HookConfig{Job: httpFixtureJob(request, body)})
+}
+
+// httpFixtureJob returns the container sending a fixture's request with curl.
+// The request travels in the environment so nothing is interpolated into the script.
+func httpFixtureJob(request *config.FixtureHTTPConfig, body []byte) *config.HookJobConfig {
+	env := map[string]string{
+		"KRAZE_FIXTURE_URL":    request.URL,
+		"KRAZE_FIXTURE_METHOD": request.GetMethod(),
+	}
+	names := make([]string, 0, len(request.Headers))
+	for name := range request.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	args := []string{"curl", "-fsS", "--retry", "5", "--retry-connrefused", "-X", `"$KRAZE_FIXTURE_METHOD"`}
+	for itr, name := range names {
+		variable := fmt.Sprintf("KRAZE_FIXTURE_HEADER_%d", itr)
+		env[variable] = name + ": " + request.Headers[name]
+		args = append(args, "-H", fmt.Sprintf(`"$%s"`, variable))
+	}
+	script := strings.Join(append(args, `"$KRAZE_FIXTURE_URL"`), " ")
+	if body != nil {
+		env["KRAZE_FIXTURE_BODY"] = string(body)
+		script = `printf '%s' "$KRAZE_FIXTURE_BODY" | ` + strings.Join(append(args, "--data-binary", "@-", `"$KRAZE_FIXTURE_URL"`), " ")
+	}
+
+	return &config.HookJobConfig{
+		Image:   request.GetImage(),
+		Command: []string{"sh", "-c", script},
+		Env:     env,
+	}
+}
+
+// loadManifestsFixture applies seed manifests in the service's namespace and
+// waits for the Jobs among them to complete. Jobs can't be changed once
+// created, so a Job left by an earlier load is replaced.
+func loadManifestsFixture(ctx context.Context, fixtureCtx *FixtureContext, paths []string) error {
+	restConfig, err := GetRESTConfigFromKubeconfigContent(fixtureCtx.KubeConfig)
+	if err != nil {
+		return err
+	}
+	manifest, err := newManifestsProviderForConfig(restConfig, &ProviderOptions{KubeConfig: fixtureCtx.KubeConfig, Quiet: true, Output: io.Discard})
+	if err != nil {
+		return err
+	}
+
+	var resources []*unstructured.Unstructured
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read seed manifest: %w", err)
+		}
+		objs, err := ParseResources(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		resources = append(resources, objs...)
+	}
+	manifest.setDefaultNamespace(resources, fixtureCtx.Service.GetNamespace())
+
+	var jobs []*batchv1.Job
+	for _, obj := range resources {
+		manifest.addTrackingLabels(obj, fixtureCtx.Service)
+		isJob := obj.GroupVersionKind().GroupKind() == batchv1.SchemeGroupVersion.WithKind("Job").GroupKind()
+		if isJob {
+			if err := deleteJobAndWait(ctx, manifest.clientset, obj.GetNamespace(), obj.GetName()); err != nil {
+				return err
+			}
+		}
+		if err := manifest.applyResource(ctx, obj); err != nil {
+			return fmt.Errorf("%s/%s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		fixtureCtx.output(fmt.Sprintf("Applied %s/%s", obj.GetKind(), obj.GetName()))
+		if isJob {
+			jobs = append(jobs, &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: obj.GetName(), Namespace: obj.GetNamespace()}})
+		}
+	}
+
+	for _, job := range jobs {
+		fixtureCtx.output(fmt.Sprintf("Waiting for job %s", job.Name))
+		err := waitForHookJob(ctx, manifest.clientset, job)
+		reportHookJobLogs(manifest.clientset, job, fixtureCtx.output)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteJobAndWait deletes a Job with its pods, if it exists, and waits until it's gone
+func deleteJobAndWait(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+	jobs := clientset.BatchV1().Jobs(namespace)
+	propagation := metav1.DeletePropagationForeground
+	err := jobs.Delete(ctx, name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete job %s: %w", name, err)
+	}
+
+	ticker := time.NewTicker(hookPollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := jobs.Get(ctx, name, metav1.GetOptions{}); apierrors.IsNotFound(err) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("job %s from an earlier load wasn't deleted: %w", name, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package providers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestFixtureChecksum(test *testing.T) {
+	dir := test.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			test.Fatal(err)
+		}
+		return path
+	}
+	checksum := func(fixture *config.FixtureConfig) string {
+		sum, err := FixtureChecksum(fixture)
+		if err != nil {
+			test.Fatalf("FixtureChecksum() error = %v", err)
+		}
+		return sum
+	}
+
+	users := write("users.sql", "INSERT INTO users VALUES (1);")
+	original := checksum(&config.FixtureConfig{SQL: &config.FixtureSQLConfig{File: users}})
+
+	moved := write("moved.sql", "INSERT INTO users VALUES (1);")
+	if got := checksum(&config.FixtureConfig{SQL: &config.FixtureSQLConfig{File: moved}, Timeout: "1m", Retries: 3}); got != original {
+		test.Error("checksum changed when only the path, timeout and retries changed")
+	}
+	if got := checksum(&config.FixtureConfig{SQL: &config.FixtureSQLConfig{File: users, Database: "other"}}); got == original {
+		test.Error("checksum unchanged when the database changed")
+	}
+	write("users.sql", "INSERT INTO users VALUES (2);")
+	if got := checksum(&config.FixtureConfig{SQL: &config.FixtureSQLConfig{File: users}}); got == original {
+		test.Error("checksum unchanged when the file changed")
+	}
+
+	if _, err := FixtureChecksum(&config.FixtureConfig{Manifests: []string{filepath.Join(dir, "missing.yaml")}}); err == nil {
+		test.Error("FixtureChecksum() of a missing file succeeded")
+	}
+}
+
+func TestSQLFixtureCommand(test *testing.T) {
+	tests := []struct {
+		name     string
+		sql      config.FixtureSQLConfig
+		expected []string
+	}{
+		{
+			name:     "postgres defaults",
+			sql:      config.FixtureSQLConfig{File: "seed.sql"},
+			expected: []string{"psql", "-X", "-q", "-v", "ON_ERROR_STOP=1", "-U", "postgres"},
+		},
+		{
+			name:     "postgres database",
+			sql:      config.FixtureSQLConfig{File: "seed.sql", User: "app", Database: "shop"},
+			expected: []string{"psql", "-X", "-q", "-v", "ON_ERROR_STOP=1", "-U", "app", "-d", "shop"},
+		},
+		{
+			name:     "mysql",
+			sql:      config.FixtureSQLConfig{File: "seed.sql", Engine: "mysql", Database: "shop"},
+			expected: []string{"sh", "-c", `MYSQL_PWD="${MYSQL_ROOT_PASSWORD:-$MYSQL_PASSWORD}" exec mysql -u "$0" ${1:+"$1"}`, "root", "shop"},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := sqlFixtureCommand(&tt.sql); !reflect.DeepEqual(got, tt.expected) {
+				test.Errorf("sqlFixtureCommand() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHTTPFixtureJob(test *testing.T) {
+	request := &config.FixtureHTTPConfig{
+		URL:     "http://api.app:8080/seed",
+		Method:  "put",
+		Headers: map[string]string{"Content-Type": "application/json", "Authorization": "Bearer $TOKEN"},
+	}
+
+	job := httpFixtureJob(request, []byte(`{"name": "it's"}`))
+	if job.Image != config.DefaultFixtureHTTPImage {
+		test.Errorf("image = %q", job.Image)
+	}
+	script := job.Command[2]
+	expected := `printf '%s' "$KRAZE_FIXTURE_BODY" | curl -fsS --retry 5 --retry-connrefused -X "$KRAZE_FIXTURE_METHOD" -H "$KRAZE_FIXTURE_HEADER_0" -H "$KRAZE_FIXTURE_HEADER_1" --data-binary @- "$KRAZE_FIXTURE_URL"`
+	if script != expected {
+		test.Errorf("script = %s\nwant %s", script, expected)
+	}
+	// Values stay out of the script, so quotes and $ in them aren't interpreted
+	for name, value := range map[string]string{
+		"KRAZE_FIXTURE_METHOD":   "PUT",
+		"KRAZE_FIXTURE_HEADER_0": "Authorization: Bearer $TOKEN",
+		"KRAZE_FIXTURE_HEADER_1": "Content-Type: application/json",
+		"KRAZE_FIXTURE_BODY":     `{"name": "it's"}`,
+	} {
+		if job.Env[name] != value {
+			test.Errorf("%s = %q, want %q", name, job.Env[name], value)
+		}
+	}
+
+	withoutBody := httpFixtureJob(&config.FixtureHTTPConfig{URL: "http://api/seed"}, nil)
+	if script := withoutBody.Command[2]; strings.Contains(script, "--data-binary") {
+		test.Errorf("script without a body = %s", script)
+	}
+	if _, ok := withoutBody.Env["KRAZE_FIXTURE_BODY"]; ok {
+		test.Error("KRAZE_FIXTURE_BODY set without a body")
+	}
+}
+
+func TestDeleteJobAndWait(test *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset(&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "seed", Namespace: "data"}})
+
+	if err := deleteJobAndWait(ctx, clientset, "data", "seed"); err != nil {
+		test.Fatalf("deleteJobAndWait() error = %v", err)
+	}
+	if _, err := clientset.BatchV1().Jobs("data").Get(ctx, "seed", metav1.GetOptions{}); err == nil {
+		test.Error("job still exists")
+	}
+	if err := deleteJobAndWait(ctx, clientset, "data", "missing"); err != nil {
+		test.Errorf("deleteJobAndWait() of a missing job error = %v", err)
+	}
+}
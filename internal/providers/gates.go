@@ -0,0 +1,184 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/hjames9/kraze/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// probeImage runs tcp and http dependency probes inside the cluster
+	probeImage = "busybox:1.37"
+
+	// probeLabel marks probe pods created for dependency conditions
+	probeLabel = "kraze.probe"
+
+	// probeInterval is the pause between failed probe attempts
+	probeInterval = 2 * time.Second
+)
+
+// HelperImages returns the images of the pods kraze itself runs in the
+// cluster, which offline bundles have to carry
+func HelperImages() []string {
+	return slices.Compact([]string{probeImage, volumeHelperImage})
+}
+
+// tcpProbeScript retries until the host accepts connections on the port
+const tcpProbeScript = `until nc -z -w 2 "$PROBE_HOST" "$PROBE_PORT"; do sleep 2; done`
+
+// httpProbeScript retries until the URL returns a 2xx response
+const httpProbeScript = `until wget -q -O /dev/null -T 2 $PROBE_FLAGS "$PROBE_URL"; do sleep 2; done`
+
+// WaitForDependency waits until a dependency passes the probe of a
+// service_healthy condition, retrying until the condition's timeout. tcp and
+// http probes run from a short-lived pod in the dependency's namespace, so
+// cluster DNS names resolve as they do for workloads. exec probes run in a
+// running pod of the dependency.
+func WaitForDependency(ctx context.Context, kubeconfig string, dependency *config.ServiceConfig, condition config.DependencyCondition) error {
+	timeout, err := condition.GetTimeout()
+	if err != nil {
+		return fmt.Errorf("invalid timeout for dependency '%s': %w", dependency.Name, err)
+	}
+
+	restConfig, err := getRESTConfigFromKubeconfig(kubeconfig)
+	if err != nil {
+		return err
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create clientset: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if len(condition.Exec) > 0 {
+		err = waitForExecProbe(ctx, kubeconfig, restConfig, clientset, dependency, condition.Exec)
+	} else {
+		err = waitForProbePod(ctx, clientset, dependency, condition.Probe)
+	}
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("dependency '%s' did not pass %s within %v", dependency.Name, condition.ProbeDescription(), timeout)
+	}
+	return err
+}
+
+// waitForProbePod runs a tcp or http probe in a pod until it succeeds
+func waitForProbePod(ctx context.Context, clientset kubernetes.Interface, dependency *config.ServiceConfig, rawProbe string) error {
+	probe, err := config.ParseProbe(rawProbe)
+	if err != nil {
+		return err
+	}
+
+	pod := probePod(dependency.GetNamespace(), dependency.Name, probe)
+	created, err := clientset.CoreV1().Pods(pod.Namespace).Create(ctx, pod, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create probe pod for dependency '%s': %w", dependency.Name, err)
+	}
+	defer deleteHelperPod(clientset, created)
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		current, err := clientset.CoreV1().Pods(created.Namespace).Get(ctx, created.Name, metav1.GetOptions{})
+		if err == nil {
+			switch current.Status.Phase {
+			case corev1.PodSucceeded:
+				return nil
+			case corev1.PodFailed:
+				return fmt.Errorf("probe pod for dependency '%s' failed: %s", dependency.Name, current.Status.Message)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// probePod returns a pod that exits once the probe succeeds
+func probePod(namespace, dependencyName string, probe *config.Probe) *corev1.Pod {
+	var script string
+	var env []corev1.EnvVar
+	if probe.Scheme == "tcp" {
+		script = tcpProbeScript
+		env = []corev1.EnvVar{{Name: "PROBE_HOST", Value: probe.Host}, {Name: "PROBE_PORT", Value: probe.Port}}
+	} else {
+		script = httpProbeScript
+		env = []corev1.EnvVar{{Name: "PROBE_URL", Value: probe.URL}}
+		if probe.Scheme == "https" {
+			// Local clusters serve self-signed certificates
+			env = append(env, corev1.EnvVar{Name: "PROBE_FLAGS", Value: "--no-check-certificate"})
+		}
+	}
+
+	prefix := dependencyName
+	if len(prefix) > 40 {
+		prefix = prefix[:40]
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("kraze-probe-%s-%s", prefix, utilrand.String(5)),
+			Namespace: namespace,
+			Labels:    map[string]string{probeLabel: dependencyName, managedByLabel: "kraze"},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{{
+				Name:    "probe",
+				Image:   probeImage,
+				Command: []string{"sh", "-c", script},
+				Env:     env,
+			}},
+		},
+	}
+}
+
+// waitForExecProbe runs a command in a running pod of the dependency until it exits 0
+func waitForExecProbe(ctx context.Context, kubeconfig string, restConfig *rest.Config, clientset kubernetes.Interface, dependency *config.ServiceConfig, command []string) error {
+	for {
+		pod, err := runningServicePod(ctx, kubeconfig, clientset, dependency)
+		if err == nil {
+			if err = execInPod(ctx, restConfig, clientset, pod, command, nil, io.Discard); err == nil {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(probeInterval):
+		}
+	}
+}
+
+// runningServicePod returns a running, ready pod of a service
+func runningServicePod(ctx context.Context, kubeconfig string, clientset kubernetes.Interface, service *config.ServiceConfig) (*corev1.Pod, error) {
+	names, err := GetPodsForService(ctx, kubeconfig, service)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		pod, err := clientset.CoreV1().Pods(service.GetNamespace()).Get(ctx, name, metav1.GetOptions{})
+		if err != nil || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == corev1.PodReady && cond.Status == corev1.ConditionTrue {
+				return pod, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no ready pods found for service '%s'", service.Name)
+}
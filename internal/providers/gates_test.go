@@ -0,0 +1,102 @@
+package providers
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestProbePod(test *testing.T) {
+	tests := []struct {
+		name      string
+		probe     string
+		script    string
+		expectEnv map[string]string
+	}{
+		{
+			name:      "tcp probe",
+			probe:     "tcp://postgres:5432",
+			script:    tcpProbeScript,
+			expectEnv: map[string]string{"PROBE_HOST": "postgres", "PROBE_PORT": "5432"},
+		},
+		{
+			name:      "https probe skips certificate checks",
+			probe:     "https://api:8443/healthz",
+			script:    httpProbeScript,
+			expectEnv: map[string]string{"PROBE_URL": "https://api:8443/healthz", "PROBE_FLAGS": "--no-check-certificate"},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			probe, err := config.ParseProbe(tt.probe)
+			if err != nil {
+				test.Fatal(err)
+			}
+			pod := probePod("data", "postgres", probe)
+
+			if pod.Namespace != "data" || !strings.HasPrefix(pod.Name, "kraze-probe-postgres-") {
+				test.Errorf("pod = %s/%s, want data/kraze-probe-postgres-*", pod.Namespace, pod.Name)
+			}
+			container := pod.Spec.Containers[0]
+			if container.Command[2] != tt.script {
+				test.Errorf("script = %q, want %q", container.Command[2], tt.script)
+			}
+			env := make(map[string]string)
+			for _, itr := range container.Env {
+				env[itr.Name] = itr.Value
+			}
+			for key, value := range tt.expectEnv {
+				if env[key] != value {
+					test.Errorf("env %s = %q, want %q", key, env[key], value)
+				}
+			}
+		})
+	}
+}
+
+func TestWaitForProbePod(test *testing.T) {
+	tests := []struct {
+		name    string
+		phase   corev1.PodPhase
+		wantErr bool
+	}{
+		{name: "probe succeeds", phase: corev1.PodSucceeded},
+		{name: "probe pod fails", phase: corev1.PodFailed, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			clientset.PrependReactor("get", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				pod := &corev1.Pod{Status: corev1.PodStatus{Phase: tt.phase}}
+				return true, pod, nil
+			})
+
+			dependency := &config.ServiceConfig{Name: "postgres", Type: "helm", Namespace: "data"}
+			err := waitForProbePod(context.Background(), clientset, dependency, "tcp://postgres:5432")
+			if (err != nil) != tt.wantErr {
+				test.Errorf("waitForProbePod() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHelperImages(test *testing.T) {
+	images := HelperImages()
+	for _, image := range []string{probeImage, volumeHelperImage} {
+		if !slices.Contains(images, image) {
+			test.Errorf("HelperImages() = %v, want %s", images, image)
+		}
+	}
+	if len(slices.Compact(slices.Sorted(slices.Values(images)))) != len(images) {
+		test.Errorf("HelperImages() = %v, want each image once", images)
+	}
+}
@@ -14,13 +14,15 @@ import (
 
 	"github.com/hjames9/kraze/internal/color"
 	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/telemetry"
 	"gopkg.in/yaml.v3"
 	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/chart"
+	chartcommon "helm.sh/helm/v4/pkg/chart/common"
 	"helm.sh/helm/v4/pkg/chart/loader"
 	"helm.sh/helm/v4/pkg/cli"
 	"helm.sh/helm/v4/pkg/getter"
 	"helm.sh/helm/v4/pkg/kube"
-	"helm.sh/helm/v4/pkg/registry"
 	ri "helm.sh/helm/v4/pkg/release"
 	rcommon "helm.sh/helm/v4/pkg/release/common"
 	repov1 "helm.sh/helm/v4/pkg/repo/v1"
@@ -28,9 +30,11 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
@@ -65,7 +69,9 @@ func NewHelmProvider(opts *ProviderOptions) (*HelmProvider, error) {
 // This ensures Helm stores release metadata in the correct namespace
 func (helm *HelmProvider) getActionConfig(namespace string) (*action.Configuration, error) {
 	var logHandler slog.Handler
-	if helm.opts.Verbose {
+	if helm.opts.Verbose && helm.opts.Output != nil {
+		logHandler = slog.NewTextHandler(helm.opts.Output, nil)
+	} else if helm.opts.Verbose {
 		logHandler = slog.Default().Handler()
 	} else {
 		logHandler = slog.NewTextHandler(io.Discard, nil)
@@ -85,10 +91,7 @@ func (helm *HelmProvider) getActionConfig(namespace string) (*action.Configurati
 	}
 
 	// Initialize registry client for OCI support
-	registryClient, err := registry.NewClient(
-		registry.ClientOptDebug(helm.opts.Verbose),
-		registry.ClientOptCredentialsFile(helm.settings.RegistryConfig),
-	)
+	registryClient, err := helm.newRegistryClient()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create registry client: %w", err)
 	}
@@ -97,8 +100,23 @@ func (helm *HelmProvider) getActionConfig(namespace string) (*action.Configurati
 	return actionConfig, nil
 }
 
+// getOfflineActionConfig builds a minimal action config for work that doesn't
+// need a cluster: registry client only, no kubeconfig
+func (helm *HelmProvider) getOfflineActionConfig() (*action.Configuration, error) {
+	actionConfig := action.NewConfiguration()
+	registryClient, err := helm.newRegistryClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create registry client: %w", err)
+	}
+	actionConfig.RegistryClient = registryClient
+	return actionConfig, nil
+}
+
 // Install installs or upgrades a Helm chart (idempotent)
-func (helm *HelmProvider) Install(ctx context.Context, service *config.ServiceConfig) error {
+func (helm *HelmProvider) Install(ctx context.Context, service *config.ServiceConfig) (err error) {
+	ctx, span := telemetry.StartSpan(ctx, "helm.install", telemetry.Service(service.Name))
+	defer func() { telemetry.EndSpan(span, err) }()
+
 	// Get action config for this service's namespace
 	actionConfig, err := helm.getActionConfig(service.GetNamespace())
 	if err != nil {
@@ -111,52 +129,58 @@ func (helm *HelmProvider) Install(ctx context.Context, service *config.ServiceCo
 	_, err = histClient.Run(service.Name)
 	releaseExists := err == nil
 
-	// Get chart path - download if remote
-	chartPath, err := helm.getChartPath(ctx, service)
+	chrt, values, err := helm.loadChartAndValues(ctx, service)
 	if err != nil {
-		return fmt.Errorf("failed to get chart: %w", err)
+		return err
 	}
 
-	chart, err := loader.Load(chartPath)
+	clientset, err := kubernetes.NewForConfig(helm.restConfig)
 	if err != nil {
-		return fmt.Errorf("failed to load chart: %w", err)
+		return fmt.Errorf("failed to create clientset: %w", err)
 	}
 
-	// Load values
-	values, err := helm.loadValues(service)
-	if err != nil {
-		return fmt.Errorf("failed to load values: %w", err)
+	// The chart's pods reference the sandbox account and may mount the
+	// service's Secrets, so they must exist first
+	if service.RBAC != nil {
+		if err := ensureServiceRBAC(ctx, clientset, service); err != nil {
+			return fmt.Errorf("failed to apply RBAC sandbox: %w", err)
+		}
+	}
+	if err := ensureServiceSecrets(ctx, clientset, service); err != nil {
+		return fmt.Errorf("failed to apply secrets: %w", err)
 	}
 
 	var rel ri.Releaser
+	renderer := newPostRenderer(service, helm.opts.SuspendCronJobs)
 
 	if releaseExists {
 		// Upgrade existing release
 		upgradeClient := action.NewUpgrade(actionConfig)
 		upgradeClient.Namespace = service.GetNamespace()
-		upgradeClient.WaitStrategy = kube.HookOnlyStrategy
-		upgradeClient.WaitForJobs = false
+		upgradeClient.WaitStrategy, upgradeClient.WaitForJobs = helmWaitStrategy(service)
+		upgradeClient.PostRenderer = renderer
 
-		if helm.opts.Timeout != "" {
-			timeout, err := time.ParseDuration(helm.opts.Timeout)
-			if err == nil {
-				upgradeClient.Timeout = timeout
-			}
-		}
+		upgradeClient.Timeout = helmTimeout(helm.opts.Timeout, upgradeClient.WaitForJobs)
 
 		if service.Version != "" {
 			upgradeClient.Version = service.Version
 		}
 
 		if !helm.opts.Quiet {
-			fmt.Printf("Upgrading Helm chart '%s' in namespace '%s'...\n", service.Name, service.GetNamespace())
+			fmt.Fprintf(helm.opts.output(), "Upgrading Helm chart '%s' in namespace '%s'...\n", service.Name, service.GetNamespace())
+		}
+		rel, err = upgradeClient.RunWithContext(ctx, service.Name, chrt, values)
+		if helm.opts.Verbose && rel != nil {
+			printHookOrder(helm.opts.output(), service.Name, rel)
 		}
-		rel, err = upgradeClient.RunWithContext(ctx, service.Name, chart, values)
 		if err != nil {
+			if strings.Contains(err.Error(), "pre-upgrade") {
+				ShowFailedMigrationJobLogs(ctx, helm.opts.output(), helm.opts.KubeConfig, service.GetNamespace())
+			}
 			return fmt.Errorf("failed to upgrade chart: %w", err)
 		}
 		if !helm.opts.Quiet {
-			fmt.Printf("%s Chart '%s' upgraded successfully\n", color.Checkmark(), service.Name)
+			fmt.Fprintf(helm.opts.output(), "%s Chart '%s' upgraded successfully\n", color.Checkmark(), service.Name)
 		}
 	} else {
 		// Install new release
@@ -164,29 +188,30 @@ func (helm *HelmProvider) Install(ctx context.Context, service *config.ServiceCo
 		installClient.ReleaseName = service.Name
 		installClient.Namespace = service.GetNamespace()
 		installClient.CreateNamespace = service.ShouldCreateNamespace()
-		installClient.WaitStrategy = kube.HookOnlyStrategy
-		installClient.WaitForJobs = false
+		installClient.WaitStrategy, installClient.WaitForJobs = helmWaitStrategy(service)
+		installClient.PostRenderer = renderer
 
-		if helm.opts.Timeout != "" {
-			timeout, err := time.ParseDuration(helm.opts.Timeout)
-			if err == nil {
-				installClient.Timeout = timeout
-			}
-		}
+		installClient.Timeout = helmTimeout(helm.opts.Timeout, installClient.WaitForJobs)
 
 		if service.Version != "" {
 			installClient.Version = service.Version
 		}
 
 		if !helm.opts.Quiet {
-			fmt.Printf("Installing Helm chart '%s' in namespace '%s'...\n", service.Name, service.GetNamespace())
+			fmt.Fprintf(helm.opts.output(), "Installing Helm chart '%s' in namespace '%s'...\n", service.Name, service.GetNamespace())
+		}
+		rel, err = installClient.RunWithContext(ctx, chrt, values)
+		if helm.opts.Verbose && rel != nil {
+			printHookOrder(helm.opts.output(), service.Name, rel)
 		}
-		rel, err = installClient.RunWithContext(ctx, chart, values)
 		if err != nil {
+			if strings.Contains(err.Error(), "pre-install") {
+				ShowFailedMigrationJobLogs(ctx, helm.opts.output(), helm.opts.KubeConfig, service.GetNamespace())
+			}
 			return fmt.Errorf("failed to install chart: %w", err)
 		}
 		if !helm.opts.Quiet {
-			fmt.Printf("%s Chart '%s' installed successfully\n", color.Checkmark(), service.Name)
+			fmt.Fprintf(helm.opts.output(), "%s Chart '%s' installed successfully\n", color.Checkmark(), service.Name)
 		}
 	}
 
@@ -197,17 +222,26 @@ func (helm *HelmProvider) Install(ctx context.Context, service *config.ServiceCo
 		}
 	}
 
+	reportResourceChanges(service, postRenderChanges(renderer), helm.opts)
+
+	// Warn about settings that would leave pods Pending on this cluster
+	if manifest != "" {
+		if resources, err := parseManifestsYAML(manifest); err == nil {
+			lintRenderedWorkloads(ctx, helm.opts.KubeConfig, resources, service.GetNamespace(), helm.opts)
+		}
+	}
+
 	// Inject config checksums to force rollouts when ConfigMaps/Secrets change
 	if manifest != "" {
 		checksum, err := calculateConfigChecksum(manifest)
 		if err != nil {
 			if helm.opts.Verbose {
-				fmt.Printf("Warning: failed to calculate config checksum: %v\n", err)
+				fmt.Fprintf(helm.opts.output(), "Warning: failed to calculate config checksum: %v\n", err)
 			}
 		} else if checksum != "" {
 			if err := helm.injectConfigChecksums(ctx, service.GetNamespace(), manifest, checksum); err != nil {
 				if helm.opts.Verbose {
-					fmt.Printf("Warning: failed to inject config checksums: %v\n", err)
+					fmt.Fprintf(helm.opts.output(), "Warning: failed to inject config checksums: %v\n", err)
 				}
 			}
 		}
@@ -219,13 +253,135 @@ func (helm *HelmProvider) Install(ctx context.Context, service *config.ServiceCo
 		if err := WaitForManifestsInNamespace(ctx, helm.opts.KubeConfig, manifest, service.GetNamespace(), helm.opts); err != nil {
 			return fmt.Errorf("failed waiting for resources: %w", err)
 		}
+	} else if helm.opts.WaitForMigrations && manifest != "" {
+		// Hook Jobs are already awaited by Helm; this covers *-migrate Jobs in the release manifest
+		if err := WaitForMigrationJobsInNamespace(ctx, helm.opts.KubeConfig, manifest, service.GetNamespace(), helm.opts); err != nil {
+			return fmt.Errorf("failed waiting for migration jobs: %w", err)
+		}
 	}
 
 	return nil
 }
 
+// loadChartAndValues loads the service's chart (downloading remote charts) and its values
+func (helm *HelmProvider) loadChartAndValues(ctx context.Context, service *config.ServiceConfig) (chart.Charter, map[string]interface{}, error) {
+	// Get chart path - download if remote
+	chartPath, err := helm.getChartPath(ctx, service)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get chart: %w", err)
+	}
+
+	if service.IsLocalChart() {
+		if err := helm.buildChartDependencies(chartPath); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	chrt, err := loader.Load(chartPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load chart: %w", err)
+	}
+
+	// Load values
+	values, err := helm.loadValues(service)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load values: %w", err)
+	}
+	if service.RBAC != nil {
+		values = mergeMaps(values, serviceAccountValues(service))
+	}
+	return chrt, values, nil
+}
+
+// Render renders the chart, including CRDs and hooks, like 'helm template'.
+// Templates see the cluster's Kubernetes version and API versions, so charts
+// that pick an apiVersion by capability render what a real install would.
+// Without a cluster (see RenderOffline) charts see Helm's default capabilities
+// and render as a first install.
+func (helm *HelmProvider) Render(ctx context.Context, service *config.ServiceConfig) ([]*unstructured.Unstructured, error) {
+	offline := helm.restConfig == nil
+	var actionConfig *action.Configuration
+	var err error
+	if offline {
+		actionConfig, err = helm.getOfflineActionConfig()
+	} else {
+		actionConfig, err = helm.getActionConfig(service.GetNamespace())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	releaseExists := false
+	if !offline {
+		histClient := action.NewHistory(actionConfig)
+		histClient.Max = 1
+		_, err = histClient.Run(service.Name)
+		releaseExists = err == nil
+	}
+
+	chrt, values, err := helm.loadChartAndValues(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+
+	client := action.NewInstall(actionConfig)
+	client.DryRunStrategy = action.DryRunClient
+	client.Replace = true
+	client.ReleaseName = service.Name
+	client.Namespace = service.GetNamespace()
+	client.IsUpgrade = releaseExists
+	client.IncludeCRDs = true
+	client.PostRenderer = newPostRenderer(service, helm.opts.SuspendCronJobs)
+	if service.Version != "" {
+		client.Version = service.Version
+	}
+
+	if !offline {
+		if discoveryClient, err := discovery.NewDiscoveryClientForConfig(helm.restConfig); err == nil {
+			if serverVersion, err := discoveryClient.ServerVersion(); err == nil {
+				if kubeVersion, err := chartcommon.ParseKubeVersion(serverVersion.GitVersion); err == nil {
+					client.KubeVersion = kubeVersion
+				}
+			}
+			if apiVersions, err := action.GetVersionSet(discoveryClient); err == nil {
+				client.APIVersions = apiVersions
+			}
+		}
+	}
+
+	rel, err := client.RunWithContext(ctx, chrt, values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render chart: %w", err)
+	}
+	acc, err := ri.NewAccessor(rel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rendered release: %w", err)
+	}
+
+	manifest := acc.Manifest()
+	for _, hook := range acc.Hooks() {
+		if hookAcc, err := ri.NewHookAccessor(hook); err == nil {
+			manifest += "\n---\n" + hookAcc.Manifest()
+		}
+	}
+	resources, err := parseManifestsYAML(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	// The RBAC sandbox is applied alongside the release
+	rbacObjects, err := serviceRBACObjects(service)
+	if err != nil {
+		return nil, err
+	}
+	return append(resources, rbacObjects...), nil
+}
+
 // Uninstall removes a Helm release
-func (helm *HelmProvider) Uninstall(ctx context.Context, service *config.ServiceConfig) error {
+func (helm *HelmProvider) Uninstall(ctx context.Context, service *config.ServiceConfig) (err error) {
+	ctx, span := telemetry.StartSpan(ctx, "helm.uninstall", telemetry.Service(service.Name))
+	defer func() { telemetry.EndSpan(span, err) }()
+
 	// Get action config for this service's namespace
 	actionConfig, err := helm.getActionConfig(service.GetNamespace())
 	if err != nil {
@@ -262,36 +418,46 @@ func (helm *HelmProvider) Uninstall(ctx context.Context, service *config.Service
 	client.WaitStrategy = kube.HookOnlyStrategy
 
 	if !helm.opts.Quiet {
-		fmt.Printf("Uninstalling Helm release '%s' from namespace '%s'...\n", service.Name, service.GetNamespace())
+		fmt.Fprintf(helm.opts.output(), "Uninstalling Helm release '%s' from namespace '%s'...\n", service.Name, service.GetNamespace())
 	}
 
 	// Show CRD behavior if verbose or if CRDs will be deleted
 	if !keepCRDs {
 		if helm.opts.Verbose {
-			fmt.Printf("[HELM] CRDs will be deleted (use --keep-crds to preserve)\n")
+			fmt.Fprintf(helm.opts.output(), "[HELM] CRDs will be deleted (use --keep-crds to preserve)\n")
 		}
 	} else {
 		if helm.opts.Verbose {
-			fmt.Printf("[HELM] CRDs will be preserved\n")
+			fmt.Fprintf(helm.opts.output(), "[HELM] CRDs will be preserved\n")
 		}
 	}
 
-	// Get the release info before uninstalling to find CRDs
+	// Get the release info before uninstalling to find CRDs. Helm deletes the
+	// CRDs in the chart's templates itself, even with --keep-crds.
 	var releaseCRDs []string
-	if !keepCRDs {
-		// Get release to find associated CRDs
-		statusClient := action.NewStatus(actionConfig)
-		relRaw, err := statusClient.Run(service.Name)
-		if err == nil && relRaw != nil {
-			if acc, accErr := ri.NewAccessor(relRaw); accErr == nil {
-				if m := acc.Manifest(); m != "" {
-					// Parse manifest to find CRDs
-					releaseCRDs = helm.extractCRDsFromManifest(m)
-				}
+	statusClient := action.NewStatus(actionConfig)
+	relRaw, err := statusClient.Run(service.Name)
+	if err == nil && relRaw != nil {
+		if acc, accErr := ri.NewAccessor(relRaw); accErr == nil {
+			if m := acc.Manifest(); m != "" {
+				// Parse manifest to find CRDs
+				releaseCRDs = helm.extractCRDsFromManifest(m)
 			}
 		}
 	}
 
+	// Don't delete CRDs out from under custom resources of other services
+	// sharing the operator
+	if len(releaseCRDs) > 0 && !helm.opts.ForceCRDDeletion {
+		dynamicClient, err := dynamic.NewForConfig(helm.restConfig)
+		if err != nil {
+			return fmt.Errorf("failed to create dynamic client: %w", err)
+		}
+		if err := checkCRDsInUse(ctx, dynamicClient, service, releaseCRDs); err != nil {
+			return err
+		}
+	}
+
 	// Uninstall the release
 	_, err = client.Run(service.Name)
 	if err != nil {
@@ -299,18 +465,31 @@ func (helm *HelmProvider) Uninstall(ctx context.Context, service *config.Service
 	}
 
 	if !helm.opts.Quiet {
-		fmt.Printf("%s Release '%s' uninstalled successfully\n", color.Checkmark(), service.Name)
+		fmt.Fprintf(helm.opts.output(), "%s Release '%s' uninstalled successfully\n", color.Checkmark(), service.Name)
+	}
+
+	clientset, err := kubernetes.NewForConfig(helm.restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create clientset: %w", err)
+	}
+	if service.RBAC != nil {
+		if err := deleteServiceRBAC(ctx, clientset, service); err != nil {
+			fmt.Fprintf(helm.opts.output(), "%s Warning: Failed to delete RBAC sandbox: %v\n", color.Warning(), err)
+		}
+	}
+	if err := deleteServiceSecrets(ctx, clientset, service, nil); err != nil {
+		fmt.Fprintf(helm.opts.output(), "%s Warning: Failed to delete secrets: %v\n", color.Warning(), err)
 	}
 
 	// Delete CRDs if requested
 	if !keepCRDs && len(releaseCRDs) > 0 {
 		if helm.opts.Verbose {
-			fmt.Printf("[HELM] Deleting %d CRD(s)...\n", len(releaseCRDs))
+			fmt.Fprintf(helm.opts.output(), "[HELM] Deleting %d CRD(s)...\n", len(releaseCRDs))
 		}
 		if err := helm.deleteCRDs(ctx, releaseCRDs); err != nil {
-			fmt.Printf("%s Warning: Failed to delete some CRDs: %v\n", color.Warning(), err)
+			fmt.Fprintf(helm.opts.output(), "%s Warning: Failed to delete some CRDs: %v\n", color.Warning(), err)
 		} else if helm.opts.Verbose {
-			fmt.Printf("[HELM] CRDs deleted successfully\n")
+			fmt.Fprintf(helm.opts.output(), "[HELM] CRDs deleted successfully\n")
 		}
 	}
 
@@ -352,6 +531,33 @@ func (helm *HelmProvider) Status(ctx context.Context, service *config.ServiceCon
 	return status, nil
 }
 
+// releaseResources returns the resources of a service's installed release, or
+// renders the chart when it was applied without a release (e.g. helm template
+// piped to kubectl). Hooks are left out since they don't outlive the install.
+func (helm *HelmProvider) releaseResources(ctx context.Context, service *config.ServiceConfig) ([]*unstructured.Unstructured, error) {
+	actionConfig, err := helm.getActionConfig(service.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+
+	if relRaw, err := action.NewGet(actionConfig).Run(service.Name); err == nil {
+		acc, err := ri.NewAccessor(relRaw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read release: %w", err)
+		}
+		return parseManifestsYAML(acc.Manifest())
+	}
+
+	if helm.opts.Verbose {
+		fmt.Fprintf(helm.opts.output(), "No release '%s' found, waiting for the rendered chart\n", service.Name)
+	}
+	rendered, err := helm.Render(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+	return withoutHooks(rendered), nil
+}
+
 // IsInstalled checks if a Helm release is installed
 func (helm *HelmProvider) IsInstalled(ctx context.Context, service *config.ServiceConfig) (bool, error) {
 	// Get action config for this service's namespace
@@ -439,10 +645,7 @@ func (helm *HelmProvider) pullHTTPChart(service *config.ServiceConfig) (string,
 	actionConfig := action.NewConfiguration()
 
 	// Initialize registry client for OCI support (for consistency)
-	registryClient, err := registry.NewClient(
-		registry.ClientOptDebug(helm.opts.Verbose),
-		registry.ClientOptCredentialsFile(helm.settings.RegistryConfig),
-	)
+	registryClient, err := helm.newRegistryClient()
 	if err != nil {
 		return "", fmt.Errorf("failed to create registry client: %w", err)
 	}
@@ -460,7 +663,7 @@ func (helm *HelmProvider) pullHTTPChart(service *config.ServiceConfig) (string,
 
 	// Pull the chart
 	if helm.opts.Verbose {
-		fmt.Printf("Pulling chart '%s' from repository '%s'...\n", service.Chart, repoName)
+		fmt.Fprintf(helm.opts.output(), "Pulling chart '%s' from repository '%s'...\n", service.Chart, repoName)
 	}
 
 	_, err = pull.Run(chartRef)
@@ -498,7 +701,7 @@ func (helm *HelmProvider) pullHTTPChart(service *config.ServiceConfig) (string,
 	}
 
 	if helm.opts.Verbose {
-		fmt.Printf("Chart downloaded to: %s\n", chartPath)
+		fmt.Fprintf(helm.opts.output(), "Chart downloaded to: %s\n", chartPath)
 	}
 
 	return chartPath, nil
@@ -539,7 +742,7 @@ func (helm *HelmProvider) addHTTPRepository(repoURL string) (string, error) {
 	// Check if repository already exists
 	if file.Has(repoName) {
 		if helm.opts.Verbose {
-			fmt.Printf("Repository '%s' already exists\n", repoName)
+			fmt.Fprintf(helm.opts.output(), "Repository '%s' already exists\n", repoName)
 		}
 		return repoName, nil
 	}
@@ -553,7 +756,7 @@ func (helm *HelmProvider) addHTTPRepository(repoURL string) (string, error) {
 
 	// Download index file
 	if helm.opts.Verbose {
-		fmt.Printf("Adding Helm repository '%s' (%s)\n", repoName, repoURL)
+		fmt.Fprintf(helm.opts.output(), "Adding Helm repository '%s' (%s)\n", repoName, repoURL)
 	}
 
 	_, err = chartRepoClient.DownloadIndexFile()
@@ -570,7 +773,7 @@ func (helm *HelmProvider) addHTTPRepository(repoURL string) (string, error) {
 	}
 
 	if helm.opts.Verbose {
-		fmt.Printf("Repository '%s' added successfully\n", repoName)
+		fmt.Fprintf(helm.opts.output(), "Repository '%s' added successfully\n", repoName)
 	}
 
 	return repoName, nil
@@ -578,11 +781,12 @@ func (helm *HelmProvider) addHTTPRepository(repoURL string) (string, error) {
 
 // NewHelmProviderForPacking creates a minimal HelmProvider that can pull charts
 // without a live Kubernetes cluster. Suitable for use by the pack command.
-func NewHelmProviderForPacking(verbose bool) *HelmProvider {
+func NewHelmProviderForPacking(verbose bool, registries []config.RegistryConfig) *HelmProvider {
 	return &HelmProvider{
 		settings: cli.New(),
 		opts: &ProviderOptions{
-			Verbose: verbose,
+			Verbose:    verbose,
+			Registries: registries,
 		},
 	}
 }
@@ -595,16 +799,10 @@ func (helm *HelmProvider) PullChartToDir(service *config.ServiceConfig, destDir
 		return "", fmt.Errorf("service %q is not a remote Helm chart", service.Name)
 	}
 
-	// Build a minimal action config: registry client only, no kubeconfig.
-	actionConfig := action.NewConfiguration()
-	registryClient, err := registry.NewClient(
-		registry.ClientOptDebug(helm.opts.Verbose),
-		registry.ClientOptCredentialsFile(helm.settings.RegistryConfig),
-	)
+	actionConfig, err := helm.getOfflineActionConfig()
 	if err != nil {
-		return "", fmt.Errorf("failed to create registry client: %w", err)
+		return "", err
 	}
-	actionConfig.RegistryClient = registryClient
 
 	pull := action.NewPull(action.WithConfig(actionConfig))
 	pull.Settings = helm.settings
@@ -628,7 +826,7 @@ func (helm *HelmProvider) PullChartToDir(service *config.ServiceConfig, destDir
 	}
 
 	if helm.opts.Verbose {
-		fmt.Printf("Pulling chart %q to %s\n", chartRef, destDir)
+		fmt.Fprintf(helm.opts.output(), "Pulling chart %q to %s\n", chartRef, destDir)
 	}
 
 	if _, err = pull.Run(chartRef); err != nil {
@@ -704,16 +902,16 @@ func (helm *HelmProvider) loadValues(service *config.ServiceConfig) (map[string]
 	// Priority 1: Inline values
 	if service.ValuesInline != "" {
 		if helm.opts.Verbose {
-			fmt.Printf("Loading inline values...\n")
+			fmt.Fprintf(helm.opts.output(), "Loading inline values...\n")
 		}
 
 		// Parse inline YAML
-		if err := yaml.Unmarshal([]byte(service.ValuesInline), &values); err != nil {
+		if err := yaml.Unmarshal([]byte(service.Build.SubstituteImage(service.ValuesInline)), &values); err != nil {
 			return nil, fmt.Errorf("failed to parse inline values: %w", err)
 		}
 
 		if helm.opts.Verbose {
-			fmt.Printf("Loaded %d value(s) from inline values\n", len(values))
+			fmt.Fprintf(helm.opts.output(), "Loaded %d value(s) from inline values\n", len(values))
 		}
 
 		return values, nil
@@ -725,19 +923,19 @@ func (helm *HelmProvider) loadValues(service *config.ServiceConfig) (map[string]
 
 		if helm.opts.Verbose {
 			if len(files) == 1 {
-				fmt.Printf("Loading values from: %s\n", files[0])
+				fmt.Fprintf(helm.opts.output(), "Loading values from: %s\n", files[0])
 			} else {
-				fmt.Printf("Loading and merging values from %d file(s)...\n", len(files))
+				fmt.Fprintf(helm.opts.output(), "Loading and merging values from %d file(s)...\n", len(files))
 			}
 		}
 
 		for i, valuesFile := range files {
 			if helm.opts.Verbose && len(files) > 1 {
-				fmt.Printf("  [%d/%d] %s\n", i+1, len(files), valuesFile)
+				fmt.Fprintf(helm.opts.output(), "  [%d/%d] %s\n", i+1, len(files), valuesFile)
 			}
 
 			// Read the values file
-			data, err := os.ReadFile(valuesFile)
+			data, err := service.ReadValuesFile(valuesFile)
 			if err != nil {
 				return nil, fmt.Errorf("failed to read values file %s: %w", valuesFile, err)
 			}
@@ -754,9 +952,9 @@ func (helm *HelmProvider) loadValues(service *config.ServiceConfig) (map[string]
 
 		if helm.opts.Verbose {
 			if len(files) == 1 {
-				fmt.Printf("Loaded %d value(s) from %s\n", len(values), files[0])
+				fmt.Fprintf(helm.opts.output(), "Loaded %d value(s) from %s\n", len(values), files[0])
 			} else {
-				fmt.Printf("Loaded and merged %d total value(s) from %d file(s)\n", len(values), len(files))
+				fmt.Fprintf(helm.opts.output(), "Loaded and merged %d total value(s) from %d file(s)\n", len(values), len(files))
 			}
 		}
 
@@ -906,7 +1104,7 @@ func (helm *HelmProvider) deleteCRDs(ctx context.Context, crdNames []string) err
 	var errs []string
 	for _, crdName := range crdNames {
 		if helm.opts.Verbose {
-			fmt.Printf("[HELM] Deleting CRD: %s\n", crdName)
+			fmt.Fprintf(helm.opts.output(), "[HELM] Deleting CRD: %s\n", crdName)
 		}
 
 		err := dynamicClient.Resource(crdGVR).Delete(ctx, crdName, metav1.DeleteOptions{})
@@ -914,7 +1112,7 @@ func (helm *HelmProvider) deleteCRDs(ctx context.Context, crdNames []string) err
 			if errors.IsNotFound(err) {
 				// CRD already deleted, ignore
 				if helm.opts.Verbose {
-					fmt.Printf("[HELM] CRD %s already deleted\n", crdName)
+					fmt.Fprintf(helm.opts.output(), "[HELM] CRD %s already deleted\n", crdName)
 				}
 			} else {
 				errs = append(errs, fmt.Sprintf("%s: %v", crdName, err))
@@ -1056,7 +1254,7 @@ func (helm *HelmProvider) injectConfigChecksums(ctx context.Context, namespace s
 		}
 
 		// Use shared patching function
-		patchWorkloadWithConfigChecksum(ctx, dynamicClient, mapper, kind, name, docNamespace, checksum, helm.opts.Verbose)
+		patchWorkloadWithConfigChecksum(ctx, dynamicClient, mapper, kind, name, docNamespace, checksum, helm.opts.output(), helm.opts.Verbose)
 	}
 
 	return nil
@@ -0,0 +1,108 @@
+package providers
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hjames9/kraze/internal/config"
+	"helm.sh/helm/v4/pkg/kube"
+	ri "helm.sh/helm/v4/pkg/release"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+// defaultHelmWaitTimeout is how long Helm waits for a release when no timeout
+// is set, the same as the helm CLI's default
+const defaultHelmWaitTimeout = 5 * time.Minute
+
+// hookEventOrder is the order Helm fires hook events in during installs and upgrades
+var hookEventOrder = []release.HookEvent{
+	release.HookPreInstall,
+	release.HookPostInstall,
+	release.HookPreUpgrade,
+	release.HookPostUpgrade,
+}
+
+// helmWaitStrategy returns how Helm waits during an install or upgrade. By
+// default kraze waits for the release's resources itself and Helm only waits for
+// hooks, so post-install hooks run as soon as the resources are applied. With
+// wait_for_jobs Helm waits for the resources and Jobs first, like 'helm --wait
+// --wait-for-jobs', for charts whose post hooks need the release running.
+func helmWaitStrategy(service *config.ServiceConfig) (kube.WaitStrategy, bool) {
+	if service.ShouldWaitForJobs() {
+		return kube.StatusWatcherStrategy, true
+	}
+	return kube.HookOnlyStrategy, false
+}
+
+// helmTimeout parses the timeout of a Helm operation, falling back to the helm
+// CLI's default when Helm waits for the release and no valid timeout is set
+func helmTimeout(timeout string, waitForJobs bool) time.Duration {
+	if parsed, err := time.ParseDuration(timeout); err == nil {
+		return parsed
+	}
+	if waitForJobs {
+		return defaultHelmWaitTimeout
+	}
+	return 0
+}
+
+// orderedHooks returns the release's hooks that fire on event in the order
+// Helm runs them: by weight, then by name, keeping Helm's kind order otherwise
+func orderedHooks(hooks []*release.Hook, event release.HookEvent) []*release.Hook {
+	var ordered []*release.Hook
+	for _, hook := range hooks {
+		for _, hookEvent := range hook.Events {
+			if hookEvent == event {
+				ordered = append(ordered, hook)
+				break
+			}
+		}
+	}
+	sort.SliceStable(ordered, func(left, right int) bool {
+		if ordered[left].Weight == ordered[right].Weight {
+			return ordered[left].Name < ordered[right].Name
+		}
+		return ordered[left].Weight < ordered[right].Weight
+	})
+	return ordered
+}
+
+// printHookOrder prints the release's install and upgrade hooks in the order
+// Helm runs them, with their weights, delete policies and last run
+func printHookOrder(out io.Writer, serviceName string, rel ri.Releaser) {
+	v1, ok := rel.(*release.Release)
+	if !ok || len(v1.Hooks) == 0 {
+		return
+	}
+
+	var lines []string
+	for _, event := range hookEventOrder {
+		hooks := orderedHooks(v1.Hooks, event)
+		if len(hooks) == 0 {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("  %s:", event))
+		for _, hook := range hooks {
+			line := fmt.Sprintf("    %s/%s (weight %d", strings.ToLower(hook.Kind), hook.Name, hook.Weight)
+			if len(hook.DeletePolicies) > 0 {
+				policies := make([]string, len(hook.DeletePolicies))
+				for itr, policy := range hook.DeletePolicies {
+					policies[itr] = string(policy)
+				}
+				line += ", delete " + strings.Join(policies, ",")
+			}
+			line += ")"
+			if phase := hook.LastRun.Phase; phase != release.HookPhaseUnknown && phase != "" {
+				line += " " + string(phase)
+			}
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "Helm hooks of '%s' (in the order Helm runs them):\n%s\n", serviceName, strings.Join(lines, "\n"))
+}
@@ -0,0 +1,89 @@
+package providers
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hjames9/kraze/internal/config"
+	"helm.sh/helm/v4/pkg/kube"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func TestOrderedHooks(test *testing.T) {
+	hooks := []*release.Hook{
+		{Name: "seed", Kind: "Job", Weight: 10, Events: []release.HookEvent{release.HookPostInstall, release.HookPostUpgrade}},
+		{Name: "settings", Kind: "ConfigMap", Weight: -5, Events: []release.HookEvent{release.HookPreInstall}},
+		{Name: "migrate", Kind: "Job", Weight: 0, Events: []release.HookEvent{release.HookPreInstall, release.HookPreUpgrade}},
+		{Name: "backup", Kind: "Job", Weight: 0, Events: []release.HookEvent{release.HookPreInstall}},
+		{Name: "smoke", Kind: "Pod", Events: []release.HookEvent{release.HookTest}},
+	}
+
+	tests := []struct {
+		event    release.HookEvent
+		expected []string
+	}{
+		{event: release.HookPreInstall, expected: []string{"settings", "backup", "migrate"}},
+		{event: release.HookPostInstall, expected: []string{"seed"}},
+		{event: release.HookPreUpgrade, expected: []string{"migrate"}},
+		{event: release.HookPreDelete},
+	}
+
+	for _, tt := range tests {
+		test.Run(string(tt.event), func(test *testing.T) {
+			var names []string
+			for _, hook := range orderedHooks(hooks, tt.event) {
+				names = append(names, hook.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.expected, ",") {
+				test.Errorf("orderedHooks() = %v, want %v", names, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPrintHookOrder(test *testing.T) {
+	rel := &release.Release{Hooks: []*release.Hook{
+		{Name: "seed", Kind: "Job", Weight: 5, Events: []release.HookEvent{release.HookPostInstall},
+			DeletePolicies: []release.HookDeletePolicy{release.HookSucceeded}, LastRun: release.HookExecution{Phase: release.HookPhaseFailed}},
+		{Name: "migrate", Kind: "Job", Weight: -1, Events: []release.HookEvent{release.HookPreInstall}},
+	}}
+
+	var out bytes.Buffer
+	printHookOrder(&out, "api", rel)
+	expected := "Helm hooks of 'api' (in the order Helm runs them):\n" +
+		"  pre-install:\n    job/migrate (weight -1)\n" +
+		"  post-install:\n    job/seed (weight 5, delete hook-succeeded) Failed\n"
+	if out.String() != expected {
+		test.Errorf("printHookOrder() =\n%s\nwant\n%s", out.String(), expected)
+	}
+
+	out.Reset()
+	printHookOrder(&out, "api", &release.Release{Hooks: []*release.Hook{{Name: "smoke", Kind: "Pod", Events: []release.HookEvent{release.HookTest}}}})
+	if out.Len() != 0 {
+		test.Errorf("printHookOrder() with only test hooks = %q, want nothing", out.String())
+	}
+}
+
+func TestHelmWaitStrategy(test *testing.T) {
+	enabled := true
+	strategy, waitForJobs := helmWaitStrategy(&config.ServiceConfig{Name: "api", Type: "helm"})
+	if strategy != kube.HookOnlyStrategy || waitForJobs {
+		test.Errorf("helmWaitStrategy() = %s, %v, want hook-only without jobs", strategy, waitForJobs)
+	}
+	strategy, waitForJobs = helmWaitStrategy(&config.ServiceConfig{Name: "api", Type: "helm", WaitForJobs: &enabled})
+	if strategy != kube.StatusWatcherStrategy || !waitForJobs {
+		test.Errorf("helmWaitStrategy() with wait_for_jobs = %s, %v, want the status watcher with jobs", strategy, waitForJobs)
+	}
+
+	if timeout := helmTimeout("", true); timeout != defaultHelmWaitTimeout {
+		test.Errorf("helmTimeout() = %s, want the default", timeout)
+	}
+	if timeout := helmTimeout("2m", true); timeout != 2*time.Minute {
+		test.Errorf("helmTimeout(2m) = %s", timeout)
+	}
+	if timeout := helmTimeout("", false); timeout != 0 {
+		test.Errorf("helmTimeout() without waiting = %s, want 0", timeout)
+	}
+}
@@ -0,0 +1,293 @@
+package providers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	osexec "os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hjames9/kraze/internal/config"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// hookLabel marks Jobs kraze runs as hooks, set to the hook phase
+	hookLabel = "kraze.hook"
+
+	// hookPollInterval is the pause between checks of a hook Job's status
+	hookPollInterval = time.Second
+
+	// hookLogLines is how many log lines of a hook Job are reported
+	hookLogLines = 50
+)
+
+// HookContext is what a hook runs against
+type HookContext struct {
+	ClusterName string
+	KubeConfig  string // Kubeconfig content
+	Phase       string // One of the config.Hook* phases
+	Service     *config.ServiceConfig
+
+	// OnOutput receives each line the hook prints
+	OnOutput func(line string)
+}
+
+// RunHook runs a hook until an attempt succeeds or its retries are used up.
+// Each attempt gets the hook's timeout.
+func RunHook(ctx context.Context, hookCtx *HookContext, hook *config.HookConfig) error {
+	timeout, err := hook.GetTimeout()
+	if err != nil {
+		return fmt.Errorf("invalid timeout for hook '%s': %w", hook.Description(), err)
+	}
+
+	attempts := hook.Retries + 1
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		if hook.Job != nil {
+			err = runHookJob(attemptCtx, hookCtx, hook)
+		} else {
+			err = runHookCommand(attemptCtx, hookCtx, hook)
+		}
+		if err != nil && attemptCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+			err = fmt.Errorf("timed out after %v", timeout)
+		}
+		cancel()
+
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if attempt >= attempts {
+			return fmt.Errorf("%s hook '%s' failed after %d attempt(s): %w", hookCtx.Phase, hook.Description(), attempt, err)
+		}
+		hookCtx.output(fmt.Sprintf("Attempt %d/%d failed: %v, retrying", attempt, attempts, err))
+	}
+}
+
+// output passes a line of hook output on, if anyone is listening
+func (hookCtx *HookContext) output(line string) {
+	if hookCtx.OnOutput != nil {
+		hookCtx.OnOutput(line)
+	}
+}
+
+// env returns the KRAZE_* variables every hook gets
+func (hookCtx *HookContext) env() map[string]string {
+	return map[string]string{
+		"KRAZE_CLUSTER":   hookCtx.ClusterName,
+		"KRAZE_SERVICE":   hookCtx.Service.Name,
+		"KRAZE_NAMESPACE": hookCtx.Service.GetNamespace(),
+		"KRAZE_HOOK":      hookCtx.Phase,
+	}
+}
+
+// runHookCommand runs a local hook command with KUBECONFIG pointing at the cluster
+func runHookCommand(ctx context.Context, hookCtx *HookContext, hook *config.HookConfig) error {
+	kubeconfigFile, err := os.CreateTemp("", "kraze-hook-kubeconfig-*")
+	if err != nil {
+		return fmt.Errorf("failed to create kubeconfig for hook: %w", err)
+	}
+	defer os.Remove(kubeconfigFile.Name())
+	if _, err := kubeconfigFile.WriteString(hookCtx.KubeConfig); err != nil {
+		kubeconfigFile.Close()
+		return fmt.Errorf("failed to write kubeconfig for hook: %w", err)
+	}
+	kubeconfigFile.Close()
+
+	var cmd *osexec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = osexec.CommandContext(ctx, "cmd", "/C", hook.Command)
+	} else {
+		cmd = osexec.CommandContext(ctx, "sh", "-c", hook.Command)
+	}
+	cmd.Dir = hook.Dir
+	cmd.Env = append(os.Environ(), "KUBECONFIG="+kubeconfigFile.Name())
+	for name, value := range hookCtx.env() {
+		cmd.Env = append(cmd.Env, name+"="+value)
+	}
+	// Don't wait forever for background processes holding the output open
+	cmd.WaitDelay = 5 * time.Second
+
+	reader, writer := io.Pipe()
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+	done := make(chan string)
+	go func() {
+		done <- forwardLines(reader, hookCtx.output)
+	}()
+
+	err = cmd.Run()
+	writer.Close()
+	lastLine := <-done
+	if err != nil && lastLine != "" {
+		return fmt.Errorf("%w: %s", err, lastLine)
+	}
+	return err
+}
+
+// forwardLines passes each line read to output and returns the last non-empty one
+func forwardLines(reader io.Reader, output func(line string)) string {
+	var lastLine string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		output(line)
+		lastLine = line
+	}
+	// Keep draining so the command never blocks on a full pipe
+	_, _ = io.Copy(io.Discard, reader)
+	return lastLine
+}
+
+// runHookJob runs a hook Job in the service's namespace, reports its logs once
+// it finishes and deletes it
+func runHookJob(ctx context.Context, hookCtx *HookContext, hook *config.HookConfig) error {
+	clientset, err := GetClientsetFromKubeconfigContent(hookCtx.KubeConfig)
+	if err != nil {
+		return err
+	}
+
+	service := hookCtx.Service
+	if service.ShouldCreateNamespace() {
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: service.GetNamespace()}}
+		if _, err := clientset.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create namespace: %w", err)
+		}
+	}
+
+	job := hookJob(hookCtx, hook.Job)
+	created, err := clientset.BatchV1().Jobs(job.Namespace).Create(ctx, job, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create hook job: %w", err)
+	}
+	hookCtx.output(fmt.Sprintf("Started job %s", created.Name))
+	defer deleteHookJob(clientset, created)
+
+	waitErr := waitForHookJob(ctx, clientset, created)
+	reportHookJobLogs(clientset, created, hookCtx.output)
+	return waitErr
+}
+
+// hookJob builds the Job of a hook. kraze retries hooks itself, so the Job
+// doesn't.
+func hookJob(hookCtx *HookContext, jobCfg *config.HookJobConfig) *batchv1.Job {
+	prefix := hookCtx.Service.Name
+	if len(prefix) > 30 {
+		prefix = prefix[:30]
+	}
+	phase := strings.ReplaceAll(hookCtx.Phase, "_", "-")
+
+	env := hookCtx.env()
+	for name, value := range jobCfg.Env {
+		env[name] = value
+	}
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	envVars := make([]corev1.EnvVar, 0, len(names))
+	for _, name := range names {
+		envVars = append(envVars, corev1.EnvVar{Name: name, Value: env[name]})
+	}
+
+	labels := map[string]string{
+		managedByLabel: "kraze",
+		serviceLabel:   hookCtx.Service.Name,
+		hookLabel:      phase,
+	}
+	backoffLimit := int32(0)
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("kraze-hook-%s-%s-%s", prefix, phase, utilrand.String(5)),
+			Namespace: hookCtx.Service.GetNamespace(),
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ServiceAccountName: jobCfg.ServiceAccount,
+					Containers: []corev1.Container{{
+						Name:    "hook",
+						Image:   jobCfg.Image,
+						Command: jobCfg.Command,
+						Args:    jobCfg.Args,
+						Env:     envVars,
+					}},
+				},
+			},
+		},
+	}
+}
+
+// waitForHookJob waits until a hook Job succeeds or fails
+func waitForHookJob(ctx context.Context, clientset kubernetes.Interface, job *batchv1.Job) error {
+	ticker := time.NewTicker(hookPollInterval)
+	defer ticker.Stop()
+	for {
+		current, err := clientset.BatchV1().Jobs(job.Namespace).Get(ctx, job.Name, metav1.GetOptions{})
+		if err == nil {
+			if current.Status.Succeeded > 0 {
+				return nil
+			}
+			if current.Status.Failed > 0 || isTypedJobFailed(current) {
+				return fmt.Errorf("job %s failed", job.Name)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// reportHookJobLogs passes the logs of a hook Job's pod to output
+func reportHookJobLogs(clientset kubernetes.Interface, job *batchv1.Job, output func(line string)) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pods, err := clientset.CoreV1().Pods(job.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("job-name=%s", job.Name),
+	})
+	if err != nil {
+		return
+	}
+	tailLines := int64(hookLogLines)
+	for _, pod := range pods.Items {
+		logs, err := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{TailLines: &tailLines}).Stream(ctx)
+		if err != nil {
+			continue
+		}
+		forwardLines(logs, output)
+		logs.Close()
+	}
+}
+
+// deleteHookJob deletes a hook Job and its pods
+func deleteHookJob(clientset kubernetes.Interface, job *batchv1.Job) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	propagation := metav1.DeletePropagationBackground
+	_ = clientset.BatchV1().Jobs(job.Namespace).Delete(ctx, job.Name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+}
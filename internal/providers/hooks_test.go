@@ -0,0 +1,134 @@
+package providers
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRunHookCommand(test *testing.T) {
+	if runtime.GOOS == "windows" {
+		test.Skip("hook commands use sh in this test")
+	}
+
+	tests := []struct {
+		name     string
+		hook     config.HookConfig
+		wantErr  string
+		wantLine string
+	}{
+		{
+			name:     "environment",
+			hook:     config.HookConfig{Command: `echo "$KRAZE_HOOK $KRAZE_SERVICE $KRAZE_NAMESPACE $KRAZE_CLUSTER"; test -s "$KUBECONFIG"`},
+			wantLine: "post_install api data dev",
+		},
+		{
+			name:     "succeeds on retry",
+			hook:     config.HookConfig{Command: `test -f marker || { touch marker; echo first; exit 1; }`, Retries: 1},
+			wantLine: "Attempt 1/2 failed: exit status 1: first, retrying",
+		},
+		{
+			name:    "fails after retries",
+			hook:    config.HookConfig{Name: "migrate", Command: "echo broken >&2; exit 3", Retries: 1},
+			wantErr: "post_install hook 'migrate' failed after 2 attempt(s): exit status 3: broken",
+		},
+		{
+			name:    "timeout",
+			hook:    config.HookConfig{Command: "exec sleep 5", Timeout: "100ms"},
+			wantErr: "timed out after 100ms",
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			hook := tt.hook
+			hook.Dir = test.TempDir()
+			var lines []string
+			hookCtx := &HookContext{
+				ClusterName: "dev",
+				KubeConfig:  "apiVersion: v1\nkind: Config\n",
+				Phase:       config.HookPostInstall,
+				Service:     &config.ServiceConfig{Name: "api", Type: "manifests", Namespace: "data"},
+				OnOutput:    func(line string) { lines = append(lines, line) },
+			}
+
+			err := RunHook(context.Background(), hookCtx, &hook)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					test.Errorf("RunHook() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				test.Fatalf("RunHook() error = %v", err)
+			}
+			if !strings.Contains(strings.Join(lines, "\n"), tt.wantLine) {
+				test.Errorf("RunHook() output = %q, want %q", lines, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestHookJob(test *testing.T) {
+	hookCtx := &HookContext{
+		ClusterName: "dev",
+		Phase:       config.HookPreInstall,
+		Service:     &config.ServiceConfig{Name: "api", Type: "manifests", Namespace: "data"},
+	}
+	job := hookJob(hookCtx, &config.HookJobConfig{
+		Image:          "migrate/migrate:v4",
+		Args:           []string{"up"},
+		Env:            map[string]string{"DATABASE_URL": "postgres://db", "KRAZE_HOOK": "overridden"},
+		ServiceAccount: "migrator",
+	})
+
+	if !strings.HasPrefix(job.Name, "kraze-hook-api-pre-install-") || job.Namespace != "data" {
+		test.Errorf("job = %s/%s, want a kraze-hook-api-pre-install-* job in data", job.Namespace, job.Name)
+	}
+	if job.Labels[hookLabel] != "pre-install" || job.Labels[serviceLabel] != "api" {
+		test.Errorf("labels = %v, want the hook and service labels", job.Labels)
+	}
+	if *job.Spec.BackoffLimit != 0 {
+		test.Errorf("backoffLimit = %d, want 0 so kraze owns the retries", *job.Spec.BackoffLimit)
+	}
+	pod := job.Spec.Template.Spec
+	if pod.ServiceAccountName != "migrator" || pod.Containers[0].Image != "migrate/migrate:v4" {
+		test.Errorf("pod spec = %+v, want the hook's image and service account", pod)
+	}
+	var env []string
+	for _, envVar := range pod.Containers[0].Env {
+		env = append(env, envVar.Name+"="+envVar.Value)
+	}
+	expected := "DATABASE_URL=postgres://db,KRAZE_CLUSTER=dev,KRAZE_HOOK=overridden,KRAZE_NAMESPACE=data,KRAZE_SERVICE=api"
+	if strings.Join(env, ",") != expected {
+		test.Errorf("env = %s, want %s", strings.Join(env, ","), expected)
+	}
+}
+
+func TestWaitForHookJob(test *testing.T) {
+	tests := []struct {
+		name    string
+		status  batchv1.JobStatus
+		wantErr bool
+	}{
+		{name: "succeeded", status: batchv1.JobStatus{Succeeded: 1}},
+		{name: "failed", status: batchv1.JobStatus{Failed: 1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "kraze-hook-api", Namespace: "data"}, Status: tt.status}
+			clientset := fake.NewSimpleClientset(job)
+			err := waitForHookJob(context.Background(), clientset, job)
+			if (err != nil) != tt.wantErr {
+				test.Errorf("waitForHookJob() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hjames9/kraze/internal/config"
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/kube"
+	ri "helm.sh/helm/v4/pkg/release"
+	rcommon "helm.sh/helm/v4/pkg/release/common"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// interruptRecoveryTimeout bounds how long rolling back an interrupted release may take
+const interruptRecoveryTimeout = 2 * time.Minute
+
+// releaseRevision is a revision of a Helm release with its status
+type releaseRevision struct {
+	Revision int
+	Status   rcommon.Status
+}
+
+// ReleaseRecovery is how RecoverInterruptedRelease cleaned up a release
+type ReleaseRecovery struct {
+	Uninstalled bool // The release was a first install and was removed
+	Revision    int  // The revision the release was rolled back to
+}
+
+// String describes the recovery for messages
+func (recovery *ReleaseRecovery) String() string {
+	if recovery.Uninstalled {
+		return "uninstalled the partial release"
+	}
+	return fmt.Sprintf("rolled back to revision %d", recovery.Revision)
+}
+
+// RecoverInterruptedRelease cleans up a service's Helm release after its install
+// or upgrade was interrupted, so it isn't left pending (which blocks the next
+// install with "another operation is in progress"). An upgrade is rolled back to
+// the last deployed revision, and a first install is uninstalled. Returns nil
+// when the release was already deployed or never created.
+func RecoverInterruptedRelease(service *config.ServiceConfig, opts *ProviderOptions) (*ReleaseRecovery, error) {
+	helm, err := NewHelmProvider(opts)
+	if err != nil {
+		return nil, err
+	}
+	actionConfig, err := helm.getActionConfig(service.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := action.NewHistory(actionConfig).Run(service.Name)
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read release history: %w", err)
+	}
+	revisions := make([]releaseRevision, 0, len(history))
+	for _, rel := range history {
+		acc, err := ri.NewAccessor(rel)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read release: %w", err)
+		}
+		revisions = append(revisions, releaseRevision{Revision: acc.Version(), Status: rcommon.Status(acc.Status())})
+	}
+
+	target, interrupted := rollbackTarget(revisions)
+	if !interrupted {
+		return nil, nil
+	}
+	if target == 0 {
+		uninstall := action.NewUninstall(actionConfig)
+		uninstall.DisableHooks = true
+		uninstall.KeepHistory = false
+		uninstall.WaitStrategy = kube.HookOnlyStrategy
+		uninstall.Timeout = interruptRecoveryTimeout
+		if _, err := uninstall.Run(service.Name); err != nil {
+			return nil, fmt.Errorf("failed to uninstall interrupted release: %w", err)
+		}
+		return &ReleaseRecovery{Uninstalled: true}, nil
+	}
+
+	rollback := action.NewRollback(actionConfig)
+	rollback.Version = target
+	rollback.WaitStrategy = kube.HookOnlyStrategy
+	rollback.Timeout = interruptRecoveryTimeout
+	if err := rollback.Run(service.Name); err != nil {
+		return nil, fmt.Errorf("failed to roll back interrupted release to revision %d: %w", target, err)
+	}
+	return &ReleaseRecovery{Revision: target}, nil
+}
+
+// rollbackTarget returns whether the latest revision of a release was left
+// unfinished, and the revision to roll back to: the latest one that was
+// deployed before it, or 0 when none was and the release should be removed
+func rollbackTarget(revisions []releaseRevision) (int, bool) {
+	latest := releaseRevision{}
+	for _, revision := range revisions {
+		if revision.Revision > latest.Revision {
+			latest = revision
+		}
+	}
+	if latest.Revision == 0 || latest.Status == rcommon.StatusDeployed {
+		return 0, false
+	}
+
+	target := 0
+	for _, revision := range revisions {
+		if revision.Revision >= latest.Revision || revision.Revision <= target {
+			continue
+		}
+		if revision.Status == rcommon.StatusDeployed || revision.Status == rcommon.StatusSuperseded {
+			target = revision.Revision
+		}
+	}
+	return target, true
+}
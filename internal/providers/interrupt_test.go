@@ -0,0 +1,53 @@
+package providers
+
+import (
+	"testing"
+
+	rcommon "helm.sh/helm/v4/pkg/release/common"
+)
+
+func TestRollbackTarget(test *testing.T) {
+	tests := []struct {
+		name            string
+		revisions       []releaseRevision
+		wantTarget      int
+		wantInterrupted bool
+	}{
+		{name: "no release"},
+		{
+			name:      "deployed",
+			revisions: []releaseRevision{{1, rcommon.StatusSuperseded}, {2, rcommon.StatusDeployed}},
+		},
+		{
+			name:            "first install pending",
+			revisions:       []releaseRevision{{1, rcommon.StatusPendingInstall}},
+			wantInterrupted: true,
+		},
+		{
+			name:            "first install failed",
+			revisions:       []releaseRevision{{1, rcommon.StatusFailed}},
+			wantInterrupted: true,
+		},
+		{
+			name:            "upgrade pending",
+			revisions:       []releaseRevision{{1, rcommon.StatusSuperseded}, {2, rcommon.StatusDeployed}, {3, rcommon.StatusPendingUpgrade}},
+			wantTarget:      2,
+			wantInterrupted: true,
+		},
+		{
+			name:            "skips failed revisions",
+			revisions:       []releaseRevision{{3, rcommon.StatusFailed}, {1, rcommon.StatusDeployed}, {4, rcommon.StatusFailed}, {2, rcommon.StatusFailed}},
+			wantTarget:      1,
+			wantInterrupted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			target, interrupted := rollbackTarget(tt.revisions)
+			if target != tt.wantTarget || interrupted != tt.wantInterrupted {
+				test.Errorf("rollbackTarget() = %d, %v, want %d, %v", target, interrupted, tt.wantTarget, tt.wantInterrupted)
+			}
+		})
+	}
+}
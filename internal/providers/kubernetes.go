@@ -7,12 +7,14 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/hjames9/kraze/internal/config"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/portforward"
 	"k8s.io/client-go/transport/spdy"
@@ -40,9 +42,24 @@ func GetClientsetFromKubeconfig(kubeconfigPath string) (kubernetes.Interface, er
 }
 
 // GetClientsetFromKubeconfigContent creates a Kubernetes clientset from kubeconfig content (YAML string)
-// skipTLSVerify should only be set to true for local kind clusters where IPs may be patched for custom networks.
-// For external clusters (Docker Desktop, Minikube, etc.) this should be false to maintain proper TLS verification.
-func GetClientsetFromKubeconfigContent(kubeconfigContent string, skipTLSVerify bool) (kubernetes.Interface, error) {
+func GetClientsetFromKubeconfigContent(kubeconfigContent string) (kubernetes.Interface, error) {
+	restConfig, err := GetRESTConfigFromKubeconfigContent(kubeconfigContent)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create clientset
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+
+	return clientset, nil
+}
+
+// GetRESTConfigFromKubeconfigContent creates a REST config from kubeconfig content.
+// TLS is verified: kind's API server certificate covers the addresses kraze connects to.
+func GetRESTConfigFromKubeconfigContent(kubeconfigContent string) (*rest.Config, error) {
 	if kubeconfigContent == "" {
 		return nil, fmt.Errorf("kubeconfig content is empty")
 	}
@@ -59,26 +76,40 @@ func GetClientsetFromKubeconfigContent(kubeconfigContent string, skipTLSVerify b
 		return nil, fmt.Errorf("failed to create REST config: %w", err)
 	}
 
-	// Skip TLS verification only for kind clusters where IP patching may cause cert hostname mismatches
-	// This is safe for local development but should NEVER be used for external/production clusters
-	if skipTLSVerify {
-		restConfig.TLSClientConfig.Insecure = true
-		restConfig.TLSClientConfig.CAData = nil
-		restConfig.TLSClientConfig.CAFile = ""
+	return restConfig, nil
+}
+
+// ImpersonateKubeconfig returns kubeconfig content whose current user impersonates
+// the given user and groups (like kubectl --as/--as-group). Every REST config built
+// from it, including the Helm provider's, sends the impersonation headers.
+func ImpersonateKubeconfig(kubeconfigContent, user string, groups []string) (string, error) {
+	if user == "" {
+		return kubeconfigContent, nil
 	}
 
-	// Create clientset
-	clientset, err := kubernetes.NewForConfig(restConfig)
+	kubeconfig, err := clientcmd.Load([]byte(kubeconfigContent))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create clientset: %w", err)
+		return "", fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+	context, exists := kubeconfig.Contexts[kubeconfig.CurrentContext]
+	if !exists {
+		return "", fmt.Errorf("kubeconfig has no current context")
 	}
+	authInfo, exists := kubeconfig.AuthInfos[context.AuthInfo]
+	if !exists {
+		return "", fmt.Errorf("kubeconfig user '%s' not found", context.AuthInfo)
+	}
+	authInfo.Impersonate = user
+	authInfo.ImpersonateGroups = groups
 
-	return clientset, nil
+	content, err := clientcmd.Write(*kubeconfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize kubeconfig: %w", err)
+	}
+	return string(content), nil
 }
 
 // GetPodsForService returns pod names for a given service
-// For Helm services: uses helm release labels
-// For manifest services: uses user-specified labels or service name
 func GetPodsForService(ctx context.Context, kubeconfig string, service *config.ServiceConfig) ([]string, error) {
 	restConfig, err := getRESTConfigFromKubeconfig(kubeconfig)
 	if err != nil {
@@ -92,27 +123,9 @@ func GetPodsForService(ctx context.Context, kubeconfig string, service *config.S
 
 	namespace := service.GetNamespace()
 
-	// Build label selector based on service type
-	var labelSelector string
-	switch service.Type {
-	case "helm":
-		// Helm uses app.kubernetes.io/instance=<release-name>
-		labelSelector = fmt.Sprintf("app.kubernetes.io/instance=%s", service.Name)
-	case "manifests":
-		// For manifests, try to use user-specified labels or fallback to app label
-		if len(service.Labels) > 0 {
-			// Build selector from service labels
-			selectors := make([]string, 0, len(service.Labels))
-			for key, value := range service.Labels {
-				selectors = append(selectors, fmt.Sprintf("%s=%s", key, value))
-			}
-			labelSelector = strings.Join(selectors, ",")
-		} else {
-			// Fallback to app=service-name
-			labelSelector = fmt.Sprintf("app=%s", service.Name)
-		}
-	default:
-		return nil, fmt.Errorf("unsupported service type: %s", service.Type)
+	labelSelector, err := ServicePodSelector(service)
+	if err != nil {
+		return nil, err
 	}
 
 	// List pods with the label selector
@@ -146,6 +159,32 @@ func GetPodsForService(ctx context.Context, kubeconfig string, service *config.S
 	return podNames, nil
 }
 
+// ServicePodSelector returns the label selector for the pods of a service
+// For Helm services: uses helm release labels
+// For manifest services: uses user-specified labels or service name
+func ServicePodSelector(service *config.ServiceConfig) (string, error) {
+	switch service.Type {
+	case "helm":
+		// Helm uses app.kubernetes.io/instance=<release-name>
+		return fmt.Sprintf("app.kubernetes.io/instance=%s", service.Name), nil
+	case "manifests":
+		// For manifests, try to use user-specified labels or fallback to app label
+		if len(service.Labels) > 0 {
+			// Build selector from service labels
+			selectors := make([]string, 0, len(service.Labels))
+			for key, value := range service.Labels {
+				selectors = append(selectors, fmt.Sprintf("%s=%s", key, value))
+			}
+			sort.Strings(selectors)
+			return strings.Join(selectors, ","), nil
+		}
+		// Fallback to app=service-name
+		return fmt.Sprintf("app=%s", service.Name), nil
+	default:
+		return "", fmt.Errorf("unsupported service type: %s", service.Type)
+	}
+}
+
 // PortForward establishes a port-forward connection to a pod
 func PortForward(ctx context.Context, kubeconfigContent, namespace, podName string, ports []string) error {
 	// Parse kubeconfig
@@ -159,13 +198,6 @@ func PortForward(ctx context.Context, kubeconfigContent, namespace, podName stri
 		return fmt.Errorf("failed to create REST config: %w", err)
 	}
 
-	// Skip TLS verification for kind clusters
-	if restConfig.TLSClientConfig.CAData != nil || restConfig.TLSClientConfig.CAFile != "" {
-		restConfig.TLSClientConfig.Insecure = true
-		restConfig.TLSClientConfig.CAData = nil
-		restConfig.TLSClientConfig.CAFile = ""
-	}
-
 	// Create clientset
 	clientset, err := kubernetes.NewForConfig(restConfig)
 	if err != nil {
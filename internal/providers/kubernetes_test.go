@@ -0,0 +1,62 @@
+package providers
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://127.0.0.1:6443
+  name: kind-dev
+contexts:
+- context:
+    cluster: kind-dev
+    user: kind-dev
+  name: kind-dev
+current-context: kind-dev
+users:
+- name: kind-dev
+  user:
+    token: abc
+`
+
+func TestImpersonateKubeconfig(test *testing.T) {
+	tests := []struct {
+		name   string
+		user   string
+		groups []string
+	}{
+		{name: "service account", user: "system:serviceaccount:team-a:deployer"},
+		{name: "user and groups", user: "jane", groups: []string{"developers", "qa"}},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			content, err := ImpersonateKubeconfig(testKubeconfig, tt.user, tt.groups)
+			if err != nil {
+				test.Fatalf("ImpersonateKubeconfig() error = %v", err)
+			}
+			restConfig, err := getRESTConfigFromKubeconfig(content)
+			if err != nil {
+				test.Fatalf("getRESTConfigFromKubeconfig() error = %v", err)
+			}
+			if restConfig.Impersonate.UserName != tt.user {
+				test.Errorf("Impersonate.UserName = %q, want %q", restConfig.Impersonate.UserName, tt.user)
+			}
+			if !reflect.DeepEqual(restConfig.Impersonate.Groups, tt.groups) {
+				test.Errorf("Impersonate.Groups = %v, want %v", restConfig.Impersonate.Groups, tt.groups)
+			}
+			if restConfig.BearerToken != "abc" {
+				test.Errorf("BearerToken = %q, want the original credentials kept", restConfig.BearerToken)
+			}
+		})
+	}
+
+	unchanged, err := ImpersonateKubeconfig(testKubeconfig, "", nil)
+	if err != nil || unchanged != testKubeconfig {
+		test.Errorf("ImpersonateKubeconfig() without a user should return the kubeconfig unchanged")
+	}
+}
@@ -0,0 +1,90 @@
+package providers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/localca"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// localCADir returns the directory of the local CA. Replaced in tests.
+var localCADir = localca.DefaultDir
+
+// localTLSSecret returns the TLS Secret for a service's local_tls hosts. The
+// certificate in existing is kept while it still covers the hosts, so every
+// install doesn't mint a new one.
+func localTLSSecret(service *config.ServiceConfig, existing *corev1.Secret) (*corev1.Secret, error) {
+	dir, err := localCADir()
+	if err != nil {
+		return nil, err
+	}
+	ca, err := localca.LoadOrCreate(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := service.LocalTLS.Hosts
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      service.LocalTLS.GetSecretName(service.Name),
+			Namespace: service.GetNamespace(),
+			Labels:    map[string]string{managedByLabel: "kraze", serviceLabel: service.Name, secretLabel: "true"},
+		},
+		Type: corev1.SecretTypeTLS,
+	}
+	if existing != nil && existing.Type == corev1.SecretTypeTLS && ca.Covers(existing.Data[corev1.TLSCertKey], hosts, time.Now()) {
+		secret.Data = existing.Data
+		return secret, nil
+	}
+
+	certPEM, keyPEM, err := ca.Issue(hosts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue local_tls certificate: %w", err)
+	}
+	secret.Data = map[string][]byte{
+		corev1.TLSCertKey:       certPEM,
+		corev1.TLSPrivateKeyKey: keyPEM,
+		"ca.crt":                ca.CertPEM,
+	}
+	return secret, nil
+}
+
+// injectIngressTLS points an Ingress without TLS settings at the service's
+// local_tls Secret, for the rule hosts the certificate covers
+func injectIngressTLS(obj *unstructured.Unstructured, service *config.ServiceConfig) bool {
+	if service.LocalTLS == nil || obj.GetKind() != "Ingress" {
+		return false
+	}
+	if tls, found, _ := unstructured.NestedSlice(obj.Object, "spec", "tls"); found && len(tls) > 0 {
+		return false
+	}
+	rules, _, _ := unstructured.NestedSlice(obj.Object, "spec", "rules")
+
+	var hosts []interface{}
+	for _, itr := range rules {
+		rule, ok := itr.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		host, _ := rule["host"].(string)
+		for _, pattern := range service.LocalTLS.Hosts {
+			if host != "" && localca.MatchesHost(pattern, host) {
+				hosts = append(hosts, host)
+				break
+			}
+		}
+	}
+	if len(hosts) == 0 {
+		return false
+	}
+
+	tls := []interface{}{map[string]interface{}{
+		"hosts":      hosts,
+		"secretName": service.LocalTLS.GetSecretName(service.Name),
+	}}
+	return unstructured.SetNestedSlice(obj.Object, tls, "spec", "tls") == nil
+}
@@ -0,0 +1,153 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// useTestLocalCA keeps the local CA of a test in a temporary directory
+func useTestLocalCA(test *testing.T) {
+	dir := test.TempDir()
+	original := localCADir
+	localCADir = func() (string, error) { return dir, nil }
+	test.Cleanup(func() { localCADir = original })
+}
+
+func TestEnsureServiceSecretsLocalTLS(test *testing.T) {
+	useTestLocalCA(test)
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset()
+	service := &config.ServiceConfig{
+		Name:      "web",
+		Namespace: "frontend",
+		LocalTLS:  &config.LocalTLSConfig{Hosts: []string{"web.localtest.me"}},
+	}
+
+	if err := ensureServiceSecrets(ctx, clientset, service); err != nil {
+		test.Fatalf("ensureServiceSecrets() error = %v", err)
+	}
+	first, err := clientset.CoreV1().Secrets("frontend").Get(ctx, "web-tls", metav1.GetOptions{})
+	if err != nil {
+		test.Fatalf("Expected the web-tls Secret: %v", err)
+	}
+	if first.Type != corev1.SecretTypeTLS || len(first.Data[corev1.TLSCertKey]) == 0 || len(first.Data["ca.crt"]) == 0 {
+		test.Errorf("Unexpected Secret %s with keys %v", first.Type, reflect.ValueOf(first.Data).MapKeys())
+	}
+
+	// Installing again keeps the certificate
+	if err := ensureServiceSecrets(ctx, clientset, service); err != nil {
+		test.Fatalf("ensureServiceSecrets() error = %v", err)
+	}
+	second, _ := clientset.CoreV1().Secrets("frontend").Get(ctx, "web-tls", metav1.GetOptions{})
+	if !bytes.Equal(first.Data[corev1.TLSCertKey], second.Data[corev1.TLSCertKey]) {
+		test.Error("Expected the certificate to be reused while it covers the hosts")
+	}
+
+	// A new host gets a new certificate
+	service.LocalTLS.Hosts = append(service.LocalTLS.Hosts, "api.localtest.me")
+	if err := ensureServiceSecrets(ctx, clientset, service); err != nil {
+		test.Fatalf("ensureServiceSecrets() error = %v", err)
+	}
+	third, _ := clientset.CoreV1().Secrets("frontend").Get(ctx, "web-tls", metav1.GetOptions{})
+	if bytes.Equal(first.Data[corev1.TLSCertKey], third.Data[corev1.TLSCertKey]) {
+		test.Error("Expected a new certificate once a host was added")
+	}
+
+	// Removing local_tls deletes the Secret
+	service.LocalTLS = nil
+	if err := ensureServiceSecrets(ctx, clientset, service); err != nil {
+		test.Fatalf("ensureServiceSecrets() error = %v", err)
+	}
+	if _, err := clientset.CoreV1().Secrets("frontend").Get(ctx, "web-tls", metav1.GetOptions{}); err == nil {
+		test.Error("Expected the Secret to be deleted once local_tls was removed")
+	}
+}
+
+func TestEnsureServiceSecretsLocalTLSRefusesUnmanaged(test *testing.T) {
+	useTestLocalCA(test)
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-tls", Namespace: "frontend", Labels: map[string]string{"app.kubernetes.io/managed-by": "cert-manager"}},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("theirs")},
+		Type:       corev1.SecretTypeTLS,
+	})
+	service := &config.ServiceConfig{
+		Name:      "web",
+		Namespace: "frontend",
+		LocalTLS:  &config.LocalTLSConfig{Hosts: []string{"web.localtest.me"}},
+	}
+
+	if err := ensureServiceSecrets(ctx, clientset, service); err == nil {
+		test.Fatal("Expected an error for a Secret kraze didn't create")
+	}
+	secret, _ := clientset.CoreV1().Secrets("frontend").Get(ctx, "web-tls", metav1.GetOptions{})
+	if string(secret.Data[corev1.TLSCertKey]) != "theirs" {
+		test.Error("Expected the existing Secret to be left alone")
+	}
+}
+
+func TestInjectIngressTLS(test *testing.T) {
+	service := &config.ServiceConfig{
+		Name:     "web",
+		LocalTLS: &config.LocalTLSConfig{Hosts: []string{"*.localtest.me"}, SecretName: "local-certs"},
+	}
+	ingress := func(tls []interface{}, hosts ...string) *unstructured.Unstructured {
+		var rules []interface{}
+		for _, host := range hosts {
+			rules = append(rules, map[string]interface{}{"host": host})
+		}
+		spec := map[string]interface{}{"rules": rules}
+		if tls != nil {
+			spec["tls"] = tls
+		}
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "networking.k8s.io/v1",
+			"kind":       "Ingress",
+			"metadata":   map[string]interface{}{"name": "web"},
+			"spec":       spec,
+		}}
+	}
+
+	tests := []struct {
+		name     string
+		obj      *unstructured.Unstructured
+		injected bool
+		expected []interface{}
+	}{
+		{
+			name:     "matching hosts",
+			obj:      ingress(nil, "web.localtest.me", "web.example.com"),
+			injected: true,
+			expected: []interface{}{map[string]interface{}{"hosts": []interface{}{"web.localtest.me"}, "secretName": "local-certs"}},
+		},
+		{
+			name:     "existing tls is kept",
+			obj:      ingress([]interface{}{map[string]interface{}{"secretName": "chart-tls"}}, "web.localtest.me"),
+			expected: []interface{}{map[string]interface{}{"secretName": "chart-tls"}},
+		},
+		{
+			name: "no matching host",
+			obj:  ingress(nil, "web.example.com"),
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if injected := injectIngressTLS(tt.obj, service); injected != tt.injected {
+				test.Errorf("injectIngressTLS() = %v, want %v", injected, tt.injected)
+			}
+			tls, _, _ := unstructured.NestedSlice(tt.obj.Object, "spec", "tls")
+			if !reflect.DeepEqual(tls, tt.expected) {
+				test.Errorf("Expected tls %v, got %v", tt.expected, tls)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package providers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// logPodPollInterval is how often new pods (e.g. after a rollout) are picked up when following logs
+const logPodPollInterval = 2 * time.Second
+
+// LogOptions controls which logs StreamServiceLogs fetches
+type LogOptions struct {
+	Follow     bool
+	Since      time.Duration // Only logs newer than this (0 = all)
+	Tail       int64         // Lines from the end of each container's log (-1 = all)
+	Container  string        // Only this container (empty = all containers)
+	Timestamps bool
+}
+
+// ServicePodSelectors returns label selectors matching the pods of a service.
+// Helm pods carry the release instance label. Manifests pods are found through
+// the selectors of the workloads kraze labeled for the service, plus any bare
+// pods carrying the tracking labels themselves.
+func ServicePodSelectors(ctx context.Context, clientset kubernetes.Interface, service *config.ServiceConfig) ([]string, error) {
+	if service.IsHelm() {
+		return []string{serviceWorkloadSelector(service)}, nil
+	}
+
+	owned := serviceWorkloadSelector(service)
+	namespace := service.GetNamespace()
+	listOpts := metav1.ListOptions{LabelSelector: owned}
+	selectors := []string{owned}
+
+	addSelector := func(selector *metav1.LabelSelector) {
+		parsed, err := metav1.LabelSelectorAsSelector(selector)
+		if err != nil || parsed.Empty() {
+			return
+		}
+		selectors = append(selectors, parsed.String())
+	}
+
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, listOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for _, deployment := range deployments.Items {
+		addSelector(deployment.Spec.Selector)
+	}
+
+	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(ctx, listOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for _, statefulSet := range statefulSets.Items {
+		addSelector(statefulSet.Spec.Selector)
+	}
+
+	daemonSets, err := clientset.AppsV1().DaemonSets(namespace).List(ctx, listOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+	for _, daemonSet := range daemonSets.Items {
+		addSelector(daemonSet.Spec.Selector)
+	}
+
+	jobs, err := clientset.BatchV1().Jobs(namespace).List(ctx, listOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	for _, job := range jobs.Items {
+		addSelector(job.Spec.Selector)
+	}
+
+	return selectors, nil
+}
+
+// ListServicePods returns the pods of a service sorted by name, skipping terminating pods
+func ListServicePods(ctx context.Context, clientset kubernetes.Interface, service *config.ServiceConfig) ([]corev1.Pod, error) {
+	selectors, err := ServicePodSelectors(ctx, clientset, service)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]corev1.Pod)
+	for _, selector := range selectors {
+		pods, err := clientset.CoreV1().Pods(service.GetNamespace()).List(ctx, metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pods: %w", err)
+		}
+		for _, pod := range pods.Items {
+			if pod.DeletionTimestamp == nil {
+				byName[pod.Name] = pod
+			}
+		}
+	}
+
+	result := make([]corev1.Pod, 0, len(byName))
+	for _, pod := range byName {
+		result = append(result, pod)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// StreamServiceLogs writes the logs of all containers in the service's pods to out,
+// interleaved line by line and prefixed with a pod/container tag colored per pod.
+// When following, pods created later (rollouts, restarts) are picked up as they
+// appear, and the call returns when ctx is cancelled.
+func StreamServiceLogs(ctx context.Context, clientset kubernetes.Interface, service *config.ServiceConfig, opts LogOptions, out io.Writer) error {
+	pods, err := ListServicePods(ctx, clientset, service)
+	if err != nil {
+		return err
+	}
+	if len(pods) == 0 && !opts.Follow {
+		return fmt.Errorf("no pods found for service '%s' in namespace '%s'", service.Name, service.GetNamespace())
+	}
+
+	streamer := &logStreamer{
+		clientset: clientset,
+		namespace: service.GetNamespace(),
+		opts:      opts,
+		out:       out,
+		started:   make(map[string]bool),
+	}
+
+	for _, pod := range pods {
+		streamer.startPod(ctx, pod, true)
+	}
+	if streamer.count() == 0 && !opts.Follow {
+		if opts.Container == "" {
+			return fmt.Errorf("no containers have started in the pods of service '%s'", service.Name)
+		}
+		return fmt.Errorf("no containers matching '%s' in the pods of service '%s'", opts.Container, service.Name)
+	}
+
+	if opts.Follow {
+		ticker := time.NewTicker(logPodPollInterval)
+		defer ticker.Stop()
+	poll:
+		for {
+			select {
+			case <-ctx.Done():
+				break poll
+			case <-ticker.C:
+				pods, err := ListServicePods(ctx, clientset, service)
+				if err != nil {
+					continue
+				}
+				for _, pod := range pods {
+					streamer.startPod(ctx, pod, false)
+				}
+			}
+		}
+	}
+
+	streamer.wg.Wait()
+	return nil
+}
+
+// logStreamer streams container logs concurrently, writing whole lines to a shared writer
+type logStreamer struct {
+	clientset kubernetes.Interface
+	namespace string
+	opts      LogOptions
+	out       io.Writer
+
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	started map[string]bool // pod/container/restart streams already started
+}
+
+// startPod starts a stream for each container of a pod, init containers first,
+// that isn't already streaming. A restarted container is streamed again, as
+// its previous stream ended with it. Streams started while following (new pods
+// and restarts) are read from the start rather than with --since and --tail.
+func (streamer *logStreamer) startPod(ctx context.Context, pod corev1.Pod, initial bool) {
+	containers := append(slices.Clone(pod.Spec.InitContainers), pod.Spec.Containers...)
+	for _, container := range containers {
+		if streamer.opts.Container != "" && container.Name != streamer.opts.Container {
+			continue
+		}
+		// Containers that haven't started yet have no logs; they are retried on the next poll
+		status, started := containerStarted(pod, container.Name)
+		if !started {
+			continue
+		}
+
+		key := fmt.Sprintf("%s/%s/%d", pod.Name, container.Name, status.RestartCount)
+		streamer.mu.Lock()
+		if streamer.started[key] {
+			streamer.mu.Unlock()
+			continue
+		}
+		streamer.started[key] = true
+		streamer.mu.Unlock()
+
+		streamer.wg.Add(1)
+		go func(podName, containerName string) {
+			defer streamer.wg.Done()
+			if err := streamer.stream(ctx, podName, containerName, initial); err != nil && ctx.Err() == nil {
+				streamer.writeLine(podName, containerName, color.Yellow(fmt.Sprintf("(log stream ended: %v)", err)))
+			}
+		}(pod.Name, container.Name)
+	}
+}
+
+// count returns how many streams have been started
+func (streamer *logStreamer) count() int {
+	streamer.mu.Lock()
+	defer streamer.mu.Unlock()
+	return len(streamer.started)
+}
+
+// stream copies one container's log to the output line by line, limited by
+// --since and --tail for the initial streams
+func (streamer *logStreamer) stream(ctx context.Context, podName, containerName string, initial bool) error {
+	logOpts := &corev1.PodLogOptions{
+		Container:  containerName,
+		Follow:     streamer.opts.Follow,
+		Timestamps: streamer.opts.Timestamps,
+	}
+	if initial && streamer.opts.Since > 0 {
+		seconds := int64(streamer.opts.Since.Seconds())
+		if seconds < 1 {
+			seconds = 1
+		}
+		logOpts.SinceSeconds = &seconds
+	}
+	if initial && streamer.opts.Tail >= 0 {
+		tail := streamer.opts.Tail
+		logOpts.TailLines = &tail
+	}
+
+	reader, err := streamer.clientset.CoreV1().Pods(streamer.namespace).GetLogs(podName, logOpts).Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		streamer.writeLine(podName, containerName, scanner.Text())
+	}
+	return scanner.Err()
+}
+
+// writeLine writes one prefixed log line without interleaving partial lines
+func (streamer *logStreamer) writeLine(podName, containerName, line string) {
+	prefix := color.ForKey(podName)(fmt.Sprintf("[%s/%s]", podName, containerName))
+	streamer.mu.Lock()
+	defer streamer.mu.Unlock()
+	fmt.Fprintf(streamer.out, "%s %s\n", prefix, line)
+}
+
+// containerStarted returns the status of a container or init container, and
+// true if it is running or has run before
+func containerStarted(pod corev1.Pod, containerName string) (corev1.ContainerStatus, bool) {
+	for _, status := range slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses) {
+		if status.Name != containerName {
+			continue
+		}
+		return status, status.State.Running != nil || status.State.Terminated != nil || status.LastTerminationState.Terminated != nil
+	}
+	return corev1.ContainerStatus{}, false
+}
@@ -0,0 +1,154 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func logTestPod(name string, podLabels map[string]string, started bool) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app", Labels: podLabels},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "main"}}},
+	}
+	if started {
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name:  "main",
+			State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+		}}
+	}
+	return pod
+}
+
+func TestListServicePods(test *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web",
+			Namespace: "app",
+			Labels:    map[string]string{managedByLabel: "kraze", serviceLabel: "web"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+		},
+	}
+
+	clientset := fake.NewSimpleClientset(
+		deployment,
+		logTestPod("web-b", map[string]string{"app": "web"}, true),
+		logTestPod("web-a", map[string]string{"app": "web"}, true),
+		logTestPod("web-debug", map[string]string{managedByLabel: "kraze", serviceLabel: "web"}, true),
+		logTestPod("api-a", map[string]string{"app": "api"}, true),
+		logTestPod("redis-0", map[string]string{"app.kubernetes.io/instance": "redis"}, true),
+	)
+
+	tests := []struct {
+		name     string
+		service  config.ServiceConfig
+		expected []string
+	}{
+		{
+			name:     "manifests service via workload selector and tracking labels",
+			service:  config.ServiceConfig{Name: "web", Type: "manifests", Namespace: "app"},
+			expected: []string{"web-a", "web-b", "web-debug"},
+		},
+		{
+			name:     "helm service via release label",
+			service:  config.ServiceConfig{Name: "redis", Type: "helm", Namespace: "app"},
+			expected: []string{"redis-0"},
+		},
+		{
+			name:     "service without pods",
+			service:  config.ServiceConfig{Name: "worker", Type: "manifests", Namespace: "app"},
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			pods, err := ListServicePods(context.Background(), clientset, &tt.service)
+			if err != nil {
+				test.Fatalf("ListServicePods() error = %v", err)
+			}
+			if len(pods) != len(tt.expected) {
+				test.Fatalf("got %d pods, want %v", len(pods), tt.expected)
+			}
+			for itr, pod := range pods {
+				if pod.Name != tt.expected[itr] {
+					test.Errorf("pod %d: got %s, want %s", itr, pod.Name, tt.expected[itr])
+				}
+			}
+		})
+	}
+}
+
+func TestStreamServiceLogs(test *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		logTestPod("redis-0", map[string]string{"app.kubernetes.io/instance": "redis"}, true),
+		logTestPod("redis-1", map[string]string{"app.kubernetes.io/instance": "redis"}, false),
+	)
+	service := &config.ServiceConfig{Name: "redis", Type: "helm", Namespace: "app"}
+
+	var out bytes.Buffer
+	if err := StreamServiceLogs(context.Background(), clientset, service, LogOptions{Tail: -1}, &out); err != nil {
+		test.Fatalf("StreamServiceLogs() error = %v", err)
+	}
+
+	output := out.String()
+	if !strings.Contains(output, "[redis-0/main]") {
+		test.Errorf("expected a prefixed line for redis-0, got %q", output)
+	}
+	if strings.Contains(output, "redis-1") {
+		test.Errorf("expected no stream for a container that hasn't started, got %q", output)
+	}
+
+	// A container filter matching nothing is an error rather than silent empty output
+	err := StreamServiceLogs(context.Background(), clientset, service, LogOptions{Tail: -1, Container: "sidecar"}, &out)
+	if err == nil {
+		test.Error("expected an error for a container that doesn't exist")
+	}
+
+	// Without a filter, pods whose containers haven't started don't name an empty container
+	pending := fake.NewSimpleClientset(logTestPod("redis-1", map[string]string{"app.kubernetes.io/instance": "redis"}, false))
+	err = StreamServiceLogs(context.Background(), pending, service, LogOptions{Tail: -1}, &out)
+	if err == nil || strings.Contains(err.Error(), "''") {
+		test.Errorf("StreamServiceLogs() error = %v, want one about containers not started", err)
+	}
+}
+
+func TestLogStreamerStartPod(test *testing.T) {
+	pod := logTestPod("web-0", nil, true)
+	pod.Spec.InitContainers = []corev1.Container{{Name: "migrate"}}
+	pod.Status.InitContainerStatuses = []corev1.ContainerStatus{{
+		Name:  "migrate",
+		State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}},
+	}}
+	streamer := &logStreamer{
+		clientset: fake.NewSimpleClientset(pod),
+		namespace: "app",
+		opts:      LogOptions{Tail: -1},
+		out:       &bytes.Buffer{},
+		started:   make(map[string]bool),
+	}
+
+	streamer.startPod(context.Background(), *pod, true)
+	streamer.wg.Wait()
+	if !streamer.started["web-0/migrate/0"] || !streamer.started["web-0/main/0"] {
+		test.Fatalf("started = %v, want the init container and the container", streamer.started)
+	}
+
+	// Polling again doesn't start the same streams twice, but a restart streams again
+	streamer.startPod(context.Background(), *pod, false)
+	pod.Status.ContainerStatuses[0].RestartCount = 1
+	streamer.startPod(context.Background(), *pod, false)
+	streamer.wg.Wait()
+	if streamer.count() != 3 || !streamer.started["web-0/main/1"] {
+		test.Errorf("started = %v, want the restarted container streamed again", streamer.started)
+	}
+}
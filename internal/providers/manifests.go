@@ -6,7 +6,6 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -16,6 +15,7 @@ import (
 
 	"github.com/hjames9/kraze/internal/color"
 	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/telemetry"
 	yamlv3 "gopkg.in/yaml.v3"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -26,12 +26,16 @@ import (
 	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/restmapper"
 )
 
 const (
 	managedByLabel = "app.kubernetes.io/managed-by"
 	serviceLabel   = "kraze.service"
+
+	// fieldManager is the server-side apply field manager for resources kraze applies
+	fieldManager = "kraze"
 )
 
 // ManifestsProvider implements the Provider interface for raw Kubernetes manifests
@@ -49,7 +53,12 @@ func NewManifestsProvider(opts *ProviderOptions) (*ManifestsProvider, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get REST config: %w", err)
 	}
+	return newManifestsProviderForConfig(restConfig, opts)
+}
 
+// newManifestsProviderForConfig creates a manifests provider talking to the
+// cluster of a REST config, e.g. the in-cluster config of the agent
+func newManifestsProviderForConfig(restConfig *rest.Config, opts *ProviderOptions) (*ManifestsProvider, error) {
 	// Create dynamic client
 	dynamicClient, err := dynamic.NewForConfig(restConfig)
 	if err != nil {
@@ -83,7 +92,10 @@ func NewManifestsProvider(opts *ProviderOptions) (*ManifestsProvider, error) {
 }
 
 // Install applies Kubernetes manifests
-func (manifest *ManifestsProvider) Install(ctx context.Context, service *config.ServiceConfig) error {
+func (manifest *ManifestsProvider) Install(ctx context.Context, service *config.ServiceConfig) (err error) {
+	ctx, span := telemetry.StartSpan(ctx, "manifests.apply", telemetry.Service(service.Name))
+	defer func() { telemetry.EndSpan(span, err) }()
+
 	// Create namespace if it doesn't exist and should be created
 	if service.ShouldCreateNamespace() {
 		if err := manifest.ensureNamespace(ctx, service.GetNamespace()); err != nil {
@@ -97,16 +109,22 @@ func (manifest *ManifestsProvider) Install(ctx context.Context, service *config.
 		return fmt.Errorf("failed to load manifests: %w", err)
 	}
 
+	// The workloads may mount the service's Secrets, so they go first
+	if err := ensureServiceSecrets(ctx, manifest.clientset, service); err != nil {
+		return fmt.Errorf("failed to apply secrets: %w", err)
+	}
+
 	if len(manifests) == 0 {
 		return fmt.Errorf("no manifests found")
 	}
 
 	if !manifest.opts.Quiet {
-		fmt.Printf("Applying %d manifest(s) for service '%s'...\n", len(manifests), service.Name)
+		fmt.Fprintf(manifest.opts.output(), "Applying %d manifest(s) for service '%s'...\n", len(manifests), service.Name)
 	}
 
 	// Track applied resources with their fully resolved state (including namespace)
 	var appliedObjects []*unstructured.Unstructured
+	var resourceChanges []ResourceChange
 
 	// Apply each manifest
 	for itr, manifestContent := range manifests {
@@ -124,6 +142,17 @@ func (manifest *ManifestsProvider) Install(ctx context.Context, service *config.
 		// Add tracking labels
 		manifest.addTrackingLabels(obj, service)
 
+		// Run the service's pods under its RBAC sandbox account
+		if service.RBAC != nil {
+			injectServiceAccount(obj, service.RBAC.GetServiceAccount(service.Name))
+		}
+		resourceChanges = append(resourceChanges, OverrideResources(obj, service.ResourcesOverride)...)
+		injectIngressTLS(obj, service)
+		injectDiscovery(obj, service)
+		if manifest.opts.SuspendCronJobs {
+			suspendCronJob(obj)
+		}
+
 		// Set namespace if not specified and resource is namespaced
 		if obj.GetNamespace() == "" && manifest.isNamespacedResource(obj) {
 			obj.SetNamespace(service.GetNamespace())
@@ -136,7 +165,7 @@ func (manifest *ManifestsProvider) Install(ctx context.Context, service *config.
 		}
 
 		if manifest.opts.Verbose {
-			fmt.Printf("  %s Applied %s/%s\n", color.Checkmark(), obj.GetKind(), obj.GetName())
+			fmt.Fprintf(manifest.opts.output(), "  %s Applied %s/%s\n", color.Checkmark(), obj.GetKind(), obj.GetName())
 		}
 
 		// Track the fully resolved object for waiting
@@ -144,19 +173,34 @@ func (manifest *ManifestsProvider) Install(ctx context.Context, service *config.
 	}
 
 	if !manifest.opts.Quiet {
-		fmt.Printf("%s Manifests applied successfully for '%s'\n", color.Checkmark(), service.Name)
+		fmt.Fprintf(manifest.opts.output(), "%s Manifests applied successfully for '%s'\n", color.Checkmark(), service.Name)
+	}
+	reportResourceChanges(service, resourceChanges, manifest.opts)
+
+	// Delete what earlier installs applied that the manifests no longer contain
+	applied := ResourceRefs(appliedObjects)
+	if stale := StaleResources(manifest.opts.PruneResources, applied); len(stale) > 0 {
+		if _, err := pruneResources(ctx, manifest.dynamicClient, manifest.getGVR, service.Name, stale, manifest.opts); err != nil {
+			return err
+		}
+	}
+	if manifest.opts.OnApplied != nil {
+		manifest.opts.OnApplied(applied)
 	}
 
+	// Warn about settings that would leave pods Pending on this cluster
+	lintRenderedWorkloads(ctx, manifest.opts.KubeConfig, appliedObjects, service.GetNamespace(), manifest.opts)
+
 	// Inject config checksums to force rollouts when ConfigMaps/Secrets change
 	checksum, err := calculateConfigChecksumFromObjects(appliedObjects)
 	if err != nil {
 		if manifest.opts.Verbose {
-			fmt.Printf("Warning: failed to calculate config checksum: %v\n", err)
+			fmt.Fprintf(manifest.opts.output(), "Warning: failed to calculate config checksum: %v\n", err)
 		}
 	} else if checksum != "" {
 		if err := manifest.injectConfigChecksumsToObjects(ctx, appliedObjects, checksum); err != nil {
 			if manifest.opts.Verbose {
-				fmt.Printf("Warning: failed to inject config checksums: %v\n", err)
+				fmt.Fprintf(manifest.opts.output(), "Warning: failed to inject config checksums: %v\n", err)
 			}
 		}
 	}
@@ -166,15 +210,59 @@ func (manifest *ManifestsProvider) Install(ctx context.Context, service *config.
 		if err := manifest.waitForAppliedResources(ctx, appliedObjects); err != nil {
 			return fmt.Errorf("failed waiting for resources: %w", err)
 		}
+	} else if manifest.opts.WaitForMigrations {
+		// Dependents must not start before migrations ran or against APIs that aren't served yet
+		if gates := append(FilterMigrationJobs(appliedObjects), FilterAPIRegistrations(appliedObjects)...); len(gates) > 0 {
+			if err := manifest.waitForAppliedResources(ctx, gates); err != nil {
+				return fmt.Errorf("failed waiting for migration jobs: %w", err)
+			}
+		}
 	}
 
 	return nil
 }
 
+// Render parses the service's manifests, setting the service namespace on
+// namespaced resources that don't specify one
+func (manifest *ManifestsProvider) Render(ctx context.Context, service *config.ServiceConfig) ([]*unstructured.Unstructured, error) {
+	manifests, err := manifest.loadManifests(service)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manifests: %w", err)
+	}
+
+	var resources []*unstructured.Unstructured
+	for itr, manifestContent := range manifests {
+		obj, err := manifest.parseManifest(manifestContent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse manifest %d: %w", itr+1, err)
+		}
+		if obj == nil {
+			continue
+		}
+		if obj.GetNamespace() == "" && manifest.isNamespacedResource(obj) {
+			obj.SetNamespace(service.GetNamespace())
+		}
+		if service.RBAC != nil {
+			injectServiceAccount(obj, service.RBAC.GetServiceAccount(service.Name))
+		}
+		OverrideResources(obj, service.ResourcesOverride)
+		injectIngressTLS(obj, service)
+		injectDiscovery(obj, service)
+		if manifest.opts.SuspendCronJobs {
+			suspendCronJob(obj)
+		}
+		resources = append(resources, obj)
+	}
+	return resources, nil
+}
+
 // Uninstall removes Kubernetes resources
-func (manifest *ManifestsProvider) Uninstall(ctx context.Context, service *config.ServiceConfig) error {
+func (manifest *ManifestsProvider) Uninstall(ctx context.Context, service *config.ServiceConfig) (err error) {
+	ctx, span := telemetry.StartSpan(ctx, "manifests.delete", telemetry.Service(service.Name))
+	defer func() { telemetry.EndSpan(span, err) }()
+
 	if !manifest.opts.Quiet {
-		fmt.Printf("Deleting resources for service '%s'...\n", service.Name)
+		fmt.Fprintf(manifest.opts.output(), "Deleting resources for service '%s'...\n", service.Name)
 	}
 
 	// Load manifests to get resource info
@@ -183,6 +271,21 @@ func (manifest *ManifestsProvider) Uninstall(ctx context.Context, service *confi
 		return fmt.Errorf("failed to load manifests: %w", err)
 	}
 
+	// Don't delete CRDs out from under custom resources of other services
+	if !manifest.opts.ForceCRDDeletion {
+		var crdNames []string
+		for _, manifestContent := range manifests {
+			if obj, err := manifest.parseManifest(manifestContent); err == nil && obj != nil && obj.GetKind() == kindCRD {
+				crdNames = append(crdNames, obj.GetName())
+			}
+		}
+		if len(crdNames) > 0 {
+			if err := checkCRDsInUse(ctx, manifest.dynamicClient, service, crdNames); err != nil {
+				return err
+			}
+		}
+	}
+
 	// Delete each resource
 	deletedCount := 0
 	for itr, manifestContent := range manifests {
@@ -190,7 +293,7 @@ func (manifest *ManifestsProvider) Uninstall(ctx context.Context, service *confi
 		if err != nil {
 			// Log warning but continue
 			if !manifest.opts.Quiet {
-				fmt.Printf("  Warning: failed to parse manifest %d: %v\n", itr+1, err)
+				fmt.Fprintf(manifest.opts.output(), "  Warning: failed to parse manifest %d: %v\n", itr+1, err)
 			}
 			continue
 		}
@@ -207,20 +310,24 @@ func (manifest *ManifestsProvider) Uninstall(ctx context.Context, service *confi
 		// Delete the resource
 		if err := manifest.deleteResource(ctx, obj); err != nil {
 			if !manifest.opts.Quiet {
-				fmt.Printf("  Warning: failed to delete %s/%s: %v\n",
+				fmt.Fprintf(manifest.opts.output(), "  Warning: failed to delete %s/%s: %v\n",
 					obj.GetKind(), obj.GetName(), err)
 			}
 			continue
 		}
 
 		if manifest.opts.Verbose {
-			fmt.Printf("  %s Deleted %s/%s\n", color.Checkmark(), obj.GetKind(), obj.GetName())
+			fmt.Fprintf(manifest.opts.output(), "  %s Deleted %s/%s\n", color.Checkmark(), obj.GetKind(), obj.GetName())
 		}
 		deletedCount++
 	}
 
+	if err := deleteServiceSecrets(ctx, manifest.clientset, service, nil); err != nil && !manifest.opts.Quiet {
+		fmt.Fprintf(manifest.opts.output(), "  Warning: failed to delete secrets: %v\n", err)
+	}
+
 	if !manifest.opts.Quiet {
-		fmt.Printf("%s Deleted %d resource(s) for '%s'\n", color.Checkmark(), deletedCount, service.Name)
+		fmt.Fprintf(manifest.opts.output(), "%s Deleted %d resource(s) for '%s'\n", color.Checkmark(), deletedCount, service.Name)
 	}
 	return nil
 }
@@ -286,6 +393,21 @@ func (manifest *ManifestsProvider) IsInstalled(ctx context.Context, service *con
 
 // loadManifests loads manifest files and returns their contents
 func (manifest *ManifestsProvider) loadManifests(service *config.ServiceConfig) ([]string, error) {
+	manifests, err := manifest.readManifestFiles(service)
+	if err != nil {
+		return nil, err
+	}
+
+	// The RBAC sandbox goes first so the ServiceAccount exists before the workloads
+	rbacManifests, err := serviceRBACManifests(service)
+	if err != nil {
+		return nil, err
+	}
+	return append(rbacManifests, manifests...), nil
+}
+
+// readManifestFiles reads the service's manifest files or URL and splits them into documents
+func (manifest *ManifestsProvider) readManifestFiles(service *config.ServiceConfig) ([]string, error) {
 	var files []string
 
 	// Collect file paths
@@ -295,11 +417,8 @@ func (manifest *ManifestsProvider) loadManifests(service *config.ServiceConfig)
 	} else if service.Path != "" {
 		// Check if path is a URL
 		if config.IsHTTPURL(service.Path) {
-			// Remote manifest - download and process directly
-			if manifest.opts.Verbose {
-				fmt.Printf("Downloading manifest from %s...\n", service.Path)
-			}
-			content, err := manifest.downloadManifest(service.Path)
+			// Remote manifest - download (or read from the cache) and process directly
+			content, err := fetchRemoteManifest(context.Background(), service, manifest.opts.output(), manifest.opts.Verbose)
 			if err != nil {
 				return nil, fmt.Errorf("failed to download manifest from %s: %w", service.Path, err)
 			}
@@ -344,9 +463,12 @@ func (manifest *ManifestsProvider) loadManifests(service *config.ServiceConfig)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read file %s: %w", file, err)
 		}
+		if content, err = config.DecryptIfSOPS(context.Background(), file, content); err != nil {
+			return nil, err
+		}
 
-		// Split multi-document YAML
-		docs := manifest.splitYAML(string(content))
+		// Split multi-document YAML, with a ko build's references replaced by its image
+		docs := manifest.splitYAML(service.Build.SubstituteImage(string(content)))
 		manifests = append(manifests, docs...)
 	}
 
@@ -383,26 +505,6 @@ func (manifest *ManifestsProvider) splitYAML(content string) []string {
 	return docs
 }
 
-// downloadManifest downloads a manifest from a remote URL
-func (manifest *ManifestsProvider) downloadManifest(url string) (string, error) {
-	resp, err := http.Get(url)
-	if err != nil {
-		return "", fmt.Errorf("failed to fetch URL: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	return string(body), nil
-}
-
 // parseManifest parses a YAML manifest into an unstructured object
 func (manifest *ManifestsProvider) parseManifest(content string) (*unstructured.Unstructured, error) {
 	// Trim whitespace
@@ -454,7 +556,7 @@ func (manifest *ManifestsProvider) applyResource(ctx context.Context, obj *unstr
 
 	if manifest.opts.Verbose {
 		gvk := obj.GroupVersionKind()
-		fmt.Printf("  Applying %s/%s (GVK: %s/%s/%s -> GVR: %s/%s/%s)\n",
+		fmt.Fprintf(manifest.opts.output(), "  Applying %s/%s (GVK: %s/%s/%s -> GVR: %s/%s/%s)\n",
 			obj.GetKind(), obj.GetName(),
 			gvk.Group, gvk.Version, gvk.Kind,
 			gvr.Group, gvr.Version, gvr.Resource)
@@ -467,51 +569,42 @@ func (manifest *ManifestsProvider) applyResource(ctx context.Context, obj *unstr
 		client = manifest.dynamicClient.Resource(gvr)
 	}
 
-	// Try to get existing resource
-	existing, err := client.Get(ctx, name, metav1.GetOptions{})
-	if err != nil {
-		// Only create if resource doesn't exist; return other errors
-		if errors.IsNotFound(err) {
-			_, createErr := client.Create(ctx, obj, metav1.CreateOptions{})
-			if createErr != nil {
-				// Return error with context
-				return fmt.Errorf("failed to create resource: %w", createErr)
-			}
-			return nil
-		}
-		// Return other errors (e.g., permission denied, API not available)
-		return fmt.Errorf("failed to check if resource exists: %w", err)
-	}
-
-	// PVCs are immutable once bound — skip update to avoid "spec is immutable" errors.
-	// Storage resize is the only allowed mutation, but kraze does not manage that.
+	// PVCs are immutable once bound — never re-apply an existing one to avoid
+	// "spec is immutable" errors. Storage resize is the only allowed mutation,
+	// but kraze does not manage that.
 	if obj.GetKind() == "PersistentVolumeClaim" {
-		return nil
-	}
-
-	// Resource exists — update it. Kubernetes controllers continuously update resource
-	// status, so the resourceVersion can change between our Get and Update. Retry on
-	// 409 Conflict by re-fetching the latest version each time.
-	const maxUpdateRetries = 5
-	latest := existing
-	for attempt := range maxUpdateRetries {
-		obj.SetResourceVersion(latest.GetResourceVersion())
-		_, err = client.Update(ctx, obj, metav1.UpdateOptions{})
+		_, err := client.Get(ctx, name, metav1.GetOptions{})
 		if err == nil {
 			return nil
 		}
-		if !errors.IsConflict(err) {
-			return err
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to check if resource exists: %w", err)
 		}
-		// Conflict: re-fetch and retry
-		if attempt < maxUpdateRetries-1 {
-			latest, err = client.Get(ctx, name, metav1.GetOptions{})
-			if err != nil {
-				return fmt.Errorf("failed to re-fetch resource after conflict: %w", err)
-			}
+	}
+
+	// Server-side apply only claims the fields set in the manifest, so fields set by
+	// controllers (replicas from an HPA, injected sidecars, defaulted values) are left
+	// alone and repeated applies converge instead of racing on resourceVersion
+	_, err = client.Apply(ctx, name, prepareForApply(obj), metav1.ApplyOptions{
+		FieldManager: fieldManager,
+		Force:        manifest.opts.ForceConflicts,
+	})
+	if err != nil {
+		if errors.IsConflict(err) {
+			return fmt.Errorf("failed to apply resource (fields are owned by another manager, use --force-conflicts to take ownership): %w", err)
 		}
+		return fmt.Errorf("failed to apply resource: %w", err)
 	}
-	return fmt.Errorf("failed to update resource after %d attempts due to conflicts: %w", maxUpdateRetries, err)
+	return nil
+}
+
+// prepareForApply returns a copy of obj suitable for a server-side apply request,
+// which rejects objects carrying managedFields and must not pin a resourceVersion
+func prepareForApply(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	applyObj := obj.DeepCopy()
+	applyObj.SetManagedFields(nil)
+	applyObj.SetResourceVersion("")
+	return applyObj
 }
 
 // deleteResource deletes a resource using the dynamic client
@@ -563,32 +656,34 @@ func (manifest *ManifestsProvider) resourceExists(ctx context.Context, obj *unst
 func (manifest *ManifestsProvider) isNamespacedResource(obj *unstructured.Unstructured) bool {
 	gvk := obj.GroupVersionKind()
 
-	// Use REST mapper to determine if resource is namespaced
-	mapping, err := manifest.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
-	if err != nil {
-		// If REST mapper fails, use a list of known cluster-scoped resources
-		clusterScopedKinds := map[string]bool{
-			"Namespace":                      true,
-			"Node":                           true,
-			"PersistentVolume":               true,
-			"ClusterRole":                    true,
-			"ClusterRoleBinding":             true,
-			"CustomResourceDefinition":       true,
-			"StorageClass":                   true,
-			"VolumeAttachment":               true,
-			"APIService":                     true,
-			"MutatingWebhookConfiguration":   true,
-			"ValidatingWebhookConfiguration": true,
-			"PriorityClass":                  true,
-			"RuntimeClass":                   true,
-			"CSIDriver":                      true,
-			"CSINode":                        true,
-			"IngressClass":                   true,
-		}
-		return !clusterScopedKinds[gvk.Kind]
-	}
-
-	return mapping.Scope.Name() == "namespace"
+	// Use REST mapper to determine if resource is namespaced (there is no
+	// mapper when rendering offline)
+	if manifest.mapper != nil {
+		if mapping, err := restMappingWithReset(manifest.mapper, gvk); err == nil {
+			return mapping.Scope.Name() == "namespace"
+		}
+	}
+
+	// If REST mapper fails, use a list of known cluster-scoped resources
+	clusterScopedKinds := map[string]bool{
+		"Namespace":                      true,
+		"Node":                           true,
+		"PersistentVolume":               true,
+		"ClusterRole":                    true,
+		"ClusterRoleBinding":             true,
+		"CustomResourceDefinition":       true,
+		"StorageClass":                   true,
+		"VolumeAttachment":               true,
+		"APIService":                     true,
+		"MutatingWebhookConfiguration":   true,
+		"ValidatingWebhookConfiguration": true,
+		"PriorityClass":                  true,
+		"RuntimeClass":                   true,
+		"CSIDriver":                      true,
+		"CSINode":                        true,
+		"IngressClass":                   true,
+	}
+	return !clusterScopedKinds[gvk.Kind]
 }
 
 // getGVR returns the GroupVersionResource for an object
@@ -596,7 +691,7 @@ func (manifest *ManifestsProvider) getGVR(obj *unstructured.Unstructured) (schem
 	gvk := obj.GroupVersionKind()
 
 	// Use REST mapper to properly map GVK to GVR
-	mapping, err := manifest.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	mapping, err := restMappingWithReset(manifest.mapper, gvk)
 	if err != nil {
 		// Fallback to simple pluralization if discovery fails
 		resource := strings.ToLower(gvk.Kind)
@@ -625,44 +720,58 @@ func (manifest *ManifestsProvider) waitForAppliedResources(ctx context.Context,
 	}
 
 	if !manifest.opts.Quiet {
-		fmt.Printf("Waiting for resources to be ready (timeout: %v)...\n", timeout)
+		fmt.Fprintf(manifest.opts.output(), "Waiting for resources to be ready (timeout: %v)...\n", timeout)
 	}
 
 	// Create context with timeout
 	waitCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
+	// Surface Warning events related to the applied resources while waiting
+	defer monitorWarningEvents(waitCtx, manifest.clientset, resources, manifest.opts)()
+
 	// Wait for each resource
 	for _, obj := range resources {
 		kind := obj.GetKind()
 		name := obj.GetName()
 
 		// Only wait for resources that have a meaningful ready state
-		if !shouldWaitForResource(kind) {
+		if !shouldWaitForResource(obj, manifest.opts.ReadinessRules) {
 			if manifest.opts.Verbose {
-				fmt.Printf("  Skipping wait for %s/%s (not a waitable resource)\n", kind, name)
+				fmt.Fprintf(manifest.opts.output(), "  Skipping wait for %s/%s (not a waitable resource)\n", kind, name)
 			}
 			continue
 		}
 
 		if !manifest.opts.Quiet {
-			fmt.Printf("  Waiting for %s/%s to be ready...\n", kind, name)
+			fmt.Fprintf(manifest.opts.output(), "  Waiting for %s/%s to be ready...\n", kind, name)
 		}
 
-		if err := waitForResourceReady(waitCtx, manifest.dynamicClient, manifest.clientset, manifest.mapper, obj, manifest.opts.Verbose); err != nil {
-			if waitCtx.Err() == context.DeadlineExceeded {
-				return fmt.Errorf("timeout waiting for %s/%s to be ready", kind, name)
+		if err := waitForResourceReady(waitCtx, manifest.dynamicClient, manifest.clientset, manifest.mapper, obj, manifest.opts); err != nil {
+			return &ResourceWaitError{
+				Kind:      kind,
+				Namespace: obj.GetNamespace(),
+				Name:      name,
+				Timeout:   waitCtx.Err() == context.DeadlineExceeded,
+				Err:       err,
 			}
-			return fmt.Errorf("error waiting for %s/%s: %w", kind, name, err)
 		}
 
 		if !manifest.opts.Quiet {
-			fmt.Printf("  %s %s/%s is ready\n", color.Checkmark(), kind, name)
+			fmt.Fprintf(manifest.opts.output(), "  %s %s/%s is ready\n", color.Checkmark(), kind, name)
+		}
+	}
+
+	// Later services look up kinds through discovery, which lags behind newly registered APIs
+	if groupVersions := registeredGroupVersions(resources); len(groupVersions) > 0 {
+		if err := waitForDiscovery(waitCtx, manifest.clientset.Discovery(), groupVersions, manifest.opts.output(), manifest.opts.Verbose); err != nil {
+			return err
 		}
+		manifest.mapper.Reset()
 	}
 
 	if !manifest.opts.Quiet {
-		fmt.Printf("%s All resources are ready\n", color.Checkmark())
+		fmt.Fprintf(manifest.opts.output(), "%s All resources are ready\n", color.Checkmark())
 	}
 	return nil
 }
@@ -707,7 +816,7 @@ func (manifest *ManifestsProvider) ensureNamespace(ctx context.Context, namespac
 
 	// Namespace doesn't exist, create it
 	if manifest.opts.Verbose {
-		fmt.Printf("Creating namespace '%s'...\n", namespace)
+		fmt.Fprintf(manifest.opts.output(), "Creating namespace '%s'...\n", namespace)
 	}
 	_, err = client.Create(ctx, ns, metav1.CreateOptions{})
 	if err != nil && !errors.IsAlreadyExists(err) {
@@ -786,7 +895,7 @@ func (manifest *ManifestsProvider) injectConfigChecksumsToObjects(ctx context.Co
 		}
 
 		// Use shared patching function
-		patchWorkloadWithConfigChecksum(ctx, manifest.dynamicClient, manifest.mapper, kind, name, namespace, checksum, manifest.opts.Verbose)
+		patchWorkloadWithConfigChecksum(ctx, manifest.dynamicClient, manifest.mapper, kind, name, namespace, checksum, manifest.opts.output(), manifest.opts.Verbose)
 	}
 
 	return nil
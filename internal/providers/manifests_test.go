@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/hjames9/kraze/internal/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
@@ -196,6 +197,32 @@ func TestAddTrackingLabels_PreservesExisting(test *testing.T) {
 	}
 }
 
+func TestPrepareForApply(test *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetName("settings")
+	obj.SetResourceVersion("12345")
+	obj.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "kubectl-edit", Operation: metav1.ManagedFieldsOperationUpdate}})
+
+	applyObj := prepareForApply(obj)
+
+	if applyObj.GetResourceVersion() != "" {
+		test.Errorf("resourceVersion: got %q, want empty", applyObj.GetResourceVersion())
+	}
+	if len(applyObj.GetManagedFields()) != 0 {
+		test.Errorf("managedFields: got %d entries, want none", len(applyObj.GetManagedFields()))
+	}
+	if applyObj.GetName() != "settings" || applyObj.GetKind() != "ConfigMap" {
+		test.Errorf("identity changed: got %s/%s", applyObj.GetKind(), applyObj.GetName())
+	}
+
+	// The original object is left untouched
+	if obj.GetResourceVersion() != "12345" || len(obj.GetManagedFields()) != 1 {
+		test.Error("prepareForApply modified the original object")
+	}
+}
+
 func TestLoadManifests(test *testing.T) {
 	mp := &ManifestsProvider{}
 
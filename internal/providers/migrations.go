@@ -0,0 +1,182 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/color"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+)
+
+// helmHookAnnotation is the annotation Helm uses to mark hook resources
+const helmHookAnnotation = "helm.sh/hook"
+
+// migrationNameSegments are dash-separated name segments that identify a Job as a
+// database migration by convention (e.g. "api-migrate", "db-migrations-3")
+var migrationNameSegments = map[string]bool{
+	"migrate":    true,
+	"migration":  true,
+	"migrations": true,
+	"migrator":   true,
+}
+
+// IsMigrationJob returns true if the object is a Job that looks like a migration:
+// either a Helm pre-install/pre-upgrade hook or a Job whose name follows the
+// *-migrate naming convention
+func IsMigrationJob(obj *unstructured.Unstructured) bool {
+	if obj.GetKind() != "Job" {
+		return false
+	}
+	return isMigrationJobMeta(obj.GetName(), obj.GetAnnotations())
+}
+
+// isMigrationJobMeta checks Job name and annotations for migration conventions
+func isMigrationJobMeta(name string, annotations map[string]string) bool {
+	if isPreInstallHook(annotations) {
+		return true
+	}
+
+	for _, segment := range strings.Split(strings.ToLower(name), "-") {
+		if migrationNameSegments[segment] {
+			return true
+		}
+	}
+	return false
+}
+
+// isPreInstallHook returns true if the annotations mark a Helm pre-install or pre-upgrade hook
+func isPreInstallHook(annotations map[string]string) bool {
+	hooks, ok := annotations[helmHookAnnotation]
+	if !ok {
+		return false
+	}
+
+	for _, hook := range strings.Split(hooks, ",") {
+		switch strings.TrimSpace(hook) {
+		case "pre-install", "pre-upgrade":
+			return true
+		}
+	}
+	return false
+}
+
+// FilterMigrationJobs returns the migration Jobs found in a list of resources
+func FilterMigrationJobs(resources []*unstructured.Unstructured) []*unstructured.Unstructured {
+	var jobs []*unstructured.Unstructured
+	for _, obj := range resources {
+		if IsMigrationJob(obj) {
+			jobs = append(jobs, obj)
+		}
+	}
+	return jobs
+}
+
+// WaitForMigrationJobsInNamespace waits for migration Jobs defined in YAML manifests to
+// complete, and for the APIs they register (APIServices, CRDs) to be served, regardless
+// of opts.Wait. Used when a service is not waited on but has dependents that must not
+// start before its migrations have run or against APIs that aren't served yet.
+func WaitForMigrationJobsInNamespace(ctx context.Context, kubeconfigContent, manifestYAML, defaultNamespace string, opts *ProviderOptions) error {
+	resources, err := parseManifestsYAML(manifestYAML)
+	if err != nil {
+		return fmt.Errorf("failed to parse manifests: %w", err)
+	}
+
+	jobs := FilterMigrationJobs(resources)
+	registrations := FilterAPIRegistrations(resources)
+	if len(jobs) == 0 && len(registrations) == 0 {
+		return nil
+	}
+
+	if opts.Verbose {
+		fmt.Fprintf(opts.output(), "Waiting for %d migration job(s) and %d API registration(s) before continuing with dependent services...\n", len(jobs), len(registrations))
+	}
+
+	return waitForResources(ctx, kubeconfigContent, append(jobs, registrations...), defaultNamespace, opts)
+}
+
+// ShowFailedMigrationJobLogs displays logs for failed migration Jobs in a namespace.
+// Helm runs pre-install/pre-upgrade hooks itself and only reports that the hook failed,
+// so this surfaces the output of the Job that actually failed.
+func ShowFailedMigrationJobLogs(ctx context.Context, out io.Writer, kubeconfigContent, namespace string) {
+	restConfig, err := getRESTConfigFromKubeconfig(kubeconfigContent)
+	if err != nil {
+		return
+	}
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return
+	}
+
+	jobs, err := clientset.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return
+	}
+
+	for _, job := range jobs.Items {
+		if !isMigrationJobMeta(job.Name, job.Annotations) || !isTypedJobFailed(&job) {
+			continue
+		}
+
+		fmt.Fprintf(out, "\n  %s Migration job %s/%s failed\n", color.Warning(), job.Namespace, job.Name)
+		displayJobPodLogs(ctx, out, clientset, job.Namespace, job.Name)
+	}
+}
+
+// isTypedJobFailed returns true if a typed Job has a Failed=True condition
+func isTypedJobFailed(job *batchv1.Job) bool {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// displayJobPodLogs shows logs from the most recent Pod created by a Job
+func displayJobPodLogs(ctx context.Context, out io.Writer, clientset *kubernetes.Clientset, namespace, jobName string) {
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("job-name=%s", jobName),
+	})
+	if err != nil || len(pods.Items) == 0 {
+		return
+	}
+
+	// Most recently created pod first
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[j].CreationTimestamp.Before(&pods.Items[i].CreationTimestamp)
+	})
+
+	displayPodContainerLogs(ctx, out, clientset, &pods.Items[0])
+}
+
+// displayPodContainerLogs shows recent logs from every container in a Pod
+func displayPodContainerLogs(ctx context.Context, out io.Writer, clientset *kubernetes.Clientset, pod *corev1.Pod) {
+	containers := make([]string, 0, len(pod.Spec.InitContainers)+len(pod.Spec.Containers))
+	for _, container := range pod.Spec.InitContainers {
+		containers = append(containers, container.Name)
+	}
+	for _, container := range pod.Spec.Containers {
+		containers = append(containers, container.Name)
+	}
+
+	for _, containerName := range containers {
+		logs, err := getContainerLogs(ctx, clientset, pod.Namespace, pod.Name, containerName, 50)
+		if err != nil || len(logs) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(out, "  Last %d log lines from pod '%s' container '%s':\n", len(logs), pod.Name, containerName)
+		for _, log := range logs {
+			fmt.Fprintln(out, log)
+		}
+		fmt.Fprintln(out)
+	}
+}
@@ -0,0 +1,76 @@
+package providers
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestIsMigrationJob(test *testing.T) {
+	tests := []struct {
+		name        string
+		kind        string
+		objName     string
+		annotations map[string]string
+		expected    bool
+	}{
+		{
+			name:     "job with -migrate suffix",
+			kind:     "Job",
+			objName:  "api-migrate",
+			expected: true,
+		},
+		{
+			name:     "job with migrations segment and revision",
+			kind:     "Job",
+			objName:  "app-db-migrations-3",
+			expected: true,
+		},
+		{
+			name:        "helm pre-install hook",
+			kind:        "Job",
+			objName:     "keycloak-setup",
+			annotations: map[string]string{"helm.sh/hook": "pre-install,pre-upgrade"},
+			expected:    true,
+		},
+		{
+			name:        "helm post-install hook",
+			kind:        "Job",
+			objName:     "keycloak-setup",
+			annotations: map[string]string{"helm.sh/hook": "post-install"},
+			expected:    false,
+		},
+		{
+			name:     "regular job",
+			kind:     "Job",
+			objName:  "report-generator",
+			expected: false,
+		},
+		{
+			name:     "migrate substring is not a segment",
+			kind:     "Job",
+			objName:  "immigrated-users",
+			expected: false,
+		},
+		{
+			name:     "deployment named migrate",
+			kind:     "Deployment",
+			objName:  "api-migrate",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			obj := &unstructured.Unstructured{}
+			obj.SetKind(tt.kind)
+			obj.SetName(tt.objName)
+			obj.SetAnnotations(tt.annotations)
+
+			result := IsMigrationJob(obj)
+			if result != tt.expected {
+				test.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
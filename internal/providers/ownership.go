@@ -0,0 +1,143 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hjames9/kraze/internal/config"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// ownedResourceTypes are the namespaced resource types swept for kraze-labeled
+// leftovers when a service is removed from a namespace kraze didn't create
+var ownedResourceTypes = []schema.GroupVersionResource{
+	{Group: "apps", Version: "v1", Resource: "deployments"},
+	{Group: "apps", Version: "v1", Resource: "statefulsets"},
+	{Group: "apps", Version: "v1", Resource: "daemonsets"},
+	{Group: "batch", Version: "v1", Resource: "cronjobs"},
+	{Group: "batch", Version: "v1", Resource: "jobs"},
+	{Group: "", Version: "v1", Resource: "pods"},
+	{Group: "", Version: "v1", Resource: "services"},
+	{Group: "", Version: "v1", Resource: "configmaps"},
+	{Group: "", Version: "v1", Resource: "secrets"},
+	{Group: "", Version: "v1", Resource: "serviceaccounts"},
+	{Group: "", Version: "v1", Resource: "persistentvolumeclaims"},
+	{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "rolebindings"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "roles"},
+}
+
+// OwnedResourceSelectors returns the label selectors matching resources kraze
+// labels as its own for a service. Helm releases track their own resources,
+// so only manifests services have selectors.
+func OwnedResourceSelectors(svc *config.ServiceConfig) []string {
+	if !svc.IsManifests() {
+		return nil
+	}
+	return []string{labels.Set{managedByLabel: "kraze", serviceLabel: svc.Name}.String()}
+}
+
+// DeleteLabeledResources deletes resources in a namespace matching a kraze
+// ownership selector and returns how many were deleted. The namespace itself
+// is never touched.
+func DeleteLabeledResources(ctx context.Context, kubeconfig, namespace, selector string) (int, error) {
+	restConfig, err := getRESTConfigFromKubeconfig(kubeconfig)
+	if err != nil {
+		return 0, err
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	return deleteLabeledResources(ctx, dynamicClient, namespace, selector)
+}
+
+// deleteLabeledResources is DeleteLabeledResources against a given client
+func deleteLabeledResources(ctx context.Context, client dynamic.Interface, namespace, selector string) (int, error) {
+	if err := validateOwnedSelector(selector); err != nil {
+		return 0, err
+	}
+
+	propagation := metav1.DeletePropagationBackground
+	deletedCount := 0
+	for _, gvr := range ownedResourceTypes {
+		list, err := client.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue // Resource type not served by this cluster
+			}
+			return deletedCount, fmt.Errorf("failed to list %s: %w", gvr.Resource, err)
+		}
+
+		for _, item := range list.Items {
+			if item.GetDeletionTimestamp() != nil {
+				continue
+			}
+			err := client.Resource(gvr).Namespace(namespace).Delete(ctx, item.GetName(), metav1.DeleteOptions{PropagationPolicy: &propagation})
+			if err != nil {
+				if errors.IsNotFound(err) {
+					continue // Already deleted (e.g. garbage collected with its owner)
+				}
+				return deletedCount, fmt.Errorf("failed to delete %s %s: %w", gvr.Resource, item.GetName(), err)
+			}
+			deletedCount++
+		}
+	}
+
+	return deletedCount, nil
+}
+
+// validateOwnedSelector refuses selectors that don't pin both the kraze
+// managed-by label and a service, so a bad state entry can't delete resources
+// kraze doesn't own
+func validateOwnedSelector(selector string) error {
+	set, err := labels.ConvertSelectorToLabelsMap(selector)
+	if err != nil {
+		return fmt.Errorf("invalid ownership selector '%s': %w", selector, err)
+	}
+	if set[managedByLabel] != "kraze" || set[serviceLabel] == "" {
+		return fmt.Errorf("refusing to delete by selector '%s': it must match %s=kraze and %s", selector, managedByLabel, serviceLabel)
+	}
+	return nil
+}
+
+// UninstallRecorded removes a service whose config is gone, from what the
+// cluster state recorded: the Helm release named after it, if there is one,
+// and the resources its manifests applied that still carry its labels. CRDs
+// applied by manifests are kept, as pruning never deletes them.
+func UninstallRecorded(ctx context.Context, serviceName, namespace string, applied []string, opts *ProviderOptions) error {
+	service := &config.ServiceConfig{Name: serviceName, Type: "helm", Namespace: namespace}
+	helm, err := NewHelmProvider(opts)
+	if err != nil {
+		return err
+	}
+	installed, err := helm.IsInstalled(ctx, service)
+	if err != nil {
+		return fmt.Errorf("failed to look up Helm release '%s': %w", serviceName, err)
+	}
+	if installed {
+		if err := helm.Uninstall(ctx, service); err != nil {
+			return err
+		}
+	}
+
+	if len(applied) == 0 {
+		return nil
+	}
+	manifest, err := NewManifestsProvider(opts)
+	if err != nil {
+		return err
+	}
+	refs := make([]ResourceRef, 0, len(applied))
+	for _, ref := range applied {
+		refs = append(refs, ResourceRef(ref))
+	}
+	_, err = pruneResources(ctx, manifest.dynamicClient, manifest.getGVR, serviceName, refs, opts)
+	return err
+}
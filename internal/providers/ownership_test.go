@@ -0,0 +1,125 @@
+package providers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestOwnedResourceSelectors(test *testing.T) {
+	tests := []struct {
+		name     string
+		svc      config.ServiceConfig
+		expected []string
+	}{
+		{
+			name:     "manifests service",
+			svc:      config.ServiceConfig{Name: "web", Type: "manifests"},
+			expected: []string{"app.kubernetes.io/managed-by=kraze,kraze.service=web"},
+		},
+		{
+			name:     "helm service has no selectors",
+			svc:      config.ServiceConfig{Name: "redis", Type: "helm"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			got := OwnedResourceSelectors(&tt.svc)
+			if len(got) != len(tt.expected) {
+				test.Fatalf("got %v, want %v", got, tt.expected)
+			}
+			for itr := range got {
+				if got[itr] != tt.expected[itr] {
+					test.Errorf("selector %d: got %q, want %q", itr, got[itr], tt.expected[itr])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateOwnedSelector(test *testing.T) {
+	tests := []struct {
+		name     string
+		selector string
+		wantErr  bool
+	}{
+		{name: "kraze service selector", selector: "app.kubernetes.io/managed-by=kraze,kraze.service=web", wantErr: false},
+		{name: "empty selector", selector: "", wantErr: true},
+		{name: "missing service", selector: "app.kubernetes.io/managed-by=kraze", wantErr: true},
+		{name: "other manager", selector: "app.kubernetes.io/managed-by=Helm,kraze.service=web", wantErr: true},
+		{name: "set-based selector", selector: "kraze.service in (web)", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			err := validateOwnedSelector(tt.selector)
+			if (err != nil) != tt.wantErr {
+				test.Errorf("validateOwnedSelector(%q) error = %v, wantErr %v", tt.selector, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func newLabeledObject(apiVersion, kind, namespace, name string, labels map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetLabels(labels)
+	return obj
+}
+
+func TestDeleteLabeledResources(test *testing.T) {
+	owned := map[string]string{managedByLabel: "kraze", serviceLabel: "web"}
+	otherService := map[string]string{managedByLabel: "kraze", serviceLabel: "api"}
+	foreign := map[string]string{"app": "web"}
+
+	listKinds := make(map[schema.GroupVersionResource]string, len(ownedResourceTypes))
+	for _, gvr := range ownedResourceTypes {
+		listKinds[gvr] = gvr.Resource + "List"
+	}
+
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds,
+		newLabeledObject("apps/v1", "Deployment", "shared", "web", owned),
+		newLabeledObject("v1", "ConfigMap", "shared", "web-config", owned),
+		newLabeledObject("v1", "ConfigMap", "shared", "api-config", otherService),
+		newLabeledObject("v1", "Secret", "shared", "team-secret", foreign),
+		newLabeledObject("v1", "ConfigMap", "other", "web-config", owned),
+	)
+
+	deleted, err := deleteLabeledResources(context.Background(), client, "shared", "app.kubernetes.io/managed-by=kraze,kraze.service=web")
+	if err != nil {
+		test.Fatalf("deleteLabeledResources() error = %v", err)
+	}
+	if deleted != 2 {
+		test.Errorf("deleted = %d, want 2", deleted)
+	}
+
+	remaining := []struct {
+		gvr       schema.GroupVersionResource
+		namespace string
+		name      string
+	}{
+		{schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}, "shared", "api-config"},
+		{schema.GroupVersionResource{Version: "v1", Resource: "secrets"}, "shared", "team-secret"},
+		{schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}, "other", "web-config"},
+	}
+	for _, res := range remaining {
+		if _, err := client.Resource(res.gvr).Namespace(res.namespace).Get(context.Background(), res.name, metav1.GetOptions{}); err != nil {
+			test.Errorf("%s %s/%s should not have been deleted: %v", res.gvr.Resource, res.namespace, res.name, err)
+		}
+	}
+
+	if _, err := deleteLabeledResources(context.Background(), client, "shared", "app=web"); err == nil {
+		test.Error("expected error for a selector without kraze ownership labels")
+	}
+}
@@ -0,0 +1,234 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hjames9/kraze/internal/color"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// pendingGracePeriod is how long a pod may stay Pending for a diagnosable reason
+// before it is treated as a failure, unless the service's pending_timeout says
+// otherwise. The scheduler retries unschedulable pods and volumes can take a few
+// seconds to provision, so transient states are tolerated.
+const pendingGracePeriod = 60 * time.Second
+
+// pendingDiagnosis explains why a pod is stuck Pending and how to fix it
+type pendingDiagnosis struct {
+	Reason     string   // Short cause, e.g. "Unschedulable"
+	Message    string   // Scheduler or kubelet message
+	Suggestion string   // One-line summary of the fix
+	Steps      []string // Concrete things to check
+}
+
+// FailureMessage formats the diagnosis like the other pod failure messages
+func (diagnosis pendingDiagnosis) FailureMessage() string {
+	return fmt.Sprintf("Pod pending: %s - %s", diagnosis.Reason, diagnosis.Message)
+}
+
+// diagnosePendingPod looks for causes that keep a Pending pod from ever starting:
+// scheduling failures (from the PodScheduled condition) and, once scheduled,
+// kubelet Warning events for volumes, image pull secrets and sandboxes.
+// Slow image pulls and other progress are not diagnosed.
+func diagnosePendingPod(pod *corev1.Pod, events []corev1.Event, now time.Time, timeout time.Duration) (pendingDiagnosis, bool) {
+	if pod.Status.Phase != corev1.PodPending {
+		return pendingDiagnosis{}, false
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type != corev1.PodScheduled || condition.Status != corev1.ConditionFalse || condition.Reason != corev1.PodReasonUnschedulable {
+			continue
+		}
+		since := condition.LastTransitionTime.Time
+		if since.IsZero() {
+			since = pod.CreationTimestamp.Time
+		}
+		if now.Sub(since) < timeout {
+			return pendingDiagnosis{}, false
+		}
+		return diagnoseUnschedulable(condition.Message), true
+	}
+
+	if now.Sub(pod.CreationTimestamp.Time) < timeout {
+		return pendingDiagnosis{}, false
+	}
+
+	// Scheduled but not started: the latest relevant kubelet warning explains why
+	for itr := len(events) - 1; itr >= 0; itr-- {
+		event := events[itr]
+		if event.Type != corev1.EventTypeWarning {
+			continue
+		}
+		if diagnosis, found := diagnoseKubeletEvent(event); found {
+			return diagnosis, true
+		}
+	}
+
+	return pendingDiagnosis{}, false
+}
+
+// diagnoseUnschedulable maps a scheduler message to targeted suggestions
+func diagnoseUnschedulable(message string) pendingDiagnosis {
+	diagnosis := pendingDiagnosis{Reason: "Unschedulable", Message: message}
+	lower := strings.ToLower(message)
+
+	switch {
+	case strings.Contains(lower, "unbound immediate persistentvolumeclaims") || strings.Contains(lower, "persistentvolumeclaim") && strings.Contains(lower, "not found"):
+		diagnosis.Reason = "UnboundPersistentVolumeClaim"
+		diagnosis.Suggestion = "Suggestion: A PersistentVolumeClaim used by the pod cannot be bound"
+		diagnosis.Steps = []string{
+			"Check the claim's storageClassName exists: kubectl get storageclass (kind provides 'standard')",
+			"Check the claim is created by this or an earlier service (add depends_on if needed)",
+		}
+	case strings.Contains(lower, "volume node affinity conflict"):
+		diagnosis.Reason = "VolumeNodeAffinityConflict"
+		diagnosis.Suggestion = "Suggestion: The pod's volume is bound to a node the pod cannot run on"
+		diagnosis.Steps = []string{
+			"Local volumes are pinned to the node they were created on",
+			"Delete the PersistentVolumeClaim to re-provision it, or relax the pod's node selector",
+		}
+	case strings.Contains(lower, "insufficient cpu") || strings.Contains(lower, "insufficient memory"):
+		diagnosis.Reason = "InsufficientResources"
+		diagnosis.Suggestion = "Suggestion: No node has enough free CPU or memory for the pod's requests"
+		diagnosis.Steps = []string{
+			"Lower resources.requests for local development",
+			"Add worker nodes (cluster.config or cluster.preset) or give Docker more resources",
+		}
+	case strings.Contains(lower, "anti-affinity") || strings.Contains(lower, "pod affinity"):
+		diagnosis.Reason = "PodAffinity"
+		diagnosis.Suggestion = "Suggestion: Pod (anti-)affinity rules cannot be satisfied with the cluster's nodes"
+		diagnosis.Steps = []string{
+			"Reduce replicas or use preferredDuringSchedulingIgnoredDuringExecution",
+			"Add worker nodes (cluster.config or cluster.preset)",
+		}
+	case strings.Contains(lower, "topology spread") || strings.Contains(lower, "missing required label"):
+		diagnosis.Reason = "TopologySpread"
+		diagnosis.Suggestion = "Suggestion: Topology spread constraints cannot be satisfied"
+		diagnosis.Steps = []string{
+			"Use whenUnsatisfiable: ScheduleAnyway for local development",
+			"Or add the topology label (e.g. topology.kubernetes.io/zone) to nodes in cluster.config",
+		}
+	case strings.Contains(lower, "node affinity") || strings.Contains(lower, "node selector"):
+		diagnosis.Reason = "NodeSelector"
+		diagnosis.Suggestion = "Suggestion: No node matches the pod's nodeSelector or node affinity"
+		diagnosis.Steps = []string{
+			"Check node labels: kubectl get nodes --show-labels",
+			"Add the labels to nodes in cluster.config, or remove the selector for local development",
+		}
+	case strings.Contains(lower, "taint"):
+		diagnosis.Reason = "UntoleratedTaint"
+		diagnosis.Suggestion = "Suggestion: Every node has a taint the pod does not tolerate"
+		diagnosis.Steps = []string{
+			"Check node taints: kubectl describe nodes | grep Taints",
+			"Add a matching toleration, or run without dedicated (tainted) nodes locally",
+		}
+	default:
+		diagnosis.Suggestion = "Suggestion: The scheduler cannot place the pod on any node"
+		diagnosis.Steps = []string{"Review the scheduler message above and the pod spec's scheduling constraints"}
+	}
+
+	return diagnosis
+}
+
+// diagnoseKubeletEvent maps a kubelet Warning event for a scheduled pod to suggestions
+func diagnoseKubeletEvent(event corev1.Event) (pendingDiagnosis, bool) {
+	message := strings.TrimSpace(event.Message)
+	lower := strings.ToLower(message)
+
+	switch event.Reason {
+	case "FailedMount":
+		diagnosis := pendingDiagnosis{
+			Reason:     "FailedMount",
+			Message:    message,
+			Suggestion: "Suggestion: A volume cannot be mounted",
+			Steps:      []string{"Check the volume's source exists and is reachable"},
+		}
+		if strings.Contains(lower, "not found") {
+			diagnosis.Suggestion = "Suggestion: A Secret or ConfigMap mounted by the pod does not exist"
+			diagnosis.Steps = []string{
+				"Check the name and namespace of the referenced Secret/ConfigMap",
+				"If another service creates it, add that service to depends_on",
+			}
+		}
+		return diagnosis, true
+	case "FailedAttachVolume":
+		return pendingDiagnosis{
+			Reason:     "FailedAttachVolume",
+			Message:    message,
+			Suggestion: "Suggestion: A volume cannot be attached to the node",
+			Steps:      []string{"Check the PersistentVolume and its provisioner: kubectl describe pv"},
+		}, true
+	case "FailedToRetrieveImagePullSecret":
+		return pendingDiagnosis{
+			Reason:     "MissingImagePullSecret",
+			Message:    message,
+			Suggestion: "Suggestion: An imagePullSecret referenced by the pod does not exist",
+			Steps: []string{
+				"Create the secret in the pod's namespace: kubectl create secret docker-registry ...",
+				"For local images, load them with kraze load-image <image> and drop the pull secret",
+			},
+		}, true
+	case "FailedCreatePodSandBox":
+		return pendingDiagnosis{
+			Reason:     "FailedCreatePodSandBox",
+			Message:    message,
+			Suggestion: "Suggestion: The node cannot create the pod's sandbox (usually networking)",
+			Steps: []string{
+				"Check the CNI pods are running: kubectl get pods -n kube-system",
+				"Recreating the cluster (kraze destroy && kraze up) resets node networking",
+			},
+		}, true
+	}
+
+	return pendingDiagnosis{}, false
+}
+
+// checkPendingPod diagnoses a Pending pod, fetching its events once it has been
+// pending for longer than timeout
+func checkPendingPod(ctx context.Context, clientset kubernetes.Interface, pod *corev1.Pod, timeout time.Duration) (pendingDiagnosis, bool) {
+	if pod.Status.Phase != corev1.PodPending {
+		return pendingDiagnosis{}, false
+	}
+
+	var events []corev1.Event
+	if time.Since(pod.CreationTimestamp.Time) >= timeout {
+		list, err := clientset.CoreV1().Events(pod.Namespace).List(ctx, metav1.ListOptions{
+			FieldSelector: fmt.Sprintf("involvedObject.name=%s,involvedObject.kind=Pod", pod.Name),
+		})
+		if err == nil {
+			events = list.Items
+			sort.SliceStable(events, func(i, j int) bool {
+				return eventLastSeen(events[i]).Before(eventLastSeen(events[j]))
+			})
+		}
+	}
+
+	return diagnosePendingPod(pod, events, time.Now(), timeout)
+}
+
+// eventLastSeen returns when an event last occurred, whichever timestamp field the source set
+func eventLastSeen(event corev1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// printPendingSuggestions prints the targeted suggestions for a pending diagnosis
+func printPendingSuggestions(out io.Writer, diagnosis pendingDiagnosis) {
+	fmt.Fprintf(out, "  %s\n", color.Info(diagnosis.Suggestion))
+	for _, step := range diagnosis.Steps {
+		fmt.Fprintf(out, "  - %s\n", step)
+	}
+	fmt.Fprintln(out)
+}
@@ -0,0 +1,143 @@
+package providers
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDiagnosePendingPod(test *testing.T) {
+	now := time.Now()
+	old := metav1.NewTime(now.Add(-2 * time.Minute))
+	recent := metav1.NewTime(now.Add(-10 * time.Second))
+
+	unschedulablePod := func(message string, since metav1.Time) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", CreationTimestamp: since},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				Conditions: []corev1.PodCondition{{
+					Type:               corev1.PodScheduled,
+					Status:             corev1.ConditionFalse,
+					Reason:             corev1.PodReasonUnschedulable,
+					Message:            message,
+					LastTransitionTime: since,
+				}},
+			},
+		}
+	}
+	scheduledPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", CreationTimestamp: old},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodPending,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodScheduled, Status: corev1.ConditionTrue}},
+		},
+	}
+	warning := func(reason, message string) corev1.Event {
+		return corev1.Event{Type: corev1.EventTypeWarning, Reason: reason, Message: message}
+	}
+
+	tests := []struct {
+		name           string
+		pod            *corev1.Pod
+		events         []corev1.Event
+		timeout        time.Duration // pendingGracePeriod if 0
+		expectedStuck  bool
+		expectedReason string
+	}{
+		{
+			name:           "insufficient resources",
+			pod:            unschedulablePod("0/3 nodes are available: 1 node(s) had untolerated taint {node-role.kubernetes.io/control-plane: }, 2 Insufficient memory.", old),
+			expectedStuck:  true,
+			expectedReason: "InsufficientResources",
+		},
+		{
+			name:           "unbound pvc",
+			pod:            unschedulablePod("0/1 nodes are available: pod has unbound immediate PersistentVolumeClaims.", old),
+			expectedStuck:  true,
+			expectedReason: "UnboundPersistentVolumeClaim",
+		},
+		{
+			name:           "anti-affinity",
+			pod:            unschedulablePod("0/1 nodes are available: 1 node(s) didn't match pod anti-affinity rules.", old),
+			expectedStuck:  true,
+			expectedReason: "PodAffinity",
+		},
+		{
+			name:           "node selector",
+			pod:            unschedulablePod("0/1 nodes are available: 1 node(s) didn't match Pod's node affinity/selector.", old),
+			expectedStuck:  true,
+			expectedReason: "NodeSelector",
+		},
+		{
+			name:          "unschedulable within grace period",
+			pod:           unschedulablePod("0/1 nodes are available: 1 Insufficient cpu.", recent),
+			expectedStuck: false,
+		},
+		{
+			name:          "unschedulable within a longer pending_timeout",
+			pod:           unschedulablePod("0/1 nodes are available: 1 Insufficient cpu.", old),
+			timeout:       5 * time.Minute,
+			expectedStuck: false,
+		},
+		{
+			name:           "unschedulable past a shorter pending_timeout",
+			pod:            unschedulablePod("0/1 nodes are available: 1 Insufficient cpu.", recent),
+			timeout:        5 * time.Second,
+			expectedStuck:  true,
+			expectedReason: "InsufficientResources",
+		},
+		{
+			name:           "missing secret volume",
+			pod:            scheduledPod,
+			events:         []corev1.Event{warning("FailedMount", `MountVolume.SetUp failed for volume "config" : secret "api-config" not found`)},
+			expectedStuck:  true,
+			expectedReason: "FailedMount",
+		},
+		{
+			name:           "missing image pull secret",
+			pod:            scheduledPod,
+			events:         []corev1.Event{warning("FailedToRetrieveImagePullSecret", `Unable to retrieve some image pull secrets (regcred)`)},
+			expectedStuck:  true,
+			expectedReason: "MissingImagePullSecret",
+		},
+		{
+			name:          "slow image pull is not diagnosed",
+			pod:           scheduledPod,
+			events:        []corev1.Event{{Type: corev1.EventTypeNormal, Reason: "Pulling", Message: "Pulling image \"big:latest\""}},
+			expectedStuck: false,
+		},
+		{
+			name: "running pod",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "web-1", CreationTimestamp: old},
+				Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+			},
+			expectedStuck: false,
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			timeout := tt.timeout
+			if timeout == 0 {
+				timeout = pendingGracePeriod
+			}
+			diagnosis, stuck := diagnosePendingPod(tt.pod, tt.events, now, timeout)
+			if stuck != tt.expectedStuck {
+				test.Fatalf("stuck = %v, want %v (diagnosis: %+v)", stuck, tt.expectedStuck, diagnosis)
+			}
+			if !stuck {
+				return
+			}
+			if diagnosis.Reason != tt.expectedReason {
+				test.Errorf("reason = %q, want %q", diagnosis.Reason, tt.expectedReason)
+			}
+			if diagnosis.Suggestion == "" || len(diagnosis.Steps) == 0 {
+				test.Errorf("expected suggestions, got %+v", diagnosis)
+			}
+		})
+	}
+}
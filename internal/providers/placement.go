@@ -0,0 +1,127 @@
+package providers
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// ImagePlacement maps each image used by rendered workloads to the nodes their
+// pods can be scheduled on, considering cordoning, taints, nodeSelector and
+// required node affinity. Node names are sorted. Workloads whose pods fit no
+// node are left out, so their images are treated like unknown ones and loaded
+// everywhere.
+func ImagePlacement(resources []*unstructured.Unstructured, nodes []corev1.Node) map[string][]string {
+	placed := make(map[string]map[string]bool)
+	for _, obj := range resources {
+		workload, ok := workloadFromObject(obj)
+		if !ok {
+			continue
+		}
+		spec := workload.template.Spec
+
+		var eligible []string
+		for _, node := range eligibleNodes(spec, nodes) {
+			if matchesRequiredNodeAffinity(spec.Affinity, node.Labels) {
+				eligible = append(eligible, node.Name)
+			}
+		}
+		if len(eligible) == 0 {
+			continue
+		}
+
+		for _, image := range podImages(spec) {
+			if placed[image] == nil {
+				placed[image] = make(map[string]bool)
+			}
+			for _, name := range eligible {
+				placed[image][name] = true
+			}
+		}
+	}
+
+	placement := make(map[string][]string, len(placed))
+	for image, nodeNames := range placed {
+		for name := range nodeNames {
+			placement[image] = append(placement[image], name)
+		}
+		sort.Strings(placement[image])
+	}
+	return placement
+}
+
+// podImages returns the images of a pod's containers and image volumes
+func podImages(spec corev1.PodSpec) []string {
+	var images []string
+	for _, container := range spec.InitContainers {
+		images = append(images, container.Image)
+	}
+	for _, container := range spec.Containers {
+		images = append(images, container.Image)
+	}
+	for _, volume := range spec.Volumes {
+		if volume.Image != nil {
+			images = append(images, volume.Image.Reference)
+		}
+	}
+
+	result := images[:0]
+	for _, image := range images {
+		if image != "" {
+			result = append(result, image)
+		}
+	}
+	return result
+}
+
+// matchesRequiredNodeAffinity returns true if a node's labels satisfy a pod's
+// required node affinity: any one of its terms, with all of a term's expressions
+func matchesRequiredNodeAffinity(affinity *corev1.Affinity, nodeLabels map[string]string) bool {
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+
+	for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		if len(term.MatchExpressions) == 0 {
+			// Terms that only match fields (metadata.name) aren't evaluated
+			if len(term.MatchFields) > 0 {
+				return true
+			}
+			continue
+		}
+		selector, err := nodeSelectorTermSelector(term)
+		if err != nil {
+			// The API server rejects invalid terms, so they can't narrow placement
+			return true
+		}
+		if selector.Matches(labels.Set(nodeLabels)) {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeSelectorTermSelector converts a node selector term's expressions to a label selector
+func nodeSelectorTermSelector(term corev1.NodeSelectorTerm) (labels.Selector, error) {
+	operators := map[corev1.NodeSelectorOperator]selection.Operator{
+		corev1.NodeSelectorOpIn:           selection.In,
+		corev1.NodeSelectorOpNotIn:        selection.NotIn,
+		corev1.NodeSelectorOpExists:       selection.Exists,
+		corev1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+		corev1.NodeSelectorOpGt:           selection.GreaterThan,
+		corev1.NodeSelectorOpLt:           selection.LessThan,
+	}
+
+	selector := labels.NewSelector()
+	for _, expression := range term.MatchExpressions {
+		requirement, err := labels.NewRequirement(expression.Key, operators[expression.Operator], expression.Values)
+		if err != nil {
+			return nil, err
+		}
+		selector = selector.Add(*requirement)
+	}
+	return selector, nil
+}
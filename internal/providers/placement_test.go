@@ -0,0 +1,155 @@
+package providers
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const placementTestGPUDeployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: trainer
+spec:
+  selector:
+    matchLabels:
+      app: trainer
+  template:
+    metadata:
+      labels:
+        app: trainer
+    spec:
+      nodeSelector:
+        accelerator: gpu
+      initContainers:
+        - name: fetch
+          image: busybox:1.36
+      containers:
+        - name: trainer
+          image: trainer:dev
+`
+
+const placementTestAffinityJob = `
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: report
+spec:
+  schedule: "@daily"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          affinity:
+            nodeAffinity:
+              requiredDuringSchedulingIgnoredDuringExecution:
+                nodeSelectorTerms:
+                  - matchExpressions:
+                      - key: disk
+                        operator: In
+                        values: [ssd]
+                  - matchExpressions:
+                      - key: accelerator
+                        operator: Exists
+          containers:
+            - name: report
+              image: report:dev
+`
+
+const placementTestDaemonSet = `
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: agent
+spec:
+  selector:
+    matchLabels:
+      app: agent
+  template:
+    metadata:
+      labels:
+        app: agent
+    spec:
+      tolerations:
+        - operator: Exists
+      containers:
+        - name: agent
+          image: agent:dev
+`
+
+const placementTestUnschedulable = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: nowhere
+spec:
+  nodeSelector:
+    disk: nvme
+  containers:
+    - name: nowhere
+      image: nowhere:dev
+`
+
+func TestImagePlacement(test *testing.T) {
+	controlPlaneTaint := corev1.Taint{Key: "node-role.kubernetes.io/control-plane", Effect: corev1.TaintEffectNoSchedule}
+	nodes := []corev1.Node{
+		lintTestNode("kraze-control-plane", "4", "8Gi", nil, controlPlaneTaint),
+		lintTestNode("kraze-worker", "4", "8Gi", map[string]string{"accelerator": "gpu"}),
+		lintTestNode("kraze-worker2", "4", "8Gi", map[string]string{"disk": "ssd"}),
+	}
+
+	tests := []struct {
+		name     string
+		docs     []string
+		expected map[string][]string
+	}{
+		{
+			name: "node selector",
+			docs: []string{placementTestGPUDeployment},
+			expected: map[string][]string{
+				"busybox:1.36": {"kraze-worker"},
+				"trainer:dev":  {"kraze-worker"},
+			},
+		},
+		{
+			name:     "required node affinity terms are alternatives",
+			docs:     []string{placementTestAffinityJob},
+			expected: map[string][]string{"report:dev": {"kraze-worker", "kraze-worker2"}},
+		},
+		{
+			name:     "tolerating every taint places on every node",
+			docs:     []string{placementTestDaemonSet},
+			expected: map[string][]string{"agent:dev": {"kraze-control-plane", "kraze-worker", "kraze-worker2"}},
+		},
+		{
+			name:     "unconstrained workload skips the tainted control plane",
+			docs:     []string{lintTestSmallDeployment},
+			expected: map[string][]string{"api": {"kraze-worker", "kraze-worker2"}},
+		},
+		{
+			name:     "workload fitting no node is left out",
+			docs:     []string{placementTestUnschedulable},
+			expected: map[string][]string{},
+		},
+		{
+			name: "images shared by workloads take the union",
+			docs: []string{placementTestGPUDeployment, placementTestAffinityJob},
+			expected: map[string][]string{
+				"busybox:1.36": {"kraze-worker"},
+				"trainer:dev":  {"kraze-worker"},
+				"report:dev":   {"kraze-worker", "kraze-worker2"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			got := ImagePlacement(lintTestObjects(test, tt.docs...), nodes)
+			if !reflect.DeepEqual(got, tt.expected) {
+				test.Errorf("ImagePlacement() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
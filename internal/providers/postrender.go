@@ -0,0 +1,174 @@
+package providers
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/config"
+	"helm.sh/helm/v4/pkg/postrenderer"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kustomizetypes "sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// postRenderer applies a helm service's post_render patches and command, then
+// its resources_override, local_tls, discovery injection and suspended
+// CronJobs, to the chart's rendered manifests
+type postRenderer struct {
+	config          *config.PostRenderConfig
+	service         *config.ServiceConfig
+	suspendCronJobs bool
+	changes         []ResourceChange // What resources_override changed in the last run
+}
+
+// newPostRenderer returns the post-renderer for a helm service, or nil if the
+// service has no post_render, resources_override, local_tls or discovery
+// settings and its CronJobs aren't suspended
+func newPostRenderer(service *config.ServiceConfig, suspendCronJobs bool) postrenderer.PostRenderer {
+	if service.PostRender == nil && !service.ResourcesOverride.IsEnabled() && service.LocalTLS == nil && service.DiscoveryConfigMap == "" && !suspendCronJobs {
+		return nil
+	}
+	postRender := service.PostRender
+	if postRender == nil {
+		postRender = &config.PostRenderConfig{}
+	}
+	return &postRenderer{config: postRender, service: service, suspendCronJobs: suspendCronJobs}
+}
+
+// postRenderChanges returns what a post-renderer's resources_override changed
+func postRenderChanges(renderer postrenderer.PostRenderer) []ResourceChange {
+	if renderer, ok := renderer.(*postRenderer); ok {
+		return renderer.changes
+	}
+	return nil
+}
+
+// Run implements postrenderer.PostRenderer
+func (renderer *postRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	manifests := renderedManifests.Bytes()
+	if len(renderer.config.Patches) > 0 {
+		patched, err := applyPatches(manifests, renderer.config.Patches)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply post_render patches: %w", err)
+		}
+		manifests = patched
+	}
+	if renderer.config.Exec != "" {
+		out, err := runPostRenderCommand(manifests, renderer.config)
+		if err != nil {
+			return nil, err
+		}
+		manifests = out
+	}
+	if renderer.service.ResourcesOverride.IsEnabled() {
+		overridden, changes, err := overrideManifestResources(manifests, renderer.service.ResourcesOverride)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply resources_override: %w", err)
+		}
+		manifests = overridden
+		renderer.changes = changes
+	}
+	if renderer.service.LocalTLS != nil {
+		withTLS, err := transformManifests(manifests, func(obj *unstructured.Unstructured) bool {
+			return injectIngressTLS(obj, renderer.service)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply local_tls: %w", err)
+		}
+		manifests = withTLS
+	}
+	if renderer.service.DiscoveryConfigMap != "" {
+		injected, err := transformManifests(manifests, func(obj *unstructured.Unstructured) bool {
+			return injectDiscovery(obj, renderer.service)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to inject discovery ConfigMap: %w", err)
+		}
+		manifests = injected
+	}
+	if renderer.suspendCronJobs {
+		suspended, err := transformManifests(manifests, suspendCronJob)
+		if err != nil {
+			return nil, fmt.Errorf("failed to suspend cronjobs: %w", err)
+		}
+		manifests = suspended
+	}
+	return bytes.NewBuffer(manifests), nil
+}
+
+// applyPatches applies kustomize patches to multi-document YAML, in an
+// in-memory kustomization so nothing is written to disk
+func applyPatches(manifests []byte, patches []config.PostRenderPatch) ([]byte, error) {
+	kustomization := kustomizetypes.Kustomization{
+		TypeMeta: kustomizetypes.TypeMeta{
+			APIVersion: kustomizetypes.KustomizationVersion,
+			Kind:       kustomizetypes.KustomizationKind,
+		},
+		Resources: []string{"manifests.yaml"},
+	}
+	for _, patch := range patches {
+		kustomization.Patches = append(kustomization.Patches, kustomizetypes.Patch{
+			Patch:  patch.Patch,
+			Target: patchSelector(patch.Target),
+		})
+	}
+	kustomizationYAML, err := sigsyaml.Marshal(kustomization)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode kustomization: %w", err)
+	}
+
+	fs := filesys.MakeFsInMemory()
+	if err := fs.WriteFile("/manifests.yaml", manifests); err != nil {
+		return nil, err
+	}
+	if err := fs.WriteFile("/kustomization.yaml", kustomizationYAML); err != nil {
+		return nil, err
+	}
+
+	resources, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fs, "/")
+	if err != nil {
+		return nil, err
+	}
+	return resources.AsYaml()
+}
+
+// patchSelector converts a patch target to a kustomize selector
+func patchSelector(target *config.PatchTarget) *kustomizetypes.Selector {
+	if target == nil {
+		return nil
+	}
+	return &kustomizetypes.Selector{
+		ResId: resid.ResId{
+			Gvk:       resid.Gvk{Group: target.Group, Version: target.Version, Kind: target.Kind},
+			Name:      target.Name,
+			Namespace: target.Namespace,
+		},
+		LabelSelector:      target.LabelSelector,
+		AnnotationSelector: target.AnnotationSelector,
+	}
+}
+
+// runPostRenderCommand pipes the manifests through the post_render command
+func runPostRenderCommand(manifests []byte, postRender *config.PostRenderConfig) ([]byte, error) {
+	cmd := exec.Command(postRender.Exec, postRender.Args...)
+	cmd.Dir = postRender.Dir
+	cmd.Stdin = bytes.NewReader(manifests)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("post_render command %s failed: %s", postRender.Exec, msg)
+		}
+		return nil, fmt.Errorf("post_render command %s failed: %w", postRender.Exec, err)
+	}
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return nil, fmt.Errorf("post_render command %s produced no manifests", postRender.Exec)
+	}
+	return stdout.Bytes(), nil
+}
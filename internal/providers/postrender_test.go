@@ -0,0 +1,158 @@
+package providers
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+)
+
+const postRenderManifests = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  template:
+    spec:
+      containers:
+        - name: api
+          image: api:1.0
+          imagePullPolicy: Always
+          resources:
+            requests:
+              cpu: "2"
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: api
+spec:
+  ports:
+    - port: 80
+`
+
+func TestPostRendererPatches(test *testing.T) {
+	tests := []struct {
+		name        string
+		patches     []config.PostRenderPatch
+		contains    []string
+		notContains []string
+	}{
+		{
+			name: "strategic merge",
+			patches: []config.PostRenderPatch{{Patch: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  template:
+    spec:
+      containers:
+        - name: api
+          imagePullPolicy: IfNotPresent
+`}},
+			contains:    []string{"imagePullPolicy: IfNotPresent", "image: api:1.0"},
+			notContains: []string{"imagePullPolicy: Always"},
+		},
+		{
+			name: "json6902 with target",
+			patches: []config.PostRenderPatch{{
+				Target: &config.PatchTarget{Kind: "Deployment"},
+				Patch: `- op: remove
+  path: /spec/template/spec/containers/0/resources`,
+			}},
+			contains:    []string{"image: api:1.0"},
+			notContains: []string{"cpu:"},
+		},
+		{
+			name: "strategic merge sidecar",
+			patches: []config.PostRenderPatch{{
+				Target: &config.PatchTarget{Kind: "Deployment", Name: "api"},
+				Patch: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: any
+spec:
+  template:
+    spec:
+      containers:
+        - name: proxy
+          image: envoy:1.30
+`,
+			}},
+			contains: []string{"name: proxy", "name: api", "kind: Service"},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			renderer := newPostRenderer(&config.ServiceConfig{PostRender: &config.PostRenderConfig{Patches: tt.patches}}, false)
+			out, err := renderer.Run(bytes.NewBufferString(postRenderManifests))
+			if err != nil {
+				test.Fatalf("Run() error = %v", err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(out.String(), want) {
+					test.Errorf("Run() output is missing %q:\n%s", want, out.String())
+				}
+			}
+			for _, unwanted := range tt.notContains {
+				if strings.Contains(out.String(), unwanted) {
+					test.Errorf("Run() output contains %q:\n%s", unwanted, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestPostRendererExec(test *testing.T) {
+	if runtime.GOOS == "windows" {
+		test.Skip("uses sh")
+	}
+
+	renderer := newPostRenderer(&config.ServiceConfig{PostRender: &config.PostRenderConfig{
+		Patches: []config.PostRenderPatch{{
+			Target: &config.PatchTarget{Kind: "Service"},
+			Patch:  "- op: replace\n  path: /spec/ports/0/port\n  value: 8080",
+		}},
+		Exec: "sh",
+		Args: []string{"-c", "sed 's/api:1.0/api:dev/'"},
+	}}, false)
+	out, err := renderer.Run(bytes.NewBufferString(postRenderManifests))
+	if err != nil {
+		test.Fatalf("Run() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "image: api:dev") || !strings.Contains(out.String(), "port: 8080") {
+		test.Errorf("Run() did not apply both the patches and the command:\n%s", out.String())
+	}
+
+	failing := newPostRenderer(&config.ServiceConfig{PostRender: &config.PostRenderConfig{
+		Exec: "sh",
+		Args: []string{"-c", "echo bad manifest >&2; exit 1"},
+	}}, false)
+	if _, err := failing.Run(bytes.NewBufferString(postRenderManifests)); err == nil || !strings.Contains(err.Error(), "bad manifest") {
+		test.Errorf("Run() error = %v, want the command's stderr", err)
+	}
+}
+
+func TestNewPostRendererWithoutConfig(test *testing.T) {
+	if renderer := newPostRenderer(&config.ServiceConfig{Name: "api"}, false); renderer != nil {
+		test.Errorf("newPostRenderer() = %v, want nil", renderer)
+	}
+}
+
+func TestPostRendererSuspendsCronJobs(test *testing.T) {
+	renderer := newPostRenderer(&config.ServiceConfig{Name: "reports"}, true)
+	if renderer == nil {
+		test.Fatal("newPostRenderer() = nil, want a renderer suspending cronjobs")
+	}
+	out, err := renderer.Run(bytes.NewBufferString("apiVersion: batch/v1\nkind: CronJob\nmetadata:\n  name: cleanup\nspec:\n  schedule: '*/5 * * * *'\n"))
+	if err != nil {
+		test.Fatalf("Run() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "suspend: true") {
+		test.Errorf("Run() did not suspend the cronjob:\n%s", out.String())
+	}
+}
@@ -6,25 +6,34 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
 	"strings"
 	"time"
 
+	kstatus "github.com/fluxcd/cli-utils/pkg/kstatus/status"
 	"github.com/hjames9/kraze/internal/color"
 	"github.com/hjames9/kraze/internal/config"
+	"github.com/hjames9/kraze/internal/telemetry"
+	"helm.sh/helm/v4/pkg/cli"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
+	watchtools "k8s.io/client-go/tools/watch"
 )
 
 // imagePullGracePeriod is how long to tolerate ImagePullBackOff / ErrImagePull on a pod
@@ -33,6 +42,9 @@ import (
 // can transiently hit a pull error even though the image is already present locally.
 const imagePullGracePeriod = 30 * time.Second
 
+// errJobFailed is returned when a Job reports a Failed condition
+var errJobFailed = fmt.Errorf("job failed")
+
 // Provider is the interface that all service providers must implement
 type Provider interface {
 	// Install installs a service
@@ -46,6 +58,9 @@ type Provider interface {
 
 	// IsInstalled checks if a service is currently installed
 	IsInstalled(ctx context.Context, service *config.ServiceConfig) (bool, error)
+
+	// Render returns the resources Install would apply, without changing the cluster
+	Render(ctx context.Context, service *config.ServiceConfig) ([]*unstructured.Unstructured, error)
 }
 
 // ServiceStatus represents the status of a deployed service
@@ -76,8 +91,91 @@ type ProviderOptions struct {
 	// KeepCRDs determines if CRDs should be kept when uninstalling Helm charts
 	KeepCRDs bool
 
+	// ForceCRDDeletion uninstalls services even when CRDs they delete are still
+	// used by custom resources of other services or namespaces
+	ForceCRDDeletion bool
+
 	// Quiet suppresses intermediate status messages (for clean progress UI)
 	Quiet bool
+
+	// WaitForMigrations waits for migration Jobs to complete even when Wait is false,
+	// so that dependent services don't start before migrations have run
+	WaitForMigrations bool
+
+	// ForceConflicts takes ownership of fields managed by other field managers
+	// when server-side applying manifests, instead of failing on the conflict
+	ForceConflicts bool
+
+	// OnWarningEvent is called for Warning events related to resources being waited on.
+	// If nil, notices are printed unless Quiet is set.
+	OnWarningEvent func(EventNotice)
+
+	// OnLintFinding is called for problems found in rendered workloads at install time
+	// (see LintWorkloads). If nil, findings are printed unless Quiet is set.
+	OnLintFinding func(config.LintFinding)
+
+	// PruneResources are the resources a manifests service's last install applied.
+	// Those its manifests no longer contain are deleted after applying.
+	PruneResources []ResourceRef
+
+	// OnApplied is called with the resources a manifests install applied
+	OnApplied func([]ResourceRef)
+
+	// ReadinessRules tell the wait engine when resources of a kind are ready,
+	// keyed by Kind.group (see config.ReadinessRule)
+	ReadinessRules map[string]config.ReadinessRule
+
+	// Workloads make resources optional or give them their own wait timeout
+	// (see config.WorkloadReadiness)
+	Workloads []config.WorkloadReadiness
+
+	// PendingTimeout is how long a pod may stay Pending for a diagnosed cause
+	// before the wait fails, pendingGracePeriod if 0
+	PendingTimeout time.Duration
+
+	// SuspendCronJobs renders the service's CronJobs suspended, so scheduled
+	// jobs only run when triggered with 'kraze run-cron'
+	SuspendCronJobs bool
+
+	// OnOptionalNotReady is called for optional resources that didn't become
+	// ready. If nil, a warning is printed unless Quiet is set.
+	OnOptionalNotReady func(*ResourceWaitError)
+
+	// Registries are the credentials Helm uses for charts in private OCI registries
+	Registries []config.RegistryConfig
+
+	// OnResourcesOverridden is called with the requests and limits the service's
+	// resources_override changed at install time. If nil, they are printed unless
+	// Quiet is set.
+	OnResourcesOverridden func([]ResourceChange)
+
+	// Output receives the provider's messages, stdout if nil
+	Output io.Writer
+}
+
+// output returns the writer the provider's messages go to
+func (opts *ProviderOptions) output() io.Writer {
+	if opts == nil || opts.Output == nil {
+		return os.Stdout
+	}
+	return opts.Output
+}
+
+// pendingTimeout returns how long a pod may stay Pending for a diagnosed cause
+func (opts *ProviderOptions) pendingTimeout() time.Duration {
+	if opts == nil || opts.PendingTimeout <= 0 {
+		return pendingGracePeriod
+	}
+	return opts.PendingTimeout
+}
+
+// optionalNotReady reports an optional resource that didn't become ready
+func (opts *ProviderOptions) optionalNotReady(err *ResourceWaitError) {
+	if opts.OnOptionalNotReady != nil {
+		opts.OnOptionalNotReady(err)
+	} else if !opts.Quiet {
+		fmt.Fprintf(opts.output(), "  %s Optional %s/%s isn't ready, continuing: %v\n", color.Warning(), err.Kind, err.Name, err)
+	}
 }
 
 // NewProvider creates a provider based on the service type
@@ -92,6 +190,20 @@ func NewProvider(service *config.ServiceConfig, opts *ProviderOptions) (Provider
 	}
 }
 
+// RenderOffline renders a service without a cluster, for cluster.none configs.
+// Charts render like 'helm template' (default capabilities, first install) and
+// manifests are namespaced using a list of well-known cluster-scoped kinds.
+func RenderOffline(ctx context.Context, service *config.ServiceConfig, opts *ProviderOptions) ([]*unstructured.Unstructured, error) {
+	switch service.Type {
+	case "helm":
+		return (&HelmProvider{opts: opts, settings: cli.New()}).Render(ctx, service)
+	case "manifests":
+		return (&ManifestsProvider{opts: opts}).Render(ctx, service)
+	default:
+		return nil, fmt.Errorf("unsupported service type: %s", service.Type)
+	}
+}
+
 // CheckNamespaceExists checks if a namespace exists in the cluster
 func CheckNamespaceExists(ctx context.Context, kubeconfig, namespace string) (bool, error) {
 	restConfig, err := getRESTConfigFromKubeconfig(kubeconfig)
@@ -101,7 +213,8 @@ func CheckNamespaceExists(ctx context.Context, kubeconfig, namespace string) (bo
 	return namespaceExists(ctx, restConfig, namespace)
 }
 
-// getRESTConfigFromKubeconfig creates a REST config from kubeconfig content
+// getRESTConfigFromKubeconfig creates a REST config from kubeconfig content. Any
+// impersonation set by ImpersonateKubeconfig carries over to the REST config.
 func getRESTConfigFromKubeconfig(kubeconfigContent string) (*rest.Config, error) {
 	if kubeconfigContent == "" {
 		return nil, fmt.Errorf("kubeconfig content is empty")
@@ -117,15 +230,6 @@ func getRESTConfigFromKubeconfig(kubeconfigContent string) (*rest.Config, error)
 		return nil, fmt.Errorf("failed to create REST config: %w", err)
 	}
 
-	// Skip TLS verification when connecting via container IP
-	// This is necessary because the API server cert may not include all network IPs
-	if restConfig.TLSClientConfig.CAData != nil || restConfig.TLSClientConfig.CAFile != "" {
-		restConfig.TLSClientConfig.Insecure = true
-		// Clear CA data when using insecure mode
-		restConfig.TLSClientConfig.CAData = nil
-		restConfig.TLSClientConfig.CAFile = ""
-	}
-
 	return restConfig, nil
 }
 
@@ -397,6 +501,47 @@ func WaitForNamespaceDeletion(ctx context.Context, kubeconfig, namespace string,
 	}
 }
 
+// ResourceWaitError is returned when a resource doesn't become ready in time or fails
+type ResourceWaitError struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Timeout   bool // The wait timed out rather than the resource failing
+	Err       error
+}
+
+func (err *ResourceWaitError) Error() string {
+	if err.Timeout {
+		return fmt.Sprintf("timeout waiting for %s/%s to be ready", err.Kind, err.Name)
+	}
+	return fmt.Sprintf("error waiting for %s/%s: %v", err.Kind, err.Name, err.Err)
+}
+
+func (err *ResourceWaitError) Unwrap() error {
+	return err.Err
+}
+
+// WaitForService waits for the resources of a service that's already applied,
+// possibly by other means than kraze (CI steps, kubectl, helm), to become ready
+func WaitForService(ctx context.Context, service *config.ServiceConfig, opts *ProviderOptions) error {
+	provider, err := NewProvider(service, opts)
+	if err != nil {
+		return err
+	}
+
+	var resources []*unstructured.Unstructured
+	if helm, ok := provider.(*HelmProvider); ok {
+		resources, err = helm.releaseResources(ctx, service)
+	} else {
+		resources, err = provider.Render(ctx, service)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find resources of '%s': %w", service.Name, err)
+	}
+
+	return waitForResources(ctx, opts.KubeConfig, resources, service.GetNamespace(), opts)
+}
+
 // WaitForManifests waits for resources defined in YAML manifests to become ready
 // This is a convenience wrapper for WaitForManifestsInNamespace with no default namespace
 func WaitForManifests(ctx context.Context, kubeconfigContent, manifestYAML string, opts *ProviderOptions) error {
@@ -410,6 +555,24 @@ func WaitForManifestsInNamespace(ctx context.Context, kubeconfigContent, manifes
 		return nil
 	}
 
+	// Parse YAML manifests into resources
+	resources, err := parseManifestsYAML(manifestYAML)
+	if err != nil {
+		return fmt.Errorf("failed to parse manifests: %w", err)
+	}
+
+	return waitForResources(ctx, kubeconfigContent, resources, defaultNamespace, opts)
+}
+
+// waitForResources waits for already-parsed resources to become ready
+// The defaultNamespace is applied to resources that don't have a namespace set
+func waitForResources(ctx context.Context, kubeconfigContent string, resources []*unstructured.Unstructured, defaultNamespace string, opts *ProviderOptions) (err error) {
+	if len(resources) == 0 {
+		return nil // Nothing to wait for
+	}
+	ctx, span := telemetry.StartSpan(ctx, "wait.resources")
+	defer func() { telemetry.EndSpan(span, err) }()
+
 	// Parse timeout
 	timeout := 10 * time.Minute // default
 	if opts.Timeout != "" {
@@ -445,65 +608,124 @@ func WaitForManifestsInNamespace(ctx context.Context, kubeconfigContent, manifes
 	cachedDiscoveryClient := memory.NewMemCacheClient(discoveryClient)
 	mapper := restmapper.NewDeferredDiscoveryRESTMapper(cachedDiscoveryClient)
 
-	// Parse YAML manifests into resources
-	resources, err := parseManifestsYAML(manifestYAML)
-	if err != nil {
-		return fmt.Errorf("failed to parse manifests: %w", err)
-	}
-
-	if len(resources) == 0 {
-		return nil // Nothing to wait for
-	}
-
 	if !opts.Quiet {
-		fmt.Printf("Waiting for resources to be ready (timeout: %v)...\n", timeout)
+		fmt.Fprintf(opts.output(), "Waiting for resources to be ready (timeout: %v)...\n", timeout)
 	}
 
 	// Create context with timeout
 	waitCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	// Wait for each resource
+	// Apply default namespace to resources that don't have one and are namespaced
 	for _, obj := range resources {
-		kind := obj.GetKind()
-		name := obj.GetName()
-
-		// Apply default namespace if the resource doesn't have one and is namespaced
 		if obj.GetNamespace() == "" && defaultNamespace != "" {
 			// Check if this resource type is namespaced
-			gvk := obj.GroupVersionKind()
-			mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+			mapping, err := restMappingWithReset(mapper, obj.GroupVersionKind())
 			if err == nil && mapping.Scope.Name() == "namespace" {
 				obj.SetNamespace(defaultNamespace)
 			}
 		}
+	}
 
-		// Only wait for resources that have a meaningful ready state
-		if !shouldWaitForResource(kind) {
-			if opts.Verbose {
-				fmt.Printf("  Skipping wait for %s/%s (not a waitable resource)\n", kind, name)
-			}
-			continue
+	// Surface Warning events (FailedScheduling, FailedMount, OOMKilled, ...) while waiting,
+	// before a pod reaches a terminal failure state. The monitor stops on return
+	// rather than with waitCtx, as resources with a grace period wait longer.
+	defer monitorWarningEvents(ctx, clientset, resources, opts)()
+
+	// Wait for optional resources last, so they can't use up the time of required ones
+	required, optional := splitWaits(resources, opts)
+
+	for _, obj := range required {
+		if waitErr := waitForWorkload(ctx, waitCtx, dynamicClient, clientset, mapper, obj, opts); waitErr != nil {
+			return waitErr
 		}
+	}
 
+	// Later services look up kinds through discovery, which lags behind newly registered APIs
+	if groupVersions := registeredGroupVersions(resources); len(groupVersions) > 0 {
+		if err := waitForDiscovery(waitCtx, clientset.Discovery(), groupVersions, opts.output(), opts.Verbose); err != nil {
+			return err
+		}
+	}
+
+	notReady := 0
+	for _, obj := range optional {
+		// Each optional resource gets the whole timeout, not what the required ones left
+		optionalCtx, cancelOptional := context.WithTimeout(ctx, timeout)
+		waitErr := waitForWorkload(ctx, optionalCtx, dynamicClient, clientset, mapper, obj, opts)
+		cancelOptional()
+		if waitErr != nil {
+			if ctx.Err() != nil {
+				// Interrupted, which isn't the resource's failure
+				return waitErr
+			}
+			notReady++
+			opts.optionalNotReady(waitErr)
+		}
+	}
+	if notReady > 0 {
 		if !opts.Quiet {
-			fmt.Printf("  Waiting for %s/%s to be ready...\n", kind, name)
+			fmt.Fprintf(opts.output(), "%s Required resources are ready (%d optional resource(s) aren't)\n", color.Checkmark(), notReady)
 		}
+		return nil
+	}
+
+	if !opts.Quiet {
+		fmt.Fprintf(opts.output(), "%s All resources are ready\n", color.Checkmark())
+	}
+	return nil
+}
 
-		if err := waitForResourceReady(waitCtx, dynamicClient, clientset, mapper, obj, opts.Verbose); err != nil {
-			if waitCtx.Err() == context.DeadlineExceeded {
-				return fmt.Errorf("timeout waiting for %s/%s to be ready", kind, name)
+// splitWaits returns the resources worth waiting for, split into required ones
+// and the ones the service's workloads mark optional
+func splitWaits(resources []*unstructured.Unstructured, opts *ProviderOptions) (required, optional []*unstructured.Unstructured) {
+	for _, obj := range resources {
+		// Only wait for resources that have a meaningful ready state
+		if !shouldWaitForResource(obj, opts.ReadinessRules) {
+			if opts.Verbose {
+				fmt.Fprintf(opts.output(), "  Skipping wait for %s/%s (not a waitable resource)\n", obj.GetKind(), obj.GetName())
 			}
-			return fmt.Errorf("error waiting for %s/%s: %w", kind, name, err)
+			continue
+		}
+		if workload, ok := config.FindWorkloadReadiness(opts.Workloads, obj.GetKind(), obj.GetName()); ok && workload.Optional {
+			optional = append(optional, obj)
+		} else {
+			required = append(required, obj)
 		}
+	}
+	return required, optional
+}
 
+// waitForWorkload waits for one resource to become ready within waitCtx, or
+// within its grace period from ctx when the service's workloads give it one
+func waitForWorkload(ctx, waitCtx context.Context, dynamicClient dynamic.Interface, clientset *kubernetes.Clientset, mapper *restmapper.DeferredDiscoveryRESTMapper, obj *unstructured.Unstructured, opts *ProviderOptions) *ResourceWaitError {
+	kind := obj.GetKind()
+	name := obj.GetName()
+
+	resourceCtx := waitCtx
+	if workload, ok := config.FindWorkloadReadiness(opts.Workloads, kind, name); ok && workload.GetWaitTimeout() > 0 {
+		var cancel context.CancelFunc
+		resourceCtx, cancel = context.WithTimeout(ctx, workload.GetWaitTimeout())
+		defer cancel()
 		if !opts.Quiet {
-			fmt.Printf("  %s %s/%s is ready\n", color.Checkmark(), kind, name)
+			fmt.Fprintf(opts.output(), "  Waiting for %s/%s to be ready (grace period: %v)...\n", kind, name, workload.GetWaitTimeout())
+		}
+	} else if !opts.Quiet {
+		fmt.Fprintf(opts.output(), "  Waiting for %s/%s to be ready...\n", kind, name)
+	}
+
+	if err := waitForResourceReady(resourceCtx, dynamicClient, clientset, mapper, obj, opts); err != nil {
+		return &ResourceWaitError{
+			Kind:      kind,
+			Namespace: obj.GetNamespace(),
+			Name:      name,
+			Timeout:   resourceCtx.Err() == context.DeadlineExceeded,
+			Err:       err,
 		}
 	}
 
 	if !opts.Quiet {
-		fmt.Printf("%s All resources are ready\n", color.Checkmark())
+		fmt.Fprintf(opts.output(), "  %s %s/%s is ready\n", color.Checkmark(), kind, name)
 	}
 	return nil
 }
@@ -585,22 +807,46 @@ func parseYAMLToUnstructured(content string) (*unstructured.Unstructured, error)
 	return obj, nil
 }
 
-// shouldWaitForResource determines if we should wait for a resource type
-func shouldWaitForResource(kind string) bool {
-	waitableKinds := map[string]bool{
-		"Deployment":  true,
-		"StatefulSet": true,
-		"DaemonSet":   true,
-		"Job":         true,
-		"Pod":         true,
+// shouldWaitForResource determines if we should wait for a resource
+func shouldWaitForResource(obj *unstructured.Unstructured, rules map[string]config.ReadinessRule) bool {
+	if _, ok := readinessRuleFor(rules, obj); ok {
+		return true
 	}
-	return waitableKinds[kind]
+
+	waitableKinds := map[string]bool{
+		"Deployment":          true,
+		"StatefulSet":         true,
+		"DaemonSet":           true,
+		"Job":                 true,
+		"Pod":                 true,
+		kindAPIService:        true,
+		kindCRD:               true,
+		kindValidatingWebhook: true,
+		kindMutatingWebhook:   true,
+	}
+	if waitableKinds[obj.GetKind()] {
+		return true
+	}
+	return isCustomResource(obj)
 }
 
-// waitForResourceReady waits for a specific resource to become ready
-func waitForResourceReady(ctx context.Context, dynamicClient dynamic.Interface, clientset *kubernetes.Clientset, mapper *restmapper.DeferredDiscoveryRESTMapper, obj *unstructured.Unstructured, verbose bool) error {
-	gvk := obj.GroupVersionKind()
-	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+// isCustomResource returns true for resources of third-party API groups, whose
+// readiness kstatus reads from their standard conditions (Ready, Reconciling, Stalled)
+func isCustomResource(obj *unstructured.Unstructured) bool {
+	group := obj.GroupVersionKind().Group
+	return strings.Contains(group, ".") && !strings.HasSuffix(group, ".k8s.io")
+}
+
+// readinessRecheckInterval is how often a watched resource is re-evaluated
+// without changes: for failing pods that don't update the resource itself, and
+// for checks outside the resource (CRD conversion webhooks, image-pull grace periods)
+const readinessRecheckInterval = 5 * time.Second
+
+// waitForResourceReady waits for a specific resource to become ready. The
+// resource is watched, so readiness is seen as soon as its status changes.
+// A readiness rule for its kind replaces the built-in checks.
+func waitForResourceReady(ctx context.Context, dynamicClient dynamic.Interface, clientset *kubernetes.Clientset, mapper *restmapper.DeferredDiscoveryRESTMapper, obj *unstructured.Unstructured, opts *ProviderOptions) error {
+	mapping, err := restMappingWithReset(mapper, obj.GroupVersionKind())
 	if err != nil {
 		return err
 	}
@@ -617,265 +863,256 @@ func waitForResourceReady(ctx context.Context, dynamicClient dynamic.Interface,
 		client = dynamicClient.Resource(gvr)
 	}
 
-	// Track whether we've seen the resource at least once
-	resourceSeen := false
+	// Watch only this resource
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", name).String()
 
-	// Per-pod grace period tracking for image-pull failures (see checkControlledPodsForFailures)
-	imagePullFailFirstSeen := make(map[string]time.Time)
+	tracker := &readinessTracker{
+		clientset:              clientset,
+		kind:                   kind,
+		out:                    opts.output(),
+		verbose:                opts.Verbose,
+		rules:                  opts.ReadinessRules,
+		pendingTimeout:         opts.pendingTimeout(),
+		imagePullFailFirstSeen: make(map[string]time.Time),
+	}
 
-	// Poll until ready
-	ticker := time.NewTicker(2 * time.Second)
+	ticker := time.NewTicker(readinessRecheckInterval)
 	defer ticker.Stop()
 
+	for {
+		// List to get the current state and a resource version to watch from
+		list, err := client.List(ctx, metav1.ListOptions{FieldSelector: fieldSelector})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// Transient error, retry after the next tick
+			if tracker.verbose {
+				fmt.Fprintf(tracker.out, "    Warning: failed to get resource status: %v\n", err)
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+			}
+			continue
+		}
+
+		if len(list.Items) > 0 {
+			if done, err := tracker.update(ctx, &list.Items[0]); done || err != nil {
+				return err
+			}
+		} else if tracker.current != nil {
+			return fmt.Errorf("resource was deleted")
+		} else if tracker.verbose {
+			fmt.Fprintf(tracker.out, "    Resource not found yet, waiting for creation...\n")
+		}
+
+		watcher, err := watchtools.NewRetryWatcherWithContext(ctx, list.GetResourceVersion(), &cache.ListWatch{
+			WatchFuncWithContext: func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+				options.FieldSelector = fieldSelector
+				return client.Watch(ctx, options)
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to watch resource: %w", err)
+		}
+
+		done, err := tracker.watch(ctx, watcher, ticker)
+		watcher.Stop()
+		if done || err != nil {
+			return err
+		}
+		// The watch ended (e.g. its resource version expired), list again
+	}
+}
+
+// readinessTracker follows one resource's state while waiting for it
+type readinessTracker struct {
+	clientset *kubernetes.Clientset
+	kind      string
+	out       io.Writer
+	verbose   bool
+	rules     map[string]config.ReadinessRule
+	current   *unstructured.Unstructured // Latest state, nil until the resource is seen
+	message   string                     // Last progress message printed
+
+	// How long a pod may stay Pending for a diagnosed cause (see checkPendingPod)
+	pendingTimeout time.Duration
+
+	// Per-pod grace period tracking for image-pull failures (see checkControlledPodsForFailures)
+	imagePullFailFirstSeen map[string]time.Time
+}
+
+// watch handles watch events until the resource is ready or fails, or the
+// watch ends, in which case done is false
+func (tracker *readinessTracker) watch(ctx context.Context, watcher watch.Interface, ticker *time.Ticker) (bool, error) {
 	for {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return false, ctx.Err()
 		case <-ticker.C:
-			// Get current state
-			current, err := client.Get(ctx, name, metav1.GetOptions{})
-			if err != nil {
-				if errors.IsNotFound(err) {
-					// Only treat as "deleted" if we've seen it before
-					if resourceSeen {
-						return fmt.Errorf("resource was deleted")
-					}
-					// Resource not created yet, keep waiting
-					if verbose {
-						fmt.Printf("    Resource not found yet, waiting for creation...\n")
-					}
-					continue
-				}
-				// Transient error, continue polling
-				if verbose {
-					fmt.Printf("    Warning: failed to get resource status: %v\n", err)
-				}
+			if tracker.current == nil {
 				continue
 			}
-
-			// We've successfully retrieved the resource
-			resourceSeen = true
-
-			// Check if ready based on kind
-			ready, err := isResourceReady(current, kind)
-			if err != nil {
-				if verbose {
-					fmt.Printf("    Warning: failed to check readiness: %v\n", err)
-				}
-				continue
+			if done, err := tracker.update(ctx, tracker.current); done || err != nil {
+				return done, err
 			}
-
-			if ready {
-				return nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return false, nil
 			}
-
-			// Check for failure states in Pods (direct or owned by this resource)
-			if kind == "Pod" {
-				// Skip Pods that are being terminated - they're expected to go away
-				deletionTimestamp, _, _ := unstructured.NestedString(current.Object, "metadata", "deletionTimestamp")
-				if deletionTimestamp != "" {
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				current, ok := event.Object.(*unstructured.Unstructured)
+				if !ok {
 					continue
 				}
-
-				// Direct Pod resource
-				if failed, failureMsg := checkPodFailureState(current); failed {
-					displayPodDiagnostics(ctx, clientset, current, failureMsg)
-					return fmt.Errorf("pod failed: %s", failureMsg)
+				if done, err := tracker.update(ctx, current); done || err != nil {
+					return done, err
 				}
-			} else if kind == "Deployment" || kind == "StatefulSet" || kind == "DaemonSet" || kind == "Job" {
-				// Check Pods controlled by this resource
-				if err := checkControlledPodsForFailures(ctx, clientset, current, kind, imagePullFailFirstSeen); err != nil {
-					return err
+			case watch.Deleted:
+				if tracker.current != nil {
+					return false, fmt.Errorf("resource was deleted")
 				}
-			}
-
-			// If not ready and verbose, show we're still waiting
-			if verbose {
-				fmt.Printf("    Still waiting (not ready yet)...\n")
+			case watch.Error:
+				if tracker.verbose {
+					fmt.Fprintf(tracker.out, "    Warning: watch failed: %v\n", errors.FromObject(event.Object))
+				}
+				return false, nil
 			}
 		}
 	}
 }
 
-// isResourceReady checks if a resource is ready based on its kind and status
-func isResourceReady(obj *unstructured.Unstructured, kind string) (bool, error) {
-	status, found, err := unstructured.NestedMap(obj.Object, "status")
-	if err != nil {
-		return false, err
-	}
-	if !found {
-		return false, nil
-	}
-
-	switch kind {
-	case "Deployment":
-		return isDeploymentReady(obj, status)
-	case "StatefulSet":
-		return isStatefulSetReady(obj, status)
-	case "DaemonSet":
-		return isDaemonSetReady(obj, status)
-	case "Job":
-		return isJobReady(obj, status)
-	case "Pod":
-		return isPodReady(obj, status)
-	default:
-		// For other resources (like CRDs), try checking status.conditions
-		return hasReadyCondition(status)
-	}
-}
-
-// isDeploymentReady checks if a Deployment is ready
-func isDeploymentReady(obj *unstructured.Unstructured, status map[string]interface{}) (bool, error) {
-	spec, _, err := unstructured.NestedMap(obj.Object, "spec")
-	if err != nil {
-		return false, err
-	}
-
-	desiredReplicas := int64(1) // default
-	if replicas, found, _ := unstructured.NestedInt64(spec, "replicas"); found {
-		desiredReplicas = replicas
-	}
+// update evaluates the latest state of the resource, returning done when it's
+// ready and an error when it failed
+func (tracker *readinessTracker) update(ctx context.Context, current *unstructured.Unstructured) (bool, error) {
+	tracker.current = current
+	kind := tracker.kind
 
-	availableReplicas, found, err := unstructured.NestedInt64(status, "availableReplicas")
-	if err != nil {
-		return false, err
-	}
-	if !found {
+	if rule, ok := readinessRuleFor(tracker.rules, current); ok {
+		ready, message, err := evaluateReadinessRule(current, rule)
+		if err != nil || ready {
+			return ready, err
+		}
+		tracker.progress(message)
 		return false, nil
 	}
 
-	updatedReplicas, found, err := unstructured.NestedInt64(status, "updatedReplicas")
-	if err != nil {
+	ready, message, err := isResourceReady(current, kind)
+	if err == errJobFailed {
+		// A Job that exhausted its retries will never become ready
+		displayJobPodLogs(ctx, tracker.out, tracker.clientset, current.GetNamespace(), current.GetName())
 		return false, err
 	}
-	if !found {
-		return false, nil
-	}
-
-	return availableReplicas >= desiredReplicas && updatedReplicas >= desiredReplicas, nil
-}
-
-// isStatefulSetReady checks if a StatefulSet is ready
-func isStatefulSetReady(obj *unstructured.Unstructured, status map[string]interface{}) (bool, error) {
-	spec, _, err := unstructured.NestedMap(obj.Object, "spec")
 	if err != nil {
-		return false, err
+		// Failures other than a Job's are left to the pod diagnostics below, or the timeout
+		message = fmt.Sprintf("Not ready: %v", err)
 	}
 
-	desiredReplicas := int64(1) // default
-	if replicas, found, _ := unstructured.NestedInt64(spec, "replicas"); found {
-		desiredReplicas = replicas
-	}
-
-	readyReplicas, found, err := unstructured.NestedInt64(status, "readyReplicas")
-	if err != nil {
-		return false, err
-	}
-	if !found {
-		// No readyReplicas field yet - StatefulSet controller hasn't updated status
+	if ready && kind == kindCRD && !conversionWebhookReady(ctx, tracker.clientset, current) {
+		tracker.progress("Waiting for the conversion webhook to have a ready endpoint...")
 		return false, nil
 	}
-
-	// Check if we have enough ready replicas
-	isReady := readyReplicas >= desiredReplicas
-
-	// Debug output for troubleshooting
-	if !isReady {
-		fmt.Printf("      StatefulSet not ready: readyReplicas=%d, desiredReplicas=%d\n", readyReplicas, desiredReplicas)
-	}
-
-	return isReady, nil
-}
-
-// isDaemonSetReady checks if a DaemonSet is ready
-func isDaemonSetReady(obj *unstructured.Unstructured, status map[string]interface{}) (bool, error) {
-	desiredScheduled, found, err := unstructured.NestedInt64(status, "desiredNumberScheduled")
-	if err != nil || !found {
-		return false, err
-	}
-
-	numberReady, found, err := unstructured.NestedInt64(status, "numberReady")
-	if err != nil || !found {
-		return false, err
-	}
-
-	// A DaemonSet with 0 desired pods (no matching nodes) is trivially ready
-	if desiredScheduled == 0 {
-		return true, nil
-	}
-	return numberReady >= desiredScheduled, nil
-}
-
-// isJobReady checks if a Job has completed
-func isJobReady(obj *unstructured.Unstructured, status map[string]interface{}) (bool, error) {
-	succeeded, found, err := unstructured.NestedInt64(status, "succeeded")
-	if err != nil {
-		return false, err
+	if ready && isAdmissionWebhook(kind) {
+		if webhooksReady, service := admissionWebhooksReady(ctx, tracker.clientset, current); !webhooksReady {
+			tracker.progress(fmt.Sprintf("Waiting for webhook service %s to have a ready endpoint...", service))
+			return false, nil
+		}
 	}
-	if found && succeeded > 0 {
+	if ready {
 		return true, nil
 	}
 
-	conditions, found, err := unstructured.NestedSlice(status, "conditions")
-	if err != nil || !found {
-		return false, err
-	}
-
-	for _, cond := range conditions {
-		condMap, ok := cond.(map[string]interface{})
-		if !ok {
-			continue
+	// Check for failure states in Pods (direct or owned by this resource)
+	if kind == "Pod" {
+		// Skip Pods that are being terminated - they're expected to go away
+		if current.GetDeletionTimestamp() != nil {
+			return false, nil
 		}
 
-		condType, _, _ := unstructured.NestedString(condMap, "type")
-		condStatus, _, _ := unstructured.NestedString(condMap, "status")
+		// Direct Pod resource
+		if failed, failureMsg := checkPodFailureState(current); failed {
+			displayPodDiagnostics(ctx, tracker.out, tracker.clientset, current, failureMsg)
+			return false, fmt.Errorf("pod failed: %s", failureMsg)
+		}
 
-		if condType == "Complete" && condStatus == "True" {
-			return true, nil
+		var pod corev1.Pod
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(current.Object, &pod); err == nil {
+			if diagnosis, stuck := checkPendingPod(ctx, tracker.clientset, &pod, tracker.pendingTimeout); stuck {
+				displayPodDiagnostics(ctx, tracker.out, tracker.clientset, current, diagnosis.FailureMessage())
+				printPendingSuggestions(tracker.out, diagnosis)
+				return false, fmt.Errorf("pod pending: %s", diagnosis.FailureMessage())
+			}
 		}
-		if condType == "Failed" && condStatus == "True" {
-			return false, fmt.Errorf("job failed")
+	} else if kind == "Deployment" || kind == "StatefulSet" || kind == "DaemonSet" || kind == "Job" {
+		// Check Pods controlled by this resource
+		if err := checkControlledPodsForFailures(ctx, tracker.out, tracker.clientset, current, kind, tracker.pendingTimeout, tracker.imagePullFailFirstSeen); err != nil {
+			return false, err
 		}
 	}
 
+	// If not ready and verbose, show why when it changes
+	if message != "" {
+		tracker.progress(message)
+	}
 	return false, nil
 }
 
-// isPodReady checks if a Pod is ready.
-// Completed pods (Succeeded) are considered ready — they ran to completion successfully.
-func isPodReady(obj *unstructured.Unstructured, status map[string]interface{}) (bool, error) {
-	phase, found, err := unstructured.NestedString(status, "phase")
-	if err != nil || !found {
-		return false, err
+// progress prints a waiting message in verbose mode, once per change
+func (tracker *readinessTracker) progress(message string) {
+	if !tracker.verbose || message == tracker.message {
+		return
 	}
+	tracker.message = message
+	fmt.Fprintf(tracker.out, "    %s\n", message)
+}
 
-	if phase == "Succeeded" {
-		return true, nil
-	}
-	if phase != "Running" {
-		return false, nil
+// isResourceReady computes a resource's readiness with kstatus, returning a
+// message saying why it isn't ready yet. A resource kstatus reports as failed
+// returns an error (errJobFailed for Jobs).
+func isResourceReady(obj *unstructured.Unstructured, kind string) (bool, string, error) {
+	switch kind {
+	case kindAPIService:
+		// kstatus has no rules for APIServices, and the aggregator's Available
+		// condition isn't one of the standard conditions
+		status, _, _ := unstructured.NestedMap(obj.Object, "status")
+		if available, message, _ := findCondition(status, "Available"); available != "True" {
+			if message != "" {
+				message = "Not available: " + message
+			}
+			return false, message, nil
+		}
+		return true, "", nil
+	case "Pod":
+		// kstatus considers any finished pod current, but only a successful one is ready
+		if phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase"); phase == "Failed" || phase == "Unknown" {
+			return false, fmt.Sprintf("Pod is in the %s phase", phase), nil
+		}
 	}
 
-	conditions, found, err := unstructured.NestedSlice(status, "conditions")
-	if err != nil || !found {
-		return false, err
+	result, err := kstatus.Compute(obj)
+	if err != nil {
+		return false, "", err
 	}
 
-	for _, cond := range conditions {
-		condMap, ok := cond.(map[string]interface{})
-		if !ok {
-			continue
+	switch result.Status {
+	case kstatus.CurrentStatus:
+		// kstatus considers a started Job current, but a Job is ready when it completes
+		if kind == "Job" {
+			status, _, _ := unstructured.NestedMap(obj.Object, "status")
+			complete, _, _ := findCondition(status, "Complete")
+			return complete == "True", result.Message, nil
 		}
-
-		condType, _, _ := unstructured.NestedString(condMap, "type")
-		condStatus, _, _ := unstructured.NestedString(condMap, "status")
-
-		if condType == "Ready" && condStatus == "True" {
-			return true, nil
+		return true, "", nil
+	case kstatus.FailedStatus:
+		if kind == "Job" {
+			return false, result.Message, errJobFailed
 		}
+		return false, result.Message, fmt.Errorf("%s", result.Message)
 	}
-
-	return false, nil
+	return false, result.Message, nil
 }
 
 // isImagePullFailure returns true when the failure message comes from an image-pull error.
@@ -898,7 +1135,7 @@ func withinImagePullGracePeriod(podKey string, firstSeen map[string]time.Time) b
 // checkControlledPodsForFailures checks Pods controlled by a Deployment/StatefulSet/etc for failures.
 // imagePullFailFirstSeen tracks the first time each pod (namespace/name) was seen in an image-pull
 // failure state so that transient pull errors are tolerated for imagePullGracePeriod before failing.
-func checkControlledPodsForFailures(ctx context.Context, clientset *kubernetes.Clientset, obj *unstructured.Unstructured, kind string, imagePullFailFirstSeen map[string]time.Time) error {
+func checkControlledPodsForFailures(ctx context.Context, out io.Writer, clientset *kubernetes.Clientset, obj *unstructured.Unstructured, kind string, pendingTimeout time.Duration, imagePullFailFirstSeen map[string]time.Time) error {
 	namespace := obj.GetNamespace()
 
 	// Get the selector for finding Pods
@@ -965,12 +1202,29 @@ func checkControlledPodsForFailures(ctx context.Context, clientset *kubernetes.C
 			podUnstructured.SetNamespace(pod.Namespace)
 			podUnstructured.SetName(pod.Name)
 
-			displayPodDiagnostics(ctx, clientset, podUnstructured, failureMsg)
+			displayPodDiagnostics(ctx, out, clientset, podUnstructured, failureMsg)
+			if kind == "Job" && pod.Status.Phase == corev1.PodFailed {
+				// Failed Job pods don't report a container name in the failure
+				// message, so show logs from every container
+				displayPodContainerLogs(ctx, out, clientset, &pod)
+			}
 			return fmt.Errorf("%s has failing pod %s: %s", kind, pod.Name, failureMsg)
 		} else {
 			// Pod recovered — clear any grace period state so a future failure on
 			// this pod name starts a fresh timer.
 			delete(imagePullFailFirstSeen, podKey)
+
+			// A pod stuck Pending never reaches a container failure state, so
+			// diagnose it from its conditions and events instead of timing out
+			if diagnosis, stuck := checkPendingPod(ctx, clientset, &pod, pendingTimeout); stuck {
+				podUnstructured := &unstructured.Unstructured{}
+				podUnstructured.SetNamespace(pod.Namespace)
+				podUnstructured.SetName(pod.Name)
+
+				displayPodDiagnostics(ctx, out, clientset, podUnstructured, diagnosis.FailureMessage())
+				printPendingSuggestions(out, diagnosis)
+				return fmt.Errorf("%s has pending pod %s: %s", kind, pod.Name, diagnosis.FailureMessage())
+			}
 		}
 	}
 
@@ -1218,21 +1472,21 @@ func getContainerLogs(ctx context.Context, clientset *kubernetes.Clientset, name
 }
 
 // displayPodDiagnostics shows detailed diagnostics for a failed Pod
-func displayPodDiagnostics(ctx context.Context, clientset *kubernetes.Clientset, obj *unstructured.Unstructured, failureMsg string) {
+func displayPodDiagnostics(ctx context.Context, out io.Writer, clientset *kubernetes.Clientset, obj *unstructured.Unstructured, failureMsg string) {
 	namespace := obj.GetNamespace()
 	podName := obj.GetName()
 
-	fmt.Printf("\n  %s Pod %s/%s is experiencing issues:\n", color.Warning(), namespace, podName)
-	fmt.Printf("  %s\n\n", failureMsg)
+	fmt.Fprintf(out, "\n  %s Pod %s/%s is experiencing issues:\n", color.Warning(), namespace, podName)
+	fmt.Fprintf(out, "  %s\n\n", failureMsg)
 
 	// Fetch and display events
 	events, err := getPodEvents(ctx, clientset, namespace, podName, 5)
 	if err == nil && len(events) > 0 {
-		fmt.Printf("  Recent events:\n")
+		fmt.Fprintf(out, "  Recent events:\n")
 		for _, event := range events {
-			fmt.Println(event)
+			fmt.Fprintln(out, event)
 		}
-		fmt.Println()
+		fmt.Fprintln(out)
 	}
 
 	// Try to get container logs if it's a CrashLoopBackOff
@@ -1242,28 +1496,28 @@ func displayPodDiagnostics(ctx context.Context, clientset *kubernetes.Clientset,
 		if containerName != "" {
 			logs, err := getContainerLogs(ctx, clientset, namespace, podName, containerName, 50)
 			if err == nil && len(logs) > 0 {
-				fmt.Printf("  Last %d log lines from container '%s':\n", len(logs), containerName)
+				fmt.Fprintf(out, "  Last %d log lines from container '%s':\n", len(logs), containerName)
 				for _, log := range logs {
-					fmt.Println(log)
+					fmt.Fprintln(out, log)
 				}
-				fmt.Println()
+				fmt.Fprintln(out)
 			}
 		}
 	}
 
 	// Provide helpful suggestions based on failure type
 	if strings.Contains(failureMsg, "ImagePullBackOff") || strings.Contains(failureMsg, "ErrImagePull") {
-		fmt.Printf("  %s\n", color.Info("Suggestion: Check that the image exists and is accessible"))
-		fmt.Printf("  - For local images, use: kraze load-image <image>\n")
-		fmt.Printf("  - For private registries, ensure imagePullSecrets are configured\n\n")
+		fmt.Fprintf(out, "  %s\n", color.Info("Suggestion: Check that the image exists and is accessible"))
+		fmt.Fprintf(out, "  - For local images, use: kraze load-image <image>\n")
+		fmt.Fprintf(out, "  - For private registries, ensure imagePullSecrets are configured\n\n")
 	} else if strings.Contains(failureMsg, "CrashLoopBackOff") {
-		fmt.Printf("  %s\n", color.Info("Suggestion: The container is crashing on startup"))
-		fmt.Printf("  - Check environment variables and configuration\n")
-		fmt.Printf("  - Review the logs above for error messages\n\n")
+		fmt.Fprintf(out, "  %s\n", color.Info("Suggestion: The container is crashing on startup"))
+		fmt.Fprintf(out, "  - Check environment variables and configuration\n")
+		fmt.Fprintf(out, "  - Review the logs above for error messages\n\n")
 	} else if strings.Contains(failureMsg, "CreateContainerConfigError") {
-		fmt.Printf("  %s\n", color.Info("Suggestion: There's an issue with the container configuration"))
-		fmt.Printf("  - Check ConfigMaps and Secrets are created\n")
-		fmt.Printf("  - Verify volume mounts and environment variables\n\n")
+		fmt.Fprintf(out, "  %s\n", color.Info("Suggestion: There's an issue with the container configuration"))
+		fmt.Fprintf(out, "  - Check ConfigMaps and Secrets are created\n")
+		fmt.Fprintf(out, "  - Verify volume mounts and environment variables\n\n")
 	}
 }
 
@@ -1282,30 +1536,6 @@ func extractContainerName(msg string) string {
 	return msg[start : start+end]
 }
 
-// hasReadyCondition checks if a resource has a Ready=True condition (for CRDs)
-func hasReadyCondition(status map[string]interface{}) (bool, error) {
-	conditions, found, err := unstructured.NestedSlice(status, "conditions")
-	if err != nil || !found {
-		return false, nil
-	}
-
-	for _, cond := range conditions {
-		condMap, ok := cond.(map[string]interface{})
-		if !ok {
-			continue
-		}
-
-		condType, _, _ := unstructured.NestedString(condMap, "type")
-		condStatus, _, _ := unstructured.NestedString(condMap, "status")
-
-		if condType == "Ready" && condStatus == "True" {
-			return true, nil
-		}
-	}
-
-	return false, nil
-}
-
 // patchWorkloadWithConfigChecksum patches a Deployment, StatefulSet, or DaemonSet
 // with a config checksum annotation to force a rollout when the checksum changes
 func patchWorkloadWithConfigChecksum(
@@ -1313,6 +1543,7 @@ func patchWorkloadWithConfigChecksum(
 	dynamicClient dynamic.Interface,
 	mapper *restmapper.DeferredDiscoveryRESTMapper,
 	kind, name, namespace, checksum string,
+	out io.Writer,
 	verbose bool,
 ) error {
 	// Get the GVR for this resource
@@ -1330,7 +1561,7 @@ func patchWorkloadWithConfigChecksum(
 	mapping, err := mapper.RESTMapping(gk, gvk.Version)
 	if err != nil {
 		if verbose {
-			fmt.Printf("Warning: failed to get REST mapping for %s: %v\n", kind, err)
+			fmt.Fprintf(out, "Warning: failed to get REST mapping for %s: %v\n", kind, err)
 		}
 		return err
 	}
@@ -1340,19 +1571,19 @@ func patchWorkloadWithConfigChecksum(
 
 	// Patch the resource with checksum annotation
 	// We use merge patch to add the annotation to spec.template.metadata.annotations
-	patch := fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{"kraze.dev/config-hash":"%s"}}}}}`, checksum)
+	patch := fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{%q:%q}}}}}`, configHashAnnotation, checksum)
 
 	_, err = resourceClient.Patch(ctx, name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
 	if err != nil {
 		// Log warning but don't fail - the resource might not exist yet or might not support patching
 		if verbose {
-			fmt.Printf("Warning: failed to patch %s/%s with config checksum: %v\n", kind, name, err)
+			fmt.Fprintf(out, "Warning: failed to patch %s/%s with config checksum: %v\n", kind, name, err)
 		}
 		return err
 	}
 
 	if verbose {
-		fmt.Printf("Added config checksum annotation to %s/%s (hash: %s)\n", kind, name, checksum[:8])
+		fmt.Fprintf(out, "Added config checksum annotation to %s/%s (hash: %s)\n", kind, name, checksum[:8])
 	}
 
 	return nil
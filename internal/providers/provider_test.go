@@ -1,6 +1,13 @@
 package providers
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -50,6 +57,73 @@ func TestNewProvider(test *testing.T) {
 	}
 }
 
+func TestRenderOffline(test *testing.T) {
+	dir := test.TempDir()
+	writeFile := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			test.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			test.Fatal(err)
+		}
+	}
+	writeFile("chart/Chart.yaml", "apiVersion: v2\nname: web\nversion: 0.1.0\n")
+	writeFile("chart/values.yaml", "replicas: 1\n")
+	writeFile("chart/templates/deployment.yaml", `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}
+  namespace: {{ .Release.Namespace }}
+spec:
+  replicas: {{ .Values.replicas }}
+`)
+	writeFile("k8s/app.yaml", `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: reader
+`)
+
+	tests := []struct {
+		name       string
+		service    *config.ServiceConfig
+		namespaces []string
+	}{
+		{
+			name:       "local chart",
+			service:    &config.ServiceConfig{Name: "web", Type: "helm", Path: filepath.Join(dir, "chart"), Namespace: "web"},
+			namespaces: []string{"web"},
+		},
+		{
+			name:       "manifests",
+			service:    &config.ServiceConfig{Name: "app", Type: "manifests", Path: filepath.Join(dir, "k8s"), Namespace: "app"},
+			namespaces: []string{"app", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			resources, err := RenderOffline(context.Background(), tt.service, &ProviderOptions{Quiet: true})
+			if err != nil {
+				test.Fatalf("RenderOffline() error = %v", err)
+			}
+			if len(resources) != len(tt.namespaces) {
+				test.Fatalf("RenderOffline() returned %d resources, want %d", len(resources), len(tt.namespaces))
+			}
+			for itr, obj := range resources {
+				if obj.GetNamespace() != tt.namespaces[itr] {
+					test.Errorf("%s namespace = %q, want %q", obj.GetName(), obj.GetNamespace(), tt.namespaces[itr])
+				}
+			}
+		})
+	}
+}
+
 func TestIsPodReady(test *testing.T) {
 	tests := []struct {
 		name       string
@@ -102,7 +176,7 @@ func TestIsPodReady(test *testing.T) {
 
 	for _, tt := range tests {
 		test.Run(tt.name, func(test *testing.T) {
-			obj := &unstructured.Unstructured{}
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "Pod"}}
 			status := map[string]interface{}{
 				"phase": tt.phase,
 			}
@@ -114,12 +188,14 @@ func TestIsPodReady(test *testing.T) {
 				status["conditions"] = conds
 			}
 
-			ready, err := isPodReady(obj, status)
+			obj.Object["status"] = status
+
+			ready, _, err := isResourceReady(obj, "Pod")
 			if err != nil {
 				test.Fatalf("unexpected error: %v", err)
 			}
 			if ready != tt.wantReady {
-				test.Errorf("isPodReady(%q) = %v, want %v", tt.phase, ready, tt.wantReady)
+				test.Errorf("isResourceReady(%q) = %v, want %v", tt.phase, ready, tt.wantReady)
 			}
 		})
 	}
@@ -128,6 +204,118 @@ func TestIsPodReady(test *testing.T) {
 // Note: Testing actual Helm and Manifests provider creation requires valid kubeconfig
 // These are tested through integration tests or in actual cluster environments
 
+func TestIsResourceReadyKstatus(test *testing.T) {
+	tests := []struct {
+		name      string
+		object    string
+		wantReady bool
+		wantErr   error
+	}{
+		{
+			name: "running Job is not ready",
+			object: `{"apiVersion": "batch/v1", "kind": "Job", "metadata": {"name": "migrate"},
+				"status": {"startTime": "2024-01-01T00:00:00Z", "active": 1}}`,
+			wantReady: false,
+		},
+		{
+			name: "completed Job is ready",
+			object: `{"apiVersion": "batch/v1", "kind": "Job", "metadata": {"name": "migrate"},
+				"status": {"startTime": "2024-01-01T00:00:00Z", "succeeded": 1,
+					"conditions": [{"type": "Complete", "status": "True"}]}}`,
+			wantReady: true,
+		},
+		{
+			name: "failed Job",
+			object: `{"apiVersion": "batch/v1", "kind": "Job", "metadata": {"name": "migrate"},
+				"status": {"startTime": "2024-01-01T00:00:00Z", "failed": 6,
+					"conditions": [{"type": "Failed", "status": "True"}]}}`,
+			wantErr: errJobFailed,
+		},
+		{
+			name: "Deployment whose spec change isn't observed yet",
+			object: `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "api", "generation": 2},
+				"spec": {"replicas": 1},
+				"status": {"observedGeneration": 1, "replicas": 1, "updatedReplicas": 1, "readyReplicas": 1, "availableReplicas": 1}}`,
+			wantReady: false,
+		},
+		{
+			name: "rolled out Deployment",
+			object: `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "api", "generation": 2},
+				"spec": {"replicas": 1},
+				"status": {"observedGeneration": 2, "replicas": 1, "updatedReplicas": 1, "readyReplicas": 1, "availableReplicas": 1,
+					"conditions": [{"type": "Available", "status": "True"}]}}`,
+			wantReady: true,
+		},
+		{
+			name: "custom resource with Ready=False",
+			object: `{"apiVersion": "cert-manager.io/v1", "kind": "Certificate", "metadata": {"name": "tls"},
+				"status": {"conditions": [{"type": "Ready", "status": "False", "reason": "Issuing"}]}}`,
+			wantReady: false,
+		},
+		{
+			name: "custom resource with Ready=True",
+			object: `{"apiVersion": "cert-manager.io/v1", "kind": "Certificate", "metadata": {"name": "tls"},
+				"status": {"conditions": [{"type": "Ready", "status": "True"}]}}`,
+			wantReady: true,
+		},
+		{
+			name:      "custom resource without status",
+			object:    `{"apiVersion": "monitoring.coreos.com/v1", "kind": "ServiceMonitor", "metadata": {"name": "api"}}`,
+			wantReady: true,
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			obj := &unstructured.Unstructured{}
+			if err := obj.UnmarshalJSON([]byte(tt.object)); err != nil {
+				test.Fatalf("invalid test object: %v", err)
+			}
+
+			ready, _, err := isResourceReady(obj, obj.GetKind())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					test.Fatalf("isResourceReady() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				test.Fatalf("unexpected error: %v", err)
+			}
+			if ready != tt.wantReady {
+				test.Errorf("isResourceReady() = %v, want %v", ready, tt.wantReady)
+			}
+		})
+	}
+}
+
+func TestShouldWaitForResource(test *testing.T) {
+	tests := []struct {
+		apiVersion string
+		kind       string
+		want       bool
+	}{
+		{apiVersion: "apps/v1", kind: "Deployment", want: true},
+		{apiVersion: "batch/v1", kind: "Job", want: true},
+		{apiVersion: "v1", kind: "Service", want: false},
+		{apiVersion: "v1", kind: "PersistentVolumeClaim", want: false},
+		{apiVersion: "networking.k8s.io/v1", kind: "Ingress", want: false},
+		{apiVersion: "cert-manager.io/v1", kind: "Certificate", want: true},
+		{apiVersion: "admissionregistration.k8s.io/v1", kind: "ValidatingWebhookConfiguration", want: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.kind, func(test *testing.T) {
+			obj := &unstructured.Unstructured{}
+			obj.SetAPIVersion(tt.apiVersion)
+			obj.SetKind(tt.kind)
+			if got := shouldWaitForResource(obj, nil); got != tt.want {
+				test.Errorf("shouldWaitForResource(%s %s) = %v, want %v", tt.apiVersion, tt.kind, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestServiceStatus(test *testing.T) {
 	status := &ServiceStatus{
 		Name:      "test-service",
@@ -297,3 +485,109 @@ func TestIsEventRecent(t *testing.T) {
 		})
 	}
 }
+
+func TestResourceWaitError(test *testing.T) {
+	tests := []struct {
+		name     string
+		err      *ResourceWaitError
+		expected string
+	}{
+		{
+			name:     "timeout",
+			err:      &ResourceWaitError{Kind: "Deployment", Name: "api", Timeout: true, Err: context.DeadlineExceeded},
+			expected: "timeout waiting for Deployment/api to be ready",
+		},
+		{
+			name:     "failure",
+			err:      &ResourceWaitError{Kind: "Job", Name: "migrate", Err: errJobFailed},
+			expected: "error waiting for Job/migrate: job failed",
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			wrapped := fmt.Errorf("failed waiting for resources: %w", tt.err)
+			if got := tt.err.Error(); got != tt.expected {
+				test.Errorf("Error() = %q, want %q", got, tt.expected)
+			}
+			var waitErr *ResourceWaitError
+			if !errors.As(wrapped, &waitErr) || waitErr.Name != tt.err.Name {
+				test.Errorf("errors.As() did not find the ResourceWaitError in %v", wrapped)
+			}
+			if !errors.Is(wrapped, tt.err.Err) {
+				test.Errorf("errors.Is(%v, %v) = false", wrapped, tt.err.Err)
+			}
+		})
+	}
+}
+
+func TestOptionalNotReady(test *testing.T) {
+	waitErr := &ResourceWaitError{Kind: "Deployment", Name: "exporter", Timeout: true, Err: context.DeadlineExceeded}
+	tests := []struct {
+		name     string
+		quiet    bool
+		callback bool
+		printed  bool
+	}{
+		{name: "printed by default", printed: true},
+		{name: "quiet", quiet: true},
+		{name: "callback", callback: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			var out bytes.Buffer
+			var reported []*ResourceWaitError
+			opts := &ProviderOptions{Quiet: tt.quiet, Output: &out}
+			if tt.callback {
+				opts.OnOptionalNotReady = func(err *ResourceWaitError) { reported = append(reported, err) }
+			}
+
+			opts.optionalNotReady(waitErr)
+
+			if printed := strings.Contains(out.String(), "Optional Deployment/exporter isn't ready"); printed != tt.printed {
+				test.Errorf("printed = %v, want %v (output: %q)", printed, tt.printed, out.String())
+			}
+			if tt.callback && (len(reported) != 1 || reported[0] != waitErr) {
+				test.Errorf("callback got %v, want the wait error", reported)
+			}
+		})
+	}
+}
+
+func TestSplitWaits(test *testing.T) {
+	resource := func(apiVersion, kind, name string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		obj.SetName(name)
+		return obj
+	}
+	resources := []*unstructured.Unstructured{
+		resource("apps/v1", "Deployment", "api"),
+		resource("apps/v1", "Deployment", "node-exporter"),
+		resource("v1", "Service", "api"),
+		resource("apps/v1", "StatefulSet", "indexer"),
+		resource("apps/v1", "DaemonSet", "log-exporter"),
+	}
+	opts := &ProviderOptions{Workloads: []config.WorkloadReadiness{
+		{Kind: "Deployment", Name: "*-exporter", Optional: true},
+		{Kind: "StatefulSet", Name: "indexer", WaitTimeout: "15m"},
+	}}
+
+	names := func(objs []*unstructured.Unstructured) string {
+		var result []string
+		for _, obj := range objs {
+			result = append(result, obj.GetKind()+"/"+obj.GetName())
+		}
+		return strings.Join(result, ",")
+	}
+	required, optional := splitWaits(resources, opts)
+	// A grace period keeps a resource required, and a workload's kind limits what it matches
+	if want := "Deployment/api,StatefulSet/indexer,DaemonSet/log-exporter"; names(required) != want {
+		test.Errorf("required = %v, want %v", names(required), want)
+	}
+	if want := "Deployment/node-exporter"; names(optional) != want {
+		test.Errorf("optional = %v, want %v", names(optional), want)
+	}
+}
@@ -0,0 +1,147 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/color"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// unprunableKinds are never pruned: deleting them would delete everything in
+// them (a namespace's resources, a CRD's custom resources)
+var unprunableKinds = map[string]bool{
+	"Namespace": true,
+	kindCRD:     true,
+}
+
+// ResourceRef identifies a resource applied for a service, as
+// "<apiVersion>:<kind>:<namespace>:<name>" (namespace empty when cluster-scoped)
+type ResourceRef string
+
+// NewResourceRef returns the reference to an object
+func NewResourceRef(obj *unstructured.Unstructured) ResourceRef {
+	return ResourceRef(strings.Join([]string{obj.GetAPIVersion(), obj.GetKind(), obj.GetNamespace(), obj.GetName()}, ":"))
+}
+
+// ResourceRefs returns the sorted references to objects
+func ResourceRefs(objs []*unstructured.Unstructured) []ResourceRef {
+	refs := make([]ResourceRef, 0, len(objs))
+	for _, obj := range objs {
+		refs = append(refs, NewResourceRef(obj))
+	}
+	sort.Slice(refs, func(left, right int) bool { return refs[left] < refs[right] })
+	return refs
+}
+
+// parse splits a reference into its object, or returns false if it's malformed.
+// The name comes last and may contain colons (e.g., ClusterRole system:auth-delegator).
+func (ref ResourceRef) parse() (*unstructured.Unstructured, bool) {
+	parts := strings.SplitN(string(ref), ":", 4)
+	if len(parts) != 4 || parts[0] == "" || parts[1] == "" || parts[3] == "" {
+		return nil, false
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(parts[0])
+	obj.SetKind(parts[1])
+	obj.SetNamespace(parts[2])
+	obj.SetName(parts[3])
+	return obj, true
+}
+
+// String returns the reference as Kind/name or Kind/namespace/name
+func (ref ResourceRef) String() string {
+	obj, ok := ref.parse()
+	if !ok {
+		return string(ref)
+	}
+	if obj.GetNamespace() == "" {
+		return obj.GetKind() + "/" + obj.GetName()
+	}
+	return obj.GetKind() + "/" + obj.GetNamespace() + "/" + obj.GetName()
+}
+
+// identity returns the reference without its API version, which doesn't change
+// which object it names (a manifest moved from v1beta1 to v1 is the same object)
+func (ref ResourceRef) identity() string {
+	obj, ok := ref.parse()
+	if !ok {
+		return string(ref)
+	}
+	return strings.Join([]string{obj.GroupVersionKind().Group, obj.GetKind(), obj.GetNamespace(), obj.GetName()}, ":")
+}
+
+// StaleResources returns the previously applied resources that aren't in the current set
+func StaleResources(previous, current []ResourceRef) []ResourceRef {
+	applied := make(map[string]bool, len(current))
+	for _, ref := range current {
+		applied[ref.identity()] = true
+	}
+	var stale []ResourceRef
+	for _, ref := range previous {
+		if !applied[ref.identity()] {
+			stale = append(stale, ref)
+		}
+	}
+	return stale
+}
+
+// pruneResources deletes resources a service applied before that it no longer
+// renders. A resource is only deleted while it still carries the service's
+// tracking labels, so one that was adopted by something else is left alone.
+// It returns the resources deleted.
+func pruneResources(ctx context.Context, client dynamic.Interface, resolve func(*unstructured.Unstructured) (schema.GroupVersionResource, error), serviceName string, stale []ResourceRef, opts *ProviderOptions) ([]ResourceRef, error) {
+	propagation := metav1.DeletePropagationBackground
+	var pruned []ResourceRef
+	for _, ref := range stale {
+		obj, ok := ref.parse()
+		if !ok || unprunableKinds[obj.GetKind()] {
+			if opts.Verbose {
+				fmt.Fprintf(opts.output(), "  Not pruning %s\n", ref)
+			}
+			continue
+		}
+
+		gvr, err := resolve(obj)
+		if err != nil {
+			if opts.Verbose {
+				fmt.Fprintf(opts.output(), "  Warning: can't prune %s: %v\n", ref, err)
+			}
+			continue
+		}
+		var resource dynamic.ResourceInterface = client.Resource(gvr)
+		if obj.GetNamespace() != "" {
+			resource = client.Resource(gvr).Namespace(obj.GetNamespace())
+		}
+
+		live, err := resource.Get(ctx, obj.GetName(), metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			continue // Already gone
+		}
+		if err != nil {
+			return pruned, fmt.Errorf("failed to get %s: %w", ref, err)
+		}
+		labels := live.GetLabels()
+		if labels[managedByLabel] != "kraze" || labels[serviceLabel] != serviceName {
+			if opts.Verbose {
+				fmt.Fprintf(opts.output(), "  Not pruning %s: it's no longer labeled as part of '%s'\n", ref, serviceName)
+			}
+			continue
+		}
+
+		if err := resource.Delete(ctx, obj.GetName(), metav1.DeleteOptions{PropagationPolicy: &propagation}); err != nil && !errors.IsNotFound(err) {
+			return pruned, fmt.Errorf("failed to prune %s: %w", ref, err)
+		}
+		if !opts.Quiet {
+			fmt.Fprintf(opts.output(), "  %s Pruned %s\n", color.Checkmark(), ref)
+		}
+		pruned = append(pruned, ref)
+	}
+	return pruned, nil
+}
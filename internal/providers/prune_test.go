@@ -0,0 +1,148 @@
+package providers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestResourceRef(test *testing.T) {
+	tests := []struct {
+		name     string
+		obj      *unstructured.Unstructured
+		expected ResourceRef
+		display  string
+	}{
+		{
+			name:     "namespaced",
+			obj:      newLabeledObject("apps/v1", "Deployment", "shared", "web", nil),
+			expected: "apps/v1:Deployment:shared:web",
+			display:  "Deployment/shared/web",
+		},
+		{
+			name:     "cluster-scoped",
+			obj:      newLabeledObject("rbac.authorization.k8s.io/v1", "ClusterRole", "", "web-reader", nil),
+			expected: "rbac.authorization.k8s.io/v1:ClusterRole::web-reader",
+			display:  "ClusterRole/web-reader",
+		},
+		{
+			name:     "name with colons",
+			obj:      newLabeledObject("rbac.authorization.k8s.io/v1", "ClusterRoleBinding", "", "system:web:auth-delegator", nil),
+			expected: "rbac.authorization.k8s.io/v1:ClusterRoleBinding::system:web:auth-delegator",
+			display:  "ClusterRoleBinding/system:web:auth-delegator",
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			ref := NewResourceRef(tt.obj)
+			if ref != tt.expected {
+				test.Errorf("NewResourceRef() = %q, want %q", ref, tt.expected)
+			}
+			if ref.String() != tt.display {
+				test.Errorf("String() = %q, want %q", ref.String(), tt.display)
+			}
+		})
+	}
+}
+
+func TestStaleResources(test *testing.T) {
+	tests := []struct {
+		name     string
+		previous []ResourceRef
+		current  []ResourceRef
+		expected []ResourceRef
+	}{
+		{
+			name:     "removed resource is stale",
+			previous: []ResourceRef{"v1:ConfigMap:shared:web-config", "apps/v1:Deployment:shared:web"},
+			current:  []ResourceRef{"apps/v1:Deployment:shared:web"},
+			expected: []ResourceRef{"v1:ConfigMap:shared:web-config"},
+		},
+		{
+			name:     "nothing removed",
+			previous: []ResourceRef{"apps/v1:Deployment:shared:web"},
+			current:  []ResourceRef{"apps/v1:Deployment:shared:web", "v1:Service:shared:web"},
+			expected: nil,
+		},
+		{
+			name:     "changed API version is the same resource",
+			previous: []ResourceRef{"autoscaling/v2beta2:HorizontalPodAutoscaler:shared:web"},
+			current:  []ResourceRef{"autoscaling/v2:HorizontalPodAutoscaler:shared:web"},
+			expected: nil,
+		},
+		{
+			name:     "moved namespace is stale",
+			previous: []ResourceRef{"v1:ConfigMap:old:web-config"},
+			current:  []ResourceRef{"v1:ConfigMap:new:web-config"},
+			expected: []ResourceRef{"v1:ConfigMap:old:web-config"},
+		},
+		{
+			name:     "name with colons",
+			previous: []ResourceRef{"rbac.authorization.k8s.io/v1beta1:ClusterRole::system:web", "rbac.authorization.k8s.io/v1:ClusterRole::system:web:reader"},
+			current:  []ResourceRef{"rbac.authorization.k8s.io/v1:ClusterRole::system:web"},
+			expected: []ResourceRef{"rbac.authorization.k8s.io/v1:ClusterRole::system:web:reader"},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			got := StaleResources(tt.previous, tt.current)
+			if len(got) != len(tt.expected) {
+				test.Fatalf("StaleResources() = %v, want %v", got, tt.expected)
+			}
+			for itr := range got {
+				if got[itr] != tt.expected[itr] {
+					test.Errorf("resource %d: got %q, want %q", itr, got[itr], tt.expected[itr])
+				}
+			}
+		})
+	}
+}
+
+func TestPruneResources(test *testing.T) {
+	owned := map[string]string{managedByLabel: "kraze", serviceLabel: "web"}
+	otherService := map[string]string{managedByLabel: "kraze", serviceLabel: "api"}
+
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(),
+		newLabeledObject("v1", "ConfigMap", "shared", "web-config", owned),
+		newLabeledObject("v1", "ConfigMap", "shared", "adopted-config", otherService),
+		newLabeledObject("v1", "Namespace", "", "shared", owned),
+	)
+	resolve := func(obj *unstructured.Unstructured) (schema.GroupVersionResource, error) {
+		gvk := obj.GroupVersionKind()
+		return schema.GroupVersionResource{Group: gvk.Group, Version: gvk.Version, Resource: strings.ToLower(gvk.Kind) + "s"}, nil
+	}
+
+	stale := []ResourceRef{
+		"v1:ConfigMap:shared:web-config",
+		"v1:ConfigMap:shared:adopted-config",
+		"v1:ConfigMap:shared:already-deleted",
+		"v1:Namespace::shared",
+	}
+	pruned, err := pruneResources(context.Background(), client, resolve, "web", stale, &ProviderOptions{Quiet: true})
+	if err != nil {
+		test.Fatalf("pruneResources() error = %v", err)
+	}
+	if len(pruned) != 1 || pruned[0] != "v1:ConfigMap:shared:web-config" {
+		test.Errorf("pruned = %v, want [v1:ConfigMap:shared:web-config]", pruned)
+	}
+
+	configMaps := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	if _, err := client.Resource(configMaps).Namespace("shared").Get(context.Background(), "web-config", metav1.GetOptions{}); err == nil {
+		test.Error("web-config should have been pruned")
+	}
+	if _, err := client.Resource(configMaps).Namespace("shared").Get(context.Background(), "adopted-config", metav1.GetOptions{}); err != nil {
+		test.Errorf("adopted-config belongs to another service and should not have been pruned: %v", err)
+	}
+	namespaces := schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+	if _, err := client.Resource(namespaces).Get(context.Background(), "shared", metav1.GetOptions{}); err != nil {
+		test.Errorf("namespaces should never be pruned: %v", err)
+	}
+}
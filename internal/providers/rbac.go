@@ -0,0 +1,223 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// serviceRBAC returns the ServiceAccount, Role and RoleBinding of a service's
+// RBAC sandbox. The Role and RoleBinding are only generated when the sandbox
+// grants rules; otherwise the account has no permissions at all.
+func serviceRBAC(service *config.ServiceConfig) (*corev1.ServiceAccount, *rbacv1.Role, *rbacv1.RoleBinding) {
+	name := service.RBAC.GetServiceAccount(service.Name)
+	meta := metav1.ObjectMeta{
+		Name:      name,
+		Namespace: service.GetNamespace(),
+		Labels:    map[string]string{managedByLabel: "kraze", serviceLabel: service.Name},
+	}
+
+	account := &corev1.ServiceAccount{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+		ObjectMeta: meta,
+	}
+	if len(service.RBAC.Rules) == 0 {
+		return account, nil, nil
+	}
+
+	role := &rbacv1.Role{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "Role"},
+		ObjectMeta: *meta.DeepCopy(),
+	}
+	for _, rule := range service.RBAC.Rules {
+		apiGroups := rule.APIGroups
+		if len(apiGroups) == 0 {
+			// The core group, as kubectl create role does
+			apiGroups = []string{""}
+		}
+		role.Rules = append(role.Rules, rbacv1.PolicyRule{
+			APIGroups:     apiGroups,
+			Resources:     rule.Resources,
+			ResourceNames: rule.ResourceNames,
+			Verbs:         rule.Verbs,
+		})
+	}
+
+	binding := &rbacv1.RoleBinding{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "RoleBinding"},
+		ObjectMeta: *meta.DeepCopy(),
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: name},
+		Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: name, Namespace: service.GetNamespace()}},
+	}
+	return account, role, binding
+}
+
+// serviceRBACObjects returns a service's RBAC sandbox as unstructured objects
+func serviceRBACObjects(service *config.ServiceConfig) ([]*unstructured.Unstructured, error) {
+	if service.RBAC == nil {
+		return nil, nil
+	}
+
+	account, role, binding := serviceRBAC(service)
+	typed := []runtime.Object{account}
+	if role != nil {
+		typed = append(typed, role, binding)
+	}
+
+	var objects []*unstructured.Unstructured
+	for _, obj := range typed {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert RBAC object: %w", err)
+		}
+		// Drop the null creationTimestamp the converter leaves behind
+		unstructured.RemoveNestedField(content, "metadata", "creationTimestamp")
+		objects = append(objects, &unstructured.Unstructured{Object: content})
+	}
+	return objects, nil
+}
+
+// serviceRBACManifests returns a service's RBAC sandbox as YAML documents, so
+// the manifests provider applies, renders and deletes it with the service
+func serviceRBACManifests(service *config.ServiceConfig) ([]string, error) {
+	objects, err := serviceRBACObjects(service)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifests []string
+	for _, obj := range objects {
+		data, err := sigsyaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s: %w", obj.GetKind(), err)
+		}
+		manifests = append(manifests, string(data))
+	}
+	return manifests, nil
+}
+
+// serviceAccountValues returns Helm values that point the chart at the
+// sandbox's ServiceAccount, following the common serviceAccount.create/name
+// chart convention under the configured values key
+func serviceAccountValues(service *config.ServiceConfig) map[string]interface{} {
+	values := map[string]interface{}{
+		"create": false,
+		"name":   service.RBAC.GetServiceAccount(service.Name),
+	}
+	keys := strings.Split(service.RBAC.GetValuesKey(), ".")
+	for itr := len(keys) - 1; itr >= 0; itr-- {
+		values = map[string]interface{}{keys[itr]: values}
+	}
+	return values
+}
+
+// injectServiceAccount runs a workload's pods under the sandbox's ServiceAccount.
+// Pods that already name an account other than "default" keep it.
+func injectServiceAccount(obj *unstructured.Unstructured, accountName string) bool {
+	specPath := podSpecPath(obj.GetKind())
+	if specPath == nil {
+		return false
+	}
+
+	current, _, _ := unstructured.NestedString(obj.Object, append(specPath, "serviceAccountName")...)
+	if current != "" && current != "default" {
+		return false
+	}
+	if err := unstructured.SetNestedField(obj.Object, accountName, append(specPath, "serviceAccountName")...); err != nil {
+		return false
+	}
+	return true
+}
+
+// ensureServiceRBAC creates or updates a service's RBAC sandbox. Helm services
+// need it before the chart installs, so the namespace is created here when the
+// service allows it.
+func ensureServiceRBAC(ctx context.Context, clientset kubernetes.Interface, service *config.ServiceConfig) error {
+	namespace := service.GetNamespace()
+	if service.ShouldCreateNamespace() {
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+		if _, err := clientset.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create namespace: %w", err)
+		}
+	}
+
+	account, role, binding := serviceRBAC(service)
+	accounts := clientset.CoreV1().ServiceAccounts(namespace)
+	if existing, err := accounts.Get(ctx, account.Name, metav1.GetOptions{}); err == nil {
+		// Keep token secrets and image pull secrets others attached
+		existing.Labels = account.Labels
+		if _, err := accounts.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update ServiceAccount %s: %w", account.Name, err)
+		}
+	} else if !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to get ServiceAccount %s: %w", account.Name, err)
+	} else if _, err := accounts.Create(ctx, account, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create ServiceAccount %s: %w", account.Name, err)
+	}
+
+	roles := clientset.RbacV1().Roles(namespace)
+	bindings := clientset.RbacV1().RoleBindings(namespace)
+	if role == nil {
+		// Rules were removed from the config since the last install
+		return deleteRoleAndBinding(ctx, clientset, namespace, account.Name)
+	}
+
+	if existing, err := roles.Get(ctx, role.Name, metav1.GetOptions{}); err == nil {
+		existing.Labels = role.Labels
+		existing.Rules = role.Rules
+		if _, err := roles.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update Role %s: %w", role.Name, err)
+		}
+	} else if !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to get Role %s: %w", role.Name, err)
+	} else if _, err := roles.Create(ctx, role, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create Role %s: %w", role.Name, err)
+	}
+
+	if existing, err := bindings.Get(ctx, binding.Name, metav1.GetOptions{}); err == nil {
+		existing.Labels = binding.Labels
+		existing.Subjects = binding.Subjects
+		if _, err := bindings.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update RoleBinding %s: %w", binding.Name, err)
+		}
+	} else if !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to get RoleBinding %s: %w", binding.Name, err)
+	} else if _, err := bindings.Create(ctx, binding, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create RoleBinding %s: %w", binding.Name, err)
+	}
+	return nil
+}
+
+// deleteServiceRBAC deletes a service's RBAC sandbox, ignoring objects that are already gone
+func deleteServiceRBAC(ctx context.Context, clientset kubernetes.Interface, service *config.ServiceConfig) error {
+	namespace := service.GetNamespace()
+	name := service.RBAC.GetServiceAccount(service.Name)
+	if err := deleteRoleAndBinding(ctx, clientset, namespace, name); err != nil {
+		return err
+	}
+	if err := clientset.CoreV1().ServiceAccounts(namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete ServiceAccount %s: %w", name, err)
+	}
+	return nil
+}
+
+// deleteRoleAndBinding deletes the sandbox's Role and RoleBinding if they exist
+func deleteRoleAndBinding(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+	if err := clientset.RbacV1().RoleBindings(namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete RoleBinding %s: %w", name, err)
+	}
+	if err := clientset.RbacV1().Roles(namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete Role %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package providers
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestServiceRBACObjects(test *testing.T) {
+	tests := []struct {
+		name     string
+		rbac     *config.RBACConfig
+		expected []string
+	}{
+		{name: "no sandbox"},
+		{name: "account without permissions", rbac: &config.RBACConfig{}, expected: []string{"ServiceAccount/api"}},
+		{
+			name:     "account with rules",
+			rbac:     &config.RBACConfig{ServiceAccount: "api-runner", Rules: []config.PolicyRule{{Resources: []string{"configmaps"}, Verbs: []string{"get"}}}},
+			expected: []string{"ServiceAccount/api-runner", "Role/api-runner", "RoleBinding/api-runner"},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			service := &config.ServiceConfig{Name: "api", Namespace: "backend", RBAC: tt.rbac}
+			objects, err := serviceRBACObjects(service)
+			if err != nil {
+				test.Fatalf("serviceRBACObjects() error = %v", err)
+			}
+
+			var got []string
+			for _, obj := range objects {
+				got = append(got, obj.GetKind()+"/"+obj.GetName())
+				if obj.GetNamespace() != "backend" {
+					test.Errorf("%s namespace = %q, want 'backend'", obj.GetKind(), obj.GetNamespace())
+				}
+				if obj.GetLabels()[serviceLabel] != "api" {
+					test.Errorf("%s is missing the service label", obj.GetKind())
+				}
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				test.Errorf("serviceRBACObjects() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestServiceRBACDefaultsCoreGroup(test *testing.T) {
+	service := &config.ServiceConfig{Name: "api", RBAC: &config.RBACConfig{Rules: []config.PolicyRule{
+		{Resources: []string{"secrets"}, Verbs: []string{"get"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"list"}},
+	}}}
+	_, role, binding := serviceRBAC(service)
+
+	if !reflect.DeepEqual(role.Rules[0].APIGroups, []string{""}) {
+		test.Errorf("rule without apiGroups = %v, want the core group", role.Rules[0].APIGroups)
+	}
+	if !reflect.DeepEqual(role.Rules[1].APIGroups, []string{"apps"}) {
+		test.Errorf("rule apiGroups = %v, want [apps]", role.Rules[1].APIGroups)
+	}
+	if binding.RoleRef.Name != "api" || binding.Subjects[0].Name != "api" || binding.Subjects[0].Namespace != "default" {
+		test.Errorf("binding = %+v, want api bound to ServiceAccount default/api", binding)
+	}
+}
+
+func TestServiceRBACManifests(test *testing.T) {
+	service := &config.ServiceConfig{Name: "api", RBAC: &config.RBACConfig{}}
+	manifests, err := serviceRBACManifests(service)
+	if err != nil {
+		test.Fatalf("serviceRBACManifests() error = %v", err)
+	}
+	if len(manifests) != 1 || !strings.Contains(manifests[0], "kind: ServiceAccount") {
+		test.Fatalf("serviceRBACManifests() = %v, want one ServiceAccount", manifests)
+	}
+	if strings.Contains(manifests[0], "creationTimestamp") {
+		test.Errorf("manifest has a creationTimestamp:\n%s", manifests[0])
+	}
+}
+
+func TestServiceAccountValues(test *testing.T) {
+	tests := []struct {
+		name     string
+		rbac     *config.RBACConfig
+		expected map[string]interface{}
+	}{
+		{
+			name:     "default key",
+			rbac:     &config.RBACConfig{},
+			expected: map[string]interface{}{"serviceAccount": map[string]interface{}{"create": false, "name": "api"}},
+		},
+		{
+			name: "nested key",
+			rbac: &config.RBACConfig{ServiceAccount: "runner", ValuesKey: "controller.serviceAccount"},
+			expected: map[string]interface{}{"controller": map[string]interface{}{
+				"serviceAccount": map[string]interface{}{"create": false, "name": "runner"},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			got := serviceAccountValues(&config.ServiceConfig{Name: "api", RBAC: tt.rbac})
+			if !reflect.DeepEqual(got, tt.expected) {
+				test.Errorf("serviceAccountValues() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestInjectServiceAccount(test *testing.T) {
+	tests := []struct {
+		name     string
+		obj      map[string]interface{}
+		path     []string
+		expected string
+		injected bool
+	}{
+		{
+			name:     "deployment without account",
+			obj:      map[string]interface{}{"kind": "Deployment", "spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{}}}},
+			path:     []string{"spec", "template", "spec", "serviceAccountName"},
+			expected: "api",
+			injected: true,
+		},
+		{
+			name:     "default account is replaced",
+			obj:      map[string]interface{}{"kind": "Pod", "spec": map[string]interface{}{"serviceAccountName": "default"}},
+			path:     []string{"spec", "serviceAccountName"},
+			expected: "api",
+			injected: true,
+		},
+		{
+			name:     "explicit account is kept",
+			obj:      map[string]interface{}{"kind": "StatefulSet", "spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{"serviceAccountName": "db"}}}},
+			path:     []string{"spec", "template", "spec", "serviceAccountName"},
+			expected: "db",
+		},
+		{
+			name:     "cronjob",
+			obj:      map[string]interface{}{"kind": "CronJob", "spec": map[string]interface{}{}},
+			path:     []string{"spec", "jobTemplate", "spec", "template", "spec", "serviceAccountName"},
+			expected: "api",
+			injected: true,
+		},
+		{
+			name: "not a workload",
+			obj:  map[string]interface{}{"kind": "ConfigMap", "data": map[string]interface{}{}},
+			path: []string{"spec", "serviceAccountName"},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			obj := &unstructured.Unstructured{Object: tt.obj}
+			if injected := injectServiceAccount(obj, "api"); injected != tt.injected {
+				test.Errorf("injectServiceAccount() = %v, want %v", injected, tt.injected)
+			}
+			got, _, _ := unstructured.NestedString(obj.Object, tt.path...)
+			if got != tt.expected {
+				test.Errorf("serviceAccountName = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEnsureServiceRBAC(test *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset()
+	service := &config.ServiceConfig{Name: "api", Namespace: "backend", RBAC: &config.RBACConfig{Rules: []config.PolicyRule{
+		{Resources: []string{"configmaps"}, Verbs: []string{"get"}},
+	}}}
+
+	if err := ensureServiceRBAC(ctx, clientset, service); err != nil {
+		test.Fatalf("ensureServiceRBAC() error = %v", err)
+	}
+	if _, err := clientset.CoreV1().Namespaces().Get(ctx, "backend", metav1.GetOptions{}); err != nil {
+		test.Errorf("namespace was not created: %v", err)
+	}
+
+	// Re-running updates the rules in place
+	service.RBAC.Rules[0].Verbs = []string{"get", "watch"}
+	if err := ensureServiceRBAC(ctx, clientset, service); err != nil {
+		test.Fatalf("ensureServiceRBAC() second run error = %v", err)
+	}
+	role, err := clientset.RbacV1().Roles("backend").Get(ctx, "api", metav1.GetOptions{})
+	if err != nil {
+		test.Fatalf("Role not found: %v", err)
+	}
+	if !reflect.DeepEqual(role.Rules[0].Verbs, []string{"get", "watch"}) {
+		test.Errorf("role verbs = %v, want [get watch]", role.Rules[0].Verbs)
+	}
+
+	// Dropping the rules removes the Role and RoleBinding but keeps the account
+	service.RBAC.Rules = nil
+	if err := ensureServiceRBAC(ctx, clientset, service); err != nil {
+		test.Fatalf("ensureServiceRBAC() without rules error = %v", err)
+	}
+	if _, err := clientset.RbacV1().RoleBindings("backend").Get(ctx, "api", metav1.GetOptions{}); !errors.IsNotFound(err) {
+		test.Errorf("RoleBinding still exists after rules were removed (err = %v)", err)
+	}
+	if _, err := clientset.CoreV1().ServiceAccounts("backend").Get(ctx, "api", metav1.GetOptions{}); err != nil {
+		test.Errorf("ServiceAccount was removed: %v", err)
+	}
+
+	if err := deleteServiceRBAC(ctx, clientset, service); err != nil {
+		test.Fatalf("deleteServiceRBAC() error = %v", err)
+	}
+	if _, err := clientset.CoreV1().ServiceAccounts("backend").Get(ctx, "api", metav1.GetOptions{}); !errors.IsNotFound(err) {
+		test.Errorf("ServiceAccount still exists after delete (err = %v)", err)
+	}
+}
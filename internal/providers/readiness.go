@@ -0,0 +1,64 @@
+package providers
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/hjames9/kraze/internal/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// readinessRuleFor returns the configured readiness rule of a resource's kind
+func readinessRuleFor(rules map[string]config.ReadinessRule, obj *unstructured.Unstructured) (config.ReadinessRule, bool) {
+	gvk := obj.GroupVersionKind()
+	rule, ok := rules[config.ReadinessKey(gvk.Kind, gvk.Group)]
+	return rule, ok
+}
+
+// evaluateReadinessRule checks a resource against a readiness rule, returning
+// a message saying why it isn't ready yet, or an error when it failed
+func evaluateReadinessRule(obj *unstructured.Unstructured, rule config.ReadinessRule) (bool, string, error) {
+	if rule.JSONPath != "" {
+		value, err := fieldValue(obj, rule.JSONPath)
+		if err != nil {
+			return false, fmt.Sprintf("Waiting for %s to be set", rule.JSONPath), nil
+		}
+		if value == rule.Value {
+			return true, "", nil
+		}
+		if slices.Contains(rule.Failed, value) {
+			return false, "", fmt.Errorf("%s is %s", rule.JSONPath, value)
+		}
+		return false, fmt.Sprintf("%s is '%s', waiting for '%s'", rule.JSONPath, value, rule.Value), nil
+	}
+
+	condition := findConditionObject(obj, rule.Condition)
+	if condition == nil {
+		return false, fmt.Sprintf("Waiting for the %s condition", rule.Condition), nil
+	}
+	status, _ := condition["status"].(string)
+	reason, _ := condition["reason"].(string)
+	message, _ := condition["message"].(string)
+	// A condition the controller hasn't updated for the latest spec is stale
+	if observed, ok := condition["observedGeneration"].(int64); ok && observed < obj.GetGeneration() {
+		return false, fmt.Sprintf("Waiting for the %s condition to observe generation %d", rule.Condition, obj.GetGeneration()), nil
+	}
+	if status == rule.GetStatus() {
+		return true, "", nil
+	}
+	if reason != "" && slices.Contains(rule.Failed, reason) {
+		return false, "", fmt.Errorf("%s condition is %s (%s): %s", rule.Condition, status, reason, message)
+	}
+	return false, fmt.Sprintf("%s condition is %s, waiting for %s: %s", rule.Condition, status, rule.GetStatus(), message), nil
+}
+
+// findConditionObject returns a resource's status condition of a type
+func findConditionObject(obj *unstructured.Unstructured, conditionType string) map[string]interface{} {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, itr := range conditions {
+		if condition, ok := itr.(map[string]interface{}); ok && condition["type"] == conditionType {
+			return condition
+		}
+	}
+	return nil
+}
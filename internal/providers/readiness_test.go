@@ -0,0 +1,84 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func readinessTestResource(status map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "kafka.strimzi.io/v1beta2",
+		"kind":       "Kafka",
+		"metadata":   map[string]interface{}{"name": "events", "generation": int64(2)},
+		"status":     status,
+	}}
+}
+
+func TestEvaluateReadinessRule(test *testing.T) {
+	conditionRule := config.ReadinessRule{Condition: "Ready", Failed: []string{"InvalidConfig"}}
+	phaseRule := config.ReadinessRule{JSONPath: ".status.phase", Value: "Running", Failed: []string{"Failed"}}
+	condition := func(status, reason string, generation int64) map[string]interface{} {
+		return map[string]interface{}{"conditions": []interface{}{
+			map[string]interface{}{"type": "Ready", "status": status, "reason": reason, "observedGeneration": generation},
+		}}
+	}
+
+	tests := []struct {
+		name      string
+		rule      config.ReadinessRule
+		status    map[string]interface{}
+		wantReady bool
+		wantErr   bool
+	}{
+		{name: "condition true", rule: conditionRule, status: condition("True", "", 2), wantReady: true},
+		{name: "condition false", rule: conditionRule, status: condition("False", "Creating", 2)},
+		{name: "condition missing", rule: conditionRule, status: map[string]interface{}{}},
+		{name: "condition stale", rule: conditionRule, status: condition("True", "", 1)},
+		{name: "condition failed", rule: conditionRule, status: condition("False", "InvalidConfig", 2), wantErr: true},
+		{name: "condition status", rule: config.ReadinessRule{Condition: "Degraded", Status: "False"}, status: map[string]interface{}{"conditions": []interface{}{
+			map[string]interface{}{"type": "Degraded", "status": "False"},
+		}}, wantReady: true},
+		{name: "jsonpath value", rule: phaseRule, status: map[string]interface{}{"phase": "Running"}, wantReady: true},
+		{name: "jsonpath other value", rule: phaseRule, status: map[string]interface{}{"phase": "Provisioning"}},
+		{name: "jsonpath unset", rule: phaseRule},
+		{name: "jsonpath failed", rule: phaseRule, status: map[string]interface{}{"phase": "Failed"}, wantErr: true},
+		{
+			name:      "jsonpath filter",
+			rule:      config.ReadinessRule{JSONPath: `{.status.listeners[?(@.name=="plain")].bootstrapServers}`, Value: "events-kafka-bootstrap:9092"},
+			status:    map[string]interface{}{"listeners": []interface{}{map[string]interface{}{"name": "plain", "bootstrapServers": "events-kafka-bootstrap:9092"}}},
+			wantReady: true,
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			obj := readinessTestResource(tt.status)
+			if tt.status == nil {
+				unstructured.RemoveNestedField(obj.Object, "status")
+			}
+			ready, message, err := evaluateReadinessRule(obj, tt.rule)
+			if (err != nil) != tt.wantErr {
+				test.Fatalf("evaluateReadinessRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ready != tt.wantReady {
+				test.Errorf("evaluateReadinessRule() = %v (%s), want %v", ready, message, tt.wantReady)
+			}
+			if !ready && !tt.wantErr && message == "" {
+				test.Error("Expected a message saying why the resource isn't ready")
+			}
+		})
+	}
+}
+
+func TestShouldWaitForResourceWithRule(test *testing.T) {
+	service := &unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "Service"}}
+	if shouldWaitForResource(service, nil) {
+		test.Fatal("Expected Services not to be waited for without a rule")
+	}
+	rules := map[string]config.ReadinessRule{"Service": {JSONPath: ".spec.clusterIP", Value: "None"}}
+	if !shouldWaitForResource(service, rules) {
+		test.Error("Expected a Service with a readiness rule to be waited for")
+	}
+}
@@ -0,0 +1,87 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// ReapplyDrift re-applies the resources that differ from the cluster or are
+// missing from it. Fields another manager took over (replicas scaled by an
+// HPA, say) are left to it: a resource whose apply would conflict is skipped.
+// Returns the resources that were re-applied. A resource that fails to apply
+// doesn't stop the others; the failures are returned together.
+func ReapplyDrift(ctx context.Context, restConfig *rest.Config, resources []*unstructured.Unstructured, opts *ProviderOptions) ([]ResourceRef, error) {
+	manifest, err := newManifestsProviderForConfig(restConfig, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var reapplied []ResourceRef
+	var errs []error
+	for _, obj := range resources {
+		if obj.GetNamespace() == "" && manifest.isNamespacedResource(obj) {
+			errs = append(errs, fmt.Errorf("%s has no namespace", NewResourceRef(obj)))
+			continue
+		}
+		diff, err := manifest.diffResource(ctx, obj)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if diff.Action == "" || diff.Conflict {
+			continue
+		}
+		if diff.Error != "" {
+			errs = append(errs, fmt.Errorf("%s: %s", diff.Ref, diff.Error))
+			continue
+		}
+		if err := manifest.applyResource(ctx, obj); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", diff.Ref, err))
+			continue
+		}
+		reapplied = append(reapplied, diff.Ref)
+	}
+	return reapplied, errors.Join(errs...)
+}
+
+// RestartCrashedPods deletes the pods matching a selector that are stuck in
+// CrashLoopBackOff, so their controller recreates them without the back-off.
+// Pods without a controller are left alone, as nothing would recreate them.
+// Returns the names of the deleted pods.
+func RestartCrashedPods(ctx context.Context, clientset kubernetes.Interface, namespace, selector string) ([]string, error) {
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	var restarted []string
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp != nil || metav1.GetControllerOf(&pod) == nil || !isCrashLooping(&pod) {
+			continue
+		}
+		err := clientset.CoreV1().Pods(namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return restarted, fmt.Errorf("failed to delete pod %s: %w", pod.Name, err)
+		}
+		restarted = append(restarted, pod.Name)
+	}
+	return restarted, nil
+}
+
+// isCrashLooping returns true if a container of the pod is in CrashLoopBackOff
+func isCrashLooping(pod *corev1.Pod) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Waiting != nil && status.State.Waiting.Reason == "CrashLoopBackOff" {
+			return true
+		}
+	}
+	return false
+}
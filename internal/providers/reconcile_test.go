@@ -0,0 +1,73 @@
+package providers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRestartCrashedPods(test *testing.T) {
+	controller := true
+	owner := []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "api-5d9c", UID: "1", Controller: &controller}}
+	pod := func(name string, owners []metav1.OwnerReference, reason string) *corev1.Pod {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app", Labels: map[string]string{"app": "api"}, OwnerReferences: owners}}
+		status := corev1.ContainerStatus{Name: "api", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}
+		if reason != "" {
+			status.State = corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}}
+		}
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{status}
+		return pod
+	}
+	clientset := fake.NewSimpleClientset(
+		pod("api-crashing", owner, "CrashLoopBackOff"),
+		pod("api-running", owner, ""),
+		pod("api-pulling", owner, "ImagePullBackOff"),
+		pod("api-bare", nil, "CrashLoopBackOff"),
+	)
+
+	restarted, err := RestartCrashedPods(context.Background(), clientset, "app", "app=api")
+	if err != nil {
+		test.Fatalf("RestartCrashedPods() error = %v", err)
+	}
+	if !reflect.DeepEqual(restarted, []string{"api-crashing"}) {
+		test.Errorf("restarted = %v, want [api-crashing]", restarted)
+	}
+
+	pods, err := clientset.CoreV1().Pods("app").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		test.Fatal(err)
+	}
+	if len(pods.Items) != 3 {
+		test.Errorf("%d pods left, want 3", len(pods.Items))
+	}
+}
+
+func TestServicePodSelector(test *testing.T) {
+	tests := []struct {
+		name     string
+		labels   map[string]string
+		typ      string
+		expected string
+	}{
+		{name: "helm", typ: "helm", expected: "app.kubernetes.io/instance=api"},
+		{name: "manifests fallback", typ: "manifests", expected: "app=api"},
+		{name: "manifests labels", typ: "manifests", labels: map[string]string{"tier": "web", "app": "shop"}, expected: "app=shop,tier=web"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			selector, err := ServicePodSelector(&config.ServiceConfig{Name: "api", Type: tt.typ, Labels: tt.labels})
+			if err != nil {
+				test.Fatal(err)
+			}
+			if selector != tt.expected {
+				test.Errorf("ServicePodSelector() = %q, want %q", selector, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/hjames9/kraze/internal/config"
+	"helm.sh/helm/v4/pkg/registry"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+)
+
+// newRegistryClient creates the Helm client for OCI registries. Hosts in the
+// config's registries use the credentials configured there; others use those
+// from 'helm registry login', falling back to Docker's.
+func (helm *HelmProvider) newRegistryClient(extra ...registry.ClientOption) (*registry.Client, error) {
+	clientOpts := []registry.ClientOption{
+		registry.ClientOptDebug(helm.opts.Verbose),
+		registry.ClientOptCredentialsFile(helm.settings.RegistryConfig),
+	}
+	if len(helm.opts.Registries) > 0 {
+		authorizer, err := registryAuthorizer(helm.settings.RegistryConfig, helm.opts.Registries, helm.opts.Verbose)
+		if err != nil {
+			return nil, err
+		}
+		clientOpts = append(clientOpts, registry.ClientOptAuthorizer(*authorizer))
+	}
+	return registry.NewClient(append(clientOpts, extra...)...)
+}
+
+// registryAuthorizer builds the authorizer Helm's registry client would,
+// with the configured registries' credentials taking precedence
+func registryAuthorizer(credentialsFile string, registries []config.RegistryConfig, debug bool) (*auth.Client, error) {
+	storeOptions := credentials.StoreOptions{
+		AllowPlaintextPut:        true,
+		DetectDefaultNativeStore: true,
+	}
+	var store credentials.Store
+	store, err := credentials.NewStore(credentialsFile, storeOptions)
+	if err != nil {
+		return nil, err
+	}
+	if dockerStore, err := credentials.NewStoreFromDocker(storeOptions); err == nil {
+		store = credentials.NewStoreWithFallbacks(store, dockerStore)
+	}
+
+	return &auth.Client{
+		Client:     &http.Client{Transport: registry.NewTransport(debug)},
+		Credential: registryCredential(registries, credentials.Credential(store)),
+	}, nil
+}
+
+// registryCredential returns the configured credentials for registries in the
+// list and asks fallback for the others
+func registryCredential(registries []config.RegistryConfig, fallback auth.CredentialFunc) auth.CredentialFunc {
+	return func(ctx context.Context, hostport string) (auth.Credential, error) {
+		reg := config.FindRegistry(registries, hostport)
+		if reg == nil {
+			return fallback(ctx, hostport)
+		}
+		password, err := reg.ResolvePassword(ctx)
+		if err != nil {
+			return auth.EmptyCredential, err
+		}
+		return auth.Credential{Username: reg.Username, Password: password}, nil
+	}
+}
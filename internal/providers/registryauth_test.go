@@ -0,0 +1,32 @@
+package providers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+func TestRegistryCredential(test *testing.T) {
+	ctx := context.Background()
+	registries := []config.RegistryConfig{{Host: "ghcr.io", Username: "octocat", Password: "ghp_token"}}
+	fallback := func(_ context.Context, hostport string) (auth.Credential, error) {
+		return auth.Credential{Username: "helm", Password: hostport}, nil
+	}
+	credential := registryCredential(registries, fallback)
+
+	got, err := credential(ctx, "ghcr.io")
+	if err != nil || got.Username != "octocat" || got.Password != "ghp_token" {
+		test.Errorf("credential(ghcr.io) = %+v, %v", got, err)
+	}
+	got, err = credential(ctx, "quay.io")
+	if err != nil || got.Username != "helm" || got.Password != "quay.io" {
+		test.Errorf("credential(quay.io) = %+v, %v, want the fallback's", got, err)
+	}
+
+	credential = registryCredential([]config.RegistryConfig{{Host: "ghcr.io", Username: "octocat"}}, fallback)
+	if _, err := credential(ctx, "ghcr.io"); err == nil {
+		test.Error("credential() with an empty password succeeded")
+	}
+}
@@ -0,0 +1,303 @@
+package providers
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/hjames9/kraze/internal/config"
+)
+
+// manifestCacheDir returns the directory downloaded remote manifests are cached in
+var manifestCacheDir = func() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".kraze", "cache", "manifests"), nil
+}
+
+// manifestClient downloads remote manifests; the timeout keeps a stalled
+// server from hanging the command
+var manifestClient = &http.Client{Timeout: 2 * time.Minute}
+
+// offline makes remote manifests come from the cache only (kraze --offline)
+var offline bool
+
+// UseOffline reads remote manifests from the cache instead of downloading them
+func UseOffline(enabled bool) {
+	offline = enabled
+}
+
+// githubHosts serve raw file contents and accept a GitHub token
+var githubHosts = map[string]bool{
+	"raw.githubusercontent.com": true,
+	"api.github.com":            true,
+}
+
+// cachedManifest describes a cached remote manifest, stored next to its content
+type cachedManifest struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	SHA256       string `json:"sha256"`
+}
+
+// fetchRemoteManifest returns the content of a service's remote manifest. The
+// download carries the service's headers, or credentials from GITHUB_TOKEN or
+// netrc, and is cached: the cached copy is revalidated with its ETag, used
+// without asking the server when it matches the service's sha256, and used
+// as is when offline. The content must match the service's sha256, if set.
+func fetchRemoteManifest(ctx context.Context, service *config.ServiceConfig, out io.Writer, verbose bool) (string, error) {
+	source := service.Path
+	cacheDir, err := manifestCacheDir()
+	if err != nil {
+		return "", err
+	}
+	key := sha256.Sum256([]byte(source))
+	base := filepath.Join(cacheDir, hex.EncodeToString(key[:8]))
+	cached, content := readCachedManifest(base, source)
+
+	if cached != nil && service.SHA256 != "" && strings.EqualFold(cached.SHA256, service.SHA256) {
+		if verbose {
+			fmt.Fprintf(out, "Using cached manifest for %s (sha256 pinned)\n", source)
+		}
+		return string(content), nil
+	}
+	if offline {
+		if cached == nil {
+			return "", fmt.Errorf("%s isn't cached, run once without --offline to download it", source)
+		}
+		if err := verifyChecksum(service.SHA256, cached.SHA256); err != nil {
+			return "", err
+		}
+		if verbose {
+			fmt.Fprintf(out, "Using cached manifest for %s (offline)\n", source)
+		}
+		return string(content), nil
+	}
+
+	if verbose {
+		fmt.Fprintf(out, "Downloading manifest from %s...\n", source)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	for name, value := range service.Headers {
+		request.Header.Set(name, value)
+	}
+	if request.Header.Get("Authorization") == "" {
+		addCredentials(request)
+	}
+	if cached != nil {
+		if cached.ETag != "" {
+			request.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			request.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := manifestClient.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch URL (use --offline to use the cached copy): %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		if verbose {
+			fmt.Fprintf(out, "Cached manifest for %s is up to date\n", source)
+		}
+		if err := verifyChecksum(service.SHA256, cached.SHA256); err != nil {
+			return "", err
+		}
+		return string(content), nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+	sum := sha256.Sum256(body)
+	checksum := hex.EncodeToString(sum[:])
+	if err := verifyChecksum(service.SHA256, checksum); err != nil {
+		return "", err
+	}
+
+	entry := &cachedManifest{
+		URL:          source,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		SHA256:       checksum,
+	}
+	if err := writeCachedManifest(cacheDir, base, entry, body); err != nil && verbose {
+		fmt.Fprintf(out, "Warning: failed to cache manifest: %v\n", err)
+	}
+	return string(body), nil
+}
+
+// verifyChecksum returns an error if a manifest's checksum isn't the expected one (if any)
+func verifyChecksum(expected, actual string) error {
+	if expected != "" && !strings.EqualFold(expected, actual) {
+		return fmt.Errorf("checksum mismatch: expected sha256 %s, got %s", strings.ToLower(expected), actual)
+	}
+	return nil
+}
+
+// readCachedManifest returns the cached copy of a URL, or nil if there is none
+func readCachedManifest(base, source string) (*cachedManifest, []byte) {
+	data, err := os.ReadFile(base + ".json")
+	if err != nil {
+		return nil, nil
+	}
+	var entry cachedManifest
+	if err := json.Unmarshal(data, &entry); err != nil || entry.URL != source {
+		return nil, nil
+	}
+	content, err := os.ReadFile(base + ".yaml")
+	if err != nil {
+		return nil, nil
+	}
+	// A cached copy that was changed on disk isn't trusted
+	sum := sha256.Sum256(content)
+	if hex.EncodeToString(sum[:]) != entry.SHA256 {
+		return nil, nil
+	}
+	return &entry, content
+}
+
+// writeCachedManifest caches a downloaded manifest with its description
+func writeCachedManifest(cacheDir, base string, entry *cachedManifest, content []byte) error {
+	if err := os.MkdirAll(cacheDir, 0700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(base+".yaml", content, 0600); err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(base+".json", data, 0600)
+}
+
+// addCredentials authorizes a request with GITHUB_TOKEN (or GH_TOKEN) for
+// GitHub's raw content, or with the host's login from the netrc file
+func addCredentials(request *http.Request) {
+	host := request.URL.Hostname()
+	if githubHosts[host] {
+		for _, name := range []string{"GITHUB_TOKEN", "GH_TOKEN"} {
+			if token := os.Getenv(name); token != "" {
+				request.Header.Set("Authorization", "token "+token)
+				return
+			}
+		}
+	}
+	if login, password, found := netrcCredentials(host); found {
+		request.SetBasicAuth(login, password)
+	}
+}
+
+// netrcPath returns the netrc file: $NETRC, or .netrc (_netrc on Windows) in the home directory
+func netrcPath() string {
+	if path := os.Getenv("NETRC"); path != "" {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(homeDir, "_netrc")
+	}
+	return filepath.Join(homeDir, ".netrc")
+}
+
+// netrcCredentials returns the login for host from the netrc file, falling
+// back to its default entry
+func netrcCredentials(host string) (string, string, bool) {
+	path := netrcPath()
+	if path == "" {
+		return "", "", false
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return "", "", false
+	}
+	defer file.Close()
+	return parseNetrc(file, host)
+}
+
+// parseNetrc finds the login for host in netrc content
+func parseNetrc(reader io.Reader, host string) (string, string, bool) {
+	type entry struct{ login, password string }
+	var matched, fallback *entry
+	var current *entry
+
+	scanner := bufio.NewScanner(reader)
+	inMacro := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		// A macro definition runs until an empty line
+		if inMacro {
+			inMacro = strings.TrimSpace(line) != ""
+			continue
+		}
+		fields := strings.Fields(line)
+		for itr := 0; itr < len(fields); itr++ {
+			value := ""
+			if itr+1 < len(fields) {
+				value = fields[itr+1]
+			}
+			switch fields[itr] {
+			case "machine":
+				current = &entry{}
+				if value == host && matched == nil {
+					matched = current
+				}
+				itr++
+			case "default":
+				current = &entry{}
+				if fallback == nil {
+					fallback = current
+				}
+			case "login":
+				if current != nil {
+					current.login = value
+				}
+				itr++
+			case "password":
+				if current != nil {
+					current.password = value
+				}
+				itr++
+			case "account":
+				itr++
+			case "macdef":
+				inMacro = true
+				itr = len(fields)
+			}
+		}
+	}
+
+	for _, found := range []*entry{matched, fallback} {
+		if found != nil && found.login != "" {
+			return found.login, found.password, true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,120 @@
+package providers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+)
+
+func TestFetchRemoteManifest(test *testing.T) {
+	const manifest = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n"
+	sum := sha256.Sum256([]byte(manifest))
+	checksum := hex.EncodeToString(sum[:])
+
+	var requests, notModified int
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requests++
+		authorization = request.Header.Get("Authorization")
+		if request.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			writer.WriteHeader(http.StatusNotModified)
+			return
+		}
+		writer.Header().Set("ETag", `"v1"`)
+		io.WriteString(writer, manifest)
+	}))
+	defer server.Close()
+
+	cacheDir := test.TempDir()
+	previousDir := manifestCacheDir
+	manifestCacheDir = func() (string, error) { return cacheDir, nil }
+	defer func() { manifestCacheDir = previousDir }()
+	test.Setenv("NETRC", "")
+	test.Setenv("HOME", test.TempDir())
+
+	fetch := func(service *config.ServiceConfig) (string, error) {
+		return fetchRemoteManifest(context.Background(), service, io.Discard, false)
+	}
+	url := server.URL + "/manifest.yaml"
+
+	// Downloaded and cached with the service's headers
+	content, err := fetch(&config.ServiceConfig{Path: url, Headers: map[string]string{"Authorization": "Bearer secret"}})
+	if err != nil || content != manifest {
+		test.Fatalf("first fetch = %q, %v", content, err)
+	}
+	if authorization != "Bearer secret" {
+		test.Errorf("Authorization = %q, want the service's header", authorization)
+	}
+
+	// Revalidated with the ETag
+	content, err = fetch(&config.ServiceConfig{Path: url})
+	if err != nil || content != manifest || notModified != 1 {
+		test.Fatalf("revalidated fetch = %q, %v (%d not modified)", content, err, notModified)
+	}
+
+	// A pinned checksum matching the cache skips the request
+	before := requests
+	content, err = fetch(&config.ServiceConfig{Path: url, SHA256: strings.ToUpper(checksum)})
+	if err != nil || content != manifest || requests != before {
+		test.Fatalf("pinned fetch = %q, %v (%d requests)", content, err, requests-before)
+	}
+
+	// A pinned checksum that doesn't match fails
+	wrong := strings.Repeat("0", 64)
+	if _, err := fetch(&config.ServiceConfig{Path: url, SHA256: wrong}); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		test.Errorf("mismatched fetch error = %v, want a checksum mismatch", err)
+	}
+
+	// Offline reads the cache only
+	UseOffline(true)
+	defer UseOffline(false)
+	before = requests
+	if content, err := fetch(&config.ServiceConfig{Path: url}); err != nil || content != manifest || requests != before {
+		test.Errorf("offline fetch = %q, %v (%d requests)", content, err, requests-before)
+	}
+	if _, err := fetch(&config.ServiceConfig{Path: server.URL + "/other.yaml"}); err == nil || !strings.Contains(err.Error(), "isn't cached") {
+		test.Errorf("offline fetch of an uncached URL error = %v", err)
+	}
+}
+
+func TestParseNetrc(test *testing.T) {
+	const netrc = `machine example.com login alice password s3cret
+macdef init
+cd /pub
+machine ignored.com login nobody
+
+machine git.internal
+  login bob
+  password hunter2
+default login anonymous password guest
+`
+	tests := []struct {
+		name     string
+		host     string
+		login    string
+		password string
+		found    bool
+	}{
+		{name: "single line", host: "example.com", login: "alice", password: "s3cret", found: true},
+		{name: "multi line", host: "git.internal", login: "bob", password: "hunter2", found: true},
+		{name: "macro body is skipped", host: "ignored.com", login: "anonymous", password: "guest", found: true},
+		{name: "default", host: "other.org", login: "anonymous", password: "guest", found: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			login, password, found := parseNetrc(strings.NewReader(netrc), tt.host)
+			if login != tt.login || password != tt.password || found != tt.found {
+				test.Errorf("parseNetrc(%q) = %q, %q, %v, want %q, %q, %v", tt.host, login, password, found, tt.login, tt.password, tt.found)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/hjames9/kraze/internal/config"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// configHashAnnotation is set on pod templates so workloads roll out when the
+// service's ConfigMaps or Secrets change
+const configHashAnnotation = "kraze.dev/config-hash"
+
+// RenderForApply renders a service without a cluster, as 'kraze up' would
+// leave it applied: manifests carry kraze's tracking labels and workloads carry
+// the config hash annotation kraze patches in after applying. Secrets from the
+// service's secrets section are left out, since resolving them reads secret stores.
+func RenderForApply(ctx context.Context, service *config.ServiceConfig, opts *ProviderOptions) ([]*unstructured.Unstructured, error) {
+	resources, err := RenderOffline(ctx, service, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := decorateForApply(service, resources); err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+// decorateForApply adds what installing sets on top of the rendered resources:
+// tracking labels on manifests and the config hash on workloads, computed like
+// install does from everything but Helm hooks
+func decorateForApply(service *config.ServiceConfig, resources []*unstructured.Unstructured) error {
+	if service.Type == "manifests" {
+		manifest := &ManifestsProvider{}
+		for _, obj := range resources {
+			manifest.addTrackingLabels(obj, service)
+		}
+	}
+
+	checksum, err := calculateConfigChecksumFromObjects(withoutHooks(resources))
+	if err != nil {
+		return fmt.Errorf("failed to calculate config checksum: %w", err)
+	}
+	injectConfigHash(resources, checksum)
+	return nil
+}
+
+// withoutHooks returns the resources that aren't Helm hooks
+func withoutHooks(resources []*unstructured.Unstructured) []*unstructured.Unstructured {
+	var kept []*unstructured.Unstructured
+	for _, obj := range resources {
+		if _, isHook := obj.GetAnnotations()[helmHookAnnotation]; !isHook {
+			kept = append(kept, obj)
+		}
+	}
+	return kept
+}
+
+// injectConfigHash sets the config hash annotation on the pod templates of
+// Deployments, StatefulSets and DaemonSets
+func injectConfigHash(resources []*unstructured.Unstructured, checksum string) {
+	if checksum == "" {
+		return
+	}
+	for _, obj := range resources {
+		switch obj.GetKind() {
+		case "Deployment", "StatefulSet", "DaemonSet":
+		default:
+			continue
+		}
+		annotations, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "template", "metadata", "annotations")
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[configHashAnnotation] = checksum
+		_ = unstructured.SetNestedStringMap(obj.Object, annotations, "spec", "template", "metadata", "annotations")
+	}
+}
+
+// NamespaceObject returns a Namespace resource, for namespaces kraze creates
+func NamespaceObject(name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("Namespace")
+	obj.SetName(name)
+	return obj
+}
+
+// ParseResources reads multi-document YAML, such as MarshalResources writes
+func ParseResources(data []byte) ([]*unstructured.Unstructured, error) {
+	return parseManifestsYAML(string(data))
+}
+
+// MarshalResources serializes resources as multi-document YAML, indented like kubectl's
+func MarshalResources(resources []*unstructured.Unstructured) ([]byte, error) {
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	for _, obj := range resources {
+		if err := encoder.Encode(obj.Object); err != nil {
+			return nil, fmt.Errorf("failed to serialize %s/%s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to serialize resources: %w", err)
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,71 @@
+package providers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestRenderForApply(test *testing.T) {
+	dir := test.TempDir()
+	manifest := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  LOG_LEVEL: debug
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  template:
+    metadata:
+      annotations:
+        team: backend
+`
+	if err := os.WriteFile(filepath.Join(dir, "app.yaml"), []byte(manifest), 0644); err != nil {
+		test.Fatal(err)
+	}
+	service := &config.ServiceConfig{Name: "api", Type: "manifests", Path: dir, Namespace: "app"}
+
+	resources, err := RenderForApply(context.Background(), service, &ProviderOptions{Quiet: true})
+	if err != nil {
+		test.Fatalf("RenderForApply() error = %v", err)
+	}
+	if len(resources) != 2 {
+		test.Fatalf("RenderForApply() returned %d resources, want 2", len(resources))
+	}
+
+	for _, obj := range resources {
+		if obj.GetLabels()[serviceLabel] != "api" || obj.GetLabels()[managedByLabel] != "kraze" {
+			test.Errorf("%s labels = %v, want kraze tracking labels", obj.GetName(), obj.GetLabels())
+		}
+		if obj.GetNamespace() != "app" {
+			test.Errorf("%s namespace = %q, want app", obj.GetName(), obj.GetNamespace())
+		}
+	}
+
+	checksum, _ := calculateConfigChecksumFromObjects(resources)
+	annotations, _, _ := unstructured.NestedStringMap(resources[1].Object, "spec", "template", "metadata", "annotations")
+	if annotations[configHashAnnotation] != checksum || checksum == "" {
+		test.Errorf("pod template annotations = %v, want %s=%s", annotations, configHashAnnotation, checksum)
+	}
+	if annotations["team"] != "backend" {
+		test.Errorf("pod template annotations = %v, existing annotation dropped", annotations)
+	}
+
+	data, err := MarshalResources(resources)
+	if err != nil {
+		test.Fatalf("MarshalResources() error = %v", err)
+	}
+	if docs := strings.Split(string(data), "---\n"); len(docs) != 2 || !strings.Contains(docs[1], "kind: Deployment") {
+		test.Errorf("MarshalResources() = %s, want two documents", data)
+	}
+}
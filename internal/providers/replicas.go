@@ -0,0 +1,69 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hjames9/kraze/internal/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ReplicaCount is the ready and desired pod count across a service's workloads
+type ReplicaCount struct {
+	Ready     int32 `json:"ready"`
+	Desired   int32 `json:"desired"`
+	Workloads int   `json:"workloads"` // Deployments, StatefulSets and DaemonSets counted
+}
+
+// String formats the count as ready/desired
+func (count ReplicaCount) String() string {
+	return fmt.Sprintf("%d/%d", count.Ready, count.Desired)
+}
+
+// ServiceReplicas sums the replicas of a service's Deployments, StatefulSets and DaemonSets
+func ServiceReplicas(ctx context.Context, clientset kubernetes.Interface, service *config.ServiceConfig) (ReplicaCount, error) {
+	namespace := service.GetNamespace()
+	listOpts := metav1.ListOptions{LabelSelector: serviceWorkloadSelector(service)}
+	var count ReplicaCount
+
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, listOpts)
+	if err != nil {
+		return count, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for _, deployment := range deployments.Items {
+		desired := int32(1)
+		if deployment.Spec.Replicas != nil {
+			desired = *deployment.Spec.Replicas
+		}
+		count.Desired += desired
+		count.Ready += deployment.Status.ReadyReplicas
+		count.Workloads++
+	}
+
+	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(ctx, listOpts)
+	if err != nil {
+		return count, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for _, statefulSet := range statefulSets.Items {
+		desired := int32(1)
+		if statefulSet.Spec.Replicas != nil {
+			desired = *statefulSet.Spec.Replicas
+		}
+		count.Desired += desired
+		count.Ready += statefulSet.Status.ReadyReplicas
+		count.Workloads++
+	}
+
+	daemonSets, err := clientset.AppsV1().DaemonSets(namespace).List(ctx, listOpts)
+	if err != nil {
+		return count, fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+	for _, daemonSet := range daemonSets.Items {
+		count.Desired += daemonSet.Status.DesiredNumberScheduled
+		count.Ready += daemonSet.Status.NumberReady
+		count.Workloads++
+	}
+
+	return count, nil
+}
@@ -0,0 +1,68 @@
+package providers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestServiceReplicas(test *testing.T) {
+	owned := map[string]string{managedByLabel: "kraze", serviceLabel: "api"}
+	three := int32(3)
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "app", Labels: owned},
+			Spec:       appsv1.DeploymentSpec{Replicas: &three},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: 2},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-cache", Namespace: "app", Labels: owned},
+			Status:     appsv1.StatefulSetStatus{ReadyReplicas: 1},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-agent", Namespace: "app", Labels: owned},
+			Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: 2, NumberReady: 2},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "app", Labels: map[string]string{managedByLabel: "kraze", serviceLabel: "worker"}},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: 1},
+		},
+	)
+
+	tests := []struct {
+		name     string
+		service  config.ServiceConfig
+		expected ReplicaCount
+	}{
+		{
+			name:     "sums all workload kinds",
+			service:  config.ServiceConfig{Name: "api", Type: "manifests", Namespace: "app"},
+			expected: ReplicaCount{Ready: 5, Desired: 6, Workloads: 3},
+		},
+		{
+			name:     "deployment without replicas defaults to one",
+			service:  config.ServiceConfig{Name: "worker", Type: "manifests", Namespace: "app"},
+			expected: ReplicaCount{Ready: 1, Desired: 1, Workloads: 1},
+		},
+		{
+			name:    "service without workloads",
+			service: config.ServiceConfig{Name: "jobs", Type: "manifests", Namespace: "app"},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			count, err := ServiceReplicas(context.Background(), clientset, &tt.service)
+			if err != nil {
+				test.Fatalf("ServiceReplicas() error = %v", err)
+			}
+			if count != tt.expected {
+				test.Errorf("ServiceReplicas() = %+v, want %+v", count, tt.expected)
+			}
+		})
+	}
+}
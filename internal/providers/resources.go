@@ -0,0 +1,233 @@
+package providers
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/config"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// overriddenResources are the container resources resources_override changes
+var overriddenResources = []string{"cpu", "memory"}
+
+// ResourceChange is a container request or limit changed by resources_override
+type ResourceChange struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Container string `json:"container"`
+	Field     string `json:"field"`        // e.g. requests.cpu
+	From      string `json:"from"`         // Rendered value
+	To        string `json:"to,omitempty"` // Applied value, empty when removed
+}
+
+// String formats the change as Kind/name [container] field: from -> to
+func (change ResourceChange) String() string {
+	to := change.To
+	if to == "" {
+		to = "removed"
+	}
+	return fmt.Sprintf("%s/%s [%s] %s: %s -> %s", change.Kind, change.Name, change.Container, change.Field, change.From, to)
+}
+
+// podSpecPath returns where the pod spec of a pod-creating kind is, or nil for other kinds
+func podSpecPath(kind string) []string {
+	switch kind {
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job":
+		return []string{"spec", "template", "spec"}
+	case "CronJob":
+		return []string{"spec", "jobTemplate", "spec", "template", "spec"}
+	case "Pod":
+		return []string{"spec"}
+	}
+	return nil
+}
+
+// OverrideResources applies a service's resources_override to the containers
+// and init containers of a workload, returning what it changed. Requests left
+// above a capped limit are lowered to it, as Kubernetes requires.
+func OverrideResources(obj *unstructured.Unstructured, override *config.ResourcesOverride) []ResourceChange {
+	specPath := podSpecPath(obj.GetKind())
+	if specPath == nil || !override.IsEnabled() {
+		return nil
+	}
+
+	var changes []ResourceChange
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, found, err := unstructured.NestedSlice(obj.Object, append(specPath, field)...)
+		if err != nil || !found {
+			continue
+		}
+		changed := false
+		for _, itr := range containers {
+			container, ok := itr.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := container["name"].(string)
+			containerChanges := overrideContainerResources(container, override)
+			for idx := range containerChanges {
+				containerChanges[idx].Kind = obj.GetKind()
+				containerChanges[idx].Name = obj.GetName()
+				containerChanges[idx].Container = name
+			}
+			changes = append(changes, containerChanges...)
+			changed = changed || len(containerChanges) > 0
+		}
+		if changed {
+			unstructured.SetNestedSlice(obj.Object, containers, append(specPath, field)...)
+		}
+	}
+	return changes
+}
+
+// overrideContainerResources applies an override to one container's resources
+func overrideContainerResources(container map[string]interface{}, override *config.ResourcesOverride) []ResourceChange {
+	resources, _ := container["resources"].(map[string]interface{})
+	if resources == nil {
+		return nil
+	}
+	requests, _ := resources["requests"].(map[string]interface{})
+	limits, _ := resources["limits"].(map[string]interface{})
+
+	var changes []ResourceChange
+	strip := func(values map[string]interface{}, field string) {
+		for _, name := range overriddenResources {
+			if value, exists := values[name]; exists {
+				changes = append(changes, ResourceChange{Field: field + "." + name, From: fmt.Sprint(value)})
+				delete(values, name)
+			}
+		}
+	}
+	lower := func(values map[string]interface{}, field, name string, max resource.Quantity) {
+		value, exists := values[name]
+		if !exists {
+			return
+		}
+		current, err := resource.ParseQuantity(fmt.Sprint(value))
+		if err != nil || current.Cmp(max) <= 0 {
+			return
+		}
+		values[name] = max.String()
+		changes = append(changes, ResourceChange{Field: field + "." + name, From: fmt.Sprint(value), To: max.String()})
+	}
+
+	// Kubernetes defaults a missing request to the limit, so stripping
+	// requests strips the limits too
+	if (override.ShouldStripLimits() || override.ShouldStripRequests()) && limits != nil {
+		strip(limits, "limits")
+	}
+	if override.ShouldStripRequests() && requests != nil {
+		strip(requests, "requests")
+	}
+	for _, name := range overriddenResources {
+		if max, ok := resourceCap(override.MaxLimits, name); ok && limits != nil {
+			lower(limits, "limits", name, max)
+		}
+		if max, ok := resourceCap(override.MaxRequests, name); ok && requests != nil {
+			lower(requests, "requests", name, max)
+		}
+		// A request can't exceed its limit
+		if value, exists := limits[name]; exists && requests != nil {
+			if limit, err := resource.ParseQuantity(fmt.Sprint(value)); err == nil {
+				lower(requests, "requests", name, limit)
+			}
+		}
+	}
+
+	for field, values := range map[string]map[string]interface{}{"requests": requests, "limits": limits} {
+		if values != nil && len(values) == 0 {
+			delete(resources, field)
+		}
+	}
+	return changes
+}
+
+// resourceCap returns the cap for cpu or memory, if one is set
+func resourceCap(cap *config.ResourceCap, name string) (resource.Quantity, bool) {
+	if cap == nil {
+		return resource.Quantity{}, false
+	}
+	value := cap.CPU
+	if name == "memory" {
+		value = cap.Memory
+	}
+	if value == "" {
+		return resource.Quantity{}, false
+	}
+	quantity, err := resource.ParseQuantity(value)
+	return quantity, err == nil
+}
+
+// documentSeparator splits multi-document YAML
+var documentSeparator = regexp.MustCompile(`(?m)^---[ \t]*$`)
+
+// transformManifests changes the objects in multi-document YAML with
+// transform, which reports whether it changed an object. Documents it doesn't
+// change are kept byte for byte.
+func transformManifests(manifests []byte, transform func(obj *unstructured.Unstructured) bool) ([]byte, error) {
+	documents := documentSeparator.Split(string(manifests), -1)
+	changed := false
+	for itr, document := range documents {
+		var object map[string]interface{}
+		if err := sigsyaml.Unmarshal([]byte(document), &object); err != nil || object == nil {
+			continue
+		}
+		obj := &unstructured.Unstructured{Object: object}
+		if !transform(obj) {
+			continue
+		}
+		data, err := sigsyaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s/%s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		// Keep the leading comments, such as helm's "# Source:" lines
+		var comments strings.Builder
+		for _, line := range strings.Split(strings.TrimLeft(document, "\n"), "\n") {
+			if !strings.HasPrefix(line, "#") {
+				break
+			}
+			comments.WriteString(line + "\n")
+		}
+		documents[itr] = "\n" + comments.String() + string(data)
+		changed = true
+	}
+	if !changed {
+		return manifests, nil
+	}
+	return []byte(strings.Join(documents, "---")), nil
+}
+
+// overrideManifestResources applies resources_override to the workloads in
+// multi-document YAML
+func overrideManifestResources(manifests []byte, override *config.ResourcesOverride) ([]byte, []ResourceChange, error) {
+	var changes []ResourceChange
+	result, err := transformManifests(manifests, func(obj *unstructured.Unstructured) bool {
+		objChanges := OverrideResources(obj, override)
+		changes = append(changes, objChanges...)
+		return len(objChanges) > 0
+	})
+	return result, changes, err
+}
+
+// reportResourceChanges passes what resources_override changed to the
+// handler, or prints it when there is none
+func reportResourceChanges(service *config.ServiceConfig, changes []ResourceChange, opts *ProviderOptions) {
+	if len(changes) == 0 {
+		return
+	}
+	if opts.OnResourcesOverridden != nil {
+		opts.OnResourcesOverridden(changes)
+		return
+	}
+	if opts.Quiet {
+		return
+	}
+	fmt.Fprintf(opts.output(), "resources_override changed %d request(s) and limit(s) of '%s':\n", len(changes), service.Name)
+	for _, change := range changes {
+		fmt.Fprintf(opts.output(), "  %s\n", change.String())
+	}
+}
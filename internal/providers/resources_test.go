@@ -0,0 +1,164 @@
+package providers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// workloadWithResources returns a Deployment with one container using the given resources
+func workloadWithResources(resources map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "api"},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "app", "image": "api:1.0", "resources": resources},
+					},
+				},
+			},
+		},
+	}}
+}
+
+func TestOverrideResources(test *testing.T) {
+	enabled := true
+	tests := []struct {
+		name      string
+		resources map[string]interface{}
+		override  *config.ResourcesOverride
+		expected  map[string]interface{}
+		changes   []string
+	}{
+		{
+			name: "cap requests",
+			resources: map[string]interface{}{
+				"requests": map[string]interface{}{"cpu": "2", "memory": "256Mi"},
+			},
+			override: &config.ResourcesOverride{MaxRequests: &config.ResourceCap{CPU: "500m", Memory: "512Mi"}},
+			expected: map[string]interface{}{
+				"requests": map[string]interface{}{"cpu": "500m", "memory": "256Mi"},
+			},
+			changes: []string{"Deployment/api [app] requests.cpu: 2 -> 500m"},
+		},
+		{
+			name: "capped limit lowers the request",
+			resources: map[string]interface{}{
+				"requests": map[string]interface{}{"memory": "2Gi"},
+				"limits":   map[string]interface{}{"memory": int64(4294967296)},
+			},
+			override: &config.ResourcesOverride{MaxLimits: &config.ResourceCap{Memory: "1Gi"}},
+			expected: map[string]interface{}{
+				"requests": map[string]interface{}{"memory": "1Gi"},
+				"limits":   map[string]interface{}{"memory": "1Gi"},
+			},
+			changes: []string{
+				"Deployment/api [app] limits.memory: 4294967296 -> 1Gi",
+				"Deployment/api [app] requests.memory: 2Gi -> 1Gi",
+			},
+		},
+		{
+			name: "strip limits",
+			resources: map[string]interface{}{
+				"requests": map[string]interface{}{"cpu": "100m"},
+				"limits":   map[string]interface{}{"cpu": "1", "nvidia.com/gpu": "1"},
+			},
+			override: &config.ResourcesOverride{StripLimits: &enabled},
+			expected: map[string]interface{}{
+				"requests": map[string]interface{}{"cpu": "100m"},
+				"limits":   map[string]interface{}{"nvidia.com/gpu": "1"},
+			},
+			changes: []string{"Deployment/api [app] limits.cpu: 1 -> removed"},
+		},
+		{
+			name: "strip requests removes the empty map",
+			resources: map[string]interface{}{
+				"requests": map[string]interface{}{"cpu": "100m", "memory": "64Mi"},
+			},
+			override: &config.ResourcesOverride{StripRequests: &enabled},
+			expected: map[string]interface{}{},
+			changes: []string{
+				"Deployment/api [app] requests.cpu: 100m -> removed",
+				"Deployment/api [app] requests.memory: 64Mi -> removed",
+			},
+		},
+		{
+			name: "strip requests strips the limits they default to",
+			resources: map[string]interface{}{
+				"requests": map[string]interface{}{"memory": "2Gi"},
+				"limits":   map[string]interface{}{"memory": "4Gi", "nvidia.com/gpu": "1"},
+			},
+			override: &config.ResourcesOverride{StripRequests: &enabled},
+			expected: map[string]interface{}{
+				"limits": map[string]interface{}{"nvidia.com/gpu": "1"},
+			},
+			changes: []string{
+				"Deployment/api [app] limits.memory: 4Gi -> removed",
+				"Deployment/api [app] requests.memory: 2Gi -> removed",
+			},
+		},
+		{
+			name: "disabled",
+			resources: map[string]interface{}{
+				"requests": map[string]interface{}{"cpu": "2"},
+			},
+			override: &config.ResourcesOverride{MaxRequests: &config.ResourceCap{CPU: "500m"}, Disabled: true},
+			expected: map[string]interface{}{
+				"requests": map[string]interface{}{"cpu": "2"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			obj := workloadWithResources(tt.resources)
+			var changes []string
+			for _, change := range OverrideResources(obj, tt.override) {
+				changes = append(changes, change.String())
+			}
+			if !reflect.DeepEqual(changes, tt.changes) {
+				test.Errorf("Expected changes %v, got %v", tt.changes, changes)
+			}
+
+			containers, _, _ := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+			result := containers[0].(map[string]interface{})["resources"]
+			if !reflect.DeepEqual(result, tt.expected) {
+				test.Errorf("Expected resources %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestOverrideManifestResources(test *testing.T) {
+	manifests := "---\n# Source: chart/templates/service.yaml\napiVersion: v1\nkind: Service\nmetadata:\n  name: api\n" +
+		"---\n# Source: chart/templates/deployment.yaml\n" + postRenderManifests[:strings.Index(postRenderManifests, "---")]
+	override := &config.ResourcesOverride{MaxRequests: &config.ResourceCap{CPU: "250m"}}
+
+	result, changes, err := overrideManifestResources([]byte(manifests), override)
+	if err != nil {
+		test.Fatalf("overrideManifestResources() error = %v", err)
+	}
+	if len(changes) != 1 || changes[0].To != "250m" {
+		test.Errorf("Expected requests.cpu to be capped, got %v", changes)
+	}
+	output := string(result)
+	if !strings.HasPrefix(output, "---\n# Source: chart/templates/service.yaml\napiVersion: v1\nkind: Service\nmetadata:\n  name: api\n---") {
+		test.Errorf("Expected the unchanged Service to be kept as is:\n%s", output)
+	}
+	for _, expected := range []string{"# Source: chart/templates/deployment.yaml", "cpu: 250m", "imagePullPolicy: Always"} {
+		if !strings.Contains(output, expected) {
+			test.Errorf("Expected output to contain %q:\n%s", expected, output)
+		}
+	}
+
+	unchanged, changes, err := overrideManifestResources([]byte(manifests), &config.ResourcesOverride{MaxRequests: &config.ResourceCap{CPU: "4"}})
+	if err != nil || len(changes) != 0 || string(unchanged) != manifests {
+		test.Errorf("Expected manifests within the caps to be returned unchanged, got %v, %v", changes, err)
+	}
+}
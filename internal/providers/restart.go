@@ -0,0 +1,72 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hjames9/kraze/internal/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// restartedAtAnnotation is set on pod templates to roll workloads, like kubectl rollout restart
+const restartedAtAnnotation = "kraze.dev/restartedAt"
+
+// serviceWorkloadSelector returns the label selector for the workloads of a service:
+// the release instance label for Helm, the kraze tracking labels for manifests
+func serviceWorkloadSelector(service *config.ServiceConfig) string {
+	if service.IsHelm() {
+		return labels.Set{"app.kubernetes.io/instance": service.Name}.String()
+	}
+	return labels.Set{managedByLabel: "kraze", serviceLabel: service.Name}.String()
+}
+
+// RestartServiceWorkloads rolls the Deployments, StatefulSets and DaemonSets of a
+// service so their pods are recreated, e.g. to pick up an image reloaded under the
+// same tag. Returns the restarted workloads as kind/name.
+func RestartServiceWorkloads(ctx context.Context, clientset kubernetes.Interface, service *config.ServiceConfig) ([]string, error) {
+	namespace := service.GetNamespace()
+	listOpts := metav1.ListOptions{LabelSelector: serviceWorkloadSelector(service)}
+	patch := []byte(fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{%q:%q}}}}}`,
+		restartedAtAnnotation, time.Now().Format(time.RFC3339)))
+
+	var restarted []string
+
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, listOpts)
+	if err != nil {
+		return restarted, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for _, deployment := range deployments.Items {
+		if _, err := clientset.AppsV1().Deployments(namespace).Patch(ctx, deployment.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return restarted, fmt.Errorf("failed to restart deployment %s: %w", deployment.Name, err)
+		}
+		restarted = append(restarted, "Deployment/"+deployment.Name)
+	}
+
+	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(ctx, listOpts)
+	if err != nil {
+		return restarted, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for _, statefulSet := range statefulSets.Items {
+		if _, err := clientset.AppsV1().StatefulSets(namespace).Patch(ctx, statefulSet.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return restarted, fmt.Errorf("failed to restart statefulset %s: %w", statefulSet.Name, err)
+		}
+		restarted = append(restarted, "StatefulSet/"+statefulSet.Name)
+	}
+
+	daemonSets, err := clientset.AppsV1().DaemonSets(namespace).List(ctx, listOpts)
+	if err != nil {
+		return restarted, fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+	for _, daemonSet := range daemonSets.Items {
+		if _, err := clientset.AppsV1().DaemonSets(namespace).Patch(ctx, daemonSet.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return restarted, fmt.Errorf("failed to restart daemonset %s: %w", daemonSet.Name, err)
+		}
+		restarted = append(restarted, "DaemonSet/"+daemonSet.Name)
+	}
+
+	return restarted, nil
+}
@@ -0,0 +1,71 @@
+package providers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRestartServiceWorkloads(test *testing.T) {
+	owned := map[string]string{managedByLabel: "kraze", serviceLabel: "api"}
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "app", Labels: owned}},
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "api-cache", Namespace: "app", Labels: owned}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "app", Labels: map[string]string{managedByLabel: "kraze", serviceLabel: "worker"}}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "redis-master", Namespace: "app", Labels: map[string]string{"app.kubernetes.io/instance": "redis"}}},
+	)
+
+	tests := []struct {
+		name     string
+		service  config.ServiceConfig
+		expected []string
+	}{
+		{
+			name:     "manifests service via tracking labels",
+			service:  config.ServiceConfig{Name: "api", Type: "manifests", Namespace: "app"},
+			expected: []string{"Deployment/api", "StatefulSet/api-cache"},
+		},
+		{
+			name:     "helm service via release label",
+			service:  config.ServiceConfig{Name: "redis", Type: "helm", Namespace: "app"},
+			expected: []string{"Deployment/redis-master"},
+		},
+		{
+			name:    "service without workloads",
+			service: config.ServiceConfig{Name: "jobs", Type: "manifests", Namespace: "app"},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			restarted, err := RestartServiceWorkloads(context.Background(), clientset, &tt.service)
+			if err != nil {
+				test.Fatalf("RestartServiceWorkloads() error = %v", err)
+			}
+			if !reflect.DeepEqual(restarted, tt.expected) {
+				test.Errorf("restarted = %v, want %v", restarted, tt.expected)
+			}
+		})
+	}
+
+	deployment, err := clientset.AppsV1().Deployments("app").Get(context.Background(), "api", metav1.GetOptions{})
+	if err != nil {
+		test.Fatal(err)
+	}
+	if deployment.Spec.Template.Annotations[restartedAtAnnotation] == "" {
+		test.Errorf("expected %s on the pod template, got %v", restartedAtAnnotation, deployment.Spec.Template.Annotations)
+	}
+
+	worker, err := clientset.AppsV1().Deployments("app").Get(context.Background(), "worker", metav1.GetOptions{})
+	if err != nil {
+		test.Fatal(err)
+	}
+	if _, ok := worker.Spec.Template.Annotations[restartedAtAnnotation]; ok {
+		test.Error("expected other services' workloads to be left alone")
+	}
+}
@@ -0,0 +1,68 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// transientMessages are parts of error messages that mean a retry may
+// succeed, for errors that reach kraze as text (Helm and registry clients
+// often flatten the errors they wrap)
+var transientMessages = []string{
+	"failed calling webhook",             // Admission webhook whose pods aren't ready yet
+	"no endpoints available for service", // Webhook or API service without ready pods
+	"connection refused",
+	"connection reset by peer",
+	"broken pipe",
+	"i/o timeout",
+	"tls handshake timeout",
+	"unexpected eof",
+	"http2: client connection lost",
+	"the server is currently unable to handle the request",
+	"the server was unable to return a response in the time allotted",
+	"etcdserver: request timed out",
+	"etcdserver: leader changed",
+	"too many requests",
+	"toomanyrequests", // Registry rate limits
+	"502 bad gateway",
+	"503 service unavailable",
+	"504 gateway timeout",
+	"temporary failure in name resolution",
+	"another operation (install/upgrade/rollback) is in progress",
+}
+
+// IsTransientError reports whether an install failed for a reason that may go
+// away on its own, such as a webhook that isn't ready yet, a registry hiccup
+// or the API server briefly unreachable. Errors in the config, templates or
+// manifests, and cancellation, are not transient.
+func IsTransientError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) || apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) || apierrors.IsInternalError(err) || apierrors.IsUnexpectedServerError(err) {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, part := range transientMessages {
+		if strings.Contains(message, part) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestIsTransientError(test *testing.T) {
+	resource := schema.GroupResource{Resource: "deployments"}
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "nil", err: nil, expected: false},
+		{name: "webhook not ready", err: errors.New(`Internal error occurred: failed calling webhook "validate.nginx.ingress.kubernetes.io": no endpoints available for service "ingress-nginx-controller-admission"`), expected: true},
+		{name: "server timeout", err: apierrors.NewServerTimeout(resource, "create", 1), expected: true},
+		{name: "too many requests", err: apierrors.NewTooManyRequests("slow down", 1), expected: true},
+		{name: "service unavailable", err: apierrors.NewServiceUnavailable("apiserver restarting"), expected: true},
+		{name: "wrapped connection refused", err: fmt.Errorf("failed to apply: %w", syscall.ECONNREFUSED), expected: true},
+		{name: "registry rate limit", err: errors.New("failed to pull chart: response status code 429: toomanyrequests: rate limit exceeded"), expected: true},
+		{name: "registry gateway error", err: errors.New("failed to fetch https://charts.example.com/index.yaml : 503 Service Unavailable"), expected: true},
+		{name: "invalid manifest", err: apierrors.NewInvalid(schema.GroupKind{Kind: "Deployment"}, "api", nil), expected: false},
+		{name: "template error", err: errors.New(`template: api/templates/deployment.yaml:12: function "lookupp" not defined`), expected: false},
+		{name: "forbidden", err: apierrors.NewForbidden(resource, "api", errors.New("no RBAC")), expected: false},
+		{name: "cancelled", err: fmt.Errorf("install: %w", context.Canceled), expected: false},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := IsTransientError(tt.err); got != tt.expected {
+				test.Errorf("IsTransientError(%v) = %v, expected %v", tt.err, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/config"
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/chart"
+	ri "helm.sh/helm/v4/pkg/release"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ChartMetadata is the chart a Helm release was installed from
+type ChartMetadata struct {
+	Name         string
+	Version      string
+	AppVersion   string
+	Dependencies []ChartMetadata // Subcharts
+}
+
+// ReleaseChart returns the chart of a service's installed Helm release, with its subcharts
+func ReleaseChart(service *config.ServiceConfig, opts *ProviderOptions) (*ChartMetadata, error) {
+	helm, err := NewHelmProvider(opts)
+	if err != nil {
+		return nil, err
+	}
+	actionConfig, err := helm.getActionConfig(service.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	rel, err := action.NewGet(actionConfig).Run(service.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get release: %w", err)
+	}
+	acc, err := ri.NewAccessor(rel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read release: %w", err)
+	}
+	return chartMetadata(acc.Chart())
+}
+
+// chartMetadata reads the name and versions of a chart and its subcharts
+func chartMetadata(chrt chart.Charter) (*ChartMetadata, error) {
+	acc, err := chart.NewAccessor(chrt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chart: %w", err)
+	}
+	meta := acc.MetadataAsMap()
+	metadata := &ChartMetadata{Name: acc.Name()}
+	metadata.Version, _ = meta["Version"].(string)
+	metadata.AppVersion, _ = meta["AppVersion"].(string)
+	for _, dependency := range acc.Dependencies() {
+		sub, err := chartMetadata(dependency)
+		if err != nil {
+			return nil, err
+		}
+		metadata.Dependencies = append(metadata.Dependencies, *sub)
+	}
+	return metadata, nil
+}
+
+// RunningImage is an image a service's pods run, with the digest the node resolved
+type RunningImage struct {
+	Image   string // Reference as the container runtime reports it, e.g. docker.io/library/redis:7.2
+	Digest  string // Repository digest (sha256:...) when the image was pulled from a registry
+	ImageID string // Image ID (sha256:...) when it was loaded into the node rather than pulled
+}
+
+// ServiceRunningImages returns the images the containers and init containers
+// of a service's pods run
+func ServiceRunningImages(ctx context.Context, clientset kubernetes.Interface, service *config.ServiceConfig) ([]RunningImage, error) {
+	namespace := service.GetNamespace()
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: serviceWorkloadSelector(service)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods in '%s': %w", namespace, err)
+	}
+
+	seen := make(map[string]bool)
+	var images []RunningImage
+	for _, pod := range pods.Items {
+		for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+			if status.ImageID == "" {
+				continue
+			}
+			image := parseRunningImage(status.Image, status.ImageID)
+			if seen[image.Image+"|"+image.Digest+"|"+image.ImageID] {
+				continue
+			}
+			seen[image.Image+"|"+image.Digest+"|"+image.ImageID] = true
+			images = append(images, image)
+		}
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i].Image < images[j].Image })
+	return images, nil
+}
+
+// parseRunningImage reads a container status's image and image ID, which is
+// repository@sha256:... for pulled images and a bare sha256:... for loaded ones
+func parseRunningImage(image, imageID string) RunningImage {
+	running := RunningImage{Image: image}
+	imageID = strings.TrimPrefix(imageID, "docker-pullable://")
+	if at := strings.LastIndex(imageID, "@"); at != -1 {
+		running.Digest = imageID[at+1:]
+	} else {
+		running.ImageID = imageID
+	}
+	// Images run by digest are reported by their digest only
+	if at := strings.Index(running.Image, "@"); at != -1 {
+		running.Image = running.Image[:at]
+	}
+	return running
+}
@@ -0,0 +1,83 @@
+package providers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParseRunningImage(test *testing.T) {
+	tests := []struct {
+		name    string
+		image   string
+		imageID string
+		want    RunningImage
+	}{
+		{
+			name:    "pulled image",
+			image:   "docker.io/library/redis:7.2",
+			imageID: "docker.io/library/redis@sha256:abc",
+			want:    RunningImage{Image: "docker.io/library/redis:7.2", Digest: "sha256:abc"},
+		},
+		{
+			name:    "docker runtime prefix",
+			image:   "redis:7.2",
+			imageID: "docker-pullable://redis@sha256:abc",
+			want:    RunningImage{Image: "redis:7.2", Digest: "sha256:abc"},
+		},
+		{
+			name:    "loaded image",
+			image:   "docker.io/library/api:dev",
+			imageID: "sha256:def",
+			want:    RunningImage{Image: "docker.io/library/api:dev", ImageID: "sha256:def"},
+		},
+		{
+			name:    "run by digest",
+			image:   "ghcr.io/org/app@sha256:abc",
+			imageID: "ghcr.io/org/app@sha256:abc",
+			want:    RunningImage{Image: "ghcr.io/org/app", Digest: "sha256:abc"},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := parseRunningImage(tt.image, tt.imageID); got != tt.want {
+				test.Errorf("parseRunningImage() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceRunningImages(test *testing.T) {
+	pod := func(name string, labels map[string]string, statuses ...corev1.ContainerStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels},
+			Status:     corev1.PodStatus{ContainerStatuses: statuses},
+		}
+	}
+	helmLabels := map[string]string{"app.kubernetes.io/instance": "cache"}
+	clientset := fake.NewSimpleClientset(
+		pod("cache-0", helmLabels,
+			corev1.ContainerStatus{Image: "docker.io/library/redis:7.2", ImageID: "docker.io/library/redis@sha256:aaa"},
+			corev1.ContainerStatus{Image: "docker.io/library/busybox:1.36", ImageID: ""}),
+		pod("cache-1", helmLabels,
+			corev1.ContainerStatus{Image: "docker.io/library/redis:7.2", ImageID: "docker.io/library/redis@sha256:aaa"}),
+		pod("other", map[string]string{"app.kubernetes.io/instance": "other"},
+			corev1.ContainerStatus{Image: "docker.io/library/nginx:1.27", ImageID: "docker.io/library/nginx@sha256:bbb"}),
+	)
+
+	service := &config.ServiceConfig{Name: "cache", Type: "helm", Namespace: "default"}
+	images, err := ServiceRunningImages(context.Background(), clientset, service)
+	if err != nil {
+		test.Fatalf("ServiceRunningImages() error = %v", err)
+	}
+	want := []RunningImage{{Image: "docker.io/library/redis:7.2", Digest: "sha256:aaa"}}
+	if !reflect.DeepEqual(images, want) {
+		test.Errorf("ServiceRunningImages() = %+v, want %+v", images, want)
+	}
+}
@@ -0,0 +1,193 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/hjames9/kraze/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// secretLabel marks the Secrets kraze creates from a service's secrets block
+const secretLabel = "kraze.secret"
+
+// runSecretCommand runs a secret manager's CLI and returns what it printed.
+// Replaced in tests.
+var runSecretCommand = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, fmt.Errorf("%s is not installed", name)
+		}
+		return nil, fmt.Errorf("%s failed: %s", name, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// resolveSecretSource reads the value of a Secret key from its source
+func resolveSecretSource(ctx context.Context, source config.SecretSource) ([]byte, error) {
+	switch {
+	case source.Env != "":
+		value, ok := os.LookupEnv(source.Env)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s is not set", source.Env)
+		}
+		return []byte(value), nil
+	case source.File != "":
+		data, err := os.ReadFile(source.File)
+		if err != nil {
+			return nil, err
+		}
+		return config.DecryptIfSOPS(ctx, source.File, data)
+	case source.OnePassword != "":
+		return runSecretCommand(ctx, "op", "read", "--no-newline", source.OnePassword)
+	case source.Vault != "":
+		path, field := source.VaultPathAndField()
+		value, err := runSecretCommand(ctx, "vault", "kv", "get", "-field="+field, path)
+		return bytes.TrimSuffix(value, []byte("\n")), err
+	}
+	return nil, fmt.Errorf("no source")
+}
+
+// serviceSecrets resolves a service's secrets block into Secrets. Values are
+// read on every install, so rotated credentials are picked up.
+func serviceSecrets(ctx context.Context, service *config.ServiceConfig) ([]*corev1.Secret, error) {
+	var secrets []*corev1.Secret
+	for _, secretConfig := range service.Secrets {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      secretConfig.Name,
+				Namespace: service.GetNamespace(),
+				Labels:    map[string]string{managedByLabel: "kraze", serviceLabel: service.Name, secretLabel: "true"},
+			},
+			Type: corev1.SecretType(secretConfig.GetType()),
+			Data: make(map[string][]byte, len(secretConfig.Data)),
+		}
+		for _, key := range secretConfig.Keys() {
+			source := secretConfig.Data[key]
+			value, err := resolveSecretSource(ctx, source)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s/%s from %s: %w", secretConfig.Name, key, source.Describe(), err)
+			}
+			secret.Data[key] = value
+		}
+		secrets = append(secrets, secret)
+	}
+	return secrets, nil
+}
+
+// ensureServiceSecrets creates or updates a service's Secrets (including its
+// local_tls certificate) before it installs, and deletes the ones removed from its secrets block. They're applied
+// directly rather than with the service's resources, so their values never
+// appear in rendered manifests or Helm release history.
+func ensureServiceSecrets(ctx context.Context, clientset kubernetes.Interface, service *config.ServiceConfig) error {
+	secrets, err := serviceSecrets(ctx, service)
+	if err != nil {
+		return err
+	}
+
+	namespace := service.GetNamespace()
+	client := clientset.CoreV1().Secrets(namespace)
+	if service.LocalTLS != nil {
+		existing, err := client.Get(ctx, service.LocalTLS.GetSecretName(service.Name), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			existing = nil
+		} else if err != nil {
+			return fmt.Errorf("failed to get Secret %s: %w", service.LocalTLS.GetSecretName(service.Name), err)
+		} else if !ownsSecret(existing, service) {
+			// Never reuse (or replace) a certificate kraze didn't issue
+			return secretNotOwnedError(existing, service)
+		}
+		secret, err := localTLSSecret(service, existing)
+		if err != nil {
+			return err
+		}
+		secrets = append(secrets, secret)
+	}
+
+	if len(secrets) > 0 && service.ShouldCreateNamespace() {
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+		if _, err := clientset.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create namespace: %w", err)
+		}
+	}
+
+	wanted := make(map[string]bool, len(secrets))
+	for _, secret := range secrets {
+		wanted[secret.Name] = true
+		existing, err := client.Get(ctx, secret.Name, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			if _, err := client.Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+				return fmt.Errorf("failed to create Secret %s: %w", secret.Name, err)
+			}
+		case err != nil:
+			return fmt.Errorf("failed to get Secret %s: %w", secret.Name, err)
+		case !ownsSecret(existing, service):
+			return secretNotOwnedError(existing, service)
+		case existing.Type != secret.Type:
+			// The type of a Secret can't be changed
+			if err := client.Delete(ctx, secret.Name, metav1.DeleteOptions{}); err != nil {
+				return fmt.Errorf("failed to replace Secret %s: %w", secret.Name, err)
+			}
+			if _, err := client.Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+				return fmt.Errorf("failed to create Secret %s: %w", secret.Name, err)
+			}
+		default:
+			if existing.Labels == nil {
+				existing.Labels = make(map[string]string, len(secret.Labels))
+			}
+			maps.Copy(existing.Labels, secret.Labels)
+			existing.Data = secret.Data
+			existing.StringData = nil
+			if _, err := client.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+				return fmt.Errorf("failed to update Secret %s: %w", secret.Name, err)
+			}
+		}
+	}
+
+	return deleteServiceSecrets(ctx, clientset, service, wanted)
+}
+
+// ownsSecret returns true if kraze created a Secret for the service, so it may
+// overwrite it
+func ownsSecret(secret *corev1.Secret, service *config.ServiceConfig) bool {
+	return secret.Labels[managedByLabel] == "kraze" && secret.Labels[serviceLabel] == service.Name && secret.Labels[secretLabel] == "true"
+}
+
+// secretNotOwnedError reports a Secret of the same name kraze didn't create for the service
+func secretNotOwnedError(secret *corev1.Secret, service *config.ServiceConfig) error {
+	return fmt.Errorf("Secret %s/%s already exists and wasn't created by kraze for '%s'; delete it or name the secret differently", secret.Namespace, secret.Name, service.Name)
+}
+
+// deleteServiceSecrets deletes the Secrets kraze created for a service, except
+// the ones kept
+func deleteServiceSecrets(ctx context.Context, clientset kubernetes.Interface, service *config.ServiceConfig, keep map[string]bool) error {
+	client := clientset.CoreV1().Secrets(service.GetNamespace())
+	selector := fmt.Sprintf("%s=true,%s=%s", secretLabel, serviceLabel, service.Name)
+	list, err := client.List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return fmt.Errorf("failed to list Secrets: %w", err)
+	}
+	for _, secret := range list.Items {
+		if keep[secret.Name] {
+			continue
+		}
+		if err := client.Delete(ctx, secret.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete Secret %s: %w", secret.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package providers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestResolveSecretSource(test *testing.T) {
+	test.Setenv("KRAZE_TEST_PASSWORD", "from-env")
+	file := filepath.Join(test.TempDir(), "token")
+	if err := os.WriteFile(file, []byte("from-file"), 0600); err != nil {
+		test.Fatal(err)
+	}
+
+	original := runSecretCommand
+	defer func() { runSecretCommand = original }()
+	runSecretCommand = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		return []byte(name + " " + strings.Join(args, " ") + "\n"), nil
+	}
+
+	tests := []struct {
+		name     string
+		source   config.SecretSource
+		expected string
+		wantErr  string
+	}{
+		{name: "env", source: config.SecretSource{Env: "KRAZE_TEST_PASSWORD"}, expected: "from-env"},
+		{name: "unset env", source: config.SecretSource{Env: "KRAZE_TEST_UNSET"}, wantErr: "is not set"},
+		{name: "file", source: config.SecretSource{File: file}, expected: "from-file"},
+		{name: "1Password", source: config.SecretSource{OnePassword: "op://dev/api/key"}, expected: "op read --no-newline op://dev/api/key\n"},
+		{name: "Vault", source: config.SecretSource{Vault: "secret/api#token"}, expected: "vault kv get -field=token secret/api"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			got, err := resolveSecretSource(context.Background(), tt.source)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					test.Errorf("resolveSecretSource() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				test.Fatalf("resolveSecretSource() error = %v", err)
+			}
+			if string(got) != tt.expected {
+				test.Errorf("resolveSecretSource() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEnsureServiceSecrets(test *testing.T) {
+	test.Setenv("KRAZE_TEST_PASSWORD", "rotated")
+	labels := map[string]string{managedByLabel: "kraze", serviceLabel: "api", secretLabel: "true"}
+	clientset := fake.NewSimpleClientset(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "shop", Labels: labels},
+			Data:       map[string][]byte{"password": []byte("old")},
+			Type:       corev1.SecretTypeOpaque,
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "removed", Namespace: "shop", Labels: labels},
+			Type:       corev1.SecretTypeOpaque,
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "shop"},
+			Type:       corev1.SecretTypeOpaque,
+		},
+	)
+
+	createNamespace := false
+	service := &config.ServiceConfig{
+		Name:            "api",
+		Namespace:       "shop",
+		CreateNamespace: &createNamespace,
+		Secrets: []config.SecretConfig{
+			{Name: "db", Data: map[string]config.SecretSource{"password": {Env: "KRAZE_TEST_PASSWORD"}}},
+			{Name: "tls", Type: "kubernetes.io/tls", Data: map[string]config.SecretSource{"tls.key": {Env: "KRAZE_TEST_PASSWORD"}}},
+		},
+	}
+	if err := ensureServiceSecrets(context.Background(), clientset, service); err != nil {
+		test.Fatalf("ensureServiceSecrets() error = %v", err)
+	}
+
+	secrets := clientset.CoreV1().Secrets("shop")
+	db, err := secrets.Get(context.Background(), "db", metav1.GetOptions{})
+	if err != nil || string(db.Data["password"]) != "rotated" {
+		test.Errorf("db = %v, %v, want the rotated password", db, err)
+	}
+	tls, err := secrets.Get(context.Background(), "tls", metav1.GetOptions{})
+	if err != nil || tls.Type != "kubernetes.io/tls" || !reflect.DeepEqual(tls.Labels, labels) {
+		test.Errorf("tls = %v, %v, want a labeled kubernetes.io/tls Secret", tls, err)
+	}
+	if _, err := secrets.Get(context.Background(), "removed", metav1.GetOptions{}); err == nil {
+		test.Error("removed should have been deleted")
+	}
+	if _, err := secrets.Get(context.Background(), "unrelated", metav1.GetOptions{}); err != nil {
+		test.Errorf("unrelated should have been kept: %v", err)
+	}
+
+	if err := deleteServiceSecrets(context.Background(), clientset, service, nil); err != nil {
+		test.Fatalf("deleteServiceSecrets() error = %v", err)
+	}
+	list, _ := secrets.List(context.Background(), metav1.ListOptions{})
+	if len(list.Items) != 1 || list.Items[0].Name != "unrelated" {
+		test.Errorf("after delete: %v, want only unrelated", list.Items)
+	}
+}
+
+func TestEnsureServiceSecrets_RefusesUnmanaged(test *testing.T) {
+	test.Setenv("KRAZE_TEST_PASSWORD", "from-kraze")
+	otherService := map[string]string{managedByLabel: "kraze", serviceLabel: "web", secretLabel: "true"}
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+	}{
+		{name: "created outside kraze"},
+		{name: "created for another service", labels: otherService},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			clientset := fake.NewSimpleClientset(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "shop", Labels: tt.labels},
+				Data:       map[string][]byte{"password": []byte("theirs")},
+				Type:       corev1.SecretTypeOpaque,
+			})
+			createNamespace := false
+			service := &config.ServiceConfig{
+				Name:            "api",
+				Namespace:       "shop",
+				CreateNamespace: &createNamespace,
+				Secrets:         []config.SecretConfig{{Name: "db", Data: map[string]config.SecretSource{"password": {Env: "KRAZE_TEST_PASSWORD"}}}},
+			}
+
+			err := ensureServiceSecrets(context.Background(), clientset, service)
+			if err == nil || !strings.Contains(err.Error(), "wasn't created by kraze") {
+				test.Fatalf("ensureServiceSecrets() error = %v, want the Secret refused", err)
+			}
+			db, _ := clientset.CoreV1().Secrets("shop").Get(context.Background(), "db", metav1.GetOptions{})
+			if string(db.Data["password"]) != "theirs" {
+				test.Errorf("password = %q, want the Secret left alone", db.Data["password"])
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/hjames9/kraze/internal/config"
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v4/pkg/action"
+	ri "helm.sh/helm/v4/pkg/release"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+const (
+	// volumeHelperImage runs tar in the helper pods that copy PVC data
+	volumeHelperImage = "busybox:1.37"
+
+	// volumeHelperLabel marks helper pods created by 'kraze snapshot'
+	volumeHelperLabel = "kraze.snapshot"
+
+	// volumeHelperMountPath is where helper pods mount the claim
+	volumeHelperMountPath = "/data"
+
+	// volumeHelperStartTimeout bounds how long to wait for a helper pod to start
+	volumeHelperStartTimeout = 3 * time.Minute
+)
+
+// ServiceSnapshot is what a service has applied to the cluster
+type ServiceSnapshot struct {
+	Values   []byte // Helm release values (Helm services only)
+	Manifest []byte // Applied resources as multi-document YAML
+}
+
+// SnapshotService captures what a service applied: the values and manifest of
+// its Helm release, or its rendered manifests
+func SnapshotService(ctx context.Context, service *config.ServiceConfig, opts *ProviderOptions) (*ServiceSnapshot, error) {
+	provider, err := NewProvider(service, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if helm, ok := provider.(*HelmProvider); ok {
+		return helm.releaseSnapshot(service)
+	}
+
+	resources, err := provider.Render(ctx, service)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render manifests: %w", err)
+	}
+	manifest, err := MarshalResources(resources)
+	if err != nil {
+		return nil, err
+	}
+	return &ServiceSnapshot{Manifest: manifest}, nil
+}
+
+// releaseSnapshot reads the user-supplied values and the manifest of a service's release
+func (helm *HelmProvider) releaseSnapshot(service *config.ServiceConfig) (*ServiceSnapshot, error) {
+	actionConfig, err := helm.getActionConfig(service.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := action.NewGetValues(actionConfig).Run(service.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get release values: %w", err)
+	}
+	valuesYAML, err := yaml.Marshal(values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize release values: %w", err)
+	}
+
+	rel, err := action.NewGet(actionConfig).Run(service.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get release: %w", err)
+	}
+	acc, err := ri.NewAccessor(rel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read release: %w", err)
+	}
+
+	return &ServiceSnapshot{Values: valuesYAML, Manifest: []byte(acc.Manifest())}, nil
+}
+
+// ListBoundClaims returns the bound PersistentVolumeClaims of a namespace
+func ListBoundClaims(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]corev1.PersistentVolumeClaim, error) {
+	list, err := clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PersistentVolumeClaims in '%s': %w", namespace, err)
+	}
+
+	var claims []corev1.PersistentVolumeClaim
+	for _, pvc := range list.Items {
+		if pvc.Status.Phase == corev1.ClaimBound && pvc.DeletionTimestamp == nil {
+			claims = append(claims, pvc)
+		}
+	}
+	return claims, nil
+}
+
+// BackupVolume streams the contents of a claim as a gzipped tar into out, via a
+// helper pod that mounts the claim read-only. Data is copied while the
+// workloads keep running.
+func BackupVolume(ctx context.Context, restConfig *rest.Config, clientset kubernetes.Interface, pvc *corev1.PersistentVolumeClaim, out io.Writer) error {
+	// A ReadWriteOnce volume can only be mounted on the node that already uses it
+	nodeName, err := claimNode(ctx, clientset, pvc)
+	if err != nil {
+		return err
+	}
+
+	pod, err := startVolumeHelper(ctx, clientset, pvc.Namespace, pvc.Name, nodeName, true)
+	if err != nil {
+		return err
+	}
+	defer deleteHelperPod(clientset, pod)
+
+	command := []string{"tar", "czf", "-", "-C", volumeHelperMountPath, "."}
+	return execInPod(ctx, restConfig, clientset, pod, command, nil, out)
+}
+
+// RestoreVolume creates a claim (and its namespace, if missing) and extracts a
+// gzipped tar into it via a helper pod, before any workload mounts it
+func RestoreVolume(ctx context.Context, restConfig *rest.Config, clientset kubernetes.Interface, pvc *corev1.PersistentVolumeClaim, data io.Reader) error {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: pvc.Namespace}}
+	if _, err := clientset.CoreV1().Namespaces().Create(ctx, namespace, metav1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create namespace '%s': %w", pvc.Namespace, err)
+	}
+	if _, err := clientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Create(ctx, pvc, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create PersistentVolumeClaim %s/%s: %w", pvc.Namespace, pvc.Name, err)
+	}
+
+	pod, err := startVolumeHelper(ctx, clientset, pvc.Namespace, pvc.Name, "", false)
+	if err != nil {
+		return err
+	}
+	defer deleteHelperPod(clientset, pod)
+
+	command := []string{"tar", "xzf", "-", "-C", volumeHelperMountPath}
+	return execInPod(ctx, restConfig, clientset, pod, command, data, io.Discard)
+}
+
+// claimNode returns the node of a running pod that mounts the claim, or "" if none does
+func claimNode(ctx context.Context, clientset kubernetes.Interface, pvc *corev1.PersistentVolumeClaim) (string, error) {
+	pods, err := clientset.CoreV1().Pods(pvc.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to list pods in '%s': %w", pvc.Namespace, err)
+	}
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName == "" || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == pvc.Name {
+				return pod.Spec.NodeName, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// volumeHelperPod returns a pod that mounts a claim and sleeps until deleted
+func volumeHelperPod(namespace, claimName, nodeName string, readOnly bool) *corev1.Pod {
+	// Keep the claim's name readable at the start of the pod name
+	prefix := claimName
+	if len(prefix) > 40 {
+		prefix = strings.TrimRight(prefix[:40], "-.")
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("kraze-snapshot-%s-%s", prefix, utilrand.String(5)),
+			Namespace: namespace,
+			Labels:    map[string]string{volumeHelperLabel: "true", managedByLabel: "kraze"},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:      nodeName,
+			RestartPolicy: corev1.RestartPolicyNever,
+			// Run wherever the volume lives, including tainted control-plane nodes
+			Tolerations: []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			Containers: []corev1.Container{{
+				Name:         "helper",
+				Image:        volumeHelperImage,
+				Command:      []string{"sleep", "3600"},
+				VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: volumeHelperMountPath, ReadOnly: readOnly}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: "data",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claimName, ReadOnly: readOnly},
+				},
+			}},
+		},
+	}
+}
+
+// startVolumeHelper creates a helper pod for a claim and waits until it runs
+func startVolumeHelper(ctx context.Context, clientset kubernetes.Interface, namespace, claimName, nodeName string, readOnly bool) (*corev1.Pod, error) {
+	pod, err := clientset.CoreV1().Pods(namespace).Create(ctx, volumeHelperPod(namespace, claimName, nodeName, readOnly), metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create helper pod for volume %s/%s: %w", namespace, claimName, err)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, volumeHelperStartTimeout)
+	defer cancel()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		current, err := clientset.CoreV1().Pods(namespace).Get(waitCtx, pod.Name, metav1.GetOptions{})
+		if err == nil {
+			switch current.Status.Phase {
+			case corev1.PodRunning:
+				return current, nil
+			case corev1.PodFailed, corev1.PodSucceeded:
+				deleteHelperPod(clientset, pod)
+				return nil, fmt.Errorf("helper pod for volume %s/%s exited before it could be used", namespace, claimName)
+			}
+		}
+
+		select {
+		case <-waitCtx.Done():
+			deleteHelperPod(clientset, pod)
+			return nil, fmt.Errorf("timed out waiting for helper pod for volume %s/%s to start (image %s)", namespace, claimName, volumeHelperImage)
+		case <-ticker.C:
+		}
+	}
+}
+
+// deleteHelperPod removes a helper or probe pod without waiting for it to terminate
+func deleteHelperPod(clientset kubernetes.Interface, pod *corev1.Pod) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	gracePeriod := int64(0)
+	_ = clientset.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{GracePeriodSeconds: &gracePeriod})
+}
+
+// execInPod runs a command in the first container of a pod with the given streams
+func execInPod(ctx context.Context, restConfig *rest.Config, clientset kubernetes.Interface, pod *corev1.Pod, command []string, stdin io.Reader, stdout io.Writer) error {
+	req := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: pod.Spec.Containers[0].Name,
+			Command:   command,
+			Stdin:     stdin != nil,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(restConfig, "POST", req.URL())
+	if err != nil {
+		return fmt.Errorf("failed to create exec executor: %w", err)
+	}
+
+	var stderr bytes.Buffer
+	if err := executor.StreamWithContext(ctx, remotecommand.StreamOptions{Stdin: stdin, Stdout: stdout, Stderr: &stderr}); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return fmt.Errorf("%s failed in %s: %w: %s", command[0], pod.Name, err, message)
+		}
+		return fmt.Errorf("%s failed in %s: %w", command[0], pod.Name, err)
+	}
+	return nil
+}
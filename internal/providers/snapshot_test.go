@@ -0,0 +1,105 @@
+package providers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestListBoundClaims(test *testing.T) {
+	now := metav1.Now()
+	clientset := fake.NewSimpleClientset(
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "app"}, Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "app"}, Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "deleting", Namespace: "app", DeletionTimestamp: &now, Finalizers: []string{"kubernetes.io/pvc-protection"}}, Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "other"}, Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound}},
+	)
+
+	claims, err := ListBoundClaims(context.Background(), clientset, "app")
+	if err != nil {
+		test.Fatalf("ListBoundClaims() error = %v", err)
+	}
+	if len(claims) != 1 || claims[0].Name != "data" {
+		test.Errorf("ListBoundClaims() = %v, want only 'data'", claims)
+	}
+}
+
+func TestClaimNode(test *testing.T) {
+	claimVolume := corev1.Volume{Name: "data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"}}}
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "app"}}
+
+	tests := []struct {
+		name     string
+		pods     []corev1.Pod
+		expected string
+	}{
+		{
+			name: "running pod using the claim",
+			pods: []corev1.Pod{
+				{ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "app"}, Spec: corev1.PodSpec{NodeName: "worker", Volumes: []corev1.Volume{claimVolume}}, Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+			},
+			expected: "worker",
+		},
+		{
+			name: "pending pod is ignored",
+			pods: []corev1.Pod{
+				{ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "app"}, Spec: corev1.PodSpec{Volumes: []corev1.Volume{claimVolume}}, Status: corev1.PodStatus{Phase: corev1.PodPending}},
+			},
+		},
+		{
+			name: "pod using another claim",
+			pods: []corev1.Pod{
+				{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"}, Spec: corev1.PodSpec{NodeName: "worker2", Volumes: []corev1.Volume{{Name: "cache", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "cache"}}}}}, Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			for idx := range tt.pods {
+				if _, err := clientset.CoreV1().Pods("app").Create(context.Background(), &tt.pods[idx], metav1.CreateOptions{}); err != nil {
+					test.Fatal(err)
+				}
+			}
+
+			nodeName, err := claimNode(context.Background(), clientset, pvc)
+			if err != nil {
+				test.Fatalf("claimNode() error = %v", err)
+			}
+			if nodeName != tt.expected {
+				test.Errorf("claimNode() = %q, want %q", nodeName, tt.expected)
+			}
+		})
+	}
+}
+
+func TestVolumeHelperPod(test *testing.T) {
+	longName := strings.Repeat("a", 60)
+	pod := volumeHelperPod("app", longName, "worker", true)
+
+	if !strings.HasPrefix(pod.Name, "kraze-snapshot-"+strings.Repeat("a", 40)+"-") {
+		test.Errorf("pod name = %q, want claim prefix truncated to 40 characters", pod.Name)
+	}
+	if len(pod.Name) > 63 {
+		test.Errorf("pod name %q is longer than 63 characters", pod.Name)
+	}
+	if pod.Spec.NodeName != "worker" {
+		test.Errorf("node name = %q, want 'worker'", pod.Spec.NodeName)
+	}
+	if !pod.Spec.Containers[0].VolumeMounts[0].ReadOnly || !pod.Spec.Volumes[0].PersistentVolumeClaim.ReadOnly {
+		test.Error("backup helper pod should mount the claim read-only")
+	}
+	if pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName != longName {
+		test.Errorf("claim name = %q, want %q", pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName, longName)
+	}
+
+	restore := volumeHelperPod("app", "data", "", false)
+	if restore.Spec.Containers[0].VolumeMounts[0].ReadOnly {
+		test.Error("restore helper pod should mount the claim read-write")
+	}
+}
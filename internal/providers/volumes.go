@@ -0,0 +1,360 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hjames9/kraze/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// localPathProvisioner provisions the volumes of kind's default StorageClass,
+	// each in its own directory on a node
+	localPathProvisioner = "rancher.io/local-path"
+
+	// provisionedByAnnotation names the provisioner that created a PersistentVolume
+	provisionedByAnnotation = "pv.kubernetes.io/provisioned-by"
+
+	// helmInstanceLabel is set by most charts on the objects of a release
+	helmInstanceLabel = "app.kubernetes.io/instance"
+)
+
+// Volume is a PersistentVolume and the claim bound to it, or a claim still waiting for one
+type Volume struct {
+	Name          string `json:"name,omitempty"` // PersistentVolume, empty for a pending claim
+	Namespace     string `json:"namespace,omitempty"`
+	Claim         string `json:"claim,omitempty"`
+	Service       string `json:"service,omitempty"` // From the claim's kraze or Helm release label
+	Status        string `json:"status"`
+	Capacity      string `json:"capacity,omitempty"`
+	StorageClass  string `json:"storage_class,omitempty"`
+	ReclaimPolicy string `json:"reclaim_policy,omitempty"`
+	Node          string `json:"node,omitempty"` // Node holding the data of a local volume
+	Path          string `json:"path,omitempty"` // Where the data is on that node
+	LocalPath     bool   `json:"local_path"`     // Provisioned by local-path, so the directory belongs to the volume alone
+}
+
+// ListVolumes returns every PersistentVolume with its claim, and the claims
+// that aren't bound yet
+func ListVolumes(ctx context.Context, clientset kubernetes.Interface) ([]Volume, error) {
+	pvs, err := clientset.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PersistentVolumes: %w", err)
+	}
+	pvcs, err := clientset.CoreV1().PersistentVolumeClaims("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PersistentVolumeClaims: %w", err)
+	}
+
+	claims := make(map[string]*corev1.PersistentVolumeClaim, len(pvcs.Items))
+	for itr := range pvcs.Items {
+		pvc := &pvcs.Items[itr]
+		claims[pvc.Namespace+"/"+pvc.Name] = pvc
+	}
+
+	volumes := make([]Volume, 0, len(pvs.Items))
+	for itr := range pvs.Items {
+		pv := &pvs.Items[itr]
+		volume := volumeInfo(pv)
+		if ref := pv.Spec.ClaimRef; ref != nil {
+			volume.Namespace = ref.Namespace
+			volume.Claim = ref.Name
+			if pvc, ok := claims[ref.Namespace+"/"+ref.Name]; ok && pvc.UID == ref.UID {
+				volume.Service = claimService(pvc)
+				delete(claims, ref.Namespace+"/"+ref.Name)
+			}
+		}
+		volumes = append(volumes, volume)
+	}
+
+	// Claims left over aren't bound to a volume yet
+	for _, pvc := range claims {
+		volume := Volume{
+			Namespace: pvc.Namespace,
+			Claim:     pvc.Name,
+			Service:   claimService(pvc),
+			Status:    string(pvc.Status.Phase),
+		}
+		if request, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+			volume.Capacity = request.String()
+		}
+		if pvc.Spec.StorageClassName != nil {
+			volume.StorageClass = *pvc.Spec.StorageClassName
+		}
+		volumes = append(volumes, volume)
+	}
+
+	sort.Slice(volumes, func(i, j int) bool {
+		if volumes[i].Namespace != volumes[j].Namespace {
+			return volumes[i].Namespace < volumes[j].Namespace
+		}
+		if volumes[i].Claim != volumes[j].Claim {
+			return volumes[i].Claim < volumes[j].Claim
+		}
+		return volumes[i].Name < volumes[j].Name
+	})
+	return volumes, nil
+}
+
+// volumeInfo describes a PersistentVolume, without its claim
+func volumeInfo(pv *corev1.PersistentVolume) Volume {
+	volume := Volume{
+		Name:          pv.Name,
+		Status:        string(pv.Status.Phase),
+		StorageClass:  pv.Spec.StorageClassName,
+		ReclaimPolicy: string(pv.Spec.PersistentVolumeReclaimPolicy),
+		LocalPath:     pv.Annotations[provisionedByAnnotation] == localPathProvisioner,
+	}
+	if capacity, ok := pv.Spec.Capacity[corev1.ResourceStorage]; ok {
+		volume.Capacity = capacity.String()
+	}
+	volume.Node, volume.Path = volumeLocation(pv)
+	return volume
+}
+
+// volumeLocation returns the node and path of the data of a hostPath or local
+// volume, or empty strings for network volumes
+func volumeLocation(pv *corev1.PersistentVolume) (string, string) {
+	var path string
+	switch {
+	case pv.Spec.HostPath != nil:
+		path = pv.Spec.HostPath.Path
+	case pv.Spec.Local != nil:
+		path = pv.Spec.Local.Path
+	default:
+		return "", ""
+	}
+
+	if pv.Spec.NodeAffinity == nil || pv.Spec.NodeAffinity.Required == nil {
+		return "", path
+	}
+	for _, term := range pv.Spec.NodeAffinity.Required.NodeSelectorTerms {
+		for _, expr := range term.MatchExpressions {
+			if expr.Key == corev1.LabelHostname && expr.Operator == corev1.NodeSelectorOpIn && len(expr.Values) == 1 {
+				return expr.Values[0], path
+			}
+		}
+	}
+	return "", path
+}
+
+// claimService returns the service a claim was labeled with by kraze or its Helm release
+func claimService(pvc *corev1.PersistentVolumeClaim) string {
+	if name := pvc.Labels[serviceLabel]; name != "" {
+		return name
+	}
+	return pvc.Labels[helmInstanceLabel]
+}
+
+// ServiceClaims returns the claims of a service: those labeled with it, those
+// its pods mount, and those its StatefulSets' volumeClaimTemplates created.
+// Call it before uninstalling, while the pods and StatefulSets still exist.
+func ServiceClaims(ctx context.Context, clientset kubernetes.Interface, service *config.ServiceConfig) ([]corev1.PersistentVolumeClaim, error) {
+	namespace := service.GetNamespace()
+	listOpts := metav1.ListOptions{LabelSelector: serviceWorkloadSelector(service)}
+
+	pvcs, err := clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PersistentVolumeClaims in '%s': %w", namespace, err)
+	}
+	if len(pvcs.Items) == 0 {
+		return nil, nil
+	}
+
+	mounted := make(map[string]bool)
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, listOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods in '%s': %w", namespace, err)
+	}
+	for _, pod := range pods.Items {
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim != nil {
+				mounted[volume.PersistentVolumeClaim.ClaimName] = true
+			}
+		}
+	}
+
+	// StatefulSets name their claims <template>-<statefulset>-<ordinal>, and
+	// leave them behind when scaled down or deleted
+	var templatePrefixes []string
+	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(ctx, listOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list StatefulSets in '%s': %w", namespace, err)
+	}
+	for _, sts := range statefulSets.Items {
+		for _, template := range sts.Spec.VolumeClaimTemplates {
+			templatePrefixes = append(templatePrefixes, fmt.Sprintf("%s-%s-", template.Name, sts.Name))
+		}
+	}
+
+	var claims []corev1.PersistentVolumeClaim
+	for _, pvc := range pvcs.Items {
+		if claimService(&pvc) == service.Name || mounted[pvc.Name] || hasAnyPrefix(pvc.Name, templatePrefixes) {
+			claims = append(claims, pvc)
+		}
+	}
+	return claims, nil
+}
+
+// hasAnyPrefix reports whether value starts with one of the prefixes
+func hasAnyPrefix(value string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// OrphanedClaims returns the claims of a namespace that no pod mounts
+func OrphanedClaims(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]corev1.PersistentVolumeClaim, error) {
+	pvcs, err := clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PersistentVolumeClaims in '%s': %w", namespace, err)
+	}
+	if len(pvcs.Items) == 0 {
+		return nil, nil
+	}
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods in '%s': %w", namespace, err)
+	}
+
+	mounted := make(map[string]bool)
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim != nil {
+				mounted[volume.PersistentVolumeClaim.ClaimName] = true
+			}
+		}
+	}
+
+	var claims []corev1.PersistentVolumeClaim
+	for _, pvc := range pvcs.Items {
+		if !mounted[pvc.Name] && pvc.DeletionTimestamp == nil {
+			claims = append(claims, pvc)
+		}
+	}
+	return claims, nil
+}
+
+// ReleasedVolumes returns the PersistentVolumes whose claim was deleted but
+// which were kept by their Retain policy. With namespaces set, only volumes
+// that were claimed from those namespaces are returned.
+func ReleasedVolumes(ctx context.Context, clientset kubernetes.Interface, namespaces map[string]bool) ([]corev1.PersistentVolume, error) {
+	pvs, err := clientset.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PersistentVolumes: %w", err)
+	}
+	var released []corev1.PersistentVolume
+	for _, pv := range pvs.Items {
+		if pv.Status.Phase != corev1.VolumeReleased {
+			continue
+		}
+		if namespaces != nil && (pv.Spec.ClaimRef == nil || !namespaces[pv.Spec.ClaimRef.Namespace]) {
+			continue
+		}
+		released = append(released, pv)
+	}
+	return released, nil
+}
+
+// ClaimVolumes returns the PersistentVolumes the claims are bound to
+func ClaimVolumes(ctx context.Context, clientset kubernetes.Interface, claims []corev1.PersistentVolumeClaim) ([]corev1.PersistentVolume, error) {
+	var volumes []corev1.PersistentVolume
+	for _, pvc := range claims {
+		if pvc.Spec.VolumeName == "" {
+			continue
+		}
+		pv, err := clientset.CoreV1().PersistentVolumes().Get(ctx, pvc.Spec.VolumeName, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return volumes, fmt.Errorf("failed to get PersistentVolume %s: %w", pvc.Spec.VolumeName, err)
+		}
+		volumes = append(volumes, *pv)
+	}
+	return volumes, nil
+}
+
+// DeleteClaims deletes claims and waits up to timeout for their volumes to be
+// deleted by their provisioner or released. Returns the volumes that were
+// released and still exist, which are for the caller to delete.
+func DeleteClaims(ctx context.Context, clientset kubernetes.Interface, claims []corev1.PersistentVolumeClaim, timeout time.Duration) ([]corev1.PersistentVolume, error) {
+	var volumeNames []string
+	for _, pvc := range claims {
+		err := clientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Delete(ctx, pvc.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to delete PersistentVolumeClaim %s/%s: %w", pvc.Namespace, pvc.Name, err)
+		}
+		if pvc.Spec.VolumeName != "" {
+			volumeNames = append(volumeNames, pvc.Spec.VolumeName)
+		}
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		var released []corev1.PersistentVolume
+		pending := 0
+		for _, name := range volumeNames {
+			pv, err := clientset.CoreV1().PersistentVolumes().Get(ctx, name, metav1.GetOptions{})
+			if errors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to get PersistentVolume %s: %w", name, err)
+			}
+			switch pv.Status.Phase {
+			case corev1.VolumeReleased, corev1.VolumeFailed:
+				// A Delete policy volume stays Released until its provisioner gets to it
+				if pv.Spec.PersistentVolumeReclaimPolicy == corev1.PersistentVolumeReclaimRetain || time.Now().After(deadline) {
+					released = append(released, *pv)
+				} else {
+					pending++
+				}
+			default:
+				pending++
+			}
+		}
+		if pending == 0 || time.Now().After(deadline) {
+			return released, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// DeleteVolume deletes a PersistentVolume. The data of a hostPath or local
+// volume stays on its node.
+func DeleteVolume(ctx context.Context, clientset kubernetes.Interface, pv *corev1.PersistentVolume) error {
+	err := clientset.CoreV1().PersistentVolumes().Delete(ctx, pv.Name, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete PersistentVolume %s: %w", pv.Name, err)
+	}
+	return nil
+}
+
+// DescribeVolume returns the location and ownership of a PersistentVolume
+func DescribeVolume(pv *corev1.PersistentVolume) Volume {
+	volume := volumeInfo(pv)
+	if ref := pv.Spec.ClaimRef; ref != nil {
+		volume.Namespace = ref.Namespace
+		volume.Claim = ref.Name
+	}
+	return volume
+}
@@ -0,0 +1,244 @@
+package providers
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/hjames9/kraze/internal/config"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func volumeTestClaim(namespace, name, volumeName string, labels map[string]string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels, UID: types.UID(namespace + "/" + name)},
+		Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: volumeName},
+		Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+	}
+}
+
+func volumeTestPod(namespace, name string, labels map[string]string, claims ...string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	for _, claim := range claims {
+		pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+			Name:         claim,
+			VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim}},
+		})
+	}
+	return pod
+}
+
+func volumeTestPV(name string, phase corev1.PersistentVolumePhase, policy corev1.PersistentVolumeReclaimPolicy, claim *corev1.PersistentVolumeClaim) *corev1.PersistentVolume {
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: map[string]string{provisionedByAnnotation: localPathProvisioner}},
+		Spec: corev1.PersistentVolumeSpec{
+			Capacity:                      corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+			PersistentVolumeReclaimPolicy: policy,
+			StorageClassName:              "standard",
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{Path: "/var/local-path-provisioner/" + name},
+			},
+			NodeAffinity: &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{Key: corev1.LabelHostname, Operator: corev1.NodeSelectorOpIn, Values: []string{"dev-worker"}}},
+			}}}},
+		},
+		Status: corev1.PersistentVolumeStatus{Phase: phase},
+	}
+	if claim != nil {
+		pv.Spec.ClaimRef = &corev1.ObjectReference{Namespace: claim.Namespace, Name: claim.Name, UID: claim.UID}
+	}
+	return pv
+}
+
+func claimNames(claims []corev1.PersistentVolumeClaim) []string {
+	var names []string
+	for _, pvc := range claims {
+		names = append(names, pvc.Namespace+"/"+pvc.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestServiceClaims(test *testing.T) {
+	owned := map[string]string{managedByLabel: "kraze", serviceLabel: "api"}
+	clientset := fake.NewSimpleClientset(
+		volumeTestClaim("app", "api-uploads", "", owned),
+		volumeTestClaim("app", "api-cache", "", nil),
+		volumeTestClaim("app", "data-postgres-0", "", nil),
+		volumeTestClaim("app", "data-postgres-1", "", nil),
+		volumeTestClaim("app", "redis-data", "", map[string]string{helmInstanceLabel: "redis"}),
+		volumeTestClaim("app", "unrelated", "", nil),
+		volumeTestPod("app", "api-abc", owned, "api-cache"),
+		volumeTestPod("app", "other", nil, "unrelated"),
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "app", Labels: map[string]string{helmInstanceLabel: "db"}},
+			Spec: appsv1.StatefulSetSpec{VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{ObjectMeta: metav1.ObjectMeta{Name: "data"}},
+			}},
+		},
+	)
+
+	tests := []struct {
+		name     string
+		service  config.ServiceConfig
+		expected []string
+	}{
+		{
+			name:     "manifests service via label and mounted claims",
+			service:  config.ServiceConfig{Name: "api", Type: "manifests", Namespace: "app"},
+			expected: []string{"app/api-cache", "app/api-uploads"},
+		},
+		{
+			name:     "helm service via instance label",
+			service:  config.ServiceConfig{Name: "redis", Type: "helm", Namespace: "app"},
+			expected: []string{"app/redis-data"},
+		},
+		{
+			name:     "statefulset claims, including scaled down ordinals",
+			service:  config.ServiceConfig{Name: "db", Type: "helm", Namespace: "app"},
+			expected: []string{"app/data-postgres-0", "app/data-postgres-1"},
+		},
+		{
+			name:    "service without claims",
+			service: config.ServiceConfig{Name: "web", Type: "manifests", Namespace: "app"},
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			claims, err := ServiceClaims(context.Background(), clientset, &tt.service)
+			if err != nil {
+				test.Fatalf("ServiceClaims() error = %v", err)
+			}
+			if got := claimNames(claims); !reflect.DeepEqual(got, tt.expected) {
+				test.Errorf("claims = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOrphanedClaims(test *testing.T) {
+	finished := volumeTestPod("app", "migrate", nil, "scratch")
+	finished.Status.Phase = corev1.PodSucceeded
+	clientset := fake.NewSimpleClientset(
+		volumeTestClaim("app", "in-use", "", nil),
+		volumeTestClaim("app", "scratch", "", nil),
+		volumeTestClaim("app", "leftover", "", nil),
+		volumeTestClaim("other", "elsewhere", "", nil),
+		volumeTestPod("app", "web", nil, "in-use"),
+		finished,
+	)
+
+	claims, err := OrphanedClaims(context.Background(), clientset, "app")
+	if err != nil {
+		test.Fatalf("OrphanedClaims() error = %v", err)
+	}
+	expected := []string{"app/leftover", "app/scratch"}
+	if got := claimNames(claims); !reflect.DeepEqual(got, expected) {
+		test.Errorf("claims = %v, want %v", got, expected)
+	}
+}
+
+func TestReleasedVolumes(test *testing.T) {
+	appClaim := volumeTestClaim("app", "data", "", nil)
+	otherClaim := volumeTestClaim("other", "data", "", nil)
+	clientset := fake.NewSimpleClientset(
+		volumeTestPV("pvc-bound", corev1.VolumeBound, corev1.PersistentVolumeReclaimDelete, appClaim),
+		volumeTestPV("pvc-app", corev1.VolumeReleased, corev1.PersistentVolumeReclaimRetain, appClaim),
+		volumeTestPV("pvc-other", corev1.VolumeReleased, corev1.PersistentVolumeReclaimRetain, otherClaim),
+	)
+
+	tests := []struct {
+		name       string
+		namespaces map[string]bool
+		expected   []string
+	}{
+		{name: "whole cluster", expected: []string{"pvc-app", "pvc-other"}},
+		{name: "claimed from namespace", namespaces: map[string]bool{"app": true}, expected: []string{"pvc-app"}},
+		{name: "no match", namespaces: map[string]bool{"web": true}},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			released, err := ReleasedVolumes(context.Background(), clientset, tt.namespaces)
+			if err != nil {
+				test.Fatalf("ReleasedVolumes() error = %v", err)
+			}
+			var names []string
+			for _, pv := range released {
+				names = append(names, pv.Name)
+			}
+			sort.Strings(names)
+			if !reflect.DeepEqual(names, tt.expected) {
+				test.Errorf("released = %v, want %v", names, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDeleteClaims(test *testing.T) {
+	retained := volumeTestClaim("app", "retained", "pvc-retained", nil)
+	deleted := volumeTestClaim("app", "deleted", "pvc-deleted", nil)
+	clientset := fake.NewSimpleClientset(
+		retained, deleted,
+		volumeTestPV("pvc-retained", corev1.VolumeReleased, corev1.PersistentVolumeReclaimRetain, retained),
+	)
+
+	released, err := DeleteClaims(context.Background(), clientset, []corev1.PersistentVolumeClaim{*retained, *deleted}, 0)
+	if err != nil {
+		test.Fatalf("DeleteClaims() error = %v", err)
+	}
+	if len(released) != 1 || released[0].Name != "pvc-retained" {
+		test.Errorf("released = %v, want [pvc-retained]", released)
+	}
+	left, err := clientset.CoreV1().PersistentVolumeClaims("app").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		test.Fatal(err)
+	}
+	if len(left.Items) != 0 {
+		test.Errorf("%d claim(s) left, want 0", len(left.Items))
+	}
+}
+
+func TestListVolumes(test *testing.T) {
+	bound := volumeTestClaim("app", "data", "pvc-data", map[string]string{serviceLabel: "api"})
+	storageClass := "standard"
+	pending := volumeTestClaim("app", "pending", "", nil)
+	pending.Status.Phase = corev1.ClaimPending
+	pending.Spec.StorageClassName = &storageClass
+	pending.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("5Gi")}
+	gone := volumeTestClaim("app", "gone", "", nil)
+	clientset := fake.NewSimpleClientset(
+		bound, pending,
+		volumeTestPV("pvc-data", corev1.VolumeBound, corev1.PersistentVolumeReclaimDelete, bound),
+		volumeTestPV("pvc-gone", corev1.VolumeReleased, corev1.PersistentVolumeReclaimRetain, gone),
+	)
+
+	volumes, err := ListVolumes(context.Background(), clientset)
+	if err != nil {
+		test.Fatalf("ListVolumes() error = %v", err)
+	}
+	expected := []Volume{
+		{
+			Name: "pvc-data", Namespace: "app", Claim: "data", Service: "api", Status: "Bound", Capacity: "1Gi",
+			StorageClass: "standard", ReclaimPolicy: "Delete", Node: "dev-worker", Path: "/var/local-path-provisioner/pvc-data", LocalPath: true,
+		},
+		{
+			Name: "pvc-gone", Namespace: "app", Claim: "gone", Status: "Released", Capacity: "1Gi",
+			StorageClass: "standard", ReclaimPolicy: "Retain", Node: "dev-worker", Path: "/var/local-path-provisioner/pvc-gone", LocalPath: true,
+		},
+		{Namespace: "app", Claim: "pending", Status: "Pending", Capacity: "5Gi", StorageClass: "standard"},
+	}
+	if !reflect.DeepEqual(volumes, expected) {
+		test.Errorf("ListVolumes() =\n%+v\nwant\n%+v", volumes, expected)
+	}
+}
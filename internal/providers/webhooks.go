@@ -0,0 +1,62 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Kinds that register admission webhooks. The API server calls them for the
+// objects they match as soon as they exist, so objects applied while a
+// webhook's service has no ready endpoint fail (or skip validation, with
+// failurePolicy Ignore). Like a CRD with a conversion webhook, they're ready
+// once each in-cluster webhook service has a ready endpoint.
+const (
+	kindValidatingWebhook = "ValidatingWebhookConfiguration"
+	kindMutatingWebhook   = "MutatingWebhookConfiguration"
+)
+
+// isAdmissionWebhook reports whether a kind registers admission webhooks
+func isAdmissionWebhook(kind string) bool {
+	return kind == kindValidatingWebhook || kind == kindMutatingWebhook
+}
+
+// admissionWebhookServices returns the in-cluster services of a webhook
+// configuration's webhooks as namespace/name, once each. Webhooks called by URL are skipped.
+func admissionWebhookServices(obj *unstructured.Unstructured) [][2]string {
+	webhooks, _, _ := unstructured.NestedSlice(obj.Object, "webhooks")
+	seen := make(map[[2]string]bool)
+	var services [][2]string
+	for _, itr := range webhooks {
+		webhook, ok := itr.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		service, found, _ := unstructured.NestedMap(webhook, "clientConfig", "service")
+		if !found {
+			continue
+		}
+		namespace, _ := service["namespace"].(string)
+		name, _ := service["name"].(string)
+		key := [2]string{namespace, name}
+		if name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		services = append(services, key)
+	}
+	return services
+}
+
+// admissionWebhooksReady reports whether every in-cluster service of a webhook
+// configuration has a ready endpoint, and the first one that doesn't
+func admissionWebhooksReady(ctx context.Context, clientset kubernetes.Interface, obj *unstructured.Unstructured) (bool, string) {
+	for _, service := range admissionWebhookServices(obj) {
+		if !serviceHasReadyEndpoint(ctx, clientset, service[0], service[1]) {
+			return false, fmt.Sprintf("%s/%s", service[0], service[1])
+		}
+	}
+	return true, ""
+}
@@ -0,0 +1,75 @@
+package providers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const webhooksTestConfiguration = `
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: ingress-nginx-admission
+webhooks:
+  - name: validate.nginx.ingress.kubernetes.io
+    clientConfig:
+      service:
+        namespace: ingress-nginx
+        name: ingress-nginx-controller-admission
+        path: /networking/v1/ingresses
+  - name: validate-v1beta1.nginx.ingress.kubernetes.io
+    clientConfig:
+      service:
+        namespace: ingress-nginx
+        name: ingress-nginx-controller-admission
+  - name: external.example.com
+    clientConfig:
+      url: https://webhook.example.com/validate
+`
+
+func TestAdmissionWebhookServices(test *testing.T) {
+	obj := lintTestObjects(test, webhooksTestConfiguration)[0]
+	want := [][2]string{{"ingress-nginx", "ingress-nginx-controller-admission"}}
+	if got := admissionWebhookServices(obj); !reflect.DeepEqual(got, want) {
+		test.Errorf("admissionWebhookServices() = %v, want %v", got, want)
+	}
+}
+
+func TestAdmissionWebhooksReady(test *testing.T) {
+	obj := lintTestObjects(test, webhooksTestConfiguration)[0]
+	ready := true
+	notReady := false
+	slice := func(readiness *bool) *discoveryv1.EndpointSlice {
+		return &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: "admission-1", Namespace: "ingress-nginx", Labels: map[string]string{discoveryv1.LabelServiceName: "ingress-nginx-controller-admission"}},
+			Endpoints:  []discoveryv1.Endpoint{{Addresses: []string{"10.244.0.7"}, Conditions: discoveryv1.EndpointConditions{Ready: readiness}}},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		clientset *fake.Clientset
+		want      bool
+	}{
+		{name: "no endpoints", clientset: fake.NewSimpleClientset(), want: false},
+		{name: "endpoint not ready", clientset: fake.NewSimpleClientset(slice(&notReady)), want: false},
+		{name: "ready endpoint", clientset: fake.NewSimpleClientset(slice(&ready)), want: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			got, service := admissionWebhooksReady(context.Background(), tt.clientset, obj)
+			if got != tt.want {
+				test.Errorf("admissionWebhooksReady() = %v, want %v", got, tt.want)
+			}
+			if !got && service != "ingress-nginx/ingress-nginx-controller-admission" {
+				test.Errorf("admissionWebhooksReady() service = %q", service)
+			}
+		})
+	}
+}
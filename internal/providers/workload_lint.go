@@ -0,0 +1,420 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/hjames9/kraze/internal/color"
+	"github.com/hjames9/kraze/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Rule IDs for checks on rendered workloads, run at install time against the
+// cluster's nodes. KZ2xx continues the config lint numbering (see config.LintRules)
+// and the same lint.ignore list suppresses them.
+const (
+	RuleOversizedRequests       = "KZ201"
+	RuleUnsatisfiableAntiAffine = "KZ202"
+	RuleBlockingPDB             = "KZ203"
+	RuleUnsatisfiableSpread     = "KZ204"
+)
+
+// renderedWorkload is a pod-creating resource with its pod template
+type renderedWorkload struct {
+	kind      string
+	name      string
+	namespace string
+	replicas  int32
+	template  corev1.PodTemplateSpec
+}
+
+func (workload renderedWorkload) ref() string {
+	return workload.kind + "/" + workload.name
+}
+
+// LintWorkloads checks rendered resources for settings that leave pods Pending
+// or block drains on the given nodes. Resources without a namespace are treated
+// as being in defaultNamespace.
+func LintWorkloads(resources []*unstructured.Unstructured, nodes []corev1.Node, defaultNamespace string) []config.LintFinding {
+	var workloads []renderedWorkload
+	var pdbs []policyv1.PodDisruptionBudget
+	for _, obj := range resources {
+		namespace := obj.GetNamespace()
+		if namespace == "" {
+			namespace = defaultNamespace
+		}
+
+		if obj.GetKind() == "PodDisruptionBudget" {
+			var pdb policyv1.PodDisruptionBudget
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &pdb); err == nil {
+				pdb.Namespace = namespace
+				pdbs = append(pdbs, pdb)
+			}
+			continue
+		}
+
+		if workload, ok := workloadFromObject(obj); ok {
+			workload.namespace = namespace
+			workloads = append(workloads, workload)
+		}
+	}
+
+	var findings []config.LintFinding
+	for _, workload := range workloads {
+		eligible := eligibleNodes(workload.template.Spec, nodes)
+		findings = append(findings, lintOversizedRequests(workload, eligible)...)
+		findings = append(findings, lintAntiAffinity(workload, eligible)...)
+		findings = append(findings, lintTopologySpread(workload, eligible)...)
+	}
+	findings = append(findings, lintBlockingPDBs(pdbs, workloads)...)
+
+	return findings
+}
+
+// workloadFromObject extracts the pod template and replica count of pod-creating kinds
+func workloadFromObject(obj *unstructured.Unstructured) (renderedWorkload, bool) {
+	var templatePath []string
+	replicas := int32(1)
+
+	switch obj.GetKind() {
+	case "Deployment", "StatefulSet", "ReplicaSet":
+		templatePath = []string{"spec", "template"}
+		if value, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas"); found {
+			replicas = int32(value)
+		}
+	case "DaemonSet":
+		// One pod per eligible node, so replica-based checks don't apply
+		templatePath = []string{"spec", "template"}
+		replicas = 0
+	case "Job":
+		templatePath = []string{"spec", "template"}
+		if value, found, _ := unstructured.NestedInt64(obj.Object, "spec", "parallelism"); found {
+			replicas = int32(value)
+		}
+	case "CronJob":
+		templatePath = []string{"spec", "jobTemplate", "spec", "template"}
+		if value, found, _ := unstructured.NestedInt64(obj.Object, "spec", "jobTemplate", "spec", "parallelism"); found {
+			replicas = int32(value)
+		}
+	case "Pod":
+		var pod corev1.Pod
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &pod); err != nil {
+			return renderedWorkload{}, false
+		}
+		return renderedWorkload{
+			kind:     "Pod",
+			name:     obj.GetName(),
+			replicas: 1,
+			template: corev1.PodTemplateSpec{ObjectMeta: pod.ObjectMeta, Spec: pod.Spec},
+		}, true
+	default:
+		return renderedWorkload{}, false
+	}
+
+	rawTemplate, found, err := unstructured.NestedMap(obj.Object, templatePath...)
+	if err != nil || !found {
+		return renderedWorkload{}, false
+	}
+	var template corev1.PodTemplateSpec
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(rawTemplate, &template); err != nil {
+		return renderedWorkload{}, false
+	}
+
+	return renderedWorkload{
+		kind:     obj.GetKind(),
+		name:     obj.GetName(),
+		replicas: replicas,
+		template: template,
+	}, true
+}
+
+// eligibleNodes returns the nodes a pod could be scheduled on considering
+// cordoning, taints and nodeSelector (affinity is checked by the individual rules)
+func eligibleNodes(spec corev1.PodSpec, nodes []corev1.Node) []corev1.Node {
+	var eligible []corev1.Node
+	for _, node := range nodes {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		if !labels.SelectorFromSet(spec.NodeSelector).Matches(labels.Set(node.Labels)) {
+			continue
+		}
+		if !toleratesNodeTaints(spec.Tolerations, node.Spec.Taints) {
+			continue
+		}
+		eligible = append(eligible, node)
+	}
+	return eligible
+}
+
+// toleratesNodeTaints returns true if every scheduling-relevant taint is tolerated
+func toleratesNodeTaints(tolerations []corev1.Toleration, taints []corev1.Taint) bool {
+	for itr := range taints {
+		taint := &taints[itr]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for idx := range tolerations {
+			if toleratesTaint(tolerations[idx], taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
+
+// toleratesTaint applies the scheduler's toleration matching for Equal and Exists operators
+func toleratesTaint(toleration corev1.Toleration, taint *corev1.Taint) bool {
+	if toleration.Effect != "" && toleration.Effect != taint.Effect {
+		return false
+	}
+	if toleration.Key != "" && toleration.Key != taint.Key {
+		return false
+	}
+	switch toleration.Operator {
+	case corev1.TolerationOpExists:
+		return true
+	case "", corev1.TolerationOpEqual:
+		return toleration.Key != "" && toleration.Value == taint.Value
+	default:
+		return false
+	}
+}
+
+// podRequests returns the effective CPU and memory requests of one pod: the sum
+// over containers, or the largest init container if that is bigger
+func podRequests(spec corev1.PodSpec) (cpu, memory resource.Quantity) {
+	for _, container := range spec.Containers {
+		cpu.Add(container.Resources.Requests[corev1.ResourceCPU])
+		memory.Add(container.Resources.Requests[corev1.ResourceMemory])
+	}
+	for _, container := range spec.InitContainers {
+		if request := container.Resources.Requests[corev1.ResourceCPU]; request.Cmp(cpu) > 0 {
+			cpu = request.DeepCopy()
+		}
+		if request := container.Resources.Requests[corev1.ResourceMemory]; request.Cmp(memory) > 0 {
+			memory = request.DeepCopy()
+		}
+	}
+	return cpu, memory
+}
+
+// lintOversizedRequests flags pods whose requests exceed every eligible node's allocatable capacity
+func lintOversizedRequests(workload renderedWorkload, nodes []corev1.Node) []config.LintFinding {
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	cpu, memory := podRequests(workload.template.Spec)
+	var maxCPU, maxMemory resource.Quantity
+	for _, node := range nodes {
+		if allocatable := node.Status.Allocatable[corev1.ResourceCPU]; allocatable.Cmp(maxCPU) > 0 {
+			maxCPU = allocatable.DeepCopy()
+		}
+		if allocatable := node.Status.Allocatable[corev1.ResourceMemory]; allocatable.Cmp(maxMemory) > 0 {
+			maxMemory = allocatable.DeepCopy()
+		}
+	}
+
+	var findings []config.LintFinding
+	if !maxCPU.IsZero() && cpu.Cmp(maxCPU) > 0 {
+		findings = append(findings, config.LintFinding{
+			RuleID:  RuleOversizedRequests,
+			Field:   workload.ref(),
+			Message: fmt.Sprintf("pods request %s CPU but the largest node has %s allocatable; they will stay Pending", cpu.String(), maxCPU.String()),
+			Hint:    "lower resources.requests.cpu for local development",
+		})
+	}
+	if !maxMemory.IsZero() && memory.Cmp(maxMemory) > 0 {
+		findings = append(findings, config.LintFinding{
+			RuleID:  RuleOversizedRequests,
+			Field:   workload.ref(),
+			Message: fmt.Sprintf("pods request %s memory but the largest node has %s allocatable; they will stay Pending", memory.String(), maxMemory.String()),
+			Hint:    "lower resources.requests.memory for local development, or give Docker more memory",
+		})
+	}
+	return findings
+}
+
+// lintAntiAffinity flags required pod anti-affinity against the workload's own
+// pods when there are fewer topology domains than replicas
+func lintAntiAffinity(workload renderedWorkload, nodes []corev1.Node) []config.LintFinding {
+	affinity := workload.template.Spec.Affinity
+	if workload.replicas <= 1 || affinity == nil || affinity.PodAntiAffinity == nil {
+		return nil
+	}
+
+	var findings []config.LintFinding
+	for _, term := range affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+		if !termSelectsOwnPods(term, workload.template.Labels) {
+			continue
+		}
+		domains := countTopologyDomains(nodes, term.TopologyKey)
+		if int(workload.replicas) <= domains {
+			continue
+		}
+		findings = append(findings, config.LintFinding{
+			RuleID:  RuleUnsatisfiableAntiAffine,
+			Field:   workload.ref(),
+			Message: fmt.Sprintf("%d replicas require pod anti-affinity on '%s' but only %d schedulable domain(s) exist; %d replica(s) will stay Pending", workload.replicas, term.TopologyKey, domains, int(workload.replicas)-domains),
+			Hint:    "reduce replicas, switch to preferredDuringSchedulingIgnoredDuringExecution, or add worker nodes (cluster.config or cluster.preset)",
+		})
+	}
+	return findings
+}
+
+// termSelectsOwnPods returns true if an affinity term's selector matches the pod template labels
+func termSelectsOwnPods(term corev1.PodAffinityTerm, podLabels map[string]string) bool {
+	if term.LabelSelector == nil {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(term.LabelSelector)
+	if err != nil || selector.Empty() {
+		return false
+	}
+	return selector.Matches(labels.Set(podLabels))
+}
+
+// countTopologyDomains counts distinct values of a topology label across nodes
+func countTopologyDomains(nodes []corev1.Node, topologyKey string) int {
+	domains := make(map[string]bool)
+	for _, node := range nodes {
+		if value, exists := node.Labels[topologyKey]; exists {
+			domains[value] = true
+		}
+	}
+	return len(domains)
+}
+
+// lintTopologySpread flags hard spread constraints on a topology key no eligible node has
+func lintTopologySpread(workload renderedWorkload, nodes []corev1.Node) []config.LintFinding {
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	var findings []config.LintFinding
+	for _, constraint := range workload.template.Spec.TopologySpreadConstraints {
+		if constraint.WhenUnsatisfiable != corev1.DoNotSchedule {
+			continue
+		}
+		if countTopologyDomains(nodes, constraint.TopologyKey) > 0 {
+			continue
+		}
+		findings = append(findings, config.LintFinding{
+			RuleID:  RuleUnsatisfiableSpread,
+			Field:   workload.ref(),
+			Message: fmt.Sprintf("topologySpreadConstraints require '%s' but no node has that label; pods will stay Pending", constraint.TopologyKey),
+			Hint:    fmt.Sprintf("set whenUnsatisfiable: ScheduleAnyway, or add the '%s' label to nodes in cluster.config", constraint.TopologyKey),
+		})
+	}
+	return findings
+}
+
+// lintBlockingPDBs flags PodDisruptionBudgets that allow no voluntary disruptions
+// for the workloads they select, which blocks node drains and kind node restarts
+func lintBlockingPDBs(pdbs []policyv1.PodDisruptionBudget, workloads []renderedWorkload) []config.LintFinding {
+	var findings []config.LintFinding
+	for _, pdb := range pdbs {
+		if pdb.Spec.Selector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || selector.Empty() {
+			continue
+		}
+
+		var blocked []string
+		for _, workload := range workloads {
+			if workload.namespace != pdb.Namespace || workload.replicas == 0 {
+				continue
+			}
+			if !selector.Matches(labels.Set(workload.template.Labels)) {
+				continue
+			}
+			if allowsNoDisruptions(pdb.Spec, workload.replicas) {
+				blocked = append(blocked, workload.ref())
+			}
+		}
+		if len(blocked) == 0 {
+			continue
+		}
+		sort.Strings(blocked)
+
+		findings = append(findings, config.LintFinding{
+			RuleID:  RuleBlockingPDB,
+			Field:   "PodDisruptionBudget/" + pdb.Name,
+			Message: fmt.Sprintf("allows no disruptions for %v; draining or removing nodes will block", blocked),
+			Hint:    "lower minAvailable or raise maxUnavailable for local development",
+		})
+	}
+	return findings
+}
+
+// allowsNoDisruptions returns true if a PDB permits zero evictions at the given replica count
+func allowsNoDisruptions(spec policyv1.PodDisruptionBudgetSpec, replicas int32) bool {
+	if spec.MaxUnavailable != nil {
+		maxUnavailable, err := intstr.GetScaledValueFromIntOrPercent(spec.MaxUnavailable, int(replicas), true)
+		return err == nil && maxUnavailable == 0
+	}
+	if spec.MinAvailable != nil {
+		minAvailable, err := intstr.GetScaledValueFromIntOrPercent(spec.MinAvailable, int(replicas), true)
+		return err == nil && minAvailable >= int(replicas)
+	}
+	return false
+}
+
+// lintRenderedWorkloads runs LintWorkloads against the cluster's nodes and reports
+// findings to opts.OnLintFinding (printing them when no handler is set and output
+// isn't quiet). Lint problems never fail an install.
+func lintRenderedWorkloads(ctx context.Context, kubeconfig string, resources []*unstructured.Unstructured, defaultNamespace string, opts *ProviderOptions) {
+	notify := opts.OnLintFinding
+	if notify == nil && !opts.Quiet {
+		notify = func(finding config.LintFinding) { printLintFinding(opts.output(), finding) }
+	}
+	if notify == nil || len(resources) == 0 {
+		return
+	}
+
+	restConfig, err := getRESTConfigFromKubeconfig(kubeconfig)
+	if err != nil {
+		return
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return
+	}
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		if opts.Verbose {
+			fmt.Fprintf(opts.output(), "Warning: skipping workload lint, failed to list nodes: %v\n", err)
+		}
+		return
+	}
+
+	for _, finding := range LintWorkloads(resources, nodes.Items, defaultNamespace) {
+		notify(finding)
+	}
+}
+
+// printLintFinding is the default lint handler used when no handler is configured
+func printLintFinding(out io.Writer, finding config.LintFinding) {
+	fmt.Fprintf(out, "  %s %s\n", color.Warning(), finding.String())
+	if finding.Hint != "" {
+		fmt.Fprintf(out, "      hint: %s\n", finding.Hint)
+	}
+}
@@ -0,0 +1,230 @@
+package providers
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func lintTestNode(name string, cpu, memory string, labels map[string]string, taints ...corev1.Taint) corev1.Node {
+	nodeLabels := map[string]string{"kubernetes.io/hostname": name}
+	for key, value := range labels {
+		nodeLabels[key] = value
+	}
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: nodeLabels},
+		Spec:       corev1.NodeSpec{Taints: taints},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			},
+		},
+	}
+}
+
+func lintTestObjects(test *testing.T, docs ...string) []*unstructured.Unstructured {
+	test.Helper()
+	objects, err := parseManifestsYAML(strings.Join(docs, "\n---\n"))
+	if err != nil {
+		test.Fatalf("failed to parse test manifests: %v", err)
+	}
+	return objects
+}
+
+const lintTestDeployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      affinity:
+        podAntiAffinity:
+          requiredDuringSchedulingIgnoredDuringExecution:
+            - topologyKey: kubernetes.io/hostname
+              labelSelector:
+                matchLabels:
+                  app: web
+      topologySpreadConstraints:
+        - maxSkew: 1
+          topologyKey: topology.kubernetes.io/zone
+          whenUnsatisfiable: DoNotSchedule
+          labelSelector:
+            matchLabels:
+              app: web
+      containers:
+        - name: web
+          image: nginx
+          resources:
+            requests:
+              cpu: "16"
+              memory: 1Gi
+`
+
+const lintTestPDB = `
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: web
+spec:
+  minAvailable: 3
+  selector:
+    matchLabels:
+      app: web
+`
+
+const lintTestSmallDeployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: api
+  template:
+    metadata:
+      labels:
+        app: api
+    spec:
+      containers:
+        - name: api
+          image: api
+          resources:
+            requests:
+              cpu: 100m
+              memory: 128Mi
+`
+
+func TestLintWorkloads(test *testing.T) {
+	controlPlaneTaint := corev1.Taint{Key: "node-role.kubernetes.io/control-plane", Effect: corev1.TaintEffectNoSchedule}
+
+	tests := []struct {
+		name     string
+		docs     []string
+		nodes    []corev1.Node
+		expected []string
+	}{
+		{
+			name:     "single kind node flags every rule",
+			docs:     []string{lintTestDeployment, lintTestPDB},
+			nodes:    []corev1.Node{lintTestNode("kind-control-plane", "8", "16Gi", nil)},
+			expected: []string{RuleOversizedRequests, RuleUnsatisfiableAntiAffine, RuleUnsatisfiableSpread, RuleBlockingPDB},
+		},
+		{
+			name: "tainted control plane does not count as a domain",
+			docs: []string{lintTestDeployment},
+			nodes: []corev1.Node{
+				lintTestNode("cp", "32", "64Gi", map[string]string{"topology.kubernetes.io/zone": "a"}, controlPlaneTaint),
+				lintTestNode("w1", "32", "64Gi", map[string]string{"topology.kubernetes.io/zone": "a"}),
+				lintTestNode("w2", "32", "64Gi", map[string]string{"topology.kubernetes.io/zone": "b"}),
+			},
+			expected: []string{RuleUnsatisfiableAntiAffine},
+		},
+		{
+			name: "enough nodes with zones",
+			docs: []string{lintTestDeployment},
+			nodes: []corev1.Node{
+				lintTestNode("w1", "32", "64Gi", map[string]string{"topology.kubernetes.io/zone": "a"}),
+				lintTestNode("w2", "32", "64Gi", map[string]string{"topology.kubernetes.io/zone": "b"}),
+				lintTestNode("w3", "32", "64Gi", map[string]string{"topology.kubernetes.io/zone": "c"}),
+			},
+			expected: nil,
+		},
+		{
+			name:     "small workload is clean",
+			docs:     []string{lintTestSmallDeployment},
+			nodes:    []corev1.Node{lintTestNode("kind-control-plane", "8", "16Gi", nil)},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			findings := LintWorkloads(lintTestObjects(test, tt.docs...), tt.nodes, "default")
+
+			var got []string
+			for _, finding := range findings {
+				got = append(got, finding.RuleID)
+			}
+			if len(got) != len(tt.expected) {
+				test.Fatalf("got findings %v, want %v", findings, tt.expected)
+			}
+			for itr := range got {
+				if got[itr] != tt.expected[itr] {
+					test.Errorf("finding %d: got %s, want %s", itr, got[itr], tt.expected[itr])
+				}
+			}
+		})
+	}
+}
+
+func TestAllowsNoDisruptions(test *testing.T) {
+	intPtr := func(value int32) *intstr.IntOrString { v := intstr.FromInt32(value); return &v }
+	strPtr := func(value string) *intstr.IntOrString { v := intstr.FromString(value); return &v }
+
+	tests := []struct {
+		name           string
+		minAvailable   *intstr.IntOrString
+		maxUnavailable *intstr.IntOrString
+		replicas       int32
+		expected       bool
+	}{
+		{name: "maxUnavailable zero", maxUnavailable: intPtr(0), replicas: 3, expected: true},
+		{name: "maxUnavailable one", maxUnavailable: intPtr(1), replicas: 3, expected: false},
+		{name: "minAvailable equals replicas", minAvailable: intPtr(1), replicas: 1, expected: true},
+		{name: "minAvailable below replicas", minAvailable: intPtr(1), replicas: 2, expected: false},
+		{name: "minAvailable 100 percent", minAvailable: strPtr("100%"), replicas: 2, expected: true},
+		{name: "minAvailable 50 percent", minAvailable: strPtr("50%"), replicas: 2, expected: false},
+		{name: "no limits", replicas: 1, expected: false},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			spec := policyv1.PodDisruptionBudgetSpec{MinAvailable: tt.minAvailable, MaxUnavailable: tt.maxUnavailable}
+			if got := allowsNoDisruptions(spec, tt.replicas); got != tt.expected {
+				test.Errorf("allowsNoDisruptions() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestToleratesTaint(test *testing.T) {
+	taint := &corev1.Taint{Key: "node-role.kubernetes.io/control-plane", Effect: corev1.TaintEffectNoSchedule}
+
+	tests := []struct {
+		name       string
+		toleration corev1.Toleration
+		expected   bool
+	}{
+		{name: "exists on key", toleration: corev1.Toleration{Key: taint.Key, Operator: corev1.TolerationOpExists}, expected: true},
+		{name: "exists on everything", toleration: corev1.Toleration{Operator: corev1.TolerationOpExists}, expected: true},
+		{name: "equal with empty value", toleration: corev1.Toleration{Key: taint.Key, Effect: corev1.TaintEffectNoSchedule}, expected: true},
+		{name: "different effect", toleration: corev1.Toleration{Key: taint.Key, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute}, expected: false},
+		{name: "different key", toleration: corev1.Toleration{Key: "gpu", Operator: corev1.TolerationOpExists}, expected: false},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := toleratesTaint(tt.toleration, taint); got != tt.expected {
+				test.Errorf("toleratesTaint() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package sbom
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CycloneDX 1.5 JSON, limited to the fields kraze fills in
+type cdxBOM struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     cdxTools     `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	Type       string        `json:"type"`
+	BOMRef     string        `json:"bom-ref,omitempty"`
+	Name       string        `json:"name"`
+	Version    string        `json:"version,omitempty"`
+	PURL       string        `json:"purl,omitempty"`
+	Hashes     []cdxHash     `json:"hashes,omitempty"`
+	Properties []cdxProperty `json:"properties,omitempty"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// environmentRef is the bom-ref of the environment the SBOM describes
+const environmentRef = "environment"
+
+// cycloneDX encodes the inventory as CycloneDX JSON. The environment is the
+// described component and depends on its charts and images; charts depend on
+// their subcharts.
+func cycloneDX(inv *Inventory, doc Document) ([]byte, error) {
+	bom := cdxBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + doc.Serial,
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: doc.Timestamp.Format(time.RFC3339),
+			Tools:     cdxTools{Components: []cdxComponent{{Type: "application", Name: "kraze", Version: doc.ToolVersion}}},
+			Component: cdxComponent{Type: "platform", BOMRef: environmentRef, Name: inv.Cluster},
+		},
+		Components:   []cdxComponent{},
+		Dependencies: []cdxDependency{},
+	}
+
+	root := cdxDependency{Ref: environmentRef}
+	seen := make(map[string]bool)
+	var addChart func(chart Chart) string
+	addChart = func(chart Chart) string {
+		ref := "chart:" + chart.Name + "@" + chart.Version
+		if seen[ref] {
+			return ref
+		}
+		seen[ref] = true
+		component := cdxComponent{Type: "application", BOMRef: ref, Name: chart.Name, Version: chart.Version, PURL: chartPURL(chart)}
+		component.Properties = append(component.Properties, cdxProperty{Name: "kraze:type", Value: "helm-chart"})
+		if chart.AppVersion != "" {
+			component.Properties = append(component.Properties, cdxProperty{Name: "helm:appVersion", Value: chart.AppVersion})
+		}
+		component.Properties = append(component.Properties, serviceProperties(chart.Services)...)
+		bom.Components = append(bom.Components, component)
+
+		dependency := cdxDependency{Ref: ref}
+		for _, sub := range chart.Dependencies {
+			dependency.DependsOn = append(dependency.DependsOn, addChart(sub))
+		}
+		bom.Dependencies = append(bom.Dependencies, dependency)
+		return ref
+	}
+	for _, chart := range inv.Charts {
+		root.DependsOn = append(root.DependsOn, addChart(chart))
+	}
+
+	for _, image := range inv.Images {
+		ref := "image:" + imageKey(image)
+		component := cdxComponent{Type: "container", BOMRef: ref, Name: image.Name, Version: imageVersion(image), PURL: imagePURL(image)}
+		if hash := imageHash(image); hash != "" {
+			component.Hashes = []cdxHash{{Alg: "SHA-256", Content: hash}}
+		}
+		if image.ImageID != "" {
+			component.Properties = append(component.Properties, cdxProperty{Name: "kraze:imageID", Value: image.ImageID})
+		}
+		component.Properties = append(component.Properties, serviceProperties(image.Services)...)
+		bom.Components = append(bom.Components, component)
+		bom.Dependencies = append(bom.Dependencies, cdxDependency{Ref: ref})
+		root.DependsOn = append(root.DependsOn, ref)
+	}
+	bom.Dependencies = append([]cdxDependency{root}, bom.Dependencies...)
+
+	return json.MarshalIndent(bom, "", "  ")
+}
+
+// serviceProperties lists the services using a component
+func serviceProperties(services []string) []cdxProperty {
+	properties := make([]cdxProperty, 0, len(services))
+	for _, service := range services {
+		properties = append(properties, cdxProperty{Name: "kraze:service", Value: service})
+	}
+	return properties
+}
@@ -0,0 +1,165 @@
+// Package sbom writes a software bill of materials for a kraze environment:
+// the Helm charts its services were installed from and the container images
+// their pods run, as CycloneDX or SPDX JSON.
+package sbom
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// FormatCycloneDX is CycloneDX 1.5 JSON
+	FormatCycloneDX = "cyclonedx"
+
+	// FormatSPDX is SPDX 2.3 JSON
+	FormatSPDX = "spdx"
+)
+
+// Formats are the supported SBOM formats
+var Formats = []string{FormatCycloneDX, FormatSPDX}
+
+// Inventory is what an environment runs
+type Inventory struct {
+	Cluster string
+	Charts  []Chart
+	Images  []Image
+}
+
+// Chart is a Helm chart a service was installed from
+type Chart struct {
+	Name         string
+	Version      string
+	AppVersion   string
+	Repository   string   // Repository URL, empty for local charts
+	Services     []string // Services installed from the chart
+	Dependencies []Chart  // Subcharts
+}
+
+// Image is a container image run by the environment's pods
+type Image struct {
+	Name     string   // Reference as the pods use it, e.g. docker.io/library/redis:7.2
+	Digest   string   // Repository digest the image was pulled by (sha256:...), if known
+	ImageID  string   // Local image ID (sha256:...), for images loaded rather than pulled
+	Services []string // Services whose pods run the image
+}
+
+// Document describes the SBOM itself
+type Document struct {
+	Serial      string // UUID, generated when empty
+	Timestamp   time.Time
+	ToolVersion string // kraze version
+}
+
+// Sort orders the charts and images by name, for stable output
+func (inv *Inventory) Sort() {
+	sort.Slice(inv.Charts, func(i, j int) bool {
+		return inv.Charts[i].Name+"@"+inv.Charts[i].Version < inv.Charts[j].Name+"@"+inv.Charts[j].Version
+	})
+	sort.Slice(inv.Images, func(i, j int) bool { return imageKey(inv.Images[i]) < imageKey(inv.Images[j]) })
+	for itr := range inv.Charts {
+		sort.Strings(inv.Charts[itr].Services)
+	}
+	for itr := range inv.Images {
+		sort.Strings(inv.Images[itr].Services)
+	}
+}
+
+// Write encodes the inventory in format
+func Write(inv *Inventory, format string, doc Document) ([]byte, error) {
+	if doc.Serial == "" {
+		serial, err := newUUID()
+		if err != nil {
+			return nil, err
+		}
+		doc.Serial = serial
+	}
+	if doc.Timestamp.IsZero() {
+		doc.Timestamp = time.Now()
+	}
+	doc.Timestamp = doc.Timestamp.UTC().Truncate(time.Second)
+
+	switch format {
+	case FormatCycloneDX:
+		return cycloneDX(inv, doc)
+	case FormatSPDX:
+		return spdx(inv, doc)
+	}
+	return nil, fmt.Errorf("unknown SBOM format '%s' (expected %s)", format, strings.Join(Formats, " or "))
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() (string, error) {
+	var raw [16]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return "", fmt.Errorf("failed to generate serial number: %w", err)
+	}
+	raw[6] = raw[6]&0x0f | 0x40
+	raw[8] = raw[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", raw[0:4], raw[4:6], raw[6:8], raw[8:10], raw[10:16]), nil
+}
+
+// splitImage splits an image reference into repository and tag
+func splitImage(name string) (string, string) {
+	if at := strings.Index(name, "@"); at != -1 {
+		name = name[:at]
+	}
+	slash := strings.LastIndex(name, "/")
+	if colon := strings.LastIndex(name, ":"); colon > slash {
+		return name[:colon], name[colon+1:]
+	}
+	return name, "latest"
+}
+
+// imageVersion is the version an image is reported with: its digest, or its tag
+func imageVersion(image Image) string {
+	if image.Digest != "" {
+		return image.Digest
+	}
+	_, tag := splitImage(image.Name)
+	return tag
+}
+
+// imageKey identifies an image in the document; the same name can run by
+// several digests
+func imageKey(image Image) string {
+	if hash := imageHash(image); hash != "" {
+		return image.Name + "@" + hash
+	}
+	return image.Name
+}
+
+// imageHash returns the hex SHA-256 that identifies an image, if known
+func imageHash(image Image) string {
+	for _, id := range []string{image.Digest, image.ImageID} {
+		if hash, ok := strings.CutPrefix(id, "sha256:"); ok {
+			return hash
+		}
+	}
+	return ""
+}
+
+// imagePURL returns the package URL of an image: pkg:oci with the digest when
+// it's known, pkg:docker with the tag otherwise
+func imagePURL(image Image) string {
+	repository, tag := splitImage(image.Name)
+	name := strings.ToLower(repository[strings.LastIndex(repository, "/")+1:])
+	if image.Digest != "" {
+		query := url.Values{"repository_url": {repository}, "tag": {tag}}
+		return fmt.Sprintf("pkg:oci/%s@%s?%s", name, url.QueryEscape(image.Digest), query.Encode())
+	}
+	return fmt.Sprintf("pkg:docker/%s@%s", strings.ToLower(repository), url.PathEscape(tag))
+}
+
+// chartPURL returns the package URL of a chart
+func chartPURL(chart Chart) string {
+	purl := fmt.Sprintf("pkg:helm/%s@%s", strings.ToLower(chart.Name), url.PathEscape(chart.Version))
+	if chart.Repository != "" {
+		purl += "?" + url.Values{"repository_url": {chart.Repository}}.Encode()
+	}
+	return purl
+}
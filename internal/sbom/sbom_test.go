@@ -0,0 +1,207 @@
+package sbom
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testInventory() *Inventory {
+	inv := &Inventory{
+		Cluster: "dev",
+		Charts: []Chart{
+			{
+				Name: "redis", Version: "19.0.1", AppVersion: "7.2.4", Repository: "oci://registry-1.docker.io/bitnamicharts",
+				Services:     []string{"cache"},
+				Dependencies: []Chart{{Name: "common", Version: "2.19.0"}},
+			},
+		},
+		Images: []Image{
+			{Name: "docker.io/library/redis:7.2", Digest: "sha256:aaa", Services: []string{"cache"}},
+			{Name: "docker.io/library/api:dev", ImageID: "sha256:bbb", Services: []string{"worker", "api"}},
+			{Name: "docker.io/library/nginx:1.27", Services: []string{"web"}},
+		},
+	}
+	inv.Sort()
+	return inv
+}
+
+var testDocument = Document{
+	Serial:      "00000000-0000-4000-8000-000000000000",
+	Timestamp:   time.Date(2026, 1, 2, 3, 4, 5, 600, time.FixedZone("EST", -5*60*60)),
+	ToolVersion: "1.2.3",
+}
+
+func TestSplitImage(test *testing.T) {
+	tests := []struct {
+		name       string
+		image      string
+		repository string
+		tag        string
+	}{
+		{name: "tagged", image: "docker.io/library/redis:7.2", repository: "docker.io/library/redis", tag: "7.2"},
+		{name: "untagged", image: "redis", repository: "redis", tag: "latest"},
+		{name: "registry port", image: "localhost:5000/api", repository: "localhost:5000/api", tag: "latest"},
+		{name: "registry port and tag", image: "localhost:5000/api:dev", repository: "localhost:5000/api", tag: "dev"},
+		{name: "digest", image: "ghcr.io/org/app:1.0@sha256:abc", repository: "ghcr.io/org/app", tag: "1.0"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			repository, tag := splitImage(tt.image)
+			if repository != tt.repository || tag != tt.tag {
+				test.Errorf("splitImage() = %q, %q, want %q, %q", repository, tag, tt.repository, tt.tag)
+			}
+		})
+	}
+}
+
+func TestPURLs(test *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{
+			name: "image by digest",
+			got:  imagePURL(Image{Name: "docker.io/library/redis:7.2", Digest: "sha256:aaa"}),
+			want: "pkg:oci/redis@sha256%3Aaaa?repository_url=docker.io%2Flibrary%2Fredis&tag=7.2",
+		},
+		{
+			name: "image by tag",
+			got:  imagePURL(Image{Name: "docker.io/library/API:dev"}),
+			want: "pkg:docker/docker.io/library/api@dev",
+		},
+		{
+			name: "remote chart",
+			got:  chartPURL(Chart{Name: "redis", Version: "19.0.1", Repository: "https://charts.bitnami.com/bitnami"}),
+			want: "pkg:helm/redis@19.0.1?repository_url=https%3A%2F%2Fcharts.bitnami.com%2Fbitnami",
+		},
+		{
+			name: "local chart",
+			got:  chartPURL(Chart{Name: "api", Version: "0.1.0"}),
+			want: "pkg:helm/api@0.1.0",
+		},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if tt.got != tt.want {
+				test.Errorf("purl = %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteCycloneDX(test *testing.T) {
+	data, err := Write(testInventory(), FormatCycloneDX, testDocument)
+	if err != nil {
+		test.Fatalf("Write() error = %v", err)
+	}
+	var bom cdxBOM
+	if err := json.Unmarshal(data, &bom); err != nil {
+		test.Fatalf("invalid JSON: %v", err)
+	}
+
+	if bom.BOMFormat != "CycloneDX" || bom.SerialNumber != "urn:uuid:"+testDocument.Serial {
+		test.Errorf("header = %s %s", bom.BOMFormat, bom.SerialNumber)
+	}
+	if bom.Metadata.Timestamp != "2026-01-02T08:04:05Z" {
+		test.Errorf("timestamp = %s, want 2026-01-02T08:04:05Z", bom.Metadata.Timestamp)
+	}
+
+	components := make(map[string]cdxComponent)
+	for _, component := range bom.Components {
+		components[component.BOMRef] = component
+	}
+	if len(components) != 5 {
+		test.Fatalf("got %d components, want 5 (2 charts, 3 images)", len(components))
+	}
+	redis := components["image:docker.io/library/redis:7.2@aaa"]
+	if redis.Type != "container" || redis.Version != "sha256:aaa" || len(redis.Hashes) != 1 || redis.Hashes[0].Content != "aaa" {
+		test.Errorf("redis image = %+v", redis)
+	}
+	api := components["image:docker.io/library/api:dev@bbb"]
+	if len(api.Properties) != 3 || api.Properties[0].Value != "sha256:bbb" || api.Properties[1].Value != "api" {
+		test.Errorf("api image properties = %+v", api.Properties)
+	}
+	if nginx := components["image:docker.io/library/nginx:1.27"]; nginx.Version != "1.27" || len(nginx.Hashes) != 0 {
+		test.Errorf("nginx image = %+v", nginx)
+	}
+
+	dependsOn := make(map[string][]string)
+	for _, dependency := range bom.Dependencies {
+		dependsOn[dependency.Ref] = dependency.DependsOn
+	}
+	if len(dependsOn[environmentRef]) != 4 {
+		test.Errorf("environment depends on %v, want the chart and 3 images", dependsOn[environmentRef])
+	}
+	if subcharts := dependsOn["chart:redis@19.0.1"]; len(subcharts) != 1 || subcharts[0] != "chart:common@2.19.0" {
+		test.Errorf("redis chart depends on %v, want chart:common@2.19.0", subcharts)
+	}
+}
+
+func TestWriteSPDX(test *testing.T) {
+	data, err := Write(testInventory(), FormatSPDX, testDocument)
+	if err != nil {
+		test.Fatalf("Write() error = %v", err)
+	}
+	var document spdxDocument
+	if err := json.Unmarshal(data, &document); err != nil {
+		test.Fatalf("invalid JSON: %v", err)
+	}
+
+	if document.SPDXVersion != "SPDX-2.3" || !strings.HasSuffix(document.DocumentNamespace, "kraze-dev-"+testDocument.Serial) {
+		test.Errorf("header = %s %s", document.SPDXVersion, document.DocumentNamespace)
+	}
+
+	packages := make(map[string]spdxPackage)
+	for _, pkg := range document.Packages {
+		if _, ok := packages[pkg.SPDXID]; ok {
+			test.Errorf("duplicate SPDX ID %s", pkg.SPDXID)
+		}
+		packages[pkg.SPDXID] = pkg
+	}
+	if len(packages) != 6 {
+		test.Fatalf("got %d packages, want 6 (environment, 2 charts, 3 images)", len(packages))
+	}
+	redis := packages["SPDXRef-Chart-redis-19.0.1"]
+	if redis.DownloadLocation != "oci://registry-1.docker.io/bitnamicharts" || redis.Comment != "Helm chart, app version 7.2.4, used by cache" {
+		test.Errorf("redis chart = %+v", redis)
+	}
+	image := packages["SPDXRef-Image-docker.io-library-redis-7.2-aaa"]
+	if len(image.Checksums) != 1 || image.Checksums[0].ChecksumValue != "aaa" {
+		test.Errorf("redis image = %+v", image)
+	}
+
+	relationships := make(map[string]bool)
+	for _, relationship := range document.Relationships {
+		relationships[relationship.SPDXElementID+" "+relationship.RelationshipType+" "+relationship.RelatedSPDXElement] = true
+	}
+	for _, want := range []string{
+		"SPDXRef-DOCUMENT DESCRIBES SPDXRef-Environment",
+		"SPDXRef-Environment CONTAINS SPDXRef-Chart-redis-19.0.1",
+		"SPDXRef-Chart-redis-19.0.1 DEPENDS_ON SPDXRef-Chart-common-2.19.0",
+		"SPDXRef-Environment CONTAINS SPDXRef-Image-docker.io-library-nginx-1.27",
+	} {
+		if !relationships[want] {
+			test.Errorf("missing relationship %s", want)
+		}
+	}
+}
+
+func TestWrite(test *testing.T) {
+	if _, err := Write(testInventory(), "syft", testDocument); err == nil {
+		test.Error("Write() expected error for unknown format")
+	}
+
+	first, err := Write(testInventory(), FormatCycloneDX, Document{ToolVersion: "1.2.3"})
+	if err != nil {
+		test.Fatalf("Write() error = %v", err)
+	}
+	second, _ := Write(testInventory(), FormatCycloneDX, Document{ToolVersion: "1.2.3"})
+	if string(first) == string(second) {
+		test.Error("Write() expected a new serial number for each document")
+	}
+}
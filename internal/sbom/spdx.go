@@ -0,0 +1,155 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SPDX 2.3 JSON, limited to the fields kraze fills in
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name                  string            `json:"name"`
+	SPDXID                string            `json:"SPDXID"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose,omitempty"`
+	Checksums             []spdxChecksum    `json:"checksums,omitempty"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs,omitempty"`
+	Comment               string            `json:"comment,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// spdxEnvironmentID is the SPDX identifier of the environment package
+const spdxEnvironmentID = "SPDXRef-Environment"
+
+// invalidSPDXIDChars are replaced in SPDX identifiers, which only allow letters, digits, . and -
+var invalidSPDXIDChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// spdxID returns an SPDX identifier for a package
+func spdxID(kind, name string) string {
+	return "SPDXRef-" + kind + "-" + strings.Trim(invalidSPDXIDChars.ReplaceAllString(name, "-"), "-")
+}
+
+// spdx encodes the inventory as SPDX JSON. The document describes the
+// environment package, which contains the charts and images; charts depend on
+// their subcharts.
+func spdx(inv *Inventory, doc Document) ([]byte, error) {
+	document := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              "kraze-" + inv.Cluster,
+		DocumentNamespace: fmt.Sprintf("https://spdx.org/spdxdocs/kraze-%s-%s", inv.Cluster, doc.Serial),
+		CreationInfo: spdxCreationInfo{
+			Created:  doc.Timestamp.Format(time.RFC3339),
+			Creators: []string{"Tool: kraze-" + doc.ToolVersion},
+		},
+		Packages: []spdxPackage{{
+			Name:             inv.Cluster,
+			SPDXID:           spdxEnvironmentID,
+			DownloadLocation: "NOASSERTION",
+			Comment:          "kraze environment",
+		}},
+		Relationships: []spdxRelationship{{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: spdxEnvironmentID}},
+	}
+	relate := func(from, relationship, to string) {
+		document.Relationships = append(document.Relationships, spdxRelationship{SPDXElementID: from, RelationshipType: relationship, RelatedSPDXElement: to})
+	}
+
+	seen := make(map[string]bool)
+	var addChart func(chart Chart) string
+	addChart = func(chart Chart) string {
+		id := spdxID("Chart", chart.Name+"-"+chart.Version)
+		if seen[id] {
+			return id
+		}
+		seen[id] = true
+		pkg := spdxPackage{
+			Name:                  chart.Name,
+			SPDXID:                id,
+			VersionInfo:           chart.Version,
+			DownloadLocation:      "NOASSERTION",
+			PrimaryPackagePurpose: "APPLICATION",
+			ExternalRefs:          []spdxExternalRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: chartPURL(chart)}},
+			Comment:               packageComment("Helm chart", chart.AppVersion, chart.Services),
+		}
+		if chart.Repository != "" {
+			pkg.DownloadLocation = chart.Repository
+		}
+		document.Packages = append(document.Packages, pkg)
+		for _, sub := range chart.Dependencies {
+			relate(id, "DEPENDS_ON", addChart(sub))
+		}
+		return id
+	}
+	for _, chart := range inv.Charts {
+		relate(spdxEnvironmentID, "CONTAINS", addChart(chart))
+	}
+
+	for _, image := range inv.Images {
+		id := spdxID("Image", imageKey(image))
+		pkg := spdxPackage{
+			Name:                  image.Name,
+			SPDXID:                id,
+			VersionInfo:           imageVersion(image),
+			DownloadLocation:      "NOASSERTION",
+			PrimaryPackagePurpose: "CONTAINER",
+			ExternalRefs:          []spdxExternalRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: imagePURL(image)}},
+			Comment:               packageComment("Container image", "", image.Services),
+		}
+		if hash := imageHash(image); hash != "" {
+			pkg.Checksums = []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: hash}}
+		}
+		document.Packages = append(document.Packages, pkg)
+		relate(spdxEnvironmentID, "CONTAINS", id)
+	}
+
+	return json.MarshalIndent(document, "", "  ")
+}
+
+// packageComment describes a package and the services that use it
+func packageComment(kind, appVersion string, services []string) string {
+	comment := kind
+	if appVersion != "" {
+		comment += ", app version " + appVersion
+	}
+	if len(services) > 0 {
+		comment += ", used by " + strings.Join(services, ", ")
+	}
+	return comment
+}
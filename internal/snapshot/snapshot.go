@@ -0,0 +1,186 @@
+// Package snapshot reads and writes kraze snapshot archives: the config that
+// created an environment, what each service applied, the data of its
+// PersistentVolumeClaims and the images that were loaded into the cluster.
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// MetadataFile is the snapshot description at the root of the archive
+	MetadataFile = "kraze-snapshot.json"
+
+	// PackageFile is the kraze pack archive of the config, at the root of the archive
+	PackageFile = "package.tar.gz"
+
+	// FormatVersion is the current snapshot format version
+	FormatVersion = 1
+)
+
+// stripAnnotationPrefixes are PVC annotations set by the control plane while
+// binding a claim. They describe the old cluster and are dropped on restore.
+var stripAnnotationPrefixes = []string{
+	"pv.kubernetes.io/",
+	"volume.beta.kubernetes.io/",
+	"volume.kubernetes.io/",
+}
+
+// Metadata describes a snapshot
+type Metadata struct {
+	Version           int       `json:"version"`
+	KrazeVersion      string    `json:"kraze_version"`
+	CreatedAt         time.Time `json:"created_at"`
+	ClusterName       string    `json:"cluster_name"`
+	KubernetesVersion string    `json:"kubernetes_version,omitempty"`
+	Services          []Service `json:"services"`
+	Volumes           []Volume  `json:"volumes,omitempty"`
+}
+
+// Service is an installed service in a snapshot
+type Service struct {
+	Name             string            `json:"name"`
+	Type             string            `json:"type"`
+	Namespace        string            `json:"namespace"`
+	CreatedNamespace bool              `json:"created_namespace,omitempty"`
+	Images           map[string]string `json:"images,omitempty"`   // Loaded image name to SHA256 hash
+	Values           string            `json:"values,omitempty"`   // Archive path of the Helm release values
+	Manifest         string            `json:"manifest,omitempty"` // Archive path of the applied resources
+}
+
+// Volume is a PersistentVolumeClaim and the archive path of its data
+type Volume struct {
+	Namespace    string            `json:"namespace"`
+	Name         string            `json:"name"`
+	Size         string            `json:"size"`
+	AccessModes  []string          `json:"access_modes"`
+	StorageClass string            `json:"storage_class,omitempty"`
+	VolumeMode   string            `json:"volume_mode,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	Data         string            `json:"data"`
+}
+
+// ServicePath returns the archive path of a file belonging to a service
+func ServicePath(service, fileName string) string {
+	return path.Join("services", service, fileName)
+}
+
+// VolumeFromPVC describes a claim for a snapshot
+func VolumeFromPVC(pvc *corev1.PersistentVolumeClaim) Volume {
+	vol := Volume{
+		Namespace:   pvc.Namespace,
+		Name:        pvc.Name,
+		Labels:      pvc.Labels,
+		Annotations: map[string]string{},
+		Data:        path.Join("volumes", pvc.Namespace, pvc.Name+".tar.gz"),
+	}
+	if size, exists := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; exists {
+		vol.Size = size.String()
+	}
+	for _, mode := range pvc.Spec.AccessModes {
+		vol.AccessModes = append(vol.AccessModes, string(mode))
+	}
+	if pvc.Spec.StorageClassName != nil {
+		vol.StorageClass = *pvc.Spec.StorageClassName
+	}
+	if pvc.Spec.VolumeMode != nil {
+		vol.VolumeMode = string(*pvc.Spec.VolumeMode)
+	}
+
+	for key, value := range pvc.Annotations {
+		if !hasAnyPrefix(key, stripAnnotationPrefixes) {
+			vol.Annotations[key] = value
+		}
+	}
+	if len(vol.Annotations) == 0 {
+		vol.Annotations = nil
+	}
+	return vol
+}
+
+// PVC returns an unbound claim equivalent to the snapshotted one
+func (vol Volume) PVC() (*corev1.PersistentVolumeClaim, error) {
+	size, err := resource.ParseQuantity(vol.Size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid size '%s' for volume %s/%s: %w", vol.Size, vol.Namespace, vol.Name, err)
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        vol.Name,
+			Namespace:   vol.Namespace,
+			Labels:      vol.Labels,
+			Annotations: vol.Annotations,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+			},
+		},
+	}
+	for _, mode := range vol.AccessModes {
+		pvc.Spec.AccessModes = append(pvc.Spec.AccessModes, corev1.PersistentVolumeAccessMode(mode))
+	}
+	if vol.StorageClass != "" {
+		storageClass := vol.StorageClass
+		pvc.Spec.StorageClassName = &storageClass
+	}
+	if vol.VolumeMode != "" {
+		volumeMode := corev1.PersistentVolumeMode(vol.VolumeMode)
+		pvc.Spec.VolumeMode = &volumeMode
+	}
+	return pvc, nil
+}
+
+// WriteMetadata writes the snapshot description into a staging directory
+func WriteMetadata(dir string, meta *Metadata) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize snapshot metadata: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, MetadataFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write snapshot metadata: %w", err)
+	}
+	return nil
+}
+
+// ReadMetadata reads the snapshot description from an extracted snapshot
+func ReadMetadata(dir string) (*Metadata, error) {
+	data, err := os.ReadFile(filepath.Join(dir, MetadataFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("not a kraze snapshot: %s is missing", MetadataFile)
+		}
+		return nil, fmt.Errorf("failed to read snapshot metadata: %w", err)
+	}
+
+	var meta Metadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot metadata: %w", err)
+	}
+	if meta.Version > FormatVersion {
+		return nil, fmt.Errorf("snapshot format version %d is newer than this kraze supports (%d), upgrade kraze", meta.Version, FormatVersion)
+	}
+	return &meta, nil
+}
+
+// hasAnyPrefix reports whether s starts with one of prefixes
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
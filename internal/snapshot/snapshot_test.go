@@ -0,0 +1,128 @@
+package snapshot
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestVolumeFromPVC(test *testing.T) {
+	storageClass := "standard"
+	volumeMode := corev1.PersistentVolumeFilesystem
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "data-postgres-0",
+			Namespace: "db",
+			Labels:    map[string]string{"app": "postgres"},
+			Annotations: map[string]string{
+				"pv.kubernetes.io/bind-completed":               "yes",
+				"volume.beta.kubernetes.io/storage-provisioner": "rancher.io/local-path",
+				"volume.kubernetes.io/selected-node":            "kraze-control-plane",
+				"team":                                          "data",
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: &storageClass,
+			VolumeMode:       &volumeMode,
+			VolumeName:       "pvc-1234",
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("8Gi")},
+			},
+		},
+	}
+
+	vol := VolumeFromPVC(pvc)
+	expected := Volume{
+		Namespace:    "db",
+		Name:         "data-postgres-0",
+		Size:         "8Gi",
+		AccessModes:  []string{"ReadWriteOnce"},
+		StorageClass: "standard",
+		VolumeMode:   "Filesystem",
+		Labels:       map[string]string{"app": "postgres"},
+		Annotations:  map[string]string{"team": "data"},
+		Data:         "volumes/db/data-postgres-0.tar.gz",
+	}
+	if !reflect.DeepEqual(vol, expected) {
+		test.Fatalf("VolumeFromPVC() = %+v, want %+v", vol, expected)
+	}
+
+	restored, err := vol.PVC()
+	if err != nil {
+		test.Fatalf("PVC() error = %v", err)
+	}
+	if restored.Spec.VolumeName != "" {
+		test.Errorf("restored claim is bound to volume %q, want unbound", restored.Spec.VolumeName)
+	}
+	if !reflect.DeepEqual(restored.Spec.AccessModes, pvc.Spec.AccessModes) {
+		test.Errorf("access modes = %v, want %v", restored.Spec.AccessModes, pvc.Spec.AccessModes)
+	}
+	size := restored.Spec.Resources.Requests[corev1.ResourceStorage]
+	if size.Cmp(resource.MustParse("8Gi")) != 0 {
+		test.Errorf("size = %s, want 8Gi", size.String())
+	}
+	if *restored.Spec.StorageClassName != "standard" || *restored.Spec.VolumeMode != volumeMode {
+		test.Errorf("storage class/volume mode = %s/%s, want standard/Filesystem", *restored.Spec.StorageClassName, *restored.Spec.VolumeMode)
+	}
+}
+
+func TestVolumePVCInvalidSize(test *testing.T) {
+	if _, err := (Volume{Namespace: "db", Name: "data", Size: "lots"}).PVC(); err == nil {
+		test.Error("PVC() expected an error for an invalid size")
+	}
+}
+
+func TestMetadataRoundTrip(test *testing.T) {
+	tests := []struct {
+		name      string
+		version   int
+		expectErr bool
+	}{
+		{name: "current version", version: FormatVersion},
+		{name: "newer version", version: FormatVersion + 1, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			dir := test.TempDir()
+			meta := &Metadata{
+				Version:     tt.version,
+				CreatedAt:   time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+				ClusterName: "dev",
+				Services: []Service{
+					{Name: "redis", Type: "helm", Namespace: "cache", Values: ServicePath("redis", "values.yaml"), Images: map[string]string{"app:dev": "abc"}},
+				},
+			}
+			if err := WriteMetadata(dir, meta); err != nil {
+				test.Fatal(err)
+			}
+
+			read, err := ReadMetadata(dir)
+			if tt.expectErr {
+				if err == nil {
+					test.Error("ReadMetadata() expected an error")
+				}
+				return
+			}
+			if err != nil {
+				test.Fatalf("ReadMetadata() error = %v", err)
+			}
+			if !reflect.DeepEqual(read, meta) {
+				test.Errorf("ReadMetadata() = %+v, want %+v", read, meta)
+			}
+		})
+	}
+}
+
+func TestReadMetadataMissing(test *testing.T) {
+	_, err := ReadMetadata(test.TempDir())
+	if err == nil || !strings.Contains(err.Error(), "not a kraze snapshot") {
+		test.Errorf("ReadMetadata() error = %v, want 'not a kraze snapshot'", err)
+	}
+}
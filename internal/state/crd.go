@@ -0,0 +1,511 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// EnvironmentGroup is the API group of the KrazeEnvironment custom resource
+	EnvironmentGroup = "kraze.dev"
+
+	// EnvironmentVersion is the served version of the KrazeEnvironment custom resource
+	EnvironmentVersion = "v1alpha1"
+
+	// EnvironmentKind is the kind of the custom resource the crd backend stores state in
+	EnvironmentKind = "KrazeEnvironment"
+
+	// environmentResource is the plural resource name of KrazeEnvironment
+	environmentResource = "krazeenvironments"
+
+	// environmentCRDName is the name of the KrazeEnvironment CustomResourceDefinition
+	environmentCRDName = environmentResource + "." + EnvironmentGroup
+
+	// defaultEnvironment names the KrazeEnvironment of the environment run
+	// without --suffix; variants are named by their suffix
+	defaultEnvironment = "default"
+
+	// crdEstablishTimeout is how long kraze waits for a new CRD to be served
+	crdEstablishTimeout = 30 * time.Second
+)
+
+// environment is a KrazeEnvironment: the cluster it's for in its spec, and
+// the state of its services in its status subresource
+type environment struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Metadata   metav1.ObjectMeta  `json:"metadata"`
+	Spec       environmentSpec    `json:"spec"`
+	Status     *environmentStatus `json:"status,omitempty"`
+}
+
+// environmentSpec identifies the environment a KrazeEnvironment is for
+type environmentSpec struct {
+	Cluster     string   `json:"cluster"`
+	Suffix      string   `json:"suffix,omitempty"`       // --suffix of a variant
+	ConfigPaths []string `json:"config_paths,omitempty"` // Config files the environment was brought up from
+}
+
+// environmentStatus is the cluster state, compressed like the state in a
+// ConfigMap, with a summary for kubectl's output. A state too large for one
+// KrazeEnvironment is split across extra ones named after it.
+type environmentStatus struct {
+	ClusterName       string    `json:"cluster_name,omitempty"`
+	InstalledServices int       `json:"installed_services,omitempty"`
+	LastUpdated       time.Time `json:"last_updated,omitzero"`
+	Encoding          string    `json:"encoding,omitempty"`
+	Shards            int       `json:"shards,omitempty"`
+	Checksum          string    `json:"checksum"`        // SHA-256 of the whole encoded state, on the KrazeEnvironment and each shard
+	State             string    `json:"state,omitempty"` // The encoded state, or this object's part of it
+}
+
+// environmentName returns the name of the KrazeEnvironment of the environment being run
+func environmentName() string {
+	if variantSuffix == "" {
+		return defaultEnvironment
+	}
+	return variantSuffix
+}
+
+// environmentShardName returns the name of the KrazeEnvironment holding part
+// index of a split state
+func environmentShardName(index int) string {
+	return fmt.Sprintf("%s.shard-%d", environmentName(), index)
+}
+
+// environmentPath returns the API path of a KrazeEnvironment, or of all of
+// them when name is empty
+func environmentPath(name string) string {
+	path := "/apis/" + EnvironmentGroup + "/" + EnvironmentVersion + "/" + environmentResource
+	if name != "" {
+		path += "/" + name
+	}
+	return path
+}
+
+// restClient returns a client for raw API requests, nil for clients that
+// can't make them (like the fake clientset)
+func restClient(clientset kubernetes.Interface) rest.Interface {
+	discovery := clientset.Discovery()
+	if discovery == nil {
+		return nil
+	}
+	return discovery.RESTClient()
+}
+
+// isMissing returns whether an error means the resource, or its CRD, doesn't exist
+func isMissing(err error) bool {
+	return errors.IsNotFound(err) || errors.IsMethodNotSupported(err)
+}
+
+// getEnvironment reads a KrazeEnvironment. Returns nil when there is none or
+// the CRD isn't installed.
+func getEnvironment(ctx context.Context, client rest.Interface, name string) (*environment, error) {
+	data, err := client.Get().AbsPath(environmentPath(name)).Do(ctx).Raw()
+	if err != nil {
+		if isMissing(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s '%s': %w", EnvironmentKind, name, err)
+	}
+	var object environment
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, fmt.Errorf("failed to parse %s '%s': %w", EnvironmentKind, name, err)
+	}
+	return &object, nil
+}
+
+// loadEnvironment reads the cluster state from the KrazeEnvironment of the
+// environment being run, and from the ones it was split across. Returns nil
+// when there is none or the CRD isn't installed.
+func loadEnvironment(ctx context.Context, clientset kubernetes.Interface) (*ClusterState, error) {
+	client := restClient(clientset)
+	if client == nil {
+		return nil, nil
+	}
+	name := environmentName()
+	object, err := getEnvironment(ctx, client, name)
+	if err != nil || object == nil || object.Status == nil || object.Status.State == "" {
+		return nil, err
+	}
+
+	status := object.Status
+	shards := max(status.Shards, 1)
+	chunks := []string{status.State}
+	for index := 1; index < shards; index++ {
+		shard, err := getEnvironment(ctx, client, environmentShardName(index))
+		if err != nil {
+			return nil, err
+		}
+		if shard == nil || shard.Status == nil {
+			return nil, fmt.Errorf("cluster state %s '%s' is missing", EnvironmentKind, environmentShardName(index))
+		}
+		if shard.Status.Checksum != status.Checksum {
+			return nil, fmt.Errorf("cluster state %s '%s' is from another save than '%s' (the state changed while it was read); run the command again", EnvironmentKind, environmentShardName(index), name)
+		}
+		chunks = append(chunks, shard.Status.State)
+	}
+	encoded := strings.Join(chunks, "")
+	if stateChecksum(encoded) != status.Checksum {
+		return nil, fmt.Errorf("cluster state in %s '%s' doesn't match its checksum", EnvironmentKind, name)
+	}
+
+	data, err := decodeState(encoded, status.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	var state ClusterState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse cluster state: %w", err)
+	}
+	if state.Services == nil {
+		state.Services = make(map[string]ServiceMetadata)
+	}
+	if err := state.migrate(); err != nil {
+		return nil, fmt.Errorf("failed to migrate cluster state: %w", err)
+	}
+	state.storedBytes, state.storedShards, state.backend = len(encoded), shards, BackendCRD
+	return &state, nil
+}
+
+// saveEnvironment writes the cluster state to the status of the environment's
+// KrazeEnvironment, installing the CRD first when the cluster doesn't have it.
+// The state is compressed and, when it outgrows one object, split across extra
+// KrazeEnvironments, which are written before the main one that says how many
+// there are.
+func (cs *ClusterState) saveEnvironment(ctx context.Context, clientset kubernetes.Interface) error {
+	client := restClient(clientset)
+	if client == nil {
+		return fmt.Errorf("the crd state backend needs a connection to a Kubernetes API server")
+	}
+	if err := ensureEnvironmentCRD(ctx, client); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(cs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cluster state: %w", err)
+	}
+	chunks, err := encodeState(data)
+	if err != nil {
+		return err
+	}
+	checksum := stateChecksum(strings.Join(chunks, ""))
+
+	name := environmentName()
+	spec := environmentSpec{Cluster: cs.ClusterName, Suffix: variantSuffix, ConfigPaths: cs.ConfigPaths}
+	for index := 1; index < len(chunks); index++ {
+		shardSpec := environmentSpec{Cluster: cs.ClusterName, Suffix: variantSuffix}
+		shardStatus := &environmentStatus{Checksum: checksum, State: chunks[index]}
+		if _, err := writeEnvironment(ctx, client, environmentShardName(index), map[string]string{ShardLabel: name}, shardSpec, shardStatus); err != nil {
+			return err
+		}
+	}
+
+	status := &environmentStatus{
+		ClusterName: cs.ClusterName,
+		LastUpdated: cs.LastUpdated,
+		Encoding:    encodingGzip,
+		Shards:      len(chunks),
+		Checksum:    checksum,
+		State:       chunks[0],
+	}
+	for _, svc := range cs.Services {
+		if svc.Installed {
+			status.InstalledServices++
+		}
+	}
+	previousShards, err := writeEnvironment(ctx, client, name, nil, spec, status)
+	if err != nil {
+		return err
+	}
+
+	// Parts left over from a larger state are no longer read
+	for index := len(chunks); index < previousShards; index++ {
+		err := client.Delete().AbsPath(environmentPath(environmentShardName(index))).Do(ctx).Error()
+		if err != nil && !isMissing(err) {
+			return fmt.Errorf("failed to delete %s '%s': %w", EnvironmentKind, environmentShardName(index), err)
+		}
+	}
+
+	cs.storedBytes, cs.storedShards = 0, len(chunks)
+	for _, chunk := range chunks {
+		cs.storedBytes += len(chunk)
+	}
+	return nil
+}
+
+// writeEnvironment creates or updates a KrazeEnvironment with a spec and
+// status. Returns the number of parts its status said the state had before
+// (1 when it didn't say).
+func writeEnvironment(ctx context.Context, client rest.Interface, name string, labels map[string]string, spec environmentSpec, status *environmentStatus) (int, error) {
+	// Another command may write the resource between reading and writing it,
+	// so the write is retried against the latest version
+	previousShards := 1
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		data, err := client.Get().AbsPath(environmentPath(name)).Do(ctx).Raw()
+		var object environment
+		switch {
+		case errors.IsNotFound(err):
+			// The status subresource ignores status on create, so it's written after
+			object = environment{
+				APIVersion: EnvironmentGroup + "/" + EnvironmentVersion,
+				Kind:       EnvironmentKind,
+				Metadata: metav1.ObjectMeta{
+					Name:   name,
+					Labels: map[string]string{"app.kubernetes.io/managed-by": "kraze"},
+				},
+				Spec: spec,
+			}
+			for key, value := range labels {
+				object.Metadata.Labels[key] = value
+			}
+			body, err := json.Marshal(object)
+			if err != nil {
+				return fmt.Errorf("failed to marshal %s: %w", EnvironmentKind, err)
+			}
+			if data, err = client.Post().AbsPath(environmentPath("")).SetHeader("Content-Type", "application/json").Body(body).Do(ctx).Raw(); err != nil {
+				return fmt.Errorf("failed to create %s '%s': %w", EnvironmentKind, name, err)
+			}
+		case err != nil:
+			return fmt.Errorf("failed to read %s '%s': %w", EnvironmentKind, name, err)
+		}
+		if err := json.Unmarshal(data, &object); err != nil {
+			return fmt.Errorf("failed to parse %s '%s': %w", EnvironmentKind, name, err)
+		}
+		if object.Status != nil && object.Status.Shards > 0 {
+			previousShards = object.Status.Shards
+		}
+
+		if !specEqual(object.Spec, spec) {
+			object.Spec, object.Status = spec, nil
+			body, err := json.Marshal(object)
+			if err != nil {
+				return fmt.Errorf("failed to marshal %s: %w", EnvironmentKind, err)
+			}
+			if data, err = client.Put().AbsPath(environmentPath(name)).SetHeader("Content-Type", "application/json").Body(body).Do(ctx).Raw(); err != nil {
+				return err
+			}
+			if err := json.Unmarshal(data, &object); err != nil {
+				return fmt.Errorf("failed to parse %s '%s': %w", EnvironmentKind, name, err)
+			}
+		}
+
+		object.Status = status
+		body, err := json.Marshal(object)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", EnvironmentKind, err)
+		}
+		return client.Put().AbsPath(environmentPath(name)+"/status").SetHeader("Content-Type", "application/json").Body(body).Do(ctx).Error()
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to write cluster state to %s '%s': %w", EnvironmentKind, name, err)
+	}
+	return previousShards, nil
+}
+
+// specEqual returns whether two KrazeEnvironment specs are the same
+func specEqual(a, b environmentSpec) bool {
+	first, _ := json.Marshal(a)
+	second, _ := json.Marshal(b)
+	return string(first) == string(second)
+}
+
+// deleteEnvironment removes the KrazeEnvironment of the environment being
+// run, with the ones a split state uses. The CRD stays, since other
+// environments may use it.
+func deleteEnvironment(ctx context.Context, clientset kubernetes.Interface) error {
+	client := restClient(clientset)
+	if client == nil {
+		return nil
+	}
+	shards, err := listEnvironmentItems(ctx, client, ShardLabel+"="+environmentName())
+	if err != nil {
+		return err
+	}
+	for _, shard := range shards {
+		err := client.Delete().AbsPath(environmentPath(shard.Metadata.Name)).Do(ctx).Error()
+		if err != nil && !isMissing(err) {
+			return fmt.Errorf("failed to delete %s '%s': %w", EnvironmentKind, shard.Metadata.Name, err)
+		}
+	}
+
+	err = client.Delete().AbsPath(environmentPath(environmentName())).Do(ctx).Error()
+	if err != nil && !isMissing(err) {
+		return fmt.Errorf("failed to delete %s '%s': %w", EnvironmentKind, environmentName(), err)
+	}
+	return nil
+}
+
+// listEnvironmentItems returns the KrazeEnvironments matching a label
+// selector, none when the CRD isn't installed
+func listEnvironmentItems(ctx context.Context, client rest.Interface, selector string) ([]environment, error) {
+	request := client.Get().AbsPath(environmentPath(""))
+	if selector != "" {
+		request = request.Param("labelSelector", selector)
+	}
+	data, err := request.Do(ctx).Raw()
+	if err != nil {
+		if isMissing(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list %ss: %w", EnvironmentKind, err)
+	}
+
+	var list struct {
+		Items []environment `json:"items"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse %s list: %w", EnvironmentKind, err)
+	}
+	return list.Items, nil
+}
+
+// listEnvironments returns the names of the KrazeEnvironments in the cluster,
+// leaving out the parts of split states
+func listEnvironments(ctx context.Context, clientset kubernetes.Interface) ([]string, error) {
+	client := restClient(clientset)
+	if client == nil {
+		return nil, nil
+	}
+	items, err := listEnvironmentItems(ctx, client, "")
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		if _, isShard := item.Metadata.Labels[ShardLabel]; !isShard {
+			names = append(names, item.Metadata.Name)
+		}
+	}
+	return names, nil
+}
+
+// ensureEnvironmentCRD installs the KrazeEnvironment CRD unless the cluster
+// has it, and waits until the API server serves it
+func ensureEnvironmentCRD(ctx context.Context, client rest.Interface) error {
+	crdPath := "/apis/apiextensions.k8s.io/v1/customresourcedefinitions"
+	_, err := client.Get().AbsPath(crdPath, environmentCRDName).Do(ctx).Raw()
+	if err == nil {
+		return nil
+	}
+	if !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to read the %s CRD: %w", EnvironmentKind, err)
+	}
+
+	body, err := json.Marshal(environmentCRD())
+	if err != nil {
+		return fmt.Errorf("failed to marshal the %s CRD: %w", EnvironmentKind, err)
+	}
+	err = client.Post().AbsPath(crdPath).SetHeader("Content-Type", "application/json").Body(body).Do(ctx).Error()
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to install the %s CRD: %w", EnvironmentKind, err)
+	}
+
+	err = wait.PollUntilContextTimeout(ctx, 500*time.Millisecond, crdEstablishTimeout, true, func(ctx context.Context) (bool, error) {
+		data, err := client.Get().AbsPath(crdPath, environmentCRDName).Do(ctx).Raw()
+		if err != nil {
+			return false, nil
+		}
+		return crdEstablished(data), nil
+	})
+	if err != nil {
+		return fmt.Errorf("the %s CRD was not established: %w", EnvironmentKind, err)
+	}
+	return nil
+}
+
+// crdEstablished returns whether a CustomResourceDefinition's Established condition is true
+func crdEstablished(data []byte) bool {
+	var crd struct {
+		Status struct {
+			Conditions []struct {
+				Type   string `json:"type"`
+				Status string `json:"status"`
+			} `json:"conditions"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(data, &crd); err != nil {
+		return false
+	}
+	for _, condition := range crd.Status.Conditions {
+		if condition.Type == "Established" && condition.Status == "True" {
+			return true
+		}
+	}
+	return false
+}
+
+// environmentCRD returns the KrazeEnvironment CustomResourceDefinition
+func environmentCRD() map[string]interface{} {
+	stringArray := map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}}
+	return map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata": map[string]interface{}{
+			"name":   environmentCRDName,
+			"labels": map[string]interface{}{"app.kubernetes.io/managed-by": "kraze"},
+		},
+		"spec": map[string]interface{}{
+			"group": EnvironmentGroup,
+			"scope": "Cluster",
+			"names": map[string]interface{}{
+				"plural":     environmentResource,
+				"singular":   "krazeenvironment",
+				"kind":       EnvironmentKind,
+				"listKind":   EnvironmentKind + "List",
+				"shortNames": []string{"kenv"},
+			},
+			"versions": []interface{}{
+				map[string]interface{}{
+					"name":         EnvironmentVersion,
+					"served":       true,
+					"storage":      true,
+					"subresources": map[string]interface{}{"status": map[string]interface{}{}},
+					"additionalPrinterColumns": []interface{}{
+						map[string]interface{}{"name": "Cluster", "type": "string", "jsonPath": ".spec.cluster"},
+						map[string]interface{}{"name": "Services", "type": "integer", "jsonPath": ".status.installed_services"},
+						map[string]interface{}{"name": "Updated", "type": "date", "jsonPath": ".status.last_updated"},
+					},
+					"schema": map[string]interface{}{
+						"openAPIV3Schema": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"spec": map[string]interface{}{
+									"type": "object",
+									"properties": map[string]interface{}{
+										"cluster":      map[string]interface{}{"type": "string"},
+										"suffix":       map[string]interface{}{"type": "string"},
+										"config_paths": stringArray,
+									},
+								},
+								"status": map[string]interface{}{
+									"type": "object",
+									"properties": map[string]interface{}{
+										"cluster_name":       map[string]interface{}{"type": "string"},
+										"installed_services": map[string]interface{}{"type": "integer"},
+										"last_updated":       map[string]interface{}{"type": "string", "format": "date-time"},
+										"encoding":           map[string]interface{}{"type": "string"},
+										"shards":             map[string]interface{}{"type": "integer"},
+										"checksum":           map[string]interface{}{"type": "string"},
+										"state":              map[string]interface{}{"type": "string"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,297 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// apiServer is an in-memory API server for the raw requests of the crd
+// backend and the ConfigMap requests of the clientset
+type apiServer struct {
+	mutex    sync.Mutex
+	objects  map[string]map[string]interface{}
+	version  int
+	requests []string // Method and path of every request
+}
+
+func (server *apiServer) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	writer.Header().Set("Content-Type", "application/json")
+	path := request.URL.Path
+	server.requests = append(server.requests, request.Method+" "+path)
+	var body map[string]interface{}
+	if data, _ := io.ReadAll(request.Body); len(data) > 0 {
+		if err := json.Unmarshal(data, &body); err != nil {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	switch request.Method {
+	case http.MethodGet:
+		if object, exists := server.objects[path]; exists {
+			json.NewEncoder(writer).Encode(object)
+			return
+		}
+		if strings.HasSuffix(path, "s") {
+			server.list(writer, path, request.URL.Query().Get("labelSelector"))
+			return
+		}
+		server.notFound(writer)
+	case http.MethodPost:
+		metadata := body["metadata"].(map[string]interface{})
+		if strings.HasSuffix(path, "customresourcedefinitions") {
+			body["status"] = map[string]interface{}{"conditions": []interface{}{map[string]interface{}{"type": "Established", "status": "True"}}}
+		}
+		if strings.HasSuffix(path, environmentResource) {
+			delete(body, "status")
+		}
+		server.store(path+"/"+metadata["name"].(string), body)
+		writer.WriteHeader(http.StatusCreated)
+		json.NewEncoder(writer).Encode(body)
+	case http.MethodPut:
+		if base, found := strings.CutSuffix(path, "/status"); found {
+			server.objects[base]["status"] = body["status"]
+			server.store(base, server.objects[base])
+			json.NewEncoder(writer).Encode(server.objects[base])
+			return
+		}
+		if existing, exists := server.objects[path]; exists && existing["status"] != nil {
+			body["status"] = existing["status"]
+		}
+		server.store(path, body)
+		json.NewEncoder(writer).Encode(body)
+	case http.MethodDelete:
+		if _, exists := server.objects[path]; !exists {
+			server.notFound(writer)
+			return
+		}
+		delete(server.objects, path)
+		writer.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Success"}`))
+	}
+}
+
+// store saves an object with a new resource version
+func (server *apiServer) store(path string, object map[string]interface{}) {
+	server.version++
+	object["metadata"].(map[string]interface{})["resourceVersion"] = strconv.Itoa(server.version)
+	server.objects[path] = object
+}
+
+// list writes the objects under a collection path that have a label
+func (server *apiServer) list(writer http.ResponseWriter, path, selector string) {
+	key, value, _ := strings.Cut(selector, "=")
+	items := []interface{}{}
+	for objectPath, object := range server.objects {
+		rest, found := strings.CutPrefix(objectPath, path+"/")
+		if !found || strings.Contains(rest, "/") {
+			continue
+		}
+		labels, _ := object["metadata"].(map[string]interface{})["labels"].(map[string]interface{})
+		if key != "" && labels[key] != value {
+			continue
+		}
+		items = append(items, object)
+	}
+	json.NewEncoder(writer).Encode(map[string]interface{}{"items": items})
+}
+
+func (server *apiServer) notFound(writer http.ResponseWriter) {
+	writer.WriteHeader(http.StatusNotFound)
+	writer.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`))
+}
+
+// newAPIServer starts an in-memory API server and returns a clientset for it
+func newAPIServer(t *testing.T) (*apiServer, kubernetes.Interface) {
+	t.Helper()
+	server := &apiServer{objects: make(map[string]map[string]interface{})}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: httpServer.URL, ContentConfig: rest.ContentConfig{ContentType: "application/json"}})
+	if err != nil {
+		t.Fatalf("Failed to create clientset: %v", err)
+	}
+	return server, clientset
+}
+
+func TestCRDBackend(t *testing.T) {
+	ctx := context.Background()
+	server, clientset := newAPIServer(t)
+	UseVariant("")
+	t.Cleanup(func() { UseVariant("") })
+
+	cs := New("test-cluster", false, false, 0, false, 0)
+	cs.MarkServiceInstalledWithNamespace("redis", "data", true)
+	cs.SetConfigPaths([]string{"/src/kraze.yml"})
+	cs.UseBackend(BackendCRD)
+	if err := cs.Save(ctx, clientset); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	if _, exists := server.objects["/apis/apiextensions.k8s.io/v1/customresourcedefinitions/"+environmentCRDName]; !exists {
+		t.Error("Expected the KrazeEnvironment CRD to be installed")
+	}
+	object, exists := server.objects[environmentPath(defaultEnvironment)]
+	if !exists {
+		t.Fatal("Expected the default KrazeEnvironment to be created")
+	}
+	if spec := object["spec"].(map[string]interface{}); spec["cluster"] != "test-cluster" {
+		t.Errorf("Expected spec.cluster test-cluster, got %v", spec["cluster"])
+	}
+	status := object["status"].(map[string]interface{})
+	if status["installed_services"] != float64(1) {
+		t.Errorf("Expected status.installed_services 1, got %v", status["installed_services"])
+	}
+	if status["encoding"] != encodingGzip || status["services"] != nil {
+		t.Errorf("Expected the state compressed in the status, got %v", status)
+	}
+	if !strings.HasPrefix(environmentPath(""), "/apis/kraze.dev/") {
+		t.Errorf("environmentPath() = %s, want the kraze.dev group", environmentPath(""))
+	}
+
+	loaded, err := Load(ctx, clientset, "test-cluster", BackendCRD)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if loaded.Backend() != BackendCRD || !loaded.IsServiceInstalled("redis") || !loaded.IsNamespaceCreated("data") {
+		t.Errorf("Expected the state to load from the crd backend, got backend %s and services %v", loaded.Backend(), loaded.Services)
+	}
+
+	// Switching back moves the state into a ConfigMap
+	loaded.UseBackend(BackendConfigMap)
+	if err := loaded.Save(ctx, clientset); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	if _, exists := server.objects[environmentPath(defaultEnvironment)]; exists {
+		t.Error("Expected the KrazeEnvironment to be removed after moving to a ConfigMap")
+	}
+	if _, exists := server.objects["/api/v1/namespaces/kube-system/configmaps/"+ConfigMapName]; !exists {
+		t.Error("Expected the state ConfigMap to be created")
+	}
+	moved, err := Load(ctx, clientset, "test-cluster", "")
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if moved.Backend() != BackendConfigMap || !moved.IsServiceInstalled("redis") {
+		t.Errorf("Expected the state to load from the configmap backend, got %s", moved.Backend())
+	}
+}
+
+func TestCRDBackendVariants(t *testing.T) {
+	ctx := context.Background()
+	_, clientset := newAPIServer(t)
+	t.Cleanup(func() { UseVariant("") })
+
+	for _, suffix := range []string{"pr-1", "pr-2"} {
+		UseVariant(suffix)
+		cs := New("test-cluster", false, false, 0, false, 0)
+		cs.UseBackend(BackendCRD)
+		if err := cs.Save(ctx, clientset); err != nil {
+			t.Fatalf("Failed to save state: %v", err)
+		}
+	}
+	UseVariant("pr-3")
+	if err := New("test-cluster", false, false, 0, false, 0).Save(ctx, clientset); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	variants, err := ListVariants(ctx, clientset)
+	if err != nil {
+		t.Fatalf("ListVariants() error = %v", err)
+	}
+	if strings.Join(variants, ",") != "pr-1,pr-2,pr-3" {
+		t.Errorf("ListVariants() = %v, want [pr-1 pr-2 pr-3]", variants)
+	}
+
+	UseVariant("pr-1")
+	if err := Delete(ctx, clientset); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if loaded, err := Load(ctx, clientset, "test-cluster", ""); err != nil || loaded != nil {
+		t.Errorf("Load() after Delete() = %v, %v, want no state", loaded, err)
+	}
+}
+
+func TestCRDBackendSharded(t *testing.T) {
+	ctx := context.Background()
+	server, clientset := newAPIServer(t)
+	UseVariant("")
+	t.Cleanup(func() { UseVariant("") })
+
+	cs := largeState()
+	cs.UseBackend(BackendCRD)
+	if err := cs.Save(ctx, clientset); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	_, shards := cs.StoredSize()
+	if shards < 2 {
+		t.Fatalf("Expected the state to be split across KrazeEnvironments, got %d", shards)
+	}
+	shard, exists := server.objects[environmentPath(environmentShardName(1))]
+	if !exists {
+		t.Fatal("Expected a shard KrazeEnvironment to be created")
+	}
+	if labels := shard["metadata"].(map[string]interface{})["labels"].(map[string]interface{}); labels[ShardLabel] != defaultEnvironment {
+		t.Errorf("Expected shard label %s=%s, got %v", ShardLabel, defaultEnvironment, labels)
+	}
+	if variants, err := ListVariants(ctx, clientset); err != nil || len(variants) != 0 {
+		t.Errorf("ListVariants() = %v, %v, want no variants", variants, err)
+	}
+
+	loaded, err := Load(ctx, clientset, "test-cluster", BackendCRD)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if !reflect.DeepEqual(loaded.GetAppliedResources("svc-7"), cs.GetAppliedResources("svc-7")) {
+		t.Error("Expected applied resources to round-trip through the shards")
+	}
+
+	// A shard from another save is refused rather than joined
+	shard["status"].(map[string]interface{})["checksum"] = "stale"
+	if _, err := Load(ctx, clientset, "test-cluster", BackendCRD); err == nil || !strings.Contains(err.Error(), "another save") {
+		t.Errorf("Load() error = %v, want the stale shard refused", err)
+	}
+
+	if err := Delete(ctx, clientset); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	for path := range server.objects {
+		if strings.HasPrefix(path, environmentPath("")+"/") {
+			t.Errorf("Expected %s to be deleted with the state", path)
+		}
+	}
+}
+
+func TestLoadSkipsCRDWithConfigMapBackend(t *testing.T) {
+	ctx := context.Background()
+	server, clientset := newAPIServer(t)
+	UseVariant("")
+	t.Cleanup(func() { UseVariant("") })
+
+	if err := New("test-cluster", false, false, 0, false, 0).Save(ctx, clientset); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	server.requests = nil
+	if loaded, err := Load(ctx, clientset, "test-cluster", BackendConfigMap); err != nil || loaded == nil {
+		t.Fatalf("Load() = %v, %v, want the ConfigMap state", loaded, err)
+	}
+	for _, request := range server.requests {
+		if strings.Contains(request, EnvironmentGroup) {
+			t.Errorf("Expected no %s request with the configmap backend, got %s", EnvironmentKind, request)
+		}
+	}
+}
@@ -1,15 +1,26 @@
 package state
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
 )
 
 const (
@@ -19,13 +30,74 @@ const (
 	// ConfigMapNamespace is the namespace where kraze metadata is stored
 	ConfigMapNamespace = "kube-system"
 
-	// ConfigMapDataKey is the key in the ConfigMap data field
+	// ConfigMapDataKey is the key in the ConfigMap data field. It holds plain
+	// JSON state, or only the version of state stored under ConfigMapStateKey,
+	// so older kraze refuses that state instead of failing to parse it.
 	ConfigMapDataKey = "metadata"
 
+	// ConfigMapStateKey is the key of the encoded state in the ConfigMap data field
+	ConfigMapStateKey = "state"
+
 	// CurrentStateVersion is the current version of the state format
-	CurrentStateVersion = 3
+	CurrentStateVersion = 4
+
+	// EncodingAnnotation records how the state in the ConfigMap is encoded;
+	// state without it is plain JSON
+	EncodingAnnotation = "kraze.dev/state-encoding"
+
+	// ShardsAnnotation is the number of ConfigMaps the state is split across
+	ShardsAnnotation = "kraze.dev/state-shards"
+
+	// ChecksumAnnotation is the SHA-256 of the whole encoded state, set on the
+	// state's ConfigMap and on each of its shards, so a load never joins shards
+	// from different saves
+	ChecksumAnnotation = "kraze.dev/state-checksum"
+
+	// ShardLabel marks the extra ConfigMaps (or KrazeEnvironments) of a split
+	// state with the name of the state's ConfigMap (or KrazeEnvironment)
+	ShardLabel = "kraze.dev/state-shard"
+
+	// encodingGzip is gzip-compressed JSON, base64-encoded
+	encodingGzip = "gzip+base64"
+
+	// maxShardBytes is how much of the encoded state one ConfigMap holds,
+	// leaving room for its metadata under the 1MiB object size limit
+	maxShardBytes = 900 * 1024
+
+	// warnStateBytes is the encoded size above which the state's size is
+	// worth a warning
+	warnStateBytes = 3 * maxShardBytes / 4
+)
+
+// Where the state is stored (the state_backend setting)
+const (
+	BackendConfigMap = "configmap" // ConfigMap in kube-system (default)
+	BackendCRD       = "crd"       // KrazeEnvironment custom resource
 )
 
+// configMapName is the ConfigMap the state is read from and written to
+var configMapName = ConfigMapName
+
+// variantSuffix is the --suffix of the environment variant being run
+var variantSuffix string
+
+// UseVariant keeps the state of an environment variant (kraze --suffix) in its
+// own ConfigMap, so variants sharing a cluster don't see each other's services
+func UseVariant(suffix string) {
+	variantSuffix = suffix
+	if suffix == "" {
+		configMapName = ConfigMapName
+		return
+	}
+	configMapName = ConfigMapName + "-" + suffix
+}
+
+// Name returns the name of the state of the environment being run, which
+// tells variants sharing a cluster apart
+func Name() string {
+	return configMapName
+}
+
 // ClusterState represents the state of deployed services stored in the cluster
 type ClusterState struct {
 	Version          int                        `json:"version"` // State format version
@@ -36,8 +108,15 @@ type ClusterState struct {
 	AMDGPUEnabled    bool                       `json:"amd_gpu_enabled,omitempty"`    // Whether cluster was created with AMD GPU support
 	AMDGPUCount      int                        `json:"amd_gpu_count,omitempty"`      // Number of AMD GPUs configured at creation
 	ConfigPaths      []string                   `json:"config_paths,omitempty"`       // Absolute paths to config files used with this cluster
+	ClusterChecksum  string                     `json:"cluster_checksum,omitempty"`   // Checksum of the cluster config the cluster was created from
 	Services         map[string]ServiceMetadata `json:"services"`
+	InterruptedRun   *InterruptedRun            `json:"interrupted_run,omitempty"` // The last 'kraze up' when it was interrupted, for --resume
 	LastUpdated      time.Time                  `json:"last_updated"`
+
+	storedBytes  int      // Encoded size of the state when last loaded or saved
+	storedShards int      // ConfigMaps the state was split across
+	backend      string   // Backend Save writes to
+	storedIn     []string // Backends holding a copy of the state, which Save removes from the others
 }
 
 // ServiceMetadata represents the metadata for a single service
@@ -48,6 +127,31 @@ type ServiceMetadata struct {
 	Namespace        string            `json:"namespace,omitempty"`         // The namespace this service is in
 	CreatedNamespace bool              `json:"created_namespace,omitempty"` // Whether we created the namespace
 	ImageHashes      map[string]string `json:"image_hashes,omitempty"`      // Map of image name to SHA256 hash
+	OwnedSelectors   []string          `json:"owned_selectors,omitempty"`   // Label selectors for kraze-owned resources in a pre-existing namespace
+	InstallSeconds   float64           `json:"install_seconds,omitempty"`   // How long the last install took, including waiting for readiness
+	ImageLoadBytes   int64             `json:"image_load_bytes,omitempty"`  // Size of the images the last install loaded into the cluster
+	AppliedResources []string          `json:"applied_resources,omitempty"` // Resources the last install applied (manifests services), for pruning
+	Assertions       []AssertionResult `json:"assertions,omitempty"`        // Outcome of the service's assertions when last checked
+	InstallReason    string            `json:"install_reason,omitempty"`    // Why the last install included the service (requested, dependency of X, label match)
+	Fixtures         map[string]string `json:"fixtures,omitempty"`          // Checksum of each fixture when it last loaded successfully
+	Partial          bool              `json:"partial,omitempty"`           // The last install was interrupted before it finished
+}
+
+// InterruptedRun records a 'kraze up' that was interrupted, so --resume can
+// continue it with the same selection of services
+type InterruptedRun struct {
+	Services      []string  `json:"services,omitempty"` // Services named on the command line
+	Labels        []string  `json:"labels,omitempty"`   // --label filters
+	NoDeps        bool      `json:"no_deps,omitempty"`  // Whether --no-deps was set
+	Pending       []string  `json:"pending"`            // Services the run didn't finish installing
+	InterruptedAt time.Time `json:"interrupted_at"`
+}
+
+// AssertionResult is the recorded outcome of one expectation of a service's assertion
+type AssertionResult struct {
+	Assertion string `json:"assertion"`
+	Passed    bool   `json:"passed"`
+	Message   string `json:"message,omitempty"`
 }
 
 // New creates a new empty cluster state
@@ -62,13 +166,49 @@ func New(clusterName string, isExternal bool, nvidiaGPUEnabled bool, nvidiaGPUCo
 		AMDGPUCount:      amdGPUCount,
 		Services:         make(map[string]ServiceMetadata),
 		LastUpdated:      time.Now(),
+		backend:          BackendConfigMap,
+	}
+}
+
+// Load reads the cluster state from whichever backend holds it, the newer copy
+// when a change of backend left it in both. Returns nil when there is none.
+// backend is the configured state_backend: the KrazeEnvironment is only read
+// with crd, or when no ConfigMap holds the state, as when the environment
+// moved back from the crd backend or its backend isn't known.
+func Load(ctx context.Context, clientset kubernetes.Interface, clusterName, backend string) (*ClusterState, error) {
+	state, err := loadConfigMap(ctx, clientset)
+	if err != nil {
+		return nil, err
+	}
+	var environment *ClusterState
+	if backend == BackendCRD || state == nil {
+		if environment, err = loadEnvironment(ctx, clientset); err != nil {
+			return nil, err
+		}
 	}
+
+	var storedIn []string
+	if state != nil {
+		storedIn = append(storedIn, BackendConfigMap)
+	}
+	if environment != nil {
+		storedIn = append(storedIn, BackendCRD)
+		if state == nil || environment.LastUpdated.After(state.LastUpdated) {
+			state = environment
+		}
+	}
+	if state == nil {
+		return nil, nil
+	}
+	state.storedIn = storedIn
+	return state, nil
 }
 
-// Load reads the cluster state from a ConfigMap in the cluster
-func Load(ctx context.Context, clientset kubernetes.Interface, clusterName string) (*ClusterState, error) {
+// loadConfigMap reads the cluster state from a ConfigMap, and from the
+// ConfigMaps it was split across when it outgrew one
+func loadConfigMap(ctx context.Context, clientset kubernetes.Interface) (*ClusterState, error) {
 	// Try to get the ConfigMap
-	cm, err := clientset.CoreV1().ConfigMaps(ConfigMapNamespace).Get(ctx, ConfigMapName, metav1.GetOptions{})
+	cm, err := clientset.CoreV1().ConfigMaps(ConfigMapNamespace).Get(ctx, configMapName, metav1.GetOptions{})
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// ConfigMap doesn't exist yet, return nil (caller will create new state)
@@ -79,17 +219,47 @@ func Load(ctx context.Context, clientset kubernetes.Interface, clusterName strin
 	}
 
 	// Get the metadata from the ConfigMap
-	metadataJSON, exists := cm.Data[ConfigMapDataKey]
+	metadata, exists := stateData(cm)
 	if !exists {
 		// ConfigMap exists but has no data, return empty state
 		return nil, nil
 	}
 
+	shards := 1
+	if value := cm.Annotations[ShardsAnnotation]; value != "" {
+		if shards, err = strconv.Atoi(value); err != nil || shards < 1 {
+			return nil, fmt.Errorf("invalid %s annotation '%s' on cluster state ConfigMap", ShardsAnnotation, value)
+		}
+	}
+	checksum := cm.Annotations[ChecksumAnnotation]
+	chunks := []string{metadata}
+	for index := 1; index < shards; index++ {
+		shard, err := clientset.CoreV1().ConfigMaps(ConfigMapNamespace).Get(ctx, shardName(index), metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cluster state ConfigMap %s: %w", shardName(index), err)
+		}
+		if shard.Annotations[ChecksumAnnotation] != checksum {
+			return nil, fmt.Errorf("cluster state ConfigMap %s is from another save than %s (the state changed while it was read); run the command again", shardName(index), configMapName)
+		}
+		chunk, _ := stateData(shard)
+		chunks = append(chunks, chunk)
+	}
+	encoded := strings.Join(chunks, "")
+	if checksum != "" && stateChecksum(encoded) != checksum {
+		return nil, fmt.Errorf("cluster state in %s doesn't match its %s annotation", configMapName, ChecksumAnnotation)
+	}
+
+	metadataJSON, err := decodeState(encoded, cm.Annotations[EncodingAnnotation])
+	if err != nil {
+		return nil, err
+	}
+
 	// Unmarshal the JSON
 	var state ClusterState
-	if err := json.Unmarshal([]byte(metadataJSON), &state); err != nil {
+	if err := json.Unmarshal(metadataJSON, &state); err != nil {
 		return nil, fmt.Errorf("failed to parse cluster state: %w", err)
 	}
+	state.storedBytes, state.storedShards, state.backend = len(encoded), shards, BackendConfigMap
 
 	// Handle migration from older versions
 	if err := state.migrate(); err != nil {
@@ -99,6 +269,74 @@ func Load(ctx context.Context, clientset kubernetes.Interface, clusterName strin
 	return &state, nil
 }
 
+// stateData returns the state a ConfigMap holds: encoded under
+// ConfigMapStateKey, or as plain JSON under ConfigMapDataKey
+func stateData(cm *corev1.ConfigMap) (string, bool) {
+	if data, exists := cm.Data[ConfigMapStateKey]; exists {
+		return data, true
+	}
+	data, exists := cm.Data[ConfigMapDataKey]
+	return data, exists
+}
+
+// shardName returns the name of the ConfigMap holding part index of a split
+// state. The dot keeps it apart from the state of a --suffix variant, whose
+// suffix can't contain one.
+func shardName(index int) string {
+	return fmt.Sprintf("%s.shard-%d", configMapName, index)
+}
+
+// stateChecksum returns the SHA-256 of an encoded state, hex-encoded
+func stateChecksum(encoded string) string {
+	sum := sha256.Sum256([]byte(encoded))
+	return hex.EncodeToString(sum[:])
+}
+
+// encodeState compresses state JSON and splits it into chunks that each fit a ConfigMap
+func encodeState(data []byte) ([]string, error) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress cluster state: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress cluster state: %w", err)
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(compressed.Bytes())
+	var chunks []string
+	for len(encoded) > maxShardBytes {
+		chunks = append(chunks, encoded[:maxShardBytes])
+		encoded = encoded[maxShardBytes:]
+	}
+	return append(chunks, encoded), nil
+}
+
+// decodeState returns the JSON of state stored with an encoding
+func decodeState(encoded, encoding string) ([]byte, error) {
+	switch encoding {
+	case "":
+		return []byte(encoded), nil
+	case encodingGzip:
+		compressed, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode cluster state: %w", err)
+		}
+		reader, err := gzip.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress cluster state: %w", err)
+		}
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress cluster state: %w", err)
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("cluster state encoding '%s' is not supported - please upgrade kraze", encoding)
+	}
+}
+
 // migrate handles migration from older state versions to the current version
 func (cs *ClusterState) migrate() error {
 	// Migrate from v0 (no version field) to v1
@@ -123,6 +361,13 @@ func (cs *ClusterState) migrate() error {
 		cs.Version = 3
 	}
 
+	// Migrate from v3 to v4
+	if cs.Version == 3 {
+		// v3 state was plain JSON; v4 state is encoded and may be split across
+		// ConfigMaps, which the load already undid. The fields are unchanged.
+		cs.Version = 4
+	}
+
 	// Check if version is supported
 	if cs.Version > CurrentStateVersion {
 		return fmt.Errorf("cluster state version %d is newer than supported version %d - please upgrade kraze",
@@ -132,57 +377,206 @@ func (cs *ClusterState) migrate() error {
 	return nil
 }
 
-// Save writes the cluster state to a ConfigMap in the cluster
+// Save writes the cluster state to its backend, then removes the copies a
+// change of backend left in the other one
 func (cs *ClusterState) Save(ctx context.Context, clientset kubernetes.Interface) error {
 	// Ensure version is set to current version
 	cs.Version = CurrentStateVersion
 	cs.LastUpdated = time.Now()
 
+	backend := cs.Backend()
+	var err error
+	if backend == BackendCRD {
+		err = cs.saveEnvironment(ctx, clientset)
+	} else {
+		err = cs.saveConfigMap(ctx, clientset)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, stored := range cs.storedIn {
+		switch {
+		case stored == backend:
+		case stored == BackendCRD:
+			err = deleteEnvironment(ctx, clientset)
+		default:
+			err = deleteConfigMaps(ctx, clientset)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to remove the cluster state from its previous backend: %w", err)
+		}
+	}
+	cs.storedIn = []string{backend}
+	return nil
+}
+
+// UseBackend makes Save write the state to a backend (configmap or crd),
+// moving it there when it's stored in the other one
+func (cs *ClusterState) UseBackend(backend string) {
+	if backend == "" {
+		backend = BackendConfigMap
+	}
+	cs.backend = backend
+}
+
+// Backend returns the backend the state is saved to
+func (cs *ClusterState) Backend() string {
+	if cs.backend == "" {
+		return BackendConfigMap
+	}
+	return cs.backend
+}
+
+// saveConfigMap writes the cluster state to a ConfigMap in the cluster,
+// compressed. State that outgrows one ConfigMap is split across extra
+// ConfigMaps, which are written before the main one that says how many there are.
+func (cs *ClusterState) saveConfigMap(ctx context.Context, clientset kubernetes.Interface) error {
 	// Marshal to JSON
-	data, err := json.MarshalIndent(cs, "", "  ")
+	data, err := json.Marshal(cs)
 	if err != nil {
 		return fmt.Errorf("failed to marshal cluster state: %w", err)
 	}
+	chunks, err := encodeState(data)
+	if err != nil {
+		return err
+	}
+
+	// Another command may create or update the ConfigMap between reading and
+	// writing it, so the write is retried against the latest version
+	isRetryable := func(err error) bool {
+		return errors.IsConflict(err) || errors.IsAlreadyExists(err)
+	}
+	checksum := stateChecksum(strings.Join(chunks, ""))
+	for index := 1; index < len(chunks); index++ {
+		err := retry.OnError(retry.DefaultRetry, isRetryable, func() error {
+			_, err := writeConfigMap(ctx, clientset, shardName(index), chunks[index], map[string]string{ShardLabel: configMapName}, map[string]string{ChecksumAnnotation: checksum})
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
 
+	annotations := map[string]string{
+		EncodingAnnotation: encodingGzip,
+		ShardsAnnotation:   strconv.Itoa(len(chunks)),
+		ChecksumAnnotation: checksum,
+	}
+	var previousShards int
+	err = retry.OnError(retry.DefaultRetry, isRetryable, func() error {
+		previousShards, err = writeConfigMap(ctx, clientset, configMapName, chunks[0], nil, annotations)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// Parts left over from a larger state are no longer read
+	for index := len(chunks); index < previousShards; index++ {
+		err := clientset.CoreV1().ConfigMaps(ConfigMapNamespace).Delete(ctx, shardName(index), metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete cluster state ConfigMap %s: %w", shardName(index), err)
+		}
+	}
+
+	cs.storedBytes, cs.storedShards = 0, len(chunks)
+	for _, chunk := range chunks {
+		cs.storedBytes += len(chunk)
+	}
+	return nil
+}
+
+// writeConfigMap creates or updates a cluster state ConfigMap with one part of
+// the encoded state. Returns the number of parts the ConfigMap said the state
+// had before (1 when it didn't say).
+func writeConfigMap(ctx context.Context, clientset kubernetes.Interface, name, data string, labels, annotations map[string]string) (int, error) {
 	// Try to get existing ConfigMap
-	cm, err := clientset.CoreV1().ConfigMaps(ConfigMapNamespace).Get(ctx, ConfigMapName, metav1.GetOptions{})
+	cm, err := clientset.CoreV1().ConfigMaps(ConfigMapNamespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// ConfigMap doesn't exist, create it
 			cm = &corev1.ConfigMap{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      ConfigMapName,
+					Name:      name,
 					Namespace: ConfigMapNamespace,
 					Labels: map[string]string{
 						"app.kubernetes.io/managed-by": "kraze",
 					},
+					Annotations: annotations,
 				},
 				Data: map[string]string{
-					ConfigMapDataKey: string(data),
+					ConfigMapDataKey:  versionStub(),
+					ConfigMapStateKey: data,
 				},
 			}
+			for key, value := range labels {
+				cm.Labels[key] = value
+			}
 			_, err = clientset.CoreV1().ConfigMaps(ConfigMapNamespace).Create(ctx, cm, metav1.CreateOptions{})
 			if err != nil {
-				return fmt.Errorf("failed to create cluster state ConfigMap: %w", err)
+				return 0, fmt.Errorf("failed to create cluster state ConfigMap: %w", err)
 			}
-			return nil
+			return 0, nil
 		}
-		return fmt.Errorf("failed to get cluster state ConfigMap: %w", err)
+		return 0, fmt.Errorf("failed to get cluster state ConfigMap: %w", err)
+	}
+
+	previousShards := 1
+	if value, err := strconv.Atoi(cm.Annotations[ShardsAnnotation]); err == nil {
+		previousShards = value
 	}
 
 	// ConfigMap exists, update it
-	cm.Data[ConfigMapDataKey] = string(data)
+	if cm.Data == nil {
+		cm.Data = make(map[string]string)
+	}
+	cm.Data[ConfigMapDataKey] = versionStub()
+	cm.Data[ConfigMapStateKey] = data
+	if len(annotations) > 0 && cm.Annotations == nil {
+		cm.Annotations = make(map[string]string)
+	}
+	for key, value := range annotations {
+		cm.Annotations[key] = value
+	}
 	_, err = clientset.CoreV1().ConfigMaps(ConfigMapNamespace).Update(ctx, cm, metav1.UpdateOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to update cluster state ConfigMap: %w", err)
+		return 0, fmt.Errorf("failed to update cluster state ConfigMap: %w", err)
 	}
 
-	return nil
+	return previousShards, nil
 }
 
-// Delete removes the cluster state ConfigMap from the cluster
+// versionStub is the plain JSON stored next to encoded state, which older kraze
+// reads as state of a version it doesn't support
+func versionStub() string {
+	return fmt.Sprintf(`{"version":%d}`, CurrentStateVersion)
+}
+
+// Delete removes the cluster state from the cluster, from either backend
 func Delete(ctx context.Context, clientset kubernetes.Interface) error {
-	err := clientset.CoreV1().ConfigMaps(ConfigMapNamespace).Delete(ctx, ConfigMapName, metav1.DeleteOptions{})
+	if err := deleteConfigMaps(ctx, clientset); err != nil {
+		return err
+	}
+	return deleteEnvironment(ctx, clientset)
+}
+
+// deleteConfigMaps removes the cluster state ConfigMap, with the ConfigMaps a
+// split state uses
+func deleteConfigMaps(ctx context.Context, clientset kubernetes.Interface) error {
+	shards, err := clientset.CoreV1().ConfigMaps(ConfigMapNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: ShardLabel + "=" + configMapName,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list cluster state ConfigMaps: %w", err)
+	}
+	for _, shard := range shards.Items {
+		if err := clientset.CoreV1().ConfigMaps(ConfigMapNamespace).Delete(ctx, shard.Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete cluster state ConfigMap %s: %w", shard.Name, err)
+		}
+	}
+
+	err = clientset.CoreV1().ConfigMaps(ConfigMapNamespace).Delete(ctx, configMapName, metav1.DeleteOptions{})
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// ConfigMap doesn't exist, that's fine
@@ -193,6 +587,60 @@ func Delete(ctx context.Context, clientset kubernetes.Interface) error {
 	return nil
 }
 
+// StoredSize returns the encoded size of the state and the number of
+// ConfigMaps it's split across, as of when it was last loaded or saved
+func (cs *ClusterState) StoredSize() (int, int) {
+	return cs.storedBytes, cs.storedShards
+}
+
+// SizeWarning describes a state that's large enough to slow down every
+// command that reads it, empty when it isn't
+func (cs *ClusterState) SizeWarning() string {
+	if cs.storedBytes <= warnStateBytes {
+		return ""
+	}
+	message := fmt.Sprintf("Cluster state is %d KiB compressed", cs.storedBytes/1024)
+	if cs.storedShards > 1 && cs.Backend() == BackendCRD {
+		message += fmt.Sprintf(" and split across %d %ss", cs.storedShards, EnvironmentKind)
+	} else if cs.storedShards > 1 {
+		message += fmt.Sprintf(" and split across %d ConfigMaps in %s", cs.storedShards, ConfigMapNamespace)
+	}
+	return message + "; every command reads it, so large states slow kraze down"
+}
+
+// ListVariants returns the suffixes of the environment variants (kraze --suffix)
+// with state in the cluster
+func ListVariants(ctx context.Context, clientset kubernetes.Interface) ([]string, error) {
+	cms, err := clientset.CoreV1().ConfigMaps(ConfigMapNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "app.kubernetes.io/managed-by=kraze",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster state ConfigMaps: %w", err)
+	}
+
+	var suffixes []string
+	for _, cm := range cms.Items {
+		if _, isShard := cm.Labels[ShardLabel]; isShard {
+			continue
+		}
+		if suffix, ok := strings.CutPrefix(cm.Name, ConfigMapName+"-"); ok {
+			suffixes = append(suffixes, suffix)
+		}
+	}
+
+	names, err := listEnvironments(ctx, clientset)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if name != defaultEnvironment && !slices.Contains(suffixes, name) {
+			suffixes = append(suffixes, name)
+		}
+	}
+	sort.Strings(suffixes)
+	return suffixes, nil
+}
+
 // MarkServiceInstalled marks a service as installed (basic version)
 func (cs *ClusterState) MarkServiceInstalled(serviceName string) {
 	cs.Services[serviceName] = ServiceMetadata{
@@ -207,8 +655,14 @@ func (cs *ClusterState) MarkServiceInstalledWithNamespace(serviceName, namespace
 	// Preserve existing image hashes if they exist
 	existingMetadata, exists := cs.Services[serviceName]
 	imageHashes := make(map[string]string)
+	var fixtures map[string]string
 	if exists {
 		imageHashes = existingMetadata.ImageHashes
+		fixtures = existingMetadata.Fixtures
+		// The namespace already exists on re-install, but kraze still owns it
+		if existingMetadata.Namespace == namespace && existingMetadata.CreatedNamespace {
+			createdNamespace = true
+		}
 	}
 
 	cs.Services[serviceName] = ServiceMetadata{
@@ -218,6 +672,7 @@ func (cs *ClusterState) MarkServiceInstalledWithNamespace(serviceName, namespace
 		Namespace:        namespace,
 		CreatedNamespace: createdNamespace,
 		ImageHashes:      imageHashes,
+		Fixtures:         fixtures,
 	}
 }
 
@@ -233,6 +688,132 @@ func (cs *ClusterState) MarkServiceInstalledWithImages(serviceName, namespace st
 	}
 }
 
+// MarkServicePartial records a service whose install was interrupted after it
+// started applying resources, keeping it tracked so 'kraze down' removes them
+func (cs *ClusterState) MarkServicePartial(serviceName, namespace string, createdNamespace bool) {
+	if svc, exists := cs.Services[serviceName]; exists && svc.Installed {
+		svc.Partial = true
+		svc.UpdatedAt = time.Now()
+		cs.Services[serviceName] = svc
+		return
+	}
+	cs.MarkServiceInstalledWithNamespace(serviceName, namespace, createdNamespace)
+	svc := cs.Services[serviceName]
+	svc.Partial = true
+	cs.Services[serviceName] = svc
+}
+
+// IsServicePartial returns true if the service's last install was interrupted
+func (cs *ClusterState) IsServicePartial(serviceName string) bool {
+	svc, exists := cs.Services[serviceName]
+	return exists && svc.Partial
+}
+
+// SetInterruptedRun records an interrupted 'kraze up', or clears it when run is nil
+func (cs *ClusterState) SetInterruptedRun(run *InterruptedRun) {
+	cs.InterruptedRun = run
+}
+
+// SetOwnedSelectors records the label selectors identifying a service's resources.
+// Used for namespaces kraze didn't create, where only these resources may be removed.
+func (cs *ClusterState) SetOwnedSelectors(serviceName string, selectors []string) {
+	svc, exists := cs.Services[serviceName]
+	if !exists {
+		return
+	}
+	svc.OwnedSelectors = selectors
+	cs.Services[serviceName] = svc
+}
+
+// SetInstallStats records how long a service's install took and the size of the images it loaded
+func (cs *ClusterState) SetInstallStats(serviceName string, duration time.Duration, imageLoadBytes int64) {
+	svc, exists := cs.Services[serviceName]
+	if !exists {
+		return
+	}
+	svc.InstallSeconds = duration.Seconds()
+	svc.ImageLoadBytes = imageLoadBytes
+	cs.Services[serviceName] = svc
+}
+
+// SetAppliedResources records the resources a service's install applied
+func (cs *ClusterState) SetAppliedResources(serviceName string, resources []string) {
+	svc, exists := cs.Services[serviceName]
+	if !exists {
+		return
+	}
+	svc.AppliedResources = resources
+	cs.Services[serviceName] = svc
+}
+
+// SetAssertions records the outcome of a service's assertions
+func (cs *ClusterState) SetAssertions(serviceName string, results []AssertionResult) {
+	svc, exists := cs.Services[serviceName]
+	if !exists {
+		return
+	}
+	svc.Assertions = results
+	cs.Services[serviceName] = svc
+}
+
+// SetInstallReason records why the last install included a service
+func (cs *ClusterState) SetInstallReason(serviceName, reason string) {
+	svc, exists := cs.Services[serviceName]
+	if !exists {
+		return
+	}
+	svc.InstallReason = reason
+	cs.Services[serviceName] = svc
+}
+
+// SetFixtureChecksum records the checksum of a service's fixture once it loaded
+func (cs *ClusterState) SetFixtureChecksum(serviceName, fixture, checksum string) {
+	svc, exists := cs.Services[serviceName]
+	if !exists {
+		return
+	}
+	if svc.Fixtures == nil {
+		svc.Fixtures = make(map[string]string)
+	}
+	svc.Fixtures[fixture] = checksum
+	cs.Services[serviceName] = svc
+}
+
+// GetFixtureChecksum returns the checksum a service's fixture last loaded with, or "" if it never did
+func (cs *ClusterState) GetFixtureChecksum(serviceName, fixture string) string {
+	if svc, exists := cs.Services[serviceName]; exists {
+		return svc.Fixtures[fixture]
+	}
+	return ""
+}
+
+// GetAppliedResources returns the resources a service's last install applied
+func (cs *ClusterState) GetAppliedResources(serviceName string) []string {
+	if svc, exists := cs.Services[serviceName]; exists {
+		return svc.AppliedResources
+	}
+	return nil
+}
+
+// GetOwnedSelectors returns the recorded ownership label selectors for a service
+func (cs *ClusterState) GetOwnedSelectors(serviceName string) []string {
+	if svc, exists := cs.Services[serviceName]; exists {
+		return svc.OwnedSelectors
+	}
+	return nil
+}
+
+// IsNamespaceCreated returns true if any tracked service recorded creating the namespace.
+// Namespaces without such a record existed before kraze and must never be deleted by it.
+func (cs *ClusterState) IsNamespaceCreated(namespace string) bool {
+	for _, svc := range cs.Services {
+		if svc.CreatedNamespace && svc.Namespace == namespace {
+			return true
+		}
+	}
+	return false
+}
+
 // MarkServiceUninstalled marks a service as uninstalled (removes it from state)
 func (cs *ClusterState) MarkServiceUninstalled(serviceName string) {
 	delete(cs.Services, serviceName)
@@ -377,6 +958,18 @@ func (cs *ClusterState) HasConfigPaths() bool {
 	return len(cs.ConfigPaths) > 0
 }
 
+// SetClusterChecksum records the checksum of the cluster config the cluster was created from
+func (cs *ClusterState) SetClusterChecksum(checksum string) {
+	cs.ClusterChecksum = checksum
+}
+
+// HasClusterDrifted returns true if the cluster was created from a cluster
+// config with another checksum. Clusters created before checksums were
+// recorded never drift.
+func (cs *ClusterState) HasClusterDrifted(checksum string) bool {
+	return cs.ClusterChecksum != "" && cs.ClusterChecksum != checksum
+}
+
 // GetChangedImages compares current image hashes with stored hashes
 // Returns a list of images that are new or have changed
 func (cs *ClusterState) GetChangedImages(serviceName string, currentHashes map[string]string) []string {
@@ -3,6 +3,10 @@ package state
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -128,7 +132,7 @@ func TestSaveAndLoad(t *testing.T) {
 	}
 
 	// Load state
-	loaded, err := Load(ctx, clientset, "test-cluster")
+	loaded, err := Load(ctx, clientset, "test-cluster", "")
 	if err != nil {
 		t.Fatalf("Failed to load state: %v", err)
 	}
@@ -155,7 +159,7 @@ func TestLoadNonexistent(t *testing.T) {
 	clientset := fake.NewSimpleClientset()
 
 	// Load when ConfigMap doesn't exist
-	loaded, err := Load(ctx, clientset, "test-cluster")
+	loaded, err := Load(ctx, clientset, "test-cluster", "")
 	if err != nil {
 		t.Errorf("Expected no error for nonexistent ConfigMap, got %v", err)
 	}
@@ -184,7 +188,7 @@ func TestLoadInvalidJSON(t *testing.T) {
 		t.Fatalf("Failed to create ConfigMap: %v", err)
 	}
 
-	_, err = Load(ctx, clientset, "test-cluster")
+	_, err = Load(ctx, clientset, "test-cluster", "")
 	if err == nil {
 		t.Error("Expected error for invalid JSON, got nil")
 	}
@@ -579,7 +583,7 @@ func TestGPUFieldsPersistence(t *testing.T) {
 		t.Fatalf("Failed to save state: %v", err)
 	}
 
-	loaded, err := Load(ctx, clientset, "gpu-cluster")
+	loaded, err := Load(ctx, clientset, "gpu-cluster", "")
 	if err != nil {
 		t.Fatalf("Failed to load state: %v", err)
 	}
@@ -618,7 +622,7 @@ func TestStatePersistence(t *testing.T) {
 	}
 
 	// Load and verify all fields
-	loaded, err := Load(ctx, clientset, "test-cluster")
+	loaded, err := Load(ctx, clientset, "test-cluster", "")
 	if err != nil {
 		t.Fatalf("Failed to load state: %v", err)
 	}
@@ -678,7 +682,7 @@ func TestMigrationV0ToCurrentVersion(t *testing.T) {
 		t.Fatalf("Failed to create ConfigMap: %v", err)
 	}
 
-	loaded, err := Load(ctx, clientset, "test-cluster")
+	loaded, err := Load(ctx, clientset, "test-cluster", "")
 	if err != nil {
 		t.Fatalf("Failed to load v0 state: %v", err)
 	}
@@ -734,7 +738,7 @@ func TestMigrationV2ToV3(t *testing.T) {
 		t.Fatalf("Failed to create ConfigMap: %v", err)
 	}
 
-	loaded, err := Load(ctx, clientset, "test-cluster")
+	loaded, err := Load(ctx, clientset, "test-cluster", "")
 	if err != nil {
 		t.Fatalf("Failed to load v2 state: %v", err)
 	}
@@ -760,7 +764,7 @@ func TestConfigPathsRoundTrip(t *testing.T) {
 		t.Fatalf("Failed to save state: %v", err)
 	}
 
-	loaded, err := Load(ctx, clientset, "test-cluster")
+	loaded, err := Load(ctx, clientset, "test-cluster", "")
 	if err != nil {
 		t.Fatalf("Failed to load state: %v", err)
 	}
@@ -835,7 +839,7 @@ func TestMigrationV1ToV2(t *testing.T) {
 		t.Fatalf("Failed to create ConfigMap: %v", err)
 	}
 
-	loaded, err := Load(ctx, clientset, "test-cluster")
+	loaded, err := Load(ctx, clientset, "test-cluster", "")
 	if err != nil {
 		t.Fatalf("Failed to load v1 state: %v", err)
 	}
@@ -890,7 +894,7 @@ func TestMigrationVersionTooNew(t *testing.T) {
 		t.Fatalf("Failed to create ConfigMap: %v", err)
 	}
 
-	_, err = Load(ctx, clientset, "test-cluster")
+	_, err = Load(ctx, clientset, "test-cluster", "")
 	if err == nil {
 		t.Error("Expected error for state version newer than supported, got nil")
 	}
@@ -918,7 +922,7 @@ func TestUpdateExistingConfigMap(t *testing.T) {
 	}
 
 	// Load and verify both services
-	loaded, err := Load(ctx, clientset, "test-cluster")
+	loaded, err := Load(ctx, clientset, "test-cluster", "")
 	if err != nil {
 		t.Fatalf("Failed to load updated state: %v", err)
 	}
@@ -931,3 +935,404 @@ func TestUpdateExistingConfigMap(t *testing.T) {
 		t.Error("Expected postgres to be installed after update")
 	}
 }
+
+func TestMarkServiceInstalledWithNamespaceKeepsCreatedNamespace(t *testing.T) {
+	cs := New("test-cluster", false, false, 0, false, 0)
+
+	// First up creates the namespace; the second finds it already existing
+	cs.MarkServiceInstalledWithNamespace("backend", "app", true)
+	cs.MarkServiceInstalledWithNamespace("backend", "app", false)
+
+	if !cs.Services["backend"].CreatedNamespace {
+		t.Error("Expected CreatedNamespace to be preserved across re-installs")
+	}
+
+	// Moving to a different namespace uses the new value
+	cs.MarkServiceInstalledWithNamespace("backend", "shared", false)
+	if cs.Services["backend"].CreatedNamespace {
+		t.Error("Expected CreatedNamespace to be false after moving to a pre-existing namespace")
+	}
+}
+
+func TestIsNamespaceCreated(t *testing.T) {
+	cs := New("test-cluster", false, false, 0, false, 0)
+
+	cs.MarkServiceInstalledWithNamespace("backend", "app", true)
+	cs.MarkServiceInstalledWithNamespace("frontend", "app", false)
+	cs.MarkServiceInstalledWithNamespace("redis", "shared", false)
+
+	if !cs.IsNamespaceCreated("app") {
+		t.Error("Expected 'app' to be created by kraze")
+	}
+	if cs.IsNamespaceCreated("shared") {
+		t.Error("Expected 'shared' to be pre-existing")
+	}
+	if cs.IsNamespaceCreated("unknown") {
+		t.Error("Expected untracked namespace to be treated as pre-existing")
+	}
+}
+
+func TestOwnedSelectors(t *testing.T) {
+	cs := New("test-cluster", false, false, 0, false, 0)
+
+	selectors := []string{"app.kubernetes.io/managed-by=kraze,kraze.service=redis"}
+	cs.SetOwnedSelectors("redis", selectors)
+	if got := cs.GetOwnedSelectors("redis"); got != nil {
+		t.Errorf("Expected no selectors for untracked service, got %v", got)
+	}
+
+	cs.MarkServiceInstalledWithNamespace("redis", "shared", false)
+	cs.SetOwnedSelectors("redis", selectors)
+
+	got := cs.GetOwnedSelectors("redis")
+	if len(got) != 1 || got[0] != selectors[0] {
+		t.Errorf("Expected %v, got %v", selectors, got)
+	}
+
+	cs.MarkServiceUninstalled("redis")
+	if got := cs.GetOwnedSelectors("redis"); got != nil {
+		t.Errorf("Expected no selectors after uninstall, got %v", got)
+	}
+}
+
+func TestSetInstallStats(t *testing.T) {
+	cs := New("test-cluster", false, false, 0, false, 0)
+
+	cs.SetInstallStats("api", time.Minute, 1024)
+	if _, exists := cs.Services["api"]; exists {
+		t.Error("Expected no stats for untracked service")
+	}
+
+	cs.MarkServiceInstalledWithNamespace("api", "app", true)
+	cs.SetInstallStats("api", 90*time.Second, 1024)
+
+	svc := cs.Services["api"]
+	if svc.InstallSeconds != 90 {
+		t.Errorf("Expected 90 install seconds, got %v", svc.InstallSeconds)
+	}
+	if svc.ImageLoadBytes != 1024 {
+		t.Errorf("Expected 1024 image load bytes, got %d", svc.ImageLoadBytes)
+	}
+}
+
+func TestSetAppliedResources(t *testing.T) {
+	cs := New("test-cluster", false, false, 0, false, 0)
+	resources := []string{"apps/v1:Deployment:app:api", "v1:ConfigMap:app:api-config"}
+
+	cs.SetAppliedResources("api", resources)
+	if got := cs.GetAppliedResources("api"); got != nil {
+		t.Errorf("Expected no resources for untracked service, got %v", got)
+	}
+
+	cs.MarkServiceInstalledWithNamespace("api", "app", true)
+	cs.SetAppliedResources("api", resources)
+	if got := cs.GetAppliedResources("api"); len(got) != 2 || got[0] != resources[0] {
+		t.Errorf("Expected %v, got %v", resources, got)
+	}
+
+	// A reinstall records the resources it applied again
+	cs.MarkServiceInstalledWithNamespace("api", "app", true)
+	if got := cs.GetAppliedResources("api"); got != nil {
+		t.Errorf("Expected resources to be reset on reinstall, got %v", got)
+	}
+}
+
+func TestFixtureChecksums(t *testing.T) {
+	cs := New("test-cluster", false, false, 0, false, 0)
+
+	cs.SetFixtureChecksum("db", "users.sql", "abc")
+	if got := cs.GetFixtureChecksum("db", "users.sql"); got != "" {
+		t.Errorf("Expected no checksum for untracked service, got %q", got)
+	}
+
+	cs.MarkServiceInstalledWithNamespace("db", "data", true)
+	cs.SetFixtureChecksum("db", "users.sql", "abc")
+	if got := cs.GetFixtureChecksum("db", "users.sql"); got != "abc" {
+		t.Errorf("Expected checksum abc, got %q", got)
+	}
+
+	// Loaded fixtures are remembered across reinstalls, but not after an uninstall
+	cs.MarkServiceInstalledWithNamespace("db", "data", true)
+	if got := cs.GetFixtureChecksum("db", "users.sql"); got != "abc" {
+		t.Errorf("Expected checksum to survive reinstall, got %q", got)
+	}
+	cs.MarkServiceUninstalled("db")
+	cs.MarkServiceInstalledWithNamespace("db", "data", true)
+	if got := cs.GetFixtureChecksum("db", "users.sql"); got != "" {
+		t.Errorf("Expected checksum to be forgotten after uninstall, got %q", got)
+	}
+}
+
+func TestSaveExistingConfigMapWithoutData(t *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName, Namespace: ConfigMapNamespace},
+	})
+
+	cs := New("test-cluster", false, false, 0, false, 0)
+	cs.MarkServiceInstalled("redis")
+	if err := cs.Save(ctx, clientset); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	loaded, err := Load(ctx, clientset, "test-cluster", "")
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if loaded == nil || !loaded.IsServiceInstalled("redis") {
+		t.Error("Expected redis to be installed")
+	}
+}
+
+func TestUseVariant(t *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset()
+
+	mainState := New("test-cluster", false, false, 0, false, 0)
+	mainState.MarkServiceInstalled("redis")
+	if err := mainState.Save(ctx, clientset); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	UseVariant("pr-123")
+	defer UseVariant("")
+
+	loaded, err := Load(ctx, clientset, "test-cluster", "")
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if loaded != nil {
+		t.Fatalf("Variant loaded the main environment's state: %+v", loaded.Services)
+	}
+
+	variant := New("test-cluster", false, false, 0, false, 0)
+	variant.MarkServiceInstalled("postgres")
+	if err := variant.Save(ctx, clientset); err != nil {
+		t.Fatalf("Failed to save variant state: %v", err)
+	}
+	if _, err := clientset.CoreV1().ConfigMaps(ConfigMapNamespace).Get(ctx, ConfigMapName+"-pr-123", metav1.GetOptions{}); err != nil {
+		t.Fatalf("Variant ConfigMap was not created: %v", err)
+	}
+
+	if err := Delete(ctx, clientset); err != nil {
+		t.Fatalf("Failed to delete variant state: %v", err)
+	}
+	if _, err := clientset.CoreV1().ConfigMaps(ConfigMapNamespace).Get(ctx, ConfigMapName, metav1.GetOptions{}); err != nil {
+		t.Errorf("Deleting the variant state removed the main state: %v", err)
+	}
+}
+
+func TestListVariants(t *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset()
+
+	for _, suffix := range []string{"", "pr-456", "pr-123"} {
+		UseVariant(suffix)
+		if err := New("test-cluster", false, false, 0, false, 0).Save(ctx, clientset); err != nil {
+			t.Fatalf("Failed to save state: %v", err)
+		}
+	}
+	UseVariant("")
+
+	suffixes, err := ListVariants(ctx, clientset)
+	if err != nil {
+		t.Fatalf("ListVariants() error = %v", err)
+	}
+	if !reflect.DeepEqual(suffixes, []string{"pr-123", "pr-456"}) {
+		t.Errorf("ListVariants() = %v, want [pr-123 pr-456]", suffixes)
+	}
+}
+
+func TestHasClusterDrifted(t *testing.T) {
+	cs := New("test-cluster", false, false, 0, false, 0)
+	if cs.HasClusterDrifted("abc") {
+		t.Error("A cluster without a recorded checksum drifted")
+	}
+
+	cs.SetClusterChecksum("abc")
+	if cs.HasClusterDrifted("abc") {
+		t.Error("A cluster with the same checksum drifted")
+	}
+	if !cs.HasClusterDrifted("def") {
+		t.Error("A cluster with another checksum didn't drift")
+	}
+}
+
+func TestSaveCompressed(t *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset()
+
+	cs := New("test-cluster", false, false, 0, false, 0)
+	cs.MarkServiceInstalledWithImages("api", "apps", true, map[string]string{"api:dev": "sha256:abc"})
+	if err := cs.Save(ctx, clientset); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	cm, err := clientset.CoreV1().ConfigMaps(ConfigMapNamespace).Get(ctx, ConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("ConfigMap was not created: %v", err)
+	}
+	if cm.Annotations[EncodingAnnotation] != encodingGzip || cm.Annotations[ShardsAnnotation] != "1" {
+		t.Errorf("Expected gzip encoding in 1 ConfigMap, got annotations %v", cm.Annotations)
+	}
+	if json.Valid([]byte(cm.Data[ConfigMapStateKey])) {
+		t.Error("Expected the stored state not to be plain JSON")
+	}
+	// Older kraze reads only the version, newer than the 3 it supports
+	var stub ClusterState
+	if err := json.Unmarshal([]byte(cm.Data[ConfigMapDataKey]), &stub); err != nil || stub.Version <= 3 {
+		t.Errorf("Expected a plain JSON version above 3 for older kraze, got %q (%v)", cm.Data[ConfigMapDataKey], err)
+	}
+
+	loaded, err := Load(ctx, clientset, "test-cluster", "")
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if hashes := loaded.GetImageHashes("api"); hashes["api:dev"] != "sha256:abc" {
+		t.Errorf("Expected image hash to round-trip, got %v", hashes)
+	}
+	if size, shards := loaded.StoredSize(); size != len(cm.Data[ConfigMapStateKey]) || shards != 1 {
+		t.Errorf("StoredSize() = %d, %d, want %d, 1", size, shards, len(cm.Data[ConfigMapStateKey]))
+	}
+	if warning := loaded.SizeWarning(); warning != "" {
+		t.Errorf("Expected no size warning for a small state, got %q", warning)
+	}
+}
+
+// largeState returns a state too large for one ConfigMap: random resource
+// names barely compress
+func largeState() *ClusterState {
+	random := rand.New(rand.NewSource(1))
+	cs := New("test-cluster", false, false, 0, false, 0)
+	for itr := 0; itr < 40; itr++ {
+		name := fmt.Sprintf("svc-%d", itr)
+		cs.MarkServiceInstalled(name)
+		resources := make([]string, 0, 1000)
+		for resource := 0; resource < 1000; resource++ {
+			resources = append(resources, fmt.Sprintf("v1/ConfigMap/apps/%016x%016x%016x", random.Uint64(), random.Uint64(), random.Uint64()))
+		}
+		cs.SetAppliedResources(name, resources)
+	}
+	return cs
+}
+
+func TestSaveSharded(t *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset()
+
+	cs := largeState()
+	if err := cs.Save(ctx, clientset); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	_, shards := cs.StoredSize()
+	if shards < 2 {
+		t.Fatalf("Expected the state to be split across ConfigMaps, got %d", shards)
+	}
+	if cs.SizeWarning() == "" {
+		t.Error("Expected a size warning for a split state")
+	}
+	shard, err := clientset.CoreV1().ConfigMaps(ConfigMapNamespace).Get(ctx, shardName(1), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Shard ConfigMap was not created: %v", err)
+	}
+	if shard.Labels[ShardLabel] != ConfigMapName {
+		t.Errorf("Expected shard label %s=%s, got %v", ShardLabel, ConfigMapName, shard.Labels)
+	}
+	variants, err := ListVariants(ctx, clientset)
+	if err != nil || len(variants) != 0 {
+		t.Errorf("ListVariants() = %v, %v, want no variants", variants, err)
+	}
+
+	loaded, err := Load(ctx, clientset, "test-cluster", "")
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if !reflect.DeepEqual(loaded.GetAppliedResources("svc-7"), cs.GetAppliedResources("svc-7")) {
+		t.Error("Expected applied resources to round-trip through the shards")
+	}
+
+	// A variant suffixed shard-1 doesn't share its ConfigMap with a shard
+	UseVariant("shard-1")
+	variantName := configMapName
+	UseVariant("")
+	if shardName(1) == variantName {
+		t.Errorf("shardName(1) = %q collides with the state of --suffix shard-1", shardName(1))
+	}
+
+	// A shard from another save is refused rather than joined
+	shard.Annotations[ChecksumAnnotation] = "stale"
+	if _, err := clientset.CoreV1().ConfigMaps(ConfigMapNamespace).Update(ctx, shard, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Failed to update shard: %v", err)
+	}
+	if _, err := Load(ctx, clientset, "test-cluster", ""); err == nil || !strings.Contains(err.Error(), "another save") {
+		t.Errorf("Load() error = %v, want the stale shard refused", err)
+	}
+
+	// A smaller state removes the shards it no longer uses
+	for itr := 0; itr < 40; itr++ {
+		loaded.MarkServiceUninstalled(fmt.Sprintf("svc-%d", itr))
+	}
+	if err := loaded.Save(ctx, clientset); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	if _, err := clientset.CoreV1().ConfigMaps(ConfigMapNamespace).Get(ctx, shardName(1), metav1.GetOptions{}); err == nil {
+		t.Error("Expected the unused shard to be deleted")
+	}
+
+	// Delete removes the shards too
+	if err := cs.Save(ctx, clientset); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	if err := Delete(ctx, clientset); err != nil {
+		t.Fatalf("Failed to delete state: %v", err)
+	}
+	configMaps, err := clientset.CoreV1().ConfigMaps(ConfigMapNamespace).List(ctx, metav1.ListOptions{})
+	if err != nil || len(configMaps.Items) != 0 {
+		t.Errorf("Expected no ConfigMaps after Delete, got %d (%v)", len(configMaps.Items), err)
+	}
+}
+
+func TestMarkServicePartial(t *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset()
+	cs := New("test-cluster", false, false, 0, false, 0)
+
+	// A first install that was interrupted is tracked so down removes it
+	cs.MarkServicePartial("api", "apps", true)
+	if !cs.IsServiceInstalled("api") || !cs.IsServicePartial("api") {
+		t.Errorf("Expected api to be installed and partial, got %+v", cs.Services["api"])
+	}
+	if !cs.IsNamespaceCreated("apps") {
+		t.Error("Expected the namespace to be recorded as created")
+	}
+
+	// An interrupted upgrade keeps what the last install recorded
+	cs.MarkServiceInstalledWithNamespace("db", "data", false)
+	cs.SetInstallReason("db", "requested")
+	cs.MarkServicePartial("db", "data", false)
+	if !cs.IsServicePartial("db") || cs.Services["db"].InstallReason != "requested" {
+		t.Errorf("Expected db to be partial with its install reason, got %+v", cs.Services["db"])
+	}
+
+	// Finishing the install clears it
+	cs.MarkServiceInstalledWithNamespace("api", "apps", true)
+	if cs.IsServicePartial("api") {
+		t.Error("Expected a finished install to clear partial")
+	}
+
+	cs.SetInterruptedRun(&InterruptedRun{Labels: []string{"tier=backend"}, Pending: []string{"db", "api"}})
+	if err := cs.Save(ctx, clientset); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	loaded, err := Load(ctx, clientset, "test-cluster", "")
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if loaded.InterruptedRun == nil || !reflect.DeepEqual(loaded.InterruptedRun.Pending, []string{"db", "api"}) {
+		t.Errorf("Expected the interrupted run to round-trip, got %+v", loaded.InterruptedRun)
+	}
+	if !loaded.IsServicePartial("db") {
+		t.Error("Expected partial to round-trip")
+	}
+}
@@ -0,0 +1,166 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute keys naming what a phase worked on, shown next to it in the breakdown
+const (
+	serviceKey = attribute.Key("kraze.service")
+	imageKey   = attribute.Key("kraze.image")
+)
+
+// Service returns the attribute naming the service a phase works on
+func Service(name string) attribute.KeyValue {
+	return serviceKey.String(name)
+}
+
+// Image returns the attribute naming the image a phase works on
+func Image(name string) attribute.KeyValue {
+	return imageKey.String(name)
+}
+
+// Phase is a finished span in the timing breakdown
+type Phase struct {
+	Name     string        `json:"name"`
+	Detail   string        `json:"detail,omitempty"` // Service or image the phase worked on
+	Duration time.Duration `json:"duration"`
+	Failed   bool          `json:"failed,omitempty"`
+	Depth    int           `json:"depth"` // Nesting below the command's root span
+}
+
+// Recorder is a span processor that keeps finished spans for the breakdown
+type Recorder struct {
+	mu    sync.Mutex
+	spans []sdktrace.ReadOnlySpan
+}
+
+// NewRecorder returns an empty Recorder
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// OnStart implements sdktrace.SpanProcessor
+func (recorder *Recorder) OnStart(ctx context.Context, span sdktrace.ReadWriteSpan) {}
+
+// OnEnd implements sdktrace.SpanProcessor
+func (recorder *Recorder) OnEnd(span sdktrace.ReadOnlySpan) {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	recorder.spans = append(recorder.spans, span)
+}
+
+// Shutdown implements sdktrace.SpanProcessor
+func (recorder *Recorder) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// ForceFlush implements sdktrace.SpanProcessor
+func (recorder *Recorder) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// Phases returns the recorded spans depth-first, children in start order.
+// Spans whose parent wasn't recorded are listed at the top level.
+func (recorder *Recorder) Phases() []Phase {
+	recorder.mu.Lock()
+	spans := append([]sdktrace.ReadOnlySpan(nil), recorder.spans...)
+	recorder.mu.Unlock()
+
+	sort.SliceStable(spans, func(left, right int) bool {
+		return spans[left].StartTime().Before(spans[right].StartTime())
+	})
+
+	recorded := make(map[trace.SpanID]bool, len(spans))
+	for _, span := range spans {
+		recorded[span.SpanContext().SpanID()] = true
+	}
+	children := make(map[trace.SpanID][]sdktrace.ReadOnlySpan)
+	var roots []sdktrace.ReadOnlySpan
+	for _, span := range spans {
+		parent := span.Parent().SpanID()
+		if span.Parent().IsValid() && recorded[parent] {
+			children[parent] = append(children[parent], span)
+		} else {
+			roots = append(roots, span)
+		}
+	}
+
+	var phases []Phase
+	var walk func(span sdktrace.ReadOnlySpan, depth int)
+	walk = func(span sdktrace.ReadOnlySpan, depth int) {
+		phase := Phase{
+			Name:     span.Name(),
+			Duration: span.EndTime().Sub(span.StartTime()),
+			Failed:   span.Status().Code == codes.Error,
+			Depth:    depth,
+		}
+		for _, attr := range span.Attributes() {
+			if attr.Key == serviceKey || attr.Key == imageKey {
+				phase.Detail = attr.Value.AsString()
+			}
+		}
+		phases = append(phases, phase)
+		for _, child := range children[span.SpanContext().SpanID()] {
+			walk(child, depth+1)
+		}
+	}
+	for _, root := range roots {
+		walk(root, 0)
+	}
+	return phases
+}
+
+// PrintBreakdown writes the phases as an indented table with each phase's
+// share of the first (root) phase's duration
+func PrintBreakdown(out io.Writer, phases []Phase) {
+	if len(phases) == 0 {
+		return
+	}
+	total := phases[0].Duration
+
+	labels := make([]string, len(phases))
+	width := 0
+	for itr, phase := range phases {
+		label := strings.Repeat("  ", phase.Depth) + phase.Name
+		if phase.Detail != "" {
+			label += " " + phase.Detail
+		}
+		if phase.Failed {
+			label += " (failed)"
+		}
+		labels[itr] = label
+		width = max(width, len(label))
+	}
+
+	fmt.Fprintf(out, "\nTiming breakdown:\n")
+	for itr, phase := range phases {
+		share := ""
+		if total > 0 {
+			share = fmt.Sprintf("%3.0f%%", 100*phase.Duration.Seconds()/total.Seconds())
+		}
+		fmt.Fprintf(out, "  %-*s  %9s  %s\n", width, labels[itr], formatDuration(phase.Duration), share)
+	}
+}
+
+// formatDuration rounds a duration for the breakdown (e.g., 1m2.3s, 450ms, 120µs)
+func formatDuration(duration time.Duration) string {
+	switch {
+	case duration < time.Millisecond:
+		return duration.Round(time.Microsecond).String()
+	case duration < time.Second:
+		return duration.Round(time.Millisecond).String()
+	}
+	return duration.Round(100 * time.Millisecond).String()
+}
@@ -0,0 +1,75 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestRecorderPhases(test *testing.T) {
+	recorder := NewRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := provider.Tracer(tracerName)
+
+	ctx, root := tracer.Start(context.Background(), "kraze up")
+	_, create := tracer.Start(ctx, "cluster.create")
+	create.End()
+	installCtx, install := tracer.Start(ctx, "service.install")
+	install.SetAttributes(Service("api"))
+	_, load := tracer.Start(installCtx, "image.load")
+	load.SetAttributes(Image("api:dev"))
+	EndSpan(load, errors.New("no space left on device"))
+	install.End()
+	root.End()
+
+	var got []string
+	for _, phase := range recorder.Phases() {
+		label := strings.Repeat("-", phase.Depth) + phase.Name
+		if phase.Detail != "" {
+			label += " " + phase.Detail
+		}
+		if phase.Failed {
+			label += " (failed)"
+		}
+		got = append(got, label)
+	}
+	expected := []string{"kraze up", "-cluster.create", "-service.install api", "--image.load api:dev (failed)"}
+	if !reflect.DeepEqual(got, expected) {
+		test.Errorf("Phases() = %v, want %v", got, expected)
+	}
+}
+
+func TestPrintBreakdown(test *testing.T) {
+	phases := []Phase{
+		{Name: "kraze up", Duration: 4 * time.Minute},
+		{Name: "cluster.create", Duration: time.Minute, Depth: 1},
+		{Name: "service.install", Detail: "api", Duration: 250 * time.Millisecond, Depth: 1},
+	}
+
+	var out bytes.Buffer
+	PrintBreakdown(&out, phases)
+
+	for _, line := range []string{
+		"  kraze up                    4m0s  100%\n",
+		"    cluster.create            1m0s   25%\n",
+		"    service.install api      250ms    0%\n",
+	} {
+		if !strings.Contains(out.String(), line) {
+			test.Errorf("breakdown is missing %q:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestPrintBreakdownEmpty(test *testing.T) {
+	var out bytes.Buffer
+	PrintBreakdown(&out, nil)
+	if out.Len() != 0 {
+		test.Errorf("PrintBreakdown(nil) wrote %q", out.String())
+	}
+}
@@ -0,0 +1,107 @@
+// Package telemetry traces the phases of kraze commands with OpenTelemetry, for
+// a timing breakdown at the end of a run and optional export over OTLP
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hjames9/kraze/internal/color"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of kraze spans
+const tracerName = "github.com/hjames9/kraze"
+
+// otlpEndpointEnvVars enable the OTLP exporter when set, as in other OpenTelemetry SDKs
+var otlpEndpointEnvVars = []string{"OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"}
+
+// OTLPConfigured returns true if an OTLP endpoint is set in the environment
+func OTLPConfigured() bool {
+	if os.Getenv("OTEL_SDK_DISABLED") == "true" {
+		return false
+	}
+	for _, name := range otlpEndpointEnvVars {
+		if os.Getenv(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Session is the tracing of one kraze command: the root span, the spans
+// recorded for the breakdown, and the OTLP exporter if one is configured
+type Session struct {
+	provider *sdktrace.TracerProvider
+	recorder *Recorder
+	root     trace.Span
+}
+
+// Start installs a tracer provider and starts the root span of a command. Spans
+// are recorded for Breakdown, and exported over OTLP when OTLPConfigured.
+func Start(ctx context.Context, command, version string) (context.Context, *Session, error) {
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", "kraze"),
+		attribute.String("service.version", version),
+	))
+	if err != nil {
+		return ctx, nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	recorder := NewRecorder()
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res), sdktrace.WithSpanProcessor(recorder)}
+	if OTLPConfigured() {
+		// Endpoint, headers and protocol options come from the OTEL_EXPORTER_OTLP_* variables
+		exporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return ctx, nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(provider)
+	// The SDK reports export failures to the global error handler, which logs them by default
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		fmt.Fprintf(os.Stderr, "%s Trace export failed: %v\n", color.Warning(), err)
+	}))
+
+	ctx, root := provider.Tracer(tracerName).Start(ctx, command)
+	return ctx, &Session{provider: provider, recorder: recorder, root: root}, nil
+}
+
+// End ends the root span with the command's error and flushes the exporter
+func (session *Session) End(ctx context.Context, err error) error {
+	EndSpan(session.root, err)
+	if err := session.provider.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to export traces: %w", err)
+	}
+	return nil
+}
+
+// Breakdown returns the phases recorded during the session
+func (session *Session) Breakdown() []Phase {
+	return session.recorder.Phases()
+}
+
+// StartSpan starts a span for a phase of a command. Without a Session the
+// global tracer provider is a no-op, so instrumentation costs nothing.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan ends a span, marking it failed if err is not nil
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -54,11 +54,11 @@ type ProgressManager interface {
 // otherwise ScrollingProgress. InteractiveProgress uses a viewport so it works
 // regardless of how many services there are relative to terminal height.
 func NewProgressManager(verbose bool, plain bool, total int) ProgressManager {
-	if plain || verbose || !isatty.IsTerminal(os.Stdout.Fd()) {
+	if plain || verbose || !isatty.IsTerminal(outputTerminal().Fd()) {
 		return &ScrollingProgress{verbose: verbose, out: os.Stdout}
 	}
 
-	_, height, err := term.GetSize(int(os.Stdout.Fd()))
+	_, height, err := term.GetSize(int(outputTerminal().Fd()))
 	if err != nil || height <= 0 {
 		height = 24
 	}
@@ -419,6 +419,8 @@ func getOperationPastTense(operation string) string {
 		return "installed"
 	case "Uninstalling":
 		return "uninstalled"
+	case "Seeding":
+		return "seeded"
 	default:
 		return "completed"
 	}
@@ -0,0 +1,263 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// RecordingFormat selects how a recorded run is written
+type RecordingFormat string
+
+const (
+	// RecordingCast writes an asciicast v2 file, replayable with 'asciinema play'
+	RecordingCast RecordingFormat = "cast"
+	// RecordingText writes a plain transcript with terminal escape sequences removed
+	RecordingText RecordingFormat = "text"
+)
+
+// Extension returns the file extension for recordings in this format
+func (format RecordingFormat) Extension() string {
+	if format == RecordingText {
+		return ".txt"
+	}
+	return ".cast"
+}
+
+// ParseRecordingFormat validates a recording format name
+func ParseRecordingFormat(name string) (RecordingFormat, error) {
+	switch RecordingFormat(name) {
+	case RecordingCast, RecordingText:
+		return RecordingFormat(name), nil
+	}
+	return "", fmt.Errorf("invalid recording format '%s' (expected cast or text)", name)
+}
+
+// terminalFile is checked for terminal capabilities instead of os.Stdout while a
+// recording has redirected os.Stdout through a pipe
+var terminalFile *os.File
+
+// outputTerminal returns the file that decides whether output goes to a terminal
+func outputTerminal() *os.File {
+	if terminalFile != nil {
+		return terminalFile
+	}
+	return os.Stdout
+}
+
+// ansiSequence matches CSI and OSC terminal escape sequences
+var ansiSequence = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)`)
+
+// carriageReturns matches line endings written as \r\n
+var carriageReturns = regexp.MustCompile(`\r+\n`)
+
+// StripANSI removes terminal escape sequences and CRLF line endings from output
+func StripANSI(text string) string {
+	return carriageReturns.ReplaceAllString(ansiSequence.ReplaceAllString(text, ""), "\n")
+}
+
+// recordingSink writes captured output in a recording format
+type recordingSink interface {
+	write(elapsed time.Duration, data []byte) error
+}
+
+// castSink writes asciicast v2 output events
+type castSink struct {
+	out     io.Writer
+	pending []byte // Incomplete UTF-8 sequence held back until the next write
+}
+
+// castHeader is the first line of an asciicast v2 file
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+func newCastSink(out io.Writer, title string, width, height int, started time.Time) (*castSink, error) {
+	header := castHeader{
+		Version:   2,
+		Width:     width,
+		Height:    height,
+		Timestamp: started.Unix(),
+		Title:     title,
+		Env:       map[string]string{"TERM": os.Getenv("TERM"), "SHELL": os.Getenv("SHELL")},
+	}
+	line, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fmt.Fprintf(out, "%s\n", line); err != nil {
+		return nil, err
+	}
+	return &castSink{out: out}, nil
+}
+
+func (sink *castSink) write(elapsed time.Duration, data []byte) error {
+	// Events are JSON strings, so a character split across reads must not be emitted in halves
+	data = append(sink.pending, data...)
+	cut := len(data)
+	for itr := len(data) - 1; itr >= 0 && itr >= len(data)-utf8.UTFMax; itr-- {
+		if utf8.RuneStart(data[itr]) {
+			if !utf8.FullRune(data[itr:]) {
+				cut = itr
+			}
+			break
+		}
+	}
+	sink.pending = append([]byte(nil), data[cut:]...)
+	data = data[:cut]
+	if len(data) == 0 {
+		return nil
+	}
+
+	event, err := json.Marshal([]interface{}{elapsed.Seconds(), "o", string(data)})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(sink.out, "%s\n", event)
+	return err
+}
+
+// textSink writes output with escape sequences removed
+type textSink struct {
+	out io.Writer
+}
+
+func (sink *textSink) write(_ time.Duration, data []byte) error {
+	_, err := io.WriteString(sink.out, StripANSI(string(data)))
+	return err
+}
+
+// Recorder captures everything written to os.Stdout and os.Stderr while it runs,
+// passing it through to the terminal and writing it to a recording file
+type Recorder struct {
+	started   time.Time
+	file      *os.File
+	sink      recordingSink
+	stdout    *os.File
+	stderr    *os.File
+	pipes     []*os.File
+	mu        sync.Mutex
+	wg        sync.WaitGroup
+	writeErr  error
+	stopOnce  sync.Once
+	stopError error
+}
+
+// StartRecording starts capturing stdout and stderr into a new file at path
+func StartRecording(path string, format RecordingFormat, title string) (*Recorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording: %w", err)
+	}
+
+	rec := &Recorder{started: time.Now(), file: file, stdout: os.Stdout, stderr: os.Stderr}
+
+	switch format {
+	case RecordingText:
+		rec.sink = &textSink{out: file}
+	default:
+		width, height, err := term.GetSize(int(rec.stdout.Fd()))
+		if err != nil || width <= 0 || height <= 0 {
+			width, height = 80, 24
+		}
+		rec.sink, err = newCastSink(file, title, width, height, rec.started)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to write recording header: %w", err)
+		}
+	}
+
+	stdoutPipe, err := rec.capture(rec.stdout)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	stderrPipe, err := rec.capture(rec.stderr)
+	if err != nil {
+		stdoutPipe.Close()
+		file.Close()
+		return nil, err
+	}
+
+	terminalFile = rec.stdout
+	os.Stdout = stdoutPipe
+	os.Stderr = stderrPipe
+	return rec, nil
+}
+
+// capture returns the write end of a pipe whose output is copied to target and the recording
+func (rec *Recorder) capture(target *os.File) (*os.File, error) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture output: %w", err)
+	}
+	rec.pipes = append(rec.pipes, writer)
+
+	rec.wg.Add(1)
+	go func() {
+		defer rec.wg.Done()
+		defer reader.Close()
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := reader.Read(buf)
+			if n > 0 {
+				target.Write(buf[:n])
+				rec.record(buf[:n])
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return writer, nil
+}
+
+// record appends captured output to the recording, keeping the first write error
+func (rec *Recorder) record(data []byte) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if rec.writeErr != nil {
+		return
+	}
+	rec.writeErr = rec.sink.write(time.Since(rec.started), data)
+}
+
+// Started returns when the recording began
+func (rec *Recorder) Started() time.Time {
+	return rec.started
+}
+
+// Stop restores stdout and stderr, waits for captured output to be written and
+// closes the recording. Idempotent.
+func (rec *Recorder) Stop() error {
+	rec.stopOnce.Do(func() {
+		os.Stdout = rec.stdout
+		os.Stderr = rec.stderr
+		terminalFile = nil
+
+		for _, pipe := range rec.pipes {
+			pipe.Close()
+		}
+		rec.wg.Wait()
+
+		if err := rec.file.Close(); err != nil && rec.writeErr == nil {
+			rec.writeErr = err
+		}
+		if rec.writeErr != nil {
+			rec.stopError = fmt.Errorf("failed to write recording: %w", rec.writeErr)
+		}
+	})
+	return rec.stopError
+}
@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStripANSI(test *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "plain text", input: "Installing 3 service(s)...\n", expected: "Installing 3 service(s)...\n"},
+		{name: "colors", input: "\x1b[32m✓\x1b[0m redis\n", expected: "✓ redis\n"},
+		{name: "cursor movement", input: "\x1b[3A\x1b[2Kpostgres\n", expected: "postgres\n"},
+		{name: "window title", input: "\x1b]0;kraze\x07done\n", expected: "done\n"},
+		{name: "crlf line endings", input: "one\r\ntwo\r\n", expected: "one\ntwo\n"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			if got := StripANSI(tt.input); got != tt.expected {
+				test.Errorf("StripANSI(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCastSinkSplitCharacters(test *testing.T) {
+	var buf bytes.Buffer
+	sink, err := newCastSink(&buf, "kraze up", 120, 40, time.Unix(1700000000, 0))
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// A spinner frame is three bytes in UTF-8; split it across writes
+	frame := []byte("⠋ redis")
+	if err := sink.write(time.Second, frame[:1]); err != nil {
+		test.Fatal(err)
+	}
+	if err := sink.write(2*time.Second, frame[1:]); err != nil {
+		test.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		test.Fatalf("got %d lines, want header and one event:\n%s", len(lines), buf.String())
+	}
+
+	var header castHeader
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil {
+		test.Fatalf("invalid header: %v", err)
+	}
+	if header.Version != 2 || header.Width != 120 || header.Height != 40 || header.Timestamp != 1700000000 {
+		test.Errorf("unexpected header: %+v", header)
+	}
+
+	var event []interface{}
+	if err := json.Unmarshal([]byte(lines[1]), &event); err != nil {
+		test.Fatalf("invalid event: %v", err)
+	}
+	if event[0] != 2.0 || event[1] != "o" || event[2] != "⠋ redis" {
+		test.Errorf("event = %v, want [2 o ⠋ redis]", event)
+	}
+}
+
+func TestRecorderCapturesOutput(test *testing.T) {
+	path := filepath.Join(test.TempDir(), "run.txt")
+	originalStdout := os.Stdout
+
+	// Send the pass-through output somewhere quiet
+	quiet, err := os.Create(filepath.Join(test.TempDir(), "terminal"))
+	if err != nil {
+		test.Fatal(err)
+	}
+	defer quiet.Close()
+	os.Stdout = quiet
+	defer func() { os.Stdout = originalStdout }()
+
+	rec, err := StartRecording(path, RecordingText, "kraze up")
+	if err != nil {
+		test.Fatal(err)
+	}
+	if outputTerminal() != quiet {
+		test.Error("expected terminal checks to use the original stdout while recording")
+	}
+
+	fmt.Println("\x1b[32mInstalling\x1b[0m 1 service(s)...")
+	fmt.Fprintln(os.Stderr, "Warning: slow pull")
+
+	if err := rec.Stop(); err != nil {
+		test.Fatalf("Stop() error = %v", err)
+	}
+	if os.Stdout != quiet || outputTerminal() != quiet {
+		test.Error("expected stdout to be restored")
+	}
+
+	recorded, err := os.ReadFile(path)
+	if err != nil {
+		test.Fatal(err)
+	}
+	for _, want := range []string{"Installing 1 service(s)...\n", "Warning: slow pull\n"} {
+		if !strings.Contains(string(recorded), want) {
+			test.Errorf("recording missing %q:\n%s", want, recorded)
+		}
+	}
+
+	passedThrough, err := os.ReadFile(quiet.Name())
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !strings.Contains(string(passedThrough), "\x1b[32mInstalling") {
+		test.Errorf("expected output to be passed through unchanged, got %q", passedThrough)
+	}
+}
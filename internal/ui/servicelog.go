@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ServiceLogs hands out an output stream per service, so services installed
+// in parallel don't interleave their output. Streams only write whole lines,
+// prefixed with the service name on the console, and with a logs directory
+// each service's full output also goes to <dir>/<service>.log.
+type ServiceLogs struct {
+	dir     string
+	console io.Writer
+	mutex   sync.Mutex
+}
+
+// NewServiceLogs creates the logs directory when dir is set. A nil console
+// keeps the output out of the terminal, leaving it to the progress display.
+func NewServiceLogs(dir string, console io.Writer) (*ServiceLogs, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create logs directory: %w", err)
+		}
+	}
+	return &ServiceLogs{dir: dir, console: console}, nil
+}
+
+// Path returns the log file of a service, or "" without a logs directory
+func (logs *ServiceLogs) Path(service string) string {
+	if logs.dir == "" {
+		return ""
+	}
+	return filepath.Join(logs.dir, service+".log")
+}
+
+// Open returns the stream of a service, truncating its log file. Console
+// lines are prefixed with the service name when prefix is set.
+func (logs *ServiceLogs) Open(service string, prefix bool) (*ServiceStream, error) {
+	stream := &ServiceStream{logs: logs}
+	if prefix {
+		stream.prefix = "[" + service + "] "
+	}
+	if path := logs.Path(service); path != "" {
+		file, err := os.Create(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create log file for '%s': %w", service, err)
+		}
+		stream.file = file
+	}
+	return stream, nil
+}
+
+// ServiceStream is the line-buffered output of one service
+type ServiceStream struct {
+	logs    *ServiceLogs
+	prefix  string
+	file    *os.File
+	pending []byte
+}
+
+// Write buffers p and writes out every line it completes
+func (stream *ServiceStream) Write(p []byte) (int, error) {
+	stream.pending = append(stream.pending, p...)
+	for {
+		end := bytes.IndexByte(stream.pending, '\n')
+		if end < 0 {
+			return len(p), nil
+		}
+		line := stream.pending[:end+1]
+		stream.pending = stream.pending[end+1:]
+		if err := stream.writeLine(line); err != nil {
+			return len(p), err
+		}
+	}
+}
+
+// writeLine writes a line to the log file without colors, and to the console
+// while holding the lock shared by every stream
+func (stream *ServiceStream) writeLine(line []byte) error {
+	stream.logs.mutex.Lock()
+	defer stream.logs.mutex.Unlock()
+
+	if stream.file != nil {
+		if _, err := io.WriteString(stream.file, StripANSI(string(line))); err != nil {
+			return fmt.Errorf("failed to write log file: %w", err)
+		}
+	}
+	if stream.logs.console != nil {
+		if _, err := io.WriteString(stream.logs.console, stream.prefix+string(line)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close writes out a trailing partial line and closes the log file
+func (stream *ServiceStream) Close() error {
+	var err error
+	if len(stream.pending) > 0 {
+		err = stream.writeLine(append(stream.pending, '\n'))
+		stream.pending = nil
+	}
+	if stream.file != nil {
+		if closeErr := stream.file.Close(); err == nil {
+			err = closeErr
+		}
+		stream.file = nil
+	}
+	return err
+}
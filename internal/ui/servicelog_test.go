@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestServiceStream(test *testing.T) {
+	tests := []struct {
+		name     string
+		prefix   bool
+		writes   []string
+		expected string
+	}{
+		{name: "whole lines", writes: []string{"one\ntwo\n"}, expected: "one\ntwo\n"},
+		{name: "prefixed", prefix: true, writes: []string{"one\ntwo\n"}, expected: "[redis] one\n[redis] two\n"},
+		{name: "split lines", prefix: true, writes: []string{"Apply", "ing\nDo", "ne\n"}, expected: "[redis] Applying\n[redis] Done\n"},
+		{name: "partial line on close", prefix: true, writes: []string{"one\ntwo"}, expected: "[redis] one\n[redis] two\n"},
+	}
+
+	for _, tt := range tests {
+		test.Run(tt.name, func(test *testing.T) {
+			var console bytes.Buffer
+			logs, err := NewServiceLogs("", &console)
+			if err != nil {
+				test.Fatal(err)
+			}
+			stream, err := logs.Open("redis", tt.prefix)
+			if err != nil {
+				test.Fatal(err)
+			}
+			for _, write := range tt.writes {
+				if _, err := stream.Write([]byte(write)); err != nil {
+					test.Fatal(err)
+				}
+			}
+			if err := stream.Close(); err != nil {
+				test.Fatal(err)
+			}
+			if console.String() != tt.expected {
+				test.Errorf("console = %q, want %q", console.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestServiceLogsDir(test *testing.T) {
+	dir := filepath.Join(test.TempDir(), "logs")
+	logs, err := NewServiceLogs(dir, nil)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Parallel writers only ever produce whole lines in their own file
+	var wg sync.WaitGroup
+	for _, service := range []string{"redis", "postgres"} {
+		stream, err := logs.Open(service, true)
+		if err != nil {
+			test.Fatal(err)
+		}
+		wg.Add(1)
+		go func(service string) {
+			defer wg.Done()
+			defer stream.Close()
+			for itr := 0; itr < 100; itr++ {
+				fmt.Fprintf(stream, "\x1b[32m✓\x1b[0m %s ", service)
+				fmt.Fprintf(stream, "line %d\n", itr)
+			}
+		}(service)
+	}
+	wg.Wait()
+
+	for _, service := range []string{"redis", "postgres"} {
+		if logs.Path(service) != filepath.Join(dir, service+".log") {
+			test.Errorf("Path(%q) = %q", service, logs.Path(service))
+		}
+		data, err := os.ReadFile(logs.Path(service))
+		if err != nil {
+			test.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		if len(lines) != 100 {
+			test.Fatalf("%s.log has %d lines, want 100", service, len(lines))
+		}
+		for itr, line := range lines {
+			if expected := fmt.Sprintf("✓ %s line %d", service, itr); line != expected {
+				test.Errorf("%s.log line %d = %q, want %q", service, itr, line, expected)
+			}
+		}
+	}
+}